@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/api"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/preflight"
+)
+
+// command is a subcommand of the BFF binary. Every command takes the configuration flags.
+type command struct {
+	// name is the words of the command, e.g. "config validate".
+	name    string
+	summary string
+	run     func(fs *flag.FlagSet, args []string, stdout, stderr io.Writer) int
+}
+
+var commands = []command{
+	{name: "serve", summary: "Start the BFF (the default without a command)", run: runServe},
+	{name: "config validate", summary: "Validate the configuration and print it, secrets redacted; exits 1 when it is invalid", run: runConfigValidate},
+	{name: "preflight", summary: "Run the preflight checks and print their report; exits 1 when a check fails", run: runPreflight},
+	{name: "openapi export", summary: "Print the OpenAPI document of the API", run: runOpenAPIExport},
+	{name: "routes list", summary: "List the routes of the server", run: runRoutesList},
+}
+
+// programName names the binary in the usage messages.
+var programName = filepath.Base(os.Args[0])
+
+// run runs the command args start with, serve when they start with a flag or are empty, and
+// returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) > 0 && (args[0] == "help" || args[0] == "-help" || args[0] == "--help" || args[0] == "-h") {
+		printCommands(stdout)
+		return 0
+	}
+	cmd, rest, ok := findCommand(args)
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", strings.Join(args, " "))
+		printCommands(stderr)
+		return 2
+	}
+
+	fs := flag.NewFlagSet(programName+" "+cmd.name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s [flags]\n\n%s.\n\nFlags:\n", programName, cmd.name, cmd.summary)
+		fs.PrintDefaults()
+	}
+	return cmd.run(fs, rest, stdout, stderr)
+}
+
+// findCommand returns the command args start with and the arguments after its name.
+func findCommand(args []string) (command, []string, bool) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return commands[0], args, true
+	}
+	for _, cmd := range commands {
+		words := strings.Fields(cmd.name)
+		if len(args) >= len(words) && strings.Join(args[:len(words)], " ") == cmd.name {
+			return cmd, args[len(words):], true
+		}
+	}
+	return command{}, nil, false
+}
+
+func printCommands(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s [command] [flags]\n\nCommands:\n", programName)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-16s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(w, "\nRun %s <command> -help for the flags, shared by every command.\n", programName)
+}
+
+// loadCommandConfig loads the configuration of a command, with the settings of the modules in
+// sections. When it fails, the problems are printed to stderr and ok is false, with the exit
+// code.
+func loadCommandConfig(fs *flag.FlagSet, args []string, sections []any, stderr io.Writer) (cfg config.EnvConfig, code int, ok bool) {
+	cfg, err := loadConfig(fs, args, sections)
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		return cfg, 0, false
+	case err != nil:
+		var validationErr *config.ValidationError
+		if !errors.As(err, &validationErr) {
+			// The flag set already printed the parse error and the usage
+			return cfg, 2, false
+		}
+		logger := slog.New(slog.NewTextHandler(stderr, nil))
+		for _, problem := range config.Problems(err) {
+			logger.Error("invalid configuration", "error", problem)
+		}
+		return cfg, 1, false
+	}
+	return cfg, 0, true
+}
+
+// runConfigValidate prints the configuration, module settings included, as a JSON object keyed
+// by flag name, with its secrets redacted.
+func runConfigValidate(fs *flag.FlagSet, args []string, stdout, stderr io.Writer) int {
+	sections := api.ModuleConfigs()
+	cfg, code, ok := loadCommandConfig(fs, args, sections, stderr)
+	if !ok {
+		return code
+	}
+
+	values := map[string]any{}
+	for _, section := range append([]any{cfg}, sections...) {
+		for _, attr := range config.Redacted(section).Group() {
+			values[attr.Key] = jsonValue(attr.Value)
+		}
+	}
+	return writeJSON(stdout, stderr, values)
+}
+
+// jsonValue returns a redacted configuration value as JSON: groups as objects, durations as
+// strings like the flags take them.
+func jsonValue(v slog.Value) any {
+	switch v.Kind() {
+	case slog.KindGroup:
+		group := map[string]any{}
+		for _, attr := range v.Group() {
+			group[attr.Key] = jsonValue(attr.Value)
+		}
+		return group
+	case slog.KindDuration:
+		return v.Duration().String()
+	}
+	return v.Any()
+}
+
+// runPreflight runs the preflight checks of the configuration, whatever -preflight, and prints
+// their report. Logs go to stderr.
+func runPreflight(fs *flag.FlagSet, args []string, stdout, stderr io.Writer) int {
+	app, code, ok := newCommandApp(fs, args, stderr, false)
+	if !ok {
+		return code
+	}
+	defer app.Shutdown()
+
+	report := app.Preflight(context.Background())
+	if code := writeJSON(stdout, stderr, report); code != 0 {
+		return code
+	}
+	if report.Status == preflight.StatusFail {
+		return 1
+	}
+	return 0
+}
+
+// runOpenAPIExport prints the OpenAPI document of the configuration.
+func runOpenAPIExport(fs *flag.FlagSet, args []string, stdout, stderr io.Writer) int {
+	app, code, ok := newCommandApp(fs, args, stderr, true)
+	if !ok {
+		return code
+	}
+	defer app.Shutdown()
+
+	spec, err := app.OpenAPISpec()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if _, err := fmt.Fprintf(stdout, "%s\n", spec); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// runRoutesList prints the routes of the configuration, one "METHOD pattern" per line.
+func runRoutesList(fs *flag.FlagSet, args []string, stdout, stderr io.Writer) int {
+	app, code, ok := newCommandApp(fs, args, stderr, true)
+	if !ok {
+		return code
+	}
+	defer app.Shutdown()
+
+	for _, route := range app.RouteList() {
+		if _, err := fmt.Fprintln(stdout, route); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	}
+	return 0
+}
+
+// newCommandApp builds the App of the configuration for a command, logging to stderr. Commands
+// that only describe the API set offline, and use the in-memory Kubernetes mock rather than
+// a cluster: the routes don't depend on it.
+func newCommandApp(fs *flag.FlagSet, args []string, stderr io.Writer, offline bool) (*api.App, int, bool) {
+	cfg, code, ok := loadCommandConfig(fs, args, api.ModuleConfigs(), stderr)
+	if !ok {
+		return nil, code, false
+	}
+	if offline {
+		cfg.MockK8Client = true
+		cfg.MockK8sBackend = config.MockK8sBackendMemory
+		cfg.MockBFFClients = true
+	}
+	_, logger, err := newLogger(cfg, stderr)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return nil, 1, false
+	}
+	app, err := api.NewApp(cfg, logger)
+	if err != nil {
+		logger.Error(err.Error())
+		return nil, 1, false
+	}
+	return app, 0, true
+}
+
+// writeJSON prints v as indented JSON.
+func writeJSON(stdout, stderr io.Writer, v any) int {
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
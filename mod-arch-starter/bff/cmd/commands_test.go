@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindCommand(t *testing.T) {
+	for args, want := range map[string]string{
+		"":                         "serve",
+		"-port=4000":               "serve",
+		"serve -port=4000":         "serve",
+		"config validate -port=1":  "config validate",
+		"routes list":              "routes list",
+		"openapi export -dev-mode": "openapi export",
+	} {
+		cmd, rest, ok := findCommand(strings.Fields(args))
+		require.True(t, ok, args)
+		assert.Equal(t, want, cmd.name, args)
+		if strings.Contains(args, "-") {
+			assert.Len(t, rest, 1, "the flags are left to the command")
+		}
+	}
+
+	for _, args := range []string{"config", "routes", "bogus -port=4000"} {
+		_, _, ok := findCommand(strings.Fields(args))
+		assert.False(t, ok, args)
+	}
+}
+
+func TestRun(t *testing.T) {
+	runArgs := func(args ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		code := run(args, &stdout, &stderr)
+		return code, stdout.String(), stderr.String()
+	}
+
+	code, stdout, _ := runArgs("help")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout, "config validate")
+	code, _, stderr := runArgs("bogus")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `unknown command "bogus"`)
+
+	code, stdout, _ = runArgs("config", "validate", "-port=4100", "-panic-report-dsn=https://key@sentry.example.com/1")
+	require.Equal(t, 0, code)
+	var values map[string]any
+	require.NoError(t, json.Unmarshal([]byte(stdout), &values))
+	assert.Equal(t, float64(4100), values["port"])
+	assert.Equal(t, "[REDACTED]", values["panic-report-dsn"])
+	assert.Equal(t, "30s", values["shutdown-timeout"])
+	code, _, stderr = runArgs("config", "validate", "-admin-port=-1")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "admin-port")
+	code, _, _ = runArgs("config", "validate", "-no-such-flag")
+	assert.Equal(t, 2, code)
+	code, _, stderr = runArgs("routes", "list", "-help")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stderr, "Usage: ")
+
+	// Offline, with the in-memory Kubernetes mock
+	code, stdout, _ = runArgs("routes", "list", "-debug-endpoints")
+	require.Equal(t, 0, code)
+	routes := strings.Split(strings.TrimSpace(stdout), "\n")
+	assert.Contains(t, routes, "GET /api/v1/namespaces")
+	assert.Contains(t, routes, "GET /api/v1/debug/preflight")
+	assert.Contains(t, routes, "GET /healthcheck")
+
+	code, stdout, _ = runArgs("openapi", "export")
+	require.Equal(t, 0, code)
+	var spec map[string]any
+	require.NoError(t, json.Unmarshal([]byte(stdout), &spec))
+	assert.Contains(t, spec["paths"], "/api/v1/namespaces")
+}
@@ -0,0 +1,8 @@
+package main
+
+// The database/sql drivers of -database-driver: pgx for PostgreSQL, and the pure-Go sqlite,
+// which needs no cgo. Downstream builds import others, like lib/pq or mattn/go-sqlite3, here.
+import (
+	_ "github.com/jackc/pgx/v5/stdlib" // "pgx"
+	_ "modernc.org/sqlite"             // "sqlite"
+)
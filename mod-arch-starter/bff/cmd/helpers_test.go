@@ -1,37 +0,0 @@
-package main
-
-import (
-	"testing"
-)
-
-func TestNewOriginParser(t *testing.T) {
-	cases := []struct {
-		name     string
-		input    string
-		expected []string
-	}{
-		{"one item", "https://test.com", []string{"https://test.com"}},
-		{"two items", "https://test.com,https://test2.com", []string{"https://test.com", "https://test2.com"}},
-		{"two items spaced", "https://test.com,    https://test2.com", []string{"https://test.com", "https://test2.com"}},
-		{"empty", "", []string{}},
-		{"wildcard", "*", []string{"*"}},
-	}
-
-	for _, tc := range cases {
-		t.Run(tc.name, func(t *testing.T) {
-			var allowList []string
-			parser := newOriginParser(&allowList, "")
-			if err := parser(tc.input); err != nil {
-				t.Fatalf("unexpected error: %v", err)
-			}
-			if len(allowList) != len(tc.expected) {
-				t.Fatalf("expected len=%d got=%d", len(tc.expected), len(allowList))
-			}
-			for i := range allowList {
-				if allowList[i] != tc.expected[i] {
-					t.Fatalf("expected %v got %v", tc.expected, allowList)
-				}
-			}
-		})
-	}
-}
@@ -5,11 +5,16 @@ import (
 	"crypto/tls"
 	"flag"
 	"fmt"
+	"io"
 	"os/signal"
+	"reflect"
+	"slices"
 	"syscall"
 
 	"github.com/opendatahub-io/mod-arch-library/bff/internal/api"
 	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	logging "github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/servertls"
 
 	"log/slog"
 	"net/http"
@@ -17,72 +22,23 @@ import (
 	"time"
 )
 
-const (
-	serverIdleTimeout  = time.Minute
-	serverReadTimeout  = 30 * time.Second
-	serverWriteTimeout = 60 * time.Second
-	shutdownTimeout    = 30 * time.Second
-)
-
 func main() {
-	var cfg config.EnvConfig
-	var certFile, keyFile string
-
-	flag.IntVar(&cfg.Port, "port", getEnvAsInt("PORT", 4000), "API server port")
-	flag.StringVar(&certFile, "cert-file", "", "Path to TLS certificate file")
-	flag.StringVar(&keyFile, "key-file", "", "Path to TLS key file")
-	flag.BoolVar(&cfg.MockK8Client, "mock-k8s-client", false, "Use mock Kubernetes client")
-	flag.BoolVar(&cfg.MockHTTPClient, "mock-http-client", false, "Use mock HTTP client")
-	flag.BoolVar(&cfg.DevMode, "dev-mode", false, "Use development mode for access to local K8s cluster")
-	flag.IntVar(&cfg.DevModeClientPort, "dev-mode-client-port", getEnvAsInt("DEV_MODE_CLIENT_PORT", 8080), "Use port when in development mode for client")
-
-	// New deployment mode flag
-	flag.Var(&cfg.DeploymentMode, "deployment-mode", "Deployment mode (kubeflow, federated, or standalone)")
-
-	flag.StringVar(&cfg.StaticAssetsDir, "static-assets-dir", "./static", "Configure frontend static assets root directory")
-	flag.TextVar(&cfg.LogLevel, "log-level", parseLevel(getEnvAsString("LOG_LEVEL", "INFO")), "Sets server log level, possible values: error, warn, info, debug")
-	flag.Func("allowed-origins", "Sets allowed origins for CORS purposes, accepts a comma separated list of origins or * to allow all, default none", newOriginParser(&cfg.AllowedOrigins, getEnvAsString("ALLOWED_ORIGINS", "")))
-	// bundle-paths accepts a comma-separated list of CA bundle file paths to trust for outbound TLS.
-	// If not provided via flag, it can be set via BUNDLE_PATHS env var (comma-separated). Defaults to empty.
-	defaultBundlePaths := getEnvAsString("BUNDLE_PATHS", "")
-	flag.Func("bundle-paths", "Comma-separated list of PEM CA bundle file paths to trust for outbound TLS (optional)", newOriginParser(&cfg.BundlePaths, defaultBundlePaths))
-	flag.StringVar(&cfg.AuthMethod, "auth-method", "internal", "Authentication method (internal or user_token)")
-	flag.StringVar(&cfg.AuthTokenHeader, "auth-token-header", getEnvAsString("AUTH_TOKEN_HEADER", config.DefaultAuthTokenHeader), "Header used to extract the token (e.g., Authorization)")
-	flag.StringVar(&cfg.AuthTokenPrefix, "auth-token-prefix", getEnvAsString("AUTH_TOKEN_PREFIX", config.DefaultAuthTokenPrefix), "Prefix used in the token header (e.g., 'Bearer ')")
-
-	// TLS configuration flags
-	flag.BoolVar(&cfg.InsecureSkipVerify, "insecure-skip-verify", getEnvAsBool("INSECURE_SKIP_VERIFY", false), "Skip TLS certificate verification (useful for development, default: false)")
-
-	// ─── BFF Inter-Communication ─────────────────────────────────
-	flag.BoolVar(&cfg.MockBFFClients, "mock-bff-clients",
-		getEnvAsBool("MOCK_BFF_CLIENTS", false),
-		"Enable mock BFF clients (no real HTTP calls to other BFFs)")
-
-	// Deprecated flags - kept for backward compatibility
-	flag.BoolVar(&cfg.StandaloneMode, "standalone-mode", false, "DEPRECATED: Use -deployment-mode=standalone instead")
-	flag.BoolVar(&cfg.FederatedPlatform, "federated-platform", false, "DEPRECATED: Use -deployment-mode=federated instead")
-
-	flag.Parse()
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
 
-	// Handle backward compatibility: if old flags are used, override deployment mode
-	if cfg.StandaloneMode {
-		cfg.DeploymentMode = config.DeploymentModeStandalone
-	} else if cfg.FederatedPlatform {
-		cfg.DeploymentMode = config.DeploymentModeFederated
+// runServe starts the BFF and serves until a shutdown signal.
+func runServe(fs *flag.FlagSet, args []string, stdout, stderr io.Writer) int {
+	// The settings of the modules are loaded with the BFF configuration
+	cfg, code, ok := loadCommandConfig(fs, args, api.ModuleConfigs(), stderr)
+	if !ok {
+		return code
 	}
 
-	// Ensure the deprecated boolean fields are consistent with the new deployment mode
-	cfg.StandaloneMode = cfg.DeploymentMode.IsStandaloneMode()
-	cfg.FederatedPlatform = cfg.DeploymentMode.IsFederatedMode()
-
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: cfg.LogLevel,
-	}))
-
-	//validate auth method
-	if cfg.AuthMethod != config.AuthMethodInternal && cfg.AuthMethod != config.AuthMethodUser {
-		logger.Error("invalid auth method: (must be internal or user_token)", "authMethod", cfg.AuthMethod)
-		os.Exit(1)
+	// The levels can be changed at runtime by reloading the configuration file.
+	logLevels, logger, err := newLogger(cfg, stdout)
+	if err != nil {
+		slog.Error("invalid configuration", "error", err)
+		return 1
 	}
 
 	// Only use for logging errors about logging configuration.
@@ -91,59 +47,244 @@ func main() {
 	app, err := api.NewApp(cfg, slog.New(logger.Handler()))
 	if err != nil {
 		logger.Error(err.Error())
-		os.Exit(1)
+		return 1
 	}
 
-	srv := &http.Server{
-		Addr:         fmt.Sprintf(":%d", cfg.Port),
-		Handler:      app.Routes(),
-		IdleTimeout:  serverIdleTimeout,
-		ReadTimeout:  serverReadTimeout,
-		WriteTimeout: serverWriteTimeout,
-		ErrorLog:     slog.NewLogLogger(logger.Handler(), slog.LevelError),
+	// Check the permissions, upstreams and TLS files before serving
+	if err := app.RunPreflight(context.Background()); err != nil {
+		logger.Error(err.Error())
+		return 1
 	}
 
-	// Start the server in a goroutine
-	go func() {
-		logger.Info("starting server", "addr", srv.Addr, "TLS enabled", (certFile != "" && keyFile != ""))
-		var err error
-		if certFile != "" && keyFile != "" {
-			// Configure TLS if both cert and key files are provided
-			tlsConfig := &tls.Config{
-				MinVersion: tls.VersionTLS13,
+	// Apply the reloadable settings of the configuration file when it changes, e.g. a mounted ConfigMap
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	if path := config.FilePath(fs, nil); path != "" {
+		reloader := config.NewReloader(cfg, func() (config.EnvConfig, error) {
+			// Module settings are not reloaded: load them into copies, so their keys are known
+			return loadConfig(flag.NewFlagSet(fs.Name(), flag.ContinueOnError), args, copySections(api.ModuleConfigs()))
+		}, logger)
+		reloader.Subscribe(func(old, updated config.EnvConfig) {
+			// Only apply the settings that changed, so levels set with PUT /api/v1/debug/loglevel
+			// survive unrelated reloads
+			if updated.LogLevel != old.LogLevel {
+				logLevels.SetLevel(updated.LogLevel)
 			}
-			srv.TLSConfig = tlsConfig
-			err = srv.ListenAndServeTLS(certFile, keyFile)
-		} else {
-			err = srv.ListenAndServe()
-		}
-		if err != nil && err != http.ErrServerClosed {
-			logger.Error("HTTP server ListenAndServe", "error", err)
+			if !slices.Equal(updated.LogLevels, old.LogLevels) {
+				packageLevels, _ := logging.ParsePackageLevels(updated.LogLevels)
+				logLevels.SetPackageLevels(packageLevels)
+			}
+		})
+		reloader.Subscribe(app.ApplyConfigChange)
+		if err := reloader.Watch(watchCtx, path); err != nil {
+			logger.Error("configuration changes will not be reloaded", "error", err)
 		}
-	}()
+	}
+
+	// Reload the targeted feature flags when their file changes, e.g. a mounted ConfigMap
+	if err := app.WatchFeatureFlags(watchCtx); err != nil {
+		logger.Error("feature flag changes will not be reloaded", "error", err)
+	}
+
+	// Reload the rules of the policy authorizer when its file changes
+	if err := app.WatchAuthorizationPolicy(watchCtx); err != nil {
+		logger.Error("authorization policy changes will not be reloaded", "error", err)
+	}
+
+	// Reload the outbound CA bundles and client certificates when they rotate
+	if err := app.WatchOutboundTLS(watchCtx); err != nil {
+		logger.Error("outbound TLS file changes will not be reloaded", "error", err)
+	}
+
+	// Run the background jobs until shutdown
+	app.StartJobs(watchCtx)
+
+	// Prime the caches of the warmup namespaces, /readyz fails until then
+	app.StartWarmup(watchCtx)
+
+	srv := newServer(cfg, cfg.Port, app.Routes(), logger)
+
+	// Long-lived streams never go idle, so end them as soon as the listener closes
+	srv.RegisterOnShutdown(app.CloseStreams)
+
+	tlsConfig, err := newTLSConfig(watchCtx, cfg, logger)
+	if err != nil {
+		logger.Error(err.Error())
+		stopWatch()
+		return 1
+	}
+
+	// Start the server in a goroutine
+	go serve(srv, tlsConfig, logger)
+
+	// The debug endpoints, when kept off the API port, have their own server
+	var adminSrv *http.Server
+	if handler := app.AdminRoutes(); handler != nil {
+		adminSrv = newServer(cfg, cfg.AdminPort, handler, logger)
+		go serve(adminSrv, tlsConfig, logger)
+	}
 
 	// Graceful shutdown setup
-	shutdownCh := make(chan os.Signal, 1)
+	shutdownCh := make(chan os.Signal, 2)
 	signal.Notify(shutdownCh, os.Interrupt, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 
 	// Wait for shutdown signal
 	<-shutdownCh
-	logger.Info("shutting down gracefully...")
+	logger.Info("shutting down gracefully...", "delay", cfg.ShutdownDelay, "timeout", cfg.ShutdownTimeout)
+
+	// A second signal skips the drain
+	go func() {
+		<-shutdownCh
+		logger.Warn("second shutdown signal received, exiting immediately")
+		os.Exit(1)
+	}()
+
+	// Fail readiness first, so traffic moves to other replicas before the listener closes
+	app.StartDrain()
+	if cfg.ShutdownDelay > 0 {
+		time.Sleep(cfg.ShutdownDelay)
+	}
 
 	// Create a context with timeout for the shutdown process
-	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	timeout := cfg.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = config.DefaultShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	// Shutdown the HTTP server gracefully
+	// Shutdown the HTTP server gracefully: stop accepting connections and wait for in-flight requests
 	if err := srv.Shutdown(ctx); err != nil {
-		logger.Error("server shutdown failed", "error", err)
+		logger.Error("server shutdown failed", "error", err, "inFlight", app.InFlightRequests())
 	}
+	if adminSrv != nil {
+		if err := adminSrv.Shutdown(ctx); err != nil {
+			logger.Error("admin server shutdown failed", "error", err)
+		}
+	}
+
+	stopWatch()
 
-	// Shutdown the App gracefully
+	// Shutdown the App gracefully (informers and watches, WebSocket tracker, tracing)
 	if err := app.Shutdown(); err != nil {
 		logger.Error("failed to shutdown Kubernetes manager", "error", err)
 	}
 
 	logger.Info("server stopped")
-	os.Exit(0)
+	return 0
+}
+
+// newLogger returns the logger of cfg writing to output, and its levels.
+// Validate already checked the per-package levels.
+func newLogger(cfg config.EnvConfig, output io.Writer) (*logging.Levels, *slog.Logger, error) {
+	packageLevels, _ := logging.ParsePackageLevels(cfg.LogLevels)
+	logLevels := logging.NewLevels(cfg.LogLevel)
+	logLevels.SetPackageLevels(packageLevels)
+	logger, err := logging.New(logging.Options{Format: cfg.LogFormat, Output: output, Levels: logLevels})
+	return logLevels, logger, err
+}
+
+// newTLSConfig returns the TLS configuration of the servers, nil without a certificate and key.
+// The certificate, key and client CAs are reloaded when their files change, until ctx is done.
+func newTLSConfig(ctx context.Context, cfg config.EnvConfig, logger *slog.Logger) (*tls.Config, error) {
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, nil
+	}
+	server, err := servertls.New(servertls.Config{
+		CertFile:     cfg.CertFile,
+		KeyFile:      cfg.KeyFile,
+		ClientCAFile: cfg.TLSClientCAFile,
+		ClientAuth:   cfg.TLSClientAuth,
+		MinVersion:   cfg.TLSMinVersion,
+	}, logging.ForPackage(logger, "servertls"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range server.Files() {
+		err := config.WatchFile(ctx, file, logger, func() {
+			if err := server.Reload(); err != nil {
+				logger.Error("failed to reload TLS files, keeping the current ones", "path", file, "error", err)
+			}
+		})
+		if err != nil {
+			logger.Error("TLS file changes will not be reloaded", "error", err)
+		}
+	}
+	return server.TLSConfig(), nil
+}
+
+// newServer returns the server of handler on port, with the timeouts, limits and protocols of
+// cfg: HTTP/1.1, HTTP/2 over TLS unless disabled, and h2c when enabled.
+func newServer(cfg config.EnvConfig, port int, handler http.Handler, logger *slog.Logger) *http.Server {
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(cfg.HTTP2Enabled)
+	protocols.SetUnencryptedHTTP2(cfg.HTTP2Enabled && cfg.H2CEnabled)
+
+	srv := &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           handler,
+		IdleTimeout:       cfg.ServerIdleTimeout,
+		ReadTimeout:       cfg.ServerReadTimeout,
+		ReadHeaderTimeout: cfg.ServerReadHeaderTimeout,
+		WriteTimeout:      cfg.ServerWriteTimeout,
+		MaxHeaderBytes:    cfg.ServerMaxHeaderBytes,
+		Protocols:         &protocols,
+		HTTP2: &http.HTTP2Config{
+			MaxConcurrentStreams: cfg.HTTP2MaxConcurrentStreams,
+			SendPingTimeout:      cfg.HTTP2PingInterval,
+			PingTimeout:          cfg.HTTP2PingTimeout,
+		},
+		ErrorLog: slog.NewLogLogger(logger.Handler(), slog.LevelError),
+	}
+	srv.SetKeepAlivesEnabled(cfg.ServerKeepAlives)
+	return srv
+}
+
+// serve runs srv until it is shut down, with TLS when tlsConfig is set.
+func serve(srv *http.Server, tlsConfig *tls.Config, logger *slog.Logger) {
+	logger.Info("starting server", "addr", srv.Addr, "TLS enabled", tlsConfig != nil, "protocols", srv.Protocols.String())
+	var err error
+	if tlsConfig != nil {
+		// The certificate is served by tlsConfig, so it can be reloaded
+		srv.TLSConfig = tlsConfig
+		err = srv.ListenAndServeTLS("", "")
+	} else {
+		err = srv.ListenAndServe()
+	}
+	if err != nil && err != http.ErrServerClosed {
+		logger.Error("HTTP server ListenAndServe", "addr", srv.Addr, "error", err)
+	}
+}
+
+// loadConfig loads the configuration from defaults, the -config file, environment variables and
+// args, the command-line flags registered on fs.
+func loadConfig(fs *flag.FlagSet, args []string, sections []any) (config.EnvConfig, error) {
+	cfg := config.DefaultEnvConfig()
+	if err := config.Load(&cfg, config.LoadOptions{FlagSet: fs, Args: args, Sections: sections}); err != nil {
+		return cfg, err
+	}
+
+	// Handle backward compatibility: if old flags are used, override deployment mode
+	if cfg.StandaloneMode {
+		cfg.DeploymentMode = config.DeploymentModeStandalone
+	} else if cfg.FederatedPlatform {
+		cfg.DeploymentMode = config.DeploymentModeFederated
+	}
+
+	// Ensure the deprecated boolean fields are consistent with the new deployment mode
+	cfg.StandaloneMode = cfg.DeploymentMode.IsStandaloneMode()
+	cfg.FederatedPlatform = cfg.DeploymentMode.IsFederatedMode()
+	return cfg, nil
+}
+
+// copySections returns copies of the configuration sections, pointers to structs.
+func copySections(sections []any) []any {
+	copies := make([]any, len(sections))
+	for i, section := range sections {
+		value := reflect.ValueOf(section).Elem()
+		copied := reflect.New(value.Type())
+		copied.Elem().Set(value)
+		copies[i] = copied.Interface()
+	}
+	return copies
 }
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewServer(t *testing.T) {
+	cfg := config.DefaultEnvConfig()
+	cfg.ServerReadHeaderTimeout = 5 * time.Second
+	cfg.HTTP2MaxConcurrentStreams = 100
+	cfg.HTTP2PingInterval = 30 * time.Second
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	srv := newServer(cfg, 4000, http.NotFoundHandler(), logger)
+	assert.Equal(t, ":4000", srv.Addr)
+	assert.Equal(t, 5*time.Second, srv.ReadHeaderTimeout)
+	assert.Equal(t, config.DefaultServerWriteTimeout, srv.WriteTimeout)
+	assert.Equal(t, 100, srv.HTTP2.MaxConcurrentStreams)
+	assert.Equal(t, 30*time.Second, srv.HTTP2.SendPingTimeout)
+	assert.True(t, srv.Protocols.HTTP1())
+	assert.True(t, srv.Protocols.HTTP2())
+	assert.False(t, srv.Protocols.UnencryptedHTTP2())
+
+	cfg.HTTP2Enabled = false
+	assert.False(t, newServer(cfg, 4000, http.NotFoundHandler(), logger).Protocols.HTTP2())
+}
+
+func TestNewServer_H2C(t *testing.T) {
+	cfg := config.DefaultEnvConfig()
+	cfg.H2CEnabled = true
+	srv := newServer(cfg, 0, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Proto)
+	}), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = srv.Serve(listener) }()
+	t.Cleanup(func() { _ = srv.Close() })
+
+	// A client with prior knowledge speaks HTTP/2 over plaintext
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: &protocols}}
+	resp, err := client.Get("http://" + listener.Addr().String())
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP/2.0", string(body))
+}
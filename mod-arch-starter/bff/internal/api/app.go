@@ -2,37 +2,117 @@ package api
 
 import (
 	"context"
-	"crypto/x509"
+	"crypto/tls"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
-	"os"
-	"path"
+	"slices"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/bffclient"
 	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/bffclient/bffmocks"
+	grpcclient "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/grpc"
 	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
 	k8mocks "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes/k8mocks"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/objectstore"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/tlsclient"
 	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 
-	helper "github.com/opendatahub-io/mod-arch-library/bff/internal/helpers"
+	logging "github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
 
+	"github.com/graph-gophers/graphql-go"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/audit"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/authz"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/cache"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/chaos"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/concurrency"
 	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/database"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/featureflags"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/healthcheck"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/i18n"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/jobs"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/leader"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/metrics"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/mockserver"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/notifications"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/operations"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/panicreport"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/provisioning"
 	"github.com/opendatahub-io/mod-arch-library/bff/internal/proxy"
 	"github.com/opendatahub-io/mod-arch-library/bff/internal/repositories"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/resilience"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/session"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/tracing"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/watchmux"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/webhooks"
 
 	"github.com/julienschmidt/httprouter"
 )
 
 const (
-	Version         = "1.0.0"
-	PathPrefix      = "/mod-arch"
-	ApiPathPrefix   = "/api/v1"
-	HealthCheckPath = "/healthcheck"
-	UserPath      = ApiPathPrefix + "/user"
-	NamespacePath = ApiPathPrefix + "/namespaces"
+	PathPrefix           = "/mod-arch"
+	ApiPathPrefix        = "/api/v1"
+	HealthCheckPath      = "/healthcheck"
+	MetricsPath          = "/metrics"
+	UserPath             = ApiPathPrefix + "/user"
+	PreferencesPath      = UserPath + "/preferences"
+	NamespacePath        = ApiPathPrefix + "/namespaces"
+	ClustersPath         = ApiPathPrefix + "/clusters"
+	ClusterHealthPath    = ApiPathPrefix + "/cluster-health"
+	HardwareProfilesPath = ApiPathPrefix + "/hardware-profiles"
+	PermissionsPath      = ApiPathPrefix + "/permissions"
+	PermissionsBatchPath = PermissionsPath + "/batch"
+	WatchPath            = ApiPathPrefix + "/watch/:resource"
+	PodLogsPath          = ApiPathPrefix + "/pods/:pod/logs"
+	ServicesPath         = ApiPathPrefix + "/services"
+	EventsPath           = ApiPathPrefix + "/events"
+	QuotaPath            = ApiPathPrefix + "/quota"
+	RoleBindingsPath     = ApiPathPrefix + "/role-bindings"
+	RoleBindingPath      = RoleBindingsPath + "/:name"
+	TokenRequestPath     = ApiPathPrefix + "/serviceaccounts/:name/token"
+	SecretsPath          = ApiPathPrefix + "/secrets"
+	SecretPath           = SecretsPath + "/:name"
+	ConfigMapsPath       = ApiPathPrefix + "/configmaps"
+	ConfigMapPath        = ConfigMapsPath + "/:name"
+	ObjectStoragePath    = ApiPathPrefix + "/object-storage/:secret"
+	BucketsPath          = ObjectStoragePath + "/buckets"
+	ObjectsPath          = ObjectStoragePath + "/objects"
+	ObjectPath           = ObjectStoragePath + "/object"
+	PresignPath          = ObjectStoragePath + "/presign"
+	LogLevelPath         = ApiPathPrefix + "/debug/loglevel"
+	ChaosPath            = ApiPathPrefix + "/debug/chaos"
+	DebugRuntimePath     = ApiPathPrefix + "/debug/runtime"
+	DebugPreflightPath   = ApiPathPrefix + "/debug/preflight"
+	ExpvarPath           = ApiPathPrefix + "/debug/vars"
+	PprofPath            = ApiPathPrefix + "/debug/pprof/"
+	OpenAPIPath          = ApiPathPrefix + "/openapi.json"
+	APIDocsPath          = ApiPathPrefix + "/docs"
+	FrontendConfigPath   = ApiPathPrefix + "/config"
+	FeaturesPath         = ApiPathPrefix + "/features"
+	ModulesPath          = ApiPathPrefix + "/modules"
+	VersionPath          = ApiPathPrefix + "/version"
+	LoginPath            = ApiPathPrefix + "/login"
+	LoginCallbackPath    = LoginPath + "/callback"
+	LogoutPath           = ApiPathPrefix + "/logout"
+	NotificationsPath    = ApiPathPrefix + "/notifications/stream"
+	OperationsPath       = ApiPathPrefix + "/operations"
+	OperationPath        = OperationsPath + "/:id"
+	GraphQLPath          = ApiPathPrefix + "/graphql"
+
+	ModelRegistryListPath      = ApiPathPrefix + "/model_registry"
+	ModelRegistryPath          = ModelRegistryListPath + "/:" + ModelRegistryIDParam
+	RegisteredModelListPath    = ModelRegistryPath + "/registered_models"
+	RegisteredModelPath        = RegisteredModelListPath + "/:" + RegisteredModelIDParam
+	RegisteredModelVersionPath = RegisteredModelPath + "/versions"
+	ModelVersionPath           = ModelRegistryPath + "/model_versions/:" + ModelVersionIDParam
+	ModelVersionArtifactPath   = ModelVersionPath + "/artifacts"
+	ModelArtifactPath          = ModelRegistryPath + "/model_artifacts/:" + ModelArtifactIDParam
 )
 
 type App struct {
@@ -42,11 +122,102 @@ type App struct {
 	repositories            *repositories.Repositories
 	//used only on mocked k8s client
 	testEnv *envtest.Environment
-	// rootCAs used for outbound TLS connections to Client Service
-	rootCAs *x509.CertPool
+	// outboundTLS holds the TLS client configurations of the upstreams
+	outboundTLS *tlsclient.Set
 	// bffClientFactory creates clients for inter-BFF communication
 	bffClientFactory bffclient.BFFClientFactory
 	wsTracker        *proxy.ConnectionTracker
+	// healthChecks backs /livez, /readyz and /healthz
+	healthChecks *healthcheck.Registry
+	// jobs runs the background jobs from StartJobs
+	jobs *jobs.Scheduler
+	// leader elects the replica running the leader-only jobs; nil unless -leader-election-enabled
+	leader *leader.Elector
+	// notifications is the bus of the notifications streamed on NotificationsPath
+	notifications *notifications.Bus
+	// watchMux shares the watches of WatchPath; nil unless -watch-multiplexing
+	watchMux *watchmux.Mux
+	// operations tracks the long-running actions started with StartOperation
+	operations *operations.Tracker
+	// namespaceTemplates are the templates of ProvisionNamespaceHandler; nil unless
+	// -namespace-provisioning is set
+	namespaceTemplates *provisioning.Set
+	// database stores the state of the modules; nil unless -database-driver is set
+	database *database.DB
+	// graphqlSchema is the schema served on GraphQLPath; nil unless -graphql-enabled
+	graphqlSchema *graphql.Schema
+	// graphqlReviewWait gathers the access reviews of a GraphQL query into batches (see
+	// graphQLReviews)
+	graphqlReviewWait time.Duration
+	// reverseProxy serves the routes registered with RegisterProxyRoute; nil when there are none
+	reverseProxy *proxy.ReverseProxy
+	// frontendDevServer proxies the frontend to -frontend-dev-server-url; nil unless set
+	frontendDevServer *proxy.ReverseProxy
+	// modules are the registered modules, initialized (see RegisterModule)
+	modules []Module
+	// uiModules are the manifests of the federated frontends served at ModulesPath
+	uiModules []models.UIModule
+	// versionedRoutes are the routes of the API versions (see RegisterVersionedRoute) and modules
+	versionedRoutes []VersionedRoute
+	// deprecations of the deprecated routes, by method and route pattern
+	deprecations map[string]Deprecation
+	// identityExtractor resolves the RequestIdentity of incoming API requests
+	identityExtractor IdentityExtractor
+	// authorizer authorizes the API requests once their identity is known; nil unless
+	// cfg.Authorizers is set or authorizers are registered
+	authorizer authz.Authorizer
+	// authzPolicy is the reloadable policy authorizer; nil unless cfg.Authorizers lists it
+	authzPolicy *authz.PolicyAuthorizer
+	// sessions signs users in and keeps their tokens; nil unless cfg.SessionEnabled
+	sessions *session.Manager
+	// metrics is nil unless cfg.MetricsEnabled is set
+	metrics *metrics.Metrics
+	// shutdownTracing flushes pending spans; nil unless cfg.TracingEnabled is set
+	shutdownTracing func(context.Context) error
+	// drain tracks in-flight requests and ends long-lived streams on shutdown
+	drain drainState
+	// warmup tracks the cache warmup of StartWarmup
+	warmup warmupState
+	// idempotency stores the responses replayed by HonorIdempotencyKeys; nil when disabled
+	idempotency *idempotency
+	// bodyLog logs the bodies of the requests of -body-log-routes (see LogBodies)
+	bodyLog *bodyLogger
+	// configSubscribers are notified of reloaded configuration values
+	configSubscribers configSubscribers
+	// rateLimiters throttles API requests per user and per client IP
+	rateLimiters rateLimiters
+	// concurrency caps the API requests served at once; nil unless cfg.MaxConcurrentRequests
+	// or cfg.MaxConcurrentRequestsPerUser is set
+	concurrency *concurrency.Limiter
+	// responseCache is nil unless cfg.ResponseCacheTTL is set
+	responseCache *cache.Cache
+	// dashboardCache caches the reads of ODHDashboard; nil unless cfg.ODHDashboardCacheTTL is set
+	dashboardCache *cache.Cache
+	// openAPISpec is the JSON OpenAPI document served at OpenAPIPath
+	openAPISpec []byte
+	// featureFlags are evaluated per request by FeatureEnabled and FeaturesPath
+	featureFlags *featureflags.Set
+	// auditor records the mutating requests; nil unless cfg.AuditSinks is set
+	auditor *audit.Auditor
+	// mockServer serves the fixtures of the API routes; nil unless cfg.MockServerFixtures is set
+	mockServer *mockserver.Server
+	// webhooks dispatches the resource and operation events; nil unless cfg.WebhookEndpoints
+	// is set
+	webhooks *webhooks.Dispatcher
+	// panicReporter forwards the recovered panics; nil unless cfg.PanicReportDSN is set
+	panicReporter *panicreport.Reporter
+	// resilience retries the calls to the Kubernetes API server and upstream services, and
+	// holds their circuit breakers
+	resilience *resilience.Policy
+	// chaos injects the faults of the fault injection rules; nil unless cfg.ChaosEnabled is set
+	chaos *chaos.Injector
+	// messages localizes the error messages
+	messages *i18n.Catalog
+	// grpcConns holds the connections to the gRPC services of downstream repositories
+	grpcConns *grpcclient.Pool
+	// clusters holds the client factories of the local and other clusters; it is also the
+	// kubernetesClientFactory. Nil in apps built around a factory (see clusterRegistry).
+	clusters *k8s.ClusterRegistry
 }
 
 func NewApp(cfg config.EnvConfig, logger *slog.Logger) (*App, error) {
@@ -55,43 +226,63 @@ func NewApp(cfg config.EnvConfig, logger *slog.Logger) (*App, error) {
 	var err error
 	// used only on mocked k8s client
 	var testEnv *envtest.Environment
-	var rootCAs *x509.CertPool
-
-	// Initialize CA pool if bundle paths are provided
-	if len(cfg.BundlePaths) > 0 {
-		// Start with system certs if available
-		if pool, err := x509.SystemCertPool(); err == nil {
-			rootCAs = pool
-		} else {
-			rootCAs = x509.NewCertPool()
-		}
-		var loadedAny bool
-		for _, p := range cfg.BundlePaths {
-			p = strings.TrimSpace(p)
-			if p == "" {
-				continue
-			}
-			// Read and append each PEM bundle; ignore errors per file, log at debug
-			pemBytes, readErr := os.ReadFile(p)
-			if readErr != nil {
-				logger.Debug("CA bundle not readable, skipping", slog.String("path", p), slog.Any("error", readErr))
-				continue
-			}
-			if ok := rootCAs.AppendCertsFromPEM(pemBytes); !ok {
-				logger.Debug("No certs appended from PEM bundle", slog.String("path", p))
-				continue
-			}
-			loadedAny = true
-			logger.Info("Added CA bundle", slog.String("path", p))
-		}
-		if !loadedAny {
-			// If none were loaded successfully, keep rootCAs nil to fall back to default transport behavior
-			rootCAs = nil
-			logger.Warn("No CA certificates loaded from bundle-paths; falling back to system defaults")
+
+	outboundTLS, err := newOutboundTLS(cfg, logging.ForPackage(logger, "tlsclient"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Kubernetes clients pick up transport wrappers when they are created, so metrics
+	// must be set up before the client factory.
+	var appMetrics *metrics.Metrics
+	if cfg.MetricsEnabled {
+		appMetrics = metrics.New()
+		k8s.RegisterTransportWrapper("metrics", appMetrics.WrapKubernetesTransport)
+		appMetrics.RecordKubernetesRateLimiter()
+	}
+	k8s.UseClientSettings(k8s.ClientSettingsFromEnv(cfg))
+
+	upstreamPolicy := resilience.New(resilience.ConfigFromEnv(cfg))
+	if appMetrics != nil {
+		upstreamPolicy.Observe(appMetrics.RecordCircuitBreakerState, appMetrics.RecordUpstreamRetry)
+	}
+	// Each cluster has its own circuit breaker. The API servers of the other clusters are only
+	// known once the clusters are loaded, below.
+	var clusters atomic.Pointer[k8s.ClusterRegistry]
+	k8s.RegisterTransportWrapper("resilience", upstreamPolicy.WrapTransportFor(func(req *http.Request) string {
+		return kubernetesUpstreamOf(clusters.Load().ClusterOfRequest(req))
+	}))
+
+	// Faults are injected below the retries, so they are retried like real failures
+	var faults *chaos.Injector
+	if cfg.ChaosEnabled {
+		faults = chaos.New()
+		k8s.RegisterTransportWrapper("chaos", faults.WrapTransportFor(func(req *http.Request) string {
+			return kubernetesUpstreamOf(clusters.Load().ClusterOfRequest(req))
+		}))
+	}
+
+	var shutdownTracing func(context.Context) error
+	if cfg.TracingEnabled {
+		shutdownTracing, err = tracing.Setup(context.Background(), Version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up tracing: %w", err)
 		}
+		k8s.RegisterTransportWrapper("tracing", func(rt http.RoundTripper) http.RoundTripper {
+			return tracing.WrapTransport(rt, kubernetesSpanName)
+		})
 	}
 
-	if cfg.MockK8Client {
+	// Integrations log under their own package, so their level can be set separately (-log-levels)
+	k8sLogger := logging.ForPackage(logger, "kubernetes")
+	bffLogger := logging.ForPackage(logger, "bffclient")
+
+	if cfg.MockK8Client && cfg.MockK8sBackend == config.MockK8sBackendMemory || cfg.MockServerFixtures != "" {
+		//mock all k8s calls with in-memory fixtures, no cluster needed
+		logger.Info("Using in-memory mock Kubernetes client")
+		k8sFactory, err = k8mocks.NewInMemoryKubernetesClientFactory(cfg, k8sLogger)
+
+	} else if cfg.MockK8Client {
 		//mock all k8s calls with 'env test'
 		var clientset kubernetes.Interface
 		ctx, cancel := context.WithCancel(context.Background())
@@ -104,16 +295,32 @@ func NewApp(cfg config.EnvConfig, logger *slog.Logger) (*App, error) {
 			return nil, fmt.Errorf("failed to setup envtest: %w", err)
 		}
 		//create mocked kubernetes client factory
-		k8sFactory, err = k8mocks.NewMockedKubernetesClientFactory(clientset, testEnv, cfg, logger)
+		k8sFactory, err = k8mocks.NewMockedKubernetesClientFactory(clientset, testEnv, cfg, k8sLogger)
 
 	} else {
 		//create kubernetes client factory
-		k8sFactory, err = k8s.NewKubernetesClientFactory(cfg, logger)
+		k8sFactory, err = k8s.NewKubernetesClientFactory(cfg, k8sLogger)
 	}
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
 	}
+	if factory, ok := k8sFactory.(interface{ ReviewCache() *k8s.ReviewCache }); ok && appMetrics != nil {
+		factory.ReviewCache().Observe(appMetrics.RecordReviewCacheLookup)
+	}
+
+	clusterRegistry := k8s.NewClusterRegistry(k8sFactory, cfg.ClusterName, k8sLogger)
+	clusters.Store(clusterRegistry)
+	loadCtx, cancelLoad := context.WithTimeout(context.Background(), clusterLoadTimeout)
+	err = clusterRegistry.Load(loadCtx, cfg)
+	cancelLoad()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load clusters: %w", err)
+	}
+	if len(cfg.AllowedNamespaces) > 0 {
+		logger.Info("Restricting the BFF to namespaces", slog.Any("namespaces", cfg.AllowedNamespaces))
+		clusterRegistry.RestrictToNamespaces(k8s.NewNamespaceScope(cfg.AllowedNamespaces, k8sLogger))
+	}
 
 	// Initialize BFF client factory for inter-BFF communication
 	var bffFactory bffclient.BFFClientFactory
@@ -130,34 +337,257 @@ func NewApp(cfg config.EnvConfig, logger *slog.Logger) (*App, error) {
 	//       targetCfg.DevOverrideURL = cfg.BFFTargetDevURL
 	//   }
 
-	if cfg.MockBFFClients {
+	if cfg.MockBFFClients || cfg.MockServerFixtures != "" {
 		logger.Info("Using mock BFF client factory")
-		bffFactory = bffmocks.NewMockClientFactory(logger)
+		bffFactory = bffmocks.NewMockClientFactory(bffLogger)
 	} else {
 		logger.Info("Using real BFF client factory")
-		bffFactory = bffclient.NewRealClientFactory(bffConfig, rootCAs, cfg.InsecureSkipVerify, logger)
+		bffFactory = bffclient.NewRealClientFactory(bffConfig, func(target bffclient.BFFTarget) *tls.Config {
+			return outboundTLS.TLSConfig(string(target))
+		}, bffLogger)
 	}
 
 	app := &App{
 		config:                  cfg,
 		logger:                  logger,
-		kubernetesClientFactory: k8sFactory,
+		kubernetesClientFactory: clusterRegistry,
 		repositories:            repositories.NewRepositories(),
 		testEnv:                 testEnv,
-		rootCAs:                 rootCAs,
+		outboundTLS:             outboundTLS,
 		bffClientFactory:        bffFactory,
+		metrics:                 appMetrics,
+		shutdownTracing:         shutdownTracing,
+		rateLimiters:            newRateLimiters(cfg),
+		concurrency:             newConcurrencyLimiter(cfg, appMetrics),
+		resilience:              upstreamPolicy,
+		chaos:                   faults,
+		grpcConns:               grpcclient.NewPool(logging.ForPackage(logger, "grpc"), outboundTLS.TLSConfig),
+		clusters:                clusterRegistry,
+		notifications:           notifications.New(cfg.NotificationHistory),
+	}
+	app.repositories.User.UseAdminGroups(cfg.AdminGroups)
+	if cfg.KubeflowMode {
+		app.repositories.Namespace.UseProfiles()
+	}
+	identities := repositories.NewIdentityCache(cfg.IdentityCacheTTL, cfg.IdentityCacheMaxEntries)
+	if appMetrics != nil {
+		identities.Observe(appMetrics.RecordIdentityLookup)
+	}
+	app.repositories.User.UseIdentityCache(identities)
+	if cfg.WatchMultiplexing {
+		app.watchMux = watchmux.New(watchmux.Options{Logger: logging.ForPackage(logger, "watchmux")})
+		app.repositories.Watch.UseMultiplexer(app.watchMux)
+	}
+	redaction := repositories.RedactionPolicy{ConfigMapKeys: cfg.RedactedConfigMapKeys, RevealVerb: cfg.RevealVerb}
+	app.repositories.Secret.UseRedactionPolicy(redaction)
+	app.repositories.ConfigMap.UseRedactionPolicy(redaction)
+	app.repositories.Preferences.UseNamespace(preferencesNamespace(cfg))
+	app.repositories.ObjectStorage.UseClientConfig(objectstore.Config{
+		TLSConfig:    outboundTLS.TLSConfig(UpstreamObjectStorage),
+		AllowedHosts: cfg.ObjectStorageAllowedHosts,
+		Logger:       logging.ForPackage(logger, "objectstore"),
+		Resilience:   upstreamPolicy,
+	})
+	components, operators, err := clusterHealthComponents(cfg)
+	if err != nil {
+		return nil, err
+	}
+	app.repositories.ClusterHealth.UseComponents(components, operators)
+	if app.messages, err = i18n.Load(cfg.MessageCatalogDir); err != nil {
+		return nil, err
 	}
 
-	app.wsTracker = proxy.NewConnectionTracker(app.logger)
+	if _, err := repositories.ParseServiceSelector(app.serviceLabelSelector(), cfg.ServiceAnnotationSelector); err != nil {
+		return nil, err
+	}
+	app.repositories.Service.UseURLResolvers(serviceURLResolvers(cfg)...)
+
+	if err := app.setupResponseCache(); err != nil {
+		return nil, err
+	}
+	if cfg.ODHDashboardCacheTTL > 0 {
+		app.dashboardCache = cache.New(cache.Options{
+			TTL:    cfg.ODHDashboardCacheTTL,
+			Logger: logging.ForPackage(app.logger, "odhdashboard"),
+		})
+	}
+	if cfg.NamespaceProvisioning {
+		if app.namespaceTemplates, err = provisioning.Load(cfg.NamespaceTemplatesDir); err != nil {
+			return nil, err
+		}
+		logger.Info("Namespace provisioning enabled", slog.Any("templates", app.namespaceTemplates.Names()))
+	}
+
+	app.wsTracker = proxy.NewConnectionTracker(logging.ForPackage(app.logger, "proxy"))
+	app.sessions, err = app.newSessions()
+	if err != nil {
+		return nil, err
+	}
+	app.identityExtractor, err = app.newIdentityExtractor()
+	if err != nil {
+		return nil, err
+	}
+	app.authorizer, err = app.newAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+	app.database, err = app.newDatabase()
+	if err != nil {
+		return nil, err
+	}
+	app.modules, err = app.newModules()
+	if err != nil {
+		return nil, err
+	}
+	app.uiModules, err = app.newUIModules()
+	if err != nil {
+		return nil, err
+	}
+	app.reverseProxy, err = app.newReverseProxy()
+	if err != nil {
+		return nil, err
+	}
+	app.frontendDevServer, err = app.newFrontendDevServer()
+	if err != nil {
+		return nil, err
+	}
+	app.versionedRoutes, app.deprecations, err = app.newVersionedRoutes()
+	if err != nil {
+		return nil, err
+	}
+	app.healthChecks, err = app.newHealthChecks()
+	if err != nil {
+		return nil, err
+	}
+	app.mockServer, err = app.newMockServer()
+	if err != nil {
+		return nil, err
+	}
+	app.webhooks, err = app.newWebhooks()
+	if err != nil {
+		return nil, err
+	}
+	app.operations, err = app.newOperations()
+	if err != nil {
+		return nil, err
+	}
+	if app.operations != nil && app.webhooks != nil {
+		app.operations.OnCompleted(app.dispatchOperationEvent)
+	}
+	app.bodyLog = app.newBodyLogger()
+	app.idempotency, err = app.newIdempotency()
+	if err != nil {
+		return nil, err
+	}
+	app.graphqlSchema, err = app.newGraphQLSchema()
+	if err != nil {
+		return nil, err
+	}
+	app.leader, err = app.newLeaderElector()
+	if err != nil {
+		return nil, err
+	}
+	app.jobs, err = app.newJobs()
+	if err != nil {
+		return nil, err
+	}
+	app.openAPISpec, err = app.newOpenAPISpec()
+	if err != nil {
+		return nil, err
+	}
+	flags, err := newFeatureFlags(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if app.featureFlags, err = featureflags.NewSet(flags); err != nil {
+		return nil, fmt.Errorf("invalid feature flags: %w", err)
+	}
+	app.auditor, err = app.newAuditor()
+	if err != nil {
+		return nil, err
+	}
+	app.panicReporter, err = app.newPanicReporter()
+	if err != nil {
+		return nil, err
+	}
 
 	return app, nil
 }
 
 func (app *App) Shutdown() error {
 	app.logger.Info("shutting down app...")
+	app.CloseStreams()
+	if app.jobs != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := app.jobs.Stop(ctx); err != nil {
+			app.logger.Warn("failed to stop background jobs", "error", err)
+		}
+	}
+	if app.leader != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := app.leader.Stop(ctx); err != nil {
+			app.logger.Warn("failed to leave the leader election", "error", err)
+		}
+	}
+	if app.operations != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := app.operations.Stop(ctx); err != nil {
+			app.logger.Warn("failed to stop the running operations", "error", err)
+		}
+	}
+	app.closeModules()
+	if app.database != nil {
+		if err := app.database.Close(); err != nil {
+			app.logger.Warn("failed to close the database", "error", err)
+		}
+	}
+	if app.grpcConns != nil {
+		if err := app.grpcConns.Close(); err != nil {
+			app.logger.Warn("failed to close gRPC connections", "error", err)
+		}
+	}
 	if app.wsTracker != nil {
 		app.wsTracker.Stop()
 	}
+	if app.watchMux != nil {
+		app.watchMux.Close()
+	}
+	if app.auditor != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := app.auditor.Close(ctx); err != nil {
+			app.logger.Warn("failed to deliver audit entries", "error", err)
+		}
+	}
+	if app.webhooks != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := app.webhooks.Close(ctx); err != nil {
+			app.logger.Warn("failed to deliver webhook events", "error", err)
+		}
+	}
+	if app.panicReporter != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := app.panicReporter.Close(ctx); err != nil {
+			app.logger.Warn("failed to send panic reports", "error", err)
+		}
+	}
+	if closer, ok := app.kubernetesClientFactory.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			app.logger.Warn("failed to close Kubernetes client factory", "error", err)
+		}
+	}
+	if app.shutdownTracing != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := app.shutdownTracing(ctx); err != nil {
+			app.logger.Warn("failed to flush traces", "error", err)
+		}
+	}
 	if app.testEnv == nil {
 		return nil
 	}
@@ -165,16 +595,35 @@ func (app *App) Shutdown() error {
 	return app.testEnv.Stop()
 }
 
-func (app *App) Routes() http.Handler {
-	// Router for /api/v1/*
-	apiRouter := httprouter.New()
+// setupResponseCache creates the response cache when it is enabled and lets the repositories
+// use it. Changes seen by the informer cache invalidate cached responses before their TTL.
+func (app *App) setupResponseCache() error {
+	if app.config.ResponseCacheTTL <= 0 {
+		return nil
+	}
+	app.responseCache = cache.New(cache.Options{
+		TTL:        app.config.ResponseCacheTTL,
+		MaxEntries: app.config.ResponseCacheMaxEntries,
+		Logger:     logging.ForPackage(app.logger, "cache"),
+	})
+	app.repositories.Service.UseCache(app.responseCache)
 
-	apiRouter.NotFound = http.HandlerFunc(app.notFoundResponse)
-	apiRouter.MethodNotAllowed = http.HandlerFunc(app.methodNotAllowedResponse)
+	factory, ok := app.kubernetesClientFactory.(interface{ ResourceCache() *k8s.ResourceCache })
+	if !ok || factory.ResourceCache() == nil {
+		return nil
+	}
+	for _, resource := range app.config.CacheResources {
+		if _, err := factory.ResourceCache().AddEventHandler(resource, app.responseCache.EventHandler(resource)); err != nil {
+			return fmt.Errorf("failed to set up response cache invalidation: %w", err)
+		}
+	}
+	return nil
+}
 
-	// Minimal Kubernetes-backed starter endpoints
-	apiRouter.GET(UserPath, app.UserHandler)
-	apiRouter.GET(NamespacePath, app.GetNamespacesHandler)
+func (app *App) Routes() http.Handler {
+	// Router for /api/v1/*, serving the mock fixtures in mock server mode
+	apiRouter := app.apiRoutes()
+	app.logUnmatchedFixtures(apiRouter)
 
 	// Inter-BFF Communication routes — wire your target BFF endpoints here.
 	// Example:
@@ -187,39 +636,310 @@ func (app *App) Routes() http.Handler {
 	// App Router
 	appMux := http.NewServeMux()
 
-	// handler for api calls
-	appMux.Handle(ApiPathPrefix+"/", apiRouter)
-	appMux.Handle(PathPrefix+ApiPathPrefix+"/", http.StripPrefix(PathPrefix, apiRouter))
-
-	// file server for the frontend file and SPA routes
-	staticDir := http.Dir(app.config.StaticAssetsDir)
-	fileServer := http.FileServer(staticDir)
-	appMux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		ctxLogger := helper.GetContextLoggerFromReq(r)
-		// Check if the requested file exists
-		if _, err := staticDir.Open(r.URL.Path); err == nil {
-			ctxLogger.Debug("Serving static file", slog.String("path", r.URL.Path))
-			// Serve the file if it exists
-			fileServer.ServeHTTP(w, r)
-			return
-		}
-
-		// Fallback to index.html for SPA routes
-		ctxLogger.Debug("Static asset not found, serving index.html", slog.String("path", r.URL.Path))
-		http.ServeFile(w, r, path.Join(app.config.StaticAssetsDir, "index.html"))
-	})
+	// handler for api calls, one mount per API version
+	for _, version := range app.apiVersions() {
+		appMux.Handle(APIVersionPrefix(version)+"/", app.SelectCluster(apiRouter))
+		appMux.Handle(PathPrefix+APIVersionPrefix(version)+"/", http.StripPrefix(PathPrefix, app.SelectCluster(apiRouter)))
+	}
+
+	// Reverse-proxied module APIs (see RegisterProxyRoute); the mux prefers them over apiRouter
+	if app.reverseProxy != nil {
+		for _, prefix := range app.reverseProxy.PathPrefixes() {
+			appMux.Handle(prefix, app.reverseProxy)
+			appMux.Handle(PathPrefix+prefix, http.StripPrefix(PathPrefix, app.reverseProxy))
+		}
+	}
+
+	// Frontend assets, with index.html for the SPA routes, or the frontend dev server
+	appMux.Handle("/", app.frontendHandler())
 
 	// Create a mux for the healthcheck endpoint
 	healthcheckMux := http.NewServeMux()
 	healthcheckRouter := httprouter.New()
 	healthcheckRouter.GET(HealthCheckPath, app.HealthcheckHandler)
-	healthcheckMux.Handle(HealthCheckPath, app.RecoverPanic(app.EnableTelemetry(healthcheckRouter)))
+	healthcheckMux.Handle(HealthCheckPath, app.EnableTelemetry(app.RecoverPanic(healthcheckRouter)))
 
 	// Combines the healthcheck endpoint with the rest of the routes
 	// Apply middleware to appMux which contains the API routes
 	combinedMux := http.NewServeMux()
 	combinedMux.Handle(HealthCheckPath, healthcheckMux)
-	combinedMux.Handle("/", app.RecoverPanic(app.EnableTelemetry(app.EnableCORS(app.InjectRequestIdentity(appMux)))))
+	if app.healthChecks != nil {
+		for path, handler := range app.healthHandlers() {
+			combinedMux.Handle(path, app.EnableTelemetry(app.RecoverPanic(handler)))
+		}
+	}
+	var proxyPrefixes []string
+	if app.reverseProxy != nil {
+		proxyPrefixes = app.reverseProxy.PathPrefixes()
+	}
+	route := app.routePattern(apiRouter.Router, proxyPrefixes...)
+	restrictGuests := func(next http.Handler) http.Handler {
+		return app.RestrictGuests(func(r *http.Request) bool { return apiRouter.guestAllowed(r.Method, route(r)) }, next)
+	}
+	withRoute := func(middleware func(metrics.RouteFunc, http.Handler) http.Handler) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler { return middleware(route, next) }
+	}
+
+	// The middlewares of the API and frontend routes, from the outermost: each one only sees
+	// the requests those above it let through.
+	middlewares := []func(http.Handler) http.Handler{
+		app.EnableTelemetry,                 // first, so every response has a request ID, a log line and metrics
+		app.RecoverPanic,                    // inside EnableTelemetry, so the panics are logged with the request ID
+		app.CompressResponses,               // above the middlewares answering errors, which are compressed too
+		app.SetSecurityHeaders,              // on every response, the rejected requests included
+		withRoute(app.MarkDeprecated),       // on every response of a deprecated route, the rejected ones included
+		app.TrackInFlight,                   // counts every request shutdown waits for, the rejected ones included
+		app.EnableCORS,                      // before the rate limits and CSRF, so preflights are answered as such
+		app.LimitByIP,                       // before authentication, so the clients failing it are throttled too
+		app.ProtectCSRF,                     // before the session, so a forged request never uses it
+		app.LoadSession,                     // the session tokens, for the identity extractor
+		app.InjectRequestIdentity,           // the RequestIdentity of everything below
+		restrictGuests,                      // the routes marked for guests only
+		withRoute(app.Authorize),            // before anything spends resources on a denied request
+		withRoute(app.HonorIdempotencyKeys), // replays the stored responses before they are audited, limited or run
+		withRoute(app.AuditRequests),        // the requests answered below it, with their user
+		withRoute(app.LogBodies),            // the bodies as the limits and handlers see them
+		app.LimitByUser,                     // the rate limit of the user of RequestIdentity
+		withRoute(app.LimitByCost),          // the cost budget of the user, charged only for the rate-allowed requests
+		app.LimitConcurrency,                // holds a slot only for the requests allowed to run
+		withRoute(app.EnforceTimeouts),      // times the handler only, not the wait for a slot
+		withRoute(app.InjectFaults),         // inside EnforceTimeouts, so an injected latency past the timeout answers 504
+		withRoute(app.FilterFields),         // right above the handlers, whose ResponseWriter it wraps
+	}
+	var api http.Handler = appMux
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		api = middlewares[i](api)
+	}
+	combinedMux.Handle("/", api)
+
+	var handler http.Handler = combinedMux
+
+	if app.shutdownTracing != nil {
+		// Outside EnableTelemetry so the request trace_id is the OpenTelemetry trace ID
+		handler = tracing.Middleware(func(r *http.Request) string {
+			if pattern := route(r); pattern != "" {
+				return r.Method + " " + pattern
+			}
+			return r.Method
+		}, handler)
+	}
+
+	if app.metrics != nil {
+		// Metrics are served unauthenticated, like the healthcheck
+		combinedMux.Handle(MetricsPath, app.metrics.Handler())
+		handler = app.metrics.Middleware(route, handler)
+	}
+
+	return handler
+}
+
+// apiRoutes returns the router of the API routes.
+func (app *App) apiRoutes() *apiRouter {
+	apiRouter := app.newAPIRouter()
 
-	return combinedMux
+	// Minimal Kubernetes-backed starter endpoints; polled GETs answer 304 when unchanged. The
+	// guest routes are the read-only ones guests may call in guest mode.
+	apiRouter.guestGET(UserPath, app.ConditionalGET(app.UserHandler))
+	apiRouter.guestGET(PreferencesPath, app.ConditionalGET(app.GetPreferencesHandler))
+	apiRouter.PUT(PreferencesPath, app.PutPreferencesHandler)
+	apiRouter.DELETE(PreferencesPath, app.DeletePreferencesHandler)
+	apiRouter.guestGET(NamespacePath, app.ConditionalGET(app.GetNamespacesHandler))
+	if app.namespaceTemplates != nil && app.operations != nil {
+		apiRouter.POST(NamespacePath, app.ProvisionNamespaceHandler)
+	}
+	apiRouter.guestGET(ClustersPath, app.ConditionalGET(app.GetClustersHandler))
+	apiRouter.guestGET(ClusterHealthPath, app.ConditionalGET(app.GetClusterHealthHandler))
+	apiRouter.guestGET(HardwareProfilesPath, app.ConditionalGET(app.GetHardwareProfilesHandler))
+	apiRouter.guestGET(PermissionsPath, app.ConditionalGET(app.PermissionsHandler))
+	apiRouter.POST(PermissionsBatchPath, app.PermissionsBatchHandler)
+	apiRouter.allowGuests(http.MethodPost, PermissionsBatchPath) // reviews access, changes nothing
+	apiRouter.guestGET(WatchPath, app.WatchHandler)
+	if app.notifications != nil {
+		apiRouter.guestGET(NotificationsPath, app.NotificationsStreamHandler)
+	}
+	if app.operations != nil {
+		apiRouter.guestGET(OperationPath, app.GetOperationHandler)
+	}
+	if app.graphqlSchema != nil {
+		apiRouter.guestGET(GraphQLPath, app.GraphQLHandler)
+		apiRouter.POST(GraphQLPath, app.GraphQLHandler)
+		apiRouter.allowGuests(http.MethodPost, GraphQLPath) // queries only
+	}
+	apiRouter.guestGET(PodLogsPath, app.AttachNamespace(app.PodLogsHandler))
+	apiRouter.guestGET(ServicesPath, app.ConditionalGET(app.AttachNamespace(app.GetServicesHandler)))
+	apiRouter.guestGET(EventsPath, app.ConditionalGET(app.AttachNamespace(app.GetEventsHandler)))
+	apiRouter.guestGET(QuotaPath, app.ConditionalGET(app.AttachNamespace(app.GetQuotaHandler)))
+	apiRouter.guestGET(RoleBindingsPath, app.ConditionalGET(app.AttachNamespace(app.GetRoleBindingsHandler)))
+	apiRouter.POST(RoleBindingsPath, app.AttachNamespace(app.CreateRoleBindingHandler))
+	apiRouter.DELETE(RoleBindingPath, app.AttachNamespace(app.DeleteRoleBindingHandler))
+	if app.config.ServiceAccountTokens {
+		apiRouter.POST(TokenRequestPath, app.AttachNamespace(app.CreateServiceAccountTokenHandler))
+	}
+	apiRouter.GET(SecretsPath, app.ConditionalGET(app.AttachNamespace(app.GetSecretsHandler)))
+	apiRouter.POST(SecretsPath, app.AttachNamespace(app.CreateSecretHandler))
+	apiRouter.GET(SecretPath, app.ConditionalGET(app.AttachNamespace(app.GetSecretHandler)))
+	apiRouter.PUT(SecretPath, app.AttachNamespace(app.UpdateSecretHandler))
+	apiRouter.guestGET(ConfigMapsPath, app.ConditionalGET(app.AttachNamespace(app.GetConfigMapsHandler)))
+	apiRouter.POST(ConfigMapsPath, app.AttachNamespace(app.CreateConfigMapHandler))
+	apiRouter.guestGET(ConfigMapPath, app.ConditionalGET(app.AttachNamespace(app.GetConfigMapHandler)))
+	apiRouter.PUT(ConfigMapPath, app.AttachNamespace(app.UpdateConfigMapHandler))
+	apiRouter.guestGET(BucketsPath, app.AttachNamespace(app.GetBucketsHandler))
+	apiRouter.guestGET(ObjectsPath, app.AttachNamespace(app.GetObjectsHandler))
+	if app.config.ObjectStorageDownloadLimit > 0 {
+		apiRouter.guestGET(ObjectPath, app.AttachNamespace(app.DownloadObjectHandler))
+	}
+	if app.config.ObjectStorageUploadLimit > 0 {
+		apiRouter.PUT(ObjectPath, app.AttachNamespace(app.UploadObjectHandler))
+		apiRouter.POST(ObjectsPath, app.AttachNamespace(app.UploadObjectsHandler))
+	}
+	apiRouter.POST(PresignPath, app.AttachNamespace(app.PresignObjectHandler))
+	apiRouter.guestGET(FeaturesPath, app.ConditionalGET(app.FeaturesHandler))
+	apiRouter.guestGET(ModulesPath, app.ConditionalGET(app.GetUIModulesHandler))
+	apiRouter.guestGET(VersionPath, app.ConditionalGET(app.VersionHandler))
+	apiRouter.PUT(LogLevelPath, app.LogLevelHandler)
+	if app.chaos != nil {
+		// Never mocked, like the diagnostics
+		apiRouter.handleUnmocked(http.MethodGet, ChaosPath, app.GetChaosRulesHandler)
+		apiRouter.handleUnmocked(http.MethodPut, ChaosPath, app.PutChaosRulesHandler)
+		apiRouter.handleUnmocked(http.MethodDelete, ChaosPath, app.DeleteChaosRulesHandler)
+	}
+	if app.debugEndpointsOnAPIPort() {
+		// Diagnostics are never mocked
+		app.addDebugRoutes(apiRouter.handleUnmocked)
+	}
+	if app.sessions != nil {
+		apiRouter.GET(LoginPath, app.LoginHandler)
+		apiRouter.GET(LoginCallbackPath, app.LoginCallbackHandler)
+		apiRouter.POST(LogoutPath, app.LogoutHandler)
+	}
+
+	// Model registry endpoints, acting as the caller on the registry named in the path
+	modelRegistry := func(handler httprouter.Handle) httprouter.Handle {
+		return app.AttachNamespace(app.AttachModelRegistryClient(handler))
+	}
+	apiRouter.guestGET(ModelRegistryListPath, app.ConditionalGET(app.AttachNamespace(app.GetModelRegistriesHandler)))
+	apiRouter.guestGET(RegisteredModelListPath, app.ConditionalGET(modelRegistry(app.GetRegisteredModelsHandler)))
+	apiRouter.POST(RegisteredModelListPath, modelRegistry(app.CreateRegisteredModelHandler))
+	apiRouter.guestGET(RegisteredModelPath, app.ConditionalGET(modelRegistry(app.GetRegisteredModelHandler)))
+	apiRouter.PATCH(RegisteredModelPath, modelRegistry(app.UpdateRegisteredModelHandler))
+	apiRouter.guestGET(RegisteredModelVersionPath, app.ConditionalGET(modelRegistry(app.GetModelVersionsHandler)))
+	apiRouter.POST(RegisteredModelVersionPath, modelRegistry(app.CreateModelVersionHandler))
+	apiRouter.guestGET(ModelVersionPath, app.ConditionalGET(modelRegistry(app.GetModelVersionHandler)))
+	apiRouter.PATCH(ModelVersionPath, modelRegistry(app.UpdateModelVersionHandler))
+	apiRouter.guestGET(ModelVersionArtifactPath, app.ConditionalGET(modelRegistry(app.GetModelArtifactsHandler)))
+	apiRouter.POST(ModelVersionArtifactPath, modelRegistry(app.CreateModelArtifactHandler))
+	apiRouter.guestGET(ModelArtifactPath, app.ConditionalGET(modelRegistry(app.GetModelArtifactHandler)))
+	apiRouter.PATCH(ModelArtifactPath, modelRegistry(app.UpdateModelArtifactHandler))
+
+	// Runtime configuration of the frontend, served without authentication
+	apiRouter.GET(FrontendConfigPath, app.ConditionalGET(app.FrontendConfigHandler))
+
+	// API documentation, served without authentication; Swagger UI only in dev mode
+	apiRouter.GET(OpenAPIPath, app.ConditionalGET(app.OpenAPIHandler))
+	if app.config.DevMode {
+		apiRouter.GET(APIDocsPath, app.apiDocsHandler())
+		apiRouter.GET(APIDocsPath+"/*filepath", app.apiDocsHandler())
+	}
+
+	// Routes registered by downstream code for the API versions, v1 included
+	for _, route := range app.versionedRoutes {
+		apiRouter.Handle(route.Method, route.Pattern(), route.Handler(app))
+		if route.Guests {
+			apiRouter.allowGuests(route.Method, route.Pattern())
+		}
+	}
+
+	return apiRouter
+}
+
+// RouteList returns the routes of Routes as "METHOD pattern", sorted: the API routes, the health
+// and metrics endpoints, and "* prefix" for the reverse-proxied prefixes. The other paths serve
+// the frontend.
+func (app *App) RouteList() []string {
+	router := app.apiRoutes()
+	routes := append(append([]string{}, router.routes...), router.unmocked...)
+	routes = append(routes, mockserver.RouteKey(http.MethodGet, HealthCheckPath))
+	if app.healthChecks != nil {
+		for path := range app.healthHandlers() {
+			routes = append(routes, mockserver.RouteKey(http.MethodGet, path))
+		}
+	}
+	if app.metrics != nil {
+		routes = append(routes, mockserver.RouteKey(http.MethodGet, MetricsPath))
+	}
+	if app.reverseProxy != nil {
+		for _, prefix := range app.reverseProxy.PathPrefixes() {
+			routes = append(routes, mockserver.RouteKey("*", prefix))
+		}
+	}
+	slices.Sort(routes)
+	return slices.Compact(routes)
+}
+
+// kubernetesUpstream names the Kubernetes API server in the circuit breaker and retry metrics.
+const kubernetesUpstream = "kubernetes"
+
+// clusterLoadTimeout bounds reading the kubeconfig Secrets of the clusters at startup.
+const clusterLoadTimeout = 30 * time.Second
+
+// kubernetesUpstreamOf names the API server of cluster like kubernetesUpstream, with the
+// cluster name appended for the other clusters than the local one ("").
+func kubernetesUpstreamOf(cluster string) string {
+	if cluster == "" {
+		return kubernetesUpstream
+	}
+	return kubernetesUpstream + "/" + cluster
+}
+
+// kubernetesSpanName names client spans of Kubernetes API server calls, e.g. "k8s list namespaces".
+func kubernetesSpanName(r *http.Request) string {
+	verb, resource := metrics.KubernetesRequestInfo(r)
+	if resource == "" {
+		return "k8s " + verb + " " + r.URL.Path
+	}
+	return "k8s " + verb + " " + resource
+}
+
+// routePattern labels API requests with the route they matched (e.g. "/api/v1/namespaces/:namespace")
+// instead of the raw path, which keeps the metrics label cardinality bounded.
+// Proxied requests are labelled with their route prefix, e.g. "/api/v1/proxy/model-registry/*".
+func (app *App) routePattern(apiRouter *httprouter.Router, proxyPrefixes ...string) metrics.RouteFunc {
+	return func(r *http.Request) string {
+		switch p := r.URL.Path; {
+		case p == HealthCheckPath, p == MetricsPath, p == LivezPath, p == ReadyzPath, p == HealthzPath:
+			return p
+		case !app.requiresAuth(p):
+			return ""
+		}
+
+		p := strings.TrimPrefix(r.URL.Path, PathPrefix)
+		for _, prefix := range proxyPrefixes {
+			if strings.HasPrefix(p, prefix) {
+				return prefix + "*"
+			}
+		}
+		handle, params, _ := apiRouter.Lookup(r.Method, p)
+		if handle == nil {
+			return ""
+		}
+		// Replace parameter values with their names, e.g. /namespaces/foo -> /namespaces/:namespace.
+		// Params are in path order; a catch-all param swallows the rest of the path.
+		segments := strings.Split(p, "/")
+		next := 0
+		for _, param := range params {
+			if strings.HasPrefix(param.Value, "/") {
+				rest := strings.Count(param.Value, "/")
+				segments = append(segments[:len(segments)-rest], "*"+param.Key)
+				break
+			}
+			for i := next; i < len(segments); i++ {
+				if segments[i] == param.Value {
+					segments[i] = ":" + param.Key
+					next = i + 1
+					break
+				}
+			}
+		}
+		return strings.Join(segments, "/")
+	}
 }
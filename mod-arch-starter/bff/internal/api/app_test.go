@@ -0,0 +1,33 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoutePattern(t *testing.T) {
+	noop := func(http.ResponseWriter, *http.Request, httprouter.Params) {}
+	router := httprouter.New()
+	router.GET(UserPath, noop)
+	router.GET(ApiPathPrefix+"/namespaces/:namespace/models/:name", noop)
+	router.GET(ApiPathPrefix+"/files/*path", noop)
+	route := (&App{}).routePattern(router, ApiPathPrefix+"/proxy/registry/")
+
+	tests := map[string]string{
+		"/api/v1/user":                         "/api/v1/user",
+		"/mod-arch/api/v1/user":                "/api/v1/user",
+		"/api/v1/namespaces/models/models/abc": "/api/v1/namespaces/:namespace/models/:name",
+		"/api/v1/files/a/b/c":                  "/api/v1/files/*path",
+		"/api/v1/unknown":                      "",
+		"/mod-arch/api/v1/proxy/registry/x/y":  "/api/v1/proxy/registry/*",
+		"/static/app.js":                       "",
+		HealthCheckPath:                        HealthCheckPath,
+	}
+	for path, want := range tests {
+		assert.Equal(t, want, route(httptest.NewRequest(http.MethodGet, path, nil)), path)
+	}
+}
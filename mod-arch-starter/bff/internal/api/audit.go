@@ -0,0 +1,221 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/audit"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	helper "github.com/opendatahub-io/mod-arch-library/bff/internal/helpers"
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	logging "github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/metrics"
+	"k8s.io/client-go/kubernetes"
+)
+
+// newAuditor starts the auditor of -audit-sinks; nil when auditing is disabled.
+func (app *App) newAuditor() (*audit.Auditor, error) {
+	cfg := app.config
+	if len(cfg.AuditSinks) == 0 {
+		return nil, nil
+	}
+	logger := logging.ForPackage(app.logger, "audit")
+
+	var sinks []audit.Sink
+	for _, name := range cfg.AuditSinks {
+		switch name {
+		case config.AuditSinkLog:
+			sinks = append(sinks, &audit.LogSink{Logger: logger})
+		case config.AuditSinkEvents:
+			namespace := cfg.AuditEventsNamespace
+			if namespace == "" {
+				namespace = os.Getenv("POD_NAMESPACE")
+			}
+			if namespace == "" {
+				return nil, fmt.Errorf("the events audit sink needs -audit-events-namespace or POD_NAMESPACE")
+			}
+			restConfig, err := helper.GetKubeconfig()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get kubeconfig for the events audit sink: %w", err)
+			}
+			clientset, err := kubernetes.NewForConfig(restConfig)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create Kubernetes client for the events audit sink: %w", err)
+			}
+			sinks = append(sinks, &audit.EventSink{Client: clientset, Namespace: namespace})
+		case config.AuditSinkWebhook:
+			transport := http.DefaultTransport.(*http.Transport).Clone()
+			transport.TLSClientConfig = app.upstreamTLSConfig(UpstreamAuditWebhook)
+			sinks = append(sinks, &audit.WebhookSink{
+				URL:    cfg.AuditWebhookURL,
+				Client: &http.Client{Timeout: 10 * time.Second, Transport: transport},
+			})
+		}
+	}
+	return audit.New(audit.Options{Sinks: sinks, QueueSize: cfg.AuditQueueSize, Logger: logger}), nil
+}
+
+// unauditedRoutes are the POST routes that only read, e.g. batched permission checks.
+var unauditedRoutes = map[string]bool{
+	PermissionsBatchPath: true,
+	GraphQLPath:          true,
+}
+
+// AuditRequests records an audit entry for every mutating request (POST, PUT, PATCH, DELETE)
+// but unauditedRoutes, once it is answered, including the denied and failed ones, and
+// dispatches those that succeeded to the webhooks as resource events. The name of a created
+// object is read from its response. It runs after InjectRequestIdentity; route names the
+// matched route.
+func (app *App) AuditRequests(route metrics.RouteFunc, next http.Handler) http.Handler {
+	if app.auditor == nil && app.webhooks == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verb := audit.Verb(r.Method)
+		pattern := route(r)
+		if verb == "" || unauditedRoutes[pattern] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		recorder := &responseRecorder{statusRecorder: statusRecorder{ResponseWriter: w}}
+		var writer http.ResponseWriter = &recorder.statusRecorder
+		if verb == audit.VerbCreate {
+			writer = recorder
+		}
+		r = r.WithContext(audit.WithAnnotations(r.Context()))
+		next.ServeHTTP(writer, r)
+
+		path := strings.TrimPrefix(r.URL.Path, PathPrefix)
+		resource, name := auditTarget(pattern, path)
+		if name == "" && recorder.status() == http.StatusCreated {
+			name = createdName(recorder)
+		}
+		entry := audit.Entry{
+			Time:        start,
+			Verb:        verb,
+			Method:      r.Method,
+			Path:        path,
+			Route:       pattern,
+			Resource:    resource,
+			Name:        name,
+			Namespace:   r.URL.Query().Get(string(constants.NamespaceHeaderParameterKey)),
+			Status:      recorder.status(),
+			Annotations: audit.AnnotationsFrom(r.Context()),
+			Latency:     time.Since(start),
+		}
+		entry.Outcome = audit.Outcome(entry.Status)
+		entry.RequestID, _ = r.Context().Value(constants.RequestIdKey).(string)
+
+		identity, _ := r.Context().Value(constants.RequestIdentityKey).(*k8s.RequestIdentity)
+		if identity != nil {
+			entry.User = identity.UserID
+			entry.Groups = identity.Groups
+		}
+		if entry.User == "" && identity != nil && identity.Token != "" {
+			// Tokens are resolved by the API server, off the request path
+			go func() {
+				entry.User = app.auditUser(identity)
+				app.auditor.Record(entry)
+				app.dispatchResourceEvent(entry)
+			}()
+			return
+		}
+		app.auditor.Record(entry)
+		app.dispatchResourceEvent(entry)
+	})
+}
+
+// createdName returns the name of the object created by a recorded response, as
+// {"data": {"name": ...}}, or "".
+func createdName(recorder *responseRecorder) string {
+	if recorder.overflow {
+		return ""
+	}
+	var created struct {
+		Data struct {
+			Name string `json:"name"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.body.Bytes(), &created); err != nil {
+		return ""
+	}
+	return created.Data.Name
+}
+
+// auditUser resolves the user of a token identity, or identifies it by the token hash when
+// the API server can't.
+func (app *App) auditUser(identity *k8s.RequestIdentity) string {
+	ctx := context.WithValue(context.Background(), constants.RequestIdentityKey, identity)
+	client, err := app.kubernetesClientFactory.GetClient(ctx)
+	if err == nil {
+		var user string
+		if user, err = client.GetUser(ctx, identity); err == nil && user != "" {
+			return user
+		}
+	}
+	return rateLimitKey(identity)
+}
+
+// auditTarget returns the resource of a route pattern, its last static segment, and the name
+// in path of the parameter following it, e.g. "secrets" and "db" for /api/v1/secrets/:name.
+func auditTarget(pattern, path string) (resource, name string) {
+	if pattern == "" {
+		return "", ""
+	}
+	patternSegments := strings.Split(pattern, "/")
+	pathSegments := strings.Split(path, "/")
+	for i, segment := range patternSegments {
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			if i > 0 && patternSegments[i-1] == resource && i < len(pathSegments) {
+				name = pathSegments[i]
+			}
+		case strings.HasPrefix(segment, "*"):
+			return resource, name
+		case segment != "":
+			resource, name = segment, ""
+		}
+	}
+	return resource, name
+}
+
+// statusRecorder captures the status code of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (w *statusRecorder) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *statusRecorder) status() int {
+	if w.code == 0 {
+		return http.StatusOK
+	}
+	return w.code
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/audit"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditRequests(t *testing.T) {
+	app := newWatchTestApp(t)
+	var mu sync.Mutex
+	var entries []audit.Entry
+	app.auditor = audit.New(audit.Options{Sinks: []audit.Sink{audit.SinkFunc(func(_ context.Context, entry audit.Entry) error {
+		mu.Lock()
+		defer mu.Unlock()
+		entries = append(entries, entry)
+		return nil
+	})}, Logger: app.logger})
+	routes := app.Routes()
+	serve := func(method, path, body string) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(constants.KubeflowUserIDHeader, "doraNonAdmin@example.com")
+		req.Header.Set(constants.RequestIDHeader, "req-"+method)
+		routes.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	serve(http.MethodGet, SecretsPath+"?namespace=dora-namespace", "")
+	serve(http.MethodPost, PermissionsBatchPath, `{"data":[{"verb":"get","resource":"pods"}]}`)
+	serve(http.MethodPost, SecretsPath+"?namespace=dora-namespace", `{"data":{"name":"db","data":{}}}`)
+	serve(http.MethodPut, SecretsPath+"/db?namespace=bella-namespace", `{"data":{"data":{}}}`)
+	require.NoError(t, app.auditor.Close(context.Background()))
+
+	require.Len(t, entries, 2, "reads and permission checks are not audited")
+	created := entries[0]
+	assert.Equal(t, "doraNonAdmin@example.com", created.User)
+	assert.Equal(t, audit.VerbCreate, created.Verb)
+	assert.Equal(t, "secrets", created.Resource)
+	assert.Equal(t, "dora-namespace", created.Namespace)
+	assert.Equal(t, "db", created.Name, "read from the response")
+	assert.Equal(t, http.StatusCreated, created.Status)
+	assert.Equal(t, audit.OutcomeSuccess, created.Outcome)
+	assert.Equal(t, "req-POST", created.RequestID)
+	assert.Equal(t, SecretsPath, created.Route)
+
+	denied := entries[1]
+	assert.Equal(t, audit.VerbUpdate, denied.Verb)
+	assert.Equal(t, "db", denied.Name)
+	assert.Equal(t, http.StatusForbidden, denied.Status)
+	assert.Equal(t, audit.OutcomeDenied, denied.Outcome)
+}
+
+func TestAuditTarget(t *testing.T) {
+	for _, tc := range []struct{ pattern, path, resource, name string }{
+		{SecretPath, "/api/v1/secrets/db", "secrets", "db"},
+		{SecretsPath, "/api/v1/secrets", "secrets", ""},
+		{PreferencesPath, "/api/v1/user/preferences", "preferences", ""},
+		{RegisteredModelPath, "/api/v1/model_registry/mr/registered_models/7", "registered_models", "7"},
+		{RegisteredModelListPath, "/api/v1/model_registry/mr/registered_models", "registered_models", ""},
+		{"/api/v1/proxy/pipelines/*", "/api/v1/proxy/pipelines/runs/1", "pipelines", ""},
+		{"", "/unknown", "", ""},
+	} {
+		resource, name := auditTarget(tc.pattern, tc.path)
+		assert.Equal(t, tc.resource, resource, tc.pattern)
+		assert.Equal(t, tc.name, name, tc.pattern)
+	}
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/authz"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/metrics"
+)
+
+// AuthorizerFactory builds an Authorizer once the App exists, so it can use app dependencies
+// such as the Kubernetes client factory.
+type AuthorizerFactory func(app *App) (authz.Authorizer, error)
+
+var (
+	authorizerMu        sync.RWMutex
+	authorizerFactories []AuthorizerFactory
+)
+
+// RegisterAuthorizer adds an authorizer of the API requests, asked after the built-in ones of
+// -authorizers: a request is served when every authorizer allows it. This should be called from
+// an init() function in the downstream code.
+//
+// Example usage in downstream code:
+//
+//	func init() {
+//	    api.RegisterAuthorizer(func(app *api.App) (authz.Authorizer, error) {
+//	        return authz.Func(func(ctx context.Context, req authz.Request) (authz.Decision, error) {
+//	            if req.Namespace != "" && !strings.HasPrefix(req.Namespace, "team-") {
+//	                return authz.Deny("only the team-* namespaces are served"), nil
+//	            }
+//	            return authz.Allow(), nil
+//	        }), nil
+//	    })
+//	}
+func RegisterAuthorizer(factory AuthorizerFactory) { //nolint:unused
+	authorizerMu.Lock()
+	defer authorizerMu.Unlock()
+	authorizerFactories = append(authorizerFactories, factory)
+}
+
+// newAuthorizer chains the authorizers of -authorizers and the registered ones; nil when there
+// are none.
+func (app *App) newAuthorizer() (authz.Authorizer, error) {
+	var authorizers []authz.Authorizer
+	for _, name := range app.config.Authorizers {
+		switch name {
+		case config.AuthorizerSAR:
+			authorizers = append(authorizers, authz.SubjectAccessReview{Group: app.config.AuthzGroup, Client: app.kubernetesClientFactory.GetClient})
+		case config.AuthorizerPolicy:
+			policy, err := authz.LoadFile(app.config.AuthzPolicyFile)
+			if err != nil {
+				return nil, err
+			}
+			if app.authzPolicy, err = authz.NewPolicyAuthorizer(policy); err != nil {
+				return nil, err
+			}
+			authorizers = append(authorizers, app.authzPolicy)
+		case config.AuthorizerOPA:
+			transport := http.DefaultTransport.(*http.Transport).Clone()
+			transport.TLSClientConfig = app.upstreamTLSConfig(UpstreamOPA)
+			authorizers = append(authorizers, authz.OPA{URL: app.config.AuthzOPAURL, Client: &http.Client{Timeout: authz.DefaultOPATimeout, Transport: transport}})
+		default:
+			return nil, fmt.Errorf("authorizers: unknown authorizer %q", name)
+		}
+	}
+
+	authorizerMu.RLock()
+	factories := authorizerFactories
+	authorizerMu.RUnlock()
+	for _, factory := range factories {
+		authorizer, err := factory(app)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build registered authorizer: %w", err)
+		}
+		authorizers = append(authorizers, authorizer)
+	}
+
+	if len(authorizers) == 0 {
+		return nil, nil
+	}
+	app.logger.Info("authorizing API requests", "authorizers", app.config.Authorizers, "registered", len(factories))
+	return authz.Chain(authorizers...), nil
+}
+
+// WatchAuthorizationPolicy reloads the rules of the policy authorizer whenever
+// -authz-policy-file changes, until ctx is done. Invalid files are logged and the current rules
+// kept.
+func (app *App) WatchAuthorizationPolicy(ctx context.Context) error {
+	path := app.config.AuthzPolicyFile
+	if path == "" || app.authzPolicy == nil {
+		return nil
+	}
+	return config.WatchFile(ctx, path, app.logger, func() {
+		policy, err := authz.LoadFile(path)
+		if err == nil {
+			err = app.authzPolicy.Replace(policy)
+		}
+		if err != nil {
+			app.logger.Error("failed to reload the authorization policy, keeping the current one", "path", path, "error", err)
+			return
+		}
+		app.logger.Info("authorization policy reloaded", "path", path, "rules", len(policy.Rules))
+	})
+}
+
+// Authorize asks the authorizer whether the API request, whose identity InjectRequestIdentity
+// resolved, may be served: denied requests get a 403, and the requests the authorizer fails to
+// decide a 500.
+func (app *App) Authorize(route metrics.RouteFunc, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if app.authorizer == nil || !app.requiresAuth(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		req := authz.Request{Method: r.Method, Path: strings.TrimPrefix(r.URL.Path, PathPrefix)}
+		req.Identity, _ = r.Context().Value(constants.RequestIdentityKey).(*k8s.RequestIdentity)
+		if req.Route = route(r); req.Route == "" {
+			req.Route = req.Path
+		}
+		req.Namespace = routeNamespace(req.Route, r)
+
+		decision, err := app.authorizer.Authorize(r.Context(), req)
+		if err != nil {
+			app.serverErrorResponse(w, r, fmt.Errorf("failed to authorize the request: %w", err))
+			return
+		}
+		if !decision.Allowed {
+			app.requestLogger(r).Info("request denied by the authorization policy", "route", req.Route, "namespace", req.Namespace, "reason", decision.Reason)
+			app.forbiddenResponse(w, r, "denied by the authorization policy")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// routeNamespace returns the namespace of r, which matched the route pattern: its :namespace
+// path parameter, or else its namespace query parameter.
+func routeNamespace(route string, r *http.Request) string {
+	if namespace := requestNamespaceParameter(route, strings.TrimPrefix(r.URL.Path, PathPrefix)); namespace != "" {
+		return namespace
+	}
+	return r.URL.Query().Get(string(constants.NamespaceHeaderParameterKey))
+}
+
+// requestNamespaceParameter returns the segment of path at the :namespace parameter of the
+// route pattern, or "".
+func requestNamespaceParameter(route, path string) string {
+	param := ":" + string(constants.NamespaceHeaderParameterKey)
+	routeSegments, pathSegments := strings.Split(route, "/"), strings.Split(path, "/")
+	for i, segment := range routeSegments {
+		if segment == param && i < len(pathSegments) {
+			return pathSegments[i]
+		}
+	}
+	return ""
+}
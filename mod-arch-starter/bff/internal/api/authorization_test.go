@@ -0,0 +1,86 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/authz"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorize(t *testing.T) {
+	app := newWatchTestApp(t)
+	var asked []authz.Request
+	app.authorizer = authz.Func(func(_ context.Context, req authz.Request) (authz.Decision, error) {
+		asked = append(asked, req)
+		switch req.Namespace {
+		case "bella-namespace":
+			return authz.Deny("bella-namespace is frozen"), nil
+		case "broken":
+			return authz.Decision{}, errors.New("policy agent unreachable")
+		}
+		return authz.Allow(), nil
+	})
+
+	var body string
+	serve := func(target string) int {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set(constants.KubeflowUserIDHeader, "user@example.com")
+		rr := httptest.NewRecorder()
+		app.Routes().ServeHTTP(rr, req)
+		body = rr.Body.String()
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("/api/v1/namespaces"))
+	require.Len(t, asked, 1)
+	assert.Equal(t, "user@example.com", asked[0].Identity.UserID)
+	assert.Equal(t, "/api/v1/namespaces", asked[0].Route)
+	assert.Empty(t, asked[0].Namespace)
+
+	assert.Equal(t, http.StatusForbidden, serve("/api/v1/services?namespace=bella-namespace"))
+	assert.NotContains(t, body, "frozen", "the reason is only logged")
+	assert.Equal(t, http.StatusInternalServerError, serve("/api/v1/services?namespace=broken"))
+	assert.Equal(t, "/api/v1/services", asked[2].Route)
+
+	// Public routes are not authorized
+	asked = nil
+	assert.Equal(t, http.StatusOK, serve(HealthCheckPath))
+	assert.Empty(t, asked)
+}
+
+func TestRequestNamespaceParameter(t *testing.T) {
+	assert.Equal(t, "team-a", requestNamespaceParameter("/api/v1/namespaces/:namespace/secrets", "/api/v1/namespaces/team-a/secrets"))
+	assert.Empty(t, requestNamespaceParameter("/api/v1/secrets", "/api/v1/secrets"))
+}
+
+func TestNewAuthorizer(t *testing.T) {
+	app := newWatchTestApp(t)
+	authorizer, err := app.newAuthorizer()
+	require.NoError(t, err)
+	assert.Nil(t, authorizer, "no authorizer by default")
+
+	app.config.Authorizers = []string{config.AuthorizerPolicy}
+	app.config.AuthzPolicyFile = "missing.yaml"
+	_, err = app.newAuthorizer()
+	assert.ErrorContains(t, err, "authorization policy")
+
+	app.config.Authorizers = []string{config.AuthorizerSAR}
+	app.config.AuthzGroup = config.DefaultAuthzGroup
+	authorizer, err = app.newAuthorizer()
+	require.NoError(t, err)
+	app.authorizer = authorizer
+
+	// The in-memory mock grants non-admins their namespaces only
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/namespaces", nil)
+	req.Header.Set(constants.KubeflowUserIDHeader, "doraNonAdmin@example.com")
+	rr := httptest.NewRecorder()
+	app.Routes().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+}
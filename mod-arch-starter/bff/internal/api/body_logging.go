@@ -0,0 +1,295 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	helper "github.com/opendatahub-io/mod-arch-library/bff/internal/helpers"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/metrics"
+)
+
+// redactedValue replaces the redacted values of logged bodies and headers.
+const redactedValue = "[REDACTED]"
+
+// bodyLogger holds the settings of LogBodies. The routes and sample rate are reloadable, so
+// bodies can be logged during an incident without a restart.
+type bodyLogger struct {
+	settings atomic.Pointer[bodyLogSettings]
+	maxSize  int
+	// fields are the lower-cased patterns of -body-log-redacted-fields
+	fields      []string
+	tokenHeader string
+}
+
+type bodyLogSettings struct {
+	routes     map[string]bool
+	sampleRate float64
+}
+
+// newBodyLogger returns the body logger of the configuration, following its reloads.
+func (app *App) newBodyLogger() *bodyLogger {
+	l := &bodyLogger{maxSize: app.config.BodyLogMaxSize, tokenHeader: app.config.AuthTokenHeader}
+	for _, pattern := range app.config.BodyLogRedactedFields {
+		l.fields = append(l.fields, strings.ToLower(pattern))
+	}
+	l.configure(app.config)
+	app.OnConfigChange(func(old, updated config.EnvConfig) {
+		if !slices.Equal(old.BodyLogRoutes, updated.BodyLogRoutes) || old.BodyLogSampleRate != updated.BodyLogSampleRate {
+			l.configure(updated)
+		}
+	})
+	return l
+}
+
+func (l *bodyLogger) configure(cfg config.EnvConfig) {
+	// Validated with the configuration
+	routes, _ := config.ParseBodyLogRoutes(cfg.BodyLogRoutes)
+	l.settings.Store(&bodyLogSettings{routes: routes, sampleRate: cfg.BodyLogSampleRate})
+}
+
+// sampled reports whether the bodies of a request of the route pattern are logged.
+func (s *bodyLogSettings) sampled(method, pattern string) bool {
+	if len(s.routes) == 0 || pattern == "" {
+		return false
+	}
+	if !s.routes[config.BodyLogAllRoutes] && !s.routes[pattern] && !s.routes[method+" "+pattern] {
+		return false
+	}
+	return rand.Float64() < s.sampleRate
+}
+
+// LogBodies logs the request and response of a sample of the requests of -body-log-routes,
+// with their headers and bodies, once they are answered; route names the matched route. The
+// values of the credential headers, of the JSON fields of -body-log-redacted-fields and of the
+// data of Secrets are redacted, and bodies are truncated to -body-log-max-size. Only JSON and
+// text bodies are logged; the others are described by their type and size. The bodies are
+// captured as the handler reads and writes them, so streams and uploads are not buffered.
+func (app *App) LogBodies(route metrics.RouteFunc, next http.Handler) http.Handler {
+	if app.bodyLog == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pattern := route(r)
+		if !app.bodyLog.settings.Load().sampled(r.Method, pattern) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		request := &bodyCapture{max: app.bodyLog.maxSize}
+		if r.Body != nil && r.Body != http.NoBody {
+			r.Body = &capturingBody{ReadCloser: r.Body, capture: request}
+		}
+		recorder := &bodyRecorder{statusRecorder: statusRecorder{ResponseWriter: w}, capture: &bodyCapture{max: app.bodyLog.maxSize}}
+		next.ServeHTTP(recorder, r)
+
+		// Secrets are read and written as the data of their envelope
+		secret := strings.HasPrefix(pattern, SecretsPath)
+		logger.FromContext(r.Context()).Info("HTTP exchange",
+			slog.String("method", r.Method),
+			slog.String("route", pattern),
+			slog.String("url", r.URL.String()),
+			slog.Int("status", recorder.status()),
+			slog.Duration("duration", time.Since(start)),
+			slog.Group("request",
+				slog.Any("headers", app.bodyLog.headers(r.Header)),
+				slog.Any("body", app.bodyLog.body(request, r.Header, secret))),
+			slog.Group("response",
+				slog.Any("headers", app.bodyLog.headers(recorder.Header())),
+				slog.Any("body", app.bodyLog.body(recorder.capture, recorder.Header(), secret))),
+		)
+	})
+}
+
+// headers returns header to log, with the credential headers redacted.
+func (l *bodyLogger) headers(header http.Header) slog.LogValuer {
+	if l.tokenHeader != "" && header.Get(l.tokenHeader) != "" {
+		header = header.Clone()
+		header.Set(l.tokenHeader, redactedValue)
+	}
+	return helper.HeaderLogValuer{Header: header}
+}
+
+// body returns the captured body to log, the Content-Type and Content-Encoding of header
+// telling how to read it; the Secret data of its envelope is redacted when secret is set.
+func (l *bodyLogger) body(c *bodyCapture, header http.Header, secret bool) slog.Value {
+	attrs := []slog.Attr{slog.Int64("size", c.size)}
+	if c.size == 0 {
+		return slog.GroupValue(attrs...)
+	}
+	truncated := c.size > int64(c.buf.Len())
+	if truncated {
+		attrs = append(attrs, slog.Bool("truncated", true))
+	}
+
+	contentType := header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	encoded := header.Get("Content-Encoding") != "" && !strings.EqualFold(header.Get("Content-Encoding"), "identity")
+	switch {
+	case encoded:
+		attrs = append(attrs, slog.String("encoding", header.Get("Content-Encoding")))
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		attrs = append(attrs, slog.String("content", l.redactJSON(c.buf.Bytes(), secret)))
+	case strings.HasPrefix(mediaType, "text/") && !secret:
+		attrs = append(attrs, slog.String("content", strings.ToValidUTF8(c.buf.String(), "�")))
+	default:
+		attrs = append(attrs, slog.String("type", contentType))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// redacted reports whether the value of the JSON field key is redacted.
+func (l *bodyLogger) redacted(key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range l.fields {
+		if matched, _ := path.Match(pattern, key); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// redactJSON re-encodes the JSON document data, possibly truncated, with the values of the
+// redacted fields replaced; when secret is set, so are the data and stringData fields of the
+// objects below the root, i.e. of the Secrets of an envelope. A truncated or invalid document
+// ends where its last complete token does.
+func (l *bodyLogger) redactJSON(data []byte, secret bool) string {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	type frame struct {
+		object bool
+		// items counts the keys and values written in the frame
+		items int
+	}
+	var (
+		out   bytes.Buffer
+		stack []frame
+		// skip is the nesting of the redacted composite value being skipped, 0 when none
+		skip int
+	)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if !errors.Is(err, io.EOF) || len(stack) > 0 {
+				out.WriteString("…")
+			}
+			return out.String()
+		}
+
+		if skip > 0 {
+			if delim, ok := token.(json.Delim); ok {
+				if delim == '{' || delim == '[' {
+					skip++
+				} else {
+					skip--
+				}
+			}
+			continue
+		}
+
+		var top *frame
+		if len(stack) > 0 {
+			top = &stack[len(stack)-1]
+		}
+		if delim, ok := token.(json.Delim); ok && (delim == '}' || delim == ']') {
+			out.WriteRune(rune(delim))
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		if top != nil && top.items > 0 {
+			if top.object && top.items%2 == 1 {
+				out.WriteByte(':')
+			} else {
+				out.WriteByte(',')
+			}
+		}
+
+		if top != nil && top.object && top.items%2 == 0 {
+			// A key: redact its value, or skip it when it is an object or array
+			key := token.(string)
+			encoded, _ := json.Marshal(key)
+			out.Write(encoded)
+			top.items++
+			if l.redacted(key) || secret && len(stack) > 1 && (key == "data" || key == "stringData") {
+				value, err := decoder.Token()
+				if err != nil {
+					out.WriteString("…")
+					return out.String()
+				}
+				if delim, ok := value.(json.Delim); ok && (delim == '{' || delim == '[') {
+					skip = 1
+				}
+				out.WriteString(`:"` + redactedValue + `"`)
+				top.items++
+			}
+			continue
+		}
+
+		if top != nil {
+			top.items++
+		}
+		switch token := token.(type) {
+		case json.Delim:
+			out.WriteRune(rune(token))
+			stack = append(stack, frame{object: token == '{'})
+		case nil:
+			out.WriteString("null")
+		default:
+			encoded, _ := json.Marshal(token)
+			out.Write(encoded)
+		}
+	}
+}
+
+// bodyCapture keeps the first max bytes written to it, counting all of them.
+type bodyCapture struct {
+	buf  bytes.Buffer
+	size int64
+	max  int
+}
+
+func (c *bodyCapture) Write(b []byte) {
+	c.size += int64(len(b))
+	if room := c.max - c.buf.Len(); room > 0 {
+		c.buf.Write(b[:min(room, len(b))])
+	}
+}
+
+// capturingBody captures a request body as the handler reads it.
+type capturingBody struct {
+	io.ReadCloser
+	capture *bodyCapture
+}
+
+func (b *capturingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.capture.Write(p[:n])
+	return n, err
+}
+
+// bodyRecorder captures the status and body of a response as the handler writes them.
+type bodyRecorder struct {
+	statusRecorder
+	capture *bodyCapture
+}
+
+func (w *bodyRecorder) Write(b []byte) (int, error) {
+	n, err := w.statusRecorder.Write(b)
+	w.capture.Write(b[:n])
+	return n, err
+}
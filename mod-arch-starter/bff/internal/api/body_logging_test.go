@@ -0,0 +1,86 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogBodies(t *testing.T) {
+	app := newWatchTestApp(t)
+	var logs bytes.Buffer
+	app.logger = slog.New(slog.NewJSONHandler(&logs, nil))
+	app.config.BodyLogRoutes = []string{"POST " + SecretsPath, PermissionsBatchPath}
+	app.config.BodyLogSampleRate = 1
+	app.config.BodyLogMaxSize = 1024
+	app.config.BodyLogRedactedFields = config.DefaultBodyLogRedactedFields
+	app.config.AuthTokenHeader = "X-Forwarded-Access-Token"
+	app.bodyLog = app.newBodyLogger()
+	routes := app.Routes()
+	serve := func(method, path, body string) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(constants.KubeflowUserIDHeader, "doraNonAdmin@example.com")
+		req.Header.Set("X-Forwarded-Access-Token", "sha256~token")
+		routes.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	serve(http.MethodPost, SecretsPath+"?namespace=dora-namespace", `{"data":{"name":"db","data":{"password":"czNjcjN0"}}}`)
+	serve(http.MethodGet, SecretsPath+"?namespace=dora-namespace", "")
+	serve(http.MethodPost, PermissionsBatchPath, `{"data":[{"verb":"get","resource":"pods"}]}`)
+
+	var entries []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		if entry["msg"] == "HTTP exchange" {
+			entries = append(entries, entry)
+		}
+	}
+	require.Len(t, entries, 2, "only the routes of -body-log-routes are logged")
+	assert.NotContains(t, logs.String(), "czNjcjN0")
+	assert.NotContains(t, logs.String(), "sha256~token")
+
+	created := entries[0]
+	assert.Equal(t, SecretsPath, created["route"])
+	assert.Equal(t, float64(http.StatusCreated), created["status"])
+	request := created["request"].(map[string]any)
+	assert.Equal(t, `{"data":{"name":"db","data":"[REDACTED]"}}`, request["body"].(map[string]any)["content"])
+	assert.Equal(t, "[REDACTED]", request["headers"].(map[string]any)["X-Forwarded-Access-Token"])
+	response := created["response"].(map[string]any)["body"].(map[string]any)
+	assert.Contains(t, response["content"], `"name":"db"`)
+	assert.Contains(t, response["content"], `"data":"[REDACTED]"`)
+
+	checked := entries[1]["response"].(map[string]any)["body"].(map[string]any)
+	assert.Contains(t, checked["content"], `"allowed":`)
+}
+
+func TestBodyLogger_RedactJSON(t *testing.T) {
+	l := &bodyLogger{fields: []string{"*password*", "*token*"}}
+	for _, tc := range []struct {
+		name, in, out string
+		secret        bool
+	}{
+		{"fields", `{"user": "dora", "Password": "hunter2", "nested": [{"accessToken": {"a": [1]}, "n": 1.5}]}`,
+			`{"user":"dora","Password":"[REDACTED]","nested":[{"accessToken":"[REDACTED]","n":1.5}]}`, false},
+		{"secret data", `{"data": [{"name": "db", "data": {"user": "ZG9yYQ=="}, "stringData": null}]}`,
+			`{"data":[{"name":"db","data":"[REDACTED]","stringData":"[REDACTED]"}]}`, true},
+		{"secret envelope", `{"data": {"name": "db"}}`, `{"data":{"name":"db"}}`, true},
+		{"truncated", `{"items": [true, null, "pass`, `{"items":[true,null…`, false},
+		{"truncated redacted", `{"token": {"value": "abc`, `{"token":"[REDACTED]"…`, false},
+		{"not JSON", `[1, oops]`, `[1…`, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.out, l.redactJSON([]byte(tc.in), tc.secret))
+		})
+	}
+}
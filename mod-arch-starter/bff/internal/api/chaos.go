@@ -0,0 +1,43 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/metrics"
+)
+
+// InjectFaults applies the fault injection rules of the routes (see -chaos-enabled) to the
+// API requests: it waits the latency of the matching rule, then drops the connection or
+// answers with the injected error. ChaosPath itself is exempt, so the faults can always be
+// removed. It runs inside EnforceTimeouts: a latency past the timeout of the route answers 504.
+func (app *App) InjectFaults(route metrics.RouteFunc, next http.Handler) http.Handler {
+	if app.chaos == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pattern := route(r)
+		if pattern == ChaosPath {
+			next.ServeHTTP(w, r)
+			return
+		}
+		fault := app.chaos.RouteFault(pattern)
+		if !fault.Wait(r.Context().Done()) {
+			// The client went away, or EnforceTimeouts answers
+			return
+		}
+		switch {
+		case fault.Drop:
+			// net/http closes the connection without a response
+			panic(http.ErrAbortHandler)
+		case fault.Status != 0:
+			app.errorResponse(w, r, &HTTPError{StatusCode: fault.Status, Error: ErrorPayload{
+				Code:    strconv.Itoa(fault.Status),
+				Message: fmt.Sprintf("fault injected into %s %s", r.Method, pattern),
+			}})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
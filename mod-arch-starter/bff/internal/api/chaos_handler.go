@@ -0,0 +1,98 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/chaos"
+	logging "github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/validation"
+)
+
+type ChaosRulesEnvelope Envelope[[]models.ChaosRule, None]
+
+// GetChaosRulesHandler returns the fault injection rules. Only cluster admins may call it.
+func (app *App) GetChaosRulesHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	if !app.requireClusterAdmin(w, r, "read the fault injection rules") {
+		return
+	}
+	app.writeChaosRules(w, r)
+}
+
+// PutChaosRulesHandler replaces the fault injection rules; an empty list stops the faults.
+// Only cluster admins may call it. The rules last until the BFF restarts.
+func (app *App) PutChaosRulesHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	if !app.requireClusterAdmin(w, r, "change the fault injection rules") {
+		return
+	}
+	var body ChaosRulesEnvelope
+	if err := app.ReadValidJSON(w, r, &body); err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	rules := make([]chaos.Rule, 0, len(body.Data))
+	for i, data := range body.Data {
+		rule := chaos.Rule{
+			Route:       data.Route,
+			Upstream:    data.Upstream,
+			ErrorRate:   data.ErrorRate,
+			ErrorStatus: data.ErrorStatus,
+			DropRate:    data.DropRate,
+		}
+		if data.Latency != "" {
+			latency, err := time.ParseDuration(data.Latency)
+			if err != nil {
+				app.apiErrorResponse(w, r, validation.Invalid(fmt.Sprintf("data[%d].latency", i), "must be a duration, like 1.5s"))
+				return
+			}
+			rule.Latency = latency
+		}
+		if err := rule.Validate(); err != nil {
+			app.apiErrorResponse(w, r, validation.Invalid(fmt.Sprintf("data[%d]", i), err.Error()))
+			return
+		}
+		rules = append(rules, rule)
+	}
+	if err := app.chaos.SetRules(rules); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	logging.FromRequest(r).Warn("fault injection rules changed", "rules", len(rules))
+	app.writeChaosRules(w, r)
+}
+
+// DeleteChaosRulesHandler removes the fault injection rules. Only cluster admins may call it.
+func (app *App) DeleteChaosRulesHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	if !app.requireClusterAdmin(w, r, "change the fault injection rules") {
+		return
+	}
+	if err := app.chaos.SetRules(nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	logging.FromRequest(r).Warn("fault injection rules removed")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *App) writeChaosRules(w http.ResponseWriter, r *http.Request) {
+	rules := []models.ChaosRule{}
+	for _, rule := range app.chaos.Rules() {
+		data := models.ChaosRule{
+			Route:       rule.Route,
+			Upstream:    rule.Upstream,
+			ErrorRate:   rule.ErrorRate,
+			ErrorStatus: rule.ErrorStatus,
+			DropRate:    rule.DropRate,
+		}
+		if rule.Latency > 0 {
+			data.Latency = rule.Latency.String()
+		}
+		rules = append(rules, data)
+	}
+	if err := app.WriteJSON(w, http.StatusOK, ChaosRulesEnvelope{Data: rules}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/chaos"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChaosRulesHandlers(t *testing.T) {
+	app := newWatchTestApp(t)
+	app.chaos = chaos.New()
+	routes := app.Routes()
+	serve := func(method, path, user, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(constants.KubeflowUserIDHeader, user)
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, req)
+		return rr
+	}
+	rules := `{"data": [
+		{"route": "/api/v1/namespaces", "errorRate": 1, "errorStatus": 502},
+		{"route": "/api/v1/user", "dropRate": 1},
+		{"upstream": "kubernetes", "latency": "250ms"},
+		{"route": "*", "errorRate": 1}
+	]}`
+
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodPut, ChaosPath, "doraNonAdmin@example.com", rules).Code)
+	rr := serve(http.MethodPut, ChaosPath, "user@example.com", rules)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var envelope ChaosRulesEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &envelope))
+	require.Len(t, envelope.Data, 4)
+	assert.Equal(t, "250ms", envelope.Data[2].Latency)
+
+	rr = serve(http.MethodGet, NamespacePath, "doraNonAdmin@example.com", "")
+	assert.Equal(t, http.StatusBadGateway, rr.Code)
+	assert.Contains(t, rr.Body.String(), "fault injected into GET /api/v1/namespaces")
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		serve(http.MethodGet, UserPath, "doraNonAdmin@example.com", "")
+	}, "dropped connection")
+	rr = serve(http.MethodGet, ChaosPath, "user@example.com", "")
+	assert.Equal(t, http.StatusOK, rr.Code, "the rules can always be read and removed")
+
+	rr = serve(http.MethodPut, ChaosPath, "user@example.com", `{"data": [{"route": "*", "latency": "soon"}]}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "data[0].latency")
+	rr = serve(http.MethodPut, ChaosPath, "user@example.com", `{"data": [{"route": "*", "errorRate": 2}]}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "data[0].errorRate")
+	rr = serve(http.MethodPut, ChaosPath, "user@example.com", `{"data": [{"errorRate": 1}]}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "either route or upstream is required")
+	assert.Len(t, app.chaos.Rules(), 4, "invalid rules change nothing")
+
+	assert.Equal(t, http.StatusNoContent, serve(http.MethodDelete, ChaosPath, "user@example.com", "").Code)
+	assert.Empty(t, app.chaos.Rules())
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, NamespacePath, "doraNonAdmin@example.com", "").Code)
+}
+
+func TestChaosRulesHandlers_Disabled(t *testing.T) {
+	app := newWatchTestApp(t)
+	req := httptest.NewRequest(http.MethodGet, ChaosPath, nil)
+	req.Header.Set(constants.KubeflowUserIDHeader, "user@example.com")
+	rr := httptest.NewRecorder()
+	app.Routes().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/repositories"
+)
+
+type ClusterHealthEnvelope Envelope[models.ClusterHealthModel, None]
+
+// GetClusterHealthHandler summarizes the health of the cluster (of the cluster query
+// parameter) for the system status banner of the UI. An unhealthy cluster is still a 200: the
+// status is in the document.
+func (app *App) GetClusterHealthHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+
+	ctx := r.Context()
+	identity, ok := ctx.Value(constants.RequestIdentityKey).(*kubernetes.RequestIdentity)
+	if !ok || identity == nil {
+		app.badRequestResponse(w, r, fmt.Errorf("missing RequestIdentity in context"))
+		return
+	}
+
+	client, err := app.kubernetesClientFactory.GetClient(ctx)
+	if err != nil {
+		app.serverErrorResponse(w, r, fmt.Errorf("failed to get Kubernetes client: %w", err))
+		return
+	}
+	var checker kubernetes.APIServerChecker
+	if factory, err := app.clusterRegistry().Factory(kubernetes.ClusterFromContext(ctx)); err == nil {
+		checker, _ = factory.(kubernetes.APIServerChecker)
+	}
+
+	health, err := app.repositories.ClusterHealth.GetClusterHealth(client, ctx, checker)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.WriteJSON(w, http.StatusOK, ClusterHealthEnvelope{Data: health}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// clusterHealthComponents converts the -health-components and -health-operators of cfg for
+// ClusterHealthRepository.UseComponents.
+func clusterHealthComponents(cfg config.EnvConfig) ([]repositories.ClusterComponent, []repositories.OperatorResource, error) {
+	componentRefs, err := cfg.ParseHealthComponents()
+	if err != nil {
+		return nil, nil, err
+	}
+	operatorRefs, err := cfg.ParseHealthOperators()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	components := make([]repositories.ClusterComponent, len(componentRefs))
+	for i, ref := range componentRefs {
+		components[i] = repositories.ClusterComponent{Name: ref.Name, Namespace: ref.Namespace, Selector: ref.Selector}
+	}
+	operators := make([]repositories.OperatorResource, len(operatorRefs))
+	for i, ref := range operatorRefs {
+		operators[i] = repositories.OperatorResource{Name: ref.Name, Resource: ref.Resource, Namespace: ref.Namespace, ObjectName: ref.ObjectName}
+	}
+	return components, operators, nil
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetClusterHealthHandler(t *testing.T) {
+	app := newWatchTestApp(t)
+	components, operators, err := clusterHealthComponents(config.EnvConfig{
+		HealthComponents: []string{"pipelines=odh/app=ds-pipelines"},
+		HealthOperators:  []string{"odh=datascienceclusters.v1.datasciencecluster.opendatahub.io/default-dsc"},
+	})
+	require.NoError(t, err)
+	app.repositories.ClusterHealth.UseComponents(components, operators)
+
+	req := httptest.NewRequest(http.MethodGet, ClusterHealthPath, nil)
+	req.Header.Set(constants.KubeflowUserIDHeader, "doraNonAdmin@example.com")
+	rr := httptest.NewRecorder()
+	app.Routes().ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var envelope ClusterHealthEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &envelope))
+	assert.Equal(t, models.HealthStatusDegraded, envelope.Data.Status)
+	assert.True(t, envelope.Data.APIServer.Reachable)
+	require.Len(t, envelope.Data.Components, 1)
+	assert.Equal(t, models.HealthStatusUnavailable, envelope.Data.Components[0].Status)
+	require.Len(t, envelope.Data.Operators, 1)
+	assert.Equal(t, models.HealthStatusUnavailable, envelope.Data.Operators[0].Status)
+
+	// Without components nor operators, the API server alone is reported
+	app.repositories.ClusterHealth.UseComponents(nil, nil)
+	rr = httptest.NewRecorder()
+	app.Routes().ServeHTTP(rr, req)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &envelope))
+	assert.Equal(t, models.HealthStatusHealthy, envelope.Data.Status)
+	assert.Empty(t, envelope.Data.Components)
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/apierrors"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+)
+
+type ClustersEnvelope Envelope[[]models.ClusterModel, None]
+
+// GetClustersHandler lists the clusters API requests can target, local first, with whether
+// their API server is reachable.
+func (app *App) GetClustersHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+
+	ctx := r.Context()
+	identity, ok := ctx.Value(constants.RequestIdentityKey).(*kubernetes.RequestIdentity)
+	if !ok || identity == nil {
+		app.badRequestResponse(w, r, fmt.Errorf("missing RequestIdentity in context"))
+		return
+	}
+
+	statuses := app.clusterRegistry().Status(ctx)
+	clusters := make([]models.ClusterModel, len(statuses))
+	for i, status := range statuses {
+		clusters[i] = models.ClusterModel{Name: status.Name, Local: status.Local, Reachable: status.Reachable}
+	}
+
+	if err := app.WriteJSON(w, http.StatusOK, ClustersEnvelope{Data: clusters}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// SelectCluster targets the Kubernetes clients of API requests at the cluster of their cluster
+// query parameter, the local one by default. Unknown clusters are answered 404.
+func (app *App) SelectCluster(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get(constants.ClusterQueryParameter)
+		clusters := app.clusterRegistry()
+		if name == "" || name == clusters.LocalName() {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !clusters.Has(name) {
+			app.apiErrorResponse(w, r, apierrors.NotFound(fmt.Sprintf("cluster %q not found", name)))
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(kubernetes.WithCluster(r.Context(), name)))
+	})
+}
+
+// clusterRegistry returns the cluster registry of the app, or a registry of its client
+// factory alone for apps built around a factory, e.g. in tests.
+func (app *App) clusterRegistry() *kubernetes.ClusterRegistry {
+	if app.clusters != nil {
+		return app.clusters
+	}
+	return kubernetes.NewClusterRegistry(app.kubernetesClientFactory, app.config.ClusterName, app.logger)
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes/k8mocks"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetClustersHandler(t *testing.T) {
+	app := newWatchTestApp(t)
+	spoke, err := k8mocks.NewInMemoryKubernetesClientFactory(app.config, app.logger)
+	require.NoError(t, err)
+	app.clusters = kubernetes.NewClusterRegistry(app.kubernetesClientFactory, "hub", app.logger)
+	require.NoError(t, app.clusters.Register("spoke", spoke))
+	app.kubernetesClientFactory = app.clusters
+	routes := app.Routes()
+	get := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set(constants.KubeflowUserIDHeader, "doraNonAdmin@example.com")
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get(ClustersPath)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var envelope ClustersEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &envelope))
+	assert.Equal(t, []models.ClusterModel{
+		{Name: "hub", Local: true, Reachable: true},
+		{Name: "spoke", Reachable: true},
+	}, envelope.Data)
+
+	assert.Equal(t, http.StatusOK, get(NamespacePath+"?cluster=spoke").Code)
+	assert.Equal(t, http.StatusOK, get(NamespacePath+"?cluster=hub").Code)
+	rr = get(NamespacePath + "?cluster=other")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), `cluster \"other\" not found`)
+}
+
+func TestGetClustersHandler_LocalOnly(t *testing.T) {
+	app := newWatchTestApp(t)
+	req := httptest.NewRequest(http.MethodGet, ClustersPath, nil)
+	req.Header.Set(constants.KubeflowUserIDHeader, "doraNonAdmin@example.com")
+	rr := httptest.NewRecorder()
+	app.Routes().ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var envelope ClustersEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &envelope))
+	assert.Equal(t, []models.ClusterModel{{Name: "local", Local: true, Reachable: true}}, envelope.Data)
+}
@@ -0,0 +1,273 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Content codings of CompressResponses, by order of preference.
+const (
+	encodingZstd = "zstd"
+	encodingGzip = "gzip"
+)
+
+var (
+	gzipWriters = sync.Pool{New: func() any { return gzip.NewWriter(io.Discard) }}
+	zstdWriters = sync.Pool{New: func() any {
+		// One goroutine per encoder: responses are small and compressed concurrently
+		encoder, _ := zstd.NewWriter(io.Discard, zstd.WithEncoderLevel(zstd.SpeedDefault), zstd.WithEncoderConcurrency(1), zstd.WithLowerEncoderMem(true))
+		return encoder
+	}}
+)
+
+// CompressResponses compresses the responses with zstd or gzip, as negotiated from the
+// Accept-Encoding of the request, when their Content-Type matches -compression-content-types
+// and their body reaches -compression-min-size. It never buffers streams: WebSocket upgrades,
+// Server-Sent Events and responses already encoded (precompressed frontend assets, proxied
+// upstream responses) are passed through, and a response flushed before reaching the minimum
+// size is sent uncompressed. Compressed responses get a weak ETag, as their bytes differ from
+// the representation the ETag was computed on.
+func (app *App) CompressResponses(next http.Handler) http.Handler {
+	if !app.config.Compression {
+		return next
+	}
+	contentTypes := app.config.CompressionContentTypes
+	minSize := app.config.CompressionMinSize
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead || r.Header.Get("Upgrade") != "" || r.Header.Get("Range") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding, contentTypes: contentTypes, minSize: minSize}
+		next.ServeHTTP(cw, r)
+		// Not deferred: after a panic RecoverPanic replaces the buffered response
+		cw.close()
+	})
+}
+
+// negotiateEncoding returns the preferred coding of acceptEncoding among zstd and gzip, or ""
+// when the client accepts neither. Equal qualities prefer zstd.
+func negotiateEncoding(acceptEncoding string) string {
+	qualities := map[string]float64{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		quality := 1.0
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			var err error
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		qualities[name] = quality
+	}
+	best, bestQuality := "", 0.0
+	for _, encoding := range []string{encodingZstd, encodingGzip} {
+		quality, ok := qualities[encoding]
+		if !ok {
+			quality, ok = qualities["*"]
+		}
+		if ok && quality > bestQuality {
+			best, bestQuality = encoding, quality
+		}
+	}
+	return best
+}
+
+// compressibleType reports whether the media type of contentType matches one of patterns,
+// such as "application/json" or "text/*". Event streams are never compressed.
+func compressibleType(contentType string, patterns []string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType == "text/event-stream" {
+		return false
+	}
+	for _, pattern := range patterns {
+		if prefix, found := strings.CutSuffix(pattern, "/*"); found {
+			if strings.HasPrefix(mediaType, strings.ToLower(prefix)+"/") {
+				return true
+			}
+		} else if strings.EqualFold(mediaType, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// compressWriter buffers the beginning of a response until it knows whether to compress it.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding     string
+	contentTypes []string
+	minSize      int
+
+	code        int
+	buf         bytes.Buffer
+	decided     bool
+	encoder     io.WriteCloser // nil when passing the response through
+	wroteHeader bool
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if code < http.StatusOK {
+		// Informational responses, e.g. 103 Early Hints, precede the final one
+		if !w.wroteHeader {
+			w.ResponseWriter.WriteHeader(code)
+		}
+		return
+	}
+	if w.code != 0 {
+		return
+	}
+	w.code = code
+	if !bodyAllowed(code) || !w.eligible() {
+		w.passThrough()
+		return
+	}
+	if length, err := strconv.Atoi(w.Header().Get("Content-Length")); err == nil {
+		if length < w.minSize {
+			w.passThrough()
+		} else {
+			w.compress()
+		}
+	}
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if !w.decided {
+		if w.code == 0 {
+			if w.Header().Get("Content-Type") == "" {
+				// Sniffed now as the server could not sniff the compressed body
+				w.Header().Set("Content-Type", http.DetectContentType(b))
+			}
+			w.WriteHeader(http.StatusOK)
+		}
+		if !w.decided {
+			w.buf.Write(b)
+			if w.buf.Len() >= w.minSize {
+				w.compress()
+			}
+			return len(b), nil
+		}
+	}
+	if w.encoder != nil {
+		return w.encoder.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush sends the response written so far: uncompressed if it is still below the minimum
+// size, as flushing means the handler is streaming.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		if w.code == 0 {
+			w.WriteHeader(http.StatusOK)
+		}
+		if !w.decided {
+			w.passThrough()
+		}
+	}
+	if flusher, ok := w.encoder.(interface{ Flush() error }); ok {
+		_ = flusher.Flush()
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// eligible reports whether the headers of the response allow compressing it.
+func (w *compressWriter) eligible() bool {
+	header := w.Header()
+	return header.Get("Content-Encoding") == "" && header.Get("Content-Range") == "" &&
+		compressibleType(header.Get("Content-Type"), w.contentTypes)
+}
+
+// passThrough sends the response as written by the handler.
+func (w *compressWriter) passThrough() {
+	w.decided = true
+	w.writeHeader()
+	if w.buf.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.buf.Bytes())
+		w.buf.Reset()
+	}
+}
+
+// compress sends the response encoded, starting with the buffered bytes.
+func (w *compressWriter) compress() {
+	w.decided = true
+	header := w.Header()
+	header.Del("Content-Length")
+	header.Set("Content-Encoding", w.encoding)
+	header.Add("Vary", "Accept-Encoding")
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		header.Set("ETag", "W/"+etag)
+	}
+	w.writeHeader()
+
+	switch w.encoding {
+	case encodingZstd:
+		encoder := zstdWriters.Get().(*zstd.Encoder)
+		encoder.Reset(w.ResponseWriter)
+		w.encoder = encoder
+	default:
+		encoder := gzipWriters.Get().(*gzip.Writer)
+		encoder.Reset(w.ResponseWriter)
+		w.encoder = encoder
+	}
+	if w.buf.Len() > 0 {
+		_, _ = w.encoder.Write(w.buf.Bytes())
+		w.buf.Reset()
+	}
+}
+
+func (w *compressWriter) writeHeader() {
+	if !w.wroteHeader && w.code != 0 {
+		w.wroteHeader = true
+		w.ResponseWriter.WriteHeader(w.code)
+	}
+}
+
+// close ends the response once the handler returns.
+func (w *compressWriter) close() {
+	if !w.decided {
+		if w.code == 0 && w.buf.Len() == 0 {
+			// Nothing written: the server sends its own 200
+			return
+		}
+		w.passThrough()
+	}
+	if w.encoder == nil {
+		return
+	}
+	_ = w.encoder.Close()
+	switch encoder := w.encoder.(type) {
+	case *zstd.Encoder:
+		encoder.Reset(io.Discard)
+		zstdWriters.Put(encoder)
+	case *gzip.Writer:
+		encoder.Reset(io.Discard)
+		gzipWriters.Put(encoder)
+	}
+	w.encoder = nil
+}
+
+// bodyAllowed reports whether a response with status code has a body.
+func bodyAllowed(code int) bool {
+	return code != http.StatusNoContent && code != http.StatusNotModified
+}
@@ -0,0 +1,209 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCompressionTestApp(t *testing.T) *App {
+	app := newWatchTestApp(t)
+	defaults := config.DefaultEnvConfig()
+	app.config.Compression = true
+	app.config.CompressionMinSize = defaults.CompressionMinSize
+	app.config.CompressionContentTypes = defaults.CompressionContentTypes
+	return app
+}
+
+// decompress returns the body of rr decoded with its Content-Encoding.
+func decompress(t *testing.T, rr *httptest.ResponseRecorder) string {
+	t.Helper()
+	var reader io.Reader = rr.Body
+	switch rr.Header().Get("Content-Encoding") {
+	case "gzip":
+		gz, err := gzip.NewReader(rr.Body)
+		require.NoError(t, err)
+		reader = gz
+	case "zstd":
+		decoder, err := zstd.NewReader(rr.Body)
+		require.NoError(t, err)
+		defer decoder.Close()
+		reader = decoder
+	}
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestCompressResponses(t *testing.T) {
+	body := `{"data":"` + strings.Repeat("a", 4096) + `"}`
+	handler := newCompressionTestApp(t).CompressResponses(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.Header().Set("ETag", `"abc"`)
+		_, _ = io.WriteString(w, body)
+	}))
+
+	tests := []struct {
+		acceptEncoding string
+		want           string
+	}{
+		{"gzip, deflate, br, zstd", "zstd"},
+		{"gzip", "gzip"},
+		{"zstd;q=0.5, gzip", "gzip"},
+		{"*", "zstd"},
+		{"gzip;q=0, br", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.acceptEncoding, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/items", nil)
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, tt.want, rr.Header().Get("Content-Encoding"))
+			assert.Equal(t, body, decompress(t, rr))
+			if tt.want == "" {
+				assert.Equal(t, `"abc"`, rr.Header().Get("ETag"))
+				assert.Equal(t, strconv.Itoa(len(body)), rr.Header().Get("Content-Length"))
+				return
+			}
+			assert.Less(t, rr.Body.Len(), len(body))
+			assert.Equal(t, `W/"abc"`, rr.Header().Get("ETag"))
+			assert.Empty(t, rr.Header().Get("Content-Length"))
+			assert.Equal(t, "Accept-Encoding", rr.Header().Get("Vary"))
+		})
+	}
+}
+
+func TestCompressResponses_Skipped(t *testing.T) {
+	large := strings.Repeat("a", 4096)
+	tests := []struct {
+		name    string
+		method  string
+		header  http.Header
+		handler http.HandlerFunc
+	}{
+		{"below the minimum size", http.MethodGet, nil, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{"data":"small"}`)
+		}},
+		{"content type not listed", http.MethodGet, nil, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = io.WriteString(w, large)
+		}},
+		{"already encoded", http.MethodGet, nil, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/javascript")
+			w.Header().Set("Content-Encoding", "br")
+			_, _ = io.WriteString(w, large)
+		}},
+		{"event stream", http.MethodGet, nil, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = io.WriteString(w, large)
+		}},
+		{"websocket upgrade", http.MethodGet, http.Header{"Upgrade": {"websocket"}}, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = io.WriteString(w, large)
+		}},
+		{"range", http.MethodGet, http.Header{"Range": {"bytes=0-99"}}, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = io.WriteString(w, large)
+		}},
+		{"not modified", http.MethodGet, nil, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotModified)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newCompressionTestApp(t).CompressResponses(tt.handler)
+			req := httptest.NewRequest(tt.method, "/", nil)
+			for key, values := range tt.header {
+				req.Header[key] = values
+			}
+			req.Header.Set("Accept-Encoding", "gzip, zstd")
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.NotContains(t, []string{"gzip", "zstd"}, rr.Header().Get("Content-Encoding"))
+			assert.Empty(t, rr.Header().Get("Vary"))
+		})
+	}
+}
+
+func TestCompressResponses_Streaming(t *testing.T) {
+	app := newCompressionTestApp(t)
+
+	// Flushed below the minimum size: streamed uncompressed
+	flushed := make(chan string, 1)
+	handler := app.CompressResponses(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = io.WriteString(w, "line 1\n")
+		require.NoError(t, http.NewResponseController(w).Flush())
+		flushed <- w.(interface{ Unwrap() http.ResponseWriter }).Unwrap().(*httptest.ResponseRecorder).Body.String()
+		_, _ = io.WriteString(w, strings.Repeat("line\n", 1000))
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, "line 1\n", <-flushed)
+	assert.Empty(t, rr.Header().Get("Content-Encoding"))
+	assert.True(t, rr.Flushed)
+
+	// Flushed once compressing: the encoder is flushed too
+	body := strings.Repeat("line\n", 1000)
+	handler = app.CompressResponses(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = io.WriteString(w, body)
+		require.NoError(t, http.NewResponseController(w).Flush())
+		recorder := w.(interface{ Unwrap() http.ResponseWriter }).Unwrap().(*httptest.ResponseRecorder)
+		assert.Positive(t, recorder.Body.Len(), "the compressed bytes are sent on flush")
+		_, _ = io.WriteString(w, body)
+	}))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, "gzip", rr.Header().Get("Content-Encoding"))
+	assert.Equal(t, body+body, decompress(t, rr))
+}
+
+func TestCompressResponses_ConditionalGET(t *testing.T) {
+	app := newCompressionTestApp(t)
+	app.config.CompressionMinSize = 0
+	routes := app.Routes()
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, UserPath, nil)
+		req.Header.Set(constants.KubeflowUserIDHeader, "user@example.com")
+		req.Header.Set("Accept-Encoding", "gzip")
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get("")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "gzip", rr.Header().Get("Content-Encoding"))
+	assert.Contains(t, decompress(t, rr), "user@example.com")
+	etag := rr.Header().Get("ETag")
+	require.True(t, strings.HasPrefix(etag, `W/"`), etag)
+
+	rr = get(etag)
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+	assert.Empty(t, rr.Body.String())
+}
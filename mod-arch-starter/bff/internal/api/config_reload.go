@@ -0,0 +1,34 @@
+package api
+
+import (
+	"sync"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+)
+
+// configSubscribers is notified of runtime configuration changes, see OnConfigChange.
+type configSubscribers struct {
+	mu  sync.RWMutex
+	fns []func(old, updated config.EnvConfig)
+}
+
+// OnConfigChange registers fn to be called when reloadable settings (fields tagged reload:"true",
+// such as the log level) change at runtime. Config() keeps returning the startup configuration.
+// Downstream extensions can call it from their handler and route factories.
+func (app *App) OnConfigChange(fn func(old, updated config.EnvConfig)) { //nolint:unused
+	app.configSubscribers.mu.Lock()
+	defer app.configSubscribers.mu.Unlock()
+	app.configSubscribers.fns = append(app.configSubscribers.fns, fn)
+}
+
+// ApplyConfigChange notifies the OnConfigChange subscribers. Subscribe it to the
+// config.Reloader following the configuration file.
+func (app *App) ApplyConfigChange(old, updated config.EnvConfig) {
+	app.configSubscribers.mu.RLock()
+	fns := append([]func(old, updated config.EnvConfig){}, app.configSubscribers.fns...)
+	app.configSubscribers.mu.RUnlock()
+
+	for _, fn := range fns {
+		fn(old, updated)
+	}
+}
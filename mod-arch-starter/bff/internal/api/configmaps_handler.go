@@ -0,0 +1,165 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/pagination"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/validation"
+)
+
+type ConfigMapsEnvelope PagedResponse[models.ConfigMapModel]
+type ConfigMapEnvelope Envelope[*models.ConfigMapModel, None]
+type ConfigMapDryRunEnvelope Envelope[*models.ConfigMapModel, *DryRunMetadata]
+
+var configMapFields = pagination.Fields[models.ConfigMapModel]{
+	"name":      func(configMap models.ConfigMapModel) string { return configMap.Name },
+	"managedBy": func(configMap models.ConfigMapModel) string { return configMap.ManagedBy },
+}
+
+// GetConfigMapsHandler lists the ConfigMaps of the namespace (AttachNamespace), with the
+// values of -redacted-configmap-keys redacted. With managed=true only the ConfigMaps created
+// through the BFF are listed. The list parameters of pagination.ParseListOptions page the result.
+func (app *App) GetConfigMapsHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	managed, err := boolQuery(r, "managed")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	opts, err := pagination.ParseListOptions(r.URL.Query())
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	identity, namespace, client, ok := app.namespacedRequest(w, r)
+	if !ok {
+		return
+	}
+
+	configMaps, err := app.repositories.ConfigMap.List(client, r.Context(), identity, namespace, managed)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+
+	page, metadata, err := pagination.Apply(configMaps, opts, configMapFields)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.WriteJSON(w, http.StatusOK, ConfigMapsEnvelope{Data: page, Metadata: metadata}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// GetConfigMapHandler returns the ConfigMap named in the path, redacted unless reveal=true and
+// the user passes the reveal check.
+func (app *App) GetConfigMapHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	reveal, err := boolQuery(r, "reveal")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	identity, namespace, client, ok := app.namespacedRequest(w, r)
+	if !ok {
+		return
+	}
+
+	configMap, err := app.repositories.ConfigMap.Get(client, r.Context(), identity, namespace, ps.ByName("name"), reveal)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if err := app.WriteJSON(w, http.StatusOK, ConfigMapEnvelope{Data: &configMap}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// CreateConfigMapHandler creates a ConfigMap in the namespace, managed by the BFF. With dryRun=true
+// nothing is created, and the changes are returned in the metadata.
+func (app *App) CreateConfigMapHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	r, dryRun, ok := app.dryRunRequest(w, r)
+	if !ok {
+		return
+	}
+	var body ConfigMapEnvelope
+	if err := app.ReadValidJSON(w, r, &body); err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if body.Data == nil || body.Data.Name == "" {
+		app.apiErrorResponse(w, r, validation.Invalid("data.name", "is required"))
+		return
+	}
+	identity, namespace, client, ok := app.namespacedRequest(w, r)
+	if !ok {
+		return
+	}
+
+	created, err := app.repositories.ConfigMap.Create(client, r.Context(), identity, namespace, *body.Data)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if dryRun != nil {
+		if err := app.WriteJSON(w, http.StatusOK, ConfigMapDryRunEnvelope{Data: &created, Metadata: newDryRunMetadata(dryRun)}, nil); err != nil {
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	if err := app.WriteJSON(w, http.StatusCreated, ConfigMapEnvelope{Data: &created}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// UpdateConfigMapHandler applies the data of the ConfigMap named in the path, when it is
+// managed by the BFF, like UpdateSecretHandler: force=true takes over the keys other field
+// managers own. Keys left in redactedKeys keep their value. dryRun=true previews the update.
+func (app *App) UpdateConfigMapHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	r, dryRun, ok := app.dryRunRequest(w, r)
+	if !ok {
+		return
+	}
+	force, err := boolQuery(r, "force")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	var body ConfigMapEnvelope
+	if err := app.ReadValidJSON(w, r, &body); err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if body.Data == nil {
+		app.apiErrorResponse(w, r, validation.Invalid("data", "is required"))
+		return
+	}
+	name := ps.ByName("name")
+	if body.Data.Name != "" && body.Data.Name != name {
+		app.apiErrorResponse(w, r, validation.Invalid("data.name", fmt.Sprintf("%q does not match the path", body.Data.Name)))
+		return
+	}
+	body.Data.Name = name
+	identity, namespace, client, ok := app.namespacedRequest(w, r)
+	if !ok {
+		return
+	}
+
+	updated, err := app.repositories.ConfigMap.Update(client, r.Context(), identity, namespace, *body.Data, force)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if dryRun != nil {
+		if err := app.WriteJSON(w, http.StatusOK, ConfigMapDryRunEnvelope{Data: &updated, Metadata: newDryRunMetadata(dryRun)}, nil); err != nil {
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	if err := app.WriteJSON(w, http.StatusOK, ConfigMapEnvelope{Data: &updated}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/contracttest"
+	"github.com/stretchr/testify/require"
+)
+
+// TestContracts checks the responses of the starter routes with the golden files of
+// testdata/contracts and the OpenAPI document. Run it with UPDATE_CONTRACTS=true to record
+// intended changes.
+func TestContracts(t *testing.T) {
+	app := newWatchTestApp(t)
+	spec, err := app.OpenAPISpec()
+	require.NoError(t, err)
+	const dora = "doraNonAdmin@example.com"
+	asDora := http.Header{constants.KubeflowUserIDHeader: {dora}}
+
+	contracttest.Suite{
+		Handler: app.Routes(),
+		Spec:    spec,
+		Header:  http.Header{constants.KubeflowUserIDHeader: {"user@example.com"}},
+	}.Run(t,
+		contracttest.Case{Name: "get-user", Method: http.MethodGet, Path: UserPath},
+		contracttest.Case{Name: "get-user-non-admin", Method: http.MethodGet, Path: UserPath, Header: asDora},
+		contracttest.Case{Name: "list-namespaces", Method: http.MethodGet, Path: NamespacePath},
+		contracttest.Case{Name: "list-namespaces-page", Method: http.MethodGet, Path: NamespacePath + "?pageSize=1&sortBy=name"},
+		contracttest.Case{Name: "list-namespaces-non-admin", Method: http.MethodGet, Path: NamespacePath, Header: asDora},
+		contracttest.Case{Name: "list-secrets-forbidden", Method: http.MethodGet, Path: SecretsPath + "?namespace=kubeflow", Header: asDora, Status: http.StatusForbidden},
+		contracttest.Case{Name: "list-secrets-invalid-namespace", Method: http.MethodGet, Path: SecretsPath + "?namespace=Kubeflow", Status: http.StatusBadRequest},
+		contracttest.Case{Name: "check-permission", Method: http.MethodGet, Path: PermissionsPath + "?verb=list&resource=pods&namespace=dora-namespace", Header: asDora},
+	)
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/rs/cors"
+)
+
+// CORSPolicy is the CORS policy of a group of routes.
+type CORSPolicy struct {
+	// AllowedOrigins lists the origins allowed to call the routes, "*" for any origin, or
+	// patterns with one wildcard such as "https://*.apps.example.com". Empty disables CORS.
+	AllowedOrigins []string
+	AllowedMethods []string
+	AllowedHeaders []string
+	ExposedHeaders []string
+	// AllowCredentials lets browsers send cookies and read responses of credentialed requests.
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight response; zero uses their default.
+	MaxAge time.Duration
+}
+
+// CORSPolicyFactory derives the policy of a group of routes from the configured policy.
+type CORSPolicyFactory func(base CORSPolicy) CORSPolicy
+
+var (
+	corsPolicyMu sync.RWMutex
+	corsPolicies = map[string]CORSPolicyFactory{
+		// EventSource reconnects send Last-Event-ID, and streams are only read
+		ApiPathPrefix + "/watch/": func(base CORSPolicy) CORSPolicy {
+			base.AllowedMethods = []string{http.MethodGet}
+			base.AllowedHeaders = append(base.AllowedHeaders, "Last-Event-ID")
+			return base
+		},
+	}
+)
+
+// RegisterCORSPolicy sets the CORS policy of the API routes under pathPrefix, derived from
+// the configured policy (ALLOWED_ORIGINS and CORS_*). Requests use the policy of the longest
+// matching prefix. This should be called from an init() function in the downstream code.
+//
+// Example usage in downstream code:
+//
+//	func init() {
+//	    // Let any origin read the public catalog, without credentials
+//	    api.RegisterCORSPolicy(api.ApiPathPrefix+"/catalog/", func(base api.CORSPolicy) api.CORSPolicy {
+//	        base.AllowedOrigins = []string{"*"}
+//	        base.AllowedMethods = []string{http.MethodGet}
+//	        base.AllowCredentials = false
+//	        return base
+//	    })
+//	}
+func RegisterCORSPolicy(pathPrefix string, factory CORSPolicyFactory) { //nolint:unused
+	corsPolicyMu.Lock()
+	defer corsPolicyMu.Unlock()
+	corsPolicies[pathPrefix] = factory
+}
+
+// corsPolicy returns the configured CORS policy, with the headers the BFF reads and sets.
+func (app *App) corsPolicy() CORSPolicy {
+	allowedHeaders := []string{constants.KubeflowUserIDHeader, constants.KubeflowUserGroupsIdHeader, constants.TraceParentHeader, constants.TraceStateHeader, constants.RequestIDHeader, constants.IdempotencyKeyHeader, "If-None-Match"}
+	exposedHeaders := []string{constants.RequestIDHeader, constants.IdempotentReplayedHeader, "ETag"}
+	if app.config.KubeflowMode {
+		for _, header := range []string{app.config.UserIDHeader, app.config.GroupsHeader} {
+			if !slices.Contains(allowedHeaders, header) {
+				allowedHeaders = append(allowedHeaders, header)
+			}
+		}
+	}
+	if app.config.CSRFEnabled {
+		allowedHeaders = append(allowedHeaders, app.config.CSRFHeader)
+		exposedHeaders = append(exposedHeaders, app.config.CSRFHeader)
+	}
+	if app.config.CostBudget > 0 {
+		exposedHeaders = append(exposedHeaders, rateLimitLimitHeader, rateLimitRemainingHeader, rateLimitResetHeader, rateLimitPolicyHeader)
+	}
+
+	methods := app.config.CORSAllowedMethods
+	if len(methods) == 0 {
+		methods = config.DefaultCORSAllowedMethods
+	}
+
+	return CORSPolicy{
+		AllowedOrigins:   app.config.AllowedOrigins,
+		AllowedMethods:   methods,
+		AllowedHeaders:   append(allowedHeaders, app.config.CORSAllowedHeaders...),
+		ExposedHeaders:   append(exposedHeaders, app.config.CORSExposedHeaders...),
+		AllowCredentials: app.config.CORSAllowCredentials,
+		MaxAge:           app.config.CORSMaxAge,
+	}
+}
+
+// EnableCORS answers CORS preflight requests and sets the CORS headers of responses with the
+// policy of the request path (see RegisterCORSPolicy). Routes whose policy allows no origin
+// get no CORS headers; by default ALLOWED_ORIGINS is empty and CORS is disabled.
+func (app *App) EnableCORS(next http.Handler) http.Handler {
+	base := app.corsPolicy()
+
+	corsPolicyMu.RLock()
+	prefixes := make([]string, 0, len(corsPolicies))
+	handlers := map[string]http.Handler{}
+	for prefix, factory := range corsPolicies {
+		// Copy the slices so factories can append to them
+		derived := base
+		derived.AllowedOrigins = append([]string(nil), base.AllowedOrigins...)
+		derived.AllowedMethods = append([]string(nil), base.AllowedMethods...)
+		derived.AllowedHeaders = append([]string(nil), base.AllowedHeaders...)
+		derived.ExposedHeaders = append([]string(nil), base.ExposedHeaders...)
+		prefixes = append(prefixes, prefix)
+		handlers[prefix] = app.corsHandler(factory(derived), next)
+	}
+	corsPolicyMu.RUnlock()
+
+	defaultHandler := app.corsHandler(base, next)
+	if len(prefixes) == 0 {
+		return defaultHandler
+	}
+	// Longest prefix first
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, PathPrefix)
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(path, prefix) {
+				handlers[prefix].ServeHTTP(w, r)
+				return
+			}
+		}
+		defaultHandler.ServeHTTP(w, r)
+	})
+}
+
+func (app *App) corsHandler(policy CORSPolicy, next http.Handler) http.Handler {
+	if len(policy.AllowedOrigins) == 0 {
+		// CORS is disabled, this middleware becomes a noop.
+		return next
+	}
+
+	c := cors.New(cors.Options{
+		AllowedOrigins:     policy.AllowedOrigins,
+		AllowCredentials:   policy.AllowCredentials,
+		AllowedMethods:     policy.AllowedMethods,
+		AllowedHeaders:     policy.AllowedHeaders,
+		ExposedHeaders:     policy.ExposedHeaders,
+		MaxAge:             int(policy.MaxAge / time.Second),
+		Debug:              app.config.LogLevel == slog.LevelDebug,
+		OptionsPassthrough: false,
+	})
+
+	return c.Handler(next)
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnableCORS(t *testing.T) {
+	app := newWatchTestApp(t)
+	app.config.AllowedOrigins = []string{"https://*.apps.example.com"}
+	app.config.CORSAllowCredentials = true
+	app.config.CORSMaxAge = 10 * time.Minute
+	app.config.CORSAllowedHeaders = []string{"X-Module-Version"}
+
+	preflight := func(path, origin, method, headers string) http.Header {
+		req := httptest.NewRequest(http.MethodOptions, path, nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", method)
+		if headers != "" {
+			req.Header.Set("Access-Control-Request-Headers", headers)
+		}
+		rr := httptest.NewRecorder()
+		app.Routes().ServeHTTP(rr, req)
+		return rr.Header()
+	}
+
+	allowed := preflight(UserPath, "https://dashboard.apps.example.com", http.MethodPut, "kubeflow-userid,x-module-version")
+	assert.Equal(t, "https://dashboard.apps.example.com", allowed.Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", allowed.Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "600", allowed.Get("Access-Control-Max-Age"))
+	assert.Equal(t, "PUT", allowed.Get("Access-Control-Allow-Methods"))
+
+	assert.Empty(t, preflight(UserPath, "https://evil.example.com", http.MethodGet, "").Get("Access-Control-Allow-Origin"))
+
+	// The watch stream only allows GET, with Last-Event-ID
+	watch := ApiPathPrefix + "/watch/pods"
+	assert.Empty(t, preflight(watch, "https://dashboard.apps.example.com", http.MethodPut, "").Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "last-event-id", preflight(watch, "https://dashboard.apps.example.com", http.MethodGet, "last-event-id").Get("Access-Control-Allow-Headers"))
+	assert.Empty(t, preflight(UserPath, "https://dashboard.apps.example.com", http.MethodGet, "last-event-id").Get("Access-Control-Allow-Headers"))
+}
+
+func TestRegisterCORSPolicy(t *testing.T) {
+	corsPolicyMu.Lock()
+	saved := corsPolicies
+	corsPolicies = map[string]CORSPolicyFactory{}
+	for prefix, factory := range saved {
+		corsPolicies[prefix] = factory
+	}
+	corsPolicyMu.Unlock()
+	t.Cleanup(func() {
+		corsPolicyMu.Lock()
+		corsPolicies = saved
+		corsPolicyMu.Unlock()
+	})
+
+	RegisterCORSPolicy(ApiPathPrefix+"/catalog/", func(base CORSPolicy) CORSPolicy {
+		base.AllowedOrigins = []string{"*"}
+		base.AllowCredentials = false
+		return base
+	})
+	app := newWatchTestApp(t)
+	handler := app.Routes()
+
+	get := func(path string) http.Header {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Origin", "https://anywhere.example.com")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Header()
+	}
+	assert.Equal(t, "*", get(PathPrefix+ApiPathPrefix+"/catalog/models").Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, get(UserPath).Get("Access-Control-Allow-Origin"), "CORS stays disabled elsewhere")
+}
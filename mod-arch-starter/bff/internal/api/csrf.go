@@ -0,0 +1,115 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// csrfTokenLength is the length of the base64url encoding of a 32 bytes token.
+const csrfTokenLength = 43
+
+// ProtectCSRF implements the double-submit cookie protection of -csrf-enabled for the API.
+// Safe requests (GET, HEAD, OPTIONS) get a random token in the CSRF cookie, unless they sent
+// one, and in the CSRF response header. Mutating requests must send the cookie's token back in
+// the CSRF header, which a cross-site page can't read or set, or get a 403. Requests without
+// any cookie are API clients authenticating with a token rather than a browser session, which
+// forged requests would ride on, so they are not checked; neither are -csrf-exempt-paths.
+func (app *App) ProtectCSRF(next http.Handler) http.Handler {
+	if !app.config.CSRFEnabled {
+		return next
+	}
+	sameSite := parseSameSite(app.config.CSRFSameSite)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Signing out is public but a forged sign-out would still end the session of the browser
+		if !app.requiresAuth(r.URL.Path) && strings.TrimPrefix(r.URL.Path, PathPrefix) != LogoutPath || app.csrfExempt(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token := ""
+		if cookie, err := r.Cookie(app.config.CSRFCookieName); err == nil && validCSRFToken(cookie.Value) {
+			token = cookie.Value
+		}
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			if token == "" {
+				token = newCSRFToken()
+				http.SetCookie(w, &http.Cookie{
+					Name:     app.config.CSRFCookieName,
+					Value:    token,
+					Path:     "/",
+					Secure:   !app.config.DevMode || sameSite == http.SameSiteNoneMode,
+					SameSite: sameSite,
+				})
+			}
+			w.Header().Set(app.config.CSRFHeader, token)
+
+		default:
+			if len(r.Cookies()) == 0 {
+				break
+			}
+			sent := r.Header.Get(app.config.CSRFHeader)
+			if token == "" || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+				app.csrfFailedResponse(w, r)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (app *App) csrfExempt(path string) bool {
+	path = strings.TrimPrefix(path, PathPrefix)
+	for _, prefix := range app.config.CSRFExemptPaths {
+		if prefix != "" && strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (app *App) csrfFailedResponse(w http.ResponseWriter, r *http.Request) {
+	app.requestLogger(r).Warn("CSRF check failed", "method", r.Method, "uri", r.URL.RequestURI())
+
+	httpError := &HTTPError{
+		StatusCode: http.StatusForbidden,
+		Error: ErrorPayload{
+			Code:    strconv.Itoa(http.StatusForbidden),
+			Message: "missing or invalid CSRF token: send the " + app.config.CSRFCookieName + " cookie value in the " + app.config.CSRFHeader + " header",
+		},
+	}
+	app.errorResponse(w, r, httpError)
+}
+
+func newCSRFToken() string {
+	token := make([]byte, 32)
+	// crypto/rand.Read never fails since Go 1.24
+	_, _ = rand.Read(token)
+	return base64.RawURLEncoding.EncodeToString(token)
+}
+
+func validCSRFToken(token string) bool {
+	if len(token) != csrfTokenLength {
+		return false
+	}
+	_, err := base64.RawURLEncoding.DecodeString(token)
+	return err == nil
+}
+
+// parseSameSite maps the -csrf-same-site values (lax, strict or none) to their attribute.
+func parseSameSite(value string) http.SameSite {
+	switch strings.ToLower(value) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
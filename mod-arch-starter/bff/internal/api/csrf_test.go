@@ -0,0 +1,86 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCSRFTestApp(t *testing.T) *App {
+	app := newWatchTestApp(t)
+	app.config.CSRFEnabled = true
+	app.config.CSRFCookieName = config.DefaultCSRFCookieName
+	app.config.CSRFHeader = config.DefaultCSRFHeader
+	app.config.CSRFSameSite = "strict"
+	return app
+}
+
+func TestProtectCSRF(t *testing.T) {
+	app := newCSRFTestApp(t)
+	routes := app.Routes()
+	serve := func(method, path string, cookies []*http.Cookie, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(`{"data":{"levels":{}}}`))
+		req.Header.Set(constants.KubeflowUserIDHeader, "user@example.com")
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		if token != "" {
+			req.Header.Set(config.DefaultCSRFHeader, token)
+		}
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Safe requests are issued a token
+	rr := serve(http.MethodGet, UserPath, nil, "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	cookies := rr.Result().Cookies()
+	require.Len(t, cookies, 1)
+	cookie := cookies[0]
+	assert.Equal(t, config.DefaultCSRFCookieName, cookie.Name)
+	assert.Equal(t, http.SameSiteStrictMode, cookie.SameSite)
+	assert.True(t, cookie.Secure)
+	assert.False(t, cookie.HttpOnly, "the frontend reads the token")
+	assert.Equal(t, cookie.Value, rr.Header().Get(config.DefaultCSRFHeader))
+
+	// ... and keep it
+	rr = serve(http.MethodGet, UserPath, []*http.Cookie{cookie}, "")
+	assert.Empty(t, rr.Result().Cookies())
+	assert.Equal(t, cookie.Value, rr.Header().Get(config.DefaultCSRFHeader))
+
+	session := &http.Cookie{Name: "_oauth_proxy", Value: "session"}
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodPut, LogLevelPath, []*http.Cookie{session}, "").Code,
+		"a session without token")
+	rr = serve(http.MethodPut, LogLevelPath, []*http.Cookie{session, cookie}, "")
+	assert.Equal(t, http.StatusForbidden, rr.Code, "a token cookie without header")
+	assert.Contains(t, rr.Body.String(), "CSRF token")
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodPut, LogLevelPath, []*http.Cookie{session, cookie}, newCSRFToken()).Code,
+		"a header not matching the cookie")
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodPut, LogLevelPath, []*http.Cookie{session, {Name: cookie.Name, Value: "x"}}, "x").Code,
+		"tokens not issued by the BFF")
+
+	assert.NotEqual(t, http.StatusForbidden, serve(http.MethodPut, LogLevelPath, []*http.Cookie{session, cookie}, cookie.Value).Code)
+	assert.NotEqual(t, http.StatusForbidden, serve(http.MethodPut, LogLevelPath, nil, "").Code, "token-authenticated clients send no cookie")
+
+	app.config.CSRFExemptPaths = []string{ApiPathPrefix + "/debug/"}
+	routes = app.Routes()
+	assert.NotEqual(t, http.StatusForbidden, serve(http.MethodPut, PathPrefix+LogLevelPath, []*http.Cookie{session}, "").Code, "exempt path")
+}
+
+func TestProtectCSRF_Disabled(t *testing.T) {
+	app := newWatchTestApp(t)
+	req := httptest.NewRequest(http.MethodGet, UserPath, nil)
+	req.Header.Set(constants.KubeflowUserIDHeader, "user@example.com")
+	rr := httptest.NewRecorder()
+	app.Routes().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Result().Cookies())
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/database"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/healthcheck"
+)
+
+// databaseOpenTimeout bounds the connection to the database at startup.
+const databaseOpenTimeout = 30 * time.Second
+
+// newDatabase opens the database of -database-driver and -database-url; nil when they are
+// unset, the BFF then keeps no state.
+func (app *App) newDatabase() (*database.DB, error) {
+	if app.config.DatabaseDriver == "" {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), databaseOpenTimeout)
+	defer cancel()
+	db, err := database.Open(ctx, database.Config{
+		Driver:       app.config.DatabaseDriver,
+		URL:          app.config.DatabaseURL,
+		MaxOpenConns: app.config.DatabaseMaxOpenConns,
+	})
+	if err != nil {
+		return nil, err
+	}
+	app.logger.Info("connected to the database", slog.String("driver", app.config.DatabaseDriver))
+	return db, nil
+}
+
+// migrateModule applies the migrations of a DatabaseModule before its Init.
+func (app *App) migrateModule(module DatabaseModule) error {
+	if app.database == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), databaseOpenTimeout)
+	defer cancel()
+	if err := app.database.Migrate(ctx, module.Name(), module.Migrations(), app.logger); err != nil {
+		return fmt.Errorf("failed to migrate the database of module %s: %w", module.Name(), err)
+	}
+	return nil
+}
+
+// databaseCheck is the readiness check of the database.
+func (app *App) databaseCheck() healthcheck.Check {
+	return healthcheck.Check{Name: "database", Func: app.database.Ping}
+}
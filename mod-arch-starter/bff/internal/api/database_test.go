@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/database"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/healthcheck"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingDriver is registered as "sqlite": it records the statements and returns no rows.
+type recordingDriver struct {
+	mu         sync.Mutex
+	statements []string
+}
+
+var sqliteDriver = &recordingDriver{}
+
+func init() {
+	sql.Register("sqlite", sqliteDriver)
+}
+
+func (d *recordingDriver) record(query string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.statements = append(d.statements, query)
+}
+
+func (d *recordingDriver) Open(string) (driver.Conn, error) { return recordingConn{d}, nil }
+
+type recordingConn struct{ d *recordingDriver }
+
+func (c recordingConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c recordingConn) Close() error                        { return nil }
+func (c recordingConn) Begin() (driver.Tx, error)           { return c, nil }
+func (c recordingConn) Commit() error                       { return nil }
+func (c recordingConn) Rollback() error                     { return nil }
+
+func (c recordingConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.d.record(query)
+	return driver.RowsAffected(0), nil
+}
+
+func (c recordingConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.d.record(query)
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string         { return []string{"version"} }
+func (emptyRows) Close() error              { return nil }
+func (emptyRows) Next([]driver.Value) error { return io.EOF }
+
+type viewsModule struct {
+	testModule
+	db *database.DB
+}
+
+func (m *viewsModule) Migrations() []database.Migration {
+	return []database.Migration{{Version: 1, Name: "create views", SQL: "CREATE TABLE views_views (id TEXT PRIMARY KEY)"}}
+}
+
+func (m *viewsModule) Init(app *App) error {
+	m.db = app.Database()
+	return nil
+}
+
+func TestDatabaseModule(t *testing.T) {
+	module := &viewsModule{testModule: testModule{name: "views"}}
+	registerTestModules(t, module)
+
+	app := newWatchTestApp(t)
+	var err error
+	app.database, err = app.newDatabase()
+	require.NoError(t, err)
+	assert.Nil(t, app.database, "stateless by default")
+	_, err = app.newModules()
+	require.NoError(t, err)
+	assert.Nil(t, module.db)
+
+	app.config.DatabaseDriver, app.config.DatabaseURL, app.config.DatabaseMaxOpenConns = "sqlite", "file::memory:", 1
+	app.database, err = app.newDatabase()
+	require.NoError(t, err)
+	_, err = app.newModules()
+	require.NoError(t, err)
+	assert.Same(t, app.database, module.db)
+	assert.Contains(t, sqliteDriver.statements, "CREATE TABLE views_views (id TEXT PRIMARY KEY)", "migrated before Init")
+
+	app.healthChecks, err = app.newHealthChecks()
+	require.NoError(t, err)
+	statuses := map[string]string{}
+	for _, result := range app.healthChecks.Run(context.Background(), healthcheck.Readiness).Checks {
+		statuses[result.Name] = result.Status
+	}
+	assert.Equal(t, healthcheck.StatusOK, statuses["database"], "pinged by the readiness checks")
+	require.NoError(t, app.database.Close())
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	logging "github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+)
+
+type LogLevelsEnvelope Envelope[models.LogLevels, None]
+type LogLevelsUpdateEnvelope Envelope[models.LogLevelsUpdate, None]
+
+// LogLevelHandler changes the log level, and optionally the per-package levels, of the running
+// BFF. Only cluster admins may call it. Changes last until the BFF restarts or the log settings
+// of the configuration file change.
+func (app *App) LogLevelHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	if !app.requireClusterAdmin(w, r, "change the log level") {
+		return
+	}
+
+	levels := logging.LevelsOf(app.logger)
+	if levels == nil {
+		app.serverErrorResponse(w, r, fmt.Errorf("the log level of this logger cannot be changed at runtime"))
+		return
+	}
+
+	var update LogLevelsUpdateEnvelope
+	if err := app.ReadJSON(w, r, &update); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// Validate everything before applying anything, so a bad request changes nothing
+	var level *slog.Level
+	if update.Data.Level != "" {
+		parsed, err := parseLogLevel(update.Data.Level)
+		if err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+		level = &parsed
+	}
+	packages := make(map[string]*slog.Level, len(update.Data.Packages))
+	for pkg, value := range update.Data.Packages {
+		if strings.TrimSpace(pkg) == "" {
+			app.badRequestResponse(w, r, fmt.Errorf("package name must not be empty"))
+			return
+		}
+		packages[pkg] = nil
+		if value == "" {
+			continue
+		}
+		parsed, err := parseLogLevel(value)
+		if err != nil {
+			app.badRequestResponse(w, r, fmt.Errorf("package %s: %w", pkg, err))
+			return
+		}
+		packages[pkg] = &parsed
+	}
+
+	if level != nil {
+		levels.SetLevel(*level)
+	}
+	for pkg, packageLevel := range packages {
+		if packageLevel == nil {
+			levels.ResetPackageLevel(pkg)
+		} else {
+			levels.SetPackageLevel(pkg, *packageLevel)
+		}
+	}
+
+	current := currentLogLevels(levels)
+	logging.FromRequest(r).Info("log levels changed", "level", current.Level, "packages", current.Packages)
+
+	if err := app.WriteJSON(w, http.StatusOK, LogLevelsEnvelope{Data: current}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// requireClusterAdmin answers 403 Forbidden, and returns false, unless the user of the request
+// is a cluster admin; action completes the error message, e.g. "change the log level".
+func (app *App) requireClusterAdmin(w http.ResponseWriter, r *http.Request, action string) bool {
+	ctx := r.Context()
+	identity, ok := ctx.Value(constants.RequestIdentityKey).(*kubernetes.RequestIdentity)
+	if !ok || identity == nil {
+		app.badRequestResponse(w, r, fmt.Errorf("missing RequestIdentity in context"))
+		return false
+	}
+
+	client, err := app.kubernetesClientFactory.GetClient(ctx)
+	if err != nil {
+		app.serverErrorResponse(w, r, fmt.Errorf("failed to get Kubernetes client: %w", err))
+		return false
+	}
+
+	user, err := app.repositories.User.GetUser(client, ctx, identity)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return false
+	}
+	if !user.ClusterAdmin {
+		app.forbiddenResponse(w, r, fmt.Sprintf("user %s is not a cluster admin and cannot %s", user.UserID, action))
+		return false
+	}
+	return true
+}
+
+func parseLogLevel(value string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		return level, fmt.Errorf("invalid log level %q (must be error, warn, info or debug)", value)
+	}
+	return level, nil
+}
+
+func currentLogLevels(levels *logging.Levels) models.LogLevels {
+	current := models.LogLevels{Level: levels.Level().String(), Packages: map[string]string{}}
+	for pkg, level := range levels.PackageLevels() {
+		current.Packages[pkg] = level.String()
+	}
+	return current
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogLevelHandler(t *testing.T) {
+	app := newWatchTestApp(t)
+	levels := logger.NewLevels(slog.LevelInfo)
+	var err error
+	app.logger, err = logger.New(logger.Options{Output: io.Discard, Levels: levels})
+	require.NoError(t, err)
+
+	put := func(user, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, LogLevelPath, strings.NewReader(body))
+		req.Header.Set(constants.KubeflowUserIDHeader, user)
+		rr := httptest.NewRecorder()
+		app.Routes().ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := put("doraNonAdmin@example.com", `{"data": {"level": "debug"}}`)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Equal(t, slog.LevelInfo, levels.Level())
+
+	rr = put("user@example.com", `{"data": {"level": "debug", "packages": {"proxy": "warn"}}}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var envelope LogLevelsEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &envelope))
+	assert.Equal(t, "DEBUG", envelope.Data.Level)
+	assert.Equal(t, map[string]string{"proxy": "WARN"}, envelope.Data.Packages)
+	assert.Equal(t, slog.LevelDebug, levels.Level())
+
+	// An invalid level rejects the whole update
+	rr = put("user@example.com", `{"data": {"level": "error", "packages": {"proxy": "loud"}}}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, slog.LevelDebug, levels.Level())
+
+	// An empty package level removes the override
+	rr = put("user@example.com", `{"data": {"packages": {"proxy": ""}}}`)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, levels.PackageLevels())
+	assert.Equal(t, slog.LevelDebug, levels.Level())
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+)
+
+type RuntimeInfoEnvelope Envelope[models.RuntimeInfo, None]
+
+// debugAction completes the forbidden message of the debug endpoints.
+const debugAction = "read the runtime diagnostics"
+
+// debugEndpointsOnAPIPort reports whether the debug endpoints are served with the API, rather
+// than by AdminRoutes.
+func (app *App) debugEndpointsOnAPIPort() bool {
+	return app.config.DebugEndpoints && app.config.AdminPort == 0
+}
+
+// addDebugRoutes adds the pprof profiles, the expvar variables, the runtime statistics and the
+// preflight report with handle, for cluster admins only.
+func (app *App) addDebugRoutes(handle func(method, path string, handle httprouter.Handle)) {
+	// pprof serves the profiles under /debug/pprof/, and names them after that prefix
+	profiles := http.NewServeMux()
+	profiles.HandleFunc("/debug/pprof/", pprof.Index)
+	profiles.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	profiles.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	profiles.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	profiles.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	pprofHandler := app.clusterAdminOnly(http.StripPrefix(ApiPathPrefix, profiles))
+
+	handle(http.MethodGet, DebugRuntimePath, app.RuntimeInfoHandler)
+	handle(http.MethodGet, DebugPreflightPath, app.PreflightHandler)
+	handle(http.MethodGet, ExpvarPath, app.clusterAdminOnly(expvar.Handler()))
+	handle(http.MethodGet, PprofPath+"*profile", pprofHandler)
+	handle(http.MethodPost, PprofPath+"*profile", pprofHandler)
+}
+
+// clusterAdminOnly serves next to cluster admins only.
+func (app *App) clusterAdminOnly(next http.Handler) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		if app.requireClusterAdmin(w, r, debugAction) {
+			next.ServeHTTP(w, r)
+		}
+	}
+}
+
+// RuntimeInfoHandler serves the goroutine count, memory use, garbage collector statistics and
+// build information of the BFF. Only cluster admins may call it.
+func (app *App) RuntimeInfoHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	if !app.requireClusterAdmin(w, r, debugAction) {
+		return
+	}
+
+	if err := app.WriteJSON(w, http.StatusOK, RuntimeInfoEnvelope{Data: runtimeInfo()}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func runtimeInfo() models.RuntimeInfo {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	info := models.RuntimeInfo{
+		GoVersion:  runtime.Version(),
+		Goroutines: runtime.NumGoroutine(),
+		NumCPU:     runtime.NumCPU(),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		Memory: models.RuntimeMemory{
+			HeapAlloc:   stats.HeapAlloc,
+			HeapInuse:   stats.HeapInuse,
+			HeapObjects: stats.HeapObjects,
+			Sys:         stats.Sys,
+		},
+		GC: models.RuntimeGC{
+			Runs:       stats.NumGC,
+			PauseTotal: time.Duration(stats.PauseTotalNs),
+		},
+		Build: models.RuntimeBuild{Settings: map[string]string{}},
+	}
+	if stats.NumGC > 0 {
+		lastRun := time.Unix(0, int64(stats.LastGC)).UTC()
+		info.GC.LastRun = &lastRun
+		info.GC.LastPause = time.Duration(stats.PauseNs[(stats.NumGC+255)%256])
+	}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		info.Build.Path = build.Path
+		info.Build.Version = build.Main.Version
+		for _, setting := range build.Settings {
+			info.Build.Settings[setting.Key] = setting.Value
+		}
+	}
+	return info
+}
+
+// AdminRoutes returns the handler of the debug endpoints when they are served on their own
+// port (-admin-port), or nil. Requests are authenticated like the API ones.
+func (app *App) AdminRoutes() http.Handler {
+	if !app.config.DebugEndpoints || app.config.AdminPort == 0 {
+		return nil
+	}
+	router := httprouter.New()
+	router.NotFound = http.HandlerFunc(app.notFoundResponse)
+	router.MethodNotAllowed = http.HandlerFunc(app.methodNotAllowedResponse)
+	app.addDebugRoutes(router.Handle)
+	return app.EnableTelemetry(app.RecoverPanic(app.InjectRequestIdentity(app.RestrictGuests(nil, router))))
+}
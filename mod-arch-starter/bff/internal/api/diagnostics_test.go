@@ -0,0 +1,136 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/preflight"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugEndpoints(t *testing.T) {
+	app := newWatchTestApp(t)
+	app.config.DebugEndpoints = true
+	routes := app.Routes()
+	get := func(user, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(constants.KubeflowUserIDHeader, user)
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get("user@example.com", DebugRuntimePath)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var envelope RuntimeInfoEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &envelope))
+	assert.Positive(t, envelope.Data.Goroutines)
+	assert.Positive(t, envelope.Data.Memory.HeapAlloc)
+	assert.NotEmpty(t, envelope.Data.GoVersion)
+
+	rr = get("user@example.com", ExpvarPath)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"memstats"`)
+
+	for _, path := range []string{PprofPath, PprofPath + "goroutine?debug=1", PathPrefix + PprofPath + "heap"} {
+		assert.Equal(t, http.StatusOK, get("user@example.com", path).Code, path)
+	}
+
+	// Cluster admins only
+	for _, path := range []string{DebugRuntimePath, ExpvarPath, PprofPath + "heap"} {
+		assert.Equal(t, http.StatusForbidden, get("doraNonAdmin@example.com", path).Code, path)
+	}
+	rr = httptest.NewRecorder()
+	routes.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, DebugRuntimePath, nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Nil(t, app.AdminRoutes())
+}
+
+func TestDebugEndpoints_Disabled(t *testing.T) {
+	app := newWatchTestApp(t)
+	req := httptest.NewRequest(http.MethodGet, DebugRuntimePath, nil)
+	req.Header.Set(constants.KubeflowUserIDHeader, "user@example.com")
+	rr := httptest.NewRecorder()
+	app.Routes().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Nil(t, app.AdminRoutes())
+}
+
+func TestAdminRoutes(t *testing.T) {
+	app := newWatchTestApp(t)
+	app.config.DebugEndpoints = true
+	app.config.AdminPort = 4001
+	admin := app.AdminRoutes()
+	require.NotNil(t, admin)
+
+	req := httptest.NewRequest(http.MethodGet, DebugRuntimePath, nil)
+	req.Header.Set(constants.KubeflowUserIDHeader, "user@example.com")
+	rr := httptest.NewRecorder()
+	app.Routes().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code, "not served on the API port")
+
+	rr = httptest.NewRecorder()
+	admin.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	req = httptest.NewRequest(http.MethodGet, PprofPath+"heap", nil)
+	req.Header.Set(constants.KubeflowUserIDHeader, "doraNonAdmin@example.com")
+	rr = httptest.NewRecorder()
+	admin.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	req = httptest.NewRequest(http.MethodGet, UserPath, nil)
+	req.Header.Set(constants.KubeflowUserIDHeader, "user@example.com")
+	rr = httptest.NewRecorder()
+	admin.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code, "only the debug endpoints are served")
+}
+
+func TestPreflight(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+	defer upstream.Close()
+	app := newWatchTestApp(t)
+	app.config.DebugEndpoints = true
+	app.config.AuditWebhookURL = upstream.URL
+	app.config.BundlePaths = []string{filepath.Join(t.TempDir(), "missing.pem")}
+	routes := app.Routes()
+
+	req := httptest.NewRequest(http.MethodGet, DebugPreflightPath, nil)
+	req.Header.Set(constants.KubeflowUserIDHeader, "user@example.com")
+	rr := httptest.NewRecorder()
+	routes.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var envelope PreflightEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &envelope))
+	assert.Equal(t, preflight.StatusWarn, envelope.Data.Status, "the missing bundle is skipped")
+	statuses := map[string]preflight.Status{}
+	for _, result := range envelope.Data.Results {
+		statuses[result.Name] = result.Status
+	}
+	assert.Equal(t, preflight.StatusPass, statuses["rbac/create subjectaccessreviews.authorization.k8s.io"])
+	assert.Equal(t, preflight.StatusPass, statuses["rbac/impersonate users"])
+	assert.Equal(t, preflight.StatusPass, statuses["upstream/audit-webhook"])
+	assert.Equal(t, preflight.StatusWarn, statuses["tls/bundle/"+app.config.BundlePaths[0]])
+
+	req = httptest.NewRequest(http.MethodGet, DebugPreflightPath, nil)
+	req.Header.Set(constants.KubeflowUserIDHeader, "doraNonAdmin@example.com")
+	rr = httptest.NewRecorder()
+	routes.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	// Only failures stop the startup, in the fail mode
+	app.config.Preflight = config.PreflightFail
+	assert.NoError(t, app.RunPreflight(t.Context()))
+	app.config.TLSClientCAFile = app.config.BundlePaths[0]
+	assert.ErrorContains(t, app.RunPreflight(t.Context()), "preflight checks failed")
+	app.config.Preflight = config.PreflightWarn
+	assert.NoError(t, app.RunPreflight(t.Context()))
+}
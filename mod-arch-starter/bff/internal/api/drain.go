@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/healthcheck"
+)
+
+// errDraining fails the readiness check once the server starts shutting down.
+var errDraining = errors.New("server is shutting down")
+
+// drainState tracks in-flight requests and tells long-lived streams to end when the server
+// shuts down, so a rolling update ends them cleanly instead of cutting the connection when the
+// process exits. The zero value is ready to use.
+type drainState struct {
+	draining atomic.Bool
+	inFlight atomic.Int64
+
+	once    sync.Once
+	streams context.Context
+	cancel  context.CancelFunc
+}
+
+// streamsContext is done once streams must end.
+func (d *drainState) streamsContext() context.Context {
+	d.once.Do(func() {
+		d.streams, d.cancel = context.WithCancel(context.Background())
+	})
+	return d.streams
+}
+
+// StartDrain marks the app as draining: /readyz starts failing so the pod is taken out of the
+// Service endpoints, while requests keep being served. Call CloseStreams when the listener closes.
+func (app *App) StartDrain() {
+	if !app.drain.draining.Swap(true) {
+		app.logger.Info("draining, readiness checks now fail", "inFlight", app.InFlightRequests())
+	}
+}
+
+// CloseStreams ends the long-lived responses that would otherwise keep the server from shutting
+// down: watch streams and streaming or upgraded proxy requests are cancelled, and tracked
+// WebSocket connections get a "going away" close frame. Browsers reconnect to another replica
+// (EventSource resumes from its Last-Event-ID). Register it with http.Server.RegisterOnShutdown.
+func (app *App) CloseStreams() {
+	app.StartDrain()
+	app.drain.streamsContext()
+	app.drain.cancel()
+	if app.wsTracker != nil {
+		app.wsTracker.CloseAll("server shutting down")
+	}
+}
+
+// InFlightRequests returns the number of requests currently being served.
+func (app *App) InFlightRequests() int64 {
+	return app.drain.inFlight.Load()
+}
+
+// TrackInFlight counts the requests being served, for logging during shutdown.
+func (app *App) TrackInFlight(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		app.drain.inFlight.Add(1)
+		defer app.drain.inFlight.Add(-1)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// streamContext derives the context of a long-lived response: it is done when the request is,
+// or when CloseStreams is called.
+func (app *App) streamContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(app.drain.streamsContext(), cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// drainingCheck fails once the app is draining.
+func (app *App) drainingCheck() healthcheck.Check {
+	return healthcheck.Check{
+		Name: "shutdown",
+		Func: func(context.Context) error {
+			if app.drain.draining.Load() {
+				return errDraining
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartDrain_FailsReadiness(t *testing.T) {
+	app := newWatchTestApp(t)
+	var err error
+	app.healthChecks, err = app.newHealthChecks()
+	require.NoError(t, err)
+	routes := app.Routes()
+
+	rr := httptest.NewRecorder()
+	routes.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, ReadyzPath, nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	app.StartDrain()
+
+	rr = httptest.NewRecorder()
+	routes.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, ReadyzPath, nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Contains(t, rr.Body.String(), errDraining.Error())
+
+	// Liveness is unaffected, the pod must not be restarted while draining
+	rr = httptest.NewRecorder()
+	routes.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, LivezPath, nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestCloseStreams_EndsWatchStreams(t *testing.T) {
+	app := newWatchTestApp(t)
+	routes := app.Routes()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/watch/services?namespace=dora-namespace", nil)
+	req.Header.Set(constants.KubeflowUserIDHeader, "doraNonAdmin@example.com")
+	rr := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		routes.ServeHTTP(rr, req)
+	}()
+
+	require.Eventually(t, func() bool { return app.InFlightRequests() == 1 }, 5*time.Second, 10*time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("watch stream ended before CloseStreams")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	app.CloseStreams()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch stream was not ended by CloseStreams")
+	}
+	assert.Equal(t, int64(0), app.InFlightRequests())
+	assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+}
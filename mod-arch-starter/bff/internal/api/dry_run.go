@@ -0,0 +1,41 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/audit"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/objectdiff"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/repositories"
+)
+
+// dryRunAnnotation marks the audit entries of dry runs. They change nothing, so they are not
+// dispatched to the webhooks.
+const dryRunAnnotation = "dryRun"
+
+// DryRunMetadata is the metadata of the response of a dry run: the changes the write would
+// have made, with the values of redacted fields left out.
+type DryRunMetadata struct {
+	DryRun  bool                `json:"dryRun"`
+	Changes []objectdiff.Change `json:"changes"`
+}
+
+// dryRunRequest parses the dryRun query parameter of a write. With dryRun=true it returns r
+// with a repositories.WithDryRun context, annotated for the audit, and its DryRun; otherwise r
+// and nil. It writes an error response when the parameter is invalid.
+func (app *App) dryRunRequest(w http.ResponseWriter, r *http.Request) (*http.Request, *repositories.DryRun, bool) {
+	enabled, err := boolQuery(r, "dryRun")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return r, nil, false
+	}
+	if !enabled {
+		return r, nil, true
+	}
+	ctx, dryRun := repositories.WithDryRun(r.Context())
+	audit.Annotate(ctx, dryRunAnnotation, "true")
+	return r.WithContext(ctx), dryRun, true
+}
+
+func newDryRunMetadata(dryRun *repositories.DryRun) *DryRunMetadata {
+	return &DryRunMetadata{DryRun: true, Changes: dryRun.Changes()}
+}
@@ -1,21 +1,22 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"strconv"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/apierrors"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
 )
 
-type HTTPError struct {
-	StatusCode int          `json:"-"`
-	Error      ErrorPayload `json:"error"`
-}
+// HTTPError and ErrorPayload are the JSON error envelope shared by all handlers (see apierrors).
+type HTTPError = apierrors.ErrorEnvelope
 
-type ErrorPayload struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
-}
+type ErrorPayload = apierrors.ErrorPayload
 
 func (app *App) LogError(r *http.Request, err error) {
 	var (
@@ -23,7 +24,16 @@ func (app *App) LogError(r *http.Request, err error) {
 		uri    = r.URL.RequestURI()
 	)
 
-	app.logger.Error(err.Error(), "method", method, "uri", uri)
+	app.requestLogger(r).Error(err.Error(), "method", method, "uri", uri)
+}
+
+// requestLogger returns the request-scoped logger of r (see EnableTelemetry), so error logs carry
+// the request ID returned in the error envelope. It falls back to app.logger.
+func (app *App) requestLogger(r *http.Request) *slog.Logger {
+	if logger, ok := r.Context().Value(constants.TraceLoggerKey).(*slog.Logger); ok && logger != nil {
+		return logger
+	}
+	return app.logger
 }
 
 func (app *App) badRequestResponse(w http.ResponseWriter, r *http.Request, err error) {
@@ -31,15 +41,40 @@ func (app *App) badRequestResponse(w http.ResponseWriter, r *http.Request, err e
 	app.errorResponse(w, r, httpError)
 }
 
+func (app *App) unauthorizedResponse(w http.ResponseWriter, r *http.Request, err error) {
+	// Log the validation failure, but don't echo token details back to the client
+	app.requestLogger(r).Warn("Authentication failed", "error", err, "method", r.Method, "uri", r.URL.RequestURI())
+
+	httpError := &HTTPError{StatusCode: http.StatusUnauthorized, Error: ErrorPayload{Code: strconv.Itoa(http.StatusUnauthorized), Message: apierrors.MessageUnauthorized}}
+	app.errorResponse(w, r, httpError)
+}
+
 func (app *App) forbiddenResponse(w http.ResponseWriter, r *http.Request, message string) {
 	// Log the detailed error message as a warning
-	app.logger.Warn("Access forbidden", "message", message, "method", r.Method, "uri", r.URL.RequestURI())
+	app.requestLogger(r).Warn("Access forbidden", "message", message, "method", r.Method, "uri", r.URL.RequestURI())
 
-	httpError := &HTTPError{StatusCode: http.StatusForbidden, Error: ErrorPayload{Code: strconv.Itoa(http.StatusForbidden), Message: "Access forbidden"}}
+	httpError := &HTTPError{StatusCode: http.StatusForbidden, Error: ErrorPayload{Code: strconv.Itoa(http.StatusForbidden), Message: apierrors.MessageForbidden}}
 	app.errorResponse(w, r, httpError)
 }
 
+// errorResponse writes httpErr, stamped with the request ID. Messages with a key (see
+// apierrors.MessageKey) are localized for the Accept-Language of r from the message catalog.
 func (app *App) errorResponse(w http.ResponseWriter, r *http.Request, httpErr *HTTPError) {
+	if httpErr.Error.RequestID == "" {
+		if requestId, ok := r.Context().Value(constants.RequestIdKey).(string); ok {
+			httpErr.Error.RequestID = requestId
+		}
+	}
+	if httpErr.Error.MessageKey == "" {
+		httpErr.Error.MessageKey = apierrors.MessageKey(httpErr.Error.Message)
+	}
+	if httpErr.Error.MessageKey != "" {
+		message, locale := app.messages.Localize(r.Header.Get("Accept-Language"), httpErr.Error.MessageKey, httpErr.Error.Message)
+		httpErr.Error.Message = message
+		w.Header().Set("Content-Language", locale.String())
+		w.Header().Add("Vary", "Accept-Language")
+	}
+
 	err := app.WriteJSON(w, httpErr.StatusCode, httpErr, nil)
 	if err != nil {
 		app.LogError(r, err)
@@ -47,16 +82,38 @@ func (app *App) errorResponse(w http.ResponseWriter, r *http.Request, httpErr *H
 	}
 }
 
+// apiErrorResponse translates an error returned by a repository or integration (for example a
+// Kubernetes StatusError) into the matching status code and error envelope.
+// Server-side failures are logged; the underlying error is never sent to the client.
+func (app *App) apiErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	apiErr := apierrors.FromError(err)
+	if apiErr.StatusCode >= http.StatusInternalServerError {
+		app.LogError(r, err)
+	} else {
+		app.requestLogger(r).Debug("Request failed", "status", apiErr.StatusCode, "error", err, "method", r.Method, "uri", r.URL.RequestURI())
+	}
+
+	if retryAfter, ok := apiErr.Details["retryAfterSeconds"].(int32); ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter)))
+	}
+	app.errorResponse(w, r, apiErr.Envelope(""))
+}
+
 func (app *App) serverErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, apierrors.ErrRequestTimeout) {
+		// The request exceeded its timeout (see EnforceTimeouts) while the handler waited
+		app.apiErrorResponse(w, r, err)
+		return
+	}
 	app.LogError(r, err)
 
-	httpError := &HTTPError{StatusCode: http.StatusInternalServerError, Error: ErrorPayload{Code: strconv.Itoa(http.StatusInternalServerError), Message: "the server encountered a problem and could not process your request"}}
+	httpError := &HTTPError{StatusCode: http.StatusInternalServerError, Error: ErrorPayload{Code: strconv.Itoa(http.StatusInternalServerError), Message: apierrors.MessageInternal}}
 	app.errorResponse(w, r, httpError)
 }
 
 func (app *App) notFoundResponse(w http.ResponseWriter, r *http.Request) {
 
-	httpError := &HTTPError{StatusCode: http.StatusNotFound, Error: ErrorPayload{Code: strconv.Itoa(http.StatusNotFound), Message: "the requested resource could not be found"}}
+	httpError := &HTTPError{StatusCode: http.StatusNotFound, Error: ErrorPayload{Code: strconv.Itoa(http.StatusNotFound), Message: apierrors.MessageNotFound}}
 	app.errorResponse(w, r, httpError)
 }
 
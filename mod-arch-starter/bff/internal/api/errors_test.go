@@ -0,0 +1,108 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/apierrors"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestApiErrorResponse(t *testing.T) {
+	app := &App{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantMsg    string
+	}{
+		{
+			name:       "kubernetes forbidden",
+			err:        fmt.Errorf("error fetching namespaces: %w", k8serrors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, "", fmt.Errorf("denied"))),
+			wantStatus: http.StatusForbidden,
+			wantMsg:    "Access forbidden",
+		},
+		{
+			name:       "kubernetes too many requests",
+			err:        k8serrors.NewTooManyRequests("slow down", 3),
+			wantStatus: http.StatusTooManyRequests,
+			wantMsg:    "too many requests, please retry later",
+		},
+		{
+			name:       "unknown error is hidden",
+			err:        fmt.Errorf("secret connection string leaked"),
+			wantStatus: http.StatusInternalServerError,
+			wantMsg:    "the server encountered a problem and could not process your request",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/namespaces", nil)
+			req = req.WithContext(context.WithValue(req.Context(), constants.RequestIdKey, "req-123"))
+			rr := httptest.NewRecorder()
+
+			app.apiErrorResponse(rr, req, tt.err)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			var body HTTPError
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+			assert.Equal(t, tt.wantMsg, body.Error.Message)
+			assert.Equal(t, fmt.Sprint(tt.wantStatus), body.Error.Code)
+			assert.Equal(t, "req-123", body.Error.RequestID)
+		})
+	}
+}
+
+func TestErrorResponse_Localized(t *testing.T) {
+	messages, err := i18n.Load("")
+	require.NoError(t, err)
+	app := &App{logger: slog.New(slog.NewTextHandler(io.Discard, nil)), messages: messages}
+	respond := func(acceptLanguage string, err error) (*httptest.ResponseRecorder, ErrorPayload) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/namespaces", nil)
+		req.Header.Set("Accept-Language", acceptLanguage)
+		rr := httptest.NewRecorder()
+		app.apiErrorResponse(rr, req, err)
+		var body HTTPError
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		return rr, body.Error
+	}
+	forbidden := k8serrors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, "", fmt.Errorf("denied"))
+
+	rr, payload := respond("es-MX,es;q=0.9,en;q=0.8", forbidden)
+	assert.Equal(t, "Acceso prohibido", payload.Message)
+	assert.Equal(t, apierrors.KeyForbidden, payload.MessageKey)
+	assert.Equal(t, "403", payload.Code, "codes are not localized")
+	assert.Equal(t, "es", rr.Header().Get("Content-Language"))
+	assert.Equal(t, "Accept-Language", rr.Header().Get("Vary"))
+
+	rr, payload = respond("de-DE", forbidden)
+	assert.Equal(t, apierrors.MessageForbidden, payload.Message, "unknown locales get English")
+	assert.Equal(t, "en", rr.Header().Get("Content-Language"))
+
+	rr, payload = respond("fr", apierrors.BadRequest("name is required"))
+	assert.Equal(t, "name is required", payload.Message, "messages without a key are not localized")
+	assert.Empty(t, payload.MessageKey)
+	assert.Empty(t, rr.Header().Get("Content-Language"))
+}
+
+func TestApiErrorResponse_RetryAfter(t *testing.T) {
+	app := &App{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	rr := httptest.NewRecorder()
+
+	app.apiErrorResponse(rr, httptest.NewRequest(http.MethodGet, "/", nil), k8serrors.NewTooManyRequests("slow down", 3))
+
+	assert.Equal(t, "3", rr.Header().Get("Retry-After"))
+}
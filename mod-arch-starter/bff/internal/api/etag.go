@@ -0,0 +1,129 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// DefaultCacheControl is the Cache-Control of the responses of ConditionalGET: responses depend
+// on the caller, so only their browser may store them, and must revalidate them with their ETag.
+const DefaultCacheControl = "private, no-cache"
+
+// ContentETag returns a strong ETag of a response body.
+func ContentETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+}
+
+// ResourceVersionETag returns a weak ETag of a Kubernetes resourceVersion, for responses built
+// from one object or list: equal versions give equivalent, not byte-identical, responses.
+func ResourceVersionETag(resourceVersion string) string {
+	return `W/"` + resourceVersion + `"`
+}
+
+// CheckNotModified sets the ETag of the response and, when the If-None-Match header of the
+// request matches it, answers 304 Not Modified and returns true. Handlers that can compute
+// an ETag cheaply (e.g. ResourceVersionETag) call it before building the response:
+//
+//	if app.CheckNotModified(w, r, api.ResourceVersionETag(list.GetResourceVersion())) {
+//	    return
+//	}
+func (app *App) CheckNotModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+	if r.Method != http.MethodGet && r.Method != http.MethodHead || !etagMatches(r.Header.Get("If-None-Match"), etag) {
+		return false
+	}
+	if w.Header().Get("Cache-Control") == "" {
+		w.Header().Set("Cache-Control", DefaultCacheControl)
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatches reports whether the If-None-Match header value matches etag, using the weak
+// comparison of RFC 9110: W/ prefixes are ignored.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ConditionalGET buffers the 200 responses of a GET handler to set their ETag, a ContentETag
+// unless the handler set one, and their Cache-Control, DefaultCacheControl unless the handler
+// set one. Requests whose If-None-Match matches the ETag get a 304 without body, so clients
+// polling an endpoint only download changes. It must not wrap streaming handlers.
+func (app *App) ConditionalGET(next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next(w, r, ps)
+			return
+		}
+
+		buffered := &bufferedResponseWriter{ResponseWriter: w}
+		next(buffered, r, ps)
+
+		if buffered.status == 0 {
+			buffered.status = http.StatusOK
+		}
+		if buffered.status != http.StatusOK {
+			w.WriteHeader(buffered.status)
+			_, _ = w.Write(buffered.body.Bytes())
+			return
+		}
+
+		if w.Header().Get("Cache-Control") == "" {
+			w.Header().Set("Cache-Control", DefaultCacheControl)
+		}
+		etag := w.Header().Get("ETag")
+		if etag == "" {
+			etag = ContentETag(buffered.body.Bytes())
+			w.Header().Set("ETag", etag)
+		}
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.Header().Del("Content-Type")
+			w.Header().Del("Content-Length")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(buffered.body.Bytes())
+	}
+}
+
+// bufferedResponseWriter holds the status and body of a response; headers are set on the
+// wrapped writer directly.
+type bufferedResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *bufferedResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
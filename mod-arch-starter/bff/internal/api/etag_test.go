@@ -0,0 +1,94 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConditionalGET_User(t *testing.T) {
+	app := newWatchTestApp(t)
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, UserPath, nil)
+		req.Header.Set(constants.KubeflowUserIDHeader, "user@example.com")
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rr := httptest.NewRecorder()
+		app.Routes().ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get("")
+	require.Equal(t, http.StatusOK, rr.Code)
+	etag := rr.Header().Get("ETag")
+	assert.Equal(t, ContentETag(rr.Body.Bytes()), etag)
+	assert.Equal(t, DefaultCacheControl, rr.Header().Get("Cache-Control"))
+
+	rr = get(`"other", ` + etag)
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+	assert.Empty(t, rr.Body.String())
+	assert.Equal(t, etag, rr.Header().Get("ETag"))
+	assert.Empty(t, rr.Header().Get("Content-Type"))
+
+	assert.Equal(t, http.StatusOK, get(`"other"`).Code)
+}
+
+func TestConditionalGET(t *testing.T) {
+	app := newWatchTestApp(t)
+	serve := func(handler httprouter.Handle, method, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rr := httptest.NewRecorder()
+		app.ConditionalGET(handler)(rr, req, nil)
+		return rr
+	}
+
+	versioned := func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		if app.CheckNotModified(w, r, ResourceVersionETag("42")) {
+			return
+		}
+		w.Header().Set("Cache-Control", "private, max-age=5")
+		_, _ = w.Write([]byte("body"))
+	}
+	rr := serve(versioned, http.MethodGet, "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `W/"42"`, rr.Header().Get("ETag"), "the handler ETag is kept")
+	assert.Equal(t, "private, max-age=5", rr.Header().Get("Cache-Control"))
+	assert.Equal(t, "body", rr.Body.String())
+
+	rr = serve(versioned, http.MethodGet, `"42"`)
+	assert.Equal(t, http.StatusNotModified, rr.Code, "weak comparison")
+	assert.Empty(t, rr.Body.String())
+
+	failing := func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		app.notFoundResponse(w, r)
+	}
+	rr = serve(failing, http.MethodGet, "*")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Empty(t, rr.Header().Get("ETag"), "errors are not cached")
+	assert.NotEmpty(t, rr.Body.String())
+
+	created := func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.WriteHeader(http.StatusCreated)
+	}
+	rr = serve(created, http.MethodPost, "*")
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Empty(t, rr.Header().Get("ETag"), "only GET and HEAD are conditional")
+}
+
+func TestETagMatches(t *testing.T) {
+	assert.True(t, etagMatches(`"a"`, `"a"`))
+	assert.True(t, etagMatches(`W/"a"`, `"a"`))
+	assert.True(t, etagMatches(`"b" , "a"`, `W/"a"`))
+	assert.True(t, etagMatches(`*`, `"a"`))
+	assert.False(t, etagMatches(``, `"a"`))
+	assert.False(t, etagMatches(`"b"`, `"a"`))
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/pagination"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/repositories"
+)
+
+type EventsEnvelope PagedResponse[models.EventModel]
+
+var eventFields = pagination.Fields[models.EventModel]{
+	"type":   func(event models.EventModel) string { return event.Type },
+	"reason": func(event models.EventModel) string { return event.Reason },
+	"lastTimestamp": func(event models.EventModel) string {
+		if event.LastTimestamp == nil {
+			return ""
+		}
+		return event.LastTimestamp.UTC().Format(time.RFC3339)
+	},
+}
+
+// GetEventsHandler lists the Events about the resource of the kind and name query parameters
+// (and optionally uid) in the namespace (AttachNamespace), most recent first. The list
+// parameters of pagination.ParseListOptions page the result.
+func (app *App) GetEventsHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+
+	ctx := r.Context()
+	identity, ok := ctx.Value(constants.RequestIdentityKey).(*kubernetes.RequestIdentity)
+	if !ok || identity == nil {
+		app.badRequestResponse(w, r, fmt.Errorf("missing RequestIdentity in context"))
+		return
+	}
+	namespace := requestNamespace(r)
+
+	query := r.URL.Query()
+	object := repositories.EventObject{Kind: query.Get("kind"), Name: query.Get("name"), UID: query.Get("uid")}
+	if object.Kind == "" || object.Name == "" {
+		app.badRequestResponse(w, r, fmt.Errorf("missing required query parameters: kind and name"))
+		return
+	}
+	opts, err := pagination.ParseListOptions(query)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	client, err := app.kubernetesClientFactory.GetClient(ctx)
+	if err != nil {
+		app.serverErrorResponse(w, r, fmt.Errorf("failed to get Kubernetes client: %w", err))
+		return
+	}
+
+	events, err := app.repositories.Events.GetEvents(client, ctx, identity, namespace, object)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+
+	page, metadata, err := pagination.Apply(events, opts, eventFields)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	err = app.WriteJSON(w, http.StatusOK, EventsEnvelope{Data: page, Metadata: metadata}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEventsHandler(t *testing.T) {
+	app := newWatchTestApp(t)
+	routes := app.Routes()
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, EventsPath+query, nil)
+		req.Header.Set(constants.KubeflowUserIDHeader, "doraNonAdmin@example.com")
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get("?namespace=dora-namespace&kind=Pod&name=mod-arch-dora-7c9d8-x2k4p")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var envelope EventsEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &envelope))
+	require.Len(t, envelope.Data, 2)
+	assert.Equal(t, "BackOff", envelope.Data[0].Reason)
+	assert.Equal(t, "Scheduled", envelope.Data[1].Reason)
+
+	rr = get("?namespace=dora-namespace&kind=Pod&name=mod-arch-dora-7c9d8-x2k4p&filter=type:warning")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &envelope))
+	require.Len(t, envelope.Data, 1)
+	assert.Equal(t, int32(5), envelope.Data[0].Count)
+
+	assert.Equal(t, http.StatusForbidden, get("?namespace=bella-namespace&kind=Deployment&name=mod-arch-bella").Code)
+	assert.Equal(t, http.StatusBadRequest, get("?namespace=dora-namespace&kind=Pod").Code)
+	assert.Equal(t, http.StatusBadRequest, get("?kind=Pod&name=web").Code)
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/featureflags"
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+)
+
+type FeaturesEnvelope Envelope[map[string]bool, None]
+
+// newFeatureFlags returns the flags of -frontend-features, replaced by the flags of the same
+// name in -feature-flags-file.
+func newFeatureFlags(cfg config.EnvConfig) ([]featureflags.Flag, error) {
+	values, err := config.ParseFeatureFlags(cfg.FrontendFeatures)
+	if err != nil {
+		return nil, err
+	}
+	var fromFile []featureflags.Flag
+	if cfg.FeatureFlagsFile != "" {
+		if fromFile, err = featureflags.LoadFile(cfg.FeatureFlagsFile); err != nil {
+			return nil, err
+		}
+	}
+
+	flags := make([]featureflags.Flag, 0, len(values)+len(fromFile))
+	for name, enabled := range values {
+		if !definesFlag(fromFile, name) {
+			flags = append(flags, featureflags.Flag{Name: name, Enabled: enabled})
+		}
+	}
+	return append(flags, fromFile...), nil
+}
+
+func definesFlag(flags []featureflags.Flag, name string) bool {
+	for _, flag := range flags {
+		if flag.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// featureFlagSet returns the feature flags of the app; apps not created by NewApp, e.g. in
+// tests, get the flags of their configuration.
+func (app *App) featureFlagSet() (*featureflags.Set, error) {
+	if app.featureFlags != nil {
+		return app.featureFlags, nil
+	}
+	flags, err := newFeatureFlags(app.config)
+	if err != nil {
+		return nil, err
+	}
+	return featureflags.NewSet(flags)
+}
+
+// WatchFeatureFlags reloads the flags whenever -feature-flags-file changes, e.g. when its
+// ConfigMap is updated, until ctx is done. Invalid files are logged and the current flags kept.
+func (app *App) WatchFeatureFlags(ctx context.Context) error {
+	path := app.config.FeatureFlagsFile
+	if path == "" || app.featureFlags == nil {
+		return nil
+	}
+	return config.WatchFile(ctx, path, app.logger, func() {
+		flags, err := newFeatureFlags(app.config)
+		if err == nil {
+			err = app.featureFlags.Replace(flags)
+		}
+		if err != nil {
+			app.logger.Error("failed to reload feature flags, keeping the current ones", "path", path, "error", err)
+			return
+		}
+		app.logger.Info("feature flags reloaded", "path", path, "flags", len(flags))
+	})
+}
+
+// FeatureEnabled evaluates the flag name for the RequestIdentity of r, and the namespace set
+// by AttachNamespace. Unknown flags are disabled.
+//
+// Example usage in downstream handlers:
+//
+//	if !app.FeatureEnabled(r, "notebooks-v2") {
+//	    app.NotImplemented(w, r, "notebooks-v2")
+//	    return
+//	}
+func (app *App) FeatureEnabled(r *http.Request, name string) bool { //nolint:unused
+	set, err := app.featureFlagSet()
+	if err != nil {
+		app.requestLogger(r).Error("invalid feature flags", "error", err)
+		return false
+	}
+	return set.Enabled(name, featureSubject(r))
+}
+
+// featureSubject is the subject of the flags evaluated for r.
+func featureSubject(r *http.Request) featureflags.Subject {
+	var subject featureflags.Subject
+	if identity, ok := r.Context().Value(constants.RequestIdentityKey).(*k8s.RequestIdentity); ok && identity != nil {
+		subject.User = identity.UserID
+		subject.Groups = identity.Groups
+	}
+	if namespace, ok := r.Context().Value(constants.NamespaceHeaderParameterKey).(string); ok {
+		subject.Namespace = namespace
+	} else {
+		subject.Namespace = r.URL.Query().Get(string(constants.NamespaceHeaderParameterKey))
+	}
+	return subject
+}
+
+// FeaturesHandler serves the value of every feature flag for the caller, and the namespace of
+// the optional namespace query parameter.
+func (app *App) FeaturesHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	identity, ok := r.Context().Value(constants.RequestIdentityKey).(*k8s.RequestIdentity)
+	if !ok || identity == nil {
+		app.badRequestResponse(w, r, fmt.Errorf("missing RequestIdentity in context"))
+		return
+	}
+
+	set, err := app.featureFlagSet()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.WriteJSON(w, http.StatusOK, FeaturesEnvelope{Data: set.Evaluate(featureSubject(r))}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
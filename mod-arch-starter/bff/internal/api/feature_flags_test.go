@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/featureflags"
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testFeatureFlags = `flags:
+  - name: pipelines
+    rules:
+      - namespaces: [kubeflow]
+        enabled: true
+  - name: tuning
+    rules:
+      - users: [user@example.com]
+        enabled: true
+`
+
+func newFeatureFlagsTestApp(t *testing.T) *App {
+	app := newWatchTestApp(t)
+	app.config.FrontendFeatures = []string{"catalog", "tuning=false"}
+	app.config.FeatureFlagsFile = filepath.Join(t.TempDir(), "flags.yaml")
+	require.NoError(t, os.WriteFile(app.config.FeatureFlagsFile, []byte(testFeatureFlags), 0o600))
+
+	flags, err := newFeatureFlags(app.config)
+	require.NoError(t, err)
+	app.featureFlags, err = featureflags.NewSet(flags)
+	require.NoError(t, err)
+	return app
+}
+
+func TestFeaturesHandler(t *testing.T) {
+	app := newFeatureFlagsTestApp(t)
+	routes := app.Routes()
+	features := func(user, path string) map[string]bool {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(constants.KubeflowUserIDHeader, user)
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		var envelope FeaturesEnvelope
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &envelope))
+		return envelope.Data
+	}
+
+	assert.Equal(t, map[string]bool{"catalog": true, "pipelines": false, "tuning": true}, features("user@example.com", FeaturesPath))
+	assert.Equal(t, map[string]bool{"catalog": true, "pipelines": true, "tuning": false}, features("other@example.com", FeaturesPath+"?namespace=kubeflow"))
+
+	// The public configuration has the anonymous values
+	rr := httptest.NewRecorder()
+	routes.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, FrontendConfigPath, nil))
+	var config FrontendConfigEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &config))
+	assert.Equal(t, map[string]bool{"catalog": true, "pipelines": false, "tuning": false}, config.Data.Features)
+}
+
+func TestFeatureEnabled(t *testing.T) {
+	app := newFeatureFlagsTestApp(t)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx := context.WithValue(req.Context(), constants.RequestIdentityKey, &k8s.RequestIdentity{UserID: "user@example.com"})
+	ctx = context.WithValue(ctx, constants.NamespaceHeaderParameterKey, "kubeflow")
+	req = req.WithContext(ctx)
+
+	assert.True(t, app.FeatureEnabled(req, "tuning"))
+	assert.True(t, app.FeatureEnabled(req, "pipelines"))
+	assert.False(t, app.FeatureEnabled(req, "unknown"))
+}
+
+func TestWatchFeatureFlags(t *testing.T) {
+	app := newFeatureFlagsTestApp(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	require.NoError(t, app.WatchFeatureFlags(ctx))
+
+	require.NoError(t, os.WriteFile(app.config.FeatureFlagsFile, []byte("flags:\n  - name: pipelines\n    enabled: true\n"), 0o600))
+	assert.Eventually(t, func() bool {
+		return app.featureFlags.Enabled("pipelines", featureflags.Subject{})
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.False(t, app.featureFlags.Enabled("tuning", featureflags.Subject{}), "back to the -frontend-features value")
+
+	// Invalid files keep the current flags
+	require.NoError(t, os.WriteFile(app.config.FeatureFlagsFile, []byte("flags: [{name: pipelines}, {name: pipelines}]\n"), 0o600))
+	time.Sleep(500 * time.Millisecond)
+	assert.True(t, app.featureFlags.Enabled("pipelines", featureflags.Subject{}))
+}
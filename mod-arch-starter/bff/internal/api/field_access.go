@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/fieldaccess"
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	logging "github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/metrics"
+)
+
+// FilterFields lets WriteJSON strip the model fields the user may not read, those with an
+// access tag whose permission is denied (see fieldaccess). It wraps the ResponseWriter of the
+// handlers with the request, so it runs right above them; handler wrappers of the writer must
+// implement Unwrap. The permissions are checked in the namespace of the request, resolved like
+// Authorize does from the route.
+func (app *App) FilterFields(route metrics.RouteFunc, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pattern := route(r)
+		if pattern == "" {
+			pattern = strings.TrimPrefix(r.URL.Path, PathPrefix)
+		}
+		next.ServeHTTP(&fieldAccessWriter{ResponseWriter: w, request: r, namespace: routeNamespace(pattern, r)}, r)
+	})
+}
+
+// fieldAccessWriter carries the request of a response, and its namespace, to WriteJSON.
+type fieldAccessWriter struct {
+	http.ResponseWriter
+	request   *http.Request
+	namespace string
+}
+
+func (w *fieldAccessWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// filterFields returns data without the fields denied to the user of the request of w. The
+// checks fail closed: a field is left out when its permission can't be checked, e.g. of
+// responses written outside FilterFields.
+func (app *App) filterFields(w http.ResponseWriter, data any) (any, error) {
+	var r *http.Request
+	var namespace string
+	for w != nil && r == nil {
+		switch writer := w.(type) {
+		case *fieldAccessWriter:
+			r, namespace = writer.request, writer.namespace
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = writer.Unwrap()
+		default:
+			w = nil
+		}
+	}
+	ctx := context.Background()
+	if r != nil {
+		ctx = r.Context()
+	}
+	return fieldaccess.Filter(ctx, data, app.fieldAccessCheck(r, namespace))
+}
+
+// fieldAccessCheck checks the access tags for the user of r, in namespace for the access
+// reviews.
+func (app *App) fieldAccessCheck(r *http.Request, namespace string) fieldaccess.CheckFunc {
+	return func(ctx context.Context, requirement fieldaccess.Requirement) bool {
+		if r == nil {
+			return false
+		}
+		identity, ok := ctx.Value(constants.RequestIdentityKey).(*k8s.RequestIdentity)
+		if !ok || identity == nil {
+			return false
+		}
+		logger := logging.FromRequest(r)
+		client, err := app.kubernetesClientFactory.GetClient(ctx)
+		if err != nil {
+			logger.Warn("failed to check the access to response fields", "access", requirement.String(), "error", err)
+			return false
+		}
+		if requirement.ClusterAdmin {
+			user, err := app.repositories.User.GetUser(client, ctx, identity)
+			if err != nil {
+				logger.Warn("failed to check the access to response fields", "access", requirement.String(), "error", err)
+				return false
+			}
+			return user.ClusterAdmin
+		}
+		check, err := app.repositories.Permission.CheckPermission(client, ctx, identity, requirement.Verb, requirement.Group, requirement.Resource, namespace)
+		if err != nil {
+			logger.Warn("failed to check the access to response fields", "access", requirement.String(), "error", err)
+			return false
+		}
+		return check.Allowed
+	}
+}
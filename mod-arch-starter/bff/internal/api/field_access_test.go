@@ -0,0 +1,62 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fieldAccessTestModel struct {
+	Name    string                 `json:"name"`
+	Admin   string                 `json:"admin,omitempty" access:"cluster-admin"`
+	Secrets []string               `json:"secrets,omitempty" access:"list secrets"`
+	Items   []fieldAccessTestModel `json:"items,omitempty"`
+}
+
+func TestFilterFields(t *testing.T) {
+	app := newWatchTestApp(t)
+	data := fieldAccessTestModel{Name: "a", Admin: "x", Secrets: []string{"db"}, Items: []fieldAccessTestModel{{Name: "b", Admin: "y"}}}
+	namespacedPath := NamespacePath + "/:namespace/things"
+	route := func(r *http.Request) string {
+		if strings.HasPrefix(r.URL.Path, NamespacePath+"/") {
+			return namespacedPath
+		}
+		return r.URL.Path
+	}
+	handler := app.InjectRequestIdentity(app.FilterFields(route, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		require.NoError(t, app.WriteJSON(w, http.StatusOK, Envelope[fieldAccessTestModel, None]{Data: data}, nil))
+	})))
+	getPath := func(user, path string) fieldAccessTestModel {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(constants.KubeflowUserIDHeader, user)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		var envelope Envelope[fieldAccessTestModel, None]
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &envelope))
+		return envelope.Data
+	}
+	get := func(user, namespace string) fieldAccessTestModel {
+		return getPath(user, NamespacePath+"?namespace="+namespace)
+	}
+
+	assert.Equal(t, data, get("user@example.com", "dora-namespace"), "cluster admins read everything")
+	assert.Equal(t, fieldAccessTestModel{Name: "a", Secrets: []string{"db"}, Items: []fieldAccessTestModel{{Name: "b"}}}, get("doraNonAdmin@example.com", "dora-namespace"))
+	assert.Equal(t, fieldAccessTestModel{Name: "a", Items: []fieldAccessTestModel{{Name: "b"}}}, get("doraNonAdmin@example.com", "bella-namespace"))
+	assert.Equal(t, "x", data.Admin, "the data is not modified")
+
+	// The namespace of a path-namespaced route is its :namespace parameter
+	assert.Equal(t, fieldAccessTestModel{Name: "a", Secrets: []string{"db"}, Items: []fieldAccessTestModel{{Name: "b"}}}, getPath("doraNonAdmin@example.com", NamespacePath+"/dora-namespace/things"))
+	assert.Equal(t, fieldAccessTestModel{Name: "a", Items: []fieldAccessTestModel{{Name: "b"}}}, getPath("doraNonAdmin@example.com", NamespacePath+"/bella-namespace/things?namespace=dora-namespace"))
+
+	// Responses written outside FilterFields fail closed
+	rr := httptest.NewRecorder()
+	require.NoError(t, app.WriteJSON(rr, http.StatusOK, data, nil))
+	assert.NotContains(t, rr.Body.String(), "admin")
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/frontend"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/proxy"
+)
+
+var (
+	frontendFilesMu sync.RWMutex
+	frontendFiles   fs.FS
+)
+
+// RegisterFrontendAssets serves the frontend from fsys, typically an embed.FS compiled into the
+// downstream binary, instead of -static-assets-dir. This should be called from an init()
+// function in the downstream code.
+//
+// Example usage in downstream code:
+//
+//	//go:embed all:dist
+//	var dist embed.FS
+//
+//	func init() {
+//	    assets, _ := fs.Sub(dist, "dist")
+//	    api.RegisterFrontendAssets(assets)
+//	}
+func RegisterFrontendAssets(fsys fs.FS) { //nolint:unused
+	frontendFilesMu.Lock()
+	defer frontendFilesMu.Unlock()
+	frontendFiles = fsys
+}
+
+// frontendFiles returns the registered frontend assets, or the -static-assets-dir directory.
+func (app *App) frontendFiles() fs.FS {
+	frontendFilesMu.RLock()
+	defer frontendFilesMu.RUnlock()
+	if frontendFiles != nil {
+		return frontendFiles
+	}
+	return os.DirFS(app.config.StaticAssetsDir)
+}
+
+// frontendHandler serves the frontend assets, with index.html for the SPA routes, or proxies the
+// frontend to the dev server of -frontend-dev-server-url.
+func (app *App) frontendHandler() http.Handler {
+	if app.frontendDevServer != nil {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// The dev server's pages have no CSP nonce, and hot reloading evaluates code
+			w.Header().Del("Content-Security-Policy")
+			app.frontendDevServer.ServeHTTP(w, r)
+		})
+	}
+	return frontend.NewHandler(frontend.Options{
+		Files:          app.frontendFiles(),
+		TransformIndex: addScriptNonce,
+	})
+}
+
+// newFrontendDevServer builds the proxy to the frontend dev server, in dev mode. It returns nil
+// when -frontend-dev-server-url is not set.
+func (app *App) newFrontendDevServer() (*proxy.ReverseProxy, error) {
+	if !app.config.DevMode || app.config.FrontendDevServerURL == "" {
+		return nil, nil
+	}
+	target, err := url.Parse(app.config.FrontendDevServerURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid frontend dev server URL: %w", err)
+	}
+	app.logger.Info("proxying the frontend to its dev server", "url", target.String())
+
+	return proxy.NewReverseProxy([]proxy.Route{{
+		Name:       "frontend-dev-server",
+		PathPrefix: "/",
+		Target:     target,
+		// Hot reloading keeps a WebSocket (or an event stream) open
+		Streaming: true,
+	}}, proxy.ReverseProxyOptions{
+		ErrorHandler: func(w http.ResponseWriter, _ *http.Request, statusCode int, _ error) {
+			http.Error(w, fmt.Sprintf("The frontend dev server at %s is not reachable, is it running?", target), statusCode)
+		},
+		DrainContext: app.drain.streamsContext(),
+		Logger:       logger.ForPackage(app.logger, "proxy"),
+	})
+}
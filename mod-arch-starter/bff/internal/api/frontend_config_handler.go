@@ -0,0 +1,84 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/featureflags"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+)
+
+type FrontendConfigEnvelope Envelope[models.FrontendConfig, None]
+
+var (
+	frontendConfigMu     sync.RWMutex
+	frontendConfigValues = map[string]func(app *App) any{}
+)
+
+// RegisterFrontendConfig publishes the value returned by value under name in the extensions
+// of /api/v1/config, for settings of a downstream module. The endpoint is public, so never
+// publish secrets. This should be called from an init() function in the downstream code.
+//
+// Example usage in downstream code:
+//
+//	func init() {
+//	    api.RegisterFrontendConfig("notebooks", func(app *api.App) any {
+//	        return map[string]any{"defaultImage": app.Config().NotebookImage}
+//	    })
+//	}
+func RegisterFrontendConfig(name string, value func(app *App) any) { //nolint:unused
+	frontendConfigMu.Lock()
+	defer frontendConfigMu.Unlock()
+	frontendConfigValues[name] = value
+}
+
+// FrontendConfigHandler serves the runtime configuration of the frontend. Like the OpenAPI
+// document it is served without authentication, so the frontend can read it before login.
+func (app *App) FrontendConfigHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	flags, err := app.featureFlagSet()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	// Validated at startup
+	services, err := config.ParseServiceURLs(app.config.FrontendServiceURLs)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	frontendConfig := models.FrontendConfig{
+		DeploymentMode: app.config.DeploymentMode.String(),
+		AuthMethod:     app.config.AuthMethod,
+		DevMode:        app.config.DevMode,
+		KubeflowMode:   app.config.KubeflowMode,
+		GuestMode:      app.config.GuestMode,
+		// Public, so without the targeting of FeaturesPath
+		Features: flags.Evaluate(featureflags.Subject{}),
+		Services: services,
+	}
+	if app.config.AuthMethod == config.AuthMethodUser {
+		frontendConfig.AuthTokenHeader = app.config.AuthTokenHeader
+	}
+	if app.config.CSRFEnabled {
+		frontendConfig.CSRF = &models.CSRFConfig{CookieName: app.config.CSRFCookieName, HeaderName: app.config.CSRFHeader}
+	}
+
+	frontendConfigMu.RLock()
+	for name, value := range frontendConfigValues {
+		if frontendConfig.Extensions == nil {
+			frontendConfig.Extensions = make(map[string]any, len(frontendConfigValues))
+		}
+		frontendConfig.Extensions[name] = value(app)
+	}
+	frontendConfigMu.RUnlock()
+
+	// The same for every caller, unlike the rest of the API
+	w.Header().Set("Cache-Control", "public, no-cache")
+	err = app.WriteJSON(w, http.StatusOK, FrontendConfigEnvelope{Data: frontendConfig}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrontendConfigHandler(t *testing.T) {
+	app := newWatchTestApp(t)
+	app.config.DeploymentMode = config.DeploymentModeStandalone
+	app.config.AuthMethod = config.AuthMethodUser
+	app.config.AuthTokenHeader = config.DefaultAuthTokenHeader
+	app.config.CSRFEnabled = true
+	app.config.CSRFCookieName = config.DefaultCSRFCookieName
+	app.config.CSRFHeader = config.DefaultCSRFHeader
+	app.config.FrontendFeatures = []string{"pipelines", "model-catalog=false"}
+	app.config.FrontendServiceURLs = []string{"mlflow=/mlflow"}
+
+	RegisterFrontendConfig("test", func(app *App) any { return app.Config().Port })
+	t.Cleanup(func() {
+		frontendConfigMu.Lock()
+		delete(frontendConfigValues, "test")
+		frontendConfigMu.Unlock()
+	})
+	app.config.Port = 4242
+
+	// Public: no identity header nor token
+	rr := httptest.NewRecorder()
+	app.Routes().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, PathPrefix+FrontendConfigPath, nil))
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, "public, no-cache", rr.Header().Get("Cache-Control"))
+	assert.NotEmpty(t, rr.Header().Get("ETag"))
+
+	var envelope FrontendConfigEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &envelope))
+	assert.Equal(t, models.FrontendConfig{
+		DeploymentMode:  "standalone",
+		AuthMethod:      config.AuthMethodUser,
+		AuthTokenHeader: "Authorization",
+		CSRF:            &models.CSRFConfig{CookieName: "csrf_token", HeaderName: "X-CSRF-Token"},
+		Features:        map[string]bool{"pipelines": true, "model-catalog": false},
+		Services:        map[string]string{"mlflow": "/mlflow"},
+		Extensions:      map[string]any{"test": float64(4242)},
+	}, envelope.Data)
+}
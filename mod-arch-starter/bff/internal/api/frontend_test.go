@@ -0,0 +1,80 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrontendDevServer(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	devServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ws" {
+			conn, err := upgrader.Upgrade(w, r, nil)
+			require.NoError(t, err)
+			defer conn.Close()
+			_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"hot"}`))
+			return
+		}
+		_, _ = io.WriteString(w, "dev server "+r.URL.Path)
+	}))
+	defer devServer.Close()
+
+	app := newSecurityHeadersTestApp(t)
+	app.config.DevMode = true
+	app.config.FrontendDevServerURL = devServer.URL
+	var err error
+	app.frontendDevServer, err = app.newFrontendDevServer()
+	require.NoError(t, err)
+	bff := httptest.NewServer(app.Routes())
+	defer bff.Close()
+
+	resp, err := http.Get(bff.URL + "/notebooks/lab")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "dev server /notebooks/lab", string(body))
+	assert.Empty(t, resp.Header.Get("Content-Security-Policy"))
+	assert.Equal(t, "nosniff", resp.Header.Get("X-Content-Type-Options"), "the other headers are kept")
+
+	// The APIs are still served by the BFF
+	req, _ := http.NewRequest(http.MethodGet, bff.URL+UserPath, nil)
+	req.Header.Set(constants.KubeflowUserIDHeader, "user@example.com")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "application/json")
+
+	// Hot reloading WebSocket
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(bff.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, message, err := conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"hot"}`, string(message))
+
+	devServer.Close()
+	resp, err = http.Get(bff.URL + "/")
+	require.NoError(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Contains(t, string(body), "is not reachable")
+}
+
+func TestFrontendDevServer_DevModeOnly(t *testing.T) {
+	app := newSecurityHeadersTestApp(t)
+	app.config.FrontendDevServerURL = "http://localhost:9000"
+	devServer, err := app.newFrontendDevServer()
+	require.NoError(t, err)
+	assert.Nil(t, devServer)
+}
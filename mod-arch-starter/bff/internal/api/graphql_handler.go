@@ -0,0 +1,452 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/apierrors"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/repositories"
+)
+
+// GraphQLRequest and GraphQLResponse document the bodies of GraphQLPath in the OpenAPI
+// document.
+type (
+	GraphQLRequest struct {
+		Query         string         `json:"query"`
+		OperationName string         `json:"operationName,omitempty"`
+		Variables     map[string]any `json:"variables,omitempty"`
+	}
+	GraphQLResponse struct {
+		Data   map[string]any          `json:"data,omitempty"`
+		Errors []*gqlerrors.QueryError `json:"errors,omitempty"`
+	}
+)
+
+// graphQLSDL is the schema of GraphQLPath. Its fields are resolved by the methods of
+// graphQLQuery and of the types it returns.
+var graphQLSDL = fmt.Sprintf(`schema {
+	query: Query
+}
+
+type Query {
+	user: User!
+	"The namespaces the current user can access."
+	namespaces: [Namespace!]!
+	"A namespace by name, null unless the current user can access it."
+	namespace(name: String!): Namespace
+	"Checks of up to %d permissions of the current user, in the same order."
+	permissions(checks: [PermissionInput!]!): [Permission!]!
+}
+
+"The current user."
+type User {
+	userId: String!
+	groups: [String!]!
+	clusterAdmin: Boolean!
+}
+
+"A namespace the current user can access."
+type Namespace {
+	name: String!
+	displayName: String
+	"The backend Services of the namespace, null unless the user can list them."
+	services(
+		"Label selector of the Services, instead of the configured one."
+		labelSelector: String
+	): [Service!]
+	"Whether the user may perform verb on resource in the namespace."
+	can(verb: String!, resource: String!, group: String): Boolean
+}
+
+"A backend Service discovered in a namespace."
+type Service {
+	name: String!
+	namespace: String!
+	displayName: String!
+	description: String
+	externalAddress: String
+	externalURLs: [ServiceURL!]!
+	ports: [ServicePort!]!
+	health: ServiceHealth!
+}
+
+type ServicePort {
+	name: String
+	port: Int!
+	protocol: String!
+	"In-cluster URL of the port."
+	url: String!
+}
+
+"External URL of a Service, from a Route, Ingress or HTTPRoute."
+type ServiceURL {
+	url: String!
+	"Kind of the object exposing the Service."
+	source: String!
+	"Name of the object exposing the Service."
+	name: String!
+	tls: Boolean!
+	port: String
+}
+
+type ServiceHealth {
+	"available, unavailable or unknown."
+	status: String!
+	readyEndpoints: Int!
+	totalEndpoints: Int!
+}
+
+type Permission {
+	verb: String!
+	group: String!
+	resource: String!
+	namespace: String
+	allowed: Boolean!
+	"Why the check could not be performed, when it is not allowed."
+	error: String
+}
+
+input PermissionInput {
+	verb: String!
+	resource: String!
+	group: String
+	"Namespace of the check, cluster-wide when unset or empty."
+	namespace: String
+}
+`, MaxPermissionChecks)
+
+// GraphQLHandler executes the GraphQL query of the request: the query, operationName and
+// variables of a JSON POST body, or of the query parameters of a GET. A GET without a query
+// returns the schema in SDL. Invalid queries are answered with a 400, executed ones with a 200
+// and the errors of the fields that failed.
+func (app *App) GraphQLHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var req GraphQLRequest
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		if query.Get("query") == "" {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			_, _ = w.Write([]byte(graphQLSDL))
+			return
+		}
+		req.Query, req.OperationName = query.Get("query"), query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				app.badRequestResponse(w, r, fmt.Errorf("invalid variables: %w", err))
+				return
+			}
+		}
+	} else if err := app.ReadJSON(w, r, &req); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// The namespaces are listed once per query, for the namespaces and namespace fields
+	ctx := context.WithValue(r.Context(), graphQLNamespacesKey{}, sync.OnceValues(func() ([]models.NamespaceModel, error) {
+		client, identity, err := app.graphQLClient(r.Context())
+		if err != nil {
+			return nil, err
+		}
+		return app.repositories.Namespace.GetNamespaces(client, r.Context(), identity)
+	}))
+	// The access reviews of the fields are batched, see graphQLReviews
+	ctx = context.WithValue(ctx, graphQLReviewsKey{}, app.newGraphQLReviews(r.Context()))
+	resp := app.graphqlSchema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+	status := http.StatusOK
+	if resp.Data == nil {
+		status = http.StatusBadRequest
+	}
+	if err := app.WriteJSON(w, status, resp, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// newGraphQLSchema parses graphQLSDL with the resolvers composing the user, namespace, service
+// and permission repositories, nil unless -graphql-enabled is set.
+func (app *App) newGraphQLSchema() (*graphql.Schema, error) {
+	if !app.config.GraphQLEnabled {
+		return nil, nil
+	}
+	app.graphqlReviewWait = graphQLReviewWait
+	schema, err := graphql.ParseSchema(graphQLSDL, &graphQLQuery{app: app},
+		graphql.UseStringDescriptions(),
+		graphql.MaxDepth(app.config.GraphQLMaxDepth),
+		// Resolve the fields of a list at once, so their access reviews are loaded as one batch
+		graphql.MaxParallelism(MaxPermissionChecks),
+		graphql.Logger(graphQLPanicLogger{}))
+	if err != nil {
+		return nil, fmt.Errorf("invalid GraphQL schema: %w", err)
+	}
+	return schema, nil
+}
+
+type graphQLNamespacesKey struct{}
+
+// graphQLQuery resolves the fields of the Query type.
+type graphQLQuery struct {
+	app *App
+}
+
+func (q *graphQLQuery) User(ctx context.Context) (*graphQLUser, error) {
+	client, identity, err := q.app.graphQLClient(ctx)
+	if err != nil {
+		return nil, q.app.graphQLError(ctx, err)
+	}
+	user, err := q.app.repositories.User.GetUser(client, ctx, identity)
+	if err != nil {
+		return nil, q.app.graphQLError(ctx, err)
+	}
+	return &graphQLUser{user}, nil
+}
+
+func (q *graphQLQuery) Namespaces(ctx context.Context) ([]*graphQLNamespace, error) {
+	list, err := ctx.Value(graphQLNamespacesKey{}).(func() ([]models.NamespaceModel, error))()
+	if err != nil {
+		return nil, q.app.graphQLError(ctx, err)
+	}
+	namespaces := make([]*graphQLNamespace, len(list))
+	for i, ns := range list {
+		namespaces[i] = &graphQLNamespace{app: q.app, ns: ns}
+	}
+	return namespaces, nil
+}
+
+func (q *graphQLQuery) Namespace(ctx context.Context, args struct{ Name string }) (*graphQLNamespace, error) {
+	namespaces, err := q.Namespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, ns := range namespaces {
+		if ns.ns.Name == args.Name {
+			return ns, nil
+		}
+	}
+	return nil, nil
+}
+
+type graphQLPermissionInput struct {
+	Verb, Resource   string
+	Group, Namespace *string
+}
+
+func (q *graphQLQuery) Permissions(ctx context.Context, args struct{ Checks []graphQLPermissionInput }) ([]*graphQLPermission, error) {
+	if len(args.Checks) == 0 || len(args.Checks) > MaxPermissionChecks {
+		return nil, q.app.graphQLError(ctx, apierrors.BadRequest(fmt.Sprintf("checks: must have between 1 and %d checks, got %d", MaxPermissionChecks, len(args.Checks))))
+	}
+	checks := make([]models.PermissionRequest, len(args.Checks))
+	for i, check := range args.Checks {
+		checks[i] = models.PermissionRequest{Verb: check.Verb, Group: deref(check.Group), Resource: check.Resource, Namespace: deref(check.Namespace)}
+	}
+	results, err := q.app.checkGraphQLPermissions(ctx, checks...)
+	if err != nil {
+		return nil, q.app.graphQLError(ctx, err)
+	}
+	permissions := make([]*graphQLPermission, len(results))
+	for i, result := range results {
+		permissions[i] = &graphQLPermission{result}
+	}
+	return permissions, nil
+}
+
+type graphQLUser struct{ user *models.User }
+
+func (u *graphQLUser) UserID() string     { return u.user.UserID }
+func (u *graphQLUser) Groups() []string   { return u.user.Groups }
+func (u *graphQLUser) ClusterAdmin() bool { return u.user.ClusterAdmin }
+
+type graphQLNamespace struct {
+	app *App
+	ns  models.NamespaceModel
+}
+
+func (n *graphQLNamespace) Name() string         { return n.ns.Name }
+func (n *graphQLNamespace) DisplayName() *string { return n.ns.DisplayName }
+
+// Services is null, with a 403 error, unless the user can list the Services of the namespace.
+func (n *graphQLNamespace) Services(ctx context.Context, args struct{ LabelSelector *string }) (*[]*graphQLService, error) {
+	allowed, err := n.Can(ctx, graphQLCanArgs{Verb: "list", Resource: "services"})
+	if err != nil {
+		return nil, err
+	}
+	if !*allowed {
+		return nil, n.app.graphQLError(ctx, apierrors.Forbidden(apierrors.MessageForbidden))
+	}
+
+	labelSelector := deref(args.LabelSelector)
+	if labelSelector == "" {
+		labelSelector = n.app.serviceLabelSelector()
+	}
+	selector, err := repositories.ParseServiceSelector(labelSelector, n.app.config.ServiceAnnotationSelector)
+	if err != nil {
+		return nil, n.app.graphQLError(ctx, apierrors.BadRequest(err.Error()))
+	}
+	client, identity, err := n.app.graphQLClient(ctx)
+	if err != nil {
+		return nil, n.app.graphQLError(ctx, err)
+	}
+	list, err := n.app.repositories.Service.GetServices(client, ctx, identity, n.ns.Name, selector)
+	if err != nil {
+		return nil, n.app.graphQLError(ctx, err)
+	}
+	services := make([]*graphQLService, len(list))
+	for i := range list {
+		services[i] = &graphQLService{list[i]}
+	}
+	return &services, nil
+}
+
+type graphQLCanArgs struct {
+	Verb, Resource string
+	Group          *string
+}
+
+// Can is the result of the access review of verb on resource in the namespace, failing when it
+// could not be performed.
+func (n *graphQLNamespace) Can(ctx context.Context, args graphQLCanArgs) (*bool, error) {
+	results, err := n.app.checkGraphQLPermissions(ctx, models.PermissionRequest{
+		Verb: args.Verb, Group: deref(args.Group), Resource: args.Resource, Namespace: n.ns.Name,
+	})
+	if err != nil {
+		return nil, n.app.graphQLError(ctx, err)
+	}
+	if results[0].Error != "" {
+		return nil, n.app.graphQLError(ctx, fmt.Errorf("access review failed: %s", results[0].Error))
+	}
+	return &results[0].Allowed, nil
+}
+
+type graphQLService struct{ svc models.ServiceModel }
+
+func (s *graphQLService) Name() string             { return s.svc.Name }
+func (s *graphQLService) Namespace() string        { return s.svc.Namespace }
+func (s *graphQLService) DisplayName() string      { return s.svc.DisplayName }
+func (s *graphQLService) Description() *string     { return optional(s.svc.Description) }
+func (s *graphQLService) ExternalAddress() *string { return optional(s.svc.ExternalAddress) }
+func (s *graphQLService) Health() *graphQLServiceHealth {
+	return &graphQLServiceHealth{s.svc.Health}
+}
+
+func (s *graphQLService) ExternalURLs() []*graphQLServiceURL {
+	urls := make([]*graphQLServiceURL, len(s.svc.ExternalURLs))
+	for i := range s.svc.ExternalURLs {
+		urls[i] = &graphQLServiceURL{s.svc.ExternalURLs[i]}
+	}
+	return urls
+}
+
+func (s *graphQLService) Ports() []*graphQLServicePort {
+	ports := make([]*graphQLServicePort, len(s.svc.Ports))
+	for i := range s.svc.Ports {
+		ports[i] = &graphQLServicePort{s.svc.Ports[i]}
+	}
+	return ports
+}
+
+type graphQLServicePort struct{ port models.ServicePort }
+
+func (p *graphQLServicePort) Name() *string    { return optional(p.port.Name) }
+func (p *graphQLServicePort) Port() int32      { return p.port.Port }
+func (p *graphQLServicePort) Protocol() string { return p.port.Protocol }
+func (p *graphQLServicePort) URL() string      { return p.port.URL }
+
+type graphQLServiceURL struct{ url models.ServiceURL }
+
+func (u *graphQLServiceURL) URL() string    { return u.url.URL }
+func (u *graphQLServiceURL) Source() string { return u.url.Source }
+func (u *graphQLServiceURL) Name() string   { return u.url.Name }
+func (u *graphQLServiceURL) TLS() bool      { return u.url.TLS }
+func (u *graphQLServiceURL) Port() *string  { return optional(u.url.Port) }
+
+type graphQLServiceHealth struct{ health models.ServiceHealth }
+
+func (h *graphQLServiceHealth) Status() string        { return h.health.Status }
+func (h *graphQLServiceHealth) ReadyEndpoints() int32 { return int32(h.health.ReadyEndpoints) }
+func (h *graphQLServiceHealth) TotalEndpoints() int32 { return int32(h.health.TotalEndpoints) }
+
+type graphQLPermission struct{ check models.PermissionCheck }
+
+func (p *graphQLPermission) Verb() string       { return p.check.Verb }
+func (p *graphQLPermission) Group() string      { return p.check.Group }
+func (p *graphQLPermission) Resource() string   { return p.check.Resource }
+func (p *graphQLPermission) Namespace() *string { return optional(p.check.Namespace) }
+func (p *graphQLPermission) Allowed() bool      { return p.check.Allowed }
+func (p *graphQLPermission) Error() *string     { return optional(p.check.Error) }
+
+// checkGraphQLPermissions returns the results of the access reviews of checks for the user of
+// ctx, batched with those of the other fields of the query.
+func (app *App) checkGraphQLPermissions(ctx context.Context, checks ...models.PermissionRequest) ([]models.PermissionCheck, error) {
+	return ctx.Value(graphQLReviewsKey{}).(*graphQLReviews).load(ctx, checks...)
+}
+
+// graphQLClient returns the Kubernetes client and identity of the request of ctx.
+func (app *App) graphQLClient(ctx context.Context) (kubernetes.KubernetesClientInterface, *kubernetes.RequestIdentity, error) {
+	identity, ok := ctx.Value(constants.RequestIdentityKey).(*kubernetes.RequestIdentity)
+	if !ok || identity == nil {
+		return nil, nil, apierrors.BadRequest("missing RequestIdentity in context")
+	}
+	client, err := app.kubernetesClientFactory.GetClient(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get Kubernetes client: %w", err)
+	}
+	return client, identity, nil
+}
+
+// graphQLFieldError is the error of a field, presented like apiErrorResponse presents the
+// errors of handlers: the status code is the code extension, and server-side failures are
+// logged but never sent.
+type graphQLFieldError struct {
+	apiErr *apierrors.Error
+	err    error
+}
+
+func (app *App) graphQLError(ctx context.Context, err error) error {
+	apiErr := apierrors.FromError(err)
+	if apiErr.StatusCode >= http.StatusInternalServerError {
+		logger.FromContext(ctx).Error("GraphQL field failed", "error", err)
+	}
+	return &graphQLFieldError{apiErr: apiErr, err: err}
+}
+
+func (e *graphQLFieldError) Error() string { return e.apiErr.Message }
+func (e *graphQLFieldError) Unwrap() error { return e.err }
+
+func (e *graphQLFieldError) Extensions() map[string]any {
+	extensions := map[string]any{"code": strconv.Itoa(e.apiErr.StatusCode)}
+	if len(e.apiErr.Details) > 0 {
+		extensions["details"] = e.apiErr.Details
+	}
+	return extensions
+}
+
+// graphQLPanicLogger logs the panics of resolvers, which fail their field, with the BFF logger.
+type graphQLPanicLogger struct{}
+
+func (graphQLPanicLogger) LogPanic(ctx context.Context, value any) {
+	logger.FromContext(ctx).Error("GraphQL resolver panicked", "panic", value)
+}
+
+// optional is s, nil when empty, for the nullable String fields.
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// deref is *s, empty when nil, for the nullable String arguments.
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
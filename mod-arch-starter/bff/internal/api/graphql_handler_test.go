@@ -0,0 +1,177 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphQLHandler(t *testing.T) {
+	app := newWatchTestApp(t)
+	app.config.GraphQLEnabled, app.config.GraphQLMaxDepth = true, 10
+	var err error
+	app.graphqlSchema, err = app.newGraphQLSchema()
+	require.NoError(t, err)
+	routes := app.Routes()
+	serve := func(user string, req *http.Request) *httptest.ResponseRecorder {
+		req.Header.Set(constants.KubeflowUserIDHeader, user)
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, req)
+		return rr
+	}
+	post := func(user, query string) *httptest.ResponseRecorder {
+		body, err := json.Marshal(GraphQLRequest{Query: query})
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, GraphQLPath, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		return serve(user, req)
+	}
+
+	rr := post("doraNonAdmin@example.com", `{
+		user { userId clusterAdmin }
+		namespaces {
+			name
+			canCreate: can(verb: "create", resource: "secrets")
+			canDelete: can(verb: "delete", resource: "deployments", group: "apps")
+			services { name }
+		}
+		other: namespace(name: "bella-namespace") { name }
+		permissions(checks: [{verb: "list", resource: "namespaces"}]) { verb resource allowed }
+	}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.JSONEq(t, `{"data": {
+		"user": {"userId": "doraNonAdmin@example.com", "clusterAdmin": false},
+		"namespaces": [{"name": "dora-namespace", "canCreate": true, "canDelete": true, "services": [{"name": "mod-arch-dora"}]}],
+		"other": null,
+		"permissions": [{"verb": "list", "resource": "namespaces", "allowed": false}]
+	}}`, rr.Body.String())
+
+	rr = post("user@example.com", `{ namespace(name: "kubeflow") { services(labelSelector: "a in (") { name } } }`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var resp struct {
+		Data   map[string]any       `json:"data"`
+		Errors []*errors.QueryError `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, map[string]any{"namespace": map[string]any{"services": nil}}, resp.Data)
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "400", resp.Errors[0].Extensions["code"])
+	assert.Equal(t, []any{"namespace", "services"}, resp.Errors[0].Path)
+
+	query := url.Values{
+		"query":     {`query($name: String!) { namespace(name: $name) { name } }`},
+		"variables": {`{"name": "kubeflow"}`},
+	}
+	rr = serve("user@example.com", httptest.NewRequest(http.MethodGet, GraphQLPath+"?"+query.Encode(), nil))
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.JSONEq(t, `{"data": {"namespace": {"name": "kubeflow"}}}`, rr.Body.String())
+
+	rr = serve("user@example.com", httptest.NewRequest(http.MethodGet, GraphQLPath, nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/plain; charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.Contains(t, rr.Body.String(), "permissions(checks: [PermissionInput!]!): [Permission!]!")
+
+	rr = post("user@example.com", `{ user { unknown } }`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `Cannot query field \"unknown\" on type \"User\".`)
+	assert.NotContains(t, rr.Body.String(), `"data"`)
+}
+
+// sarCountingFactory counts the clients of its factory, and their access reviews.
+type sarCountingFactory struct {
+	kubernetes.KubernetesClientFactory
+	clients, reviews *atomic.Int32
+}
+
+func (f sarCountingFactory) GetClient(ctx context.Context) (kubernetes.KubernetesClientInterface, error) {
+	f.clients.Add(1)
+	client, err := f.KubernetesClientFactory.GetClient(ctx)
+	return sarCountingClient{client, f.reviews}, err
+}
+
+type sarCountingClient struct {
+	kubernetes.KubernetesClientInterface
+	reviews *atomic.Int32
+}
+
+func (c sarCountingClient) CanAccess(ctx context.Context, identity *kubernetes.RequestIdentity, verb, group, resource, namespace string) (bool, error) {
+	c.reviews.Add(1)
+	return c.KubernetesClientInterface.CanAccess(ctx, identity, verb, group, resource, namespace)
+}
+
+func TestGraphQLHandler_BatchesReviews(t *testing.T) {
+	app := newWatchTestApp(t)
+	app.config.GraphQLEnabled, app.config.GraphQLMaxDepth = true, 10
+	var clients, reviews atomic.Int32
+	app.kubernetesClientFactory = sarCountingFactory{app.kubernetesClientFactory, &clients, &reviews}
+	var err error
+	app.graphqlSchema, err = app.newGraphQLSchema()
+	require.NoError(t, err)
+	// Wide enough for a loaded test runner to request the reviews of every namespace
+	app.graphqlReviewWait = 100 * time.Millisecond
+	routes := app.Routes()
+	post := func(query string) *httptest.ResponseRecorder {
+		body, err := json.Marshal(GraphQLRequest{Query: query})
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, GraphQLPath, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(constants.KubeflowUserIDHeader, "user@example.com")
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := post(`{ namespaces { name } }`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	listClients := clients.Load()
+
+	clients.Store(0)
+	rr = post(`{ namespaces {
+		name
+		a: can(verb: "create", resource: "secrets")
+		b: can(verb: "create", resource: "secrets")
+		c: can(verb: "list", resource: "services")
+	} }`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var resp struct {
+		Data struct {
+			Namespaces []map[string]any `json:"namespaces"`
+		} `json:"data"`
+		Errors []*errors.QueryError `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Empty(t, resp.Errors)
+	namespaces := len(resp.Data.Namespaces)
+	require.Greater(t, namespaces, 1)
+	assert.Equal(t, int32(2*namespaces), reviews.Load(), "the two distinct checks of each namespace, reviewed once")
+	assert.Equal(t, listClients+1, clients.Load(), "in one batch")
+
+	// The reviews are bounded per query, not per field
+	checks := func(from, to int) string {
+		var list []string
+		for i := from; i < to; i++ {
+			list = append(list, fmt.Sprintf(`{verb: "get", resource: "configmaps", namespace: "ns-%d"}`, i))
+		}
+		return "[" + strings.Join(list, ",") + "]"
+	}
+	reviews.Store(0)
+	rr = post(fmt.Sprintf(`{ a: permissions(checks: %s) { allowed } b: permissions(checks: %s) { allowed } }`,
+		checks(0, MaxPermissionChecks), checks(MaxPermissionChecks, MaxPermissionChecks+10)))
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Contains(t, rr.Body.String(), fmt.Sprintf("the query checks more than %d permissions", MaxPermissionChecks))
+	assert.LessOrEqual(t, reviews.Load(), int32(MaxPermissionChecks))
+}
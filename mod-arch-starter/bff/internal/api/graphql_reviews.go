@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/apierrors"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+)
+
+// graphQLReviewWait is how long the access reviews requested by the resolvers of a GraphQL query
+// are gathered before they are run as one batch: long enough for the fields resolved in parallel,
+// like the can and services fields of every namespace of a list, to request theirs.
+const graphQLReviewWait = 2 * time.Millisecond
+
+type graphQLReviewsKey struct{}
+
+// graphQLReviews is the loader of the access reviews of a GraphQL query. The reviews requested
+// within graphQLReviewWait of each other run as one Permission.CheckPermissions batch, each
+// distinct check is reviewed once per query, and a query reviews at most MaxPermissionChecks
+// checks, however its fields and aliases request them.
+type graphQLReviews struct {
+	ctx   context.Context
+	fetch func(ctx context.Context, checks []models.PermissionRequest) ([]models.PermissionCheck, error)
+	wait  time.Duration
+	max   int
+
+	mu      sync.Mutex
+	reviews map[models.PermissionRequest]*graphQLReview
+	pending []models.PermissionRequest
+}
+
+type graphQLReview struct {
+	done   chan struct{}
+	result models.PermissionCheck
+	err    error
+}
+
+func (app *App) newGraphQLReviews(ctx context.Context) *graphQLReviews {
+	return &graphQLReviews{
+		ctx: ctx,
+		fetch: func(ctx context.Context, checks []models.PermissionRequest) ([]models.PermissionCheck, error) {
+			client, identity, err := app.graphQLClient(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return app.repositories.Permission.CheckPermissions(client, ctx, identity, checks)
+		},
+		wait:    app.graphqlReviewWait,
+		max:     MaxPermissionChecks,
+		reviews: map[models.PermissionRequest]*graphQLReview{},
+	}
+}
+
+// load returns the results of checks, in the same order, once the batch reviewing them ran.
+func (l *graphQLReviews) load(ctx context.Context, checks ...models.PermissionRequest) ([]models.PermissionCheck, error) {
+	l.mu.Lock()
+	reviews := make([]*graphQLReview, len(checks))
+	for i, check := range checks {
+		review, ok := l.reviews[check]
+		if !ok {
+			if len(l.reviews) >= l.max {
+				l.mu.Unlock()
+				return nil, apierrors.BadRequest(fmt.Sprintf("the query checks more than %d permissions", l.max))
+			}
+			review = &graphQLReview{done: make(chan struct{})}
+			l.reviews[check] = review
+			if len(l.pending) == 0 {
+				time.AfterFunc(l.wait, l.dispatch)
+			}
+			l.pending = append(l.pending, check)
+		}
+		reviews[i] = review
+	}
+	l.mu.Unlock()
+
+	results := make([]models.PermissionCheck, len(checks))
+	for i, review := range reviews {
+		select {
+		case <-review.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if review.err != nil {
+			return nil, review.err
+		}
+		results[i] = review.result
+	}
+	return results, nil
+}
+
+// dispatch reviews the pending checks as one batch.
+func (l *graphQLReviews) dispatch() {
+	l.mu.Lock()
+	checks := l.pending
+	l.pending = nil
+	reviews := make([]*graphQLReview, len(checks))
+	for i, check := range checks {
+		reviews[i] = l.reviews[check]
+	}
+	l.mu.Unlock()
+
+	results, err := l.fetch(l.ctx, checks)
+	for i, review := range reviews {
+		if err != nil {
+			review.err = err
+		} else {
+			review.result = results[i]
+		}
+		close(review.done)
+	}
+}
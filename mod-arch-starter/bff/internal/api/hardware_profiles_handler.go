@@ -0,0 +1,51 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/odhdashboard"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+)
+
+type HardwareProfilesEnvelope Envelope[models.HardwareProfilesModel, None]
+
+// GetHardwareProfilesHandler returns the hardware and accelerator profiles of the platform and
+// the accelerators of the nodes, for the resource pickers of the UI.
+func (app *App) GetHardwareProfilesHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	ctx := r.Context()
+	identity, ok := ctx.Value(constants.RequestIdentityKey).(*kubernetes.RequestIdentity)
+	if !ok || identity == nil {
+		app.badRequestResponse(w, r, fmt.Errorf("missing RequestIdentity in context"))
+		return
+	}
+
+	client, err := app.kubernetesClientFactory.GetClient(ctx)
+	if err != nil {
+		app.serverErrorResponse(w, r, fmt.Errorf("failed to get Kubernetes client: %w", err))
+		return
+	}
+
+	profiles, err := app.repositories.Hardware.GetHardwareProfiles(client, ctx, identity, app.odhDashboardClient(client, identity))
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.WriteJSON(w, http.StatusOK, HardwareProfilesEnvelope{Data: profiles}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// odhDashboardClient returns the client of the dashboard settings read by client on behalf of
+// identity, cached in the dashboard cache.
+func (app *App) odhDashboardClient(client kubernetes.KubernetesClientInterface, identity *kubernetes.RequestIdentity) *odhdashboard.Client {
+	return odhdashboard.NewClient(client, odhdashboard.Options{
+		Namespace: app.config.ODHDashboardNamespace,
+		Cache:     app.dashboardCache,
+		Identity:  identity,
+	})
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/odhdashboard"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestGetHardwareProfilesHandler(t *testing.T) {
+	app := newWatchTestApp(t)
+	app.config.ODHDashboardNamespace = config.DefaultODHDashboardNamespace
+	ctx := context.WithValue(context.Background(), constants.RequestIdentityKey, &kubernetes.RequestIdentity{UserID: "user@example.com"})
+	client, err := app.kubernetesClientFactory.GetClient(ctx)
+	require.NoError(t, err)
+	profiles, err := client.DynamicResource(odhdashboard.AcceleratorProfilesGVR)
+	require.NoError(t, err)
+	_, err = profiles.Namespace(config.DefaultODHDashboardNamespace).Create(ctx, &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "dashboard.opendatahub.io/v1", "kind": "AcceleratorProfile",
+		"metadata": map[string]any{"name": "nvidia", "namespace": config.DefaultODHDashboardNamespace},
+		"spec":     map[string]any{"displayName": "NVIDIA GPU", "identifier": "nvidia.com/gpu", "enabled": true},
+	}}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		user         string
+		accelerators bool
+	}{
+		{user: "user@example.com", accelerators: true},
+		{user: "doraNonAdmin@example.com"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, HardwareProfilesPath, nil)
+		req.Header.Set(constants.KubeflowUserIDHeader, tt.user)
+		rr := httptest.NewRecorder()
+		app.Routes().ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		var envelope HardwareProfilesEnvelope
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &envelope))
+		require.Len(t, envelope.Data.Profiles, 1, tt.user)
+		assert.Equal(t, models.HardwareProfileSourceAcceleratorProfile, envelope.Data.Profiles[0].Source)
+		assert.Equal(t, tt.accelerators, envelope.Data.Accelerators != nil, tt.user)
+	}
+}
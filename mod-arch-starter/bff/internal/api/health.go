@@ -0,0 +1,91 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/healthcheck"
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+)
+
+const (
+	LivezPath   = "/livez"
+	ReadyzPath  = "/readyz"
+	HealthzPath = "/healthz"
+)
+
+// HealthCheckFactory builds a health check once the App exists, so the check can use app
+// dependencies such as the BFF client factory.
+type HealthCheckFactory func(app *App) healthcheck.Check
+
+var (
+	healthCheckMu        sync.RWMutex
+	healthCheckFactories []HealthCheckFactory
+)
+
+// RegisterHealthCheck registers a check served on /readyz (or /livez, depending on its Scope)
+// and /healthz. This should be called from an init() function in the downstream code.
+//
+// Example usage in downstream code:
+//
+//	func init() {
+//	    api.RegisterHealthCheck(func(app *api.App) healthcheck.Check {
+//	        return healthcheck.Check{
+//	            Name:     "model-registry",
+//	            Func:     healthcheck.HTTPGet(nil, "http://model-registry.kubeflow.svc:8080/readyz"),
+//	            Optional: true,
+//	        }
+//	    })
+//	}
+func RegisterHealthCheck(factory HealthCheckFactory) { //nolint:unused
+	healthCheckMu.Lock()
+	defer healthCheckMu.Unlock()
+	healthCheckFactories = append(healthCheckFactories, factory)
+}
+
+// newHealthChecks registers the built-in checks (ping, shutdown, Kubernetes API reachability and,
+// when the informer cache is used, its sync state) followed by the downstream and module ones.
+func (app *App) newHealthChecks() (*healthcheck.Registry, error) {
+	registry := healthcheck.NewRegistry()
+	checks := []healthcheck.Check{healthcheck.Ping(), app.drainingCheck()}
+
+	if checker, ok := app.kubernetesClientFactory.(k8s.APIServerChecker); ok {
+		checks = append(checks, healthcheck.Check{Name: "kubernetes", Func: checker.CheckAPIServer})
+	}
+	if checker, ok := app.kubernetesClientFactory.(k8s.CacheSyncChecker); ok &&
+		len(app.config.CacheResources) > 0 && app.config.AuthMethod == config.AuthMethodInternal {
+		checks = append(checks, healthcheck.Check{Name: "informer-cache", Func: checker.CheckCacheSynced})
+	}
+
+	if app.database != nil {
+		checks = append(checks, app.databaseCheck())
+	}
+	if len(app.config.WarmupNamespaces) > 0 {
+		checks = append(checks, app.warmupCheck())
+	}
+
+	healthCheckMu.RLock()
+	for _, factory := range healthCheckFactories {
+		checks = append(checks, factory(app))
+	}
+	healthCheckMu.RUnlock()
+	checks = append(checks, app.moduleHealthChecks()...)
+
+	for _, check := range checks {
+		if err := registry.Register(check); err != nil {
+			return nil, fmt.Errorf("failed to register health check: %w", err)
+		}
+	}
+	return registry, nil
+}
+
+// healthHandlers maps the health endpoints to their handlers.
+func (app *App) healthHandlers() map[string]http.Handler {
+	return map[string]http.Handler{
+		LivezPath:   app.healthChecks.Handler(healthcheck.Liveness),
+		ReadyzPath:  app.healthChecks.Handler(healthcheck.Readiness),
+		HealthzPath: app.healthChecks.Handler(0),
+	}
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/healthcheck"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthEndpoints(t *testing.T) {
+	RegisterHealthCheck(func(app *App) healthcheck.Check {
+		return healthcheck.Check{Name: "upstream", Func: func(context.Context) error { return errors.New("unreachable") }}
+	})
+	t.Cleanup(func() {
+		healthCheckMu.Lock()
+		defer healthCheckMu.Unlock()
+		healthCheckFactories = nil
+	})
+
+	app := newWatchTestApp(t)
+	var err error
+	app.healthChecks, err = app.newHealthChecks()
+	require.NoError(t, err)
+	routes := app.Routes()
+
+	tests := map[string]int{
+		LivezPath:                        http.StatusOK,
+		ReadyzPath:                       http.StatusServiceUnavailable,
+		HealthzPath:                      http.StatusServiceUnavailable,
+		ReadyzPath + "?exclude=upstream": http.StatusOK,
+	}
+	for path, want := range tests {
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, want, rr.Code, path)
+		assert.Contains(t, rr.Body.String(), `"name":"ping"`, path)
+	}
+}
@@ -7,6 +7,10 @@ import (
 	"io"
 	"net/http"
 	"strings"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/apierrors"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/pagination"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/validation"
 )
 
 type Envelope[D any, M any] struct {
@@ -16,8 +20,19 @@ type Envelope[D any, M any] struct {
 
 type None *struct{}
 
+// PagedResponse is the envelope of a list response: a page of items, with the total count and
+// the token of the next page in its metadata (see pagination.ParseListOptions).
+type PagedResponse[T any] Envelope[[]T, pagination.Metadata]
+
+// WriteJSON writes data as the JSON response, without the fields the user may not read (see
+// FilterFields).
 func (app *App) WriteJSON(w http.ResponseWriter, status int, data any, headers http.Header) error {
 
+	data, err := app.filterFields(w, data)
+	if err != nil {
+		return err
+	}
+
 	js, err := json.MarshalIndent(data, "", "\t")
 
 	if err != nil {
@@ -95,6 +110,17 @@ func (app *App) ReadJSON(w http.ResponseWriter, r *http.Request, dst any) error
 	return nil
 }
 
+// ReadValidJSON decodes the body of r into dst like ReadJSON, then checks it against the
+// validate tags of its type (see package validation). Answer its error with apiErrorResponse:
+// it is a 400, and a validation failure lists the invalid fields in the "fields" detail of the
+// envelope.
+func (app *App) ReadValidJSON(w http.ResponseWriter, r *http.Request, dst any) error {
+	if err := app.ReadJSON(w, r, dst); err != nil {
+		return apierrors.BadRequest(err.Error())
+	}
+	return validation.Validate(dst)
+}
+
 func ParseURLTemplate(tmpl string, params map[string]string) string {
 	args := make([]string, len(params)*2)
 
@@ -0,0 +1,227 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/apierrors"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/cache"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/metrics"
+)
+
+const (
+	// maxIdempotencyKeyLength bounds the Idempotency-Key header, which is part of the storage key.
+	maxIdempotencyKeyLength = 255
+	// maxIdempotentBodySize bounds the request and response bodies of idempotent requests, like
+	// ReadJSON bounds the bodies of handlers; larger requests are not deduplicated.
+	maxIdempotentBodySize = 1_048_576
+)
+
+// replayedHeaders are the response headers replayed with the body of a stored response.
+var replayedHeaders = []string{"Content-Type", "Location", "ETag", "Last-Modified"}
+
+// IdempotencyBackendFactory builds the storage of the responses of the idempotent requests
+// once the App exists, e.g. on a Redis shared by the replicas.
+type IdempotencyBackendFactory func(app *App) (cache.Backend, error)
+
+var (
+	idempotencyBackendMu       sync.RWMutex
+	idempotencyBackendOverride IdempotencyBackendFactory
+)
+
+// RegisterIdempotencyBackend replaces the in-memory storage of the responses of the requests
+// carrying an Idempotency-Key, so retries reaching another replica are deduplicated too. This
+// should be called from an init() function in the downstream code.
+//
+// Example usage in downstream code:
+//
+//	func init() {
+//	    api.RegisterIdempotencyBackend(func(app *api.App) (cache.Backend, error) {
+//	        return newRedisBackend(os.Getenv("REDIS_URL"))
+//	    })
+//	}
+func RegisterIdempotencyBackend(factory IdempotencyBackendFactory) { //nolint:unused
+	idempotencyBackendMu.Lock()
+	defer idempotencyBackendMu.Unlock()
+	idempotencyBackendOverride = factory
+}
+
+// idempotency stores the responses of the requests carrying an Idempotency-Key.
+type idempotency struct {
+	backend cache.Backend
+	// inFlight holds the storage keys of the requests being answered
+	inFlight sync.Map
+}
+
+// storedResponse is the response of an idempotent request, and the fingerprint of the request
+// it answered.
+type storedResponse struct {
+	Fingerprint string      `json:"fingerprint"`
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+}
+
+// newIdempotency creates the storage of the idempotent requests, on the registered backend
+// or in memory; nil when -idempotency-key-ttl is 0.
+func (app *App) newIdempotency() (*idempotency, error) {
+	if app.config.IdempotencyKeyTTL <= 0 {
+		return nil, nil
+	}
+	idempotencyBackendMu.RLock()
+	factory := idempotencyBackendOverride
+	idempotencyBackendMu.RUnlock()
+
+	if factory == nil {
+		return &idempotency{backend: cache.NewMemoryBackend(app.config.IdempotencyMaxEntries)}, nil
+	}
+	app.logger.Info("applying idempotency backend override")
+	backend, err := factory(app)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the idempotency backend: %w", err)
+	}
+	return &idempotency{backend: backend}, nil
+}
+
+// HonorIdempotencyKeys deduplicates the retries of the POST and PUT requests carrying an
+// Idempotency-Key header: the first response of a user to a key is stored for
+// -idempotency-key-ttl and replayed, with an Idempotent-Replayed header, to the later requests
+// of the user with that key. A key reused for a different request (method, URL or body) is
+// rejected with a 422, and a retry arriving while the first request is still answered with a
+// 409. Server errors, timeouts and rate-limited responses are not stored, so they can be
+// retried; neither are the streaming routes, bodies over 1 MiB and the responses marked
+// Cache-Control: no-store, such as minted tokens. It runs after
+// InjectRequestIdentity; route names the matched route.
+func (app *App) HonorIdempotencyKeys(route metrics.RouteFunc, next http.Handler) http.Handler {
+	if app.idempotency == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(constants.IdempotencyKeyHeader)
+		identity, _ := r.Context().Value(constants.RequestIdentityKey).(*k8s.RequestIdentity)
+		pattern := route(r)
+		if key == "" || r.Method != http.MethodPost && r.Method != http.MethodPut ||
+			rateLimitKey(identity) == "" || pattern == "" || streamingRoutes[r.Method+" "+pattern] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength || !printableASCII(key) {
+			app.badRequestResponse(w, r, fmt.Errorf("invalid %s header: must be up to %d printable ASCII characters", constants.IdempotencyKeyHeader, maxIdempotencyKeyLength))
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxIdempotentBodySize+1))
+		if err != nil {
+			app.badRequestResponse(w, r, fmt.Errorf("failed to read the request body: %w", err))
+			return
+		}
+		if len(body) > maxIdempotentBodySize {
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+			next.ServeHTTP(w, r)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		sum := sha256.Sum256(append([]byte(r.Method+" "+r.URL.RequestURI()+"\n"), body...))
+		fingerprint := hex.EncodeToString(sum[:])
+
+		ctx := r.Context()
+		storageKey := "idempotency|" + rateLimitKey(identity) + "|" + key
+		if _, running := app.idempotency.inFlight.LoadOrStore(storageKey, struct{}{}); running {
+			app.apiErrorResponse(w, r, apierrors.Conflict(fmt.Sprintf("a request with this %s is still being processed", constants.IdempotencyKeyHeader)))
+			return
+		}
+		defer app.idempotency.inFlight.Delete(storageKey)
+
+		if data, ok, err := app.idempotency.backend.Get(ctx, storageKey); err != nil {
+			app.logger.Warn("idempotency backend read failed", "error", err)
+		} else if ok {
+			var stored storedResponse
+			if err := json.Unmarshal(data, &stored); err == nil {
+				if stored.Fingerprint != fingerprint {
+					app.apiErrorResponse(w, r, apierrors.New(http.StatusUnprocessableEntity,
+						fmt.Sprintf("the %s was already used for another request", constants.IdempotencyKeyHeader)))
+					return
+				}
+				for name, values := range stored.Header {
+					w.Header()[name] = values
+				}
+				w.Header().Set(constants.IdempotentReplayedHeader, "true")
+				w.WriteHeader(stored.Status)
+				_, _ = w.Write(stored.Body)
+				return
+			}
+		}
+
+		recorder := &responseRecorder{statusRecorder: statusRecorder{ResponseWriter: w}}
+		next.ServeHTTP(recorder, r)
+		status := recorder.status()
+		if recorder.overflow || status >= http.StatusInternalServerError ||
+			status == http.StatusTooManyRequests || status == http.StatusRequestTimeout ||
+			strings.Contains(w.Header().Get("Cache-Control"), "no-store") {
+			return
+		}
+		stored := storedResponse{Fingerprint: fingerprint, Status: status, Header: http.Header{}, Body: recorder.body.Bytes()}
+		for _, name := range replayedHeaders {
+			if values := w.Header().Values(name); len(values) > 0 {
+				stored.Header[name] = values
+			}
+		}
+		data, err := json.Marshal(stored)
+		if err != nil {
+			return
+		}
+		if err := app.idempotency.backend.Set(ctx, storageKey, data, app.config.IdempotencyKeyTTL, nil); err != nil {
+			app.logger.Warn("idempotency backend write failed", "error", err)
+		}
+	})
+}
+
+func printableASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < ' ' || s[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// responseRecorder keeps the status and body of a response as the handler writes them, up to
+// maxIdempotentBodySize.
+type responseRecorder struct {
+	statusRecorder
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	n, err := w.statusRecorder.Write(b)
+	if !w.overflow {
+		if w.body.Len()+n > maxIdempotentBodySize {
+			w.overflow = true
+			w.body.Reset()
+		} else {
+			w.body.Write(b[:n])
+		}
+	}
+	return n, err
+}
+
+// Flush marks the response as a stream, which is not stored.
+func (w *responseRecorder) Flush() {
+	w.overflow = true
+	w.body.Reset()
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHonorIdempotencyKeys(t *testing.T) {
+	app := newWatchTestApp(t)
+	app.config.IdempotencyKeyTTL, app.config.IdempotencyMaxEntries = time.Hour, 10
+	var err error
+	app.idempotency, err = app.newIdempotency()
+	require.NoError(t, err)
+	routes := app.Routes()
+	create := func(user, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, SecretsPath+"?namespace=dora-namespace", strings.NewReader(body))
+		req.Header.Set(constants.KubeflowUserIDHeader, user)
+		if key != "" {
+			req.Header.Set(constants.IdempotencyKeyHeader, key)
+		}
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, req)
+		return rr
+	}
+	const body = `{"data":{"name":"db","data":{"password":"czNjcjN0"}}}`
+
+	first := create("doraNonAdmin@example.com", "create-db", body)
+	require.Equal(t, http.StatusCreated, first.Code, first.Body.String())
+	assert.Empty(t, first.Header().Get(constants.IdempotentReplayedHeader))
+
+	retry := create("doraNonAdmin@example.com", "create-db", body)
+	assert.Equal(t, http.StatusCreated, retry.Code, "the retry is replayed instead of conflicting")
+	assert.Equal(t, "true", retry.Header().Get(constants.IdempotentReplayedHeader))
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, first.Header().Get("Content-Type"), retry.Header().Get("Content-Type"))
+
+	reused := create("doraNonAdmin@example.com", "create-db", `{"data":{"name":"other","data":{}}}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, reused.Code)
+
+	assert.Equal(t, http.StatusConflict, create("doraNonAdmin@example.com", "", body).Code, "requests without a key are not deduplicated")
+	assert.Equal(t, http.StatusConflict, create("doraNonAdmin@example.com", "create-db-again", body).Code)
+	assert.Equal(t, http.StatusConflict, create("doraNonAdmin@example.com", "create-db-again", body).Code, "conflicts are replayed")
+	assert.Equal(t, http.StatusForbidden, create("bellaNonAdmin@example.com", "create-db", body).Code, "keys are per user")
+	assert.Equal(t, http.StatusBadRequest, create("doraNonAdmin@example.com", "bad\x7fkey", body).Code)
+}
+
+func TestHonorIdempotencyKeys_InFlight(t *testing.T) {
+	app := newWatchTestApp(t)
+	app.config.IdempotencyKeyTTL, app.config.IdempotencyMaxEntries = time.Hour, 10
+	var err error
+	app.idempotency, err = app.newIdempotency()
+	require.NoError(t, err)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	handler := app.InjectRequestIdentity(app.HonorIdempotencyKeys(func(*http.Request) string { return SecretsPath }, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	})))
+	serve := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, SecretsPath, strings.NewReader("{}"))
+		req.Header.Set(constants.KubeflowUserIDHeader, "doraNonAdmin@example.com")
+		req.Header.Set(constants.IdempotencyKeyHeader, "k")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- serve() }()
+	<-started
+	assert.Equal(t, http.StatusConflict, serve().Code)
+	close(release)
+	assert.Equal(t, http.StatusCreated, (<-done).Code)
+	assert.Equal(t, http.StatusCreated, serve().Code)
+}
@@ -0,0 +1,442 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/oidc"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/session"
+)
+
+// OpenShift OAuth proxy headers.
+const (
+	ForwardedUserHeader        = "X-Forwarded-User"
+	ForwardedEmailHeader       = "X-Forwarded-Email"
+	ForwardedGroupsHeader      = "X-Forwarded-Groups"
+	ForwardedAccessTokenHeader = "X-Forwarded-Access-Token"
+)
+
+// ErrUnauthenticated is returned (wrapped) by an IdentityExtractor when the request carries
+// credentials that are invalid, e.g. an expired or forged token. The middleware responds
+// with 401 Unauthorized instead of 400 Bad Request.
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// ErrIdentityNotFound is returned by an IdentityExtractor when the request does not carry
+// the credentials it looks for. ChainIdentityExtractors uses it to try the next extractor.
+var ErrIdentityNotFound = errors.New("no request identity found")
+
+// IdentityExtractor resolves the RequestIdentity of an incoming request.
+// It is used by the InjectRequestIdentity middleware for every authenticated route.
+type IdentityExtractor interface {
+	ExtractIdentity(r *http.Request) (*k8s.RequestIdentity, error)
+}
+
+// IdentityExtractorFunc adapts a plain function to the IdentityExtractor interface.
+type IdentityExtractorFunc func(r *http.Request) (*k8s.RequestIdentity, error)
+
+func (f IdentityExtractorFunc) ExtractIdentity(r *http.Request) (*k8s.RequestIdentity, error) {
+	return f(r)
+}
+
+// IdentityExtractorFactory builds an IdentityExtractor once the App is initialized,
+// so the extractor can depend on configuration or other App dependencies.
+type IdentityExtractorFactory func(app *App) IdentityExtractor
+
+var (
+	identityExtractorMu       sync.RWMutex
+	identityExtractorOverride IdentityExtractorFactory
+)
+
+// RegisterIdentityExtractor replaces the default identity extraction used by the
+// InjectRequestIdentity middleware. This should be called from an init() function in the
+// downstream code, in the same way as RegisterHandlerOverride.
+//
+// Example usage in downstream code:
+//
+//	func init() {
+//	    api.RegisterIdentityExtractor(func(app *api.App) api.IdentityExtractor {
+//	        return api.ChainIdentityExtractors(
+//	            api.OAuthProxyIdentityExtractor{},
+//	            api.DefaultIdentityExtractor(app),
+//	        )
+//	    })
+//	}
+func RegisterIdentityExtractor(factory IdentityExtractorFactory) { //nolint:unused
+	identityExtractorMu.Lock()
+	defer identityExtractorMu.Unlock()
+	identityExtractorOverride = factory
+}
+
+func getIdentityExtractorOverride() IdentityExtractorFactory {
+	identityExtractorMu.RLock()
+	defer identityExtractorMu.RUnlock()
+	return identityExtractorOverride
+}
+
+// DefaultIdentityExtractor returns the extractor that delegates to the Kubernetes client
+// factory selected by the configured auth method.
+func DefaultIdentityExtractor(app *App) IdentityExtractor {
+	return IdentityExtractorFunc(func(r *http.Request) (*k8s.RequestIdentity, error) {
+		return app.kubernetesClientFactory.ExtractRequestIdentity(r.Header)
+	})
+}
+
+// newIdentityExtractor returns the registered identity extractor, the OIDC extractor when an
+// issuer is configured, or the default one. With -session-enabled, the tokens of sessions are
+// tried first, and with -tls-client-cert-identity, verified client certificates before them.
+// With -guest-mode, the requests without credentials are served as the guest.
+func (app *App) newIdentityExtractor() (IdentityExtractor, error) {
+	if factory := getIdentityExtractorOverride(); factory != nil {
+		app.logger.Info("applying identity extractor override")
+		return factory(app), nil
+	}
+
+	extractor, err := app.newCredentialsIdentityExtractor()
+	if err != nil {
+		return nil, err
+	}
+	if app.sessions != nil {
+		extractor = ChainIdentityExtractors(SessionIdentityExtractor{
+			Next:   extractor,
+			Header: app.config.AuthTokenHeader,
+			Prefix: app.config.AuthTokenPrefix,
+		}, extractor)
+	}
+	if app.config.TLSClientCertIdentity {
+		app.logger.Info("authenticating requests with verified client certificates")
+		extractor = ChainIdentityExtractors(ClientCertificateIdentityExtractor{}, extractor)
+	}
+	if app.config.GuestMode {
+		app.logger.Info("serving the requests without credentials as a read-only guest", "user", app.config.GuestUser, "groups", app.config.GuestGroups)
+		extractor = GuestIdentityExtractor{
+			Next:     extractor,
+			Identity: k8s.RequestIdentity{UserID: app.config.GuestUser, Groups: app.config.GuestGroups},
+			CredentialHeaders: []string{
+				app.config.AuthTokenHeader, constants.KubeflowUserIDHeader, app.config.UserIDHeader, ForwardedUserHeader, ForwardedAccessTokenHeader,
+			},
+		}
+	}
+	return extractor, nil
+}
+
+// newCredentialsIdentityExtractor returns the OIDC extractor when an issuer is configured, or
+// the default one.
+func (app *App) newCredentialsIdentityExtractor() (IdentityExtractor, error) {
+
+	if app.config.OIDCIssuerURL != "" {
+		verifier, err := oidc.NewVerifier(oidc.Config{
+			IssuerURL:     app.config.OIDCIssuerURL,
+			ClientID:      app.config.OIDCClientID,
+			UsernameClaim: app.config.OIDCUsernameClaim,
+			GroupsClaim:   app.config.OIDCGroupsClaim,
+			HTTPClient: &http.Client{
+				Timeout:   10 * time.Second,
+				Transport: &http.Transport{TLSClientConfig: app.upstreamTLSConfig(UpstreamOIDC)},
+			},
+		}, app.logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OIDC verifier: %w", err)
+		}
+		app.logger.Info("validating request tokens with OIDC", "issuer", app.config.OIDCIssuerURL)
+		return OIDCIdentityExtractor{
+			Verifier: verifier,
+			Header:   app.config.AuthTokenHeader,
+			Prefix:   app.config.AuthTokenPrefix,
+		}, nil
+	}
+
+	var extractor IdentityExtractor = DefaultIdentityExtractor(app)
+	if app.config.KubeflowMode {
+		app.logger.Info("reading the identity from the Kubeflow headers", "userIDHeader", app.config.UserIDHeader, "groupsHeader", app.config.GroupsHeader)
+		extractor = KubeflowHeaderIdentityExtractor{
+			UserIDHeader: app.config.UserIDHeader,
+			UserIDPrefix: app.config.UserIDPrefix,
+			GroupsHeader: app.config.GroupsHeader,
+		}
+	}
+	if len(app.config.TrustedProxyCIDRs) == 0 {
+		if app.config.KubeflowMode {
+			return nil, errors.New("trusted-proxy-cidrs: required in Kubeflow mode")
+		}
+		return extractor, nil
+	}
+	trusted, err := NewTrustedProxyIdentityExtractor(extractor, app.config.TrustedProxyCIDRs)
+	if err != nil {
+		return nil, fmt.Errorf("trusted-proxy-cidrs: %w", err)
+	}
+	app.logger.Info("accepting identity headers from trusted proxies only", "cidrs", app.config.TrustedProxyCIDRs)
+	return trusted, nil
+}
+
+// ChainIdentityExtractors tries each extractor in order and returns the first identity found.
+// Extractors that return ErrIdentityNotFound are skipped; any other error stops the chain.
+func ChainIdentityExtractors(extractors ...IdentityExtractor) IdentityExtractor {
+	return IdentityExtractorFunc(func(r *http.Request) (*k8s.RequestIdentity, error) {
+		for _, e := range extractors {
+			identity, err := e.ExtractIdentity(r)
+			if errors.Is(err, ErrIdentityNotFound) {
+				continue
+			}
+			return identity, err
+		}
+		return nil, ErrIdentityNotFound
+	})
+}
+
+// KubeflowHeaderIdentityExtractor reads the kubeflow-userid and kubeflow-groups headers
+// set by the Kubeflow Central Dashboard / Istio auth stack. The headers can be renamed, and
+// UserIDPrefix, e.g. "accounts.google.com:", is stripped from the user, like the USERID_HEADER
+// and USERID_PREFIX settings of the Kubeflow web apps.
+type KubeflowHeaderIdentityExtractor struct {
+	// UserIDHeader and GroupsHeader default to kubeflow-userid and kubeflow-groups.
+	UserIDHeader string
+	UserIDPrefix string
+	GroupsHeader string
+}
+
+func (e KubeflowHeaderIdentityExtractor) ExtractIdentity(r *http.Request) (*k8s.RequestIdentity, error) {
+	userHeader, groupsHeader := e.UserIDHeader, e.GroupsHeader
+	if userHeader == "" {
+		userHeader = constants.KubeflowUserIDHeader
+	}
+	if groupsHeader == "" {
+		groupsHeader = constants.KubeflowUserGroupsIdHeader
+	}
+	userID := strings.TrimSpace(strings.TrimPrefix(r.Header.Get(userHeader), e.UserIDPrefix))
+	if userID == "" {
+		return nil, fmt.Errorf("%w: missing %s header", ErrIdentityNotFound, userHeader)
+	}
+	return &k8s.RequestIdentity{
+		UserID: userID,
+		Groups: splitHeaderList(r.Header.Get(groupsHeader)),
+	}, nil
+}
+
+// TrustedProxyIdentityExtractor accepts the identities extracted by Next only from the proxies
+// of its networks, e.g. the Istio sidecar injecting the identity headers of a Kubeflow install:
+// a client reaching the BFF directly could set the headers itself. Requests from other peers
+// are unauthenticated.
+type TrustedProxyIdentityExtractor struct {
+	Next    IdentityExtractor
+	Proxies []*net.IPNet
+}
+
+// NewTrustedProxyIdentityExtractor trusts the proxies of cidrs, e.g. "127.0.0.6/32".
+func NewTrustedProxyIdentityExtractor(next IdentityExtractor, cidrs []string) (TrustedProxyIdentityExtractor, error) {
+	e := TrustedProxyIdentityExtractor{Next: next}
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return TrustedProxyIdentityExtractor{}, fmt.Errorf("invalid CIDR %q: %w", cidr, err)
+		}
+		e.Proxies = append(e.Proxies, network)
+	}
+	return e, nil
+}
+
+func (e TrustedProxyIdentityExtractor) ExtractIdentity(r *http.Request) (*k8s.RequestIdentity, error) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !slices.ContainsFunc(e.Proxies, func(network *net.IPNet) bool { return network.Contains(ip) }) {
+		return nil, fmt.Errorf("%w: identity headers are only accepted from the trusted proxies, not from %s", ErrUnauthenticated, host)
+	}
+	return e.Next.ExtractIdentity(r)
+}
+
+// BearerTokenIdentityExtractor reads a bearer token from Header, stripping Prefix.
+// The zero value reads "Authorization: Bearer <token>".
+type BearerTokenIdentityExtractor struct {
+	Header string
+	Prefix string
+}
+
+func (e BearerTokenIdentityExtractor) ExtractIdentity(r *http.Request) (*k8s.RequestIdentity, error) {
+	header := e.Header
+	prefix := e.Prefix
+	if header == "" {
+		header = "Authorization"
+		prefix = "Bearer "
+	}
+
+	raw := r.Header.Get(header)
+	if raw == "" {
+		return nil, fmt.Errorf("%w: missing %s header", ErrIdentityNotFound, header)
+	}
+	if prefix != "" {
+		if !strings.HasPrefix(raw, prefix) {
+			return nil, fmt.Errorf("expected token header %s to start with prefix %q", header, prefix)
+		}
+		raw = strings.TrimPrefix(raw, prefix)
+	}
+
+	token := strings.TrimSpace(raw)
+	if token == "" {
+		return nil, fmt.Errorf("empty token in header %s", header)
+	}
+	return &k8s.RequestIdentity{Token: token}, nil
+}
+
+// OAuthProxyIdentityExtractor reads the headers set by the OpenShift OAuth proxy:
+// X-Forwarded-User (falling back to X-Forwarded-Email), X-Forwarded-Groups and
+// X-Forwarded-Access-Token. At least a user or an access token must be present.
+type OAuthProxyIdentityExtractor struct{}
+
+func (OAuthProxyIdentityExtractor) ExtractIdentity(r *http.Request) (*k8s.RequestIdentity, error) {
+	userID := r.Header.Get(ForwardedUserHeader)
+	if userID == "" {
+		userID = r.Header.Get(ForwardedEmailHeader)
+	}
+	token := strings.TrimSpace(r.Header.Get(ForwardedAccessTokenHeader))
+	if userID == "" && token == "" {
+		return nil, fmt.Errorf("%w: missing %s and %s headers", ErrIdentityNotFound, ForwardedUserHeader, ForwardedAccessTokenHeader)
+	}
+	return &k8s.RequestIdentity{
+		UserID: userID,
+		Groups: splitHeaderList(r.Header.Get(ForwardedGroupsHeader)),
+		Token:  token,
+	}, nil
+}
+
+// ClientCertificateIdentityExtractor maps a verified TLS client certificate to an identity
+// following the Kubernetes convention: the subject common name is the user and the subject
+// organizations are the groups. It requires the server to be configured to request and
+// verify client certificates.
+type ClientCertificateIdentityExtractor struct{}
+
+func (ClientCertificateIdentityExtractor) ExtractIdentity(r *http.Request) (*k8s.RequestIdentity, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, fmt.Errorf("%w: no verified client certificate", ErrIdentityNotFound)
+	}
+	cert := r.TLS.VerifiedChains[0][0]
+	if cert.Subject.CommonName == "" {
+		return nil, errors.New("client certificate has an empty common name")
+	}
+	groups := make([]string, 0, len(cert.Subject.Organization))
+	groups = append(groups, cert.Subject.Organization...)
+	return &k8s.RequestIdentity{
+		UserID: cert.Subject.CommonName,
+		Groups: groups,
+	}, nil
+}
+
+// SessionIdentityExtractor authenticates the requests of a session loaded by LoadSession with
+// its token, handed to Next in Header after Prefix like a bearer token sent by the client (see
+// BearerTokenIdentityExtractor for the zero value).
+// Requests of a session that ended are unauthenticated, so the frontend signs in again.
+type SessionIdentityExtractor struct {
+	Next   IdentityExtractor
+	Header string
+	Prefix string
+}
+
+func (e SessionIdentityExtractor) ExtractIdentity(r *http.Request) (*k8s.RequestIdentity, error) {
+	s, err := session.FromContext(r.Context())
+	if errors.Is(err, session.ErrExpired) {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	if s == nil {
+		return nil, fmt.Errorf("%w: no session", ErrIdentityNotFound)
+	}
+	header, prefix := e.Header, e.Prefix
+	if header == "" {
+		header, prefix = "Authorization", "Bearer "
+	}
+	r = r.Clone(r.Context())
+	r.Header.Set(header, prefix+s.Token())
+	return e.Next.ExtractIdentity(r)
+}
+
+// OIDCIdentityExtractor validates a JWT bearer token against an OIDC issuer and maps its
+// claims to an identity: the configured username claim becomes UserID, the groups claim
+// becomes Groups, and the raw token is kept in Token so it can be passed through to an
+// API server that trusts the same issuer.
+type OIDCIdentityExtractor struct {
+	Verifier *oidc.Verifier
+	// Header and Prefix locate the token, see BearerTokenIdentityExtractor.
+	Header string
+	Prefix string
+}
+
+func (e OIDCIdentityExtractor) ExtractIdentity(r *http.Request) (*k8s.RequestIdentity, error) {
+	tokenIdentity, err := BearerTokenIdentityExtractor{Header: e.Header, Prefix: e.Prefix}.ExtractIdentity(r)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := e.Verifier.Verify(r.Context(), tokenIdentity.Token)
+	if err != nil {
+		if errors.Is(err, oidc.ErrInvalidToken) {
+			return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+		}
+		return nil, err
+	}
+
+	cfg := e.Verifier.Config()
+	userID := claims.String(cfg.UsernameClaim)
+	if userID == "" {
+		return nil, fmt.Errorf("%w: token has no %q claim", ErrUnauthenticated, cfg.UsernameClaim)
+	}
+
+	return &k8s.RequestIdentity{
+		UserID: userID,
+		Groups: claims.StringSlice(cfg.GroupsClaim),
+		Token:  tokenIdentity.Token,
+	}, nil
+}
+
+// GuestIdentityExtractor serves the requests without credentials as Identity, the guest of a
+// public demo: Next extracts the identity of the others. A request sending credentials that
+// Next rejects is not downgraded to a guest, nor is an unauthenticated one, e.g. of an expired
+// session, so clients notice they have to sign in again.
+type GuestIdentityExtractor struct {
+	Next     IdentityExtractor
+	Identity k8s.RequestIdentity
+	// CredentialHeaders are the headers carrying credentials, e.g. the token header.
+	CredentialHeaders []string
+}
+
+func (e GuestIdentityExtractor) ExtractIdentity(r *http.Request) (*k8s.RequestIdentity, error) {
+	identity, err := e.Next.ExtractIdentity(r)
+	if err == nil || errors.Is(err, ErrUnauthenticated) || e.hasCredentials(r) {
+		return identity, err
+	}
+	guest := e.Identity
+	guest.Groups = slices.Clone(guest.Groups)
+	guest.Token = ""
+	guest.Guest = true
+	return &guest, nil
+}
+
+func (e GuestIdentityExtractor) hasCredentials(r *http.Request) bool {
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		return true
+	}
+	return slices.ContainsFunc(e.CredentialHeaders, func(header string) bool {
+		return header != "" && r.Header.Get(header) != ""
+	})
+}
+
+// splitHeaderList splits a comma-separated header value, trimming blanks.
+func splitHeaderList(value string) []string {
+	items := []string{}
+	if value == "" {
+		return items
+	}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,212 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKubeflowHeaderIdentityExtractor(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/user", nil)
+	_, err := KubeflowHeaderIdentityExtractor{}.ExtractIdentity(req)
+	assert.ErrorIs(t, err, ErrIdentityNotFound)
+
+	req.Header.Set(constants.KubeflowUserIDHeader, "user@example.com")
+	req.Header.Set(constants.KubeflowUserGroupsIdHeader, "a, b,,c")
+	identity, err := KubeflowHeaderIdentityExtractor{}.ExtractIdentity(req)
+	require.NoError(t, err)
+	assert.Equal(t, "user@example.com", identity.UserID)
+	assert.Equal(t, []string{"a", "b", "c"}, identity.Groups)
+
+	extractor := KubeflowHeaderIdentityExtractor{UserIDHeader: "x-goog-authenticated-user-email", UserIDPrefix: "accounts.google.com:", GroupsHeader: "x-groups"}
+	_, err = extractor.ExtractIdentity(req)
+	assert.ErrorIs(t, err, ErrIdentityNotFound, "the default headers are ignored once renamed")
+	req.Header.Set("x-goog-authenticated-user-email", "accounts.google.com:user@example.com")
+	req.Header.Set("x-groups", "team-a")
+	identity, err = extractor.ExtractIdentity(req)
+	require.NoError(t, err)
+	assert.Equal(t, "user@example.com", identity.UserID)
+	assert.Equal(t, []string{"team-a"}, identity.Groups)
+}
+
+func TestTrustedProxyIdentityExtractor(t *testing.T) {
+	_, err := NewTrustedProxyIdentityExtractor(KubeflowHeaderIdentityExtractor{}, []string{"127.0.0.6"})
+	assert.Error(t, err)
+	extractor, err := NewTrustedProxyIdentityExtractor(KubeflowHeaderIdentityExtractor{}, []string{"127.0.0.6/32", "10.0.0.0/8"})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/user", nil)
+	req.Header.Set(constants.KubeflowUserIDHeader, "user@example.com")
+	for addr, trusted := range map[string]bool{"127.0.0.6:41000": true, "10.1.2.3:8080": true, "192.0.2.1:1234": false, "[::1]:1234": false, "bogus": false} {
+		req.RemoteAddr = addr
+		identity, err := extractor.ExtractIdentity(req)
+		if trusted {
+			require.NoError(t, err, addr)
+			assert.Equal(t, "user@example.com", identity.UserID)
+		} else {
+			assert.ErrorIs(t, err, ErrUnauthenticated, addr)
+		}
+	}
+}
+
+func TestBearerTokenIdentityExtractor(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/user", nil)
+	_, err := BearerTokenIdentityExtractor{}.ExtractIdentity(req)
+	assert.ErrorIs(t, err, ErrIdentityNotFound)
+
+	req.Header.Set("Authorization", "Basic abc")
+	_, err = BearerTokenIdentityExtractor{}.ExtractIdentity(req)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrIdentityNotFound)
+
+	req.Header.Set("Authorization", "Bearer my-token")
+	identity, err := BearerTokenIdentityExtractor{}.ExtractIdentity(req)
+	require.NoError(t, err)
+	assert.Equal(t, "my-token", identity.Token)
+
+	req.Header.Set("x-forwarded-access-token", "raw-token")
+	identity, err = BearerTokenIdentityExtractor{Header: "x-forwarded-access-token"}.ExtractIdentity(req)
+	require.NoError(t, err)
+	assert.Equal(t, "raw-token", identity.Token)
+}
+
+func TestOAuthProxyIdentityExtractor(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/user", nil)
+	_, err := OAuthProxyIdentityExtractor{}.ExtractIdentity(req)
+	assert.ErrorIs(t, err, ErrIdentityNotFound)
+
+	req.Header.Set(ForwardedEmailHeader, "user@example.com")
+	req.Header.Set(ForwardedAccessTokenHeader, "sha256~token")
+	req.Header.Set(ForwardedGroupsHeader, "admins")
+	identity, err := OAuthProxyIdentityExtractor{}.ExtractIdentity(req)
+	require.NoError(t, err)
+	assert.Equal(t, "user@example.com", identity.UserID)
+	assert.Equal(t, "sha256~token", identity.Token)
+	assert.Equal(t, []string{"admins"}, identity.Groups)
+
+	req.Header.Set(ForwardedUserHeader, "user")
+	identity, err = OAuthProxyIdentityExtractor{}.ExtractIdentity(req)
+	require.NoError(t, err)
+	assert.Equal(t, "user", identity.UserID)
+}
+
+func TestClientCertificateIdentityExtractor(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/user", nil)
+	_, err := ClientCertificateIdentityExtractor{}.ExtractIdentity(req)
+	assert.ErrorIs(t, err, ErrIdentityNotFound)
+
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "system:node:one", Organization: []string{"system:nodes"}}}
+	req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	identity, err := ClientCertificateIdentityExtractor{}.ExtractIdentity(req)
+	require.NoError(t, err)
+	assert.Equal(t, "system:node:one", identity.UserID)
+	assert.Equal(t, []string{"system:nodes"}, identity.Groups)
+}
+
+func TestGuestIdentityExtractor(t *testing.T) {
+	extractor := GuestIdentityExtractor{
+		Next:              KubeflowHeaderIdentityExtractor{},
+		Identity:          k8s.RequestIdentity{UserID: "guest", Groups: []string{"demo"}},
+		CredentialHeaders: []string{constants.KubeflowUserIDHeader, "Authorization"},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/user", nil)
+	identity, err := extractor.ExtractIdentity(req)
+	require.NoError(t, err)
+	assert.Equal(t, &k8s.RequestIdentity{UserID: "guest", Groups: []string{"demo"}, Guest: true}, identity)
+
+	req.Header.Set(constants.KubeflowUserIDHeader, "user@example.com")
+	identity, err = extractor.ExtractIdentity(req)
+	require.NoError(t, err)
+	assert.Equal(t, "user@example.com", identity.UserID)
+	assert.False(t, identity.Guest)
+
+	// Rejected credentials are not downgraded to a guest
+	extractor.Next = BearerTokenIdentityExtractor{}
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/user", nil)
+	req.Header.Set("Authorization", "Basic dXNlcg==")
+	_, err = extractor.ExtractIdentity(req)
+	assert.Error(t, err)
+	extractor.Next = IdentityExtractorFunc(func(*http.Request) (*k8s.RequestIdentity, error) {
+		return nil, fmt.Errorf("%w: session expired", ErrUnauthenticated)
+	})
+	_, err = extractor.ExtractIdentity(httptest.NewRequest(http.MethodGet, "/api/v1/user", nil))
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+}
+
+func TestNewIdentityExtractor_KubeflowMode(t *testing.T) {
+	app := newWatchTestApp(t)
+	app.config.KubeflowMode = true
+	app.config.UserIDHeader = "x-goog-authenticated-user-email"
+	app.config.UserIDPrefix = "accounts.google.com:"
+	app.config.TrustedProxyCIDRs = []string{"127.0.0.6/32"}
+	extractor, err := app.newIdentityExtractor()
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/user", nil)
+	req.Header.Set("x-goog-authenticated-user-email", "accounts.google.com:user@example.com")
+	_, err = extractor.ExtractIdentity(req)
+	assert.ErrorIs(t, err, ErrUnauthenticated, "the test request comes from 192.0.2.1")
+
+	req.RemoteAddr = "127.0.0.6:41000"
+	identity, err := extractor.ExtractIdentity(req)
+	require.NoError(t, err)
+	assert.Equal(t, "user@example.com", identity.UserID)
+
+	app.config.TrustedProxyCIDRs = nil
+	_, err = app.newIdentityExtractor()
+	assert.ErrorContains(t, err, "required in Kubeflow mode", "the headers of any peer are never trusted")
+}
+
+func TestNewIdentityExtractor_ClientCertificates(t *testing.T) {
+	app := newWatchTestApp(t)
+	app.config.TLSClientCertIdentity = true
+	extractor, err := app.newIdentityExtractor()
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/user", nil)
+	req.Header.Set(constants.KubeflowUserIDHeader, "user@example.com")
+	identity, err := extractor.ExtractIdentity(req)
+	require.NoError(t, err)
+	assert.Equal(t, "user@example.com", identity.UserID, "requests without a certificate use the other sources")
+
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "alice", Organization: []string{"admins"}}}
+	req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	identity, err = extractor.ExtractIdentity(req)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", identity.UserID)
+	assert.Equal(t, []string{"admins"}, identity.Groups)
+}
+
+func TestChainIdentityExtractors(t *testing.T) {
+	chain := ChainIdentityExtractors(OAuthProxyIdentityExtractor{}, KubeflowHeaderIdentityExtractor{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/user", nil)
+	_, err := chain.ExtractIdentity(req)
+	assert.ErrorIs(t, err, ErrIdentityNotFound)
+
+	req.Header.Set(constants.KubeflowUserIDHeader, "kf-user")
+	identity, err := chain.ExtractIdentity(req)
+	require.NoError(t, err)
+	assert.Equal(t, "kf-user", identity.UserID)
+
+	req.Header.Set(ForwardedUserHeader, "proxy-user")
+	identity, err = chain.ExtractIdentity(req)
+	require.NoError(t, err)
+	assert.Equal(t, "proxy-user", identity.UserID)
+
+	failing := ChainIdentityExtractors(IdentityExtractorFunc(func(r *http.Request) (*k8s.RequestIdentity, error) {
+		return nil, errors.New("invalid credentials")
+	}), KubeflowHeaderIdentityExtractor{})
+	_, err = failing.ExtractIdentity(req)
+	assert.EqualError(t, err, "invalid credentials")
+}
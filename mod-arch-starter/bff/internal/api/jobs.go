@@ -0,0 +1,219 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/healthcheck"
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/jobs"
+	logging "github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/notifications"
+)
+
+// Names of the built-in jobs, for -job-schedules.
+const (
+	JobResponseCachePrune = "response-cache-prune"
+	JobReviewCachePrune   = "review-cache-prune"
+	JobHealthProbe        = "health-probe"
+	JobOperationsPrune    = "operations-prune"
+)
+
+// Types of the notifications of JobHealthProbe.
+const (
+	NotificationHealthCheckFailing   = "health-check.failing"
+	NotificationHealthCheckRecovered = "health-check.recovered"
+)
+
+// JobFactory builds a background job once the App exists, so the job can use app dependencies
+// such as the repositories.
+type JobFactory func(app *App) jobs.Job
+
+var (
+	jobMu        sync.RWMutex
+	jobFactories []JobFactory
+)
+
+// RegisterJob registers a background job, run on its schedule from StartJobs until the App
+// shuts down; -job-schedules can override the schedule. This should be called from an init()
+// function in the downstream code.
+//
+// Example usage in downstream code:
+//
+//	func init() {
+//	    api.RegisterJob(func(app *api.App) jobs.Job {
+//	        return jobs.Job{
+//	            Name:     "model-catalog-sync",
+//	            Schedule: jobs.Every(10 * time.Minute),
+//	            Timeout:  2 * time.Minute,
+//	            // Once across the replicas, with -leader-election-enabled
+//	            LeaderOnly: true,
+//	            Run:        syncModelCatalog,
+//	        }
+//	    })
+//	}
+func RegisterJob(factory JobFactory) { //nolint:unused
+	jobMu.Lock()
+	defer jobMu.Unlock()
+	jobFactories = append(jobFactories, factory)
+}
+
+// newJobs schedules the built-in jobs, then the downstream and module ones, with the schedules
+// of -job-schedules.
+func (app *App) newJobs() (*jobs.Scheduler, error) {
+	scheduler := jobs.NewScheduler(logging.ForPackage(app.logger, "jobs"))
+	if app.metrics != nil {
+		scheduler.Observe(app.metrics.RecordJobRun)
+	}
+	if app.leader != nil {
+		scheduler.UseLeader(app.leader)
+	}
+
+	all := app.builtinJobs()
+	jobMu.RLock()
+	for _, factory := range jobFactories {
+		all = append(all, factory(app))
+	}
+	jobMu.RUnlock()
+	all = append(all, app.moduleJobs()...)
+
+	schedules, err := config.ParseJobSchedules(app.config.JobSchedules)
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range all {
+		if schedule, ok := schedules[job.Name]; ok {
+			delete(schedules, job.Name)
+			if schedule == nil {
+				app.logger.Info("background job turned off", "job", job.Name)
+				continue
+			}
+			job.Schedule = schedule
+		}
+		if err := scheduler.Add(job); err != nil {
+			return nil, fmt.Errorf("failed to schedule job: %w", err)
+		}
+	}
+	if unknown := slices.Sorted(maps.Keys(schedules)); len(unknown) > 0 {
+		return nil, fmt.Errorf("job-schedules: unknown job %q", unknown[0])
+	}
+	return scheduler, nil
+}
+
+// builtinJobs returns the jobs pruning the expired entries of the response and review caches,
+// when enabled, pruning the completed operations and probing the health checks.
+func (app *App) builtinJobs() []jobs.Job {
+	var builtins []jobs.Job
+	if app.responseCache != nil {
+		builtins = append(builtins, jobs.Job{
+			Name:     JobResponseCachePrune,
+			Schedule: jobs.Every(5 * time.Minute),
+			Run: func(ctx context.Context) error {
+				if pruned := app.responseCache.Prune(); pruned > 0 {
+					app.logger.Debug("pruned response cache", "entries", pruned)
+				}
+				return nil
+			},
+		})
+	}
+	if app.clusters != nil && k8s.ReviewCacheConfigFromEnv(app.config).Enabled() {
+		builtins = append(builtins, jobs.Job{
+			Name:     JobReviewCachePrune,
+			Schedule: jobs.Every(5 * time.Minute),
+			Run: func(ctx context.Context) error {
+				for _, name := range app.clusters.Names() {
+					factory, err := app.clusters.Factory(name)
+					if err != nil {
+						return err
+					}
+					if reviews, ok := factory.(interface{ ReviewCache() *k8s.ReviewCache }); ok {
+						reviews.ReviewCache().Prune()
+					}
+				}
+				return nil
+			},
+		})
+	}
+	if app.operations != nil {
+		builtins = append(builtins, jobs.Job{
+			Name:     JobOperationsPrune,
+			Schedule: jobs.Every(5 * time.Minute),
+			Run: func(ctx context.Context) error {
+				pruned, err := app.operations.Prune(ctx, app.config.OperationRetention)
+				if pruned > 0 {
+					app.logger.Debug("pruned completed operations", "operations", pruned)
+				}
+				return err
+			},
+		})
+	}
+	if app.healthChecks != nil {
+		builtins = append(builtins, jobs.Job{
+			Name:     JobHealthProbe,
+			Schedule: jobs.Every(30 * time.Second),
+			Timeout:  healthcheck.DefaultTimeout * 2,
+			Run:      app.probeHealthChecks(),
+		})
+	}
+	return builtins
+}
+
+// probeHealthChecks returns the run of JobHealthProbe: it runs every health check, logs the
+// results changing and records them as metrics, so a failing optional dependency shows up
+// although it never fails the readiness of the pod. The changes are also notified to the
+// -admin-groups. The shutdown check only fails on purpose.
+func (app *App) probeHealthChecks() func(ctx context.Context) error {
+	passing := map[string]bool{}
+	return func(ctx context.Context) error {
+		for _, result := range app.healthChecks.Run(ctx, 0, app.drainingCheck().Name).Checks {
+			passed := result.Status == healthcheck.StatusOK
+			if was, seen := passing[result.Name]; seen && was != passed {
+				if passed {
+					app.logger.Info("health check recovered", "check", result.Name)
+				} else {
+					app.logger.Warn("health check failing", "check", result.Name, "error", result.Error)
+				}
+				app.notifyHealthCheck(result)
+			}
+			passing[result.Name] = passed
+			if app.metrics != nil {
+				app.metrics.RecordHealthCheck(result.Name, passed)
+			}
+		}
+		return nil
+	}
+}
+
+// notifyHealthCheck notifies the admins of a health check failing or recovering.
+func (app *App) notifyHealthCheck(result healthcheck.CheckResult) {
+	if len(app.config.AdminGroups) == 0 {
+		return
+	}
+	n := notifications.Notification{
+		Type:     NotificationHealthCheckRecovered,
+		Severity: notifications.SeveritySuccess,
+		Title:    fmt.Sprintf("Health check %q recovered", result.Name),
+		Groups:   app.config.AdminGroups,
+	}
+	if result.Status != healthcheck.StatusOK {
+		n.Type, n.Severity = NotificationHealthCheckFailing, notifications.SeverityWarning
+		n.Title, n.Message = fmt.Sprintf("Health check %q failing", result.Name), result.Error
+	}
+	app.notifications.Publish(n)
+}
+
+// StartJobs runs the background jobs on their schedules, and takes part in the leader
+// election, until ctx is done or the App shuts down. It returns right away.
+func (app *App) StartJobs(ctx context.Context) {
+	if app.leader != nil {
+		app.leader.Start(ctx)
+	}
+	if app.jobs != nil {
+		app.jobs.Start(ctx)
+	}
+}
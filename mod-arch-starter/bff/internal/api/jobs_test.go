@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/healthcheck"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/jobs"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/metrics"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/notifications"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewJobs(t *testing.T) {
+	RegisterJob(func(app *App) jobs.Job {
+		return jobs.Job{Name: "sync", Schedule: jobs.Every(time.Hour), Run: func(context.Context) error { return nil }}
+	})
+	t.Cleanup(func() {
+		jobMu.Lock()
+		defer jobMu.Unlock()
+		jobFactories = nil
+	})
+
+	app := newWatchTestApp(t)
+	var err error
+	app.healthChecks, err = app.newHealthChecks()
+	require.NoError(t, err)
+
+	scheduler, err := app.newJobs()
+	require.NoError(t, err)
+	assert.Equal(t, []string{JobHealthProbe, "sync"}, scheduler.Names())
+
+	app.config.JobSchedules = []string{"sync=off", JobHealthProbe + "=@every 1m"}
+	scheduler, err = app.newJobs()
+	require.NoError(t, err)
+	assert.Equal(t, []string{JobHealthProbe}, scheduler.Names())
+
+	app.config.JobSchedules = []string{"missing=@hourly"}
+	_, err = app.newJobs()
+	assert.ErrorContains(t, err, `unknown job "missing"`)
+}
+
+func TestProbeHealthChecks(t *testing.T) {
+	failing := true
+	RegisterHealthCheck(func(app *App) healthcheck.Check {
+		return healthcheck.Check{Name: "upstream", Optional: true, Func: func(context.Context) error {
+			if failing {
+				return errors.New("unreachable")
+			}
+			return nil
+		}}
+	})
+	t.Cleanup(func() {
+		healthCheckMu.Lock()
+		defer healthCheckMu.Unlock()
+		healthCheckFactories = nil
+	})
+
+	app := newWatchTestApp(t)
+	app.metrics = metrics.New()
+	app.config.AdminGroups = []string{"admins"}
+	app.notifications = notifications.New(10)
+	var err error
+	app.healthChecks, err = app.newHealthChecks()
+	require.NoError(t, err)
+	scrape := func() string {
+		rr := httptest.NewRecorder()
+		app.metrics.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, MetricsPath, nil))
+		return rr.Body.String()
+	}
+
+	probe := app.probeHealthChecks()
+	require.NoError(t, probe(context.Background()))
+	assert.Contains(t, scrape(), `bff_health_check_up{check="upstream"} 0`)
+	assert.Contains(t, scrape(), `bff_health_check_up{check="ping"} 1`)
+
+	failing = false
+	require.NoError(t, probe(context.Background()))
+	assert.Contains(t, scrape(), `bff_health_check_up{check="upstream"} 1`)
+	notified, _, sub := app.notifications.Subscribe("", func(n notifications.Notification) bool { return n.For("", []string{"admins"}) })
+	sub.Close()
+	require.Len(t, notified, 1, "the admins are notified of the changes")
+	assert.Equal(t, NotificationHealthCheckRecovered, notified[0].Type)
+
+	app.StartDrain()
+	require.NoError(t, probe(context.Background()))
+	assert.NotContains(t, scrape(), `check="shutdown"`, "draining is not a failure")
+}
+
+func TestNewLeaderElector(t *testing.T) {
+	app := newWatchTestApp(t)
+	elector, err := app.newLeaderElector()
+	require.NoError(t, err)
+	assert.Nil(t, elector, "disabled by default")
+
+	t.Setenv("POD_NAMESPACE", "")
+	app.config.LeaderElectionEnabled = true
+	_, err = app.newLeaderElector()
+	assert.ErrorContains(t, err, "POD_NAMESPACE")
+}
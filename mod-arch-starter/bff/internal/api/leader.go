@@ -0,0 +1,48 @@
+package api
+
+import (
+	"fmt"
+	"os"
+
+	helper "github.com/opendatahub-io/mod-arch-library/bff/internal/helpers"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/leader"
+	logging "github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"k8s.io/client-go/kubernetes"
+)
+
+// newLeaderElector creates the elector of -leader-election-enabled, started with the jobs; nil
+// when leader election is disabled.
+func (app *App) newLeaderElector() (*leader.Elector, error) {
+	cfg := app.config
+	if !cfg.LeaderElectionEnabled {
+		return nil, nil
+	}
+	namespace := cfg.LeaderElectionNamespace
+	if namespace == "" {
+		namespace = os.Getenv("POD_NAMESPACE")
+	}
+	if namespace == "" {
+		return nil, fmt.Errorf("leader election needs -leader-election-namespace or POD_NAMESPACE")
+	}
+	restConfig, err := helper.GetKubeconfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig for leader election: %w", err)
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client for leader election: %w", err)
+	}
+
+	elector, err := leader.New(clientset, leader.Config{
+		Namespace:     namespace,
+		Name:          cfg.LeaderElectionLeaseName,
+		LeaseDuration: cfg.LeaderElectionLeaseDuration,
+	}, logging.ForPackage(app.logger, "leader"))
+	if err != nil {
+		return nil, err
+	}
+	if app.metrics != nil {
+		elector.Observe(app.metrics.RecordLeader)
+	}
+	return elector, nil
+}
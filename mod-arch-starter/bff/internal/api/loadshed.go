@@ -0,0 +1,96 @@
+package api
+
+import (
+	"errors"
+	"math"
+	"net/http"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/apierrors"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/concurrency"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/metrics"
+)
+
+// newConcurrencyLimiter returns the limiter of the -max-concurrent-requests caps, nil when none
+// is set, recording its queue depth on appMetrics if not nil.
+func newConcurrencyLimiter(cfg config.EnvConfig, appMetrics *metrics.Metrics) *concurrency.Limiter {
+	limiter := concurrency.New(concurrency.Config{
+		MaxInFlight:       cfg.MaxConcurrentRequests,
+		MaxInFlightPerKey: cfg.MaxConcurrentRequestsPerUser,
+		QueueSize:         cfg.ConcurrencyQueueSize,
+		QueueTimeout:      cfg.ConcurrencyQueueTimeout,
+	})
+	if limiter != nil && appMetrics != nil {
+		limiter.Observe(appMetrics.RecordQueueDepth)
+	}
+	return limiter
+}
+
+// LimitConcurrency caps the API requests served at once by -max-concurrent-requests, and those
+// of each user by -max-concurrent-requests-per-user (keyed like LimitByUser). Requests over a
+// cap wait briefly for a slot, then are shed with a 503 and a Retry-After header.
+//
+// A stream (a watch, pod logs) holds its slot until its first flush only, so long-lived streams
+// don't starve the other requests; the rate limits and the stream caps bound them instead.
+func (app *App) LimitConcurrency(next http.Handler) http.Handler {
+	if app.concurrency == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !app.requiresAuth(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		identity, _ := r.Context().Value(constants.RequestIdentityKey).(*k8s.RequestIdentity)
+		release, err := app.concurrency.Acquire(r.Context(), rateLimitKey(identity))
+		if err != nil {
+			var rejected *concurrency.RejectedError
+			if errors.As(err, &rejected) {
+				app.overloadedResponse(w, r, rejected)
+			}
+			// Otherwise the client went away while queued
+			return
+		}
+		defer release()
+
+		next.ServeHTTP(&releasingWriter{ResponseWriter: w, release: release}, r)
+	})
+}
+
+// overloadedResponse writes a 503 with a Retry-After header of the queue timeout, in whole
+// seconds.
+func (app *App) overloadedResponse(w http.ResponseWriter, r *http.Request, rejected *concurrency.RejectedError) {
+	if app.metrics != nil {
+		app.metrics.RecordShed(rejected.Limit, rejected.Reason)
+	}
+
+	seconds := int32(math.Ceil(app.config.ConcurrencyQueueTimeout.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	app.apiErrorResponse(w, r, &apierrors.Error{
+		StatusCode: http.StatusServiceUnavailable,
+		Message:    apierrors.MessageOverloaded,
+		Details:    map[string]any{"reason": "Overloaded", "limit": rejected.Limit, "retryAfterSeconds": seconds},
+	})
+}
+
+// releasingWriter frees the concurrency slot of its request on the first flush, which only
+// streams do.
+type releasingWriter struct {
+	http.ResponseWriter
+	release func()
+}
+
+func (w *releasingWriter) Flush() {
+	w.release()
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *releasingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLoadShedTestApp(t *testing.T, cfg config.EnvConfig) *App {
+	app := newWatchTestApp(t)
+	app.config.ConcurrencyQueueTimeout = cfg.ConcurrencyQueueTimeout
+	app.concurrency = newConcurrencyLimiter(cfg, nil)
+	return app
+}
+
+// serveAs serves a request of user to path through LimitConcurrency with handler.
+func serveAs(app *App, user, path string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req = req.WithContext(context.WithValue(req.Context(), constants.RequestIdentityKey, &kubernetes.RequestIdentity{UserID: user}))
+	rr := httptest.NewRecorder()
+	app.LimitConcurrency(handler).ServeHTTP(rr, req)
+	return rr
+}
+
+func TestLimitConcurrency(t *testing.T) {
+	app := newLoadShedTestApp(t, config.EnvConfig{MaxConcurrentRequestsPerUser: 1, ConcurrencyQueueTimeout: 1500 * time.Millisecond})
+
+	started, unblock := make(chan struct{}), make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		serveAs(app, "alice", UserPath, func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-unblock
+		})
+	}()
+	<-started
+
+	rr := serveAs(app, "alice", UserPath, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the request over the cap must be shed")
+	})
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, "2", rr.Header().Get("Retry-After"))
+	var body HTTPError
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, "Overloaded", body.Error.Details["reason"])
+	assert.Equal(t, "user", body.Error.Details["limit"])
+
+	// Other users, and requests outside the API, are served
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) }
+	assert.Equal(t, http.StatusNoContent, serveAs(app, "bob", UserPath, ok).Code)
+	assert.Equal(t, http.StatusNoContent, serveAs(app, "alice", HealthCheckPath, ok).Code)
+
+	close(unblock)
+	<-done
+	assert.Equal(t, http.StatusNoContent, serveAs(app, "alice", UserPath, ok).Code)
+}
+
+func TestLimitConcurrency_StreamsReleaseTheirSlot(t *testing.T) {
+	app := newLoadShedTestApp(t, config.EnvConfig{MaxConcurrentRequests: 1})
+
+	flushed, unblock := make(chan struct{}), make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		serveAs(app, "alice", WatchPath, func(w http.ResponseWriter, r *http.Request) {
+			_ = http.NewResponseController(w).Flush()
+			close(flushed)
+			<-unblock
+		})
+	}()
+	<-flushed
+
+	rr := serveAs(app, "bob", UserPath, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	close(unblock)
+	<-done
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/sse"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PodLogsHandler streams the logs of the pod in the path, in the namespace query parameter.
+// The container, follow, previous, timestamps, tailLines, sinceSeconds, sinceTime (RFC 3339)
+// and limitBytes query parameters are those of the Kubernetes pod log API.
+//
+// Logs are sent as chunked text/plain, flushed as they arrive, or as Server-Sent Events (a
+// "log" event per line, then "end") when the request accepts text/event-stream, as
+// EventSource does. The stream is opened before the response starts, so authorization failures
+// are returned as regular JSON errors (e.g. 403) rather than in the logs.
+func (app *App) PodLogsHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := r.Context()
+	identity, ok := ctx.Value(constants.RequestIdentityKey).(*kubernetes.RequestIdentity)
+	if !ok || identity == nil {
+		app.badRequestResponse(w, r, fmt.Errorf("missing RequestIdentity in context"))
+		return
+	}
+	namespace := requestNamespace(r)
+	if namespace == "" {
+		app.badRequestResponse(w, r, fmt.Errorf("missing namespace in the context"))
+		return
+	}
+
+	opts, err := podLogOptions(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	client, err := app.kubernetesClientFactory.GetClient(ctx)
+	if err != nil {
+		app.serverErrorResponse(w, r, fmt.Errorf("failed to get Kubernetes client: %w", err))
+		return
+	}
+
+	// Followed logs end on shutdown, like watches
+	streamCtx, cancel := app.streamContext(ctx)
+	defer cancel()
+
+	pod := ps.ByName("pod")
+	logs, err := app.repositories.Logs.StreamPodLogs(client, streamCtx, identity, namespace, pod, opts)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	defer logs.Close()
+
+	if acceptsEventStream(r) {
+		stream, err := sse.NewStream(w)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		err = sse.StreamLines(streamCtx, stream, logs, 0)
+		if err != nil {
+			logger.FromRequest(r).Debug("log stream ended", "pod", pod, "error", err)
+		}
+		return
+	}
+
+	controller := http.NewResponseController(w)
+	// Followed logs outlive the server write timeout by design
+	_ = controller.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := logs.Read(buf)
+		if n > 0 {
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return
+			}
+			_ = controller.Flush()
+		}
+		if err != nil {
+			if err != io.EOF && streamCtx.Err() == nil {
+				logger.FromRequest(r).Debug("log stream ended", "pod", pod, "error", err)
+			}
+			return
+		}
+	}
+}
+
+// podLogOptions parses the query parameters of PodLogsHandler.
+func podLogOptions(r *http.Request) (*corev1.PodLogOptions, error) {
+	query := r.URL.Query()
+	opts := &corev1.PodLogOptions{Container: query.Get("container")}
+
+	for name, value := range map[string]*bool{"follow": &opts.Follow, "previous": &opts.Previous, "timestamps": &opts.Timestamps} {
+		if raw := query.Get(name); raw != "" {
+			parsed, err := strconv.ParseBool(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q: must be true or false", name, raw)
+			}
+			*value = parsed
+		}
+	}
+
+	// tailLines may be 0, to follow only new lines
+	for name, value := range map[string]**int64{"tailLines": &opts.TailLines, "sinceSeconds": &opts.SinceSeconds, "limitBytes": &opts.LimitBytes} {
+		if raw := query.Get(name); raw != "" {
+			parsed, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil || parsed < 0 || (parsed == 0 && name != "tailLines") {
+				return nil, fmt.Errorf("invalid %s %q: must be a positive number", name, raw)
+			}
+			*value = &parsed
+		}
+	}
+
+	if raw := query.Get("sinceTime"); raw != "" {
+		if opts.SinceSeconds != nil {
+			return nil, fmt.Errorf("sinceSeconds and sinceTime are mutually exclusive")
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sinceTime %q: must be an RFC 3339 time", raw)
+		}
+		opts.SinceTime = &metav1.Time{Time: parsed}
+	}
+	return opts, nil
+}
+
+// acceptsEventStream reports whether the request asks for Server-Sent Events.
+func acceptsEventStream(r *http.Request) bool {
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		if mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted)); err == nil && mediaType == "text/event-stream" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveLogs(t *testing.T, app *App, ctx context.Context, path, accept string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil).WithContext(ctx)
+	req.Header.Set(constants.KubeflowUserIDHeader, "doraNonAdmin@example.com")
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rr := httptest.NewRecorder()
+	app.Routes().ServeHTTP(rr, req)
+	return rr
+}
+
+func TestPodLogsHandler(t *testing.T) {
+	app := newWatchTestApp(t)
+	ctx := context.Background()
+
+	rr := serveLogs(t, app, ctx, "/api/v1/pods/web/logs?namespace=dora-namespace&container=app&tailLines=2", "")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, "text/plain; charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "mock log line 19 of web/app\nmock log line 20 of web/app\n", rr.Body.String())
+
+	rr = serveLogs(t, app, ctx, "/api/v1/pods/web/logs?namespace=dora-namespace&tailLines=1", "text/event-stream")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "event: log\ndata: mock log line 20 of web/web\n\nevent: end\ndata: \n\n", rr.Body.String())
+}
+
+func TestPodLogsHandler_Follow(t *testing.T) {
+	app := newWatchTestApp(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	rr := serveLogs(t, app, ctx, "/api/v1/pods/web/logs?namespace=dora-namespace&follow=true&tailLines=1", "text/event-stream")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "data: mock log line 20 of web/web\n")
+	assert.NotContains(t, rr.Body.String(), "event: end", "the stream ends with the request")
+}
+
+func TestPodLogsHandler_Errors(t *testing.T) {
+	app := newWatchTestApp(t)
+	ctx := context.Background()
+
+	rr := serveLogs(t, app, ctx, "/api/v1/pods/web/logs?namespace=bella-namespace", "text/event-stream")
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Contains(t, rr.Header().Get("Content-Type"), "application/json")
+
+	for _, query := range []string{
+		"",
+		"?namespace=dora-namespace&tailLines=-1",
+		"?namespace=dora-namespace&limitBytes=0",
+		"?namespace=dora-namespace&follow=maybe",
+		"?namespace=dora-namespace&sinceTime=yesterday",
+		"?namespace=dora-namespace&sinceSeconds=60&sinceTime=2024-01-01T00:00:00Z",
+	} {
+		rr = serveLogs(t, app, ctx, "/api/v1/pods/web/logs"+query, "")
+		assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+	}
+}
@@ -2,40 +2,93 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
-	"runtime/debug"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/julienschmidt/httprouter"
 	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
 	helper "github.com/opendatahub-io/mod-arch-library/bff/internal/helpers"
-	"github.com/rs/cors"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/metrics"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/panicreport"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/proxy"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/tracing"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/validation"
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
 )
 
+// RecoverPanic answers a request whose handler panicked with a 500 error envelope, logs the
+// panic and its stack trace with the request logger, counts it in bff_http_panics_total and
+// forwards it to the panic reporter, if any. It runs inside EnableTelemetry so the log and the
+// report carry the request ID. http.ErrAbortHandler is re-panicked, net/http aborts quietly.
 func (app *App) RecoverPanic(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
-			if err := recover(); err != nil {
-				w.Header().Set("Connection", "close")
-				app.serverErrorResponse(w, r, fmt.Errorf("%s", err))
-				app.logger.Error("Recovered from panic", slog.String("stack_trace", string(debug.Stack())))
+			value := recover()
+			if value == nil {
+				return
 			}
+			if value == http.ErrAbortHandler {
+				panic(value)
+			}
+
+			report := panicreport.Capture(value, r)
+			report.RequestID, _ = r.Context().Value(constants.RequestIdKey).(string)
+			report.Route = metrics.RouteFromContext(r.Context())
+			app.requestLogger(r).Error("Recovered from panic",
+				slog.String("panic", report.Message),
+				slog.String("method", r.Method),
+				slog.String("uri", r.URL.Path),
+				slog.String("route", report.Route),
+				slog.String("stack_trace", report.Stack))
+			if app.metrics != nil {
+				app.metrics.RecordPanic(report.Route)
+			}
+			app.panicReporter.Report(report)
+
+			w.Header().Set("Connection", "close")
+			app.errorResponse(w, r, &HTTPError{StatusCode: http.StatusInternalServerError, Error: ErrorPayload{
+				Code:    strconv.Itoa(http.StatusInternalServerError),
+				Message: "the server encountered a problem and could not process your request",
+			}})
 		}()
 
 		next.ServeHTTP(w, r)
 	})
 }
 
-func requiresAuth(path string) bool {
-	prefixes := []string{
-		ApiPathPrefix,
-		PathPrefix + ApiPathPrefix,
+// newPanicReporter starts the reporter of -panic-report-dsn; nil when reporting is disabled.
+func (app *App) newPanicReporter() (*panicreport.Reporter, error) {
+	if app.config.PanicReportDSN == "" {
+		return nil, nil
+	}
+	sender, err := panicreport.NewSentrySender(app.config.PanicReportDSN)
+	if err != nil {
+		return nil, fmt.Errorf("panic-report-dsn: %w", err)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = app.upstreamTLSConfig(UpstreamPanicReport)
+	sender.Client = &http.Client{Timeout: 10 * time.Second, Transport: transport}
+	sender.Release = Version
+	return panicreport.New(panicreport.Options{Sender: sender, Logger: logger.ForPackage(app.logger, "panicreport")}), nil
+}
+
+func (app *App) requiresAuth(path string) bool {
+	// The API documentation and frontend configuration are public, like the healthcheck, and so
+	// is signing in and out
+	if p := strings.TrimPrefix(path, PathPrefix); p == OpenAPIPath || p == FrontendConfigPath || p == APIDocsPath || strings.HasPrefix(p, APIDocsPath+"/") ||
+		p == LoginPath || p == LoginCallbackPath || p == LogoutPath {
+		return false
 	}
-	for _, p := range prefixes {
-		if strings.HasPrefix(path, p) {
+	for _, version := range app.apiVersions() {
+		if p := APIVersionPrefix(version); strings.HasPrefix(path, p) || strings.HasPrefix(path, PathPrefix+p) {
 			return true
 		}
 	}
@@ -44,50 +97,85 @@ func requiresAuth(path string) bool {
 
 func (app *App) InjectRequestIdentity(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if !requiresAuth(r.URL.Path) {
+		if !app.requiresAuth(r.URL.Path) {
 			next.ServeHTTP(w, r)
 			return
 		}
 
-		identity, error := app.kubernetesClientFactory.ExtractRequestIdentity(r.Header)
-		if error != nil {
-			app.badRequestResponse(w, r, error)
-			return
+		extractor := app.identityExtractor
+		if extractor == nil {
+			extractor = DefaultIdentityExtractor(app)
 		}
+		r = app.webSocketTokenRequest(r)
 
+		identity, err := extractor.ExtractIdentity(r)
+		if err != nil {
+			if errors.Is(err, ErrUnauthenticated) {
+				app.unauthorizedResponse(w, r, err)
+				return
+			}
+			app.badRequestResponse(w, r, err)
+			return
+		}
 		ctx := context.WithValue(r.Context(), constants.RequestIdentityKey, identity)
+		if identity != nil && identity.UserID != "" {
+			ctx = logger.With(ctx, slog.String(logger.UserIDKey, identity.UserID))
+		}
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
-func (app *App) EnableCORS(next http.Handler) http.Handler {
-	if len(app.config.AllowedOrigins) == 0 {
-		// CORS is disabled, this middleware becomes a noop.
-		return next
-	}
-
-	c := cors.New(cors.Options{
-		AllowedOrigins:     app.config.AllowedOrigins,
-		AllowCredentials:   true,
-		AllowedMethods:     []string{"GET", "PUT", "POST", "PATCH", "DELETE"},
-		AllowedHeaders:     []string{constants.KubeflowUserIDHeader, constants.KubeflowUserGroupsIdHeader},
-		Debug:              app.config.LogLevel == slog.LevelDebug,
-		OptionsPassthrough: false,
+// RestrictGuests refuses guests the requests allowed reports false for: the routes not marked
+// for guests in apiRoutes, the reverse-proxied APIs included. A nil allowed refuses them every
+// request. It runs after InjectRequestIdentity.
+func (app *App) RestrictGuests(allowed func(r *http.Request) bool, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, _ := r.Context().Value(constants.RequestIdentityKey).(*kubernetes.RequestIdentity)
+		if identity != nil && identity.Guest && (allowed == nil || !allowed(r)) {
+			app.forbiddenResponse(w, r, "guests may only call read-only endpoints, sign in to make changes")
+			return
+		}
+		next.ServeHTTP(w, r)
 	})
+}
 
-	return c.Handler(next)
+// webSocketTokenRequest moves the bearer token of a WebSocket upgrade from its subprotocol (see
+// proxy.BearerSubprotocol) to the token header, for browsers, which can't set headers on
+// WebSockets. Other requests, and upgrades sending the token header, are returned unchanged.
+func (app *App) webSocketTokenRequest(r *http.Request) *http.Request {
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") || r.Header.Get(app.config.AuthTokenHeader) != "" {
+		return r
+	}
+	header := r.Header.Clone()
+	token, ok := proxy.TakeBearerSubprotocol(header)
+	if !ok {
+		return r
+	}
+	header.Set(app.config.AuthTokenHeader, app.config.AuthTokenPrefix+token)
+	r = r.Clone(r.Context())
+	r.Header = header
+	return r
 }
 
 func (app *App) EnableTelemetry(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Adds a unique id to the context to allow tracing of requests
-		traceId := uuid.NewString()
-		ctx := context.WithValue(r.Context(), constants.TraceIdKey, traceId)
+		// Every request gets an ID, reused from X-Request-ID when a proxy in front already set one,
+		// and echoed back so clients can report it.
+		requestId := requestID(r)
+		w.Header().Set(constants.RequestIDHeader, requestId)
+
+		// When OpenTelemetry tracing is enabled, reuse its trace ID so logs and traces correlate.
+		traceId := tracing.TraceID(r.Context())
+		if traceId == "" {
+			traceId = requestId
+		}
+		ctx := context.WithValue(r.Context(), constants.RequestIdKey, requestId)
+		ctx = context.WithValue(ctx, constants.TraceIdKey, traceId)
 
 		// logger will only be nil in tests.
 		if app.logger != nil {
-			traceLogger := app.logger.With(slog.String("trace_id", traceId))
-			ctx = context.WithValue(ctx, constants.TraceLoggerKey, traceLogger)
+			traceLogger := app.logger.With(slog.String(logger.RequestIDKey, requestId), slog.String(logger.TraceIDKey, traceId))
+			ctx = logger.NewContext(ctx, traceLogger)
 
 			traceLogger.Debug("Incoming HTTP request", slog.Any("request", helper.RequestLogValuer{Request: r}))
 		}
@@ -95,17 +183,97 @@ func (app *App) EnableTelemetry(next http.Handler) http.Handler {
 	})
 }
 
+// maxRequestIDLength bounds a client-provided X-Request-ID, which ends up in every log line.
+const maxRequestIDLength = 128
+
+// requestID returns the X-Request-ID of r if it is safe to log, otherwise a new UUID.
+func requestID(r *http.Request) string {
+	id := r.Header.Get(constants.RequestIDHeader)
+	if id == "" || len(id) > maxRequestIDLength {
+		return uuid.NewString()
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("-_.:", c)) {
+			return uuid.NewString()
+		}
+	}
+	return id
+}
+
+// AttachNamespace scopes a request to the namespace of its :namespace path parameter or
+// namespace query parameter: the name must be a valid namespace name, the identity must be
+// allowed to get the namespace, and the namespace must exist. The verified namespace is stored
+// once per request as a kubernetes.NamespaceContext, for the handlers (requestNamespace) and
+// the repositories (kubernetes.NamespaceFromContext), and as a string under
+// constants.NamespaceHeaderParameterKey.
 func (app *App) AttachNamespace(next func(http.ResponseWriter, *http.Request, httprouter.Params)) httprouter.Handle {
 	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-		namespace := r.URL.Query().Get(string(constants.NamespaceHeaderParameterKey))
+		param := string(constants.NamespaceHeaderParameterKey)
+		namespace := ps.ByName(param)
+		if query := r.URL.Query().Get(param); namespace == "" {
+			namespace = query
+		} else if query != "" && query != namespace {
+			app.badRequestResponse(w, r, validation.Invalid(param, fmt.Sprintf("the query parameter %q doesn't match the namespace %q of the path", query, namespace)))
+			return
+		}
 		if namespace == "" {
 			app.badRequestResponse(w, r, fmt.Errorf("missing required query parameter: %s", constants.NamespaceHeaderParameterKey))
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), constants.NamespaceHeaderParameterKey, namespace)
-		r = r.WithContext(ctx)
+		ctx := r.Context()
+		if !kubernetes.VerifiedNamespace(ctx, namespace) {
+			ns, ok := app.verifyNamespace(w, r, namespace)
+			if !ok {
+				return
+			}
+			ctx = kubernetes.WithNamespace(ctx, ns)
+		}
+		ctx = context.WithValue(ctx, constants.NamespaceHeaderParameterKey, namespace)
+		next(w, r.WithContext(ctx), ps)
+	}
+}
+
+// verifyNamespace validates the name of namespace, reviews the access of the identity of r to
+// it and reads it, writing an error response when one fails. Access is reviewed first, so the
+// existence of a namespace is only revealed to the users allowed to get it.
+func (app *App) verifyNamespace(w http.ResponseWriter, r *http.Request, namespace string) (kubernetes.NamespaceContext, bool) {
+	if problems := k8svalidation.IsDNS1123Label(namespace); len(problems) > 0 {
+		app.badRequestResponse(w, r, validation.Invalid(string(constants.NamespaceHeaderParameterKey), fmt.Sprintf("invalid namespace %q: %s", namespace, strings.Join(problems, ", "))))
+		return kubernetes.NamespaceContext{}, false
+	}
 
-		next(w, r, ps)
+	ctx := r.Context()
+	identity, ok := ctx.Value(constants.RequestIdentityKey).(*kubernetes.RequestIdentity)
+	if !ok || identity == nil {
+		app.badRequestResponse(w, r, fmt.Errorf("missing RequestIdentity in context"))
+		return kubernetes.NamespaceContext{}, false
+	}
+	client, err := app.kubernetesClientFactory.GetClient(ctx)
+	if err != nil {
+		app.serverErrorResponse(w, r, fmt.Errorf("failed to get Kubernetes client: %w", err))
+		return kubernetes.NamespaceContext{}, false
 	}
+
+	allowed, err := client.CanAccess(ctx, identity, "get", "", "namespaces", namespace)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return kubernetes.NamespaceContext{}, false
+	}
+	if !allowed {
+		app.forbiddenResponse(w, r, fmt.Sprintf("user %s cannot access namespace %q", identity.UserID, namespace))
+		return kubernetes.NamespaceContext{}, false
+	}
+	ns, err := client.Reader().GetNamespace(ctx, namespace)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return kubernetes.NamespaceContext{}, false
+	}
+	return kubernetes.NamespaceContext{Name: ns.Name, Labels: ns.Labels}, true
+}
+
+// requestNamespace returns the namespace verified by AttachNamespace, or "".
+func requestNamespace(r *http.Request) string {
+	ns, _ := kubernetes.NamespaceFromContext(r.Context())
+	return ns.Name
 }
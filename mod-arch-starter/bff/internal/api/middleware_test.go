@@ -0,0 +1,205 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/metrics"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/panicreport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnableTelemetry_RequestScopedLogger(t *testing.T) {
+	app := newWatchTestApp(t)
+	var buf bytes.Buffer
+	var err error
+	app.logger, err = logger.New(logger.Options{Output: &buf, Levels: logger.NewLevels(slog.LevelDebug)})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, NamespacePath, nil)
+	req.Header.Set(constants.KubeflowUserIDHeader, "user@example.com")
+	req.Header.Set(constants.RequestIDHeader, "req-123")
+	rr := httptest.NewRecorder()
+	app.Routes().ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "req-123", rr.Header().Get(constants.RequestIDHeader))
+
+	// The repository logs with the request ID, trace ID and user ID of the request
+	var record map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		if record["msg"] == "listed namespaces" {
+			break
+		}
+	}
+	assert.Equal(t, "listed namespaces", record["msg"])
+	assert.Equal(t, "req-123", record[logger.RequestIDKey])
+	assert.Equal(t, "req-123", record[logger.TraceIDKey])
+	assert.Equal(t, "user@example.com", record[logger.UserIDKey])
+}
+
+func TestRequestID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	generated := requestID(req)
+	assert.Len(t, generated, 36)
+
+	req.Header.Set(constants.RequestIDHeader, "abc-DEF_1.2:3")
+	assert.Equal(t, "abc-DEF_1.2:3", requestID(req))
+
+	// Unsafe or oversized IDs are replaced rather than logged
+	for _, id := range []string{"bad id\n", strings.Repeat("a", maxRequestIDLength+1)} {
+		req.Header.Set(constants.RequestIDHeader, id)
+		assert.NotEqual(t, id, requestID(req))
+	}
+}
+
+type recordingSender struct{ reports chan panicreport.Report }
+
+func (s *recordingSender) Send(_ context.Context, report panicreport.Report) error {
+	s.reports <- report
+	return nil
+}
+
+func TestRecoverPanic(t *testing.T) {
+	var buf bytes.Buffer
+	sender := &recordingSender{reports: make(chan panicreport.Report, 1)}
+	app := &App{
+		logger:        slog.New(slog.NewJSONHandler(&buf, nil)),
+		metrics:       metrics.New(),
+		panicReporter: panicreport.New(panicreport.Options{Sender: sender}),
+	}
+	defer app.panicReporter.Close(context.Background())
+	handler := app.metrics.Middleware(func(*http.Request) string { return "/api/v1/boom" },
+		app.EnableTelemetry(app.RecoverPanic(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			panic("boom")
+		}))))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/boom?token=s3cr3t", nil)
+	req.Header.Set(constants.RequestIDHeader, "req-123")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.Equal(t, "close", rr.Header().Get("Connection"))
+	var envelope HTTPError
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &envelope))
+	assert.Equal(t, "500", envelope.Error.Code)
+	assert.Equal(t, "req-123", envelope.Error.RequestID)
+	assert.NotContains(t, rr.Body.String(), "boom", "the panic value isn't sent to the client")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "Recovered from panic", record["msg"])
+	assert.Equal(t, "boom", record["panic"])
+	assert.Equal(t, "req-123", record[logger.RequestIDKey])
+	assert.Contains(t, record["stack_trace"], "TestRecoverPanic")
+
+	report := <-sender.reports
+	assert.Equal(t, "boom", report.Message)
+	assert.Equal(t, "req-123", report.RequestID)
+	assert.Equal(t, "/api/v1/boom", report.Route)
+	assert.Equal(t, "/api/v1/boom", report.Path, "without the query")
+
+	scrape := httptest.NewRecorder()
+	app.metrics.Handler().ServeHTTP(scrape, httptest.NewRequest(http.MethodGet, MetricsPath, nil))
+	assert.Contains(t, scrape.Body.String(), `bff_http_panics_total{route="/api/v1/boom"} 1`)
+}
+
+func TestRecoverPanic_ErrAbortHandler(t *testing.T) {
+	app := &App{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	handler := app.RecoverPanic(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
+
+func TestInjectRequestIdentity_Guest(t *testing.T) {
+	app := newWatchTestApp(t)
+	app.config.GuestMode = true
+	app.config.GuestUser = "demo-guest"
+	var err error
+	app.identityExtractor, err = app.newIdentityExtractor()
+	require.NoError(t, err)
+	serve := func(method, target string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(`{"data":{}}`))
+		for name, value := range header {
+			req.Header.Set(name, value)
+		}
+		rr := httptest.NewRecorder()
+		app.Routes().ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve(http.MethodGet, "/api/v1/user", nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.JSONEq(t, `{"data":{"userId":"demo-guest","groups":[],"clusterAdmin":false,"guest":true}}`, rr.Body.String())
+
+	rr = serve(http.MethodPost, "/api/v1/secrets?namespace=dora-namespace", map[string]string{"Content-Type": "application/json"})
+	assert.Equal(t, http.StatusForbidden, rr.Code, "guests are read-only")
+	rr = serve(http.MethodGet, "/api/v1/secrets?namespace=dora-namespace", nil)
+	assert.Equal(t, http.StatusForbidden, rr.Code, "only the routes marked for guests are served")
+	rr = serve(http.MethodGet, "/api/v1/version", nil)
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	rr = serve(http.MethodPost, "/api/v1/permissions/batch", map[string]string{"Content-Type": "application/json"})
+	assert.NotEqual(t, http.StatusForbidden, rr.Code, "read-only POST routes are served too")
+	rr = serve(http.MethodGet, "/api/v1/proxy/unknown/items", nil)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	rr = serve(http.MethodGet, "/api/v1/user", map[string]string{constants.KubeflowUserIDHeader: "user@example.com"})
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"data":{"userId":"user@example.com","groups":[],"clusterAdmin":true}}`, rr.Body.String(), "requests with credentials keep their identity")
+}
+
+func TestAttachNamespace(t *testing.T) {
+	app := newWatchTestApp(t)
+	var got kubernetes.NamespaceContext
+	handler := app.AttachNamespace(func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		got, _ = kubernetes.NamespaceFromContext(r.Context())
+		assert.Equal(t, got.Name, r.Context().Value(constants.NamespaceHeaderParameterKey))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	serve := func(ctx context.Context, user, target string, ps httprouter.Params) int {
+		got = kubernetes.NamespaceContext{}
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		identity := &kubernetes.RequestIdentity{UserID: user}
+		req = req.WithContext(context.WithValue(ctx, constants.RequestIdentityKey, identity))
+		rr := httptest.NewRecorder()
+		handler(rr, req, ps)
+		return rr.Code
+	}
+	ctx := context.Background()
+	const dora = "doraNonAdmin@example.com"
+
+	assert.Equal(t, http.StatusNoContent, serve(ctx, dora, "/x?namespace=dora-namespace", nil))
+	assert.Equal(t, "dora-namespace", got.Name)
+	path := httprouter.Params{{Key: "namespace", Value: "dora-namespace"}}
+	assert.Equal(t, http.StatusNoContent, serve(ctx, dora, "/x", path))
+	assert.Equal(t, http.StatusBadRequest, serve(ctx, dora, "/x?namespace=kubeflow", path), "the query doesn't match the path")
+
+	assert.Equal(t, http.StatusBadRequest, serve(ctx, dora, "/x", nil))
+	assert.Equal(t, http.StatusBadRequest, serve(ctx, dora, "/x?namespace=Not_A_Namespace", nil))
+	assert.Equal(t, http.StatusForbidden, serve(ctx, dora, "/x?namespace=kubeflow", nil))
+	assert.Equal(t, http.StatusForbidden, serve(ctx, dora, "/x?namespace=missing", nil), "existence is hidden from users who can't get it")
+	assert.Equal(t, http.StatusNotFound, serve(ctx, "user@example.com", "/x?namespace=missing", nil))
+	assert.Empty(t, got.Name)
+
+	// Verified once per request
+	verified := kubernetes.WithNamespace(ctx, kubernetes.NamespaceContext{Name: "kubeflow"})
+	assert.Equal(t, http.StatusNoContent, serve(verified, dora, "/x?namespace=kubeflow", nil))
+	assert.Equal(t, "kubeflow", got.Name)
+}
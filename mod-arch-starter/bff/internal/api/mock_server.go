@@ -0,0 +1,117 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+	logging "github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/mockserver"
+)
+
+// newMockServer loads the fixtures of -mock-server-fixtures; nil when it is not set.
+func (app *App) newMockServer() (*mockserver.Server, error) {
+	cfg := app.config
+	if cfg.MockServerFixtures == "" {
+		return nil, nil
+	}
+	server, err := mockserver.Load(cfg.MockServerFixtures, mockserver.Options{
+		Latency:   cfg.MockServerLatency,
+		ErrorRate: cfg.MockServerErrorRate,
+		Error: func(w http.ResponseWriter, r *http.Request, status int, message string) {
+			app.errorResponse(w, r, &HTTPError{StatusCode: status, Error: ErrorPayload{Code: strconv.Itoa(status), Message: message}})
+		},
+		Logger: logging.ForPackage(app.logger, "mockserver"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	app.logger.Warn("Serving mock fixtures instead of the API handlers", "fixtures", cfg.MockServerFixtures)
+	return server, nil
+}
+
+// apiRouter is the router of the API routes. It records the routes registered on it and, in
+// mock server mode, serves their fixtures instead of their handlers, route wrappers like
+// AttachNamespace included; the middlewares of Routes still run.
+type apiRouter struct {
+	*httprouter.Router
+	mock   *mockserver.Server
+	routes []string
+	// unmocked are the routes of handleUnmocked
+	unmocked []string
+	// guests are the read-only routes guests may call, see allowGuests
+	guests map[string]bool
+}
+
+func (app *App) newAPIRouter() *apiRouter {
+	router := &apiRouter{Router: httprouter.New(), mock: app.mockServer, guests: map[string]bool{}}
+	router.NotFound = http.HandlerFunc(app.notFoundResponse)
+	router.MethodNotAllowed = http.HandlerFunc(app.methodNotAllowedResponse)
+	return router
+}
+
+func (r *apiRouter) Handle(method, path string, handle httprouter.Handle) {
+	r.routes = append(r.routes, mockserver.RouteKey(method, path))
+	if r.mock != nil {
+		handle = r.mock.Handle(method, path)
+	}
+	r.Router.Handle(method, path, handle)
+}
+
+// handleUnmocked registers a route that is never mocked, e.g. the diagnostics.
+func (r *apiRouter) handleUnmocked(method, path string, handle httprouter.Handle) {
+	r.unmocked = append(r.unmocked, mockserver.RouteKey(method, path))
+	r.Router.Handle(method, path, handle)
+}
+
+// allowGuests lets guests call the route of method and path in guest mode. Only read-only
+// routes are marked: guests are refused every other one.
+func (r *apiRouter) allowGuests(method, path string) {
+	r.guests[mockserver.RouteKey(method, path)] = true
+}
+
+// guestGET registers a GET route guests may call.
+func (r *apiRouter) guestGET(path string, handle httprouter.Handle) {
+	r.GET(path, handle)
+	r.allowGuests(http.MethodGet, path)
+}
+
+// guestAllowed reports whether guests may call the route pattern with method.
+func (r *apiRouter) guestAllowed(method, pattern string) bool {
+	return pattern != "" && r.guests[mockserver.RouteKey(method, pattern)]
+}
+
+func (r *apiRouter) GET(path string, handle httprouter.Handle) {
+	r.Handle(http.MethodGet, path, handle)
+}
+
+func (r *apiRouter) POST(path string, handle httprouter.Handle) {
+	r.Handle(http.MethodPost, path, handle)
+}
+
+func (r *apiRouter) PUT(path string, handle httprouter.Handle) {
+	r.Handle(http.MethodPut, path, handle)
+}
+
+func (r *apiRouter) PATCH(path string, handle httprouter.Handle) {
+	r.Handle(http.MethodPatch, path, handle)
+}
+
+func (r *apiRouter) DELETE(path string, handle httprouter.Handle) {
+	r.Handle(http.MethodDelete, path, handle)
+}
+
+// logUnmatchedFixtures warns of the registered routes without fixtures, answered with a 501,
+// and of the fixtures of routes that are not registered.
+func (app *App) logUnmatchedFixtures(router *apiRouter) {
+	if router.mock == nil {
+		return
+	}
+	missing, unknown := router.mock.Unmatched(router.routes)
+	if len(missing) > 0 {
+		app.logger.Warn("Routes without mock fixtures answer 501", "routes", missing)
+	}
+	if len(unknown) > 0 {
+		app.logger.Warn("Mock fixtures of unknown routes", "routes", unknown)
+	}
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMockServer(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "namespaces.yaml"), []byte(`
+- method: GET
+  route: /api/v1/namespaces
+  body: {data: [{name: fixture-namespace}]}
+- method: GET
+  route: /api/v1/unknown
+`), 0o600))
+
+	app := newWatchTestApp(t)
+	app.config.MockServerFixtures = dir
+	var err error
+	app.mockServer, err = app.newMockServer()
+	require.NoError(t, err)
+	routes := app.Routes()
+	serve := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(constants.KubeflowUserIDHeader, "doraNonAdmin@example.com")
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve(NamespacePath)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.JSONEq(t, `{"data": [{"name": "fixture-namespace"}]}`, rr.Body.String(), "instead of the namespaces of the user")
+	assert.NotEmpty(t, rr.Header().Get(constants.RequestIDHeader), "the middlewares run")
+
+	rr = serve(UserPath)
+	assert.Equal(t, http.StatusNotImplemented, rr.Code)
+	var envelope HTTPError
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &envelope))
+	assert.Equal(t, "501", envelope.Error.Code)
+	assert.Contains(t, envelope.Error.Message, "no mock fixture matches GET "+UserPath)
+
+	assert.Equal(t, http.StatusNotFound, serve(ApiPathPrefix+"/unknown").Code, "only registered routes are mocked")
+}
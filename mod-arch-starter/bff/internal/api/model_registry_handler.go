@@ -0,0 +1,406 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	mrserver "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/httpclient"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/modelregistry"
+	logging "github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/pagination"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/repositories"
+)
+
+// Path parameters of the model registry endpoints.
+const (
+	ModelRegistryIDParam   = "model_registry_id"
+	RegisteredModelIDParam = "registered_model_id"
+	ModelVersionIDParam    = "model_version_id"
+	ModelArtifactIDParam   = "model_artifact_id"
+)
+
+type RegisteredModelListEnvelope Envelope[*models.RegisteredModelList, None]
+type RegisteredModelEnvelope Envelope[*models.RegisteredModel, None]
+type ModelVersionListEnvelope Envelope[*models.ModelVersionList, None]
+type ModelVersionEnvelope Envelope[*models.ModelVersion, None]
+type ModelArtifactListEnvelope Envelope[*models.ModelArtifactList, None]
+type ModelArtifactEnvelope Envelope[*models.ModelArtifact, None]
+
+// GetModelRegistriesHandler lists the model registry Services (component=model-registry) of the
+// namespace (AttachNamespace).
+func (app *App) GetModelRegistriesHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	ctx := r.Context()
+	identity, ok := ctx.Value(constants.RequestIdentityKey).(*kubernetes.RequestIdentity)
+	if !ok || identity == nil {
+		app.badRequestResponse(w, r, fmt.Errorf("missing RequestIdentity in context"))
+		return
+	}
+	namespace := requestNamespace(r)
+
+	selector, err := repositories.ParseServiceSelector(modelregistry.ServiceSelector, "")
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	opts, err := pagination.ParseListOptions(r.URL.Query())
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	client, err := app.kubernetesClientFactory.GetClient(ctx)
+	if err != nil {
+		app.serverErrorResponse(w, r, fmt.Errorf("failed to get Kubernetes client: %w", err))
+		return
+	}
+
+	registries, err := app.repositories.Service.GetServices(client, ctx, identity, namespace, selector)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+
+	page, metadata, err := pagination.Apply(registries, opts, serviceFields)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.WriteJSON(w, http.StatusOK, ServicesEnvelope{Data: page, Metadata: metadata}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// AttachModelRegistryClient stores in the request context a modelregistry client for the
+// registry Service named by the model_registry_id path parameter in the namespace
+// (AttachNamespace), acting as the caller. With -model-registry-url that URL is used instead.
+func (app *App) AttachModelRegistryClient(next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		ctx := r.Context()
+		identity, ok := ctx.Value(constants.RequestIdentityKey).(*kubernetes.RequestIdentity)
+		if !ok || identity == nil {
+			app.badRequestResponse(w, r, fmt.Errorf("missing RequestIdentity in context"))
+			return
+		}
+		namespace := requestNamespace(r)
+
+		serverURL := app.config.ModelRegistryURL
+		if serverURL == "" {
+			client, err := app.kubernetesClientFactory.GetClient(ctx)
+			if err != nil {
+				app.serverErrorResponse(w, r, fmt.Errorf("failed to get Kubernetes client: %w", err))
+				return
+			}
+			serverURL, err = app.repositories.ModelRegistry.GetModelRegistryURL(client, ctx, identity, namespace, ps.ByName(ModelRegistryIDParam))
+			if err != nil {
+				app.apiErrorResponse(w, r, err)
+				return
+			}
+		}
+
+		registryClient, err := modelregistry.NewClient(mrserver.UpstreamConfig{
+			BaseURL:    serverURL,
+			TLSConfig:  app.upstreamTLSConfig(UpstreamModelRegistry),
+			Resilience: app.resilience,
+			// Faults of the upstream named after the host, like its circuit breaker
+			WrapTransport: app.chaos.WrapTransportFor(func(req *http.Request) string { return req.URL.Host }),
+		}, identity, logging.ForPackage(app.logger, "modelregistry"))
+		if err != nil {
+			app.serverErrorResponse(w, r, fmt.Errorf("failed to create model registry client: %w", err))
+			return
+		}
+
+		ctx = context.WithValue(ctx, constants.ModelRegistryClientKey, modelregistry.ClientInterface(registryClient))
+		next(w, r.WithContext(ctx), ps)
+	}
+}
+
+// modelRegistryClient returns the client stored by AttachModelRegistryClient, writing an error
+// response when it is missing.
+func (app *App) modelRegistryClient(w http.ResponseWriter, r *http.Request) (modelregistry.ClientInterface, bool) {
+	client, ok := r.Context().Value(constants.ModelRegistryClientKey).(modelregistry.ClientInterface)
+	if !ok || client == nil {
+		app.serverErrorResponse(w, r, fmt.Errorf("missing model registry client in context"))
+		return nil, false
+	}
+	return client, true
+}
+
+// modelRegistryListOptions parses the pagination query parameters, writing a 400 when invalid.
+func (app *App) modelRegistryListOptions(w http.ResponseWriter, r *http.Request) (modelregistry.ListOptions, bool) {
+	opts, err := modelregistry.ParseListOptions(r.URL.Query())
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return modelregistry.ListOptions{}, false
+	}
+	return opts, true
+}
+
+// ─── REGISTERED MODELS ──────────────────────────────────────
+
+func (app *App) GetRegisteredModelsHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	client, ok := app.modelRegistryClient(w, r)
+	if !ok {
+		return
+	}
+	opts, ok := app.modelRegistryListOptions(w, r)
+	if !ok {
+		return
+	}
+
+	list, err := app.repositories.ModelRegistry.ListRegisteredModels(client, r.Context(), opts)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if err := app.WriteJSON(w, http.StatusOK, RegisteredModelListEnvelope{Data: list}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *App) GetRegisteredModelHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	client, ok := app.modelRegistryClient(w, r)
+	if !ok {
+		return
+	}
+
+	model, err := app.repositories.ModelRegistry.GetRegisteredModel(client, r.Context(), ps.ByName(RegisteredModelIDParam))
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if err := app.WriteJSON(w, http.StatusOK, RegisteredModelEnvelope{Data: model}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *App) CreateRegisteredModelHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	client, ok := app.modelRegistryClient(w, r)
+	if !ok {
+		return
+	}
+	var body RegisteredModelEnvelope
+	if err := app.ReadJSON(w, r, &body); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if body.Data == nil {
+		app.badRequestResponse(w, r, fmt.Errorf("missing registered model in request body"))
+		return
+	}
+
+	created, err := app.repositories.ModelRegistry.CreateRegisteredModel(client, r.Context(), *body.Data)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if err := app.WriteJSON(w, http.StatusCreated, RegisteredModelEnvelope{Data: created}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *App) UpdateRegisteredModelHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	client, ok := app.modelRegistryClient(w, r)
+	if !ok {
+		return
+	}
+	var body RegisteredModelEnvelope
+	if err := app.ReadJSON(w, r, &body); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if body.Data == nil {
+		app.badRequestResponse(w, r, fmt.Errorf("missing registered model in request body"))
+		return
+	}
+
+	updated, err := app.repositories.ModelRegistry.UpdateRegisteredModel(client, r.Context(), ps.ByName(RegisteredModelIDParam), *body.Data)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if err := app.WriteJSON(w, http.StatusOK, RegisteredModelEnvelope{Data: updated}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// ─── MODEL VERSIONS ─────────────────────────────────────────
+
+func (app *App) GetModelVersionsHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	client, ok := app.modelRegistryClient(w, r)
+	if !ok {
+		return
+	}
+	opts, ok := app.modelRegistryListOptions(w, r)
+	if !ok {
+		return
+	}
+
+	list, err := app.repositories.ModelRegistry.ListModelVersions(client, r.Context(), ps.ByName(RegisteredModelIDParam), opts)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if err := app.WriteJSON(w, http.StatusOK, ModelVersionListEnvelope{Data: list}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *App) GetModelVersionHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	client, ok := app.modelRegistryClient(w, r)
+	if !ok {
+		return
+	}
+
+	version, err := app.repositories.ModelRegistry.GetModelVersion(client, r.Context(), ps.ByName(ModelVersionIDParam))
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if err := app.WriteJSON(w, http.StatusOK, ModelVersionEnvelope{Data: version}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *App) CreateModelVersionHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	client, ok := app.modelRegistryClient(w, r)
+	if !ok {
+		return
+	}
+	var body ModelVersionEnvelope
+	if err := app.ReadJSON(w, r, &body); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if body.Data == nil {
+		app.badRequestResponse(w, r, fmt.Errorf("missing model version in request body"))
+		return
+	}
+
+	created, err := app.repositories.ModelRegistry.CreateModelVersion(client, r.Context(), ps.ByName(RegisteredModelIDParam), *body.Data)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if err := app.WriteJSON(w, http.StatusCreated, ModelVersionEnvelope{Data: created}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *App) UpdateModelVersionHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	client, ok := app.modelRegistryClient(w, r)
+	if !ok {
+		return
+	}
+	var body ModelVersionEnvelope
+	if err := app.ReadJSON(w, r, &body); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if body.Data == nil {
+		app.badRequestResponse(w, r, fmt.Errorf("missing model version in request body"))
+		return
+	}
+
+	updated, err := app.repositories.ModelRegistry.UpdateModelVersion(client, r.Context(), ps.ByName(ModelVersionIDParam), *body.Data)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if err := app.WriteJSON(w, http.StatusOK, ModelVersionEnvelope{Data: updated}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// ─── MODEL ARTIFACTS ────────────────────────────────────────
+
+func (app *App) GetModelArtifactsHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	client, ok := app.modelRegistryClient(w, r)
+	if !ok {
+		return
+	}
+	opts, ok := app.modelRegistryListOptions(w, r)
+	if !ok {
+		return
+	}
+
+	list, err := app.repositories.ModelRegistry.ListModelArtifacts(client, r.Context(), ps.ByName(ModelVersionIDParam), opts)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if err := app.WriteJSON(w, http.StatusOK, ModelArtifactListEnvelope{Data: list}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *App) GetModelArtifactHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	client, ok := app.modelRegistryClient(w, r)
+	if !ok {
+		return
+	}
+
+	artifact, err := app.repositories.ModelRegistry.GetModelArtifact(client, r.Context(), ps.ByName(ModelArtifactIDParam))
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if err := app.WriteJSON(w, http.StatusOK, ModelArtifactEnvelope{Data: artifact}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *App) CreateModelArtifactHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	client, ok := app.modelRegistryClient(w, r)
+	if !ok {
+		return
+	}
+	var body ModelArtifactEnvelope
+	if err := app.ReadJSON(w, r, &body); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if body.Data == nil {
+		app.badRequestResponse(w, r, fmt.Errorf("missing model artifact in request body"))
+		return
+	}
+
+	created, err := app.repositories.ModelRegistry.CreateModelArtifact(client, r.Context(), ps.ByName(ModelVersionIDParam), *body.Data)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if err := app.WriteJSON(w, http.StatusCreated, ModelArtifactEnvelope{Data: created}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *App) UpdateModelArtifactHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	client, ok := app.modelRegistryClient(w, r)
+	if !ok {
+		return
+	}
+	var body ModelArtifactEnvelope
+	if err := app.ReadJSON(w, r, &body); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if body.Data == nil {
+		app.badRequestResponse(w, r, fmt.Errorf("missing model artifact in request body"))
+		return
+	}
+
+	updated, err := app.repositories.ModelRegistry.UpdateModelArtifact(client, r.Context(), ps.ByName(ModelArtifactIDParam), *body.Data)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if err := app.WriteJSON(w, http.StatusOK, ModelArtifactEnvelope{Data: updated}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
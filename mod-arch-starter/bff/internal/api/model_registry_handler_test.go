@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/modelregistry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelRegistryHandlers(t *testing.T) {
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "user@example.com", r.Header.Get(constants.KubeflowUserIDHeader), "the caller is forwarded")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == modelregistry.APIPath+"/registered_models":
+			assert.Equal(t, "5", r.URL.Query().Get("pageSize"))
+			_, _ = w.Write([]byte(`{"items":[{"id":"1","name":"fraud"}],"nextPageToken":"","pageSize":5,"size":1}`))
+		case r.Method == http.MethodPost && r.URL.Path == modelregistry.APIPath+"/registered_models":
+			_, _ = w.Write([]byte(`{"id":"2","name":"churn"}`))
+		case r.Method == http.MethodGet && r.URL.Path == modelregistry.APIPath+"/model_versions/9":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"code":"404","message":"no model version found for id 9"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer registry.Close()
+
+	app := newWatchTestApp(t)
+	app.config.ModelRegistryURL = registry.URL
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(constants.KubeflowUserIDHeader, "user@example.com")
+		rr := httptest.NewRecorder()
+		app.Routes().ServeHTTP(rr, req)
+		return rr
+	}
+	registeredModels := ModelRegistryListPath + "/registry/registered_models"
+
+	rr := do(http.MethodGet, registeredModels+"?namespace=kubeflow&pageSize=5", "")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var list RegisteredModelListEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+	require.Len(t, list.Data.Items, 1)
+	assert.Equal(t, "fraud", list.Data.Items[0].Name)
+
+	rr = do(http.MethodPost, registeredModels+"?namespace=kubeflow", `{"data":{"name":"churn"}}`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var created RegisteredModelEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+	assert.Equal(t, "2", created.Data.ID)
+
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, ModelRegistryListPath+"/registry/model_versions/9?namespace=kubeflow", "").Code)
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodGet, registeredModels+"?namespace=kubeflow&pageSize=0", "").Code)
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, registeredModels+"?namespace=kubeflow", `{}`).Code)
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodGet, registeredModels, "").Code, "missing namespace")
+}
+
+func TestAttachModelRegistryClient_ResolvesService(t *testing.T) {
+	app := newWatchTestApp(t)
+
+	req := httptest.NewRequest(http.MethodGet, ModelRegistryListPath+"/mod-arch/registered_models?namespace=kubeflow", nil)
+	req.Header.Set(constants.KubeflowUserIDHeader, "user@example.com")
+	rr := httptest.NewRecorder()
+	app.Routes().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code, "mod-arch is not a model registry Service")
+}
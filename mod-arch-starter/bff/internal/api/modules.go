@@ -0,0 +1,317 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/database"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/healthcheck"
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/jobs"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/validation"
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
+	toolscache "k8s.io/client-go/tools/cache"
+)
+
+// Module is a feature area of a BFF (e.g. notebooks or pipelines) packaged to be plugged into
+// the starter with RegisterModule. NewApp assembles the registered modules into one server:
+// their routes are served behind the shared middleware (authentication, rate limits, audit,
+// metrics, ...) next to the starter endpoints.
+//
+// A module provides what it needs by implementing the optional interfaces below:
+// RouteModule, HealthCheckModule, JobModule, ConfigModule, DatabaseModule, InformerModule and
+// UIModule. Modules implementing io.Closer are closed when the App shuts down.
+type Module interface {
+	// Name identifies the module in logs and errors; it is a unique RFC 1123 label, e.g. "notebooks".
+	Name() string
+	// Init is called by NewApp once the shared dependencies exist, before the other methods.
+	// It builds the repositories and clients of the module from the App, e.g. with
+	// app.KubernetesClientFactory() and app.ResponseCache(); an error makes NewApp fail.
+	Init(app *App) error
+}
+
+// RouteModule is a Module serving API routes, in any API version (see VersionedRoute). Routes
+// without a Version are added to the starter API, APIVersionV1.
+type RouteModule interface {
+	Module
+	Routes() []VersionedRoute
+}
+
+// HealthCheckModule is a Module with health checks, served like the ones registered with
+// RegisterHealthCheck.
+type HealthCheckModule interface {
+	Module
+	HealthChecks() []healthcheck.Check
+}
+
+// JobModule is a Module with background jobs, scheduled like the ones registered with
+// RegisterJob.
+type JobModule interface {
+	Module
+	Jobs() []jobs.Job
+}
+
+// ConfigModule is a Module with its own settings.
+type ConfigModule interface {
+	Module
+	// Config returns a pointer to the settings, a struct with config tags (see config.Load)
+	// holding their defaults. They are loaded with the BFF configuration (see ModuleConfigs),
+	// so they have flags, environment variables and configuration file keys, and are
+	// checked by their Validate method, if any. They are not reloaded.
+	Config() any
+}
+
+// DatabaseModule is a Module keeping state in the database of -database-url, e.g. saved views.
+type DatabaseModule interface {
+	Module
+	// Migrations returns the versions of the schema of the tables of the module, applied
+	// with database.Migrate before Init when the database is enabled. Init gets the database
+	// with app.Database(), nil when it is not.
+	Migrations() []database.Migration
+}
+
+// InformerModule is a Module reacting to changes of the resources of the informer cache.
+type InformerModule interface {
+	Module
+	// EventHandlers returns the handlers of the changes, by cached resource
+	// (config.CacheResourceNamespaces or config.CacheResourceServices). Handlers of resources
+	// not in -cache-resources are not called.
+	EventHandlers() map[string]toolscache.ResourceEventHandler
+}
+
+// UIModule is a Module with a federated frontend, listed by ModulesPath for the host shell.
+type UIModule interface {
+	Module
+	// UIManifest describes the frontend; its Name defaults to the module name, and its
+	// RemoteEntry is replaced by the one of -ui-module-remote-entries, if any.
+	UIManifest() models.UIModule
+}
+
+var (
+	moduleMu sync.RWMutex
+	modules  []Module
+)
+
+// RegisterModule adds a module to the BFF. This should be called from an init() function in the
+// downstream code.
+//
+// Example usage in downstream code:
+//
+//	type notebooksModule struct {
+//	    config       NotebooksConfig
+//	    repositories *NotebookRepository
+//	}
+//
+//	func (m *notebooksModule) Name() string { return "notebooks" }
+//	func (m *notebooksModule) Config() any  { return &m.config }
+//
+//	func (m *notebooksModule) Init(app *api.App) error {
+//	    m.repositories = NewNotebookRepository(app.KubernetesClientFactory(), m.config.ImageRegistry)
+//	    return nil
+//	}
+//
+//	func (m *notebooksModule) Routes() []api.VersionedRoute {
+//	    return []api.VersionedRoute{{
+//	        Method:  http.MethodGet,
+//	        Path:    "/notebooks",
+//	        Handler: func(app *api.App) httprouter.Handle { return m.ListNotebooksHandler(app) },
+//	    }}
+//	}
+//
+//	func init() {
+//	    api.RegisterModule(&notebooksModule{config: NotebooksConfig{MaxNotebooks: 10}})
+//	}
+func RegisterModule(module Module) { //nolint:unused
+	moduleMu.Lock()
+	defer moduleMu.Unlock()
+	modules = append(modules, module)
+}
+
+// registeredModules returns the registered modules, in registration order.
+func registeredModules() []Module {
+	moduleMu.RLock()
+	defer moduleMu.RUnlock()
+	return slices.Clone(modules)
+}
+
+// ModuleConfigs returns the settings of the registered modules, to pass to config.Load as
+// LoadOptions.Sections before NewApp.
+func ModuleConfigs() []any {
+	var sections []any
+	for _, module := range registeredModules() {
+		if configModule, ok := module.(ConfigModule); ok {
+			sections = append(sections, configModule.Config())
+		}
+	}
+	return sections
+}
+
+// newModules initializes the registered modules and attaches their informer event handlers.
+// Their routes and health checks are collected by newVersionedRoutes and newHealthChecks.
+func (app *App) newModules() ([]Module, error) {
+	registered := registeredModules()
+	names := map[string]bool{}
+	for _, module := range registered {
+		name := module.Name()
+		if problems := k8svalidation.IsDNS1123Label(name); len(problems) > 0 {
+			return nil, fmt.Errorf("invalid module name %q: %s", name, problems[0])
+		}
+		if names[name] {
+			return nil, fmt.Errorf("module %q is registered twice", name)
+		}
+		names[name] = true
+
+		attrs := []any{slog.String("module", name)}
+		if configModule, ok := module.(ConfigModule); ok {
+			attrs = append(attrs, slog.Any("config", config.Redacted(configModule.Config())))
+		}
+		app.logger.Info("initializing module", attrs...)
+		if databaseModule, ok := module.(DatabaseModule); ok {
+			if err := app.migrateModule(databaseModule); err != nil {
+				return nil, err
+			}
+		}
+		if err := module.Init(app); err != nil {
+			return nil, fmt.Errorf("failed to initialize module %s: %w", name, err)
+		}
+
+		if informerModule, ok := module.(InformerModule); ok {
+			if err := app.addModuleEventHandlers(informerModule); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return registered, nil
+}
+
+func (app *App) addModuleEventHandlers(module InformerModule) error {
+	var resourceCache *k8s.ResourceCache
+	if factory, ok := app.kubernetesClientFactory.(interface{ ResourceCache() *k8s.ResourceCache }); ok {
+		resourceCache = factory.ResourceCache()
+	}
+	for resource, handler := range module.EventHandlers() {
+		if !config.IsValidCacheResource(resource) {
+			return fmt.Errorf("module %s: unsupported cache resource %q", module.Name(), resource)
+		}
+		added := false
+		if resourceCache != nil {
+			var err error
+			if added, err = resourceCache.AddEventHandler(resource, handler); err != nil {
+				return fmt.Errorf("module %s: %w", module.Name(), err)
+			}
+		}
+		if !added {
+			app.logger.Warn("module event handler not attached, the resource is not cached",
+				slog.String("module", module.Name()), slog.String("resource", resource))
+		}
+	}
+	return nil
+}
+
+// moduleRoutes returns the routes of the modules, in APIVersionV1 unless they set a Version.
+func (app *App) moduleRoutes() []VersionedRoute {
+	var routes []VersionedRoute
+	for _, module := range app.modules {
+		routeModule, ok := module.(RouteModule)
+		if !ok {
+			continue
+		}
+		for _, route := range routeModule.Routes() {
+			if route.Version == "" {
+				route.Version = APIVersionV1
+			}
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+// moduleHealthChecks returns the health checks of the modules.
+func (app *App) moduleHealthChecks() []healthcheck.Check {
+	var checks []healthcheck.Check
+	for _, module := range app.modules {
+		if healthModule, ok := module.(HealthCheckModule); ok {
+			checks = append(checks, healthModule.HealthChecks()...)
+		}
+	}
+	return checks
+}
+
+// moduleJobs returns the background jobs of the modules.
+func (app *App) moduleJobs() []jobs.Job {
+	var all []jobs.Job
+	for _, module := range app.modules {
+		if jobModule, ok := module.(JobModule); ok {
+			all = append(all, jobModule.Jobs()...)
+		}
+	}
+	return all
+}
+
+// newUIModules assembles the manifests of the UI modules: those of the registered modules, in
+// registration order, then the modules only named in -ui-module-remote-entries, by name.
+func (app *App) newUIModules() ([]models.UIModule, error) {
+	// Validated at startup
+	remoteEntries, err := config.ParseRemoteEntries(app.config.UIModuleRemoteEntries)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := []models.UIModule{}
+	for _, module := range app.modules {
+		uiModule, ok := module.(UIModule)
+		if !ok {
+			continue
+		}
+		manifest := uiModule.UIManifest()
+		if manifest.Name == "" {
+			manifest.Name = module.Name()
+		}
+		manifests = append(manifests, manifest)
+	}
+	configured := slices.Sorted(maps.Keys(remoteEntries))
+	for _, name := range configured {
+		if !slices.ContainsFunc(manifests, func(m models.UIModule) bool { return m.Name == name }) {
+			manifests = append(manifests, models.UIModule{Name: name})
+		}
+	}
+
+	names := map[string]bool{}
+	for i := range manifests {
+		manifest := &manifests[i]
+		if remoteEntry, ok := remoteEntries[manifest.Name]; ok {
+			manifest.RemoteEntry = remoteEntry
+		}
+		if manifest.Routes == nil {
+			manifest.Routes = []models.UIModuleRoute{}
+		}
+		if manifest.Permissions == nil {
+			manifest.Permissions = []models.PermissionRequest{}
+		}
+		if err := validation.Validate(manifest); err != nil {
+			return nil, fmt.Errorf("invalid UI module %q: %w", manifest.Name, err)
+		}
+		if names[manifest.Name] {
+			return nil, fmt.Errorf("UI module %q is registered twice", manifest.Name)
+		}
+		names[manifest.Name] = true
+	}
+	return manifests, nil
+}
+
+// closeModules closes the modules implementing io.Closer, in reverse order of initialization.
+func (app *App) closeModules() {
+	for _, module := range slices.Backward(app.modules) {
+		if closer, ok := module.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				app.logger.Warn("failed to close module", "module", module.Name(), "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+)
+
+type UIModulesEnvelope Envelope[[]models.UIModule, None]
+
+// GetUIModulesHandler serves the manifest of the federated UI modules, so a host shell can
+// discover and load their frontends. It is the same for every user: the shell checks the
+// permissions of each module with PermissionsBatchPath.
+func (app *App) GetUIModulesHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	manifests := app.uiModules
+	if manifests == nil {
+		var err error
+		if manifests, err = app.newUIModules(); err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	if err := app.WriteJSON(w, http.StatusOK, UIModulesEnvelope{Data: manifests}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type uiTestModule struct {
+	testModule
+	manifest models.UIModule
+}
+
+func (m *uiTestModule) UIManifest() models.UIModule { return m.manifest }
+
+func TestGetUIModulesHandler(t *testing.T) {
+	registerTestModules(t,
+		&uiTestModule{testModule: testModule{name: "notebooks"}, manifest: models.UIModule{
+			RemoteEntry: "/notebooks/remoteEntry.js",
+			Routes:      []models.UIModuleRoute{{Path: "/notebooks/*", Exposed: "./NotebooksPage", Title: "Notebooks"}},
+			Permissions: []models.PermissionRequest{{Verb: "list", Group: "kubeflow.org", Resource: "notebooks"}},
+		}},
+		&uiTestModule{testModule: testModule{name: "pipelines"}, manifest: models.UIModule{RemoteEntry: "/pipelines/remoteEntry.js"}},
+		&testModule{name: "backend-only"},
+	)
+
+	app := newWatchTestApp(t)
+	app.config.UIModuleRemoteEntries = []string{"pipelines=https://pipelines.example.com/remoteEntry.js", "catalog=/catalog/remoteEntry.js"}
+	var err error
+	app.modules, err = app.newModules()
+	require.NoError(t, err)
+	app.uiModules, err = app.newUIModules()
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, ModulesPath, nil)
+	req.Header.Set(constants.KubeflowUserIDHeader, "user@example.com")
+	rr := httptest.NewRecorder()
+	app.Routes().ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var envelope UIModulesEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &envelope))
+	assert.Equal(t, []models.UIModule{
+		{
+			Name:        "notebooks",
+			RemoteEntry: "/notebooks/remoteEntry.js",
+			Routes:      []models.UIModuleRoute{{Path: "/notebooks/*", Exposed: "./NotebooksPage", Title: "Notebooks"}},
+			Permissions: []models.PermissionRequest{{Verb: "list", Group: "kubeflow.org", Resource: "notebooks"}},
+		},
+		{Name: "pipelines", RemoteEntry: "https://pipelines.example.com/remoteEntry.js", Routes: []models.UIModuleRoute{}, Permissions: []models.PermissionRequest{}},
+		{Name: "catalog", RemoteEntry: "/catalog/remoteEntry.js", Routes: []models.UIModuleRoute{}, Permissions: []models.PermissionRequest{}},
+	}, envelope.Data)
+}
+
+func TestNewUIModules_Invalid(t *testing.T) {
+	for name, manifest := range map[string]models.UIModule{
+		"remote entry": {},
+		"route":        {RemoteEntry: "/notebooks/remoteEntry.js", Routes: []models.UIModuleRoute{{Path: "/notebooks"}}},
+		"permission":   {RemoteEntry: "/notebooks/remoteEntry.js", Permissions: []models.PermissionRequest{{Verb: "list"}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			registerTestModules(t, &uiTestModule{testModule: testModule{name: "notebooks"}, manifest: manifest})
+			app := newWatchTestApp(t)
+			var err error
+			app.modules, err = app.newModules()
+			require.NoError(t, err)
+			_, err = app.newUIModules()
+			assert.ErrorContains(t, err, `invalid UI module "notebooks"`)
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/healthcheck"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	toolscache "k8s.io/client-go/tools/cache"
+)
+
+type notebooksConfig struct {
+	MaxNotebooks int `config:"notebooks-max"`
+}
+
+type testModule struct {
+	name     string
+	config   notebooksConfig
+	app      *App
+	initErr  error
+	handlers map[string]toolscache.ResourceEventHandler
+	closed   bool
+}
+
+func (m *testModule) Name() string { return m.name }
+func (m *testModule) Config() any  { return &m.config }
+func (m *testModule) Close() error { m.closed = true; return nil }
+
+func (m *testModule) Init(app *App) error {
+	m.app = app
+	return m.initErr
+}
+
+func (m *testModule) Routes() []VersionedRoute {
+	return []VersionedRoute{
+		{Method: http.MethodGet, Path: "/notebooks/:name", Handler: versionHandler("v1")},
+		{Version: "v2", Method: http.MethodGet, Path: "/notebooks/:name", Handler: versionHandler("v2")},
+	}
+}
+
+func (m *testModule) HealthChecks() []healthcheck.Check {
+	return []healthcheck.Check{{Name: m.name, Func: func(context.Context) error { return errors.New("unreachable") }}}
+}
+
+func (m *testModule) EventHandlers() map[string]toolscache.ResourceEventHandler {
+	return m.handlers
+}
+
+func registerTestModules(t *testing.T, registered ...Module) {
+	t.Helper()
+	for _, module := range registered {
+		RegisterModule(module)
+	}
+	t.Cleanup(func() {
+		moduleMu.Lock()
+		defer moduleMu.Unlock()
+		modules = nil
+	})
+}
+
+func TestModules(t *testing.T) {
+	module := &testModule{name: "notebooks", config: notebooksConfig{MaxNotebooks: 10}, handlers: map[string]toolscache.ResourceEventHandler{
+		config.CacheResourceNamespaces: toolscache.ResourceEventHandlerFuncs{},
+	}}
+	registerTestModules(t, module)
+	assert.Equal(t, []any{&module.config}, ModuleConfigs())
+
+	app := newWatchTestApp(t)
+	var err error
+	app.modules, err = app.newModules()
+	require.NoError(t, err)
+	assert.Same(t, app, module.app)
+	app.versionedRoutes, app.deprecations, err = app.newVersionedRoutes()
+	require.NoError(t, err)
+	app.healthChecks, err = app.newHealthChecks()
+	require.NoError(t, err)
+	routes := app.Routes()
+
+	for path, want := range map[string]string{
+		"/api/v1/notebooks/lab":              `{"data": "v1 lab"}`,
+		"/api/v2/notebooks/lab":              `{"data": "v2 lab"}`,
+		PathPrefix + "/api/v2/notebooks/lab": `{"data": "v2 lab"}`,
+	} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(constants.KubeflowUserIDHeader, "user@example.com")
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, path)
+		assert.JSONEq(t, want, rr.Body.String(), path)
+	}
+
+	// Module routes are behind the shared middleware
+	rr := httptest.NewRecorder()
+	routes.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v2/notebooks/lab", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = httptest.NewRecorder()
+	routes.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, ReadyzPath, nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"name":"notebooks"`)
+
+	app.closeModules()
+	assert.True(t, module.closed)
+}
+
+func TestNewModules_Invalid(t *testing.T) {
+	for name, module := range map[string]*testModule{
+		"name":     {name: "Notebooks"},
+		"init":     {name: "notebooks", initErr: errors.New("no registry")},
+		"resource": {name: "notebooks", handlers: map[string]toolscache.ResourceEventHandler{"pods": toolscache.ResourceEventHandlerFuncs{}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			registerTestModules(t, module)
+			_, err := newWatchTestApp(t).newModules()
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("duplicate", func(t *testing.T) {
+		registerTestModules(t, &testModule{name: "notebooks"}, &testModule{name: "notebooks"})
+		_, err := newWatchTestApp(t).newModules()
+		assert.ErrorContains(t, err, `module "notebooks" is registered twice`)
+	})
+}
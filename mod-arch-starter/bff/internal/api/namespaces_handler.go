@@ -1,17 +1,40 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
 	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
 	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/operations"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/pagination"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/provisioning"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/validation"
 
 	"github.com/julienschmidt/httprouter"
 )
 
-type NamespacesEnvelope Envelope[[]models.NamespaceModel, None]
+// NamespaceProvisioningOperation is the type of the operations started by
+// ProvisionNamespaceHandler.
+const NamespaceProvisioningOperation = "namespace-provisioning"
+
+type NamespacesEnvelope PagedResponse[models.NamespaceModel]
+
+type NamespaceProvisioningEnvelope Envelope[*models.NamespaceProvisioningRequest, None]
+
+var namespaceFields = pagination.Fields[models.NamespaceModel]{
+	"name": func(ns models.NamespaceModel) string { return ns.Name },
+	"displayName": func(ns models.NamespaceModel) string {
+		if ns.DisplayName == nil {
+			return ""
+		}
+		return *ns.DisplayName
+	},
+}
 
 func (app *App) GetNamespacesHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 
@@ -22,6 +45,12 @@ func (app *App) GetNamespacesHandler(w http.ResponseWriter, r *http.Request, _ h
 		return
 	}
 
+	opts, err := pagination.ParseListOptions(r.URL.Query())
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
 	client, err := app.kubernetesClientFactory.GetClient(ctx)
 	if err != nil {
 		app.serverErrorResponse(w, r, fmt.Errorf("failed to get Kubernetes client: %w", err))
@@ -30,12 +59,19 @@ func (app *App) GetNamespacesHandler(w http.ResponseWriter, r *http.Request, _ h
 
 	namespaces, err := app.repositories.Namespace.GetNamespaces(client, ctx, identity)
 	if err != nil {
-		app.serverErrorResponse(w, r, err)
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+
+	page, metadata, err := pagination.Apply(namespaces, opts, namespaceFields)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
 		return
 	}
 
 	namespacesEnvelope := NamespacesEnvelope{
-		Data: namespaces,
+		Data:     page,
+		Metadata: metadata,
 	}
 
 	err = app.WriteJSON(w, http.StatusOK, namespacesEnvelope, nil)
@@ -44,3 +80,65 @@ func (app *App) GetNamespacesHandler(w http.ResponseWriter, r *http.Request, _ h
 		app.serverErrorResponse(w, r, err)
 	}
 }
+
+// ProvisionNamespaceHandler creates a namespace and its companion resources from a template of
+// the -namespace-templates-dir set, as a namespace-provisioning operation answered with a 202.
+// The access check, the name conflict and the template are checked first and answered right
+// away; the operation then reports each object created, and rolls them back on failure.
+func (app *App) ProvisionNamespaceHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var body NamespaceProvisioningEnvelope
+	if err := app.ReadValidJSON(w, r, &body); err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if body.Data == nil {
+		app.apiErrorResponse(w, r, validation.Invalid("data", "is required"))
+		return
+	}
+	request := *body.Data
+	if request.Template == "" {
+		request.Template = provisioning.DefaultTemplate
+	}
+
+	ctx := r.Context()
+	identity, ok := ctx.Value(constants.RequestIdentityKey).(*kubernetes.RequestIdentity)
+	if !ok || identity == nil {
+		app.badRequestResponse(w, r, fmt.Errorf("missing RequestIdentity in context"))
+		return
+	}
+	manifests, err := app.namespaceTemplates.Render(request.Template, provisioning.Data{
+		Name:        request.Name,
+		DisplayName: request.DisplayName,
+		Description: request.Description,
+		Requester:   identity.UserID,
+		Parameters:  request.Parameters,
+	})
+	if errors.Is(err, provisioning.ErrUnknownTemplate) {
+		app.apiErrorResponse(w, r, validation.Invalid("data.template", fmt.Sprintf("must be one of %s", strings.Join(app.namespaceTemplates.Names(), ", "))))
+		return
+	}
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	client, err := app.kubernetesClientFactory.GetClient(ctx)
+	if err != nil {
+		app.serverErrorResponse(w, r, fmt.Errorf("failed to get Kubernetes client: %w", err))
+		return
+	}
+	if err := app.repositories.Namespace.CheckProvision(client, ctx, identity, request.Name); err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+
+	app.StartOperation(w, r, NamespaceProvisioningOperation, func(ctx context.Context, progress *operations.Progress) (any, error) {
+		objects, err := app.repositories.Namespace.Provision(client, ctx, identity, manifests, func(percent int, message string) {
+			progress.Report(ctx, percent, message)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return models.NamespaceProvisioningModel{Namespace: request.Name, Template: request.Template, Objects: objects}, nil
+	})
+}
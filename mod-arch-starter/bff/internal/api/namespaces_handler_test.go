@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/operations"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/provisioning"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvisionNamespaceHandler(t *testing.T) {
+	app := newWatchTestApp(t)
+	post := func(user, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, NamespacePath, strings.NewReader(body))
+		req.Header.Set(constants.KubeflowUserIDHeader, user)
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		app.Routes().ServeHTTP(rr, req)
+		return rr
+	}
+	assert.Equal(t, http.StatusMethodNotAllowed, post("user@example.com", `{"data": {"name": "team-a"}}`).Code, "disabled by default")
+
+	var err error
+	app.namespaceTemplates, err = provisioning.Load("")
+	require.NoError(t, err)
+	app.operations, err = app.newOperations()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = app.operations.Stop(context.Background()) })
+
+	rr := post("user@example.com", `{"data": {"name": "team-a", "displayName": "Team A", "parameters": {"cpu": "16"}}}`)
+	require.Equal(t, http.StatusAccepted, rr.Code, rr.Body.String())
+	var started OperationEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &started))
+	assert.Equal(t, NamespaceProvisioningOperation, started.Data.Type)
+	assert.Equal(t, OperationsPath+"/"+started.Data.ID, rr.Header().Get("Location"))
+
+	var op operations.Operation
+	require.Eventually(t, func() bool {
+		op, err = app.operations.Get(context.Background(), started.Data.ID)
+		return err == nil && op.Status != operations.StatusRunning
+	}, 5*time.Second, time.Millisecond)
+	require.Equal(t, operations.StatusSucceeded, op.Status, op.Error)
+	result, err := json.Marshal(op.Result)
+	require.NoError(t, err)
+	assert.Contains(t, string(result), `"namespace":"team-a"`)
+	assert.Contains(t, string(result), `"kind":"ResourceQuota"`)
+
+	rr = post("user@example.com", `{"data": {"name": "team-a"}}`)
+	assert.Equal(t, http.StatusConflict, rr.Code, rr.Body.String())
+	rr = post("doraNonAdmin@example.com", `{"data": {"name": "team-b"}}`)
+	assert.Equal(t, http.StatusForbidden, rr.Code, rr.Body.String())
+	rr = post("user@example.com", `{"data": {"name": "team-b", "template": "gpu"}}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "must be one of default")
+	rr = post("user@example.com", `{"data": {"name": "Team B"}}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "data.name")
+}
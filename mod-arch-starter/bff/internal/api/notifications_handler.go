@@ -0,0 +1,84 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/notifications"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/sse"
+)
+
+// EventNotification is the SSE event type of the notifications.
+const EventNotification = "notification"
+
+// streamHeartbeat is how often a comment is sent on idle notification and operation streams.
+const streamHeartbeat = 30 * time.Second
+
+// NotificationsStreamHandler streams the notifications meant for the requesting user as
+// Server-Sent Events, starting with the recent ones. A reconnecting EventSource sends the ID of
+// the last notification it got in Last-Event-ID, and replays the ones it missed; when the ID is
+// unknown (it comes from another replica, or the notifications after it aged out) the stream
+// starts with sse.EventResync, then the whole history.
+func (app *App) NotificationsStreamHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	ctx := r.Context()
+	identity, ok := ctx.Value(constants.RequestIdentityKey).(*kubernetes.RequestIdentity)
+	if !ok || identity == nil {
+		app.badRequestResponse(w, r, fmt.Errorf("missing RequestIdentity in context"))
+		return
+	}
+
+	lastID := r.Header.Get("Last-Event-ID")
+	replay, resumed, sub := app.notifications.Subscribe(lastID, func(n notifications.Notification) bool {
+		return n.For(identity.UserID, identity.Groups)
+	})
+	defer sub.Close()
+
+	stream, err := sse.NewStream(w)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	streamCtx, cancel := app.streamContext(ctx)
+	defer cancel()
+
+	send := func(n notifications.Notification) error {
+		return stream.Send(sse.Event{ID: n.ID, Event: EventNotification, Data: n})
+	}
+	if !resumed {
+		if err := stream.Send(sse.Event{Event: sse.EventResync}); err != nil {
+			return
+		}
+	}
+	for _, n := range replay {
+		if err := send(n); err != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-streamCtx.Done():
+			return
+		case n, ok := <-sub.C:
+			if !ok {
+				// Too far behind: the client reconnects and replays what it missed
+				logger.FromRequest(r).Debug("notification stream dropped, the client lagged behind")
+				return
+			}
+			if err := send(n); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if err := stream.Comment("heartbeat"); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/notifications"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationsStreamHandler(t *testing.T) {
+	app := newWatchTestApp(t)
+	app.notifications = notifications.New(10)
+	identity := &kubernetes.RequestIdentity{UserID: "doraNonAdmin@example.com", Groups: []string{"dora-team"}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(context.WithValue(r.Context(), constants.RequestIdentityKey, identity))
+		app.NotificationsStreamHandler(w, r, nil)
+	}))
+	defer server.Close()
+
+	// connect streams the notifications; next returns the data line of the next event of type
+	// event, or "" when the stream ends first
+	connect := func(lastID string) (next func(event string) string, closeStream func()) {
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		if lastID != "" {
+			req.Header.Set("Last-Event-ID", lastID)
+		}
+		resp, err := server.Client().Do(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+		lines := bufio.NewScanner(resp.Body)
+		return func(event string) string {
+			for lines.Scan() {
+				if lines.Text() == "event: "+event && lines.Scan() {
+					return lines.Text()
+				}
+			}
+			return ""
+		}, func() { _ = resp.Body.Close() }
+	}
+
+	first := app.notifications.Publish(notifications.Notification{Type: "resource.created", Title: "for the team", Groups: []string{"dora-team"}})
+	app.notifications.Publish(notifications.Notification{Title: "for bella", Users: []string{"bellaNonAdmin@example.com"}})
+
+	next, closeStream := connect("")
+	assert.Contains(t, next(EventNotification), `"title":"for the team"`, "replays the recent notifications")
+	app.notifications.Publish(notifications.Notification{Title: "for dora", Severity: notifications.SeverityError, Users: []string{identity.UserID}})
+	live := next(EventNotification)
+	assert.Contains(t, live, `"title":"for dora"`, "skips those of other users")
+	assert.Contains(t, live, `"severity":"error"`)
+	closeStream()
+
+	next, closeStream = connect(first.ID)
+	assert.Contains(t, next(EventNotification), `"title":"for dora"`, "resumes after Last-Event-ID")
+	closeStream()
+
+	next, closeStream = connect("other-replica-1")
+	assert.Equal(t, "data: ", next("resync"), "an unknown Last-Event-ID resyncs")
+	assert.Contains(t, next(EventNotification), `"title":"for the team"`)
+	closeStream()
+}
@@ -0,0 +1,264 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/apierrors"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/objectstore"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/upload"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/validation"
+)
+
+type BucketsEnvelope Envelope[[]objectstore.Bucket, None]
+type ObjectsEnvelope Envelope[objectstore.ObjectList, None]
+type ObjectEnvelope Envelope[objectstore.ObjectInfo, None]
+type UploadedObjectsEnvelope Envelope[[]objectstore.ObjectInfo, None]
+type PresignRequestEnvelope Envelope[models.PresignRequest, None]
+type PresignedURLEnvelope Envelope[models.PresignedURL, None]
+
+// objectStorageRequest returns the client of the object storage of the Secret named in the
+// path, read on behalf of the user in the namespace (AttachNamespace), writing an error
+// response when it fails.
+func (app *App) objectStorageRequest(w http.ResponseWriter, r *http.Request, ps httprouter.Params) (*objectstore.Client, bool) {
+	identity, namespace, client, ok := app.namespacedRequest(w, r)
+	if !ok {
+		return nil, false
+	}
+	storage, err := app.repositories.ObjectStorage.Client(client, r.Context(), identity, namespace, ps.ByName("secret"))
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return nil, false
+	}
+	return storage, true
+}
+
+// objectKey returns the required key query parameter.
+func objectKey(r *http.Request) (string, error) {
+	key := r.URL.Query().Get("key")
+	if key == "" {
+		return "", validation.Invalid("key", "is required")
+	}
+	return key, nil
+}
+
+// GetBucketsHandler lists the buckets of the credentials of the Secret named in the path.
+func (app *App) GetBucketsHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	storage, ok := app.objectStorageRequest(w, r, ps)
+	if !ok {
+		return
+	}
+	buckets, err := storage.ListBuckets(r.Context())
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if err := app.WriteJSON(w, http.StatusOK, BucketsEnvelope{Data: buckets}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// GetObjectsHandler lists a page of the objects of the bucket query parameter, by default the
+// bucket of the Secret, whose key starts with prefix. Keys are grouped into prefixes, like
+// folders, by the delimiter ("/" unless set, empty lists every object). pageSize bounds the
+// page and the nextPageToken of the response lists the next one.
+func (app *App) GetObjectsHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	query := r.URL.Query()
+	opts := objectstore.ListOptions{
+		Prefix:            query.Get("prefix"),
+		Delimiter:         "/",
+		ContinuationToken: query.Get("nextPageToken"),
+	}
+	if query.Has("delimiter") {
+		opts.Delimiter = query.Get("delimiter")
+	}
+	if value := query.Get("pageSize"); value != "" {
+		pageSize, err := strconv.Atoi(value)
+		if err != nil || pageSize < 1 || pageSize > objectstore.MaxListKeys {
+			app.badRequestResponse(w, r, fmt.Errorf("invalid pageSize %q: must be between 1 and %d", value, objectstore.MaxListKeys))
+			return
+		}
+		opts.MaxKeys = pageSize
+	}
+	storage, ok := app.objectStorageRequest(w, r, ps)
+	if !ok {
+		return
+	}
+
+	list, err := storage.ListObjects(r.Context(), query.Get("bucket"), opts)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if err := app.WriteJSON(w, http.StatusOK, ObjectsEnvelope{Data: list}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// DownloadObjectHandler streams the object key of the bucket query parameter, by default the
+// bucket of the Secret, as an attachment. Objects larger than -object-storage-download-limit
+// are refused: the browser downloads them with a presigned URL instead.
+func (app *App) DownloadObjectHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	key, err := objectKey(r)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	storage, ok := app.objectStorageRequest(w, r, ps)
+	if !ok {
+		return
+	}
+
+	object, err := storage.GetObject(r.Context(), r.URL.Query().Get("bucket"), key)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	defer object.Body.Close()
+	limit := app.config.ObjectStorageDownloadLimit
+	if object.Size > limit {
+		err := apierrors.BadRequest(fmt.Sprintf("the object is larger than the download limit of %d bytes, download it with a presigned URL", limit))
+		err.Details = map[string]any{"size": object.Size, "limit": limit}
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+
+	// Large objects outlive the server write timeout; the download limit bounds them
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	header := w.Header()
+	contentType := object.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	header.Set("Content-Type", contentType)
+	// Served as an attachment, so an HTML object never runs in the origin of the BFF
+	header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(key)}))
+	if object.Size >= 0 {
+		header.Set("Content-Length", strconv.FormatInt(object.Size, 10))
+	}
+	if object.ETag != "" {
+		header.Set("ETag", `"`+object.ETag+`"`)
+	}
+	if !object.LastModified.IsZero() {
+		header.Set("Last-Modified", object.LastModified.UTC().Format(http.TimeFormat))
+	}
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, io.LimitReader(object.Body, limit)); err != nil {
+		// The response started: the client sees a truncated download
+		app.requestLogger(r).Warn("object download interrupted", "key", key, "error", err)
+	}
+}
+
+// UploadObjectHandler writes the body of the request to the object key of the bucket query
+// parameter, by default the bucket of the Secret, streaming it to the storage. The request
+// must have a Content-Length, at most -object-storage-upload-limit.
+func (app *App) UploadObjectHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	key, err := objectKey(r)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	limit := app.config.ObjectStorageUploadLimit
+	if r.ContentLength < 0 {
+		app.apiErrorResponse(w, r, apierrors.New(http.StatusLengthRequired, "uploads must have a Content-Length"))
+		return
+	}
+	if r.ContentLength > limit {
+		app.apiErrorResponse(w, r, apierrors.New(http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("the object is larger than the upload limit of %d bytes, upload it with a presigned URL", limit)))
+		return
+	}
+	storage, ok := app.objectStorageRequest(w, r, ps)
+	if !ok {
+		return
+	}
+
+	// Large objects outlive the server timeouts; the upload limit bounds them
+	controller := http.NewResponseController(w)
+	_ = controller.SetReadDeadline(time.Time{})
+	_ = controller.SetWriteDeadline(time.Time{})
+	body := http.MaxBytesReader(w, r.Body, limit)
+	info, err := storage.PutObject(r.Context(), r.URL.Query().Get("bucket"), key, body, r.ContentLength, r.Header.Get("Content-Type"))
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if err := app.WriteJSON(w, http.StatusCreated, ObjectEnvelope{Data: info}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// maxUploadedObjects bounds the files of a form upload.
+const maxUploadedObjects = 100
+
+// UploadObjectsHandler stores the files of a multipart/form-data form, like the file input of
+// a browser, in the bucket query parameter, by default the bucket of the Secret, each one at
+// the key prefix + its file name. The form is at most -object-storage-upload-limit, and its
+// progress is notified to the user.
+func (app *App) UploadObjectsHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	storage, ok := app.objectStorageRequest(w, r, ps)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	var objects []objectstore.ObjectInfo
+	opts := upload.Options{MaxSize: app.config.ObjectStorageUploadLimit, MaxFiles: maxUploadedObjects}
+	_, err := app.ReceiveUpload(w, r, opts, func(ctx context.Context, file *upload.File) error {
+		info, err := storage.Upload(ctx, query.Get("bucket"), query.Get("prefix")+file.Filename, file, file.ContentType)
+		if err != nil {
+			return err
+		}
+		objects = append(objects, info)
+		return nil
+	})
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if err := app.WriteJSON(w, http.StatusCreated, UploadedObjectsEnvelope{Data: objects}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// PresignObjectHandler returns a URL to GET or PUT an object without credentials, valid for
+// expiresInSeconds, by default and at most -object-storage-presign-expiry. The URL addresses
+// the endpoint of the Secret, which the browser must be able to reach.
+func (app *App) PresignObjectHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	var body PresignRequestEnvelope
+	if err := app.ReadValidJSON(w, r, &body); err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	expires := app.config.ObjectStoragePresignExpiry
+	if seconds := body.Data.ExpiresInSeconds; seconds > 0 {
+		requested := time.Duration(seconds) * time.Second
+		if requested > expires {
+			app.apiErrorResponse(w, r, validation.Invalid("data.expiresInSeconds", fmt.Sprintf("must be at most %d", int(expires.Seconds()))))
+			return
+		}
+		expires = requested
+	}
+	storage, ok := app.objectStorageRequest(w, r, ps)
+	if !ok {
+		return
+	}
+
+	presigned, err := storage.PresignURL(body.Data.Method, body.Data.Bucket, body.Data.Key, expires)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	url := models.PresignedURL{URL: presigned, Method: body.Data.Method, ExpiresAt: time.Now().Add(expires).UTC()}
+	if err := app.WriteJSON(w, http.StatusOK, PresignedURLEnvelope{Data: url}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
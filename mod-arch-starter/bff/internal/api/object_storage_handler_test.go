@@ -0,0 +1,136 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/objectstore"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/notifications"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObjectStorageHandlers(t *testing.T) {
+	objects := map[string]string{"models/granite/config.json": `{"layers":40}`}
+	storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/models/")
+		switch {
+		case r.URL.Query().Get("list-type") == "2":
+			_, _ = io.WriteString(w, `<ListBucketResult><Contents><Key>models/granite/config.json</Key><Size>13</Size></Contents></ListBucketResult>`)
+		case r.Method == http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			objects[key] = string(body)
+			w.Header().Set("ETag", `"abc"`)
+		case objects[key] != "":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = io.WriteString(w, objects[key])
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`)
+		}
+	}))
+	defer storage.Close()
+
+	app := newWatchTestApp(t)
+	app.config.ObjectStorageDownloadLimit = 1024
+	app.config.ObjectStorageUploadLimit = 16
+	app.config.ObjectStoragePresignExpiry = 10 * time.Minute
+	app.notifications = notifications.New(0)
+	app.repositories.ObjectStorage.UseClientConfig(objectstore.Config{AllowedHosts: []string{"127.0.0.1"}})
+	routes := app.Routes()
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(constants.KubeflowUserIDHeader, "doraNonAdmin@example.com")
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, req)
+		return rr
+	}
+	encode := func(value string) string { return base64.StdEncoding.EncodeToString([]byte(value)) }
+	rr := serve(http.MethodPost, SecretsPath+"?namespace=dora-namespace", fmt.Sprintf(`{"data":{"name":"models","data":{"AWS_ACCESS_KEY_ID":%q,"AWS_SECRET_ACCESS_KEY":%q,"AWS_S3_ENDPOINT":%q,"AWS_S3_BUCKET":%q}}}`,
+		encode("minio"), encode("minio123"), encode(storage.URL), encode("models")))
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	rr = serve(http.MethodPost, SecretsPath+"?namespace=dora-namespace", `{"data":{"name":"other","data":{"password":"czNjcmV0"}}}`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	base := ApiPathPrefix + "/object-storage/models"
+
+	rr = serve(http.MethodGet, base+"/objects?namespace=dora-namespace&prefix=models/", "")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var list ObjectsEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+	assert.Equal(t, "models", list.Data.Bucket)
+	require.Len(t, list.Data.Objects, 1)
+	assert.Equal(t, int64(13), list.Data.Objects[0].Size)
+
+	rr = serve(http.MethodGet, base+"/object?namespace=dora-namespace&key="+url.QueryEscape("models/granite/config.json"), "")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, `{"layers":40}`, rr.Body.String())
+	assert.Equal(t, `attachment; filename=config.json`, rr.Header().Get("Content-Disposition"), "never rendered by the browser")
+	rr = serve(http.MethodGet, base+"/object?namespace=dora-namespace&key=missing", "")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), "NoSuchKey")
+
+	rr = serve(http.MethodPut, base+"/object?namespace=dora-namespace&key=models/README.md", "# Granite")
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var uploaded ObjectEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &uploaded))
+	assert.Equal(t, "abc", uploaded.Data.ETag)
+	assert.Equal(t, "# Granite", objects["models/README.md"])
+	assert.Equal(t, http.StatusRequestEntityTooLarge, serve(http.MethodPut, base+"/object?namespace=dora-namespace&key=big", strings.Repeat("0", 17)).Code)
+
+	_, _, sub := app.notifications.Subscribe("", func(n notifications.Notification) bool { return n.For("doraNonAdmin@example.com", nil) })
+	defer sub.Close()
+	app.config.ObjectStorageUploadLimit = 1024
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	file, err := writer.CreateFormFile("file", "tokenizer.json")
+	require.NoError(t, err)
+	_, _ = io.WriteString(file, `{"vocab":[]}`)
+	require.NoError(t, writer.Close())
+	req := httptest.NewRequest(http.MethodPost, base+"/objects?namespace=dora-namespace&prefix=models/granite/", &form)
+	req.Header.Set(constants.KubeflowUserIDHeader, "doraNonAdmin@example.com")
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	rr = httptest.NewRecorder()
+	routes.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var uploadedFiles UploadedObjectsEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &uploadedFiles))
+	require.Len(t, uploadedFiles.Data, 1)
+	assert.Equal(t, "models/granite/tokenizer.json", uploadedFiles.Data[0].Key)
+	assert.Equal(t, "application/json", uploadedFiles.Data[0].ContentType, "detected")
+	assert.Equal(t, `{"vocab":[]}`, objects["models/granite/tokenizer.json"])
+	var progress notifications.Notification
+	for progress = range sub.C {
+		if progress.Data.(UploadProgress).Done {
+			break
+		}
+	}
+	assert.Equal(t, NotificationUploadProgress, progress.Type)
+	assert.Equal(t, rr.Header().Get(constants.RequestIDHeader), progress.Data.(UploadProgress).RequestID)
+	assert.Equal(t, http.StatusUnsupportedMediaType, serve(http.MethodPost, base+"/objects?namespace=dora-namespace", "{}").Code)
+
+	rr = serve(http.MethodPost, base+"/presign?namespace=dora-namespace", `{"data":{"key":"models/granite/config.json","method":"GET","expiresInSeconds":60}}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var presigned PresignedURLEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &presigned))
+	assert.True(t, strings.HasPrefix(presigned.Data.URL, storage.URL+"/models/models/granite/config.json?"), presigned.Data.URL)
+	assert.Contains(t, presigned.Data.URL, "X-Amz-Expires=60")
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, base+"/presign?namespace=dora-namespace", `{"data":{"key":"a","method":"GET","expiresInSeconds":3600}}`).Code,
+		"longer than the configured expiry")
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, base+"/presign?namespace=dora-namespace", `{"data":{"key":"a","method":"DELETE"}}`).Code)
+
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodGet, ApiPathPrefix+"/object-storage/other/objects?namespace=dora-namespace", "").Code,
+		"not object storage credentials")
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, ApiPathPrefix+"/object-storage/missing/objects?namespace=dora-namespace", "").Code)
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodGet, ApiPathPrefix+"/object-storage/models/objects?namespace=bella-namespace", "").Code)
+}
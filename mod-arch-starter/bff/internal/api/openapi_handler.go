@@ -0,0 +1,442 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/apierrors"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/openapi"
+)
+
+var (
+	openAPIOperationsMu sync.RWMutex
+	openAPIOperations   []openapi.Operation
+)
+
+// RegisterOpenAPIOperations adds operations to the document served at /api/v1/openapi.json,
+// typically describing the routes a downstream module adds. This should be called from an
+// init() function in the downstream code; request and response schemas are generated from
+// the Go types of Request and Response.
+//
+// Example usage in downstream code:
+//
+//	func init() {
+//	    api.RegisterOpenAPIOperations(openapi.Operation{
+//	        Method:     http.MethodGet,
+//	        Path:       api.ApiPathPrefix + "/notebooks",
+//	        Summary:    "List the notebooks of a namespace",
+//	        Tags:       []string{"notebooks"},
+//	        Parameters: []openapi.Parameter{openapi.Query("namespace", "Namespace of the notebooks", true)},
+//	        Response:   NotebooksEnvelope{},
+//	    })
+//	}
+func RegisterOpenAPIOperations(ops ...openapi.Operation) { //nolint:unused
+	openAPIOperationsMu.Lock()
+	defer openAPIOperationsMu.Unlock()
+	openAPIOperations = append(openAPIOperations, ops...)
+}
+
+// newOpenAPISpec builds the JSON document of the starter and registered operations.
+func (app *App) newOpenAPISpec() ([]byte, error) {
+	openAPIOperationsMu.RLock()
+	registered := append([]openapi.Operation(nil), openAPIOperations...)
+	openAPIOperationsMu.RUnlock()
+	operations := append(starterOperations(), registered...)
+	if app.debugEndpointsOnAPIPort() {
+		operations = append(operations, openapi.Operation{Method: http.MethodGet, Path: DebugRuntimePath, ID: "getRuntimeInfo", Tags: []string{"debug"},
+			Summary: "Get the goroutines, memory, garbage collector statistics and build information (cluster admins only)", Response: RuntimeInfoEnvelope{}},
+			openapi.Operation{Method: http.MethodGet, Path: DebugPreflightPath, ID: "getPreflightReport", Tags: []string{"debug"},
+				Summary: "Check the permissions of the service account, the upstreams and the TLS files (cluster admins only)", Response: PreflightEnvelope{}})
+	}
+	if app.operations != nil {
+		operations = append(operations, openapi.Operation{Method: http.MethodGet, Path: OperationPath, ID: "getOperation", Tags: []string{"operations"},
+			Summary:  "Get an operation of the user; follow it as Server-Sent Events until it completes when text/event-stream is accepted",
+			Response: OperationEnvelope{}})
+	}
+	if app.namespaceTemplates != nil && app.operations != nil {
+		operations = append(operations, openapi.Operation{Method: http.MethodPost, Path: NamespacePath, ID: "provisionNamespace", Tags: []string{"namespaces"},
+			Summary:     "Create a namespace and its companion resources from a template, as an operation",
+			Description: "The operation result lists the objects created; they are deleted again when one of them can't be created.",
+			Request:     NamespaceProvisioningEnvelope{}, Response: OperationEnvelope{}, Status: http.StatusAccepted})
+	}
+	if app.config.ServiceAccountTokens {
+		operations = append(operations, openapi.Operation{Method: http.MethodPost, Path: TokenRequestPath, ID: "createServiceAccountToken", Tags: []string{"serviceaccounts"},
+			Summary:     "Mint a short-lived token of a ServiceAccount, e.g. to connect an external tool",
+			Description: "The token is valid up to the configured ceiling and scoped to the allowed audiences; it is returned once and never stored.",
+			Parameters:  []openapi.Parameter{namespaceParameter},
+			Request:     ServiceAccountTokenRequestEnvelope{}, Response: ServiceAccountTokenEnvelope{}, Status: http.StatusCreated})
+	}
+	if app.chaos != nil {
+		operations = append(operations,
+			openapi.Operation{Method: http.MethodGet, Path: ChaosPath, ID: "getChaosRules", Tags: []string{"debug"},
+				Summary: "Get the fault injection rules (cluster admins only)", Response: ChaosRulesEnvelope{}},
+			openapi.Operation{Method: http.MethodPut, Path: ChaosPath, ID: "setChaosRules", Tags: []string{"debug"},
+				Summary:     "Replace the fault injection rules (cluster admins only)",
+				Description: "Each rule delays, fails or drops the requests of a route, or the calls to an upstream; the first matching rule applies.",
+				Request:     ChaosRulesEnvelope{}, Response: ChaosRulesEnvelope{}},
+			openapi.Operation{Method: http.MethodDelete, Path: ChaosPath, ID: "deleteChaosRules", Tags: []string{"debug"},
+				Summary: "Remove the fault injection rules (cluster admins only)", Status: http.StatusNoContent})
+	}
+	if app.graphqlSchema != nil {
+		operations = append(operations,
+			openapi.Operation{Method: http.MethodGet, Path: GraphQLPath, ID: "getGraphQL", Tags: []string{"graphql"},
+				Summary:     "Run a GraphQL query, or get the schema in SDL without query",
+				Description: "Without query, the schema is returned as text/plain.",
+				Parameters: []openapi.Parameter{
+					openapi.Query("query", "GraphQL query", false),
+					openapi.Query("operationName", "Operation of the query to run", false),
+					openapi.Query("variables", "JSON object of the variables of the operation", false),
+				},
+				Response: GraphQLResponse{}},
+			openapi.Operation{Method: http.MethodPost, Path: GraphQLPath, ID: "postGraphQL", Tags: []string{"graphql"},
+				Summary:  "Run a GraphQL query",
+				Request:  GraphQLRequest{},
+				Response: GraphQLResponse{}})
+	}
+	if app.config.ObjectStorageDownloadLimit > 0 {
+		operations = append(operations, openapi.Operation{Method: http.MethodGet, Path: ObjectPath, ID: "downloadObject", Tags: []string{"object-storage"},
+			Summary:     "Download an object, up to the download limit",
+			Parameters:  []openapi.Parameter{namespaceParameter, bucketParameter, objectKeyParameter},
+			ContentType: "application/octet-stream", Response: &openapi.Schema{Type: "string", Format: "binary"}})
+	}
+	if app.config.ObjectStorageUploadLimit > 0 {
+		operations = append(operations,
+			openapi.Operation{Method: http.MethodPut, Path: ObjectPath, ID: "uploadObject", Tags: []string{"object-storage"},
+				Summary:     "Upload an object, up to the upload limit",
+				Description: "The body of the request, with its Content-Length and Content-Type, is the content of the object.",
+				Parameters:  []openapi.Parameter{namespaceParameter, bucketParameter, objectKeyParameter},
+				Response:    ObjectEnvelope{}, Status: http.StatusCreated},
+			openapi.Operation{Method: http.MethodPost, Path: ObjectsPath, ID: "uploadObjects", Tags: []string{"object-storage"},
+				Summary: "Upload the files of a form, up to the upload limit",
+				Description: "Each file of the multipart/form-data body is stored at the key prefix + its file name. " +
+					"The progress is notified to the user as upload.progress notifications.",
+				Parameters: []openapi.Parameter{
+					namespaceParameter,
+					bucketParameter,
+					openapi.Query("prefix", "Prefix of the keys of the files, e.g. models/granite/", false),
+				},
+				Request: &openapi.Schema{Type: "object", Properties: map[string]*openapi.Schema{
+					"file": {Type: "array", Items: &openapi.Schema{Type: "string", Format: "binary"}},
+				}},
+				RequestContentType: "multipart/form-data",
+				Response:           UploadedObjectsEnvelope{}, Status: http.StatusCreated})
+	}
+	if app.sessions != nil {
+		operations = append(operations,
+			openapi.Operation{Method: http.MethodGet, Path: LoginPath, ID: "login", Tags: []string{"session"}, Public: true,
+				Summary:    "Sign in: redirect to the OIDC provider, then to returnTo once signed in",
+				Parameters: []openapi.Parameter{openapi.Query("returnTo", "Path to land on once signed in (default /)", false)},
+				Status:     http.StatusFound},
+			openapi.Operation{Method: http.MethodGet, Path: LoginCallbackPath, ID: "loginCallback", Tags: []string{"session"}, Public: true,
+				Summary: "Complete the sign-in redirected back by the OIDC provider and start the session", Status: http.StatusFound},
+			openapi.Operation{Method: http.MethodPost, Path: LogoutPath, ID: "logout", Tags: []string{"session"}, Public: true,
+				Summary: "Sign out: revoke the tokens of the session and clear its cookie", Response: LogoutEnvelope{}})
+	}
+	for i, op := range operations {
+		if _, ok := app.deprecations[op.Method+" "+op.Path]; ok {
+			operations[i].Deprecated = true
+		}
+	}
+
+	builder := openapi.NewBuilder(openapi.Info{
+		Title:   "Mod Arch BFF API",
+		Version: Version,
+	}).
+		AddServer(openapi.Server{URL: "/"}).
+		AddServer(openapi.Server{URL: PathPrefix, Description: "Behind the " + PathPrefix + " path prefix"}).
+		AddSecurityScheme("bearerToken", openapi.SecurityScheme{Type: "http", Scheme: "bearer", Description: "Kubernetes token (user_token auth method)"}).
+		AddSecurityScheme("kubeflowUserId", openapi.SecurityScheme{Type: "apiKey", In: "header", Name: constants.KubeflowUserIDHeader, Description: "User set by the authenticating proxy (internal auth method)"}).
+		ErrorResponse(apierrors.ErrorEnvelope{}).
+		Add(operations...)
+
+	doc, err := builder.Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OpenAPI document: %w", err)
+	}
+	return json.Marshal(doc)
+}
+
+// OpenAPISpec returns the JSON OpenAPI document of the API.
+func (app *App) OpenAPISpec() ([]byte, error) {
+	if app.openAPISpec != nil {
+		return app.openAPISpec, nil
+	}
+	return app.newOpenAPISpec()
+}
+
+// OpenAPIHandler serves the OpenAPI document of the API. Like the healthcheck it is served
+// without authentication.
+func (app *App) OpenAPIHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	spec, err := app.OpenAPISpec()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// The same for every caller, unlike the rest of the API
+	w.Header().Set("Cache-Control", "public, no-cache")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(spec)
+}
+
+// apiDocsHandler serves Swagger UI for the OpenAPI document; it is only routed in dev mode.
+func (app *App) apiDocsHandler() httprouter.Handle {
+	// Relative to /api/v1/docs/, so it also resolves behind PathPrefix
+	ui := http.StripPrefix(APIDocsPath, openapi.SwaggerUIHandler("Mod Arch BFF API", "../openapi.json"))
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		if ps.ByName("filepath") == "" {
+			// Relative, unlike http.Redirect, which would drop PathPrefix
+			w.Header().Set("Location", "docs/")
+			w.WriteHeader(http.StatusMovedPermanently)
+			return
+		}
+		ui.ServeHTTP(w, r)
+	}
+}
+
+var namespaceParameter = openapi.Query(string(constants.NamespaceHeaderParameterKey), "Namespace of the request", true)
+
+// dryRunParameter previews a write, answered with the changes in the metadata instead.
+var dryRunParameter = openapi.Parameter{Name: "dryRun", Description: "Only preview the write with a server-side dry run, returning the changes in the metadata", Schema: &openapi.Schema{Type: "boolean"}}
+
+// forceParameter takes over the fields of a server-side apply other field managers own.
+var forceParameter = openapi.Parameter{Name: "force", Description: "Take over the keys other field managers own instead of failing with a 409", Schema: &openapi.Schema{Type: "boolean"}}
+
+// bucketParameter and objectKeyParameter address an object of the object storage.
+var (
+	bucketParameter    = openapi.Query("bucket", "Bucket (default the bucket of the Secret)", false)
+	objectKeyParameter = openapi.Query("key", "Key of the object", true)
+)
+
+// listParameters are the query parameters of pagination.ParseListOptions.
+var listParameters = []openapi.Parameter{
+	{Name: "page", Description: "Page number, from 1 (requires pageSize)", Schema: &openapi.Schema{Type: "integer"}},
+	{Name: "pageSize", Description: "Number of items per page (1-1000)", Schema: &openapi.Schema{Type: "integer"}},
+	openapi.Query("orderBy", "Field to sort by, prefixed with - for descending order", false),
+	openapi.Query("filter", "Comma-separated field:value or value terms, matched as case-insensitive substrings", false),
+	openapi.Query("nextPageToken", "Token of the page to return, from the previous page", false),
+}
+
+var modelRegistryListParameters = []openapi.Parameter{
+	namespaceParameter,
+	{Name: "pageSize", Description: "Number of items per page (1-1000)", Schema: &openapi.Schema{Type: "integer", Format: "int32"}},
+	{Name: "orderBy", Schema: &openapi.Schema{Type: "string", Enum: []any{"CREATE_TIME", "LAST_UPDATE_TIME", "ID"}}},
+	{Name: "sortOrder", Schema: &openapi.Schema{Type: "string", Enum: []any{"ASC", "DESC"}}},
+	openapi.Query("nextPageToken", "Token of the page to return, from the previous page", false),
+}
+
+// starterOperations describes the routes registered by Routes.
+func starterOperations() []openapi.Operation {
+	registry := []openapi.Parameter{namespaceParameter}
+	return []openapi.Operation{
+		{Method: http.MethodGet, Path: UserPath, ID: "getUser", Tags: []string{"user"},
+			Summary: "Get the current user, their groups and whether they are a cluster admin",
+			Parameters: []openapi.Parameter{
+				{Name: "namespaceRoles", Description: "Also list the rules of the user in each namespace they can access", Schema: &openapi.Schema{Type: "boolean"}},
+			},
+			Response: UserEnvelope{}},
+		{Method: http.MethodGet, Path: PreferencesPath, ID: "getPreferences", Tags: []string{"user"},
+			Summary: "Get the UI preferences of the current user", Response: PreferencesEnvelope{}},
+		{Method: http.MethodPut, Path: PreferencesPath, ID: "putPreferences", Tags: []string{"user"},
+			Summary: "Replace the UI preferences of the current user; the resourceVersion read, if any, guards against concurrent changes",
+			Request: PreferencesEnvelope{}, Response: PreferencesEnvelope{}},
+		{Method: http.MethodDelete, Path: PreferencesPath, ID: "deletePreferences", Tags: []string{"user"},
+			Summary:    "Reset the UI preferences of the current user",
+			Parameters: []openapi.Parameter{openapi.Query("resourceVersion", "Only delete the preferences at this version", false)},
+			Status:     http.StatusNoContent},
+		{Method: http.MethodGet, Path: NamespacePath, ID: "listNamespaces", Tags: []string{"namespaces"},
+			Summary:    "List the namespaces the user can access",
+			Parameters: listParameters, Response: NamespacesEnvelope{}},
+		{Method: http.MethodGet, Path: ClustersPath, ID: "listClusters", Tags: []string{"clusters"},
+			Summary:  "List the clusters other requests can target with the cluster query parameter, and whether they are reachable",
+			Response: ClustersEnvelope{}},
+		{Method: http.MethodGet, Path: ClusterHealthPath, ID: "getClusterHealth", Tags: []string{"clusters"},
+			Summary:  "Summarize the health of the cluster: API server reachability, readiness of the configured components and conditions of the configured operators",
+			Response: ClusterHealthEnvelope{}},
+		{Method: http.MethodGet, Path: HardwareProfilesPath, ID: "listHardwareProfiles", Tags: []string{"hardware"},
+			Summary:  "List the hardware and accelerator profiles of the platform, and the accelerators of the nodes when the user may list them",
+			Response: HardwareProfilesEnvelope{}},
+		{Method: http.MethodGet, Path: PermissionsPath, ID: "checkPermission", Tags: []string{"permissions"},
+			Summary: "Check whether the user may perform a verb on a resource",
+			Parameters: []openapi.Parameter{
+				openapi.Query("verb", "Verb, e.g. list", true),
+				openapi.Query("resource", "Resource, e.g. services", true),
+				openapi.Query("group", "API group (default core)", false),
+				openapi.Query("namespace", "Namespace (default cluster-wide)", false),
+			},
+			Response: PermissionEnvelope{}},
+		{Method: http.MethodPost, Path: PermissionsBatchPath, ID: "checkPermissions", Tags: []string{"permissions"},
+			Summary: "Check a batch of up to 250 permissions of the user, in one request",
+			Request: PermissionRequestsEnvelope{}, Response: PermissionListEnvelope{}},
+		{Method: http.MethodGet, Path: WatchPath, ID: "watchResource", Tags: []string{"watch"},
+			Summary:     "Stream the changes of a resource as Server-Sent Events",
+			ContentType: "text/event-stream", Response: &openapi.Schema{Type: "string"},
+			Parameters: []openapi.Parameter{
+				openapi.Query("group", "API group (default core)", false),
+				openapi.Query("version", "API version (default v1)", false),
+				openapi.Query("namespace", "Namespace (default cluster-wide)", false),
+				openapi.Query("resourceVersion", "Resume point, when Last-Event-ID is not sent", false),
+			}},
+		{Method: http.MethodGet, Path: NotificationsPath, ID: "streamNotifications", Tags: []string{"notifications"},
+			Summary:     "Stream the notifications of the user as Server-Sent Events, replaying the recent ones",
+			ContentType: "text/event-stream", Response: &openapi.Schema{Type: "string"}},
+		{Method: http.MethodGet, Path: PodLogsPath, ID: "streamPodLogs", Tags: []string{"logs"},
+			Summary:     "Stream the logs of a pod, as text or as Server-Sent Events when text/event-stream is accepted",
+			ContentType: "text/plain", Response: &openapi.Schema{Type: "string"},
+			Parameters: []openapi.Parameter{
+				namespaceParameter,
+				openapi.Query("container", "Container (default the only container of the pod)", false),
+				{Name: "follow", Description: "Keep streaming new lines", Schema: &openapi.Schema{Type: "boolean"}},
+				{Name: "previous", Description: "Logs of the previous, terminated container", Schema: &openapi.Schema{Type: "boolean"}},
+				{Name: "timestamps", Description: "Prefix every line with its RFC 3339 timestamp", Schema: &openapi.Schema{Type: "boolean"}},
+				{Name: "tailLines", Description: "Number of lines from the end of the logs to start with", Schema: &openapi.Schema{Type: "integer"}},
+				{Name: "sinceSeconds", Description: "Only lines of the last seconds", Schema: &openapi.Schema{Type: "integer"}},
+				openapi.Query("sinceTime", "Only lines since this RFC 3339 time (not with sinceSeconds)", false),
+				{Name: "limitBytes", Description: "Maximum number of bytes to send", Schema: &openapi.Schema{Type: "integer"}},
+			}},
+		{Method: http.MethodGet, Path: ServicesPath, ID: "listServices", Tags: []string{"services"},
+			Summary: "List the backend Services of a namespace",
+			Parameters: append([]openapi.Parameter{
+				namespaceParameter,
+				openapi.Query("labelSelector", "Replaces the configured label selector", false),
+			}, listParameters...),
+			Response: ServicesEnvelope{}},
+		{Method: http.MethodGet, Path: EventsPath, ID: "listEvents", Tags: []string{"events"},
+			Summary: "List the events about a resource of a namespace, most recent first",
+			Parameters: append([]openapi.Parameter{
+				namespaceParameter,
+				openapi.Query("kind", "Kind of the resource, e.g. Pod", true),
+				openapi.Query("name", "Name of the resource", true),
+				openapi.Query("uid", "UID of the resource, to leave out the events of former resources of the same name", false),
+			}, listParameters...),
+			Response: EventsEnvelope{}},
+		{Method: http.MethodGet, Path: QuotaPath, ID: "getQuota", Tags: []string{"quota"},
+			Summary:    "Get the resource quotas of a namespace with their usage, and its limit ranges",
+			Parameters: []openapi.Parameter{namespaceParameter},
+			Response:   QuotaEnvelope{}},
+		{Method: http.MethodGet, Path: RoleBindingsPath, ID: "listRoleBindings", Tags: []string{"sharing"},
+			Summary:    "List the users and groups a namespace is shared with, by their admin, edit or view RoleBindings",
+			Parameters: []openapi.Parameter{namespaceParameter},
+			Response:   RoleBindingsEnvelope{}},
+		{Method: http.MethodPost, Path: RoleBindingsPath, ID: "createRoleBinding", Tags: []string{"sharing"},
+			Summary: "Share a namespace with a user or group, with the edit or view role", Parameters: []openapi.Parameter{namespaceParameter, dryRunParameter},
+			Request: RoleBindingRequestEnvelope{}, Response: RoleBindingEnvelope{}, Status: http.StatusCreated},
+		{Method: http.MethodDelete, Path: RoleBindingPath, ID: "deleteRoleBinding", Tags: []string{"sharing"},
+			Summary: "Revoke the access granted by an admin, edit or view RoleBinding", Parameters: []openapi.Parameter{namespaceParameter},
+			Status: http.StatusNoContent},
+		{Method: http.MethodGet, Path: SecretsPath, ID: "listSecrets", Tags: []string{"secrets"},
+			Summary: "List the Secrets of a namespace, with their values redacted",
+			Parameters: append([]openapi.Parameter{
+				namespaceParameter,
+				{Name: "managed", Description: "Only the Secrets created through the BFF", Schema: &openapi.Schema{Type: "boolean"}},
+			}, listParameters...),
+			Response: SecretsEnvelope{}},
+		{Method: http.MethodPost, Path: SecretsPath, ID: "createSecret", Tags: []string{"secrets"},
+			Summary: "Create a Secret managed by the BFF", Parameters: []openapi.Parameter{namespaceParameter, dryRunParameter},
+			Request: SecretEnvelope{}, Response: SecretEnvelope{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: SecretPath, ID: "getSecret", Tags: []string{"secrets"},
+			Summary: "Get a Secret, with its values redacted unless revealed",
+			Parameters: []openapi.Parameter{
+				namespaceParameter,
+				{Name: "reveal", Description: "Return the values, when the user may reveal them", Schema: &openapi.Schema{Type: "boolean"}},
+			},
+			Response: SecretEnvelope{}},
+		{Method: http.MethodPut, Path: SecretPath, ID: "updateSecret", Tags: []string{"secrets"},
+			Summary: "Update a Secret managed by the BFF; redacted keys keep their value", Parameters: []openapi.Parameter{namespaceParameter, dryRunParameter, forceParameter},
+			Request: SecretEnvelope{}, Response: SecretEnvelope{}},
+		{Method: http.MethodGet, Path: ConfigMapsPath, ID: "listConfigMaps", Tags: []string{"configmaps"},
+			Summary: "List the ConfigMaps of a namespace, with the configured keys redacted",
+			Parameters: append([]openapi.Parameter{
+				namespaceParameter,
+				{Name: "managed", Description: "Only the ConfigMaps created through the BFF", Schema: &openapi.Schema{Type: "boolean"}},
+			}, listParameters...),
+			Response: ConfigMapsEnvelope{}},
+		{Method: http.MethodPost, Path: ConfigMapsPath, ID: "createConfigMap", Tags: []string{"configmaps"},
+			Summary: "Create a ConfigMap managed by the BFF", Parameters: []openapi.Parameter{namespaceParameter, dryRunParameter},
+			Request: ConfigMapEnvelope{}, Response: ConfigMapEnvelope{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: ConfigMapPath, ID: "getConfigMap", Tags: []string{"configmaps"},
+			Summary: "Get a ConfigMap, with the configured keys redacted unless revealed",
+			Parameters: []openapi.Parameter{
+				namespaceParameter,
+				{Name: "reveal", Description: "Return the redacted values, when the user may reveal them", Schema: &openapi.Schema{Type: "boolean"}},
+			},
+			Response: ConfigMapEnvelope{}},
+		{Method: http.MethodPut, Path: ConfigMapPath, ID: "updateConfigMap", Tags: []string{"configmaps"},
+			Summary: "Update a ConfigMap managed by the BFF; redacted keys keep their value", Parameters: []openapi.Parameter{namespaceParameter, dryRunParameter, forceParameter},
+			Request: ConfigMapEnvelope{}, Response: ConfigMapEnvelope{}},
+		{Method: http.MethodGet, Path: BucketsPath, ID: "listBuckets", Tags: []string{"object-storage"},
+			Summary: "List the buckets of the object storage credentials of a Secret", Parameters: []openapi.Parameter{namespaceParameter},
+			Response: BucketsEnvelope{}},
+		{Method: http.MethodGet, Path: ObjectsPath, ID: "listObjects", Tags: []string{"object-storage"},
+			Summary: "List a page of the objects of a bucket, grouped into prefixes by the delimiter",
+			Parameters: []openapi.Parameter{
+				namespaceParameter,
+				bucketParameter,
+				openapi.Query("prefix", "Only the objects whose key starts with this prefix", false),
+				openapi.Query("delimiter", "Groups the keys into prefixes, like folders (default /, empty lists every object)", false),
+				{Name: "pageSize", Description: "Number of objects and prefixes of the page (at most 1000)", Schema: &openapi.Schema{Type: "integer"}},
+				openapi.Query("nextPageToken", "Token of the next page, returned by the previous one", false),
+			},
+			Response: ObjectsEnvelope{}},
+		{Method: http.MethodPost, Path: PresignPath, ID: "presignObject", Tags: []string{"object-storage"},
+			Summary: "Get a URL to download or upload an object directly, without credentials", Parameters: []openapi.Parameter{namespaceParameter},
+			Request: PresignRequestEnvelope{}, Response: PresignedURLEnvelope{}},
+		{Method: http.MethodGet, Path: FeaturesPath, ID: "getFeatures", Tags: []string{"config"},
+			Summary:    "Evaluate the feature flags for the user, and optionally a namespace",
+			Parameters: []openapi.Parameter{openapi.Query("namespace", "Namespace of the namespace targeting rules", false)},
+			Response:   FeaturesEnvelope{}},
+		{Method: http.MethodGet, Path: ModulesPath, ID: "listUIModules", Tags: []string{"config"},
+			Summary:  "List the federated UI modules, with their remote entry, routes and required permissions",
+			Response: UIModulesEnvelope{}},
+		{Method: http.MethodGet, Path: VersionPath, ID: "getVersion", Tags: []string{"config"},
+			Summary:  "Get the version, git commit, build date and Go runtime of the BFF",
+			Response: VersionEnvelope{}},
+		{Method: http.MethodPut, Path: LogLevelPath, ID: "setLogLevel", Tags: []string{"debug"},
+			Summary: "Change the log levels (cluster admins only)",
+			Request: LogLevelsUpdateEnvelope{}, Response: LogLevelsEnvelope{}},
+
+		{Method: http.MethodGet, Path: ModelRegistryListPath, ID: "listModelRegistries", Tags: []string{"model registry"},
+			Summary:    "List the model registries of a namespace",
+			Parameters: append([]openapi.Parameter{namespaceParameter}, listParameters...), Response: ServicesEnvelope{}},
+		{Method: http.MethodGet, Path: RegisteredModelListPath, ID: "listRegisteredModels", Tags: []string{"model registry"},
+			Summary: "List registered models", Parameters: modelRegistryListParameters, Response: RegisteredModelListEnvelope{}},
+		{Method: http.MethodPost, Path: RegisteredModelListPath, ID: "createRegisteredModel", Tags: []string{"model registry"},
+			Summary: "Create a registered model", Parameters: registry,
+			Request: RegisteredModelEnvelope{}, Response: RegisteredModelEnvelope{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: RegisteredModelPath, ID: "getRegisteredModel", Tags: []string{"model registry"},
+			Summary: "Get a registered model", Parameters: registry, Response: RegisteredModelEnvelope{}},
+		{Method: http.MethodPatch, Path: RegisteredModelPath, ID: "updateRegisteredModel", Tags: []string{"model registry"},
+			Summary: "Update a registered model", Parameters: registry,
+			Request: RegisteredModelEnvelope{}, Response: RegisteredModelEnvelope{}},
+		{Method: http.MethodGet, Path: RegisteredModelVersionPath, ID: "listModelVersions", Tags: []string{"model registry"},
+			Summary: "List the versions of a registered model", Parameters: modelRegistryListParameters, Response: ModelVersionListEnvelope{}},
+		{Method: http.MethodPost, Path: RegisteredModelVersionPath, ID: "createModelVersion", Tags: []string{"model registry"},
+			Summary: "Create a version of a registered model", Parameters: registry,
+			Request: ModelVersionEnvelope{}, Response: ModelVersionEnvelope{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: ModelVersionPath, ID: "getModelVersion", Tags: []string{"model registry"},
+			Summary: "Get a model version", Parameters: registry, Response: ModelVersionEnvelope{}},
+		{Method: http.MethodPatch, Path: ModelVersionPath, ID: "updateModelVersion", Tags: []string{"model registry"},
+			Summary: "Update a model version", Parameters: registry,
+			Request: ModelVersionEnvelope{}, Response: ModelVersionEnvelope{}},
+		{Method: http.MethodGet, Path: ModelVersionArtifactPath, ID: "listModelArtifacts", Tags: []string{"model registry"},
+			Summary: "List the model artifacts of a model version", Parameters: modelRegistryListParameters, Response: ModelArtifactListEnvelope{}},
+		{Method: http.MethodPost, Path: ModelVersionArtifactPath, ID: "createModelArtifact", Tags: []string{"model registry"},
+			Summary: "Create a model artifact of a model version", Parameters: registry,
+			Request: ModelArtifactEnvelope{}, Response: ModelArtifactEnvelope{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: ModelArtifactPath, ID: "getModelArtifact", Tags: []string{"model registry"},
+			Summary: "Get a model artifact", Parameters: registry, Response: ModelArtifactEnvelope{}},
+		{Method: http.MethodPatch, Path: ModelArtifactPath, ID: "updateModelArtifact", Tags: []string{"model registry"},
+			Summary: "Update a model artifact", Parameters: registry,
+			Request: ModelArtifactEnvelope{}, Response: ModelArtifactEnvelope{}},
+
+		{Method: http.MethodGet, Path: FrontendConfigPath, ID: "getFrontendConfig", Tags: []string{"config"}, Public: true,
+			Summary: "Get the runtime configuration of the frontend", Response: FrontendConfigEnvelope{}},
+		{Method: http.MethodGet, Path: OpenAPIPath, ID: "getOpenAPI", Tags: []string{"docs"}, Public: true,
+			Summary: "Get this OpenAPI document", Response: &openapi.Schema{Type: "object"}},
+	}
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/openapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPIHandler(t *testing.T) {
+	app := newWatchTestApp(t)
+
+	for _, path := range []string{OpenAPIPath, PathPrefix + OpenAPIPath} {
+		rr := httptest.NewRecorder()
+		app.Routes().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+
+		require.Equal(t, http.StatusOK, rr.Code, "served without authentication at %s", path)
+		assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+		var doc openapi.Document
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &doc))
+		assert.Equal(t, openapi.Version, doc.OpenAPI)
+		assert.Contains(t, doc.Paths, "/api/v1/model_registry/{model_registry_id}/registered_models")
+		assert.Contains(t, doc.Components.Schemas, "ServicesEnvelope")
+	}
+}
+
+// Every documented starter operation must be routed, so the document doesn't drift from Routes.
+func TestStarterOperationsAreRouted(t *testing.T) {
+	app := newWatchTestApp(t)
+	handler := app.Routes()
+	params := strings.NewReplacer(":"+ModelRegistryIDParam, "r", ":"+RegisteredModelIDParam, "1",
+		":"+ModelVersionIDParam, "1", ":"+ModelArtifactIDParam, "1")
+
+	for _, op := range starterOperations() {
+		if op.ContentType == "text/event-stream" {
+			// Routed streams don't end; the watch has its own tests
+			continue
+		}
+		// Without the namespace parameter or a body, routed requests fail validation before
+		// reaching any upstream
+		req := httptest.NewRequest(op.Method, params.Replace(op.Path), nil)
+		req.Header.Set(constants.KubeflowUserIDHeader, "user@example.com")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		assert.NotContains(t, []int{http.StatusNotFound, http.StatusMethodNotAllowed}, rr.Code, "%s %s", op.Method, op.Path)
+	}
+}
+
+func TestAPIDocs_DevModeOnly(t *testing.T) {
+	app := newWatchTestApp(t)
+	rr := httptest.NewRecorder()
+	app.Routes().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, APIDocsPath+"/", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	app.config.DevMode = true
+	routes := app.Routes()
+
+	rr = httptest.NewRecorder()
+	routes.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, APIDocsPath, nil))
+	assert.Equal(t, http.StatusMovedPermanently, rr.Code)
+	assert.Equal(t, "docs/", rr.Header().Get("Location"))
+
+	rr = httptest.NewRecorder()
+	routes.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, PathPrefix+APIDocsPath+"/", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "swagger-initializer.js")
+
+	rr = httptest.NewRecorder()
+	routes.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, APIDocsPath+"/swagger-ui.css", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestRegisterOpenAPIOperations(t *testing.T) {
+	openAPIOperationsMu.Lock()
+	saved := openAPIOperations
+	openAPIOperationsMu.Unlock()
+	t.Cleanup(func() {
+		openAPIOperationsMu.Lock()
+		openAPIOperations = saved
+		openAPIOperationsMu.Unlock()
+	})
+
+	RegisterOpenAPIOperations(openapi.Operation{Method: http.MethodGet, Path: ApiPathPrefix + "/notebooks", Response: ServicesEnvelope{}})
+	app := newWatchTestApp(t)
+	spec, err := app.newOpenAPISpec()
+	require.NoError(t, err)
+	assert.Contains(t, string(spec), `"/api/v1/notebooks"`)
+
+	RegisterOpenAPIOperations(openapi.Operation{Method: http.MethodGet, Path: UserPath})
+	_, err = app.newOpenAPISpec()
+	assert.ErrorContains(t, err, "duplicate operation")
+}
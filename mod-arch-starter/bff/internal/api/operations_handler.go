@@ -0,0 +1,157 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	logging "github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/operations"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/sse"
+)
+
+type OperationEnvelope Envelope[operations.Operation, None]
+
+// EventOperation is the SSE event type of the states of an operation.
+const EventOperation = "operation"
+
+// OperationStoreFactory builds the store of the operations once the App exists, e.g. on the
+// database of a repository.
+type OperationStoreFactory func(app *App) (operations.Store, error)
+
+var (
+	operationStoreMu       sync.RWMutex
+	operationStoreOverride OperationStoreFactory
+)
+
+// RegisterOperationStore replaces the in-memory store of the operations, e.g. with one in a
+// database so operations survive restarts and can be polled from any replica. This should be
+// called from an init() function in the downstream code.
+//
+// Example usage in downstream code:
+//
+//	func init() {
+//	    api.RegisterOperationStore(func(app *api.App) (operations.Store, error) {
+//	        return newPostgresOperationStore(app.Config().DatabaseURL)
+//	    })
+//	}
+func RegisterOperationStore(factory OperationStoreFactory) { //nolint:unused
+	operationStoreMu.Lock()
+	defer operationStoreMu.Unlock()
+	operationStoreOverride = factory
+}
+
+// newOperations creates the tracker of the operations, on the registered store or in memory.
+func (app *App) newOperations() (*operations.Tracker, error) {
+	operationStoreMu.RLock()
+	factory := operationStoreOverride
+	operationStoreMu.RUnlock()
+
+	var store operations.Store = operations.NewMemoryStore()
+	if factory != nil {
+		app.logger.Info("applying operation store override")
+		var err error
+		if store, err = factory(app); err != nil {
+			return nil, fmt.Errorf("failed to create the operation store: %w", err)
+		}
+	}
+	return operations.NewTracker(store, logging.ForPackage(app.logger, "operations")), nil
+}
+
+// StartOperation starts fn as an operation of opType for the requesting user, and answers with a
+// 202 Accepted carrying the operation, with its URL in the Location header. Handlers of
+// long-running actions call it instead of waiting for the action to complete.
+//
+// Example usage in downstream code:
+//
+//	func (h *handlers) registerModel(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+//	    h.app.StartOperation(w, r, "model-registration", func(ctx context.Context, progress *operations.Progress) (any, error) {
+//	        progress.Report(ctx, 10, "uploading the model card")
+//	        return h.registry.Register(ctx, model)
+//	    })
+//	}
+func (app *App) StartOperation(w http.ResponseWriter, r *http.Request, opType string, fn operations.Func) {
+	identity, _ := r.Context().Value(constants.RequestIdentityKey).(*kubernetes.RequestIdentity)
+	op, err := app.operations.Start(r.Context(), operationOwner(identity), opType, fn)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	headers := http.Header{"Location": {OperationsPath + "/" + op.ID}}
+	if err := app.WriteJSON(w, http.StatusAccepted, OperationEnvelope{Data: op}, headers); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// GetOperationHandler returns an operation of the requesting user. Requests accepting
+// text/event-stream, like a browser EventSource, follow it instead: an "operation" event is
+// sent with its current state, then whenever it changes, and the stream ends once the
+// operation completed.
+func (app *App) GetOperationHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := r.Context()
+	identity, ok := ctx.Value(constants.RequestIdentityKey).(*kubernetes.RequestIdentity)
+	if !ok || identity == nil {
+		app.badRequestResponse(w, r, fmt.Errorf("missing RequestIdentity in context"))
+		return
+	}
+	id := ps.ByName("id")
+
+	// Watched before the first read, so no change is missed in between
+	changes, stop := app.operations.Watch(id)
+	defer stop()
+	op, err := app.operations.Get(ctx, id)
+	if errors.Is(err, operations.ErrNotFound) || err == nil && op.Owner != operationOwner(identity) {
+		// The operations of other users are not found either, so their IDs don't leak
+		app.notFoundResponse(w, r)
+		return
+	}
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !acceptsEventStream(r) {
+		if err := app.WriteJSON(w, http.StatusOK, OperationEnvelope{Data: op}, nil); err != nil {
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	stream, err := sse.NewStream(w)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	streamCtx, cancel := app.streamContext(ctx)
+	defer cancel()
+	// A reconnecting client gets the current state again, so the events have no ID
+	if err := stream.Send(sse.Event{Event: EventOperation, Data: op}); err != nil || op.Done() {
+		return
+	}
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-streamCtx.Done():
+			return
+		case op = <-changes:
+			if err := stream.Send(sse.Event{Event: EventOperation, Data: op}); err != nil || op.Done() {
+				return
+			}
+		case <-heartbeat.C:
+			if err := stream.Comment("heartbeat"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// operationOwner identifies the user of identity as the owner of operations.
+func operationOwner(identity *kubernetes.RequestIdentity) string {
+	return rateLimitKey(identity)
+}
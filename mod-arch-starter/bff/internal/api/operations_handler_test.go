@@ -0,0 +1,91 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/operations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperationHandlers(t *testing.T) {
+	app := newWatchTestApp(t)
+	var err error
+	app.operations, err = app.newOperations()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = app.operations.Stop(context.Background()) })
+	dora := &kubernetes.RequestIdentity{UserID: "doraNonAdmin@example.com"}
+	withIdentity := func(r *http.Request, identity *kubernetes.RequestIdentity) *http.Request {
+		return r.WithContext(context.WithValue(r.Context(), constants.RequestIdentityKey, identity))
+	}
+
+	release := make(chan struct{})
+	rr := httptest.NewRecorder()
+	app.StartOperation(rr, withIdentity(httptest.NewRequest(http.MethodPost, "/api/v1/workbenches", nil), dora), "workbench-creation",
+		func(ctx context.Context, progress *operations.Progress) (any, error) {
+			progress.Report(ctx, 40, "pulling the image")
+			<-release
+			return map[string]string{"name": "my-workbench"}, nil
+		})
+	require.Equal(t, http.StatusAccepted, rr.Code)
+	var started OperationEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &started))
+	assert.Equal(t, operations.StatusRunning, started.Data.Status)
+	assert.Equal(t, OperationsPath+"/"+started.Data.ID, rr.Header().Get("Location"))
+
+	get := func(identity *kubernetes.RequestIdentity, id string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		app.GetOperationHandler(rr, withIdentity(httptest.NewRequest(http.MethodGet, OperationsPath+"/"+id, nil), identity),
+			httprouter.Params{{Key: "id", Value: id}})
+		return rr
+	}
+	assert.Eventually(t, func() bool {
+		rr = get(dora, started.Data.ID)
+		return rr.Code == http.StatusOK && strings.Contains(rr.Body.String(), `"message": "pulling the image"`)
+	}, 5*time.Second, time.Millisecond, "polled with its progress")
+	assert.Contains(t, rr.Body.String(), `"type": "workbench-creation"`)
+	assert.Equal(t, http.StatusNotFound, get(&kubernetes.RequestIdentity{UserID: "bellaNonAdmin@example.com"}, started.Data.ID).Code,
+		"the operations of other users are not found")
+	assert.Equal(t, http.StatusNotFound, get(dora, "missing").Code)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		app.GetOperationHandler(w, withIdentity(r, dora), httprouter.Params{{Key: "id", Value: started.Data.ID}})
+	}))
+	defer server.Close()
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := server.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	lines := bufio.NewScanner(resp.Body)
+	var states []string
+	for lines.Scan() {
+		line := lines.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		var op operations.Operation
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &op))
+		states = append(states, string(op.Status))
+		if op.Status == operations.StatusRunning {
+			assert.Equal(t, "pulling the image", op.Message)
+			close(release)
+		} else {
+			assert.JSONEq(t, `{"name":"my-workbench"}`, string(op.Result))
+		}
+	}
+	assert.Equal(t, []string{"running", "succeeded"}, states, "the stream ends once the operation completed")
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/tlsclient"
+)
+
+// Names of the built-in upstreams in -upstream-tls. Proxy routes and BFF targets use their own
+// names.
+const (
+	UpstreamModelRegistry = "model-registry"
+	UpstreamOIDC          = "oidc"
+	UpstreamAuditWebhook  = "audit-webhook"
+	UpstreamPanicReport   = "panic-report"
+	UpstreamObjectStorage = "object-storage"
+	UpstreamWebhooks      = "webhooks"
+	UpstreamOPA           = "opa"
+)
+
+// newOutboundTLS loads the outbound TLS of the upstreams: -bundle-paths and
+// -insecure-skip-verify by default, and the -upstream-tls entries of the named upstreams.
+func newOutboundTLS(cfg config.EnvConfig, logger *slog.Logger) (*tlsclient.Set, error) {
+	var caFiles []string
+	for _, path := range cfg.BundlePaths {
+		if path = strings.TrimSpace(path); path != "" {
+			caFiles = append(caFiles, path)
+		}
+	}
+	defaults, err := tlsclient.New(tlsclient.Config{
+		CAFiles:            caFiles,
+		SkipMissingCAs:     true,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}, logger)
+	if err != nil {
+		return nil, fmt.Errorf("bundle-paths: %w", err)
+	}
+	if len(caFiles) > 0 && defaults.RootCAs() != nil {
+		logger.Info("Added CA bundles", slog.Any("paths", caFiles))
+	}
+
+	refs, err := cfg.ParseUpstreamTLS()
+	if err != nil {
+		return nil, err
+	}
+	named := make(map[string]*tlsclient.Source, len(refs))
+	for _, ref := range refs {
+		source, err := tlsclient.New(tlsclient.Config{
+			CAFiles:            ref.CAFiles,
+			CertFile:           ref.CertFile,
+			KeyFile:            ref.KeyFile,
+			ServerName:         ref.ServerName,
+			InsecureSkipVerify: ref.InsecureSkipVerify,
+		}, logger.With("upstream", ref.Name))
+		if err != nil {
+			return nil, fmt.Errorf("upstream-tls of %s: %w", ref.Name, err)
+		}
+		if ref.InsecureSkipVerify {
+			logger.Warn("TLS verification is disabled for an upstream", "upstream", ref.Name)
+		}
+		named[ref.Name] = source
+	}
+	return tlsclient.NewSet(defaults, named), nil
+}
+
+// upstreamTLSConfig returns the TLS client configuration of the upstream name.
+func (app *App) upstreamTLSConfig(name string) *tls.Config {
+	return app.outboundTLS.TLSConfig(name)
+}
+
+// WatchOutboundTLS reloads the CA bundles and client certificates of the upstreams when their
+// files change, e.g. a rotated trusted CA ConfigMap, until ctx is done. New connections use the
+// reloaded files. It returns once the watches are set up.
+func (app *App) WatchOutboundTLS(ctx context.Context) error {
+	for _, source := range app.outboundTLS.Sources() {
+		for _, path := range source.Files() {
+			err := config.WatchFile(ctx, path, app.logger, func() {
+				if err := source.Reload(); err != nil {
+					app.logger.Error("failed to reload outbound TLS files, keeping the current ones", "path", path, "error", err)
+				}
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
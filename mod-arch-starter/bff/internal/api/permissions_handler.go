@@ -0,0 +1,104 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/validation"
+)
+
+type PermissionEnvelope Envelope[models.PermissionCheck, None]
+type PermissionRequestsEnvelope Envelope[[]models.PermissionRequest, None]
+type PermissionListEnvelope Envelope[[]models.PermissionCheck, None]
+
+// MaxPermissionChecks bounds the checks of a batch.
+const MaxPermissionChecks = 250
+
+// Validate bounds the number of checks of a batch.
+func (e PermissionRequestsEnvelope) Validate() error {
+	if len(e.Data) == 0 || len(e.Data) > MaxPermissionChecks {
+		return validation.Invalid("data", fmt.Sprintf("must have between 1 and %d checks, got %d", MaxPermissionChecks, len(e.Data)))
+	}
+	return nil
+}
+
+// PermissionsHandler answers "can the current user perform <verb> on <resource>?" so the
+// frontend can conditionally render actions. Supported query parameters are verb, resource
+// (both required), group and namespace (both optional).
+func (app *App) PermissionsHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+
+	ctx := r.Context()
+	identity, ok := ctx.Value(constants.RequestIdentityKey).(*kubernetes.RequestIdentity)
+	if !ok || identity == nil {
+		app.badRequestResponse(w, r, fmt.Errorf("missing RequestIdentity in context"))
+		return
+	}
+
+	query := r.URL.Query()
+	verb := query.Get("verb")
+	resource := query.Get("resource")
+	if verb == "" || resource == "" {
+		app.badRequestResponse(w, r, fmt.Errorf("missing required query parameters: verb and resource"))
+		return
+	}
+
+	client, err := app.kubernetesClientFactory.GetClient(ctx)
+	if err != nil {
+		app.serverErrorResponse(w, r, fmt.Errorf("failed to get Kubernetes client: %w", err))
+		return
+	}
+
+	permission, err := app.repositories.Permission.CheckPermission(client, ctx, identity, verb, query.Get("group"), resource, query.Get("namespace"))
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+
+	permissionRes := PermissionEnvelope{
+		Data: permission,
+	}
+
+	err = app.WriteJSON(w, http.StatusOK, permissionRes, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// PermissionsBatchHandler runs the checks in the body, up to MaxPermissionChecks, and answers
+// with their results in the same order, so a page needs a single request for all its
+// actions. Checks that could not be performed are not allowed and carry an error.
+func (app *App) PermissionsBatchHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	ctx := r.Context()
+	identity, ok := ctx.Value(constants.RequestIdentityKey).(*kubernetes.RequestIdentity)
+	if !ok || identity == nil {
+		app.badRequestResponse(w, r, fmt.Errorf("missing RequestIdentity in context"))
+		return
+	}
+
+	var body PermissionRequestsEnvelope
+	if err := app.ReadValidJSON(w, r, &body); err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+
+	client, err := app.kubernetesClientFactory.GetClient(ctx)
+	if err != nil {
+		app.serverErrorResponse(w, r, fmt.Errorf("failed to get Kubernetes client: %w", err))
+		return
+	}
+
+	results, err := app.repositories.Permission.CheckPermissions(client, ctx, identity, body.Data)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.WriteJSON(w, http.StatusOK, PermissionListEnvelope{Data: results}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermissionsBatchHandler(t *testing.T) {
+	routes := newWatchTestApp(t).Routes()
+	batch := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, PermissionsBatchPath, strings.NewReader(body))
+		req.Header.Set(constants.KubeflowUserIDHeader, "doraNonAdmin@example.com")
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := batch(`{"data": [
+		{"verb": "create", "resource": "secrets", "namespace": "dora-namespace"},
+		{"verb": "delete", "group": "apps", "resource": "deployments", "namespace": "bella-namespace"}
+	]}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var envelope PermissionListEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &envelope))
+	assert.Equal(t, []models.PermissionCheck{
+		{Verb: "create", Resource: "secrets", Namespace: "dora-namespace", Allowed: true},
+		{Verb: "delete", Group: "apps", Resource: "deployments", Namespace: "bella-namespace", Allowed: false},
+	}, envelope.Data)
+
+	rr = batch(`{"data": [{"verb": "get", "resource": "pods"}, {"resource": "pods"}]}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	var errEnvelope HTTPError
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &errEnvelope))
+	assert.Equal(t, "data[1].verb is required", errEnvelope.Error.Message)
+	assert.Equal(t, []any{map[string]any{"field": "data[1].verb", "message": "is required"}}, errEnvelope.Error.Details["fields"])
+
+	assert.Equal(t, http.StatusBadRequest, batch(`{"data": []}`).Code)
+	many := strings.Repeat(`{"verb": "get", "resource": "pods"},`, MaxPermissionChecks+1)
+	assert.Equal(t, http.StatusBadRequest, batch(`{"data": [`+strings.TrimSuffix(many, ",")+`]}`).Code)
+}
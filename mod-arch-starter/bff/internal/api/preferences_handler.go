@@ -0,0 +1,102 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/validation"
+)
+
+type PreferencesEnvelope Envelope[*models.UserPreferences, None]
+
+// preferencesNamespace is -preferences-namespace, or the namespace of the BFF pod.
+func preferencesNamespace(cfg config.EnvConfig) string {
+	if cfg.PreferencesNamespace != "" {
+		return cfg.PreferencesNamespace
+	}
+	return os.Getenv("POD_NAMESPACE")
+}
+
+// GetPreferencesHandler returns the UI preferences of the current user, empty (without a
+// resourceVersion) when none are stored.
+func (app *App) GetPreferencesHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	identity, client, ok := app.preferencesRequest(w, r)
+	if !ok {
+		return
+	}
+
+	prefs, err := app.repositories.Preferences.Get(client, r.Context(), identity)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if err := app.WriteJSON(w, http.StatusOK, PreferencesEnvelope{Data: &prefs}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// PutPreferencesHandler replaces the UI preferences of the current user. The body carries the
+// resourceVersion that was read, or none to store the first preferences; a 409 means they
+// changed in the meantime and must be read again.
+func (app *App) PutPreferencesHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var body PreferencesEnvelope
+	if err := app.ReadValidJSON(w, r, &body); err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if body.Data == nil {
+		app.apiErrorResponse(w, r, validation.Invalid("data", "is required"))
+		return
+	}
+	identity, client, ok := app.preferencesRequest(w, r)
+	if !ok {
+		return
+	}
+
+	prefs, err := app.repositories.Preferences.Put(client, r.Context(), identity, *body.Data)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if err := app.WriteJSON(w, http.StatusOK, PreferencesEnvelope{Data: &prefs}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// DeletePreferencesHandler resets the UI preferences of the current user. The optional
+// resourceVersion query parameter makes it fail with a 409 when they changed since.
+func (app *App) DeletePreferencesHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	identity, client, ok := app.preferencesRequest(w, r)
+	if !ok {
+		return
+	}
+
+	err := app.repositories.Preferences.Delete(client, r.Context(), identity, r.URL.Query().Get("resourceVersion"))
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *App) preferencesRequest(w http.ResponseWriter, r *http.Request) (*kubernetes.RequestIdentity, kubernetes.KubernetesClientInterface, bool) {
+	ctx := r.Context()
+	identity, ok := ctx.Value(constants.RequestIdentityKey).(*kubernetes.RequestIdentity)
+	if !ok || identity == nil {
+		app.badRequestResponse(w, r, fmt.Errorf("missing RequestIdentity in context"))
+		return nil, nil, false
+	}
+
+	client, err := app.kubernetesClientFactory.GetClient(ctx)
+	if err != nil {
+		app.serverErrorResponse(w, r, fmt.Errorf("failed to get Kubernetes client: %w", err))
+		return nil, nil, false
+	}
+	return identity, client, true
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferencesHandlers(t *testing.T) {
+	app := newWatchTestApp(t)
+	app.repositories.Preferences.UseNamespace("kubeflow")
+	routes := app.Routes()
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(constants.KubeflowUserIDHeader, "doraNonAdmin@example.com")
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, req)
+		return rr
+	}
+	read := func(rr *httptest.ResponseRecorder) PreferencesEnvelope {
+		t.Helper()
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		var envelope PreferencesEnvelope
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &envelope))
+		return envelope
+	}
+
+	assert.Empty(t, read(serve(http.MethodGet, PreferencesPath, "")).Data.ResourceVersion)
+
+	created := read(serve(http.MethodPut, PreferencesPath, `{"data":{"theme":"dark","tables":{"services":{"columns":["name"]}}}}`))
+	require.NotEmpty(t, created.Data.ResourceVersion)
+	assert.Equal(t, []string{"name"}, read(serve(http.MethodGet, PreferencesPath, "")).Data.Tables["services"].Columns)
+
+	stale := `{"data":{"theme":"light","resourceVersion":"` + created.Data.ResourceVersion + `"}}`
+	read(serve(http.MethodPut, PreferencesPath, stale))
+	assert.Equal(t, http.StatusConflict, serve(http.MethodPut, PreferencesPath, stale).Code)
+	assert.Equal(t, http.StatusConflict, serve(http.MethodDelete, PreferencesPath+"?resourceVersion="+created.Data.ResourceVersion, "").Code)
+
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPut, PreferencesPath, `{"data":{"colour":"red"}}`).Code)
+	assert.Equal(t, http.StatusNoContent, serve(http.MethodDelete, PreferencesPath, "").Code)
+	assert.Empty(t, read(serve(http.MethodGet, PreferencesPath, "")).Data.Theme)
+
+	app.repositories.Preferences.UseNamespace("")
+	assert.Equal(t, http.StatusServiceUnavailable, serve(http.MethodGet, PreferencesPath, "").Code)
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	logging "github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/preflight"
+)
+
+type PreflightEnvelope Envelope[preflight.Report, None]
+
+// RunPreflight runs the preflight checks of -preflight at startup and logs their report. It
+// fails when a check failed in the fail mode.
+func (app *App) RunPreflight(ctx context.Context) error {
+	if app.config.Preflight == config.PreflightOff {
+		return nil
+	}
+	report := app.Preflight(ctx)
+	report.Log(logging.ForPackage(app.logger, "preflight"))
+	if report.Status == preflight.StatusFail && app.config.Preflight == config.PreflightFail {
+		return errors.New("preflight checks failed, see the hints above (-preflight=warn starts anyway)")
+	}
+	return nil
+}
+
+// Preflight checks the permissions of the backend credentials, the reachability of the
+// configured upstreams and the TLS files of the configuration.
+func (app *App) Preflight(ctx context.Context) preflight.Report {
+	checks := []preflight.Check{app.rbacPreflight, app.tlsPreflight}
+	if checker, ok := app.kubernetesClientFactory.(k8s.APIServerChecker); ok {
+		checks = append(checks, func(ctx context.Context) []preflight.Result {
+			if err := checker.CheckAPIServer(ctx); err != nil {
+				return []preflight.Result{preflight.Fail("upstream/kubernetes", err.Error(),
+					"check the kubeconfig or the in-cluster service account, and the network policies to the API server")}
+			}
+			return []preflight.Result{preflight.Pass("upstream/kubernetes", "the API server is reachable")}
+		})
+	}
+	if app.database != nil {
+		checks = append(checks, func(ctx context.Context) []preflight.Result {
+			if err := app.database.Ping(ctx); err != nil {
+				return []preflight.Result{preflight.Fail("upstream/database", err.Error(), "check -database-url and that the database accepts connections from the BFF")}
+			}
+			return []preflight.Result{preflight.Pass("upstream/database", "the database is reachable")}
+		})
+	}
+
+	upstreams := map[string]string{
+		UpstreamModelRegistry: app.config.ModelRegistryURL,
+		UpstreamAuditWebhook:  app.config.AuditWebhookURL,
+	}
+	if app.config.OIDCIssuerURL != "" {
+		upstreams[UpstreamOIDC] = strings.TrimSuffix(app.config.OIDCIssuerURL, "/") + "/.well-known/openid-configuration"
+	}
+	for _, name := range []string{UpstreamModelRegistry, UpstreamOIDC, UpstreamAuditWebhook} {
+		if url := upstreams[name]; url != "" {
+			checks = append(checks, func(ctx context.Context) []preflight.Result {
+				transport := &http.Transport{Proxy: http.ProxyFromEnvironment, TLSClientConfig: app.upstreamTLSConfig(name)}
+				defer transport.CloseIdleConnections()
+				return []preflight.Result{preflight.Reachable(ctx, &http.Client{Transport: transport}, "upstream/"+name, url, name)}
+			})
+		}
+	}
+	return preflight.Run(ctx, 0, checks)
+}
+
+// rbacPreflight reviews the permissions the backend credentials need with the auth method.
+func (app *App) rbacPreflight(ctx context.Context) []preflight.Result {
+	permissions := k8s.BackendPermissions(app.config.AuthMethod)
+	if app.config.KubeflowMode && app.config.AuthMethod == config.AuthMethodInternal {
+		permissions = append(permissions, k8s.Permission{Verb: "list", Group: "kubeflow.org", Resource: "profiles", Reason: "lists the namespaces of the Kubeflow Profiles"})
+	}
+	if len(permissions) == 0 {
+		return nil
+	}
+	checker, ok := app.kubernetesClientFactory.(k8s.BackendAccessChecker)
+	if !ok {
+		return []preflight.Result{preflight.Warn("rbac", "the permissions of the Kubernetes client can't be reviewed", "")}
+	}
+
+	results := make([]preflight.Result, 0, len(permissions))
+	for _, permission := range permissions {
+		name := "rbac/" + permission.String()
+		allowed, err := checker.CanBackendAccess(ctx, permission.Verb, permission.Group, permission.Resource)
+		switch {
+		case err != nil:
+			results = append(results, preflight.Fail(name, err.Error(), "check that the API server is reachable"))
+		case allowed:
+			results = append(results, preflight.Pass(name, "allowed: "+permission.Reason))
+		default:
+			message := fmt.Sprintf("the service account can't %s, which %s", permission, permission.Reason)
+			hint := fmt.Sprintf("grant %s cluster-wide to the service account of the BFF with a ClusterRole and ClusterRoleBinding", permission)
+			if permission.Optional {
+				results = append(results, preflight.Warn(name, message, hint))
+			} else {
+				results = append(results, preflight.Fail(name, message, hint))
+			}
+		}
+	}
+	return results
+}
+
+// tlsPreflight checks the certificates and CA bundles of the configuration.
+func (app *App) tlsPreflight(context.Context) []preflight.Result {
+	now := time.Now()
+	var results []preflight.Result
+	if app.config.CertFile != "" && app.config.KeyFile != "" {
+		results = append(results, preflight.Certificate("tls/server", app.config.CertFile, app.config.KeyFile, now))
+	}
+	if app.config.TLSClientCAFile != "" {
+		results = append(results, preflight.CABundle("tls/client-ca", app.config.TLSClientCAFile, now))
+	}
+	for _, path := range app.config.BundlePaths {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		// Missing bundles are skipped, e.g. optional ConfigMap volumes
+		result := preflight.CABundle("tls/bundle/"+path, path, now)
+		if result.Status == preflight.StatusFail {
+			result.Status = preflight.StatusWarn
+		}
+		results = append(results, result)
+	}
+
+	// Validate already parsed the entries
+	refs, _ := app.config.ParseUpstreamTLS()
+	for _, ref := range refs {
+		name := "tls/upstream/" + ref.Name
+		for _, path := range ref.CAFiles {
+			results = append(results, preflight.CABundle(name, path, now))
+		}
+		if ref.CertFile != "" {
+			results = append(results, preflight.Certificate(name, ref.CertFile, ref.KeyFile, now))
+		}
+		if ref.InsecureSkipVerify {
+			results = append(results, preflight.Warn(name, "the certificate of the upstream is not verified", "trust its CA with ca=<path> instead of insecure-skip-verify"))
+		}
+	}
+	return results
+}
+
+// PreflightHandler runs the preflight checks and serves their report. Only cluster admins may
+// call it.
+func (app *App) PreflightHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	if !app.requireClusterAdmin(w, r, debugAction) {
+		return
+	}
+
+	if err := app.WriteJSON(w, http.StatusOK, PreflightEnvelope{Data: app.Preflight(r.Context())}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
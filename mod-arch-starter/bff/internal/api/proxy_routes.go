@@ -0,0 +1,105 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/apierrors"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/proxy"
+)
+
+// ProxyRouteFactory builds a reverse-proxy route once the App exists, so the route can use
+// app dependencies such as the Kubernetes client factory for service discovery.
+type ProxyRouteFactory func(app *App) (proxy.Route, error)
+
+var (
+	proxyRouteMu sync.RWMutex
+	proxyRoutes  []ProxyRouteFactory
+)
+
+// RegisterProxyRoute registers a route that forwards every request under its path prefix
+// to an upstream. This should be called from an init() function in the downstream code.
+// Path prefixes must be under ApiPathPrefix so requests are authenticated first; the
+// caller's identity is forwarded to the upstream.
+//
+// Example usage in downstream code:
+//
+//	func init() {
+//	    api.RegisterProxyRoute(func(app *api.App) (proxy.Route, error) {
+//	        resolve, err := proxy.ServiceResolver(app.KubernetesClientFactory(), proxy.ServiceTarget{
+//	            Selector: "app.kubernetes.io/component=model-registry",
+//	            PortName: "http-api",
+//	        })
+//	        if err != nil {
+//	            return proxy.Route{}, err
+//	        }
+//	        return proxy.Route{
+//	            Name:          "model-registry",
+//	            PathPrefix:    api.ApiPathPrefix + "/proxy/model-registry/",
+//	            RewritePrefix: "/api/model_registry/v1alpha3/",
+//	            Resolve:       resolve,
+//	        }, nil
+//	    })
+//	}
+func RegisterProxyRoute(factory ProxyRouteFactory) { //nolint:unused
+	proxyRouteMu.Lock()
+	defer proxyRouteMu.Unlock()
+	proxyRoutes = append(proxyRoutes, factory)
+}
+
+// newReverseProxy builds the registered proxy routes. It returns nil when none are registered.
+func (app *App) newReverseProxy() (*proxy.ReverseProxy, error) {
+	proxyRouteMu.RLock()
+	factories := append([]ProxyRouteFactory(nil), proxyRoutes...)
+	proxyRouteMu.RUnlock()
+	if len(factories) == 0 {
+		return nil, nil
+	}
+
+	routes := make([]proxy.Route, 0, len(factories))
+	for _, factory := range factories {
+		route, err := factory(app)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build proxy route: %w", err)
+		}
+		if !strings.HasPrefix(route.PathPrefix, ApiPathPrefix+"/") {
+			return nil, fmt.Errorf("proxy route %q: path prefix %q must be under %s", route.Name, route.PathPrefix, ApiPathPrefix)
+		}
+		if route.Transport == nil && app.outboundTLS.Has(route.Name) {
+			routeTransport := http.DefaultTransport.(*http.Transport).Clone()
+			routeTransport.TLSClientConfig = app.upstreamTLSConfig(route.Name)
+			route.Transport = routeTransport
+		}
+		app.logger.Info("registering proxy route", "route", route.Name, "path_prefix", route.PathPrefix)
+		routes = append(routes, route)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = app.upstreamTLSConfig("")
+
+	return proxy.NewReverseProxy(routes, proxy.ReverseProxyOptions{
+		Transport:       transport,
+		AuthTokenHeader: app.config.AuthTokenHeader,
+		AuthTokenPrefix: app.config.AuthTokenPrefix,
+		CheckOrigin:     proxy.OriginChecker(app.corsPolicy().AllowedOrigins),
+		ErrorHandler:    app.proxyErrorResponse,
+		DrainContext:    app.drain.streamsContext(),
+		Logger:          logger.ForPackage(app.logger, "proxy"),
+	})
+}
+
+// proxyErrorResponse answers requests the proxy could not forward. Client errors reported by
+// the resolver (missing namespace, forbidden) keep their status; anything else is a 502/503.
+func (app *App) proxyErrorResponse(w http.ResponseWriter, r *http.Request, statusCode int, err error) {
+	if apiErr := apierrors.FromError(err); apiErr.StatusCode < http.StatusInternalServerError {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+
+	httpError := &HTTPError{StatusCode: statusCode, Error: ErrorPayload{Code: strconv.Itoa(statusCode), Message: http.StatusText(statusCode)}}
+	app.errorResponse(w, r, httpError)
+}
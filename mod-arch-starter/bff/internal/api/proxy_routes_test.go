@@ -0,0 +1,132 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/proxy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func registerTestProxyRoute(t *testing.T, factory ProxyRouteFactory) {
+	t.Helper()
+	RegisterProxyRoute(factory)
+	t.Cleanup(func() {
+		proxyRouteMu.Lock()
+		defer proxyRouteMu.Unlock()
+		proxyRoutes = nil
+	})
+}
+
+func TestProxyRoutes_ForwardAuthenticatedRequests(t *testing.T) {
+	var gotPath, gotUser string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotUser = r.Header.Get(constants.KubeflowUserIDHeader)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer upstream.Close()
+	target, err := url.Parse(upstream.URL)
+	require.NoError(t, err)
+
+	registerTestProxyRoute(t, func(app *App) (proxy.Route, error) {
+		return proxy.Route{Name: "upstream", PathPrefix: ApiPathPrefix + "/proxy/upstream/", RewritePrefix: "/v1/", Target: target}, nil
+	})
+
+	app := newWatchTestApp(t)
+	app.reverseProxy, err = app.newReverseProxy()
+	require.NoError(t, err)
+
+	for _, path := range []string{"/api/v1/proxy/upstream/models", PathPrefix + "/api/v1/proxy/upstream/models"} {
+		gotPath, gotUser = "", ""
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(constants.KubeflowUserIDHeader, "user@example.com")
+		rr := httptest.NewRecorder()
+
+		app.Routes().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNoContent, rr.Code, path)
+		assert.Equal(t, "/v1/models", gotPath, path)
+		assert.Equal(t, "user@example.com", gotUser, path)
+	}
+
+	// Requests without an identity never reach the upstream
+	gotPath = ""
+	rr := httptest.NewRecorder()
+	app.Routes().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/proxy/upstream/models", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Empty(t, gotPath)
+}
+
+func TestProxyRoutes_WebSocketBearerSubprotocol(t *testing.T) {
+	var gotAuthorization, gotProtocols string
+	upgrader := websocket.Upgrader{Subprotocols: []string{"v4.channel.k8s.io"}}
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuthorization = r.Header.Get("Authorization")
+		gotProtocols = r.Header.Get("Sec-WebSocket-Protocol")
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		conn.Close()
+	}))
+	defer upstream.Close()
+	target, err := url.Parse(upstream.URL)
+	require.NoError(t, err)
+
+	registerTestProxyRoute(t, func(app *App) (proxy.Route, error) {
+		return proxy.Route{Name: "exec", PathPrefix: ApiPathPrefix + "/proxy/exec/", Target: target}, nil
+	})
+
+	app := newWatchTestApp(t)
+	app.config.AuthTokenHeader = config.DefaultAuthTokenHeader
+	app.config.AuthTokenPrefix = config.DefaultAuthTokenPrefix
+	app.identityExtractor = IdentityExtractorFunc(func(r *http.Request) (*k8s.RequestIdentity, error) {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found {
+			return nil, ErrUnauthenticated
+		}
+		return &k8s.RequestIdentity{Token: token}, nil
+	})
+	app.reverseProxy, err = app.newReverseProxy()
+	require.NoError(t, err)
+	front := httptest.NewServer(app.Routes())
+	defer front.Close()
+
+	// Browsers can't set the Authorization header of a WebSocket
+	dialer := websocket.Dialer{Subprotocols: []string{proxy.BearerSubprotocol("user-token"), "v4.channel.k8s.io"}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(front.URL, "http")+"/api/v1/proxy/exec/attach", nil)
+	require.NoError(t, err)
+	conn.Close()
+
+	assert.Equal(t, "v4.channel.k8s.io", conn.Subprotocol())
+	assert.Equal(t, "Bearer user-token", gotAuthorization)
+	assert.Equal(t, "v4.channel.k8s.io", gotProtocols, "the token isn't forwarded in the subprotocols")
+}
+
+func TestProxyRoutes_RejectsPrefixOutsideAPI(t *testing.T) {
+	registerTestProxyRoute(t, func(app *App) (proxy.Route, error) {
+		return proxy.Route{Name: "public", PathPrefix: "/public/", Target: &url.URL{Scheme: "http", Host: "upstream"}}, nil
+	})
+
+	app := newWatchTestApp(t)
+	_, err := app.newReverseProxy()
+	assert.ErrorContains(t, err, "must be under /api/v1")
+}
+
+func TestProxyErrorResponse(t *testing.T) {
+	app := newWatchTestApp(t)
+
+	rr := httptest.NewRecorder()
+	app.proxyErrorResponse(rr, httptest.NewRequest(http.MethodGet, "/api/v1/proxy/x", nil), http.StatusServiceUnavailable, proxy.ErrNoUpstream)
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"code": "503"`)
+}
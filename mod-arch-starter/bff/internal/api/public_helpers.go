@@ -1,17 +1,30 @@
 package api
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
 
 	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/cache"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/chaos"
 	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/database"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/featureflags"
 	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/bffclient"
+	grpcclient "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/grpc"
 	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/odhdashboard"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/metrics"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/notifications"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/operations"
 	"github.com/opendatahub-io/mod-arch-library/bff/internal/proxy"
 	"github.com/opendatahub-io/mod-arch-library/bff/internal/repositories"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/resilience"
 )
 
 // BadRequest sends a 400 Bad Request response with the given error message.
@@ -26,6 +39,13 @@ func (app *App) ServerError(w http.ResponseWriter, r *http.Request, err error) {
 	app.serverErrorResponse(w, r, err)
 }
 
+// ErrorResponse translates err (for example a Kubernetes StatusError returned by a repository)
+// into the matching status code and JSON error envelope.
+// This is a public wrapper around apiErrorResponse for use by downstream extensions.
+func (app *App) ErrorResponse(w http.ResponseWriter, r *http.Request, err error) { //nolint:unused
+	app.apiErrorResponse(w, r, err)
+}
+
 // NotImplemented sends a 501 Not Implemented response for features not yet available.
 // This is used to create placeholder handlers that downstream code can override.
 func (app *App) NotImplemented(w http.ResponseWriter, r *http.Request, feature string) { //nolint:unused
@@ -57,6 +77,12 @@ func (app *App) Config() config.EnvConfig { //nolint:unused
 	return app.config
 }
 
+// FeatureFlags returns the feature flags of the app. Handlers usually call FeatureEnabled instead.
+// This allows downstream extensions to evaluate flags outside of requests.
+func (app *App) FeatureFlags() *featureflags.Set { //nolint:unused
+	return app.featureFlags
+}
+
 // Logger returns the application logger.
 // This allows downstream extensions to use the same logger as the app.
 func (app *App) Logger() *slog.Logger { //nolint:unused
@@ -69,6 +95,12 @@ func (app *App) KubernetesClientFactory() k8s.KubernetesClientFactory { //nolint
 	return app.kubernetesClientFactory
 }
 
+// Clusters returns the registry of the clusters the Kubernetes clients can target.
+// This allows downstream extensions to target a cluster by name.
+func (app *App) Clusters() *k8s.ClusterRegistry { //nolint:unused
+	return app.clusterRegistry()
+}
+
 // Repositories returns the repositories container.
 // This allows downstream extensions to access the repositories.
 func (app *App) Repositories() *repositories.Repositories { //nolint:unused
@@ -81,6 +113,82 @@ func (app *App) BFFClientFactory() bffclient.BFFClientFactory { //nolint:unused
 	return app.bffClientFactory
 }
 
+// Metrics returns the Prometheus metrics of the app, or nil when METRICS_ENABLED is off.
+// Downstream extensions can register their own collectors on Metrics().Registry().
+func (app *App) Metrics() *metrics.Metrics { //nolint:unused
+	return app.metrics
+}
+
+// ResponseCache returns the response cache, or nil when RESPONSE_CACHE_TTL is not set.
+// Downstream repositories can opt into it with cache.Get; a nil cache caches nothing.
+func (app *App) ResponseCache() *cache.Cache { //nolint:unused
+	return app.responseCache
+}
+
+// ODHDashboard returns a client of the OpenDataHub dashboard settings of
+// ODH_DASHBOARD_NAMESPACE, reading them with the credentials of the request. Downstream
+// handlers use it to follow the platform settings, e.g. to hide a feature disabled in the
+// OdhDashboardConfig; odhdashboard.ErrNotInstalled tells that the cluster has no dashboard.
+func (app *App) ODHDashboard(ctx context.Context) (*odhdashboard.Client, error) { //nolint:unused
+	client, err := app.kubernetesClientFactory.GetClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	identity, _ := ctx.Value(constants.RequestIdentityKey).(*k8s.RequestIdentity)
+	return app.odhDashboardClient(client, identity), nil
+}
+
+// Resilience returns the retry settings and circuit breakers of the upstream calls.
+// Downstream extensions pass it in mrserver.UpstreamConfig.Resilience, or wrap the transport of
+// their own clients with Resilience().WrapTransport(name).
+func (app *App) Resilience() *resilience.Policy { //nolint:unused
+	return app.resilience
+}
+
+// Chaos returns the fault injection of -chaos-enabled, nil when disabled. Downstream extensions
+// inject the faults of their upstreams with Chaos().WrapTransportFor, or in
+// mrserver.UpstreamConfig.WrapTransport; a nil injector injects nothing.
+func (app *App) Chaos() *chaos.Injector { //nolint:unused
+	return app.chaos
+}
+
+// UpstreamTLSConfig returns the TLS client configuration of the upstream name: its
+// -upstream-tls entry, or -bundle-paths and -insecure-skip-verify. Downstream extensions pass it
+// in mrserver.UpstreamConfig.TLSConfig or grpcclient.Config.TLSConfig; it follows the rotations
+// of the files.
+func (app *App) UpstreamTLSConfig(name string) *tls.Config { //nolint:unused
+	return app.upstreamTLSConfig(name)
+}
+
+// GRPCConnections returns the shared connections to gRPC services, closed on shutdown.
+// Downstream repositories get the connection of their service with
+// GRPCConnections().Conn(grpcclient.Config{...}) and call it with the request context, which
+// carries the caller's identity.
+func (app *App) GRPCConnections() *grpcclient.Pool { //nolint:unused
+	return app.grpcConns
+}
+
+// Notifications returns the bus of the notifications streamed to the UI.
+// Downstream repositories and watchers publish with Notifications().Publish; address them to
+// users or groups, as without recipients every user receives them.
+func (app *App) Notifications() *notifications.Bus { //nolint:unused
+	return app.notifications
+}
+
+// Database returns the database of the state of the modules, nil unless -database-driver and
+// -database-url are set. DatabaseModule modules create their tables with Migrations, and
+// their repositories query them with database.Table.
+func (app *App) Database() *database.DB { //nolint:unused
+	return app.database
+}
+
+// Operations returns the tracker of the long-running actions. Handlers usually call
+// StartOperation instead; jobs and other background code can start operations on behalf of a
+// user with Operations().Start.
+func (app *App) Operations() *operations.Tracker { //nolint:unused
+	return app.operations
+}
+
 // WebSocketTracker returns the shared connection tracker for WebSocket endpoints.
 func (app *App) WebSocketTracker() *proxy.ConnectionTracker { //nolint:unused
 	return app.wsTracker
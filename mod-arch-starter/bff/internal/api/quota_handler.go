@@ -0,0 +1,41 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+)
+
+type QuotaEnvelope Envelope[models.QuotaModel, None]
+
+// GetQuotaHandler returns the ResourceQuotas, with their usage, and the LimitRanges of the
+// namespace (AttachNamespace).
+func (app *App) GetQuotaHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	ctx := r.Context()
+	identity, ok := ctx.Value(constants.RequestIdentityKey).(*kubernetes.RequestIdentity)
+	if !ok || identity == nil {
+		app.badRequestResponse(w, r, fmt.Errorf("missing RequestIdentity in context"))
+		return
+	}
+	namespace := requestNamespace(r)
+
+	client, err := app.kubernetesClientFactory.GetClient(ctx)
+	if err != nil {
+		app.serverErrorResponse(w, r, fmt.Errorf("failed to get Kubernetes client: %w", err))
+		return
+	}
+
+	quota, err := app.repositories.Quota.GetQuota(client, ctx, identity, namespace)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.WriteJSON(w, http.StatusOK, QuotaEnvelope{Data: quota}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
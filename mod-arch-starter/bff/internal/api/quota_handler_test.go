@@ -0,0 +1,38 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetQuotaHandler(t *testing.T) {
+	app := newWatchTestApp(t)
+
+	for _, tt := range []struct {
+		namespace string
+		status    int
+	}{
+		{namespace: "dora-namespace", status: http.StatusOK},
+		{namespace: "bella-namespace", status: http.StatusForbidden},
+		{status: http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest(http.MethodGet, QuotaPath+"?namespace="+tt.namespace, nil)
+		req.Header.Set(constants.KubeflowUserIDHeader, "doraNonAdmin@example.com")
+		rr := httptest.NewRecorder()
+		app.Routes().ServeHTTP(rr, req)
+
+		require.Equal(t, tt.status, rr.Code, rr.Body.String())
+		if tt.status == http.StatusOK {
+			var envelope QuotaEnvelope
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &envelope))
+			assert.Equal(t, tt.namespace, envelope.Data.Namespace)
+			assert.NotNil(t, envelope.Data.Resources)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/apierrors"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/metrics"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/ratelimit"
+)
+
+// Names of the rate limits, used as the "limit" label of the throttled requests metric.
+const (
+	rateLimitUser = "user"
+	rateLimitIP   = "ip"
+	rateLimitCost = "cost"
+)
+
+// Headers reporting the cost budget of the user to the clients, so they back off before
+// being throttled (see the IETF RateLimit header fields draft).
+const (
+	rateLimitLimitHeader     = "RateLimit-Limit"
+	rateLimitRemainingHeader = "RateLimit-Remaining"
+	rateLimitResetHeader     = "RateLimit-Reset"
+	rateLimitPolicyHeader    = "RateLimit-Policy"
+)
+
+// routeCosts are the costs of the expensive routes, keyed like -route-costs; other requests
+// cost 1.
+var routeCosts = map[string]int{
+	// Access reviews of every namespace
+	http.MethodGet + " " + NamespacePath: 10,
+	// One access review per entry
+	http.MethodPost + " " + PermissionsBatchPath: 5,
+	// Lists of every namespace, or of the cluster
+	http.MethodGet + " " + ServicesPath:          5,
+	http.MethodGet + " " + ClusterHealthPath:     5,
+	http.MethodGet + " " + HardwareProfilesPath:  5,
+	http.MethodGet + " " + ModelRegistryListPath: 5,
+	// Queries may resolve many resources
+	http.MethodPost + " " + GraphQLPath: 5,
+}
+
+// rateLimiters throttles API requests per user and per client IP, and spends the cost budgets
+// of the users; a nil limiter is disabled.
+type rateLimiters struct {
+	user *ratelimit.Limiter
+	ip   *ratelimit.Limiter
+	cost *ratelimit.Budget
+	// costs overrides routeCosts
+	costs map[string]int
+}
+
+func newRateLimiters(cfg config.EnvConfig) rateLimiters {
+	var limiters rateLimiters
+	if cfg.RateLimitUser > 0 {
+		limiters.user = ratelimit.New(cfg.RateLimitUser, cfg.RateLimitUserBurst)
+	}
+	if cfg.RateLimitIP > 0 {
+		limiters.ip = ratelimit.New(cfg.RateLimitIP, cfg.RateLimitIPBurst)
+	}
+	if cfg.CostBudget > 0 {
+		limiters.cost = ratelimit.NewBudget(cfg.CostBudget, cfg.CostWindow)
+		// Validated with the configuration
+		limiters.costs, _ = config.ParseRouteCosts(cfg.RouteCosts)
+	}
+	return limiters
+}
+
+// LimitByIP rejects API requests of client IPs over the -rate-limit-ip limit with a 429. It runs
+// before InjectRequestIdentity, so clients failing authentication are throttled too.
+func (app *App) LimitByIP(next http.Handler) http.Handler {
+	if app.rateLimiters.ip == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !app.requiresAuth(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if allowed, retryAfter := app.rateLimiters.ip.Allow(clientIP(r)); !allowed {
+			app.rateLimitedResponse(w, r, rateLimitIP, retryAfter)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// LimitByUser rejects API requests of users over the -rate-limit-user limit with a 429. It runs
+// after InjectRequestIdentity and keys the limit by the RequestIdentity user (see rateLimitKey).
+func (app *App) LimitByUser(next http.Handler) http.Handler {
+	if app.rateLimiters.user == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, _ := r.Context().Value(constants.RequestIdentityKey).(*k8s.RequestIdentity)
+		key := rateLimitKey(identity)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if allowed, retryAfter := app.rateLimiters.user.Allow(key); !allowed {
+			app.rateLimitedResponse(w, r, rateLimitUser, retryAfter)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// LimitByCost spends the cost of API requests (route names the matched route) from the
+// -cost-budget of their user, keyed like LimitByUser, and rejects the requests over it with a
+// 429. The RateLimit-* headers of the responses report the budget left, so clients can slow
+// down before that.
+func (app *App) LimitByCost(route metrics.RouteFunc, next http.Handler) http.Handler {
+	if app.rateLimiters.cost == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, _ := r.Context().Value(constants.RequestIdentityKey).(*k8s.RequestIdentity)
+		key := rateLimitKey(identity)
+		pattern := route(r)
+		cost := app.rateLimiters.routeCost(r.Method, pattern)
+		if key == "" || pattern == "" || cost == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		allowed, usage := app.rateLimiters.cost.Spend(key, cost)
+		header := w.Header()
+		header.Set(rateLimitLimitHeader, strconv.Itoa(usage.Limit))
+		header.Set(rateLimitRemainingHeader, strconv.Itoa(usage.Remaining))
+		header.Set(rateLimitResetHeader, strconv.Itoa(ceilSeconds(usage.Reset, 0)))
+		header.Set(rateLimitPolicyHeader, fmt.Sprintf("%d;w=%d", usage.Limit, ceilSeconds(app.config.CostWindow, 1)))
+		if !allowed {
+			app.rateLimitedResponse(w, r, rateLimitCost, usage.RetryAfter)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// routeCost returns the cost of a request of the route pattern: its -route-costs entry, its
+// routeCosts entry, or 1.
+func (l rateLimiters) routeCost(method, pattern string) int {
+	for _, key := range []string{method + " " + pattern, pattern} {
+		if cost, ok := l.costs[key]; ok {
+			return cost
+		}
+	}
+	if cost, ok := routeCosts[method+" "+pattern]; ok {
+		return cost
+	}
+	return 1
+}
+
+// ceilSeconds rounds d up to whole seconds, but not below minimum.
+func ceilSeconds(d time.Duration, minimum int) int {
+	return max(minimum, int(math.Ceil(d.Seconds())))
+}
+
+// rateLimitKey identifies the user of identity. With the user_token auth method only the token
+// is known until the API server resolves it, so its hash stands in for the user.
+func rateLimitKey(identity *k8s.RequestIdentity) string {
+	switch {
+	case identity == nil:
+		return ""
+	case identity.UserID != "":
+		return "user:" + identity.UserID
+	case identity.Token != "":
+		sum := sha256.Sum256([]byte(identity.Token))
+		return "token:" + hex.EncodeToString(sum[:16])
+	default:
+		return ""
+	}
+}
+
+// rateLimitedResponse writes a 429 with a Retry-After header, in whole seconds.
+func (app *App) rateLimitedResponse(w http.ResponseWriter, r *http.Request, limit string, retryAfter time.Duration) {
+	if app.metrics != nil {
+		app.metrics.RecordThrottled(limit)
+	}
+
+	seconds := int32(ceilSeconds(retryAfter, 1))
+	app.apiErrorResponse(w, r, &apierrors.Error{
+		StatusCode: http.StatusTooManyRequests,
+		Message:    apierrors.MessageTooManyRequests,
+		Details:    map[string]any{"limit": limit, "retryAfterSeconds": seconds},
+	})
+}
+
+// clientIP returns the IP address of the peer of r.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
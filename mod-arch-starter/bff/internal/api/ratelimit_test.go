@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimits(t *testing.T) {
+	app := newWatchTestApp(t)
+	app.rateLimiters = newRateLimiters(config.EnvConfig{RateLimitUser: 0.001, RateLimitUserBurst: 2, RateLimitIP: 0.001, RateLimitIPBurst: 3})
+	routes := app.Routes()
+
+	get := func(user, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, UserPath, nil)
+		req.Header.Set(constants.KubeflowUserIDHeader, user)
+		req.RemoteAddr = remoteAddr
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, req)
+		return rr
+	}
+
+	assert.Equal(t, http.StatusOK, get("user@example.com", "10.0.0.1:1000").Code)
+	assert.Equal(t, http.StatusOK, get("user@example.com", "10.0.0.1:1001").Code)
+
+	rr := get("user@example.com", "10.0.0.2:1000")
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.NotEmpty(t, rr.Header().Get("Retry-After"))
+	var body HTTPError
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, rateLimitUser, body.Error.Details["limit"])
+
+	// Another user from the first IP hits the IP limit
+	assert.Equal(t, http.StatusOK, get("doraNonAdmin@example.com", "10.0.0.1:1002").Code)
+	rr = get("doraNonAdmin@example.com", "10.0.0.1:1003")
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, rateLimitIP, body.Error.Details["limit"])
+
+	// Health endpoints are never throttled
+	for i := 0; i < 5; i++ {
+		req := httptest.NewRequest(http.MethodGet, HealthCheckPath, nil)
+		req.RemoteAddr = "10.0.0.1:1004"
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+	}
+}
+
+func TestRateLimitKey(t *testing.T) {
+	assert.Empty(t, rateLimitKey(nil))
+	assert.Equal(t, "user:alice", rateLimitKey(&kubernetes.RequestIdentity{UserID: "alice", Token: "t"}))
+	tokenKey := rateLimitKey(&kubernetes.RequestIdentity{Token: "secret-token"})
+	assert.Contains(t, tokenKey, "token:")
+	assert.NotContains(t, tokenKey, "secret-token")
+}
+
+func TestCostBudget(t *testing.T) {
+	app := newWatchTestApp(t)
+	app.config.CostWindow = time.Minute
+	app.rateLimiters = newRateLimiters(config.EnvConfig{CostBudget: 12, CostWindow: time.Minute, RouteCosts: []string{"GET /api/v1/version=0"}})
+	routes := app.Routes()
+
+	get := func(path, user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(constants.KubeflowUserIDHeader, user)
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get(NamespacePath, "user@example.com")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "12", rr.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "2", rr.Header().Get("RateLimit-Remaining"), "listing namespaces costs 10")
+	assert.Equal(t, "60", rr.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "12;w=60", rr.Header().Get("RateLimit-Policy"))
+
+	rr = get(UserPath, "user@example.com")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "1", rr.Header().Get("RateLimit-Remaining"))
+
+	rr = get(NamespacePath, "user@example.com")
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "1", rr.Header().Get("RateLimit-Remaining"), "a rejected request costs nothing")
+	assert.Equal(t, "60", rr.Header().Get("Retry-After"))
+	var body HTTPError
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, rateLimitCost, body.Error.Details["limit"])
+
+	assert.Equal(t, http.StatusOK, get(UserPath, "user@example.com").Code)
+	rr = get(VersionPath, "user@example.com")
+	assert.Equal(t, http.StatusOK, rr.Code, "route-costs frees the route")
+	assert.Empty(t, rr.Header().Get("RateLimit-Remaining"))
+
+	// Users have their own budgets
+	assert.Equal(t, http.StatusOK, get(NamespacePath, "doraNonAdmin@example.com").Code)
+}
+
+func TestRateLimiters_RouteCost(t *testing.T) {
+	limiters := newRateLimiters(config.EnvConfig{CostBudget: 100, CostWindow: time.Minute, RouteCosts: []string{"/api/v1/secrets=3", "POST /api/v1/secrets=7"}})
+	assert.Equal(t, 10, limiters.routeCost(http.MethodGet, NamespacePath))
+	assert.Equal(t, 1, limiters.routeCost(http.MethodGet, UserPath))
+	assert.Equal(t, 3, limiters.routeCost(http.MethodGet, SecretsPath))
+	assert.Equal(t, 7, limiters.routeCost(http.MethodPost, SecretsPath))
+}
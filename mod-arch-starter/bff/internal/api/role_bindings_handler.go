@@ -0,0 +1,83 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/validation"
+)
+
+type RoleBindingsEnvelope Envelope[[]models.RoleBindingModel, None]
+type RoleBindingEnvelope Envelope[*models.RoleBindingModel, None]
+type RoleBindingDryRunEnvelope Envelope[*models.RoleBindingModel, *DryRunMetadata]
+type RoleBindingRequestEnvelope Envelope[*models.RoleBindingRequest, None]
+
+// GetRoleBindingsHandler lists who the namespace (AttachNamespace) is shared with: the
+// bindings of the admin, edit and view roles to users and groups.
+func (app *App) GetRoleBindingsHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	identity, namespace, client, ok := app.namespacedRequest(w, r)
+	if !ok {
+		return
+	}
+
+	bindings, err := app.repositories.RoleBinding.List(client, r.Context(), identity, namespace)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if err := app.WriteJSON(w, http.StatusOK, RoleBindingsEnvelope{Data: bindings}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// CreateRoleBindingHandler shares the namespace with a user or group, with the edit or view role.
+// With dryRun=true nothing is created, and the changes are returned in the metadata.
+func (app *App) CreateRoleBindingHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	r, dryRun, ok := app.dryRunRequest(w, r)
+	if !ok {
+		return
+	}
+	var body RoleBindingRequestEnvelope
+	if err := app.ReadValidJSON(w, r, &body); err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if body.Data == nil {
+		app.apiErrorResponse(w, r, validation.Invalid("data", "is required"))
+		return
+	}
+	identity, namespace, client, ok := app.namespacedRequest(w, r)
+	if !ok {
+		return
+	}
+
+	created, err := app.repositories.RoleBinding.Grant(client, r.Context(), identity, namespace, *body.Data)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if dryRun != nil {
+		if err := app.WriteJSON(w, http.StatusOK, RoleBindingDryRunEnvelope{Data: &created, Metadata: newDryRunMetadata(dryRun)}, nil); err != nil {
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	if err := app.WriteJSON(w, http.StatusCreated, RoleBindingEnvelope{Data: &created}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// DeleteRoleBindingHandler revokes the access granted by the binding named in the path.
+func (app *App) DeleteRoleBindingHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	identity, namespace, client, ok := app.namespacedRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.repositories.RoleBinding.Revoke(client, r.Context(), identity, namespace, ps.ByName("name")); err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
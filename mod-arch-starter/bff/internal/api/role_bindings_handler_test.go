@@ -0,0 +1,54 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoleBindingHandlers(t *testing.T) {
+	app := newWatchTestApp(t)
+	call := func(method, path, user string, body io.Reader) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, body)
+		req.Header.Set(constants.KubeflowUserIDHeader, user)
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		app.Routes().ServeHTTP(rr, req)
+		return rr
+	}
+	const dora = "doraNonAdmin@example.com"
+
+	rr := call(http.MethodPost, RoleBindingsPath+"?namespace=dora-namespace", dora,
+		strings.NewReader(`{"data": {"role": "view", "subject": {"kind": "Group", "name": "data-scientists"}}}`))
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var created RoleBindingEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+	assert.Equal(t, "view", created.Data.Role)
+
+	rr = call(http.MethodGet, RoleBindingsPath+"?namespace=dora-namespace", dora, nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var listed RoleBindingsEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &listed))
+	require.Len(t, listed.Data, 1)
+	assert.Equal(t, created.Data.Name, listed.Data[0].Name)
+
+	rr = call(http.MethodPost, RoleBindingsPath+"?namespace=dora-namespace", dora,
+		strings.NewReader(`{"data": {"role": "admin", "subject": {"kind": "ServiceAccount", "name": "default"}}}`))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "data.role")
+	assert.Contains(t, rr.Body.String(), "data.subject.kind")
+
+	rr = call(http.MethodGet, RoleBindingsPath+"?namespace=bella-namespace", dora, nil)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	rr = call(http.MethodDelete, RoleBindingsPath+"/"+created.Data.Name+"?namespace=dora-namespace", "bellaNonAdmin@example.com", nil)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	rr = call(http.MethodDelete, RoleBindingsPath+"/"+created.Data.Name+"?namespace=dora-namespace", dora, nil)
+	assert.Equal(t, http.StatusNoContent, rr.Code, rr.Body.String())
+}
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/pagination"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/validation"
+)
+
+type SecretsEnvelope PagedResponse[models.SecretModel]
+type SecretEnvelope Envelope[*models.SecretModel, None]
+type SecretDryRunEnvelope Envelope[*models.SecretModel, *DryRunMetadata]
+
+var secretFields = pagination.Fields[models.SecretModel]{
+	"name":      func(secret models.SecretModel) string { return secret.Name },
+	"type":      func(secret models.SecretModel) string { return secret.Type },
+	"managedBy": func(secret models.SecretModel) string { return secret.ManagedBy },
+}
+
+// namespacedRequest returns the RequestIdentity, the verified namespace (AttachNamespace) and the
+// Kubernetes client of a request, writing an error response when one is missing.
+func (app *App) namespacedRequest(w http.ResponseWriter, r *http.Request) (*kubernetes.RequestIdentity, string, kubernetes.KubernetesClientInterface, bool) {
+	ctx := r.Context()
+	identity, ok := ctx.Value(constants.RequestIdentityKey).(*kubernetes.RequestIdentity)
+	if !ok || identity == nil {
+		app.badRequestResponse(w, r, fmt.Errorf("missing RequestIdentity in context"))
+		return nil, "", nil, false
+	}
+	namespace := requestNamespace(r)
+
+	client, err := app.kubernetesClientFactory.GetClient(ctx)
+	if err != nil {
+		app.serverErrorResponse(w, r, fmt.Errorf("failed to get Kubernetes client: %w", err))
+		return nil, "", nil, false
+	}
+	return identity, namespace, client, true
+}
+
+// boolQuery parses the optional boolean query parameter name.
+func boolQuery(r *http.Request, name string) (bool, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q: must be true or false", name, raw)
+	}
+	return value, nil
+}
+
+// GetSecretsHandler lists the Secrets of the namespace (AttachNamespace), redacted. With
+// managed=true only the Secrets created through the BFF are listed. The list parameters of
+// pagination.ParseListOptions page the result.
+func (app *App) GetSecretsHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	managed, err := boolQuery(r, "managed")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	opts, err := pagination.ParseListOptions(r.URL.Query())
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	identity, namespace, client, ok := app.namespacedRequest(w, r)
+	if !ok {
+		return
+	}
+
+	secrets, err := app.repositories.Secret.List(client, r.Context(), identity, namespace, managed)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+
+	page, metadata, err := pagination.Apply(secrets, opts, secretFields)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.WriteJSON(w, http.StatusOK, SecretsEnvelope{Data: page, Metadata: metadata}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// GetSecretHandler returns the Secret named in the path, redacted unless reveal=true and the
+// user passes the reveal check.
+func (app *App) GetSecretHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	reveal, err := boolQuery(r, "reveal")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	identity, namespace, client, ok := app.namespacedRequest(w, r)
+	if !ok {
+		return
+	}
+
+	secret, err := app.repositories.Secret.Get(client, r.Context(), identity, namespace, ps.ByName("name"), reveal)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if err := app.WriteJSON(w, http.StatusOK, SecretEnvelope{Data: &secret}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// CreateSecretHandler creates a Secret in the namespace, managed by the BFF. With dryRun=true
+// nothing is created, and the changes are returned in the metadata.
+func (app *App) CreateSecretHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	r, dryRun, ok := app.dryRunRequest(w, r)
+	if !ok {
+		return
+	}
+	var body SecretEnvelope
+	if err := app.ReadValidJSON(w, r, &body); err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if body.Data == nil || body.Data.Name == "" {
+		app.apiErrorResponse(w, r, validation.Invalid("data.name", "is required"))
+		return
+	}
+	identity, namespace, client, ok := app.namespacedRequest(w, r)
+	if !ok {
+		return
+	}
+
+	created, err := app.repositories.Secret.Create(client, r.Context(), identity, namespace, *body.Data)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if dryRun != nil {
+		if err := app.WriteJSON(w, http.StatusOK, SecretDryRunEnvelope{Data: &created, Metadata: newDryRunMetadata(dryRun)}, nil); err != nil {
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	if err := app.WriteJSON(w, http.StatusCreated, SecretEnvelope{Data: &created}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// UpdateSecretHandler applies the data of the Secret named in the path, when it is managed by
+// the BFF. Keys left in redactedKeys keep their value. It fails with a 409 listing the
+// conflicts when it sets keys another field manager owns, unless force=true takes them over.
+// dryRun=true previews the update.
+func (app *App) UpdateSecretHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	r, dryRun, ok := app.dryRunRequest(w, r)
+	if !ok {
+		return
+	}
+	force, err := boolQuery(r, "force")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	var body SecretEnvelope
+	if err := app.ReadValidJSON(w, r, &body); err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if body.Data == nil {
+		app.apiErrorResponse(w, r, validation.Invalid("data", "is required"))
+		return
+	}
+	name := ps.ByName("name")
+	if body.Data.Name != "" && body.Data.Name != name {
+		app.apiErrorResponse(w, r, validation.Invalid("data.name", fmt.Sprintf("%q does not match the path", body.Data.Name)))
+		return
+	}
+	body.Data.Name = name
+	identity, namespace, client, ok := app.namespacedRequest(w, r)
+	if !ok {
+		return
+	}
+
+	updated, err := app.repositories.Secret.Update(client, r.Context(), identity, namespace, *body.Data, force)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if dryRun != nil {
+		if err := app.WriteJSON(w, http.StatusOK, SecretDryRunEnvelope{Data: &updated, Metadata: newDryRunMetadata(dryRun)}, nil); err != nil {
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	if err := app.WriteJSON(w, http.StatusOK, SecretEnvelope{Data: &updated}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
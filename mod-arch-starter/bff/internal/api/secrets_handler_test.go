@@ -0,0 +1,153 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/objectdiff"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretHandlers(t *testing.T) {
+	app := newWatchTestApp(t)
+	routes := app.Routes()
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(constants.KubeflowUserIDHeader, "doraNonAdmin@example.com")
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve(http.MethodPost, SecretsPath+"?namespace=dora-namespace", `{"data":{"name":"connection","data":{"password":"czNjcmV0"}}}`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var secret SecretEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &secret))
+	assert.Equal(t, repositories.DefaultManager, secret.Data.ManagedBy)
+	assert.Equal(t, []string{"password"}, secret.Data.RedactedKeys)
+	assert.NotContains(t, rr.Body.String(), "czNjcmV0")
+
+	rr = serve(http.MethodGet, SecretsPath+"?namespace=dora-namespace&managed=true", "")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var secrets SecretsEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &secrets))
+	require.Len(t, secrets.Data, 1)
+	assert.NotContains(t, rr.Body.String(), "czNjcmV0")
+
+	rr = serve(http.MethodPut, SecretsPath+"/connection?namespace=dora-namespace", `{"data":{"data":{"user":"ZG9yYQ==","password":null},"redactedKeys":["password"]}}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	rr = serve(http.MethodGet, SecretsPath+"/connection?namespace=dora-namespace&reveal=true", "")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &secret))
+	assert.Equal(t, map[string][]byte{"user": []byte("dora"), "password": []byte("s3cret")}, secret.Data.Data)
+
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodGet, SecretsPath+"/connection?namespace=dora-namespace&reveal=yes", "").Code)
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPut, SecretsPath+"/connection?namespace=dora-namespace", `{"data":{"name":"other"}}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, SecretsPath+"?namespace=dora-namespace", `{"data":{}}`).Code)
+	rr = serve(http.MethodPost, SecretsPath+"?namespace=dora-namespace", `{"data":{"name":"Connection_1"}}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"field": "data.name"`, "field-level details")
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, SecretsPath+"/missing?namespace=dora-namespace", "").Code)
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodGet, SecretsPath+"?namespace=bella-namespace", "").Code)
+}
+
+func TestSecretHandlers_Force(t *testing.T) {
+	app := newWatchTestApp(t)
+	routes := app.Routes()
+	serve := func(user, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(constants.KubeflowUserIDHeader, user)
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve("doraNonAdmin@example.com", http.MethodPost, SecretsPath+"?namespace=dora-namespace", `{"data":{"name":"connection","data":{"password":"czNjcmV0"}}}`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+	// The password was applied by dora
+	update := `{"data":{"data":{"password":"b3RoZXI="}}}`
+	rr = serve("user@example.com", http.MethodPut, SecretsPath+"/connection?namespace=dora-namespace", update)
+	require.Equal(t, http.StatusConflict, rr.Code, rr.Body.String())
+	assert.Contains(t, rr.Body.String(), `"field": ".data.password"`)
+	assert.Contains(t, rr.Body.String(), `mod-arch/doraNonAdmin@example.com`)
+
+	rr = serve("user@example.com", http.MethodPut, SecretsPath+"/connection?namespace=dora-namespace&force=true", update)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, http.StatusBadRequest, serve("user@example.com", http.MethodPut, SecretsPath+"/connection?namespace=dora-namespace&force=yes", update).Code)
+}
+
+func TestConfigMapHandlers(t *testing.T) {
+	app := newWatchTestApp(t)
+	app.repositories.ConfigMap.UseRedactionPolicy(repositories.RedactionPolicy{ConfigMapKeys: []string{"*token*"}})
+	routes := app.Routes()
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(constants.KubeflowUserIDHeader, "doraNonAdmin@example.com")
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve(http.MethodPost, ConfigMapsPath+"?namespace=dora-namespace", `{"data":{"name":"settings","data":{"url":"https://db","api_token":"t0ken"}}}`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+	rr = serve(http.MethodGet, ConfigMapsPath+"/settings?namespace=dora-namespace", "")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var configMap ConfigMapEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &configMap))
+	assert.Equal(t, map[string]string{"url": "https://db", "api_token": ""}, configMap.Data.Data)
+	assert.Equal(t, []string{"api_token"}, configMap.Data.RedactedKeys)
+
+	rr = serve(http.MethodGet, ConfigMapsPath+"/settings?namespace=dora-namespace&reveal=true", "")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Contains(t, rr.Body.String(), "t0ken")
+
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodPut, ConfigMapsPath+"/settings?namespace=bella-namespace", `{"data":{"data":{}}}`).Code)
+}
+
+func TestSecretHandlers_DryRun(t *testing.T) {
+	app := newWatchTestApp(t)
+	routes := app.Routes()
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(constants.KubeflowUserIDHeader, "doraNonAdmin@example.com")
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve(http.MethodPost, SecretsPath+"?namespace=dora-namespace&dryRun=true", `{"data":{"name":"connection","data":{"password":"czNjcmV0"}}}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var preview SecretDryRunEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &preview))
+	assert.Equal(t, "connection", preview.Data.Name)
+	require.NotNil(t, preview.Metadata)
+	assert.True(t, preview.Metadata.DryRun)
+	assert.Contains(t, preview.Metadata.Changes, objectdiff.Change{Op: objectdiff.OpAdd, Path: "/data/password", Redacted: true})
+	assert.NotContains(t, rr.Body.String(), "czNjcmV0")
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, SecretsPath+"/connection?namespace=dora-namespace", "").Code, "nothing is created")
+
+	rr = serve(http.MethodPost, SecretsPath+"?namespace=dora-namespace", `{"data":{"name":"connection","data":{"password":"czNjcmV0"}}}`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+	rr = serve(http.MethodPut, SecretsPath+"/connection?namespace=dora-namespace&dryRun=true", `{"data":{"data":{"password":null},"labels":{"team":"a"},"redactedKeys":["password"]}}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &preview))
+	assert.Equal(t, []objectdiff.Change{{Op: objectdiff.OpAdd, Path: "/metadata/labels/team", Value: "a"}}, preview.Metadata.Changes, "redacted keys keep their value")
+
+	rr = serve(http.MethodGet, SecretsPath+"/connection?namespace=dora-namespace", "")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var secret SecretEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &secret))
+	assert.Empty(t, secret.Data.Labels, "nothing is updated")
+
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, SecretsPath+"?namespace=dora-namespace&dryRun=maybe", `{"data":{"name":"other"}}`).Code)
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+)
+
+// SetSecurityHeaders sets X-Content-Type-Options and the configured Strict-Transport-Security,
+// X-Frame-Options, Referrer-Policy and Content-Security-Policy headers on every response, unless
+// -security-headers=false. A {nonce} in the policy is replaced with a random nonce, which
+// HTML pages read with CSPNonce to allow their inline scripts.
+func (app *App) SetSecurityHeaders(next http.Handler) http.Handler {
+	if !app.config.SecurityHeaders {
+		return next
+	}
+	hsts := ""
+	if app.config.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.FormatInt(int64(app.config.HSTSMaxAge/time.Second), 10)
+	}
+	policy := app.config.ContentSecurityPolicy
+	withNonce := strings.Contains(policy, config.CSPNoncePlaceholder)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := w.Header()
+		header.Set("X-Content-Type-Options", "nosniff")
+		if hsts != "" {
+			header.Set("Strict-Transport-Security", hsts)
+		}
+		if app.config.FrameOptions != "" {
+			header.Set("X-Frame-Options", app.config.FrameOptions)
+		}
+		if app.config.ReferrerPolicy != "" {
+			header.Set("Referrer-Policy", app.config.ReferrerPolicy)
+		}
+		if withNonce {
+			nonce := newCSPNonce()
+			header.Set("Content-Security-Policy", strings.ReplaceAll(policy, config.CSPNoncePlaceholder, nonce))
+			r = r.WithContext(context.WithValue(r.Context(), constants.CSPNonceKey, nonce))
+		} else if policy != "" {
+			header.Set("Content-Security-Policy", policy)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// CSPNonce returns the nonce of the Content-Security-Policy of the response to r, to set on the
+// inline <script> tags of a page, or "" when the policy has none.
+func CSPNonce(r *http.Request) string {
+	nonce, _ := r.Context().Value(constants.CSPNonceKey).(string)
+	return nonce
+}
+
+func newCSPNonce() string {
+	nonce := make([]byte, 16)
+	// crypto/rand.Read never fails since Go 1.24
+	_, _ = rand.Read(nonce)
+	return base64.RawURLEncoding.EncodeToString(nonce)
+}
+
+var scriptTag = regexp.MustCompile(`(?i)<script\b`)
+
+// addScriptNonce sets the CSP nonce of the response on the <script> tags of the frontend index.
+func addScriptNonce(r *http.Request, index []byte) []byte {
+	nonce := CSPNonce(r)
+	if nonce == "" {
+		return index
+	}
+	return scriptTag.ReplaceAll(index, []byte(`<script nonce="`+nonce+`"`))
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSecurityHeadersTestApp(t *testing.T) *App {
+	app := newWatchTestApp(t)
+	defaults := config.DefaultEnvConfig()
+	app.config.SecurityHeaders = true
+	app.config.HSTSMaxAge = defaults.HSTSMaxAge
+	app.config.FrameOptions = defaults.FrameOptions
+	app.config.ReferrerPolicy = defaults.ReferrerPolicy
+	app.config.ContentSecurityPolicy = defaults.ContentSecurityPolicy
+
+	app.config.StaticAssetsDir = t.TempDir()
+	index := `<html><head><script src="app.js"></script><SCRIPT>window.x = 1</SCRIPT></head></html>`
+	require.NoError(t, os.WriteFile(filepath.Join(app.config.StaticAssetsDir, "index.html"), []byte(index), 0o600))
+	return app
+}
+
+func TestSetSecurityHeaders(t *testing.T) {
+	app := newSecurityHeadersTestApp(t)
+	req := httptest.NewRequest(http.MethodGet, UserPath, nil)
+	req.Header.Set(constants.KubeflowUserIDHeader, "user@example.com")
+	rr := httptest.NewRecorder()
+	app.Routes().ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "nosniff", rr.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "max-age=31536000", rr.Header().Get("Strict-Transport-Security"))
+	assert.Equal(t, "DENY", rr.Header().Get("X-Frame-Options"))
+	assert.Equal(t, "strict-origin-when-cross-origin", rr.Header().Get("Referrer-Policy"))
+	csp := rr.Header().Get("Content-Security-Policy")
+	assert.Contains(t, csp, "default-src 'self'")
+	assert.NotContains(t, csp, config.CSPNoncePlaceholder)
+}
+
+func TestSetSecurityHeaders_IndexNonce(t *testing.T) {
+	app := newSecurityHeadersTestApp(t)
+	routes := app.Routes()
+
+	var nonces []string
+	for _, path := range []string{"/", "/some/spa/route"} {
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, rr.Code, path)
+
+		csp := rr.Header().Get("Content-Security-Policy")
+		_, rest, found := strings.Cut(csp, "'nonce-")
+		require.True(t, found, csp)
+		nonce, _, _ := strings.Cut(rest, "'")
+		assert.Equal(t, 2, strings.Count(rr.Body.String(), `nonce="`+nonce+`"`), "every script tag gets the nonce")
+		assert.Equal(t, "no-cache", rr.Header().Get("Cache-Control"))
+		nonces = append(nonces, nonce)
+	}
+	assert.NotEqual(t, nonces[0], nonces[1], "a nonce per response")
+}
+
+func TestSetSecurityHeaders_Overrides(t *testing.T) {
+	app := newSecurityHeadersTestApp(t)
+	app.config.HSTSMaxAge = 0
+	app.config.FrameOptions = "SAMEORIGIN"
+	app.config.ContentSecurityPolicy = "default-src 'self'"
+
+	rr := httptest.NewRecorder()
+	app.Routes().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Empty(t, rr.Header().Get("Strict-Transport-Security"))
+	assert.Equal(t, "SAMEORIGIN", rr.Header().Get("X-Frame-Options"))
+	assert.Equal(t, "default-src 'self'", rr.Header().Get("Content-Security-Policy"))
+	assert.NotContains(t, rr.Body.String(), "nonce=")
+
+	app.config.SecurityHeaders = false
+	rr = httptest.NewRecorder()
+	app.Routes().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Empty(t, rr.Header().Get("X-Content-Type-Options"))
+	assert.Empty(t, rr.Header().Get("Content-Security-Policy"))
+}
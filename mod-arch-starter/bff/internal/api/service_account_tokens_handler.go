@@ -0,0 +1,76 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/audit"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/validation"
+)
+
+type ServiceAccountTokenEnvelope Envelope[*models.ServiceAccountTokenModel, None]
+type ServiceAccountTokenRequestEnvelope Envelope[*models.ServiceAccountTokenRequest, None]
+
+// CreateServiceAccountTokenHandler mints a token of the ServiceAccount named in the path, in
+// the namespace of AttachNamespace, valid for the requested time up to
+// -service-account-token-max-ttl and scoped to one or more audiences of
+// -service-account-token-audiences.
+// The response is never cached nor replayed, and the audit entry of the request records the
+// service account, audiences and expiration of the token.
+func (app *App) CreateServiceAccountTokenHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	var body ServiceAccountTokenRequestEnvelope
+	if err := app.ReadValidJSON(w, r, &body); err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	if body.Data == nil {
+		app.apiErrorResponse(w, r, validation.Invalid("data", "is required"))
+		return
+	}
+	maxTTL := app.config.ServiceAccountTokenMaxTTL
+	ttl := time.Duration(body.Data.ExpirationSeconds) * time.Second
+	switch {
+	case ttl == 0:
+		ttl = maxTTL
+	case ttl < config.MinServiceAccountTokenTTL:
+		app.apiErrorResponse(w, r, validation.Invalid("data.expirationSeconds", fmt.Sprintf("must be at least %d", int64(config.MinServiceAccountTokenTTL/time.Second))))
+		return
+	case ttl > maxTTL:
+		app.apiErrorResponse(w, r, validation.Invalid("data.expirationSeconds", fmt.Sprintf("must be at most %d", int64(maxTTL/time.Second))))
+		return
+	}
+	// Tokens without audiences would be valid at the API server
+	if len(body.Data.Audiences) == 0 {
+		app.apiErrorResponse(w, r, validation.Invalid("data.audiences", "is required"))
+		return
+	}
+	for i, audience := range body.Data.Audiences {
+		if !slices.Contains(app.config.ServiceAccountTokenAudiences, audience) {
+			app.apiErrorResponse(w, r, validation.Invalid(fmt.Sprintf("data.audiences[%d]", i), "is not an allowed audience"))
+			return
+		}
+	}
+	identity, namespace, client, ok := app.namespacedRequest(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	token, err := app.repositories.ServiceAccount.Mint(client, r.Context(), identity, namespace, ps.ByName("name"), body.Data.Audiences, ttl)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+	audit.Annotate(r.Context(), "serviceAccount", token.ServiceAccount)
+	audit.Annotate(r.Context(), "audiences", strings.Join(token.Audiences, ","))
+	audit.Annotate(r.Context(), "expiration", token.ExpirationTimestamp.Format(time.RFC3339))
+	if err := app.WriteJSON(w, http.StatusCreated, ServiceAccountTokenEnvelope{Data: &token}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/audit"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateServiceAccountTokenHandler(t *testing.T) {
+	app := newWatchTestApp(t)
+	var mu sync.Mutex
+	var entries []audit.Entry
+	app.auditor = audit.New(audit.Options{Sinks: []audit.Sink{audit.SinkFunc(func(_ context.Context, entry audit.Entry) error {
+		mu.Lock()
+		defer mu.Unlock()
+		entries = append(entries, entry)
+		return nil
+	})}, Logger: app.logger})
+	app.config.IdempotencyKeyTTL, app.config.IdempotencyMaxEntries = time.Hour, 10
+	var err error
+	app.idempotency, err = app.newIdempotency()
+	require.NoError(t, err)
+	post := func(namespace, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, ApiPathPrefix+"/serviceaccounts/default/token?namespace="+namespace, strings.NewReader(body))
+		req.Header.Set(constants.KubeflowUserIDHeader, "doraNonAdmin@example.com")
+		req.Header.Set(constants.IdempotencyKeyHeader, "mint-"+body)
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		app.Routes().ServeHTTP(rr, req)
+		return rr
+	}
+	assert.Equal(t, http.StatusNotFound, post("dora-namespace", `{"data": {}}`).Code, "disabled by default")
+
+	app.config.ServiceAccountTokens = true
+	app.config.ServiceAccountTokenMaxTTL = time.Hour
+	app.config.ServiceAccountTokenAudiences = []string{"model-registry"}
+
+	before := time.Now()
+	rr := post("dora-namespace", `{"data": {"audiences": ["model-registry"]}}`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+	var minted ServiceAccountTokenEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &minted))
+	assert.Equal(t, "default", minted.Data.ServiceAccount)
+	assert.NotEmpty(t, minted.Data.Token)
+	assert.WithinDuration(t, before.Add(time.Hour), minted.Data.ExpirationTimestamp, 5*time.Second, "the ceiling by default")
+	rr = post("dora-namespace", `{"data": {"audiences": ["model-registry"]}}`)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	assert.Empty(t, rr.Header().Get(constants.IdempotentReplayedHeader), "tokens are not stored for replay")
+
+	rr = post("dora-namespace", `{"data": {"expirationSeconds": 7200}}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "must be at most 3600")
+	for _, expiration := range []string{"-60", "599"} {
+		rr = post("dora-namespace", `{"data": {"audiences": ["model-registry"], "expirationSeconds": `+expiration+`}}`)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, expiration)
+		assert.Contains(t, rr.Body.String(), "must be at least 600", expiration)
+	}
+	rr = post("dora-namespace", `{"data": {}}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "tokens are never for the API server")
+	assert.Contains(t, rr.Body.String(), "data.audiences")
+	rr = post("dora-namespace", `{"data": {"audiences": ["https://kubernetes.default.svc"]}}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "data.audiences[0]")
+	assert.Equal(t, http.StatusForbidden, post("bella-namespace", `{"data": {"audiences": ["model-registry"]}}`).Code)
+
+	require.NoError(t, app.auditor.Close(context.Background()))
+	var issued *audit.Entry
+	for i := range entries {
+		if entries[i].Status == http.StatusCreated {
+			issued = &entries[i]
+		}
+	}
+	require.NotNil(t, issued)
+	assert.Equal(t, map[string]string{
+		"serviceAccount": "default",
+		"audiences":      "model-registry",
+		"expiration":     minted.Data.ExpirationTimestamp.Format(time.RFC3339),
+	}, issued.Annotations)
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/pagination"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/repositories"
+)
+
+type ServicesEnvelope PagedResponse[models.ServiceModel]
+
+var serviceFields = pagination.Fields[models.ServiceModel]{
+	"name":        func(svc models.ServiceModel) string { return svc.Name },
+	"displayName": func(svc models.ServiceModel) string { return svc.DisplayName },
+	"status":      func(svc models.ServiceModel) string { return svc.Health.Status },
+}
+
+// GetServicesHandler lists the backend Services of the namespace (AttachNamespace) matching
+// SERVICE_LABEL_SELECTOR and SERVICE_ANNOTATION_SELECTOR. The optional labelSelector query
+// parameter replaces the configured label selector; the list parameters of
+// pagination.ParseListOptions page the result.
+func (app *App) GetServicesHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+
+	ctx := r.Context()
+	identity, ok := ctx.Value(constants.RequestIdentityKey).(*kubernetes.RequestIdentity)
+	if !ok || identity == nil {
+		app.badRequestResponse(w, r, fmt.Errorf("missing RequestIdentity in context"))
+		return
+	}
+	namespace := requestNamespace(r)
+
+	labelSelector := r.URL.Query().Get("labelSelector")
+	if labelSelector == "" {
+		labelSelector = app.serviceLabelSelector()
+	}
+	selector, err := repositories.ParseServiceSelector(labelSelector, app.config.ServiceAnnotationSelector)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	opts, err := pagination.ParseListOptions(r.URL.Query())
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	client, err := app.kubernetesClientFactory.GetClient(ctx)
+	if err != nil {
+		app.serverErrorResponse(w, r, fmt.Errorf("failed to get Kubernetes client: %w", err))
+		return
+	}
+
+	services, err := app.repositories.Service.GetServices(client, ctx, identity, namespace, selector)
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+
+	page, metadata, err := pagination.Apply(services, opts, serviceFields)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	servicesEnvelope := ServicesEnvelope{
+		Data:     page,
+		Metadata: metadata,
+	}
+
+	err = app.WriteJSON(w, http.StatusOK, servicesEnvelope, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *App) serviceLabelSelector() string {
+	if app.config.ServiceLabelSelector == "" {
+		return config.DefaultServiceLabelSelector
+	}
+	return app.config.ServiceLabelSelector
+}
+
+// serviceURLResolvers maps the -service-url-sources of cfg to their resolvers, in order, for
+// ServiceRepository.UseURLResolvers.
+func serviceURLResolvers(cfg config.EnvConfig) []repositories.ServiceURLResolver {
+	resolvers := make([]repositories.ServiceURLResolver, 0, len(cfg.ServiceURLSources))
+	for _, source := range cfg.ServiceURLSources {
+		switch source {
+		case config.ServiceURLSourceRoute:
+			resolvers = append(resolvers, repositories.RouteURLs)
+		case config.ServiceURLSourceIngress:
+			resolvers = append(resolvers, repositories.IngressURLs)
+		case config.ServiceURLSourceHTTPRoute:
+			resolvers = append(resolvers, repositories.HTTPRouteURLs)
+		}
+	}
+	return resolvers
+}
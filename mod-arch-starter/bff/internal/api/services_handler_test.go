@@ -0,0 +1,52 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetServicesHandler(t *testing.T) {
+	app := newWatchTestApp(t)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, ServicesPath+query, nil)
+		req.Header.Set(constants.KubeflowUserIDHeader, "user@example.com")
+		rr := httptest.NewRecorder()
+		app.Routes().ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get("?namespace=kubeflow")
+	require.Equal(t, http.StatusOK, rr.Code)
+	var envelope ServicesEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &envelope))
+	names := []string{}
+	for _, svc := range envelope.Data {
+		names = append(names, svc.Name)
+	}
+	assert.Equal(t, []string{"mod-arch", "mod-arch-one"}, names)
+	assert.Equal(t, 2, *envelope.Metadata.TotalCount)
+
+	rr = get("?namespace=kubeflow&orderBy=-name&pageSize=1")
+	require.Equal(t, http.StatusOK, rr.Code)
+	envelope = ServicesEnvelope{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &envelope))
+	require.Len(t, envelope.Data, 1)
+	assert.Equal(t, "mod-arch-one", envelope.Data[0].Name)
+	assert.Equal(t, "2", envelope.Metadata.NextPageToken)
+
+	rr = get("?namespace=kubeflow&labelSelector=" + url.QueryEscape("!component"))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"non-mod-arch"`)
+
+	assert.Equal(t, http.StatusBadRequest, get("").Code, "missing namespace")
+	assert.Equal(t, http.StatusBadRequest, get("?namespace=kubeflow&labelSelector="+url.QueryEscape("a in (")).Code, "invalid selector")
+	assert.Equal(t, http.StatusBadRequest, get("?namespace=kubeflow&orderBy=port").Code, "unknown field")
+}
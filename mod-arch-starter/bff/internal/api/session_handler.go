@@ -0,0 +1,136 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	logging "github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/session"
+)
+
+type LogoutEnvelope Envelope[models.Logout, None]
+
+// newSessions returns the session manager of -session-enabled, nil when it is not set.
+func (app *App) newSessions() (*session.Manager, error) {
+	if !app.config.SessionEnabled {
+		return nil, nil
+	}
+	sealer, err := session.NewSealer(app.config.SessionSecret)
+	if err != nil {
+		return nil, err
+	}
+	cookie := session.CookieOptions{
+		Name:     app.config.SessionCookieName,
+		Path:     "/",
+		Secure:   !app.config.DevMode,
+		SameSite: http.SameSiteLaxMode,
+	}
+	timeouts := session.Timeouts{Idle: app.config.SessionIdleTimeout, Absolute: app.config.SessionAbsoluteTimeout}
+	var store session.Store = session.NewCookieStore(cookie, sealer)
+	if app.config.SessionStore == session.StoreMemory {
+		store = session.NewMemoryStore(cookie, timeouts)
+	}
+	// The login cookie is sent back by the redirect of the provider, a top-level navigation
+	login := cookie
+	login.Name += "_login"
+
+	// The provider sends the browser back to the origin of the BFF once signed out
+	redirect, err := url.Parse(app.config.OAuthRedirectURL)
+	if err != nil {
+		return nil, err
+	}
+	provider := session.NewOAuthClient(session.OAuthConfig{
+		IssuerURL:             app.config.OIDCIssuerURL,
+		ClientID:              app.config.OIDCClientID,
+		ClientSecret:          app.config.OAuthClientSecret,
+		RedirectURL:           app.config.OAuthRedirectURL,
+		Scopes:                app.config.OAuthScopes,
+		PostLogoutRedirectURL: (&url.URL{Scheme: redirect.Scheme, Host: redirect.Host, Path: "/"}).String(),
+		HTTPClient: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: app.upstreamTLSConfig(UpstreamOIDC)},
+		},
+	})
+
+	app.logger.Info("signing users in with sessions", "issuer", app.config.OIDCIssuerURL, "store", app.config.SessionStore)
+	return session.NewManager(session.Config{
+		Store:         store,
+		Provider:      provider,
+		Timeouts:      timeouts,
+		RefreshBefore: app.config.SessionRefreshBefore,
+		Login:         login,
+		Sealer:        sealer,
+	}, logging.ForPackage(app.logger, "session")), nil
+}
+
+// LoadSession loads the session of API requests, refreshing its tokens when they are about to
+// expire, for SessionIdentityExtractor. It does nothing unless -session-enabled is set.
+func (app *App) LoadSession(next http.Handler) http.Handler {
+	if app.sessions == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !app.requiresAuth(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		s, err := app.sessions.Load(w, r)
+		if err != nil && !errors.Is(err, session.ErrNoSession) && !errors.Is(err, session.ErrExpired) {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(session.NewContext(r.Context(), s, err)))
+	})
+}
+
+// LoginHandler starts a sign-in: it redirects the browser to the OIDC provider, which sends it
+// back to LoginCallbackHandler. The returnTo query parameter is the path the browser lands on
+// once signed in (default "/").
+func (app *App) LoginHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	authURL, err := app.sessions.BeginLogin(w, r, r.URL.Query().Get("returnTo"))
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// LoginCallbackHandler completes a sign-in: it exchanges the authorization code of the provider
+// for tokens, starts their session and redirects the browser to the returnTo path of the
+// sign-in.
+func (app *App) LoginCallbackHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	returnTo, err := app.sessions.CompleteLogin(w, r)
+	if errors.Is(err, session.ErrLoginFailed) {
+		app.unauthorizedResponse(w, r, err)
+		return
+	}
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	http.Redirect(w, r, returnTo, http.StatusFound)
+}
+
+// LogoutHandler ends the session of the request: its tokens are revoked at the provider and
+// its cookie cleared. The response carries the URL ending the session at the provider too,
+// when it supports it.
+func (app *App) LogoutHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	ended, err := app.sessions.End(w, r)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	var logout models.Logout
+	if ended != nil {
+		if logout.EndSessionURL, err = app.sessions.Provider().EndSessionURL(r.Context(), ended.Tokens.IDToken); err != nil {
+			app.requestLogger(r).Warn("failed to build the end session URL of the provider", "error", err)
+		}
+	}
+	if err := app.WriteJSON(w, http.StatusOK, LogoutEnvelope{Data: logout}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/oidc"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/session"
+)
+
+// newSessionTestApp returns an App signing users in at a test provider, which returns the
+// tokens of the code "code" and records the tokens revoked.
+func newSessionTestApp(t *testing.T) (*App, *[]string) {
+	t.Helper()
+	var mu sync.Mutex
+	revoked := []string{}
+	var idp *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(oidc.ProviderMetadata{
+			Issuer:                idp.URL,
+			AuthorizationEndpoint: idp.URL + "/auth",
+			TokenEndpoint:         idp.URL + "/token",
+			RevocationEndpoint:    idp.URL + "/revoke",
+			EndSessionEndpoint:    idp.URL + "/logout",
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.PostFormValue("code") != "code" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"access","refresh_token":"refresh","id_token":"id","expires_in":3600}`))
+	})
+	mux.HandleFunc("/revoke", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		revoked = append(revoked, r.PostFormValue("token"))
+		mu.Unlock()
+	})
+	idp = httptest.NewServer(mux)
+	t.Cleanup(idp.Close)
+
+	app := newWatchTestApp(t)
+	app.config.OIDCIssuerURL = idp.URL
+	app.config.OIDCClientID = "bff"
+	app.config.SessionEnabled = true
+	app.config.SessionStore = session.StoreCookie
+	app.config.SessionSecret = "a test secret of at least 32 bytes"
+	app.config.SessionCookieName = config.DefaultSessionCookieName
+	app.config.SessionIdleTimeout = config.DefaultSessionIdleTimeout
+	app.config.SessionAbsoluteTimeout = config.DefaultSessionAbsoluteTimeout
+	app.config.SessionRefreshBefore = config.DefaultSessionRefreshBefore
+	app.config.OAuthRedirectURL = "https://bff.example.com" + LoginCallbackPath
+	app.config.OAuthScopes = config.DefaultOAuthScopes
+	var err error
+	app.sessions, err = app.newSessions()
+	require.NoError(t, err)
+	return app, &revoked
+}
+
+func TestSessionHandlers(t *testing.T) {
+	app, revoked := newSessionTestApp(t)
+	routes := app.Routes()
+	cookies := map[string]*http.Cookie{}
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, req)
+		for _, c := range rr.Result().Cookies() {
+			if c.MaxAge < 0 {
+				delete(cookies, c.Name)
+			} else {
+				cookies[c.Name] = c
+			}
+		}
+		return rr
+	}
+
+	rr := serve(http.MethodGet, LoginPath+"?returnTo=/models")
+	require.Equal(t, http.StatusFound, rr.Code)
+	authURL, err := url.Parse(rr.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "/auth", authURL.Path)
+	assert.Equal(t, "https://bff.example.com"+LoginCallbackPath, authURL.Query().Get("redirect_uri"))
+
+	rr = serve(http.MethodGet, LoginCallbackPath+"?code=code&state=forged")
+	assert.Equal(t, http.StatusUnauthorized, rr.Code, "a forged callback")
+
+	serve(http.MethodGet, LoginPath+"?returnTo=/models")
+	rr = serve(http.MethodGet, LoginCallbackPath+"?code=code")
+	assert.Equal(t, http.StatusUnauthorized, rr.Code, "a callback without state")
+
+	rr = serve(http.MethodGet, LoginPath+"?returnTo=/models")
+	authURL, _ = url.Parse(rr.Header().Get("Location"))
+	rr = serve(http.MethodGet, LoginCallbackPath+"?code=code&state="+authURL.Query().Get("state"))
+	require.Equal(t, http.StatusFound, rr.Code)
+	assert.Equal(t, "/models", rr.Header().Get("Location"))
+	require.Contains(t, cookies, config.DefaultSessionCookieName)
+	assert.True(t, cookies[config.DefaultSessionCookieName].HttpOnly)
+
+	rr = serve(http.MethodPost, LogoutPath)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var logout LogoutEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &logout))
+	endURL, err := url.Parse(logout.Data.EndSessionURL)
+	require.NoError(t, err)
+	assert.Equal(t, "/logout", endURL.Path)
+	assert.Equal(t, "id", endURL.Query().Get("id_token_hint"))
+	assert.Equal(t, "https://bff.example.com/", endURL.Query().Get("post_logout_redirect_uri"))
+	assert.Equal(t, []string{"refresh", "access"}, *revoked)
+	assert.NotContains(t, cookies, config.DefaultSessionCookieName, "the session cookie is cleared")
+
+	rr = serve(http.MethodPost, LogoutPath)
+	assert.Equal(t, http.StatusOK, rr.Code, "signing out without a session")
+}
+
+func TestSessionHandlers_LogoutIsCSRFProtected(t *testing.T) {
+	app, revoked := newSessionTestApp(t)
+	app.config.CSRFEnabled = true
+	app.config.CSRFCookieName = config.DefaultCSRFCookieName
+	app.config.CSRFHeader = config.DefaultCSRFHeader
+
+	req := httptest.NewRequest(http.MethodPost, LogoutPath, nil)
+	req.AddCookie(&http.Cookie{Name: config.DefaultSessionCookieName, Value: "session"})
+	rr := httptest.NewRecorder()
+	app.Routes().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Empty(t, *revoked)
+}
+
+func TestSessionIdentityExtractor(t *testing.T) {
+	var token string
+	extractor := SessionIdentityExtractor{Next: IdentityExtractorFunc(func(r *http.Request) (*k8s.RequestIdentity, error) {
+		token = r.Header.Get("Authorization")
+		return &k8s.RequestIdentity{UserID: "alice"}, nil
+	})}
+	request := func(s *session.Session, err error) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, UserPath, nil)
+		return req.WithContext(session.NewContext(context.Background(), s, err))
+	}
+
+	identity, err := extractor.ExtractIdentity(request(&session.Session{Tokens: session.Tokens{AccessToken: "access", IDToken: "id", Expiry: time.Now().Add(time.Hour)}}, nil))
+	require.NoError(t, err)
+	assert.Equal(t, "alice", identity.UserID)
+	assert.Equal(t, "Bearer id", token, "the ID token is forwarded")
+
+	_, err = extractor.ExtractIdentity(request(nil, session.ErrNoSession))
+	assert.ErrorIs(t, err, ErrIdentityNotFound, "requests without a session try the next extractors")
+
+	_, err = extractor.ExtractIdentity(request(nil, session.ErrExpired))
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+}
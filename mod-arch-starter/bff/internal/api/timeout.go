@@ -0,0 +1,178 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/apierrors"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/metrics"
+)
+
+// streamingRoutes stream their responses for as long as the client listens, so
+// -request-timeout does not apply to them; -route-timeouts still may.
+var streamingRoutes = map[string]bool{
+	http.MethodGet + " " + WatchPath:         true,
+	http.MethodGet + " " + PodLogsPath:       true,
+	http.MethodGet + " " + NotificationsPath: true,
+	// Transfers of large objects
+	http.MethodGet + " " + ObjectPath:   true,
+	http.MethodPut + " " + ObjectPath:   true,
+	http.MethodPost + " " + ObjectsPath: true,
+}
+
+// EnforceTimeouts bounds each API request by -request-timeout, or the -route-timeouts entry of
+// its route (route names the matched route). The deadline is set on the request context, so
+// the repositories and Kubernetes calls given r.Context() are canceled with it. A request
+// still running when it expires gets a 504 envelope with its request ID, unless its response
+// has started; later writes of the handler fail with http.ErrHandlerTimeout.
+//
+// Streams are not bounded: the streaming routes, WebSocket upgrades and requests accepting
+// Server-Sent Events.
+func (app *App) EnforceTimeouts(route metrics.RouteFunc, next http.Handler) http.Handler {
+	// Validated with the configuration
+	overrides, _ := config.ParseRouteTimeouts(app.config.RouteTimeouts)
+	if app.config.RequestTimeout <= 0 && len(overrides) == 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pattern := route(r)
+		timeout := app.routeTimeout(r, pattern, overrides)
+		if timeout <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, cancel := context.WithTimeoutCause(r.Context(), timeout, apierrors.ErrRequestTimeout)
+		defer cancel()
+		r = r.WithContext(ctx)
+
+		tw := &timeoutWriter{ResponseWriter: w, header: w.Header().Clone()}
+		stop := context.AfterFunc(ctx, func() {
+			if context.Cause(ctx) != apierrors.ErrRequestTimeout {
+				// The client went away
+				return
+			}
+			tw.timeout(func(w http.ResponseWriter) {
+				app.apiErrorResponse(w, r, fmt.Errorf("%s %s exceeded its %s timeout: %w", r.Method, pattern, timeout, apierrors.ErrRequestTimeout))
+			})
+		})
+		next.ServeHTTP(tw, r)
+		stop()
+		tw.finish()
+	})
+}
+
+// routeTimeout returns the timeout of a request of the route pattern, 0 for none.
+func (app *App) routeTimeout(r *http.Request, pattern string, overrides map[string]time.Duration) time.Duration {
+	if pattern == "" {
+		// The frontend, and unknown paths answered right away
+		return 0
+	}
+	if timeout, ok := overrides[r.Method+" "+pattern]; ok {
+		return timeout
+	}
+	if timeout, ok := overrides[pattern]; ok {
+		return timeout
+	}
+	if streamingRoutes[r.Method+" "+pattern] || r.Header.Get("Upgrade") != "" || acceptsEventStream(r) {
+		return 0
+	}
+	return app.config.RequestTimeout
+}
+
+// timeoutWriter serializes the writes of the handler with the 504 written when the request
+// times out. The handler gets its own header map, copied to the response when it starts, so
+// a timeout never races with the handler setting headers.
+type timeoutWriter struct {
+	http.ResponseWriter
+
+	mu          sync.Mutex
+	header      http.Header
+	wroteHeader bool
+	timedOut    bool
+	done        bool
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writeHeader(code)
+}
+
+func (w *timeoutWriter) writeHeader(code int) {
+	if w.timedOut || w.wroteHeader {
+		return
+	}
+	w.copyHeader()
+	if code >= http.StatusOK {
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	w.writeHeader(http.StatusOK)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *timeoutWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return
+	}
+	w.writeHeader(http.StatusOK)
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *timeoutWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// timeout writes the response of a timed out request with respond, unless the handler already
+// returned or started its response. The response is flushed, though it only completes once
+// the handler returns, which the canceled context makes prompt.
+func (w *timeoutWriter) timeout(respond func(w http.ResponseWriter)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.done || w.wroteHeader {
+		return
+	}
+	w.timedOut = true
+	respond(w.ResponseWriter)
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// finish stops the writer once the handler returned, before the response is complete.
+func (w *timeoutWriter) finish() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.done = true
+	if !w.wroteHeader && !w.timedOut {
+		// Nothing written: the server sends a 200 with the headers set by the handler
+		w.copyHeader()
+	}
+}
+
+func (w *timeoutWriter) copyHeader() {
+	header := w.ResponseWriter.Header()
+	clear(header)
+	for key, values := range w.header {
+		header[key] = values
+	}
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const slowPath = ApiPathPrefix + "/slow"
+
+func newTimeoutTestApp(t *testing.T, timeout time.Duration, routeTimeouts ...string) *App {
+	app := newWatchTestApp(t)
+	app.config.RequestTimeout = timeout
+	app.config.RouteTimeouts = routeTimeouts
+	return app
+}
+
+// serveSlow serves req through EnforceTimeouts with handler, as the route pattern.
+func serveSlow(app *App, pattern string, req *http.Request, handler http.HandlerFunc) *httptest.ResponseRecorder {
+	route := func(*http.Request) string { return pattern }
+	req = req.WithContext(context.WithValue(req.Context(), constants.RequestIdKey, "req-1"))
+	rr := httptest.NewRecorder()
+	app.EnforceTimeouts(route, handler).ServeHTTP(rr, req)
+	return rr
+}
+
+func TestEnforceTimeouts(t *testing.T) {
+	app := newTimeoutTestApp(t, 50*time.Millisecond)
+
+	var deadline time.Time
+	start := time.Now()
+	rr := serveSlow(app, slowPath, httptest.NewRequest(http.MethodGet, slowPath, nil), func(w http.ResponseWriter, r *http.Request) {
+		deadline, _ = r.Context().Deadline()
+		<-r.Context().Done()
+		app.serverErrorResponse(w, r, fmt.Errorf("failed to list services: %w", r.Context().Err()))
+	})
+
+	assert.WithinDuration(t, start.Add(50*time.Millisecond), deadline, 40*time.Millisecond)
+	require.Equal(t, http.StatusGatewayTimeout, rr.Code)
+	var envelope HTTPError
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &envelope))
+	assert.Equal(t, "504", envelope.Error.Code)
+	assert.Equal(t, "req-1", envelope.Error.RequestID)
+	assert.Equal(t, "Timeout", envelope.Error.Details["reason"])
+}
+
+func TestEnforceTimeouts_HandlerIgnoringDeadline(t *testing.T) {
+	app := newTimeoutTestApp(t, 20*time.Millisecond)
+
+	var writeErr error
+	rr := serveSlow(app, slowPath, httptest.NewRequest(http.MethodGet, slowPath, nil), func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.Header().Set("ETag", `"late"`)
+		_, writeErr = io.WriteString(w, `{"data":"late"}`)
+	})
+
+	assert.Equal(t, http.StatusGatewayTimeout, rr.Code)
+	assert.ErrorIs(t, writeErr, http.ErrHandlerTimeout)
+	assert.Empty(t, rr.Header().Get("ETag"))
+	assert.NotContains(t, rr.Body.String(), "late")
+}
+
+func TestEnforceTimeouts_ResponseStarted(t *testing.T) {
+	app := newTimeoutTestApp(t, 20*time.Millisecond)
+
+	rr := serveSlow(app, slowPath, httptest.NewRequest(http.MethodGet, slowPath, nil), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = io.WriteString(w, "partial")
+		<-r.Context().Done()
+	})
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/plain", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "partial", rr.Body.String())
+}
+
+func TestEnforceTimeouts_Routes(t *testing.T) {
+	tests := []struct {
+		name          string
+		routeTimeouts []string
+		pattern       string
+		header        http.Header
+		want          time.Duration
+	}{
+		{"default", nil, slowPath, nil, time.Minute},
+		{"method override", []string{"GET " + slowPath + "=2m", slowPath + "=3m"}, slowPath, nil, 2 * time.Minute},
+		{"route override", []string{"POST " + slowPath + "=2m", slowPath + "=3m"}, slowPath, nil, 3 * time.Minute},
+		{"disabled", []string{slowPath + "=0"}, slowPath, nil, 0},
+		{"frontend", nil, "", nil, 0},
+		{"watch", nil, WatchPath, nil, 0},
+		{"bounded watch", []string{WatchPath + "=1h"}, WatchPath, nil, time.Hour},
+		{"event stream", nil, slowPath, http.Header{"Accept": {"text/event-stream"}}, 0},
+		{"websocket", nil, slowPath, http.Header{"Upgrade": {"websocket"}, "Connection": {"Upgrade"}}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTimeoutTestApp(t, time.Minute, tt.routeTimeouts...)
+			req := httptest.NewRequest(http.MethodGet, slowPath, nil)
+			for key, values := range tt.header {
+				req.Header[key] = values
+			}
+
+			var got time.Duration
+			start := time.Now()
+			rr := serveSlow(app, tt.pattern, req, func(w http.ResponseWriter, r *http.Request) {
+				if deadline, ok := r.Context().Deadline(); ok {
+					got = deadline.Sub(start)
+				}
+				w.WriteHeader(http.StatusNoContent)
+			})
+			assert.Equal(t, http.StatusNoContent, rr.Code)
+			assert.InDelta(t, tt.want, got, float64(time.Second))
+		})
+	}
+}
+
+func TestEnforceTimeouts_Disabled(t *testing.T) {
+	app := newTimeoutTestApp(t, 0)
+	rr := serveSlow(app, slowPath, httptest.NewRequest(http.MethodGet, slowPath, nil), func(w http.ResponseWriter, r *http.Request) {
+		_, ok := r.Context().Deadline()
+		assert.False(t, ok)
+		w.Header().Set("X-Handled", "true")
+	})
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "true", rr.Header().Get("X-Handled"))
+
+	// Headers of a handler writing nothing are kept when a timeout applies too
+	app = newTimeoutTestApp(t, time.Minute)
+	rr = serveSlow(app, slowPath, httptest.NewRequest(http.MethodGet, slowPath, nil), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Handled", "true")
+	})
+	assert.Equal(t, "true", rr.Header().Get("X-Handled"))
+}
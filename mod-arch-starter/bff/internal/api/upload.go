@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/notifications"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/upload"
+)
+
+// NotificationUploadProgress is the type of the notifications of the progress of uploads.
+const NotificationUploadProgress = "upload.progress"
+
+// UploadProgress is the Data of the upload.progress notifications. RequestID is the
+// X-Request-ID of the upload: the UI sets it on the request to recognize its notifications.
+type UploadProgress struct {
+	RequestID string `json:"requestId"`
+	upload.Progress
+}
+
+// ReceiveUpload receives the files of the multipart/form-data request r, streaming each one to
+// dest without buffering the body (see upload.Receive), and publishes the progress to the
+// requesting user as transient upload.progress notifications, besides calling
+// opts.OnProgress. The handler answers its errors with apiErrorResponse. The write deadline of
+// the server is lifted, so the handler can answer once the destination has stored the files.
+func (app *App) ReceiveUpload(w http.ResponseWriter, r *http.Request, opts upload.Options, dest upload.Destination) (*upload.Result, error) {
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	identity, _ := r.Context().Value(constants.RequestIdentityKey).(*kubernetes.RequestIdentity)
+	if identity != nil && identity.UserID != "" {
+		requestID, _ := r.Context().Value(constants.RequestIdKey).(string)
+		onProgress := opts.OnProgress
+		opts.OnProgress = func(progress upload.Progress) {
+			if onProgress != nil {
+				onProgress(progress)
+			}
+			title := "Uploading " + progress.Filename
+			if progress.Done {
+				title = "Upload received"
+			}
+			app.notifications.Publish(notifications.Notification{
+				Type:      NotificationUploadProgress,
+				Severity:  notifications.SeverityInfo,
+				Title:     title,
+				Data:      UploadProgress{RequestID: requestID, Progress: progress},
+				Users:     []string{identity.UserID},
+				Transient: true,
+			})
+		}
+	}
+	return upload.Receive(w, r, opts, dest)
+}
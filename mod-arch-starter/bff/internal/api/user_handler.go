@@ -12,7 +12,14 @@ import (
 
 type UserEnvelope Envelope[*models.User, None]
 
+// UserHandler returns the current user. With namespaceRoles=true the user's rules in every
+// namespace they can access are listed too, sparing the frontend an access review per page.
 func (app *App) UserHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	withRoles, err := boolQuery(r, "namespaceRoles")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
 
 	ctx := r.Context()
 	identity, ok := ctx.Value(constants.RequestIdentityKey).(*kubernetes.RequestIdentity)
@@ -21,17 +28,23 @@ func (app *App) UserHandler(w http.ResponseWriter, r *http.Request, _ httprouter
 		return
 	}
 
-	client, err := app.kubernetesClientFactory.GetClient(r.Context())
+	client, err := app.kubernetesClientFactory.GetClient(ctx)
 	if err != nil {
 		app.serverErrorResponse(w, r, fmt.Errorf("failed to get Kubernetes client: %w", err))
 		return
 	}
 
-	user, err := app.repositories.User.GetUser(client, identity)
+	user, err := app.repositories.User.GetUser(client, ctx, identity)
 	if err != nil {
-		app.serverErrorResponse(w, r, err)
+		app.apiErrorResponse(w, r, err)
 		return
 	}
+	if withRoles {
+		if user.NamespaceRoles, err = app.repositories.User.GetNamespaceRoles(client, ctx, identity); err != nil {
+			app.apiErrorResponse(w, r, err)
+			return
+		}
+	}
 
 	userRes := UserEnvelope{
 		Data: user,
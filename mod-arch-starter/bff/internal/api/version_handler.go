@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+)
+
+// The build of the BFF, set at build time with -ldflags, e.g.
+//
+//	go build -ldflags "-X github.com/opendatahub-io/mod-arch-library/bff/internal/api.GitCommit=$(git rev-parse HEAD)" ./cmd
+//
+// GitCommit defaults to the commit Go embeds when building from a git checkout, whose tree state
+// is then reported too.
+var (
+	Version   = "1.0.0"
+	GitCommit = ""
+	BuildDate = ""
+)
+
+type VersionEnvelope Envelope[models.VersionInfo, None]
+
+// VersionHandler serves the version, git commit, build date and Go runtime of the BFF.
+func (app *App) VersionHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	if err := app.WriteJSON(w, http.StatusOK, VersionEnvelope{Data: versionInfo()}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func versionInfo() models.VersionInfo {
+	info := models.VersionInfo{
+		Version:   Version,
+		GitCommit: GitCommit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		Compiler:  runtime.Compiler,
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	build, ok := debug.ReadBuildInfo()
+	if !ok || info.GitCommit != "" {
+		return info
+	}
+	// Built from a git checkout without ldflags: use the VCS information Go embeds
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.GitCommit = setting.Value
+		case "vcs.modified":
+			info.GitTreeState = "clean"
+			if setting.Value == "true" {
+				info.GitTreeState = "dirty"
+			}
+		}
+	}
+	return info
+}
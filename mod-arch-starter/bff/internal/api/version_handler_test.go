@@ -0,0 +1,34 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionHandler(t *testing.T) {
+	commit, date := GitCommit, BuildDate
+	t.Cleanup(func() { GitCommit, BuildDate = commit, date })
+	GitCommit, BuildDate = "0123abc", "2025-06-01T12:00:00Z"
+
+	req := httptest.NewRequest(http.MethodGet, VersionPath, nil)
+	req.Header.Set(constants.KubeflowUserIDHeader, "user@example.com")
+	rr := httptest.NewRecorder()
+	newWatchTestApp(t).Routes().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var envelope VersionEnvelope
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &envelope))
+	assert.Equal(t, Version, envelope.Data.Version)
+	assert.Equal(t, "0123abc", envelope.Data.GitCommit)
+	assert.Empty(t, envelope.Data.GitTreeState, "unknown for the commit set with ldflags")
+	assert.Equal(t, "2025-06-01T12:00:00Z", envelope.Data.BuildDate)
+	assert.Equal(t, runtime.Version(), envelope.Data.GoVersion)
+	assert.Equal(t, runtime.GOOS+"/"+runtime.GOARCH, envelope.Data.Platform)
+}
@@ -0,0 +1,196 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/metrics"
+)
+
+// APIVersionV1 is the version of the starter endpoints, mounted at ApiPathPrefix.
+const APIVersionV1 = "v1"
+
+// apiVersionName matches the API versions, e.g. v2 or v3beta1.
+var apiVersionName = regexp.MustCompile(`^v[1-9][0-9]*((alpha|beta)[1-9][0-9]*)?$`)
+
+// APIVersionPrefix returns the mount of an API version, e.g. "/api/v2" for "v2".
+func APIVersionPrefix(version string) string {
+	return "/api/" + version
+}
+
+// VersionedRoute is a route of a version of the API, added by downstream code with
+// RegisterVersionedRoute.
+type VersionedRoute struct {
+	// Version is the API version of the route, e.g. "v2"; APIVersionV1 adds a route to the
+	// starter API. Each version is mounted at APIVersionPrefix(Version), with the /api/v1
+	// middleware (authentication, rate limits, audit, ...).
+	Version string
+	// Method is the HTTP method, e.g. http.MethodGet.
+	Method string
+	// Path is relative to the version mount and uses the httprouter syntax, e.g. "/notebooks/:name".
+	Path string
+	// Handler builds the handler of the route once the App exists.
+	Handler func(app *App) httprouter.Handle
+	// Deprecation marks the route deprecated; nil for a supported route.
+	Deprecation *Deprecation
+	// Guests lets guests call the route in guest mode; set it on read-only routes only.
+	Guests bool
+}
+
+// Pattern returns the full path of the route, e.g. "/api/v2/notebooks/:name".
+func (r VersionedRoute) Pattern() string {
+	return APIVersionPrefix(r.Version) + r.Path
+}
+
+// Deprecation describes a deprecated route. Its responses carry the Deprecation (RFC 9745)
+// and, when set, Sunset (RFC 8594) headers and Link headers to the successor and the
+// documentation, and its OpenAPI operation is marked deprecated.
+type Deprecation struct {
+	// Since is when the route was deprecated (required).
+	Since time.Time
+	// Sunset is when the route will be removed; zero when not planned yet.
+	Sunset time.Time
+	// Successor is the path or URL of the route replacing it, e.g. "/api/v2/notebooks".
+	Successor string
+	// Documentation is the URL of the migration notes.
+	Documentation string
+}
+
+// header sets the deprecation headers of a response.
+func (d Deprecation) header(h http.Header) {
+	h.Set("Deprecation", "@"+strconv.FormatInt(d.Since.Unix(), 10))
+	if !d.Sunset.IsZero() {
+		h.Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if d.Successor != "" {
+		h.Add("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", d.Successor))
+	}
+	if d.Documentation != "" {
+		h.Add("Link", fmt.Sprintf("<%s>; rel=\"deprecation\"", d.Documentation))
+	}
+}
+
+func (d Deprecation) validate() error {
+	if d.Since.IsZero() {
+		return fmt.Errorf("the deprecation date is required")
+	}
+	if !d.Sunset.IsZero() && d.Sunset.Before(d.Since) {
+		return fmt.Errorf("the sunset date must not be before the deprecation date")
+	}
+	return nil
+}
+
+var (
+	versionedRouteMu sync.RWMutex
+	versionedRoutes  []VersionedRoute
+	deprecatedRoutes = map[string]Deprecation{}
+)
+
+// RegisterVersionedRoute adds a route to a version of the API, mounting the version if it is
+// new. This should be called from an init() function in the downstream code. A route kept for
+// the clients of a previous version is registered with a Deprecation, next to its successor.
+//
+// Example usage in downstream code:
+//
+//	func init() {
+//	    api.RegisterVersionedRoute(api.VersionedRoute{
+//	        Version: "v2",
+//	        Method:  http.MethodGet,
+//	        Path:    "/notebooks",
+//	        Handler: func(app *api.App) httprouter.Handle { return NotebooksV2Handler(app) },
+//	    })
+//	    api.RegisterVersionedRoute(api.VersionedRoute{
+//	        Version: api.APIVersionV1,
+//	        Method:  http.MethodGet,
+//	        Path:    "/notebooks",
+//	        Handler: func(app *api.App) httprouter.Handle { return NotebooksV1Handler(app) },
+//	        Deprecation: &api.Deprecation{
+//	            Since:     time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+//	            Sunset:    time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+//	            Successor: "/api/v2/notebooks",
+//	        },
+//	    })
+//	}
+func RegisterVersionedRoute(route VersionedRoute) { //nolint:unused
+	versionedRouteMu.Lock()
+	defer versionedRouteMu.Unlock()
+	versionedRoutes = append(versionedRoutes, route)
+}
+
+// DeprecateRoute marks a route already served by the BFF deprecated, e.g. a starter endpoint
+// (api.UserPath) or a route added to apiRouter by downstream code. path is the full route
+// pattern. This should be called from an init() function in the downstream code.
+func DeprecateRoute(method, path string, deprecation Deprecation) { //nolint:unused
+	versionedRouteMu.Lock()
+	defer versionedRouteMu.Unlock()
+	deprecatedRoutes[method+" "+path] = deprecation
+}
+
+// apiVersions returns the mounted API versions: v1 and the versions of the versioned routes.
+func (app *App) apiVersions() []string {
+	versions := []string{APIVersionV1}
+	for _, route := range app.versionedRoutes {
+		if !slices.Contains(versions, route.Version) {
+			versions = append(versions, route.Version)
+		}
+	}
+	return versions
+}
+
+// newVersionedRoutes validates the registered versioned routes and those of the modules, and
+// returns them with the deprecations of all routes, keyed by method and pattern.
+func (app *App) newVersionedRoutes() ([]VersionedRoute, map[string]Deprecation, error) {
+	versionedRouteMu.RLock()
+	routes := slices.Clone(versionedRoutes)
+	deprecations := make(map[string]Deprecation, len(deprecatedRoutes))
+	for key, deprecation := range deprecatedRoutes {
+		deprecations[key] = deprecation
+	}
+	versionedRouteMu.RUnlock()
+	routes = append(routes, app.moduleRoutes()...)
+
+	for key, deprecation := range deprecations {
+		if err := deprecation.validate(); err != nil {
+			return nil, nil, fmt.Errorf("deprecated route %s: %w", key, err)
+		}
+	}
+	for _, route := range routes {
+		switch {
+		case !apiVersionName.MatchString(route.Version):
+			return nil, nil, fmt.Errorf("versioned route %s %s: version %q must look like v2 or v3beta1", route.Method, route.Path, route.Version)
+		case !strings.HasPrefix(route.Path, "/"):
+			return nil, nil, fmt.Errorf("versioned route %s %s: the path must start with /", route.Method, route.Path)
+		case route.Method == "" || route.Handler == nil:
+			return nil, nil, fmt.Errorf("versioned route %s %s: the method and handler are required", route.Method, route.Path)
+		}
+		if route.Deprecation != nil {
+			if err := route.Deprecation.validate(); err != nil {
+				return nil, nil, fmt.Errorf("versioned route %s %s: %w", route.Method, route.Pattern(), err)
+			}
+			deprecations[route.Method+" "+route.Pattern()] = *route.Deprecation
+		}
+		app.logger.Info("registering versioned route", "method", route.Method, "path", route.Pattern(), "deprecated", route.Deprecation != nil)
+	}
+	return routes, deprecations, nil
+}
+
+// MarkDeprecated adds the deprecation headers to the responses of the deprecated routes;
+// route names the matched route.
+func (app *App) MarkDeprecated(route metrics.RouteFunc, next http.Handler) http.Handler {
+	if len(app.deprecations) == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if deprecation, ok := app.deprecations[r.Method+" "+route(r)]; ok {
+			deprecation.header(w.Header())
+		}
+		next.ServeHTTP(w, r)
+	})
+}
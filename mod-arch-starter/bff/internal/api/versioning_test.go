@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/openapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func registerTestVersionedRoutes(t *testing.T, routes ...VersionedRoute) {
+	t.Helper()
+	for _, route := range routes {
+		RegisterVersionedRoute(route)
+	}
+	t.Cleanup(func() {
+		versionedRouteMu.Lock()
+		defer versionedRouteMu.Unlock()
+		versionedRoutes = nil
+		deprecatedRoutes = map[string]Deprecation{}
+	})
+}
+
+func versionHandler(version string) func(app *App) httprouter.Handle {
+	return func(app *App) httprouter.Handle {
+		return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+			_ = app.WriteJSON(w, http.StatusOK, Envelope[string, None]{Data: version + " " + ps.ByName("name")}, nil)
+		}
+	}
+}
+
+func TestVersionedRoutes(t *testing.T) {
+	since := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	registerTestVersionedRoutes(t,
+		VersionedRoute{Version: "v2", Method: http.MethodGet, Path: "/notebooks/:name", Handler: versionHandler("v2")},
+		VersionedRoute{Version: APIVersionV1, Method: http.MethodGet, Path: "/notebooks/:name", Handler: versionHandler("v1"), Deprecation: &Deprecation{
+			Since:         since,
+			Sunset:        sunset,
+			Successor:     "/api/v2/notebooks/:name",
+			Documentation: "https://example.com/migrating-to-v2",
+		}},
+	)
+	DeprecateRoute(http.MethodGet, ClustersPath, Deprecation{Since: since})
+
+	app := newWatchTestApp(t)
+	var err error
+	app.versionedRoutes, app.deprecations, err = app.newVersionedRoutes()
+	require.NoError(t, err)
+	routes := app.Routes()
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(constants.KubeflowUserIDHeader, "user@example.com")
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, req)
+		return rr
+	}
+
+	for _, path := range []string{"/api/v2/notebooks/lab", PathPrefix + "/api/v2/notebooks/lab"} {
+		rr := get(path)
+		require.Equal(t, http.StatusOK, rr.Code, path)
+		assert.JSONEq(t, `{"data": "v2 lab"}`, rr.Body.String())
+		assert.Empty(t, rr.Header().Get("Deprecation"), "v2 is supported")
+	}
+
+	rr := get("/api/v1/notebooks/lab")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"data": "v1 lab"}`, rr.Body.String())
+	assert.Equal(t, "@1748736000", rr.Header().Get("Deprecation"))
+	assert.Equal(t, "Thu, 01 Jan 2026 00:00:00 GMT", rr.Header().Get("Sunset"))
+	assert.Equal(t, []string{
+		`</api/v2/notebooks/:name>; rel="successor-version"`,
+		`<https://example.com/migrating-to-v2>; rel="deprecation"`,
+	}, rr.Header().Values("Link"))
+
+	assert.Equal(t, "@1748736000", get(ClustersPath).Header().Get("Deprecation"), "starter routes can be deprecated")
+	assert.Empty(t, get(NamespacePath).Header().Get("Deprecation"))
+
+	// Versioned routes are authenticated like /api/v1
+	rr = httptest.NewRecorder()
+	routes.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v2/notebooks/lab", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.NotEqual(t, http.StatusOK, get("/api/v3/notebooks/lab").Code, "unregistered versions aren't mounted")
+
+	spec, err := app.newOpenAPISpec()
+	require.NoError(t, err)
+	var doc openapi.Document
+	require.NoError(t, json.Unmarshal(spec, &doc))
+	assert.True(t, doc.Paths["/api/v1/clusters"]["get"].Deprecated)
+	assert.False(t, doc.Paths["/api/v1/namespaces"]["get"].Deprecated)
+}
+
+func TestNewVersionedRoutes_Invalid(t *testing.T) {
+	for name, route := range map[string]VersionedRoute{
+		"version":     {Version: "2", Method: http.MethodGet, Path: "/notebooks", Handler: versionHandler("v2")},
+		"path":        {Version: "v2", Method: http.MethodGet, Path: "notebooks", Handler: versionHandler("v2")},
+		"handler":     {Version: "v2", Method: http.MethodGet, Path: "/notebooks"},
+		"deprecation": {Version: "v2", Method: http.MethodGet, Path: "/notebooks", Handler: versionHandler("v2"), Deprecation: &Deprecation{}},
+		"sunset": {Version: "v2", Method: http.MethodGet, Path: "/notebooks", Handler: versionHandler("v2"), Deprecation: &Deprecation{
+			Since:  time.Now(),
+			Sunset: time.Now().Add(-time.Hour),
+		}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			registerTestVersionedRoutes(t, route)
+			_, _, err := newWatchTestApp(t).newVersionedRoutes()
+			assert.Error(t, err)
+		})
+	}
+}
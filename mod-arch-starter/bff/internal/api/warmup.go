@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/healthcheck"
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/parallel"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/repositories"
+)
+
+// warmupState tracks the cache warmup of StartWarmup, for the cache-warmup readiness check.
+type warmupState struct {
+	started atomic.Bool
+	// done is set once every step ran, or the warmup timed out
+	done                     atomic.Bool
+	total, succeeded, failed atomic.Int64
+}
+
+// warmupStep is a call priming a cache.
+type warmupStep struct {
+	name string
+	run  func(ctx context.Context, client k8s.KubernetesClientInterface) error
+}
+
+// StartWarmup primes the caches for cfg.WarmupNamespaces in the background: it reads every
+// namespace, then, for every identity of cfg.WarmupUsers, its user, its access to the
+// namespaces and their services, as the first requests of the users would. The cache-warmup
+// readiness check fails until every step ran, or cfg.WarmupTimeout elapsed; failed steps are
+// logged and don't delay readiness. It returns right away, and does nothing without warmup
+// namespaces or when called again.
+func (app *App) StartWarmup(ctx context.Context) {
+	if len(app.config.WarmupNamespaces) == 0 || !app.warmup.started.CompareAndSwap(false, true) {
+		return
+	}
+	steps, err := app.warmupSteps()
+	if err != nil {
+		app.logger.Error("cache warmup skipped", "error", err)
+		app.warmup.done.Store(true)
+		return
+	}
+	app.warmup.total.Store(int64(len(steps)))
+	app.recordWarmupProgress()
+
+	go func() {
+		defer app.warmup.done.Store(true)
+		start := time.Now()
+		ctx, cancel := context.WithTimeout(ctx, app.config.WarmupTimeout)
+		defer cancel()
+
+		client, err := app.kubernetesClientFactory.GetClient(ctx)
+		if err != nil {
+			app.logger.Error("cache warmup skipped", "error", fmt.Errorf("failed to get Kubernetes client: %w", err))
+			return
+		}
+		_ = parallel.ForEach(ctx, parallel.Options{}, len(steps), func(ctx context.Context, i int) {
+			if err := steps[i].run(ctx, client); err != nil {
+				app.warmup.failed.Add(1)
+				app.logger.Warn("cache warmup step failed", "step", steps[i].name, "error", err)
+			} else {
+				app.warmup.succeeded.Add(1)
+			}
+			app.recordWarmupProgress()
+		})
+
+		duration := time.Since(start)
+		if app.metrics != nil {
+			app.metrics.RecordWarmupDone(duration)
+		}
+		pending, succeeded, failed := app.warmupProgress()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && pending > 0 {
+			app.logger.Warn("cache warmup timed out, turning ready with cold caches", "pending", pending, "succeeded", succeeded, "failed", failed)
+			return
+		}
+		app.logger.Info("cache warmup done", "succeeded", succeeded, "failed", failed, "duration", duration)
+	}()
+}
+
+// warmupSteps lists the steps of the warmup.
+func (app *App) warmupSteps() ([]warmupStep, error) {
+	identities, err := config.ParseWarmupUsers(app.config.WarmupUsers)
+	if err != nil {
+		return nil, fmt.Errorf("warmup-users: %w", err)
+	}
+	selector, err := repositories.ParseServiceSelector(app.serviceLabelSelector(), app.config.ServiceAnnotationSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	var steps []warmupStep
+	for _, namespace := range app.config.WarmupNamespaces {
+		steps = append(steps, warmupStep{name: "namespace " + namespace, run: func(ctx context.Context, client k8s.KubernetesClientInterface) error {
+			_, err := client.Reader().GetNamespace(ctx, namespace)
+			return err
+		}})
+	}
+	for _, id := range identities {
+		identity := &k8s.RequestIdentity{UserID: id.UserID, Groups: id.Groups}
+		steps = append(steps, warmupStep{name: "user " + id.UserID, run: func(ctx context.Context, client k8s.KubernetesClientInterface) error {
+			_, err := app.repositories.User.GetUser(client, ctx, identity)
+			return err
+		}})
+		for _, namespace := range app.config.WarmupNamespaces {
+			steps = append(steps, warmupStep{name: fmt.Sprintf("services of %s in %s", id.UserID, namespace), run: func(ctx context.Context, client k8s.KubernetesClientInterface) error {
+				// The access review of AttachNamespace, then the services and their review
+				if _, err := client.CanAccess(ctx, identity, "get", "", "namespaces", namespace); err != nil {
+					return err
+				}
+				_, err := app.repositories.Service.GetServices(client, ctx, identity, namespace, selector)
+				return err
+			}})
+		}
+	}
+	return steps, nil
+}
+
+// warmupProgress returns the number of pending, succeeded and failed steps.
+func (app *App) warmupProgress() (pending, succeeded, failed int) {
+	succeeded, failed = int(app.warmup.succeeded.Load()), int(app.warmup.failed.Load())
+	return int(app.warmup.total.Load()) - succeeded - failed, succeeded, failed
+}
+
+func (app *App) recordWarmupProgress() {
+	if app.metrics != nil {
+		app.metrics.RecordWarmupProgress(app.warmupProgress())
+	}
+}
+
+// warmupCheck fails until the cache warmup is over.
+func (app *App) warmupCheck() healthcheck.Check {
+	return healthcheck.Check{
+		Name: "cache-warmup",
+		Func: func(context.Context) error {
+			if app.warmup.done.Load() {
+				return nil
+			}
+			if !app.warmup.started.Load() {
+				return errors.New("the cache warmup hasn't started")
+			}
+			pending, succeeded, failed := app.warmupProgress()
+			return fmt.Errorf("warming up the caches: %d/%d steps done", succeeded+failed, pending+succeeded+failed)
+		},
+	}
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartWarmup(t *testing.T) {
+	app := newWatchTestApp(t)
+	app.config.WarmupNamespaces = []string{"dora-namespace", "missing-namespace"}
+	app.config.WarmupUsers = []string{"doraNonAdmin@example.com"}
+	app.config.WarmupTimeout = time.Minute
+	app.metrics = metrics.New()
+	check := app.warmupCheck()
+
+	assert.ErrorContains(t, check.Func(context.Background()), "hasn't started")
+
+	app.StartWarmup(context.Background())
+	require.Eventually(t, func() bool { return check.Func(context.Background()) == nil }, 10*time.Second, 10*time.Millisecond)
+
+	// Two namespaces, then the user and its access to both
+	pending, succeeded, failed := app.warmupProgress()
+	assert.Equal(t, 0, pending)
+	assert.Equal(t, 5, succeeded+failed)
+	assert.GreaterOrEqual(t, failed, 1, "the missing namespace fails without blocking readiness")
+	assert.GreaterOrEqual(t, succeeded, 3)
+}
+
+func TestStartWarmup_Canceled(t *testing.T) {
+	app := newWatchTestApp(t)
+	app.config.WarmupNamespaces = []string{"dora-namespace"}
+	app.config.WarmupTimeout = time.Minute
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	app.StartWarmup(ctx)
+
+	// Over once ctx is done, whatever ran before
+	require.Eventually(t, func() bool { return app.warmupCheck().Func(context.Background()) == nil }, 10*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/sse"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// WatchHandler streams changes of a Kubernetes resource as Server-Sent Events.
+// The resource is the path parameter; group (default core), version (default v1) and
+// namespace (default cluster-wide) are query parameters. A reconnecting EventSource resumes
+// from the Last-Event-ID header (or the resourceVersion query parameter).
+//
+// The first watch is opened before the stream starts, so authorization failures are returned
+// as regular JSON errors (e.g. 403) rather than as an event.
+func (app *App) WatchHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+
+	ctx := r.Context()
+	identity, ok := ctx.Value(constants.RequestIdentityKey).(*kubernetes.RequestIdentity)
+	if !ok || identity == nil {
+		app.badRequestResponse(w, r, fmt.Errorf("missing RequestIdentity in context"))
+		return
+	}
+
+	query := r.URL.Query()
+	gvr := schema.GroupVersionResource{
+		Group:    query.Get("group"),
+		Version:  query.Get("version"),
+		Resource: ps.ByName("resource"),
+	}
+	if gvr.Version == "" {
+		gvr.Version = "v1"
+	}
+	namespace := query.Get("namespace")
+
+	resourceVersion := r.Header.Get("Last-Event-ID")
+	if resourceVersion == "" {
+		resourceVersion = query.Get("resourceVersion")
+	}
+
+	client, err := app.kubernetesClientFactory.GetClient(ctx)
+	if err != nil {
+		app.serverErrorResponse(w, r, fmt.Errorf("failed to get Kubernetes client: %w", err))
+		return
+	}
+
+	watchFn := func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+		return app.repositories.Watch.WatchResource(client, ctx, identity, gvr, namespace, opts)
+	}
+
+	first, err := watchFn(ctx, metav1.ListOptions{ResourceVersion: resourceVersion, AllowWatchBookmarks: true})
+	if err != nil {
+		app.apiErrorResponse(w, r, err)
+		return
+	}
+
+	stream, err := sse.NewStream(w)
+	if err != nil {
+		first.Stop()
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// The stream ends on shutdown; the browser then resumes from Last-Event-ID on another replica
+	streamCtx, cancel := app.streamContext(ctx)
+	defer cancel()
+
+	// Hand the already-open watch to the first iteration of the stream loop
+	err = sse.StreamWatch(streamCtx, stream, func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+		if first != nil {
+			opened := first
+			first = nil
+			return opened, nil
+		}
+		return watchFn(ctx, opts)
+	}, sse.WatchOptions{
+		ResourceVersion: resourceVersion,
+		Logger:          logger.FromRequest(r),
+	})
+	if err != nil {
+		logger.FromRequest(r).Debug("watch stream ended", "resource", gvr.String(), "error", err)
+	}
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes/k8mocks"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/repositories"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/watchmux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newWatchTestApp(t *testing.T) *App {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := config.EnvConfig{AuthMethod: config.AuthMethodInternal}
+	factory, err := k8mocks.NewInMemoryKubernetesClientFactory(cfg, logger)
+	require.NoError(t, err)
+	return &App{config: cfg, logger: logger, kubernetesClientFactory: factory, repositories: repositories.NewRepositories()}
+}
+
+func TestWatchHandler_Forbidden(t *testing.T) {
+	app := newWatchTestApp(t)
+
+	identity := &kubernetes.RequestIdentity{UserID: "doraNonAdmin@example.com"}
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/watch/namespaces", nil)
+	req.Header.Set(constants.KubeflowUserIDHeader, identity.UserID)
+	req = req.WithContext(context.WithValue(req.Context(), constants.RequestIdentityKey, identity))
+	rr := httptest.NewRecorder()
+
+	app.Routes().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Contains(t, rr.Header().Get("Content-Type"), "application/json")
+}
+
+func TestWatchHandler_StreamsEvents(t *testing.T) {
+	app := newWatchTestApp(t)
+
+	identity := &kubernetes.RequestIdentity{UserID: "doraNonAdmin@example.com"}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	ctx = context.WithValue(ctx, constants.RequestIdentityKey, identity)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/watch/services?namespace=dora-namespace", nil).WithContext(ctx)
+	req.Header.Set(constants.KubeflowUserIDHeader, identity.UserID)
+	rr := httptest.NewRecorder()
+
+	app.Routes().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+	assert.False(t, strings.Contains(rr.Body.String(), "event: error"))
+}
+
+func TestWatchHandler_Shared(t *testing.T) {
+	app := newWatchTestApp(t)
+	mux := watchmux.New(watchmux.Options{})
+	defer mux.Close()
+	app.repositories.Watch.UseMultiplexer(mux)
+	routes := app.Routes()
+	watchNamespaces := func(user string) string {
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/watch/namespaces", nil).WithContext(ctx)
+		req.Header.Set(constants.KubeflowUserIDHeader, user)
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		return rr.Body.String()
+	}
+
+	admin := watchNamespaces("user@example.com")
+	assert.Contains(t, admin, `"name":"bella-namespace"`)
+	assert.Contains(t, admin, `"name":"dora-namespace"`)
+
+	// Users who can't watch every namespace get those they can access from the same upstream
+	dora := watchNamespaces("doraNonAdmin@example.com")
+	assert.Contains(t, dora, `"name":"dora-namespace"`)
+	assert.NotContains(t, dora, `"name":"bella-namespace"`)
+	assert.Equal(t, 1, mux.Upstreams())
+}
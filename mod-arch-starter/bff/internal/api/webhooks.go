@@ -0,0 +1,100 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/audit"
+	logging "github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/operations"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/webhooks"
+)
+
+// newWebhooks builds the dispatcher of the events to the -webhook-endpoints; nil when none
+// is set.
+func (app *App) newWebhooks() (*webhooks.Dispatcher, error) {
+	cfg := app.config
+	if len(cfg.WebhookEndpoints) == 0 {
+		return nil, nil
+	}
+	parsed, err := cfg.ParseWebhookEndpoints()
+	if err != nil {
+		return nil, err
+	}
+	endpoints := make([]webhooks.Endpoint, 0, len(parsed))
+	for _, endpoint := range parsed {
+		for _, pattern := range endpoint.Events {
+			if err := webhooks.ValidatePattern(pattern); err != nil {
+				return nil, fmt.Errorf("webhook-events: endpoint %s: %w", endpoint.Name, err)
+			}
+		}
+		endpoints = append(endpoints, webhooks.Endpoint{
+			Name:   endpoint.Name,
+			URL:    endpoint.URL,
+			Secret: endpoint.Secret,
+			Events: endpoint.Events,
+		})
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = app.upstreamTLSConfig(UpstreamWebhooks)
+	opts := webhooks.Options{
+		Endpoints:   endpoints,
+		Client:      &http.Client{Timeout: 10 * time.Second, Transport: transport},
+		QueueSize:   cfg.WebhookQueueSize,
+		MaxAttempts: cfg.WebhookMaxAttempts,
+		Logger:      logging.ForPackage(app.logger, "webhooks"),
+	}
+	if app.metrics != nil {
+		opts.OnDelivery = app.metrics.RecordWebhookDelivery
+	}
+	return webhooks.New(opts), nil
+}
+
+// resourceEventTypes are the event types of the audit verbs.
+var resourceEventTypes = map[string]string{
+	audit.VerbCreate: webhooks.EventResourceCreated,
+	audit.VerbUpdate: webhooks.EventResourceUpdated,
+	audit.VerbPatch:  webhooks.EventResourceUpdated,
+	audit.VerbDelete: webhooks.EventResourceDeleted,
+}
+
+// dispatchResourceEvent dispatches the change of a resource recorded by entry to the webhooks.
+// Failed requests and dry runs change nothing, and accepted ones (202) start an operation,
+// dispatched once it completes.
+func (app *App) dispatchResourceEvent(entry audit.Entry) {
+	eventType, ok := resourceEventTypes[entry.Verb]
+	if app.webhooks == nil || !ok || entry.Resource == "" || entry.Annotations[dryRunAnnotation] == "true" ||
+		entry.Outcome != audit.OutcomeSuccess || entry.Status == http.StatusAccepted {
+		return
+	}
+	event := webhooks.Event{
+		Type: eventType,
+		Time: entry.Time,
+		User: entry.User,
+		Resource: &webhooks.Resource{
+			Resource:  entry.Resource,
+			Namespace: entry.Namespace,
+			Name:      entry.Name,
+		},
+	}
+	if entry.RequestID != "" {
+		event.Data = map[string]string{"requestId": entry.RequestID}
+	}
+	app.webhooks.Dispatch(event)
+}
+
+// dispatchOperationEvent dispatches a completed operation to the webhooks. The user is the
+// owner of an operation started by a user ID; owners identified by a token stay anonymous.
+func (app *App) dispatchOperationEvent(op operations.Operation) {
+	event := webhooks.Event{Type: webhooks.EventOperationCompleted, Data: op}
+	if user, ok := strings.CutPrefix(op.Owner, "user:"); ok {
+		event.User = user
+	}
+	if op.CompletedAt != nil {
+		event.Time = *op.CompletedAt
+	}
+	app.webhooks.Dispatch(event)
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/operations"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/webhooks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhooks(t *testing.T) {
+	var mu sync.Mutex
+	var events []webhooks.Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !webhooks.Verify("s3cret", r.Header.Get(webhooks.TimestampHeader), body, r.Header.Get(webhooks.SignatureHeader)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var event webhooks.Event
+		_ = json.Unmarshal(body, &event)
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}))
+	defer server.Close()
+
+	app := newWatchTestApp(t)
+	app.config.WebhookEndpoints = []string{"ci=" + server.URL}
+	app.config.WebhookSecrets = []string{"ci=s3cret"}
+	var err error
+	app.webhooks, err = app.newWebhooks()
+	require.NoError(t, err)
+	app.operations, err = app.newOperations()
+	require.NoError(t, err)
+	app.operations.OnCompleted(app.dispatchOperationEvent)
+
+	routes := app.Routes()
+	serve := func(method, path, body string) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(constants.KubeflowUserIDHeader, "doraNonAdmin@example.com")
+		routes.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	serve(http.MethodPost, SecretsPath+"?namespace=dora-namespace", `{"data":{"name":"db","data":{}}}`)
+	serve(http.MethodPut, SecretsPath+"/db?namespace=bella-namespace", `{"data":{"data":{}}}`)
+	serve(http.MethodPut, SecretsPath+"/db?namespace=dora-namespace", `{"data":{"data":{"user":"ZG9yYQ=="}}}`)
+	serve(http.MethodPut, SecretsPath+"/db?namespace=dora-namespace&dryRun=true", `{"data":{"data":{}}}`)
+
+	_, err = app.operations.Start(context.Background(), "user:dora@example.com", "test", func(context.Context, *operations.Progress) (any, error) {
+		return "done", nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, app.operations.Stop(context.Background()))
+	require.NoError(t, app.webhooks.Close(context.Background()))
+
+	require.Len(t, events, 3, "denied requests and dry runs change nothing")
+	assert.Equal(t, webhooks.EventResourceCreated, events[0].Type)
+	assert.Equal(t, "doraNonAdmin@example.com", events[0].User)
+	assert.Equal(t, &webhooks.Resource{Resource: "secrets", Namespace: "dora-namespace", Name: "db"}, events[0].Resource,
+		"the name of a created object is read from the response")
+	assert.Equal(t, webhooks.EventResourceUpdated, events[1].Type)
+	assert.Equal(t, "db", events[1].Resource.Name)
+
+	completed := events[2]
+	assert.Equal(t, webhooks.EventOperationCompleted, completed.Type)
+	assert.Equal(t, "dora@example.com", completed.User)
+	op, _ := json.Marshal(completed.Data)
+	assert.Contains(t, string(op), `"status":"succeeded"`)
+	assert.Contains(t, string(op), `"result":"done"`)
+}
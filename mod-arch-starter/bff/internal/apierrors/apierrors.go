@@ -0,0 +1,298 @@
+// Package apierrors translates errors returned by repositories and integrations into the JSON
+// error envelope returned by every BFF handler:
+//
+//	{"error": {"code": "404", "message": "...", "details": {...}, "requestId": "..."}}
+//
+// Repositories keep wrapping errors with fmt.Errorf("...: %w", err); FromError walks the chain,
+// so a Kubernetes StatusError (or an *Error created here) is still found after wrapping.
+package apierrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/database"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/bffclient"
+	grpcclient "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/grpc"
+	mrserver "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/httpclient"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/objectstore"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/resilience"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/ssrf"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/validation"
+	grpcstatus "google.golang.org/grpc/status"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Messages returned to clients. Server-side failures never echo the underlying error.
+const (
+	MessageBadRequest      = "the request is invalid"
+	MessageUnauthorized    = "invalid or expired credentials"
+	MessageForbidden       = "Access forbidden"
+	MessageNotFound        = "the requested resource could not be found"
+	MessageConflict        = "the resource already exists or was modified concurrently"
+	MessageTooManyRequests = "too many requests, please retry later"
+	MessageUnavailable     = "a backing service is unavailable, please retry later"
+	MessageOverloaded      = "the server is overloaded, please retry later"
+	MessageTimeout         = "the request took too long to complete"
+	MessageInternal        = "the server encountered a problem and could not process your request"
+)
+
+// Keys of the client-facing messages, stable across releases and locales: clients can handle
+// errors by key, and the message catalog (see i18n) translates the messages by key.
+const (
+	KeyBadRequest      = "BadRequest"
+	KeyUnauthorized    = "Unauthorized"
+	KeyForbidden       = "Forbidden"
+	KeyNotFound        = "NotFound"
+	KeyConflict        = "Conflict"
+	KeyTooManyRequests = "TooManyRequests"
+	KeyUnavailable     = "Unavailable"
+	KeyOverloaded      = "Overloaded"
+	KeyTimeout         = "Timeout"
+	KeyInternal        = "Internal"
+)
+
+// messageKeys are the keys of the client-facing messages, by message.
+var messageKeys = map[string]string{
+	MessageBadRequest:      KeyBadRequest,
+	MessageUnauthorized:    KeyUnauthorized,
+	MessageForbidden:       KeyForbidden,
+	MessageNotFound:        KeyNotFound,
+	MessageConflict:        KeyConflict,
+	MessageTooManyRequests: KeyTooManyRequests,
+	MessageUnavailable:     KeyUnavailable,
+	MessageOverloaded:      KeyOverloaded,
+	MessageTimeout:         KeyTimeout,
+	MessageInternal:        KeyInternal,
+}
+
+// MessageKey returns the key of a client-facing message, or "" for the messages without one,
+// such as those describing what is invalid in a request.
+func MessageKey(message string) string {
+	return messageKeys[message]
+}
+
+// ErrRequestTimeout is the cause of the context of a request that exceeded its timeout.
+var ErrRequestTimeout = errors.New("request timeout exceeded")
+
+// ErrorPayload is the body of the "error" field in every error response. Message is
+// localized for the Accept-Language of the request when MessageKey is set.
+type ErrorPayload struct {
+	Code       string         `json:"code"`
+	Message    string         `json:"message"`
+	MessageKey string         `json:"messageKey,omitempty"`
+	Details    map[string]any `json:"details,omitempty"`
+	RequestID  string         `json:"requestId,omitempty"`
+}
+
+// ErrorEnvelope is the top-level JSON document of an error response.
+type ErrorEnvelope struct {
+	StatusCode int          `json:"-"`
+	Error      ErrorPayload `json:"error"`
+}
+
+// Error is an error that knows which HTTP status and client-facing message it maps to.
+// Repositories can return one directly when they detect a condition themselves
+// (e.g. NotFound for a missing ConfigMap key).
+type Error struct {
+	StatusCode int
+	Message    string
+	Details    map[string]any
+	Err        error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Envelope renders the error as the JSON envelope, stamped with the request ID.
+func (e *Error) Envelope(requestID string) *ErrorEnvelope {
+	return &ErrorEnvelope{
+		StatusCode: e.StatusCode,
+		Error: ErrorPayload{
+			Code:       strconv.Itoa(e.StatusCode),
+			Message:    e.Message,
+			MessageKey: MessageKey(e.Message),
+			Details:    e.Details,
+			RequestID:  requestID,
+		},
+	}
+}
+
+// New creates an Error with the given status and client-facing message.
+func New(statusCode int, message string) *Error {
+	return &Error{StatusCode: statusCode, Message: message}
+}
+
+// Wrap creates an Error that keeps err as its cause; the cause is logged but never sent to clients.
+func Wrap(statusCode int, message string, err error) *Error {
+	return &Error{StatusCode: statusCode, Message: message, Err: err}
+}
+
+func BadRequest(message string) *Error { return New(http.StatusBadRequest, message) }
+func NotFound(message string) *Error   { return New(http.StatusNotFound, message) }
+func Conflict(message string) *Error   { return New(http.StatusConflict, message) }
+func Forbidden(message string) *Error  { return New(http.StatusForbidden, message) }
+
+// FromError maps any error to an *Error:
+//   - an *Error anywhere in the chain is returned as-is;
+//   - validation errors become a 400 with their field errors as the "fields" detail;
+//   - Kubernetes API errors keep their status (401, 403, 404, 409, 429, 400/422) with a fixed
+//     message and the StatusReason, kind, name and retryAfterSeconds as details;
+//   - BFF client and upstream HTTP client errors keep their 4xx status, as do gRPC errors
+//     mapped to a 4xx (e.g. codes.NotFound), with the gRPC code as the "reason" detail, and
+//     object storage errors, with the S3 code (e.g. NoSuchKey) as the "reason" detail;
+//   - object storage calls naming no bucket become a 400;
+//   - missing database rows become a 404, and duplicate ones a 409;
+//   - calls rejected by an open circuit breaker become a 503 with retryAfterSeconds;
+//   - calls that exceeded their deadline, such as the timeout of the request, become a 504;
+//   - everything else becomes a 500 with a generic message.
+//
+// It returns nil for a nil error.
+func FromError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	var fieldErrs validation.Errors
+	if errors.As(err, &fieldErrs) {
+		return &Error{StatusCode: http.StatusBadRequest, Message: fieldErrs.Error(), Details: map[string]any{"fields": fieldErrs}, Err: err}
+	}
+
+	var status k8serrors.APIStatus
+	if errors.As(err, &status) {
+		return fromKubernetesStatus(status.Status(), err)
+	}
+
+	var openErr *resilience.OpenError
+	if errors.As(err, &openErr) {
+		apiErr := &Error{StatusCode: http.StatusServiceUnavailable, Message: MessageUnavailable, Details: map[string]any{"reason": "CircuitOpen"}, Err: err}
+		if seconds := int32(math.Ceil(openErr.RetryAfter.Seconds())); seconds > 0 {
+			apiErr.Details["retryAfterSeconds"] = seconds
+		}
+		return apiErr
+	}
+
+	var bffErr *bffclient.BFFClientError
+	if errors.As(err, &bffErr) && isClientError(bffErr.StatusCode) {
+		return &Error{
+			StatusCode: bffErr.StatusCode,
+			Message:    bffErr.Message,
+			Details:    map[string]any{"reason": bffErr.Code, "target": string(bffErr.Target)},
+			Err:        err,
+		}
+	}
+
+	var httpErr *mrserver.HTTPError
+	if errors.As(err, &httpErr) && isClientError(httpErr.StatusCode) {
+		return &Error{StatusCode: httpErr.StatusCode, Message: httpErr.Message, Err: err}
+	}
+
+	var grpcErr interface{ GRPCStatus() *grpcstatus.Status }
+	if errors.As(err, &grpcErr) && isClientError(grpcclient.HTTPStatus(grpcErr.GRPCStatus().Code())) {
+		grpcStatus := grpcErr.GRPCStatus()
+		return &Error{
+			StatusCode: grpcclient.HTTPStatus(grpcStatus.Code()),
+			Message:    grpcStatus.Message(),
+			Details:    map[string]any{"reason": grpcStatus.Code().String()},
+			Err:        err,
+		}
+	}
+
+	var s3Err *objectstore.Error
+	if errors.As(err, &s3Err) && isClientError(s3Err.StatusCode) {
+		return &Error{StatusCode: s3Err.StatusCode, Message: s3Err.Message, Details: map[string]any{"reason": s3Err.Code}, Err: err}
+	}
+	if errors.Is(err, objectstore.ErrNoBucket) {
+		return &Error{StatusCode: http.StatusBadRequest, Message: "a bucket is required, the credentials have no default bucket", Err: err}
+	}
+	if errors.Is(err, ssrf.ErrSSRFBlocked) {
+		return &Error{StatusCode: http.StatusBadRequest, Message: "the endpoint resolves to a private address the BFF may not call", Details: map[string]any{"reason": "EndpointNotAllowed"}, Err: err}
+	}
+
+	if errors.Is(err, database.ErrNotFound) {
+		return &Error{StatusCode: http.StatusNotFound, Message: MessageNotFound, Err: err}
+	}
+	if errors.Is(err, database.ErrConflict) {
+		return &Error{StatusCode: http.StatusConflict, Message: MessageConflict, Err: err}
+	}
+
+	if errors.Is(err, ErrRequestTimeout) || errors.Is(err, context.DeadlineExceeded) {
+		return &Error{StatusCode: http.StatusGatewayTimeout, Message: MessageTimeout, Details: map[string]any{"reason": "Timeout"}, Err: err}
+	}
+
+	return Wrap(http.StatusInternalServerError, MessageInternal, err)
+}
+
+func fromKubernetesStatus(status metav1.Status, err error) *Error {
+	apiErr := &Error{StatusCode: int(status.Code), Err: err}
+
+	switch status.Code {
+	case http.StatusUnauthorized:
+		apiErr.Message = MessageUnauthorized
+	case http.StatusForbidden:
+		apiErr.Message = MessageForbidden
+	case http.StatusNotFound:
+		apiErr.Message = MessageNotFound
+	case http.StatusConflict:
+		apiErr.Message = MessageConflict
+	case http.StatusTooManyRequests:
+		apiErr.Message = MessageTooManyRequests
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		// Validation messages describe the submitted object, so they are safe to return.
+		apiErr.Message = status.Message
+		if apiErr.Message == "" {
+			apiErr.Message = MessageBadRequest
+		}
+	default:
+		apiErr.StatusCode = http.StatusInternalServerError
+		apiErr.Message = MessageInternal
+		return apiErr
+	}
+
+	details := map[string]any{}
+	if status.Reason != "" {
+		details["reason"] = string(status.Reason)
+	}
+	if status.Details != nil {
+		if status.Details.Kind != "" {
+			details["kind"] = status.Details.Kind
+		}
+		if status.Details.Name != "" {
+			details["name"] = status.Details.Name
+		}
+		if status.Details.RetryAfterSeconds > 0 {
+			details["retryAfterSeconds"] = status.Details.RetryAfterSeconds
+		}
+		// The causes of a 409 are the fields of a server-side apply other field managers own.
+		if len(status.Details.Causes) > 0 && (apiErr.StatusCode == http.StatusUnprocessableEntity || apiErr.StatusCode == http.StatusConflict) {
+			details["causes"] = status.Details.Causes
+		}
+	}
+	if len(details) > 0 {
+		apiErr.Details = details
+	}
+
+	return apiErr
+}
+
+func isClientError(statusCode int) bool {
+	return statusCode >= 400 && statusCode < 500
+}
@@ -0,0 +1,136 @@
+package apierrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/database"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/bffclient"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/objectstore"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/resilience"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/validation"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestFromError_KubernetesStatus(t *testing.T) {
+	gr := schema.GroupResource{Resource: "configmaps"}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantMsg    string
+		wantReason string
+	}{
+		{"forbidden", k8serrors.NewForbidden(gr, "settings", errors.New("user cannot get")), http.StatusForbidden, MessageForbidden, "Forbidden"},
+		{"not found", k8serrors.NewNotFound(gr, "settings"), http.StatusNotFound, MessageNotFound, "NotFound"},
+		{"conflict", k8serrors.NewConflict(gr, "settings", errors.New("modified")), http.StatusConflict, MessageConflict, "Conflict"},
+		{"already exists", k8serrors.NewAlreadyExists(gr, "settings"), http.StatusConflict, MessageConflict, "AlreadyExists"},
+		{"too many requests", k8serrors.NewTooManyRequests("slow down", 5), http.StatusTooManyRequests, MessageTooManyRequests, "TooManyRequests"},
+		{"unauthorized", k8serrors.NewUnauthorized("bad token"), http.StatusUnauthorized, MessageUnauthorized, "Unauthorized"},
+		{"bad request", k8serrors.NewBadRequest("spec.name is required"), http.StatusBadRequest, "spec.name is required", "BadRequest"},
+		{"internal", k8serrors.NewInternalError(errors.New("etcd down")), http.StatusInternalServerError, MessageInternal, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Repositories wrap errors, so translation must see through the chain.
+			apiErr := FromError(fmt.Errorf("failed to get settings: %w", tt.err))
+			assert.Equal(t, tt.wantStatus, apiErr.StatusCode)
+			assert.Equal(t, tt.wantMsg, apiErr.Message)
+			if tt.wantReason != "" {
+				assert.Equal(t, tt.wantReason, apiErr.Details["reason"])
+			}
+			assert.ErrorIs(t, apiErr, tt.err)
+		})
+	}
+}
+
+func TestFromError_Details(t *testing.T) {
+	apiErr := FromError(k8serrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "settings"))
+	assert.Equal(t, "configmaps", apiErr.Details["kind"])
+	assert.Equal(t, "settings", apiErr.Details["name"])
+
+	apiErr = FromError(k8serrors.NewTooManyRequests("slow down", 7))
+	assert.Equal(t, int32(7), apiErr.Details["retryAfterSeconds"])
+}
+
+func TestFromError_Other(t *testing.T) {
+	assert.Nil(t, FromError(nil))
+
+	notFound := NotFound("no such model")
+	assert.Same(t, notFound, FromError(fmt.Errorf("lookup: %w", notFound)))
+
+	apiErr := FromError(errors.New("dial tcp: connection refused"))
+	assert.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+	assert.Equal(t, MessageInternal, apiErr.Message)
+
+	apiErr = FromError(bffclient.NewNotFoundError("maas", "model not found"))
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, "model not found", apiErr.Message)
+
+	apiErr = FromError(bffclient.NewServerUnavailableError("maas"))
+	assert.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+
+	apiErr = FromError(&url.Error{Op: "Get", URL: "https://kubernetes", Err: &resilience.OpenError{Upstream: "kubernetes", RetryAfter: 1500 * time.Millisecond}})
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	assert.Equal(t, MessageUnavailable, apiErr.Message)
+	assert.Equal(t, map[string]any{"reason": "CircuitOpen", "retryAfterSeconds": int32(2)}, apiErr.Details)
+
+	apiErr = FromError(&url.Error{Op: "Get", URL: "https://kubernetes", Err: context.DeadlineExceeded})
+	assert.Equal(t, http.StatusGatewayTimeout, apiErr.StatusCode)
+	assert.Equal(t, MessageTimeout, apiErr.Message)
+	assert.Equal(t, map[string]any{"reason": "Timeout"}, apiErr.Details)
+
+	fieldErrs := validation.Errors{{Field: "data.name", Message: "is required"}}
+	apiErr = FromError(fmt.Errorf("invalid body: %w", fieldErrs))
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Equal(t, "data.name is required", apiErr.Message)
+	assert.Equal(t, map[string]any{"fields": fieldErrs}, apiErr.Details)
+}
+
+func TestError_Envelope(t *testing.T) {
+	env := Conflict("already exists").Envelope("req-1")
+	assert.Equal(t, http.StatusConflict, env.StatusCode)
+	assert.Equal(t, ErrorPayload{Code: "409", Message: "already exists", RequestID: "req-1"}, env.Error)
+
+	env = NotFound(MessageNotFound).Envelope("req-2")
+	assert.Equal(t, KeyNotFound, env.Error.MessageKey, "the messages of the catalog have a key")
+}
+
+func TestFromError_GRPC(t *testing.T) {
+	apiErr := FromError(fmt.Errorf("failed to get artifact: %w", grpcstatus.Error(codes.NotFound, "artifact 7 not found")))
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, "artifact 7 not found", apiErr.Message)
+	assert.Equal(t, "NotFound", apiErr.Details["reason"])
+
+	apiErr = FromError(grpcstatus.Error(codes.Unavailable, "connection refused"))
+	assert.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+	assert.Equal(t, MessageInternal, apiErr.Message)
+}
+
+func TestFromError_ObjectStorage(t *testing.T) {
+	apiErr := FromError(fmt.Errorf("failed to download: %w", &objectstore.Error{StatusCode: http.StatusNotFound, Code: "NoSuchKey", Message: "The specified key does not exist."}))
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, "The specified key does not exist.", apiErr.Message)
+	assert.Equal(t, "NoSuchKey", apiErr.Details["reason"])
+
+	assert.Equal(t, http.StatusBadRequest, FromError(objectstore.ErrNoBucket).StatusCode)
+	assert.Equal(t, http.StatusInternalServerError, FromError(&objectstore.Error{StatusCode: http.StatusServiceUnavailable, Code: "SlowDown"}).StatusCode)
+}
+
+func TestFromError_Database(t *testing.T) {
+	apiErr := FromError(fmt.Errorf("failed to get the view: %w", database.ErrNotFound))
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, MessageNotFound, apiErr.Message)
+	assert.Equal(t, http.StatusConflict, FromError(fmt.Errorf("%w: duplicate key", database.ErrConflict)).StatusCode)
+}
@@ -0,0 +1,258 @@
+// Package audit records who changed what through the BFF. The API middleware builds an Entry
+// for every mutating request, and an Auditor hands the entries to its sinks in the background
+// so slow sinks never delay responses: a structured log (LogSink), Kubernetes Events
+// (EventSink) or an HTTP webhook (WebhookSink). Sink is the extension point for others.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultQueueSize bounds the entries waiting for the sinks when Options.QueueSize is not set.
+const DefaultQueueSize = 1000
+
+// Verbs of the entries, after the Kubernetes verbs of the HTTP methods.
+const (
+	VerbCreate = "create"
+	VerbUpdate = "update"
+	VerbPatch  = "patch"
+	VerbDelete = "delete"
+)
+
+// Outcomes of the entries.
+const (
+	OutcomeSuccess = "success"
+	// OutcomeDenied is a request rejected as unauthorized or forbidden (401, 403).
+	OutcomeDenied  = "denied"
+	OutcomeFailure = "failure"
+)
+
+// Entry is the audit record of a request.
+type Entry struct {
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"requestId,omitempty"`
+	User      string    `json:"user"`
+	Groups    []string  `json:"groups,omitempty"`
+	// Verb is the Kubernetes verb of the HTTP method, e.g. "create" for POST.
+	Verb   string `json:"verb"`
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	// Route is the pattern of the route, e.g. "/api/v1/secrets/:name"; empty when unmatched.
+	Route string `json:"route,omitempty"`
+	// Resource is the API resource changed, e.g. "secrets", and Name the object, if any.
+	Resource  string `json:"resource,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Status    int    `json:"status"`
+	Outcome   string `json:"outcome"`
+	// Annotations are the details added by the handler with Annotate, e.g. the audiences of a
+	// minted token.
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Latency is the duration of the request, in milliseconds in JSON.
+	Latency time.Duration `json:"-"`
+}
+
+// MarshalJSON adds the latency in milliseconds, as latencyMs.
+func (e Entry) MarshalJSON() ([]byte, error) {
+	type entry Entry
+	return json.Marshal(struct {
+		entry
+		LatencyMs float64 `json:"latencyMs"`
+	}{entry(e), float64(e.Latency.Microseconds()) / 1000})
+}
+
+type annotationsKey struct{}
+
+// annotations collects the Annotations of the entry of a request while it is handled.
+type annotations struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+// WithAnnotations returns ctx collecting the annotations of its request, so the handler can
+// Annotate the entry the middleware records once the request is answered.
+func WithAnnotations(ctx context.Context) context.Context {
+	return context.WithValue(ctx, annotationsKey{}, &annotations{})
+}
+
+// Annotate adds a detail to the entry of the request of ctx. It is a no-op for requests that
+// are not audited; never annotate with secrets, as entries are stored as they are.
+func Annotate(ctx context.Context, key, value string) {
+	collected, ok := ctx.Value(annotationsKey{}).(*annotations)
+	if !ok {
+		return
+	}
+	collected.mu.Lock()
+	defer collected.mu.Unlock()
+	if collected.values == nil {
+		collected.values = map[string]string{}
+	}
+	collected.values[key] = value
+}
+
+// AnnotationsFrom returns the annotations added to ctx, nil when there are none.
+func AnnotationsFrom(ctx context.Context) map[string]string {
+	collected, ok := ctx.Value(annotationsKey{}).(*annotations)
+	if !ok {
+		return nil
+	}
+	collected.mu.Lock()
+	defer collected.mu.Unlock()
+	if len(collected.values) == 0 {
+		return nil
+	}
+	values := make(map[string]string, len(collected.values))
+	for key, value := range collected.values {
+		values[key] = value
+	}
+	return values
+}
+
+// IsMutating reports whether requests of method change state and are audited.
+func IsMutating(method string) bool {
+	return Verb(method) != ""
+}
+
+// Verb is the Kubernetes verb of a mutating HTTP method, empty for read-only methods.
+func Verb(method string) string {
+	switch method {
+	case http.MethodPost:
+		return VerbCreate
+	case http.MethodPut:
+		return VerbUpdate
+	case http.MethodPatch:
+		return VerbPatch
+	case http.MethodDelete:
+		return VerbDelete
+	}
+	return ""
+}
+
+// Outcome classifies a response status.
+func Outcome(status int) string {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return OutcomeDenied
+	case status >= 400:
+		return OutcomeFailure
+	}
+	return OutcomeSuccess
+}
+
+// Sink stores audit entries. Record is called from a single goroutine, entries in order.
+type Sink interface {
+	Record(ctx context.Context, entry Entry) error
+}
+
+// SinkFunc adapts a function to Sink.
+type SinkFunc func(ctx context.Context, entry Entry) error
+
+func (f SinkFunc) Record(ctx context.Context, entry Entry) error {
+	return f(ctx, entry)
+}
+
+// Options configures New. Zero values use the defaults.
+type Options struct {
+	Sinks []Sink
+	// QueueSize defaults to DefaultQueueSize. Entries are dropped, and logged, when it is full.
+	QueueSize int
+	Logger    *slog.Logger
+}
+
+// Auditor delivers entries to its sinks in the background. A nil *Auditor is valid and
+// records nothing.
+type Auditor struct {
+	sinks  []Sink
+	logger *slog.Logger
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan Entry
+	done   chan struct{}
+}
+
+// New starts an Auditor; Close stops it.
+func New(opts Options) *Auditor {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultQueueSize
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	a := &Auditor{
+		sinks:  opts.Sinks,
+		logger: opts.Logger,
+		queue:  make(chan Entry, opts.QueueSize),
+		done:   make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+// Record queues entry for the sinks without blocking.
+func (a *Auditor) Record(entry Entry) {
+	if a == nil {
+		return
+	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return
+	}
+	select {
+	case a.queue <- entry:
+	default:
+		a.logger.Error("audit queue full, dropping entry", "user", entry.User, "verb", entry.Verb, "path", entry.Path, "request_id", entry.RequestID)
+	}
+}
+
+// Close stops accepting entries and waits until the queued ones are delivered, or ctx is done.
+func (a *Auditor) Close(ctx context.Context) error {
+	if a == nil {
+		return nil
+	}
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.queue)
+	}
+	a.mu.Unlock()
+
+	select {
+	case <-a.done:
+		return nil
+	case <-ctx.Done():
+		return errors.Join(errors.New("audit entries left undelivered"), ctx.Err())
+	}
+}
+
+func (a *Auditor) run() {
+	defer close(a.done)
+	for entry := range a.queue {
+		for _, sink := range a.sinks {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			if err := sink.Record(ctx, entry); err != nil {
+				a.logger.Error("failed to record audit entry", "sink", sinkName(sink), "request_id", entry.RequestID, "error", err)
+			}
+			cancel()
+		}
+	}
+}
+
+func sinkName(sink Sink) string {
+	switch sink.(type) {
+	case *LogSink:
+		return "log"
+	case *EventSink:
+		return "events"
+	case *WebhookSink:
+		return "webhook"
+	}
+	return "custom"
+}
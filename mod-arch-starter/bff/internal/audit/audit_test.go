@@ -0,0 +1,154 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var testEntry = Entry{
+	Time:      time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	RequestID: "req-1",
+	User:      "dora@example.com",
+	Verb:      VerbUpdate,
+	Method:    http.MethodPut,
+	Path:      "/api/v1/secrets/db",
+	Resource:  "secrets",
+	Name:      "db",
+	Namespace: "dora-namespace",
+	Status:    http.StatusForbidden,
+	Outcome:   OutcomeDenied,
+	Latency:   1500 * time.Microsecond,
+}
+
+func TestVerbAndOutcome(t *testing.T) {
+	assert.Equal(t, VerbCreate, Verb(http.MethodPost))
+	assert.Equal(t, VerbDelete, Verb(http.MethodDelete))
+	assert.False(t, IsMutating(http.MethodGet))
+	assert.True(t, IsMutating(http.MethodPatch))
+
+	assert.Equal(t, OutcomeSuccess, Outcome(http.StatusCreated))
+	assert.Equal(t, OutcomeDenied, Outcome(http.StatusUnauthorized))
+	assert.Equal(t, OutcomeFailure, Outcome(http.StatusConflict))
+}
+
+func TestAuditor(t *testing.T) {
+	var mu sync.Mutex
+	var recorded []Entry
+	sink := SinkFunc(func(_ context.Context, entry Entry) error {
+		mu.Lock()
+		defer mu.Unlock()
+		recorded = append(recorded, entry)
+		return nil
+	})
+	auditor := New(Options{Sinks: []Sink{sink}, Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+
+	for i := 0; i < 3; i++ {
+		auditor.Record(testEntry)
+	}
+	require.NoError(t, auditor.Close(context.Background()))
+	assert.Len(t, recorded, 3, "queued entries are delivered on close")
+
+	auditor.Record(testEntry)
+	assert.Len(t, recorded, 3, "closed")
+	require.NoError(t, auditor.Close(context.Background()))
+
+	var nilAuditor *Auditor
+	nilAuditor.Record(testEntry)
+	assert.NoError(t, nilAuditor.Close(context.Background()))
+}
+
+func TestAuditor_QueueFull(t *testing.T) {
+	release := make(chan struct{})
+	sink := SinkFunc(func(context.Context, Entry) error {
+		<-release
+		return nil
+	})
+	var logs bytes.Buffer
+	auditor := New(Options{Sinks: []Sink{sink}, QueueSize: 1, Logger: slog.New(slog.NewTextHandler(&logs, nil))})
+	for i := 0; i < 5; i++ {
+		auditor.Record(testEntry)
+	}
+	close(release)
+	require.NoError(t, auditor.Close(context.Background()))
+	assert.Contains(t, logs.String(), "audit queue full")
+}
+
+func TestEntryJSON(t *testing.T) {
+	data, err := json.Marshal(testEntry)
+	require.NoError(t, err)
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(data, &fields))
+	assert.Equal(t, 1.5, fields["latencyMs"])
+	assert.Equal(t, "denied", fields["outcome"])
+	assert.Equal(t, "req-1", fields["requestId"])
+}
+
+func TestLogSink(t *testing.T) {
+	var logs bytes.Buffer
+	sink := &LogSink{Logger: slog.New(slog.NewJSONHandler(&logs, nil))}
+	require.NoError(t, sink.Record(context.Background(), testEntry))
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &record))
+	assert.Equal(t, "audit", record["msg"])
+	assert.Equal(t, "dora@example.com", record["user"])
+	assert.Equal(t, "secrets", record["resource"])
+	assert.Equal(t, float64(http.StatusForbidden), record["status"])
+}
+
+func TestEventSink(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	sink := &EventSink{Client: client, Namespace: "mod-arch"}
+	require.NoError(t, sink.Record(context.Background(), testEntry))
+
+	events, err := client.CoreV1().Events("mod-arch").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, events.Items, 1)
+	event := events.Items[0]
+	assert.Equal(t, corev1.EventTypeWarning, event.Type)
+	assert.Equal(t, "AuditUpdate", event.Reason)
+	assert.Contains(t, event.Message, "dora@example.com update secrets/db in dora-namespace: 403 denied")
+	assert.Equal(t, "req-1", event.Annotations[EventRequestIDAnnotation])
+	assert.Equal(t, "Namespace", event.InvolvedObject.Kind)
+}
+
+func TestWebhookSink(t *testing.T) {
+	var received Entry
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		var body struct {
+			Entry
+			LatencyMs float64 `json:"latencyMs"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		received = body.Entry
+		if received.User == "fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	sink := &WebhookSink{URL: server.URL}
+	require.NoError(t, sink.Record(context.Background(), testEntry))
+	assert.Equal(t, "application/json", contentType)
+	assert.Equal(t, testEntry.Path, received.Path)
+
+	failing := testEntry
+	failing.User = "fail"
+	assert.ErrorContains(t, sink.Record(context.Background(), failing), "500")
+}
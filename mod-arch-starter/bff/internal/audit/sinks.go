@@ -0,0 +1,157 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// LogSink writes every entry as an "audit" log record at info level.
+type LogSink struct {
+	Logger *slog.Logger
+}
+
+func (s *LogSink) Record(ctx context.Context, entry Entry) error {
+	attrs := []slog.Attr{
+		slog.String("user", entry.User),
+		slog.String("verb", entry.Verb),
+		slog.String("method", entry.Method),
+		slog.String("path", entry.Path),
+		slog.String("resource", entry.Resource),
+		slog.String("name", entry.Name),
+		slog.String("namespace", entry.Namespace),
+		slog.Int("status", entry.Status),
+		slog.String("outcome", entry.Outcome),
+		slog.Duration("latency", entry.Latency),
+		slog.String("request_id", entry.RequestID),
+	}
+	if len(entry.Annotations) > 0 {
+		attrs = append(attrs, slog.Any("annotations", entry.Annotations))
+	}
+	s.Logger.LogAttrs(ctx, slog.LevelInfo, "audit", attrs...)
+	return nil
+}
+
+// Annotations of the Events recorded by EventSink, for filtering.
+const (
+	EventUserAnnotation      = "modarch.opendatahub.io/audit-user"
+	EventRequestIDAnnotation = "modarch.opendatahub.io/audit-request-id"
+)
+
+// EventReportingController is the reportingComponent of the Events recorded by EventSink.
+const EventReportingController = "modarch.opendatahub.io/audit"
+
+// EventSink records every entry as a Kubernetes Event about the Namespace, in Namespace:
+// Normal for successes, Warning otherwise, with the verb as reason. Events expire after the
+// event TTL of the API server (1h by default), so this suits operators more than compliance
+// archives. The BFF credentials need create on events in Namespace.
+type EventSink struct {
+	Client    kubernetes.Interface
+	Namespace string
+}
+
+func (s *EventSink) Record(ctx context.Context, entry Entry) error {
+	eventType := corev1.EventTypeNormal
+	if entry.Outcome != OutcomeSuccess {
+		eventType = corev1.EventTypeWarning
+	}
+	target := entry.Resource
+	if entry.Name != "" {
+		target += "/" + entry.Name
+	}
+	if entry.Namespace != "" {
+		target += " in " + entry.Namespace
+	}
+	now := metav1.NewTime(entry.Time)
+
+	message := fmt.Sprintf("%s %s %s: %d %s (%s %s, request %s)", entry.User, entry.Verb, target,
+		entry.Status, entry.Outcome, entry.Method, entry.Path, entry.RequestID)
+	if len(entry.Annotations) > 0 {
+		details := make([]string, 0, len(entry.Annotations))
+		for key, value := range entry.Annotations {
+			details = append(details, key+"="+value)
+		}
+		sort.Strings(details)
+		message += " " + strings.Join(details, " ")
+	}
+
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "mod-arch-audit-",
+			Namespace:    s.Namespace,
+			Annotations: map[string]string{
+				EventUserAnnotation:      entry.User,
+				EventRequestIDAnnotation: entry.RequestID,
+			},
+		},
+		InvolvedObject:      corev1.ObjectReference{APIVersion: "v1", Kind: "Namespace", Name: s.Namespace},
+		Type:                eventType,
+		Reason:              eventReason(entry.Verb),
+		Message:             message,
+		Source:              corev1.EventSource{Component: "mod-arch-bff"},
+		ReportingController: EventReportingController,
+		ReportingInstance:   "mod-arch-bff",
+		Action:              entry.Verb,
+		FirstTimestamp:      now,
+		LastTimestamp:       now,
+		Count:               1,
+	}
+	_, err := s.Client.CoreV1().Events(s.Namespace).Create(ctx, event, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("error creating audit event: %w", err)
+	}
+	return nil
+}
+
+// eventReason is the reason of the Events of verb, e.g. "AuditCreate".
+func eventReason(verb string) string {
+	if verb == "" {
+		return "Audit"
+	}
+	return "Audit" + strings.ToUpper(verb[:1]) + verb[1:]
+}
+
+// WebhookSink POSTs every entry as JSON to URL. Failed deliveries are not retried.
+type WebhookSink struct {
+	URL string
+	// Client defaults to an http.Client with a 10s timeout.
+	Client *http.Client
+}
+
+func (s *WebhookSink) Record(ctx context.Context, entry Entry) error {
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("error encoding audit entry: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating audit webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error calling audit webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("audit webhook responded %s", resp.Status)
+	}
+	return nil
+}
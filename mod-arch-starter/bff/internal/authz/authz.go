@@ -0,0 +1,152 @@
+// Package authz authorizes the API requests of a BFF once their identity is known, so platform
+// teams can enforce rules of their own centrally, e.g. namespace naming policies, on top of the
+// Kubernetes RBAC the handlers enforce. An Authorizer decides from the identity, the method, the
+// matched route and the namespace of a request; the built-in ones review the access with a
+// Kubernetes SubjectAccessReview, evaluate a static policy file, or ask an Open Policy Agent.
+package authz
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+)
+
+// Request describes an API request to an Authorizer.
+type Request struct {
+	Identity *k8s.RequestIdentity
+	Method   string
+	// Path is the request path, without the path prefix of the BFF.
+	Path string
+	// Route is the route pattern the request matched, e.g.
+	// /api/v1/namespaces/:namespace/secrets, or Path when it matched none.
+	Route string
+	// Namespace is the :namespace path parameter or the namespace query parameter, if any.
+	Namespace string
+}
+
+// Decision is the answer of an Authorizer.
+type Decision struct {
+	Allowed bool
+	// Reason explains a denial. It is logged, never returned to the client, so it may name
+	// the rules of the policy.
+	Reason string
+}
+
+// Allow allows a request.
+func Allow() Decision {
+	return Decision{Allowed: true}
+}
+
+// Deny denies a request for reason.
+func Deny(reason string) Decision {
+	return Decision{Reason: reason}
+}
+
+// Authorizer decides whether an API request may be served. An error, e.g. an unreachable policy
+// engine, fails the request: authorization fails closed.
+type Authorizer interface {
+	Authorize(ctx context.Context, req Request) (Decision, error)
+}
+
+// Func adapts a plain function to the Authorizer interface.
+type Func func(ctx context.Context, req Request) (Decision, error)
+
+func (f Func) Authorize(ctx context.Context, req Request) (Decision, error) {
+	return f(ctx, req)
+}
+
+// Chain allows the requests every authorizer allows, asking them in order until one denies.
+func Chain(authorizers ...Authorizer) Authorizer {
+	return Func(func(ctx context.Context, req Request) (Decision, error) {
+		for _, authorizer := range authorizers {
+			decision, err := authorizer.Authorize(ctx, req)
+			if err != nil || !decision.Allowed {
+				return decision, err
+			}
+		}
+		return Allow(), nil
+	})
+}
+
+// SubjectAccessReview authorizes requests with a SubjectAccessReview, so they are granted with
+// Kubernetes RBAC like the resources of the cluster: a route is a resource of Group named after
+// the literal segments of its pattern, past the API version and a leading
+// namespaces/:namespace, e.g. "secrets" for /api/v1/namespaces/:namespace/secrets and
+// "model_registry/registered_models" for
+// /api/v1/model_registry/:model_registry_id/registered_models. The verb follows the method:
+// get when the route ends with a parameter and list otherwise for the reads, then create,
+// update, patch and delete. Namespaced requests are reviewed in their namespace.
+//
+//	rules:
+//	  - apiGroups: [bff.opendatahub.io]
+//	    resources: [secrets]
+//	    verbs: [list, get]
+type SubjectAccessReview struct {
+	// Group is the API group of the routes, e.g. bff.opendatahub.io.
+	Group string
+	// Client returns the Kubernetes client of the request context.
+	Client func(ctx context.Context) (k8s.KubernetesClientInterface, error)
+}
+
+func (a SubjectAccessReview) Authorize(ctx context.Context, req Request) (Decision, error) {
+	if req.Identity == nil {
+		return Deny("no identity to review"), nil
+	}
+	client, err := a.Client(ctx)
+	if err != nil {
+		return Decision{}, fmt.Errorf("failed to get Kubernetes client: %w", err)
+	}
+	verb, resource := ResourceAttributes(req)
+	allowed, err := client.CanAccess(ctx, req.Identity, verb, a.Group, resource, req.Namespace)
+	if err != nil {
+		return Decision{}, err
+	}
+	if !allowed {
+		return Deny(fmt.Sprintf("user %s cannot %s %s.%s", req.Identity.UserID, verb, resource, a.Group)), nil
+	}
+	return Allow(), nil
+}
+
+// ResourceAttributes returns the verb and the resource the SubjectAccessReview authorizer
+// reviews for req.
+func ResourceAttributes(req Request) (verb, resource string) {
+	segments := strings.Split(strings.Trim(req.Route, "/"), "/")
+	if len(segments) >= 2 && segments[0] == "api" {
+		segments = segments[2:]
+	}
+	if len(segments) > 2 && segments[0] == "namespaces" && isParameter(segments[1]) {
+		segments = segments[2:]
+	}
+	var literals []string
+	for _, segment := range segments {
+		if segment != "" && !isParameter(segment) {
+			literals = append(literals, segment)
+		}
+	}
+	resource = strings.Join(literals, "/")
+
+	switch strings.ToUpper(req.Method) {
+	case http.MethodPost:
+		verb = "create"
+	case http.MethodPut:
+		verb = "update"
+	case http.MethodPatch:
+		verb = "patch"
+	case http.MethodDelete:
+		verb = "delete"
+	default:
+		verb = "list"
+		if len(segments) > 0 && isParameter(segments[len(segments)-1]) {
+			verb = "get"
+		}
+	}
+	return verb, resource
+}
+
+// isParameter reports whether a segment of a route pattern is a parameter (:name or *name).
+func isParameter(segment string) bool {
+	return strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*")
+}
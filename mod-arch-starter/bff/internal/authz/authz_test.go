@@ -0,0 +1,85 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes/k8mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceAttributes(t *testing.T) {
+	for _, tt := range []struct {
+		method, route  string
+		verb, resource string
+	}{
+		{http.MethodGet, "/api/v1/namespaces", "list", "namespaces"},
+		{http.MethodGet, "/api/v1/namespaces/:namespace", "get", "namespaces"},
+		{http.MethodGet, "/api/v1/namespaces/:namespace/secrets", "list", "secrets"},
+		{http.MethodDelete, "/api/v1/namespaces/:namespace/secrets/:name", "delete", "secrets"},
+		{http.MethodPost, "/api/v1/model_registry/:model_registry_id/registered_models", "create", "model_registry/registered_models"},
+		{http.MethodPatch, "/api/v2/user", "patch", "user"},
+		{http.MethodGet, "/api/v1/proxy/model-registry/*", "get", "proxy/model-registry"},
+	} {
+		verb, resource := ResourceAttributes(Request{Method: tt.method, Route: tt.route})
+		assert.Equal(t, tt.verb, verb, tt.route)
+		assert.Equal(t, tt.resource, resource, tt.route)
+	}
+}
+
+func TestChain(t *testing.T) {
+	var asked []string
+	authorizer := func(name string, decision Decision, err error) Authorizer {
+		return Func(func(context.Context, Request) (Decision, error) {
+			asked = append(asked, name)
+			return decision, err
+		})
+	}
+
+	decision, err := Chain(authorizer("a", Allow(), nil), authorizer("b", Allow(), nil)).Authorize(context.Background(), Request{})
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+
+	asked = nil
+	decision, err = Chain(authorizer("a", Deny("no"), nil), authorizer("b", Allow(), nil)).Authorize(context.Background(), Request{})
+	require.NoError(t, err)
+	assert.Equal(t, Deny("no"), decision)
+	assert.Equal(t, []string{"a"}, asked, "the first denial decides")
+
+	_, err = Chain(authorizer("a", Allow(), errors.New("unreachable"))).Authorize(context.Background(), Request{})
+	assert.Error(t, err)
+
+	decision, err = Chain().Authorize(context.Background(), Request{})
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+}
+
+func TestSubjectAccessReview(t *testing.T) {
+	client := k8mocks.NewMockKubernetesClient(k8mocks.DefaultMockFixtures(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	authorizer := SubjectAccessReview{Group: "bff.opendatahub.io", Client: func(context.Context) (k8s.KubernetesClientInterface, error) {
+		return client, nil
+	}}
+	dora := &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com"}
+
+	decision, err := authorizer.Authorize(context.Background(), Request{Identity: dora, Method: http.MethodGet, Route: "/api/v1/namespaces/:namespace/secrets", Namespace: "dora-namespace"})
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+
+	decision, err = authorizer.Authorize(context.Background(), Request{Identity: dora, Method: http.MethodGet, Route: "/api/v1/namespaces/:namespace/secrets", Namespace: "bella-namespace"})
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+	assert.Contains(t, decision.Reason, "cannot list secrets.bff.opendatahub.io")
+
+	decision, err = authorizer.Authorize(context.Background(), Request{Method: http.MethodGet, Route: "/api/v1/user"})
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed, "requests without identity are denied")
+
+	_, err = authorizer.Authorize(context.Background(), Request{Identity: &k8s.RequestIdentity{UserID: "unknown@example.com"}, Method: http.MethodGet, Route: "/api/v1/user"})
+	assert.Error(t, err)
+}
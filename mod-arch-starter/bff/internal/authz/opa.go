@@ -0,0 +1,108 @@
+package authz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DefaultOPATimeout bounds the decisions of an Open Policy Agent.
+const DefaultOPATimeout = 5 * time.Second
+
+// OPA authorizes requests with an Open Policy Agent, usually a sidecar, through its Data API:
+// the request is posted as the input of the decision at URL, e.g.
+// http://localhost:8181/v1/data/bff/authz/allow. The result is a boolean, or an object with an
+// allow boolean and an optional reason; an undefined result denies the request. The input
+// carries the identity without its token:
+//
+//	{"input": {"user": "alice@example.com", "groups": ["team-a"], "method": "GET",
+//	  "path": "/api/v1/namespaces/team-a/secrets", "route": "/api/v1/namespaces/:namespace/secrets",
+//	  "namespace": "team-a"}}
+//
+// A Rego policy denying the namespaces outside the team-* naming scheme:
+//
+//	package bff.authz
+//	default allow := false
+//	allow if not input.namespace
+//	allow if startswith(input.namespace, "team-")
+type OPA struct {
+	URL string
+	// Client defaults to a client timing out after DefaultOPATimeout.
+	Client *http.Client
+}
+
+// opaInput is the input of a decision.
+type opaInput struct {
+	User      string   `json:"user,omitempty"`
+	Groups    []string `json:"groups,omitempty"`
+	Guest     bool     `json:"guest,omitempty"`
+	Method    string   `json:"method"`
+	Path      string   `json:"path"`
+	Route     string   `json:"route"`
+	Namespace string   `json:"namespace,omitempty"`
+}
+
+func (a OPA) Authorize(ctx context.Context, req Request) (Decision, error) {
+	input := opaInput{Method: req.Method, Path: req.Path, Route: req.Route, Namespace: req.Namespace}
+	if req.Identity != nil {
+		input.User, input.Groups, input.Guest = req.Identity.UserID, req.Identity.Groups, req.Identity.Guest
+	}
+	body, err := json.Marshal(map[string]any{"input": input})
+	if err != nil {
+		return Decision{}, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, a.URL, bytes.NewReader(body))
+	if err != nil {
+		return Decision{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	client := a.Client
+	if client == nil {
+		client = &http.Client{Timeout: DefaultOPATimeout}
+	}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return Decision{}, fmt.Errorf("failed to query the policy agent: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return Decision{}, fmt.Errorf("the policy agent answered %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+
+	var answer struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&answer); err != nil {
+		return Decision{}, fmt.Errorf("invalid answer of the policy agent: %w", err)
+	}
+	if len(answer.Result) == 0 || string(answer.Result) == "null" {
+		return Deny("the authorization policy doesn't define a decision"), nil
+	}
+	var allowed bool
+	if err := json.Unmarshal(answer.Result, &allowed); err == nil {
+		if !allowed {
+			return Deny("denied by the authorization policy"), nil
+		}
+		return Allow(), nil
+	}
+	var result struct {
+		Allow  bool   `json:"allow"`
+		Reason string `json:"reason"`
+	}
+	if err := json.Unmarshal(answer.Result, &result); err != nil {
+		return Decision{}, fmt.Errorf("invalid decision of the policy agent, want a boolean or {allow, reason}: %s", answer.Result)
+	}
+	if !result.Allow {
+		if result.Reason == "" {
+			result.Reason = "denied by the authorization policy"
+		}
+		return Deny(result.Reason), nil
+	}
+	return Allow(), nil
+}
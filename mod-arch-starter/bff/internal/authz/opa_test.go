@@ -0,0 +1,67 @@
+package authz
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOPA(t *testing.T) {
+	var input map[string]any
+	result := `{"result": true}`
+	status := http.StatusOK
+	agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Input map[string]any `json:"input"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		input = body.Input
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(result))
+	}))
+	defer agent.Close()
+
+	authorizer := OPA{URL: agent.URL + "/v1/data/bff/authz"}
+	req := Request{
+		Identity: &k8s.RequestIdentity{UserID: "alice@example.com", Groups: []string{"team-a"}, Token: "secret"},
+		Method:   http.MethodGet, Path: "/api/v1/namespaces/team-a/secrets", Route: "/api/v1/namespaces/:namespace/secrets", Namespace: "team-a",
+	}
+
+	decision, err := authorizer.Authorize(context.Background(), req)
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, map[string]any{
+		"user": "alice@example.com", "groups": []any{"team-a"}, "method": "GET",
+		"path": "/api/v1/namespaces/team-a/secrets", "route": "/api/v1/namespaces/:namespace/secrets", "namespace": "team-a",
+	}, input, "the token is never sent")
+
+	for answer, want := range map[string]Decision{
+		`{"result": false}`:                                     Deny("denied by the authorization policy"),
+		`{"result": {"allow": true}}`:                           Allow(),
+		`{"result": {"allow": false, "reason": "team-* only"}}`: Deny("team-* only"),
+		`{}`: Deny("the authorization policy doesn't define a decision"),
+	} {
+		result = answer
+		decision, err := authorizer.Authorize(context.Background(), req)
+		require.NoError(t, err, answer)
+		assert.Equal(t, want, decision, answer)
+	}
+
+	result = `{"result": "yes"}`
+	_, err = authorizer.Authorize(context.Background(), req)
+	assert.ErrorContains(t, err, "invalid decision")
+
+	status, result = http.StatusInternalServerError, `{"code": "internal_error"}`
+	_, err = authorizer.Authorize(context.Background(), req)
+	assert.ErrorContains(t, err, "answered 500")
+
+	agent.Close()
+	_, err = authorizer.Authorize(context.Background(), req)
+	assert.ErrorContains(t, err, "failed to query the policy agent")
+}
@@ -0,0 +1,203 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Effects of the rules of a Policy.
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+// Policy is the YAML or JSON policy file of PolicyAuthorizer, e.g. mounted from a ConfigMap.
+// Its rules are evaluated in order, and the first rule matching a request decides; requests no
+// rule matches get Default (allow when empty):
+//
+//	rules:
+//	  - name: read-only-system-namespaces
+//	    effect: deny
+//	    methods: [POST, PUT, PATCH, DELETE]
+//	    namespaces: [kube-*, openshift-*]
+//	  - name: team-namespaces
+//	    effect: allow
+//	    namespaces: [team-*]
+//	  - name: other-namespaces
+//	    effect: deny
+//	    namespaces: ["*"]
+//	    reason: only the team-* namespaces are served
+type Policy struct {
+	Default string `json:"default,omitempty"`
+	Rules   []Rule `json:"rules"`
+}
+
+// Rule allows or denies the requests it matches. A request matches when it meets every
+// condition set: its method is one of Methods, its route or path matches one of Routes, its
+// namespace matches one of Namespaces (requests without namespace never do), its user is one of
+// Users and one of its groups is in Groups. Routes and Namespaces are patterns where "*"
+// matches any characters, e.g. /api/v1/namespaces/:namespace/* or team-*. A rule without
+// conditions matches every request.
+type Rule struct {
+	Name       string   `json:"name,omitempty"`
+	Effect     string   `json:"effect"`
+	Methods    []string `json:"methods,omitempty"`
+	Routes     []string `json:"routes,omitempty"`
+	Namespaces []string `json:"namespaces,omitempty"`
+	Users      []string `json:"users,omitempty"`
+	Groups     []string `json:"groups,omitempty"`
+	// Reason is logged with the denied requests (default: the name of the rule).
+	Reason string `json:"reason,omitempty"`
+}
+
+// LoadFile reads and validates the policy of the file at path.
+func LoadFile(path string) (Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Policy{}, fmt.Errorf("failed to read authorization policy: %w", err)
+	}
+	var policy Policy
+	if err := yaml.UnmarshalStrict(data, &policy); err != nil {
+		return Policy{}, fmt.Errorf("failed to parse authorization policy %s: %w", path, err)
+	}
+	if err := Validate(policy); err != nil {
+		return Policy{}, fmt.Errorf("invalid authorization policy %s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// Validate reports the invalid default and effects, and the unknown methods of policy.
+func Validate(policy Policy) error {
+	var problems []error
+	if policy.Default != "" && policy.Default != EffectAllow && policy.Default != EffectDeny {
+		problems = append(problems, fmt.Errorf("default: %q is not valid (must be allow or deny)", policy.Default))
+	}
+	for i, rule := range policy.Rules {
+		name := fmt.Sprintf("rule %d", i+1)
+		if rule.Name != "" {
+			name = fmt.Sprintf("rule %q", rule.Name)
+		}
+		if rule.Effect != EffectAllow && rule.Effect != EffectDeny {
+			problems = append(problems, fmt.Errorf("%s: effect %q is not valid (must be allow or deny)", name, rule.Effect))
+		}
+		for _, method := range rule.Methods {
+			if !slices.Contains(methods, strings.ToUpper(method)) {
+				problems = append(problems, fmt.Errorf("%s: %q is not an HTTP method", name, method))
+			}
+		}
+	}
+	return errors.Join(problems...)
+}
+
+var methods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions}
+
+// PolicyAuthorizer authorizes requests with a Policy. It is safe for concurrent use, and its
+// policy can be replaced at runtime.
+type PolicyAuthorizer struct {
+	mu     sync.RWMutex
+	policy Policy
+}
+
+// NewPolicyAuthorizer returns the authorizer of a valid policy.
+func NewPolicyAuthorizer(policy Policy) (*PolicyAuthorizer, error) {
+	a := &PolicyAuthorizer{}
+	if err := a.Replace(policy); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Replace validates policy and, when it is valid, replaces the policy of the authorizer.
+func (a *PolicyAuthorizer) Replace(policy Policy) error {
+	if err := Validate(policy); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.policy = policy
+	return nil
+}
+
+func (a *PolicyAuthorizer) Authorize(_ context.Context, req Request) (Decision, error) {
+	a.mu.RLock()
+	policy := a.policy
+	a.mu.RUnlock()
+	return policy.Evaluate(req), nil
+}
+
+// Evaluate returns the decision of the first rule of the policy matching req, or of Default.
+func (p Policy) Evaluate(req Request) Decision {
+	for i, rule := range p.Rules {
+		if !rule.matches(req) {
+			continue
+		}
+		if rule.Effect == EffectAllow {
+			return Allow()
+		}
+		reason := rule.Reason
+		if reason == "" {
+			reason = rule.Name
+		}
+		if reason == "" {
+			reason = fmt.Sprintf("denied by rule %d of the authorization policy", i+1)
+		}
+		return Deny(reason)
+	}
+	if p.Default == EffectDeny {
+		return Deny("no rule of the authorization policy allows the request")
+	}
+	return Allow()
+}
+
+func (r Rule) matches(req Request) bool {
+	if len(r.Methods) > 0 && !slices.ContainsFunc(r.Methods, func(method string) bool { return strings.EqualFold(method, req.Method) }) {
+		return false
+	}
+	if len(r.Routes) > 0 && !slices.ContainsFunc(r.Routes, func(pattern string) bool { return match(pattern, req.Route) || match(pattern, req.Path) }) {
+		return false
+	}
+	if len(r.Namespaces) > 0 && (req.Namespace == "" || !slices.ContainsFunc(r.Namespaces, func(pattern string) bool { return match(pattern, req.Namespace) })) {
+		return false
+	}
+	var user string
+	var groups []string
+	if req.Identity != nil {
+		user, groups = req.Identity.UserID, req.Identity.Groups
+	}
+	if len(r.Users) > 0 && !slices.Contains(r.Users, user) {
+		return false
+	}
+	if len(r.Groups) > 0 && !slices.ContainsFunc(groups, func(group string) bool { return slices.Contains(r.Groups, group) }) {
+		return false
+	}
+	return true
+}
+
+// match reports whether value matches pattern, where "*" matches any characters, slashes
+// included.
+func match(pattern, value string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == value
+	}
+	if !strings.HasPrefix(value, parts[0]) {
+		return false
+	}
+	value = value[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(value, part)
+		if i < 0 {
+			return false
+		}
+		value = value[i+len(part):]
+	}
+	return strings.HasSuffix(value, parts[len(parts)-1])
+}
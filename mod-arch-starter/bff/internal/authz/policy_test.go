@@ -0,0 +1,88 @@
+package authz
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicy = `
+rules:
+  - name: read-only-system-namespaces
+    effect: deny
+    methods: [post, PUT, PATCH, DELETE]
+    namespaces: [kube-*, openshift-*]
+  - effect: allow
+    groups: [platform-admins]
+  - name: team-namespaces
+    effect: allow
+    namespaces: [team-*]
+  - effect: deny
+    namespaces: ["*"]
+    reason: only the team-* namespaces are served
+  - effect: deny
+    routes: [/api/v1/namespaces/:namespace/*, /api/v1/debug/*]
+`
+
+func TestPolicyEvaluate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testPolicy), 0o600))
+	policy, err := LoadFile(path)
+	require.NoError(t, err)
+	authorizer, err := NewPolicyAuthorizer(policy)
+	require.NoError(t, err)
+
+	alice := &k8s.RequestIdentity{UserID: "alice@example.com"}
+	admin := &k8s.RequestIdentity{UserID: "root@example.com", Groups: []string{"platform-admins"}}
+	for _, tt := range []struct {
+		req    Request
+		reason string
+	}{
+		{Request{Identity: alice, Method: http.MethodGet, Path: "/api/v1/namespaces/team-a/secrets", Route: "/api/v1/namespaces/:namespace/secrets", Namespace: "team-a"}, ""},
+		{Request{Identity: alice, Method: http.MethodGet, Path: "/api/v1/user", Route: "/api/v1/user"}, ""},
+		{Request{Identity: alice, Method: http.MethodPost, Path: "/api/v1/namespaces/kube-system/secrets", Route: "/api/v1/namespaces/:namespace/secrets", Namespace: "kube-system"}, "read-only-system-namespaces"},
+		{Request{Identity: admin, Method: http.MethodDelete, Path: "/api/v1/namespaces/openshift-config/secrets", Route: "/api/v1/namespaces/:namespace/secrets", Namespace: "openshift-config"}, "read-only-system-namespaces"},
+		{Request{Identity: admin, Method: http.MethodGet, Path: "/api/v1/secrets", Route: "/api/v1/secrets", Namespace: "prod"}, ""},
+		{Request{Identity: alice, Method: http.MethodGet, Path: "/api/v1/secrets", Route: "/api/v1/secrets", Namespace: "prod"}, "only the team-* namespaces are served"},
+		{Request{Identity: alice, Method: http.MethodGet, Path: "/api/v1/debug/preflight", Route: "/api/v1/debug/preflight"}, "denied by rule 5 of the authorization policy"},
+	} {
+		decision, err := authorizer.Authorize(context.Background(), tt.req)
+		require.NoError(t, err)
+		assert.Equal(t, tt.reason == "", decision.Allowed, tt.req.Path)
+		assert.Equal(t, tt.reason, decision.Reason, tt.req.Path)
+	}
+
+	// Replaced policies apply to the next requests, invalid ones are refused
+	require.NoError(t, authorizer.Replace(Policy{Default: EffectDeny}))
+	decision, err := authorizer.Authorize(context.Background(), Request{Identity: alice, Method: http.MethodGet, Route: "/api/v1/user"})
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+	assert.Error(t, authorizer.Replace(Policy{Rules: []Rule{{Effect: "permit"}}}))
+}
+
+func TestValidatePolicy(t *testing.T) {
+	err := Validate(Policy{Default: "maybe", Rules: []Rule{{Effect: EffectAllow}, {Name: "bogus", Effect: "permit", Methods: []string{"GET", "FETCH"}}}})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `default: "maybe" is not valid`)
+	assert.ErrorContains(t, err, `rule "bogus": effect "permit" is not valid`)
+	assert.ErrorContains(t, err, `rule "bogus": "FETCH" is not an HTTP method`)
+
+	_, err = LoadFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestMatch(t *testing.T) {
+	assert.True(t, match("team-*", "team-a"))
+	assert.True(t, match("*", ""))
+	assert.True(t, match("/api/v1/namespaces/:namespace/*", "/api/v1/namespaces/:namespace/secrets/:name"))
+	assert.True(t, match("a*b*c", "abxbc"))
+	assert.False(t, match("team-*", "teams"))
+	assert.False(t, match("a*a", "a"))
+	assert.False(t, match("team-a", "team-ab"))
+}
@@ -0,0 +1,196 @@
+// Package cache is a response cache repositories can opt into. Entries are keyed by resource,
+// namespace and the requesting identity, so one user never sees a response computed for
+// another, expire after a TTL and can be invalidated by Kubernetes watch events (see
+// EventHandler). Storage is pluggable: NewMemoryBackend is the default, a shared backend
+// such as Redis can implement Backend.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+)
+
+const (
+	// DefaultTTL is the lifetime of an entry when Options.TTL is not set.
+	DefaultTTL = 30 * time.Second
+	// DefaultMaxEntries bounds the in-memory backend when Options.MaxEntries is not set.
+	DefaultMaxEntries = 1000
+)
+
+// Backend stores encoded entries. Tags group entries so they can be invalidated together,
+// e.g. every cached listing of services in a namespace. Implementations must be safe for
+// concurrent use.
+type Backend interface {
+	// Get returns the value of key, or false if it is missing or expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key for ttl, tagged with tags.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags []string) error
+	// Invalidate removes every entry tagged with any of tags.
+	Invalidate(ctx context.Context, tags ...string) error
+}
+
+// Options configures New. Zero values use the defaults.
+type Options struct {
+	// TTL defaults to DefaultTTL.
+	TTL time.Duration
+	// MaxEntries bounds the default in-memory backend (DefaultMaxEntries). Ignored with Backend.
+	MaxEntries int
+	// Backend defaults to NewMemoryBackend(MaxEntries).
+	Backend Backend
+	Logger  *slog.Logger
+}
+
+// Cache caches the responses of repositories. A nil *Cache is valid and caches nothing, so
+// repositories can call Get unconditionally.
+type Cache struct {
+	backend Backend
+	ttl     time.Duration
+	logger  *slog.Logger
+}
+
+func New(opts Options) *Cache {
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultTTL
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = DefaultMaxEntries
+	}
+	if opts.Backend == nil {
+		opts.Backend = NewMemoryBackend(opts.MaxEntries)
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	return &Cache{backend: opts.Backend, ttl: opts.TTL, logger: opts.Logger}
+}
+
+// Prune drops the expired entries of backends keeping them until read, like MemoryBackend,
+// and returns how many there were. Backends expiring entries themselves have nothing to prune.
+func (c *Cache) Prune() int {
+	if c == nil {
+		return 0
+	}
+	if pruner, ok := c.backend.(interface{ Prune() int }); ok {
+		return pruner.Prune()
+	}
+	return 0
+}
+
+// Key identifies a cached response. Resource and Namespace drive invalidation; Params holds
+// the remaining inputs of the response (e.g. a label selector).
+type Key struct {
+	// Resource is the Kubernetes resource the response is built from, e.g. "services".
+	Resource string
+	// Namespace is empty for cluster-wide responses.
+	Namespace string
+	// Identity is the requesting identity. Responses are RBAC-filtered, so it is always part
+	// of the key; a nil identity is a response shared by every user.
+	Identity *k8s.RequestIdentity
+	Params   []string
+	// Cluster is the cluster the response is built from, "" for the local one. Get defaults
+	// it to the cluster of its context (see kubernetes.WithCluster).
+	Cluster string
+}
+
+// String returns the storage key. The identity is hashed so tokens never end up in a
+// shared backend.
+func (k Key) String() string {
+	parts := []string{k.Resource, k.Namespace, identityHash(k.Identity)}
+	if k.Cluster != "" {
+		parts[0] = k.Cluster + "/" + k.Resource
+	}
+	parts = append(parts, k.Params...)
+	return strings.Join(parts, "|")
+}
+
+// Tags returns the invalidation tags of the key: the resource, and the resource in its namespace.
+func (k Key) Tags() []string {
+	return []string{ResourceTag(k.Resource), NamespaceTag(k.Resource, k.Namespace)}
+}
+
+// ResourceTag tags every entry built from resource.
+func ResourceTag(resource string) string {
+	return resource
+}
+
+// NamespaceTag tags the entries built from resource in namespace ("" for cluster-wide ones).
+func NamespaceTag(resource, namespace string) string {
+	return resource + "/" + namespace
+}
+
+func identityHash(identity *k8s.RequestIdentity) string {
+	if identity == nil {
+		return ""
+	}
+	groups := slices.Clone(identity.Groups)
+	slices.Sort(groups)
+	sum := sha256.Sum256([]byte(identity.UserID + "\x00" + strings.Join(groups, ",") + "\x00" + identity.Token))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Get returns the cached value of key, or calls load and caches its result. Errors are never
+// cached, and a failing backend only means the value is loaded.
+func Get[T any](ctx context.Context, c *Cache, key Key, load func(ctx context.Context) (T, error)) (T, error) {
+	if c == nil {
+		return load(ctx)
+	}
+
+	if key.Cluster == "" {
+		key.Cluster = k8s.ClusterFromContext(ctx)
+	}
+	storageKey := key.String()
+	if data, ok, err := c.backend.Get(ctx, storageKey); err != nil {
+		c.logger.Warn("response cache read failed", "resource", key.Resource, "error", err)
+	} else if ok {
+		var value T
+		if err := json.Unmarshal(data, &value); err == nil {
+			return value, nil
+		}
+	}
+
+	value, err := load(ctx)
+	if err != nil {
+		return value, err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value, nil
+	}
+	if err := c.backend.Set(ctx, storageKey, data, c.ttl, key.Tags()); err != nil {
+		c.logger.Warn("response cache write failed", "resource", key.Resource, "error", err)
+	}
+	return value, nil
+}
+
+// Invalidate removes the entries built from resource in namespace, and the cluster-wide ones
+// that include it.
+func (c *Cache) Invalidate(ctx context.Context, resource, namespace string) {
+	if c == nil {
+		return
+	}
+	tags := []string{NamespaceTag(resource, namespace)}
+	if namespace != "" {
+		tags = append(tags, NamespaceTag(resource, ""))
+	}
+	if err := c.backend.Invalidate(ctx, tags...); err != nil {
+		c.logger.Warn("response cache invalidation failed", "resource", resource, "namespace", namespace, "error", err)
+	}
+}
+
+// InvalidateResource removes every entry built from resource.
+func (c *Cache) InvalidateResource(ctx context.Context, resource string) {
+	if c == nil {
+		return
+	}
+	if err := c.backend.Invalidate(ctx, ResourceTag(resource)); err != nil {
+		c.logger.Warn("response cache invalidation failed", "resource", resource, "error", err)
+	}
+}
@@ -0,0 +1,152 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+)
+
+func counter(calls *int, value []string) func(context.Context) ([]string, error) {
+	return func(context.Context) ([]string, error) {
+		*calls++
+		return value, nil
+	}
+}
+
+func TestGet_CachesPerIdentity(t *testing.T) {
+	c := New(Options{TTL: time.Minute})
+	ctx := context.Background()
+	alice := Key{Resource: "services", Namespace: "team-a", Identity: &k8s.RequestIdentity{UserID: "alice"}}
+	bob := Key{Resource: "services", Namespace: "team-a", Identity: &k8s.RequestIdentity{UserID: "bob"}}
+
+	calls := 0
+	value, err := Get(ctx, c, alice, counter(&calls, []string{"svc-a"}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"svc-a"}, value)
+	value, err = Get(ctx, c, alice, counter(&calls, []string{"other"}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"svc-a"}, value, "served from the cache")
+	assert.Equal(t, 1, calls)
+
+	// Another identity never gets alice's response
+	value, err = Get(ctx, c, bob, counter(&calls, []string{"svc-b"}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"svc-b"}, value)
+	assert.Equal(t, 2, calls)
+
+	// Nor does another cluster
+	value, err = Get(k8s.WithCluster(ctx, "spoke"), c, alice, counter(&calls, []string{"svc-spoke"}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"svc-spoke"}, value)
+	assert.Equal(t, 3, calls)
+}
+
+func TestGet_DoesNotCacheErrors(t *testing.T) {
+	c := New(Options{})
+	key := Key{Resource: "services", Namespace: "team-a"}
+	_, err := Get(context.Background(), c, key, func(context.Context) (int, error) { return 0, errors.New("boom") })
+	assert.Error(t, err)
+
+	calls := 0
+	_, err = Get(context.Background(), c, key, func(context.Context) (int, error) { calls++; return 1, nil })
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestGet_NilCache(t *testing.T) {
+	calls := 0
+	for i := 0; i < 2; i++ {
+		_, err := Get(context.Background(), nil, Key{}, counter(&calls, nil))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, calls)
+}
+
+func TestKey(t *testing.T) {
+	token := Key{Resource: "services", Identity: &k8s.RequestIdentity{Token: "secret-token"}}
+	assert.NotContains(t, token.String(), "secret-token")
+
+	groupsA := Key{Identity: &k8s.RequestIdentity{UserID: "alice", Groups: []string{"a", "b"}}}
+	groupsB := Key{Identity: &k8s.RequestIdentity{UserID: "alice", Groups: []string{"b", "a"}}}
+	groupsC := Key{Identity: &k8s.RequestIdentity{UserID: "alice", Groups: []string{"a"}}}
+	assert.Equal(t, groupsA.String(), groupsB.String())
+	assert.NotEqual(t, groupsA.String(), groupsC.String())
+}
+
+func TestMemoryBackend_TTLAndEviction(t *testing.T) {
+	b := NewMemoryBackend(2)
+	now := time.Unix(1_700_000_000, 0)
+	b.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	require.NoError(t, b.Set(ctx, "a", []byte("1"), time.Minute, nil))
+	require.NoError(t, b.Set(ctx, "b", []byte("2"), time.Minute, nil))
+	_, ok, _ := b.Get(ctx, "a") // a is now the most recently used
+	assert.True(t, ok)
+	require.NoError(t, b.Set(ctx, "c", []byte("3"), time.Minute, nil))
+
+	_, ok, _ = b.Get(ctx, "b")
+	assert.False(t, ok, "least recently used entry is evicted")
+	assert.Equal(t, 2, b.Len())
+
+	now = now.Add(time.Minute)
+	_, ok, _ = b.Get(ctx, "a")
+	assert.False(t, ok, "expired")
+}
+
+func TestMemoryBackend_Prune(t *testing.T) {
+	b := NewMemoryBackend(10)
+	now := time.Unix(1_700_000_000, 0)
+	b.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	require.NoError(t, b.Set(ctx, "short", []byte("1"), time.Second, []string{"tag"}))
+	require.NoError(t, b.Set(ctx, "long", []byte("2"), time.Hour, []string{"tag"}))
+	now = now.Add(time.Minute)
+
+	c := New(Options{Backend: b})
+	assert.Equal(t, 1, c.Prune())
+	assert.Equal(t, 1, b.Len())
+	assert.NotContains(t, b.tags["tag"], "short", "the tag index is pruned too")
+	assert.Zero(t, (*Cache)(nil).Prune())
+}
+
+func TestEventHandler_Invalidates(t *testing.T) {
+	c := New(Options{TTL: time.Hour})
+	ctx := context.Background()
+	teamA := Key{Resource: "services", Namespace: "team-a"}
+	teamB := Key{Resource: "services", Namespace: "team-b"}
+	all := Key{Resource: "services"}
+	calls := 0
+	load := counter(&calls, []string{"x"})
+	for _, key := range []Key{teamA, teamB, all} {
+		_, err := Get(ctx, c, key, load)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 3, calls)
+
+	handler := c.EventHandler("services")
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: "team-a", ResourceVersion: "1"}}
+
+	// A resync of an unchanged object keeps the entries
+	handler.OnUpdate(svc, svc)
+	for _, key := range []Key{teamA, teamB, all} {
+		_, _ = Get(ctx, c, key, load)
+	}
+	assert.Equal(t, 3, calls)
+
+	// A deletion in team-a invalidates team-a and the all-namespaces listing, not team-b
+	handler.OnDelete(toolscache.DeletedFinalStateUnknown{Key: "team-a/registry", Obj: svc})
+	for _, key := range []Key{teamA, teamB, all} {
+		_, _ = Get(ctx, c, key, load)
+	}
+	assert.Equal(t, 5, calls)
+}
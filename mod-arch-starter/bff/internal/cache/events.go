@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	toolscache "k8s.io/client-go/tools/cache"
+)
+
+// EventHandler returns an informer event handler invalidating the entries built from resource
+// whenever one of its objects is added, updated or deleted, so cached responses don't wait for
+// their TTL after a change. Register it on an informer of that resource, e.g. with
+// k8s.ResourceCache.AddEventHandler.
+func (c *Cache) EventHandler(resource string) toolscache.ResourceEventHandler {
+	invalidate := func(obj any) {
+		if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			c.InvalidateResource(context.Background(), resource)
+			return
+		}
+		c.Invalidate(context.Background(), resource, accessor.GetNamespace())
+	}
+
+	return toolscache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj any) { invalidate(obj) },
+		UpdateFunc: func(old, obj any) {
+			// Periodic resyncs redeliver unchanged objects
+			oldAccessor, oldErr := meta.Accessor(old)
+			accessor, err := meta.Accessor(obj)
+			if oldErr == nil && err == nil && oldAccessor.GetResourceVersion() == accessor.GetResourceVersion() {
+				return
+			}
+			invalidate(obj)
+		},
+		DeleteFunc: invalidate,
+	}
+}
@@ -0,0 +1,135 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryBackend is an in-process LRU Backend: the least recently used entry is evicted once
+// it holds maxEntries, and expired entries are dropped when read. Each BFF replica has its
+// own, so invalidations are local to the replica.
+type MemoryBackend struct {
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	lru     *list.List // of *memoryEntry, most recently used first
+	entries map[string]*list.Element
+	tags    map[string]map[string]struct{}
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+	tags    []string
+}
+
+var _ Backend = (*MemoryBackend)(nil)
+
+func NewMemoryBackend(maxEntries int) *MemoryBackend {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &MemoryBackend{
+		maxEntries: maxEntries,
+		now:        time.Now,
+		lru:        list.New(),
+		entries:    map[string]*list.Element{},
+		tags:       map[string]map[string]struct{}{},
+	}
+}
+
+func (b *MemoryBackend) Get(_ context.Context, key string) ([]byte, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	elem, ok := b.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := elem.Value.(*memoryEntry)
+	if !b.now().Before(entry.expires) {
+		b.remove(elem)
+		return nil, false, nil
+	}
+	b.lru.MoveToFront(elem)
+	return entry.value, true, nil
+}
+
+func (b *MemoryBackend) Set(_ context.Context, key string, value []byte, ttl time.Duration, tags []string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if elem, ok := b.entries[key]; ok {
+		b.remove(elem)
+	}
+	entry := &memoryEntry{key: key, value: value, expires: b.now().Add(ttl), tags: tags}
+	b.entries[key] = b.lru.PushFront(entry)
+	for _, tag := range tags {
+		if b.tags[tag] == nil {
+			b.tags[tag] = map[string]struct{}{}
+		}
+		b.tags[tag][key] = struct{}{}
+	}
+
+	for b.lru.Len() > b.maxEntries {
+		b.remove(b.lru.Back())
+	}
+	return nil
+}
+
+func (b *MemoryBackend) Invalidate(_ context.Context, tags ...string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range b.tags[tag] {
+			if elem, ok := b.entries[key]; ok {
+				b.remove(elem)
+			}
+		}
+	}
+	return nil
+}
+
+// Len returns the number of stored entries, including expired ones not read since.
+func (b *MemoryBackend) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lru.Len()
+}
+
+// Prune drops the expired entries, which are otherwise only dropped when read or evicted, and
+// returns how many there were.
+func (b *MemoryBackend) Prune() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	pruned := 0
+	for elem := b.lru.Back(); elem != nil; {
+		prev := elem.Prev()
+		if !now.Before(elem.Value.(*memoryEntry).expires) {
+			b.remove(elem)
+			pruned++
+		}
+		elem = prev
+	}
+	return pruned
+}
+
+// remove drops elem and its tag index entries. b.mu must be held.
+func (b *MemoryBackend) remove(elem *list.Element) {
+	entry := elem.Value.(*memoryEntry)
+	b.lru.Remove(elem)
+	delete(b.entries, entry.key)
+	for _, tag := range entry.tags {
+		delete(b.tags[tag], entry.key)
+		if len(b.tags[tag]) == 0 {
+			delete(b.tags, tag)
+		}
+	}
+}
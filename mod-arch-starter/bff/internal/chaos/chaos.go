@@ -0,0 +1,240 @@
+// Package chaos injects faults into the BFF, in development, so teams check how the frontend
+// handles a slow or failing backend: latency, error responses and dropped connections, on the
+// requests of routes or on the calls to upstreams (the Kubernetes API server, model
+// registries, ...). An Injector holds the rules, changed at runtime:
+//
+//	faults := chaos.New()
+//	faults.SetRules([]chaos.Rule{
+//		{Route: "/api/v1/namespaces", Latency: 2 * time.Second},
+//		{Upstream: "kubernetes", ErrorRate: 0.2, ErrorStatus: http.StatusServiceUnavailable},
+//	})
+//
+// The API middleware applies the faults of Route; upstream clients apply those of their
+// upstream with WrapTransportFor.
+package chaos
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultErrorStatus is the status of the injected errors when Rule.ErrorStatus is not set.
+const DefaultErrorStatus = http.StatusServiceUnavailable
+
+// ErrDropped is returned by the upstream calls whose connection is dropped.
+var ErrDropped = errors.New("chaos: connection dropped")
+
+// Rule injects faults into the requests of a route, or into the calls to an upstream.
+type Rule struct {
+	// Route is a route pattern, e.g. "/api/v1/secrets/:name", a prefix of patterns ending
+	// with "*", e.g. "/api/v1/model_registry/*", or "*" for every route.
+	Route string
+	// Upstream is the name of an upstream: "kubernetes" (or "kubernetes/<cluster>"), the host
+	// of an upstream service, or "*" for every upstream.
+	Upstream string
+	// Latency delays the requests.
+	Latency time.Duration
+	// ErrorRate is the fraction of the requests answered with ErrorStatus, from 0 to 1.
+	ErrorRate float64
+	// ErrorStatus defaults to DefaultErrorStatus.
+	ErrorStatus int
+	// DropRate is the fraction of the requests whose connection is closed without a response,
+	// from 0 to 1.
+	DropRate float64
+}
+
+// Validate checks that the rule targets exactly one route or upstream and injects a fault.
+func (r Rule) Validate() error {
+	switch {
+	case (r.Route == "") == (r.Upstream == ""):
+		return errors.New("either route or upstream is required")
+	case r.Route != "" && r.Route != "*" && !strings.HasPrefix(r.Route, "/"):
+		return fmt.Errorf("route %q must be a route pattern, a prefix ending with * or *", r.Route)
+	case r.Latency < 0:
+		return errors.New("latency must not be negative")
+	case r.ErrorRate < 0 || r.ErrorRate > 1:
+		return fmt.Errorf("errorRate must be between 0 and 1, got %v", r.ErrorRate)
+	case r.DropRate < 0 || r.DropRate > 1:
+		return fmt.Errorf("dropRate must be between 0 and 1, got %v", r.DropRate)
+	case r.ErrorStatus != 0 && (r.ErrorStatus < 400 || r.ErrorStatus > 599):
+		return fmt.Errorf("errorStatus %d is not an HTTP error status", r.ErrorStatus)
+	case r.Latency == 0 && r.ErrorRate == 0 && r.DropRate == 0:
+		return errors.New("latency, errorRate or dropRate is required")
+	}
+	return nil
+}
+
+func (r Rule) matchesRoute(pattern string) bool {
+	if r.Route == "" || pattern == "" {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(r.Route, "*"); ok {
+		return strings.HasPrefix(pattern, prefix)
+	}
+	return r.Route == pattern
+}
+
+func (r Rule) matchesUpstream(upstream string) bool {
+	return r.Upstream == "*" || r.Upstream != "" && r.Upstream == upstream
+}
+
+// Fault is what to do with a request: wait Latency, then drop its connection, answer with an
+// error of Status, or serve it when neither is set.
+type Fault struct {
+	Latency time.Duration
+	Status  int
+	Drop    bool
+}
+
+// decide draws the fault of a request from the rule.
+func (r Rule) decide() Fault {
+	fault := Fault{Latency: r.Latency}
+	switch {
+	case r.DropRate > 0 && rand.Float64() < r.DropRate:
+		fault.Drop = true
+	case r.ErrorRate > 0 && rand.Float64() < r.ErrorRate:
+		fault.Status = r.ErrorStatus
+		if fault.Status == 0 {
+			fault.Status = DefaultErrorStatus
+		}
+	}
+	return fault
+}
+
+// Injector holds the rules. A nil *Injector is valid and injects nothing.
+type Injector struct {
+	mu    sync.RWMutex
+	rules []Rule
+}
+
+// New returns an injector without rules.
+func New() *Injector {
+	return &Injector{}
+}
+
+// SetRules validates rules and replaces the current ones with them; none are changed when one
+// is invalid. The first rule matching a request applies.
+func (i *Injector) SetRules(rules []Rule) error {
+	for n, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("rule %d: %w", n, err)
+		}
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.rules = slices.Clone(rules)
+	return nil
+}
+
+// Rules returns the current rules.
+func (i *Injector) Rules() []Rule {
+	if i == nil {
+		return nil
+	}
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return slices.Clone(i.rules)
+}
+
+func (i *Injector) find(match func(Rule) bool) (Rule, bool) {
+	if i == nil {
+		return Rule{}, false
+	}
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	for _, rule := range i.rules {
+		if match(rule) {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// RouteFault draws the fault of a request of the route pattern; the zero Fault when no rule
+// matches.
+func (i *Injector) RouteFault(pattern string) Fault {
+	rule, ok := i.find(func(rule Rule) bool { return rule.matchesRoute(pattern) })
+	if !ok {
+		return Fault{}
+	}
+	return rule.decide()
+}
+
+// UpstreamFault draws the fault of a call to upstream; the zero Fault when no rule matches.
+func (i *Injector) UpstreamFault(upstream string) Fault {
+	rule, ok := i.find(func(rule Rule) bool { return rule.matchesUpstream(upstream) })
+	if !ok {
+		return Fault{}
+	}
+	return rule.decide()
+}
+
+// Wait sleeps the latency of the fault, or until done is closed; it reports whether it slept.
+func (f Fault) Wait(done <-chan struct{}) bool {
+	if f.Latency <= 0 {
+		return true
+	}
+	timer := time.NewTimer(f.Latency)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-done:
+		return false
+	}
+}
+
+// WrapTransportFor returns a wrapper injecting the faults of the upstream of each request into
+// an HTTP transport; it matches transport.WrapperFunc. Dropped calls fail with ErrDropped and
+// injected errors are plain text responses of their status.
+func (i *Injector) WrapTransportFor(upstream func(req *http.Request) string) func(http.RoundTripper) http.RoundTripper {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		if i == nil {
+			return rt
+		}
+		return &roundTripper{injector: i, upstream: upstream, next: rt}
+	}
+}
+
+type roundTripper struct {
+	injector *Injector
+	upstream func(req *http.Request) string
+	next     http.RoundTripper
+}
+
+func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	upstream := rt.upstream(req)
+	fault := rt.injector.UpstreamFault(upstream)
+	if !fault.Wait(req.Context().Done()) {
+		return nil, req.Context().Err()
+	}
+	switch {
+	case fault.Drop:
+		return nil, fmt.Errorf("%s %s: %w", req.Method, upstream, ErrDropped)
+	case fault.Status != 0:
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		body := fmt.Sprintf("chaos: injected %d for %s\n", fault.Status, upstream)
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", fault.Status, http.StatusText(fault.Status)),
+			StatusCode:    fault.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+			Body:          io.NopCloser(bytes.NewBufferString(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+	return rt.next.RoundTrip(req)
+}
@@ -0,0 +1,94 @@
+package chaos
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInjector_RouteFault(t *testing.T) {
+	faults := New()
+	require.NoError(t, faults.SetRules([]Rule{
+		{Route: "/api/v1/secrets/:name", ErrorRate: 1, ErrorStatus: http.StatusBadGateway},
+		{Route: "/api/v1/secrets*", DropRate: 1},
+		{Route: "*", Latency: time.Second},
+	}))
+
+	assert.Equal(t, Fault{Status: http.StatusBadGateway}, faults.RouteFault("/api/v1/secrets/:name"), "first matching rule")
+	assert.Equal(t, Fault{Drop: true}, faults.RouteFault("/api/v1/secrets"), "prefix")
+	assert.Equal(t, Fault{Latency: time.Second}, faults.RouteFault("/api/v1/namespaces"))
+	assert.Equal(t, Fault{}, faults.RouteFault(""), "not a route")
+	assert.Equal(t, Fault{}, faults.UpstreamFault("kubernetes"), "routes only")
+
+	err := faults.SetRules([]Rule{{Upstream: "kubernetes", ErrorRate: 1}, {Route: "/api/v1/user"}})
+	assert.ErrorContains(t, err, "rule 1: latency, errorRate or dropRate is required")
+	assert.Len(t, faults.Rules(), 3, "unchanged")
+
+	var disabled *Injector
+	assert.Equal(t, Fault{}, disabled.RouteFault("/api/v1/namespaces"))
+	assert.Nil(t, disabled.Rules())
+}
+
+func TestRule_Validate(t *testing.T) {
+	for name, rule := range map[string]Rule{
+		"no target":      {ErrorRate: 1},
+		"both targets":   {Route: "*", Upstream: "*", ErrorRate: 1},
+		"bad route":      {Route: "api/v1/user", ErrorRate: 1},
+		"bad error rate": {Route: "*", ErrorRate: 1.5},
+		"bad drop rate":  {Route: "*", DropRate: -1},
+		"bad status":     {Route: "*", ErrorRate: 1, ErrorStatus: 200},
+		"bad latency":    {Route: "*", Latency: -time.Second},
+		"no fault":       {Route: "*"},
+	} {
+		assert.Error(t, rule.Validate(), name)
+	}
+	assert.NoError(t, Rule{Upstream: "model-registry.team-a:8443", Latency: time.Millisecond}.Validate())
+}
+
+func TestInjector_WrapTransportFor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	faults := New()
+	client := &http.Client{Transport: faults.WrapTransportFor(func(req *http.Request) string { return req.URL.Host })(http.DefaultTransport)}
+	get := func(ctx context.Context) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		return client.Do(req)
+	}
+
+	resp, err := get(context.Background())
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "no rules")
+
+	require.NoError(t, faults.SetRules([]Rule{{Upstream: server.Listener.Addr().String(), ErrorRate: 1}}))
+	resp, err = get(context.Background())
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, DefaultErrorStatus, resp.StatusCode)
+	assert.Contains(t, string(body), "chaos: injected 503")
+
+	require.NoError(t, faults.SetRules([]Rule{{Upstream: "*", DropRate: 1}}))
+	_, err = get(context.Background())
+	assert.ErrorIs(t, err, ErrDropped)
+
+	require.NoError(t, faults.SetRules([]Rule{{Upstream: "*", Latency: time.Hour}}))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = get(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	var disabled *Injector
+	rt := http.DefaultTransport
+	assert.Equal(t, rt, disabled.WrapTransportFor(nil)(rt))
+}
@@ -0,0 +1,198 @@
+// Package concurrency caps the requests the BFF serves at once, globally and per key (a user),
+// so a burst such as hundreds of dashboards reconnecting together queues briefly and is then
+// shed, instead of piling up goroutines and Kubernetes API calls until the pod is OOM-killed.
+package concurrency
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Limits and reasons of a RejectedError, used as labels of the shed requests metric.
+const (
+	LimitGlobal = "global"
+	LimitKey    = "user"
+
+	ReasonQueueFull    = "queue_full"
+	ReasonQueueTimeout = "queue_timeout"
+)
+
+// RejectedError is returned by Acquire when a request is shed.
+type RejectedError struct {
+	// Limit is LimitGlobal or LimitKey.
+	Limit string
+	// Reason is ReasonQueueFull when the queue was full, ReasonQueueTimeout when the request
+	// waited for the whole queue timeout.
+	Reason string
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("%s concurrency limit reached (%s)", e.Limit, e.Reason)
+}
+
+// Config sets the limits of a Limiter.
+type Config struct {
+	// MaxInFlight caps the requests served at once; zero is unlimited.
+	MaxInFlight int
+	// MaxInFlightPerKey caps the requests of a single key served at once; zero is unlimited.
+	MaxInFlightPerKey int
+	// QueueSize is how many requests may wait for each limit (the global one, and each key's)
+	// before new ones are rejected; zero rejects requests over a limit right away.
+	QueueSize int
+	// QueueTimeout is how long a request waits in a queue before it is rejected.
+	QueueTimeout time.Duration
+}
+
+// Limiter admits requests within its limits. It is safe for concurrent use.
+type Limiter struct {
+	cfg    Config
+	global *slots
+
+	mu   sync.Mutex
+	keys map[string]*keySlots
+
+	waiting atomic.Int64
+	onQueue func(waiting int)
+}
+
+// slots is a counting semaphore with a bounded queue.
+type slots struct {
+	acquired chan struct{}
+	queued   atomic.Int64
+}
+
+type keySlots struct {
+	slots
+	refs int
+}
+
+// New returns a Limiter enforcing cfg, or nil when cfg sets no limit.
+func New(cfg Config) *Limiter {
+	if cfg.MaxInFlight <= 0 && cfg.MaxInFlightPerKey <= 0 {
+		return nil
+	}
+	l := &Limiter{cfg: cfg, keys: map[string]*keySlots{}}
+	if cfg.MaxInFlight > 0 {
+		l.global = &slots{acquired: make(chan struct{}, cfg.MaxInFlight)}
+	}
+	return l
+}
+
+// Observe calls onQueue with the number of waiting requests whenever it changes, e.g. to
+// record it as a metric. Call it before l is used.
+func (l *Limiter) Observe(onQueue func(waiting int)) {
+	l.onQueue = onQueue
+}
+
+// Acquire admits a request of key ("" for none, which only the global limit applies to),
+// waiting in the queues of the limits it is over. It returns a *RejectedError when the
+// request is shed, or the error of ctx when ctx is done first. Call release once the request
+// no longer needs its slots; calling it again is a no-op.
+func (l *Limiter) Acquire(ctx context.Context, key string) (release func(), err error) {
+	var perKey *keySlots
+	if key != "" && l.cfg.MaxInFlightPerKey > 0 {
+		perKey = l.keySlots(key)
+		if err := l.wait(ctx, &perKey.slots, LimitKey); err != nil {
+			l.releaseKey(key, perKey, false)
+			return nil, err
+		}
+	}
+	if l.global != nil {
+		if err := l.wait(ctx, l.global, LimitGlobal); err != nil {
+			if perKey != nil {
+				l.releaseKey(key, perKey, true)
+			}
+			return nil, err
+		}
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			if l.global != nil {
+				<-l.global.acquired
+			}
+			if perKey != nil {
+				l.releaseKey(key, perKey, true)
+			}
+		})
+	}, nil
+}
+
+// Waiting returns the number of requests waiting in the queues.
+func (l *Limiter) Waiting() int {
+	return int(l.waiting.Load())
+}
+
+// wait takes a slot of s, waiting in its queue if there is room.
+func (l *Limiter) wait(ctx context.Context, s *slots, limit string) error {
+	select {
+	case s.acquired <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if s.queued.Add(1) > int64(l.cfg.QueueSize) {
+		s.queued.Add(-1)
+		return &RejectedError{Limit: limit, Reason: ReasonQueueFull}
+	}
+	l.queueChanged(l.waiting.Add(1))
+	defer func() {
+		s.queued.Add(-1)
+		l.queueChanged(l.waiting.Add(-1))
+	}()
+
+	timer := time.NewTimer(l.cfg.QueueTimeout)
+	defer timer.Stop()
+	select {
+	case s.acquired <- struct{}{}:
+		return nil
+	case <-timer.C:
+		return &RejectedError{Limit: limit, Reason: ReasonQueueTimeout}
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *Limiter) queueChanged(waiting int64) {
+	if l.onQueue != nil {
+		l.onQueue(int(waiting))
+	}
+}
+
+// keySlots returns the slots of key, referenced until releaseKey.
+func (l *Limiter) keySlots(key string) *keySlots {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s, ok := l.keys[key]
+	if !ok {
+		s = &keySlots{slots: slots{acquired: make(chan struct{}, l.cfg.MaxInFlightPerKey)}}
+		l.keys[key] = s
+	}
+	s.refs++
+	return s
+}
+
+// releaseKey drops a reference to the slots of key, freeing the slot taken if acquired. The
+// slots of a key are dropped with their last reference, so memory is bounded by the number
+// of active keys.
+func (l *Limiter) releaseKey(key string, s *keySlots, acquired bool) {
+	if acquired {
+		<-s.acquired
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if s.refs--; s.refs == 0 {
+		delete(l.keys, key)
+	}
+}
+
+// Len returns the number of tracked keys.
+func (l *Limiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.keys)
+}
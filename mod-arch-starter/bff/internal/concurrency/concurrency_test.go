@@ -0,0 +1,99 @@
+package concurrency
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_Disabled(t *testing.T) {
+	assert.Nil(t, New(Config{QueueSize: 10, QueueTimeout: time.Second}))
+}
+
+func TestLimiter_Global(t *testing.T) {
+	l := New(Config{MaxInFlight: 2, QueueSize: 1, QueueTimeout: time.Minute})
+	ctx := context.Background()
+
+	first, err := l.Acquire(ctx, "alice")
+	require.NoError(t, err)
+	_, err = l.Acquire(ctx, "bob")
+	require.NoError(t, err)
+
+	// The third request waits for a slot, the fourth finds the queue full
+	admitted := make(chan error, 1)
+	go func() {
+		_, err := l.Acquire(ctx, "carol")
+		admitted <- err
+	}()
+	require.Eventually(t, func() bool { return l.Waiting() == 1 }, time.Second, time.Millisecond)
+	_, err = l.Acquire(ctx, "dave")
+	assert.Equal(t, &RejectedError{Limit: LimitGlobal, Reason: ReasonQueueFull}, err)
+
+	first()
+	first() // no-op
+	require.NoError(t, <-admitted)
+	assert.Zero(t, l.Waiting())
+}
+
+func TestLimiter_QueueTimeout(t *testing.T) {
+	l := New(Config{MaxInFlight: 1, QueueSize: 1, QueueTimeout: 20 * time.Millisecond})
+	_, err := l.Acquire(context.Background(), "")
+	require.NoError(t, err)
+
+	_, err = l.Acquire(context.Background(), "")
+	assert.Equal(t, &RejectedError{Limit: LimitGlobal, Reason: ReasonQueueTimeout}, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = New(Config{MaxInFlight: 1, QueueSize: 1, QueueTimeout: time.Minute}).Acquire(ctx, "")
+	require.NoError(t, err, "a free slot is taken whatever the context")
+}
+
+func TestLimiter_PerKey(t *testing.T) {
+	l := New(Config{MaxInFlight: 10, MaxInFlightPerKey: 1})
+	ctx := context.Background()
+
+	release, err := l.Acquire(ctx, "alice")
+	require.NoError(t, err)
+	_, err = l.Acquire(ctx, "alice")
+	assert.Equal(t, &RejectedError{Limit: LimitKey, Reason: ReasonQueueFull}, err)
+
+	// Other keys, and requests without a key, are only bound by the global limit
+	_, err = l.Acquire(ctx, "bob")
+	require.NoError(t, err)
+	_, err = l.Acquire(ctx, "")
+	require.NoError(t, err)
+
+	release()
+	_, err = l.Acquire(ctx, "alice")
+	require.NoError(t, err)
+}
+
+func TestLimiter_ReleasesKeys(t *testing.T) {
+	l := New(Config{MaxInFlightPerKey: 2, QueueSize: 5, QueueTimeout: time.Second})
+	var observed sync.Map
+	l.Observe(func(waiting int) { observed.Store(waiting, true) })
+
+	var wg sync.WaitGroup
+	for range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := l.Acquire(context.Background(), "alice")
+			if assert.NoError(t, err) {
+				time.Sleep(5 * time.Millisecond)
+				release()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Zero(t, l.Len(), "the slots of idle keys are dropped")
+	assert.Zero(t, l.Waiting())
+	_, sawQueue := observed.Load(1)
+	assert.True(t, sawQueue)
+}
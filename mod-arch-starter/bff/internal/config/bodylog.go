@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// BodyLogAllRoutes in BodyLogRoutes logs the bodies of every route.
+const BodyLogAllRoutes = "*"
+
+// DefaultBodyLogMaxSize is the size from which logged bodies are truncated.
+const DefaultBodyLogMaxSize = 4096
+
+// DefaultBodyLogRedactedFields are the patterns of the JSON fields whose values are never
+// logged.
+var DefaultBodyLogRedactedFields = []string{"*password*", "*secret*", "*token*", "*credential*"}
+
+// ParseBodyLogRoutes parses the route entries of BodyLogRoutes, like the routes of
+// RouteTimeouts: a route pattern, optionally preceded by a method ("POST /api/v1/secrets"),
+// or BodyLogAllRoutes. The result is keyed by "METHOD route", by the route alone for every
+// method, or by BodyLogAllRoutes.
+func ParseBodyLogRoutes(entries []string) (map[string]bool, error) {
+	routes := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		route := strings.TrimSpace(entry)
+		if method, p, found := strings.Cut(route, " "); found {
+			p = strings.TrimSpace(p)
+			if !validMethod(method) || !strings.HasPrefix(p, "/") {
+				return nil, fmt.Errorf("invalid route %q (must be [METHOD ]/route or %s)", entry, BodyLogAllRoutes)
+			}
+			route = strings.ToUpper(method) + " " + p
+		} else if route != BodyLogAllRoutes && !strings.HasPrefix(route, "/") {
+			return nil, fmt.Errorf("invalid route %q (must be [METHOD ]/route or %s)", entry, BodyLogAllRoutes)
+		}
+		routes[route] = true
+	}
+	return routes, nil
+}
+
+// validFieldPatterns reports the first of patterns that is not a valid path.Match pattern.
+func validFieldPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			return fmt.Errorf("%q is not a valid pattern", pattern)
+		}
+	}
+	return nil
+}
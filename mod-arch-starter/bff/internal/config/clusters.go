@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ClusterRef locates the kubeconfig of a cluster other than the local one: a context of the
+// backend kubeconfig, or a Secret holding a kubeconfig.
+type ClusterRef struct {
+	Name string
+	// Context is the kubeconfig context of the cluster, when set.
+	Context string
+	// SecretNamespace and SecretName locate the kubeconfig Secret of the cluster, otherwise.
+	SecretNamespace string
+	SecretName      string
+}
+
+// ParseClusterRefs parses the "name=context" entries of ClusterContexts then the
+// "name=namespace/secret" entries of ClusterSecrets. Names are DNS labels, unique and
+// distinct from ClusterName.
+func (c EnvConfig) ParseClusterRefs() ([]ClusterRef, error) {
+	refs := make([]ClusterRef, 0, len(c.ClusterContexts)+len(c.ClusterSecrets))
+	seen := map[string]bool{c.ClusterName: true}
+
+	add := func(entry, format string, ref ClusterRef, value string) error {
+		name, valid := parseClusterName(entry, value)
+		if !valid {
+			return fmt.Errorf("invalid cluster %q (must be %s)", entry, format)
+		}
+		if seen[name] {
+			return fmt.Errorf("duplicate cluster name %q", name)
+		}
+		seen[name] = true
+		ref.Name = name
+		refs = append(refs, ref)
+		return nil
+	}
+
+	for _, entry := range c.ClusterContexts {
+		_, kubeContext, _ := strings.Cut(entry, "=")
+		kubeContext = strings.TrimSpace(kubeContext)
+		if err := add(entry, "name=context", ClusterRef{Context: kubeContext}, kubeContext); err != nil {
+			return nil, err
+		}
+	}
+	for _, entry := range c.ClusterSecrets {
+		_, secret, _ := strings.Cut(entry, "=")
+		namespace, name, _ := strings.Cut(strings.TrimSpace(secret), "/")
+		if namespace == "" || name == "" || strings.Contains(name, "/") {
+			secret = ""
+		}
+		ref := ClusterRef{SecretNamespace: namespace, SecretName: name}
+		if err := add(entry, "name=namespace/secret", ref, secret); err != nil {
+			return nil, err
+		}
+	}
+	return refs, nil
+}
+
+// parseClusterName returns the name of a "name=value" entry, and whether it is a DNS label
+// with a non-empty value.
+func parseClusterName(entry, value string) (string, bool) {
+	name, _, ok := strings.Cut(entry, "=")
+	name = strings.TrimSpace(name)
+	return name, ok && value != "" && len(validation.IsDNS1123Label(name)) == 0
+}
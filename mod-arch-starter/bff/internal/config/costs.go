@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultCostWindow is the sliding window of CostBudget.
+const DefaultCostWindow = time.Minute
+
+// ParseRouteCosts parses the "route=cost" entries of RouteCosts, like those of RouteTimeouts:
+// the result is keyed by "METHOD route", or by the route alone for every method. A zero cost
+// leaves the route out of the budget.
+func ParseRouteCosts(entries []string) (map[string]int, error) {
+	costs := make(map[string]int, len(entries))
+	for _, entry := range entries {
+		route, value, ok := strings.Cut(entry, "=")
+		route, value = strings.TrimSpace(route), strings.TrimSpace(value)
+		if method, path, found := strings.Cut(route, " "); found {
+			path = strings.TrimSpace(path)
+			if !validMethod(method) || !strings.HasPrefix(path, "/") {
+				return nil, fmt.Errorf("invalid route cost %q (must be [METHOD ]/route=cost)", entry)
+			}
+			route = strings.ToUpper(method) + " " + path
+		} else if !strings.HasPrefix(route, "/") {
+			return nil, fmt.Errorf("invalid route cost %q (must be [METHOD ]/route=cost)", entry)
+		}
+		cost, err := strconv.Atoi(value)
+		if !ok || err != nil || cost < 0 {
+			return nil, fmt.Errorf("invalid route cost %q (must be [METHOD ]/route=cost, e.g. /api/v1/namespaces=10)", entry)
+		}
+		costs[route] = cost
+	}
+	return costs, nil
+}
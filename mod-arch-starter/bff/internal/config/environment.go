@@ -4,6 +4,15 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/database"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/oidc"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/notifications"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/operations"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/servertls"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/session"
 )
 
 const (
@@ -15,16 +24,342 @@ const (
 	AuthMethodInternal = "internal"
 
 	// AuthMethodUser uses a user-provided Bearer token for authentication.
+	// Every Kubernetes request is made with the caller's token (token passthrough).
 	AuthMethodUser = "user_token"
 
+	// AuthMethodImpersonation uses the credentials of the running backend but sets the
+	// Impersonate-User and Impersonate-Group headers from kubeflow-userid/kubeflow-groups,
+	// so the API server authorizes every request as the end user.
+	// The backend's service account needs the "impersonate" verb on users and groups.
+	AuthMethodImpersonation = "impersonation"
+
 	// DefaultAuthTokenHeader is the standard header for Bearer token auth.
 	DefaultAuthTokenHeader = "Authorization"
 
 	// DefaultAuthTokenPrefix is the prefix used in the Authorization header.
 	// note: the space here is intentional, as the prefix is "Bearer " (with a space).
 	DefaultAuthTokenPrefix = "Bearer "
+
+	// DefaultUserIDHeader and DefaultGroupsHeader are the identity headers set by the Kubeflow
+	// auth stack.
+	DefaultUserIDHeader = "kubeflow-userid"
+	DefaultGroupsHeader = "kubeflow-groups"
+
+	// DefaultGuestUser is the user of the guest identity.
+	DefaultGuestUser = "guest"
+)
+
+// IsValidAuthMethod returns true if method is one of the supported authentication methods.
+func IsValidAuthMethod(method string) bool {
+	switch method {
+	case AuthMethodInternal, AuthMethodUser, AuthMethodImpersonation:
+		return true
+	default:
+		return false
+	}
+}
+
+const (
+	// CacheResourceNamespaces serves namespaces from a shared informer cache.
+	CacheResourceNamespaces = "namespaces"
+	// CacheResourceServices serves services of every namespace from a shared informer cache.
+	CacheResourceServices = "services"
+
+	// DefaultCacheResyncPeriod is how often the informers resync their cache.
+	DefaultCacheResyncPeriod = 10 * time.Minute
+)
+
+const (
+	// AuditSinkLog writes audit entries to the BFF log.
+	AuditSinkLog = "log"
+	// AuditSinkEvents records audit entries as Kubernetes Events.
+	AuditSinkEvents = "events"
+	// AuditSinkWebhook posts audit entries to -audit-webhook-url.
+	AuditSinkWebhook = "webhook"
+)
+
+// IsValidAuditSink returns true if sink is a supported audit sink.
+func IsValidAuditSink(sink string) bool {
+	switch sink {
+	case AuditSinkLog, AuditSinkEvents, AuditSinkWebhook:
+		return true
+	default:
+		return false
+	}
+}
+
+// Sources of the external URLs of discovered Services (-service-url-sources).
+const (
+	// ServiceURLSourceRoute resolves URLs from OpenShift Routes.
+	ServiceURLSourceRoute = "route"
+	// ServiceURLSourceIngress resolves URLs from Ingresses.
+	ServiceURLSourceIngress = "ingress"
+	// ServiceURLSourceHTTPRoute resolves URLs from Gateway API HTTPRoutes.
+	ServiceURLSourceHTTPRoute = "httproute"
+)
+
+// IsValidServiceURLSource returns true if source is a supported source of Service URLs.
+func IsValidServiceURLSource(source string) bool {
+	switch source {
+	case ServiceURLSourceRoute, ServiceURLSourceIngress, ServiceURLSourceHTTPRoute:
+		return true
+	default:
+		return false
+	}
+}
+
+// Modes of the startup preflight checks (-preflight).
+const (
+	// PreflightOff skips the checks.
+	PreflightOff = "off"
+	// PreflightWarn logs the problems found and starts anyway.
+	PreflightWarn = "warn"
+	// PreflightFail exits when a check fails; warnings are logged.
+	PreflightFail = "fail"
+)
+
+// IsValidPreflightMode returns true if mode is a supported preflight mode.
+func IsValidPreflightMode(mode string) bool {
+	switch mode {
+	case PreflightOff, PreflightWarn, PreflightFail:
+		return true
+	default:
+		return false
+	}
+}
+
+const (
+	// AuthorizerSAR authorizes the API requests with a SubjectAccessReview of their route.
+	AuthorizerSAR = "sar"
+	// AuthorizerPolicy authorizes the API requests with the rules of -authz-policy-file.
+	AuthorizerPolicy = "policy"
+	// AuthorizerOPA authorizes the API requests with the Open Policy Agent of -authz-opa-url.
+	AuthorizerOPA = "opa"
+
+	// DefaultAuthzGroup is the API group of the routes reviewed by the sar authorizer.
+	DefaultAuthzGroup = "bff.opendatahub.io"
+)
+
+// IsValidAuthorizer returns true if name is a built-in authorizer.
+func IsValidAuthorizer(name string) bool {
+	switch name {
+	case AuthorizerSAR, AuthorizerPolicy, AuthorizerOPA:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsValidCacheResource returns true if resource can be served from the informer cache.
+func IsValidCacheResource(resource string) bool {
+	switch resource {
+	case CacheResourceNamespaces, CacheResourceServices:
+		return true
+	default:
+		return false
+	}
+}
+
+const (
+	// DefaultServerIdleTimeout closes the keep-alive connections idle for longer.
+	DefaultServerIdleTimeout = time.Minute
+	// DefaultServerReadTimeout bounds reading a request, body included.
+	DefaultServerReadTimeout = 30 * time.Second
+	// DefaultServerReadHeaderTimeout bounds reading the headers of a request.
+	DefaultServerReadHeaderTimeout = 10 * time.Second
+	// DefaultServerWriteTimeout bounds writing a response, but streams.
+	DefaultServerWriteTimeout = 60 * time.Second
+	// DefaultServerMaxHeaderBytes bounds the headers of a request, like net/http.
+	DefaultServerMaxHeaderBytes = 1 << 20
+	// DefaultHTTP2MaxConcurrentStreams bounds the streams of an HTTP/2 connection, like net/http.
+	DefaultHTTP2MaxConcurrentStreams = 250
+	// DefaultHTTP2PingTimeout closes the HTTP/2 connections not answering a ping in time.
+	DefaultHTTP2PingTimeout = 15 * time.Second
+)
+
+const (
+	// DefaultShutdownTimeout bounds how long a shutting-down server waits for in-flight requests.
+	DefaultShutdownTimeout = 30 * time.Second
+	// DefaultRequestTimeout bounds each API request, but streams.
+	DefaultRequestTimeout = 30 * time.Second
+)
+
+const (
+	// DefaultLeaderElectionLeaseName is the Lease of -leader-election-enabled.
+	DefaultLeaderElectionLeaseName = "mod-arch-bff"
+	// DefaultLeaderElectionLeaseDuration is how long the replicas wait to take over the Lease
+	// of a leader that stopped renewing it.
+	DefaultLeaderElectionLeaseDuration = 15 * time.Second
+)
+
+// DefaultGraphQLMaxDepth bounds the nesting of the fields of a GraphQL query, deep enough for
+// the namespaces, services and ports of the schema.
+const DefaultGraphQLMaxDepth = 10
+
+const (
+	// DefaultObjectStorageTransferLimit bounds the objects downloaded or uploaded through the
+	// BFF: the largest object a single S3 upload can write, and the most allowed.
+	DefaultObjectStorageTransferLimit = 5 << 30
+	// DefaultObjectStoragePresignExpiry is the validity of the presigned URLs of objects.
+	DefaultObjectStoragePresignExpiry = 15 * time.Minute
+	// MaxObjectStoragePresignExpiry is the longest validity of presigned URLs allowed by S3.
+	MaxObjectStoragePresignExpiry = 7 * 24 * time.Hour
+)
+
+const (
+	// DefaultKubeAPIQPS and DefaultKubeAPIBurst rate limit the API server requests of each
+	// Kubernetes client, above the client-go defaults (5 and 10) sized for controllers.
+	DefaultKubeAPIQPS   = 50
+	DefaultKubeAPIBurst = 100
+	// DefaultKubeAPITimeout bounds each API server request, but watches and streams.
+	DefaultKubeAPITimeout = time.Minute
+	// DefaultKubeAPIMaxIdleConns is the client-go default.
+	DefaultKubeAPIMaxIdleConns = 25
+)
+
+const (
+	// DefaultUpstreamMaxRetries, DefaultUpstreamRetryMinBackoff and DefaultUpstreamRetryMaxBackoff
+	// retry idempotent upstream calls twice, after about 200ms then 400ms.
+	DefaultUpstreamMaxRetries      = 2
+	DefaultUpstreamRetryMinBackoff = 200 * time.Millisecond
+	DefaultUpstreamRetryMaxBackoff = 5 * time.Second
+	// DefaultCircuitBreakerFailures consecutive failures open the circuit of an upstream for
+	// DefaultCircuitBreakerCooldown.
+	DefaultCircuitBreakerFailures = 5
+	DefaultCircuitBreakerCooldown = 30 * time.Second
+)
+
+const (
+	// DefaultClusterName names the cluster the BFF runs in, among the clusters of -cluster-contexts
+	// and -cluster-secrets.
+	DefaultClusterName = "local"
+)
+
+const (
+	// DefaultConcurrencyQueueSize and DefaultConcurrencyQueueTimeout bound the requests waiting
+	// for the concurrency caps.
+	DefaultConcurrencyQueueSize    = 100
+	DefaultConcurrencyQueueTimeout = time.Second
+	// DefaultIdempotencyKeyTTL is how long the responses of idempotent requests are replayed.
+	DefaultIdempotencyKeyTTL = 24 * time.Hour
+	// DefaultIdempotencyMaxEntries bounds the in-memory responses of idempotent requests.
+	DefaultIdempotencyMaxEntries = 1000
+	// DefaultResponseCacheMaxEntries bounds the in-memory response cache.
+	DefaultResponseCacheMaxEntries = 1000
+	// DefaultReviewCacheMaxEntries bounds the cache of authentication and access reviews.
+	DefaultReviewCacheMaxEntries = 10000
+	// DefaultIdentityCacheMaxEntries bounds the cache of identity lookups.
+	DefaultIdentityCacheMaxEntries = 10000
+	// DefaultWarmupTimeout bounds the cache warmup, after which the BFF turns ready anyway.
+	DefaultWarmupTimeout = 2 * time.Minute
+)
+
+const (
+	// DefaultServiceLabelSelector selects the Services listed by /api/v1/services.
+	DefaultServiceLabelSelector = "component=mod-arch"
+)
+
+const (
+	// DefaultODHDashboardNamespace is the namespace of the OpenDataHub dashboard settings.
+	DefaultODHDashboardNamespace = "opendatahub"
+	// DefaultODHDashboardCacheTTL is how long the dashboard settings are cached.
+	DefaultODHDashboardCacheTTL = time.Minute
+)
+
+const (
+	// DefaultServiceAccountTokenMaxTTL is the longest validity of the minted ServiceAccount
+	// tokens, and the validity of those requested without one.
+	DefaultServiceAccountTokenMaxTTL = time.Hour
+	// MinServiceAccountTokenTTL is the shortest validity of a token the API server issues.
+	MinServiceAccountTokenTTL = 10 * time.Minute
+)
+
+const (
+	// DefaultAuditQueueSize bounds the audit entries waiting for the sinks.
+	DefaultAuditQueueSize = 1000
+)
+
+const (
+	// DefaultWebhookMaxAttempts is the number of deliveries of a webhook event.
+	DefaultWebhookMaxAttempts = 5
+	// DefaultWebhookQueueSize bounds the events waiting for each webhook endpoint.
+	DefaultWebhookQueueSize = 1000
+)
+
+const (
+	// DefaultRevealVerb is the verb required to reveal Secret and redacted ConfigMap values.
+	DefaultRevealVerb = "get"
+)
+
+const (
+	// DefaultCORSMaxAge is how long browsers cache CORS preflight responses.
+	DefaultCORSMaxAge = 10 * time.Minute
+)
+
+// DefaultCORSAllowedMethods are the methods of the API allowed in CORS requests.
+var DefaultCORSAllowedMethods = []string{"GET", "PUT", "POST", "PATCH", "DELETE"}
+
+// DefaultCompressionMinSize is the body size from which responses are compressed.
+const DefaultCompressionMinSize = 1024
+
+// DefaultCompressionContentTypes are the compressed media types: the API responses and the
+// frontend code, styles, pages and SVG images.
+var DefaultCompressionContentTypes = []string{
+	"application/json", "application/problem+json", "application/javascript", "application/xml",
+	"application/yaml", "image/svg+xml", "text/*",
+}
+
+const (
+	// DefaultCSRFCookieName is the cookie holding the CSRF token.
+	DefaultCSRFCookieName = "csrf_token"
+	// DefaultCSRFHeader is the header the frontend echoes the CSRF token in.
+	DefaultCSRFHeader = "X-CSRF-Token"
+	// DefaultCSRFSameSite is the SameSite attribute of the CSRF cookie.
+	DefaultCSRFSameSite = "lax"
 )
 
+const (
+	// DefaultSessionCookieName is the cookie of the sessions of -session-enabled.
+	DefaultSessionCookieName = "modarch_session"
+	// DefaultSessionIdleTimeout ends sessions without requests for this long.
+	DefaultSessionIdleTimeout = 30 * time.Minute
+	// DefaultSessionAbsoluteTimeout ends sessions this long after the sign-in.
+	DefaultSessionAbsoluteTimeout = 12 * time.Hour
+	// DefaultSessionRefreshBefore refreshes the tokens of sessions this long before they expire.
+	DefaultSessionRefreshBefore = time.Minute
+	// minSessionSecretLength is the length required of -session-secret.
+	minSessionSecretLength = 32
+)
+
+// DefaultOAuthScopes are the scopes requested when users sign in.
+var DefaultOAuthScopes = []string{"openid", "profile", "email"}
+
+const (
+	// DefaultHSTSMaxAge is how long browsers keep to HTTPS after a response (one year).
+	DefaultHSTSMaxAge = 365 * 24 * time.Hour
+	// DefaultFrameOptions forbids framing the frontend.
+	DefaultFrameOptions = "DENY"
+	// DefaultReferrerPolicy only sends the origin to other sites.
+	DefaultReferrerPolicy = "strict-origin-when-cross-origin"
+	// CSPNoncePlaceholder is replaced in ContentSecurityPolicy with a random nonce per response.
+	CSPNoncePlaceholder = "{nonce}"
+	// DefaultContentSecurityPolicy allows the frontend's own code, scripts of index.html with
+	// the response nonce, inline styles (injected by the bundler) and the Google fonts it uses.
+	DefaultContentSecurityPolicy = "default-src 'self'; script-src 'self' 'nonce-" + CSPNoncePlaceholder + "'; " +
+		"style-src 'self' 'unsafe-inline' https://fonts.googleapis.com; font-src 'self' data: https://fonts.gstatic.com; " +
+		"img-src 'self' data:; connect-src 'self'; object-src 'none'; base-uri 'self'; frame-ancestors 'none'"
+)
+
+// IsValidSameSite returns true if value is a SameSite cookie attribute: lax, strict or none.
+func IsValidSameSite(value string) bool {
+	switch strings.ToLower(value) {
+	case "lax", "strict", "none":
+		return true
+	default:
+		return false
+	}
+}
+
 // DeploymentMode represents the deployment mode enum
 type DeploymentMode string
 
@@ -72,50 +407,881 @@ func (d DeploymentMode) IsFederatedMode() bool {
 	return d == DeploymentModeFederated
 }
 
+const (
+	// MockK8sBackendEnvTest serves the mocked Kubernetes client from a local envtest API server.
+	// Requires the envtest binaries (make envtest).
+	MockK8sBackendEnvTest = "envtest"
+	// MockK8sBackendMemory serves the mocked Kubernetes client from in-memory fixtures.
+	// No cluster or additional binaries are needed.
+	MockK8sBackendMemory = "memory"
+)
+
+// EnvConfig is the BFF configuration. Load fills it from a YAML file, environment variables
+// and flags: the config tag is the flag name and file key, env the environment variable.
+// Fields tagged reload:"true" are applied at runtime when the file changes (see Reloader).
 type EnvConfig struct {
-	Port               int
-	MockK8Client       bool
-	MockHTTPClient     bool
-	DevMode            bool
-	DeploymentMode     DeploymentMode
-	DevModeClientPort  int
-	DevModeCatalogPort int
-	StaticAssetsDir    string
-	LogLevel           slog.Level
-	AllowedOrigins     []string
+	Port         int  `config:"port" env:"PORT" usage:"API server port"`
+	MockK8Client bool `config:"mock-k8s-client" env:"MOCK_K8S_CLIENT" usage:"Use mock Kubernetes client"`
+	// MockK8sBackend selects how the mocked Kubernetes client is backed when MockK8Client is set:
+	// "envtest" (default) or "memory".
+	MockK8sBackend string `config:"mock-k8s-backend" env:"MOCK_K8S_BACKEND" usage:"Backend for the mock Kubernetes client (envtest or memory)"`
+	// MockK8sFixturesPath optionally points to a JSON file with fixtures for the "memory" backend.
+	MockK8sFixturesPath string `config:"mock-k8s-fixtures" env:"MOCK_K8S_FIXTURES" usage:"Path to a JSON fixtures file for the in-memory mock Kubernetes client (optional)"`
+	// MockServerFixtures is a directory of fixture files answering the API routes instead of
+	// their handlers, for contract and end-to-end tests without a cluster (see
+	// internal/mockserver). Kubernetes and BFF clients are mocked in memory.
+	MockServerFixtures string `config:"mock-server-fixtures" env:"MOCK_SERVER_FIXTURES" usage:"Directory of fixtures served instead of the API handlers (optional, testing)"`
+	// MockServerLatency delays every response of the mock server.
+	MockServerLatency time.Duration `config:"mock-server-latency" env:"MOCK_SERVER_LATENCY" usage:"Latency added to the responses of the mock server"`
+	// MockServerErrorRate is the fraction of the requests the mock server answers with a 500,
+	// from 0 to 1.
+	MockServerErrorRate float64        `config:"mock-server-error-rate" env:"MOCK_SERVER_ERROR_RATE" usage:"Fraction of the requests the mock server fails, from 0 to 1"`
+	MockHTTPClient      bool           `config:"mock-http-client" env:"MOCK_HTTP_CLIENT" usage:"Use mock HTTP client"`
+	DevMode             bool           `config:"dev-mode" env:"DEV_MODE" usage:"Use development mode for access to local K8s cluster"`
+	DeploymentMode      DeploymentMode `config:"deployment-mode" env:"DEPLOYMENT_MODE" usage:"Deployment mode (kubeflow, federated, or standalone)"`
+	DevModeClientPort   int            `config:"dev-mode-client-port" env:"DEV_MODE_CLIENT_PORT" usage:"Use port when in development mode for client"`
+	DevModeCatalogPort  int
+	StaticAssetsDir     string     `config:"static-assets-dir" env:"STATIC_ASSETS_DIR" usage:"Configure frontend static assets root directory"`
+	LogLevel            slog.Level `config:"log-level" env:"LOG_LEVEL" usage:"Sets server log level, possible values: error, warn, info, debug" reload:"true"`
+	// LogFormat is the output format of the logs: "json" (default) or "text".
+	LogFormat string `config:"log-format" env:"LOG_FORMAT" usage:"Log output format (json or text)"`
+	// LogLevels overrides the log level per package, as "package=level" entries
+	// (e.g. "proxy=debug,kubernetes=warn"). Packages not listed use LogLevel.
+	LogLevels []string `config:"log-levels" env:"LOG_LEVELS" usage:"Comma-separated per-package log levels, e.g. proxy=debug,kubernetes=warn (optional)" reload:"true"`
 	// BundlePaths is a list of filesystem paths to PEM-encoded CA bundle files.
 	// If provided, the application will attempt to load these files and add the
 	// certificates to the HTTP client's Root CAs for outbound TLS connections.
-	// Missing or unreadable files are ignored.
-	BundlePaths []string
+	// Missing or unreadable files are ignored. The files are reloaded when they change.
+	BundlePaths []string `config:"bundle-paths" env:"BUNDLE_PATHS" usage:"Comma-separated list of PEM CA bundle file paths to trust for outbound TLS (optional)"`
+
+	// ─── HTTP SERVER ────────────────────────────────────────────
+	// The limits apply to the API server and, when it has its own port, the admin server.
+	// ServerIdleTimeout closes the keep-alive connections idle for longer (default 1m).
+	ServerIdleTimeout time.Duration `config:"server-idle-timeout" env:"SERVER_IDLE_TIMEOUT" usage:"How long keep-alive connections may stay idle"`
+
+	// ServerReadTimeout bounds reading a request, body included (default 30s), and
+	// ServerReadHeaderTimeout its headers (default 10s). Zero disables them.
+	ServerReadTimeout       time.Duration `config:"server-read-timeout" env:"SERVER_READ_TIMEOUT" usage:"Maximum time to read a request, body included (0 disables)"`
+	ServerReadHeaderTimeout time.Duration `config:"server-read-header-timeout" env:"SERVER_READ_HEADER_TIMEOUT" usage:"Maximum time to read the headers of a request (0 uses server-read-timeout)"`
+
+	// ServerWriteTimeout bounds writing a response (default 60s). Streams (watches, followed
+	// logs, Server-Sent Events and WebSockets) clear it. Zero disables it.
+	ServerWriteTimeout time.Duration `config:"server-write-timeout" env:"SERVER_WRITE_TIMEOUT" usage:"Maximum time to write a response, but streams (0 disables)"`
+
+	// ServerMaxHeaderBytes bounds the headers of a request, HTTP/2 header lists included
+	// (default 1MiB).
+	ServerMaxHeaderBytes int `config:"server-max-header-bytes" env:"SERVER_MAX_HEADER_BYTES" usage:"Maximum size of the headers of a request, in bytes"`
+
+	// ServerKeepAlives keeps the connections open between requests (default true).
+	ServerKeepAlives bool `config:"server-keep-alives" env:"SERVER_KEEP_ALIVES" usage:"Keep the connections open between requests"`
+
+	// HTTP2Enabled serves HTTP/2 to the clients negotiating it over TLS (default true), so the
+	// streams and requests of a browser tab share one connection.
+	HTTP2Enabled bool `config:"http2-enabled" env:"HTTP2_ENABLED" usage:"Serve HTTP/2 over TLS"`
+
+	// H2CEnabled also serves unencrypted HTTP/2 (h2c, with prior knowledge) without TLS, e.g. to
+	// a service mesh sidecar terminating TLS. Requires HTTP2Enabled.
+	H2CEnabled bool `config:"h2c-enabled" env:"H2C_ENABLED" usage:"Serve unencrypted HTTP/2 (h2c) without TLS, e.g. behind a service mesh"`
+
+	// HTTP2MaxConcurrentStreams bounds the concurrent requests and streams of an HTTP/2
+	// connection (default 250).
+	HTTP2MaxConcurrentStreams int `config:"http2-max-concurrent-streams" env:"HTTP2_MAX_CONCURRENT_STREAMS" usage:"Maximum concurrent streams of an HTTP/2 connection"`
+
+	// HTTP2PingInterval pings the HTTP/2 connections idle for longer, so proxies in between
+	// keep long-lived streams open, and HTTP2PingTimeout closes those not answering in time
+	// (default 15s). A zero interval (default) disables the pings.
+	HTTP2PingInterval time.Duration `config:"http2-ping-interval" env:"HTTP2_PING_INTERVAL" usage:"Ping HTTP/2 connections idle for longer (0 disables)"`
+	HTTP2PingTimeout  time.Duration `config:"http2-ping-timeout" env:"HTTP2_PING_TIMEOUT" usage:"How long an HTTP/2 ping may go unanswered before the connection closes"`
+
+	// ─── SHUTDOWN ───────────────────────────────────────────────
+	// ShutdownDelay keeps serving after SIGTERM while /readyz reports the pod as draining, so
+	// load balancers and Service endpoints stop routing to it before the listener closes.
+	// Zero (default) closes the listener right away.
+	ShutdownDelay time.Duration `config:"shutdown-delay" env:"SHUTDOWN_DELAY" usage:"Time to keep serving after SIGTERM while /readyz reports draining"`
+
+	// ShutdownTimeout bounds how long in-flight requests may take to complete once the listener
+	// is closed (default 30s). Long-lived streams (watches, proxied streams and WebSockets) are
+	// ended when draining starts, so clients reconnect to another replica.
+	ShutdownTimeout time.Duration `config:"shutdown-timeout" env:"SHUTDOWN_TIMEOUT" usage:"Maximum time to wait for in-flight requests on shutdown"`
+
+	// ─── TIMEOUTS ───────────────────────────────────────────────
+	// RequestTimeout bounds each API request (default 30s, below ServerWriteTimeout): its deadline is set on the request context, so repositories and Kubernetes calls
+	// are canceled with it, and a request exceeding it gets a 504. Streams (watches, followed
+	// logs, Server-Sent Events and WebSockets) are not bounded. Zero disables it.
+	RequestTimeout time.Duration `config:"request-timeout" env:"REQUEST_TIMEOUT" usage:"Timeout of API requests, but streams (0 disables)"`
+
+	// RouteTimeouts overrides RequestTimeout per route, as "route=duration" entries where route
+	// is a route pattern, optionally preceded by a method (see ParseRouteTimeouts), e.g.
+	// "POST /api/v1/secrets=1m,/api/v1/services=10s". A zero duration disables it.
+	RouteTimeouts []string `config:"route-timeouts" env:"ROUTE_TIMEOUTS" usage:"Comma-separated [METHOD ]/route=duration timeouts overriding request-timeout (optional)"`
+
+	// ─── JOBS ───────────────────────────────────────────────────
+	// JobSchedules overrides the schedules of background jobs, built-in or registered, as
+	// "job=schedule" entries (see ParseJobSchedules), e.g. "health-probe=@every 1m". Cron
+	// expressions can't use lists here, as commas separate the entries; "job=off" turns a
+	// job off.
+	JobSchedules []string `config:"job-schedules" env:"JOB_SCHEDULES" usage:"Comma-separated job=schedule overrides of the background job schedules, or job=off (optional)"`
+
+	// ─── LEADER ELECTION ────────────────────────────────────────
+	// LeaderElectionEnabled elects one replica with a coordination.k8s.io Lease to run the
+	// leader-only background jobs; without it, every replica runs them.
+	LeaderElectionEnabled bool `config:"leader-election-enabled" env:"LEADER_ELECTION_ENABLED" usage:"Run the leader-only background jobs on one replica, elected with a Lease"`
+	// LeaderElectionNamespace is the namespace of the Lease (default: POD_NAMESPACE).
+	LeaderElectionNamespace string `config:"leader-election-namespace" env:"LEADER_ELECTION_NAMESPACE" usage:"Namespace of the leader election Lease (default: POD_NAMESPACE)"`
+	// LeaderElectionLeaseName is the name of the Lease, shared by the replicas of one
+	// deployment.
+	LeaderElectionLeaseName string `config:"leader-election-lease-name" env:"LEADER_ELECTION_LEASE_NAME" usage:"Name of the leader election Lease"`
+	// LeaderElectionLeaseDuration is how long the other replicas wait before taking over from
+	// a leader that stopped renewing the Lease, e.g. that crashed.
+	LeaderElectionLeaseDuration time.Duration `config:"leader-election-lease-duration" env:"LEADER_ELECTION_LEASE_DURATION" usage:"How long the replicas wait to take over an unrenewed leader election Lease"`
+
+	// ─── RATE LIMITING ──────────────────────────────────────────
+	// RateLimitUser is the sustained number of API requests per second allowed per user
+	// (RequestIdentity), with bursts of up to RateLimitUserBurst. Zero (default) disables it.
+	RateLimitUser      float64 `config:"rate-limit-user" env:"RATE_LIMIT_USER" usage:"API requests per second allowed per user (0 disables)"`
+	RateLimitUserBurst int     `config:"rate-limit-user-burst" env:"RATE_LIMIT_USER_BURST" usage:"Burst of API requests allowed per user (default: the rate)"`
+
+	// RateLimitIP is the same limit per client IP, checked before authentication. Behind a
+	// reverse proxy (e.g. an oauth-proxy sidecar) every request comes from the proxy's IP, so
+	// rely on the per-user limit there.
+	RateLimitIP      float64 `config:"rate-limit-ip" env:"RATE_LIMIT_IP" usage:"API requests per second allowed per client IP (0 disables)"`
+	RateLimitIPBurst int     `config:"rate-limit-ip-burst" env:"RATE_LIMIT_IP_BURST" usage:"Burst of API requests allowed per client IP (default: the rate)"`
+
+	// CostBudget is the cost of the API requests allowed per user (RequestIdentity) within
+	// any CostWindow, a sliding window. Requests cost 1, or more on expensive routes, like
+	// listing every namespace; RouteCosts overrides the costs, as "route=cost" entries (see
+	// ParseRouteCosts), e.g. "GET /api/v1/services=20". Zero (default) disables it.
+	CostBudget int           `config:"cost-budget" env:"COST_BUDGET" usage:"Cost of the API requests allowed per user within cost-window (0 disables)"`
+	CostWindow time.Duration `config:"cost-window" env:"COST_WINDOW" usage:"Sliding window of cost-budget"`
+	RouteCosts []string      `config:"route-costs" env:"ROUTE_COSTS" usage:"Comma-separated [METHOD ]/route=cost overrides of the request costs (optional)"`
+
+	// MaxConcurrentRequests caps the API requests served at once, and
+	// MaxConcurrentRequestsPerUser those of a single user (RequestIdentity). Requests over a
+	// cap wait up to ConcurrencyQueueTimeout in a queue of ConcurrencyQueueSize requests, then
+	// are shed with a 503. Streams only hold their slot until they start. Zero (default)
+	// disables each cap.
+	MaxConcurrentRequests        int `config:"max-concurrent-requests" env:"MAX_CONCURRENT_REQUESTS" usage:"API requests served at once (0 disables)"`
+	MaxConcurrentRequestsPerUser int `config:"max-concurrent-requests-per-user" env:"MAX_CONCURRENT_REQUESTS_PER_USER" usage:"API requests of a user served at once (0 disables)"`
+
+	// ConcurrencyQueueSize is how many requests wait for the global cap, and for each user's
+	// (default 100); ConcurrencyQueueTimeout how long they wait (default 1s).
+	ConcurrencyQueueSize    int           `config:"concurrency-queue-size" env:"CONCURRENCY_QUEUE_SIZE" usage:"Requests waiting for a concurrency cap before new ones are shed"`
+	ConcurrencyQueueTimeout time.Duration `config:"concurrency-queue-timeout" env:"CONCURRENCY_QUEUE_TIMEOUT" usage:"How long a request waits for a concurrency cap before it is shed"`
 
 	// ─── AUTH ───────────────────────────────────────────────────
 	// Specifies the authentication method used by the server.
-	// Valid values: "internal" or "user_token"
-	AuthMethod string
+	// Valid values: "internal", "user_token" or "impersonation"
+	AuthMethod string `config:"auth-method" env:"AUTH_METHOD" usage:"Authentication method (internal, user_token or impersonation)"`
 
 	// Header used to extract the authentication token.
 	// Default is "Authorization" and can be overridden via CLI/env for proxy integration scenarios.
-	AuthTokenHeader string
+	AuthTokenHeader string `config:"auth-token-header" env:"AUTH_TOKEN_HEADER" usage:"Header used to extract the token (e.g., Authorization)"`
 
 	// Optional prefix to strip from the token header value.
 	// Default is "Bearer ", can be set to empty if the token is sent without a prefix.
-	AuthTokenPrefix string
+	AuthTokenPrefix string `config:"auth-token-prefix" env:"AUTH_TOKEN_PREFIX" usage:"Prefix used in the token header (e.g., 'Bearer ')"`
+
+	// AdminGroups lists groups whose members are reported as cluster admins by /api/v1/user,
+	// without the cluster-admin access review. With the internal auth method the groups come
+	// from the kubeflow-groups header, so only set it behind a proxy that controls the header.
+	AdminGroups []string `config:"admin-groups" env:"ADMIN_GROUPS" usage:"Comma-separated groups whose members are cluster admins"`
+
+	// AllowedNamespaces enables the namespace-scoped mode, for installs where the BFF and its
+	// users only have namespace-scoped RBAC: namespaces are listed from this allowlist, cluster
+	// admins are the admins of every listed namespace, and cluster-scoped calls are refused.
+	// Empty (default) lets the BFF work cluster-wide.
+	AllowedNamespaces []string `config:"allowed-namespaces" env:"ALLOWED_NAMESPACES" usage:"Comma-separated namespaces the BFF is restricted to (namespace-scoped mode), default none"`
+
+	// TrustedProxyCIDRs are the peers allowed to send the identity headers of the internal and
+	// impersonation auth methods, e.g. 127.0.0.6/32 for the Istio sidecar of a Kubeflow
+	// install, so a client reaching the pod directly can't claim an identity. Requests from
+	// other peers are unauthenticated. Empty (default) trusts every peer, and is refused in
+	// Kubeflow mode.
+	TrustedProxyCIDRs []string `config:"trusted-proxy-cidrs" env:"TRUSTED_PROXY_CIDRS" usage:"Comma-separated CIDRs of the proxies allowed to send identity headers, default any (required in Kubeflow mode)"`
+
+	// ─── KUBEFLOW ───────────────────────────────────────────────
+	// KubeflowMode integrates the BFF with the Kubeflow Central Dashboard: the identity is read
+	// from the headers set by the Kubeflow auth stack, named by UserIDHeader, UserIDPrefix and
+	// GroupsHeader, and namespaces are listed from the Kubeflow Profiles (profiles.kubeflow.org)
+	// the user can access. It requires the internal or impersonation auth method.
+	KubeflowMode bool `config:"kubeflow-mode" env:"KUBEFLOW_MODE" usage:"Integrate with the Kubeflow Central Dashboard (identity headers and Profiles)"`
+
+	// UserIDHeader, UserIDPrefix and GroupsHeader are the identity headers of Kubeflow mode,
+	// configured like the Kubeflow web apps (default "kubeflow-userid", no prefix and
+	// "kubeflow-groups"). The prefix, e.g. "accounts.google.com:", is stripped from the user.
+	UserIDHeader string `config:"userid-header" env:"USERID_HEADER" usage:"Header carrying the user in Kubeflow mode"`
+	UserIDPrefix string `config:"userid-prefix" env:"USERID_PREFIX" usage:"Prefix stripped from the user header in Kubeflow mode (optional)"`
+	GroupsHeader string `config:"groups-header" env:"GROUPS_HEADER" usage:"Header carrying the comma-separated groups in Kubeflow mode"`
+
+	// ─── GUEST MODE ─────────────────────────────────────────────
+	// GuestMode maps the API requests without credentials to the synthetic identity GuestUser,
+	// member of GuestGroups, e.g. for public demos. Guests may only call the read-only endpoints
+	// marked for them, are never cluster admins, and see what RBAC grants GuestUser. It requires
+	// the internal or impersonation auth method.
+	GuestMode bool `config:"guest-mode" env:"GUEST_MODE" usage:"Serve the requests without credentials as a read-only guest"`
+
+	// GuestUser and GuestGroups are the identity of the guests (default "guest", no groups).
+	GuestUser   string   `config:"guest-user" env:"GUEST_USER" usage:"User of the guest identity"`
+	GuestGroups []string `config:"guest-groups" env:"GUEST_GROUPS" usage:"Comma-separated groups of the guest identity (optional)"`
+
+	// ─── AUTHORIZATION ──────────────────────────────────────────
+	// Authorizers lists the authorizers asked, in order, whether an API request may be served
+	// once its identity is known: "sar" (a SubjectAccessReview of the route), "policy" (the
+	// rules of AuthzPolicyFile) and "opa" (the Open Policy Agent of AuthzOPAURL). A request is
+	// served when every authorizer, and every registered one, allows it. Empty (default) only
+	// runs the registered ones.
+	Authorizers []string `config:"authorizers" env:"AUTHORIZERS" usage:"Comma-separated authorizers of the API requests, in order: sar, policy, opa (optional)"`
+
+	// AuthzGroup is the API group of the routes reviewed by the sar authorizer (default
+	// "bff.opendatahub.io").
+	AuthzGroup string `config:"authz-group" env:"AUTHZ_GROUP" usage:"API group of the routes reviewed by the sar authorizer"`
+
+	// AuthzPolicyFile is the YAML policy file of the policy authorizer (see authz.Policy),
+	// reloaded when it changes.
+	AuthzPolicyFile string `config:"authz-policy-file" env:"AUTHZ_POLICY_FILE" usage:"YAML rules of the policy authorizer, reloaded on change"`
+
+	// AuthzOPAURL is the decision URL of the opa authorizer, e.g.
+	// http://localhost:8181/v1/data/bff/authz/allow for a sidecar.
+	AuthzOPAURL string `config:"authz-opa-url" env:"AUTHZ_OPA_URL" usage:"Decision URL of the Open Policy Agent of the opa authorizer"`
+
+	// ─── REVIEW CACHE ───────────────────────────────────────────
+	// ReviewCacheSubjectTTL caches who a token (or impersonated user) authenticates as, from
+	// SelfSubjectReviews keyed by a hash of the token, instead of a review per request.
+	// Zero (default) disables it.
+	ReviewCacheSubjectTTL time.Duration `config:"review-cache-subject-ttl" env:"REVIEW_CACHE_SUBJECT_TTL" usage:"Lifetime of cached SelfSubjectReview results (0 disables)"`
+
+	// ReviewCacheAllowedTTL and ReviewCacheDeniedTTL cache allowed and denied access reviews
+	// per identity and attributes. Zero (default) disables caching of those results.
+	ReviewCacheAllowedTTL time.Duration `config:"review-cache-allowed-ttl" env:"REVIEW_CACHE_ALLOWED_TTL" usage:"Lifetime of cached allowed access reviews (0 disables)"`
+	ReviewCacheDeniedTTL  time.Duration `config:"review-cache-denied-ttl" env:"REVIEW_CACHE_DENIED_TTL" usage:"Lifetime of cached denied access reviews (0 disables)"`
+
+	// ReviewCacheMaxEntries bounds the review cache (default 10000).
+	ReviewCacheMaxEntries int `config:"review-cache-max-entries" env:"REVIEW_CACHE_MAX_ENTRIES" usage:"Maximum number of cached authentication and access reviews"`
+
+	// ─── IDENTITY CACHE ─────────────────────────────────────────
+	// IdentityCacheTTL caches the user, groups and cluster-admin status resolved for an
+	// identity, looked up on nearly every page load. Concurrent lookups of one identity always
+	// share a single round trip; zero (default) caches nothing beyond that.
+	IdentityCacheTTL time.Duration `config:"identity-cache-ttl" env:"IDENTITY_CACHE_TTL" usage:"Lifetime of cached identity lookups (0 only shares concurrent lookups)"`
+
+	// IdentityCacheMaxEntries bounds the identity cache (default 10000).
+	IdentityCacheMaxEntries int `config:"identity-cache-max-entries" env:"IDENTITY_CACHE_MAX_ENTRIES" usage:"Maximum number of cached identity lookups"`
+
+	// ─── CORS ───────────────────────────────────────────────────
+	// AllowedOrigins enables CORS for these origins: "*" for any origin, or patterns with one
+	// wildcard such as "https://*.apps.example.com". Empty (default) disables CORS.
+	AllowedOrigins []string `config:"allowed-origins" env:"ALLOWED_ORIGINS" usage:"Sets allowed origins for CORS purposes, accepts a comma separated list of origins or * to allow all, default none"`
+
+	// CORSAllowedMethods are the methods allowed cross-origin (default GET, PUT, POST, PATCH, DELETE).
+	CORSAllowedMethods []string `config:"cors-allowed-methods" env:"CORS_ALLOWED_METHODS" usage:"Comma-separated methods allowed in CORS requests"`
+
+	// CORSAllowedHeaders and CORSExposedHeaders add request and response headers to the ones
+	// the BFF uses (identity, tracing, request ID, ETag and CSRF headers).
+	CORSAllowedHeaders []string `config:"cors-allowed-headers" env:"CORS_ALLOWED_HEADERS" usage:"Comma-separated request headers allowed in CORS requests, besides the BFF ones (optional)"`
+	CORSExposedHeaders []string `config:"cors-exposed-headers" env:"CORS_EXPOSED_HEADERS" usage:"Comma-separated response headers exposed to CORS requests, besides the BFF ones (optional)"`
+
+	// CORSAllowCredentials lets cross-origin pages send cookies (default true).
+	CORSAllowCredentials bool `config:"cors-allow-credentials" env:"CORS_ALLOW_CREDENTIALS" usage:"Allow credentials (cookies) in CORS requests"`
+
+	// CORSMaxAge is how long browsers cache preflight responses (default 10m).
+	CORSMaxAge time.Duration `config:"cors-max-age" env:"CORS_MAX_AGE" usage:"How long browsers may cache CORS preflight responses"`
+
+	// ─── CSRF ───────────────────────────────────────────────────
+	// CSRFEnabled protects the API with a double-submit cookie, for deployments where the
+	// browser authenticates with a session cookie (e.g. behind oauth-proxy): safe requests get
+	// a token cookie, and mutating requests must echo it in CSRFHeader. Requests without any
+	// cookie, i.e. API clients sending a token, are not checked.
+	CSRFEnabled bool `config:"csrf-enabled" env:"CSRF_ENABLED" usage:"Require a CSRF token on mutating API requests of cookie-authenticated clients"`
+
+	// CSRFCookieName and CSRFHeader name the token cookie and request header
+	// (default "csrf_token" and "X-CSRF-Token").
+	CSRFCookieName string `config:"csrf-cookie-name" env:"CSRF_COOKIE_NAME" usage:"Name of the CSRF token cookie"`
+	CSRFHeader     string `config:"csrf-header" env:"CSRF_HEADER" usage:"Header carrying the CSRF token on mutating requests"`
+
+	// CSRFSameSite is the SameSite attribute of the token cookie: lax (default), strict or none.
+	CSRFSameSite string `config:"csrf-same-site" env:"CSRF_SAME_SITE" usage:"SameSite attribute of the CSRF cookie (lax, strict or none)"`
+
+	// CSRFExemptPaths lists API path prefixes not checked, e.g. webhooks called by other services.
+	CSRFExemptPaths []string `config:"csrf-exempt-paths" env:"CSRF_EXEMPT_PATHS" usage:"Comma-separated API path prefixes exempt from the CSRF check (optional)"`
+
+	// ─── SECURITY HEADERS ───────────────────────────────────────
+	// SecurityHeaders sets the headers below on the API and frontend responses (default true).
+	// Each header is omitted when its setting is empty (zero for HSTSMaxAge).
+	SecurityHeaders bool `config:"security-headers" env:"SECURITY_HEADERS" usage:"Set security headers (HSTS, CSP, X-Frame-Options, ...) on responses"`
+
+	// HSTSMaxAge is the max-age of Strict-Transport-Security (default one year). Browsers only
+	// honor it over HTTPS, including when TLS is terminated by a proxy in front.
+	HSTSMaxAge time.Duration `config:"hsts-max-age" env:"HSTS_MAX_AGE" usage:"max-age of the Strict-Transport-Security header (0 omits it)"`
+
+	// FrameOptions is the X-Frame-Options header (default DENY); use SAMEORIGIN when the
+	// frontend is embedded in a frame of the same origin.
+	FrameOptions string `config:"frame-options" env:"FRAME_OPTIONS" usage:"X-Frame-Options header (DENY or SAMEORIGIN, empty omits it)"`
+
+	// ReferrerPolicy is the Referrer-Policy header (default strict-origin-when-cross-origin).
+	ReferrerPolicy string `config:"referrer-policy" env:"REFERRER_POLICY" usage:"Referrer-Policy header (empty omits it)"`
+
+	// ContentSecurityPolicy is the Content-Security-Policy header (see
+	// DefaultContentSecurityPolicy). "{nonce}" is replaced with a random nonce per response,
+	// which the BFF also adds to the <script> tags of index.html.
+	ContentSecurityPolicy string `config:"content-security-policy" env:"CONTENT_SECURITY_POLICY" usage:"Content-Security-Policy header, {nonce} is replaced with a per-response nonce (empty omits it)"`
+
+	// ─── COMPRESSION ────────────────────────────────────────────
+	// Compression compresses the API and frontend responses with zstd or gzip, as accepted by
+	// the client (default true). Streams (SSE, WebSockets) and responses already encoded
+	// upstream are never compressed.
+	Compression bool `config:"compression" env:"COMPRESSION" usage:"Compress responses with zstd or gzip, as negotiated from Accept-Encoding"`
+
+	// CompressionMinSize is the body size from which a response is compressed (default 1 KiB):
+	// smaller bodies gain little and cost CPU on both ends.
+	CompressionMinSize int `config:"compression-min-size" env:"COMPRESSION_MIN_SIZE" usage:"Minimum body size in bytes of a compressed response"`
+
+	// CompressionContentTypes lists the media types compressed, as "type/subtype" or "type/*"
+	// (see DefaultCompressionContentTypes). Images other than SVG, archives and other formats
+	// already compressed should not be listed.
+	CompressionContentTypes []string `config:"compression-content-types" env:"COMPRESSION_CONTENT_TYPES" usage:"Comma-separated media types compressed, e.g. application/json or text/*"`
+
+	// ─── FRONTEND ───────────────────────────────────────────────
+	// FrontendFeatures are feature flags without targeting rules, as "name" (enabled) or
+	// "name=false" entries, published to the frontend by /api/v1/config and /api/v1/features.
+	FrontendFeatures []string `config:"frontend-features" env:"FRONTEND_FEATURES" usage:"Comma-separated feature flags published to the frontend, as name or name=false (optional)"`
+
+	// FeatureFlagsFile is a YAML file of feature flags with targeting rules (see
+	// featureflags.File), typically mounted from a ConfigMap and reloaded when it changes. Its
+	// flags replace the FrontendFeatures of the same name.
+	FeatureFlagsFile string `config:"feature-flags-file" env:"FEATURE_FLAGS_FILE" usage:"YAML file of feature flags with targeting rules, reloaded on change (optional)"`
+
+	// FrontendServiceURLs publishes the URLs of upstream services the frontend calls directly,
+	// as "name=url" entries with an absolute http(s) URL or a path such as "/model-registry".
+	FrontendServiceURLs []string `config:"frontend-service-urls" env:"FRONTEND_SERVICE_URLS" usage:"Comma-separated name=url upstream service URLs published to the frontend (optional)"`
+
+	// FrontendDevServerURL proxies the frontend (every non-API path, WebSocket upgrades
+	// included) to a local dev server, e.g. webpack's at http://localhost:9000, so it is
+	// live-reloaded while calling the BFF APIs. Dev mode only; it replaces StaticAssetsDir.
+	FrontendDevServerURL string `config:"frontend-dev-server-url" env:"FRONTEND_DEV_SERVER_URL" usage:"Proxy the frontend to this dev server URL, e.g. http://localhost:9000 (dev mode only)"`
+
+	// UIModuleRemoteEntries sets the module federation remote entries of the UI modules listed
+	// by /api/v1/modules, as "name=url" entries. They replace the remote entry of a registered
+	// module of the same name, e.g. to serve it from another origin, and add the modules only
+	// deployed as frontends.
+	UIModuleRemoteEntries []string `config:"ui-module-remote-entries" env:"UI_MODULE_REMOTE_ENTRIES" usage:"Comma-separated name=url remote entries of the federated UI modules (optional)"`
+
+	// MessageCatalogDir is a directory of <locale>.json translations of the error messages
+	// (see package i18n), e.g. mounted from a ConfigMap, adding locales to the built-in ones
+	// or overriding their messages.
+	MessageCatalogDir string `config:"message-catalog-dir" env:"MESSAGE_CATALOG_DIR" usage:"Directory of <locale>.json translations of the error messages (optional)"`
+
+	// ─── OIDC ───────────────────────────────────────────────────
+	// OIDCIssuerURL enables JWT validation of the auth token header against this OIDC issuer.
+	// When set, the token is verified (signature via the issuer's JWKS, iss, aud, exp) and the
+	// RequestIdentity is populated from its claims. Empty disables OIDC validation.
+	OIDCIssuerURL string `config:"oidc-issuer-url" env:"OIDC_ISSUER_URL" usage:"OIDC issuer URL used to validate JWT auth tokens (optional)"`
+
+	// OIDCClientID is the expected token audience, in their aud or azp claim. Required with
+	// OIDCIssuerURL, so the tokens the issuer mints for other clients are refused.
+	OIDCClientID string `config:"oidc-client-id" env:"OIDC_CLIENT_ID" usage:"Expected audience of OIDC tokens (required with oidc-issuer-url)"`
+
+	// OIDCUsernameClaim is the claim mapped to the user ID (default "sub").
+	OIDCUsernameClaim string `config:"oidc-username-claim" env:"OIDC_USERNAME_CLAIM" usage:"OIDC claim used as the user ID"`
+
+	// OIDCGroupsClaim is the claim mapped to the user's groups (default "groups").
+	OIDCGroupsClaim string `config:"oidc-groups-claim" env:"OIDC_GROUPS_CLAIM" usage:"OIDC claim used as the user groups"`
+
+	// ─── SESSIONS ───────────────────────────────────────────────
+	// SessionEnabled signs browser users in at the OIDC issuer (authorization code flow with
+	// PKCE, client OIDCClientID) under /api/v1/login, and keeps their tokens in a session
+	// cookie authenticating their API requests. Tokens are refreshed before they expire, and
+	// /api/v1/logout revokes them. Requires OIDCIssuerURL and CSRFEnabled.
+	SessionEnabled bool `config:"session-enabled" env:"SESSION_ENABLED" usage:"Sign users in with the OIDC issuer and keep their tokens in a session cookie"`
+
+	// SessionStore keeps the sessions sealed in their cookie ("cookie", the default, served by
+	// any replica), or in memory ("memory", for tokens too large for a cookie; single replica or
+	// sticky sessions).
+	SessionStore string `config:"session-store" env:"SESSION_STORE" usage:"Where sessions are kept: cookie or memory"`
+
+	// SessionSecret encrypts the session and sign-in cookies, at least 32 characters. Changing
+	// it ends the current sessions.
+	SessionSecret string `config:"session-secret" env:"SESSION_SECRET" usage:"Secret encrypting the session cookies (at least 32 characters)" secret:"true"`
+
+	// SessionCookieName names the session cookie (default "modarch_session"); the state of
+	// sign-ins in progress is kept in the cookie of the same name suffixed with "_login".
+	SessionCookieName string `config:"session-cookie-name" env:"SESSION_COOKIE_NAME" usage:"Name of the session cookie"`
+
+	// SessionIdleTimeout ends sessions without requests for this long (default 30m), and
+	// SessionAbsoluteTimeout ends them this long after the sign-in (default 12h).
+	SessionIdleTimeout     time.Duration `config:"session-idle-timeout" env:"SESSION_IDLE_TIMEOUT" usage:"End sessions without requests for this long"`
+	SessionAbsoluteTimeout time.Duration `config:"session-absolute-timeout" env:"SESSION_ABSOLUTE_TIMEOUT" usage:"End sessions this long after the sign-in"`
+
+	// SessionRefreshBefore refreshes the tokens of a session this long before they expire
+	// (default 1m).
+	SessionRefreshBefore time.Duration `config:"session-refresh-before" env:"SESSION_REFRESH_BEFORE" usage:"Refresh session tokens this long before they expire"`
+
+	// OAuthClientSecret authenticates the BFF at the token endpoint; empty for a public client.
+	OAuthClientSecret string `config:"oauth-client-secret" env:"OAUTH_CLIENT_SECRET" usage:"OAuth client secret of the sign-in (optional for public clients)" secret:"true"`
+
+	// OAuthRedirectURL is the absolute URL of /api/v1/login/callback registered at the issuer,
+	// e.g. https://dashboard.example.com/api/v1/login/callback.
+	OAuthRedirectURL string `config:"oauth-redirect-url" env:"OAUTH_REDIRECT_URL" usage:"Absolute URL of /api/v1/login/callback registered at the OIDC issuer"`
+
+	// OAuthScopes are the scopes requested at sign-in (default openid, profile and email). Some
+	// issuers only issue refresh tokens with offline_access.
+	OAuthScopes []string `config:"oauth-scopes" env:"OAUTH_SCOPES" usage:"Comma-separated scopes requested at sign-in"`
+
+	// ─── OBSERVABILITY ──────────────────────────────────────────
+	// MetricsEnabled exposes Prometheus metrics on /metrics and instruments HTTP requests
+	// and Kubernetes API server calls.
+	MetricsEnabled bool `config:"metrics-enabled" env:"METRICS_ENABLED" usage:"Expose Prometheus metrics on /metrics"`
+
+	// TracingEnabled exports OpenTelemetry traces of HTTP requests, repositories and Kubernetes
+	// API server calls over OTLP/HTTP. The exporter is configured with the standard OTEL_*
+	// environment variables (e.g. OTEL_EXPORTER_OTLP_ENDPOINT).
+	TracingEnabled bool `config:"tracing-enabled" env:"TRACING_ENABLED" usage:"Export OpenTelemetry traces over OTLP (configured with OTEL_* env vars)"`
+
+	// PanicReportDSN forwards every panic recovered while serving a request to the error
+	// tracker of this Sentry DSN (Sentry, GlitchTip, ...). The DSN carries the project key.
+	PanicReportDSN string `config:"panic-report-dsn" env:"PANIC_REPORT_DSN" usage:"Sentry-compatible DSN receiving the reports of recovered panics (optional)" secret:"true"`
+
+	// DebugEndpoints serves the pprof profiles, the expvar variables and the runtime
+	// statistics under /api/v1/debug, to cluster admins only.
+	DebugEndpoints bool `config:"debug-endpoints" env:"DEBUG_ENDPOINTS" usage:"Serve pprof, expvar and runtime statistics to cluster admins under /api/v1/debug"`
+
+	// Preflight checks the permissions of the service account, the reachability of the
+	// upstreams and the TLS files at startup: "off", "warn" (default, logs the problems) or
+	// "fail" (exits on a failed check). With DebugEndpoints the checks also run on demand at
+	// /api/v1/debug/preflight.
+	Preflight string `config:"preflight" env:"PREFLIGHT" usage:"Startup preflight checks: off, warn (log the problems) or fail (exit when a check fails)"`
+
+	// AdminPort serves the debug endpoints on their own listener rather than the API port, so
+	// they can be kept off the Route or Ingress. 0 serves them on the API port.
+	AdminPort int `config:"admin-port" env:"ADMIN_PORT" usage:"Separate listen port of the debug endpoints (default the API port)"`
+
+	// ChaosEnabled serves /api/v1/debug/chaos, where cluster admins set faults injected into
+	// the requests of routes and the calls to upstreams, to test the frontend against a slow
+	// or failing backend. Development only: it requires DevMode. No faults are injected until
+	// rules are set.
+	ChaosEnabled bool `config:"chaos-enabled" env:"CHAOS_ENABLED" usage:"Serve the fault injection rules on /api/v1/debug/chaos (dev mode only)"`
+
+	// ─── BODY LOGGING ───────────────────────────────────────────
+	// BodyLogRoutes logs the request and response bodies of these routes, for incident analysis:
+	// route patterns, optionally preceded by a method (see ParseBodyLogRoutes), or "*" for every
+	// route. The values of redacted headers and of BodyLogRedactedFields, and the Secret data,
+	// are never logged. Default none.
+	BodyLogRoutes []string `config:"body-log-routes" env:"BODY_LOG_ROUTES" usage:"Comma-separated [METHOD ]/route routes whose request and response bodies are logged, or * for all (default none)" reload:"true"`
+
+	// BodyLogSampleRate is the fraction of the requests of BodyLogRoutes logged (default 1, all).
+	BodyLogSampleRate float64 `config:"body-log-sample-rate" env:"BODY_LOG_SAMPLE_RATE" usage:"Fraction of the requests of body-log-routes whose bodies are logged, from 0 to 1" reload:"true"`
+
+	// BodyLogMaxSize truncates the logged bodies to this many bytes (default 4 KiB).
+	BodyLogMaxSize int `config:"body-log-max-size" env:"BODY_LOG_MAX_SIZE" usage:"Size in bytes from which logged bodies are truncated"`
+
+	// BodyLogRedactedFields lists path.Match patterns of the JSON fields whose values are
+	// redacted in logged bodies, matched case-insensitively at any depth (see
+	// DefaultBodyLogRedactedFields).
+	BodyLogRedactedFields []string `config:"body-log-redacted-fields" env:"BODY_LOG_REDACTED_FIELDS" usage:"Comma-separated patterns of the JSON fields redacted in logged bodies"`
+
+	// ─── KUBERNETES CLIENT ──────────────────────────────────────
+	// KubeAPIQPS and KubeAPIBurst rate limit the API server requests of each Kubernetes client
+	// on the client side (default 50 and 100). With user_token and impersonation auth each
+	// request has its own client, so they bound a single request; with internal auth they are
+	// shared by every request.
+	KubeAPIQPS   float64 `config:"kube-api-qps" env:"KUBE_API_QPS" usage:"Sustained API server requests per second of a Kubernetes client"`
+	KubeAPIBurst int     `config:"kube-api-burst" env:"KUBE_API_BURST" usage:"Burst of API server requests of a Kubernetes client"`
+
+	// KubeAPITimeout bounds each API server request (default 1m). Watches, followed logs and
+	// upgraded connections are not bounded. Zero disables it.
+	KubeAPITimeout time.Duration `config:"kube-api-timeout" env:"KUBE_API_TIMEOUT" usage:"Timeout of API server requests, but watches and streams (0 disables)"`
+
+	// KubeAPIMaxIdleConns and KubeAPIMaxConns size the connection pool to the API
+	// server (default 25 idle connections, unlimited connections).
+	KubeAPIMaxIdleConns int `config:"kube-api-max-idle-conns" env:"KUBE_API_MAX_IDLE_CONNS" usage:"Idle connections kept open to the API server"`
+	KubeAPIMaxConns     int `config:"kube-api-max-conns" env:"KUBE_API_MAX_CONNS" usage:"Maximum connections to the API server (0 is unlimited)"`
+
+	// ─── CLUSTERS ───────────────────────────────────────────────
+	// ClusterName names the cluster of the backend kubeconfig (default "local"): the cluster
+	// of API requests without a ?cluster= parameter.
+	ClusterName string `config:"cluster-name" env:"CLUSTER_NAME" usage:"Name of the local cluster in /api/v1/clusters"`
+
+	// ClusterContexts adds clusters from the contexts of the kubeconfig, as name=context.
+	ClusterContexts []string `config:"cluster-contexts" env:"CLUSTER_CONTEXTS" usage:"Comma-separated name=context kubeconfig contexts of other clusters (optional)"`
+
+	// ClusterSecrets adds clusters from kubeconfigs stored in Secrets (under the "kubeconfig" or
+	// "value" key, as written by Cluster API and Open Cluster Management), as
+	// name=namespace/secret. The Secrets are read once at startup with the backend credentials.
+	ClusterSecrets []string `config:"cluster-secrets" env:"CLUSTER_SECRETS" usage:"Comma-separated name=namespace/secret kubeconfig Secrets of other clusters (optional)"`
+
+	// ─── CLUSTER HEALTH ─────────────────────────────────────────
+	// HealthComponents lists the components of /api/v1/cluster-health, as
+	// name=namespace/selector: the Deployments and Pods matching the label selector (without
+	// commas) in the namespace.
+	HealthComponents []string `config:"health-components" env:"HEALTH_COMPONENTS" usage:"Comma-separated name=namespace/selector components reported by /api/v1/cluster-health (optional)"`
+
+	// HealthOperators lists the operator resources of /api/v1/cluster-health, as
+	// name=resource.version.group/[namespace/]object, reported by their status conditions.
+	HealthOperators []string `config:"health-operators" env:"HEALTH_OPERATORS" usage:"Comma-separated name=resource.version.group/[namespace/]object operator resources reported by /api/v1/cluster-health (optional)"`
+
+	// ─── RESILIENCE ─────────────────────────────────────────────
+	// UpstreamMaxRetries is the number of retries of idempotent Kubernetes and upstream HTTP
+	// calls after a connection error, 429 or 5xx (default 2, 0 disables), with a jittered
+	// exponential backoff between UpstreamRetryMinBackoff and UpstreamRetryMaxBackoff.
+	UpstreamMaxRetries      int           `config:"upstream-max-retries" env:"UPSTREAM_MAX_RETRIES" usage:"Retries of idempotent upstream calls after a transient error (0 disables)"`
+	UpstreamRetryMinBackoff time.Duration `config:"upstream-retry-min-backoff" env:"UPSTREAM_RETRY_MIN_BACKOFF" usage:"Backoff before the first retry of an upstream call"`
+	UpstreamRetryMaxBackoff time.Duration `config:"upstream-retry-max-backoff" env:"UPSTREAM_RETRY_MAX_BACKOFF" usage:"Maximum backoff between retries of an upstream call"`
+
+	// CircuitBreakerFailures consecutive failures of an upstream open its circuit: calls fail
+	// fast with 503 for CircuitBreakerCooldown, then a probe call closes it again or not
+	// (default 5 and 30s, 0 disables).
+	CircuitBreakerFailures int           `config:"circuit-breaker-failures" env:"CIRCUIT_BREAKER_FAILURES" usage:"Consecutive failures opening the circuit of an upstream (0 disables)"`
+	CircuitBreakerCooldown time.Duration `config:"circuit-breaker-cooldown" env:"CIRCUIT_BREAKER_COOLDOWN" usage:"How long an open circuit fails calls before a probe"`
+
+	// ─── CACHE ──────────────────────────────────────────────────
+	// CacheResources lists the resources ("namespaces", "services") served from a shared
+	// informer cache instead of a LIST per request. Empty disables the cache.
+	// Only used with the "internal" auth method; the backend credentials need list/watch
+	// on the cached resources cluster-wide.
+	CacheResources []string `config:"cache-resources" env:"CACHE_RESOURCES" usage:"Comma-separated resources served from an informer cache: namespaces, services (internal auth only, default none)"`
+
+	// CacheResyncPeriod is how often the informers resync their cache (default 10m).
+	CacheResyncPeriod time.Duration `config:"cache-resync-period" env:"CACHE_RESYNC_PERIOD" usage:"Resync period of the informer cache"`
+
+	// WatchMultiplexing shares one Kubernetes watch per resource and namespace between the
+	// streams of /api/v1/watch, with the events filtered per user. Only used with the
+	// "internal" auth method; the backend credentials need watch on the watched resources.
+	WatchMultiplexing bool `config:"watch-multiplexing" env:"WATCH_MULTIPLEXING" usage:"Share one Kubernetes watch per resource and namespace between watch streams (internal auth only)"`
+
+	// ─── RESPONSE CACHE ─────────────────────────────────────────
+	// ResponseCacheTTL enables the response cache of the repositories that opt into it (e.g.
+	// /api/v1/services), per identity and namespace. Entries expire after the TTL, or earlier
+	// when an informer cached resource (-cache-resources) changes. Zero (default) disables it.
+	ResponseCacheTTL time.Duration `config:"response-cache-ttl" env:"RESPONSE_CACHE_TTL" usage:"Lifetime of cached API responses (0 disables the response cache)"`
+
+	// ResponseCacheMaxEntries bounds the in-memory response cache (default 1000).
+	ResponseCacheMaxEntries int `config:"response-cache-max-entries" env:"RESPONSE_CACHE_MAX_ENTRIES" usage:"Maximum number of cached API responses"`
+
+	// ─── WARMUP ─────────────────────────────────────────────────
+	// WarmupNamespaces lists the namespaces whose caches are primed on startup, before /readyz
+	// passes: the namespaces and their services are read, and the access of the WarmupUsers is
+	// reviewed. Empty (default) disables the warmup. Only used with the "internal" auth method.
+	WarmupNamespaces []string `config:"warmup-namespaces" env:"WARMUP_NAMESPACES" usage:"Comma-separated namespaces whose caches are primed before turning ready (internal auth only)"`
+
+	// WarmupUsers lists the identities primed in every warmup namespace, as "user" or
+	// "user=group1;group2" since the cached reviews are per user and groups. Optional.
+	WarmupUsers []string `config:"warmup-users" env:"WARMUP_USERS" usage:"Comma-separated identities (user or user=group1;group2) whose permissions are primed"`
+
+	// WarmupTimeout bounds the warmup: once it elapses the BFF turns ready with the caches
+	// still cold (default 2m).
+	WarmupTimeout time.Duration `config:"warmup-timeout" env:"WARMUP_TIMEOUT" usage:"How long the cache warmup may delay readiness"`
+
+	// ─── SERVICE DISCOVERY ──────────────────────────────────────
+	// ServiceLabelSelector selects the backend Services listed by /api/v1/services
+	// (label selector syntax, default "component=mod-arch").
+	ServiceLabelSelector string `config:"service-label-selector" env:"SERVICE_LABEL_SELECTOR" usage:"Label selector of the backend Services listed by /api/v1/services"`
+
+	// ServiceAnnotationSelector additionally filters those Services by annotations, using the
+	// label selector syntax (e.g. "displayName" or "routing.opendatahub.io/enabled=true"). Optional.
+	ServiceAnnotationSelector string `config:"service-annotation-selector" env:"SERVICE_ANNOTATION_SELECTOR" usage:"Annotation selector (label selector syntax) further filtering the listed Services (optional)"`
+
+	// ServiceURLSources lists the routing objects the external URLs of the listed Services are
+	// resolved from, most preferred first: route, ingress, httproute (default all, in that
+	// order). Empty disables the resolution.
+	ServiceURLSources []string `config:"service-url-sources" env:"SERVICE_URL_SOURCES" usage:"Comma-separated sources of the external URLs of the listed Services, most preferred first: route, ingress, httproute"`
+
+	// ─── MODEL REGISTRY ─────────────────────────────────────────
+	// ModelRegistryURL is the server URL of the model registry used by the /api/v1/model_registry
+	// endpoints instead of the Service named in the path, e.g. a port-forwarded registry during
+	// development ("http://localhost:8080"). Empty (default) resolves the Service.
+	ModelRegistryURL string `config:"model-registry-url" env:"MODEL_REGISTRY_URL" usage:"Model registry server URL used instead of Service discovery (optional, development)"`
+
+	// ─── ODH DASHBOARD ──────────────────────────────────────────
+	// ODHDashboardNamespace is the namespace of the OdhDashboardConfig, OdhApplications and
+	// AcceleratorProfiles read by App.ODHDashboard (default "opendatahub";
+	// "redhat-ods-applications" on OpenShift AI).
+	ODHDashboardNamespace string `config:"odh-dashboard-namespace" env:"ODH_DASHBOARD_NAMESPACE" usage:"Namespace of the OpenDataHub dashboard settings"`
+
+	// ODHDashboardCacheTTL is how long the dashboard settings are cached per user (default 1m,
+	// 0 disables the cache).
+	ODHDashboardCacheTTL time.Duration `config:"odh-dashboard-cache-ttl" env:"ODH_DASHBOARD_CACHE_TTL" usage:"Lifetime of the cached OpenDataHub dashboard settings (0 disables caching)"`
+
+	// ─── NAMESPACE PROVISIONING ─────────────────────────────────
+	// NamespaceProvisioning enables POST /api/v1/namespaces, which creates a namespace and its
+	// companion resources from a template as an operation (default false). The requester must
+	// be allowed to create namespaces.
+	NamespaceProvisioning bool `config:"namespace-provisioning" env:"NAMESPACE_PROVISIONING" usage:"Enable the provisioning of namespaces from templates"`
+
+	// NamespaceTemplatesDir is a directory of <name>.yaml namespace templates (see package
+	// provisioning), e.g. mounted from a ConfigMap. Empty (default) uses the built-in
+	// "default" template of an OpenDataHub data science project.
+	NamespaceTemplatesDir string `config:"namespace-templates-dir" env:"NAMESPACE_TEMPLATES_DIR" usage:"Directory of the namespace templates (default: the built-in templates)"`
+
+	// ─── SERVICE ACCOUNT TOKENS ─────────────────────────────────
+	// ServiceAccountTokens enables POST /api/v1/serviceaccounts/:name/token, which mints
+	// short-lived tokens of a ServiceAccount through the TokenRequest API (default false), e.g.
+	// to connect external tools to a model registry. The requester must be allowed to create
+	// serviceaccounts/token in the namespace; every issuance is audited.
+	ServiceAccountTokens bool `config:"service-account-tokens" env:"SERVICE_ACCOUNT_TOKENS" usage:"Enable the minting of ServiceAccount tokens"`
+
+	// ServiceAccountTokenMaxTTL is the longest validity of the minted tokens, and the validity
+	// of the tokens requested without one (default 1h, at least 10m).
+	ServiceAccountTokenMaxTTL time.Duration `config:"service-account-token-max-ttl" env:"SERVICE_ACCOUNT_TOKEN_MAX_TTL" usage:"Longest validity of the minted ServiceAccount tokens"`
+
+	// ServiceAccountTokenAudiences lists the audiences the minted tokens may be scoped to, and
+	// is required with ServiceAccountTokens: every token is scoped to some of them, never to
+	// the API server.
+	ServiceAccountTokenAudiences []string `config:"service-account-token-audiences" env:"SERVICE_ACCOUNT_TOKEN_AUDIENCES" usage:"Comma-separated audiences the minted ServiceAccount tokens may be scoped to (required with service-account-tokens)"`
+
+	// ─── SECRETS AND CONFIGMAPS ─────────────────────────────────
+	// RedactedConfigMapKeys lists path.Match patterns of the ConfigMap keys whose values are
+	// redacted by /api/v1/configmaps, like Secret values (e.g. "*password*,*token*").
+	RedactedConfigMapKeys []string `config:"redacted-configmap-keys" env:"REDACTED_CONFIGMAP_KEYS" usage:"Comma-separated patterns of the ConfigMap keys redacted like Secret values (optional)"`
+
+	// RevealVerb is the verb checked on secrets or configmaps before redacted values are
+	// returned (default "get"). A custom verb such as "reveal" must be granted explicitly.
+	RevealVerb string `config:"reveal-verb" env:"REVEAL_VERB" usage:"Verb required on secrets or configmaps to reveal redacted values"`
+
+	// ─── OBJECT STORAGE ─────────────────────────────────────────
+	// ObjectStorageDownloadLimit and ObjectStorageUploadLimit are the largest objects, in
+	// bytes, streamed through the BFF by /api/v1/object-storage (default 5 GiB, the most a
+	// single upload can write). 0 disables the downloads or uploads; the browser can still
+	// transfer the objects with presigned URLs.
+	ObjectStorageDownloadLimit int64 `config:"object-storage-download-limit" env:"OBJECT_STORAGE_DOWNLOAD_LIMIT" usage:"Largest object downloaded through the BFF, in bytes (0 disables downloads)"`
+	ObjectStorageUploadLimit   int64 `config:"object-storage-upload-limit" env:"OBJECT_STORAGE_UPLOAD_LIMIT" usage:"Largest object uploaded through the BFF, in bytes (0 disables uploads, at most 5 GiB)"`
+
+	// ObjectStoragePresignExpiry is the longest validity of the presigned URLs of objects; it
+	// is also the validity of those that don't ask for a shorter one (default 15m, at most 7 days).
+	ObjectStoragePresignExpiry time.Duration `config:"object-storage-presign-expiry" env:"OBJECT_STORAGE_PRESIGN_EXPIRY" usage:"Longest validity of the presigned URLs of objects"`
+
+	// ObjectStorageAllowedHosts are the endpoint hosts of the data connections called even
+	// though they resolve to private, loopback or link-local addresses, e.g. an in-cluster
+	// MinIO service. The endpoints come from Secrets users may edit, so by default the BFF only
+	// calls public addresses, never e.g. the cloud metadata service.
+	ObjectStorageAllowedHosts []string `config:"object-storage-allowed-hosts" env:"OBJECT_STORAGE_ALLOWED_HOSTS" usage:"Comma-separated object storage hosts the BFF may call at private addresses, default none"`
+
+	// ─── USER PREFERENCES ───────────────────────────────────────
+	// PreferencesNamespace is the namespace of the ConfigMaps storing the UI preferences of
+	// the users (/api/v1/user/preferences). Defaults to the namespace of the BFF pod
+	// (POD_NAMESPACE); without either the preferences endpoint is unavailable.
+	PreferencesNamespace string `config:"preferences-namespace" env:"PREFERENCES_NAMESPACE" usage:"Namespace of the ConfigMaps storing user preferences (default: POD_NAMESPACE)"`
+
+	// ─── NOTIFICATIONS ──────────────────────────────────────────
+	// NotificationHistory is the number of recent notifications kept, and replayed to the
+	// clients connecting to /api/v1/notifications/stream (default 100, 0 keeps none).
+	NotificationHistory int `config:"notification-history" env:"NOTIFICATION_HISTORY" usage:"Number of recent notifications replayed to connecting clients"`
+
+	// ─── OPERATIONS ─────────────────────────────────────────────
+	// OperationRetention is how long completed operations can still be polled on
+	// /api/v1/operations/{id} before they are pruned (default 1h).
+	OperationRetention time.Duration `config:"operation-retention" env:"OPERATION_RETENTION" usage:"How long completed operations are kept for polling"`
+
+	// ─── DATABASE ───────────────────────────────────────────────
+	// DatabaseDriver and DatabaseURL enable the relational storage of the BFF-local state of
+	// modules (see database.Open): the database/sql driver, pgx or sqlite as shipped, or
+	// postgres and sqlite3 when a downstream build imports them, and its data source name. Both are empty by default: the BFF
+	// is stateless and modules needing a database are unavailable.
+	DatabaseDriver string `config:"database-driver" env:"DATABASE_DRIVER" usage:"database/sql driver of the module state: pgx or sqlite, or postgres and sqlite3 when compiled in (default none)"`
+	DatabaseURL    string `config:"database-url" env:"DATABASE_URL" usage:"Data source name of the database of the module state" secret:"true"`
+
+	// DatabaseMaxOpenConns bounds the connections to the database (default 10).
+	DatabaseMaxOpenConns int `config:"database-max-open-conns" env:"DATABASE_MAX_OPEN_CONNS" usage:"Maximum number of open connections to the database"`
+
+	// ─── GRAPHQL ────────────────────────────────────────────────
+	// GraphQLEnabled serves /api/v1/graphql, a GraphQL schema composing the user, namespace,
+	// service and permission repositories, for the frontends preferring one round trip.
+	GraphQLEnabled bool `config:"graphql-enabled" env:"GRAPHQL_ENABLED" usage:"Serve the GraphQL endpoint /api/v1/graphql"`
+
+	// GraphQLMaxDepth bounds the nesting of the fields of a GraphQL query (default 10).
+	GraphQLMaxDepth int `config:"graphql-max-depth" env:"GRAPHQL_MAX_DEPTH" usage:"Maximum nesting of the fields of a GraphQL query"`
+
+	// ─── IDEMPOTENCY ────────────────────────────────────────────
+	// IdempotencyKeyTTL keeps the responses of the POST and PUT requests carrying an
+	// Idempotency-Key header this long (default 24h), replaying them to the retries of the same
+	// user with the same key. Zero disables it.
+	IdempotencyKeyTTL time.Duration `config:"idempotency-key-ttl" env:"IDEMPOTENCY_KEY_TTL" usage:"How long the responses of requests carrying an Idempotency-Key are replayed to their retries (0 disables)"`
+
+	// IdempotencyMaxEntries bounds the in-memory responses of idempotent requests (default 1000).
+	IdempotencyMaxEntries int `config:"idempotency-max-entries" env:"IDEMPOTENCY_MAX_ENTRIES" usage:"Maximum number of stored responses of requests carrying an Idempotency-Key"`
+
+	// ─── AUDIT ──────────────────────────────────────────────────
+	// AuditSinks lists where an audit entry of every mutating API request is recorded: log,
+	// events (Kubernetes Events in AuditEventsNamespace) and webhook. Empty (default)
+	// disables auditing.
+	AuditSinks []string `config:"audit-sinks" env:"AUDIT_SINKS" usage:"Comma-separated audit sinks of mutating requests: log, events, webhook (default none)"`
+
+	// AuditEventsNamespace is the namespace of the audit Events (default: POD_NAMESPACE).
+	AuditEventsNamespace string `config:"audit-events-namespace" env:"AUDIT_EVENTS_NAMESPACE" usage:"Namespace of the audit Events (default: POD_NAMESPACE)"`
+
+	// AuditWebhookURL receives every audit entry as a JSON POST with the webhook sink.
+	AuditWebhookURL string `config:"audit-webhook-url" env:"AUDIT_WEBHOOK_URL" usage:"URL receiving audit entries as JSON POSTs (webhook sink)"`
+
+	// AuditQueueSize bounds the audit entries waiting for the sinks; entries are dropped,
+	// and logged, when it is full (default 1000).
+	AuditQueueSize int `config:"audit-queue-size" env:"AUDIT_QUEUE_SIZE" usage:"Maximum number of audit entries waiting for the sinks"`
+
+	// ─── WEBHOOKS ───────────────────────────────────────────────
+	// WebhookEndpoints lists the endpoints receiving the events of the BFF (resources created,
+	// updated or deleted through the API, operations completed) as signed JSON POSTs, as
+	// name=url. Empty (default) disables the webhooks.
+	WebhookEndpoints []string `config:"webhook-endpoints" env:"WEBHOOK_ENDPOINTS" usage:"Comma-separated name=url endpoints receiving the events of the BFF (optional)"`
+
+	// WebhookSecrets are the HMAC-SHA256 keys of the endpoints, as name=secret; the events of
+	// an endpoint without one are unsigned.
+	WebhookSecrets []string `config:"webhook-secrets" env:"WEBHOOK_SECRETS" usage:"Comma-separated name=secret keys signing the events of the webhook endpoints" secret:"true"`
+
+	// WebhookEvents filters the events of the endpoints, as name=pattern entries where pattern
+	// matches event types (e.g. ci=resource.*,ci=operation.completed); an endpoint without
+	// entries receives every event.
+	WebhookEvents []string `config:"webhook-events" env:"WEBHOOK_EVENTS" usage:"Comma-separated name=pattern event types of the webhook endpoints (default: all)"`
+
+	// WebhookMaxAttempts is the number of deliveries of an event before it is logged as a dead
+	// letter (default 5), retried with an exponential backoff on errors, 408, 429 and 5xx.
+	WebhookMaxAttempts int `config:"webhook-max-attempts" env:"WEBHOOK_MAX_ATTEMPTS" usage:"Deliveries of a webhook event before it is dead-lettered"`
+
+	// WebhookQueueSize bounds the events waiting for each endpoint; events are dropped, and
+	// logged as dead letters, when it is full (default 1000).
+	WebhookQueueSize int `config:"webhook-queue-size" env:"WEBHOOK_QUEUE_SIZE" usage:"Maximum number of events waiting for each webhook endpoint"`
 
 	// ─── TLS ────────────────────────────────────────────────────
+	// CertFile and KeyFile enable HTTPS when both are set. Both files are reloaded when they
+	// change, so rotated certificates are served without a restart.
+	CertFile string `config:"cert-file" env:"CERT_FILE" usage:"Path to TLS certificate file"`
+	KeyFile  string `config:"key-file" env:"KEY_FILE" usage:"Path to TLS key file"`
+
+	// TLSMinVersion is the minimum TLS version served, "1.2" or "1.3" (default). TLS 1.2 is
+	// limited to forward secret AEAD cipher suites.
+	TLSMinVersion string `config:"tls-min-version" env:"TLS_MIN_VERSION" usage:"Minimum TLS version served: 1.2 or 1.3"`
+
+	// TLSClientAuth requests client certificates: "none" (default), "optional" (verified when
+	// sent) or "require". Client certificates are verified against TLSClientCAFile.
+	TLSClientAuth string `config:"tls-client-auth" env:"TLS_CLIENT_AUTH" usage:"Client certificate policy: none, optional or require"`
+
+	// TLSClientCAFile is the PEM bundle of the CAs of client certificates, reloaded when it changes.
+	TLSClientCAFile string `config:"tls-client-ca-file" env:"TLS_CLIENT_CA_FILE" usage:"Path to the CA bundle verifying client certificates"`
+
+	// TLSClientCertIdentity authenticates requests with a verified client certificate as its
+	// subject: the common name is the user and the organizations are the groups. Requests
+	// without one fall back to the other identity sources. Requires the internal or
+	// impersonation auth method.
+	TLSClientCertIdentity bool `config:"tls-client-cert-identity" env:"TLS_CLIENT_CERT_IDENTITY" usage:"Map verified client certificates to the request identity (CN user, O groups)"`
+
 	// TLS verification settings for HTTP client connections to the Client
 	// InsecureSkipVerify when true, skips TLS certificate verification (useful for development/local setups)
 	// Default is false (secure) for production environments
-	InsecureSkipVerify bool
+	InsecureSkipVerify bool `config:"insecure-skip-verify" env:"INSECURE_SKIP_VERIFY" usage:"Skip TLS certificate verification (useful for development, default: false)"`
+
+	// UpstreamTLS overrides the outbound TLS of named upstreams, as "name=option;option"
+	// entries: ca=path (repeatable, added to the system pool), cert=path and key=path (client
+	// certificate), server-name=host, and insecure-skip-verify (dev-mode only). Names are those
+	// of the proxy routes and BFF targets, model-registry, oidc, audit-webhook and
+	// panic-report. The files are reloaded when they change. Other upstreams use BundlePaths
+	// and InsecureSkipVerify.
+	UpstreamTLS []string `config:"upstream-tls" env:"UPSTREAM_TLS" usage:"Comma-separated name=option;option outbound TLS of named upstreams: ca=path, cert=path, key=path, server-name=host, insecure-skip-verify (optional)"`
 
 	// ─── BFF INTER-COMMUNICATION ─────────────────────────────────
 	// MockBFFClients enables mock mode for BFF inter-communication clients.
 	// When true, BFF clients return mock responses instead of making real HTTP calls.
-	MockBFFClients bool
+	MockBFFClients bool `config:"mock-bff-clients" env:"MOCK_BFF_CLIENTS" usage:"Enable mock BFF clients (no real HTTP calls to other BFFs)"`
 
 	// ─── DEPRECATED ─────────────────────────────────────────────
 	// The following fields are deprecated and maintained for backward compatibility
 	// Use DeploymentMode instead
-	StandaloneMode    bool
-	FederatedPlatform bool
+	StandaloneMode    bool `config:"standalone-mode" usage:"DEPRECATED: Use -deployment-mode=standalone instead"`
+	FederatedPlatform bool `config:"federated-platform" usage:"DEPRECATED: Use -deployment-mode=federated instead"`
+}
+
+// DefaultEnvConfig returns the configuration used when no source sets a value.
+func DefaultEnvConfig() EnvConfig {
+	return EnvConfig{
+		Port:                        4000,
+		MockK8sBackend:              MockK8sBackendEnvTest,
+		DevModeClientPort:           8080,
+		StaticAssetsDir:             "./static",
+		LogLevel:                    slog.LevelInfo,
+		LogFormat:                   logger.FormatJSON,
+		ShutdownTimeout:             DefaultShutdownTimeout,
+		ServerIdleTimeout:           DefaultServerIdleTimeout,
+		ServerReadTimeout:           DefaultServerReadTimeout,
+		ServerReadHeaderTimeout:     DefaultServerReadHeaderTimeout,
+		ServerWriteTimeout:          DefaultServerWriteTimeout,
+		ServerMaxHeaderBytes:        DefaultServerMaxHeaderBytes,
+		ServerKeepAlives:            true,
+		HTTP2Enabled:                true,
+		HTTP2MaxConcurrentStreams:   DefaultHTTP2MaxConcurrentStreams,
+		HTTP2PingTimeout:            DefaultHTTP2PingTimeout,
+		RequestTimeout:              DefaultRequestTimeout,
+		LeaderElectionLeaseName:     DefaultLeaderElectionLeaseName,
+		LeaderElectionLeaseDuration: DefaultLeaderElectionLeaseDuration,
+		ConcurrencyQueueSize:        DefaultConcurrencyQueueSize,
+		ConcurrencyQueueTimeout:     DefaultConcurrencyQueueTimeout,
+		CostWindow:                  DefaultCostWindow,
+		AuthMethod:                  AuthMethodInternal,
+		TLSMinVersion:               servertls.Version13,
+		TLSClientAuth:               servertls.ClientAuthNone,
+		Preflight:                   PreflightWarn,
+		AuthTokenHeader:             DefaultAuthTokenHeader,
+		AuthTokenPrefix:             DefaultAuthTokenPrefix,
+		UserIDHeader:                DefaultUserIDHeader,
+		GroupsHeader:                DefaultGroupsHeader,
+		GuestUser:                   DefaultGuestUser,
+		AuthzGroup:                  DefaultAuthzGroup,
+		OIDCUsernameClaim:           oidc.DefaultUsernameClaim,
+		OIDCGroupsClaim:             oidc.DefaultGroupsClaim,
+		CacheResyncPeriod:           DefaultCacheResyncPeriod,
+		ServiceLabelSelector:        DefaultServiceLabelSelector,
+		ServiceURLSources:           []string{ServiceURLSourceRoute, ServiceURLSourceIngress, ServiceURLSourceHTTPRoute},
+		RevealVerb:                  DefaultRevealVerb,
+		ODHDashboardNamespace:       DefaultODHDashboardNamespace,
+		ODHDashboardCacheTTL:        DefaultODHDashboardCacheTTL,
+		ObjectStorageDownloadLimit:  DefaultObjectStorageTransferLimit,
+		ObjectStorageUploadLimit:    DefaultObjectStorageTransferLimit,
+		ObjectStoragePresignExpiry:  DefaultObjectStoragePresignExpiry,
+		IdempotencyKeyTTL:           DefaultIdempotencyKeyTTL,
+		IdempotencyMaxEntries:       DefaultIdempotencyMaxEntries,
+		AuditQueueSize:              DefaultAuditQueueSize,
+		WebhookMaxAttempts:          DefaultWebhookMaxAttempts,
+		WebhookQueueSize:            DefaultWebhookQueueSize,
+		ServiceAccountTokenMaxTTL:   DefaultServiceAccountTokenMaxTTL,
+		NotificationHistory:         notifications.DefaultHistory,
+		OperationRetention:          operations.DefaultRetention,
+		GraphQLMaxDepth:             DefaultGraphQLMaxDepth,
+		DatabaseMaxOpenConns:        database.DefaultMaxOpenConns,
+		ResponseCacheMaxEntries:     DefaultResponseCacheMaxEntries,
+		ClusterName:                 DefaultClusterName,
+		KubeAPIQPS:                  DefaultKubeAPIQPS,
+		KubeAPIBurst:                DefaultKubeAPIBurst,
+		KubeAPITimeout:              DefaultKubeAPITimeout,
+		KubeAPIMaxIdleConns:         DefaultKubeAPIMaxIdleConns,
+		UpstreamMaxRetries:          DefaultUpstreamMaxRetries,
+		UpstreamRetryMinBackoff:     DefaultUpstreamRetryMinBackoff,
+		UpstreamRetryMaxBackoff:     DefaultUpstreamRetryMaxBackoff,
+		CircuitBreakerFailures:      DefaultCircuitBreakerFailures,
+		CircuitBreakerCooldown:      DefaultCircuitBreakerCooldown,
+		ReviewCacheMaxEntries:       DefaultReviewCacheMaxEntries,
+		IdentityCacheMaxEntries:     DefaultIdentityCacheMaxEntries,
+		WarmupTimeout:               DefaultWarmupTimeout,
+		CORSAllowedMethods:          DefaultCORSAllowedMethods,
+		CORSAllowCredentials:        true,
+		CORSMaxAge:                  DefaultCORSMaxAge,
+		CSRFCookieName:              DefaultCSRFCookieName,
+		CSRFHeader:                  DefaultCSRFHeader,
+		CSRFSameSite:                DefaultCSRFSameSite,
+		SessionStore:                session.StoreCookie,
+		SessionCookieName:           DefaultSessionCookieName,
+		SessionIdleTimeout:          DefaultSessionIdleTimeout,
+		SessionAbsoluteTimeout:      DefaultSessionAbsoluteTimeout,
+		SessionRefreshBefore:        DefaultSessionRefreshBefore,
+		OAuthScopes:                 DefaultOAuthScopes,
+		SecurityHeaders:             true,
+		HSTSMaxAge:                  DefaultHSTSMaxAge,
+		FrameOptions:                DefaultFrameOptions,
+		ReferrerPolicy:              DefaultReferrerPolicy,
+		ContentSecurityPolicy:       DefaultContentSecurityPolicy,
+		Compression:                 true,
+		CompressionMinSize:          DefaultCompressionMinSize,
+		CompressionContentTypes:     DefaultCompressionContentTypes,
+		BodyLogSampleRate:           1,
+		BodyLogMaxSize:              DefaultBodyLogMaxSize,
+		BodyLogRedactedFields:       DefaultBodyLogRedactedFields,
+	}
+}
+
+// LogValue logs the configuration with its secret fields redacted.
+func (c EnvConfig) LogValue() slog.Value {
+	return Redacted(c)
 }
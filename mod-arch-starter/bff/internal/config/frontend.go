@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ParseFeatureFlags parses the "name" and "name=bool" entries of FrontendFeatures.
+func ParseFeatureFlags(entries []string) (map[string]bool, error) {
+	flags := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		name, value, hasValue := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("invalid feature flag %q (must be name or name=bool)", entry)
+		}
+		enabled := true
+		if hasValue {
+			var err error
+			if enabled, err = strconv.ParseBool(strings.TrimSpace(value)); err != nil {
+				return nil, fmt.Errorf("invalid feature flag %q (must be name or name=bool)", entry)
+			}
+		}
+		flags[name] = enabled
+	}
+	return flags, nil
+}
+
+// ParseServiceURLs parses the "name=url" entries of FrontendServiceURLs. URLs are absolute
+// http(s) URLs without credentials, or paths on the frontend's origin.
+func ParseServiceURLs(entries []string) (map[string]string, error) {
+	return parsePublishedURLs(entries, "service URL")
+}
+
+// ParseRemoteEntries parses the "name=url" entries of UIModuleRemoteEntries, with the same
+// rules as ParseServiceURLs.
+func ParseRemoteEntries(entries []string) (map[string]string, error) {
+	return parsePublishedURLs(entries, "remote entry")
+}
+
+// parsePublishedURLs parses "name=url" entries of URLs published to the frontend; kind names
+// them in errors.
+func parsePublishedURLs(entries []string, kind string) (map[string]string, error) {
+	urls := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		name, value, ok := strings.Cut(entry, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || name == "" || value == "" {
+			return nil, fmt.Errorf("invalid %s %q (must be name=url)", kind, entry)
+		}
+		u, err := url.Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", kind, entry, err)
+		}
+		absolute := (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+		if !absolute && (u.Scheme != "" || u.Host != "" || !strings.HasPrefix(u.Path, "/")) {
+			return nil, fmt.Errorf("invalid %s %q (must be an http(s) URL or a path starting with /)", kind, entry)
+		}
+		if u.User != nil {
+			// Published to every visitor of the frontend
+			return nil, fmt.Errorf("invalid %s %q: must not contain credentials", kind, name)
+		}
+		urls[name] = value
+	}
+	return urls, nil
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// HealthComponentRef is a component of the cluster health document: the Deployments and Pods
+// matching Selector in Namespace.
+type HealthComponentRef struct {
+	Name      string
+	Namespace string
+	Selector  labels.Selector
+}
+
+// HealthOperatorRef is an operator resource of the cluster health document, reported by the
+// conditions of its status. Namespace is empty for cluster-scoped resources.
+type HealthOperatorRef struct {
+	Name       string
+	Resource   schema.GroupVersionResource
+	Namespace  string
+	ObjectName string
+}
+
+// ParseHealthComponents parses the "name=namespace/selector" entries of HealthComponents.
+// Names are DNS labels, unique among the components.
+func (c EnvConfig) ParseHealthComponents() ([]HealthComponentRef, error) {
+	refs := make([]HealthComponentRef, 0, len(c.HealthComponents))
+	seen := map[string]bool{}
+	for _, entry := range c.HealthComponents {
+		name, value, ok := parseHealthEntry(entry)
+		namespace, selector, _ := strings.Cut(value, "/")
+		parsed, err := labels.Parse(selector)
+		if !ok || len(validation.IsDNS1123Label(namespace)) > 0 || err != nil || parsed.Empty() {
+			return nil, fmt.Errorf("invalid health component %q (must be name=namespace/selector, e.g. model-registry=odh-model-registries/app=model-registry-operator)", entry)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate health component %q", name)
+		}
+		seen[name] = true
+		refs = append(refs, HealthComponentRef{Name: name, Namespace: namespace, Selector: parsed})
+	}
+	return refs, nil
+}
+
+// ParseHealthOperators parses the "name=resource.version.group/[namespace/]object" entries of
+// HealthOperators. Names are DNS labels, unique among the operators.
+func (c EnvConfig) ParseHealthOperators() ([]HealthOperatorRef, error) {
+	refs := make([]HealthOperatorRef, 0, len(c.HealthOperators))
+	seen := map[string]bool{}
+	for _, entry := range c.HealthOperators {
+		name, value, ok := parseHealthEntry(entry)
+		parts := strings.Split(value, "/")
+		var gvr *schema.GroupVersionResource
+		if ok && (len(parts) == 2 || len(parts) == 3) {
+			gvr, _ = schema.ParseResourceArg(parts[0])
+		}
+		if gvr == nil || gvr.Version == "" || slices.Contains(parts, "") {
+			return nil, fmt.Errorf("invalid health operator %q (must be name=resource.version.group/[namespace/]object, e.g. odh=datascienceclusters.v1.datasciencecluster.opendatahub.io/default-dsc)", entry)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate health operator %q", name)
+		}
+		seen[name] = true
+		ref := HealthOperatorRef{Name: name, Resource: *gvr, ObjectName: parts[len(parts)-1]}
+		if len(parts) == 3 {
+			ref.Namespace = parts[1]
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// parseHealthEntry returns the name and value of a "name=value" entry, and whether the name is
+// a DNS label and the value is set.
+func parseHealthEntry(entry string) (string, string, bool) {
+	name, value, ok := strings.Cut(entry, "=")
+	name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+	return name, value, ok && value != "" && len(validation.IsDNS1123Label(name)) == 0
+}
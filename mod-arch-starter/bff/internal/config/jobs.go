@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/jobs"
+)
+
+// JobScheduleOff is the schedule of JobSchedules turning a job off.
+const JobScheduleOff = "off"
+
+// ParseJobSchedules parses the "job=schedule" entries of JobSchedules, where schedule is a
+// jobs.ParseSchedule spec or JobScheduleOff. The result is keyed by job name; jobs turned off
+// have a nil Schedule.
+func ParseJobSchedules(entries []string) (map[string]jobs.Schedule, error) {
+	schedules := make(map[string]jobs.Schedule, len(entries))
+	for _, entry := range entries {
+		name, spec, ok := strings.Cut(entry, "=")
+		name, spec = strings.TrimSpace(name), strings.TrimSpace(spec)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid job schedule %q (must be job=schedule, e.g. health-probe=@every 1m)", entry)
+		}
+		if spec == JobScheduleOff {
+			schedules[name] = nil
+			continue
+		}
+		schedule, err := jobs.ParseSchedule(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid job schedule %q: %w", entry, err)
+		}
+		schedules[name] = schedule
+	}
+	return schedules, nil
+}
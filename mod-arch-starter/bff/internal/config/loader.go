@@ -0,0 +1,433 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// The flag and environment variable giving the path of the YAML configuration file.
+const (
+	ConfigFileFlag = "config"
+	ConfigFileEnv  = "CONFIG_FILE"
+)
+
+// Struct tags read by Load and Redacted.
+//
+//	type ModuleConfig struct {
+//	    config.EnvConfig
+//	    RegistryURL string `config:"registry-url" env:"REGISTRY_URL" usage:"Model registry URL"`
+//	    APIKey      string `config:"api-key" env:"API_KEY" usage:"Model registry API key" secret:"true"`
+//	}
+//
+// config is the flag name and the key in the YAML file; fields without it are ignored, except
+// embedded and nested structs whose fields are read too. env is the environment variable (none
+// when empty). secret fields are redacted when the configuration is logged; reload fields are
+// applied by a Reloader when the file changes.
+const (
+	tagName   = "config"
+	tagEnv    = "env"
+	tagUsage  = "usage"
+	tagSecret = "secret"
+)
+
+// LoadOptions tunes Load. Zero values use the defaults.
+type LoadOptions struct {
+	// FlagSet receives the configuration flags (default a new ContinueOnError set).
+	FlagSet *flag.FlagSet
+	// Args are the command-line arguments, without the program name.
+	Args []string
+	// LookupEnv reads environment variables (default os.LookupEnv).
+	LookupEnv func(string) (string, bool)
+	// Sections are more pointers to structs filled like cfg, from the same flag set, file and
+	// environment, e.g. the settings of the modules. Their names must not clash with cfg's.
+	Sections []any
+}
+
+// ValidationError reports every problem found while loading a configuration, so they can be
+// fixed in one go rather than one restart at a time.
+type ValidationError struct {
+	Problems []error
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		messages[i] = problem.Error()
+	}
+	return "invalid configuration: " + strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Unwrap() []error {
+	return e.Problems
+}
+
+// FilePath returns the configuration file Load reads given the same flag set (after Load
+// parsed it) and environment: the -config flag, or else CONFIG_FILE. Empty means none.
+func FilePath(fs *flag.FlagSet, lookupEnv func(string) (string, bool)) string {
+	if f := fs.Lookup(ConfigFileFlag); f != nil {
+		if fv, ok := f.Value.(*flagValue); ok && fv.set {
+			return fv.value
+		}
+	}
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+	path, _ := lookupEnv(ConfigFileEnv)
+	return path
+}
+
+// Problems returns the individual problems of an error returned by Load.
+func Problems(err error) []error {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Problems
+	}
+	if err == nil {
+		return nil
+	}
+	return []error{err}
+}
+
+type field struct {
+	name  string
+	env   string
+	usage string
+	value reflect.Value
+}
+
+// Load fills cfg, a pointer to a struct, from layered sources: the values already in cfg are
+// the defaults, overridden by the YAML file named by -config (or CONFIG_FILE), then by
+// environment variables, then by command-line flags. Every field is registered as a flag on
+// opts.FlagSet.
+//
+// Parse errors and unknown file keys are collected rather than returned one by one, along with
+// the joined errors of cfg.Validate() (and of the sections' Validate) when cfg implements it.
+// The result is a *ValidationError, flag.ErrHelp when -help was given, or the flag set's parse
+// error.
+func Load(cfg any, opts LoadOptions) error {
+	if opts.FlagSet == nil {
+		opts.FlagSet = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	}
+	if opts.LookupEnv == nil {
+		opts.LookupEnv = os.LookupEnv
+	}
+
+	var fields []field
+	for _, target := range append([]any{cfg}, opts.Sections...) {
+		root := reflect.ValueOf(target)
+		if root.Kind() != reflect.Pointer || root.Elem().Kind() != reflect.Struct {
+			return fmt.Errorf("config: Load requires a pointer to a struct, got %T", target)
+		}
+		targetFields, err := collectFields(root.Elem())
+		if err != nil {
+			return err
+		}
+		fields = append(fields, targetFields...)
+	}
+
+	flags := make(map[string]*flagValue, len(fields))
+	for _, f := range fields {
+		if _, duplicate := flags[f.name]; duplicate || f.name == ConfigFileFlag {
+			return fmt.Errorf("config: setting %q is defined twice", f.name)
+		}
+		fv := &flagValue{isBool: f.value.Kind() == reflect.Bool}
+		// Like the flag package, zero defaults are not printed; levels and other named values are
+		if _, named := f.value.Interface().(encoding.TextMarshaler); named || !f.value.IsZero() {
+			fv.def = formatValue(f.value)
+		}
+		flags[f.name] = fv
+		usage := f.usage
+		if f.env != "" {
+			usage += " (env " + f.env + ")"
+		}
+		opts.FlagSet.Var(fv, f.name, usage)
+	}
+	opts.FlagSet.Var(&flagValue{}, ConfigFileFlag, "Path of a YAML configuration file, keyed by flag name (env "+ConfigFileEnv+")")
+
+	if err := opts.FlagSet.Parse(opts.Args); err != nil {
+		return err
+	}
+
+	var problems []error
+
+	if path := FilePath(opts.FlagSet, opts.LookupEnv); path != "" {
+		values, err := readFile(path)
+		if err != nil {
+			problems = append(problems, err)
+		} else {
+			problems = append(problems, applyFile(fields, values, path)...)
+		}
+	}
+
+	for _, f := range fields {
+		if f.env == "" {
+			continue
+		}
+		if raw, ok := opts.LookupEnv(f.env); ok {
+			if err := setValue(f.value, raw); err != nil {
+				problems = append(problems, fmt.Errorf("environment variable %s: %w", f.env, err))
+			}
+		}
+	}
+
+	for _, f := range fields {
+		if fv := flags[f.name]; fv.set {
+			if err := setValue(f.value, fv.value); err != nil {
+				problems = append(problems, fmt.Errorf("flag -%s: %w", f.name, err))
+			}
+		}
+	}
+
+	// Fields that failed to parse keep their default, so the rest can still be validated
+	for _, target := range append([]any{cfg}, opts.Sections...) {
+		if validator, ok := target.(interface{ Validate() error }); ok {
+			problems = append(problems, flatten(validator.Validate())...)
+		}
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// collectFields walks the tagged fields of v, descending into untagged struct fields.
+func collectFields(v reflect.Value) ([]field, error) {
+	var fields []field
+	for i := 0; i < v.NumField(); i++ {
+		sf := v.Type().Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, tagged := sf.Tag.Lookup(tagName)
+		if !tagged {
+			if sf.Type.Kind() == reflect.Struct && !isScalar(v.Field(i)) {
+				nested, err := collectFields(v.Field(i))
+				if err != nil {
+					return nil, err
+				}
+				fields = append(fields, nested...)
+			}
+			continue
+		}
+		if !isScalar(v.Field(i)) {
+			return nil, fmt.Errorf("config: field %s has unsupported type %s", sf.Name, sf.Type)
+		}
+		fields = append(fields, field{
+			name:  name,
+			env:   sf.Tag.Get(tagEnv),
+			usage: sf.Tag.Get(tagUsage),
+			value: v.Field(i),
+		})
+	}
+	return fields, nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// isScalar reports whether v can be set from a single string value.
+func isScalar(v reflect.Value) bool {
+	switch v.Addr().Interface().(type) {
+	case flag.Value, encoding.TextUnmarshaler:
+		return true
+	}
+	switch v.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return v.Type().Elem().Kind() == reflect.String
+	}
+	return false
+}
+
+// setValue parses raw into v. Lists are comma-separated, durations use time.ParseDuration.
+func setValue(v reflect.Value, raw string) error {
+	switch target := v.Addr().Interface().(type) {
+	case flag.Value:
+		return target.Set(raw)
+	case encoding.TextUnmarshaler:
+		return target.UnmarshalText([]byte(raw))
+	}
+
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer %q", raw)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		v.Set(reflect.ValueOf(splitList(raw)))
+	}
+	return nil
+}
+
+// formatValue renders v the way setValue parses it, for flag defaults.
+func formatValue(v reflect.Value) string {
+	switch value := v.Interface().(type) {
+	case fmt.Stringer:
+		return value.String()
+	case encoding.TextMarshaler:
+		text, _ := value.MarshalText()
+		return string(text)
+	case []string:
+		return strings.Join(value, ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// splitList splits a comma-separated value, dropping empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func readFile(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	values := map[string]any{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// applyFile sets the fields found in the file. Unknown keys are reported, they are usually typos.
+func applyFile(fields []field, values map[string]any, path string) []error {
+	var problems []error
+	byName := make(map[string]field, len(fields))
+	for _, f := range fields {
+		byName[f.name] = f
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		f, ok := byName[key]
+		if !ok {
+			problems = append(problems, fmt.Errorf("config file %s: unknown key %q", path, key))
+			continue
+		}
+		raw, err := fileValue(values[key])
+		if err == nil {
+			err = setValue(f.value, raw)
+		}
+		if err != nil {
+			problems = append(problems, fmt.Errorf("config file %s: key %q: %w", path, key, err))
+		}
+	}
+	return problems
+}
+
+// fileValue converts a decoded YAML value to the string form parsed by setValue.
+func fileValue(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			s, err := fileValue(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = s
+		}
+		return strings.Join(items, ","), nil
+	}
+	return "", fmt.Errorf("unsupported value %v", value)
+}
+
+// flatten splits a joined error into its parts.
+func flatten(err error) []error {
+	if err == nil {
+		return nil
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
+
+// flagValue records the raw value of a flag; Load applies it after the file and environment.
+type flagValue struct {
+	def    string
+	value  string
+	set    bool
+	isBool bool
+}
+
+func (f *flagValue) String() string {
+	switch {
+	case f == nil:
+		return ""
+	case f.set:
+		return f.value
+	}
+	return f.def
+}
+
+func (f *flagValue) Set(value string) error {
+	f.value, f.set = value, true
+	return nil
+}
+
+func (f *flagValue) IsBoolFlag() bool {
+	return f.isBool
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"log/slog"
+	"reflect"
+)
+
+// RedactedValue replaces the value of non-empty secret fields in logs.
+const RedactedValue = "[REDACTED]"
+
+// Redacted returns cfg, a struct or a pointer to one, as a log group keyed by the config names
+// of its fields (the Go name for untagged ones). Fields tagged secret:"true" are replaced with
+// RedactedValue, so configurations can be logged safely. Implement slog.LogValuer with it:
+//
+//	func (c ModuleConfig) LogValue() slog.Value { return config.Redacted(c) }
+func Redacted(cfg any) slog.Value {
+	v := reflect.Indirect(reflect.ValueOf(cfg))
+	if v.Kind() != reflect.Struct {
+		return slog.AnyValue(cfg)
+	}
+	return slog.GroupValue(redactedAttrs(v)...)
+}
+
+func redactedAttrs(v reflect.Value) []slog.Attr {
+	var attrs []slog.Attr
+	for i := 0; i < v.NumField(); i++ {
+		sf := v.Type().Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+		name, tagged := sf.Tag.Lookup(tagName)
+
+		switch {
+		case !tagged && sf.Type.Kind() == reflect.Struct && !isScalarType(sf.Type):
+			if sf.Anonymous {
+				attrs = append(attrs, redactedAttrs(fv)...)
+			} else {
+				attrs = append(attrs, slog.Attr{Key: sf.Name, Value: slog.GroupValue(redactedAttrs(fv)...)})
+			}
+		case !tagged:
+			attrs = append(attrs, slog.Any(sf.Name, fv.Interface()))
+		case sf.Tag.Get(tagSecret) == "true" && !fv.IsZero():
+			attrs = append(attrs, slog.String(name, RedactedValue))
+		default:
+			attrs = append(attrs, slog.Any(name, fv.Interface()))
+		}
+	}
+	return attrs
+}
+
+// isScalarType is isScalar for values that may not be addressable.
+func isScalarType(t reflect.Type) bool {
+	return isScalar(reflect.New(t).Elem())
+}
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// tagReload marks the fields a Reloader applies at runtime; changes to the others are logged as
+// requiring a restart.
+const tagReload = "reload"
+
+// reloadDebounce coalesces the burst of events of a single update: a ConfigMap volume update
+// swaps a symlink, an editor may write, rename and chmod.
+const reloadDebounce = 200 * time.Millisecond
+
+// Reloader keeps a configuration up to date with its YAML file, for the fields tagged
+// reload:"true" (e.g. the log level). Subscribers are called after every applied change.
+// It is safe for concurrent use.
+type Reloader[T any] struct {
+	load   func() (T, error)
+	logger *slog.Logger
+
+	mu          sync.RWMutex
+	current     T
+	subscribers []func(old, updated T)
+}
+
+// NewReloader returns a Reloader starting from current. load reads the full configuration again,
+// typically by running Load with the original arguments on a fresh DefaultEnvConfig(), so the
+// environment and flags keep overriding the file.
+func NewReloader[T any](current T, load func() (T, error), logger *slog.Logger) *Reloader[T] {
+	return &Reloader[T]{current: current, load: load, logger: logger}
+}
+
+// Current returns the configuration with the reloaded values applied.
+func (r *Reloader[T]) Current() T {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.current
+}
+
+// Subscribe registers fn to be called with the previous and the updated configuration after
+// each reload that changed a reloadable field.
+func (r *Reloader[T]) Subscribe(fn func(old, updated T)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscribers = append(r.subscribers, fn)
+}
+
+// Reload loads the configuration and applies its reloadable fields. An invalid configuration is
+// rejected as a whole and the current one is kept. It returns the names of the changed fields.
+func (r *Reloader[T]) Reload() ([]string, error) {
+	next, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	old := r.current
+	updated, changed, restart := mergeReloadable(old, next)
+	if len(restart) > 0 {
+		r.logger.Warn("configuration changes require a restart to take effect", "fields", restart)
+	}
+	if len(changed) == 0 {
+		r.mu.Unlock()
+		return nil, nil
+	}
+	r.current = updated
+	subscribers := append([]func(old, updated T){}, r.subscribers...)
+	r.mu.Unlock()
+
+	r.logger.Info("configuration reloaded", "fields", changed)
+	for _, fn := range subscribers {
+		fn(old, updated)
+	}
+	return changed, nil
+}
+
+// Watch reloads the configuration whenever the file at path changes, until ctx is done (see
+// WatchFile). It returns once the watch is set up.
+func (r *Reloader[T]) Watch(ctx context.Context, path string) error {
+	return WatchFile(ctx, path, r.logger, func() {
+		if _, err := r.Reload(); err != nil {
+			r.logger.Error("failed to reload configuration, keeping the current one", "path", path, "error", err)
+		}
+	})
+}
+
+// WatchFile calls onChange whenever the file at path changes, until ctx is done. The directory
+// is watched rather than the file, so ConfigMap volume updates (which replace a symlink) and
+// editors replacing the file are seen; the events of a single update are coalesced into one
+// call. It returns once the watch is set up.
+func WatchFile(ctx context.Context, path string, logger *slog.Logger, onChange func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch %s: %w", path, err)
+	}
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		_ = watcher.Close()
+		return fmt.Errorf("failed to watch %s: %w", path, err)
+	}
+
+	go func() {
+		defer watcher.Close()
+		var debounce <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if isConfigEvent(event, path) {
+					debounce = time.After(reloadDebounce)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logger.Warn("file watch error", "path", path, "error", err)
+			case <-debounce:
+				debounce = nil
+				onChange()
+			}
+		}
+	}()
+	return nil
+}
+
+// isConfigEvent reports whether event can change the content of the file at path.
+func isConfigEvent(event fsnotify.Event, path string) bool {
+	if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+		return false
+	}
+	// ConfigMap volumes update the ..data symlink the file points through
+	name := filepath.Base(event.Name)
+	return filepath.Clean(event.Name) == filepath.Clean(path) || name == "..data"
+}
+
+// mergeReloadable copies the reloadable fields of next into a copy of current. It returns the
+// config names of the copied fields that changed, and of the other fields that changed.
+func mergeReloadable[T any](current, next T) (merged T, changed, restart []string) {
+	merged = current
+	mergeFields(reflect.ValueOf(&merged).Elem(), reflect.ValueOf(next), &changed, &restart)
+	return merged, changed, restart
+}
+
+func mergeFields(dst, src reflect.Value, changed, restart *[]string) {
+	if dst.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < dst.NumField(); i++ {
+		sf := dst.Type().Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, tagged := sf.Tag.Lookup(tagName)
+		if !tagged {
+			if sf.Type.Kind() == reflect.Struct && !isScalarType(sf.Type) {
+				mergeFields(dst.Field(i), src.Field(i), changed, restart)
+			}
+			continue
+		}
+		if reflect.DeepEqual(dst.Field(i).Interface(), src.Field(i).Interface()) {
+			continue
+		}
+		if sf.Tag.Get(tagReload) != "true" {
+			*restart = append(*restart, name)
+			continue
+		}
+		dst.Field(i).Set(src.Field(i))
+		*changed = append(*changed, name)
+	}
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFileReloader(t *testing.T, path string) *Reloader[EnvConfig] {
+	t.Helper()
+	loadFile := func() (EnvConfig, error) {
+		cfg := DefaultEnvConfig()
+		err := load(t, &cfg, map[string]string{ConfigFileEnv: path})
+		return cfg, err
+	}
+	cfg, err := loadFile()
+	require.NoError(t, err)
+	return NewReloader(cfg, loadFile, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestReloader_AppliesReloadableFields(t *testing.T) {
+	path := writeConfigFile(t, "log-level: info\nport: 4000\n")
+	reloader := newFileReloader(t, path)
+
+	var notified []slog.Level
+	reloader.Subscribe(func(old, updated EnvConfig) {
+		notified = append(notified, old.LogLevel, updated.LogLevel)
+	})
+
+	// Unchanged file: nothing to apply
+	changed, err := reloader.Reload()
+	require.NoError(t, err)
+	assert.Empty(t, changed)
+	assert.Empty(t, notified)
+
+	// The port needs a restart, only the log level is applied
+	require.NoError(t, os.WriteFile(path, []byte("log-level: debug\nport: 5000\n"), 0o600))
+	changed, err = reloader.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"log-level"}, changed)
+	assert.Equal(t, []slog.Level{slog.LevelInfo, slog.LevelDebug}, notified)
+	assert.Equal(t, slog.LevelDebug, reloader.Current().LogLevel)
+	assert.Equal(t, 4000, reloader.Current().Port)
+}
+
+func TestReloader_KeepsCurrentOnInvalidConfig(t *testing.T) {
+	path := writeConfigFile(t, "log-level: warn\n")
+	reloader := newFileReloader(t, path)
+
+	require.NoError(t, os.WriteFile(path, []byte("log-level: loud\n"), 0o600))
+	_, err := reloader.Reload()
+	assert.Error(t, err)
+	assert.Equal(t, slog.LevelWarn, reloader.Current().LogLevel)
+}
+
+func TestReloader_WatchesConfigMapUpdates(t *testing.T) {
+	// Lay out the directory like a ConfigMap volume: config.yaml -> ..data/config.yaml
+	dir := t.TempDir()
+	writeVersion := func(version, content string) {
+		versionDir := filepath.Join(dir, version)
+		require.NoError(t, os.Mkdir(versionDir, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(versionDir, "config.yaml"), []byte(content), 0o600))
+		tmpLink := filepath.Join(dir, "..data_tmp")
+		require.NoError(t, os.Symlink(version, tmpLink))
+		require.NoError(t, os.Rename(tmpLink, filepath.Join(dir, "..data")))
+	}
+	writeVersion("..v1", "log-level: info\n")
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.Symlink(filepath.Join("..data", "config.yaml"), path))
+
+	reloader := newFileReloader(t, path)
+	updates := make(chan slog.Level, 1)
+	reloader.Subscribe(func(_, updated EnvConfig) { updates <- updated.LogLevel })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, reloader.Watch(ctx, path))
+
+	writeVersion("..v2", "log-level: error\n")
+
+	select {
+	case level := <-updates:
+		assert.Equal(t, slog.LevelError, level)
+	case <-time.After(5 * time.Second):
+		t.Fatal("configuration change was not reloaded")
+	}
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ParseRouteTimeouts parses the "route=duration" entries of RouteTimeouts. A route is the
+// pattern of a registered route, e.g. "/api/v1/secrets/:name", optionally preceded by a
+// method ("POST /api/v1/secrets"); the result is keyed by "METHOD route" or by the route
+// alone for every method. A zero duration disables the timeout of the route.
+func ParseRouteTimeouts(entries []string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration, len(entries))
+	for _, entry := range entries {
+		route, value, ok := strings.Cut(entry, "=")
+		route, value = strings.TrimSpace(route), strings.TrimSpace(value)
+		if method, path, found := strings.Cut(route, " "); found {
+			path = strings.TrimSpace(path)
+			if !validMethod(method) || !strings.HasPrefix(path, "/") {
+				return nil, fmt.Errorf("invalid route timeout %q (must be [METHOD ]/route=duration)", entry)
+			}
+			route = strings.ToUpper(method) + " " + path
+		} else if !strings.HasPrefix(route, "/") {
+			return nil, fmt.Errorf("invalid route timeout %q (must be [METHOD ]/route=duration)", entry)
+		}
+		timeout, err := time.ParseDuration(value)
+		if !ok || err != nil || timeout < 0 {
+			return nil, fmt.Errorf("invalid route timeout %q (must be [METHOD ]/route=duration, e.g. /api/v1/namespaces=10s)", entry)
+		}
+		timeouts[route] = timeout
+	}
+	return timeouts, nil
+}
+
+func validMethod(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
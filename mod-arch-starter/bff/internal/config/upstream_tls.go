@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UpstreamTLSRef is the outbound TLS configuration of a named upstream (see UpstreamTLS).
+type UpstreamTLSRef struct {
+	Name               string
+	CAFiles            []string
+	CertFile           string
+	KeyFile            string
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+// ParseUpstreamTLS parses the "name=option;option..." entries of UpstreamTLS, where an option
+// is ca=path (repeatable), cert=path, key=path, server-name=host or insecure-skip-verify.
+// Names are unique among the entries.
+func (c EnvConfig) ParseUpstreamTLS() ([]UpstreamTLSRef, error) {
+	refs := make([]UpstreamTLSRef, 0, len(c.UpstreamTLS))
+	seen := map[string]bool{}
+	for _, entry := range c.UpstreamTLS {
+		name, options, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.TrimSpace(options) == "" {
+			return nil, fmt.Errorf("invalid upstream TLS entry %q (must be name=option;option, e.g. model-registry=ca=/etc/pki/registry.crt)", entry)
+		}
+		ref := UpstreamTLSRef{Name: name}
+		for _, option := range strings.Split(options, ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+			switch {
+			case key == "insecure-skip-verify" && value == "":
+				ref.InsecureSkipVerify = true
+			case key == "ca" && value != "":
+				ref.CAFiles = append(ref.CAFiles, value)
+			case key == "cert" && value != "" && ref.CertFile == "":
+				ref.CertFile = value
+			case key == "key" && value != "" && ref.KeyFile == "":
+				ref.KeyFile = value
+			case key == "server-name" && value != "" && ref.ServerName == "":
+				ref.ServerName = value
+			case key == "":
+				// Trailing separator
+			default:
+				return nil, fmt.Errorf("invalid upstream TLS option %q of %s (must be ca=path, cert=path, key=path, server-name=host or insecure-skip-verify)", option, name)
+			}
+		}
+		if (ref.CertFile == "") != (ref.KeyFile == "") {
+			return nil, fmt.Errorf("upstream TLS of %s: cert and key must be set together", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate upstream TLS entry %q", name)
+		}
+		seen[name] = true
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
@@ -0,0 +1,560 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/database"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/panicreport"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/servertls"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/session"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Validate reports every invalid setting of the configuration at once.
+func (c EnvConfig) Validate() error {
+	var problems []error
+	invalid := func(format string, args ...any) {
+		problems = append(problems, fmt.Errorf(format, args...))
+	}
+
+	if c.Port < 1 || c.Port > 65535 {
+		invalid("port: %d is not a valid port", c.Port)
+	}
+	if c.DevMode && (c.DevModeClientPort < 1 || c.DevModeClientPort > 65535) {
+		invalid("dev-mode-client-port: %d is not a valid port", c.DevModeClientPort)
+	}
+	if c.FrontendDevServerURL != "" {
+		if u, err := url.Parse(c.FrontendDevServerURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			invalid("frontend-dev-server-url: %q is not an http(s) URL", c.FrontendDevServerURL)
+		} else if !c.DevMode {
+			invalid("frontend-dev-server-url: requires dev-mode")
+		}
+	}
+	if c.MockK8Client && c.MockK8sBackend != MockK8sBackendEnvTest && c.MockK8sBackend != MockK8sBackendMemory {
+		invalid("mock-k8s-backend: %q is not valid (must be envtest or memory)", c.MockK8sBackend)
+	}
+	if c.MockServerLatency < 0 {
+		invalid("mock-server-latency: must not be negative")
+	}
+	if c.MockServerErrorRate < 0 || c.MockServerErrorRate > 1 {
+		invalid("mock-server-error-rate: must be between 0 and 1, got %v", c.MockServerErrorRate)
+	}
+
+	if !logger.IsValidFormat(c.LogFormat) {
+		invalid("log-format: %q is not valid (must be json or text)", c.LogFormat)
+	}
+	if _, err := logger.ParsePackageLevels(c.LogLevels); err != nil {
+		invalid("log-levels: %v", err)
+	}
+
+	if !IsValidAuthMethod(c.AuthMethod) {
+		invalid("auth-method: %q is not valid (must be internal, user_token or impersonation)", c.AuthMethod)
+	}
+	if c.AuthTokenHeader == "" {
+		invalid("auth-token-header: must not be empty")
+	}
+	for _, namespace := range c.AllowedNamespaces {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			invalid("allowed-namespaces: %q is not a valid namespace name", namespace)
+		}
+	}
+	if len(c.AllowedNamespaces) > 0 && len(c.CacheResources) > 0 {
+		// The informers list and watch cluster-wide
+		invalid("cache-resources: must be empty with allowed-namespaces")
+	}
+	if len(c.AllowedNamespaces) > 0 && c.NamespaceProvisioning {
+		invalid("namespace-provisioning: must be disabled with allowed-namespaces")
+	}
+	for _, cidr := range c.TrustedProxyCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			invalid("trusted-proxy-cidrs: %q is not a valid CIDR", cidr)
+		}
+	}
+	if len(c.TrustedProxyCIDRs) > 0 && c.AuthMethod != AuthMethodInternal && c.AuthMethod != AuthMethodImpersonation {
+		invalid("trusted-proxy-cidrs: requires the internal or impersonation auth method, got %q", c.AuthMethod)
+	}
+	if c.KubeflowMode {
+		if c.AuthMethod != AuthMethodInternal && c.AuthMethod != AuthMethodImpersonation {
+			invalid("kubeflow-mode: requires the internal or impersonation auth method, got %q", c.AuthMethod)
+		}
+		if c.UserIDHeader == "" {
+			invalid("userid-header: must not be empty in Kubeflow mode")
+		}
+		if c.GroupsHeader == "" {
+			invalid("groups-header: must not be empty in Kubeflow mode")
+		}
+		if len(c.TrustedProxyCIDRs) == 0 {
+			// Otherwise any client reaching the pod could send the headers of any user
+			invalid("trusted-proxy-cidrs: must list the proxies setting the identity headers in Kubeflow mode, e.g. 127.0.0.6/32 for the Istio sidecar")
+		}
+		if len(c.AllowedNamespaces) > 0 {
+			// Profiles are cluster-scoped
+			invalid("kubeflow-mode: must be disabled with allowed-namespaces")
+		}
+		if c.OIDCIssuerURL != "" {
+			invalid("kubeflow-mode: must be disabled with oidc-issuer-url")
+		}
+	}
+	if c.GuestMode {
+		// Guests have no token, the BFF acts as GuestUser
+		if c.AuthMethod != AuthMethodInternal && c.AuthMethod != AuthMethodImpersonation {
+			invalid("guest-mode: requires the internal or impersonation auth method, got %q", c.AuthMethod)
+		}
+		if strings.TrimSpace(c.GuestUser) == "" {
+			invalid("guest-user: must not be empty in guest mode")
+		}
+		if slices.ContainsFunc(c.GuestGroups, func(group string) bool { return slices.Contains(c.AdminGroups, group) }) {
+			invalid("guest-groups: must not include an admin group")
+		}
+	}
+	for _, name := range c.Authorizers {
+		if !IsValidAuthorizer(name) {
+			invalid("authorizers: %q is not valid (must be sar, policy or opa)", name)
+		}
+	}
+	if slices.Contains(c.Authorizers, AuthorizerSAR) && strings.TrimSpace(c.AuthzGroup) == "" {
+		invalid("authz-group: must not be empty with the sar authorizer")
+	}
+	if slices.Contains(c.Authorizers, AuthorizerPolicy) && c.AuthzPolicyFile == "" {
+		invalid("authz-policy-file: required by the policy authorizer")
+	}
+	if slices.Contains(c.Authorizers, AuthorizerOPA) {
+		if u, err := url.Parse(c.AuthzOPAURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			invalid("authz-opa-url: must be an http(s) URL with the opa authorizer")
+		}
+	}
+	if c.ServiceAccountTokens {
+		if c.ServiceAccountTokenMaxTTL < MinServiceAccountTokenTTL {
+			invalid("service-account-token-max-ttl: must be at least %s, got %s", MinServiceAccountTokenTTL, c.ServiceAccountTokenMaxTTL)
+		}
+		if len(c.ServiceAccountTokenAudiences) == 0 {
+			// The tokens would otherwise carry the audience of the API server
+			invalid("service-account-token-audiences: must list the audiences of the tokens with service-account-tokens")
+		}
+		for _, audience := range c.ServiceAccountTokenAudiences {
+			if strings.TrimSpace(audience) == "" {
+				invalid("service-account-token-audiences: must not contain empty audiences")
+			}
+		}
+	}
+	if c.OIDCIssuerURL != "" {
+		if u, err := url.Parse(c.OIDCIssuerURL); err != nil || u.Scheme == "" || u.Host == "" {
+			invalid("oidc-issuer-url: %q is not an absolute URL", c.OIDCIssuerURL)
+		}
+		if c.OIDCClientID == "" {
+			invalid("oidc-client-id: required with oidc-issuer-url, as the audience of the tokens")
+		}
+	}
+
+	if c.SessionEnabled {
+		if c.OIDCIssuerURL == "" {
+			invalid("session-enabled: requires oidc-issuer-url and oidc-client-id")
+		}
+		if !c.CSRFEnabled {
+			invalid("session-enabled: requires csrf-enabled, as the session cookie authenticates API requests")
+		}
+		if !session.IsValidStore(c.SessionStore) {
+			invalid("session-store: %q is not valid (must be %s or %s)", c.SessionStore, session.StoreCookie, session.StoreMemory)
+		}
+		if len(c.SessionSecret) < minSessionSecretLength {
+			invalid("session-secret: must be at least %d characters", minSessionSecretLength)
+		}
+		if c.SessionCookieName == "" {
+			invalid("session-cookie-name: must not be empty")
+		}
+		if c.SessionIdleTimeout <= 0 || c.SessionAbsoluteTimeout <= 0 {
+			invalid("session-idle-timeout and session-absolute-timeout: must be positive")
+		} else if c.SessionIdleTimeout > c.SessionAbsoluteTimeout {
+			invalid("session-idle-timeout: must not exceed session-absolute-timeout")
+		}
+		if c.SessionRefreshBefore < 0 {
+			invalid("session-refresh-before: must not be negative, got %s", c.SessionRefreshBefore)
+		}
+		if u, err := url.Parse(c.OAuthRedirectURL); err != nil || u.Host == "" || (u.Scheme != "https" && !(c.DevMode && u.Scheme == "http")) {
+			invalid("oauth-redirect-url: %q is not an absolute https URL (http in dev mode)", c.OAuthRedirectURL)
+		}
+	}
+
+	for _, origin := range c.AllowedOrigins {
+		if !validCORSOrigin(origin) {
+			invalid("allowed-origins: %q is not valid (must be *, or an origin such as https://example.com with at most one *)", origin)
+		}
+	}
+	if c.CORSMaxAge < 0 {
+		invalid("cors-max-age: must not be negative, got %s", c.CORSMaxAge)
+	}
+
+	if c.CSRFEnabled {
+		if c.CSRFCookieName == "" || c.CSRFHeader == "" {
+			invalid("csrf-cookie-name and csrf-header: must not be empty")
+		}
+		if !IsValidSameSite(c.CSRFSameSite) {
+			invalid("csrf-same-site: %q is not valid (must be lax, strict or none)", c.CSRFSameSite)
+		}
+	}
+
+	if c.HSTSMaxAge < 0 {
+		invalid("hsts-max-age: must not be negative, got %s", c.HSTSMaxAge)
+	}
+	if strings.ContainsAny(c.ContentSecurityPolicy, "\r\n") {
+		invalid("content-security-policy: must be a single line")
+	}
+
+	if c.CompressionMinSize < 0 {
+		invalid("compression-min-size: must not be negative, got %d", c.CompressionMinSize)
+	}
+	for _, contentType := range c.CompressionContentTypes {
+		if mediaType, subtype, found := strings.Cut(contentType, "/"); !found || mediaType == "" || mediaType == "*" || subtype == "" {
+			invalid("compression-content-types: %q is not a media type such as application/json or text/*", contentType)
+		}
+	}
+
+	if _, err := ParseFeatureFlags(c.FrontendFeatures); err != nil {
+		invalid("frontend-features: %v", err)
+	}
+	if _, err := ParseServiceURLs(c.FrontendServiceURLs); err != nil {
+		invalid("frontend-service-urls: %v", err)
+	}
+	if _, err := ParseRemoteEntries(c.UIModuleRemoteEntries); err != nil {
+		invalid("ui-module-remote-entries: %v", err)
+	}
+
+	for _, resource := range c.CacheResources {
+		if !IsValidCacheResource(resource) {
+			invalid("cache-resources: %q is not valid (must be namespaces or services)", resource)
+		}
+	}
+	if len(c.CacheResources) > 0 && c.CacheResyncPeriod <= 0 {
+		invalid("cache-resync-period: must be positive, got %s", c.CacheResyncPeriod)
+	}
+
+	for _, source := range c.ServiceURLSources {
+		if !IsValidServiceURLSource(source) {
+			invalid("service-url-sources: %q is not valid (must be route, ingress or httproute)", source)
+		}
+	}
+
+	if c.ModelRegistryURL != "" {
+		if u, err := url.Parse(c.ModelRegistryURL); err != nil || u.Scheme == "" || u.Host == "" {
+			invalid("model-registry-url: %q is not an absolute URL", c.ModelRegistryURL)
+		}
+	}
+
+	if c.AdminPort != 0 && (c.AdminPort < 1 || c.AdminPort > 65535 || c.AdminPort == c.Port) {
+		invalid("admin-port: %d is not a valid port, other than port", c.AdminPort)
+	}
+	if c.Preflight != "" && !IsValidPreflightMode(c.Preflight) {
+		invalid("preflight: %q is not valid (must be off, warn or fail)", c.Preflight)
+	}
+	if c.ChaosEnabled && !c.DevMode {
+		invalid("chaos-enabled: requires dev-mode")
+	}
+
+	if c.PanicReportDSN != "" {
+		if _, _, err := panicreport.ParseDSN(c.PanicReportDSN); err != nil {
+			invalid("panic-report-dsn: %v", err)
+		}
+	}
+
+	for _, pattern := range c.RedactedConfigMapKeys {
+		if _, err := path.Match(pattern, ""); err != nil {
+			invalid("redacted-configmap-keys: %q is not a valid pattern", pattern)
+		}
+	}
+
+	if c.ObjectStorageDownloadLimit < 0 {
+		invalid("object-storage-download-limit: must not be negative, got %d", c.ObjectStorageDownloadLimit)
+	}
+	if c.ObjectStorageUploadLimit < 0 || c.ObjectStorageUploadLimit > DefaultObjectStorageTransferLimit {
+		invalid("object-storage-upload-limit: must be between 0 and %d bytes, got %d", DefaultObjectStorageTransferLimit, c.ObjectStorageUploadLimit)
+	}
+	if c.ObjectStoragePresignExpiry < time.Second || c.ObjectStoragePresignExpiry > MaxObjectStoragePresignExpiry {
+		invalid("object-storage-presign-expiry: must be between 1s and %s, got %s", MaxObjectStoragePresignExpiry, c.ObjectStoragePresignExpiry)
+	}
+	for _, host := range c.ObjectStorageAllowedHosts {
+		if _, _, err := net.SplitHostPort(host); err == nil || host == "" || strings.Contains(host, "/") {
+			invalid("object-storage-allowed-hosts: %q is not a host name or IP address without scheme or port", host)
+		}
+	}
+
+	for _, sink := range c.AuditSinks {
+		if !IsValidAuditSink(sink) {
+			invalid("audit-sinks: %q is not valid (must be log, events or webhook)", sink)
+		}
+		if sink == AuditSinkWebhook {
+			if u, err := url.Parse(c.AuditWebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				invalid("audit-webhook-url: must be an http(s) URL with the webhook audit sink")
+			}
+		}
+	}
+	if c.OperationRetention <= 0 {
+		invalid("operation-retention: must be positive, got %s", c.OperationRetention)
+	}
+	if c.GraphQLMaxDepth < 1 {
+		invalid("graphql-max-depth: must be positive, got %d", c.GraphQLMaxDepth)
+	}
+	if (c.DatabaseDriver == "") != (c.DatabaseURL == "") {
+		invalid("database-driver and database-url: must be set together")
+	}
+	if c.DatabaseDriver != "" && !slices.Contains(database.Drivers(), c.DatabaseDriver) {
+		invalid("database-driver: must be one of %s, got %q", strings.Join(database.Drivers(), ", "), c.DatabaseDriver)
+	}
+	if c.DatabaseMaxOpenConns < 1 {
+		invalid("database-max-open-conns: must be positive, got %d", c.DatabaseMaxOpenConns)
+	}
+	if c.NotificationHistory < 0 {
+		invalid("notification-history: must not be negative, got %d", c.NotificationHistory)
+	}
+	if len(c.AuditSinks) > 0 && c.AuditQueueSize < 1 {
+		invalid("audit-queue-size: must be positive, got %d", c.AuditQueueSize)
+	}
+
+	if c.IdempotencyKeyTTL < 0 {
+		invalid("idempotency-key-ttl: must not be negative, got %s", c.IdempotencyKeyTTL)
+	}
+	if c.IdempotencyKeyTTL > 0 && c.IdempotencyMaxEntries < 1 {
+		invalid("idempotency-max-entries: must be positive, got %d", c.IdempotencyMaxEntries)
+	}
+	if c.ODHDashboardNamespace == "" {
+		invalid("odh-dashboard-namespace: must not be empty")
+	}
+	if c.ODHDashboardCacheTTL < 0 {
+		invalid("odh-dashboard-cache-ttl: must not be negative, got %s", c.ODHDashboardCacheTTL)
+	}
+	if c.ResponseCacheTTL < 0 {
+		invalid("response-cache-ttl: must not be negative, got %s", c.ResponseCacheTTL)
+	}
+	if c.ResponseCacheTTL > 0 && c.ResponseCacheMaxEntries < 1 {
+		invalid("response-cache-max-entries: must be positive, got %d", c.ResponseCacheMaxEntries)
+	}
+	if len(c.WarmupNamespaces) > 0 {
+		// The caches are primed with the backend credentials
+		if c.AuthMethod != AuthMethodInternal {
+			invalid("warmup-namespaces: requires the internal auth method, got %q", c.AuthMethod)
+		}
+		for _, namespace := range c.WarmupNamespaces {
+			if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+				invalid("warmup-namespaces: %q is not a valid namespace name", namespace)
+			}
+		}
+		if c.WarmupTimeout <= 0 {
+			invalid("warmup-timeout: must be positive, got %s", c.WarmupTimeout)
+		}
+	} else if len(c.WarmupUsers) > 0 {
+		invalid("warmup-users: requires warmup-namespaces")
+	}
+	if _, err := ParseWarmupUsers(c.WarmupUsers); err != nil {
+		invalid("warmup-users: %v", err)
+	}
+
+	if errs := validation.IsDNS1123Label(c.ClusterName); len(errs) > 0 {
+		invalid("cluster-name: %q is not a DNS label", c.ClusterName)
+	}
+	if _, err := c.ParseClusterRefs(); err != nil {
+		invalid("cluster-contexts and cluster-secrets: %v", err)
+	}
+	if _, err := c.ParseWebhookEndpoints(); err != nil {
+		invalid("webhook-endpoints, webhook-secrets and webhook-events: %v", err)
+	}
+	if len(c.WebhookEndpoints) > 0 && (c.WebhookMaxAttempts < 1 || c.WebhookQueueSize < 1) {
+		invalid("webhook-max-attempts and webhook-queue-size: must be positive, got %d and %d", c.WebhookMaxAttempts, c.WebhookQueueSize)
+	}
+	if _, err := c.ParseHealthComponents(); err != nil {
+		invalid("health-components: %v", err)
+	}
+	if _, err := c.ParseHealthOperators(); err != nil {
+		invalid("health-operators: %v", err)
+	}
+
+	if c.KubeAPIQPS <= 0 || c.KubeAPIBurst < 1 {
+		invalid("kube-api-qps and kube-api-burst: must be positive, got %g and %d", c.KubeAPIQPS, c.KubeAPIBurst)
+	}
+	if c.KubeAPITimeout < 0 {
+		invalid("kube-api-timeout: must not be negative, got %s", c.KubeAPITimeout)
+	}
+	if c.KubeAPIMaxIdleConns < 0 || c.KubeAPIMaxConns < 0 {
+		invalid("kube-api-max-idle-conns and kube-api-max-conns: must not be negative")
+	}
+
+	if c.UpstreamMaxRetries < 0 {
+		invalid("upstream-max-retries: must not be negative, got %d", c.UpstreamMaxRetries)
+	}
+	if c.UpstreamRetryMinBackoff <= 0 || c.UpstreamRetryMaxBackoff < c.UpstreamRetryMinBackoff {
+		invalid("upstream-retry-min-backoff and upstream-retry-max-backoff: must be positive, with the max above the min")
+	}
+	if c.CircuitBreakerFailures < 0 {
+		invalid("circuit-breaker-failures: must not be negative, got %d", c.CircuitBreakerFailures)
+	}
+	if c.CircuitBreakerFailures > 0 && c.CircuitBreakerCooldown <= 0 {
+		invalid("circuit-breaker-cooldown: must be positive, got %s", c.CircuitBreakerCooldown)
+	}
+
+	if c.ReviewCacheSubjectTTL < 0 || c.ReviewCacheAllowedTTL < 0 || c.ReviewCacheDeniedTTL < 0 {
+		invalid("review-cache-*-ttl: must not be negative")
+	}
+	if (c.ReviewCacheSubjectTTL > 0 || c.ReviewCacheAllowedTTL > 0 || c.ReviewCacheDeniedTTL > 0) && c.ReviewCacheMaxEntries < 1 {
+		invalid("review-cache-max-entries: must be positive, got %d", c.ReviewCacheMaxEntries)
+	}
+	if c.IdentityCacheTTL < 0 {
+		invalid("identity-cache-ttl: must not be negative, got %s", c.IdentityCacheTTL)
+	}
+	if c.IdentityCacheTTL > 0 && c.IdentityCacheMaxEntries < 1 {
+		invalid("identity-cache-max-entries: must be positive, got %d", c.IdentityCacheMaxEntries)
+	}
+
+	if c.ServerIdleTimeout < 0 {
+		invalid("server-idle-timeout: must not be negative, got %s", c.ServerIdleTimeout)
+	}
+	if c.ServerReadTimeout < 0 {
+		invalid("server-read-timeout: must not be negative, got %s", c.ServerReadTimeout)
+	}
+	if c.ServerReadHeaderTimeout < 0 {
+		invalid("server-read-header-timeout: must not be negative, got %s", c.ServerReadHeaderTimeout)
+	}
+	if c.ServerWriteTimeout < 0 {
+		invalid("server-write-timeout: must not be negative, got %s", c.ServerWriteTimeout)
+	}
+	if c.HTTP2PingInterval < 0 {
+		invalid("http2-ping-interval: must not be negative, got %s", c.HTTP2PingInterval)
+	}
+	if c.ServerMaxHeaderBytes < 1 {
+		invalid("server-max-header-bytes: must be positive, got %d", c.ServerMaxHeaderBytes)
+	}
+	if c.HTTP2MaxConcurrentStreams < 1 {
+		invalid("http2-max-concurrent-streams: must be positive, got %d", c.HTTP2MaxConcurrentStreams)
+	}
+	if c.HTTP2PingInterval > 0 && c.HTTP2PingTimeout <= 0 {
+		invalid("http2-ping-timeout: must be positive, got %s", c.HTTP2PingTimeout)
+	}
+	if c.H2CEnabled {
+		if !c.HTTP2Enabled {
+			invalid("h2c-enabled: requires http2-enabled")
+		}
+		if c.CertFile != "" {
+			// Plaintext HTTP/2 is only served without TLS
+			invalid("h2c-enabled: must be disabled with cert-file")
+		}
+	}
+
+	if c.ShutdownDelay < 0 {
+		invalid("shutdown-delay: must not be negative, got %s", c.ShutdownDelay)
+	}
+	if c.ShutdownTimeout < 0 {
+		invalid("shutdown-timeout: must not be negative, got %s", c.ShutdownTimeout)
+	}
+
+	if c.RequestTimeout < 0 {
+		invalid("request-timeout: must not be negative, got %s", c.RequestTimeout)
+	}
+	if _, err := ParseRouteTimeouts(c.RouteTimeouts); err != nil {
+		invalid("route-timeouts: %v", err)
+	}
+	if _, err := ParseBodyLogRoutes(c.BodyLogRoutes); err != nil {
+		invalid("body-log-routes: %v", err)
+	}
+	if c.BodyLogSampleRate < 0 || c.BodyLogSampleRate > 1 {
+		invalid("body-log-sample-rate: must be between 0 and 1, got %v", c.BodyLogSampleRate)
+	}
+	if c.BodyLogMaxSize < 1 {
+		invalid("body-log-max-size: must be positive, got %d", c.BodyLogMaxSize)
+	}
+	if err := validFieldPatterns(c.BodyLogRedactedFields); err != nil {
+		invalid("body-log-redacted-fields: %v", err)
+	}
+	if _, err := ParseJobSchedules(c.JobSchedules); err != nil {
+		invalid("job-schedules: %v", err)
+	}
+	if c.LeaderElectionEnabled {
+		if errs := validation.IsDNS1123Subdomain(c.LeaderElectionLeaseName); len(errs) > 0 {
+			invalid("leader-election-lease-name: %q is not a valid Lease name", c.LeaderElectionLeaseName)
+		}
+		if c.LeaderElectionLeaseDuration < time.Second {
+			invalid("leader-election-lease-duration: must be at least 1s, got %s", c.LeaderElectionLeaseDuration)
+		}
+	}
+
+	if c.MaxConcurrentRequests < 0 || c.MaxConcurrentRequestsPerUser < 0 {
+		invalid("max-concurrent-requests: the caps must not be negative")
+	}
+	if c.ConcurrencyQueueSize < 0 {
+		invalid("concurrency-queue-size: must not be negative, got %d", c.ConcurrencyQueueSize)
+	}
+	if c.ConcurrencyQueueSize > 0 && c.ConcurrencyQueueTimeout <= 0 {
+		invalid("concurrency-queue-timeout: must be positive, got %s", c.ConcurrencyQueueTimeout)
+	}
+	if c.RateLimitUser < 0 || c.RateLimitUserBurst < 0 {
+		invalid("rate-limit-user: rate and burst must not be negative")
+	}
+	if c.RateLimitIP < 0 || c.RateLimitIPBurst < 0 {
+		invalid("rate-limit-ip: rate and burst must not be negative")
+	}
+	if c.CostBudget < 0 {
+		invalid("cost-budget: must not be negative, got %d", c.CostBudget)
+	}
+	if c.CostBudget > 0 && c.CostWindow <= 0 {
+		invalid("cost-window: must be positive, got %s", c.CostWindow)
+	}
+	if costs, err := ParseRouteCosts(c.RouteCosts); err != nil {
+		invalid("route-costs: %v", err)
+	} else if c.CostBudget > 0 {
+		for route, cost := range costs {
+			if cost > c.CostBudget {
+				invalid("route-costs: the cost %d of %s is over the cost-budget %d", cost, route, c.CostBudget)
+			}
+		}
+	}
+
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		invalid("cert-file and key-file: both must be set to enable TLS")
+	}
+	if refs, err := c.ParseUpstreamTLS(); err != nil {
+		invalid("upstream-tls: %v", err)
+	} else {
+		for _, ref := range refs {
+			if ref.InsecureSkipVerify && !c.DevMode {
+				invalid("upstream-tls: insecure-skip-verify of %s requires dev-mode", ref.Name)
+			}
+		}
+	}
+	if !servertls.ValidMinVersion(c.TLSMinVersion) {
+		invalid("tls-min-version: %q is not valid (must be 1.2 or 1.3)", c.TLSMinVersion)
+	}
+	if !servertls.ValidClientAuth(c.TLSClientAuth) {
+		invalid("tls-client-auth: %q is not valid (must be none, optional or require)", c.TLSClientAuth)
+	}
+	verifiesClients := c.TLSClientAuth == servertls.ClientAuthOptional || c.TLSClientAuth == servertls.ClientAuthRequire
+	if verifiesClients && (c.CertFile == "" || c.TLSClientCAFile == "") {
+		invalid("tls-client-auth: cert-file, key-file and tls-client-ca-file are required to verify client certificates")
+	}
+	if c.TLSClientCertIdentity {
+		if !verifiesClients {
+			invalid("tls-client-cert-identity: requires tls-client-auth optional or require")
+		}
+		if c.AuthMethod != AuthMethodInternal && c.AuthMethod != AuthMethodImpersonation {
+			invalid("tls-client-cert-identity: requires the internal or impersonation auth method, got %q", c.AuthMethod)
+		}
+	}
+
+	return errors.Join(problems...)
+}
+
+// validCORSOrigin reports whether origin is "*" or a scheme://host[:port] origin, where one "*"
+// may stand for any part of the host.
+func validCORSOrigin(origin string) bool {
+	if origin == "*" {
+		return true
+	}
+	if strings.Count(origin, "*") > 1 {
+		return false
+	}
+	u, err := url.Parse(strings.Replace(origin, "*", "wildcard", 1))
+	return err == nil && u.Scheme != "" && u.Host != "" && u.Path == "" && u.RawQuery == "" && u.User == nil
+}
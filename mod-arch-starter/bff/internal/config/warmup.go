@@ -0,0 +1,31 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WarmupIdentity is an identity of WarmupUsers.
+type WarmupIdentity struct {
+	UserID string
+	Groups []string
+}
+
+// ParseWarmupUsers parses the "user" or "user=group1;group2" entries of WarmupUsers.
+func ParseWarmupUsers(entries []string) ([]WarmupIdentity, error) {
+	identities := make([]WarmupIdentity, 0, len(entries))
+	for _, entry := range entries {
+		user, groups, _ := strings.Cut(entry, "=")
+		identity := WarmupIdentity{UserID: strings.TrimSpace(user)}
+		if identity.UserID == "" {
+			return nil, fmt.Errorf("invalid warmup user %q (must be user or user=group1;group2)", entry)
+		}
+		for _, group := range strings.Split(groups, ";") {
+			if group = strings.TrimSpace(group); group != "" {
+				identity.Groups = append(identity.Groups, group)
+			}
+		}
+		identities = append(identities, identity)
+	}
+	return identities, nil
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// WebhookEndpoint is an endpoint of WebhookEndpoints with its secret and event patterns.
+type WebhookEndpoint struct {
+	Name   string
+	URL    string
+	Secret string
+	// Events are the patterns of the event types delivered to the endpoint, all when empty.
+	Events []string
+}
+
+// ParseWebhookEndpoints parses the "name=url" entries of WebhookEndpoints, then assigns them
+// the "name=secret" entries of WebhookSecrets and the "name=pattern" entries of WebhookEvents.
+// Names are unique DNS labels, URLs absolute http(s) URLs, and every secret and pattern names
+// an endpoint.
+func (c EnvConfig) ParseWebhookEndpoints() ([]WebhookEndpoint, error) {
+	endpoints := make([]WebhookEndpoint, 0, len(c.WebhookEndpoints))
+	index := map[string]int{}
+	for _, entry := range c.WebhookEndpoints {
+		name, value, ok := strings.Cut(entry, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || len(validation.IsDNS1123Label(name)) > 0 {
+			return nil, fmt.Errorf("invalid endpoint %q (must be name=url)", entry)
+		}
+		if u, err := url.Parse(value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("endpoint %s: %q is not an absolute http(s) URL", name, value)
+		}
+		if _, seen := index[name]; seen {
+			return nil, fmt.Errorf("duplicate endpoint name %q", name)
+		}
+		index[name] = len(endpoints)
+		endpoints = append(endpoints, WebhookEndpoint{Name: name, URL: value})
+	}
+
+	// The entries may be secrets: only the names of known endpoints are echoed
+	endpoint := func(n int, entry, format string) (*WebhookEndpoint, string, error) {
+		name, value, _ := strings.Cut(entry, "=")
+		i, known := index[strings.TrimSpace(name)]
+		switch {
+		case !known:
+			return nil, "", fmt.Errorf("entry %d does not name an endpoint (must be %s)", n+1, format)
+		case strings.TrimSpace(value) == "":
+			return nil, "", fmt.Errorf("invalid entry for endpoint %q (must be %s)", endpoints[i].Name, format)
+		}
+		return &endpoints[i], strings.TrimSpace(value), nil
+	}
+	for n, entry := range c.WebhookSecrets {
+		e, secret, err := endpoint(n, entry, "name=secret")
+		if err != nil {
+			return nil, err
+		}
+		if e.Secret != "" {
+			return nil, fmt.Errorf("duplicate secret for endpoint %q", e.Name)
+		}
+		e.Secret = secret
+	}
+	for n, entry := range c.WebhookEvents {
+		e, pattern, err := endpoint(n, entry, "name=pattern")
+		if err != nil {
+			return nil, err
+		}
+		e.Events = append(e.Events, pattern)
+	}
+	return endpoints, nil
+}
@@ -7,6 +7,13 @@ type contextKey string
 const (
 	NamespaceHeaderParameterKey contextKey = "namespace"
 
+	// NamespaceContextKey stores the verified namespace of a request (see
+	// kubernetes.WithNamespace)
+	NamespaceContextKey contextKey = "NamespaceContextKey"
+
+	// ClusterQueryParameter names the cluster targeted by an API request (see App.SelectCluster)
+	ClusterQueryParameter = "cluster"
+
 	// The following keys are used to store the user access token in the context
 	RequestIdentityKey contextKey = "requestIdentityKey"
 
@@ -17,8 +24,38 @@ const (
 	KubeflowUserIDHeader       = "kubeflow-userid" // kubeflow-userid :contains the user's email address
 	KubeflowUserGroupsIdHeader = "kubeflow-groups" // kubeflow-groups : Holds a comma-separated list of user groups
 
+	// ModelRegistryClientKey stores the modelregistry.ClientInterface of the registry named in
+	// the request path (see App.AttachModelRegistryClient)
+	ModelRegistryClientKey contextKey = "ModelRegistryClientKey"
+
+	// ClusterKey stores the cluster targeted by the Kubernetes clients of a request (see
+	// kubernetes.WithCluster)
+	ClusterKey contextKey = "ClusterKey"
+
+	// DryRunKey stores the DryRun of a request whose writes are server-side dry runs (see
+	// repositories.WithDryRun)
+	DryRunKey contextKey = "DryRunKey"
+
+	// CSPNonceKey stores the nonce of the Content-Security-Policy of the response (see
+	// App.SetSecurityHeaders)
+	CSPNonceKey contextKey = "CSPNonceKey"
+
+	RequestIdKey   contextKey = "RequestIdKey"
 	TraceIdKey     contextKey = "TraceIdKey"
 	TraceLoggerKey contextKey = "TraceLoggerKey"
+
+	// RequestIDHeader carries the ID of a request, generated by the BFF when absent and echoed
+	// in the response
+	RequestIDHeader = "X-Request-ID"
+
+	// IdempotencyKeyHeader identifies the retries of a POST or PUT request, and
+	// IdempotentReplayedHeader marks their replayed responses (see App.HonorIdempotencyKeys)
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// W3C trace-context headers propagated by the frontend when tracing is enabled
+	TraceParentHeader = "traceparent"
+	TraceStateHeader  = "tracestate"
 )
 
 // BFFTarget represents a target BFF service (re-exported from bffclient package)
@@ -0,0 +1,327 @@
+// Package contracttest checks the API contract of handlers, so breaking changes to the
+// responses of a BFF are caught by its tests before the frontends notice them. A Suite sends
+// the requests of its cases to a handler and:
+//
+//   - compares each response with its golden file, testdata/contracts/<case>.json by default,
+//     listing the changed fields by JSON pointer and flagging the breaking ones (removed fields
+//     and changed types);
+//   - validates the response body against the schema of its operation in the OpenAPI document
+//     of the API, so the document keeps describing what the handlers serve.
+//
+// Golden files are written, or rewritten after an intended change, by running the tests with
+// UPDATE_CONTRACTS=true and reviewing the diff of testdata/contracts:
+//
+//	func TestContracts(t *testing.T) {
+//		spec, err := app.OpenAPISpec()
+//		require.NoError(t, err)
+//		contracttest.Suite{Handler: app.Routes(), Spec: spec, Header: http.Header{"kubeflow-userid": {"user@example.com"}}}.Run(t,
+//			contracttest.Case{Name: "list-namespaces", Method: http.MethodGet, Path: "/api/v1/namespaces"},
+//		)
+//	}
+package contracttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/objectdiff"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/openapi"
+)
+
+const (
+	// DefaultDir holds the golden files, relative to the package of the test.
+	DefaultDir = "testdata/contracts"
+	// UpdateEnv set to true writes the golden files instead of comparing them.
+	UpdateEnv = "UPDATE_CONTRACTS"
+	// Ignored replaces the values of the ignored fields in golden files.
+	Ignored = "<ignored>"
+)
+
+// DefaultIgnore are the fields that change on every request, always ignored.
+var DefaultIgnore = []string{"/error/requestId"}
+
+// Case is a request of a Suite and the response it expects.
+type Case struct {
+	// Name names the golden file, <Dir>/<Name>.json.
+	Name   string
+	Method string
+	// Path is the request URI, with its query.
+	Path string
+	// Body, when set, is sent encoded as JSON.
+	Body   any
+	Header http.Header
+	// Status is the expected status code (default 200).
+	Status int
+}
+
+// Suite runs the contract tests of a handler.
+type Suite struct {
+	Handler http.Handler
+	// Spec is the OpenAPI document of the API (e.g. App.OpenAPISpec). Without it, the responses
+	// are only compared with their golden files.
+	Spec []byte
+	// Dir defaults to DefaultDir.
+	Dir string
+	// Header is sent with every request, e.g. the identity headers.
+	Header http.Header
+	// Ignore lists the JSON pointers of the response bodies whose values change between runs,
+	// e.g. /data/0/creationTimestamp; a "*" segment matches any key or list item. They are
+	// ignored like DefaultIgnore.
+	Ignore []string
+	// Update writes the golden files, like UPDATE_CONTRACTS=true.
+	Update bool
+}
+
+// golden is the content of a golden file.
+type golden struct {
+	Request goldenRequest `json:"request"`
+	Status  int           `json:"status"`
+	Body    any           `json:"body,omitempty"`
+}
+
+type goldenRequest struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Body   any    `json:"body,omitempty"`
+}
+
+// Run checks every case in a subtest named after it.
+func (s Suite) Run(t *testing.T, cases ...Case) {
+	t.Helper()
+	var doc *openapi.Document
+	if s.Spec != nil {
+		doc = &openapi.Document{}
+		if err := json.Unmarshal(s.Spec, doc); err != nil {
+			t.Fatalf("invalid OpenAPI document: %v", err)
+		}
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			if err := s.check(doc, c); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// Check runs c and returns the differences with its golden file and the problems of the
+// response, nil when it fulfills the contract.
+func (s Suite) Check(c Case) error {
+	var doc *openapi.Document
+	if s.Spec != nil {
+		doc = &openapi.Document{}
+		if err := json.Unmarshal(s.Spec, doc); err != nil {
+			return fmt.Errorf("invalid OpenAPI document: %w", err)
+		}
+	}
+	return s.check(doc, c)
+}
+
+func (s Suite) check(doc *openapi.Document, c Case) error {
+	if c.Name == "" || c.Method == "" || c.Path == "" {
+		return errors.New("cases need a name, a method and a path")
+	}
+	status := c.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	actual, err := s.serve(c)
+	if err != nil {
+		return err
+	}
+	var problems []string
+	if actual.Status != status {
+		problems = append(problems, fmt.Sprintf("status %d, want %d", actual.Status, status))
+	}
+	if doc != nil {
+		problems = append(problems, validate(doc, c, actual)...)
+	}
+	for _, pointer := range append(slices.Clone(DefaultIgnore), s.Ignore...) {
+		ignore(actual.Body, objectdiff.Segments(pointer))
+	}
+
+	path := filepath.Join(s.dir(), c.Name+".json")
+	if s.Update || os.Getenv(UpdateEnv) == "true" {
+		if err := write(path, actual); err != nil {
+			return err
+		}
+	} else {
+		problems = append(problems, compareGolden(path, actual)...)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%s %s:\n%s", c.Method, c.Path, strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// compareGolden compares actual with the golden file at path.
+func compareGolden(path string, actual golden) []string {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{fmt.Sprintf("no golden file %s: run the test with %s=true to record it", path, UpdateEnv)}
+	} else if err != nil {
+		return []string{err.Error()}
+	}
+	var expected golden
+	if err := json.Unmarshal(data, &expected); err != nil {
+		return []string{fmt.Sprintf("invalid golden file %s: %v", path, err)}
+	}
+	if changes := diff(expected, actual); len(changes) > 0 {
+		return []string{fmt.Sprintf("the response differs from %s (run with %s=true if the change is intended):\n%s", path, UpdateEnv, strings.Join(changes, "\n"))}
+	}
+	return nil
+}
+
+// serve sends the request of c and returns the response as a golden file.
+func (s Suite) serve(c Case) (golden, error) {
+	var body io.Reader
+	var requestBody any
+	if c.Body != nil {
+		data, err := json.Marshal(c.Body)
+		if err != nil {
+			return golden{}, fmt.Errorf("failed to encode the request body: %w", err)
+		}
+		body = bytes.NewReader(data)
+		// Recorded as decoded, like the response
+		_ = json.Unmarshal(data, &requestBody)
+	}
+	req := httptest.NewRequest(c.Method, c.Path, body)
+	for _, header := range []http.Header{s.Header, c.Header} {
+		for name, values := range header {
+			req.Header[http.CanonicalHeaderKey(name)] = values
+		}
+	}
+	if c.Body != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rr := httptest.NewRecorder()
+	s.Handler.ServeHTTP(rr, req)
+
+	result := golden{Request: goldenRequest{Method: c.Method, Path: c.Path, Body: requestBody}, Status: rr.Code}
+	if data := rr.Body.Bytes(); len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, &result.Body); err != nil {
+			// Not JSON, e.g. text/plain, compared as text
+			result.Body = rr.Body.String()
+		}
+	}
+	return result, nil
+}
+
+func (s Suite) dir() string {
+	if s.Dir != "" {
+		return s.Dir
+	}
+	return DefaultDir
+}
+
+// validate checks the response body of c against the schema of its operation.
+func validate(doc *openapi.Document, c Case, actual golden) []string {
+	path, _, _ := strings.Cut(c.Path, "?")
+	schema, err := doc.ResponseSchema(c.Method, path, actual.Status)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	if schema == nil {
+		return nil
+	}
+	var problems []string
+	for _, problem := range doc.Validate(schema, actual.Body) {
+		problems = append(problems, "schema: "+problem)
+	}
+	return problems
+}
+
+// ignore replaces the values at the JSON pointer segments of value by Ignored.
+func ignore(value any, segments []string) {
+	if len(segments) == 0 {
+		return
+	}
+	segment, last := segments[0], len(segments) == 1
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if segment != "*" && segment != key {
+				continue
+			}
+			if last {
+				v[key] = Ignored
+			} else {
+				ignore(child, segments[1:])
+			}
+		}
+	case []any:
+		for i, child := range v {
+			if segment != "*" && segment != fmt.Sprint(i) {
+				continue
+			}
+			if last {
+				v[i] = Ignored
+			} else {
+				ignore(child, segments[1:])
+			}
+		}
+	}
+}
+
+// diff lists the changes from the expected response to the actual one, one per line.
+func diff(expected, actual golden) []string {
+	var changes []string
+	if expected.Status != actual.Status {
+		changes = append(changes, fmt.Sprintf("  status: %d -> %d (breaking)", expected.Status, actual.Status))
+	}
+	for _, change := range objectdiff.Values(expected.Body, actual.Body) {
+		old, value := encode(change.OldValue, ""), encode(change.Value, "")
+		path := change.Path
+		if path == "" {
+			path = "/"
+		}
+		switch change.Op {
+		case objectdiff.OpAdd:
+			changes = append(changes, fmt.Sprintf("  + %s: %s", path, value))
+		case objectdiff.OpRemove:
+			changes = append(changes, fmt.Sprintf("  - %s: %s (breaking)", path, old))
+		default:
+			line := fmt.Sprintf("  ~ %s: %s -> %s", path, old, value)
+			if reflect.TypeOf(change.OldValue) != reflect.TypeOf(change.Value) {
+				line += " (breaking)"
+			}
+			changes = append(changes, line)
+		}
+	}
+	return changes
+}
+
+// write writes the golden file of actual at path.
+func write(path string, actual golden) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(encode(actual, "  ")), 0o644)
+}
+
+// encode returns v as JSON, indented with indent, without escaping HTML characters such as
+// the brackets of Ignored.
+func encode(v any, indent string) string {
+	var b strings.Builder
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", indent)
+	_ = encoder.Encode(v)
+	if indent == "" {
+		return strings.TrimSuffix(b.String(), "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,78 @@
+package contracttest
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/openapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type item struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+type itemsEnvelope struct {
+	Data      []item `json:"data"`
+	RequestID string `json:"requestId"`
+}
+
+func TestSuite_Check(t *testing.T) {
+	doc, err := openapi.NewBuilder(openapi.Info{Title: "test", Version: "1"}).
+		Add(openapi.Operation{Method: http.MethodGet, Path: "/items", Response: itemsEnvelope{}}).
+		Build()
+	require.NoError(t, err)
+	spec, err := json.Marshal(doc)
+	require.NoError(t, err)
+
+	var response string
+	suite := Suite{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "user@example.com", r.Header.Get("kubeflow-userid"))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(response))
+		}),
+		Spec:   spec,
+		Dir:    t.TempDir(),
+		Header: http.Header{"kubeflow-userid": {"user@example.com"}},
+		Ignore: []string{"/requestId"},
+	}
+	c := Case{Name: "list-items", Method: http.MethodGet, Path: "/items?sort=name"}
+
+	response = `{"data": [{"name": "a", "count": 1}], "requestId": "1"}`
+	err = suite.Check(c)
+	assert.ErrorContains(t, err, "UPDATE_CONTRACTS=true to record it")
+
+	suite.Update = true
+	require.NoError(t, suite.Check(c))
+	data, err := os.ReadFile(filepath.Join(suite.Dir, "list-items.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"requestId": "<ignored>"`)
+
+	suite.Update = false
+	response = `{"data": [{"name": "a", "count": 1}], "requestId": "2"}`
+	assert.NoError(t, suite.Check(c), "ignored fields may change")
+
+	response = `{"data": [{"name": "a", "count": "1"}, {"name": "b", "count": 2}], "requestId": "3"}`
+	err = suite.Check(c)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `~ /data/0/count: 1 -> "1" (breaking)`)
+	assert.Contains(t, err.Error(), `+ /data/1/name: "b"`)
+	assert.Contains(t, err.Error(), "schema: /data/0/count: string, want integer")
+
+	response = `{"data": [{"name": "a"}], "requestId": "4"}`
+	err = suite.Check(c)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `- /data/0/count: 1 (breaking)`)
+	assert.Contains(t, err.Error(), `schema: /data/0: missing required property "count"`)
+
+	err = suite.Check(Case{Name: "list-items", Method: http.MethodGet, Path: "/items", Status: http.StatusCreated})
+	assert.ErrorContains(t, err, "status 200, want 201")
+	err = suite.Check(Case{Name: "other", Method: http.MethodGet, Path: "/other"})
+	assert.ErrorContains(t, err, "no operation of the OpenAPI document serves /other")
+}
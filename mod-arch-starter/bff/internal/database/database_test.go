@@ -0,0 +1,291 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+// result is the answer of the fake database to a statement.
+type result struct {
+	columns  []string
+	rows     [][]driver.Value
+	affected int64
+	err      error
+}
+
+// fakeDB is a database/sql driver recording the statements it runs and answering them with
+// handle, or with nothing.
+type fakeDB struct {
+	mu         sync.Mutex
+	statements []string
+	handle     func(query string, args []any) result
+}
+
+func (f *fakeDB) run(query string, args []driver.NamedValue) result {
+	values := make([]any, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	f.mu.Lock()
+	f.statements = append(f.statements, query)
+	f.mu.Unlock()
+	if f.handle == nil {
+		return result{}
+	}
+	return f.handle(query, values)
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return fakeConn{f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c fakeConn) Close() error                        { return nil }
+func (c fakeConn) Begin() (driver.Tx, error) {
+	c.db.run("BEGIN", nil)
+	return fakeTx(c), nil
+}
+
+func (c fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	r := c.db.run(query, args)
+	return driver.RowsAffected(r.affected), r.err
+}
+
+func (c fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	r := c.db.run(query, args)
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &fakeRows{columns: r.columns, rows: r.rows}, nil
+}
+
+type fakeTx fakeConn
+
+func (tx fakeTx) Commit() error   { tx.db.run("COMMIT", nil); return nil }
+func (tx fakeTx) Rollback() error { tx.db.run("ROLLBACK", nil); return nil }
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func newFakeDB(t *testing.T, dialect Dialect, handle func(query string, args []any) result) (*DB, *fakeDB) {
+	fake := &fakeDB{handle: handle}
+	db := New(sql.OpenDB(fake), dialect)
+	t.Cleanup(func() { _ = db.Close() })
+	return db, fake
+}
+
+// pgError is an error of Postgres drivers.
+type pgError struct{ code string }
+
+func (e pgError) Error() string    { return "ERROR (SQLSTATE " + e.code + ")" }
+func (e pgError) SQLState() string { return e.code }
+
+func TestRebind(t *testing.T) {
+	query := `SELECT id FROM views WHERE owner = ? AND name <> '?' AND "a?" = ? LIMIT ?`
+	assert.Equal(t, `SELECT id FROM views WHERE owner = $1 AND name <> '?' AND "a?" = $2 LIMIT $3`, Postgres.Rebind(query))
+	assert.Equal(t, query, SQLite.Rebind(query))
+}
+
+func TestOpen(t *testing.T) {
+	_, err := Open(context.Background(), Config{Driver: "mysql", URL: "root@/bff"})
+	assert.ErrorContains(t, err, "unsupported driver")
+	_, err = Open(context.Background(), Config{Driver: "sqlite3", URL: "file:bff.db"})
+	assert.ErrorContains(t, err, "not compiled in", "mattn/go-sqlite3 is not imported by the tests")
+}
+
+type view struct{ ID, Owner, Name string }
+
+var views = Table[view]{
+	Name:    "views",
+	Key:     "id",
+	Columns: []string{"id", "owner", "name"},
+	Scan: func(row Scanner) (view, error) {
+		var v view
+		return v, row.Scan(&v.ID, &v.Owner, &v.Name)
+	},
+	Values: func(v view) []any { return []any{v.ID, v.Owner, v.Name} },
+}
+
+func TestTable(t *testing.T) {
+	var args []any
+	db, fake := newFakeDB(t, Postgres, func(query string, a []any) result {
+		args = a
+		switch {
+		case strings.HasPrefix(query, "INSERT") && a[0] == "v1":
+			return result{err: pgError{code: "23505"}}
+		case strings.HasPrefix(query, "UPDATE"), strings.HasPrefix(query, "DELETE"):
+			return result{affected: map[bool]int64{true: 1}[a[len(a)-1] == "v1"]}
+		case strings.HasPrefix(query, "SELECT") && (len(a) == 0 || a[0] == "v1" || a[0] == "dora"):
+			return result{columns: []string{"id", "owner", "name"}, rows: [][]driver.Value{{"v1", "dora", "GPU workbenches"}}}
+		}
+		return result{columns: []string{"id", "owner", "name"}}
+	})
+	ctx := context.Background()
+
+	require.NoError(t, views.Insert(ctx, db, view{ID: "v2", Owner: "dora", Name: "Drafts"}))
+	assert.Equal(t, "INSERT INTO views (id, owner, name) VALUES ($1, $2, $3)", fake.statements[0])
+	assert.Equal(t, []any{"v2", "dora", "Drafts"}, args)
+	assert.ErrorIs(t, views.Insert(ctx, db, view{ID: "v1"}), ErrConflict)
+
+	got, err := views.Get(ctx, db, "v1")
+	require.NoError(t, err)
+	assert.Equal(t, view{ID: "v1", Owner: "dora", Name: "GPU workbenches"}, got)
+	_, err = views.Get(ctx, db, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	list, err := views.List(ctx, db, "owner = ? ORDER BY name", "dora")
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, "SELECT id, owner, name FROM views WHERE owner = $1 ORDER BY name", fake.statements[len(fake.statements)-1])
+	list, err = views.List(ctx, db, "owner = ?", "bella")
+	require.NoError(t, err)
+	assert.Equal(t, []view{}, list)
+
+	require.NoError(t, views.Update(ctx, db, view{ID: "v1", Owner: "dora", Name: "Renamed"}))
+	assert.Equal(t, "UPDATE views SET owner = $1, name = $2 WHERE id = $3", fake.statements[len(fake.statements)-1])
+	assert.Equal(t, []any{"dora", "Renamed", "v1"}, args)
+	assert.ErrorIs(t, views.Update(ctx, db, view{ID: "missing"}), ErrNotFound)
+	require.NoError(t, views.Delete(ctx, db, "v1"))
+	assert.ErrorIs(t, views.Delete(ctx, db, "missing"), ErrNotFound)
+
+	broken := views
+	broken.Values = func(v view) []any { return []any{v.ID} }
+	assert.Error(t, broken.Insert(ctx, db, view{ID: "v3"}))
+}
+
+func TestInTx(t *testing.T) {
+	db, fake := newFakeDB(t, SQLite, func(query string, _ []any) result {
+		if strings.Contains(query, "UNIQUE") {
+			return result{err: errors.New("UNIQUE constraint failed: views.id")}
+		}
+		return result{}
+	})
+	ctx := context.Background()
+
+	require.NoError(t, db.InTx(ctx, func(tx *Tx) error {
+		_, err := tx.ExecContext(ctx, "DELETE FROM views WHERE owner = ?", "dora")
+		return err
+	}))
+	assert.Equal(t, []string{"BEGIN", "DELETE FROM views WHERE owner = ?", "COMMIT"}, fake.statements)
+
+	fake.statements = nil
+	err := db.InTx(ctx, func(tx *Tx) error {
+		_, err := tx.ExecContext(ctx, "UNIQUE")
+		return err
+	})
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.Equal(t, []string{"BEGIN", "UNIQUE", "ROLLBACK"}, fake.statements)
+
+	fake.statements = nil
+	assert.Panics(t, func() {
+		_ = db.InTx(ctx, func(*Tx) error { panic("boom") })
+	})
+	assert.Equal(t, []string{"BEGIN", "ROLLBACK"}, fake.statements)
+}
+
+func TestMigrate(t *testing.T) {
+	applied := map[string][]driver.Value{}
+	db, fake := newFakeDB(t, Postgres, func(query string, args []any) result {
+		switch {
+		case strings.HasPrefix(query, "INSERT INTO bff_schema_migrations"):
+			applied[args[0].(string)] = append(applied[args[0].(string)], args[1])
+		case strings.HasPrefix(query, "SELECT version"):
+			var rows [][]driver.Value
+			for _, version := range applied[args[0].(string)] {
+				rows = append(rows, []driver.Value{version})
+			}
+			return result{columns: []string{"version"}, rows: rows}
+		case strings.Contains(query, "broken"):
+			return result{err: errors.New("syntax error")}
+		}
+		return result{}
+	})
+	ctx := context.Background()
+	logger := slog.New(slog.DiscardHandler)
+	migrations := []Migration{
+		{Version: 1, Name: "create views", SQL: "CREATE TABLE notebooks_views (id TEXT PRIMARY KEY)"},
+		{Version: 2, Name: "add owner", SQL: "ALTER TABLE notebooks_views ADD COLUMN owner TEXT"},
+	}
+
+	require.NoError(t, db.Migrate(ctx, "notebooks", migrations, logger))
+	assert.Equal(t, "SELECT pg_advisory_xact_lock($1)", fake.statements[1], "the replicas migrate one at a time")
+	assert.Contains(t, fake.statements, "CREATE TABLE notebooks_views (id TEXT PRIMARY KEY)")
+	assert.Equal(t, []driver.Value{int64(1), int64(2)}, applied["notebooks"])
+	assert.Equal(t, "COMMIT", fake.statements[len(fake.statements)-1])
+
+	fake.statements = nil
+	migrations = append(migrations, Migration{Version: 3, Name: "add name", SQL: "ALTER TABLE notebooks_views ADD COLUMN name TEXT"})
+	require.NoError(t, db.Migrate(ctx, "notebooks", migrations, logger))
+	assert.NotContains(t, fake.statements, "CREATE TABLE notebooks_views (id TEXT PRIMARY KEY)", "applied once")
+	assert.Contains(t, fake.statements, "ALTER TABLE notebooks_views ADD COLUMN name TEXT")
+	assert.Equal(t, []driver.Value{int64(1), int64(2), int64(3)}, applied["notebooks"])
+	require.NoError(t, db.Migrate(ctx, "notebooks", migrations[:1], logger), "versions of a newer release are kept")
+
+	fake.statements = nil
+	err := db.Migrate(ctx, "pipelines", []Migration{{Version: 1, Name: "broken", SQL: "CREATE TABLE broken"}}, logger)
+	assert.ErrorContains(t, err, "migration 1 (broken) of pipelines failed")
+	assert.Equal(t, "ROLLBACK", fake.statements[len(fake.statements)-1])
+	assert.Empty(t, applied["pipelines"])
+
+	assert.ErrorContains(t, db.Migrate(ctx, "pipelines", []Migration{{Version: 2}, {Version: 1}}, logger), "increasing positive versions")
+	assert.ErrorContains(t, db.Migrate(ctx, "pipelines", []Migration{{Version: 0}}, logger), "increasing positive versions")
+}
+
+func TestSQLite(t *testing.T) {
+	ctx := context.Background()
+	db, err := Open(ctx, Config{Driver: "sqlite", URL: "file:" + t.TempDir() + "/bff.db"})
+	require.NoError(t, err)
+	defer db.Close()
+	assert.Equal(t, SQLite.Name, db.Dialect().Name)
+
+	migrations := []Migration{
+		{Version: 1, Name: "create views", SQL: "CREATE TABLE views (id TEXT PRIMARY KEY, owner TEXT NOT NULL)"},
+		{Version: 2, Name: "add name", SQL: "ALTER TABLE views ADD COLUMN name TEXT NOT NULL DEFAULT ''"},
+	}
+	logger := slog.New(slog.DiscardHandler)
+	require.NoError(t, db.Migrate(ctx, "notebooks", migrations, logger))
+	require.NoError(t, db.Migrate(ctx, "notebooks", migrations, logger), "applied once")
+	versions, err := Select(ctx, db, func(row Scanner) (int, error) {
+		var version int
+		return version, row.Scan(&version)
+	}, "SELECT version FROM bff_schema_migrations WHERE module = ? ORDER BY version", "notebooks")
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, versions)
+
+	err = db.Migrate(ctx, "pipelines", []Migration{{Version: 1, Name: "broken", SQL: "CREATE TABLE"}}, logger)
+	assert.ErrorContains(t, err, "migration 1 (broken) of pipelines failed")
+
+	require.NoError(t, views.Insert(ctx, db, view{ID: "v1", Owner: "alice", Name: "GPU workbenches"}))
+	assert.ErrorIs(t, views.Insert(ctx, db, view{ID: "v1", Owner: "bob"}), ErrConflict)
+	got, err := views.Get(ctx, db, "v1")
+	require.NoError(t, err)
+	assert.Equal(t, view{ID: "v1", Owner: "alice", Name: "GPU workbenches"}, got)
+	_, err = views.Get(ctx, db, "v2")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"time"
+)
+
+// migrationsTable records the migrations applied, by module.
+const migrationsTable = "bff_schema_migrations"
+
+// Migration is a version of the schema of a module.
+type Migration struct {
+	// Version orders the migrations of the module; it is positive and never reused.
+	Version int
+	// Name describes the change, e.g. "create views".
+	Name string
+	// SQL are the statements of the change, e.g. CREATE TABLE views (...). Use the types
+	// common to the dialects (TEXT, INTEGER, BIGINT, BOOLEAN), and tables prefixed with the
+	// module name, as the modules share the database.
+	SQL string
+}
+
+// Migrate applies the migrations of module not applied yet, in the order of their versions,
+// in one transaction: a failure leaves the schema unchanged. Replicas starting together apply
+// them once: on Postgres they wait for each other with an advisory lock, and SQLite
+// serializes the transactions. Versions applied by
+// a newer release are left alone, so a rollback keeps serving.
+func (db *DB) Migrate(ctx context.Context, module string, migrations []Migration, logger *slog.Logger) error {
+	last := 0
+	for _, m := range migrations {
+		if m.Version <= last {
+			return fmt.Errorf("database: the migrations of %s must have increasing positive versions, got %d after %d", module, m.Version, last)
+		}
+		last = m.Version
+	}
+
+	return db.InTx(ctx, func(tx *Tx) error {
+		if db.dialect.Name == Postgres.Name {
+			// Also serializes the creation of the table, which races in Postgres
+			if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(?)", lockKey()); err != nil {
+				return fmt.Errorf("database: failed to lock the migrations of %s: %w", module, err)
+			}
+		}
+		_, err := tx.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+migrationsTable+
+			" (module TEXT NOT NULL, version INTEGER NOT NULL, name TEXT NOT NULL, applied_at BIGINT NOT NULL, PRIMARY KEY (module, version))")
+		if err != nil {
+			return fmt.Errorf("database: failed to create %s: %w", migrationsTable, err)
+		}
+		applied, err := Select(ctx, tx, func(row Scanner) (int, error) {
+			var version int
+			return version, row.Scan(&version)
+		}, "SELECT version FROM "+migrationsTable+" WHERE module = ?", module)
+		if err != nil {
+			return fmt.Errorf("database: failed to read the migrations of %s: %w", module, err)
+		}
+		done := map[int]bool{}
+		for _, version := range applied {
+			done[version] = true
+		}
+
+		for _, m := range migrations {
+			if done[m.Version] {
+				continue
+			}
+			logger.Info("applying database migration", slog.String("module", module), slog.Int("version", m.Version), slog.String("name", m.Name))
+			if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+				return fmt.Errorf("database: migration %d (%s) of %s failed: %w", m.Version, m.Name, module, err)
+			}
+			if _, err := tx.ExecContext(ctx, "INSERT INTO "+migrationsTable+" (module, version, name, applied_at) VALUES (?, ?, ?, ?)",
+				module, m.Version, m.Name, time.Now().Unix()); err != nil {
+				return fmt.Errorf("database: failed to record migration %d of %s: %w", m.Version, module, err)
+			}
+		}
+		return nil
+	})
+}
+
+// lockKey is the Postgres advisory lock of the migrations.
+func lockKey() int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(migrationsTable))
+	return int64(h.Sum64())
+}
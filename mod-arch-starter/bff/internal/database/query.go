@@ -0,0 +1,59 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// Scanner reads the columns of a row, a *sql.Row or *sql.Rows.
+type Scanner interface {
+	Scan(dest ...any) error
+}
+
+// ScanFunc reads a T from a row.
+type ScanFunc[T any] func(row Scanner) (T, error)
+
+// Get returns the first row of query scanned by scan, or ErrNotFound.
+func Get[T any](ctx context.Context, q Querier, scan ScanFunc[T], query string, args ...any) (T, error) {
+	value, err := scan(q.QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		var zero T
+		return zero, ErrNotFound
+	}
+	return value, err
+}
+
+// Select returns the rows of query scanned by scan, an empty slice when there are none.
+func Select[T any](ctx context.Context, q Querier, scan ScanFunc[T], query string, args ...any) ([]T, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	values := []T{}
+	for rows.Next() {
+		value, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+// Affected returns ErrNotFound when the statement of result changed no row, e.g. the update of
+// a missing one.
+func Affected(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Table is the base of the repositories of the rows of one table, mapped to T, e.g. the View
+// of the package example:
+//
+//	var views = database.Table[View]{
+//		Name:    "notebooks_views",
+//		Key:     "id",
+//		Columns: []string{"id", "owner", "name"},
+//		Scan:    scanView,
+//		Values:  func(v View) []any { return []any{v.ID, v.Owner, v.Name} },
+//	}
+//
+//	err := views.Insert(ctx, db, View{ID: id, Owner: user, Name: "GPU workbenches"})
+//	mine, err := views.List(ctx, db, "owner = ? ORDER BY name", user)
+//
+// Its queries run on a DB or in the Tx of InTx.
+type Table[T any] struct {
+	// Name is the name of the table.
+	Name string
+	// Key is the primary key column.
+	Key string
+	// Columns are the columns of T, Key included, in the order of Scan and Values.
+	Columns []string
+	// Scan reads a T from the Columns of a row.
+	Scan ScanFunc[T]
+	// Values returns the values of the Columns of a T.
+	Values func(T) []any
+}
+
+func (t Table[T]) selectFrom() string {
+	return "SELECT " + strings.Join(t.Columns, ", ") + " FROM " + t.Name
+}
+
+// Get returns the row of key, or ErrNotFound.
+func (t Table[T]) Get(ctx context.Context, q Querier, key any) (T, error) {
+	return Get(ctx, q, t.Scan, t.selectFrom()+" WHERE "+t.Key+" = ?", key)
+}
+
+// List returns the rows matching where, a condition that may end with ORDER BY or LIMIT
+// clauses; an empty where lists every row.
+func (t Table[T]) List(ctx context.Context, q Querier, where string, args ...any) ([]T, error) {
+	query := t.selectFrom()
+	if where != "" {
+		query += " WHERE " + where
+	}
+	return Select(ctx, q, t.Scan, query, args...)
+}
+
+// Insert adds the row of v, failing with ErrConflict when its key exists.
+func (t Table[T]) Insert(ctx context.Context, q Querier, v T) error {
+	values, err := t.values(v)
+	if err != nil {
+		return err
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+	_, err = q.ExecContext(ctx, "INSERT INTO "+t.Name+" ("+strings.Join(t.Columns, ", ")+") VALUES ("+placeholders+")", values...)
+	return err
+}
+
+// Update replaces the columns of the row with the key of v, or fails with ErrNotFound.
+func (t Table[T]) Update(ctx context.Context, q Querier, v T) error {
+	values, err := t.values(v)
+	if err != nil {
+		return err
+	}
+	var set []string
+	var args []any
+	var key any
+	for i, column := range t.Columns {
+		if column == t.Key {
+			key = values[i]
+			continue
+		}
+		set = append(set, column+" = ?")
+		args = append(args, values[i])
+	}
+	return Affected(q.ExecContext(ctx, "UPDATE "+t.Name+" SET "+strings.Join(set, ", ")+" WHERE "+t.Key+" = ?", append(args, key)...))
+}
+
+// Delete deletes the row of key, or fails with ErrNotFound.
+func (t Table[T]) Delete(ctx context.Context, q Querier, key any) error {
+	return Affected(q.ExecContext(ctx, "DELETE FROM "+t.Name+" WHERE "+t.Key+" = ?", key))
+}
+
+func (t Table[T]) values(v T) ([]any, error) {
+	values := t.Values(v)
+	if len(values) != len(t.Columns) || !slices.Contains(t.Columns, t.Key) {
+		return nil, fmt.Errorf("database: table %s: %d values for the columns %v with the key %s", t.Name, len(values), t.Columns, t.Key)
+	}
+	return values, nil
+}
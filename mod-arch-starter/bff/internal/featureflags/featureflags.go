@@ -0,0 +1,197 @@
+// Package featureflags evaluates feature flags per request, with targeting rules matching the
+// user, their groups, the namespace and a percentage of users.
+package featureflags
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Flag is a feature flag and its targeting rules.
+type Flag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Enabled is the value of the flag when no rule matches.
+	Enabled bool `json:"enabled"`
+	// Rules are evaluated in order; the first matching rule sets the value.
+	Rules []Rule `json:"rules,omitempty"`
+}
+
+// Rule sets the value of a flag for the subjects it matches. A subject matches when it meets
+// every condition set: its user is one of Users, one of its groups is in Groups, the namespace
+// is one of Namespaces, and it falls in the Percentage of users. A rule without conditions
+// matches every subject.
+type Rule struct {
+	Users      []string `json:"users,omitempty"`
+	Groups     []string `json:"groups,omitempty"`
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Percentage (0-100) rolls the flag out to a share of the users, always the same ones for a
+	// flag: the user's bucket is a hash of the flag name and user. Subjects without a user
+	// never match.
+	Percentage *int `json:"percentage,omitempty"`
+	Enabled    bool `json:"enabled"`
+}
+
+// Subject is who and what a flag is evaluated for.
+type Subject struct {
+	User      string
+	Groups    []string
+	Namespace string
+}
+
+// File is the YAML or JSON file of flags, e.g. mounted from a ConfigMap:
+//
+//	flags:
+//	  - name: pipelines
+//	    enabled: false
+//	    rules:
+//	      - groups: [beta-testers]
+//	        enabled: true
+//	      - percentage: 10
+//	        enabled: true
+type File struct {
+	Flags []Flag `json:"flags"`
+}
+
+// LoadFile reads and validates the flags of the file at path.
+func LoadFile(path string) ([]Flag, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feature flags: %w", err)
+	}
+	var file File
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse feature flags %s: %w", path, err)
+	}
+	if err := Validate(file.Flags); err != nil {
+		return nil, fmt.Errorf("invalid feature flags %s: %w", path, err)
+	}
+	return file.Flags, nil
+}
+
+// Validate reports every flag without a name, defined twice, or with a percentage outside 0-100.
+func Validate(flags []Flag) error {
+	var problems []error
+	seen := make(map[string]bool, len(flags))
+	for _, flag := range flags {
+		if flag.Name == "" {
+			problems = append(problems, errors.New("flag without a name"))
+			continue
+		}
+		if seen[flag.Name] {
+			problems = append(problems, fmt.Errorf("flag %q: defined twice", flag.Name))
+		}
+		seen[flag.Name] = true
+		for i, rule := range flag.Rules {
+			if rule.Percentage != nil && (*rule.Percentage < 0 || *rule.Percentage > 100) {
+				problems = append(problems, fmt.Errorf("flag %q: rule %d: percentage %d is not between 0 and 100", flag.Name, i+1, *rule.Percentage))
+			}
+		}
+	}
+	return errors.Join(problems...)
+}
+
+// Set holds the flags evaluated by the BFF. It is safe for concurrent use, and its flags can
+// be replaced at runtime.
+type Set struct {
+	mu    sync.RWMutex
+	flags map[string]Flag
+}
+
+// NewSet returns a Set of the valid flags.
+func NewSet(flags []Flag) (*Set, error) {
+	set := &Set{}
+	if err := set.Replace(flags); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// Replace validates flags and, when they are valid, replaces the flags of the set.
+func (s *Set) Replace(flags []Flag) error {
+	if err := Validate(flags); err != nil {
+		return err
+	}
+	byName := make(map[string]Flag, len(flags))
+	for _, flag := range flags {
+		byName[flag.Name] = flag
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flags = byName
+	return nil
+}
+
+// Flags returns the flags of the set, sorted by name.
+func (s *Set) Flags() []Flag {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	flags := make([]Flag, 0, len(s.flags))
+	for _, flag := range s.flags {
+		flags = append(flags, flag)
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Name < flags[j].Name })
+	return flags
+}
+
+// Enabled evaluates the flag name for subject. Unknown flags are disabled.
+func (s *Set) Enabled(name string, subject Subject) bool {
+	s.mu.RLock()
+	flag, ok := s.flags[name]
+	s.mu.RUnlock()
+	return ok && flag.Evaluate(subject)
+}
+
+// Evaluate evaluates every flag of the set for subject.
+func (s *Set) Evaluate(subject Subject) map[string]bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	values := make(map[string]bool, len(s.flags))
+	for name, flag := range s.flags {
+		values[name] = flag.Evaluate(subject)
+	}
+	return values
+}
+
+// Evaluate returns the value of the first rule of the flag matching subject, or Enabled.
+func (f Flag) Evaluate(subject Subject) bool {
+	for _, rule := range f.Rules {
+		if rule.matches(f.Name, subject) {
+			return rule.Enabled
+		}
+	}
+	return f.Enabled
+}
+
+func (r Rule) matches(flag string, subject Subject) bool {
+	if len(r.Users) > 0 && !slices.Contains(r.Users, subject.User) {
+		return false
+	}
+	if len(r.Groups) > 0 && !slices.ContainsFunc(subject.Groups, func(group string) bool { return slices.Contains(r.Groups, group) }) {
+		return false
+	}
+	if len(r.Namespaces) > 0 && !slices.Contains(r.Namespaces, subject.Namespace) {
+		return false
+	}
+	if r.Percentage != nil && (subject.User == "" || bucket(flag, subject.User) >= *r.Percentage) {
+		return false
+	}
+	return true
+}
+
+// bucket places user in one of 100 buckets, independently for each flag so the same users
+// don't get every rollout first.
+func bucket(flag, user string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(flag))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(user))
+	return int(h.Sum32() % 100)
+}
@@ -0,0 +1,106 @@
+package featureflags
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func percentage(p int) *int { return &p }
+
+func TestFlagEvaluate(t *testing.T) {
+	flag := Flag{
+		Name: "pipelines",
+		Rules: []Rule{
+			{Users: []string{"blocked@example.com"}, Enabled: false},
+			{Groups: []string{"beta"}, Namespaces: []string{"team-a", "team-b"}, Enabled: true},
+			{Users: []string{"blocked@example.com", "dev@example.com"}, Enabled: true},
+		},
+	}
+
+	assert.False(t, flag.Evaluate(Subject{}), "the default")
+	assert.True(t, flag.Evaluate(Subject{User: "dev@example.com"}))
+	assert.True(t, flag.Evaluate(Subject{User: "u", Groups: []string{"x", "beta"}, Namespace: "team-b"}))
+	assert.False(t, flag.Evaluate(Subject{User: "u", Groups: []string{"beta"}, Namespace: "team-c"}), "every condition must match")
+	assert.False(t, flag.Evaluate(Subject{User: "blocked@example.com", Groups: []string{"beta"}, Namespace: "team-a"}), "the first matching rule wins")
+
+	flag.Enabled = true
+	flag.Rules = []Rule{{Enabled: false}}
+	assert.False(t, flag.Evaluate(Subject{}), "a rule without conditions matches everyone")
+}
+
+func TestFlagEvaluate_Percentage(t *testing.T) {
+	rollout := Flag{Name: "rollout", Rules: []Rule{{Percentage: percentage(25), Enabled: true}}}
+	other := Flag{Name: "other", Rules: []Rule{{Percentage: percentage(25), Enabled: true}}}
+
+	enabled, both := 0, 0
+	for i := 0; i < 1000; i++ {
+		subject := Subject{User: fmt.Sprintf("user-%d@example.com", i)}
+		value := rollout.Evaluate(subject)
+		assert.Equal(t, value, rollout.Evaluate(subject), "stable for a user")
+		if value {
+			enabled++
+			if other.Evaluate(subject) {
+				both++
+			}
+		}
+	}
+	assert.InDelta(t, 250, enabled, 50)
+	assert.Less(t, both, enabled/2, "flags roll out to different users")
+
+	assert.False(t, rollout.Evaluate(Subject{}), "anonymous subjects are not rolled out to")
+	all := Flag{Name: "all", Rules: []Rule{{Percentage: percentage(100), Enabled: true}}}
+	none := Flag{Name: "none", Enabled: true, Rules: []Rule{{Percentage: percentage(0), Enabled: false}}}
+	assert.True(t, all.Evaluate(Subject{User: "u"}))
+	assert.True(t, none.Evaluate(Subject{User: "u"}))
+}
+
+func TestSet(t *testing.T) {
+	set, err := NewSet([]Flag{{Name: "b", Enabled: true}, {Name: "a"}})
+	require.NoError(t, err)
+	assert.True(t, set.Enabled("b", Subject{}))
+	assert.False(t, set.Enabled("unknown", Subject{}))
+	assert.Equal(t, map[string]bool{"a": false, "b": true}, set.Evaluate(Subject{}))
+	assert.Equal(t, "a", set.Flags()[0].Name)
+
+	err = set.Replace([]Flag{{Name: "a"}, {Name: "a"}, {Name: ""}, {Name: "c", Rules: []Rule{{Percentage: percentage(101)}}}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "defined twice")
+	assert.Contains(t, err.Error(), "without a name")
+	assert.Contains(t, err.Error(), "percentage 101")
+	assert.True(t, set.Enabled("b", Subject{}), "invalid flags are not applied")
+
+	require.NoError(t, set.Replace([]Flag{{Name: "c", Enabled: true}}))
+	assert.Equal(t, map[string]bool{"c": true}, set.Evaluate(Subject{}))
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`flags:
+  - name: pipelines
+    description: Data science pipelines
+    rules:
+      - groups: [beta]
+        enabled: true
+      - percentage: 10
+        enabled: true
+`), 0o600))
+
+	flags, err := LoadFile(path)
+	require.NoError(t, err)
+	require.Len(t, flags, 1)
+	assert.Equal(t, "pipelines", flags[0].Name)
+	assert.Equal(t, []string{"beta"}, flags[0].Rules[0].Groups)
+	assert.Equal(t, 10, *flags[0].Rules[1].Percentage)
+
+	require.NoError(t, os.WriteFile(path, []byte("flags:\n  - name: pipelines\n    enable: true\n"), 0o600))
+	_, err = LoadFile(path)
+	assert.Error(t, err, "unknown fields are typos")
+
+	_, err = LoadFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
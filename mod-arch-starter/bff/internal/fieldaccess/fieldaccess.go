@@ -0,0 +1,240 @@
+// Package fieldaccess strips the fields of API responses the requesting user may not read, so
+// handlers don't each filter their sensitive data. A model field requires a permission with
+// an access tag, either an access review of a verb on a resource (in an API group, kubectl
+// style), or cluster admin:
+//
+//	type ComponentHealth struct {
+//		Name        string             `json:"name"`
+//		Deployments []DeploymentHealth `json:"deployments,omitempty" access:"cluster-admin"`
+//		Pods        []PodHealth        `json:"pods,omitempty" access:"list pods"`
+//		Scaling     []Autoscaler       `json:"scaling,omitempty" access:"get horizontalpodautoscalers.autoscaling"`
+//	}
+//
+// Filter returns a copy of a value with the fields whose permission is denied set to their
+// zero value, so they are left out of the JSON when tagged omitempty. Each permission is
+// checked once per Filter call, whatever the number of values holding the field.
+package fieldaccess
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// TagName is the struct tag of the permission of a field.
+const TagName = "access"
+
+// ClusterAdmin in an access tag requires a cluster admin.
+const ClusterAdmin = "cluster-admin"
+
+// Requirement is the permission required to read a field.
+type Requirement struct {
+	// ClusterAdmin requires a cluster admin; Verb, Group and Resource are empty then.
+	ClusterAdmin bool
+	// Verb on Resource, in Group ("" for the core group), within the namespace of the
+	// request.
+	Verb     string
+	Group    string
+	Resource string
+}
+
+func (r Requirement) String() string {
+	if r.ClusterAdmin {
+		return ClusterAdmin
+	}
+	if r.Group == "" {
+		return r.Verb + " " + r.Resource
+	}
+	return r.Verb + " " + r.Resource + "." + r.Group
+}
+
+// ParseTag parses an access tag: "cluster-admin", or "verb resource[.group]", e.g.
+// "list pods" or "get rolebindings.rbac.authorization.k8s.io".
+func ParseTag(tag string) (Requirement, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == ClusterAdmin {
+		return Requirement{ClusterAdmin: true}, nil
+	}
+	fields := strings.Fields(tag)
+	if len(fields) != 2 {
+		return Requirement{}, fmt.Errorf("invalid access tag %q (must be %s or \"verb resource[.group]\")", tag, ClusterAdmin)
+	}
+	resource, group, _ := strings.Cut(fields[1], ".")
+	return Requirement{Verb: fields[0], Group: group, Resource: resource}, nil
+}
+
+// CheckFunc reports whether the requesting user has a permission. Failed checks should be
+// reported as denied: Filter keeps no field it can't check.
+type CheckFunc func(ctx context.Context, requirement Requirement) bool
+
+// Filter returns a copy of v without the fields the user may not read, checked with check.
+// v is never modified, and returned as is when its type has no access tags.
+func Filter(ctx context.Context, v any, check CheckFunc) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	value := reflect.ValueOf(v)
+	restricted, err := hasAccessTags(value.Type())
+	if err != nil || !restricted {
+		return v, err
+	}
+	f := &filter{ctx: ctx, check: check, decisions: map[Requirement]bool{}}
+	return f.copy(value).Interface(), nil
+}
+
+type filter struct {
+	ctx       context.Context
+	check     CheckFunc
+	decisions map[Requirement]bool
+}
+
+func (f *filter) allowed(requirement Requirement) bool {
+	allowed, ok := f.decisions[requirement]
+	if !ok {
+		allowed = f.check(f.ctx, requirement)
+		f.decisions[requirement] = allowed
+	}
+	return allowed
+}
+
+// copy returns a copy of v without the denied fields, sharing the values of the types without
+// access tags with v.
+func (f *filter) copy(v reflect.Value) reflect.Value {
+	if restricted, _ := hasAccessTags(v.Type()); !restricted {
+		return v
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type().Elem())
+		copied.Elem().Set(f.copy(v.Elem()))
+		return copied
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(f.copy(v.Elem()))
+		return copied
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := range v.Len() {
+			copied.Index(i).Set(f.copy(v.Index(i)))
+		}
+		return copied
+	case reflect.Array:
+		copied := reflect.New(v.Type()).Elem()
+		for i := range v.Len() {
+			copied.Index(i).Set(f.copy(v.Index(i)))
+		}
+		return copied
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			copied.SetMapIndex(iter.Key(), f.copy(iter.Value()))
+		}
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		for _, field := range structFields(v.Type()) {
+			target := copied.Field(field.index)
+			if field.requirement != nil && !f.allowed(*field.requirement) {
+				target.SetZero()
+				continue
+			}
+			target.Set(f.copy(v.Field(field.index)))
+		}
+		return copied
+	}
+	return v
+}
+
+// accessField is an exported field of a struct that holds, or is, a restricted field.
+type accessField struct {
+	index       int
+	requirement *Requirement
+}
+
+type typeInfo struct {
+	restricted bool
+	fields     []accessField
+	err        error
+}
+
+// types caches the typeInfo of the types seen, by reflect.Type.
+var types sync.Map
+
+func structFields(t reflect.Type) []accessField {
+	info, _ := types.Load(t)
+	return info.(*typeInfo).fields
+}
+
+// hasAccessTags reports whether values of t may hold fields with access tags.
+func hasAccessTags(t reflect.Type) (bool, error) {
+	info := inspect(t, map[reflect.Type]bool{})
+	return info.restricted, info.err
+}
+
+// inspect returns the typeInfo of t. visiting holds the types being inspected: a recursive
+// type is assumed restricted where it refers to itself, so its values are walked in full.
+func inspect(t reflect.Type, visiting map[reflect.Type]bool) *typeInfo {
+	if cached, ok := types.Load(t); ok {
+		return cached.(*typeInfo)
+	}
+	if visiting[t] {
+		return &typeInfo{restricted: true}
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	info := &typeInfo{}
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		elem := inspect(t.Elem(), visiting)
+		info.restricted, info.err = elem.restricted, elem.err
+	case reflect.Interface:
+		// The dynamic type is only known at runtime; Envelope data are concrete types
+		info.restricted = true
+	case reflect.Struct:
+		for i := range t.NumField() {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			var requirement *Requirement
+			if tag, ok := field.Tag.Lookup(TagName); ok {
+				parsed, err := ParseTag(tag)
+				if err != nil {
+					info.err = fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+					break
+				}
+				requirement = &parsed
+			}
+			nested := inspect(field.Type, visiting)
+			if nested.err != nil {
+				info.err = nested.err
+				break
+			}
+			if requirement != nil || nested.restricted {
+				info.fields = append(info.fields, accessField{index: i, requirement: requirement})
+			}
+		}
+		info.restricted = info.err == nil && len(info.fields) > 0
+	}
+	if info.err != nil {
+		info.restricted = false
+	}
+	types.Store(t, info)
+	return info
+}
@@ -0,0 +1,97 @@
+package fieldaccess
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type node struct {
+	Name     string            `json:"name"`
+	Token    string            `json:"token,omitempty" access:"get secrets"`
+	Owner    *string           `json:"owner,omitempty" access:"cluster-admin"`
+	Children []node            `json:"children,omitempty"`
+	ByName   map[string]*node  `json:"byName,omitempty"`
+	Extra    any               `json:"extra,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+type plain struct {
+	Name   string
+	Values []string
+}
+
+func TestParseTag(t *testing.T) {
+	for tag, want := range map[string]Requirement{
+		"cluster-admin": {ClusterAdmin: true},
+		"list pods":     {Verb: "list", Resource: "pods"},
+		"get rolebindings.rbac.authorization.k8s.io": {Verb: "get", Resource: "rolebindings", Group: "rbac.authorization.k8s.io"},
+	} {
+		requirement, err := ParseTag(tag)
+		require.NoError(t, err, tag)
+		assert.Equal(t, want, requirement)
+		assert.Equal(t, tag, requirement.String())
+	}
+	for _, tag := range []string{"", "admin", "get secrets now"} {
+		_, err := ParseTag(tag)
+		assert.Error(t, err, tag)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	owner := "alice"
+	value := []node{{
+		Name:     "root",
+		Token:    "t0",
+		Owner:    &owner,
+		Children: []node{{Name: "child", Token: "t1"}},
+		ByName:   map[string]*node{"child": {Name: "child", Token: "t2"}},
+		Extra:    node{Name: "extra", Token: "t3"},
+		Labels:   map[string]string{"team": "a"},
+	}}
+	var checks []Requirement
+	check := func(_ context.Context, requirement Requirement) bool {
+		checks = append(checks, requirement)
+		return requirement.ClusterAdmin
+	}
+
+	filtered, err := Filter(context.Background(), value, check)
+	require.NoError(t, err)
+	assert.Equal(t, []node{{
+		Name:     "root",
+		Owner:    &owner,
+		Children: []node{{Name: "child"}},
+		ByName:   map[string]*node{"child": {Name: "child"}},
+		Extra:    node{Name: "extra"},
+		Labels:   map[string]string{"team": "a"},
+	}}, filtered)
+	assert.ElementsMatch(t, []Requirement{{Verb: "get", Resource: "secrets"}, {ClusterAdmin: true}}, checks, "each permission is checked once")
+
+	assert.Equal(t, "t0", value[0].Token, "the value is not modified")
+	assert.Equal(t, "t1", value[0].Children[0].Token)
+	assert.Equal(t, "t2", value[0].ByName["child"].Token)
+}
+
+func TestFilter_Unrestricted(t *testing.T) {
+	value := plain{Name: "a", Values: []string{"b"}}
+	filtered, err := Filter(context.Background(), value, func(context.Context, Requirement) bool {
+		t.Fatal("no permission to check")
+		return false
+	})
+	require.NoError(t, err)
+	assert.Equal(t, value, filtered)
+
+	filtered, err = Filter(context.Background(), nil, nil)
+	require.NoError(t, err)
+	assert.Nil(t, filtered)
+}
+
+func TestFilter_InvalidTag(t *testing.T) {
+	type invalid struct {
+		Secret string `access:"secrets"`
+	}
+	_, err := Filter(context.Background(), invalid{}, nil)
+	assert.ErrorContains(t, err, "invalid.Secret")
+}
@@ -0,0 +1,176 @@
+// Package frontend serves the built single-page frontend: its static assets, from a directory
+// or an embed.FS, and its index.html for every client-side route (history API fallback).
+package frontend
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cache-Control values of the responses.
+const (
+	// CacheImmutable lets browsers keep assets with a content hash in their name for a year.
+	CacheImmutable = "public, max-age=31536000, immutable"
+	// CacheRevalidate makes browsers check other files, and the index, on every use.
+	CacheRevalidate = "no-cache"
+)
+
+// DefaultIndex is the page of the client-side routes.
+const DefaultIndex = "index.html"
+
+// Options configures a Handler.
+type Options struct {
+	// Files holds the built assets: os.DirFS(dir), or an embed.FS narrowed with fs.Sub.
+	Files fs.FS
+	// Index is the file served for "/" and client-side routes (default DefaultIndex).
+	Index string
+	// TransformIndex, when set, rewrites the index for each response, e.g. to set a nonce.
+	TransformIndex func(r *http.Request, index []byte) []byte
+}
+
+// Handler serves the frontend of Options.
+type Handler struct {
+	files          fs.FS
+	index          string
+	transformIndex func(r *http.Request, index []byte) []byte
+}
+
+// NewHandler returns a handler serving the files of opts. Files are served with the gzip or
+// brotli variant built next to them ("app.js.br", "app.js.gz") to clients accepting it.
+// Requests for missing files get the index when they come from a browser navigation (they
+// accept text/html) or have no file extension, and a 404 otherwise, so a missing script
+// isn't answered with HTML. Directories are never listed.
+func NewHandler(opts Options) *Handler {
+	index := opts.Index
+	if index == "" {
+		index = DefaultIndex
+	}
+	return &Handler{files: opts.Files, index: index, transformIndex: opts.TransformIndex}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if name == "" || name == h.index {
+		h.serveIndex(w, r)
+		return
+	}
+	if info, err := fs.Stat(h.files, name); err == nil && !info.IsDir() {
+		h.serveFile(w, r, name, info)
+		return
+	}
+	if path.Ext(name) == "" || strings.Contains(r.Header.Get("Accept"), "text/html") {
+		h.serveIndex(w, r)
+		return
+	}
+	http.NotFound(w, r)
+}
+
+func (h *Handler) serveIndex(w http.ResponseWriter, r *http.Request) {
+	index, err := fs.ReadFile(h.files, h.index)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if h.transformIndex != nil {
+		index = h.transformIndex(r, index)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", CacheRevalidate)
+	http.ServeContent(w, r, h.index, time.Time{}, bytes.NewReader(index))
+}
+
+func (h *Handler) serveFile(w http.ResponseWriter, r *http.Request, name string, info fs.FileInfo) {
+	header := w.Header()
+	if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+		// Set before ServeContent, which would sniff the compressed bytes
+		header.Set("Content-Type", contentType)
+	}
+	if hashedName.MatchString(path.Base(name)) {
+		header.Set("Cache-Control", CacheImmutable)
+	} else {
+		header.Set("Cache-Control", CacheRevalidate)
+	}
+
+	served, encoding, variants := name, "", false
+	for _, variant := range encodings {
+		if _, err := fs.Stat(h.files, name+variant.suffix); err != nil {
+			continue
+		}
+		variants = true
+		if encoding == "" && acceptsEncoding(r.Header.Get("Accept-Encoding"), variant.encoding) {
+			served, encoding = name+variant.suffix, variant.encoding
+		}
+	}
+	if variants {
+		header.Add("Vary", "Accept-Encoding")
+	}
+	if encoding != "" {
+		header.Set("Content-Encoding", encoding)
+	}
+
+	file, err := h.files.Open(served)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	content, ok := file.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		content = bytes.NewReader(data)
+	}
+	http.ServeContent(w, r, name, info.ModTime(), content)
+}
+
+// hashedName matches file names with a content hash, e.g. "main.3f2a9c1b.js" or
+// "vendors-3f2a9c1b5e.bundle.js", as set by the bundler's [contenthash].
+var hashedName = regexp.MustCompile(`[.-][0-9a-f]{8,}\.`)
+
+// encodings are the pre-compressed variants, in order of preference.
+var encodings = []struct{ encoding, suffix string }{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// acceptsEncoding reports whether the Accept-Encoding header value accepts encoding, with a
+// non-zero quality.
+func acceptsEncoding(acceptEncoding, encoding string) bool {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		if name = strings.TrimSpace(name); !strings.EqualFold(name, encoding) && name != "*" {
+			continue
+		}
+		q, found := strings.CutPrefix(strings.TrimSpace(params), "q=")
+		if !found {
+			return true
+		}
+		quality, err := strconv.ParseFloat(q, 64)
+		return err == nil && quality > 0
+	}
+	return false
+}
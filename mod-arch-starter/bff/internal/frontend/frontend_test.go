@@ -0,0 +1,98 @@
+package frontend
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var files = fstest.MapFS{
+	"index.html":               {Data: []byte("<html><script src=\"main.bundle.js\"></script></html>")},
+	"main.bundle.js":           {Data: []byte("console.log('main')")},
+	"main.bundle.js.br":        {Data: []byte("brotli")},
+	"main.bundle.js.gz":        {Data: []byte("gzip")},
+	"vendor.3f2a9c1b5e.js":     {Data: []byte("console.log('vendor')")},
+	"images/logo.svg":          {Data: []byte("<svg/>")},
+	"images/nested/index.html": {Data: []byte("not a listing")},
+}
+
+func serve(t *testing.T, handler http.Handler, method, path string, headers map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestHandler_Assets(t *testing.T) {
+	handler := NewHandler(Options{Files: files})
+
+	rr := serve(t, handler, http.MethodGet, "/main.bundle.js", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "console.log('main')", rr.Body.String())
+	assert.Equal(t, "text/javascript; charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.Equal(t, CacheRevalidate, rr.Header().Get("Cache-Control"))
+	assert.Equal(t, "Accept-Encoding", rr.Header().Get("Vary"))
+	assert.Empty(t, rr.Header().Get("Content-Encoding"))
+
+	rr = serve(t, handler, http.MethodGet, "/main.bundle.js", map[string]string{"Accept-Encoding": "gzip, deflate, br"})
+	assert.Equal(t, "brotli", rr.Body.String())
+	assert.Equal(t, "br", rr.Header().Get("Content-Encoding"))
+	assert.Equal(t, "text/javascript; charset=utf-8", rr.Header().Get("Content-Type"), "the type of the original file")
+
+	rr = serve(t, handler, http.MethodGet, "/main.bundle.js", map[string]string{"Accept-Encoding": "gzip, br;q=0"})
+	assert.Equal(t, "gzip", rr.Body.String())
+	assert.Equal(t, "gzip", rr.Header().Get("Content-Encoding"))
+
+	rr = serve(t, handler, http.MethodGet, "/vendor.3f2a9c1b5e.js", map[string]string{"Accept-Encoding": "br"})
+	assert.Equal(t, CacheImmutable, rr.Header().Get("Cache-Control"), "hashed names are cached for good")
+	assert.Empty(t, rr.Header().Get("Content-Encoding"), "no variant")
+	assert.Empty(t, rr.Header().Get("Vary"))
+
+	rr = serve(t, handler, http.MethodGet, "/images/../images/logo.svg", nil)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "image/svg+xml", rr.Header().Get("Content-Type"))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(t, handler, http.MethodPost, "/main.bundle.js", nil).Code)
+}
+
+func TestHandler_HistoryFallback(t *testing.T) {
+	handler := NewHandler(Options{
+		Files: files,
+		TransformIndex: func(r *http.Request, index []byte) []byte {
+			return bytes.ReplaceAll(index, []byte("<script"), []byte(`<script nonce="n"`))
+		},
+	})
+
+	for _, path := range []string{"/", "/index.html", "/models/registry", "/images/nested/"} {
+		rr := serve(t, handler, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, rr.Code, path)
+		assert.Contains(t, rr.Body.String(), `<script nonce="n" src="main.bundle.js">`, path)
+		assert.Equal(t, "text/html; charset=utf-8", rr.Header().Get("Content-Type"), path)
+		assert.Equal(t, CacheRevalidate, rr.Header().Get("Cache-Control"), path)
+	}
+
+	assert.Equal(t, http.StatusNotFound, serve(t, handler, http.MethodGet, "/missing.js", nil).Code, "missing assets are not HTML")
+	assert.Equal(t, http.StatusOK, serve(t, handler, http.MethodGet, "/models/v1.2", map[string]string{"Accept": "text/html,*/*"}).Code,
+		"browser navigations get the index")
+
+	empty := NewHandler(Options{Files: fstest.MapFS{}})
+	assert.Equal(t, http.StatusNotFound, serve(t, empty, http.MethodGet, "/", nil).Code)
+}
+
+func TestAcceptsEncoding(t *testing.T) {
+	assert.True(t, acceptsEncoding("gzip, br", "br"))
+	assert.True(t, acceptsEncoding("BR;q=0.5", "br"))
+	assert.True(t, acceptsEncoding("*", "gzip"))
+	assert.False(t, acceptsEncoding("gzip;q=0", "gzip"))
+	assert.False(t, acceptsEncoding("gzip", "br"))
+	assert.False(t, acceptsEncoding("", "gzip"))
+}
@@ -0,0 +1,42 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Ping is the built-in liveness check: it succeeds as long as the server can answer.
+func Ping() Check {
+	return Check{
+		Name:  "ping",
+		Scope: Liveness | Readiness,
+		Func:  func(context.Context) error { return nil },
+	}
+}
+
+// HTTPGet returns a check function that GETs url and expects a status below 400, e.g. for
+// the health endpoint of an upstream service. A nil client uses http.DefaultClient.
+func HTTPGet(client *http.Client, url string) func(ctx context.Context) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+		if resp.StatusCode >= http.StatusBadRequest {
+			return fmt.Errorf("GET %s returned %d", url, resp.StatusCode)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,191 @@
+// Package healthcheck runs registerable health checks and serves them on the Kubernetes-style
+// /livez, /readyz and /healthz endpoints, with the status and latency of every check in the
+// JSON body.
+//
+// /livez runs the liveness checks only: a failure there gets the pod restarted, so they must
+// not depend on anything outside the process. /readyz runs the readiness checks (Kubernetes API,
+// upstream services, informer sync) and /healthz runs every check.
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds each check that doesn't set its own timeout.
+const DefaultTimeout = 5 * time.Second
+
+// Scope selects the endpoints a check runs on. /healthz always runs every check.
+type Scope int
+
+const (
+	// Readiness checks gate traffic to the pod (/readyz).
+	Readiness Scope = 1 << iota
+	// Liveness checks restart the pod when they fail (/livez).
+	Liveness
+)
+
+// Report statuses.
+const (
+	StatusOK = "ok"
+	// StatusDegraded means only optional checks failed; the endpoint still answers 200.
+	StatusDegraded = "degraded"
+	StatusFailed   = "failed"
+)
+
+// Check is a named health check. Func returns nil when the dependency is healthy.
+type Check struct {
+	Name string
+	Func func(ctx context.Context) error
+
+	// Scope defaults to Readiness.
+	Scope Scope
+
+	// Optional checks are reported but never fail the endpoint, e.g. for non-essential upstreams.
+	Optional bool
+
+	// Timeout defaults to DefaultTimeout.
+	Timeout time.Duration
+}
+
+// CheckResult is the outcome of one check.
+type CheckResult struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	Error     string  `json:"error,omitempty"`
+	Optional  bool    `json:"optional,omitempty"`
+	LatencyMs float64 `json:"latencyMs"`
+}
+
+// Report is the JSON body of the health endpoints.
+type Report struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// Registry holds the registered checks. It is safe for concurrent use.
+type Registry struct {
+	mu     sync.RWMutex
+	checks []Check
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a check. Names must be unique and non-empty.
+func (r *Registry) Register(check Check) error {
+	if check.Name == "" || check.Func == nil {
+		return errors.New("health check requires a name and a function")
+	}
+	if check.Scope == 0 {
+		check.Scope = Readiness
+	}
+	if check.Timeout <= 0 {
+		check.Timeout = DefaultTimeout
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.checks {
+		if existing.Name == check.Name {
+			return fmt.Errorf("health check %q is already registered", check.Name)
+		}
+	}
+	r.checks = append(r.checks, check)
+	return nil
+}
+
+// Run executes the checks in scope (every check when scope is 0) concurrently, skipping the
+// excluded names. Results keep the registration order.
+func (r *Registry) Run(ctx context.Context, scope Scope, exclude ...string) Report {
+	r.mu.RLock()
+	var checks []Check
+	for _, check := range r.checks {
+		if (scope == 0 || check.Scope&scope != 0) && !contains(exclude, check.Name) {
+			checks = append(checks, check)
+		}
+	}
+	r.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: results}
+	for _, result := range results {
+		switch {
+		case result.Status == StatusOK:
+		case result.Optional:
+			if report.Status == StatusOK {
+				report.Status = StatusDegraded
+			}
+		default:
+			report.Status = StatusFailed
+		}
+	}
+	return report
+}
+
+func run(ctx context.Context, check Check) (result CheckResult) {
+	ctx, cancel := context.WithTimeout(ctx, check.Timeout)
+	defer cancel()
+
+	result = CheckResult{Name: check.Name, Status: StatusOK, Optional: check.Optional}
+	started := time.Now()
+	defer func() {
+		if p := recover(); p != nil {
+			result.Status = StatusFailed
+			result.Error = fmt.Sprintf("panic: %v", p)
+		}
+		result.LatencyMs = float64(time.Since(started).Microseconds()) / 1000
+	}()
+
+	if err := check.Func(ctx); err != nil {
+		result.Status = StatusFailed
+		result.Error = err.Error()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			result.Error = fmt.Sprintf("timed out after %s: %v", check.Timeout, err)
+		}
+	}
+	return result
+}
+
+// Handler serves the report of the checks in scope (0 for every check). It answers 503 when
+// a required check fails. Checks can be skipped with ?exclude=<name> (repeatable).
+func (r *Registry) Handler(scope Scope) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		report := r.Run(req.Context(), scope, req.URL.Query()["exclude"]...)
+
+		status := http.StatusOK
+		if report.Status == StatusFailed {
+			status = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func failing(context.Context) error { return errors.New("boom") }
+
+func names(report Report) []string {
+	out := []string{}
+	for _, result := range report.Checks {
+		out = append(out, result.Name)
+	}
+	return out
+}
+
+func TestRegistry_Register(t *testing.T) {
+	r := NewRegistry()
+	require.NoError(t, r.Register(Ping()))
+	assert.Error(t, r.Register(Ping()), "duplicate name")
+	assert.Error(t, r.Register(Check{Name: "no-func"}))
+	assert.Error(t, r.Register(Check{Func: failing}))
+}
+
+func TestRegistry_RunScopes(t *testing.T) {
+	r := NewRegistry()
+	require.NoError(t, r.Register(Ping()))
+	require.NoError(t, r.Register(Check{Name: "kubernetes", Func: failing}))
+	require.NoError(t, r.Register(Check{Name: "upstream", Func: failing, Optional: true}))
+
+	live := r.Run(context.Background(), Liveness)
+	assert.Equal(t, StatusOK, live.Status)
+	assert.Equal(t, []string{"ping"}, names(live))
+
+	ready := r.Run(context.Background(), Readiness)
+	assert.Equal(t, StatusFailed, ready.Status)
+	assert.Equal(t, []string{"ping", "kubernetes", "upstream"}, names(ready))
+	assert.Equal(t, "boom", ready.Checks[1].Error)
+	assert.True(t, ready.Checks[2].Optional)
+
+	degraded := r.Run(context.Background(), 0, "kubernetes")
+	assert.Equal(t, StatusDegraded, degraded.Status)
+	assert.Equal(t, []string{"ping", "upstream"}, names(degraded))
+}
+
+func TestRegistry_RunTimeoutAndPanic(t *testing.T) {
+	r := NewRegistry()
+	require.NoError(t, r.Register(Check{Name: "slow", Timeout: 20 * time.Millisecond, Func: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}))
+	require.NoError(t, r.Register(Check{Name: "panics", Func: func(context.Context) error { panic("oops") }}))
+
+	report := r.Run(context.Background(), 0)
+	require.Len(t, report.Checks, 2)
+	assert.Contains(t, report.Checks[0].Error, "timed out after 20ms")
+	assert.GreaterOrEqual(t, report.Checks[0].LatencyMs, 20.0)
+	assert.Equal(t, "panic: oops", report.Checks[1].Error)
+	assert.Equal(t, StatusFailed, report.Status)
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	r := NewRegistry()
+	require.NoError(t, r.Register(Ping()))
+	require.NoError(t, r.Register(Check{Name: "kubernetes", Func: failing}))
+
+	rr := httptest.NewRecorder()
+	r.Handler(Readiness).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	var report Report
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+	assert.Equal(t, StatusFailed, report.Status)
+
+	rr = httptest.NewRecorder()
+	r.Handler(Readiness).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/readyz?exclude=kubernetes", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	r.Handler(Liveness).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestHTTPGet(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer upstream.Close()
+
+	assert.NoError(t, HTTPGet(nil, upstream.URL+"/up")(context.Background()))
+	assert.ErrorContains(t, HTTPGet(upstream.Client(), upstream.URL+"/down")(context.Background()), "returned 503")
+}
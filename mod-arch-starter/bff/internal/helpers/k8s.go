@@ -17,6 +17,15 @@ func GetKubeconfig() (*clientRest.Config, error) {
 	return kubeConfig.ClientConfig()
 }
 
+// GetKubeconfigForContext returns the configuration of the named context of the KUBECONFIG,
+// based on the default loading rules.
+func GetKubeconfigForContext(context string) (*clientRest.Config, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	configOverrides := &clientcmd.ConfigOverrides{CurrentContext: context}
+	kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
+	return kubeConfig.ClientConfig()
+}
+
 // BuildScheme builds a new runtime scheme with all the necessary types registered.
 func BuildScheme() (*runtime.Scheme, error) {
 	scheme := runtime.NewScheme()
@@ -2,31 +2,13 @@ package helper
 
 import (
 	"bytes"
-	"context"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"slices"
-
-	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
 )
 
-func GetContextLoggerFromReq(r *http.Request) *slog.Logger {
-	return GetContextLogger(r.Context())
-}
-
-func GetContextLogger(ctx context.Context) *slog.Logger {
-	logger, ok := ctx.Value(constants.TraceLoggerKey).(*slog.Logger)
-
-	if !ok {
-		logger = slog.New(slog.Default().Handler())
-		logger.Warn("Unable to get context logger for tracing, falling back to default")
-	}
-
-	return logger
-}
-
 var sensitiveHeaders = []string{
 	"Authorization",
 	"Cookie",
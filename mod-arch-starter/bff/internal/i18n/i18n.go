@@ -0,0 +1,125 @@
+// Package i18n is the catalog of the translations of the client-facing messages of the BFF,
+// keyed by stable message keys (e.g. "NotFound", see apierrors), and negotiates their locale
+// from the Accept-Language header of the requests:
+//
+//	catalog, _ := i18n.Load("")
+//	message, locale := catalog.Localize(r.Header.Get("Accept-Language"), "NotFound", apierrors.MessageNotFound)
+//
+// The messages of the code are English, the source locale; the built-in translations, and
+// those of a directory of <locale>.json files, e.g. mounted from a ConfigMap, add the other
+// locales. Each file maps message keys to messages.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// SourceLocale is the locale of the messages of the code.
+var SourceLocale = language.English
+
+//go:embed messages/*.json
+var builtin embed.FS
+
+// Catalog holds the messages of each locale. It is read-only once loaded, so safe for
+// concurrent use.
+type Catalog struct {
+	// locales lists SourceLocale first, then the locales of messages
+	locales  []language.Tag
+	messages map[language.Tag]map[string]string
+	matcher  language.Matcher
+}
+
+// Load reads the built-in translations, then the *.json files of dir, when set, which add
+// locales or override messages of the built-in ones.
+func Load(dir string) (*Catalog, error) {
+	catalog := &Catalog{messages: map[language.Tag]map[string]string{}}
+	files, _ := fs.Sub(builtin, "messages")
+	if err := catalog.read(files); err != nil {
+		return nil, err
+	}
+	if dir != "" {
+		if err := catalog.read(os.DirFS(dir)); err != nil {
+			return nil, err
+		}
+	}
+
+	catalog.locales = []language.Tag{SourceLocale}
+	for locale := range catalog.messages {
+		if locale != SourceLocale {
+			catalog.locales = append(catalog.locales, locale)
+		}
+	}
+	sort.Slice(catalog.locales[1:], func(i, j int) bool {
+		return catalog.locales[i+1].String() < catalog.locales[j+1].String()
+	})
+	catalog.matcher = language.NewMatcher(catalog.locales)
+	return catalog, nil
+}
+
+func (c *Catalog) read(files fs.FS) error {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return fmt.Errorf("failed to read the message catalog: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		locale, err := language.Parse(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			return fmt.Errorf("message catalog %s: the file name is not a locale: %w", entry.Name(), err)
+		}
+		data, err := fs.ReadFile(files, entry.Name())
+		if err != nil {
+			return fmt.Errorf("failed to read the message catalog %s: %w", entry.Name(), err)
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return fmt.Errorf("message catalog %s: %w", entry.Name(), err)
+		}
+		if c.messages[locale] == nil {
+			c.messages[locale] = map[string]string{}
+		}
+		for key, message := range messages {
+			c.messages[locale][key] = message
+		}
+	}
+	return nil
+}
+
+// Locales returns the locales of the catalog, SourceLocale first.
+func (c *Catalog) Locales() []language.Tag {
+	return append([]language.Tag(nil), c.locales...)
+}
+
+// Localize returns the message of key in the locale of the catalog best matching
+// acceptLanguage, an Accept-Language header, and that locale. It returns fallback, the
+// message of the code, and SourceLocale when no locale matches, when the locale has no
+// message of key, or when key is empty. A nil catalog always returns fallback.
+func (c *Catalog) Localize(acceptLanguage, key, fallback string) (string, language.Tag) {
+	if c == nil || key == "" || acceptLanguage == "" {
+		return fallback, SourceLocale
+	}
+	preferred, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(preferred) == 0 {
+		return fallback, SourceLocale
+	}
+	_, index, confidence := c.matcher.Match(preferred...)
+	if confidence == language.No {
+		return fallback, SourceLocale
+	}
+	locale := c.locales[index]
+	if message, ok := c.messages[locale][key]; ok && message != "" {
+		return message, locale
+	}
+	return fallback, SourceLocale
+}
@@ -0,0 +1,62 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+func TestCatalog_Localize(t *testing.T) {
+	catalog, err := Load("")
+	require.NoError(t, err)
+
+	for acceptLanguage, want := range map[string]struct {
+		message string
+		locale  language.Tag
+	}{
+		"ja":                         {"要求されたリソースが見つかりません", language.Japanese},
+		"zh-CN,zh;q=0.9":             {"找不到请求的资源", language.Chinese},
+		"de;q=0.9,fr-CA;q=0.8":       {"la ressource demandée est introuvable", language.French},
+		"en-US,es;q=0.5":             {"not found", language.English},
+		"de":                         {"not found", language.English},
+		"":                           {"not found", language.English},
+		"not a ;;; language header=": {"not found", language.English},
+	} {
+		message, locale := catalog.Localize(acceptLanguage, "NotFound", "not found")
+		assert.Equal(t, want.message, message, acceptLanguage)
+		assert.Equal(t, want.locale, locale, acceptLanguage)
+	}
+
+	message, locale := catalog.Localize("es", "Unknown", "fallback")
+	assert.Equal(t, "fallback", message, "keys missing from the locale")
+	assert.Equal(t, language.English, locale)
+
+	var disabled *Catalog
+	message, _ = disabled.Localize("es", "NotFound", "not found")
+	assert.Equal(t, "not found", message)
+}
+
+func TestLoad_Dir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "de.json"), []byte(`{"NotFound": "die Ressource wurde nicht gefunden"}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "es.json"), []byte(`{"NotFound": "no encontrado"}`), 0o600))
+	catalog, err := Load(dir)
+	require.NoError(t, err)
+
+	assert.Contains(t, catalog.Locales(), language.German)
+	assert.Equal(t, language.English, catalog.Locales()[0])
+	message, _ := catalog.Localize("de", "NotFound", "not found")
+	assert.Equal(t, "die Ressource wurde nicht gefunden", message)
+	message, _ = catalog.Localize("es", "NotFound", "not found")
+	assert.Equal(t, "no encontrado", message, "overrides the built-in message")
+	message, _ = catalog.Localize("es", "Forbidden", "forbidden")
+	assert.Equal(t, "Acceso prohibido", message, "keeps the other built-in messages")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "german.json"), []byte(`{}`), 0o600))
+	_, err = Load(dir)
+	assert.ErrorContains(t, err, "german.json")
+}
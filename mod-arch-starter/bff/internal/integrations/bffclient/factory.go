@@ -1,8 +1,9 @@
 package bffclient
 
 import (
-	"crypto/x509"
+	"crypto/tls"
 	"log/slog"
+	"net/http"
 )
 
 // BFFClientFactory interface for creating BFF clients
@@ -22,19 +23,19 @@ type BFFClientFactory interface {
 
 // RealClientFactory creates real BFF clients with HTTP communication
 type RealClientFactory struct {
-	config             *BFFClientConfig
-	rootCAs            *x509.CertPool
-	insecureSkipVerify bool
-	logger             *slog.Logger
+	config    *BFFClientConfig
+	tlsConfig func(target BFFTarget) *tls.Config
+	logger    *slog.Logger
 }
 
-// NewRealClientFactory creates a factory for real BFF clients
-func NewRealClientFactory(config *BFFClientConfig, rootCAs *x509.CertPool, insecureSkipVerify bool, logger *slog.Logger) BFFClientFactory {
+// NewRealClientFactory creates a factory for real BFF clients. tlsConfig returns the TLS
+// configuration of the clients of a target; when nil, they trust the system pool and skip
+// verification as config.InsecureSkipVerify says.
+func NewRealClientFactory(config *BFFClientConfig, tlsConfig func(target BFFTarget) *tls.Config, logger *slog.Logger) BFFClientFactory {
 	return &RealClientFactory{
-		config:             config,
-		rootCAs:            rootCAs,
-		insecureSkipVerify: insecureSkipVerify,
-		logger:             logger,
+		config:    config,
+		tlsConfig: tlsConfig,
+		logger:    logger,
 	}
 }
 
@@ -61,16 +62,20 @@ func (f *RealClientFactory) CreateClientWithHeaders(target BFFTarget, authToken
 		"hasHeaders", len(headers) > 0)
 
 	// Pass auth configuration from service config to the client
-	return NewHTTPBFFClientWithConfig(
+	client := NewHTTPBFFClientWithConfig(
 		baseURL,
 		target,
 		authToken,
 		headers,
 		serviceConfig.AuthTokenHeader,
 		serviceConfig.AuthTokenPrefix,
-		f.insecureSkipVerify,
-		f.rootCAs,
+		f.config.InsecureSkipVerify,
+		nil,
 	)
+	if f.tlsConfig != nil {
+		client.httpClient.Transport = &http.Transport{TLSClientConfig: f.tlsConfig(target)}
+	}
+	return client
 }
 
 // GetConfig returns the configuration for a specific target
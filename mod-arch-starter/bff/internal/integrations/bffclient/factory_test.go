@@ -14,7 +14,7 @@ func TestRealClientFactory_CreateClient(t *testing.T) {
 	config := NewDefaultBFFClientConfig()
 	config.PodNamespace = "test-ns"
 
-	factory := NewRealClientFactory(config, nil, false, logger)
+	factory := NewRealClientFactory(config, nil, logger)
 
 	t.Run("configured target", func(t *testing.T) {
 		client := factory.CreateClient(BFFTargetMaaS, "test-token")
@@ -37,7 +37,7 @@ func TestRealClientFactory_CreateClientWithHeaders(t *testing.T) {
 	config := NewDefaultBFFClientConfig()
 	config.PodNamespace = "test-ns"
 
-	factory := NewRealClientFactory(config, nil, false, logger)
+	factory := NewRealClientFactory(config, nil, logger)
 	headers := map[string]string{"kubeflow-userid": "user@test.com"}
 	client := factory.CreateClientWithHeaders(BFFTargetMaaS, "token", headers)
 
@@ -48,7 +48,7 @@ func TestRealClientFactory_CreateClientWithHeaders(t *testing.T) {
 func TestRealClientFactory_GetConfig(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
 	config := NewDefaultBFFClientConfig()
-	factory := NewRealClientFactory(config, nil, false, logger)
+	factory := NewRealClientFactory(config, nil, logger)
 
 	t.Run("existing target", func(t *testing.T) {
 		cfg := factory.GetConfig(BFFTargetMaaS)
@@ -65,7 +65,7 @@ func TestRealClientFactory_GetConfig(t *testing.T) {
 func TestRealClientFactory_IsTargetConfigured(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
 	config := NewDefaultBFFClientConfig()
-	factory := NewRealClientFactory(config, nil, false, logger)
+	factory := NewRealClientFactory(config, nil, logger)
 
 	assert.True(t, factory.IsTargetConfigured(BFFTargetMaaS))
 	assert.True(t, factory.IsTargetConfigured(BFFTargetGenAI))
@@ -77,7 +77,7 @@ func TestRealClientFactory_DevOverrideURL(t *testing.T) {
 	config := NewDefaultBFFClientConfig()
 	config.GetServiceConfig(BFFTargetMaaS).DevOverrideURL = "http://localhost:4000/api/v1"
 
-	factory := NewRealClientFactory(config, nil, false, logger)
+	factory := NewRealClientFactory(config, nil, logger)
 	client := factory.CreateClient(BFFTargetMaaS, "token")
 
 	require.NotNil(t, client)
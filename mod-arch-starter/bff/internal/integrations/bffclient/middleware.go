@@ -6,8 +6,8 @@ import (
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
-	helper "github.com/opendatahub-io/mod-arch-library/bff/internal/helpers"
 	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
 )
 
 // GetClient retrieves a BFF client from the context for the specified target
@@ -36,11 +36,11 @@ func AttachBFFClient(factory BFFClientFactory, target BFFTarget) func(next httpr
 	return func(next httprouter.Handle) httprouter.Handle {
 		return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 			ctx := r.Context()
-			logger := helper.GetContextLoggerFromReq(r)
+			reqLogger := logger.FromRequest(r)
 
 			// Check if target is configured
 			if !factory.IsTargetConfigured(target) {
-				logger.Debug("Target BFF not configured, attaching nil client", "target", target)
+				reqLogger.Debug("Target BFF not configured, attaching nil client", "target", target)
 				ctx = context.WithValue(ctx, constants.BFFClientKey(constants.BFFTarget(target)), nil)
 				next(w, r.WithContext(ctx), ps)
 				return
@@ -55,7 +55,7 @@ func AttachBFFClient(factory BFFClientFactory, target BFFTarget) func(next httpr
 			// Create BFF client for target
 			client := factory.CreateClient(target, authToken)
 			if client == nil {
-				logger.Warn("Failed to create BFF client", "target", target)
+				reqLogger.Warn("Failed to create BFF client", "target", target)
 				ctx = context.WithValue(ctx, constants.BFFClientKey(constants.BFFTarget(target)), nil)
 				next(w, r.WithContext(ctx), ps)
 				return
@@ -63,9 +63,9 @@ func AttachBFFClient(factory BFFClientFactory, target BFFTarget) func(next httpr
 
 			// Check availability (best effort - log but continue)
 			if !client.IsAvailable(ctx) {
-				logger.Warn("Target BFF unavailable (will continue anyway)", "target", target)
+				reqLogger.Warn("Target BFF unavailable (will continue anyway)", "target", target)
 			} else {
-				logger.Debug("Target BFF available", "target", target, "baseURL", client.GetBaseURL())
+				reqLogger.Debug("Target BFF available", "target", target, "baseURL", client.GetBaseURL())
 			}
 
 			// Attach to context
@@ -81,11 +81,11 @@ func AttachBFFClientFunc(factory BFFClientFactory, target BFFTarget) func(next h
 	return func(next http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
 			ctx := r.Context()
-			logger := helper.GetContextLoggerFromReq(r)
+			reqLogger := logger.FromRequest(r)
 
 			// Check if target is configured
 			if !factory.IsTargetConfigured(target) {
-				logger.Debug("Target BFF not configured, attaching nil client", "target", target)
+				reqLogger.Debug("Target BFF not configured, attaching nil client", "target", target)
 				ctx = context.WithValue(ctx, constants.BFFClientKey(constants.BFFTarget(target)), nil)
 				next(w, r.WithContext(ctx))
 				return
@@ -100,7 +100,7 @@ func AttachBFFClientFunc(factory BFFClientFactory, target BFFTarget) func(next h
 			// Create BFF client for target
 			client := factory.CreateClient(target, authToken)
 			if client == nil {
-				logger.Warn("Failed to create BFF client", "target", target)
+				reqLogger.Warn("Failed to create BFF client", "target", target)
 				ctx = context.WithValue(ctx, constants.BFFClientKey(constants.BFFTarget(target)), nil)
 				next(w, r.WithContext(ctx))
 				return
@@ -108,9 +108,9 @@ func AttachBFFClientFunc(factory BFFClientFactory, target BFFTarget) func(next h
 
 			// Check availability (best effort - log but continue)
 			if !client.IsAvailable(ctx) {
-				logger.Warn("Target BFF unavailable (will continue anyway)", "target", target)
+				reqLogger.Warn("Target BFF unavailable (will continue anyway)", "target", target)
 			} else {
-				logger.Debug("Target BFF available", "target", target, "baseURL", client.GetBaseURL())
+				reqLogger.Debug("Target BFF available", "target", target, "baseURL", client.GetBaseURL())
 			}
 
 			// Attach to context
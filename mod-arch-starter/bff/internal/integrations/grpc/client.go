@@ -0,0 +1,176 @@
+// Package grpcclient calls the gRPC APIs of upstream ODH services (KServe, ML Metadata, ...) on
+// behalf of the caller, the way mrserver.UpstreamClient calls their REST APIs: every call
+// carries the caller's bearer token or kubeflow metadata and the BFF request ID, is bounded by
+// the request deadline, is traced with OpenTelemetry, and goes through the circuit breaker of
+// the upstream.
+//
+// Unlike REST clients, gRPC connections are long-lived and multiplex the calls of every user:
+// a Pool keeps one connection per service, and the identity is read from the context of each
+// call (see api.InjectRequestIdentity), so repositories pass the request context to the
+// generated stubs:
+//
+//	conn, err := app.GRPCConnections().Conn(grpcclient.Config{Target: "mlmd.kubeflow:8080", Resilience: app.Resilience()})
+//	if err != nil {
+//	    return nil, err
+//	}
+//	resp, err := mlmd.NewMetadataStoreServiceClient(conn).GetArtifacts(r.Context(), req)
+package grpcclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/resilience"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// DefaultTimeout bounds the unary calls whose context has no earlier deadline.
+const DefaultTimeout = 30 * time.Second
+
+// Config describes how to reach a gRPC service.
+type Config struct {
+	// Target is the address of the service in gRPC naming syntax, e.g. "mlmd.kubeflow:8080" or
+	// "dns:///modelmesh-serving.kserve:8033".
+	Target string
+
+	// Timeout bounds the unary calls whose context has no earlier deadline (default
+	// DefaultTimeout). The deadline of the request context is always propagated to the
+	// service; streams are only bounded by their context.
+	Timeout time.Duration
+
+	// Plaintext connects without TLS, e.g. to a sidecar or through a service mesh.
+	// Otherwise the connection uses TLS with RootCAs (default the system pool).
+	Plaintext          bool
+	RootCAs            *x509.CertPool
+	InsecureSkipVerify bool
+	// TLSConfig, when set, replaces RootCAs and InsecureSkipVerify.
+	TLSConfig *tls.Config
+
+	// AuthTokenHeader and AuthTokenPrefix control how a bearer token is forwarded
+	// (default "authorization" and "Bearer "). gRPC metadata keys are lowercase.
+	AuthTokenHeader string
+	AuthTokenPrefix string
+
+	// Metadata is added to every call.
+	Metadata metadata.MD
+
+	// Resilience, when set, adds the circuit breaker of the target: while it is open calls
+	// fail with an error matching resilience.ErrCircuitOpen, without calling the service.
+	Resilience *resilience.Policy
+
+	// DialOptions are added to the options of the connection, e.g. grpc.WithDefaultServiceConfig
+	// to enable retries.
+	DialOptions []grpc.DialOption
+}
+
+func (c Config) withDefaults() Config {
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultTimeout
+	}
+	if c.AuthTokenHeader == "" {
+		c.AuthTokenHeader = "authorization"
+		if c.AuthTokenPrefix == "" {
+			c.AuthTokenPrefix = "Bearer "
+		}
+	}
+	return c
+}
+
+// NewConn creates a connection to cfg.Target with the identity, deadline, tracing and circuit
+// breaker interceptors. The connection is established lazily, on the first call, and must be
+// closed by the caller; prefer a Pool, which shares connections between requests.
+func NewConn(cfg Config, logger *slog.Logger) (*grpc.ClientConn, error) {
+	if cfg.Target == "" {
+		return nil, errors.New("gRPC target is required")
+	}
+	cfg = cfg.withDefaults()
+
+	creds := insecure.NewCredentials()
+	if !cfg.Plaintext {
+		tlsConfig := cfg.TLSConfig
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{
+				MinVersion:         tls.VersionTLS12,
+				RootCAs:            cfg.RootCAs,
+				InsecureSkipVerify: cfg.InsecureSkipVerify,
+			}
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	i := &interceptors{cfg: cfg, breaker: cfg.Resilience.Breaker(cfg.Target), logger: logger}
+	options := append([]grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithChainUnaryInterceptor(i.unary),
+		grpc.WithChainStreamInterceptor(i.stream),
+	}, cfg.DialOptions...)
+
+	conn, err := grpc.NewClient(cfg.Target, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC connection to %s: %w", cfg.Target, err)
+	}
+	return conn, nil
+}
+
+// Pool keeps one connection per target, shared by all the requests: gRPC connections multiplex
+// calls and reconnect on their own. Close it on shutdown.
+type Pool struct {
+	logger    *slog.Logger
+	tlsConfig func(target string) *tls.Config
+
+	mu     sync.Mutex
+	conns  map[string]*grpc.ClientConn
+	closed bool
+}
+
+// NewPool creates a pool whose TLS connections use the configuration tlsConfig returns for
+// their target unless their Config sets RootCAs or TLSConfig. A nil tlsConfig trusts the
+// system pool.
+func NewPool(logger *slog.Logger, tlsConfig func(target string) *tls.Config) *Pool {
+	return &Pool{logger: logger, tlsConfig: tlsConfig, conns: map[string]*grpc.ClientConn{}}
+}
+
+// Conn returns the connection to cfg.Target, creating it with cfg on first use; later calls
+// for the same target reuse it, whatever their Config.
+func (p *Pool) Conn(cfg Config) (*grpc.ClientConn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil, errors.New("gRPC connection pool is closed")
+	}
+	if conn, ok := p.conns[cfg.Target]; ok {
+		return conn, nil
+	}
+	if cfg.RootCAs == nil && cfg.TLSConfig == nil && p.tlsConfig != nil {
+		cfg.TLSConfig = p.tlsConfig(cfg.Target)
+	}
+	conn, err := NewConn(cfg, p.logger)
+	if err != nil {
+		return nil, err
+	}
+	p.conns[cfg.Target] = conn
+	return conn, nil
+}
+
+// Close closes the connections; calls in flight fail with codes.Canceled.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	var errs []error
+	for target, conn := range p.conns {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", target, err))
+		}
+	}
+	p.conns = nil
+	return errors.Join(errs...)
+}
@@ -0,0 +1,203 @@
+package grpcclient
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/resilience"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// healthService answers Check with check, recording the metadata and deadline of the last call.
+type healthService struct {
+	healthpb.UnimplementedHealthServer
+	check func(ctx context.Context) error
+
+	mu       sync.Mutex
+	md       metadata.MD
+	deadline time.Time
+}
+
+func (s *healthService) last() (metadata.MD, time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.md, s.deadline
+}
+
+func (s *healthService) Check(ctx context.Context, _ *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	s.mu.Lock()
+	s.md, _ = metadata.FromIncomingContext(ctx)
+	s.deadline, _ = ctx.Deadline()
+	s.mu.Unlock()
+	if s.check != nil {
+		if err := s.check(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+// newTestConn serves service in memory and connects to it with cfg.
+func newTestConn(t *testing.T, service *healthService, cfg Config) healthpb.HealthClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, service)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	cfg.Target = "passthrough:///bufnet"
+	cfg.Plaintext = true
+	cfg.DialOptions = append(cfg.DialOptions, grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	}))
+	conn, err := NewConn(cfg, testLogger())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+func TestConn_ForwardsIdentity(t *testing.T) {
+	tests := []struct {
+		name     string
+		identity *kubernetes.RequestIdentity
+		want     map[string]string
+	}{
+		{"token", &kubernetes.RequestIdentity{Token: "secret"}, map[string]string{"authorization": "Bearer secret"}},
+		{"kubeflow", &kubernetes.RequestIdentity{UserID: "user@example.com", Groups: []string{"a", "b"}}, map[string]string{
+			"kubeflow-userid": "user@example.com",
+			"kubeflow-groups": "a,b",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &healthService{}
+			client := newTestConn(t, service, Config{Metadata: metadata.Pairs("x-tenant", "team-a")})
+
+			ctx := context.WithValue(context.Background(), constants.RequestIdentityKey, tt.identity)
+			ctx = context.WithValue(ctx, constants.RequestIdKey, "req-1")
+			// Credentials set by the caller are replaced by the identity
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer other")
+			_, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+			require.NoError(t, err)
+
+			md, _ := service.last()
+			for key, value := range tt.want {
+				assert.Equal(t, []string{value}, md.Get(key), key)
+			}
+			if tt.identity.Token == "" {
+				assert.Empty(t, md.Get("authorization"))
+			}
+			assert.Equal(t, []string{"req-1"}, md.Get(RequestIDMetadata))
+			assert.Equal(t, []string{"team-a"}, md.Get("x-tenant"))
+		})
+	}
+}
+
+func TestConn_Deadline(t *testing.T) {
+	service := &healthService{check: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+	client := newTestConn(t, service, Config{Timeout: 50 * time.Millisecond})
+
+	start := time.Now()
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	_, deadline := service.last()
+	assert.WithinDuration(t, start.Add(50*time.Millisecond), deadline, 40*time.Millisecond)
+
+	// An earlier deadline of the request is propagated
+	service = &healthService{}
+	client = newTestConn(t, service, Config{Timeout: time.Minute})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	want, _ := ctx.Deadline()
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	_, deadline = service.last()
+	assert.WithinDuration(t, want, deadline, 100*time.Millisecond)
+}
+
+func TestConn_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	service := &healthService{check: func(context.Context) error { return status.Error(codes.NotFound, "no such service") }}
+	client := newTestConn(t, service, Config{})
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "grpc grpc.health.v1.Health/Check", spans[0].Name())
+	md, _ := service.last()
+	require.Len(t, md.Get("traceparent"), 1, "the trace context is propagated")
+	assert.Contains(t, md.Get("traceparent")[0], spans[0].SpanContext().TraceID().String())
+}
+
+func TestConn_CircuitBreaker(t *testing.T) {
+	service := &healthService{check: func(context.Context) error { return status.Error(codes.Unavailable, "restarting") }}
+	client := newTestConn(t, service, Config{Resilience: resilience.New(resilience.Config{FailureThreshold: 2, Cooldown: time.Minute})})
+
+	for range 2 {
+		_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	}
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.ErrorIs(t, err, resilience.ErrCircuitOpen)
+}
+
+func TestPool(t *testing.T) {
+	pool := NewPool(testLogger(), nil)
+	conn, err := pool.Conn(Config{Target: "mlmd.kubeflow:8080"})
+	require.NoError(t, err)
+	again, err := pool.Conn(Config{Target: "mlmd.kubeflow:8080", Timeout: time.Second})
+	require.NoError(t, err)
+	assert.Same(t, conn, again)
+	other, err := pool.Conn(Config{Target: "modelmesh-serving.kserve:8033"})
+	require.NoError(t, err)
+	assert.NotSame(t, conn, other)
+
+	_, err = pool.Conn(Config{})
+	assert.Error(t, err)
+
+	require.NoError(t, pool.Close())
+	_, err = pool.Conn(Config{Target: "mlmd.kubeflow:8080"})
+	assert.Error(t, err)
+}
+
+func TestHTTPStatus(t *testing.T) {
+	assert.Equal(t, 404, HTTPStatus(codes.NotFound))
+	assert.Equal(t, 403, HTTPStatus(codes.PermissionDenied))
+	assert.Equal(t, 503, HTTPStatus(codes.Unavailable))
+	assert.Equal(t, 500, HTTPStatus(codes.DataLoss))
+}
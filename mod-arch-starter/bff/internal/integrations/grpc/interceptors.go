@@ -0,0 +1,157 @@
+package grpcclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/resilience"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/tracing"
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const instrumentationName = "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/grpc"
+
+// RequestIDMetadata carries the BFF request ID to the service.
+var RequestIDMetadata = strings.ToLower(constants.RequestIDHeader)
+
+// interceptors wraps the calls of one connection.
+type interceptors struct {
+	cfg     Config
+	breaker *resilience.Breaker
+	logger  *slog.Logger
+}
+
+func (i *interceptors) unary(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if err := i.breaker.Allow(); err != nil {
+		return err
+	}
+	callCtx, cancel := context.WithTimeout(ctx, i.cfg.Timeout)
+	defer cancel()
+	callCtx, span := i.startSpan(callCtx, method)
+	defer span.End()
+
+	start := time.Now()
+	err := invoker(i.outgoingContext(callCtx), method, req, reply, cc, opts...)
+	i.finish(ctx, span, method, start, err)
+	return err
+}
+
+func (i *interceptors) stream(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	if err := i.breaker.Allow(); err != nil {
+		return nil, err
+	}
+	callCtx, span := i.startSpan(ctx, method)
+
+	start := time.Now()
+	stream, err := streamer(i.outgoingContext(callCtx), desc, cc, method, opts...)
+	if err != nil {
+		i.finish(ctx, span, method, start, err)
+		span.End()
+		return nil, err
+	}
+	// The breaker only judges opening the stream; the span lasts until the stream ends
+	i.breaker.Record(resilience.Succeeded)
+	return &tracedStream{ClientStream: stream, end: func(err error) {
+		i.record(span, method, start, err)
+		span.End()
+	}}, nil
+}
+
+// outgoingContext adds the caller's identity, the request ID, the trace context and the
+// configured metadata to the metadata of the call. The identity replaces any credentials
+// already in ctx; without an identity no credentials are sent.
+func (i *interceptors) outgoingContext(ctx context.Context) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	for key, values := range i.cfg.Metadata {
+		md.Set(key, values...)
+	}
+
+	identity, _ := ctx.Value(constants.RequestIdentityKey).(*kubernetes.RequestIdentity)
+	forwarded := http.Header{}
+	identity.SetForwardedHeaders(forwarded, i.cfg.AuthTokenHeader, i.cfg.AuthTokenPrefix)
+	for _, key := range []string{i.cfg.AuthTokenHeader, constants.KubeflowUserIDHeader, constants.KubeflowUserGroupsIdHeader} {
+		md.Delete(key)
+	}
+	for key, values := range forwarded {
+		md.Set(key, values...)
+	}
+
+	if requestID, ok := ctx.Value(constants.RequestIdKey).(string); ok && requestID != "" {
+		md.Set(RequestIDMetadata, requestID)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// startSpan starts the client span of a call of method, e.g. "/grpc.health.v1.Health/Check".
+func (i *interceptors) startSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	service, name, _ := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	return otel.Tracer(instrumentationName).Start(ctx, "grpc "+service+"/"+name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.RPCSystemGRPC, semconv.RPCService(service), semconv.RPCMethod(name), semconv.ServerAddress(i.cfg.Target)))
+}
+
+// finish records the outcome of a call made for the caller context ctx.
+func (i *interceptors) finish(ctx context.Context, span trace.Span, method string, start time.Time, err error) {
+	i.breaker.Record(resilience.TransportResult(ctx, HTTPStatus(status.Code(err)), nil))
+	i.record(span, method, start, err)
+}
+
+func (i *interceptors) record(span trace.Span, method string, start time.Time, err error) {
+	code := status.Code(err)
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(code)))
+	tracing.RecordError(span, err)
+	i.logger.Debug("upstream gRPC call", "target", i.cfg.Target, "method", method, "code", code.String(), "duration", time.Since(start))
+}
+
+// tracedStream ends the span of a stream when it ends.
+type tracedStream struct {
+	grpc.ClientStream
+	end  func(err error)
+	done bool
+}
+
+func (s *tracedStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil && !s.done {
+		s.done = true
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+		s.end(err)
+	}
+	return err
+}
+
+// metadataCarrier propagates the trace context in the metadata of a call.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) { metadata.MD(c).Set(key, value) }
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
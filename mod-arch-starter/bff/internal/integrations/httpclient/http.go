@@ -2,12 +2,10 @@ package mrserver
 
 import (
 	"crypto/tls"
-	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
-	"strconv"
 
 	"github.com/google/uuid"
 	helper "github.com/opendatahub-io/mod-arch-library/bff/internal/helpers"
@@ -83,28 +81,7 @@ func (c *HTTPClient) GET(url string) ([]byte, error) {
 	}
 
 	if response.StatusCode != http.StatusOK {
-		var errorResponse ErrorResponse
-		if err := json.Unmarshal(body, &errorResponse); err != nil {
-			// If we can't unmarshal as JSON, create a generic error response with the raw body
-			c.logger.Warn("received non-JSON error response",
-				"status_code", response.StatusCode,
-				"content_type", response.Header.Get("Content-Type"),
-				"body_preview", string(body[:min(len(body), 200)]))
-
-			errorResponse = ErrorResponse{
-				Code:    strconv.Itoa(response.StatusCode),
-				Message: fmt.Sprintf("HTTP %d: %s", response.StatusCode, string(body)),
-			}
-		}
-		httpError := &HTTPError{
-			StatusCode:    response.StatusCode,
-			ErrorResponse: errorResponse,
-		}
-
-		if httpError.Code == "" {
-			httpError.Code = strconv.Itoa(response.StatusCode)
-		}
-		return nil, httpError
+		return nil, newHTTPError(c.logger, response, body)
 	}
 
 	return body, nil
@@ -138,28 +115,7 @@ func (c *HTTPClient) POST(url string, body io.Reader) ([]byte, error) {
 	}
 
 	if response.StatusCode != http.StatusCreated {
-		var errorResponse ErrorResponse
-		if err := json.Unmarshal(responseBody, &errorResponse); err != nil {
-			// If we can't unmarshal as JSON, create a generic error response with the raw body
-			c.logger.Warn("received non-JSON error response",
-				"status_code", response.StatusCode,
-				"content_type", response.Header.Get("Content-Type"),
-				"body_preview", string(responseBody[:min(len(responseBody), 200)]))
-
-			errorResponse = ErrorResponse{
-				Code:    strconv.Itoa(response.StatusCode),
-				Message: fmt.Sprintf("HTTP %d: %s", response.StatusCode, string(responseBody)),
-			}
-		}
-		httpError := &HTTPError{
-			StatusCode:    response.StatusCode,
-			ErrorResponse: errorResponse,
-		}
-
-		if httpError.Code == "" {
-			httpError.Code = strconv.Itoa(response.StatusCode)
-		}
-		return nil, httpError
+		return nil, newHTTPError(c.logger, response, responseBody)
 	}
 
 	return responseBody, nil
@@ -193,28 +149,7 @@ func (c *HTTPClient) PATCH(url string, body io.Reader) ([]byte, error) {
 	}
 
 	if response.StatusCode != http.StatusOK {
-		var errorResponse ErrorResponse
-		if err := json.Unmarshal(responseBody, &errorResponse); err != nil {
-			// If we can't unmarshal as JSON, create a generic error response with the raw body
-			c.logger.Warn("received non-JSON error response",
-				"status_code", response.StatusCode,
-				"content_type", response.Header.Get("Content-Type"),
-				"body_preview", string(responseBody[:min(len(responseBody), 200)]))
-
-			errorResponse = ErrorResponse{
-				Code:    strconv.Itoa(response.StatusCode),
-				Message: fmt.Sprintf("HTTP %d: %s", response.StatusCode, string(responseBody)),
-			}
-		}
-		httpError := &HTTPError{
-			StatusCode:    response.StatusCode,
-			ErrorResponse: errorResponse,
-		}
-
-		if httpError.Code == "" {
-			httpError.Code = strconv.Itoa(response.StatusCode)
-		}
-		return nil, httpError
+		return nil, newHTTPError(c.logger, response, responseBody)
 	}
 	return responseBody, nil
 }
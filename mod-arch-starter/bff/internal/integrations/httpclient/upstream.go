@@ -0,0 +1,388 @@
+package mrserver
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/resilience"
+)
+
+// ─── UPSTREAM CLIENT ─────────────────────────────────────────────────────────────
+//
+// UpstreamClient calls REST APIs of upstream ODH services (model registry, pipelines, ...)
+// on behalf of the caller: every request carries the caller's bearer token or kubeflow
+// headers, idempotent requests are retried with exponential backoff, an optional circuit
+// breaker fails requests fast while the upstream is down, and error responses are decoded
+// into *HTTPError so apierrors keeps their 4xx status.
+
+const (
+	DefaultUpstreamTimeout    = 30 * time.Second
+	DefaultUpstreamMaxRetries = config.DefaultUpstreamMaxRetries
+	DefaultUpstreamMinBackoff = config.DefaultUpstreamRetryMinBackoff
+	DefaultUpstreamMaxBackoff = config.DefaultUpstreamRetryMaxBackoff
+
+	// RequestIDHeader carries the BFF request ID to the upstream service.
+	RequestIDHeader = constants.RequestIDHeader
+)
+
+// UpstreamConfig describes how to reach an upstream service.
+type UpstreamConfig struct {
+	// BaseURL is prepended to every request path, e.g. "https://model-registry:8443/api/model_registry/v1alpha3".
+	BaseURL string
+
+	// Timeout bounds each attempt (default DefaultUpstreamTimeout). The request context bounds the whole call.
+	Timeout time.Duration
+
+	// MaxRetries is the number of retries of idempotent requests after a connection error,
+	// 429 or 5xx (default DefaultUpstreamMaxRetries). Use -1 to disable retries.
+	MaxRetries int
+
+	// MinBackoff and MaxBackoff bound the exponential backoff between retries.
+	// A Retry-After header sent by the upstream takes precedence, capped at MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// Resilience, when set, provides the defaults of MaxRetries and the backoff, counts the
+	// retries, and adds the circuit breaker of the upstream host: while it is open requests
+	// fail with an error matching resilience.ErrCircuitOpen, without calling the upstream.
+	Resilience *resilience.Policy
+
+	// AuthTokenHeader and AuthTokenPrefix control how a bearer token is forwarded
+	// (default "Authorization" and "Bearer ").
+	AuthTokenHeader string
+	AuthTokenPrefix string
+
+	// Headers are added to every request.
+	Headers http.Header
+
+	RootCAs            *x509.CertPool
+	InsecureSkipVerify bool
+
+	// TLSConfig, when set, replaces RootCAs and InsecureSkipVerify, e.g. the configuration of
+	// the upstream in -upstream-tls returned by App.UpstreamTLSConfig.
+	TLSConfig *tls.Config
+
+	// Transport overrides the HTTP transport (the TLS settings are then ignored).
+	Transport http.RoundTripper
+
+	// WrapTransport, when set, wraps the transport, e.g. with the fault injection of
+	// App.Chaos().WrapTransportFor.
+	WrapTransport func(http.RoundTripper) http.RoundTripper
+}
+
+func (c UpstreamConfig) withDefaults() UpstreamConfig {
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultUpstreamTimeout
+	}
+	if c.Resilience != nil {
+		policy := c.Resilience.Config()
+		if c.MaxRetries == 0 {
+			c.MaxRetries = policy.MaxRetries
+			if c.MaxRetries == 0 {
+				c.MaxRetries = -1
+			}
+		}
+		if c.MinBackoff <= 0 {
+			c.MinBackoff = policy.MinBackoff
+		}
+		if c.MaxBackoff <= 0 {
+			c.MaxBackoff = policy.MaxBackoff
+		}
+	}
+	if c.MaxRetries == 0 {
+		c.MaxRetries = DefaultUpstreamMaxRetries
+	} else if c.MaxRetries < 0 {
+		c.MaxRetries = 0
+	}
+	if c.MinBackoff <= 0 {
+		c.MinBackoff = DefaultUpstreamMinBackoff
+	}
+	if c.MaxBackoff < c.MinBackoff {
+		c.MaxBackoff = max(DefaultUpstreamMaxBackoff, c.MinBackoff)
+	}
+	if c.AuthTokenHeader == "" {
+		c.AuthTokenHeader = "Authorization"
+		if c.AuthTokenPrefix == "" {
+			c.AuthTokenPrefix = "Bearer "
+		}
+	}
+	return c
+}
+
+// UpstreamClient is a typed REST client for one upstream service and one caller.
+// Create one per request with NewUpstreamClient or NewUpstreamClientFromContext.
+type UpstreamClient struct {
+	cfg      UpstreamConfig
+	client   *http.Client
+	upstream string
+	breaker  *resilience.Breaker
+	identity *kubernetes.RequestIdentity
+	logger   *slog.Logger
+}
+
+// NewUpstreamClient creates a client that forwards identity on every request.
+// A nil identity sends no credentials.
+func NewUpstreamClient(cfg UpstreamConfig, identity *kubernetes.RequestIdentity, logger *slog.Logger) (*UpstreamClient, error) {
+	if cfg.BaseURL == "" {
+		return nil, errors.New("upstream base URL is required")
+	}
+	baseURL, err := url.Parse(cfg.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid upstream base URL: %w", err)
+	}
+	cfg = cfg.withDefaults()
+
+	transport := cfg.Transport
+	if transport == nil {
+		tlsConfig := &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			InsecureSkipVerify: cfg.InsecureSkipVerify,
+		}
+		if cfg.RootCAs != nil {
+			tlsConfig.RootCAs = cfg.RootCAs
+		}
+		if cfg.TLSConfig != nil {
+			tlsConfig = cfg.TLSConfig
+		}
+		base := http.DefaultTransport.(*http.Transport).Clone()
+		base.TLSClientConfig = tlsConfig
+		transport = base
+	}
+	if cfg.WrapTransport != nil {
+		transport = cfg.WrapTransport(transport)
+	}
+
+	return &UpstreamClient{
+		cfg:      cfg,
+		client:   &http.Client{Transport: transport},
+		upstream: baseURL.Host,
+		breaker:  cfg.Resilience.Breaker(baseURL.Host),
+		identity: identity,
+		logger:   logger,
+	}, nil
+}
+
+// NewUpstreamClientFromContext creates a client for the caller whose RequestIdentity is
+// stored in ctx by the identity middleware.
+func NewUpstreamClientFromContext(ctx context.Context, cfg UpstreamConfig, logger *slog.Logger) (*UpstreamClient, error) {
+	identity, ok := ctx.Value(constants.RequestIdentityKey).(*kubernetes.RequestIdentity)
+	if !ok || identity == nil {
+		return nil, errors.New("missing RequestIdentity in context")
+	}
+	return NewUpstreamClient(cfg, identity, logger)
+}
+
+func (c *UpstreamClient) Get(ctx context.Context, path string, out any) error {
+	return c.Do(ctx, http.MethodGet, path, nil, out)
+}
+
+func (c *UpstreamClient) Post(ctx context.Context, path string, body, out any) error {
+	return c.Do(ctx, http.MethodPost, path, body, out)
+}
+
+func (c *UpstreamClient) Put(ctx context.Context, path string, body, out any) error {
+	return c.Do(ctx, http.MethodPut, path, body, out)
+}
+
+func (c *UpstreamClient) Patch(ctx context.Context, path string, body, out any) error {
+	return c.Do(ctx, http.MethodPatch, path, body, out)
+}
+
+func (c *UpstreamClient) Delete(ctx context.Context, path string) error {
+	return c.Do(ctx, http.MethodDelete, path, nil, nil)
+}
+
+// Do sends body (JSON encoded, nil for none) and decodes a 2xx JSON response into out
+// (nil to discard it). Non-2xx responses are returned as *HTTPError.
+func (c *UpstreamClient) Do(ctx context.Context, method, path string, body, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+	}
+
+	retries := 0
+	if resilience.Idempotent(method) {
+		retries = c.cfg.MaxRetries
+	}
+
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		if err := c.breaker.Allow(); err != nil {
+			// A retry stopped by the circuit opening fails with the upstream error
+			return cmp.Or(lastErr, err)
+		}
+		respBody, retryAfter, err := c.do(ctx, method, path, payload)
+		c.breaker.Record(attemptResult(ctx, err))
+		if err == nil {
+			if out == nil || len(respBody) == 0 {
+				return nil
+			}
+			if err := json.Unmarshal(respBody, out); err != nil {
+				return fmt.Errorf("failed to decode %s %s response: %w", method, path, err)
+			}
+			return nil
+		}
+
+		if attempt >= retries || !isRetryable(err) || ctx.Err() != nil {
+			return err
+		}
+		lastErr = err
+
+		delay := resilience.Backoff(c.cfg.MinBackoff, c.cfg.MaxBackoff, attempt)
+		if retryAfter > 0 {
+			delay = min(retryAfter, c.cfg.MaxBackoff)
+		}
+		c.logger.Debug("retrying upstream request", "method", method, "path", path, "attempt", attempt+1, "delay", delay, "error", err)
+		c.cfg.Resilience.RecordRetry(c.upstream)
+
+		if !resilience.Sleep(ctx, delay) {
+			return err
+		}
+	}
+}
+
+// do performs a single attempt and returns the response body of a 2xx response,
+// or the error together with the Retry-After delay requested by the upstream.
+func (c *UpstreamClient) do(ctx context.Context, method, path string, payload []byte) ([]byte, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	var bodyReader io.Reader
+	if payload != nil {
+		bodyReader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.cfg.BaseURL+path, bodyReader)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	c.applyHeaders(req)
+
+	requestId := uuid.NewString()
+	logUpstreamReq(c.logger, requestId, req)
+
+	response, err := c.client.Do(req)
+	if err != nil {
+		return nil, 0, &connectionError{err: err}
+	}
+	defer response.Body.Close()
+
+	respBody, err := io.ReadAll(response.Body)
+	logUpstreamResp(c.logger, requestId, response, respBody)
+	if err != nil {
+		return nil, 0, &connectionError{err: fmt.Errorf("error reading response body: %w", err)}
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, resilience.ParseRetryAfter(response.Header.Get("Retry-After")), newHTTPError(c.logger, response, respBody)
+	}
+	return respBody, 0, nil
+}
+
+// applyHeaders forwards the caller's identity, the request ID and the configured headers.
+func (c *UpstreamClient) applyHeaders(req *http.Request) {
+	for key, values := range c.cfg.Headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	if requestID, ok := req.Context().Value(constants.RequestIdKey).(string); ok && requestID != "" {
+		req.Header.Set(RequestIDHeader, requestID)
+	}
+
+	if c.identity != nil {
+		c.identity.SetForwardedHeaders(req.Header, c.cfg.AuthTokenHeader, c.cfg.AuthTokenPrefix)
+	}
+}
+
+// connectionError marks failures where no response was received.
+type connectionError struct {
+	err error
+}
+
+func (e *connectionError) Error() string { return fmt.Sprintf("upstream request failed: %v", e.err) }
+func (e *connectionError) Unwrap() error { return e.err }
+
+func isRetryable(err error) bool {
+	var connErr *connectionError
+	if errors.As(err, &connErr) {
+		return !errors.Is(err, context.Canceled)
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return resilience.TransientStatus(httpErr.StatusCode)
+	}
+	return false
+}
+
+// attemptResult classifies the outcome of an attempt for the circuit breaker.
+func attemptResult(ctx context.Context, err error) resilience.Result {
+	var connErr *connectionError
+	if errors.As(err, &connErr) {
+		return resilience.TransportResult(ctx, 0, err)
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return resilience.TransportResult(ctx, httpErr.StatusCode, nil)
+	}
+	return resilience.TransportResult(ctx, http.StatusOK, nil)
+}
+
+// newHTTPError decodes an upstream error response. Both the flat {"code", "message"} format
+// (model registry) and the BFF envelope {"error": {"code", "message"}} are understood;
+// anything else keeps a preview of the raw body as the message.
+func newHTTPError(logger *slog.Logger, response *http.Response, body []byte) *HTTPError {
+	var errorResponse ErrorResponse
+	var envelope struct {
+		Error *ErrorResponse `json:"error"`
+	}
+
+	switch {
+	case json.Unmarshal(body, &envelope) == nil && envelope.Error != nil:
+		errorResponse = *envelope.Error
+	case json.Unmarshal(body, &errorResponse) == nil && (errorResponse.Code != "" || errorResponse.Message != ""):
+	default:
+		// If we can't unmarshal as JSON, create a generic error response with the raw body
+		logger.Warn("received non-JSON error response",
+			"status_code", response.StatusCode,
+			"content_type", response.Header.Get("Content-Type"),
+			"body_preview", string(body[:min(len(body), 200)]))
+
+		errorResponse = ErrorResponse{
+			Code:    strconv.Itoa(response.StatusCode),
+			Message: fmt.Sprintf("HTTP %d: %s", response.StatusCode, string(body)),
+		}
+	}
+
+	httpError := &HTTPError{
+		StatusCode:    response.StatusCode,
+		ErrorResponse: errorResponse,
+	}
+	if httpError.Code == "" {
+		httpError.Code = strconv.Itoa(response.StatusCode)
+	}
+	return httpError
+}
@@ -0,0 +1,196 @@
+package mrserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/resilience"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func newTestUpstream(t *testing.T, handler http.HandlerFunc, identity *kubernetes.RequestIdentity) *UpstreamClient {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := NewUpstreamClient(UpstreamConfig{
+		BaseURL:    server.URL,
+		MinBackoff: time.Millisecond,
+		MaxBackoff: 5 * time.Millisecond,
+	}, identity, testLogger())
+	require.NoError(t, err)
+	return client
+}
+
+func TestUpstreamClient_ForwardsIdentity(t *testing.T) {
+	tests := []struct {
+		name     string
+		identity *kubernetes.RequestIdentity
+		check    func(t *testing.T, r *http.Request)
+	}{
+		{
+			name:     "bearer token",
+			identity: &kubernetes.RequestIdentity{Token: "abc", UserID: "ignored@example.com"},
+			check: func(t *testing.T, r *http.Request) {
+				assert.Equal(t, "Bearer abc", r.Header.Get("Authorization"))
+				assert.Empty(t, r.Header.Get(constants.KubeflowUserIDHeader))
+			},
+		},
+		{
+			name:     "kubeflow headers",
+			identity: &kubernetes.RequestIdentity{UserID: "user@example.com", Groups: []string{"a", "b"}},
+			check: func(t *testing.T, r *http.Request) {
+				assert.Empty(t, r.Header.Get("Authorization"))
+				assert.Equal(t, "user@example.com", r.Header.Get(constants.KubeflowUserIDHeader))
+				assert.Equal(t, "a,b", r.Header.Get(constants.KubeflowUserGroupsIdHeader))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+				tt.check(t, r)
+				assert.Equal(t, "req-1", r.Header.Get(RequestIDHeader))
+				_, _ = w.Write([]byte(`{"name":"model"}`))
+			}, tt.identity)
+
+			ctx := context.WithValue(context.Background(), constants.RequestIdKey, "req-1")
+			var out struct {
+				Name string `json:"name"`
+			}
+			require.NoError(t, client.Get(ctx, "/models/1", &out))
+			assert.Equal(t, "model", out.Name)
+		})
+	}
+}
+
+func TestUpstreamClient_RetriesIdempotentRequests(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	}, nil)
+
+	var out map[string]string
+	require.NoError(t, client.Put(context.Background(), "/models/1", map[string]string{"name": "m"}, &out))
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, "m", out["name"], "the body must be resent on every attempt")
+}
+
+func TestUpstreamClient_DoesNotRetryPost(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}, nil)
+
+	err := client.Post(context.Background(), "/models", map[string]string{"name": "m"}, nil)
+	var httpErr *HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusServiceUnavailable, httpErr.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestUpstreamClient_DecodesErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantCode string
+		wantMsg  string
+	}{
+		{"flat", `{"code":"not-found","message":"model 1 not found"}`, "not-found", "model 1 not found"},
+		{"envelope", `{"error":{"code":"404","message":"no such model"}}`, "404", "no such model"},
+		{"plain text", `nope`, "404", "HTTP 404: nope"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(tt.body))
+			}, nil)
+
+			err := client.Get(context.Background(), "/models/1", nil)
+			var httpErr *HTTPError
+			require.ErrorAs(t, err, &httpErr)
+			assert.Equal(t, http.StatusNotFound, httpErr.StatusCode)
+			assert.Equal(t, tt.wantCode, httpErr.Code)
+			assert.Equal(t, tt.wantMsg, httpErr.Message)
+		})
+	}
+}
+
+func TestUpstreamClient_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client, err := NewUpstreamClient(UpstreamConfig{BaseURL: server.URL, Timeout: 20 * time.Millisecond, MaxRetries: -1}, nil, testLogger())
+	require.NoError(t, err)
+
+	err = client.Get(context.Background(), "/slow", nil)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestNewUpstreamClientFromContext(t *testing.T) {
+	_, err := NewUpstreamClientFromContext(context.Background(), UpstreamConfig{BaseURL: "http://upstream"}, testLogger())
+	assert.Error(t, err)
+
+	ctx := context.WithValue(context.Background(), constants.RequestIdentityKey, &kubernetes.RequestIdentity{Token: "abc"})
+	client, err := NewUpstreamClientFromContext(ctx, UpstreamConfig{BaseURL: "http://upstream"}, testLogger())
+	require.NoError(t, err)
+	assert.Equal(t, "abc", client.identity.Token)
+}
+
+func TestUpstreamClient_CircuitBreaker(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	var retries atomic.Int32
+	policy := resilience.New(resilience.Config{MaxRetries: 1, MinBackoff: time.Millisecond, FailureThreshold: 3})
+	policy.Observe(nil, func(string) { retries.Add(1) })
+	client, err := NewUpstreamClient(UpstreamConfig{BaseURL: server.URL, Resilience: policy}, nil, testLogger())
+	require.NoError(t, err)
+
+	for range 2 {
+		var httpErr *HTTPError
+		require.ErrorAs(t, client.Get(context.Background(), "/models", nil), &httpErr)
+	}
+	assert.Equal(t, int32(3), calls.Load(), "the circuit opens on the third failure")
+	assert.Equal(t, int32(2), retries.Load(), "the policy provides the retries, stopped by the open circuit")
+
+	err = client.Get(context.Background(), "/models", nil)
+	assert.ErrorIs(t, err, resilience.ErrCircuitOpen)
+	assert.Equal(t, int32(3), calls.Load(), "an open circuit fails fast")
+	assert.Equal(t, resilience.StateOpen, policy.Breaker(client.upstream).State(), "one breaker per upstream host")
+}
@@ -0,0 +1,96 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// maxFieldManagerLength is the longest field manager the API server accepts.
+const maxFieldManagerLength = 128
+
+// ApplyOptions are the options of Apply.
+type ApplyOptions struct {
+	// Force takes over the fields other managers own instead of failing with a conflict.
+	Force bool
+	// DryRun applies as a server-side dry run, storing nothing.
+	DryRun bool
+}
+
+// ApplyConflict is a field of an apply owned by another field manager, with another value.
+type ApplyConflict struct {
+	// Manager is the field manager owning the field.
+	Manager string `json:"manager"`
+	// Field is the path of the field, e.g. .data.password.
+	Field string `json:"field"`
+}
+
+// FieldManager returns the field manager of the writes of module for identity,
+// "<module>/<user ID>", so the managedFields of an object tell which user of which module
+// set each field, and a user's apply doesn't silently take over the fields another user or
+// controller applied. It is module alone for identities without a user ID, e.g. bearer
+// tokens, and truncated to the 128 characters the API server accepts.
+func FieldManager(module string, identity *RequestIdentity) string {
+	manager := module
+	if identity != nil && identity.UserID != "" {
+		manager += "/" + identity.UserID
+	}
+	manager = strings.Map(func(r rune) rune {
+		if !unicode.IsPrint(r) {
+			return '_'
+		}
+		return r
+	}, manager)
+	for len(manager) > maxFieldManagerLength {
+		_, size := utf8.DecodeLastRuneInString(manager)
+		manager = manager[:len(manager)-size]
+	}
+	return manager
+}
+
+// Apply server-side applies obj, the fully specified intent of fieldManager, to resource: the
+// object is created when missing, the fields of obj are set and owned by fieldManager, and the
+// fields it owned before but left out of obj are removed; the fields of other managers are
+// kept. Where obj sets a field another manager owns to another value it fails with a 409
+// listing them (see ApplyConflicts), unless opts.Force takes them over. Set the
+// resourceVersion of obj to only apply to that version.
+func Apply(ctx context.Context, resource dynamic.ResourceInterface, obj *unstructured.Unstructured, fieldManager string, opts ApplyOptions) (*unstructured.Unstructured, error) {
+	applyOpts := metav1.ApplyOptions{FieldManager: fieldManager, Force: opts.Force}
+	if opts.DryRun {
+		applyOpts.DryRun = []string{metav1.DryRunAll}
+	}
+	return resource.Apply(ctx, obj.GetName(), obj, applyOpts)
+}
+
+// conflictManager extracts the manager of the message of a FieldManagerConflict cause, e.g.
+// conflict with "kubectl-edit" using v1.
+var conflictManager = regexp.MustCompile(`conflict with "([^"]*)"`)
+
+// ApplyConflicts returns the conflicts of an Apply that failed with a 409, nil for other
+// errors.
+func ApplyConflicts(err error) []ApplyConflict {
+	var status k8serrors.APIStatus
+	if !errors.As(err, &status) || status.Status().Details == nil || !k8serrors.IsConflict(err) {
+		return nil
+	}
+	var conflicts []ApplyConflict
+	for _, cause := range status.Status().Details.Causes {
+		if cause.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+		conflict := ApplyConflict{Field: cause.Field}
+		if match := conflictManager.FindStringSubmatch(cause.Message); match != nil {
+			conflict.Manager = match[1]
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts
+}
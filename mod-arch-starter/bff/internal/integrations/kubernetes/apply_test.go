@@ -0,0 +1,39 @@
+package kubernetes
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestFieldManager(t *testing.T) {
+	assert.Equal(t, "notebooks/dora@example.com", FieldManager("notebooks", &RequestIdentity{UserID: "dora@example.com"}))
+	assert.Equal(t, "notebooks", FieldManager("notebooks", &RequestIdentity{Token: "token"}), "without user ID")
+	assert.Equal(t, "notebooks", FieldManager("notebooks", nil))
+	assert.Equal(t, "notebooks/dora_", FieldManager("notebooks", &RequestIdentity{UserID: "dora\n"}))
+
+	long := FieldManager("notebooks", &RequestIdentity{UserID: strings.Repeat("é", 100)})
+	assert.LessOrEqual(t, len(long), maxFieldManagerLength)
+	assert.True(t, strings.HasSuffix(long, "é"), "truncated on a rune")
+}
+
+func TestApplyConflicts(t *testing.T) {
+	err := k8serrors.NewApplyConflict([]metav1.StatusCause{
+		{Type: metav1.CauseTypeFieldManagerConflict, Message: `conflict with "kubectl-edit" using v1`, Field: ".data.password"},
+		{Type: metav1.CauseTypeFieldManagerConflict, Message: `conflict with "notebooks/bella@example.com" using v1`, Field: ".metadata.labels.team"},
+	}, "Apply failed with 2 conflicts")
+
+	assert.Equal(t, []ApplyConflict{
+		{Manager: "kubectl-edit", Field: ".data.password"},
+		{Manager: "notebooks/bella@example.com", Field: ".metadata.labels.team"},
+	}, ApplyConflicts(fmt.Errorf("error applying secrets connection: %w", err)))
+
+	assert.Nil(t, ApplyConflicts(k8serrors.NewConflict(schema.GroupResource{Resource: "secrets"}, "connection", errors.New("modified"))))
+	assert.Nil(t, ApplyConflicts(errors.New("boom")))
+}
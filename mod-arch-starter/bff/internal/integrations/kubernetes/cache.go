@@ -0,0 +1,318 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// DefaultCacheSyncTimeout bounds how long Start waits for the initial LISTs.
+const DefaultCacheSyncTimeout = 30 * time.Second
+
+// ResourceReader gives read access to namespaces, services and events.
+// Reads are not authorized for the request identity: callers filter the results with
+// CanAccess (as GetNamespaces does) unless the client already acts as the user.
+type ResourceReader interface {
+	ListNamespaces(ctx context.Context) ([]corev1.Namespace, error)
+	GetNamespace(ctx context.Context, name string) (*corev1.Namespace, error)
+	// ListServices lists the services in namespace, or in every namespace when it is empty.
+	ListServices(ctx context.Context, namespace string) ([]corev1.Service, error)
+	GetService(ctx context.Context, namespace, name string) (*corev1.Service, error)
+	// ListEndpointSlices lists the EndpointSlices of a Service. They churn with every pod
+	// change, so they are never cached.
+	ListEndpointSlices(ctx context.Context, namespace, service string) ([]discoveryv1.EndpointSlice, error)
+	// ListEvents lists the events in namespace matching selector, e.g. on involvedObject.name.
+	// Like EndpointSlices they are never cached.
+	ListEvents(ctx context.Context, namespace string, selector fields.Selector) ([]corev1.Event, error)
+}
+
+// CacheConfig selects the resources kept in the ResourceCache.
+type CacheConfig struct {
+	// Resources lists the cached resources (config.CacheResourceNamespaces, config.CacheResourceServices).
+	// Resources that are not listed are always read from the API server.
+	Resources []string
+	// ResyncPeriod defaults to config.DefaultCacheResyncPeriod.
+	ResyncPeriod time.Duration
+	// SyncTimeout defaults to DefaultCacheSyncTimeout.
+	SyncTimeout time.Duration
+}
+
+// Enabled reports whether at least one resource is cached.
+func (c CacheConfig) Enabled() bool {
+	return len(c.Resources) > 0
+}
+
+// ResourceCache is a read-through ResourceReader backed by a SharedInformerFactory.
+// Cached resources are served from the informer store once it has synced; until then, and
+// for resources that are not cached, reads go straight to the API server.
+// A ResourceCache without cached resources is a plain live reader.
+type ResourceCache struct {
+	client  kubernetes.Interface
+	logger  *slog.Logger
+	timeout time.Duration
+	factory informers.SharedInformerFactory
+	stop    chan struct{}
+	stopped atomic.Bool
+
+	namespaces       corelisters.NamespaceLister
+	namespacesSynced cache.InformerSynced
+	services         corelisters.ServiceLister
+	servicesSynced   cache.InformerSynced
+	informers        map[string]cache.SharedIndexInformer
+}
+
+var _ ResourceReader = (*ResourceCache)(nil)
+
+// NewResourceCache creates the informers for the configured resources. They use the
+// credentials of client, so it needs list/watch on every cached resource cluster-wide.
+// Call Start to begin filling the cache.
+func NewResourceCache(client kubernetes.Interface, cfg CacheConfig, logger *slog.Logger) (*ResourceCache, error) {
+	if cfg.ResyncPeriod <= 0 {
+		cfg.ResyncPeriod = config.DefaultCacheResyncPeriod
+	}
+	if cfg.SyncTimeout <= 0 {
+		cfg.SyncTimeout = DefaultCacheSyncTimeout
+	}
+
+	rc := &ResourceCache{
+		client:  client,
+		logger:  logger,
+		timeout: cfg.SyncTimeout,
+	}
+	if !cfg.Enabled() {
+		return rc, nil
+	}
+
+	rc.factory = informers.NewSharedInformerFactory(client, cfg.ResyncPeriod)
+	rc.informers = map[string]cache.SharedIndexInformer{}
+	for _, resource := range cfg.Resources {
+		switch resource {
+		case config.CacheResourceNamespaces:
+			informer := rc.factory.Core().V1().Namespaces()
+			rc.namespaces = informer.Lister()
+			rc.namespacesSynced = informer.Informer().HasSynced
+			rc.informers[resource] = informer.Informer()
+		case config.CacheResourceServices:
+			informer := rc.factory.Core().V1().Services()
+			rc.services = informer.Lister()
+			rc.servicesSynced = informer.Informer().HasSynced
+			rc.informers[resource] = informer.Informer()
+		default:
+			return nil, fmt.Errorf("unsupported cache resource %q (must be %s or %s)", resource, config.CacheResourceNamespaces, config.CacheResourceServices)
+		}
+	}
+
+	return rc, nil
+}
+
+// newLiveReader returns a ResourceReader that always reads from the API server.
+func newLiveReader(client kubernetes.Interface) *ResourceCache {
+	return &ResourceCache{client: client}
+}
+
+// Start runs the informers and waits up to the sync timeout for the initial LISTs.
+// If the timeout expires the informers keep syncing in the background and reads are
+// served live until they catch up; the returned error only reports the slow start.
+func (rc *ResourceCache) Start() error {
+	if rc.factory == nil || rc.stop != nil {
+		return nil
+	}
+
+	rc.stop = make(chan struct{})
+	rc.factory.Start(rc.stop)
+
+	ctx, cancel := context.WithTimeout(context.Background(), rc.timeout)
+	defer cancel()
+
+	started := time.Now()
+	for resource, synced := range rc.factory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			return fmt.Errorf("informer cache for %s did not sync within %s", resource.Name(), rc.timeout)
+		}
+	}
+	rc.logger.Info("informer cache synced", "duration", time.Since(started))
+	return nil
+}
+
+// Stop shuts the informers down; later reads go to the API server.
+func (rc *ResourceCache) Stop() {
+	if rc.stop == nil {
+		return
+	}
+	rc.stopped.Store(true)
+	close(rc.stop)
+	rc.factory.Shutdown()
+}
+
+// AddEventHandler registers handler on the informer of resource (config.CacheResourceNamespaces
+// or config.CacheResourceServices), e.g. to invalidate derived caches when objects change.
+// It returns false if resource is not cached.
+func (rc *ResourceCache) AddEventHandler(resource string, handler cache.ResourceEventHandler) (bool, error) {
+	informer, ok := rc.informers[resource]
+	if !ok {
+		return false, nil
+	}
+	if _, err := informer.AddEventHandler(handler); err != nil {
+		return false, fmt.Errorf("failed to add %s event handler: %w", resource, err)
+	}
+	return true, nil
+}
+
+// CheckSynced fails while a cached resource has not synced yet, or after Stop.
+// A cache without cached resources is always synced.
+func (rc *ResourceCache) CheckSynced(_ context.Context) error {
+	if rc.factory == nil {
+		return nil
+	}
+	if rc.stopped.Load() {
+		return fmt.Errorf("informer cache is stopped")
+	}
+	if rc.namespacesSynced != nil && !rc.namespacesSynced() {
+		return fmt.Errorf("informer cache for %s has not synced", config.CacheResourceNamespaces)
+	}
+	if rc.servicesSynced != nil && !rc.servicesSynced() {
+		return fmt.Errorf("informer cache for %s has not synced", config.CacheResourceServices)
+	}
+	return nil
+}
+
+// useCache reports whether reads of a resource can be served from its informer store.
+func (rc *ResourceCache) useCache(synced cache.InformerSynced) bool {
+	return synced != nil && !rc.stopped.Load() && synced()
+}
+
+func (rc *ResourceCache) ListNamespaces(ctx context.Context) ([]corev1.Namespace, error) {
+	if !rc.useCache(rc.namespacesSynced) {
+		list, err := rc.client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	}
+
+	cached, err := rc.namespaces.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	namespaces := make([]corev1.Namespace, 0, len(cached))
+	for _, ns := range cached {
+		namespaces = append(namespaces, *ns.DeepCopy())
+	}
+	sort.Slice(namespaces, func(i, j int) bool { return namespaces[i].Name < namespaces[j].Name })
+	return namespaces, nil
+}
+
+func (rc *ResourceCache) GetNamespace(ctx context.Context, name string) (*corev1.Namespace, error) {
+	if !rc.useCache(rc.namespacesSynced) {
+		return rc.client.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+	}
+
+	ns, err := rc.namespaces.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return ns.DeepCopy(), nil
+}
+
+func (rc *ResourceCache) ListServices(ctx context.Context, namespace string) ([]corev1.Service, error) {
+	if !rc.useCache(rc.servicesSynced) {
+		list, err := rc.client.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	}
+
+	var cached []*corev1.Service
+	var err error
+	if namespace == "" {
+		cached, err = rc.services.List(labels.Everything())
+	} else {
+		cached, err = rc.services.Services(namespace).List(labels.Everything())
+	}
+	if err != nil {
+		return nil, err
+	}
+	services := make([]corev1.Service, 0, len(cached))
+	for _, svc := range cached {
+		services = append(services, *svc.DeepCopy())
+	}
+	sort.Slice(services, func(i, j int) bool {
+		if services[i].Namespace != services[j].Namespace {
+			return services[i].Namespace < services[j].Namespace
+		}
+		return services[i].Name < services[j].Name
+	})
+	return services, nil
+}
+
+func (rc *ResourceCache) GetService(ctx context.Context, namespace, name string) (*corev1.Service, error) {
+	if !rc.useCache(rc.servicesSynced) {
+		return rc.client.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	}
+
+	svc, err := rc.services.Services(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return svc.DeepCopy(), nil
+}
+
+func (rc *ResourceCache) ListEndpointSlices(ctx context.Context, namespace, service string) ([]discoveryv1.EndpointSlice, error) {
+	list, err := rc.client.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + service,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// ListEvents also matches the items against selector, since not every client applies field
+// selectors (fake clientsets ignore them).
+func (rc *ResourceCache) ListEvents(ctx context.Context, namespace string, selector fields.Selector) ([]corev1.Event, error) {
+	list, err := rc.client.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{FieldSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	events := make([]corev1.Event, 0, len(list.Items))
+	for _, event := range list.Items {
+		if selector.Matches(eventFields(&event)) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// eventFields are the selectable fields of an event, as defined by the API server.
+func eventFields(event *corev1.Event) fields.Set {
+	return fields.Set{
+		"metadata.name":                  event.Name,
+		"metadata.namespace":             event.Namespace,
+		"involvedObject.kind":            event.InvolvedObject.Kind,
+		"involvedObject.namespace":       event.InvolvedObject.Namespace,
+		"involvedObject.name":            event.InvolvedObject.Name,
+		"involvedObject.uid":             string(event.InvolvedObject.UID),
+		"involvedObject.apiVersion":      event.InvolvedObject.APIVersion,
+		"involvedObject.resourceVersion": event.InvolvedObject.ResourceVersion,
+		"involvedObject.fieldPath":       event.InvolvedObject.FieldPath,
+		"reason":                         event.Reason,
+		"reportingComponent":             event.ReportingController,
+		"source":                         event.Source.Component,
+		"type":                           event.Type,
+	}
+}
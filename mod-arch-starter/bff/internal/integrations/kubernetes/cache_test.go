@@ -0,0 +1,144 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// countLists records the LIST calls made against the fake clientset per resource.
+func countLists(clientset *fake.Clientset) map[string]int {
+	lists := map[string]int{}
+	clientset.PrependReactor("list", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		lists[action.GetResource().Resource]++
+		return false, nil, nil
+	})
+	return lists
+}
+
+func TestResourceCache_ServesCachedResources(t *testing.T) {
+	clientset := fake.NewClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "b"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "a"}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "a"}},
+	)
+
+	rc, err := NewResourceCache(clientset, CacheConfig{Resources: []string{config.CacheResourceNamespaces}}, testLogger())
+	require.NoError(t, err)
+	require.NoError(t, rc.Start())
+	defer rc.Stop()
+
+	lists := countLists(clientset)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		namespaces, err := rc.ListNamespaces(ctx)
+		require.NoError(t, err)
+		require.Len(t, namespaces, 2)
+		assert.Equal(t, "a", namespaces[0].Name)
+	}
+	ns, err := rc.GetNamespace(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, "b", ns.Name)
+	_, err = rc.GetNamespace(ctx, "missing")
+	assert.True(t, k8serrors.IsNotFound(err))
+	assert.Zero(t, lists["namespaces"], "cached namespaces must not be listed from the API server")
+
+	// Services are not cached, so every read goes to the API server.
+	services, err := rc.ListServices(ctx, "a")
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	assert.Equal(t, 1, lists["services"])
+
+	// Changes reach the cache through the watch.
+	_, err = clientset.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "c"}}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		namespaces, err := rc.ListNamespaces(ctx)
+		return err == nil && len(namespaces) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestResourceCache_ReadsThroughWhenNotRunning(t *testing.T) {
+	clientset := fake.NewClientset(&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "a"}})
+	rc, err := NewResourceCache(clientset, CacheConfig{Resources: []string{config.CacheResourceServices}}, testLogger())
+	require.NoError(t, err)
+	lists := countLists(clientset)
+	ctx := context.Background()
+
+	// Not started yet
+	assert.ErrorContains(t, rc.CheckSynced(ctx), "services has not synced")
+	svc, err := rc.GetService(ctx, "a", "svc")
+	require.NoError(t, err)
+	assert.Equal(t, "svc", svc.Name)
+	_, err = rc.ListServices(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, lists["services"])
+
+	require.NoError(t, rc.Start())
+	assert.NoError(t, rc.CheckSynced(ctx))
+	services, err := rc.ListServices(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+
+	// Stopped
+	rc.Stop()
+	assert.ErrorContains(t, rc.CheckSynced(ctx), "stopped")
+	listsBefore := lists["services"]
+	_, err = rc.ListServices(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, listsBefore+1, lists["services"])
+}
+
+func TestNewResourceCache_RejectsUnknownResource(t *testing.T) {
+	_, err := NewResourceCache(fake.NewClientset(), CacheConfig{Resources: []string{"pods"}}, testLogger())
+	assert.Error(t, err)
+}
+
+func TestInternalKubernetesClient_GetNamespacesUsesCache(t *testing.T) {
+	clientset := fake.NewClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "a"}})
+	rc, err := NewResourceCache(clientset, CacheConfig{Resources: []string{config.CacheResourceNamespaces}}, testLogger())
+	require.NoError(t, err)
+	require.NoError(t, rc.Start())
+	defer rc.Stop()
+
+	lists := countLists(clientset)
+	kc := &InternalKubernetesClient{SharedClientLogic: SharedClientLogic{Client: clientset, Cache: rc, Logger: testLogger()}}
+	clientset.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		sar := action.(k8stesting.CreateAction).GetObject().(*authv1.SubjectAccessReview)
+		sar.Status.Allowed = true
+		return true, sar, nil
+	})
+
+	namespaces, err := kc.GetNamespaces(context.Background(), &RequestIdentity{UserID: "user@example.com"})
+	require.NoError(t, err)
+	require.Len(t, namespaces, 1)
+	assert.Zero(t, lists["namespaces"])
+}
+
+func TestStaticClientFactory_HealthChecks(t *testing.T) {
+	clientset := fake.NewClientset()
+	client := &InternalKubernetesClient{SharedClientLogic: SharedClientLogic{Client: clientset, Logger: testLogger()}}
+	factory := &StaticClientFactory{Client: client, Logger: testLogger()}
+	ctx := context.Background()
+
+	assert.NoError(t, factory.CheckAPIServer(ctx))
+	assert.NoError(t, factory.CheckCacheSynced(ctx), "no cache is always synced")
+
+	clientset.PrependReactor("get", "version", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+	assert.ErrorContains(t, factory.CheckAPIServer(ctx), "connection refused")
+}
@@ -2,8 +2,14 @@ package kubernetes
 
 import (
 	"context"
+	"io"
 
+	authv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
 )
 
 const ComponentLabelValue = "mod-arch"
@@ -11,6 +17,49 @@ const ComponentLabelValue = "mod-arch"
 // KubernetesClientInterface exposes only the minimal surface needed by the starter project.
 type KubernetesClientInterface interface {
 	GetNamespaces(ctx context.Context, identity *RequestIdentity) ([]corev1.Namespace, error)
-	IsClusterAdmin(identity *RequestIdentity) (bool, error)
-	GetUser(identity *RequestIdentity) (string, error)
+	IsClusterAdmin(ctx context.Context, identity *RequestIdentity) (bool, error)
+	GetUser(ctx context.Context, identity *RequestIdentity) (string, error)
+	// GetGroups returns the groups the identity belongs to: the groups it was authenticated
+	// with, plus its OpenShift Group memberships on OpenShift clusters.
+	GetGroups(ctx context.Context, identity *RequestIdentity) ([]string, error)
+	// CanAccess reports whether the identity is allowed to perform verb on the given
+	// resource (in the given API group and namespace) according to a SubjectAccessReview.
+	// An empty namespace checks cluster-scoped access, and resource may name a subresource
+	// as in kubectl auth can-i, e.g. "pods/log".
+	CanAccess(ctx context.Context, identity *RequestIdentity, verb, group, resource, namespace string) (bool, error)
+	// GetRules lists what the identity may do in namespace, from a SelfSubjectRulesReview.
+	// The list may be incomplete (Incomplete is then set, e.g. with webhook authorizers), so
+	// authorize requests with CanAccess and use the rules to render what the user can do.
+	GetRules(ctx context.Context, identity *RequestIdentity, namespace string) (*authv1.SubjectRulesReviewStatus, error)
+	// WatchResource opens a watch on the resource (in the namespace, or cluster-wide when empty)
+	// on behalf of the identity. Callers must Stop the returned watch.
+	WatchResource(ctx context.Context, identity *RequestIdentity, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (watch.Interface, error)
+	// StreamPodLogs opens the log stream of a pod on behalf of the identity. Callers must
+	// Close the returned stream.
+	StreamPodLogs(ctx context.Context, identity *RequestIdentity, namespace, pod string, opts *corev1.PodLogOptions) (io.ReadCloser, error)
+	// DynamicResource returns a dynamic client for gvr, e.g. a custom resource. It uses the
+	// client's credentials: clients acting as the user are authorized by the API server, the
+	// internal client is not, so check CanAccess first (DynamicResourceRepository does).
+	DynamicResource(gvr schema.GroupVersionResource) (dynamic.NamespaceableResourceInterface, error)
+	// Reader gives read-through access to namespaces and services, served from the shared
+	// informer cache when one is configured. Reads use the client's credentials and are not
+	// authorized for the identity, so filter the results with CanAccess where needed.
+	Reader() ResourceReader
+}
+
+// SharedWatcher is implemented by the clients watching with the credentials of the BFF rather
+// than of the user, whose watches can be shared between users (see watchmux).
+type SharedWatcher interface {
+	// SharedWatch opens a watch on the resource with the client's credentials, without
+	// access review: authorize the users it is shared with.
+	SharedWatch(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (watch.Interface, error)
+}
+
+// Impersonator is implemented by the clients calling the API server with the credentials of the
+// BFF, which can call it as the user instead for the writes the API server must authorize
+// itself, e.g. the RoleBindings checked for privilege escalation.
+type Impersonator interface {
+	// Impersonate returns a client acting as the identity. The credentials of the BFF need
+	// the impersonate verb on users and groups.
+	Impersonate(identity *RequestIdentity) (KubernetesClientInterface, error)
 }
@@ -0,0 +1,152 @@
+package kubernetes
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"k8s.io/client-go/rest"
+)
+
+// ClientSettings tunes the Kubernetes clients: their client-side rate limit, the timeout of
+// their requests and their connection pool to the API server. Zero values keep the client-go
+// defaults.
+type ClientSettings struct {
+	// QPS and Burst rate limit the requests of each client; requests over the limit wait.
+	QPS   float32
+	Burst int
+	// Timeout bounds each request, but watches, followed logs and upgraded connections.
+	// rest.Config.Timeout is not used, as it would bound those as well.
+	Timeout time.Duration
+	// MaxIdleConnsPerHost and MaxConnsPerHost size the connection pool of the API server.
+	MaxIdleConnsPerHost int
+	MaxConnsPerHost     int
+}
+
+// ClientSettingsFromEnv returns the client settings from the BFF configuration.
+func ClientSettingsFromEnv(cfg config.EnvConfig) ClientSettings {
+	return ClientSettings{
+		QPS:                 float32(cfg.KubeAPIQPS),
+		Burst:               cfg.KubeAPIBurst,
+		Timeout:             cfg.KubeAPITimeout,
+		MaxIdleConnsPerHost: cfg.KubeAPIMaxIdleConns,
+		MaxConnsPerHost:     cfg.KubeAPIMaxConns,
+	}
+}
+
+var (
+	clientSettingsMu sync.RWMutex
+	clientSettings   ClientSettings
+)
+
+// UseClientSettings applies settings to every Kubernetes client created afterwards, like the
+// transport wrappers. Call this before the client factory is created.
+func UseClientSettings(settings ClientSettings) {
+	clientSettingsMu.Lock()
+	defer clientSettingsMu.Unlock()
+	clientSettings = settings
+}
+
+// applyClientSettings adds the settings of UseClientSettings to cfg, before the transport
+// wrappers so that they see the requests as sent to the API server.
+func applyClientSettings(cfg *rest.Config) {
+	clientSettingsMu.RLock()
+	settings := clientSettings
+	clientSettingsMu.RUnlock()
+
+	if settings.QPS > 0 {
+		cfg.QPS = settings.QPS
+	}
+	if settings.Burst > 0 {
+		cfg.Burst = settings.Burst
+	}
+	if settings.MaxIdleConnsPerHost > 0 || settings.MaxConnsPerHost > 0 {
+		cfg.Wrap(settings.wrapConnectionPool)
+	}
+	if settings.Timeout > 0 {
+		timeout := settings.Timeout
+		cfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			return &timeoutRoundTripper{next: rt, timeout: timeout}
+		})
+	}
+}
+
+// pooledTransports maps the transports of client-go to their copy with the connection pool
+// settings, so the clients sharing a transport (e.g. one per request with user_token auth)
+// still share their connections.
+var pooledTransports sync.Map
+
+// wrapConnectionPool returns a copy of rt with the connection pool settings, when rt is the
+// *http.Transport of client-go; other transports, e.g. of tests, are returned as is.
+func (s ClientSettings) wrapConnectionPool(rt http.RoundTripper) http.RoundTripper {
+	base, ok := rt.(*http.Transport)
+	if !ok {
+		return rt
+	}
+	type key struct {
+		base              *http.Transport
+		maxIdle, maxConns int
+	}
+	k := key{base, s.MaxIdleConnsPerHost, s.MaxConnsPerHost}
+	if pooled, ok := pooledTransports.Load(k); ok {
+		return pooled.(*http.Transport)
+	}
+	pooled := base.Clone()
+	if s.MaxIdleConnsPerHost > 0 {
+		pooled.MaxIdleConnsPerHost = s.MaxIdleConnsPerHost
+	}
+	if s.MaxConnsPerHost > 0 {
+		pooled.MaxConnsPerHost = s.MaxConnsPerHost
+	}
+	actual, _ := pooledTransports.LoadOrStore(k, pooled)
+	return actual.(*http.Transport)
+}
+
+// timeoutRoundTripper bounds API server requests but the long-running ones. The deadline
+// covers reading the response body, which is when it is released.
+type timeoutRoundTripper struct {
+	next    http.RoundTripper
+	timeout time.Duration
+}
+
+func (rt *timeoutRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if isLongRunning(req) {
+		return rt.next.RoundTrip(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), rt.timeout)
+	resp, err := rt.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// isLongRunning reports whether req is a watch, a followed log or an upgraded connection
+// (exec, attach, port-forward), which last as long as their caller needs.
+func isLongRunning(req *http.Request) bool {
+	query := req.URL.Query()
+	if watch := query.Get("watch"); watch == "true" || watch == "1" {
+		return true
+	}
+	if query.Get("follow") == "true" {
+		return true
+	}
+	return strings.EqualFold(req.Header.Get("Connection"), "upgrade") || req.Header.Get("Upgrade") != ""
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,78 @@
+package kubernetes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func TestApplyClientSettings(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("watch") != "true" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"NamespaceList","apiVersion":"v1","items":[]}`))
+	}))
+	defer srv.Close()
+
+	UseClientSettings(ClientSettings{QPS: 20, Burst: 40, Timeout: 50 * time.Millisecond})
+	defer UseClientSettings(ClientSettings{})
+
+	cfg := &rest.Config{Host: srv.URL}
+	applyClientSettings(cfg)
+	assert.Equal(t, float32(20), cfg.QPS)
+	assert.Equal(t, 40, cfg.Burst)
+	assert.Zero(t, cfg.Timeout, "the http.Client timeout would bound watches")
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	watch, err := clientset.CoreV1().Namespaces().Watch(ctx, metav1.ListOptions{})
+	require.NoError(t, err, "watches are not bounded")
+	watch.Stop()
+}
+
+func TestWrapConnectionPool(t *testing.T) {
+	base := &http.Transport{MaxIdleConnsPerHost: 25}
+	settings := ClientSettings{MaxIdleConnsPerHost: 50, MaxConnsPerHost: 100}
+
+	pooled := settings.wrapConnectionPool(base).(*http.Transport)
+	assert.Equal(t, 50, pooled.MaxIdleConnsPerHost)
+	assert.Equal(t, 100, pooled.MaxConnsPerHost)
+	assert.Equal(t, 25, base.MaxIdleConnsPerHost, "the shared transport is not modified")
+	assert.Same(t, pooled, settings.wrapConnectionPool(base), "clients of the same transport share the copy")
+
+	other := WrapRequestID(base)
+	assert.Equal(t, other, settings.wrapConnectionPool(other), "other transports are left as is")
+}
+
+func TestIsLongRunning(t *testing.T) {
+	for target, want := range map[string]bool{
+		"/api/v1/namespaces/ns/pods":                     false,
+		"/api/v1/namespaces/ns/pods?watch=true":          true,
+		"/api/v1/namespaces/ns/pods?watch=1":             true,
+		"/api/v1/namespaces/ns/pods/p/log?follow=true":   true,
+		"/api/v1/namespaces/ns/pods/p/log?follow=false":  false,
+		"/api/v1/namespaces/ns/pods?watch=false&limit=5": false,
+	} {
+		assert.Equal(t, want, isLongRunning(httptest.NewRequest(http.MethodGet, target, nil)), target)
+	}
+
+	exec := httptest.NewRequest(http.MethodPost, "/api/v1/namespaces/ns/pods/p/exec", nil)
+	exec.Header.Set("Connection", "Upgrade")
+	exec.Header.Set("Upgrade", "SPDY/3.1")
+	assert.True(t, isLongRunning(exec))
+}
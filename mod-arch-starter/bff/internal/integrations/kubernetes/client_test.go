@@ -0,0 +1,279 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/openshift"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authnv1 "k8s.io/api/authentication/v1"
+	authv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestInternalKubernetesClient_CanAccess(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	var got *authv1.SubjectAccessReview
+	clientset.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		got = action.(k8stesting.CreateAction).GetObject().(*authv1.SubjectAccessReview)
+		got.Status.Allowed = got.Spec.ResourceAttributes.Namespace == "allowed-ns"
+		return true, got, nil
+	})
+
+	kc := &InternalKubernetesClient{SharedClientLogic: SharedClientLogic{Client: clientset, Logger: testLogger()}}
+	identity := &RequestIdentity{UserID: "user@example.com", Groups: []string{"team-a"}}
+
+	allowed, err := kc.CanAccess(context.Background(), identity, "list", "apps", "deployments", "allowed-ns")
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	require.NotNil(t, got)
+	assert.Equal(t, "user@example.com", got.Spec.User)
+	assert.Equal(t, []string{"team-a"}, got.Spec.Groups)
+	assert.Equal(t, "list", got.Spec.ResourceAttributes.Verb)
+	assert.Equal(t, "apps", got.Spec.ResourceAttributes.Group)
+	assert.Equal(t, "deployments", got.Spec.ResourceAttributes.Resource)
+
+	allowed, err = kc.CanAccess(context.Background(), identity, "list", "apps", "deployments", "other-ns")
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+func TestTokenKubernetesClient_CanAccess(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		ssar := action.(k8stesting.CreateAction).GetObject().(*authv1.SelfSubjectAccessReview)
+		ssar.Status.Allowed = ssar.Spec.ResourceAttributes.Verb == "get"
+		return true, ssar, nil
+	})
+
+	kc := &TokenKubernetesClient{SharedClientLogic: SharedClientLogic{Client: clientset, Logger: testLogger()}}
+
+	allowed, err := kc.CanAccess(context.Background(), nil, "get", "", "services", "ns")
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = kc.CanAccess(context.Background(), nil, "delete", "", "services", "ns")
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+func TestStaticClientFactory_CanBackendAccess(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	var got *authv1.SelfSubjectAccessReview
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		got = action.(k8stesting.CreateAction).GetObject().(*authv1.SelfSubjectAccessReview)
+		got.Status.Allowed = got.Spec.ResourceAttributes.Resource == "namespaces"
+		return true, got, nil
+	})
+	factory := &StaticClientFactory{Client: &InternalKubernetesClient{SharedClientLogic: SharedClientLogic{Client: clientset, Logger: testLogger()}}}
+
+	allowed, err := factory.CanBackendAccess(context.Background(), "list", "", "namespaces")
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	allowed, err = factory.CanBackendAccess(context.Background(), "create", "authorization.k8s.io", "subjectaccessreviews")
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, &authv1.ResourceAttributes{Verb: "create", Group: "authorization.k8s.io", Resource: "subjectaccessreviews"}, got.Spec.ResourceAttributes)
+
+	assert.Equal(t, "create subjectaccessreviews.authorization.k8s.io", BackendPermissions("internal")[0].String())
+	assert.Equal(t, "impersonate users", BackendPermissions("impersonation")[0].String())
+	assert.Empty(t, BackendPermissions("user"), "the users' tokens only")
+}
+
+func TestTokenKubernetesClient_GetRules(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "selfsubjectrulesreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authv1.SelfSubjectRulesReview)
+		review.Status.ResourceRules = []authv1.ResourceRule{{Verbs: []string{"get", "list"}, Resources: []string{"pods"}}}
+		review.Status.Incomplete = review.Spec.Namespace != "ns"
+		return true, review, nil
+	})
+	kc := &TokenKubernetesClient{SharedClientLogic: SharedClientLogic{Client: clientset, Logger: testLogger()}}
+
+	status, err := kc.GetRules(context.Background(), nil, "ns")
+	require.NoError(t, err)
+	assert.Equal(t, []authv1.ResourceRule{{Verbs: []string{"get", "list"}, Resources: []string{"pods"}}}, status.ResourceRules)
+	assert.False(t, status.Incomplete)
+
+	_, err = (&InternalKubernetesClient{SharedClientLogic: SharedClientLogic{Client: clientset, Logger: testLogger()}}).GetRules(context.Background(), &RequestIdentity{UserID: "user@example.com"}, "ns")
+	assert.ErrorContains(t, err, "REST config", "the internal client impersonates the user")
+}
+
+func TestInternalKubernetesClient_GetRules(t *testing.T) {
+	var got *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"SelfSubjectRulesReview","apiVersion":"authorization.k8s.io/v1","status":{"resourceRules":[{"verbs":["get"],"resources":["pods"]}],"incomplete":false}}`))
+	}))
+	defer srv.Close()
+
+	kc, err := newInternalKubernetesClient(&rest.Config{Host: srv.URL, UserAgent: "mod-arch-bff"}, testLogger(), CacheConfig{})
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), constants.RequestIdKey, "req-123")
+	status, err := kc.GetRules(ctx, &RequestIdentity{UserID: "user@example.com", Groups: []string{"team-a"}}, "ns")
+	require.NoError(t, err)
+	assert.Equal(t, []authv1.ResourceRule{{Verbs: []string{"get"}, Resources: []string{"pods"}}}, status.ResourceRules)
+
+	require.NotNil(t, got)
+	assert.Equal(t, "user@example.com", got.Header.Get("Impersonate-User"))
+	assert.Equal(t, []string{"team-a"}, got.Header.Values("Impersonate-Group"))
+	assert.Equal(t, "mod-arch-bff request-id/req-123", got.Header.Get("User-Agent"), "the transport wrappers are applied once")
+}
+
+func TestFilterAccessibleNamespaces(t *testing.T) {
+	namespaces := []corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "a"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "b"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "c"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "d"}},
+	}
+
+	allowed, err := filterAccessibleNamespaces(context.Background(), testLogger(), namespaces, func(_ context.Context, namespace string) (bool, error) {
+		switch namespace {
+		case "a", "d":
+			return true, nil
+		case "c":
+			return false, errors.New("boom")
+		default:
+			return false, nil
+		}
+	})
+	require.NoError(t, err)
+	require.Len(t, allowed, 2)
+	assert.Equal(t, "a", allowed[0].Name)
+	assert.Equal(t, "d", allowed[1].Name)
+}
+
+func TestTokenKubernetesClient_GetNamespacesFiltersBySSAR(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "mine"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "theirs"}},
+	)
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		ssar := action.(k8stesting.CreateAction).GetObject().(*authv1.SelfSubjectAccessReview)
+		ssar.Status.Allowed = ssar.Spec.ResourceAttributes.Namespace == "mine"
+		return true, ssar, nil
+	})
+
+	kc := &TokenKubernetesClient{SharedClientLogic: SharedClientLogic{Client: clientset, Logger: testLogger()}}
+
+	namespaces, err := kc.GetNamespaces(context.Background(), nil)
+	require.NoError(t, err)
+	require.Len(t, namespaces, 1)
+	assert.Equal(t, "mine", namespaces[0].Name)
+}
+
+func openShiftGroup(name string, users ...string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "user.openshift.io/v1",
+		"kind":       "Group",
+		"metadata":   map[string]any{"name": name},
+		"users":      toAnySlice(users),
+	}}
+}
+
+func toAnySlice(values []string) []any {
+	out := make([]any, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
+
+func TestInternalKubernetesClient_GetGroups(t *testing.T) {
+	identity := &RequestIdentity{UserID: "user@example.com", Groups: []string{"team-a"}}
+
+	t.Run("vanilla Kubernetes", func(t *testing.T) {
+		kc := &InternalKubernetesClient{SharedClientLogic: SharedClientLogic{Client: fake.NewSimpleClientset(), Logger: testLogger()}}
+		groups, err := kc.GetGroups(context.Background(), identity)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"team-a"}, groups)
+	})
+
+	t.Run("OpenShift groups", func(t *testing.T) {
+		dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{openshift.GroupsGVR: "GroupList"},
+			openShiftGroup("odh-admins", "admin@example.com", "user@example.com"),
+			openShiftGroup("team-a", "user@example.com"),
+			openShiftGroup("others", "someone@example.com"),
+		)
+		kc := &InternalKubernetesClient{SharedClientLogic: SharedClientLogic{Client: fake.NewSimpleClientset(), OpenShift: openshift.NewClient(dynamicClient), Logger: testLogger()}}
+		groups, err := kc.GetGroups(context.Background(), identity)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"team-a", "odh-admins"}, groups)
+	})
+}
+
+func TestTokenKubernetesClient_GetGroups(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "selfsubjectreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		ssr := action.(k8stesting.CreateAction).GetObject().(*authnv1.SelfSubjectReview)
+		ssr.Status.UserInfo = authnv1.UserInfo{Username: "dev", Groups: []string{"devs", "system:authenticated"}}
+		return true, ssr, nil
+	})
+
+	kc := &TokenKubernetesClient{SharedClientLogic: SharedClientLogic{Client: clientset, Logger: testLogger()}}
+	groups, err := kc.GetGroups(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"devs", "system:authenticated"}, groups)
+
+	clientset.PrependReactor("create", "selfsubjectreviews", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("unauthorized")
+	})
+	_, err = kc.GetGroups(context.Background(), nil)
+	assert.Error(t, err)
+}
+
+func TestOpenShiftDetector(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{openshift.ProjectsGVR: "ProjectList"},
+		&unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "project.openshift.io/v1",
+			"kind":       "Project",
+			"metadata":   map[string]any{"name": "my-project"},
+		}},
+	)
+	var detector openShiftDetector
+
+	vanilla := &TokenKubernetesClient{SharedClientLogic: SharedClientLogic{Client: clientset, Dynamic: dynamicClient, Logger: testLogger()}}
+	detector.setup(&vanilla.SharedClientLogic)
+	assert.Nil(t, vanilla.OpenShift)
+
+	// The result is remembered: the cluster doesn't change between requests
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{{GroupVersion: "project.openshift.io/v1"}}
+	detector.setup(&vanilla.SharedClientLogic)
+	assert.Nil(t, vanilla.OpenShift)
+
+	var openShift openShiftDetector
+	kc := &TokenKubernetesClient{SharedClientLogic: SharedClientLogic{Client: clientset, Dynamic: dynamicClient, Logger: testLogger()}}
+	openShift.setup(&kc.SharedClientLogic)
+	require.NotNil(t, kc.OpenShift)
+
+	// Projects are listed instead of namespaces, without access reviews
+	namespaces, err := kc.GetNamespaces(context.Background(), nil)
+	require.NoError(t, err)
+	require.Len(t, namespaces, 1)
+	assert.Equal(t, "my-project", namespaces[0].Name)
+}
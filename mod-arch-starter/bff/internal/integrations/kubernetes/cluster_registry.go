@@ -0,0 +1,347 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	helper "github.com/opendatahub-io/mod-arch-library/bff/internal/helpers"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/parallel"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// clusterProbeTimeout bounds the reachability check of each cluster by Status.
+const clusterProbeTimeout = 5 * time.Second
+
+// clusterSecretKeys are the keys of a kubeconfig Secret holding the kubeconfig, in order:
+// "kubeconfig" is the common convention, "value" the one of Cluster API and Open Cluster
+// Management.
+var clusterSecretKeys = []string{"kubeconfig", "value"}
+
+// ErrUnknownCluster is returned for a cluster name missing from a ClusterRegistry.
+var ErrUnknownCluster = errors.New("unknown cluster")
+
+// ─── CLUSTER REGISTRY ───────────────────────────────────────────────────────
+// federates the clusters the BFF talks to: the local one of the backend kubeconfig and
+// the other clusters (e.g. the spokes of a hub), each with a client factory of the same
+// auth method. The registry is itself a KubernetesClientFactory whose clients target the
+// cluster of the request context (WithCluster), so repositories reach any cluster with the
+// client they are given.
+
+// ClusterRegistry holds the client factory of every cluster, by name. Register the clusters
+// before serving requests; it is safe for concurrent use afterwards.
+type ClusterRegistry struct {
+	logger    *slog.Logger
+	localName string
+	// names lists the clusters in registration order, the local one first.
+	names     []string
+	factories map[string]KubernetesClientFactory
+	// scope restricts the clients of every cluster to its namespaces, when set.
+	scope *NamespaceScope
+
+	// hosts maps the API server host of the loaded clusters to their name. Clients of the
+	// local cluster read it while Load writes it.
+	hostsMu sync.RWMutex
+	hosts   map[string]string
+}
+
+// ClusterStatus is the reachability of a cluster, as reported by ClusterRegistry.Status.
+type ClusterStatus struct {
+	Name      string
+	Local     bool
+	Reachable bool
+}
+
+var (
+	_ KubernetesClientFactory = (*ClusterRegistry)(nil)
+	_ APIServerChecker        = (*ClusterRegistry)(nil)
+	_ CacheSyncChecker        = (*ClusterRegistry)(nil)
+	_ io.Closer               = (*ClusterRegistry)(nil)
+)
+
+// NewClusterRegistry returns a registry of the local cluster only, named localName
+// (config.DefaultClusterName when empty). Add the other clusters with Load or Register.
+func NewClusterRegistry(local KubernetesClientFactory, localName string, logger *slog.Logger) *ClusterRegistry {
+	if localName == "" {
+		localName = config.DefaultClusterName
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &ClusterRegistry{
+		logger:    logger,
+		localName: localName,
+		names:     []string{localName},
+		factories: map[string]KubernetesClientFactory{localName: local},
+		hosts:     map[string]string{},
+	}
+}
+
+// Load registers the clusters of cfg.ClusterContexts and cfg.ClusterSecrets. The kubeconfig
+// Secrets are read with the backend credentials, so a missing context or Secret fails the
+// startup.
+func (r *ClusterRegistry) Load(ctx context.Context, cfg config.EnvConfig) error {
+	refs, err := cfg.ParseClusterRefs()
+	if err != nil {
+		return err
+	}
+
+	// Resolve every API server first, so that ClusterOfRequest names the clusters of the
+	// requests made while their factories are created
+	restConfigs := make([]*rest.Config, len(refs))
+	var secrets kubernetes.Interface
+	for i, ref := range refs {
+		if ref.Context != "" {
+			restConfigs[i], err = helper.GetKubeconfigForContext(ref.Context)
+			if err != nil {
+				return fmt.Errorf("failed to get kubeconfig of cluster %q: %w", ref.Name, err)
+			}
+			r.logger.Info("adding cluster", "cluster", ref.Name, "context", ref.Context)
+		} else {
+			if secrets == nil {
+				if secrets, err = newSecretReader(); err != nil {
+					return err
+				}
+			}
+			restConfigs[i], err = kubeconfigFromSecret(ctx, secrets, ref.SecretNamespace, ref.SecretName)
+			if err != nil {
+				return fmt.Errorf("failed to get kubeconfig of cluster %q: %w", ref.Name, err)
+			}
+			r.logger.Info("adding cluster", "cluster", ref.Name, "secret", ref.SecretNamespace+"/"+ref.SecretName)
+		}
+		r.addHost(restConfigs[i].Host, ref.Name)
+	}
+
+	for i, ref := range refs {
+		factory, err := NewKubernetesClientFactoryForConfig(cfg, restConfigs[i], r.logger.With("cluster", ref.Name))
+		if err != nil {
+			return fmt.Errorf("failed to create Kubernetes client of cluster %q: %w", ref.Name, err)
+		}
+		if err := r.Register(ref.Name, factory); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newSecretReader returns a client with the backend credentials to read kubeconfig Secrets.
+func newSecretReader() (kubernetes.Interface, error) {
+	kubeconfig, err := helper.GetKubeconfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	clientset, err := kubernetes.NewForConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	return clientset, nil
+}
+
+// kubeconfigFromSecret returns the rest.Config of the kubeconfig in the Secret namespace/name.
+func kubeconfigFromSecret(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*rest.Config, error) {
+	secret, err := clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret %s/%s: %w", namespace, name, err)
+	}
+	for _, key := range clusterSecretKeys {
+		if data := secret.Data[key]; len(data) > 0 {
+			restConfig, err := clientcmd.RESTConfigFromKubeConfig(data)
+			if err != nil {
+				return nil, fmt.Errorf("invalid kubeconfig in secret %s/%s: %w", namespace, name, err)
+			}
+			return restConfig, nil
+		}
+	}
+	return nil, fmt.Errorf("secret %s/%s has no kubeconfig (under a %q or %q key)", namespace, name, clusterSecretKeys[0], clusterSecretKeys[1])
+}
+
+// RestrictToNamespaces restricts the clients of every cluster to scope (see NamespaceScope).
+// Call it before serving requests.
+func (r *ClusterRegistry) RestrictToNamespaces(scope *NamespaceScope) {
+	r.scope = scope
+}
+
+// Register adds the cluster name, a DNS label, served by factory.
+func (r *ClusterRegistry) Register(name string, factory KubernetesClientFactory) error {
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return fmt.Errorf("invalid cluster name %q: %s", name, errs[0])
+	}
+	if _, exists := r.factories[name]; exists {
+		return fmt.Errorf("duplicate cluster name %q", name)
+	}
+	r.names = append(r.names, name)
+	r.factories[name] = factory
+	return nil
+}
+
+// addHost maps the API server host of a rest.Config, a URL or host:port, to cluster.
+func (r *ClusterRegistry) addHost(host, cluster string) {
+	if u, err := url.Parse(host); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	r.hostsMu.Lock()
+	defer r.hostsMu.Unlock()
+	r.hosts[host] = cluster
+}
+
+// ClusterOfRequest returns the loaded cluster whose API server req is sent to, or "" for the
+// local cluster, a cluster registered with Register, or a nil registry. Transport wrappers
+// use it to tell clusters apart, e.g. in their metrics.
+func (r *ClusterRegistry) ClusterOfRequest(req *http.Request) string {
+	if r == nil {
+		return ""
+	}
+	r.hostsMu.RLock()
+	defer r.hostsMu.RUnlock()
+	return r.hosts[req.URL.Host]
+}
+
+// LocalName returns the name of the local cluster.
+func (r *ClusterRegistry) LocalName() string {
+	return r.localName
+}
+
+// Names returns the names of the clusters, the local one first.
+func (r *ClusterRegistry) Names() []string {
+	return append([]string(nil), r.names...)
+}
+
+// Has reports whether name is a cluster of the registry; "" is the local cluster.
+func (r *ClusterRegistry) Has(name string) bool {
+	_, err := r.Factory(name)
+	return err == nil
+}
+
+// Factory returns the client factory of the cluster name, the local one for "".
+func (r *ClusterRegistry) Factory(name string) (KubernetesClientFactory, error) {
+	if name == "" {
+		name = r.localName
+	}
+	factory, ok := r.factories[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCluster, name)
+	}
+	return factory, nil
+}
+
+// ClientFor returns a client of the cluster name for the request identity of ctx, whatever
+// the cluster of ctx.
+func (r *ClusterRegistry) ClientFor(ctx context.Context, name string) (KubernetesClientInterface, error) {
+	factory, err := r.Factory(name)
+	if err != nil {
+		return nil, err
+	}
+	client, err := factory.GetClient(ctx)
+	if err != nil || r.scope == nil {
+		return client, err
+	}
+	return r.scope.Client(client), nil
+}
+
+// GetClient returns a client of the cluster of ctx (see WithCluster), the local one by default.
+func (r *ClusterRegistry) GetClient(ctx context.Context) (KubernetesClientInterface, error) {
+	return r.ClientFor(ctx, ClusterFromContext(ctx))
+}
+
+// ExtractRequestIdentity extracts the identity with the local factory: every cluster uses the
+// same auth method, so the same identity.
+func (r *ClusterRegistry) ExtractRequestIdentity(httpHeader http.Header) (*RequestIdentity, error) {
+	return r.factories[r.localName].ExtractRequestIdentity(httpHeader)
+}
+
+func (r *ClusterRegistry) ValidateRequestIdentity(identity *RequestIdentity) error {
+	return r.factories[r.localName].ValidateRequestIdentity(identity)
+}
+
+// Status checks in parallel whether the API server of each cluster is reachable. Errors are
+// logged rather than returned, as they may describe the network of the clusters.
+func (r *ClusterRegistry) Status(ctx context.Context) []ClusterStatus {
+	statuses := make([]ClusterStatus, len(r.names))
+	for i, name := range r.names {
+		statuses[i] = ClusterStatus{Name: name, Local: name == r.localName}
+	}
+	// Clusters left unchecked when ctx is done are reported unreachable
+	_ = parallel.ForEach(ctx, parallel.Options{Timeout: clusterProbeTimeout}, len(r.names), func(ctx context.Context, i int) {
+		checker, ok := r.factories[r.names[i]].(APIServerChecker)
+		if !ok {
+			statuses[i].Reachable = true
+			return
+		}
+		if err := checker.CheckAPIServer(ctx); err != nil {
+			r.logger.Warn("cluster is unreachable", "cluster", r.names[i], "error", err)
+			return
+		}
+		statuses[i].Reachable = true
+	})
+	return statuses
+}
+
+// CheckAPIServer checks the API server of the local cluster: other clusters being unreachable
+// doesn't make the BFF unhealthy.
+func (r *ClusterRegistry) CheckAPIServer(ctx context.Context) error {
+	if checker, ok := r.factories[r.localName].(APIServerChecker); ok {
+		return checker.CheckAPIServer(ctx)
+	}
+	return nil
+}
+
+// CheckCacheSynced checks the informer cache of the local cluster, the only one with a cache.
+func (r *ClusterRegistry) CheckCacheSynced(ctx context.Context) error {
+	if checker, ok := r.factories[r.localName].(CacheSyncChecker); ok {
+		return checker.CheckCacheSynced(ctx)
+	}
+	return nil
+}
+
+// ResourceCache returns the informer cache of the local cluster, or nil.
+func (r *ClusterRegistry) ResourceCache() *ResourceCache {
+	if factory, ok := r.factories[r.localName].(interface{ ResourceCache() *ResourceCache }); ok {
+		return factory.ResourceCache()
+	}
+	return nil
+}
+
+// ReviewCache returns the review cache of the local cluster, or nil.
+func (r *ClusterRegistry) ReviewCache() *ReviewCache {
+	if factory, ok := r.factories[r.localName].(interface{ ReviewCache() *ReviewCache }); ok {
+		return factory.ReviewCache()
+	}
+	return nil
+}
+
+// Close closes the factory of every cluster.
+func (r *ClusterRegistry) Close() error {
+	var errs []error
+	for _, name := range r.names {
+		if closer, ok := r.factories[name].(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("cluster %q: %w", name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WithCluster returns a copy of ctx whose Kubernetes clients, from a ClusterRegistry, target
+// the cluster name.
+func WithCluster(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, constants.ClusterKey, name)
+}
+
+// ClusterFromContext returns the cluster set by WithCluster, or "" for the local cluster.
+func ClusterFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(constants.ClusterKey).(string)
+	return name
+}
@@ -0,0 +1,141 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: hub
+  cluster: {server: "https://hub.example.com:6443"}
+- name: spoke
+  cluster: {server: "https://spoke.example.com:6443"}
+users:
+- name: admin
+  user: {token: admin-token}
+contexts:
+- name: hub-admin
+  context: {cluster: hub, user: admin}
+- name: spoke-admin
+  context: {cluster: spoke, user: admin}
+current-context: hub-admin
+`
+
+func newTestStaticFactory(reachable bool) *StaticClientFactory {
+	clientset := fake.NewSimpleClientset()
+	if !reachable {
+		clientset.PrependReactor("get", "version", func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("connection refused")
+		})
+	}
+	return &StaticClientFactory{
+		Logger: testLogger(),
+		Client: &InternalKubernetesClient{SharedClientLogic: SharedClientLogic{Client: clientset, Logger: testLogger()}},
+	}
+}
+
+func TestClusterRegistry_GetClient(t *testing.T) {
+	hub, spoke := newTestStaticFactory(true), newTestStaticFactory(true)
+	registry := NewClusterRegistry(hub, "hub", testLogger())
+	require.NoError(t, registry.Register("spoke", spoke))
+	assert.Error(t, registry.Register("spoke", spoke), "names are unique")
+	assert.Error(t, registry.Register("Spoke_1", spoke), "names are DNS labels")
+	assert.Equal(t, []string{"hub", "spoke"}, registry.Names())
+
+	ctx := context.Background()
+	client, err := registry.GetClient(ctx)
+	require.NoError(t, err)
+	assert.Same(t, hub.Client, client, "the local cluster by default")
+
+	client, err = registry.GetClient(WithCluster(ctx, "spoke"))
+	require.NoError(t, err)
+	assert.Same(t, spoke.Client, client)
+
+	client, err = registry.ClientFor(ctx, "hub")
+	require.NoError(t, err)
+	assert.Same(t, hub.Client, client)
+
+	_, err = registry.GetClient(WithCluster(ctx, "other"))
+	assert.ErrorIs(t, err, ErrUnknownCluster)
+	assert.False(t, registry.Has("other"))
+	assert.True(t, registry.Has(""))
+
+	registry.RestrictToNamespaces(NewNamespaceScope([]string{"team-a"}, testLogger()))
+	client, err = registry.GetClient(WithCluster(ctx, "spoke"))
+	require.NoError(t, err)
+	assert.IsType(t, &namespaceScopedClient{}, client, "every cluster is restricted")
+}
+
+func TestClusterRegistry_Status(t *testing.T) {
+	registry := NewClusterRegistry(newTestStaticFactory(true), "", testLogger())
+	require.NoError(t, registry.Register("spoke-a", newTestStaticFactory(false)))
+	require.NoError(t, registry.Register("spoke-b", newTestStaticFactory(true)))
+
+	assert.Equal(t, []ClusterStatus{
+		{Name: config.DefaultClusterName, Local: true, Reachable: true},
+		{Name: "spoke-a", Reachable: false},
+		{Name: "spoke-b", Reachable: true},
+	}, registry.Status(context.Background()))
+	assert.NoError(t, registry.CheckAPIServer(context.Background()), "other clusters don't make the BFF unhealthy")
+}
+
+func TestClusterRegistry_Load(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(path, []byte(testKubeconfig), 0o600))
+	t.Setenv("KUBECONFIG", path)
+
+	cfg := config.DefaultEnvConfig()
+	cfg.AuthMethod = config.AuthMethodImpersonation
+	cfg.ClusterContexts = []string{"spoke=spoke-admin"}
+	cfg.ReviewCacheAllowedTTL = time.Minute
+	registry := NewClusterRegistry(newTestStaticFactory(true), cfg.ClusterName, testLogger())
+	require.NoError(t, registry.Load(context.Background(), cfg))
+	assert.Equal(t, []string{"local", "spoke"}, registry.Names())
+
+	factory, err := registry.Factory("spoke")
+	require.NoError(t, err)
+	assert.Equal(t, "https://spoke.example.com:6443", factory.(*ImpersonationClientFactory).BaseConfig.Host)
+	assert.NotNil(t, factory.(*ImpersonationClientFactory).ReviewCache(), "each cluster has its own review cache")
+
+	assert.Equal(t, "spoke", registry.ClusterOfRequest(httptest.NewRequest("GET", "https://spoke.example.com:6443/version", nil)))
+	assert.Empty(t, registry.ClusterOfRequest(httptest.NewRequest("GET", "https://hub.example.com:6443/version", nil)))
+	var none *ClusterRegistry
+	assert.Empty(t, none.ClusterOfRequest(httptest.NewRequest("GET", "https://spoke.example.com:6443/version", nil)))
+
+	cfg.ClusterContexts = []string{"missing=missing-context"}
+	assert.Error(t, NewClusterRegistry(newTestStaticFactory(true), "", testLogger()).Load(context.Background(), cfg))
+}
+
+func TestKubeconfigFromSecret(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ocm", Name: "spoke"}, Data: map[string][]byte{"value": []byte(testKubeconfig)}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ocm", Name: "empty"}, Data: map[string][]byte{"token": []byte("t")}},
+	)
+	ctx := context.Background()
+
+	restConfig, err := kubeconfigFromSecret(ctx, clientset, "ocm", "spoke")
+	require.NoError(t, err)
+	assert.Equal(t, "https://hub.example.com:6443", restConfig.Host, "the current context of the kubeconfig")
+	assert.Equal(t, "admin-token", restConfig.BearerToken)
+
+	_, err = kubeconfigFromSecret(ctx, clientset, "ocm", "empty")
+	assert.ErrorContains(t, err, "has no kubeconfig")
+	_, err = kubeconfigFromSecret(ctx, clientset, "ocm", "missing")
+	assert.Error(t, err)
+}
@@ -7,28 +7,78 @@ import (
 	"log/slog"
 	"net/http"
 	"strings"
+	"sync"
 
 	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
 	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	helper "github.com/opendatahub-io/mod-arch-library/bff/internal/helpers"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/openshift"
+	"k8s.io/client-go/rest"
 )
 
 func NewKubernetesClientFactory(cfg config.EnvConfig, logger *slog.Logger) (KubernetesClientFactory, error) {
+	var k8sFactory KubernetesClientFactory
+	var err error
 	switch cfg.AuthMethod {
 
 	case config.AuthMethodInternal:
-		k8sFactory, err := NewStaticClientFactory(logger)
+		k8sFactory, err = NewStaticClientFactory(logger, CacheConfigFromEnv(cfg))
 		if err != nil {
 			return nil, fmt.Errorf("failed to create static client factory: %w", err)
 		}
-		return k8sFactory, nil
 
 	case config.AuthMethodUser:
-		k8sFactory := NewTokenClientFactory(logger, cfg)
-		return k8sFactory, nil
+		warnCacheUnused(cfg, logger)
+		k8sFactory = NewTokenClientFactory(logger, cfg)
+
+	case config.AuthMethodImpersonation:
+		warnCacheUnused(cfg, logger)
+		k8sFactory, err = NewImpersonationClientFactory(logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create impersonation client factory: %w", err)
+		}
 
 	default:
 		return nil, fmt.Errorf("invalid auth method: %q", cfg.AuthMethod)
 	}
+
+	if reviews := NewReviewCache(ReviewCacheConfigFromEnv(cfg)); reviews != nil {
+		logger.Info("caching authentication and access reviews", "subjectTTL", cfg.ReviewCacheSubjectTTL, "allowedTTL", cfg.ReviewCacheAllowedTTL, "deniedTTL", cfg.ReviewCacheDeniedTTL)
+		k8sFactory.(reviewCacheUser).UseReviewCache(reviews)
+	}
+	return k8sFactory, nil
+}
+
+// NewKubernetesClientFactoryForConfig creates the factory of cfg.AuthMethod for the API server
+// of restConfig, e.g. another cluster of a ClusterRegistry. It has its own review cache, as
+// the cache keys don't tell clusters apart, but no informer cache.
+func NewKubernetesClientFactoryForConfig(cfg config.EnvConfig, restConfig *rest.Config, logger *slog.Logger) (KubernetesClientFactory, error) {
+	var k8sFactory KubernetesClientFactory
+	var err error
+	switch cfg.AuthMethod {
+	case config.AuthMethodInternal:
+		k8sFactory, err = NewStaticClientFactoryForConfig(restConfig, logger, CacheConfig{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create static client factory: %w", err)
+		}
+	case config.AuthMethodUser:
+		k8sFactory = NewTokenClientFactoryForConfig(restConfig, logger, cfg)
+	case config.AuthMethodImpersonation:
+		k8sFactory = NewImpersonationClientFactoryForConfig(restConfig, logger)
+	default:
+		return nil, fmt.Errorf("invalid auth method: %q", cfg.AuthMethod)
+	}
+
+	if reviews := NewReviewCache(ReviewCacheConfigFromEnv(cfg)); reviews != nil {
+		k8sFactory.(reviewCacheUser).UseReviewCache(reviews)
+	}
+	return k8sFactory, nil
+}
+
+// reviewCacheUser is implemented by the factories whose clients memoize their reviews.
+type reviewCacheUser interface {
+	UseReviewCache(reviews *ReviewCache)
+	ReviewCache() *ReviewCache
 }
 
 // ─── STATIC FACTORY (INTERNAL) ──────────────────────────────────────────
@@ -44,24 +94,100 @@ type KubernetesClientFactory interface {
 type StaticClientFactory struct {
 	Logger *slog.Logger
 	Client KubernetesClientInterface
+	// cache is the informer cache shared by every request; nil when Client was provided directly.
+	cache *ResourceCache
+	// reviews memoizes the access reviews of Client; nil disables it.
+	reviews *ReviewCache
+}
+
+// NewStaticClientFactory creates the single backend client and, when cacheCfg enables any
+// resource, starts its informer cache. A cache that is slow to sync is only logged: reads go
+// to the API server until it catches up. Close stops the informers.
+func NewStaticClientFactory(logger *slog.Logger, cacheCfg CacheConfig) (KubernetesClientFactory, error) {
+	kubeconfig, err := helper.GetKubeconfig()
+	if err != nil {
+		logger.Error("failed to get kubeconfig", "error", err)
+		return nil, fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	return NewStaticClientFactoryForConfig(kubeconfig, logger, cacheCfg)
 }
 
-func NewStaticClientFactory(logger *slog.Logger) (KubernetesClientFactory, error) {
-	client, err := newInternalKubernetesClient(logger)
+// NewStaticClientFactoryForConfig is NewStaticClientFactory with the credentials of an
+// already-loaded rest.Config (e.g. of another cluster).
+func NewStaticClientFactoryForConfig(kubeconfig *rest.Config, logger *slog.Logger, cacheCfg CacheConfig) (KubernetesClientFactory, error) {
+	client, err := newInternalKubernetesClient(kubeconfig, logger, cacheCfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create service account client: %w", err)
 	}
+	if isOpenShift, _ := detectOpenShift(&client.SharedClientLogic); isOpenShift {
+		client.OpenShift = openshift.NewClient(client.Dynamic)
+	}
+	if cacheCfg.Enabled() {
+		logger.Info("starting informer cache", "resources", cacheCfg.Resources, "resyncPeriod", cacheCfg.ResyncPeriod)
+		if err := client.Cache.Start(); err != nil {
+			logger.Warn("informer cache not ready, serving reads from the API server until it syncs", "error", err)
+		}
+	}
 	return &StaticClientFactory{
 		Client: client,
 		Logger: logger,
+		cache:  client.Cache,
 	}, nil
 }
 
+// Close stops the informer cache, if any.
+func (f *StaticClientFactory) Close() error {
+	if f.cache != nil {
+		f.cache.Stop()
+	}
+	return nil
+}
+
+// ResourceCache returns the informer cache shared by every request, or nil.
+func (f *StaticClientFactory) ResourceCache() *ResourceCache {
+	return f.cache
+}
+
+// UseReviewCache memoizes the access reviews of the backend client with reviews. Call it
+// before serving requests.
+func (f *StaticClientFactory) UseReviewCache(reviews *ReviewCache) {
+	f.reviews = reviews
+	if client, ok := f.Client.(*InternalKubernetesClient); ok {
+		client.Reviews = reviews
+	}
+}
+
+// ReviewCache returns the review cache of the factory, or nil.
+func (f *StaticClientFactory) ReviewCache() *ReviewCache {
+	return f.reviews
+}
+
+// CacheConfigFromEnv returns the informer cache settings from the BFF configuration.
+func CacheConfigFromEnv(cfg config.EnvConfig) CacheConfig {
+	return CacheConfig{
+		Resources:    cfg.CacheResources,
+		ResyncPeriod: cfg.CacheResyncPeriod,
+	}
+}
+
+// warnCacheUnused logs that the informer cache is ignored: clients acting as the user can't
+// share a cache filled with the backend credentials.
+func warnCacheUnused(cfg config.EnvConfig, logger *slog.Logger) {
+	if len(cfg.CacheResources) > 0 {
+		logger.Warn("informer cache is only used with the internal auth method, ignoring cache resources", "authMethod", cfg.AuthMethod, "resources", cfg.CacheResources)
+	}
+}
+
 func (f *StaticClientFactory) GetClient(_ context.Context) (KubernetesClientInterface, error) {
 	return f.Client, nil
 }
 
 func (f *StaticClientFactory) ExtractRequestIdentity(httpHeader http.Header) (*RequestIdentity, error) {
+	return extractKubeflowIdentity(httpHeader)
+}
+
+// extractKubeflowIdentity reads the kubeflow-userid and kubeflow-groups headers.
+func extractKubeflowIdentity(httpHeader http.Header) (*RequestIdentity, error) {
 
 	userID := httpHeader.Get(constants.KubeflowUserIDHeader)
 	//`kubeflow-userid`: Contains the user's email address.
@@ -87,19 +213,24 @@ func (f *StaticClientFactory) ExtractRequestIdentity(httpHeader http.Header) (*R
 }
 
 func (f *StaticClientFactory) ValidateRequestIdentity(identity *RequestIdentity) error {
+	return validateKubeflowIdentity(identity)
+}
+
+func validateKubeflowIdentity(identity *RequestIdentity) error {
 	if identity == nil {
 		return errors.New("missing identity")
 	}
 	if identity.UserID == "" {
-		return errors.New("user ID (kubeflow-userid) required for internal authentication")
+		return errors.New("user ID (kubeflow-userid) required for internal or impersonation authentication")
 	}
 	return nil
 }
 
 //
 // ─── TOKEN FACTORY (USER TOKEN) ────────────────────────────────────────────────
-// uses a user-provided Bearer token for client creation.
-// each user has a separate client instance.
+// uses a user-provided Bearer token for client creation (token passthrough).
+// each request gets a separate client instance built from the caller's token,
+// so the BFF acts strictly as the user and never falls back to the service account.
 //
 
 type TokenClientFactory struct {
@@ -109,15 +240,79 @@ type TokenClientFactory struct {
 	// NewTokenKubernetesClientFn is the function used to create token-based Kubernetes clients.
 	// This can be overridden by downstream code to provide custom client creation logic.
 	NewTokenKubernetesClientFn func(token string, logger *slog.Logger) (KubernetesClientInterface, error)
+
+	// baseConfig holds the API server connection details (host, CA, proxy) shared by every
+	// per-request client. It is loaded once on first use and never carries credentials
+	// into the token clients.
+	baseConfig     *rest.Config
+	baseConfigErr  error
+	baseConfigOnce sync.Once
+
+	openShift openShiftDetector
+	// reviews memoizes the reviews of the token clients, keyed by token hash; nil disables it.
+	reviews *ReviewCache
 }
 
 func NewTokenClientFactory(logger *slog.Logger, cfg config.EnvConfig) KubernetesClientFactory {
-	return &TokenClientFactory{
-		Logger:                     logger,
-		Header:                     cfg.AuthTokenHeader,
-		Prefix:                     cfg.AuthTokenPrefix,
-		NewTokenKubernetesClientFn: NewTokenKubernetesClient,
+	return newTokenClientFactory(logger, cfg)
+}
+
+// NewTokenClientFactoryForConfig creates a token factory whose clients talk to the API server
+// of baseConfig (e.g. of another cluster) instead of the one of the kubeconfig. The
+// credentials of baseConfig are never used.
+func NewTokenClientFactoryForConfig(baseConfig *rest.Config, logger *slog.Logger, cfg config.EnvConfig) KubernetesClientFactory {
+	f := newTokenClientFactory(logger, cfg)
+	f.baseConfigOnce.Do(func() { f.baseConfig = baseConfig })
+	return f
+}
+
+func newTokenClientFactory(logger *slog.Logger, cfg config.EnvConfig) *TokenClientFactory {
+	f := &TokenClientFactory{
+		Logger: logger,
+		Header: cfg.AuthTokenHeader,
+		Prefix: cfg.AuthTokenPrefix,
+	}
+	f.NewTokenKubernetesClientFn = f.newTokenKubernetesClient
+	return f
+}
+
+// newTokenKubernetesClient is the default NewTokenKubernetesClientFn. It reuses the cached
+// base rest.Config so the kubeconfig is not re-read on every request.
+func (f *TokenClientFactory) newTokenKubernetesClient(token string, logger *slog.Logger) (KubernetesClientInterface, error) {
+	baseConfig, err := f.loadBaseConfig()
+	if err != nil {
+		logger.Error("failed to get kubeconfig", "error", err)
+		return nil, err
+	}
+	client, err := newTokenKubernetesClientForConfig(baseConfig, token, logger)
+	if err != nil {
+		return nil, err
 	}
+	f.openShift.setup(&client.SharedClientLogic)
+	client.Reviews = f.reviews
+	return client, nil
+}
+
+// UseReviewCache memoizes the reviews of the token clients created by the default
+// NewTokenKubernetesClientFn with reviews. Call it before serving requests.
+func (f *TokenClientFactory) UseReviewCache(reviews *ReviewCache) {
+	f.reviews = reviews
+}
+
+// ReviewCache returns the review cache of the factory, or nil.
+func (f *TokenClientFactory) ReviewCache() *ReviewCache {
+	return f.reviews
+}
+
+// loadBaseConfig loads the base rest.Config on first use.
+func (f *TokenClientFactory) loadBaseConfig() (*rest.Config, error) {
+	f.baseConfigOnce.Do(func() {
+		f.baseConfig, f.baseConfigErr = helper.GetKubeconfig()
+	})
+	if f.baseConfigErr != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig: %w", f.baseConfigErr)
+	}
+	return f.baseConfig, nil
 }
 
 func (f *TokenClientFactory) ExtractRequestIdentity(httpHeader http.Header) (*RequestIdentity, error) {
@@ -165,3 +360,75 @@ func (f *TokenClientFactory) GetClient(ctx context.Context) (KubernetesClientInt
 
 	return f.NewTokenKubernetesClientFn(identity.Token, f.Logger)
 }
+
+//
+// ─── IMPERSONATION FACTORY ─────────────────────────────────────────────────────
+// uses the credentials of the running backend, but impersonates the user (and groups)
+// taken from the kubeflow-userid/kubeflow-groups headers on every request.
+// the API server performs authorization as the end user, so no SAR filtering is needed
+// in the BFF itself.
+//
+
+type ImpersonationClientFactory struct {
+	Logger     *slog.Logger
+	BaseConfig *rest.Config
+
+	openShift openShiftDetector
+	// reviews memoizes the reviews of the impersonating clients; nil disables it.
+	reviews *ReviewCache
+}
+
+func NewImpersonationClientFactory(logger *slog.Logger) (KubernetesClientFactory, error) {
+	baseConfig, err := helper.GetKubeconfig()
+	if err != nil {
+		logger.Error("failed to get kubeconfig", "error", err)
+		return nil, fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	return NewImpersonationClientFactoryForConfig(baseConfig, logger), nil
+}
+
+// NewImpersonationClientFactoryForConfig creates an impersonation factory on top of an
+// already-loaded rest.Config (e.g. an envtest config).
+func NewImpersonationClientFactoryForConfig(baseConfig *rest.Config, logger *slog.Logger) KubernetesClientFactory {
+	return &ImpersonationClientFactory{
+		Logger:     logger,
+		BaseConfig: baseConfig,
+	}
+}
+
+func (f *ImpersonationClientFactory) ExtractRequestIdentity(httpHeader http.Header) (*RequestIdentity, error) {
+	return extractKubeflowIdentity(httpHeader)
+}
+
+func (f *ImpersonationClientFactory) ValidateRequestIdentity(identity *RequestIdentity) error {
+	return validateKubeflowIdentity(identity)
+}
+
+func (f *ImpersonationClientFactory) GetClient(ctx context.Context) (KubernetesClientInterface, error) {
+	identity, ok := ctx.Value(constants.RequestIdentityKey).(*RequestIdentity)
+	if !ok || identity == nil {
+		return nil, fmt.Errorf("missing RequestIdentity in context")
+	}
+	if err := f.ValidateRequestIdentity(identity); err != nil {
+		return nil, err
+	}
+
+	client, err := newImpersonatingKubernetesClient(f.BaseConfig, identity, f.Logger)
+	if err != nil {
+		return nil, err
+	}
+	f.openShift.setup(&client.SharedClientLogic)
+	client.Reviews = f.reviews
+	return client, nil
+}
+
+// UseReviewCache memoizes the reviews of the impersonating clients with reviews, keyed by
+// user and groups. Call it before serving requests.
+func (f *ImpersonationClientFactory) UseReviewCache(reviews *ReviewCache) {
+	f.reviews = reviews
+}
+
+// ReviewCache returns the review cache of the factory, or nil.
+func (f *ImpersonationClientFactory) ReviewCache() *ReviewCache {
+	return f.reviews
+}
@@ -0,0 +1,82 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// APIServerChecker is implemented by clients and client factories that can check that the
+// API server is reachable without a request identity. It backs the "kubernetes" health check.
+type APIServerChecker interface {
+	CheckAPIServer(ctx context.Context) error
+}
+
+// CacheSyncChecker is implemented by client factories with an informer cache.
+// It backs the "informer-cache" health check.
+type CacheSyncChecker interface {
+	CheckCacheSynced(ctx context.Context) error
+}
+
+var (
+	_ APIServerChecker = (*SharedClientLogic)(nil)
+	_ APIServerChecker = (*StaticClientFactory)(nil)
+	_ APIServerChecker = (*TokenClientFactory)(nil)
+	_ APIServerChecker = (*ImpersonationClientFactory)(nil)
+	_ CacheSyncChecker = (*StaticClientFactory)(nil)
+)
+
+// pingAPIServer requests /version, which every client may read (system:public-info-viewer).
+func pingAPIServer(ctx context.Context, clientset kubernetes.Interface) error {
+	if rc := clientset.Discovery().RESTClient(); rc != nil {
+		return rc.Get().AbsPath("/version").Do(ctx).Error()
+	}
+	// Fake clientsets have no REST client
+	_, err := clientset.Discovery().ServerVersion()
+	return err
+}
+
+func (kc *SharedClientLogic) CheckAPIServer(ctx context.Context) error {
+	return pingAPIServer(ctx, kc.Client)
+}
+
+// CheckAPIServer checks the API server with the backend client. Clients that can't be
+// checked (custom downstream clients) are reported healthy.
+func (f *StaticClientFactory) CheckAPIServer(ctx context.Context) error {
+	if checker, ok := f.Client.(APIServerChecker); ok {
+		return checker.CheckAPIServer(ctx)
+	}
+	return nil
+}
+
+// CheckCacheSynced fails until every informer of the cache has synced.
+func (f *StaticClientFactory) CheckCacheSynced(ctx context.Context) error {
+	if f.cache == nil {
+		return nil
+	}
+	return f.cache.CheckSynced(ctx)
+}
+
+// CheckAPIServer checks the API server anonymously: the factory holds no credentials of its own.
+func (f *TokenClientFactory) CheckAPIServer(ctx context.Context) error {
+	baseConfig, err := f.loadBaseConfig()
+	if err != nil {
+		return err
+	}
+	clientset, err := kubernetes.NewForConfig(rest.AnonymousClientConfig(baseConfig))
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	return pingAPIServer(ctx, clientset)
+}
+
+// CheckAPIServer checks the API server with the backend credentials, without impersonating anyone.
+func (f *ImpersonationClientFactory) CheckAPIServer(ctx context.Context) error {
+	clientset, err := kubernetes.NewForConfig(f.BaseConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	return pingAPIServer(ctx, clientset)
+}
@@ -3,31 +3,38 @@ package kubernetes
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"time"
 
-	helper "github.com/opendatahub-io/mod-arch-library/bff/internal/helpers"
 	authv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 )
 
 type InternalKubernetesClient struct {
 	SharedClientLogic
+	// RESTConfig is the configuration Client was created from, before the client settings
+	// and transport wrappers, used to impersonate users (see Impersonate). When nil, the
+	// clients impersonating users can't be created.
+	RESTConfig *rest.Config
 }
 
 // newInternalKubernetesClient creates a Kubernetes client
-// using the credentials of the running backend to create a single instance of the client
-// If running inside the cluster, it uses the pod's service account.
-// If running locally (e.g. for development), it uses the current user's kubeconfig context.
-func newInternalKubernetesClient(logger *slog.Logger) (KubernetesClientInterface, error) {
-	// Get kubeconfig
-	kubeconfig, err := helper.GetKubeconfig()
-	if err != nil {
-		logger.Error("failed to get kubeconfig", "error", err)
-		return nil, fmt.Errorf("failed to get kubeconfig: %w", err)
-	}
+// using the credentials of kubeconfig to create a single instance of the client
+// (see NewStaticClientFactory for the default kubeconfig). kubeconfig is not modified.
+// The resources in cacheCfg are served from shared informers; the caller starts and stops the cache.
+func newInternalKubernetesClient(kubeconfig *rest.Config, logger *slog.Logger, cacheCfg CacheConfig) (*InternalKubernetesClient, error) {
+	baseConfig := rest.CopyConfig(kubeconfig)
+	kubeconfig = rest.CopyConfig(kubeconfig)
+	applyClientSettings(kubeconfig)
+	applyTransportWrappers(kubeconfig)
 
 	// Create client
 	clientset, err := kubernetes.NewForConfig(kubeconfig)
@@ -36,12 +43,26 @@ func newInternalKubernetesClient(logger *slog.Logger) (KubernetesClientInterface
 		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
 	}
 
+	dynamicClient, err := dynamic.NewForConfig(kubeconfig)
+	if err != nil {
+		logger.Error("failed to create dynamic Kubernetes client", "error", err)
+		return nil, fmt.Errorf("failed to create dynamic Kubernetes client: %w", err)
+	}
+
+	resourceCache, err := NewResourceCache(clientset, cacheCfg, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create informer cache: %w", err)
+	}
+
 	return &InternalKubernetesClient{
 		SharedClientLogic: SharedClientLogic{
-			Client: clientset,
-			Logger: logger,
-			Token:  NewBearerToken(kubeconfig.BearerToken),
+			Client:  clientset,
+			Dynamic: dynamicClient,
+			Cache:   resourceCache,
+			Logger:  logger,
+			Token:   NewBearerToken(kubeconfig.BearerToken),
 		},
+		RESTConfig: baseConfig,
 	}, nil
 }
 
@@ -53,139 +74,46 @@ func (kc *InternalKubernetesClient) GetNamespaces(ctx context.Context, identity
 
 	// Optimization 1: Early exit for cluster admins
 	// Check if user is cluster-admin first to avoid individual SAR checks
-	isAdmin, err := kc.IsClusterAdmin(identity)
+	isAdmin, err := kc.IsClusterAdmin(ctx, identity)
 	if err != nil {
 		kc.Logger.Warn("failed to check cluster admin status", "user", identity.UserID, "error", err)
 		// Continue with individual checks if cluster admin check fails
 	} else if isAdmin {
-		namespaceList, err := kc.Client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		namespaces, err := kc.Reader().ListNamespaces(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to list namespaces: %w", err)
 		}
-		return namespaceList.Items, nil
+		return namespaces, nil
 	}
 
-	// List namespaces
-	namespaceList, err := kc.Client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	// List namespaces (from the informer cache when enabled)
+	namespaces, err := kc.Reader().ListNamespaces(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list namespaces: %w", err)
 	}
 
 	// Optimization 2: Worker pool for parallel SAR processing
-	// Use a fixed number of workers to process namespace checks, providing better resource control
-	const numWorkers = 10 // Fixed number of workers to avoid resource overhead
-
-	type sarJob struct {
-		namespace corev1.Namespace
-	}
-
-	type sarResult struct {
-		namespace corev1.Namespace
-		allowed   bool
-		err       error
-	}
-
-	// Create job and result channels
-	jobs := make(chan sarJob, len(namespaceList.Items))
-	results := make(chan sarResult, len(namespaceList.Items))
-
-	// Start worker pool
-	for w := 0; w < numWorkers; w++ {
-		go func() {
-			for {
-				select {
-				case <-ctx.Done():
-					// Respect context cancellation
-					return
-				case job, ok := <-jobs:
-					if !ok {
-						// Jobs channel closed, exit worker
-						return
-					}
-
-					// Perform SAR check
-					sar := &authv1.SubjectAccessReview{
-						Spec: authv1.SubjectAccessReviewSpec{
-							User:   identity.UserID,
-							Groups: identity.Groups,
-							ResourceAttributes: &authv1.ResourceAttributes{
-								Verb:      "get",
-								Resource:  "namespaces",
-								Namespace: job.namespace.Name,
-							},
-						},
-					}
-
-					response, err := kc.Client.AuthorizationV1().SubjectAccessReviews().Create(ctx, sar, metav1.CreateOptions{})
-
-					select {
-					case <-ctx.Done():
-						// Context cancelled, exit worker
-						return
-					case results <- sarResult{
-						namespace: job.namespace,
-						allowed:   err == nil && response.Status.Allowed,
-						err:       err,
-					}:
-						// Result sent successfully
-					}
-				}
-			}
-		}()
-	}
-
-	// Send jobs to workers
-	go func() {
-		defer close(jobs)
-		for _, ns := range namespaceList.Items {
-			select {
-			case <-ctx.Done():
-				// Context cancelled, stop sending jobs
-				return
-			case jobs <- sarJob{namespace: ns}:
-				// Job sent successfully
-			}
-		}
-	}()
-
-	// Collect results
-	var allowed []corev1.Namespace
-	errorCount := 0
-	processed := 0
-
-	for processed < len(namespaceList.Items) {
-		select {
-		case <-ctx.Done():
-			// Context cancelled, return what we have so far
-			kc.Logger.Warn("context cancelled during namespace access checks",
-				"user", identity.UserID,
-				"processed", processed,
-				"total", len(namespaceList.Items))
-			return allowed, ctx.Err()
-		case result := <-results:
-			processed++
-			if result.err != nil {
-				kc.Logger.Error("failed SAR for namespace", "namespace", result.namespace.Name, "error", result.err)
-				errorCount++
-				continue
-			}
-			if result.allowed {
-				allowed = append(allowed, result.namespace)
-			}
-		}
+	allowed, err := filterAccessibleNamespaces(ctx, kc.Logger, namespaces, func(ctx context.Context, namespace string) (bool, error) {
+		return kc.CanAccess(ctx, identity, "get", "", "namespaces", namespace)
+	})
+	if err != nil {
+		kc.Logger.Warn("context cancelled during namespace access checks", "user", identity.UserID, "error", err)
+		return allowed, err
 	}
 
 	kc.Logger.Debug("namespace access check completed",
 		"user", identity.UserID,
-		"total_namespaces", len(namespaceList.Items),
-		"accessible_namespaces", len(allowed),
-		"errors", errorCount)
+		"total_namespaces", len(namespaces),
+		"accessible_namespaces", len(allowed))
 
 	return allowed, nil
 }
 
-func (kc *InternalKubernetesClient) IsClusterAdmin(identity *RequestIdentity) (bool, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+func (kc *InternalKubernetesClient) IsClusterAdmin(ctx context.Context, identity *RequestIdentity) (bool, error) {
+	if identity.Guest {
+		return false, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
 	crbList, err := kc.Client.RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{})
@@ -209,7 +137,128 @@ func (kc *InternalKubernetesClient) IsClusterAdmin(identity *RequestIdentity) (b
 	return false, nil
 }
 
-func (kc *InternalKubernetesClient) GetUser(identity *RequestIdentity) (string, error) {
+// CanAccess performs a SubjectAccessReview on behalf of the identity carried in the
+// kubeflow-userid/kubeflow-groups headers, memoized by the review cache.
+func (kc *InternalKubernetesClient) CanAccess(ctx context.Context, identity *RequestIdentity, verb, group, resource, namespace string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	attrs := resourceAttributes(verb, group, resource, namespace)
+	allowed, err := kc.Reviews.access(identityReviewKey(identity), attrs, func() (bool, error) {
+		sar := &authv1.SubjectAccessReview{
+			Spec: authv1.SubjectAccessReviewSpec{
+				User:               identity.UserID,
+				Groups:             identity.Groups,
+				ResourceAttributes: attrs,
+			},
+		}
+		resp, err := kc.Client.AuthorizationV1().SubjectAccessReviews().Create(ctx, sar, metav1.CreateOptions{})
+		if err != nil {
+			return false, err
+		}
+		return resp.Status.Allowed, nil
+	})
+	if err != nil {
+		kc.Logger.Error("SAR failed", "user", identity.UserID, "verb", verb, "resource", resource, "namespace", namespace, "error", err)
+		return false, fmt.Errorf("failed to perform SubjectAccessReview: %w", err)
+	}
+
+	return allowed, nil
+}
+
+// GetRules runs the SelfSubjectRulesReview with the client of Impersonate, as rules reviews
+// only exist for the caller's own credentials: the backend credentials need the impersonate
+// verb on users and groups.
+func (kc *InternalKubernetesClient) GetRules(ctx context.Context, identity *RequestIdentity, namespace string) (*authv1.SubjectRulesReviewStatus, error) {
+	if identity == nil {
+		return nil, fmt.Errorf("missing identity for the rules review")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	client, err := kc.impersonate(identity)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := selfRulesReview(ctx, client.Client, namespace)
+	if err != nil {
+		kc.Logger.Error("rules review failed", "user", identity.UserID, "namespace", namespace, "error", err)
+		return nil, err
+	}
+	return status, nil
+}
+
+// Impersonate returns a client calling the API server with the backend credentials as the
+// identity, configured like the clients of the "impersonation" auth method.
+func (kc *InternalKubernetesClient) Impersonate(identity *RequestIdentity) (KubernetesClientInterface, error) {
+	if identity == nil {
+		return nil, fmt.Errorf("missing identity to impersonate")
+	}
+	client, err := kc.impersonate(identity)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+func (kc *InternalKubernetesClient) impersonate(identity *RequestIdentity) (*TokenKubernetesClient, error) {
+	if kc.RESTConfig == nil {
+		return nil, fmt.Errorf("impersonating users requires the REST config of the client")
+	}
+	return newImpersonatingKubernetesClient(kc.RESTConfig, identity, kc.Logger)
+}
+
+// WatchResource runs a SubjectAccessReview for the "watch" verb on behalf of the identity and,
+// when allowed, opens the watch with the backend credentials.
+func (kc *InternalKubernetesClient) WatchResource(ctx context.Context, identity *RequestIdentity, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (watch.Interface, error) {
+	allowed, err := kc.CanAccess(ctx, identity, "watch", gvr.Group, gvr.Resource, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, k8serrors.NewForbidden(gvr.GroupResource(), "", fmt.Errorf("user %q cannot watch %s in namespace %q", identity.UserID, gvr.Resource, namespace))
+	}
+
+	return kc.watchResource(ctx, gvr, namespace, opts)
+}
+
+// SharedWatch opens the watch with the backend credentials.
+func (kc *InternalKubernetesClient) SharedWatch(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (watch.Interface, error) {
+	return kc.watchResource(ctx, gvr, namespace, opts)
+}
+
+// StreamPodLogs runs a SubjectAccessReview for "get" on pods/log on behalf of the identity and,
+// when allowed, opens the stream with the backend credentials.
+func (kc *InternalKubernetesClient) StreamPodLogs(ctx context.Context, identity *RequestIdentity, namespace, pod string, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+	allowed, err := kc.CanAccess(ctx, identity, "get", "", "pods/log", namespace)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, k8serrors.NewForbidden(schema.GroupResource{Resource: "pods/log"}, pod, fmt.Errorf("user %q cannot get the logs of pods in namespace %q", identity.UserID, namespace))
+	}
+
+	return kc.streamPodLogs(ctx, namespace, pod, opts)
+}
+
+func (kc *InternalKubernetesClient) GetUser(_ context.Context, identity *RequestIdentity) (string, error) {
 	// On internal client, we can use the identity from request directly
 	return identity.UserID, nil
 }
+
+// GetGroups returns the groups of the kubeflow-groups header, plus on OpenShift the Groups
+// listing the user when the backend credentials may list them.
+func (kc *InternalKubernetesClient) GetGroups(ctx context.Context, identity *RequestIdentity) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if kc.OpenShift == nil {
+		return mergeGroups(identity.Groups), nil
+	}
+	openShiftGroups, err := kc.OpenShift.GroupsOf(ctx, identity.UserID)
+	if err != nil {
+		kc.Logger.Debug("failed to get OpenShift groups", "user", identity.UserID, "error", err)
+	}
+	return mergeGroups(identity.Groups, openShiftGroups), nil
+}
@@ -10,6 +10,7 @@ import (
 
 	kubernetes2 "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -74,7 +75,7 @@ func SetupEnvTest(input TestEnvInput) (*envtest.Environment, kubernetes.Interfac
 	}
 
 	// bootstrap resources
-	err = setupMock(clientset, input.Ctx)
+	err = SeedMockData(input.Ctx, clientset)
 	if err != nil {
 		input.Logger.Error("failed to setup mock data", slog.String("error", err.Error()))
 		input.Cancel()
@@ -84,7 +85,8 @@ func SetupEnvTest(input TestEnvInput) (*envtest.Environment, kubernetes.Interfac
 	return testEnv, clientset, nil
 }
 
-func setupMock(mockK8sClient kubernetes.Interface, ctx context.Context) error {
+// SeedMockData creates the default namespaces, services and RBAC bindings for DefaultTestUsers.
+func SeedMockData(ctx context.Context, mockK8sClient kubernetes.Interface) error {
 
 	err := createNamespace(mockK8sClient, ctx, "kubeflow")
 	if err != nil {
@@ -390,6 +392,24 @@ func createService(k8sClient kubernetes.Interface, ctx context.Context, name str
 		return fmt.Errorf("failed to create service: %w", err)
 	}
 
+	// envtest runs no controllers, so publish the endpoint the EndpointSlice controller would
+	ready := true
+	endpointSlice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-mock",
+			Namespace: namespace,
+			Labels:    map[string]string{discoveryv1.LabelServiceName: name},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints: []discoveryv1.Endpoint{
+			{Addresses: []string{clusterIP}, Conditions: discoveryv1.EndpointConditions{Ready: &ready}},
+		},
+	}
+	_, err = k8sClient.DiscoveryV1().EndpointSlices(namespace).Create(ctx, endpointSlice, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create endpointslice: %w", err)
+	}
+
 	return nil
 }
 
@@ -5,6 +5,7 @@ import (
 
 	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 )
 
 type InternalKubernetesClientMock struct {
@@ -12,15 +13,22 @@ type InternalKubernetesClientMock struct {
 }
 
 // newMockedInternalKubernetesClientFromClientset creates a mock from existing envtest clientset
-func newMockedInternalKubernetesClientFromClientset(clientset kubernetes.Interface, logger *slog.Logger) k8s.KubernetesClientInterface {
+func newMockedInternalKubernetesClientFromClientset(clientset kubernetes.Interface, restConfig *rest.Config, logger *slog.Logger) (k8s.KubernetesClientInterface, error) {
+	dynamicClient, err := newDynamicClient(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	return &InternalKubernetesClientMock{
 		InternalKubernetesClient: &k8s.InternalKubernetesClient{
 			SharedClientLogic: k8s.SharedClientLogic{
-				Client: clientset,
-				Logger: logger,
+				Client:  clientset,
+				Dynamic: dynamicClient,
+				Logger:  logger,
 			},
+			RESTConfig: restConfig,
 		},
-	}
+	}, nil
 }
 
 // GetServiceDetails overrides to simulate ClusterIP for localhost access
@@ -30,5 +38,3 @@ func newMockedInternalKubernetesClientFromClientset(clientset kubernetes.Interfa
 func (m *InternalKubernetesClientMock) BearerToken() (string, error) {
 	return "FAKE-BEARER-TOKEN", nil
 }
-
-// GetGroups removed in minimal starter.
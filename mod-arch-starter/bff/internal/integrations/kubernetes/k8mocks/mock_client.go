@@ -0,0 +1,704 @@
+package k8mocks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	jsonpatch "gopkg.in/evanphx/json-patch.v4"
+	authv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/managedfields"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// ─── IN-MEMORY MOCK CLIENT (no cluster, no envtest) ──────────────────────────────
+//
+// MockKubernetesClient answers every KubernetesClientInterface call from static fixtures.
+// It lets frontend developers run the BFF without a cluster or the envtest binaries.
+// Identities are matched against fixture users by token first, then by user ID.
+
+// MockUser is a fixture user. Namespaces lists the namespaces the user can access;
+// cluster admins can access every namespace.
+type MockUser struct {
+	UserName     string   `json:"userName"`
+	Token        string   `json:"token,omitempty"`
+	Groups       []string `json:"groups,omitempty"`
+	ClusterAdmin bool     `json:"clusterAdmin,omitempty"`
+	Namespaces   []string `json:"namespaces,omitempty"`
+}
+
+// MockService is a fixture Service. It gets the http-api (8080) and grpc-api (9090) ports of the
+// envtest services, plus an EndpointSlice with one endpoint that is ready unless Unavailable is set.
+type MockService struct {
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace"`
+	DisplayName string            `json:"displayName,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Unavailable bool              `json:"unavailable,omitempty"`
+}
+
+// MockEvent is a fixture Event about the object Kind/Name in Namespace. Type defaults to Normal
+// and Count to 1. The fixture events were last seen a minute apart, in order, the last one an
+// hour before the client was created.
+type MockEvent struct {
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Type      string `json:"type,omitempty"`
+	Reason    string `json:"reason"`
+	Message   string `json:"message,omitempty"`
+	Count     int32  `json:"count,omitempty"`
+}
+
+// MockFixtures is the data served by MockKubernetesClient.
+type MockFixtures struct {
+	Users      []MockUser    `json:"users"`
+	Namespaces []string      `json:"namespaces"`
+	Services   []MockService `json:"services,omitempty"`
+	Events     []MockEvent   `json:"events,omitempty"`
+}
+
+// DefaultMockFixtures mirrors the data seeded into envtest by SetupEnvTest,
+// so both mock backends behave the same for the default test users.
+func DefaultMockFixtures() MockFixtures {
+	return MockFixtures{
+		Namespaces: []string{"kubeflow", "dora-namespace", "bella-namespace", "bento-namespace"},
+		Services: []MockService{
+			{Name: "mod-arch", Namespace: "kubeflow", DisplayName: "Mod Arch", Description: "Mod Arch Description", Labels: map[string]string{"component": "mod-arch"}},
+			{Name: "mod-arch-one", Namespace: "kubeflow", DisplayName: "Mod Arch One", Description: "Mod Arch One description", Labels: map[string]string{"component": "mod-arch"}},
+			{Name: "mod-arch-dora", Namespace: "dora-namespace", DisplayName: "Mod Arch Dora", Description: "Mod Arch Dora description", Labels: map[string]string{"component": "mod-arch"}},
+			{Name: "mod-arch-bella", Namespace: "bella-namespace", DisplayName: "Mod Arch Bella", Description: "Mod Arch Bella description", Labels: map[string]string{"component": "mod-arch"}},
+			{Name: "non-mod-arch", Namespace: "kubeflow", DisplayName: "Not a Mod Arch", Description: "Not a Mod Arch Bella description"},
+		},
+		Events: []MockEvent{
+			{Namespace: "dora-namespace", Kind: "Deployment", Name: "mod-arch-dora", Reason: "ScalingReplicaSet", Message: "Scaled up replica set mod-arch-dora-7c9d8 to 1"},
+			{Namespace: "dora-namespace", Kind: "Pod", Name: "mod-arch-dora-7c9d8-x2k4p", Reason: "Scheduled", Message: "Successfully assigned dora-namespace/mod-arch-dora-7c9d8-x2k4p to node-1"},
+			{Namespace: "dora-namespace", Kind: "Pod", Name: "mod-arch-dora-7c9d8-x2k4p", Type: corev1.EventTypeWarning, Reason: "BackOff", Message: "Back-off restarting failed container server", Count: 5},
+			{Namespace: "bella-namespace", Kind: "Deployment", Name: "mod-arch-bella", Reason: "ScalingReplicaSet", Message: "Scaled up replica set mod-arch-bella-5f6b4 to 1"},
+		},
+		Users: []MockUser{
+			{
+				UserName:     DefaultTestUsers[0].UserName,
+				Token:        DefaultTestUsers[0].Token,
+				Groups:       DefaultTestUsers[0].Groups,
+				ClusterAdmin: true,
+			},
+			{
+				UserName:   DefaultTestUsers[1].UserName,
+				Token:      DefaultTestUsers[1].Token,
+				Groups:     DefaultTestUsers[1].Groups,
+				Namespaces: []string{"dora-namespace"},
+			},
+			{
+				UserName:   DefaultTestUsers[2].UserName,
+				Token:      DefaultTestUsers[2].Token,
+				Groups:     DefaultTestUsers[2].Groups,
+				Namespaces: []string{"bella-namespace"},
+			},
+		},
+	}
+}
+
+// LoadMockFixtures reads fixtures from a JSON file.
+func LoadMockFixtures(path string) (MockFixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return MockFixtures{}, fmt.Errorf("failed to read mock fixtures: %w", err)
+	}
+	var fixtures MockFixtures
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return MockFixtures{}, fmt.Errorf("failed to parse mock fixtures %s: %w", path, err)
+	}
+	return fixtures, nil
+}
+
+type MockKubernetesClient struct {
+	fixtures MockFixtures
+	logger   *slog.Logger
+	reader   k8s.ResourceReader
+
+	dynamicMu sync.Mutex
+	dynamic   map[schema.GroupVersionResource]*dynamicfake.FakeDynamicClient
+	// resourceVersion is the last resourceVersion of the dynamic store
+	resourceVersion atomic.Uint64
+}
+
+var _ k8s.KubernetesClientInterface = (*MockKubernetesClient)(nil)
+
+func NewMockKubernetesClient(fixtures MockFixtures, logger *slog.Logger) *MockKubernetesClient {
+	objects := make([]runtime.Object, 0, len(fixtures.Namespaces)+2*len(fixtures.Services)+len(fixtures.Events))
+	for _, name := range fixtures.Namespaces {
+		objects = append(objects, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}})
+	}
+	for _, svc := range fixtures.Services {
+		objects = append(objects, svc.objects()...)
+	}
+	last := time.Now().Add(-time.Hour)
+	for i, event := range fixtures.Events {
+		objects = append(objects, event.object(i, last.Add(time.Duration(i-len(fixtures.Events)+1)*time.Minute)))
+	}
+	// A live reader over a fake clientset holding the fixture namespaces, services and events.
+	reader, _ := k8s.NewResourceCache(fake.NewClientset(objects...), k8s.CacheConfig{}, logger)
+
+	return &MockKubernetesClient{
+		fixtures: fixtures,
+		logger:   logger,
+		reader:   reader,
+		dynamic:  map[schema.GroupVersionResource]*dynamicfake.FakeDynamicClient{},
+	}
+}
+
+// object returns the i-th fixture event, last seen at lastSeen.
+func (e MockEvent) object(i int, lastSeen time.Time) *corev1.Event {
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.mock%d", e.Name, i),
+			Namespace: e.Namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{Kind: e.Kind, Namespace: e.Namespace, Name: e.Name},
+		Type:           e.Type,
+		Reason:         e.Reason,
+		Message:        e.Message,
+		Count:          e.Count,
+		Source:         corev1.EventSource{Component: "mock"},
+		FirstTimestamp: metav1.NewTime(lastSeen.Add(-time.Duration(max(e.Count-1, 0)) * time.Minute)),
+		LastTimestamp:  metav1.NewTime(lastSeen),
+	}
+	if event.Type == "" {
+		event.Type = corev1.EventTypeNormal
+	}
+	if event.Count == 0 {
+		event.Count = 1
+	}
+	return event
+}
+
+// objects returns the Service and its EndpointSlice.
+func (s MockService) objects() []runtime.Object {
+	annotations := map[string]string{}
+	if s.DisplayName != "" {
+		annotations["displayName"] = s.DisplayName
+	}
+	if s.Description != "" {
+		annotations["description"] = s.Description
+	}
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: s.Name, Namespace: s.Namespace, Labels: s.Labels, Annotations: annotations},
+		Spec: corev1.ServiceSpec{
+			Type: corev1.ServiceTypeClusterIP,
+			Ports: []corev1.ServicePort{
+				{Name: "http-api", Port: 8080, Protocol: corev1.ProtocolTCP, AppProtocol: strPtr("http")},
+				{Name: "grpc-api", Port: 9090, Protocol: corev1.ProtocolTCP, AppProtocol: strPtr("grpc")},
+			},
+		},
+	}
+	ready := !s.Unavailable
+	endpointSlice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      s.Name + "-mock",
+			Namespace: s.Namespace,
+			Labels:    map[string]string{discoveryv1.LabelServiceName: s.Name},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints: []discoveryv1.Endpoint{
+			{Addresses: []string{"10.0.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: &ready}},
+		},
+	}
+	return []runtime.Object{service, endpointSlice}
+}
+
+func (m *MockKubernetesClient) findUser(identity *k8s.RequestIdentity) (*MockUser, error) {
+	if identity == nil {
+		return nil, fmt.Errorf("missing identity")
+	}
+	for i := range m.fixtures.Users {
+		u := &m.fixtures.Users[i]
+		if identity.Token != "" && u.Token == identity.Token {
+			return u, nil
+		}
+	}
+	for i := range m.fixtures.Users {
+		u := &m.fixtures.Users[i]
+		if identity.UserID != "" && u.UserName == identity.UserID {
+			return u, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown mock user")
+}
+
+func (m *MockKubernetesClient) canAccessNamespace(user *MockUser, namespace string) bool {
+	return user.ClusterAdmin || slices.Contains(user.Namespaces, namespace)
+}
+
+func (m *MockKubernetesClient) GetNamespaces(_ context.Context, identity *k8s.RequestIdentity) ([]corev1.Namespace, error) {
+	user, err := m.findUser(identity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	names := slices.Clone(m.fixtures.Namespaces)
+	sort.Strings(names)
+
+	namespaces := []corev1.Namespace{}
+	for _, name := range names {
+		if m.canAccessNamespace(user, name) {
+			namespaces = append(namespaces, corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}})
+		}
+	}
+	return namespaces, nil
+}
+
+func (m *MockKubernetesClient) IsClusterAdmin(_ context.Context, identity *k8s.RequestIdentity) (bool, error) {
+	user, err := m.findUser(identity)
+	if err != nil {
+		return false, fmt.Errorf("failed to verify cluster-admin permissions: %w", err)
+	}
+	return user.ClusterAdmin, nil
+}
+
+func (m *MockKubernetesClient) GetUser(_ context.Context, identity *k8s.RequestIdentity) (string, error) {
+	user, err := m.findUser(identity)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user identity: %w", err)
+	}
+	return user.UserName, nil
+}
+
+// GetGroups returns the fixture groups of the user, plus those the identity carries.
+func (m *MockKubernetesClient) GetGroups(_ context.Context, identity *k8s.RequestIdentity) ([]string, error) {
+	user, err := m.findUser(identity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user groups: %w", err)
+	}
+	groups := []string{}
+	for _, group := range slices.Concat(user.Groups, identity.Groups) {
+		if !slices.Contains(groups, group) {
+			groups = append(groups, group)
+		}
+	}
+	return groups, nil
+}
+
+// CanAccess grants cluster admins everything and other users any verb inside their fixture
+// namespaces. Cluster-scoped access is denied for non-admins.
+func (m *MockKubernetesClient) CanAccess(_ context.Context, identity *k8s.RequestIdentity, verb, group, resource, namespace string) (bool, error) {
+	user, err := m.findUser(identity)
+	if err != nil {
+		return false, fmt.Errorf("failed to perform SubjectAccessReview: %w", err)
+	}
+	allowed := user.ClusterAdmin || (namespace != "" && m.canAccessNamespace(user, namespace))
+	m.logger.Debug("mock access review", "user", user.UserName, "verb", verb, "group", group, "resource", resource, "namespace", namespace, "allowed", allowed)
+	return allowed, nil
+}
+
+// GetRules mirrors CanAccess: cluster admins, and other users inside their fixture
+// namespaces, may do everything.
+func (m *MockKubernetesClient) GetRules(_ context.Context, identity *k8s.RequestIdentity, namespace string) (*authv1.SubjectRulesReviewStatus, error) {
+	user, err := m.findUser(identity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to perform SelfSubjectRulesReview: %w", err)
+	}
+	status := &authv1.SubjectRulesReviewStatus{ResourceRules: []authv1.ResourceRule{}}
+	if user.ClusterAdmin || m.canAccessNamespace(user, namespace) {
+		status.ResourceRules = append(status.ResourceRules, authv1.ResourceRule{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}})
+	}
+	return status, nil
+}
+
+// WatchResource authorizes like CanAccess and replays the accessible fixture namespaces as
+// ADDED events when namespaces are watched. Other resources are not replayed, so any other
+// watch stays open without events until ctx is done.
+func (m *MockKubernetesClient) WatchResource(ctx context.Context, identity *k8s.RequestIdentity, gvr schema.GroupVersionResource, namespace string, _ metav1.ListOptions) (watch.Interface, error) {
+	allowed, err := m.CanAccess(ctx, identity, "watch", gvr.Group, gvr.Resource, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, k8serrors.NewForbidden(gvr.GroupResource(), "", fmt.Errorf("mock user cannot watch %s in namespace %q", gvr.Resource, namespace))
+	}
+
+	var objects []runtime.Object
+	if gvr.Group == "" && gvr.Resource == "namespaces" {
+		namespaces, err := m.GetNamespaces(ctx, identity)
+		if err != nil {
+			return nil, err
+		}
+		for i := range namespaces {
+			namespaces[i].ResourceVersion = "1"
+			objects = append(objects, &namespaces[i])
+		}
+	}
+
+	fw := watch.NewFakeWithChanSize(len(objects), false)
+	for _, obj := range objects {
+		fw.Add(obj)
+	}
+	go func() {
+		<-ctx.Done()
+		fw.Stop()
+	}()
+	return fw, nil
+}
+
+// SharedWatch watches with the credentials of the mock BFF: every fixture namespace is
+// replayed as ADDED events when namespaces are watched, other resources are watched in the
+// dynamic store.
+func (m *MockKubernetesClient) SharedWatch(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (watch.Interface, error) {
+	if gvr.Group != "" || gvr.Resource != "namespaces" {
+		resource, err := m.DynamicResource(gvr)
+		if err != nil {
+			return nil, err
+		}
+		return resource.Namespace(namespace).Watch(ctx, opts)
+	}
+
+	names := slices.Clone(m.fixtures.Namespaces)
+	sort.Strings(names)
+	fw := watch.NewFakeWithChanSize(len(names), false)
+	for _, name := range names {
+		fw.Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, ResourceVersion: "1"}})
+	}
+	go func() {
+		<-ctx.Done()
+		fw.Stop()
+	}()
+	return fw, nil
+}
+
+// mockLogLines is the number of lines in the log of every mock pod.
+const mockLogLines = 20
+
+// StreamPodLogs authorizes like CanAccess and serves a generated log for any pod name, honoring
+// TailLines and Timestamps. In follow mode the stream stays open after the last line until ctx
+// is done.
+func (m *MockKubernetesClient) StreamPodLogs(ctx context.Context, identity *k8s.RequestIdentity, namespace, pod string, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+	allowed, err := m.CanAccess(ctx, identity, "get", "", "pods/log", namespace)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, k8serrors.NewForbidden(schema.GroupResource{Resource: "pods/log"}, pod, fmt.Errorf("mock user cannot get the logs of pods in namespace %q", namespace))
+	}
+
+	container := opts.Container
+	if container == "" {
+		container = pod
+	}
+	first := 1
+	if opts.TailLines != nil {
+		first = max(1, mockLogLines-int(*opts.TailLines)+1)
+	}
+	var log strings.Builder
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for i := first; i <= mockLogLines; i++ {
+		if opts.Timestamps {
+			log.WriteString(start.Add(time.Duration(i)*time.Second).Format(time.RFC3339Nano) + " ")
+		}
+		fmt.Fprintf(&log, "mock log line %d of %s/%s\n", i, pod, container)
+	}
+
+	if !opts.Follow {
+		return io.NopCloser(strings.NewReader(log.String())), nil
+	}
+	r, w := io.Pipe()
+	go func() {
+		if _, err := io.WriteString(w, log.String()); err != nil {
+			return
+		}
+		<-ctx.Done()
+		_ = w.Close()
+	}()
+	return r, nil
+}
+
+// DynamicResource serves gvr from an in-memory store that starts empty and keeps what is
+// created for as long as the client lives. Like the internal client, it doesn't authorize.
+// Creates and updates set a new resourceVersion, and updates of a stale one conflict, as in
+// the API server. Dry runs are checked against the store and not stored, and applies merged
+// with the field managers of the API server (see mockResource).
+func (m *MockKubernetesClient) DynamicResource(gvr schema.GroupVersionResource) (dynamic.NamespaceableResourceInterface, error) {
+	m.dynamicMu.Lock()
+	defer m.dynamicMu.Unlock()
+
+	client, ok := m.dynamic[gvr]
+	if !ok {
+		client = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{gvr: gvr.Resource + "List"})
+		client.PrependReactor("create", "*", m.versionObject(client))
+		client.PrependReactor("update", "*", m.versionObject(client))
+		if gvr == serviceAccountsGVR {
+			client.PrependReactor("create", "serviceaccounts", issueToken(client))
+		}
+		m.dynamic[gvr] = client
+	}
+	return &mockResource{ResourceInterface: client.Resource(gvr), resource: client.Resource(gvr), gvr: gvr}, nil
+}
+
+// versionObject sets the next resourceVersion on the object written by an action, after
+// checking the resourceVersion of an update against the stored object.
+func (m *MockKubernetesClient) versionObject(client *dynamicfake.FakeDynamicClient) k8stesting.ReactionFunc {
+	return func(action k8stesting.Action) (bool, runtime.Object, error) {
+		obj, ok := action.(interface{ GetObject() runtime.Object })
+		if !ok {
+			return false, nil, nil
+		}
+		accessor, err := meta.Accessor(obj.GetObject())
+		if err != nil {
+			return false, nil, nil
+		}
+		if update, ok := action.(k8stesting.UpdateAction); ok && accessor.GetResourceVersion() != "" {
+			stored, err := client.Tracker().Get(update.GetResource(), update.GetNamespace(), accessor.GetName())
+			if err == nil {
+				if current, err := meta.Accessor(stored); err == nil && current.GetResourceVersion() != accessor.GetResourceVersion() {
+					resource := update.GetResource().GroupResource()
+					return true, nil, k8serrors.NewConflict(resource, accessor.GetName(), fmt.Errorf("the object has been modified; please apply your changes to the latest version and try again"))
+				}
+			}
+		}
+		accessor.SetResourceVersion(strconv.FormatUint(m.resourceVersion.Add(1), 10))
+		return false, nil, nil
+	}
+}
+
+// mockResource answers what the fake dynamic client gets wrong since it ignores the write
+// options: the server-side dry runs of creates, updates and JSON or merge patches, checked
+// against the stored object and returned without storing them, and server-side applies.
+type mockResource struct {
+	dynamic.ResourceInterface
+	// resource is the cluster-wide resource, nil once namespaced
+	resource dynamic.NamespaceableResourceInterface
+	gvr      schema.GroupVersionResource
+}
+
+func (r *mockResource) Namespace(namespace string) dynamic.ResourceInterface {
+	return &mockResource{ResourceInterface: r.resource.Namespace(namespace), gvr: r.gvr}
+}
+
+func (r *mockResource) Create(ctx context.Context, obj *unstructured.Unstructured, opts metav1.CreateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	if len(opts.DryRun) == 0 || len(subresources) > 0 {
+		return r.ResourceInterface.Create(ctx, obj, opts, subresources...)
+	}
+	if _, err := r.Get(ctx, obj.GetName(), metav1.GetOptions{}); err == nil {
+		return nil, k8serrors.NewAlreadyExists(r.gvr.GroupResource(), obj.GetName())
+	}
+	created := obj.DeepCopy()
+	created.SetResourceVersion("")
+	created.SetCreationTimestamp(metav1.Now())
+	return created, nil
+}
+
+func (r *mockResource) Update(ctx context.Context, obj *unstructured.Unstructured, opts metav1.UpdateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	if len(opts.DryRun) == 0 || len(subresources) > 0 {
+		return r.ResourceInterface.Update(ctx, obj, opts, subresources...)
+	}
+	stored, err := r.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if obj.GetResourceVersion() != "" && obj.GetResourceVersion() != stored.GetResourceVersion() {
+		return nil, k8serrors.NewConflict(r.gvr.GroupResource(), obj.GetName(), fmt.Errorf("the object has been modified; please apply your changes to the latest version and try again"))
+	}
+	updated := obj.DeepCopy()
+	updated.SetResourceVersion(stored.GetResourceVersion())
+	updated.SetCreationTimestamp(stored.GetCreationTimestamp())
+	updated.SetManagedFields(stored.GetManagedFields())
+	return updated, nil
+}
+
+func (r *mockResource) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	if len(opts.DryRun) == 0 || len(subresources) > 0 {
+		return r.ResourceInterface.Patch(ctx, name, pt, data, opts, subresources...)
+	}
+	stored, err := r.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	original, err := stored.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var modified []byte
+	switch pt {
+	case types.JSONPatchType:
+		patch, err := jsonpatch.DecodePatch(data)
+		if err != nil {
+			return nil, k8serrors.NewBadRequest(err.Error())
+		}
+		modified, err = patch.Apply(original)
+		if err != nil {
+			return nil, k8serrors.NewBadRequest(err.Error())
+		}
+	case types.MergePatchType:
+		modified, err = jsonpatch.MergePatch(original, data)
+		if err != nil {
+			return nil, k8serrors.NewBadRequest(err.Error())
+		}
+	default:
+		return nil, k8serrors.NewBadRequest(fmt.Sprintf("the mock client doesn't dry run %s patches", pt))
+	}
+	patched := &unstructured.Unstructured{}
+	if err := patched.UnmarshalJSON(modified); err != nil {
+		return nil, k8serrors.NewBadRequest(err.Error())
+	}
+	return patched, nil
+}
+
+// Apply merges obj into the stored object with the field manager of the API server, field
+// ownership and conflicts included, creating the object when missing. Without schemas, lists
+// are atomic.
+func (r *mockResource) Apply(ctx context.Context, name string, obj *unstructured.Unstructured, opts metav1.ApplyOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	if len(subresources) > 0 {
+		return r.ResourceInterface.Apply(ctx, name, obj, opts, subresources...)
+	}
+	gvk := obj.GroupVersionKind()
+	live, err := r.Get(ctx, name, metav1.GetOptions{})
+	exists := err == nil
+	switch {
+	case k8serrors.IsNotFound(err):
+		live = &unstructured.Unstructured{}
+		live.SetGroupVersionKind(gvk)
+	case err != nil:
+		return nil, err
+	case obj.GetResourceVersion() != "" && obj.GetResourceVersion() != live.GetResourceVersion():
+		return nil, k8serrors.NewConflict(r.gvr.GroupResource(), name, fmt.Errorf("the object has been modified; please apply your changes to the latest version and try again"))
+	}
+
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+	manager, err := managedfields.NewDefaultFieldManager(managedfields.NewDeducedTypeConverter(), scheme, scheme, scheme, gvk, gvk.GroupVersion(), "", nil)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := manager.Apply(live, obj, opts.FieldManager, opts.Force)
+	if err != nil {
+		return nil, err
+	}
+	applied, ok := merged.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("unexpected applied object %T", merged)
+	}
+	switch {
+	case len(opts.DryRun) > 0:
+		return applied, nil
+	case exists:
+		return r.ResourceInterface.Update(ctx, applied, metav1.UpdateOptions{})
+	}
+	return r.ResourceInterface.Create(ctx, applied, metav1.CreateOptions{})
+}
+
+var serviceAccountsGVR = schema.GroupVersionResource{Version: "v1", Resource: "serviceaccounts"}
+
+// issueToken answers the TokenRequests of serviceaccounts/token, which the fake client would
+// otherwise apply as an update of the ServiceAccount. The ServiceAccount must be stored,
+// except "default", which every namespace has.
+func issueToken(client *dynamicfake.FakeDynamicClient) k8stesting.ReactionFunc {
+	return func(action k8stesting.Action) (bool, runtime.Object, error) {
+		create, ok := action.(k8stesting.CreateActionImpl)
+		if !ok || create.GetSubresource() != "token" {
+			return false, nil, nil
+		}
+		if create.Name != "default" {
+			if _, err := client.Tracker().Get(serviceAccountsGVR, create.GetNamespace(), create.Name); err != nil {
+				return true, nil, err
+			}
+		}
+		request, ok := create.GetObject().(*unstructured.Unstructured)
+		if !ok {
+			return true, nil, fmt.Errorf("unexpected TokenRequest %T", create.GetObject())
+		}
+		expirationSeconds, found, _ := unstructured.NestedInt64(request.Object, "spec", "expirationSeconds")
+		if !found {
+			expirationSeconds = 3600
+		}
+		response := request.DeepCopy()
+		expiration := time.Now().Add(time.Duration(expirationSeconds) * time.Second).UTC().Format(time.RFC3339)
+		token := fmt.Sprintf("mock-token.%s.%s", create.GetNamespace(), create.Name)
+		_ = unstructured.SetNestedField(response.Object, map[string]any{"token": token, "expirationTimestamp": expiration}, "status")
+		return true, response, nil
+	}
+}
+
+// Reader serves every fixture namespace and service without authorization, like the internal client's cache.
+func (m *MockKubernetesClient) Reader() k8s.ResourceReader {
+	return m.reader
+}
+
+// ─── IN-MEMORY MOCK FACTORY ──────────────────────────────────────────────────────
+//
+// MockClientFactory extracts identities exactly like the real factory for the configured
+// auth method, and returns a single shared MockKubernetesClient.
+type MockClientFactory struct {
+	client      *MockKubernetesClient
+	realFactory k8s.KubernetesClientFactory
+}
+
+func NewInMemoryKubernetesClientFactory(cfg config.EnvConfig, logger *slog.Logger) (k8s.KubernetesClientFactory, error) {
+	fixtures := DefaultMockFixtures()
+	if cfg.MockK8sFixturesPath != "" {
+		var err error
+		fixtures, err = LoadMockFixtures(cfg.MockK8sFixturesPath)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Loaded mock Kubernetes fixtures", slog.String("path", cfg.MockK8sFixturesPath))
+	}
+
+	var realFactory k8s.KubernetesClientFactory
+	switch cfg.AuthMethod {
+	case config.AuthMethodInternal, config.AuthMethodImpersonation:
+		realFactory = &k8s.StaticClientFactory{Logger: logger}
+	case config.AuthMethodUser:
+		realFactory = k8s.NewTokenClientFactory(logger, cfg)
+	default:
+		return nil, fmt.Errorf("invalid auth method: %q", cfg.AuthMethod)
+	}
+
+	return &MockClientFactory{
+		client:      NewMockKubernetesClient(fixtures, logger),
+		realFactory: realFactory,
+	}, nil
+}
+
+func (f *MockClientFactory) GetClient(_ context.Context) (k8s.KubernetesClientInterface, error) {
+	return f.client, nil
+}
+
+func (f *MockClientFactory) ExtractRequestIdentity(httpHeader http.Header) (*k8s.RequestIdentity, error) {
+	return f.realFactory.ExtractRequestIdentity(httpHeader)
+}
+
+func (f *MockClientFactory) ValidateRequestIdentity(identity *k8s.RequestIdentity) error {
+	return f.realFactory.ValidateRequestIdentity(identity)
+}
+
+// CanBackendAccess allows everything: the in-memory backend has no RBAC to get wrong.
+func (f *MockClientFactory) CanBackendAccess(context.Context, string, string, string) (bool, error) {
+	return true, nil
+}
@@ -0,0 +1,134 @@
+package k8mocks
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestMockKubernetesClient_DefaultFixtures(t *testing.T) {
+	client := NewMockKubernetesClient(DefaultMockFixtures(), testLogger())
+	ctx := context.Background()
+
+	admin := &k8s.RequestIdentity{Token: "FAKE_CLUSTER_ADMIN_TOKEN"}
+	isAdmin, err := client.IsClusterAdmin(ctx, admin)
+	require.NoError(t, err)
+	assert.True(t, isAdmin)
+	namespaces, err := client.GetNamespaces(ctx, admin)
+	require.NoError(t, err)
+	assert.Len(t, namespaces, 4)
+
+	dora := &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com"}
+	user, err := client.GetUser(ctx, dora)
+	require.NoError(t, err)
+	assert.Equal(t, "doraNonAdmin@example.com", user)
+	namespaces, err = client.GetNamespaces(ctx, dora)
+	require.NoError(t, err)
+	require.Len(t, namespaces, 1)
+	assert.Equal(t, "dora-namespace", namespaces[0].Name)
+
+	allowed, err := client.CanAccess(ctx, dora, "list", "", "services", "dora-namespace")
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	allowed, err = client.CanAccess(ctx, dora, "list", "", "services", "bella-namespace")
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	allowed, err = client.CanAccess(ctx, dora, "list", "", "namespaces", "")
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	_, err = client.GetUser(ctx, &k8s.RequestIdentity{Token: "unknown"})
+	assert.Error(t, err)
+}
+
+func TestInMemoryKubernetesClientFactory_Fixtures(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixtures.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"namespaces":["b","a"],"users":[{"userName":"dev","token":"DEV","namespaces":["a","b"]}]}`), 0o600))
+
+	cfg := config.EnvConfig{
+		AuthMethod:          config.AuthMethodUser,
+		AuthTokenHeader:     config.DefaultAuthTokenHeader,
+		AuthTokenPrefix:     config.DefaultAuthTokenPrefix,
+		MockK8sFixturesPath: path,
+	}
+	factory, err := NewInMemoryKubernetesClientFactory(cfg, testLogger())
+	require.NoError(t, err)
+
+	identity, err := factory.ExtractRequestIdentity(http.Header{"Authorization": []string{"Bearer DEV"}})
+	require.NoError(t, err)
+
+	client, err := factory.GetClient(context.WithValue(context.Background(), constants.RequestIdentityKey, identity))
+	require.NoError(t, err)
+	namespaces, err := client.GetNamespaces(context.Background(), identity)
+	require.NoError(t, err)
+	require.Len(t, namespaces, 2)
+	assert.Equal(t, "a", namespaces[0].Name)
+	assert.Equal(t, "b", namespaces[1].Name)
+}
+
+func TestMockKubernetesClient_WatchResource(t *testing.T) {
+	client := NewMockKubernetesClient(DefaultMockFixtures(), testLogger())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	namespaces := schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+	admin := &k8s.RequestIdentity{Token: "FAKE_CLUSTER_ADMIN_TOKEN"}
+	w, err := client.WatchResource(ctx, admin, namespaces, "", metav1.ListOptions{})
+	require.NoError(t, err)
+	for i := 0; i < 4; i++ {
+		event := <-w.ResultChan()
+		assert.Equal(t, watch.Added, event.Type)
+	}
+	cancel()
+	_, open := <-w.ResultChan()
+	assert.False(t, open)
+
+	dora := &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com"}
+	_, err = client.WatchResource(context.Background(), dora, namespaces, "", metav1.ListOptions{})
+	assert.True(t, k8serrors.IsForbidden(err))
+}
+
+func TestMockKubernetesClient_StreamPodLogs(t *testing.T) {
+	client := NewMockKubernetesClient(DefaultMockFixtures(), testLogger())
+	dora := &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com"}
+	tail := int64(2)
+
+	logs, err := client.StreamPodLogs(context.Background(), dora, "dora-namespace", "web", &corev1.PodLogOptions{Container: "app", TailLines: &tail})
+	require.NoError(t, err)
+	data, err := io.ReadAll(logs)
+	require.NoError(t, err)
+	assert.Equal(t, "mock log line 19 of web/app\nmock log line 20 of web/app\n", string(data))
+
+	_, err = client.StreamPodLogs(context.Background(), dora, "bella-namespace", "web", &corev1.PodLogOptions{})
+	assert.True(t, k8serrors.IsForbidden(err))
+
+	// Follow streams stay open until ctx is done
+	ctx, cancel := context.WithCancel(context.Background())
+	logs, err = client.StreamPodLogs(ctx, dora, "dora-namespace", "web", &corev1.PodLogOptions{Follow: true, TailLines: &tail})
+	require.NoError(t, err)
+	defer logs.Close()
+	cancel()
+	data, err = io.ReadAll(logs)
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(data)), "\n"), 2)
+}
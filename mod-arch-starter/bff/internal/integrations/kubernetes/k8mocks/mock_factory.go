@@ -10,6 +10,7 @@ import (
 	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
 	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
 	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
@@ -22,7 +23,7 @@ type MockedKubernetesClientFactory interface {
 func NewMockedKubernetesClientFactory(clientset kubernetes.Interface, testEnv *envtest.Environment, cfg config.EnvConfig, logger *slog.Logger) (k8s.KubernetesClientFactory, error) {
 	switch cfg.AuthMethod {
 	case config.AuthMethodInternal:
-		k8sFactory, err := NewStaticClientFactory(clientset, logger)
+		k8sFactory, err := NewStaticClientFactoryForConfig(clientset, testEnv.Config, logger)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create static client factory: %w", err)
 		}
@@ -35,6 +36,10 @@ func NewMockedKubernetesClientFactory(clientset kubernetes.Interface, testEnv *e
 		}
 		return k8sFactory, nil
 
+	case config.AuthMethodImpersonation:
+		// envtest honours impersonation headers, so the real factory can be used as-is.
+		return k8s.NewImpersonationClientFactoryForConfig(testEnv.Config, logger), nil
+
 	default:
 		return nil, fmt.Errorf("invalid auth method: %q", cfg.AuthMethod)
 	}
@@ -45,18 +50,26 @@ type MockedStaticClientFactory struct {
 	logger                       *slog.Logger
 	serviceAccountMockedK8client k8s.KubernetesClientInterface
 	clientset                    kubernetes.Interface
+	restConfig                   *rest.Config
 	initErr                      error
 	initLock                     sync.Mutex
 	realFactoryWithoutClient     k8s.StaticClientFactory
 }
 
 func NewStaticClientFactory(clientset kubernetes.Interface, logger *slog.Logger) (k8s.KubernetesClientFactory, error) {
+	return NewStaticClientFactoryForConfig(clientset, nil, logger)
+}
+
+// NewStaticClientFactoryForConfig is NewStaticClientFactory with the rest.Config of the
+// clientset, which gives the client a dynamic client for custom resources.
+func NewStaticClientFactoryForConfig(clientset kubernetes.Interface, restConfig *rest.Config, logger *slog.Logger) (k8s.KubernetesClientFactory, error) {
 	realFactoryWithoutClient := k8s.StaticClientFactory{
 		Logger: logger,
 	}
 	return &MockedStaticClientFactory{
 		logger:                   logger,
 		clientset:                clientset,
+		restConfig:               restConfig,
 		realFactoryWithoutClient: realFactoryWithoutClient,
 	}, nil
 }
@@ -70,9 +83,9 @@ func (f *MockedStaticClientFactory) GetClient(_ context.Context) (k8s.Kubernetes
 	}
 
 	f.logger.Info("Initializing mocked service account client")
-	client := newMockedInternalKubernetesClientFromClientset(f.clientset, f.logger)
-	if client == nil {
-		f.initErr = fmt.Errorf("failed to create mocked service account client")
+	client, err := newMockedInternalKubernetesClientFromClientset(f.clientset, f.restConfig, f.logger)
+	if err != nil {
+		f.initErr = fmt.Errorf("failed to create mocked service account client: %w", err)
 		return nil, f.initErr
 	}
 
@@ -169,7 +182,10 @@ func (f *MockedTokenClientFactory) GetClient(ctx context.Context) (k8s.Kubernete
 		return nil, fmt.Errorf("failed to create impersonated client: %w", err)
 	}
 
-	client := newMockedTokenKubernetesClientFromClientset(clientset, f.logger)
+	client, err := newMockedTokenKubernetesClientFromClientset(clientset, impersonatedCfg, f.logger)
+	if err != nil {
+		return nil, err
+	}
 	f.clients[identity.Token] = client
 	return client, nil
 }
@@ -182,3 +198,15 @@ func findTestUserByToken(token string) *TestUser {
 	}
 	return nil
 }
+
+// newDynamicClient returns a dynamic client for restConfig, or nil without a rest.Config.
+func newDynamicClient(restConfig *rest.Config) (dynamic.Interface, error) {
+	if restConfig == nil {
+		return nil, nil
+	}
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+	return dynamicClient, nil
+}
@@ -5,6 +5,7 @@ import (
 
 	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 )
 
 // ⚠️ WHY THIS FILE EXISTS:
@@ -16,16 +17,22 @@ type TokenKubernetesClientMock struct {
 	*k8s.TokenKubernetesClient
 }
 
-func newMockedTokenKubernetesClientFromClientset(clientset kubernetes.Interface, logger *slog.Logger) k8s.KubernetesClientInterface {
+func newMockedTokenKubernetesClientFromClientset(clientset kubernetes.Interface, restConfig *rest.Config, logger *slog.Logger) (k8s.KubernetesClientInterface, error) {
+	dynamicClient, err := newDynamicClient(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	return &TokenKubernetesClientMock{
 		TokenKubernetesClient: &k8s.TokenKubernetesClient{
 			SharedClientLogic: k8s.SharedClientLogic{
-				Client: clientset,
-				Logger: logger,
-				Token:  k8s.NewBearerToken(""), // Unused because impersonation is already handled in the client config
+				Client:  clientset,
+				Dynamic: dynamicClient,
+				Logger:  logger,
+				Token:   k8s.NewBearerToken(""), // Unused because impersonation is already handled in the client config
 			},
 		},
-	}
+	}, nil
 }
 
 // GetServiceDetails overrides to simulate ClusterIP for localhost access
@@ -35,5 +42,3 @@ func newMockedTokenKubernetesClientFromClientset(clientset kubernetes.Interface,
 func (m *TokenKubernetesClientMock) BearerToken() (string, error) {
 	return "FAKE-BEARER-TOKEN", nil
 }
-
-// GetGroups removed in minimal starter.
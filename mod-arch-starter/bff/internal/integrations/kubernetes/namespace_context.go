@@ -0,0 +1,34 @@
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+)
+
+// NamespaceContext is the namespace targeted by a request, once its name was validated, the
+// namespace found and the access of the identity to it reviewed (see the API's
+// AttachNamespace). Repositories read it with NamespaceFromContext rather than checking the
+// namespace again.
+type NamespaceContext struct {
+	Name string
+	// Labels of the namespace when it was verified, e.g. to tell Data Science Projects apart.
+	Labels map[string]string
+}
+
+// WithNamespace returns a copy of ctx carrying ns.
+func WithNamespace(ctx context.Context, ns NamespaceContext) context.Context {
+	return context.WithValue(ctx, constants.NamespaceContextKey, ns)
+}
+
+// NamespaceFromContext returns the NamespaceContext set by WithNamespace, if any.
+func NamespaceFromContext(ctx context.Context) (NamespaceContext, bool) {
+	ns, ok := ctx.Value(constants.NamespaceContextKey).(NamespaceContext)
+	return ns, ok
+}
+
+// VerifiedNamespace reports whether namespace is the verified NamespaceContext of ctx.
+func VerifiedNamespace(ctx context.Context, namespace string) bool {
+	ns, ok := NamespaceFromContext(ctx)
+	return ok && namespace != "" && ns.Name == namespace
+}
@@ -0,0 +1,298 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/parallel"
+	authv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+)
+
+// namespaceAdminTimeout bounds the access reviews of the namespace-admin check.
+const namespaceAdminTimeout = 30 * time.Second
+
+// ─── NAMESPACE SCOPE ────────────────────────────────────────────────────────
+// restricts the clients of restricted multi-tenant installs, where the BFF and its users
+// only have namespace-scoped RBAC, to an allowlist of namespaces. The clients never make
+// cluster-scoped calls: namespaces are listed from the allowlist, "cluster admin" means
+// admin of every allowed namespace, and cluster-scoped reads, watches and access checks are
+// refused with a Forbidden error.
+
+// NamespaceScope is an allowlist of namespaces. It is immutable and safe for concurrent use.
+type NamespaceScope struct {
+	namespaces []string
+	logger     *slog.Logger
+}
+
+// NewNamespaceScope returns the scope of namespaces, ignoring duplicates.
+func NewNamespaceScope(namespaces []string, logger *slog.Logger) *NamespaceScope {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	scope := &NamespaceScope{logger: logger}
+	for _, namespace := range namespaces {
+		if namespace != "" && !slices.Contains(scope.namespaces, namespace) {
+			scope.namespaces = append(scope.namespaces, namespace)
+		}
+	}
+	return scope
+}
+
+// Namespaces returns the allowed namespaces, in configuration order.
+func (s *NamespaceScope) Namespaces() []string {
+	return append([]string(nil), s.namespaces...)
+}
+
+// Contains reports whether namespace is allowed; "" (cluster scope) never is.
+func (s *NamespaceScope) Contains(namespace string) bool {
+	return slices.Contains(s.namespaces, namespace)
+}
+
+// Client returns client restricted to the scope.
+func (s *NamespaceScope) Client(client KubernetesClientInterface) KubernetesClientInterface {
+	return &namespaceScopedClient{KubernetesClientInterface: client, scope: s}
+}
+
+// forbidden is the error of a call on resource in namespace outside the scope.
+func forbidden(resource schema.GroupResource, name, namespace string) error {
+	if namespace == "" {
+		return k8serrors.NewForbidden(resource, name, fmt.Errorf("cluster-scoped calls are disabled in namespace-scoped mode"))
+	}
+	return k8serrors.NewForbidden(resource, name, fmt.Errorf("namespace %q is not one of the allowed namespaces", namespace))
+}
+
+// namespaceScopedClient restricts a client to a NamespaceScope. GetUser and GetGroups go
+// through; on OpenShift, GetGroups already tolerates failing to list the cluster-scoped Groups.
+type namespaceScopedClient struct {
+	KubernetesClientInterface
+	scope *NamespaceScope
+}
+
+// GetNamespaces returns the allowed namespaces the identity can get. They are not read from
+// the API server, which would take a cluster-scoped list.
+func (c *namespaceScopedClient) GetNamespaces(ctx context.Context, identity *RequestIdentity) ([]corev1.Namespace, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	namespaces := scopedNamespaces(c.scope.namespaces)
+	return filterAccessibleNamespaces(ctx, c.scope.logger, namespaces, func(ctx context.Context, namespace string) (bool, error) {
+		return c.KubernetesClientInterface.CanAccess(ctx, identity, "get", "", "namespaces", namespace)
+	})
+}
+
+// IsClusterAdmin reports whether the identity may do everything in every allowed namespace,
+// which is all a namespace-scoped BFF can administer.
+func (c *namespaceScopedClient) IsClusterAdmin(ctx context.Context, identity *RequestIdentity) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, namespaceAdminTimeout)
+	defer cancel()
+
+	namespaces := c.scope.namespaces
+	allowed := make([]bool, len(namespaces))
+	errs := make([]error, len(namespaces))
+	err := parallel.ForEach(ctx, parallel.Options{Limit: namespaceAccessWorkers}, len(namespaces), func(ctx context.Context, i int) {
+		allowed[i], errs[i] = c.KubernetesClientInterface.CanAccess(ctx, identity, "*", "*", "*", namespaces[i])
+	})
+	// A single denied namespace settles it, whatever the failed checks
+	for i := range namespaces {
+		if errs[i] == nil && !allowed[i] {
+			return false, nil
+		}
+	}
+	if err == nil {
+		err = errors.Join(errs...)
+	}
+	if err != nil {
+		c.scope.logger.Error("failed to perform namespace-admin SARs", "error", err)
+		return false, fmt.Errorf("failed to verify namespace-admin permissions: %w", err)
+	}
+	return len(namespaces) > 0, nil
+}
+
+// CanAccess denies the namespaces outside the scope, and refuses cluster-scoped checks.
+func (c *namespaceScopedClient) CanAccess(ctx context.Context, identity *RequestIdentity, verb, group, resource, namespace string) (bool, error) {
+	if namespace == "" {
+		return false, forbidden(schema.GroupResource{Group: group, Resource: resource}, "", namespace)
+	}
+	if !c.scope.Contains(namespace) {
+		return false, nil
+	}
+	return c.KubernetesClientInterface.CanAccess(ctx, identity, verb, group, resource, namespace)
+}
+
+func (c *namespaceScopedClient) GetRules(ctx context.Context, identity *RequestIdentity, namespace string) (*authv1.SubjectRulesReviewStatus, error) {
+	if !c.scope.Contains(namespace) {
+		return nil, forbidden(schema.GroupResource{Group: "authorization.k8s.io", Resource: "selfsubjectrulesreviews"}, "", namespace)
+	}
+	return c.KubernetesClientInterface.GetRules(ctx, identity, namespace)
+}
+
+func (c *namespaceScopedClient) WatchResource(ctx context.Context, identity *RequestIdentity, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (watch.Interface, error) {
+	if !c.scope.Contains(namespace) {
+		return nil, forbidden(gvr.GroupResource(), "", namespace)
+	}
+	return c.KubernetesClientInterface.WatchResource(ctx, identity, gvr, namespace, opts)
+}
+
+func (c *namespaceScopedClient) StreamPodLogs(ctx context.Context, identity *RequestIdentity, namespace, pod string, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+	if !c.scope.Contains(namespace) {
+		return nil, forbidden(schema.GroupResource{Resource: "pods/log"}, pod, namespace)
+	}
+	return c.KubernetesClientInterface.StreamPodLogs(ctx, identity, namespace, pod, opts)
+}
+
+// Impersonate returns the client impersonating the identity, restricted to the scope. Clients
+// that don't impersonate already act as the identity and are returned as they are.
+func (c *namespaceScopedClient) Impersonate(identity *RequestIdentity) (KubernetesClientInterface, error) {
+	impersonator, ok := c.KubernetesClientInterface.(Impersonator)
+	if !ok {
+		return c, nil
+	}
+	client, err := impersonator.Impersonate(identity)
+	if err != nil {
+		return nil, err
+	}
+	return c.scope.Client(client), nil
+}
+
+// DynamicResource refuses the cluster-scoped calls of the resource. Namespaced calls go
+// through for any namespace, as the BFF may keep its own data (e.g. user preferences) in a
+// namespace users don't see; repositories check CanAccess, hence the allowlist, first.
+func (c *namespaceScopedClient) DynamicResource(gvr schema.GroupVersionResource) (dynamic.NamespaceableResourceInterface, error) {
+	resource, err := c.KubernetesClientInterface.DynamicResource(gvr)
+	if err != nil {
+		return nil, err
+	}
+	return &clusterRefusingResource{namespaced: resource, resource: gvr.GroupResource()}, nil
+}
+
+// Reader lists namespaces from the allowlist and refuses to read services and events across
+// namespaces.
+func (c *namespaceScopedClient) Reader() ResourceReader {
+	return &namespaceScopedReader{ResourceReader: c.KubernetesClientInterface.Reader(), scope: c.scope}
+}
+
+func scopedNamespaces(names []string) []corev1.Namespace {
+	namespaces := make([]corev1.Namespace, len(names))
+	for i, name := range names {
+		namespaces[i] = corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+	return namespaces
+}
+
+type namespaceScopedReader struct {
+	ResourceReader
+	scope *NamespaceScope
+}
+
+var namespacesResource = schema.GroupResource{Resource: "namespaces"}
+
+func (r *namespaceScopedReader) ListNamespaces(context.Context) ([]corev1.Namespace, error) {
+	return scopedNamespaces(r.scope.namespaces), nil
+}
+
+func (r *namespaceScopedReader) GetNamespace(_ context.Context, name string) (*corev1.Namespace, error) {
+	if !r.scope.Contains(name) {
+		return nil, k8serrors.NewNotFound(namespacesResource, name)
+	}
+	return &scopedNamespaces([]string{name})[0], nil
+}
+
+func (r *namespaceScopedReader) ListServices(ctx context.Context, namespace string) ([]corev1.Service, error) {
+	if namespace == "" {
+		return nil, forbidden(schema.GroupResource{Resource: "services"}, "", namespace)
+	}
+	return r.ResourceReader.ListServices(ctx, namespace)
+}
+
+func (r *namespaceScopedReader) ListEndpointSlices(ctx context.Context, namespace, service string) ([]discoveryv1.EndpointSlice, error) {
+	if namespace == "" {
+		return nil, forbidden(schema.GroupResource{Group: "discovery.k8s.io", Resource: "endpointslices"}, "", namespace)
+	}
+	return r.ResourceReader.ListEndpointSlices(ctx, namespace, service)
+}
+
+func (r *namespaceScopedReader) ListEvents(ctx context.Context, namespace string, selector fields.Selector) ([]corev1.Event, error) {
+	if namespace == "" {
+		return nil, forbidden(schema.GroupResource{Resource: "events"}, "", namespace)
+	}
+	return r.ResourceReader.ListEvents(ctx, namespace, selector)
+}
+
+// clusterRefusingResource is a dynamic resource whose cluster-scoped calls are refused: only
+// its Namespace resources reach the API server.
+type clusterRefusingResource struct {
+	namespaced dynamic.NamespaceableResourceInterface
+	resource   schema.GroupResource
+}
+
+var _ dynamic.NamespaceableResourceInterface = (*clusterRefusingResource)(nil)
+
+func (r *clusterRefusingResource) Namespace(namespace string) dynamic.ResourceInterface {
+	if namespace == "" {
+		return r
+	}
+	return r.namespaced.Namespace(namespace)
+}
+
+func (r *clusterRefusingResource) refuse(name string) error {
+	return forbidden(r.resource, name, "")
+}
+
+func (r *clusterRefusingResource) Create(_ context.Context, obj *unstructured.Unstructured, _ metav1.CreateOptions, _ ...string) (*unstructured.Unstructured, error) {
+	return nil, r.refuse(obj.GetName())
+}
+
+func (r *clusterRefusingResource) Update(_ context.Context, obj *unstructured.Unstructured, _ metav1.UpdateOptions, _ ...string) (*unstructured.Unstructured, error) {
+	return nil, r.refuse(obj.GetName())
+}
+
+func (r *clusterRefusingResource) UpdateStatus(_ context.Context, obj *unstructured.Unstructured, _ metav1.UpdateOptions) (*unstructured.Unstructured, error) {
+	return nil, r.refuse(obj.GetName())
+}
+
+func (r *clusterRefusingResource) Delete(_ context.Context, name string, _ metav1.DeleteOptions, _ ...string) error {
+	return r.refuse(name)
+}
+
+func (r *clusterRefusingResource) DeleteCollection(context.Context, metav1.DeleteOptions, metav1.ListOptions) error {
+	return r.refuse("")
+}
+
+func (r *clusterRefusingResource) Get(_ context.Context, name string, _ metav1.GetOptions, _ ...string) (*unstructured.Unstructured, error) {
+	return nil, r.refuse(name)
+}
+
+func (r *clusterRefusingResource) List(context.Context, metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	return nil, r.refuse("")
+}
+
+func (r *clusterRefusingResource) Watch(context.Context, metav1.ListOptions) (watch.Interface, error) {
+	return nil, r.refuse("")
+}
+
+func (r *clusterRefusingResource) Patch(_ context.Context, name string, _ types.PatchType, _ []byte, _ metav1.PatchOptions, _ ...string) (*unstructured.Unstructured, error) {
+	return nil, r.refuse(name)
+}
+
+func (r *clusterRefusingResource) Apply(_ context.Context, name string, _ *unstructured.Unstructured, _ metav1.ApplyOptions, _ ...string) (*unstructured.Unstructured, error) {
+	return nil, r.refuse(name)
+}
+
+func (r *clusterRefusingResource) ApplyStatus(_ context.Context, name string, _ *unstructured.Unstructured, _ metav1.ApplyOptions) (*unstructured.Unstructured, error) {
+	return nil, r.refuse(name)
+}
@@ -0,0 +1,117 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newScopedTestClient returns a client of the scope team-a, team-b and team-c whose user may
+// do everything in team-a and team-b and nothing in team-c. Cluster-scoped list calls fail the
+// test.
+func newScopedTestClient(t *testing.T) KubernetesClientInterface {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		ssar := action.(k8stesting.CreateAction).GetObject().(*authv1.SelfSubjectAccessReview)
+		namespace := ssar.Spec.ResourceAttributes.Namespace
+		ssar.Status.Allowed = namespace == "team-a" || namespace == "team-b"
+		return true, ssar, nil
+	})
+	clientset.PrependReactor("list", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() == "" {
+			t.Errorf("unexpected cluster-scoped list of %s", action.GetResource().Resource)
+		}
+		return false, nil, nil
+	})
+
+	kc := &TokenKubernetesClient{SharedClientLogic: SharedClientLogic{
+		Client:  clientset,
+		Dynamic: dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()),
+		Logger:  testLogger(),
+	}}
+	return NewNamespaceScope([]string{"team-a", "team-b", "team-a", "team-c"}, testLogger()).Client(kc)
+}
+
+func TestNamespaceScope_GetNamespaces(t *testing.T) {
+	client := newScopedTestClient(t)
+
+	namespaces, err := client.GetNamespaces(context.Background(), nil)
+	require.NoError(t, err)
+	require.Len(t, namespaces, 2)
+	assert.Equal(t, "team-a", namespaces[0].Name)
+	assert.Equal(t, "team-b", namespaces[1].Name)
+
+	namespaces, err = client.Reader().ListNamespaces(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, namespaces, 3, "the allowlist, without duplicates")
+	_, err = client.Reader().GetNamespace(context.Background(), "kube-system")
+	assert.True(t, k8serrors.IsNotFound(err))
+}
+
+func TestNamespaceScope_IsClusterAdmin(t *testing.T) {
+	admin, err := newScopedTestClient(t).IsClusterAdmin(context.Background(), nil)
+	require.NoError(t, err)
+	assert.False(t, admin, "not an admin of team-c")
+
+	clientset := fake.NewSimpleClientset()
+	var reviewed []string
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		ssar := action.(k8stesting.CreateAction).GetObject().(*authv1.SelfSubjectAccessReview)
+		reviewed = append(reviewed, ssar.Spec.ResourceAttributes.Namespace)
+		ssar.Status.Allowed = ssar.Spec.ResourceAttributes.Verb == "*" && ssar.Spec.ResourceAttributes.Resource == "*"
+		return true, ssar, nil
+	})
+	kc := &TokenKubernetesClient{SharedClientLogic: SharedClientLogic{Client: clientset, Logger: testLogger()}}
+	admin, err = NewNamespaceScope([]string{"team-a"}, testLogger()).Client(kc).IsClusterAdmin(context.Background(), nil)
+	require.NoError(t, err)
+	assert.True(t, admin)
+	assert.Equal(t, []string{"team-a"}, reviewed, "no cluster-wide review")
+}
+
+func TestNamespaceScope_RefusesClusterScopedCalls(t *testing.T) {
+	client := newScopedTestClient(t)
+	ctx := context.Background()
+
+	allowed, err := client.CanAccess(ctx, nil, "list", "", "pods", "team-a")
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	allowed, err = client.CanAccess(ctx, nil, "list", "", "pods", "kube-system")
+	require.NoError(t, err)
+	assert.False(t, allowed, "outside the allowlist")
+	_, err = client.CanAccess(ctx, nil, "list", "", "nodes", "")
+	assert.True(t, k8serrors.IsForbidden(err))
+
+	podsGVR := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	_, err = client.WatchResource(ctx, nil, podsGVR, "", metav1.ListOptions{})
+	assert.True(t, k8serrors.IsForbidden(err))
+	_, err = client.WatchResource(ctx, nil, podsGVR, "kube-system", metav1.ListOptions{})
+	assert.True(t, k8serrors.IsForbidden(err))
+	_, err = client.StreamPodLogs(ctx, nil, "kube-system", "etcd", &corev1.PodLogOptions{})
+	assert.True(t, k8serrors.IsForbidden(err))
+
+	_, err = client.Reader().ListServices(ctx, "")
+	assert.True(t, k8serrors.IsForbidden(err))
+	services, err := client.Reader().ListServices(ctx, "team-a")
+	require.NoError(t, err)
+	assert.Empty(t, services)
+
+	resource, err := client.DynamicResource(podsGVR)
+	require.NoError(t, err)
+	_, err = resource.List(ctx, metav1.ListOptions{})
+	assert.True(t, k8serrors.IsForbidden(err))
+	_, err = resource.Namespace("").Get(ctx, "etcd", metav1.GetOptions{})
+	assert.True(t, k8serrors.IsForbidden(err))
+	_, err = resource.Namespace("team-a").Get(ctx, "missing", metav1.GetOptions{})
+	assert.True(t, k8serrors.IsNotFound(err), "namespaced calls go through")
+}
@@ -0,0 +1,44 @@
+package kubernetes
+
+import (
+	"sync"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/openshift"
+)
+
+// openShiftDetector remembers whether the cluster is OpenShift for the factories creating a
+// client per request. It detects it with the first client; a failed detection is retried
+// with the next one.
+type openShiftDetector struct {
+	mu          sync.Mutex
+	detected    bool
+	isOpenShift bool
+}
+
+// setup sets kc.OpenShift when the cluster is OpenShift.
+func (d *openShiftDetector) setup(kc *SharedClientLogic) {
+	d.mu.Lock()
+	if !d.detected {
+		d.isOpenShift, d.detected = detectOpenShift(kc)
+	}
+	isOpenShift := d.isOpenShift
+	d.mu.Unlock()
+
+	if isOpenShift && kc.Dynamic != nil {
+		kc.OpenShift = openshift.NewClient(kc.Dynamic)
+	}
+}
+
+// detectOpenShift reports whether kc talks to OpenShift, and whether that could be detected.
+// Clusters that can't be detected are treated as vanilla Kubernetes.
+func detectOpenShift(kc *SharedClientLogic) (isOpenShift, ok bool) {
+	isOpenShift, err := openshift.IsOpenShift(kc.Client.Discovery())
+	if err != nil {
+		kc.Logger.Warn("failed to detect OpenShift, using the Kubernetes APIs", "error", err)
+		return false, false
+	}
+	if isOpenShift {
+		kc.Logger.Info("OpenShift detected, listing projects and reading OpenShift users and groups")
+	}
+	return isOpenShift, true
+}
@@ -0,0 +1,105 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	authv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Permission is an access the backend credentials of the BFF (its service account) need.
+type Permission struct {
+	Verb     string
+	Group    string
+	Resource string
+	// Reason tells what the BFF uses the permission for.
+	Reason string
+	// Optional permissions only disable a feature when missing.
+	Optional bool
+}
+
+// String returns the permission like kubectl auth can-i, e.g. "create
+// subjectaccessreviews.authorization.k8s.io".
+func (p Permission) String() string {
+	if p.Group == "" {
+		return p.Verb + " " + p.Resource
+	}
+	return p.Verb + " " + p.Resource + "." + p.Group
+}
+
+// BackendPermissions returns the cluster-wide permissions the backend credentials need with
+// authMethod. The user method has none: the BFF only acts with the tokens of the users.
+func BackendPermissions(authMethod string) []Permission {
+	switch authMethod {
+	case config.AuthMethodInternal:
+		return []Permission{
+			{Verb: "create", Group: "authorization.k8s.io", Resource: "subjectaccessreviews", Reason: "authorizes the requests of the users"},
+			{Verb: "list", Resource: "namespaces", Reason: "lists the namespaces the users can access"},
+			{Verb: "list", Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings", Reason: "tells the cluster admins apart"},
+			{Verb: "create", Group: "authentication.k8s.io", Resource: "tokenreviews", Reason: "authenticates bearer tokens, e.g. for a kube-rbac-proxy sidecar", Optional: true},
+			{Verb: "impersonate", Resource: "users", Reason: "reviews the rules of the users", Optional: true},
+			{Verb: "impersonate", Resource: "groups", Reason: "reviews the rules of the users", Optional: true},
+		}
+	case config.AuthMethodImpersonation:
+		return []Permission{
+			{Verb: "impersonate", Resource: "users", Reason: "acts as the users"},
+			{Verb: "impersonate", Resource: "groups", Reason: "acts with the groups of the users"},
+		}
+	}
+	return nil
+}
+
+// BackendAccessChecker is implemented by clients and client factories with backend
+// credentials. It backs the RBAC preflight checks.
+type BackendAccessChecker interface {
+	// CanBackendAccess reports whether the backend credentials may perform verb on resource
+	// cluster-wide, with a SelfSubjectAccessReview.
+	CanBackendAccess(ctx context.Context, verb, group, resource string) (bool, error)
+}
+
+var (
+	_ BackendAccessChecker = (*SharedClientLogic)(nil)
+	_ BackendAccessChecker = (*StaticClientFactory)(nil)
+	_ BackendAccessChecker = (*ImpersonationClientFactory)(nil)
+)
+
+// selfAccessReview runs a SelfSubjectAccessReview with the credentials of clientset.
+func selfAccessReview(ctx context.Context, clientset kubernetes.Interface, verb, group, resource string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	review := &authv1.SelfSubjectAccessReview{
+		Spec: authv1.SelfSubjectAccessReviewSpec{ResourceAttributes: resourceAttributes(verb, group, resource, "")},
+	}
+	resp, err := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to perform SelfSubjectAccessReview: %w", err)
+	}
+	return resp.Status.Allowed, nil
+}
+
+func (kc *SharedClientLogic) CanBackendAccess(ctx context.Context, verb, group, resource string) (bool, error) {
+	return selfAccessReview(ctx, kc.Client, verb, group, resource)
+}
+
+// CanBackendAccess reviews the access of the backend client. Clients that can't be checked
+// (custom downstream clients) are reported allowed.
+func (f *StaticClientFactory) CanBackendAccess(ctx context.Context, verb, group, resource string) (bool, error) {
+	if checker, ok := f.Client.(BackendAccessChecker); ok {
+		return checker.CanBackendAccess(ctx, verb, group, resource)
+	}
+	return true, nil
+}
+
+// CanBackendAccess reviews the access of the backend credentials, without impersonating anyone.
+func (f *ImpersonationClientFactory) CanBackendAccess(ctx context.Context, verb, group, resource string) (bool, error) {
+	clientset, err := kubernetes.NewForConfig(f.BaseConfig)
+	if err != nil {
+		return false, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	return selfAccessReview(ctx, clientset, verb, group, resource)
+}
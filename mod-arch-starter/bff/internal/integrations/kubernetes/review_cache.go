@@ -0,0 +1,157 @@
+package kubernetes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	authnv1 "k8s.io/api/authentication/v1"
+	authv1 "k8s.io/api/authorization/v1"
+	utilcache "k8s.io/apimachinery/pkg/util/cache"
+)
+
+const (
+	// ReviewSubject names the SelfSubjectReviews resolving who a token authenticates as.
+	ReviewSubject = "subject"
+	// ReviewAccess names the (Self)SubjectAccessReviews.
+	ReviewAccess = "access"
+)
+
+// ReviewCacheConfig configures NewReviewCache. A zero TTL disables caching of those results.
+type ReviewCacheConfig struct {
+	// SubjectTTL is the lifetime of the user and groups a token authenticates as.
+	SubjectTTL time.Duration
+	// AllowedTTL and DeniedTTL are the lifetimes of allowed and denied access reviews; denials
+	// are usually kept shorter, so newly granted access applies quickly.
+	AllowedTTL time.Duration
+	DeniedTTL  time.Duration
+	// MaxEntries bounds the cache; the least recently used results are evicted first.
+	MaxEntries int
+}
+
+// Enabled reports whether any result is cached.
+func (c ReviewCacheConfig) Enabled() bool {
+	return c.SubjectTTL > 0 || c.AllowedTTL > 0 || c.DeniedTTL > 0
+}
+
+// ReviewCacheConfigFromEnv returns the review cache settings from the BFF configuration.
+func ReviewCacheConfigFromEnv(cfg config.EnvConfig) ReviewCacheConfig {
+	return ReviewCacheConfig{
+		SubjectTTL: cfg.ReviewCacheSubjectTTL,
+		AllowedTTL: cfg.ReviewCacheAllowedTTL,
+		DeniedTTL:  cfg.ReviewCacheDeniedTTL,
+		MaxEntries: cfg.ReviewCacheMaxEntries,
+	}
+}
+
+// ReviewCache memoizes authentication and authorization reviews, like kube-rbac-proxy: results
+// are keyed by a hash of the token (or the impersonated user and groups) and the reviewed
+// attributes, expire after a short TTL, and errors are never cached. It is shared by every
+// client of a factory. A nil *ReviewCache caches nothing.
+type ReviewCache struct {
+	cfg     ReviewCacheConfig
+	entries *utilcache.LRUExpireCache
+	observe func(review string, hit bool)
+}
+
+// NewReviewCache returns the cache of cfg, or nil when cfg caches nothing.
+func NewReviewCache(cfg ReviewCacheConfig) *ReviewCache {
+	if !cfg.Enabled() {
+		return nil
+	}
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = config.DefaultReviewCacheMaxEntries
+	}
+	return &ReviewCache{cfg: cfg, entries: utilcache.NewLRUExpireCache(cfg.MaxEntries)}
+}
+
+// Observe calls fn on every lookup, e.g. to count hits and misses per review (ReviewSubject
+// or ReviewAccess). Call it before the cache is used.
+func (c *ReviewCache) Observe(fn func(review string, hit bool)) {
+	if c != nil {
+		c.observe = fn
+	}
+}
+
+func (c *ReviewCache) lookup(review, key string) (any, bool) {
+	value, hit := c.entries.Get(review + "\x00" + key)
+	if c.observe != nil {
+		c.observe(review, hit)
+	}
+	return value, hit
+}
+
+// Prune drops the expired reviews, which are otherwise only dropped when looked up or evicted,
+// so the reviews of users gone for good don't stay in memory.
+func (c *ReviewCache) Prune() {
+	if c == nil {
+		return
+	}
+	live := map[any]bool{}
+	for _, key := range c.entries.Keys() {
+		live[key] = true
+	}
+	c.entries.RemoveAll(func(key any) bool { return !live[key] })
+}
+
+// subject returns the cached UserInfo of the credentials identified by key, or runs the review.
+func (c *ReviewCache) subject(key string, review func() (authnv1.UserInfo, error)) (authnv1.UserInfo, error) {
+	if c == nil || key == "" || c.cfg.SubjectTTL <= 0 {
+		return review()
+	}
+	if value, ok := c.lookup(ReviewSubject, key); ok {
+		return value.(authnv1.UserInfo), nil
+	}
+	info, err := review()
+	if err != nil {
+		return info, err
+	}
+	c.entries.Add(ReviewSubject+"\x00"+key, info, c.cfg.SubjectTTL)
+	return info, nil
+}
+
+// access returns the cached result of reviewing attrs for the credentials identified by key,
+// or runs the review.
+func (c *ReviewCache) access(key string, attrs *authv1.ResourceAttributes, review func() (bool, error)) (bool, error) {
+	if c == nil || key == "" || (c.cfg.AllowedTTL <= 0 && c.cfg.DeniedTTL <= 0) {
+		return review()
+	}
+	key = key + "\x00" + strings.Join([]string{attrs.Verb, attrs.Group, attrs.Resource, attrs.Subresource, attrs.Namespace, attrs.Name}, "\x00")
+	if value, ok := c.lookup(ReviewAccess, key); ok {
+		return value.(bool), nil
+	}
+	allowed, err := review()
+	if err != nil {
+		return false, err
+	}
+	ttl := c.cfg.DeniedTTL
+	if allowed {
+		ttl = c.cfg.AllowedTTL
+	}
+	if ttl > 0 {
+		c.entries.Add(ReviewAccess+"\x00"+key, allowed, ttl)
+	}
+	return allowed, nil
+}
+
+// tokenReviewKey identifies the holder of token without keeping it in memory.
+func tokenReviewKey(token string) string {
+	if token == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(token))
+	return "token:" + hex.EncodeToString(sum[:])
+}
+
+// identityReviewKey identifies a user and groups, whatever the order of the groups.
+func identityReviewKey(identity *RequestIdentity) string {
+	if identity == nil || identity.UserID == "" {
+		return ""
+	}
+	groups := slices.Clone(identity.Groups)
+	slices.Sort(groups)
+	return "user:" + identity.UserID + "\x00" + strings.Join(groups, "\x00")
+}
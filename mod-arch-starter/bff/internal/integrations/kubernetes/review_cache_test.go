@@ -0,0 +1,168 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authnv1 "k8s.io/api/authentication/v1"
+	authv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestTokenKubernetesClient_ReviewCache(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	var ssars, ssrs int
+	var failing bool
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		ssars++
+		if failing {
+			return true, nil, errors.New("unavailable")
+		}
+		ssar := action.(k8stesting.CreateAction).GetObject().(*authv1.SelfSubjectAccessReview)
+		ssar.Status.Allowed = ssar.Spec.ResourceAttributes.Verb == "get"
+		return true, ssar, nil
+	})
+	clientset.PrependReactor("create", "selfsubjectreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		ssrs++
+		ssr := action.(k8stesting.CreateAction).GetObject().(*authnv1.SelfSubjectReview)
+		ssr.Status.UserInfo = authnv1.UserInfo{Username: "user@example.com", Groups: []string{"team-a"}}
+		return true, ssr, nil
+	})
+
+	reviews := NewReviewCache(ReviewCacheConfig{SubjectTTL: time.Minute, AllowedTTL: time.Minute})
+	lookups := map[string]int{}
+	reviews.Observe(func(review string, hit bool) {
+		if hit {
+			lookups[review]++
+		}
+	})
+	client := func(token string) *TokenKubernetesClient {
+		return &TokenKubernetesClient{
+			SharedClientLogic: SharedClientLogic{Client: clientset, Logger: testLogger(), Reviews: reviews},
+			reviewKey:         tokenReviewKey(token),
+		}
+	}
+	ctx := context.Background()
+
+	for range 3 {
+		allowed, err := client("token-a").CanAccess(ctx, nil, "get", "", "services", "ns")
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+	assert.Equal(t, 1, ssars, "allowed reviews are cached")
+	assert.Equal(t, 2, lookups[ReviewAccess])
+
+	_, err := client("token-b").CanAccess(ctx, nil, "get", "", "services", "ns")
+	require.NoError(t, err)
+	_, err = client("token-a").CanAccess(ctx, nil, "get", "", "services", "other")
+	require.NoError(t, err)
+	assert.Equal(t, 3, ssars, "results are per token and attributes")
+
+	for range 2 {
+		allowed, err := client("token-a").CanAccess(ctx, nil, "delete", "", "services", "ns")
+		require.NoError(t, err)
+		assert.False(t, allowed)
+	}
+	assert.Equal(t, 5, ssars, "denials are not cached without a denied TTL")
+
+	failing = true
+	for range 2 {
+		_, err := client("token-a").CanAccess(ctx, nil, "list", "", "services", "ns")
+		require.Error(t, err)
+	}
+	assert.Equal(t, 7, ssars, "errors are never cached")
+
+	user, err := client("token-a").GetUser(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "user@example.com", user)
+	groups, err := client("token-a").GetGroups(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"team-a"}, groups)
+	assert.Equal(t, 1, ssrs, "the user and groups come from one review")
+}
+
+func TestInternalKubernetesClient_ReviewCache(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	var sars int
+	clientset.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		sars++
+		sar := action.(k8stesting.CreateAction).GetObject().(*authv1.SubjectAccessReview)
+		sar.Status.Allowed = sar.Spec.User == "admin@example.com"
+		return true, sar, nil
+	})
+
+	reviews := NewReviewCache(ReviewCacheConfig{AllowedTTL: time.Minute, DeniedTTL: 50 * time.Millisecond})
+	kc := &InternalKubernetesClient{SharedClientLogic: SharedClientLogic{Client: clientset, Logger: testLogger(), Reviews: reviews}}
+	ctx := context.Background()
+
+	_, err := kc.CanAccess(ctx, &RequestIdentity{UserID: "admin@example.com", Groups: []string{"a", "b"}}, "get", "", "pods", "ns")
+	require.NoError(t, err)
+	allowed, err := kc.CanAccess(ctx, &RequestIdentity{UserID: "admin@example.com", Groups: []string{"b", "a"}}, "get", "", "pods", "ns")
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, 1, sars, "keyed by user and groups, in any order")
+
+	_, err = kc.CanAccess(ctx, &RequestIdentity{UserID: "admin@example.com"}, "get", "", "pods", "ns")
+	require.NoError(t, err)
+	assert.Equal(t, 2, sars, "other groups are another identity")
+
+	for range 2 {
+		allowed, err = kc.CanAccess(ctx, &RequestIdentity{UserID: "dev@example.com"}, "get", "", "pods", "ns")
+		require.NoError(t, err)
+		assert.False(t, allowed)
+	}
+	assert.Equal(t, 3, sars, "denials are cached for the denied TTL")
+	time.Sleep(100 * time.Millisecond)
+	_, err = kc.CanAccess(ctx, &RequestIdentity{UserID: "dev@example.com"}, "get", "", "pods", "ns")
+	require.NoError(t, err)
+	assert.Equal(t, 4, sars, "then reviewed again")
+}
+
+func TestNewReviewCache(t *testing.T) {
+	assert.Nil(t, NewReviewCache(ReviewCacheConfig{MaxEntries: 10}), "nothing to cache")
+
+	reviews := NewReviewCache(ReviewCacheConfig{AllowedTTL: time.Minute, MaxEntries: 1})
+	calls := 0
+	review := func() (bool, error) { calls++; return true, nil }
+	attrs := func(namespace string) *authv1.ResourceAttributes {
+		return &authv1.ResourceAttributes{Verb: "get", Resource: "pods", Namespace: namespace}
+	}
+	_, _ = reviews.access("key", attrs("a"), review)
+	_, _ = reviews.access("key", attrs("b"), review)
+	_, _ = reviews.access("key", attrs("a"), review)
+	assert.Equal(t, 3, calls, "the least recently used result is evicted")
+
+	var nilCache *ReviewCache
+	nilCache.Observe(func(string, bool) {})
+	_, _ = nilCache.access("key", attrs("a"), review)
+	_, _ = reviews.access("", attrs("a"), review)
+	assert.Equal(t, 5, calls, "nothing is cached without a cache or key")
+}
+
+func TestReviewCache_Prune(t *testing.T) {
+	reviews := NewReviewCache(ReviewCacheConfig{AllowedTTL: time.Minute, DeniedTTL: time.Millisecond})
+	attrs := &authv1.ResourceAttributes{Verb: "get", Resource: "pods", Namespace: "ns"}
+	_, _ = reviews.access("allowed", attrs, func() (bool, error) { return true, nil })
+	_, _ = reviews.access("denied", attrs, func() (bool, error) { return false, nil })
+	time.Sleep(5 * time.Millisecond)
+
+	stored := func() int {
+		n := 0
+		reviews.entries.RemoveAll(func(any) bool { n++; return false })
+		return n
+	}
+	require.Equal(t, 2, stored(), "expired reviews stay until looked up")
+	reviews.Prune()
+	assert.Equal(t, 1, stored())
+	_, ok := reviews.lookup(ReviewAccess, "allowed\x00get\x00\x00pods\x00\x00ns\x00")
+	assert.True(t, ok, "live reviews are kept")
+
+	var nilCache *ReviewCache
+	nilCache.Prune()
+}
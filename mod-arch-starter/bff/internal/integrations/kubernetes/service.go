@@ -0,0 +1,24 @@
+package kubernetes
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ServicePortURL returns the in-cluster URL of a Service port. An empty scheme is inferred:
+// https when the port is named "https" or its appProtocol is https, http otherwise.
+func ServicePortURL(svc *corev1.Service, port *corev1.ServicePort, scheme string) *url.URL {
+	if scheme == "" {
+		scheme = "http"
+		if port.Name == "https" || (port.AppProtocol != nil && strings.EqualFold(*port.AppProtocol, "https")) {
+			scheme = "https"
+		}
+	}
+	return &url.URL{
+		Scheme: scheme,
+		Host:   fmt.Sprintf("%s.%s.svc.cluster.local:%d", svc.Name, svc.Namespace, port.Port),
+	}
+}
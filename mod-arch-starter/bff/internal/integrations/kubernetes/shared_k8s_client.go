@@ -2,19 +2,153 @@ package kubernetes
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"log/slog"
+	"slices"
+	"strings"
+	"sync/atomic"
 
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/openshift"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/parallel"
+	authv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
 )
 
 type SharedClientLogic struct {
 	Client kubernetes.Interface
+	// Dynamic is used to watch arbitrary (including custom) resources.
+	// When nil, only core ("" group, v1) resources can be watched.
+	Dynamic dynamic.Interface
+	// Cache serves namespace and service reads from shared informers.
+	// When nil, Reader reads straight from the API server.
+	Cache  *ResourceCache
 	Logger *slog.Logger
 	Token  BearerToken
+	// OpenShift is set on OpenShift clusters and nil otherwise.
+	OpenShift *openshift.Client
+	// Reviews memoizes the authentication and access reviews of the client; nil disables it.
+	Reviews *ReviewCache
 }
 
 // Service discovery helpers removed for minimal starter footprint.
 
 func (kc *SharedClientLogic) BearerToken() (string, error) { return kc.Token.Raw(), nil }
 
-func (kc *SharedClientLogic) GetGroups(ctx context.Context) ([]string, error) { return []string{}, nil }
+// mergeGroups concatenates lists, dropping empty and duplicate group names. The result is never nil.
+func mergeGroups(lists ...[]string) []string {
+	merged := []string{}
+	for _, list := range lists {
+		for _, group := range list {
+			if group != "" && !slices.Contains(merged, group) {
+				merged = append(merged, group)
+			}
+		}
+	}
+	return merged
+}
+
+// Reader returns the client's informer cache, or a reader that goes straight to the
+// API server with the client's own credentials when there is no cache.
+func (kc *SharedClientLogic) Reader() ResourceReader {
+	if kc.Cache != nil {
+		return kc.Cache
+	}
+	return newLiveReader(kc.Client)
+}
+
+// DynamicResource returns the dynamic client of gvr; it fails without a dynamic client.
+func (kc *SharedClientLogic) DynamicResource(gvr schema.GroupVersionResource) (dynamic.NamespaceableResourceInterface, error) {
+	if kc.Dynamic == nil {
+		return nil, fmt.Errorf("accessing %s requires a dynamic client", gvr.String())
+	}
+	return kc.Dynamic.Resource(gvr), nil
+}
+
+// watchResource opens a watch with the client's own credentials; authorization is up to the caller.
+func (kc *SharedClientLogic) watchResource(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (watch.Interface, error) {
+	if kc.Dynamic != nil {
+		return kc.Dynamic.Resource(gvr).Namespace(namespace).Watch(ctx, opts)
+	}
+
+	if gvr.Group != "" || gvr.Version != "v1" {
+		return nil, fmt.Errorf("watching %s requires a dynamic client", gvr.String())
+	}
+	return kc.Client.CoreV1().RESTClient().Get().
+		Namespace(namespace).
+		Resource(gvr.Resource).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Watch(ctx)
+}
+
+// streamPodLogs opens a pod log stream with the client's own credentials; authorization is up
+// to the caller.
+func (kc *SharedClientLogic) streamPodLogs(ctx context.Context, namespace, pod string, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+	return kc.Client.CoreV1().Pods(namespace).GetLogs(pod, opts).Stream(ctx)
+}
+
+// selfRulesReview lists the rules of the credentials of client in namespace.
+func selfRulesReview(ctx context.Context, client kubernetes.Interface, namespace string) (*authv1.SubjectRulesReviewStatus, error) {
+	review := &authv1.SelfSubjectRulesReview{Spec: authv1.SelfSubjectRulesReviewSpec{Namespace: namespace}}
+	resp, err := client.AuthorizationV1().SelfSubjectRulesReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to perform SelfSubjectRulesReview: %w", err)
+	}
+	return &resp.Status, nil
+}
+
+// resourceAttributes returns the attributes of an access review. resource may name a
+// subresource, e.g. "pods/log".
+func resourceAttributes(verb, group, resource, namespace string) *authv1.ResourceAttributes {
+	resource, subresource, _ := strings.Cut(resource, "/")
+	return &authv1.ResourceAttributes{
+		Verb:        verb,
+		Group:       group,
+		Resource:    resource,
+		Subresource: subresource,
+		Namespace:   namespace,
+	}
+}
+
+// namespaceAccessWorkers is the fixed number of workers used to run per-namespace access
+// reviews in parallel, providing better resource control on large clusters.
+const namespaceAccessWorkers = 10
+
+// filterAccessibleNamespaces returns the namespaces for which canAccess reports true,
+// running the checks on a bounded worker pool. Namespaces whose check fails are logged
+// and treated as inaccessible. The original ordering is preserved.
+// If ctx is cancelled the namespaces resolved so far are returned along with ctx.Err().
+func filterAccessibleNamespaces(ctx context.Context, logger *slog.Logger, namespaces []corev1.Namespace, canAccess func(ctx context.Context, namespace string) (bool, error)) ([]corev1.Namespace, error) {
+	allowedIdx := make([]bool, len(namespaces))
+	var errorCount atomic.Int32
+	err := parallel.ForEach(ctx, parallel.Options{Limit: namespaceAccessWorkers}, len(namespaces), func(ctx context.Context, i int) {
+		allowed, err := canAccess(ctx, namespaces[i].Name)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Error("failed SAR for namespace", "namespace", namespaces[i].Name, "error", err)
+			}
+			errorCount.Add(1)
+			return
+		}
+		allowedIdx[i] = allowed
+	})
+
+	allowed := []corev1.Namespace{}
+	for i, ns := range namespaces {
+		if allowedIdx[i] {
+			allowed = append(allowed, ns)
+		}
+	}
+
+	if count := errorCount.Load(); count > 0 {
+		logger.Debug("namespace access checks finished with errors", "errors", count, "total", len(namespaces))
+	}
+
+	return allowed, err
+}
@@ -3,6 +3,7 @@ package kubernetes
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"strings"
 	"time"
@@ -12,6 +13,9 @@ import (
 	authv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
@@ -19,10 +23,16 @@ import (
 type TokenKubernetesClient struct {
 	SharedClientLogic
 	restConfig *rest.Config
+	// reviewKey identifies the credentials of the client in the review cache: a hash of the
+	// token, or the impersonated identity.
+	reviewKey string
 }
 
-func (kc *TokenKubernetesClient) IsClusterAdmin(_ *RequestIdentity) (bool, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (kc *TokenKubernetesClient) IsClusterAdmin(ctx context.Context, identity *RequestIdentity) (bool, error) {
+	if identity != nil && identity.Guest {
+		return false, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	// We cannot list ClusterRoleBindings here because this client is initialized with a user token,
@@ -30,22 +40,16 @@ func (kc *TokenKubernetesClient) IsClusterAdmin(_ *RequestIdentity) (bool, error
 	// Instead, we use a SelfSubjectAccessReview with wildcard '*' verb and resource,
 	// which safely asks the Kubernetes API server: "Can I do everything?"
 	// If the review returns allowed=true, it means the user has cluster-admin-equivalent permissions.
-	sar := &authv1.SelfSubjectAccessReview{
-		Spec: authv1.SelfSubjectAccessReviewSpec{
-			ResourceAttributes: &authv1.ResourceAttributes{
-				Verb:     "*",
-				Resource: "*",
-			},
-		},
-	}
-
-	resp, err := kc.Client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, sar, metav1.CreateOptions{})
+	allowed, err := kc.selfAccessReview(ctx, &authv1.ResourceAttributes{
+		Verb:     "*",
+		Resource: "*",
+	})
 	if err != nil {
 		kc.Logger.Error("failed to perform cluster-admin SAR", "error", err)
 		return false, fmt.Errorf("failed to verify cluster-admin permissions: %w", err)
 	}
 
-	if !resp.Status.Allowed {
+	if !allowed {
 		kc.Logger.Info("user is NOT cluster-admin")
 		return false, nil
 	}
@@ -63,6 +67,54 @@ func NewTokenKubernetesClient(token string, logger *slog.Logger) (KubernetesClie
 		return nil, fmt.Errorf("failed to get kubeconfig: %w", err)
 	}
 
+	return NewTokenKubernetesClientForConfig(baseConfig, token, logger)
+}
+
+// NewTokenKubernetesClientForConfig creates a Kubernetes client that talks to the API server
+// described by baseConfig while authenticating strictly as the holder of token.
+// baseConfig is not modified.
+func NewTokenKubernetesClientForConfig(baseConfig *rest.Config, token string, logger *slog.Logger) (KubernetesClientInterface, error) {
+	client, err := newTokenKubernetesClientForConfig(baseConfig, token, logger)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+func newTokenKubernetesClientForConfig(baseConfig *rest.Config, token string, logger *slog.Logger) (*TokenKubernetesClient, error) {
+	cfg := NewTokenRESTConfig(baseConfig, token)
+	applyClientSettings(cfg)
+	applyTransportWrappers(cfg)
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		logger.Error("failed to create token-based Kubernetes client", "error", err)
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		logger.Error("failed to create token-based dynamic Kubernetes client", "error", err)
+		return nil, fmt.Errorf("failed to create dynamic Kubernetes client: %w", err)
+	}
+
+	return &TokenKubernetesClient{
+		SharedClientLogic: SharedClientLogic{
+			Client:  clientset,
+			Dynamic: dynamicClient,
+			Logger:  logger,
+			// Token is retained for follow-up calls; do not log it.
+			Token: NewBearerToken(token),
+		},
+		restConfig: cfg,
+		reviewKey:  tokenReviewKey(token),
+	}, nil
+}
+
+// NewTokenRESTConfig derives a rest.Config from baseConfig that authenticates only with the
+// given bearer token. Any credentials present in baseConfig (client certificates, basic auth,
+// exec/auth providers, token files) are dropped so the service account is never used.
+func NewTokenRESTConfig(baseConfig *rest.Config, token string) *rest.Config {
 	// Start with an anonymous config to avoid preloaded auth
 	cfg := rest.AnonymousClientConfig(baseConfig)
 	cfg.BearerToken = token
@@ -74,20 +126,52 @@ func NewTokenKubernetesClient(token string, logger *slog.Logger) (KubernetesClie
 	cfg.ExecProvider = nil
 	cfg.AuthProvider = nil
 
+	return cfg
+}
+
+// NewImpersonatingKubernetesClient creates a Kubernetes client that authenticates with the
+// credentials in baseConfig while impersonating identity.UserID and identity.Groups.
+// Impersonated requests are authorized as the end user, so the self-review based checks
+// implemented by TokenKubernetesClient (SelfSubjectAccessReview, SelfSubjectReview) apply as-is.
+func NewImpersonatingKubernetesClient(baseConfig *rest.Config, identity *RequestIdentity, logger *slog.Logger) (KubernetesClientInterface, error) {
+	client, err := newImpersonatingKubernetesClient(baseConfig, identity, logger)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+func newImpersonatingKubernetesClient(baseConfig *rest.Config, identity *RequestIdentity, logger *slog.Logger) (*TokenKubernetesClient, error) {
+	cfg := rest.CopyConfig(baseConfig)
+	cfg.Impersonate = rest.ImpersonationConfig{
+		UserName: identity.UserID,
+		Groups:   identity.Groups,
+	}
+	applyClientSettings(cfg)
+	applyTransportWrappers(cfg)
+
 	clientset, err := kubernetes.NewForConfig(cfg)
 	if err != nil {
-		logger.Error("failed to create token-based Kubernetes client", "error", err)
+		logger.Error("failed to create impersonating Kubernetes client", "error", err)
 		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
 	}
 
+	dynamicClient, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		logger.Error("failed to create impersonating dynamic Kubernetes client", "error", err)
+		return nil, fmt.Errorf("failed to create dynamic Kubernetes client: %w", err)
+	}
+
 	return &TokenKubernetesClient{
 		SharedClientLogic: SharedClientLogic{
-			Client: clientset,
-			Logger: logger,
-			// Token is retained for follow-up calls; do not log it.
-			Token: NewBearerToken(token),
+			Client:  clientset,
+			Dynamic: dynamicClient,
+			Logger:  logger,
+			// Never hand out the backend credentials as the user's token.
+			Token: NewBearerToken(""),
 		},
 		restConfig: cfg,
+		reviewKey:  identityReviewKey(identity),
 	}, nil
 }
 
@@ -104,23 +188,17 @@ func (kc *TokenKubernetesClient) CanListServicesInNamespace(ctx context.Context,
 	defer cancel()
 
 	for _, verb := range []string{"get", "list"} {
-		sar := &authv1.SelfSubjectAccessReview{
-			Spec: authv1.SelfSubjectAccessReviewSpec{
-				ResourceAttributes: &authv1.ResourceAttributes{
-					Verb:      verb,
-					Resource:  "services",
-					Namespace: namespace,
-				},
-			},
-		}
-
-		resp, err := kc.Client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, sar, metav1.CreateOptions{})
+		allowed, err := kc.selfAccessReview(ctx, &authv1.ResourceAttributes{
+			Verb:      verb,
+			Resource:  "services",
+			Namespace: namespace,
+		})
 		if err != nil {
 			kc.Logger.Error("self-SAR failed", "namespace", namespace, "verb", verb, "error", err)
 			return false, err
 		}
 
-		if !resp.Status.Allowed {
+		if !allowed {
 			kc.Logger.Error("self-SAR denied", "namespace", namespace, "verb", verb)
 			return false, nil
 		}
@@ -134,23 +212,17 @@ func (kc *TokenKubernetesClient) CanAccessServiceInNamespace(ctx context.Context
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	sar := &authv1.SelfSubjectAccessReview{
-		Spec: authv1.SelfSubjectAccessReviewSpec{
-			ResourceAttributes: &authv1.ResourceAttributes{
-				Verb:      "get",
-				Resource:  "services",
-				Namespace: namespace,
-				Name:      serviceName,
-			},
-		},
-	}
-
-	resp, err := kc.Client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, sar, metav1.CreateOptions{})
+	allowed, err := kc.selfAccessReview(ctx, &authv1.ResourceAttributes{
+		Verb:      "get",
+		Resource:  "services",
+		Namespace: namespace,
+		Name:      serviceName,
+	})
 	if err != nil {
 		kc.Logger.Error("self-SAR failed", "service", serviceName, "namespace", namespace, "error", err)
 		return false, err
 	}
-	if !resp.Status.Allowed {
+	if !allowed {
 		kc.Logger.Error("self-SAR denied", "service", serviceName, "namespace", namespace)
 		return false, nil
 	}
@@ -159,38 +231,136 @@ func (kc *TokenKubernetesClient) CanAccessServiceInNamespace(ctx context.Context
 }
 
 // RequestIdentity is unused because the token already represents the user identity.
-// This endpoint is used only on dev mode that is why is safe to ignore permissions errors
-func (kc *TokenKubernetesClient) GetNamespaces(ctx context.Context, _ *RequestIdentity) ([]corev1.Namespace, error) {
+func (kc *TokenKubernetesClient) CanAccess(ctx context.Context, _ *RequestIdentity, verb, group, resource, namespace string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	allowed, err := kc.selfAccessReview(ctx, resourceAttributes(verb, group, resource, namespace))
+	if err != nil {
+		kc.Logger.Error("self-SAR failed", "verb", verb, "resource", resource, "namespace", namespace, "error", err)
+		return false, fmt.Errorf("failed to perform SelfSubjectAccessReview: %w", err)
+	}
+
+	return allowed, nil
+}
+
+// GetRules runs the SelfSubjectRulesReview with the caller's own credentials;
+// RequestIdentity is unused.
+func (kc *TokenKubernetesClient) GetRules(ctx context.Context, _ *RequestIdentity, namespace string) (*authv1.SubjectRulesReviewStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	status, err := selfRulesReview(ctx, kc.Client, namespace)
+	if err != nil {
+		kc.Logger.Error("self rules review failed", "namespace", namespace, "error", err)
+		return nil, err
+	}
+	return status, nil
+}
+
+// selfAccessReview reports whether the client's credentials may act on attrs, with a
+// SelfSubjectAccessReview memoized by the review cache.
+func (kc *TokenKubernetesClient) selfAccessReview(ctx context.Context, attrs *authv1.ResourceAttributes) (bool, error) {
+	return kc.Reviews.access(kc.reviewKey, attrs, func() (bool, error) {
+		sar := &authv1.SelfSubjectAccessReview{
+			Spec: authv1.SelfSubjectAccessReviewSpec{ResourceAttributes: attrs},
+		}
+		resp, err := kc.Client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, sar, metav1.CreateOptions{})
+		if err != nil {
+			return false, err
+		}
+		return resp.Status.Allowed, nil
+	})
+}
+
+// selfSubjectReview returns who the client's credentials authenticate as, with a
+// SelfSubjectReview memoized by the review cache.
+func (kc *TokenKubernetesClient) selfSubjectReview(ctx context.Context) (authnv1.UserInfo, error) {
+	return kc.Reviews.subject(kc.reviewKey, func() (authnv1.UserInfo, error) {
+		ssr := &authnv1.SelfSubjectReview{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "SelfSubjectReview",
+				APIVersion: "authentication.k8s.io/v1",
+			},
+		}
+		resp, err := kc.Client.AuthenticationV1().SelfSubjectReviews().Create(ctx, ssr, metav1.CreateOptions{})
+		if err != nil {
+			return authnv1.UserInfo{}, err
+		}
+		return resp.Status.UserInfo, nil
+	})
+}
+
+// RequestIdentity is unused because the token already represents the user identity.
+// The namespace list is filtered with a SelfSubjectAccessReview per namespace so only
+// namespaces the token holder can actually get are returned. Cluster admins skip the
+// per-namespace checks. On OpenShift the user's Projects are listed instead, which the API
+// server already filters by access.
+func (kc *TokenKubernetesClient) GetNamespaces(ctx context.Context, identity *RequestIdentity) ([]corev1.Namespace, error) {
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	nsList, err := kc.Client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if kc.OpenShift != nil {
+		namespaces, err := kc.OpenShift.ListProjects(ctx)
+		if err == nil {
+			return namespaces, nil
+		}
+		kc.Logger.Warn("failed to list projects, listing namespaces", "error", err)
+	}
+
+	namespaces, err := kc.Reader().ListNamespaces(ctx)
 	if err != nil {
 		kc.Logger.Error("user is not allowed to list namespaces or failed to list namespaces")
 		return []corev1.Namespace{}, fmt.Errorf("failed to list namespaces: %w", err)
 	}
 
-	return nsList.Items, nil
+	isAdmin, err := kc.IsClusterAdmin(ctx, identity)
+	if err != nil {
+		kc.Logger.Warn("failed to check cluster admin status", "error", err)
+	} else if isAdmin {
+		return namespaces, nil
+	}
+
+	allowed, err := filterAccessibleNamespaces(ctx, kc.Logger, namespaces, func(ctx context.Context, namespace string) (bool, error) {
+		return kc.CanAccess(ctx, identity, "get", "", "namespaces", namespace)
+	})
+	if err != nil {
+		kc.Logger.Warn("context cancelled during namespace access checks", "error", err)
+		return allowed, err
+	}
+
+	return allowed, nil
+}
+
+// WatchResource opens the watch with the caller's own credentials, so the API server
+// authorizes it; RequestIdentity is unused.
+func (kc *TokenKubernetesClient) WatchResource(ctx context.Context, _ *RequestIdentity, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (watch.Interface, error) {
+	return kc.watchResource(ctx, gvr, namespace, opts)
+}
+
+// StreamPodLogs opens the stream with the caller's own credentials, so the API server
+// authorizes it; RequestIdentity is unused.
+func (kc *TokenKubernetesClient) StreamPodLogs(ctx context.Context, _ *RequestIdentity, namespace, pod string, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+	return kc.streamPodLogs(ctx, namespace, pod, opts)
 }
 
-func (kc *TokenKubernetesClient) GetUser(_ *RequestIdentity) (string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (kc *TokenKubernetesClient) GetUser(ctx context.Context, _ *RequestIdentity) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	ssr := &authnv1.SelfSubjectReview{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "SelfSubjectReview",
-			APIVersion: "authentication.k8s.io/v1",
-		},
+	userInfo, err := kc.selfSubjectReview(ctx)
+	if err != nil && kc.OpenShift != nil {
+		// OpenShift releases older than 4.15 don't serve SelfSubjectReviews
+		if user, userErr := kc.OpenShift.CurrentUser(ctx); userErr == nil {
+			return user.Name, nil
+		}
 	}
-
-	resp, err := kc.Client.AuthenticationV1().SelfSubjectReviews().Create(ctx, ssr, metav1.CreateOptions{})
 	if err != nil {
 		kc.Logger.Error("failed to get user identity from token", "error", err)
 		return "", fmt.Errorf("failed to get user identity: %w", err)
 	}
 
-	username := resp.Status.UserInfo.Username
+	username := userInfo.Username
 	if username == "" {
 		kc.Logger.Error("user identity not found in token")
 		return "", fmt.Errorf("no username found in token")
@@ -208,3 +378,28 @@ func (kc *TokenKubernetesClient) GetUser(_ *RequestIdentity) (string, error) {
 
 	return username, nil
 }
+
+// GetGroups returns the groups the API server authenticated the token (or the impersonated
+// identity) with, from a SelfSubjectReview, plus the groups of the OpenShift User (users/~)
+// on OpenShift.
+// RequestIdentity is unused because the token already represents the user identity.
+func (kc *TokenKubernetesClient) GetGroups(ctx context.Context, _ *RequestIdentity) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	userInfo, err := kc.selfSubjectReview(ctx)
+	if err != nil {
+		kc.Logger.Error("failed to get user groups from token", "error", err)
+		return nil, fmt.Errorf("failed to get user groups: %w", err)
+	}
+
+	groups := userInfo.Groups
+	if kc.OpenShift != nil {
+		if user, err := kc.OpenShift.CurrentUser(ctx); err != nil {
+			kc.Logger.Debug("failed to get OpenShift user groups", "error", err)
+		} else {
+			groups = mergeGroups(groups, user.Groups)
+		}
+	}
+	return mergeGroups(groups), nil
+}
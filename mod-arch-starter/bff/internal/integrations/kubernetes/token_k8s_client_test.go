@@ -0,0 +1,61 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+)
+
+func TestNewTokenRESTConfig(t *testing.T) {
+	base := &rest.Config{
+		Host:            "https://api.example.com:6443",
+		BearerToken:     "service-account-token",
+		BearerTokenFile: "/var/run/secrets/token",
+		Username:        "admin",
+		Password:        "secret",
+		TLSClientConfig: rest.TLSClientConfig{
+			CAData:   []byte("ca"),
+			CertData: []byte("cert"),
+			KeyData:  []byte("key"),
+		},
+	}
+
+	cfg := NewTokenRESTConfig(base, "user-token")
+
+	assert.Equal(t, "https://api.example.com:6443", cfg.Host)
+	assert.Equal(t, "user-token", cfg.BearerToken)
+	assert.Empty(t, cfg.BearerTokenFile)
+	assert.Empty(t, cfg.Username)
+	assert.Empty(t, cfg.Password)
+	assert.Empty(t, cfg.CertData)
+	assert.Empty(t, cfg.KeyData)
+	assert.Equal(t, []byte("ca"), cfg.CAData)
+
+	// the base config must be left untouched
+	assert.Equal(t, "service-account-token", base.BearerToken)
+	assert.Equal(t, []byte("cert"), base.CertData)
+}
+
+func TestImpersonationClientFactory_GetClient(t *testing.T) {
+	base := &rest.Config{Host: "https://api.example.com:6443", BearerToken: "service-account-token"}
+	factory := NewImpersonationClientFactoryForConfig(base, testLogger())
+
+	_, err := factory.GetClient(context.Background())
+	assert.Error(t, err)
+
+	identity := &RequestIdentity{UserID: "user@example.com", Groups: []string{"team-a"}}
+	ctx := context.WithValue(context.Background(), constants.RequestIdentityKey, identity)
+	client, err := factory.GetClient(ctx)
+	require.NoError(t, err)
+
+	tokenClient, ok := client.(*TokenKubernetesClient)
+	require.True(t, ok)
+	assert.Equal(t, "user@example.com", tokenClient.RESTConfig().Impersonate.UserName)
+	assert.Equal(t, []string{"team-a"}, tokenClient.RESTConfig().Impersonate.Groups)
+	assert.Empty(t, tokenClient.Token.Raw())
+	assert.Empty(t, base.Impersonate.UserName)
+}
@@ -0,0 +1,81 @@
+package kubernetes
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/transport"
+)
+
+// RequestIDTransportWrapper is the name of the built-in wrapper that tags API server calls with
+// the ID of the BFF request they serve.
+const RequestIDTransportWrapper = "request-id"
+
+var (
+	transportWrappersMu sync.RWMutex
+	transportWrappers   = map[string]transport.WrapperFunc{
+		RequestIDTransportWrapper: WrapRequestID,
+	}
+)
+
+// RegisterTransportWrapper installs a wrapper around the HTTP transport of every Kubernetes
+// client created afterwards, e.g. to record metrics or traces of API server calls.
+// Wrappers are keyed by name, so registering the same name again replaces the previous wrapper;
+// a nil wrapper removes it. Call this before the client factory is created.
+func RegisterTransportWrapper(name string, wrapper transport.WrapperFunc) {
+	transportWrappersMu.Lock()
+	defer transportWrappersMu.Unlock()
+
+	if wrapper == nil {
+		delete(transportWrappers, name)
+		return
+	}
+	transportWrappers[name] = wrapper
+}
+
+// applyTransportWrappers adds the registered wrappers to cfg in name order.
+func applyTransportWrappers(cfg *rest.Config) {
+	transportWrappersMu.RLock()
+	defer transportWrappersMu.RUnlock()
+
+	names := make([]string, 0, len(transportWrappers))
+	for name := range transportWrappers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		cfg.Wrap(transportWrappers[name])
+	}
+}
+
+// WrapRequestID tags every API server call made for a BFF request with the request ID: it is
+// sent as X-Request-ID and appended to the User-Agent as "request-id/<id>". The API server
+// records the User-Agent in its audit events, so BFF actions can be correlated with its logs.
+func WrapRequestID(rt http.RoundTripper) http.RoundTripper {
+	return requestIDRoundTripper{next: rt}
+}
+
+type requestIDRoundTripper struct {
+	next http.RoundTripper
+}
+
+func (rt requestIDRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestID, _ := req.Context().Value(constants.RequestIdKey).(string)
+	if requestID == "" {
+		return rt.next.RoundTrip(req)
+	}
+
+	// A RoundTripper must not modify the request it was given
+	req = req.Clone(req.Context())
+	req.Header.Set(constants.RequestIDHeader, requestID)
+	userAgent := req.Header.Get("User-Agent")
+	if userAgent == "" {
+		userAgent = rest.DefaultKubernetesUserAgent()
+	}
+	req.Header.Set("User-Agent", userAgent+" request-id/"+requestID)
+	return rt.next.RoundTrip(req)
+}
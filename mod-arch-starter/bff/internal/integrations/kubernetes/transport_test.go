@@ -0,0 +1,43 @@
+package kubernetes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func TestWrapRequestID(t *testing.T) {
+	var userAgent, requestID string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		requestID = r.Header.Get(constants.RequestIDHeader)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"NamespaceList","apiVersion":"v1","items":[]}`))
+	}))
+	defer srv.Close()
+
+	cfg := &rest.Config{Host: srv.URL, UserAgent: "mod-arch-bff"}
+	applyTransportWrappers(cfg)
+	clientset, err := kubernetes.NewForConfig(cfg)
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), constants.RequestIdKey, "req-123")
+	_, err = clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "mod-arch-bff request-id/req-123", userAgent)
+	assert.Equal(t, "req-123", requestID)
+
+	// Calls made outside of a BFF request are left untouched
+	_, err = clientset.CoreV1().Namespaces().List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "mod-arch-bff", userAgent)
+	assert.Empty(t, requestID)
+}
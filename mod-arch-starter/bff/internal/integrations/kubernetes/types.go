@@ -1,5 +1,12 @@
 package kubernetes
 
+import (
+	"net/http"
+	"strings"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+)
+
 type ServiceDetails struct {
 	Name                string
 	DisplayName         string
@@ -14,6 +21,29 @@ type RequestIdentity struct {
 	UserID string
 	Groups []string
 	Token  string
+	// Guest marks the synthetic identity of guest mode, which is never a cluster admin.
+	Guest bool
+}
+
+// SetForwardedHeaders replaces any identity headers in h with this identity, for requests the
+// BFF makes to upstream services on the caller's behalf: the token goes in tokenHeader (after
+// tokenPrefix); without a token the user and groups go in kubeflow-userid/kubeflow-groups.
+// A nil identity only removes the headers.
+func (i *RequestIdentity) SetForwardedHeaders(h http.Header, tokenHeader, tokenPrefix string) {
+	h.Del(tokenHeader)
+	h.Del(constants.KubeflowUserIDHeader)
+	h.Del(constants.KubeflowUserGroupsIdHeader)
+
+	switch {
+	case i == nil:
+	case i.Token != "":
+		h.Set(tokenHeader, tokenPrefix+i.Token)
+	case i.UserID != "":
+		h.Set(constants.KubeflowUserIDHeader, i.UserID)
+		if len(i.Groups) > 0 {
+			h.Set(constants.KubeflowUserGroupsIdHeader, strings.Join(i.Groups, ","))
+		}
+	}
 }
 
 type BearerToken struct {
@@ -0,0 +1,184 @@
+// Package modelregistry is a typed client of the Kubeflow model registry REST API: registered
+// models, their versions and the model artifacts of the versions. Requests go through
+// mrserver.UpstreamClient, so they carry the caller's identity and are retried like every
+// upstream call, and error responses keep their status in the BFF response.
+package modelregistry
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	mrserver "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/httpclient"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+)
+
+const (
+	// APIPath is the prefix of the REST API on the model registry server.
+	APIPath = "/api/model_registry/v1alpha3"
+
+	// ServiceSelector is the label selector of model registry Services.
+	ServiceSelector = "component=model-registry"
+)
+
+// ClientInterface is implemented by Client; repositories depend on it so tests can fake the registry.
+type ClientInterface interface {
+	ListRegisteredModels(ctx context.Context, opts ListOptions) (*models.RegisteredModelList, error)
+	GetRegisteredModel(ctx context.Context, id string) (*models.RegisteredModel, error)
+	CreateRegisteredModel(ctx context.Context, model models.RegisteredModel) (*models.RegisteredModel, error)
+	UpdateRegisteredModel(ctx context.Context, id string, model models.RegisteredModel) (*models.RegisteredModel, error)
+
+	ListModelVersions(ctx context.Context, registeredModelID string, opts ListOptions) (*models.ModelVersionList, error)
+	GetModelVersion(ctx context.Context, id string) (*models.ModelVersion, error)
+	CreateModelVersion(ctx context.Context, registeredModelID string, version models.ModelVersion) (*models.ModelVersion, error)
+	UpdateModelVersion(ctx context.Context, id string, version models.ModelVersion) (*models.ModelVersion, error)
+
+	ListModelArtifacts(ctx context.Context, modelVersionID string, opts ListOptions) (*models.ModelArtifactList, error)
+	GetModelArtifact(ctx context.Context, id string) (*models.ModelArtifact, error)
+	CreateModelArtifact(ctx context.Context, modelVersionID string, artifact models.ModelArtifact) (*models.ModelArtifact, error)
+	UpdateModelArtifact(ctx context.Context, id string, artifact models.ModelArtifact) (*models.ModelArtifact, error)
+}
+
+// Client calls one model registry on behalf of one caller. Create one per request.
+type Client struct {
+	upstream *mrserver.UpstreamClient
+}
+
+var _ ClientInterface = (*Client)(nil)
+
+// NewClient creates a client of the model registry served at cfg.BaseURL, the server URL with
+// or without APIPath, forwarding identity on every request.
+func NewClient(cfg mrserver.UpstreamConfig, identity *kubernetes.RequestIdentity, logger *slog.Logger) (*Client, error) {
+	cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+	if cfg.BaseURL != "" && !strings.HasSuffix(cfg.BaseURL, APIPath) {
+		cfg.BaseURL += APIPath
+	}
+	upstream, err := mrserver.NewUpstreamClient(cfg, identity, logger)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{upstream: upstream}, nil
+}
+
+// ─── REGISTERED MODELS ──────────────────────────────────────
+
+func (c *Client) ListRegisteredModels(ctx context.Context, opts ListOptions) (*models.RegisteredModelList, error) {
+	var list models.RegisteredModelList
+	if err := c.upstream.Get(ctx, withQuery("/registered_models", opts), &list); err != nil {
+		return nil, fmt.Errorf("error listing registered models: %w", err)
+	}
+	return &list, nil
+}
+
+func (c *Client) GetRegisteredModel(ctx context.Context, id string) (*models.RegisteredModel, error) {
+	var model models.RegisteredModel
+	if err := c.upstream.Get(ctx, "/registered_models/"+url.PathEscape(id), &model); err != nil {
+		return nil, fmt.Errorf("error getting registered model %s: %w", id, err)
+	}
+	return &model, nil
+}
+
+func (c *Client) CreateRegisteredModel(ctx context.Context, model models.RegisteredModel) (*models.RegisteredModel, error) {
+	var created models.RegisteredModel
+	if err := c.upstream.Post(ctx, "/registered_models", model, &created); err != nil {
+		return nil, fmt.Errorf("error creating registered model: %w", err)
+	}
+	return &created, nil
+}
+
+func (c *Client) UpdateRegisteredModel(ctx context.Context, id string, model models.RegisteredModel) (*models.RegisteredModel, error) {
+	var updated models.RegisteredModel
+	if err := c.upstream.Patch(ctx, "/registered_models/"+url.PathEscape(id), model, &updated); err != nil {
+		return nil, fmt.Errorf("error updating registered model %s: %w", id, err)
+	}
+	return &updated, nil
+}
+
+// ─── MODEL VERSIONS ─────────────────────────────────────────
+
+func (c *Client) ListModelVersions(ctx context.Context, registeredModelID string, opts ListOptions) (*models.ModelVersionList, error) {
+	var list models.ModelVersionList
+	path := "/registered_models/" + url.PathEscape(registeredModelID) + "/versions"
+	if err := c.upstream.Get(ctx, withQuery(path, opts), &list); err != nil {
+		return nil, fmt.Errorf("error listing versions of registered model %s: %w", registeredModelID, err)
+	}
+	return &list, nil
+}
+
+func (c *Client) GetModelVersion(ctx context.Context, id string) (*models.ModelVersion, error) {
+	var version models.ModelVersion
+	if err := c.upstream.Get(ctx, "/model_versions/"+url.PathEscape(id), &version); err != nil {
+		return nil, fmt.Errorf("error getting model version %s: %w", id, err)
+	}
+	return &version, nil
+}
+
+func (c *Client) CreateModelVersion(ctx context.Context, registeredModelID string, version models.ModelVersion) (*models.ModelVersion, error) {
+	version.RegisteredModelID = registeredModelID
+	var created models.ModelVersion
+	path := "/registered_models/" + url.PathEscape(registeredModelID) + "/versions"
+	if err := c.upstream.Post(ctx, path, version, &created); err != nil {
+		return nil, fmt.Errorf("error creating version of registered model %s: %w", registeredModelID, err)
+	}
+	return &created, nil
+}
+
+func (c *Client) UpdateModelVersion(ctx context.Context, id string, version models.ModelVersion) (*models.ModelVersion, error) {
+	var updated models.ModelVersion
+	if err := c.upstream.Patch(ctx, "/model_versions/"+url.PathEscape(id), version, &updated); err != nil {
+		return nil, fmt.Errorf("error updating model version %s: %w", id, err)
+	}
+	return &updated, nil
+}
+
+// ─── MODEL ARTIFACTS ────────────────────────────────────────
+
+// ListModelArtifacts lists the model artifacts of a model version; other artifact types are skipped.
+func (c *Client) ListModelArtifacts(ctx context.Context, modelVersionID string, opts ListOptions) (*models.ModelArtifactList, error) {
+	var list models.ModelArtifactList
+	path := "/model_versions/" + url.PathEscape(modelVersionID) + "/artifacts"
+	query := opts.Query()
+	query.Set("artifactType", models.ModelArtifactType)
+	if err := c.upstream.Get(ctx, path+"?"+query.Encode(), &list); err != nil {
+		return nil, fmt.Errorf("error listing artifacts of model version %s: %w", modelVersionID, err)
+	}
+	return &list, nil
+}
+
+func (c *Client) GetModelArtifact(ctx context.Context, id string) (*models.ModelArtifact, error) {
+	var artifact models.ModelArtifact
+	if err := c.upstream.Get(ctx, "/model_artifacts/"+url.PathEscape(id), &artifact); err != nil {
+		return nil, fmt.Errorf("error getting model artifact %s: %w", id, err)
+	}
+	return &artifact, nil
+}
+
+func (c *Client) CreateModelArtifact(ctx context.Context, modelVersionID string, artifact models.ModelArtifact) (*models.ModelArtifact, error) {
+	artifact.ArtifactType = models.ModelArtifactType
+	var created models.ModelArtifact
+	path := "/model_versions/" + url.PathEscape(modelVersionID) + "/artifacts"
+	if err := c.upstream.Post(ctx, path, artifact, &created); err != nil {
+		return nil, fmt.Errorf("error creating artifact of model version %s: %w", modelVersionID, err)
+	}
+	return &created, nil
+}
+
+func (c *Client) UpdateModelArtifact(ctx context.Context, id string, artifact models.ModelArtifact) (*models.ModelArtifact, error) {
+	artifact.ArtifactType = models.ModelArtifactType
+	var updated models.ModelArtifact
+	if err := c.upstream.Patch(ctx, "/model_artifacts/"+url.PathEscape(id), artifact, &updated); err != nil {
+		return nil, fmt.Errorf("error updating model artifact %s: %w", id, err)
+	}
+	return &updated, nil
+}
+
+func withQuery(path string, opts ListOptions) string {
+	query := opts.Query()
+	if len(query) == 0 {
+		return path
+	}
+	return path + "?" + query.Encode()
+}
@@ -0,0 +1,134 @@
+package modelregistry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	mrserver "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/httpclient"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(mrserver.UpstreamConfig{BaseURL: server.URL, MaxRetries: -1},
+		&kubernetes.RequestIdentity{Token: "token-1"}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	return client
+}
+
+func TestClient_ListRegisteredModels(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, APIPath+"/registered_models", r.URL.Path)
+		assert.Equal(t, "10", r.URL.Query().Get("pageSize"))
+		assert.Equal(t, OrderByLastUpdateTime, r.URL.Query().Get("orderBy"))
+		assert.Equal(t, "Bearer token-1", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"items":[{"id":"1","name":"fraud","customProperties":{"team":{"metadataType":"MetadataStringValue","string_value":"risk"}}}],"nextPageToken":"abc","pageSize":10,"size":1}`))
+	})
+
+	list, err := client.ListRegisteredModels(context.Background(), ListOptions{PageSize: 10, OrderBy: OrderByLastUpdateTime})
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+	assert.Equal(t, "fraud", list.Items[0].Name)
+	assert.Equal(t, "risk", *list.Items[0].CustomProperties["team"].StringValue)
+	assert.Equal(t, "abc", list.NextPageToken)
+}
+
+func TestClient_CreateModelVersionAndArtifact(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		switch r.URL.Path {
+		case APIPath + "/registered_models/1/versions":
+			assert.Equal(t, "1", body["registeredModelId"])
+			_, _ = w.Write([]byte(`{"id":"2","name":"v1","registeredModelId":"1"}`))
+		case APIPath + "/model_versions/2/artifacts":
+			assert.Equal(t, models.ModelArtifactType, body["artifactType"])
+			_, _ = w.Write([]byte(`{"id":"3","uri":"s3://bucket/model","artifactType":"model-artifact"}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+	ctx := context.Background()
+
+	version, err := client.CreateModelVersion(ctx, "1", models.ModelVersion{Name: "v1"})
+	require.NoError(t, err)
+	assert.Equal(t, "2", version.ID)
+
+	artifact, err := client.CreateModelArtifact(ctx, version.ID, models.ModelArtifact{URI: "s3://bucket/model"})
+	require.NoError(t, err)
+	assert.Equal(t, "3", artifact.ID)
+}
+
+func TestClient_ErrorKeepsStatus(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"code":"404","message":"no registered model found for id 7"}`))
+	})
+
+	_, err := client.GetRegisteredModel(context.Background(), "7")
+	var httpErr *mrserver.HTTPError
+	require.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, http.StatusNotFound, httpErr.StatusCode)
+	assert.Equal(t, "no registered model found for id 7", httpErr.Message)
+}
+
+func TestNewClient_APIPath(t *testing.T) {
+	for _, baseURL := range []string{"http://registry:8080", "http://registry:8080/", "http://registry:8080" + APIPath} {
+		client, err := NewClient(mrserver.UpstreamConfig{BaseURL: baseURL}, nil, slog.Default())
+		require.NoError(t, err)
+		assert.NotNil(t, client)
+	}
+	_, err := NewClient(mrserver.UpstreamConfig{}, nil, slog.Default())
+	assert.Error(t, err)
+}
+
+func TestParseListOptions(t *testing.T) {
+	opts, err := ParseListOptions(url.Values{"pageSize": {"25"}, "sortOrder": {SortOrderDesc}, "nextPageToken": {"t"}})
+	require.NoError(t, err)
+	assert.Equal(t, ListOptions{PageSize: 25, SortOrder: SortOrderDesc, NextPageToken: "t"}, opts)
+	assert.Equal(t, "nextPageToken=t&pageSize=25&sortOrder=DESC", opts.Query().Encode())
+
+	for _, query := range []url.Values{
+		{"pageSize": {"0"}},
+		{"pageSize": {"many"}},
+		{"orderBy": {"NAME"}},
+		{"sortOrder": {"up"}},
+	} {
+		_, err := ParseListOptions(query)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestListAll(t *testing.T) {
+	pages := map[string]*models.RegisteredModelList{
+		"":   {Items: []models.RegisteredModel{{ID: "1"}, {ID: "2"}}, NextPageToken: "p2"},
+		"p2": {Items: []models.RegisteredModel{{ID: "3"}}},
+	}
+	list := func(_ context.Context, opts ListOptions) (*models.RegisteredModelList, error) {
+		assert.Equal(t, int32(2), opts.PageSize)
+		return pages[opts.NextPageToken], nil
+	}
+
+	items, err := ListAll(context.Background(), ListOptions{PageSize: 2}, list)
+	require.NoError(t, err)
+	assert.Equal(t, []models.RegisteredModel{{ID: "1"}, {ID: "2"}, {ID: "3"}}, items)
+
+	pages["p2"].NextPageToken = "p2"
+	_, err = ListAll(context.Background(), ListOptions{PageSize: 2}, list)
+	assert.Error(t, err, "a repeated page token must not loop forever")
+}
@@ -0,0 +1,100 @@
+package modelregistry
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+)
+
+// Orderings and sort orders accepted by the model registry listings.
+const (
+	OrderByCreateTime     = "CREATE_TIME"
+	OrderByLastUpdateTime = "LAST_UPDATE_TIME"
+	OrderByID             = "ID"
+
+	SortOrderAsc  = "ASC"
+	SortOrderDesc = "DESC"
+)
+
+// MaxPageSize bounds ListOptions.PageSize.
+const MaxPageSize = 1000
+
+// ListOptions selects a page of a listing. Zero values use the registry defaults.
+type ListOptions struct {
+	PageSize      int32
+	OrderBy       string
+	SortOrder     string
+	NextPageToken string
+}
+
+// Query returns the query parameters of the options.
+func (o ListOptions) Query() url.Values {
+	query := url.Values{}
+	if o.PageSize > 0 {
+		query.Set("pageSize", strconv.Itoa(int(o.PageSize)))
+	}
+	if o.OrderBy != "" {
+		query.Set("orderBy", o.OrderBy)
+	}
+	if o.SortOrder != "" {
+		query.Set("sortOrder", o.SortOrder)
+	}
+	if o.NextPageToken != "" {
+		query.Set("nextPageToken", o.NextPageToken)
+	}
+	return query
+}
+
+// ParseListOptions reads the pageSize, orderBy, sortOrder and nextPageToken query parameters
+// of a BFF request, so handlers can pass them through to the registry.
+func ParseListOptions(query url.Values) (ListOptions, error) {
+	opts := ListOptions{
+		OrderBy:       query.Get("orderBy"),
+		SortOrder:     query.Get("sortOrder"),
+		NextPageToken: query.Get("nextPageToken"),
+	}
+	if value := query.Get("pageSize"); value != "" {
+		pageSize, err := strconv.Atoi(value)
+		if err != nil || pageSize < 1 || pageSize > MaxPageSize {
+			return ListOptions{}, fmt.Errorf("invalid pageSize %q: must be between 1 and %d", value, MaxPageSize)
+		}
+		opts.PageSize = int32(pageSize)
+	}
+	switch opts.OrderBy {
+	case "", OrderByCreateTime, OrderByLastUpdateTime, OrderByID:
+	default:
+		return ListOptions{}, fmt.Errorf("invalid orderBy %q: must be %s, %s or %s", opts.OrderBy, OrderByCreateTime, OrderByLastUpdateTime, OrderByID)
+	}
+	switch opts.SortOrder {
+	case "", SortOrderAsc, SortOrderDesc:
+	default:
+		return ListOptions{}, fmt.Errorf("invalid sortOrder %q: must be %s or %s", opts.SortOrder, SortOrderAsc, SortOrderDesc)
+	}
+	return opts, nil
+}
+
+// ListAll follows the page tokens of a listing from opts and returns the items of every page,
+// e.g. ListAll(ctx, opts, client.ListRegisteredModels). opts.NextPageToken is the first page.
+func ListAll[T any](ctx context.Context, opts ListOptions, list func(ctx context.Context, opts ListOptions) (*models.ModelRegistryList[T], error)) ([]T, error) {
+	items := []T{}
+	seen := map[string]bool{}
+	for {
+		page, err := list(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page.Items...)
+		if page.NextPageToken == "" || len(page.Items) == 0 {
+			return items, nil
+		}
+		// Guard against a registry handing out the same token forever
+		if seen[page.NextPageToken] {
+			return nil, fmt.Errorf("model registry returned page token %q twice", page.NextPageToken)
+		}
+		seen[page.NextPageToken] = true
+		opts.NextPageToken = page.NextPageToken
+	}
+}
@@ -0,0 +1,80 @@
+package objectstore
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Keys of the Secrets holding S3 credentials: those of the data connections of the ODH
+// dashboard, which are also the AWS environment variables.
+const (
+	AccessKeyIDKey     = "AWS_ACCESS_KEY_ID"
+	SecretAccessKeyKey = "AWS_SECRET_ACCESS_KEY"
+	SessionTokenKey    = "AWS_SESSION_TOKEN"
+	EndpointKey        = "AWS_S3_ENDPOINT"
+	RegionKey          = "AWS_DEFAULT_REGION"
+	BucketKey          = "AWS_S3_BUCKET"
+)
+
+// DefaultRegion is the region of the credentials that don't name one.
+const DefaultRegion = "us-east-1"
+
+// Credentials are the access keys of an S3-compatible service and where to reach it.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is set for temporary credentials.
+	SessionToken string
+	// Endpoint is the URL of the service, e.g. "https://minio.storage:9000"; empty is AWS S3 in
+	// Region. An endpoint without scheme uses HTTPS.
+	Endpoint string
+	Region   string
+	// Bucket is the default bucket of the credentials, used when a call names none.
+	Bucket string
+}
+
+// CredentialsFromSecret reads the credentials from the data of a Secret, with the keys of the
+// data connections (AccessKeyIDKey, ...). The access keys are required.
+func CredentialsFromSecret(data map[string][]byte) (Credentials, error) {
+	value := func(key string) string { return strings.TrimSpace(string(data[key])) }
+	creds := Credentials{
+		AccessKeyID:     value(AccessKeyIDKey),
+		SecretAccessKey: value(SecretAccessKeyKey),
+		SessionToken:    value(SessionTokenKey),
+		Endpoint:        value(EndpointKey),
+		Region:          value(RegionKey),
+		Bucket:          value(BucketKey),
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return Credentials{}, fmt.Errorf("objectstore: the Secret has no %s or %s", AccessKeyIDKey, SecretAccessKeyKey)
+	}
+	return creds, nil
+}
+
+// endpoint returns the URL of the service, defaulting the region and the scheme.
+func (c Credentials) endpoint() (*url.URL, error) {
+	raw := c.Endpoint
+	if raw == "" {
+		raw = "https://s3." + c.region() + ".amazonaws.com"
+	} else if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	endpoint, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("objectstore: invalid endpoint %q: %w", c.Endpoint, err)
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" || endpoint.Host == "" {
+		return nil, errors.New("objectstore: the endpoint must be an http or https URL, got " + c.Endpoint)
+	}
+	endpoint.Path = strings.TrimSuffix(endpoint.Path, "/")
+	return endpoint, nil
+}
+
+func (c Credentials) region() string {
+	if c.Region == "" {
+		return DefaultRegion
+	}
+	return c.Region
+}
@@ -0,0 +1,39 @@
+package objectstore
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Error is an error response of the object storage, e.g. a 404 NoSuchKey. apierrors keeps the
+// status of the 4xx errors.
+type Error struct {
+	StatusCode int
+	// Code is the S3 error code, e.g. "NoSuchBucket" or "AccessDenied".
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("objectstore: %s (%d): %s", e.Code, e.StatusCode, e.Message)
+}
+
+// decodeError reads the XML error of resp, whose body it consumes. Without one, the code
+// derives from the status.
+func decodeError(resp *http.Response) *Error {
+	var body struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	_ = xml.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&body)
+	err := &Error{StatusCode: resp.StatusCode, Code: body.Code, Message: body.Message}
+	if err.Code == "" {
+		err.Code = http.StatusText(resp.StatusCode)
+	}
+	if err.Message == "" {
+		err.Message = "the object storage answered " + resp.Status
+	}
+	return err
+}
@@ -0,0 +1,175 @@
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// PartSize is the size of the parts of the uploads of unknown size, the most they buffer.
+	PartSize = 8 << 20
+	// maxParts is the most parts an S3 multipart upload can have.
+	maxParts = 10000
+)
+
+// Upload uploads the object key of bucket, or of the default bucket when empty, reading body
+// until EOF without knowing its size, e.g. a part of a multipart form. A body smaller than
+// PartSize is written with a single PutObject; larger ones with an S3 multipart upload, which
+// buffers one part at a time and is aborted when reading body or writing a part fails.
+func (c *Client) Upload(ctx context.Context, bucket, key string, body io.Reader, contentType string) (ObjectInfo, error) {
+	bucket, err := c.bucket(bucket)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	if key == "" {
+		return ObjectInfo{}, errors.New("objectstore: the key of the object is required")
+	}
+	part := make([]byte, c.partSize)
+	n, err := readPart(body, part)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	if n < len(part) {
+		return c.PutObject(ctx, bucket, key, bytes.NewReader(part[:n]), int64(n), contentType)
+	}
+
+	uploadID, err := c.createMultipartUpload(ctx, bucket, key, contentType)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	info, err := c.uploadParts(ctx, bucket, key, uploadID, part, body)
+	if err != nil {
+		// Aborted even when the request is canceled, so the parts don't linger in the bucket
+		abortCtx := context.WithoutCancel(ctx)
+		if abortErr := c.abortMultipartUpload(abortCtx, bucket, key, uploadID); abortErr != nil {
+			err = errors.Join(err, abortErr)
+		}
+		return ObjectInfo{}, err
+	}
+	info.ContentType = contentType
+	return info, nil
+}
+
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// readPart fills part from body, returning fewer bytes only at the end of body.
+func readPart(body io.Reader, part []byte) (int, error) {
+	n, err := io.ReadFull(body, part)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return n, fmt.Errorf("objectstore: failed to read the object: %w", err)
+	}
+	return n, nil
+}
+
+// uploadParts uploads buf, a full first part, and the rest of body, then completes the upload.
+// Only the last part may be smaller than the others.
+func (c *Client) uploadParts(ctx context.Context, bucket, key, uploadID string, buf []byte, body io.Reader) (ObjectInfo, error) {
+	var parts []completedPart
+	size := int64(0)
+	for n := len(buf); n > 0; {
+		if len(parts) == maxParts {
+			return ObjectInfo{}, fmt.Errorf("objectstore: objects of unknown size are limited to %d bytes", int64(maxParts)*int64(len(buf)))
+		}
+		etag, err := c.uploadPart(ctx, bucket, key, uploadID, len(parts)+1, buf[:n])
+		if err != nil {
+			return ObjectInfo{}, err
+		}
+		parts = append(parts, completedPart{PartNumber: len(parts) + 1, ETag: etag})
+		size += int64(n)
+		if n, err = readPart(body, buf); err != nil {
+			return ObjectInfo{}, err
+		}
+	}
+
+	etag, err := c.completeMultipartUpload(ctx, bucket, key, uploadID, parts)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	return ObjectInfo{Key: key, Size: size, ETag: etag, LastModified: c.now().UTC()}, nil
+}
+
+func (c *Client) createMultipartUpload(ctx context.Context, bucket, key, contentType string) (string, error) {
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	resp, err := c.do(ctx, http.MethodPost, c.uploadURL(bucket, key, url.Values{"uploads": {""}}), nil, 0, header)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("objectstore: failed to decode the multipart upload of %s: %w", key, err)
+	}
+	if result.UploadID == "" {
+		return "", fmt.Errorf("objectstore: the multipart upload of %s has no UploadId", key)
+	}
+	return result.UploadID, nil
+}
+
+func (c *Client) uploadPart(ctx context.Context, bucket, key, uploadID string, number int, part []byte) (string, error) {
+	query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {uploadID}}
+	resp, err := c.do(ctx, http.MethodPut, c.uploadURL(bucket, key, query), bytes.NewReader(part), int64(len(part)), nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	return resp.Header.Get("ETag"), nil
+}
+
+func (c *Client) completeMultipartUpload(ctx context.Context, bucket, key, uploadID string, parts []completedPart) (string, error) {
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+		Parts   []completedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.do(ctx, http.MethodPost, c.uploadURL(bucket, key, url.Values{"uploadId": {uploadID}}), bytes.NewReader(body), int64(len(body)), nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	// The completion may fail after the 200 has been sent, with an error in the body
+	var result struct {
+		XMLName xml.Name
+		ETag    string `xml:"ETag"`
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("objectstore: failed to decode the completion of the upload of %s: %w", key, err)
+	}
+	if result.XMLName.Local == "Error" {
+		return "", &Error{StatusCode: http.StatusInternalServerError, Code: result.Code, Message: result.Message}
+	}
+	return strings.Trim(result.ETag, `"`), nil
+}
+
+func (c *Client) abortMultipartUpload(ctx context.Context, bucket, key, uploadID string) error {
+	resp, err := c.do(ctx, http.MethodDelete, c.uploadURL(bucket, key, url.Values{"uploadId": {uploadID}}), nil, -1, nil)
+	if err != nil {
+		return fmt.Errorf("objectstore: failed to abort the multipart upload of %s: %w", key, err)
+	}
+	return resp.Body.Close()
+}
+
+func (c *Client) uploadURL(bucket, key string, query url.Values) *url.URL {
+	u := c.objectURL(bucket, key)
+	u.RawQuery = encodeQuery(query)
+	return u
+}
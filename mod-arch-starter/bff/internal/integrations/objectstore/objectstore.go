@@ -0,0 +1,399 @@
+// Package objectstore reads and writes the objects of S3-compatible storage (AWS S3, MinIO,
+// Ceph, ...), like the model artifacts of a data connection: it lists buckets and objects,
+// streams downloads and uploads, and generates presigned URLs letting the browser transfer
+// large objects directly. Requests are signed with the Signature Version 4 signer of the AWS SDK.
+//
+// The credentials usually come from a Secret of the namespace, read on behalf of the user (see
+// repositories.ObjectStorageRepository):
+//
+//	creds, err := objectstore.CredentialsFromSecret(secret.Data)
+//	if err != nil {
+//	    return err
+//	}
+//	client, err := objectstore.New(objectstore.Config{Credentials: creds, Resilience: app.Resilience()})
+//	if err != nil {
+//	    return err
+//	}
+//	list, err := client.ListObjects(ctx, "", objectstore.ListOptions{Prefix: "models/", Delimiter: "/"})
+package objectstore
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/resilience"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/ssrf"
+)
+
+const (
+	// MaxListKeys is the most objects a listing returns at once.
+	MaxListKeys = 1000
+	// MaxPresignExpiry is the longest validity of presigned URLs allowed by SigV4.
+	MaxPresignExpiry = 7 * 24 * time.Hour
+	// MaxPutSize is the largest object a single upload can write.
+	MaxPutSize = 5 << 30
+)
+
+// ErrNoBucket is returned for the calls naming no bucket when the credentials have none.
+var ErrNoBucket = errors.New("objectstore: no bucket given and the credentials have no default bucket")
+
+// Config describes how to reach an S3-compatible service.
+type Config struct {
+	Credentials Credentials
+
+	// TLSConfig is the TLS configuration of HTTPS endpoints, e.g. the one of the
+	// "object-storage" upstream returned by App.UpstreamTLSConfig; nil trusts the system pool.
+	TLSConfig *tls.Config
+
+	// Resilience, when set, adds the circuit breaker of the endpoint: while it is open calls
+	// fail with an error matching resilience.ErrCircuitOpen, without calling the service.
+	Resilience *resilience.Policy
+
+	// AllowedHosts are the endpoint hosts called even though they resolve to private, loopback
+	// or link-local addresses, e.g. an in-cluster MinIO service. The endpoints come from
+	// Secrets users may edit, so the other ones may only reach public addresses.
+	AllowedHosts []string
+
+	// Logger logs the dials of the endpoints; nil uses slog.Default().
+	Logger *slog.Logger
+
+	// Transport overrides the HTTP transport (TLSConfig and AllowedHosts are then ignored).
+	Transport http.RoundTripper
+}
+
+// NewTransport returns the HTTP transport of cfg, with its TLS configuration, which refuses
+// to dial the private, loopback and link-local addresses of the hosts not in AllowedHosts:
+// the calls then fail with an error matching ssrf.ErrSSRFBlocked.
+func NewTransport(cfg Config) *http.Transport {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = cfg.TLSConfig
+	transport.DialContext = ssrf.SafeDialContext(logger, cfg.AllowedHosts...)
+	return transport
+}
+
+// Client calls an S3-compatible service with one set of credentials. It is safe for
+// concurrent use.
+type Client struct {
+	creds      Credentials
+	endpoint   *url.URL
+	httpClient *http.Client
+	breaker    *resilience.Breaker
+	now        func() time.Time
+	partSize   int
+}
+
+func New(cfg Config) (*Client, error) {
+	endpoint, err := cfg.Credentials.endpoint()
+	if err != nil {
+		return nil, err
+	}
+	transport := cfg.Transport
+	if transport == nil {
+		transport = NewTransport(cfg)
+	}
+	return &Client{
+		creds:    cfg.Credentials,
+		endpoint: endpoint,
+		// Transfers are streamed, so only their context bounds them
+		httpClient: &http.Client{Transport: transport},
+		breaker:    cfg.Resilience.Breaker("objectstore:" + endpoint.Host),
+		now:        time.Now,
+		partSize:   PartSize,
+	}, nil
+}
+
+// Bucket is a bucket of the service.
+type Bucket struct {
+	Name         string    `xml:"Name" json:"name"`
+	CreationDate time.Time `xml:"CreationDate" json:"creationDate"`
+}
+
+// ObjectInfo describes an object.
+type ObjectInfo struct {
+	Key          string    `xml:"Key" json:"key"`
+	Size         int64     `xml:"Size" json:"size"`
+	ETag         string    `xml:"ETag" json:"etag,omitempty"`
+	LastModified time.Time `xml:"LastModified" json:"lastModified"`
+	ContentType  string    `xml:"-" json:"contentType,omitempty"`
+}
+
+// Object is an object being downloaded. Its Body must be closed.
+type Object struct {
+	ObjectInfo
+	Body io.ReadCloser
+}
+
+// ListOptions select the objects of a listing.
+type ListOptions struct {
+	// Prefix keeps the objects whose key starts with it, e.g. "models/".
+	Prefix string
+	// Delimiter groups the keys containing it after the prefix into Prefixes, e.g. "/" lists
+	// a "folder".
+	Delimiter string
+	// ContinuationToken is the NextContinuationToken of the previous page.
+	ContinuationToken string
+	// MaxKeys bounds the objects and prefixes of the page (default and at most MaxListKeys).
+	MaxKeys int
+}
+
+// ObjectList is a page of the objects of a bucket.
+type ObjectList struct {
+	Bucket  string       `json:"bucket"`
+	Prefix  string       `json:"prefix,omitempty"`
+	Objects []ObjectInfo `json:"objects"`
+	// Prefixes are the common prefixes of the keys grouped by the delimiter, the "folders".
+	Prefixes []string `json:"prefixes"`
+	// NextContinuationToken is set when there are more objects, to list the next page.
+	NextContinuationToken string `json:"nextContinuationToken,omitempty"`
+}
+
+// Bucket returns the name of the default bucket of the credentials.
+func (c *Client) Bucket() string {
+	return c.creds.Bucket
+}
+
+// ListBuckets lists the buckets the credentials own.
+func (c *Client) ListBuckets(ctx context.Context) ([]Bucket, error) {
+	resp, err := c.do(ctx, http.MethodGet, c.serviceURL(), nil, -1, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var result struct {
+		Buckets []Bucket `xml:"Buckets>Bucket"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("objectstore: failed to decode the buckets: %w", err)
+	}
+	if result.Buckets == nil {
+		result.Buckets = []Bucket{}
+	}
+	return result.Buckets, nil
+}
+
+// ListObjects lists a page of the objects of bucket, or of the default bucket when empty,
+// sorted by key.
+func (c *Client) ListObjects(ctx context.Context, bucket string, opts ListOptions) (ObjectList, error) {
+	bucket, err := c.bucket(bucket)
+	if err != nil {
+		return ObjectList{}, err
+	}
+	u := c.objectURL(bucket, "")
+	query := url.Values{"list-type": {"2"}}
+	if opts.Prefix != "" {
+		query.Set("prefix", opts.Prefix)
+	}
+	if opts.Delimiter != "" {
+		query.Set("delimiter", opts.Delimiter)
+	}
+	if opts.ContinuationToken != "" {
+		query.Set("continuation-token", opts.ContinuationToken)
+	}
+	maxKeys := opts.MaxKeys
+	if maxKeys <= 0 || maxKeys > MaxListKeys {
+		maxKeys = MaxListKeys
+	}
+	query.Set("max-keys", strconv.Itoa(maxKeys))
+	u.RawQuery = encodeQuery(query)
+
+	resp, err := c.do(ctx, http.MethodGet, u, nil, -1, nil)
+	if err != nil {
+		return ObjectList{}, err
+	}
+	defer resp.Body.Close()
+	var result struct {
+		Contents       []ObjectInfo `xml:"Contents"`
+		CommonPrefixes []struct {
+			Prefix string `xml:"Prefix"`
+		} `xml:"CommonPrefixes"`
+		IsTruncated           bool   `xml:"IsTruncated"`
+		NextContinuationToken string `xml:"NextContinuationToken"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return ObjectList{}, fmt.Errorf("objectstore: failed to decode the objects: %w", err)
+	}
+	list := ObjectList{Bucket: bucket, Prefix: opts.Prefix, Objects: make([]ObjectInfo, 0, len(result.Contents)), Prefixes: []string{}}
+	for _, object := range result.Contents {
+		object.ETag = strings.Trim(object.ETag, `"`)
+		list.Objects = append(list.Objects, object)
+	}
+	for _, prefix := range result.CommonPrefixes {
+		list.Prefixes = append(list.Prefixes, prefix.Prefix)
+	}
+	if result.IsTruncated {
+		list.NextContinuationToken = result.NextContinuationToken
+	}
+	return list, nil
+}
+
+// GetObject starts the download of the object key of bucket, or of the default bucket when
+// empty. The caller reads and closes its Body.
+func (c *Client) GetObject(ctx context.Context, bucket, key string) (*Object, error) {
+	bucket, err := c.bucket(bucket)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.do(ctx, http.MethodGet, c.objectURL(bucket, key), nil, -1, nil)
+	if err != nil {
+		return nil, err
+	}
+	info := ObjectInfo{
+		Key:         key,
+		Size:        resp.ContentLength,
+		ETag:        strings.Trim(resp.Header.Get("ETag"), `"`),
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.LastModified = modified
+	}
+	return &Object{ObjectInfo: info, Body: resp.Body}, nil
+}
+
+// PutObject uploads the object key of bucket, or of the default bucket when empty, streaming
+// the size bytes of body (at most MaxPutSize).
+func (c *Client) PutObject(ctx context.Context, bucket, key string, body io.Reader, size int64, contentType string) (ObjectInfo, error) {
+	bucket, err := c.bucket(bucket)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	if key == "" {
+		return ObjectInfo{}, errors.New("objectstore: the key of the object is required")
+	}
+	if size < 0 || size > MaxPutSize {
+		return ObjectInfo{}, fmt.Errorf("objectstore: the size of an upload must be between 0 and %d bytes, got %d", int64(MaxPutSize), size)
+	}
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	resp, err := c.do(ctx, http.MethodPut, c.objectURL(bucket, key), body, size, header)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	defer resp.Body.Close()
+	return ObjectInfo{
+		Key:          key,
+		Size:         size,
+		ETag:         strings.Trim(resp.Header.Get("ETag"), `"`),
+		LastModified: c.now().UTC(),
+		ContentType:  contentType,
+	}, nil
+}
+
+// PresignURL returns a URL to GET or PUT the object key of bucket, or of the default bucket
+// when empty, without credentials, valid for expires (at most MaxPresignExpiry). A presigned
+// PUT must not send a Content-Type other than the one accepted by the bucket policy.
+func (c *Client) PresignURL(method, bucket, key string, expires time.Duration) (string, error) {
+	if method != http.MethodGet && method != http.MethodPut {
+		return "", fmt.Errorf("objectstore: only GET and PUT URLs can be presigned, got %s", method)
+	}
+	if expires < time.Second || expires > MaxPresignExpiry {
+		return "", fmt.Errorf("objectstore: presigned URLs expire within 1s and %s, got %s", MaxPresignExpiry, expires)
+	}
+	bucket, err := c.bucket(bucket)
+	if err != nil {
+		return "", err
+	}
+	if key == "" {
+		return "", errors.New("objectstore: the key of the object is required")
+	}
+	req := &http.Request{Method: method, URL: c.objectURL(bucket, key), Header: http.Header{}}
+	presigned, err := presign(req, c.creds, expires, c.now())
+	if err != nil {
+		return "", fmt.Errorf("objectstore: failed to presign the URL: %w", err)
+	}
+	return presigned, nil
+}
+
+func (c *Client) bucket(bucket string) (string, error) {
+	if bucket == "" {
+		bucket = c.creds.Bucket
+	}
+	if bucket == "" {
+		return "", ErrNoBucket
+	}
+	return bucket, nil
+}
+
+func (c *Client) serviceURL() *url.URL {
+	u := *c.endpoint
+	u.Path = u.Path + "/"
+	return &u
+}
+
+// objectURL addresses key in bucket: virtual-hosted on AWS, where path-style addressing is
+// deprecated, unless the bucket name has dots (they break the TLS certificate of the host),
+// and path-style elsewhere, like MinIO.
+func (c *Client) objectURL(bucket, key string) *url.URL {
+	u := *c.endpoint
+	if strings.HasSuffix(u.Hostname(), ".amazonaws.com") && !strings.Contains(bucket, ".") {
+		u.Host = bucket + "." + u.Host
+		u.Path = u.Path + "/" + key
+	} else {
+		u.Path = u.Path + "/" + bucket + "/" + key
+	}
+	u.RawPath = escapePath(u.Path)
+	return &u
+}
+
+// do sends a signed request, with the size bytes of body (-1 without body), and returns the
+// successful response or the *Error of the service.
+func (c *Client) do(ctx context.Context, method string, u *url.URL, body io.Reader, size int64, header http.Header) (*http.Response, error) {
+	if err := c.breaker.Allow(); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		c.breaker.Record(resilience.Abandoned)
+		return nil, fmt.Errorf("objectstore: failed to create request: %w", err)
+	}
+	// The URL keeps the path encoding that is signed
+	req.URL = u
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	payloadHash := emptyPayload
+	if size >= 0 {
+		req.ContentLength = size
+		req.Body = http.NoBody
+		if size > 0 {
+			req.Body = io.NopCloser(body)
+			// The upload is streamed without being read twice to hash it
+			payloadHash = unsignedPayload
+		}
+	}
+	if err := sign(req, c.creds, payloadHash, c.now()); err != nil {
+		c.breaker.Record(resilience.Abandoned)
+		return nil, fmt.Errorf("objectstore: failed to sign request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if errors.Is(err, ssrf.ErrSSRFBlocked) {
+			c.breaker.Record(resilience.Abandoned)
+		} else {
+			c.breaker.Record(resilience.TransportResult(ctx, 0, err))
+		}
+		return nil, fmt.Errorf("objectstore: %s %s: %w", method, u.Redacted(), err)
+	}
+	c.breaker.Record(resilience.TransportResult(ctx, resp.StatusCode, nil))
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		defer resp.Body.Close()
+		return nil, decodeError(resp)
+	}
+	return resp, nil
+}
@@ -0,0 +1,77 @@
+package objectstore
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// Requests are signed with the Signature Version 4 signer of the AWS SDK, see
+// https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-authenticating-requests.html
+
+const (
+	service = "s3"
+
+	// unsignedPayload signs the requests whose body is streamed without hashing it first.
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	// emptyPayload is the SHA-256 of an empty body.
+	emptyPayload = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// signer signs the paths as they are sent: S3 doesn't escape them twice.
+var signer = v4.NewSigner(func(o *v4.SignerOptions) {
+	o.DisableURIPathEscaping = true
+})
+
+// sign adds the Authorization header to req, whose body has the SHA-256 payloadHash (or
+// unsignedPayload).
+func sign(req *http.Request, creds Credentials, payloadHash string, now time.Time) error {
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	return signer.SignHTTP(req.Context(), creds.aws(), req, payloadHash, service, creds.region(), now)
+}
+
+// presign returns the URL of req authenticated by its query parameters, without headers, for
+// expires.
+func presign(req *http.Request, creds Credentials, expires time.Duration, now time.Time) (string, error) {
+	query := req.URL.Query()
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expires.Seconds())))
+	req.URL.RawQuery = encodeQuery(query)
+	signed, _, err := signer.PresignHTTP(context.Background(), creds.aws(), req, unsignedPayload, service, creds.region(), now)
+	return signed, err
+}
+
+func (c Credentials) aws() aws.Credentials {
+	return aws.Credentials{AccessKeyID: c.AccessKeyID, SecretAccessKey: c.SecretAccessKey, SessionToken: c.SessionToken}
+}
+
+// encodeQuery encodes query like the canonical query of the signer, with %20 for the spaces.
+func encodeQuery(query url.Values) string {
+	return strings.ReplaceAll(query.Encode(), "+", "%20")
+}
+
+// escapePath encodes the path of an object, keeping its slashes, as it is signed.
+func escapePath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	const hexDigits = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~', c == '/':
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hexDigits[c>>4])
+			b.WriteByte(hexDigits[c&15])
+		}
+	}
+	return b.String()
+}
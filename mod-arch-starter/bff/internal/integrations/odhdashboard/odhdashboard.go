@@ -0,0 +1,386 @@
+// Package odhdashboard reads the platform settings of the OpenDataHub (and OpenShift AI)
+// dashboard, so module BFFs follow them rather than their own copies: the OdhDashboardConfig
+// (disabled features, notebook and model server size presets, admin groups), the
+// OdhApplications of the platform, and the AcceleratorProfiles and HardwareProfiles. Reads are
+// cached per identity in a cache.Cache. On clusters without the dashboard, every lookup returns
+// ErrNotInstalled so callers can fall back to their defaults.
+package odhdashboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/cache"
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	DashboardConfigsGVR    = schema.GroupVersionResource{Group: "opendatahub.io", Version: "v1alpha", Resource: "odhdashboardconfigs"}
+	ApplicationsGVR        = schema.GroupVersionResource{Group: "dashboard.opendatahub.io", Version: "v1", Resource: "odhapplications"}
+	AcceleratorProfilesGVR = schema.GroupVersionResource{Group: "dashboard.opendatahub.io", Version: "v1", Resource: "acceleratorprofiles"}
+	HardwareProfilesGVR    = schema.GroupVersionResource{Group: "infrastructure.opendatahub.io", Version: "v1", Resource: "hardwareprofiles"}
+)
+
+// Annotations of the HardwareProfiles.
+const (
+	DisplayNameAnnotation = "opendatahub.io/display-name"
+	DescriptionAnnotation = "opendatahub.io/description"
+	DisabledAnnotation    = "opendatahub.io/disabled"
+)
+
+// DashboardConfigName is the name of the OdhDashboardConfig of the dashboard namespace.
+const DashboardConfigName = "odh-dashboard-config"
+
+// ErrNotInstalled is returned when the cluster does not serve the dashboard resources, or the
+// dashboard namespace has no OdhDashboardConfig.
+var ErrNotInstalled = errors.New("the OpenDataHub dashboard is not installed")
+
+// Resources returns the dynamic resource of a GVR, like KubernetesClientInterface.DynamicResource.
+type Resources interface {
+	DynamicResource(gvr schema.GroupVersionResource) (dynamic.NamespaceableResourceInterface, error)
+}
+
+// DynamicResources adapts a dynamic client to Resources.
+func DynamicResources(client dynamic.Interface) Resources {
+	return dynamicResources{client}
+}
+
+type dynamicResources struct{ dynamic.Interface }
+
+func (d dynamicResources) DynamicResource(gvr schema.GroupVersionResource) (dynamic.NamespaceableResourceInterface, error) {
+	return d.Resource(gvr), nil
+}
+
+// Options configures NewClient.
+type Options struct {
+	// Namespace is the namespace of the dashboard, e.g. opendatahub or redhat-ods-applications.
+	Namespace string
+	// Cache caches the reads; nil disables caching.
+	Cache *cache.Cache
+	// Identity is the identity of the client, keying its cached reads; nil shares them with
+	// every client without identity, e.g. of the BFF service account.
+	Identity *k8s.RequestIdentity
+}
+
+// Client reads the dashboard resources of a namespace with the credentials of its resources.
+type Client struct {
+	resources Resources
+	opts      Options
+}
+
+func NewClient(resources Resources, opts Options) *Client {
+	return &Client{resources: resources, opts: opts}
+}
+
+// DashboardConfig is the OdhDashboardConfig of the dashboard namespace.
+type DashboardConfig struct {
+	// Flags are the flags of the dashboardConfig, e.g. disableModelRegistry (see FeatureEnabled).
+	Flags              map[string]bool     `json:"flags,omitempty"`
+	NotebookSizes      []Size              `json:"notebookSizes,omitempty"`
+	ModelServerSizes   []Size              `json:"modelServerSizes,omitempty"`
+	NotebookController *NotebookController `json:"notebookController,omitempty"`
+	// AdminGroups and AllowedGroups are the groups of the dashboard administrators and users.
+	AdminGroups   []string `json:"adminGroups,omitempty"`
+	AllowedGroups []string `json:"allowedGroups,omitempty"`
+}
+
+// FeatureEnabled reports whether feature, e.g. "modelRegistry", is not disabled by the
+// disable<Feature> flag of the dashboard. Features without a flag are enabled.
+func (c *DashboardConfig) FeatureEnabled(feature string) bool {
+	if feature == "" {
+		return true
+	}
+	return !c.Flags["disable"+strings.ToUpper(feature[:1])+feature[1:]]
+}
+
+// Size is a preset of the resources of a notebook or model server.
+type Size struct {
+	Name      string                      `json:"name"`
+	Resources corev1.ResourceRequirements `json:"resources"`
+}
+
+type NotebookController struct {
+	Enabled           bool   `json:"enabled"`
+	NotebookNamespace string `json:"notebookNamespace,omitempty"`
+	PVCSize           string `json:"pvcSize,omitempty"`
+}
+
+// Application is an OdhApplication, a component of the platform listed by the dashboard.
+type Application struct {
+	Name           string `json:"name"`
+	DisplayName    string `json:"displayName"`
+	Provider       string `json:"provider,omitempty"`
+	Description    string `json:"description,omitempty"`
+	Category       string `json:"category,omitempty"`
+	Support        string `json:"support,omitempty"`
+	DocsLink       string `json:"docsLink,omitempty"`
+	GetStartedLink string `json:"getStartedLink,omitempty"`
+	// Route, RouteNamespace and ServiceName locate the UI of the application.
+	Route          string `json:"route,omitempty"`
+	RouteNamespace string `json:"routeNamespace,omitempty"`
+	ServiceName    string `json:"serviceName,omitempty"`
+	Hidden         bool   `json:"hidden,omitempty"`
+	// Enabled is set for the applications enabled by default or by the dashboard.
+	Enabled bool `json:"enabled"`
+}
+
+// AcceleratorProfile is an AcceleratorProfile, the accelerator a workload can request.
+type AcceleratorProfile struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	Description string `json:"description,omitempty"`
+	// Identifier is the extended resource of the accelerator, e.g. nvidia.com/gpu.
+	Identifier  string              `json:"identifier"`
+	Enabled     bool                `json:"enabled"`
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+}
+
+// HardwareProfile is a HardwareProfile, the resources and scheduling of a workload, replacing
+// the AcceleratorProfiles on recent platforms.
+type HardwareProfile struct {
+	Name        string               `json:"name"`
+	DisplayName string               `json:"displayName"`
+	Description string               `json:"description,omitempty"`
+	Enabled     bool                 `json:"enabled"`
+	Identifiers []HardwareIdentifier `json:"identifiers,omitempty"`
+	// NodeSelector and Tolerations are set on the workloads of the profiles scheduled on nodes.
+	NodeSelector map[string]string   `json:"nodeSelector,omitempty"`
+	Tolerations  []corev1.Toleration `json:"tolerations,omitempty"`
+	// LocalQueueName is set for the profiles scheduled by Kueue.
+	LocalQueueName string `json:"localQueueName,omitempty"`
+}
+
+// HardwareIdentifier is a resource of a HardwareProfile and the amounts a workload can request.
+type HardwareIdentifier struct {
+	// Identifier is the resource, e.g. cpu, memory or nvidia.com/gpu.
+	Identifier  string `json:"identifier"`
+	DisplayName string `json:"displayName"`
+	// ResourceType is CPU, Memory or Accelerator.
+	ResourceType string              `json:"resourceType,omitempty"`
+	MinCount     intstr.IntOrString  `json:"minCount"`
+	MaxCount     *intstr.IntOrString `json:"maxCount,omitempty"`
+	DefaultCount intstr.IntOrString  `json:"defaultCount"`
+}
+
+// DashboardConfig returns the OdhDashboardConfig of the dashboard namespace.
+func (c *Client) DashboardConfig(ctx context.Context) (*DashboardConfig, error) {
+	return get(ctx, c, DashboardConfigsGVR.Resource, func(ctx context.Context) (*DashboardConfig, error) {
+		obj, err := c.resource(ctx, DashboardConfigsGVR, DashboardConfigName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the dashboard config: %w", err)
+		}
+		var spec struct {
+			DashboardConfig    map[string]any      `json:"dashboardConfig"`
+			NotebookSizes      []Size              `json:"notebookSizes"`
+			ModelServerSizes   []Size              `json:"modelServerSizes"`
+			NotebookController *NotebookController `json:"notebookController"`
+			GroupsConfig       struct {
+				AdminGroups   string `json:"adminGroups"`
+				AllowedGroups string `json:"allowedGroups"`
+			} `json:"groupsConfig"`
+		}
+		if err := fromSpec(obj, &spec); err != nil {
+			return nil, err
+		}
+		// Only the boolean settings are flags
+		flags := map[string]bool{}
+		for name, value := range spec.DashboardConfig {
+			if flag, ok := value.(bool); ok {
+				flags[name] = flag
+			}
+		}
+		return &DashboardConfig{
+			Flags:              flags,
+			NotebookSizes:      spec.NotebookSizes,
+			ModelServerSizes:   spec.ModelServerSizes,
+			NotebookController: spec.NotebookController,
+			AdminGroups:        splitGroups(spec.GroupsConfig.AdminGroups),
+			AllowedGroups:      splitGroups(spec.GroupsConfig.AllowedGroups),
+		}, nil
+	})
+}
+
+// Applications returns the OdhApplications of the dashboard namespace.
+func (c *Client) Applications(ctx context.Context) ([]Application, error) {
+	return get(ctx, c, ApplicationsGVR.Resource, func(ctx context.Context) ([]Application, error) {
+		items, err := c.list(ctx, ApplicationsGVR)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the dashboard applications: %w", err)
+		}
+		applications := make([]Application, 0, len(items))
+		for i := range items {
+			var spec struct {
+				DisplayName    string `json:"displayName"`
+				Provider       string `json:"provider"`
+				Description    string `json:"description"`
+				Category       string `json:"category"`
+				Support        string `json:"support"`
+				DocsLink       string `json:"docsLink"`
+				GetStartedLink string `json:"getStartedLink"`
+				Route          string `json:"route"`
+				RouteNamespace string `json:"routeNamespace"`
+				ServiceName    string `json:"serviceName"`
+				Hidden         bool   `json:"hidden"`
+				IsEnabled      bool   `json:"isEnabled"`
+			}
+			if err := fromSpec(&items[i], &spec); err != nil {
+				return nil, err
+			}
+			statusEnabled, _, _ := unstructured.NestedBool(items[i].Object, "status", "enabled")
+			applications = append(applications, Application{
+				Name:           items[i].GetName(),
+				DisplayName:    spec.DisplayName,
+				Provider:       spec.Provider,
+				Description:    spec.Description,
+				Category:       spec.Category,
+				Support:        spec.Support,
+				DocsLink:       spec.DocsLink,
+				GetStartedLink: spec.GetStartedLink,
+				Route:          spec.Route,
+				RouteNamespace: spec.RouteNamespace,
+				ServiceName:    spec.ServiceName,
+				Hidden:         spec.Hidden,
+				Enabled:        spec.IsEnabled || statusEnabled,
+			})
+		}
+		return applications, nil
+	})
+}
+
+// AcceleratorProfiles returns the AcceleratorProfiles of the dashboard namespace, enabled or
+// not.
+func (c *Client) AcceleratorProfiles(ctx context.Context) ([]AcceleratorProfile, error) {
+	return get(ctx, c, AcceleratorProfilesGVR.Resource, func(ctx context.Context) ([]AcceleratorProfile, error) {
+		items, err := c.list(ctx, AcceleratorProfilesGVR)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the accelerator profiles: %w", err)
+		}
+		profiles := make([]AcceleratorProfile, 0, len(items))
+		for i := range items {
+			var profile AcceleratorProfile
+			if err := fromSpec(&items[i], &profile); err != nil {
+				return nil, err
+			}
+			profile.Name = items[i].GetName()
+			profiles = append(profiles, profile)
+		}
+		return profiles, nil
+	})
+}
+
+// HardwareProfiles returns the HardwareProfiles of the dashboard namespace, enabled or not.
+func (c *Client) HardwareProfiles(ctx context.Context) ([]HardwareProfile, error) {
+	return get(ctx, c, HardwareProfilesGVR.Resource, func(ctx context.Context) ([]HardwareProfile, error) {
+		items, err := c.list(ctx, HardwareProfilesGVR)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the hardware profiles: %w", err)
+		}
+		profiles := make([]HardwareProfile, 0, len(items))
+		for i := range items {
+			var spec struct {
+				Identifiers []HardwareIdentifier `json:"identifiers"`
+				Scheduling  struct {
+					Node struct {
+						NodeSelector map[string]string   `json:"nodeSelector"`
+						Tolerations  []corev1.Toleration `json:"tolerations"`
+					} `json:"node"`
+					Kueue struct {
+						LocalQueueName string `json:"localQueueName"`
+					} `json:"kueue"`
+				} `json:"scheduling"`
+			}
+			if err := fromSpec(&items[i], &spec); err != nil {
+				return nil, err
+			}
+			annotations := items[i].GetAnnotations()
+			profile := HardwareProfile{
+				Name:           items[i].GetName(),
+				DisplayName:    annotations[DisplayNameAnnotation],
+				Description:    annotations[DescriptionAnnotation],
+				Enabled:        annotations[DisabledAnnotation] != "true",
+				Identifiers:    spec.Identifiers,
+				NodeSelector:   spec.Scheduling.Node.NodeSelector,
+				Tolerations:    spec.Scheduling.Node.Tolerations,
+				LocalQueueName: spec.Scheduling.Kueue.LocalQueueName,
+			}
+			if profile.DisplayName == "" {
+				profile.DisplayName = profile.Name
+			}
+			profiles = append(profiles, profile)
+		}
+		return profiles, nil
+	})
+}
+
+// entry is a cached read, and whether the dashboard is installed. Misses are cached too, so
+// clusters without the dashboard aren't asked again on every lookup.
+type entry[T any] struct {
+	Installed bool `json:"installed"`
+	Value     T    `json:"value"`
+}
+
+// get returns the value of load for resource, through the cache of c.
+func get[T any](ctx context.Context, c *Client, resource string, load func(ctx context.Context) (T, error)) (T, error) {
+	key := cache.Key{Resource: resource, Namespace: c.opts.Namespace, Identity: c.opts.Identity}
+	cached, err := cache.Get(ctx, c.opts.Cache, key, func(ctx context.Context) (entry[T], error) {
+		value, err := load(ctx)
+		if k8serrors.IsNotFound(err) {
+			return entry[T]{}, nil
+		}
+		return entry[T]{Installed: true, Value: value}, err
+	})
+	if err == nil && !cached.Installed {
+		err = ErrNotInstalled
+	}
+	return cached.Value, err
+}
+
+func (c *Client) resource(ctx context.Context, gvr schema.GroupVersionResource, name string) (*unstructured.Unstructured, error) {
+	resource, err := c.resources.DynamicResource(gvr)
+	if err != nil {
+		return nil, err
+	}
+	return resource.Namespace(c.opts.Namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+func (c *Client) list(ctx context.Context, gvr schema.GroupVersionResource) ([]unstructured.Unstructured, error) {
+	resource, err := c.resources.DynamicResource(gvr)
+	if err != nil {
+		return nil, err
+	}
+	list, err := resource.Namespace(c.opts.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// fromSpec converts the spec of obj into out.
+func fromSpec(obj *unstructured.Unstructured, out any) error {
+	spec, _, _ := unstructured.NestedMap(obj.Object, "spec")
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(spec, out); err != nil {
+		return fmt.Errorf("failed to convert %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+	return nil
+}
+
+// splitGroups splits the comma-separated groups of the groupsConfig.
+func splitGroups(groups string) []string {
+	var names []string
+	for _, group := range strings.Split(groups, ",") {
+		if group = strings.TrimSpace(group); group != "" {
+			names = append(names, group)
+		}
+	}
+	return names
+}
@@ -0,0 +1,156 @@
+package odhdashboard
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func object(apiVersion, kind, name string, spec, status map[string]any) *unstructured.Unstructured {
+	obj := map[string]any{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata":   map[string]any{"name": name, "namespace": "opendatahub"},
+		"spec":       spec,
+	}
+	if status != nil {
+		obj["status"] = status
+	}
+	return &unstructured.Unstructured{Object: obj}
+}
+
+func newFakeResources(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		DashboardConfigsGVR:    "OdhDashboardConfigList",
+		ApplicationsGVR:        "OdhApplicationList",
+		AcceleratorProfilesGVR: "AcceleratorProfileList",
+	}, objects...)
+}
+
+func TestClient(t *testing.T) {
+	resources := newFakeResources(
+		object("opendatahub.io/v1alpha", "OdhDashboardConfig", DashboardConfigName, map[string]any{
+			"dashboardConfig": map[string]any{
+				"disableModelRegistry": true,
+				"disableKServe":        false,
+				"modelMetricsEndpoint": "ignored",
+			},
+			"notebookSizes": []any{map[string]any{
+				"name":      "Small",
+				"resources": map[string]any{"requests": map[string]any{"cpu": "1", "memory": "8Gi"}},
+			}},
+			"notebookController": map[string]any{"enabled": true, "pvcSize": "20Gi"},
+			"groupsConfig":       map[string]any{"adminGroups": "odh-admins, platform-admins", "allowedGroups": "system:authenticated"},
+		}, nil),
+		object("dashboard.opendatahub.io/v1", "OdhApplication", "jupyter", map[string]any{
+			"displayName": "Jupyter",
+			"provider":    "Jupyter",
+			"category":    "Open Data Hub managed",
+			"isEnabled":   true,
+		}, nil),
+		object("dashboard.opendatahub.io/v1", "OdhApplication", "starburst", map[string]any{
+			"displayName": "Starburst",
+			"route":       "starburst",
+		}, map[string]any{"enabled": false}),
+		object("dashboard.opendatahub.io/v1", "AcceleratorProfile", "nvidia", map[string]any{
+			"displayName": "NVIDIA GPU",
+			"identifier":  "nvidia.com/gpu",
+			"enabled":     true,
+			"tolerations": []any{map[string]any{"key": "nvidia.com/gpu", "operator": "Exists", "effect": "NoSchedule"}},
+		}, nil),
+	)
+	client := NewClient(DynamicResources(resources), Options{Namespace: "opendatahub"})
+	ctx := context.Background()
+
+	config, err := client.DashboardConfig(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"disableModelRegistry": true, "disableKServe": false}, config.Flags)
+	assert.False(t, config.FeatureEnabled("modelRegistry"))
+	assert.True(t, config.FeatureEnabled("kServe"))
+	assert.True(t, config.FeatureEnabled("pipelines"))
+	require.Len(t, config.NotebookSizes, 1)
+	assert.Equal(t, "Small", config.NotebookSizes[0].Name)
+	assert.Equal(t, "8Gi", config.NotebookSizes[0].Resources.Requests.Memory().String())
+	assert.Equal(t, &NotebookController{Enabled: true, PVCSize: "20Gi"}, config.NotebookController)
+	assert.Equal(t, []string{"odh-admins", "platform-admins"}, config.AdminGroups)
+	assert.Equal(t, []string{"system:authenticated"}, config.AllowedGroups)
+
+	applications, err := client.Applications(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []Application{
+		{Name: "jupyter", DisplayName: "Jupyter", Provider: "Jupyter", Category: "Open Data Hub managed", Enabled: true},
+		{Name: "starburst", DisplayName: "Starburst", Route: "starburst"},
+	}, applications)
+
+	profiles, err := client.AcceleratorProfiles(ctx)
+	require.NoError(t, err)
+	require.Len(t, profiles, 1)
+	assert.Equal(t, "nvidia", profiles[0].Name)
+	assert.Equal(t, "nvidia.com/gpu", profiles[0].Identifier)
+	assert.True(t, profiles[0].Enabled)
+	require.Len(t, profiles[0].Tolerations, 1)
+	assert.Equal(t, "nvidia.com/gpu", profiles[0].Tolerations[0].Key)
+}
+
+func TestClient_NotInstalled(t *testing.T) {
+	resources := newFakeResources()
+	resources.PrependReactor("list", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, k8serrors.NewNotFound(action.GetResource().GroupResource(), "")
+	})
+	c := cache.New(cache.Options{TTL: time.Minute})
+	client := NewClient(DynamicResources(resources), Options{Namespace: "opendatahub", Cache: c})
+	ctx := context.Background()
+
+	_, err := client.DashboardConfig(ctx)
+	assert.ErrorIs(t, err, ErrNotInstalled)
+	_, err = client.Applications(ctx)
+	assert.ErrorIs(t, err, ErrNotInstalled)
+	_, err = client.AcceleratorProfiles(ctx)
+	assert.ErrorIs(t, err, ErrNotInstalled)
+
+	// The misses are cached
+	calls := len(resources.Actions())
+	_, err = client.Applications(ctx)
+	assert.ErrorIs(t, err, ErrNotInstalled)
+	assert.Len(t, resources.Actions(), calls)
+}
+
+func TestClient_Cache(t *testing.T) {
+	resources := newFakeResources(object("dashboard.opendatahub.io/v1", "AcceleratorProfile", "nvidia", map[string]any{
+		"displayName": "NVIDIA GPU",
+		"identifier":  "nvidia.com/gpu",
+	}, nil))
+	c := cache.New(cache.Options{TTL: time.Minute})
+	ctx := context.Background()
+
+	client := NewClient(DynamicResources(resources), Options{Namespace: "opendatahub", Cache: c})
+	profiles, err := client.AcceleratorProfiles(ctx)
+	require.NoError(t, err)
+	require.Len(t, profiles, 1)
+
+	require.NoError(t, resources.Resource(AcceleratorProfilesGVR).Namespace("opendatahub").Delete(ctx, "nvidia", metav1.DeleteOptions{}))
+	profiles, err = client.AcceleratorProfiles(ctx)
+	require.NoError(t, err)
+	assert.Len(t, profiles, 1, "served from the cache")
+
+	uncached := NewClient(DynamicResources(resources), Options{Namespace: "opendatahub"})
+	profiles, err = uncached.AcceleratorProfiles(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, profiles)
+}
+
+func TestSplitGroups(t *testing.T) {
+	assert.Nil(t, splitGroups(""))
+	assert.Equal(t, []string{"a", "b"}, splitGroups(" a,,b ,"))
+}
@@ -0,0 +1,63 @@
+// Package oidc validates OpenID Connect ID/access tokens (JWTs) issued by a configured
+// issuer. It performs OIDC discovery, caches the issuer's JWKS and refreshes it when
+// keys rotate, and verifies the signatures with go-jose.
+package oidc
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	DefaultUsernameClaim = "sub"
+	DefaultGroupsClaim   = "groups"
+
+	// DefaultJWKSRefreshInterval is how long fetched signing keys are trusted before the
+	// JWKS document is fetched again.
+	DefaultJWKSRefreshInterval = time.Hour
+
+	// minJWKSRefreshInterval bounds how often an unknown key ID can trigger a JWKS fetch,
+	// so forged tokens cannot be used to hammer the issuer.
+	minJWKSRefreshInterval = 10 * time.Second
+
+	// clockSkew is the leeway allowed when validating exp/nbf/iat.
+	clockSkew = time.Minute
+)
+
+// Config holds the settings required to validate tokens from a single issuer.
+type Config struct {
+	// IssuerURL is the OIDC issuer; discovery is performed against
+	// <IssuerURL>/.well-known/openid-configuration.
+	IssuerURL string
+
+	// ClientID is the expected audience, in the aud or the azp claim of the tokens. Required.
+	ClientID string
+
+	// UsernameClaim is the claim used as RequestIdentity.UserID (default "sub").
+	UsernameClaim string
+
+	// GroupsClaim is the claim used as RequestIdentity.Groups (default "groups").
+	GroupsClaim string
+
+	// JWKSRefreshInterval overrides DefaultJWKSRefreshInterval.
+	JWKSRefreshInterval time.Duration
+
+	// HTTPClient is used for discovery and JWKS requests. Defaults to a client with a
+	// 10 second timeout.
+	HTTPClient *http.Client
+}
+
+func (c *Config) setDefaults() {
+	if c.UsernameClaim == "" {
+		c.UsernameClaim = DefaultUsernameClaim
+	}
+	if c.GroupsClaim == "" {
+		c.GroupsClaim = DefaultGroupsClaim
+	}
+	if c.JWKSRefreshInterval <= 0 {
+		c.JWKSRefreshInterval = DefaultJWKSRefreshInterval
+	}
+	if c.HTTPClient == nil {
+		c.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+}
@@ -0,0 +1,157 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"golang.org/x/sync/singleflight"
+)
+
+type jsonWebKeySet struct {
+	// Keys are decoded one by one, so an unsupported key doesn't drop the others.
+	Keys []json.RawMessage `json:"keys"`
+}
+
+// keySet is a lazily discovered, cached view of the issuer's signing keys.
+type keySet struct {
+	cfg    Config
+	logger *slog.Logger
+	// fetches coalesces the concurrent refreshes into one request to the issuer, made without
+	// holding mu so the requests with cached keys don't wait for it.
+	fetches singleflight.Group
+
+	mu        sync.RWMutex
+	jwksURI   string
+	keys      map[string]jose.JSONWebKey
+	fetchedAt time.Time
+	now       func() time.Time
+}
+
+func newKeySet(cfg Config, logger *slog.Logger) *keySet {
+	return &keySet{
+		cfg:    cfg,
+		logger: logger,
+		keys:   map[string]jose.JSONWebKey{},
+		now:    time.Now,
+	}
+}
+
+// get returns the key for kid, refreshing the JWKS when the cache is stale or the
+// key is unknown. An empty kid is accepted when the issuer publishes a single key.
+func (ks *keySet) get(ctx context.Context, kid string) (jose.JSONWebKey, error) {
+	ks.mu.RLock()
+	sinceFetch := ks.now().Sub(ks.fetchedAt)
+	neverFetched := ks.fetchedAt.IsZero()
+	key, found := ks.lookup(kid)
+	ks.mu.RUnlock()
+	if found && sinceFetch < ks.cfg.JWKSRefreshInterval {
+		return key, nil
+	}
+
+	if neverFetched || sinceFetch >= minJWKSRefreshInterval {
+		if err := ks.refresh(ctx); err != nil {
+			if found {
+				// Keep serving the stale key rather than failing every request while the issuer is down.
+				ks.logger.Warn("failed to refresh OIDC JWKS, using cached keys", "error", err)
+				return key, nil
+			}
+			return jose.JSONWebKey{}, err
+		}
+		ks.mu.RLock()
+		key, found = ks.lookup(kid)
+		ks.mu.RUnlock()
+	}
+
+	if !found {
+		return jose.JSONWebKey{}, fmt.Errorf("%w: unknown signing key %q", ErrInvalidToken, kid)
+	}
+	return key, nil
+}
+
+// lookup must be called with ks.mu held.
+func (ks *keySet) lookup(kid string) (jose.JSONWebKey, bool) {
+	if kid == "" && len(ks.keys) == 1 {
+		for _, k := range ks.keys {
+			return k, true
+		}
+	}
+	k, ok := ks.keys[kid]
+	return k, ok
+}
+
+// refresh fetches the JWKS, once for the concurrent callers, and swaps the cached keys. Each
+// caller stops waiting when its ctx is done; the fetch itself is bounded by the HTTP client.
+func (ks *keySet) refresh(ctx context.Context) error {
+	fetch := ks.fetches.DoChan("jwks", func() (any, error) {
+		return nil, ks.fetch(context.WithoutCancel(ctx))
+	})
+	select {
+	case res := <-fetch:
+		return res.Err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (ks *keySet) fetch(ctx context.Context) error {
+	ks.mu.RLock()
+	jwksURI := ks.jwksURI
+	ks.mu.RUnlock()
+	if jwksURI == "" {
+		doc, err := ks.discover(ctx)
+		if err != nil {
+			return err
+		}
+		jwksURI = doc.JWKSURI
+	}
+
+	var set jsonWebKeySet
+	if err := ks.getJSON(ctx, jwksURI, &set); err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+
+	keys := make(map[string]jose.JSONWebKey, len(set.Keys))
+	for _, raw := range set.Keys {
+		var jwk jose.JSONWebKey
+		if err := jwk.UnmarshalJSON(raw); err != nil {
+			ks.logger.Debug("skipping unsupported JWK", "error", err)
+			continue
+		}
+		if !jwk.IsPublic() || (jwk.Use != "" && jwk.Use != "sig") {
+			ks.logger.Debug("skipping JWK not verifying signatures", "kid", jwk.KeyID, "use", jwk.Use)
+			continue
+		}
+		keys[jwk.KeyID] = jwk
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("JWKS at %s contains no usable signing keys", jwksURI)
+	}
+
+	ks.mu.Lock()
+	ks.jwksURI = jwksURI
+	ks.keys = keys
+	ks.fetchedAt = ks.now()
+	ks.mu.Unlock()
+	ks.logger.Debug("refreshed OIDC JWKS", "keys", len(keys))
+	return nil
+}
+
+func (ks *keySet) discover(ctx context.Context) (*ProviderMetadata, error) {
+	doc, err := Discover(ctx, ks.cfg.IssuerURL, ks.cfg.HTTPClient)
+	if err != nil {
+		return nil, err
+	}
+	if doc.JWKSURI == "" {
+		return nil, fmt.Errorf("OIDC discovery document has no jwks_uri")
+	}
+	return doc, nil
+}
+
+func (ks *keySet) getJSON(ctx context.Context, url string, dst any) error {
+	return getJSON(ctx, ks.cfg.HTTPClient, url, dst)
+}
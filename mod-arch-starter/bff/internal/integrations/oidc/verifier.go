@@ -0,0 +1,167 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+// ErrInvalidToken is returned (wrapped) for every token that fails validation.
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims holds the validated claims of a token.
+type Claims struct {
+	Subject  string
+	Issuer   string
+	Audience []string
+	Expiry   time.Time
+	// Raw contains every claim in the token payload.
+	Raw map[string]any
+}
+
+// String returns a string claim, or "" when it is missing or not a string.
+func (c *Claims) String(name string) string {
+	s, _ := c.Raw[name].(string)
+	return s
+}
+
+// StringSlice returns a claim that is either a string or an array of strings.
+func (c *Claims) StringSlice(name string) []string {
+	switch v := c.Raw[name].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return []string{}
+	}
+}
+
+// Verifier validates JWTs against a single OIDC issuer.
+type Verifier struct {
+	cfg    Config
+	keys   *keySet
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewVerifier creates a Verifier. Discovery and the first JWKS fetch happen lazily on the
+// first call to Verify, so the BFF can start while the issuer is temporarily unavailable.
+func NewVerifier(cfg Config, logger *slog.Logger) (*Verifier, error) {
+	if cfg.IssuerURL == "" {
+		return nil, errors.New("OIDC issuer URL is required")
+	}
+	if cfg.ClientID == "" {
+		return nil, errors.New("OIDC client ID is required, as the audience of the tokens")
+	}
+	cfg.setDefaults()
+	return &Verifier{
+		cfg:    cfg,
+		keys:   newKeySet(cfg, logger),
+		logger: logger,
+		now:    time.Now,
+	}, nil
+}
+
+// Config returns the effective verifier configuration (with defaults applied).
+func (v *Verifier) Config() Config {
+	return v.cfg
+}
+
+// supportedAlgorithms are the accepted JWS algorithms. "none" and the HMAC algorithms are
+// deliberately not supported.
+var supportedAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+}
+
+// Verify checks the token signature, issuer, audience and validity window.
+func (v *Verifier) Verify(ctx context.Context, rawToken string) (*Claims, error) {
+	jws, err := jose.ParseSignedCompact(rawToken, supportedAlgorithms)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if len(jws.Signatures) != 1 {
+		return nil, fmt.Errorf("%w: expected one signature, got %d", ErrInvalidToken, len(jws.Signatures))
+	}
+	header := jws.Signatures[0].Header
+
+	key, err := v.keys.get(ctx, header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	// A key published for one algorithm must not verify tokens of another
+	if key.Algorithm != "" && key.Algorithm != header.Algorithm {
+		return nil, fmt.Errorf("%w: signing key %q is for %s, not %s", ErrInvalidToken, key.KeyID, key.Algorithm, header.Algorithm)
+	}
+
+	payload, err := jws.Verify(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	raw := map[string]any{}
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, fmt.Errorf("%w: malformed payload: %v", ErrInvalidToken, err)
+	}
+
+	claims := &Claims{Raw: raw}
+	claims.Subject = claims.String("sub")
+	claims.Issuer = claims.String("iss")
+	claims.Audience = claims.StringSlice("aud")
+
+	if err := v.validateClaims(claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return claims, nil
+}
+
+func (v *Verifier) validateClaims(claims *Claims) error {
+	if strings.TrimSuffix(claims.Issuer, "/") != strings.TrimSuffix(v.cfg.IssuerURL, "/") {
+		return fmt.Errorf("unexpected issuer %q", claims.Issuer)
+	}
+
+	// Access tokens of some issuers, e.g. Keycloak, name the client in azp rather than aud
+	if !slices.Contains(claims.Audience, v.cfg.ClientID) && claims.String("azp") != v.cfg.ClientID {
+		return fmt.Errorf("token audience does not include %q", v.cfg.ClientID)
+	}
+
+	now := v.now()
+	exp, ok := numericDate(claims.Raw["exp"])
+	if !ok {
+		return errors.New("missing exp claim")
+	}
+	claims.Expiry = exp
+	if now.After(exp.Add(clockSkew)) {
+		return errors.New("token is expired")
+	}
+	if nbf, ok := numericDate(claims.Raw["nbf"]); ok && now.Add(clockSkew).Before(nbf) {
+		return errors.New("token is not valid yet")
+	}
+	if iat, ok := numericDate(claims.Raw["iat"]); ok && now.Add(clockSkew).Before(iat) {
+		return errors.New("token was issued in the future")
+	}
+	return nil
+}
+
+func numericDate(v any) (time.Time, bool) {
+	f, ok := v.(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(f), 0), true
+}
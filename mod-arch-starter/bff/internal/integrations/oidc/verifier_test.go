@@ -0,0 +1,207 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testIssuer struct {
+	server    *httptest.Server
+	key       *rsa.PrivateKey
+	kid       string
+	keyAlg    string
+	jwksHits  atomic.Int32
+	jwksDelay chan struct{}
+	issuerURL string
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ti := &testIssuer{key: key, kid: "key-1"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(ProviderMetadata{Issuer: ti.issuerURL, JWKSURI: ti.issuerURL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		ti.jwksHits.Add(1)
+		if ti.jwksDelay != nil {
+			<-ti.jwksDelay
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []any{
+			jose.JSONWebKey{Key: &ti.key.PublicKey, KeyID: ti.kid, Use: "sig", Algorithm: ti.keyAlg},
+			map[string]string{"kty": "OKP", "crv": "X448", "kid": "unsupported"},
+		}})
+	})
+	ti.server = httptest.NewServer(mux)
+	ti.issuerURL = ti.server.URL
+	t.Cleanup(ti.server.Close)
+	return ti
+}
+
+func (ti *testIssuer) sign(t *testing.T, kid string, claims map[string]any) string {
+	t.Helper()
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "kid": kid, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(input))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, ti.key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func (ti *testIssuer) claims(overrides map[string]any) map[string]any {
+	c := map[string]any{
+		"iss":    ti.issuerURL,
+		"sub":    "user-123",
+		"aud":    "my-client",
+		"exp":    time.Now().Add(time.Hour).Unix(),
+		"iat":    time.Now().Unix(),
+		"email":  "user@example.com",
+		"groups": []string{"team-a", "team-b"},
+	}
+	for k, v := range overrides {
+		c[k] = v
+	}
+	return c
+}
+
+func newTestVerifier(t *testing.T, ti *testIssuer) *Verifier {
+	t.Helper()
+	v, err := NewVerifier(Config{IssuerURL: ti.issuerURL, ClientID: "my-client"}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	return v
+}
+
+func TestVerifier_ValidToken(t *testing.T) {
+	ti := newTestIssuer(t)
+	v := newTestVerifier(t, ti)
+
+	claims, err := v.Verify(context.Background(), ti.sign(t, ti.kid, ti.claims(nil)))
+	require.NoError(t, err)
+	assert.Equal(t, "user-123", claims.Subject)
+	assert.Equal(t, "user@example.com", claims.String("email"))
+	assert.Equal(t, []string{"team-a", "team-b"}, claims.StringSlice("groups"))
+	assert.Equal(t, []string{"my-client"}, claims.Audience)
+
+	// a second verification must be served from the JWKS cache
+	_, err = v.Verify(context.Background(), ti.sign(t, ti.kid, ti.claims(nil)))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), ti.jwksHits.Load())
+}
+
+func TestVerifier_RejectsInvalidTokens(t *testing.T) {
+	ti := newTestIssuer(t)
+	v := newTestVerifier(t, ti)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"malformed", "not-a-jwt"},
+		{"expired", ti.sign(t, ti.kid, ti.claims(map[string]any{"exp": time.Now().Add(-time.Hour).Unix()}))},
+		{"not yet valid", ti.sign(t, ti.kid, ti.claims(map[string]any{"nbf": time.Now().Add(time.Hour).Unix()}))},
+		{"wrong issuer", ti.sign(t, ti.kid, ti.claims(map[string]any{"iss": "https://evil.example.com"}))},
+		{"wrong audience", ti.sign(t, ti.kid, ti.claims(map[string]any{"aud": []string{"other"}}))},
+		{"wrong authorized party", ti.sign(t, ti.kid, ti.claims(map[string]any{"aud": "account", "azp": "other"}))},
+		{"missing exp", ti.sign(t, ti.kid, ti.claims(map[string]any{"exp": nil}))},
+		{"unknown key", ti.sign(t, "key-2", ti.claims(nil))},
+		{"tampered payload", ti.sign(t, ti.kid, ti.claims(nil))[:40] + "x" + ti.sign(t, ti.kid, ti.claims(nil))[41:]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.Verify(context.Background(), tt.token)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
+
+func TestVerifier_RejectsAlgNone(t *testing.T) {
+	ti := newTestIssuer(t)
+	v := newTestVerifier(t, ti)
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","kid":"key-1"}`))
+	payload, _ := json.Marshal(ti.claims(nil))
+	token := header + "." + base64.RawURLEncoding.EncodeToString(payload) + "."
+
+	_, err := v.Verify(context.Background(), token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestVerifier_AuthorizedParty(t *testing.T) {
+	ti := newTestIssuer(t)
+	v := newTestVerifier(t, ti)
+
+	_, err := v.Verify(context.Background(), ti.sign(t, ti.kid, ti.claims(map[string]any{"aud": "account", "azp": "my-client"})))
+	assert.NoError(t, err)
+}
+
+func TestVerifier_RejectsKeyOfAnotherAlgorithm(t *testing.T) {
+	ti := newTestIssuer(t)
+	ti.keyAlg = "PS256"
+	v := newTestVerifier(t, ti)
+
+	_, err := v.Verify(context.Background(), ti.sign(t, ti.kid, ti.claims(nil)))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	assert.ErrorContains(t, err, "is for PS256, not RS256")
+}
+
+func TestVerifier_RefreshDoesNotBlockCachedKeys(t *testing.T) {
+	ti := newTestIssuer(t)
+	v := newTestVerifier(t, ti)
+	_, err := v.Verify(context.Background(), ti.sign(t, ti.kid, ti.claims(nil)))
+	require.NoError(t, err)
+
+	// Tokens of an unknown key wait for one slow JWKS fetch...
+	ti.jwksDelay = make(chan struct{})
+	v.keys.now = func() time.Time { return time.Now().Add(minJWKSRefreshInterval) }
+	var unknown sync.WaitGroup
+	for range 3 {
+		unknown.Add(1)
+		go func() {
+			defer unknown.Done()
+			_, err := v.Verify(context.Background(), ti.sign(t, "key-2", ti.claims(nil)))
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		}()
+	}
+	require.Eventually(t, func() bool { return ti.jwksHits.Load() == 2 }, 5*time.Second, 10*time.Millisecond)
+
+	// ...while the tokens of the cached key keep being verified
+	_, err = v.Verify(context.Background(), ti.sign(t, ti.kid, ti.claims(nil)))
+	require.NoError(t, err)
+
+	close(ti.jwksDelay)
+	unknown.Wait()
+	assert.Equal(t, int32(2), ti.jwksHits.Load(), "the concurrent refreshes share one fetch")
+}
+
+func TestNewVerifier_RequiresIssuerAndClientID(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	_, err := NewVerifier(Config{}, logger)
+	assert.Error(t, err)
+	_, err = NewVerifier(Config{IssuerURL: "https://idp.example.com"}, logger)
+	assert.ErrorContains(t, err, "client ID is required")
+}
@@ -0,0 +1,161 @@
+// Package openshift adds the OpenShift APIs the BFF uses when it runs on OpenShift: Projects,
+// which list only the namespaces the caller can see without per-namespace access reviews, the
+// users.openshift.io User and Group resources, and the metadata of the OpenShift OAuth server.
+// Detect whether a cluster is OpenShift with IsOpenShift; every lookup returns ErrNotOpenShift
+// on vanilla Kubernetes so callers can fall back to the core APIs.
+package openshift
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+var (
+	ProjectsGVR = schema.GroupVersionResource{Group: "project.openshift.io", Version: "v1", Resource: "projects"}
+	UsersGVR    = schema.GroupVersionResource{Group: "user.openshift.io", Version: "v1", Resource: "users"}
+	GroupsGVR   = schema.GroupVersionResource{Group: "user.openshift.io", Version: "v1", Resource: "groups"}
+)
+
+// OAuthMetadataPath serves the OAuth 2.0 authorization server metadata (RFC 8414) of the
+// OpenShift OAuth server on the API server.
+const OAuthMetadataPath = "/.well-known/oauth-authorization-server"
+
+// ErrNotOpenShift is returned when the cluster does not serve the requested OpenShift API.
+var ErrNotOpenShift = errors.New("not an OpenShift cluster")
+
+// IsOpenShift reports whether the API server serves the OpenShift project API.
+func IsOpenShift(client discovery.DiscoveryInterface) (bool, error) {
+	_, err := client.ServerResourcesForGroupVersion(ProjectsGVR.GroupVersion().String())
+	if k8serrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to discover the OpenShift project API: %w", err)
+	}
+	return true, nil
+}
+
+// Client reads the OpenShift APIs with the credentials of its dynamic client.
+type Client struct {
+	dynamic dynamic.Interface
+}
+
+func NewClient(dynamicClient dynamic.Interface) *Client {
+	return &Client{dynamic: dynamicClient}
+}
+
+// User is the OpenShift User the client authenticates as.
+type User struct {
+	Name       string
+	FullName   string
+	Groups     []string
+	Identities []string
+}
+
+// ListProjects returns the projects the caller can see, as the Namespaces they stand for.
+// Unlike listing namespaces, this only needs the caller's own credentials: the API server
+// filters the list by the caller's access.
+func (c *Client) ListProjects(ctx context.Context) ([]corev1.Namespace, error) {
+	list, err := c.dynamic.Resource(ProjectsGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, wrapError("failed to list projects", err)
+	}
+
+	namespaces := make([]corev1.Namespace, 0, len(list.Items))
+	for _, item := range list.Items {
+		var ns corev1.Namespace
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &ns); err != nil {
+			return nil, fmt.Errorf("failed to convert project %s: %w", item.GetName(), err)
+		}
+		ns.TypeMeta = metav1.TypeMeta{}
+		namespaces = append(namespaces, ns)
+	}
+	return namespaces, nil
+}
+
+// CurrentUser returns the User the client authenticates as (users/~).
+func (c *Client) CurrentUser(ctx context.Context) (*User, error) {
+	obj, err := c.dynamic.Resource(UsersGVR).Get(ctx, "~", metav1.GetOptions{})
+	if err != nil {
+		return nil, wrapError("failed to get the current user", err)
+	}
+
+	fullName, _, _ := unstructured.NestedString(obj.Object, "fullName")
+	groups, _, _ := unstructured.NestedStringSlice(obj.Object, "groups")
+	identities, _, _ := unstructured.NestedStringSlice(obj.Object, "identities")
+	return &User{
+		Name:       obj.GetName(),
+		FullName:   fullName,
+		Groups:     groups,
+		Identities: identities,
+	}, nil
+}
+
+// GroupsOf returns the names of the Groups listing user as a member. The client needs to be
+// allowed to list groups, which regular users usually aren't.
+func (c *Client) GroupsOf(ctx context.Context, user string) ([]string, error) {
+	list, err := c.dynamic.Resource(GroupsGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, wrapError("failed to list groups", err)
+	}
+
+	groups := []string{}
+	for _, group := range list.Items {
+		users, _, _ := unstructured.NestedStringSlice(group.Object, "users")
+		if slices.Contains(users, user) {
+			groups = append(groups, group.GetName())
+		}
+	}
+	return groups, nil
+}
+
+// OAuthMetadata is the subset of the OAuth server metadata used to log users in.
+type OAuthMetadata struct {
+	Issuer                        string   `json:"issuer"`
+	AuthorizationEndpoint         string   `json:"authorization_endpoint"`
+	TokenEndpoint                 string   `json:"token_endpoint"`
+	ScopesSupported               []string `json:"scopes_supported,omitempty"`
+	ResponseTypesSupported        []string `json:"response_types_supported,omitempty"`
+	GrantTypesSupported           []string `json:"grant_types_supported,omitempty"`
+	CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported,omitempty"`
+}
+
+// DiscoverOAuthMetadata fetches the OAuth server metadata from the API server behind client,
+// e.g. a clientset's Discovery().RESTClient(). The endpoint is public, so any credentials do.
+func DiscoverOAuthMetadata(ctx context.Context, client rest.Interface) (*OAuthMetadata, error) {
+	data, err := client.Get().AbsPath(OAuthMetadataPath).Do(ctx).Raw()
+	if err != nil {
+		return nil, wrapError("failed to discover the OAuth server", err)
+	}
+
+	var metadata OAuthMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse the OAuth server metadata: %w", err)
+	}
+	if metadata.Issuer == "" || metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" {
+		return nil, errors.New("incomplete OAuth server metadata")
+	}
+	return &metadata, nil
+}
+
+// wrapError maps a NotFound, which is what the API server answers for an API group it doesn't
+// serve, to ErrNotOpenShift.
+func wrapError(msg string, err error) error {
+	if k8serrors.IsNotFound(err) {
+		return fmt.Errorf("%s: %w", msg, ErrNotOpenShift)
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
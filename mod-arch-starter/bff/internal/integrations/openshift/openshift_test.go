@@ -0,0 +1,128 @@
+package openshift
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func object(gvk, name string, fields map[string]any) *unstructured.Unstructured {
+	obj := map[string]any{
+		"apiVersion": gvk,
+		"metadata":   map[string]any{"name": name},
+	}
+	for k, v := range fields {
+		obj[k] = v
+	}
+	return &unstructured.Unstructured{Object: obj}
+}
+
+func newFakeClient(objects ...runtime.Object) (*Client, *dynamicfake.FakeDynamicClient) {
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		ProjectsGVR: "ProjectList",
+		UsersGVR:    "UserList",
+		GroupsGVR:   "GroupList",
+	}, objects...)
+	return NewClient(dynamicClient), dynamicClient
+}
+
+func TestIsOpenShift(t *testing.T) {
+	discovery := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{}}
+	isOpenShift, err := IsOpenShift(discovery)
+	require.NoError(t, err)
+	assert.False(t, isOpenShift)
+
+	discovery.Resources = []*metav1.APIResourceList{{GroupVersion: "project.openshift.io/v1"}}
+	isOpenShift, err = IsOpenShift(discovery)
+	require.NoError(t, err)
+	assert.True(t, isOpenShift)
+}
+
+func TestClient(t *testing.T) {
+	projectA := object("project.openshift.io/v1", "team-a", map[string]any{"kind": "Project", "status": map[string]any{"phase": "Active"}})
+	projectA.SetAnnotations(map[string]string{"openshift.io/display-name": "Team A"})
+	client, _ := newFakeClient(
+		projectA,
+		object("user.openshift.io/v1", "~", map[string]any{"kind": "User", "fullName": "Dev Eloper", "groups": []any{"devs"}, "identities": []any{"htpasswd:dev"}}),
+		object("user.openshift.io/v1", "devs", map[string]any{"kind": "Group", "users": []any{"dev", "other"}}),
+		object("user.openshift.io/v1", "admins", map[string]any{"kind": "Group", "users": []any{"admin"}}),
+	)
+	ctx := context.Background()
+
+	namespaces, err := client.ListProjects(ctx)
+	require.NoError(t, err)
+	require.Len(t, namespaces, 1)
+	assert.Equal(t, "team-a", namespaces[0].Name)
+	assert.Equal(t, "Team A", namespaces[0].Annotations["openshift.io/display-name"])
+	assert.Equal(t, "Active", string(namespaces[0].Status.Phase))
+	assert.Empty(t, namespaces[0].Kind)
+
+	user, err := client.CurrentUser(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &User{Name: "~", FullName: "Dev Eloper", Groups: []string{"devs"}, Identities: []string{"htpasswd:dev"}}, user)
+
+	groups, err := client.GroupsOf(ctx, "dev")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"devs"}, groups)
+}
+
+func TestClient_NotOpenShift(t *testing.T) {
+	client, dynamicClient := newFakeClient()
+	dynamicClient.PrependReactor("*", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, k8serrors.NewNotFound(action.GetResource().GroupResource(), "")
+	})
+
+	_, err := client.ListProjects(context.Background())
+	assert.ErrorIs(t, err, ErrNotOpenShift)
+	_, err = client.CurrentUser(context.Background())
+	assert.ErrorIs(t, err, ErrNotOpenShift)
+	_, err = client.GroupsOf(context.Background(), "dev")
+	assert.ErrorIs(t, err, ErrNotOpenShift)
+}
+
+func TestDiscoverOAuthMetadata(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != OAuthMetadataPath {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"issuer": "https://oauth-openshift.apps.example.com",
+			"authorization_endpoint": "https://oauth-openshift.apps.example.com/oauth/authorize",
+			"token_endpoint": "https://oauth-openshift.apps.example.com/oauth/token",
+			"scopes_supported": ["user:full", "user:info"],
+			"code_challenge_methods_supported": ["plain", "S256"]
+		}`))
+	}))
+	defer srv.Close()
+
+	clientset, err := kubernetes.NewForConfig(&rest.Config{Host: srv.URL})
+	require.NoError(t, err)
+	metadata, err := DiscoverOAuthMetadata(context.Background(), clientset.Discovery().RESTClient())
+	require.NoError(t, err)
+	assert.Equal(t, "https://oauth-openshift.apps.example.com", metadata.Issuer)
+	assert.Equal(t, "https://oauth-openshift.apps.example.com/oauth/token", metadata.TokenEndpoint)
+	assert.Equal(t, []string{"plain", "S256"}, metadata.CodeChallengeMethodsSupported)
+
+	vanilla := httptest.NewServer(http.NotFoundHandler())
+	defer vanilla.Close()
+	clientset, err = kubernetes.NewForConfig(&rest.Config{Host: vanilla.URL})
+	require.NoError(t, err)
+	_, err = DiscoverOAuthMetadata(context.Background(), clientset.Discovery().RESTClient())
+	assert.ErrorIs(t, err, ErrNotOpenShift)
+}
@@ -0,0 +1,220 @@
+// Package tlsclient configures the TLS of the outbound connections of the BFF, per upstream:
+// the CA bundles trusted (added to the system pool), a client certificate, and, during
+// development only, skipping verification. The files are reloaded by Reload, so rotated CA
+// bundles mounted from a ConfigMap apply to new connections without a restart.
+package tlsclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// Config sets the files and policy of a Source.
+type Config struct {
+	// CAFiles are PEM bundles trusted in addition to the system pool.
+	CAFiles []string
+	// SkipMissingCAs ignores the CA files that can't be read, e.g. optional ConfigMap volumes;
+	// otherwise they fail New and Reload.
+	SkipMissingCAs bool
+
+	// CertFile and KeyFile are the client certificate presented to the upstream, when set.
+	CertFile string
+	KeyFile  string
+
+	// ServerName overrides the host name verified, e.g. to reach a Service by IP. It is required
+	// for upstreams reached by IP address when CAFiles are set.
+	ServerName string
+
+	// InsecureSkipVerify skips the verification of the upstream certificate. Development only.
+	InsecureSkipVerify bool
+}
+
+// Source holds the CAs and client certificate of an upstream, reloaded from their files by
+// Reload. It is safe for concurrent use.
+type Source struct {
+	cfg    Config
+	logger *slog.Logger
+
+	mu      sync.RWMutex
+	rootCAs *x509.CertPool
+	cert    *tls.Certificate
+}
+
+// New loads the files of cfg.
+func New(cfg Config, logger *slog.Logger) (*Source, error) {
+	if (cfg.CertFile == "") != (cfg.KeyFile == "") {
+		return nil, errors.New("a client certificate requires both a certificate and a key file")
+	}
+	s := &Source{cfg: cfg, logger: logger}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Files returns the files Reload reads, to be watched for rotations.
+func (s *Source) Files() []string {
+	files := append([]string{}, s.cfg.CAFiles...)
+	if s.cfg.CertFile != "" {
+		files = append(files, s.cfg.CertFile, s.cfg.KeyFile)
+	}
+	return files
+}
+
+// Reload reads the CA bundles and the client certificate again. On error the current ones are
+// kept.
+func (s *Source) Reload() error {
+	var rootCAs *x509.CertPool
+	if len(s.cfg.CAFiles) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		loaded := false
+		for _, path := range s.cfg.CAFiles {
+			pem, err := os.ReadFile(path)
+			if err == nil && !pool.AppendCertsFromPEM(pem) {
+				err = errors.New("no certificates found")
+			}
+			if err != nil {
+				if s.cfg.SkipMissingCAs {
+					s.logger.Debug("CA bundle not loaded, skipping", "path", path, "error", err)
+					continue
+				}
+				return fmt.Errorf("failed to load the CA bundle %s: %w", path, err)
+			}
+			loaded = true
+		}
+		if loaded {
+			rootCAs = pool
+		} else {
+			s.logger.Warn("No CA certificates loaded from the CA bundles, trusting the system pool", "paths", s.cfg.CAFiles)
+		}
+	}
+
+	var cert *tls.Certificate
+	if s.cfg.CertFile != "" {
+		pair, err := tls.LoadX509KeyPair(s.cfg.CertFile, s.cfg.KeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load the client certificate: %w", err)
+		}
+		cert = &pair
+	}
+
+	s.mu.Lock()
+	s.rootCAs = rootCAs
+	s.cert = cert
+	s.mu.Unlock()
+	return nil
+}
+
+// RootCAs returns the current pool of trusted CAs, nil for the system pool.
+func (s *Source) RootCAs() *x509.CertPool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.rootCAs
+}
+
+// TLSConfig returns a client configuration verifying upstreams against the current CAs and
+// presenting the current client certificate, so reloads apply to new connections.
+func (s *Source) TLSConfig() *tls.Config {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: s.cfg.ServerName,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			s.mu.RLock()
+			defer s.mu.RUnlock()
+			if s.cert == nil {
+				// No certificate is sent
+				return &tls.Certificate{}, nil
+			}
+			return s.cert, nil
+		},
+	}
+	if s.cfg.InsecureSkipVerify {
+		config.InsecureSkipVerify = true //nolint:gosec // G402: controlled by CLI flag, dev-only
+		return config
+	}
+	if len(s.cfg.CAFiles) == 0 {
+		return config
+	}
+
+	// tls.Config can't swap RootCAs per connection: the chain and host name are verified by
+	// VerifyConnection against the CAs current at handshake time instead of the built-in
+	// verification. The host name is the one sent in SNI, which is empty for IP addresses:
+	// those fail closed unless ServerName is set.
+	config.InsecureSkipVerify = true //nolint:gosec // G402: verified by VerifyConnection
+	config.VerifyConnection = func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return errors.New("tls: upstream sent no certificate")
+		}
+		if cs.ServerName == "" {
+			return errors.New("tls: can't verify the host name of an upstream reached by IP address with custom CA bundles, set its server name")
+		}
+		intermediates := x509.NewCertPool()
+		for _, cert := range cs.PeerCertificates[1:] {
+			intermediates.AddCert(cert)
+		}
+		_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+			Roots:         s.RootCAs(),
+			Intermediates: intermediates,
+			DNSName:       cs.ServerName,
+		})
+		return err
+	}
+	return config
+}
+
+// Set holds the Sources of the named upstreams and the default one of the others. A nil Set
+// trusts the system pool for every upstream.
+type Set struct {
+	defaults *Source
+	named    map[string]*Source
+}
+
+// NewSet returns a Set of the named Sources, falling back to defaults.
+func NewSet(defaults *Source, named map[string]*Source) *Set {
+	return &Set{defaults: defaults, named: named}
+}
+
+// Source returns the Source of the upstream name, the default one when it has none.
+func (s *Set) Source(name string) *Source {
+	if source, ok := s.named[name]; ok {
+		return source
+	}
+	return s.defaults
+}
+
+// TLSConfig returns the client configuration of the upstream name (see Source.TLSConfig).
+func (s *Set) TLSConfig(name string) *tls.Config {
+	if s == nil {
+		return &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return s.Source(name).TLSConfig()
+}
+
+// Has reports whether the upstream name has its own Source.
+func (s *Set) Has(name string) bool {
+	if s == nil {
+		return false
+	}
+	_, ok := s.named[name]
+	return ok
+}
+
+// Sources returns the default Source then the named ones, e.g. to watch their files.
+func (s *Set) Sources() []*Source {
+	if s == nil {
+		return nil
+	}
+	sources := []*Source{s.defaults}
+	for _, source := range s.named {
+		sources = append(sources, source)
+	}
+	return sources
+}
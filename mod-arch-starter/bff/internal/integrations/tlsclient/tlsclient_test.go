@@ -0,0 +1,224 @@
+package tlsclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+func (ca *testCA) issue(t *testing.T, name string, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	if ip := net.ParseIP(name); ip != nil {
+		template.IPAddresses = []net.IP{ip}
+	} else {
+		template.DNSNames = []string{name}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// newUpstream starts a TLS server with a certificate of ca for name, answering with the
+// common name of the client certificate, when clientCAs is set.
+func newUpstream(t *testing.T, ca *testCA, name string, clientCAs *x509.CertPool) *httptest.Server {
+	t.Helper()
+	cert, err := tls.X509KeyPair(ca.issue(t, name, x509.ExtKeyUsageServerAuth))
+	require.NoError(t, err)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) > 0 {
+			_, _ = io.WriteString(w, r.TLS.PeerCertificates[0].Subject.CommonName)
+		}
+	}))
+	srv.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	if clientCAs != nil {
+		srv.TLS.ClientCAs = clientCAs
+		srv.TLS.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// localURL returns the URL of srv by the localhost name, the name of the certificates.
+func localURL(srv *httptest.Server) string {
+	return strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)
+}
+
+func get(config *tls.Config, url string) (string, error) {
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	return string(body), err
+}
+
+func writeFile(t *testing.T, path string, data []byte) string {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestSource_CAReload(t *testing.T) {
+	ca, rotated := newTestCA(t), newTestCA(t)
+	upstream := newUpstream(t, rotated, "localhost", nil)
+	caFile := writeFile(t, filepath.Join(t.TempDir(), "ca.crt"), ca.pem)
+
+	source, err := New(Config{CAFiles: []string{caFile}}, testLogger())
+	require.NoError(t, err)
+	config := source.TLSConfig()
+	_, err = get(config, localURL(upstream))
+	assert.Error(t, err, "the upstream certificate is not issued by a trusted CA")
+
+	// The rotated bundle applies to the connections of the configuration already handed out
+	writeFile(t, caFile, rotated.pem)
+	require.NoError(t, source.Reload())
+	_, err = get(config, localURL(upstream))
+	assert.NoError(t, err)
+
+	// A broken bundle keeps the current CAs
+	writeFile(t, caFile, []byte("not a certificate"))
+	assert.Error(t, source.Reload())
+	_, err = get(config, localURL(upstream))
+	assert.NoError(t, err)
+}
+
+func TestSource_ServerName(t *testing.T) {
+	ca := newTestCA(t)
+	upstream := newUpstream(t, ca, "registry.kubeflow.svc", nil)
+	caFile := writeFile(t, filepath.Join(t.TempDir(), "ca.crt"), ca.pem)
+
+	source, err := New(Config{CAFiles: []string{caFile}}, testLogger())
+	require.NoError(t, err)
+	_, err = get(source.TLSConfig(), localURL(upstream))
+	assert.Error(t, err, "the certificate is not valid for localhost")
+
+	source, err = New(Config{CAFiles: []string{caFile}, ServerName: "registry.kubeflow.svc"}, testLogger())
+	require.NoError(t, err)
+	_, err = get(source.TLSConfig(), localURL(upstream))
+	assert.NoError(t, err)
+	_, err = get(source.TLSConfig(), upstream.URL)
+	assert.NoError(t, err, "the server name reaches an upstream by IP address")
+
+	source, err = New(Config{CAFiles: []string{caFile}}, testLogger())
+	require.NoError(t, err)
+	_, err = get(source.TLSConfig(), upstream.URL)
+	assert.ErrorContains(t, err, "IP address", "host names that can't be checked fail closed")
+}
+
+func TestSource_ClientCertificate(t *testing.T) {
+	ca := newTestCA(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+	upstream := newUpstream(t, ca, "localhost", clientCAs)
+	dir := t.TempDir()
+	caFile := writeFile(t, filepath.Join(dir, "ca.crt"), ca.pem)
+	certPEM, keyPEM := ca.issue(t, "bff", x509.ExtKeyUsageClientAuth)
+	certFile := writeFile(t, filepath.Join(dir, "tls.crt"), certPEM)
+	keyFile := writeFile(t, filepath.Join(dir, "tls.key"), keyPEM)
+
+	source, err := New(Config{CAFiles: []string{caFile}}, testLogger())
+	require.NoError(t, err)
+	_, err = get(source.TLSConfig(), localURL(upstream))
+	assert.Error(t, err, "the upstream requires a client certificate")
+
+	source, err = New(Config{CAFiles: []string{caFile}, CertFile: certFile, KeyFile: keyFile}, testLogger())
+	require.NoError(t, err)
+	assert.Equal(t, []string{caFile, certFile, keyFile}, source.Files())
+	user, err := get(source.TLSConfig(), localURL(upstream))
+	require.NoError(t, err)
+	assert.Equal(t, "bff", user)
+
+	_, err = New(Config{CertFile: certFile}, testLogger())
+	assert.Error(t, err)
+}
+
+func TestSource_SkipMissingCAs(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.crt")
+	_, err := New(Config{CAFiles: []string{missing}}, testLogger())
+	assert.Error(t, err)
+
+	source, err := New(Config{CAFiles: []string{missing}, SkipMissingCAs: true}, testLogger())
+	require.NoError(t, err)
+	assert.Nil(t, source.RootCAs(), "nothing loaded falls back to the system pool")
+}
+
+func TestSet(t *testing.T) {
+	defaults, err := New(Config{}, testLogger())
+	require.NoError(t, err)
+	insecure, err := New(Config{InsecureSkipVerify: true}, testLogger())
+	require.NoError(t, err)
+	set := NewSet(defaults, map[string]*Source{"dev-registry": insecure})
+
+	assert.True(t, set.TLSConfig("dev-registry").InsecureSkipVerify)
+	assert.False(t, set.TLSConfig("pipelines").InsecureSkipVerify)
+	assert.True(t, set.Has("dev-registry"))
+	assert.False(t, set.Has("pipelines"))
+	assert.Len(t, set.Sources(), 2)
+
+	var none *Set
+	assert.False(t, none.TLSConfig("pipelines").InsecureSkipVerify)
+	assert.Empty(t, none.Sources())
+}
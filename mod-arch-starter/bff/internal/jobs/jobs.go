@@ -0,0 +1,229 @@
+// Package jobs runs the periodic background tasks of the BFF, like pruning caches or probing
+// health checks, on interval or cron schedules. Each job runs with a timeout, and never
+// overlaps itself: a run due while the previous one is still going is skipped.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds the runs of jobs without a Timeout.
+const DefaultTimeout = time.Minute
+
+// Results of a run, as passed to the Observe hook.
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+	// ResultSkipped is a run skipped because the previous one was still going.
+	ResultSkipped = "skipped"
+)
+
+// Job is a periodic task.
+type Job struct {
+	// Name identifies the job in logs and metrics, e.g. "review-cache-prune".
+	Name string
+	// Schedule tells when the job runs (see Every and ParseSchedule).
+	Schedule Schedule
+	// Timeout bounds each run: its context is canceled after it (default DefaultTimeout).
+	Timeout time.Duration
+	// LeaderOnly jobs run on the leader replica only, when the scheduler has a Leader (see
+	// UseLeader): the runs due on the other replicas are skipped, and the context of a run is
+	// canceled once its replica stops leading. Jobs keeping state of their replica, like
+	// pruning a local cache, run everywhere.
+	LeaderOnly bool
+	// Run does the task. A returned error, or a panic, fails the run; the job keeps its
+	// schedule.
+	Run func(ctx context.Context) error
+}
+
+// Leader tells whether this replica is the one running the LeaderOnly jobs.
+type Leader interface {
+	// Leading returns a context done once this replica stops leading, and whether it leads.
+	Leading() (context.Context, bool)
+}
+
+// Scheduler runs jobs on their schedules between Start and Stop.
+type Scheduler struct {
+	logger  *slog.Logger
+	now     func() time.Time
+	observe func(job, result string, duration time.Duration)
+	leader  Leader
+
+	mu      sync.Mutex
+	jobs    []*Job
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+}
+
+func NewScheduler(logger *slog.Logger) *Scheduler {
+	return &Scheduler{logger: logger, now: time.Now}
+}
+
+// Observe calls fn after every run of a job, with its result (ResultSuccess, ResultFailure or
+// ResultSkipped) and duration, e.g. to record metrics. Call it before Start.
+func (s *Scheduler) Observe(fn func(job, result string, duration time.Duration)) {
+	s.observe = fn
+}
+
+// UseLeader runs the LeaderOnly jobs only while leader leads, e.g. a leader.Elector. Without
+// one, every replica runs them. Call it before Start.
+func (s *Scheduler) UseLeader(leader Leader) {
+	s.leader = leader
+}
+
+// Add schedules job. Jobs must be added before Start, with a unique name.
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" || job.Schedule == nil || job.Run == nil {
+		return errors.New("jobs: a job needs a name, a schedule and a run function")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return fmt.Errorf("jobs: can't add job %q once the scheduler started", job.Name)
+	}
+	for _, existing := range s.jobs {
+		if existing.Name == job.Name {
+			return fmt.Errorf("jobs: job %q is already scheduled", job.Name)
+		}
+	}
+	if job.Timeout <= 0 {
+		job.Timeout = DefaultTimeout
+	}
+	s.jobs = append(s.jobs, &job)
+	return nil
+}
+
+// Names returns the names of the scheduled jobs, in the order they were added.
+func (s *Scheduler) Names() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, len(s.jobs))
+	for i, job := range s.jobs {
+		names[i] = job.Name
+	}
+	return names
+}
+
+// Start runs the jobs on their schedules until ctx is done or Stop is called. It returns
+// right away; starting a started scheduler does nothing.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return
+	}
+	ctx, s.cancel = context.WithCancel(ctx)
+	for _, job := range s.jobs {
+		s.running.Add(1)
+		go func() {
+			defer s.running.Done()
+			s.loop(ctx, job)
+		}()
+	}
+}
+
+// Stop cancels the running jobs and waits for them to return, or for ctx to be done.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("jobs: runs still going on stop: %w", ctx.Err())
+	}
+}
+
+// loop runs job at each time of its schedule until ctx is done. Runs have their own goroutine,
+// so a slow run doesn't delay the schedule, only the runs due while it goes.
+func (s *Scheduler) loop(ctx context.Context, job *Job) {
+	var runs sync.WaitGroup
+	defer runs.Wait()
+	busy := make(chan struct{}, 1)
+
+	for {
+		next := job.Schedule.Next(s.now())
+		if next.IsZero() {
+			s.logger.Warn("job has no next run, stopping its schedule", "job", job.Name)
+			return
+		}
+		timer := time.NewTimer(next.Sub(s.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		select {
+		case busy <- struct{}{}:
+		default:
+			s.logger.Warn("job run skipped, the previous run is still going", "job", job.Name)
+			s.record(job.Name, ResultSkipped, 0)
+			continue
+		}
+		runs.Add(1)
+		go func() {
+			defer runs.Done()
+			defer func() { <-busy }()
+			s.run(ctx, job)
+		}()
+	}
+}
+
+// run runs job once.
+func (s *Scheduler) run(ctx context.Context, job *Job) {
+	ctx, cancel := context.WithTimeout(ctx, job.Timeout)
+	defer cancel()
+	if job.LeaderOnly && s.leader != nil {
+		term, leading := s.leader.Leading()
+		if !leading {
+			s.logger.Debug("job run skipped, another replica leads", "job", job.Name)
+			return
+		}
+		defer context.AfterFunc(term, cancel)()
+	}
+
+	start := s.now()
+	err := func() (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = fmt.Errorf("panic: %v", p)
+				s.logger.Error("job panicked", "job", job.Name, "panic", p, "stack", string(debug.Stack()))
+			}
+		}()
+		return job.Run(ctx)
+	}()
+	duration := s.now().Sub(start)
+
+	if err != nil {
+		s.logger.Error("job failed", "job", job.Name, "duration", duration, "error", err)
+		s.record(job.Name, ResultFailure, duration)
+		return
+	}
+	s.logger.Debug("job completed", "job", job.Name, "duration", duration)
+	s.record(job.Name, ResultSuccess, duration)
+}
+
+func (s *Scheduler) record(job, result string, duration time.Duration) {
+	if s.observe != nil {
+		s.observe(job, result, duration)
+	}
+}
@@ -0,0 +1,212 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedule(t *testing.T) {
+	at := func(value string) time.Time {
+		tm, err := time.Parse("2006-01-02 15:04", value)
+		require.NoError(t, err)
+		return tm
+	}
+	// 2025-01-01 is a Wednesday
+	from := at("2025-01-01 10:07")
+
+	for spec, want := range map[string]string{
+		"* * * * *":          "2025-01-01 10:08",
+		"*/15 * * * *":       "2025-01-01 10:15",
+		"0 * * * *":          "2025-01-01 11:00",
+		"@hourly":            "2025-01-01 11:00",
+		"30 9 * * *":         "2025-01-02 09:30",
+		"@daily":             "2025-01-02 00:00",
+		"0 0 * * 0":          "2025-01-05 00:00",
+		"0 0 * * 7":          "2025-01-05 00:00",
+		"0 8 * * 1-5":        "2025-01-02 08:00",
+		"0 0 1 * *":          "2025-02-01 00:00",
+		"0 0 29 2 *":         "2028-02-29 00:00",
+		"0 12 15 * 5":        "2025-01-03 12:00",
+		"5,10-20/5 10 * * *": "2025-01-01 10:10",
+	} {
+		schedule, err := ParseSchedule(spec)
+		require.NoError(t, err, spec)
+		assert.Equal(t, at(want), schedule.Next(from), spec)
+	}
+
+	every, err := ParseSchedule("@every 90s")
+	require.NoError(t, err)
+	assert.Equal(t, from.Add(90*time.Second), every.Next(from))
+
+	never, err := ParseSchedule("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, never.Next(from).IsZero(), "February 30 never comes")
+
+	for _, spec := range []string{"", "@every", "@every -1m", "@yearly", "* * * *", "60 * * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+		_, err := ParseSchedule(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+type observed struct {
+	mu      sync.Mutex
+	results map[string][]string
+}
+
+func (o *observed) record(job, result string, _ time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.results[job] = append(o.results[job], result)
+}
+
+func (o *observed) get(job string) []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]string(nil), o.results[job]...)
+}
+
+func newTestScheduler() (*Scheduler, *observed) {
+	s := NewScheduler(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	o := &observed{results: map[string][]string{}}
+	s.Observe(o.record)
+	return s, o
+}
+
+func TestScheduler(t *testing.T) {
+	s, o := newTestScheduler()
+	var runs atomic.Int32
+	require.NoError(t, s.Add(Job{Name: "count", Schedule: Every(5 * time.Millisecond), Run: func(context.Context) error {
+		runs.Add(1)
+		return nil
+	}}))
+	require.NoError(t, s.Add(Job{Name: "fail", Schedule: Every(5 * time.Millisecond), Run: func(context.Context) error {
+		return errors.New("boom")
+	}}))
+	require.NoError(t, s.Add(Job{Name: "panic", Schedule: Every(5 * time.Millisecond), Run: func(context.Context) error {
+		panic("boom")
+	}}))
+	assert.Error(t, s.Add(Job{Name: "count", Schedule: Every(time.Second), Run: func(context.Context) error { return nil }}), "names are unique")
+	assert.Error(t, s.Add(Job{Name: "no-schedule", Run: func(context.Context) error { return nil }}))
+	assert.Equal(t, []string{"count", "fail", "panic"}, s.Names())
+
+	s.Start(context.Background())
+	assert.Error(t, s.Add(Job{Name: "late", Schedule: Every(time.Second), Run: func(context.Context) error { return nil }}))
+	assert.Eventually(t, func() bool {
+		return runs.Load() >= 3 && len(o.get("fail")) >= 2 && len(o.get("panic")) >= 2
+	}, 5*time.Second, time.Millisecond)
+	require.NoError(t, s.Stop(context.Background()))
+
+	assert.Equal(t, ResultSuccess, o.get("count")[0])
+	assert.Equal(t, ResultFailure, o.get("fail")[0])
+	assert.Equal(t, ResultFailure, o.get("panic")[0], "a panic fails the run")
+	stopped := runs.Load()
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, stopped, runs.Load(), "no run after Stop")
+}
+
+func TestScheduler_OverlapAndTimeout(t *testing.T) {
+	s, o := newTestScheduler()
+	var running, maxRunning atomic.Int32
+	require.NoError(t, s.Add(Job{Name: "slow", Schedule: Every(2 * time.Millisecond), Timeout: 30 * time.Millisecond, Run: func(ctx context.Context) error {
+		if n := running.Add(1); n > maxRunning.Load() {
+			maxRunning.Store(n)
+		}
+		defer running.Add(-1)
+		<-ctx.Done()
+		return ctx.Err()
+	}}))
+
+	s.Start(context.Background())
+	assert.Eventually(t, func() bool {
+		results := o.get("slow")
+		return len(results) > 0 && results[len(results)-1] == ResultFailure
+	}, 5*time.Second, time.Millisecond, "the run times out")
+	require.NoError(t, s.Stop(context.Background()))
+
+	assert.Equal(t, int32(1), maxRunning.Load(), "runs never overlap")
+	assert.Contains(t, o.get("slow"), ResultSkipped)
+}
+
+func TestScheduler_StopWaitsForRuns(t *testing.T) {
+	s, _ := newTestScheduler()
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	require.NoError(t, s.Add(Job{Name: "stuck", Schedule: Every(time.Millisecond), Run: func(context.Context) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		// Ignores its context
+		<-release
+		return nil
+	}}))
+	s.Start(context.Background())
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, s.Stop(ctx), context.DeadlineExceeded)
+	close(release)
+	assert.NoError(t, s.Stop(context.Background()))
+}
+
+type fakeLeader struct {
+	mu     sync.Mutex
+	term   context.Context
+	cancel context.CancelFunc
+}
+
+func (l *fakeLeader) Leading() (context.Context, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.term, l.term != nil
+}
+
+func (l *fakeLeader) lead(leading bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if leading {
+		l.term, l.cancel = context.WithCancel(context.Background())
+		return
+	}
+	l.cancel()
+	l.term = nil
+}
+
+func TestScheduler_LeaderOnly(t *testing.T) {
+	s, o := newTestScheduler()
+	leader := &fakeLeader{}
+	s.UseLeader(leader)
+	var everywhere, singleton atomic.Int32
+	require.NoError(t, s.Add(Job{Name: "everywhere", Schedule: Every(2 * time.Millisecond), Run: func(context.Context) error {
+		everywhere.Add(1)
+		return nil
+	}}))
+	require.NoError(t, s.Add(Job{Name: "singleton", LeaderOnly: true, Schedule: Every(2 * time.Millisecond), Run: func(ctx context.Context) error {
+		singleton.Add(1)
+		<-ctx.Done()
+		return ctx.Err()
+	}}))
+
+	s.Start(context.Background())
+	defer func() { require.NoError(t, s.Stop(context.Background())) }()
+	assert.Eventually(t, func() bool { return everywhere.Load() >= 3 }, 5*time.Second, time.Millisecond)
+	assert.Zero(t, singleton.Load(), "not leading")
+	assert.Empty(t, o.get("singleton"), "the runs of other replicas are not recorded")
+
+	leader.lead(true)
+	assert.Eventually(t, func() bool { return singleton.Load() == 1 }, 5*time.Second, time.Millisecond)
+	leader.lead(false)
+	assert.Eventually(t, func() bool { return len(o.get("singleton")) > 0 }, 5*time.Second, time.Millisecond,
+		"losing the lead cancels the run")
+	assert.Equal(t, ResultFailure, o.get("singleton")[0])
+}
@@ -0,0 +1,181 @@
+// Package leader elects one replica of the BFF as the leader with a coordination.k8s.io Lease,
+// so singleton background jobs run once however many replicas are serving. The leader renews
+// the Lease while it runs and releases it on shutdown; another replica takes over once the
+// Lease expires otherwise.
+package leader
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// Config configures New.
+type Config struct {
+	// Namespace and Name of the Lease.
+	Namespace string
+	Name      string
+	// Identity of this replica in the Lease (default: NewIdentity()).
+	Identity string
+	// LeaseDuration is how long the other replicas wait before taking over a Lease that is not
+	// renewed, e.g. of a crashed leader. The leader renews it within two thirds of it.
+	LeaseDuration time.Duration
+}
+
+// Elector takes part in the election of its Lease between Start and Stop.
+type Elector struct {
+	elector  *leaderelection.LeaderElector
+	identity string
+	logger   *slog.Logger
+	observe  func(leading bool)
+
+	mu   sync.Mutex
+	term context.Context // of the current leadership, nil when not leading
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+func New(client kubernetes.Interface, cfg Config, logger *slog.Logger) (*Elector, error) {
+	if cfg.Namespace == "" || cfg.Name == "" {
+		return nil, errors.New("leader: the Lease needs a namespace and a name")
+	}
+	if cfg.Identity == "" {
+		cfg.Identity = NewIdentity()
+	}
+	e := &Elector{identity: cfg.Identity, logger: logger.With("lease", cfg.Namespace+"/"+cfg.Name)}
+
+	var err error
+	e.elector, err = leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock: &resourcelock.LeaseLock{
+			LeaseMeta:  metav1.ObjectMeta{Namespace: cfg.Namespace, Name: cfg.Name},
+			Client:     client.CoordinationV1(),
+			LockConfig: resourcelock.ResourceLockConfig{Identity: cfg.Identity},
+		},
+		LeaseDuration:   cfg.LeaseDuration,
+		RenewDeadline:   cfg.LeaseDuration * 2 / 3,
+		RetryPeriod:     cfg.LeaseDuration * 2 / 15,
+		ReleaseOnCancel: true,
+		Name:            cfg.Name,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: e.startedLeading,
+			OnStoppedLeading: e.stoppedLeading,
+			OnNewLeader: func(identity string) {
+				if identity != e.identity {
+					e.logger.Info("another replica leads", "leader", identity)
+				}
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("leader: %w", err)
+	}
+	return e, nil
+}
+
+// NewIdentity returns the identity of this replica: its host name, the pod name in a cluster,
+// and a random suffix telling apart restarted processes.
+func NewIdentity() string {
+	host, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return host + "_" + hex.EncodeToString(suffix)
+}
+
+// Identity returns the identity of this replica in the Lease.
+func (e *Elector) Identity() string {
+	return e.identity
+}
+
+// Observe calls fn whenever this replica starts or stops leading, e.g. to record a metric.
+// Call it before Start.
+func (e *Elector) Observe(fn func(leading bool)) {
+	e.observe = fn
+}
+
+// Leading returns the context of the current leadership, done once this replica stops leading,
+// and whether it leads.
+func (e *Elector) Leading() (context.Context, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.term == nil || e.term.Err() != nil {
+		return nil, false
+	}
+	return e.term, true
+}
+
+// Start takes part in the election until ctx is done or Stop is called, running again for
+// the Lease when this replica loses it. It returns right away; starting a started Elector does
+// nothing.
+func (e *Elector) Start(ctx context.Context) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.stop != nil {
+		return
+	}
+	ctx, e.stop = context.WithCancel(ctx)
+	e.done = make(chan struct{})
+	go func() {
+		defer close(e.done)
+		for ctx.Err() == nil {
+			e.elector.Run(ctx)
+		}
+	}()
+}
+
+// Stop leaves the election, releasing the Lease when this replica leads so another one takes
+// over right away, and waits for the release or for ctx to be done.
+func (e *Elector) Stop(ctx context.Context) error {
+	e.mu.Lock()
+	stop, done := e.stop, e.done
+	e.mu.Unlock()
+	if stop == nil {
+		return nil
+	}
+	stop()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("leader: the Lease was not released: %w", ctx.Err())
+	}
+}
+
+// startedLeading is called in its own goroutine, possibly once the term already ended. The
+// observer is called with e.mu held, so it sees the changes in order.
+func (e *Elector) startedLeading(term context.Context) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if term.Err() != nil {
+		return
+	}
+	e.term = term
+	e.logger.Info("leading", "identity", e.identity)
+	if e.observe != nil {
+		e.observe(true)
+	}
+}
+
+// stoppedLeading is called whenever an election run returns, leading or not.
+func (e *Elector) stoppedLeading() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.term == nil {
+		return
+	}
+	e.term = nil
+	e.logger.Info("stopped leading", "identity", e.identity)
+	if e.observe != nil {
+		e.observe(false)
+	}
+}
@@ -0,0 +1,81 @@
+package leader
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestElector(t *testing.T) {
+	client := fake.NewClientset()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	var mu sync.Mutex
+	changes := map[string][]bool{}
+	newElector := func(identity string) *Elector {
+		e, err := New(client, Config{Namespace: "bff", Name: "mod-arch-bff", Identity: identity, LeaseDuration: time.Second}, logger)
+		require.NoError(t, err)
+		e.Observe(func(leading bool) {
+			mu.Lock()
+			defer mu.Unlock()
+			changes[identity] = append(changes[identity], leading)
+		})
+		return e
+	}
+	observed := func(identity string) []bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]bool(nil), changes[identity]...)
+	}
+
+	first := newElector("first")
+	_, leading := first.Leading()
+	assert.False(t, leading, "not started")
+	first.Start(context.Background())
+	assert.Eventually(t, func() bool {
+		_, leading := first.Leading()
+		return leading
+	}, 5*time.Second, 10*time.Millisecond)
+
+	second := newElector("second")
+	second.Start(context.Background())
+	defer func() { assert.NoError(t, second.Stop(context.Background())) }()
+	time.Sleep(300 * time.Millisecond)
+	_, leading = second.Leading()
+	assert.False(t, leading, "one leader at a time")
+
+	term, _ := first.Leading()
+	require.NoError(t, first.Stop(context.Background()))
+	assert.Error(t, term.Err(), "stopping ends the term")
+	_, leading = first.Leading()
+	assert.False(t, leading)
+	assert.Equal(t, []bool{true, false}, observed("first"))
+
+	lease, err := client.CoordinationV1().Leases("bff").Get(context.Background(), "mod-arch-bff", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotEqual(t, "first", lease.Spec.HolderIdentity, "stopping releases the Lease")
+
+	assert.Eventually(t, func() bool {
+		_, leading := second.Leading()
+		return leading
+	}, 5*time.Second, 10*time.Millisecond, "another replica takes over")
+	assert.Equal(t, []bool{true}, observed("second"))
+}
+
+func TestNew(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	_, err := New(fake.NewClientset(), Config{Name: "mod-arch-bff", LeaseDuration: time.Second}, logger)
+	assert.Error(t, err, "the Lease needs a namespace")
+
+	e, err := New(fake.NewClientset(), Config{Namespace: "bff", Name: "mod-arch-bff", LeaseDuration: time.Second}, logger)
+	require.NoError(t, err)
+	assert.NotEmpty(t, e.Identity())
+	assert.NotEqual(t, e.Identity(), NewIdentity(), "identities tell apart processes")
+}
@@ -0,0 +1,234 @@
+// Package logger builds the BFF slog loggers: JSON (or text) output, a log level that can be
+// changed at runtime with per-package overrides, and request-scoped loggers carried in the
+// context.Context so handlers and repositories log with the request ID, trace ID and user ID.
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+)
+
+// Output formats.
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Attribute keys of the request-scoped fields.
+const (
+	RequestIDKey = "request_id"
+	TraceIDKey   = "trace_id"
+	UserIDKey    = "user_id"
+	PackageKey   = "package"
+)
+
+// IsValidFormat returns true if format is a supported output format.
+func IsValidFormat(format string) bool {
+	return format == FormatJSON || format == FormatText
+}
+
+// Levels holds the minimum log level and its per-package overrides. It is safe for concurrent
+// use, so levels can be changed while the BFF runs.
+type Levels struct {
+	level slog.LevelVar
+
+	mu       sync.RWMutex
+	packages map[string]slog.Level
+}
+
+func NewLevels(level slog.Level) *Levels {
+	l := &Levels{packages: map[string]slog.Level{}}
+	l.level.Set(level)
+	return l
+}
+
+// Level returns the level of the loggers without a package override.
+func (l *Levels) Level() slog.Level {
+	return l.level.Level()
+}
+
+func (l *Levels) SetLevel(level slog.Level) {
+	l.level.Set(level)
+}
+
+// PackageLevels returns a copy of the per-package overrides.
+func (l *Levels) PackageLevels() map[string]slog.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	packages := make(map[string]slog.Level, len(l.packages))
+	for pkg, level := range l.packages {
+		packages[pkg] = level
+	}
+	return packages
+}
+
+// SetPackageLevel overrides the level of the loggers of pkg (see ForPackage).
+func (l *Levels) SetPackageLevel(pkg string, level slog.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.packages[pkg] = level
+}
+
+// ResetPackageLevel removes the override of pkg.
+func (l *Levels) ResetPackageLevel(pkg string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.packages, pkg)
+}
+
+// SetPackageLevels replaces every per-package override.
+func (l *Levels) SetPackageLevels(packages map[string]slog.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.packages = make(map[string]slog.Level, len(packages))
+	for pkg, level := range packages {
+		l.packages[pkg] = level
+	}
+}
+
+func (l *Levels) levelFor(pkg string) slog.Level {
+	if pkg != "" {
+		l.mu.RLock()
+		level, ok := l.packages[pkg]
+		l.mu.RUnlock()
+		if ok {
+			return level
+		}
+	}
+	return l.level.Level()
+}
+
+// ParsePackageLevels parses per-package overrides written as "package=level", e.g.
+// ["proxy=debug", "kubernetes=warn"].
+func ParsePackageLevels(entries []string) (map[string]slog.Level, error) {
+	packages := make(map[string]slog.Level, len(entries))
+	for _, entry := range entries {
+		pkg, value, ok := strings.Cut(entry, "=")
+		pkg = strings.TrimSpace(pkg)
+		if !ok || pkg == "" {
+			return nil, fmt.Errorf("invalid package log level %q (must be package=level)", entry)
+		}
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(strings.TrimSpace(value))); err != nil {
+			return nil, fmt.Errorf("invalid package log level %q: %w", entry, err)
+		}
+		packages[pkg] = level
+	}
+	return packages, nil
+}
+
+// FormatPackageLevels is the inverse of ParsePackageLevels, sorted by package.
+func FormatPackageLevels(packages map[string]slog.Level) []string {
+	entries := make([]string, 0, len(packages))
+	for pkg, level := range packages {
+		entries = append(entries, pkg+"="+level.String())
+	}
+	sort.Strings(entries)
+	return entries
+}
+
+// Options configures New. Zero values use the defaults.
+type Options struct {
+	// Format is FormatJSON (default) or FormatText.
+	Format string
+	// Output defaults to os.Stdout.
+	Output io.Writer
+	// Levels defaults to info without overrides.
+	Levels *Levels
+}
+
+// New returns a logger writing to opts.Output whose level follows opts.Levels.
+func New(opts Options) (*slog.Logger, error) {
+	if opts.Output == nil {
+		opts.Output = os.Stdout
+	}
+	if opts.Levels == nil {
+		opts.Levels = NewLevels(slog.LevelInfo)
+	}
+
+	// The inner handler accepts everything, levelHandler decides per package
+	handlerOpts := &slog.HandlerOptions{Level: slog.Level(-100)}
+	var inner slog.Handler
+	switch opts.Format {
+	case FormatJSON, "":
+		inner = slog.NewJSONHandler(opts.Output, handlerOpts)
+	case FormatText:
+		inner = slog.NewTextHandler(opts.Output, handlerOpts)
+	default:
+		return nil, fmt.Errorf("invalid log format %q (must be json or text)", opts.Format)
+	}
+	return slog.New(&levelHandler{inner: inner, levels: opts.Levels}), nil
+}
+
+// ForPackage returns a logger for the named package (e.g. "proxy"), tagged with a "package"
+// attribute. Its level follows the package override of the Levels the logger was created with.
+func ForPackage(logger *slog.Logger, pkg string) *slog.Logger {
+	if h, ok := logger.Handler().(*levelHandler); ok {
+		return slog.New(&levelHandler{inner: h.inner, levels: h.levels, pkg: pkg}).With(PackageKey, pkg)
+	}
+	return logger.With(PackageKey, pkg)
+}
+
+// LevelsOf returns the Levels of a logger created with New (or derived from one), or nil.
+func LevelsOf(logger *slog.Logger) *Levels {
+	if h, ok := logger.Handler().(*levelHandler); ok {
+		return h.levels
+	}
+	return nil
+}
+
+// levelHandler filters records with the level of its package.
+type levelHandler struct {
+	inner  slog.Handler
+	levels *Levels
+	pkg    string
+}
+
+func (h *levelHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.levels.levelFor(h.pkg)
+}
+
+func (h *levelHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.inner.Handle(ctx, record)
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{inner: h.inner.WithAttrs(attrs), levels: h.levels, pkg: h.pkg}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{inner: h.inner.WithGroup(name), levels: h.levels, pkg: h.pkg}
+}
+
+// NewContext returns a copy of ctx carrying logger.
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, constants.TraceLoggerKey, logger)
+}
+
+// FromContext returns the request-scoped logger of ctx, with the request ID, trace ID and
+// user ID of the request. Outside of a request it returns slog.Default().
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(constants.TraceLoggerKey).(*slog.Logger); ok && logger != nil {
+		return logger
+	}
+	return slog.Default()
+}
+
+// FromRequest returns the request-scoped logger of r.
+func FromRequest(r *http.Request) *slog.Logger {
+	return FromContext(r.Context())
+}
+
+// With returns a copy of ctx whose logger has the extra attributes.
+func With(ctx context.Context, args ...any) context.Context {
+	return NewContext(ctx, FromContext(ctx).With(args...))
+}
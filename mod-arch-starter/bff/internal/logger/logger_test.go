@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_PackageLevels(t *testing.T) {
+	var buf bytes.Buffer
+	levels := NewLevels(slog.LevelInfo)
+	logger, err := New(Options{Output: &buf, Levels: levels})
+	require.NoError(t, err)
+	proxyLogger := ForPackage(logger, "proxy")
+
+	logger.Debug("hidden")
+	proxyLogger.Debug("hidden")
+	assert.Empty(t, buf.String())
+
+	levels.SetPackageLevel("proxy", slog.LevelDebug)
+	logger.Debug("hidden")
+	proxyLogger.With("upstream", "registry").Debug("visible")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "visible", record["msg"])
+	assert.Equal(t, "proxy", record[PackageKey])
+	assert.Equal(t, "registry", record["upstream"])
+
+	// The base level applies again once the override is removed
+	buf.Reset()
+	levels.ResetPackageLevel("proxy")
+	proxyLogger.Debug("hidden")
+	levels.SetLevel(slog.LevelDebug)
+	proxyLogger.Debug("visible")
+	assert.Contains(t, buf.String(), "visible")
+	assert.NotContains(t, buf.String(), "hidden")
+}
+
+func TestNew_Format(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(Options{Format: FormatText, Output: &buf})
+	require.NoError(t, err)
+	logger.Info("hello", "key", "value")
+	assert.Contains(t, buf.String(), "msg=hello key=value")
+
+	_, err = New(Options{Format: "xml"})
+	assert.Error(t, err)
+}
+
+func TestParsePackageLevels(t *testing.T) {
+	levels, err := ParsePackageLevels([]string{"proxy=debug", " kubernetes = WARN "})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]slog.Level{"proxy": slog.LevelDebug, "kubernetes": slog.LevelWarn}, levels)
+	assert.Equal(t, []string{"kubernetes=WARN", "proxy=DEBUG"}, FormatPackageLevels(levels))
+
+	for _, invalid := range []string{"proxy", "=debug", "proxy=loud"} {
+		_, err := ParsePackageLevels([]string{invalid})
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	assert.Same(t, slog.Default(), FromContext(context.Background()))
+
+	var buf bytes.Buffer
+	logger, err := New(Options{Output: &buf})
+	require.NoError(t, err)
+	ctx := With(NewContext(context.Background(), logger), RequestIDKey, "req-1")
+	FromContext(ctx).Info("in request")
+	assert.Contains(t, buf.String(), `"request_id":"req-1"`)
+}
@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type routeKey struct{}
+
+// UnmatchedRoute is the route label used for requests that don't match a known route
+// (static assets, 404s), which keeps the label cardinality bounded.
+const UnmatchedRoute = "other"
+
+// RouteFunc returns the route pattern of a request (e.g. "/api/v1/namespaces/:namespace"),
+// or "" when it doesn't match a known route. Raw paths must not be used as labels.
+type RouteFunc func(r *http.Request) string
+
+// Middleware records duration, request/response size and in-flight count of every request.
+// Requests are labelled with method, status code and the route returned by route.
+func (m *Metrics) Middleware(route RouteFunc, next http.Handler) http.Handler {
+	routeLabel := promhttp.WithLabelFromCtx("route", RouteFromContext)
+
+	instrumented := promhttp.InstrumentHandlerInFlight(m.httpInFlight,
+		promhttp.InstrumentHandlerDuration(m.httpRequestDuration,
+			promhttp.InstrumentHandlerRequestSize(m.httpRequestSize,
+				promhttp.InstrumentHandlerResponseSize(m.httpResponseSize, next, routeLabel),
+				routeLabel),
+			routeLabel))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		label := route(r)
+		if label == "" {
+			label = UnmatchedRoute
+		}
+		instrumented.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), routeKey{}, label)))
+	})
+}
+
+// RouteFromContext returns the route label of the request served with ctx, UnmatchedRoute when
+// it isn't served by Middleware.
+func RouteFromContext(ctx context.Context) string {
+	if r, ok := ctx.Value(routeKey{}).(string); ok {
+		return r
+	}
+	return UnmatchedRoute
+}
+
+// RecordPanic counts a panic recovered while serving a request of route.
+func (m *Metrics) RecordPanic(route string) {
+	m.httpPanics.WithLabelValues(route).Inc()
+}
+
+// RecordThrottled counts a request rejected by the rate limit named limit.
+func (m *Metrics) RecordThrottled(limit string) {
+	m.httpThrottled.WithLabelValues(limit).Inc()
+}
+
+// RecordQueueDepth sets the number of requests waiting for the concurrency limits.
+func (m *Metrics) RecordQueueDepth(waiting int) {
+	m.httpQueued.Set(float64(waiting))
+}
+
+// RecordShed counts a request rejected by the concurrency limit named limit, for reason.
+func (m *Metrics) RecordShed(limit, reason string) {
+	m.httpShed.WithLabelValues(limit, reason).Inc()
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/jobs"
+)
+
+// RecordJobRun records a run of a background job. Its signature matches the hook of
+// jobs.Scheduler.Observe; skipped runs are only counted.
+func (m *Metrics) RecordJobRun(job, result string, duration time.Duration) {
+	m.jobRuns.WithLabelValues(job, result).Inc()
+	if result != jobs.ResultSkipped {
+		m.jobRunDuration.WithLabelValues(job, result).Observe(duration.Seconds())
+	}
+}
+
+// RecordHealthCheck records the result of a background probe of the health check named check.
+func (m *Metrics) RecordHealthCheck(check string, passed bool) {
+	value := 0.0
+	if passed {
+		value = 1
+	}
+	m.healthCheckUp.WithLabelValues(check).Set(value)
+}
+
+// RecordLeader records whether this replica leads. Its signature matches the hook of
+// leader.Elector.Observe.
+func (m *Metrics) RecordLeader(leading bool) {
+	value := 0.0
+	if leading {
+		value = 1
+	}
+	m.leaderLeading.Set(value)
+}
+
+// RecordWarmupProgress records the steps of the cache warmup by state.
+func (m *Metrics) RecordWarmupProgress(pending, succeeded, failed int) {
+	m.warmupSteps.WithLabelValues("pending").Set(float64(pending))
+	m.warmupSteps.WithLabelValues("succeeded").Set(float64(succeeded))
+	m.warmupSteps.WithLabelValues("failed").Set(float64(failed))
+}
+
+// RecordWarmupDone records the duration of the cache warmup once it is over.
+func (m *Metrics) RecordWarmupDone(duration time.Duration) {
+	m.warmupDuration.Set(duration.Seconds())
+}
@@ -0,0 +1,143 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	clientmetrics "k8s.io/client-go/tools/metrics"
+)
+
+// WrapKubernetesTransport instruments a Kubernetes client transport with the API server
+// latency and request count per verb and resource. Its signature matches
+// transport.WrapperFunc, so it can be passed to rest.Config.Wrap.
+func (m *Metrics) WrapKubernetesTransport(rt http.RoundTripper) http.RoundTripper {
+	return &kubernetesRoundTripper{metrics: m, next: rt}
+}
+
+type kubernetesRoundTripper struct {
+	metrics *Metrics
+	next    http.RoundTripper
+}
+
+func (t *kubernetesRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	verb, resource := KubernetesRequestInfo(req)
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	elapsed := time.Since(start).Seconds()
+
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	t.metrics.kubernetesRequestDuration.WithLabelValues(verb, resource, code).Observe(elapsed)
+	t.metrics.kubernetesRequests.WithLabelValues(verb, resource, code).Inc()
+
+	return resp, err
+}
+
+// KubernetesRequestInfo derives the Kubernetes verb (get, list, watch, create, update, patch,
+// delete, deletecollection) and resource (with subresource, e.g. "pods/log") from an API
+// server request. Non-resource requests (e.g. /version) return the lower-cased method and "".
+func KubernetesRequestInfo(req *http.Request) (verb, resource string) {
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+
+	var parts []string
+	switch {
+	case len(segments) >= 3 && segments[0] == "api":
+		parts = segments[2:] // /api/{version}/...
+	case len(segments) >= 4 && segments[0] == "apis":
+		parts = segments[3:] // /apis/{group}/{version}/...
+	default:
+		return strings.ToLower(req.Method), ""
+	}
+
+	// /namespaces/{namespace}/{resource}/... but not /namespaces/{name} itself
+	if len(parts) > 2 && parts[0] == "namespaces" {
+		parts = parts[2:]
+	}
+
+	resource = parts[0]
+	hasName := len(parts) >= 2
+	if len(parts) >= 3 {
+		resource += "/" + parts[2]
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		switch {
+		case req.URL.Query().Get("watch") == "true" || req.URL.Query().Get("watch") == "1":
+			verb = "watch"
+		case hasName:
+			verb = "get"
+		default:
+			verb = "list"
+		}
+	case http.MethodPost:
+		verb = "create"
+	case http.MethodPut:
+		verb = "update"
+	case http.MethodPatch:
+		verb = "patch"
+	case http.MethodDelete:
+		if hasName {
+			verb = "delete"
+		} else {
+			verb = "deletecollection"
+		}
+	default:
+		verb = strings.ToLower(req.Method)
+	}
+
+	return verb, resource
+}
+
+// RecordReviewCacheLookup counts a lookup of the review cache for review.
+func (m *Metrics) RecordReviewCacheLookup(review string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.kubernetesReviewCache.WithLabelValues(review, result).Inc()
+}
+
+// RecordIdentityLookup counts an identity lookup with its result: "hit", "miss" or "shared".
+func (m *Metrics) RecordIdentityLookup(lookup, result string) {
+	m.kubernetesIdentityLookups.WithLabelValues(lookup, result).Inc()
+}
+
+// rateLimiterMetrics is the Metrics receiving client-go rate limiter latencies, see
+// RecordKubernetesRateLimiter.
+var rateLimiterMetrics atomic.Pointer[Metrics]
+
+var setRateLimiterLatency sync.Once
+
+// RecordKubernetesRateLimiter records the time Kubernetes API server requests wait for the
+// client-side rate limiter of client-go, i.e. how much they are throttled by the QPS and
+// burst of the clients. client-go reports it process-wide, so the last Metrics passed here
+// records it.
+func (m *Metrics) RecordKubernetesRateLimiter() {
+	rateLimiterMetrics.Store(m)
+	setRateLimiterLatency.Do(func() {
+		// clientmetrics.Register only applies once per process and controller-runtime already
+		// calls it on init, so the hook is set directly.
+		clientmetrics.RateLimiterLatency = rateLimiterLatency{}
+	})
+}
+
+type rateLimiterLatency struct{}
+
+func (rateLimiterLatency) Observe(_ context.Context, verb string, u url.URL, latency time.Duration) {
+	m := rateLimiterMetrics.Load()
+	if m == nil {
+		return
+	}
+	verb, resource := KubernetesRequestInfo(&http.Request{Method: verb, URL: &u})
+	m.kubernetesRateLimiter.WithLabelValues(verb, resource).Observe(latency.Seconds())
+}
@@ -0,0 +1,235 @@
+// Package metrics exposes Prometheus metrics for the BFF: HTTP server request duration, sizes
+// and in-flight requests, and Kubernetes API server latency and status codes per verb/resource.
+//
+// Metrics are registered on a dedicated registry (not the Prometheus default one), so that
+// several App instances, e.g. in tests, don't collide.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "bff"
+
+// Metrics holds the BFF collectors and the registry they are registered on.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequestDuration *prometheus.HistogramVec
+	httpRequestSize     *prometheus.HistogramVec
+	httpResponseSize    *prometheus.HistogramVec
+	httpInFlight        prometheus.Gauge
+	httpThrottled       *prometheus.CounterVec
+	httpQueued          prometheus.Gauge
+	httpShed            *prometheus.CounterVec
+	httpPanics          *prometheus.CounterVec
+
+	kubernetesRequestDuration *prometheus.HistogramVec
+	kubernetesRequests        *prometheus.CounterVec
+	kubernetesReviewCache     *prometheus.CounterVec
+	kubernetesIdentityLookups *prometheus.CounterVec
+	kubernetesRateLimiter     *prometheus.HistogramVec
+
+	upstreamCircuitBreaker *prometheus.GaugeVec
+	upstreamRetries        *prometheus.CounterVec
+	webhookDeliveries      *prometheus.CounterVec
+
+	jobRunDuration *prometheus.HistogramVec
+	jobRuns        *prometheus.CounterVec
+	healthCheckUp  *prometheus.GaugeVec
+	leaderLeading  prometheus.Gauge
+
+	warmupSteps    *prometheus.GaugeVec
+	warmupDuration prometheus.Gauge
+}
+
+// New creates the BFF collectors plus the standard Go runtime and process collectors.
+func New() *Metrics {
+	sizeBuckets := prometheus.ExponentialBuckets(100, 10, 6) // 100B .. 10MB
+
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of HTTP requests served by the BFF.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "code"}),
+		httpRequestSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_size_bytes",
+			Help:      "Size of HTTP requests served by the BFF.",
+			Buckets:   sizeBuckets,
+		}, []string{"method", "route", "code"}),
+		httpResponseSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "response_size_bytes",
+			Help:      "Size of HTTP responses written by the BFF.",
+			Buckets:   sizeBuckets,
+		}, []string{"method", "route", "code"}),
+		httpInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_in_flight",
+			Help:      "Number of HTTP requests currently being served by the BFF.",
+		}),
+		httpThrottled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_throttled_total",
+			Help:      "HTTP requests rejected with 429 by the BFF rate limits, by limit (\"user\", \"ip\" or \"cost\").",
+		}, []string{"limit"}),
+		httpQueued: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_queued",
+			Help:      "Number of HTTP requests waiting for the BFF concurrency limits.",
+		}),
+		httpShed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_shed_total",
+			Help:      "HTTP requests rejected with 503 by the BFF concurrency limits, by limit (\"global\" or \"user\") and reason (\"queue_full\" or \"queue_timeout\").",
+		}, []string{"limit", "reason"}),
+		httpPanics: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "panics_total",
+			Help:      "Panics recovered while serving HTTP requests, answered with 500, by route.",
+		}, []string{"route"}),
+		kubernetesRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "kubernetes",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of Kubernetes API server requests made by the BFF.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"verb", "resource", "code"}),
+		kubernetesRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "kubernetes",
+			Name:      "requests_total",
+			Help:      "Kubernetes API server requests made by the BFF, by status code (\"error\" for transport failures).",
+		}, []string{"verb", "resource", "code"}),
+		kubernetesReviewCache: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "kubernetes",
+			Name:      "review_cache_lookups_total",
+			Help:      "Lookups of the authentication and access review cache, by review (\"subject\" or \"access\") and result (\"hit\" or \"miss\").",
+		}, []string{"review", "result"}),
+		kubernetesIdentityLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "kubernetes",
+			Name:      "identity_lookups_total",
+			Help:      "Identity lookups such as GetUser, by lookup and result (\"hit\" from the cache, \"miss\", or \"shared\" with a concurrent miss).",
+		}, []string{"lookup", "result"}),
+		kubernetesRateLimiter: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "kubernetes",
+			Name:      "client_rate_limiter_duration_seconds",
+			Help:      "Time Kubernetes API server requests waited for the client-side rate limiter (kube-api-qps and kube-api-burst).",
+			Buckets:   []float64{0.001, 0.005, 0.025, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		}, []string{"verb", "resource"}),
+		upstreamCircuitBreaker: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "upstream",
+			Name:      "circuit_breaker_state",
+			Help:      "State of the circuit breaker of an upstream: 0 closed, 1 half-open, 2 open.",
+		}, []string{"upstream"}),
+		upstreamRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "upstream",
+			Name:      "retries_total",
+			Help:      "Retries of Kubernetes and upstream HTTP calls after a transient error.",
+		}, []string{"upstream"}),
+		webhookDeliveries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "webhooks",
+			Name:      "events_total",
+			Help:      "Events dispatched to the webhook endpoints, by endpoint and outcome (\"delivered\", \"failed\" after the retries, or \"dropped\" when the queue is full or on shutdown).",
+		}, []string{"endpoint", "outcome"}),
+		jobRunDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "jobs",
+			Name:      "run_duration_seconds",
+			Help:      "Duration of the runs of background jobs, by job and result (\"success\" or \"failure\").",
+			Buckets:   []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 300},
+		}, []string{"job", "result"}),
+		jobRuns: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "jobs",
+			Name:      "runs_total",
+			Help:      "Runs of background jobs, by job and result (\"success\", \"failure\", or \"skipped\" while the previous run was still going).",
+		}, []string{"job", "result"}),
+		healthCheckUp: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "health",
+			Name:      "check_up",
+			Help:      "Result of the last background probe of a health check: 1 passing, 0 failing.",
+		}, []string{"check"}),
+		leaderLeading: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "leader_election",
+			Name:      "leading",
+			Help:      "Whether this replica leads, and runs the leader-only background jobs: 1 leading, 0 not.",
+		}),
+		warmupSteps: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "cache_warmup",
+			Name:      "steps",
+			Help:      "Steps of the cache warmup, by state (\"pending\", \"succeeded\" or \"failed\").",
+		}, []string{"state"}),
+		warmupDuration: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "cache_warmup",
+			Name:      "duration_seconds",
+			Help:      "Duration of the cache warmup, set once it is over.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequestDuration,
+		m.httpRequestSize,
+		m.httpResponseSize,
+		m.httpInFlight,
+		m.httpThrottled,
+		m.httpQueued,
+		m.httpShed,
+		m.httpPanics,
+		m.kubernetesRequestDuration,
+		m.kubernetesRequests,
+		m.kubernetesReviewCache,
+		m.kubernetesIdentityLookups,
+		m.kubernetesRateLimiter,
+		m.upstreamCircuitBreaker,
+		m.upstreamRetries,
+		m.webhookDeliveries,
+		m.jobRunDuration,
+		m.jobRuns,
+		m.healthCheckUp,
+		m.leaderLeading,
+		m.warmupSteps,
+		m.warmupDuration,
+	)
+
+	return m
+}
+
+// Registry returns the registry the BFF collectors are registered on, so downstream code
+// can register its own collectors and have them served on /metrics.
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.InstrumentMetricHandler(m.registry, promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+}
@@ -0,0 +1,186 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/jobs"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/resilience"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	clientmetrics "k8s.io/client-go/tools/metrics"
+)
+
+func TestKubernetesRequestInfo(t *testing.T) {
+	tests := []struct {
+		method, url    string
+		verb, resource string
+	}{
+		{http.MethodGet, "/api/v1/namespaces", "list", "namespaces"},
+		{http.MethodGet, "/api/v1/namespaces/kubeflow", "get", "namespaces"},
+		{http.MethodGet, "/api/v1/namespaces/kubeflow/services", "list", "services"},
+		{http.MethodGet, "/api/v1/namespaces/kubeflow/services?watch=true", "watch", "services"},
+		{http.MethodGet, "/api/v1/namespaces/kubeflow/pods/p1/log", "get", "pods/log"},
+		{http.MethodPost, "/apis/authorization.k8s.io/v1/selfsubjectaccessreviews", "create", "selfsubjectaccessreviews"},
+		{http.MethodPut, "/apis/apps/v1/namespaces/ns/deployments/d", "update", "deployments"},
+		{http.MethodPatch, "/apis/apps/v1/namespaces/ns/deployments/d/scale", "patch", "deployments/scale"},
+		{http.MethodDelete, "/api/v1/namespaces/ns/configmaps/c", "delete", "configmaps"},
+		{http.MethodDelete, "/api/v1/namespaces/ns/configmaps", "deletecollection", "configmaps"},
+		{http.MethodGet, "/version", "get", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.url, func(t *testing.T) {
+			verb, resource := KubernetesRequestInfo(httptest.NewRequest(tt.method, tt.url, nil))
+			assert.Equal(t, tt.verb, verb)
+			assert.Equal(t, tt.resource, resource)
+		})
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestWrapKubernetesTransport(t *testing.T) {
+	m := New()
+	rt := m.WrapKubernetesTransport(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusForbidden, Body: http.NoBody}, nil
+	}))
+
+	_, err := rt.RoundTrip(httptest.NewRequest(http.MethodGet, "https://api:6443/api/v1/namespaces", nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.kubernetesRequests.WithLabelValues("list", "namespaces", "403")))
+}
+
+func TestMiddlewareAndHandler(t *testing.T) {
+	m := New()
+	handler := m.Middleware(func(r *http.Request) string {
+		if r.URL.Path == "/api/v1/user" {
+			return "/api/v1/user"
+		}
+		return ""
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = io.WriteString(w, "hello")
+	}))
+
+	for _, path := range []string{"/api/v1/user", "/static/app.js"} {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusTeapot, rr.Code)
+	}
+
+	rr := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rr.Body.String()
+	assert.Contains(t, body, `bff_http_request_duration_seconds_count{code="418",method="get",route="/api/v1/user"} 1`)
+	assert.Contains(t, body, `bff_http_request_duration_seconds_count{code="418",method="get",route="other"} 1`)
+	assert.Contains(t, body, "bff_http_requests_in_flight 0")
+	assert.False(t, strings.Contains(body, "/static/app.js"))
+}
+
+func TestRecordThrottled(t *testing.T) {
+	m := New()
+	m.RecordThrottled("user")
+	m.RecordThrottled("user")
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.httpThrottled.WithLabelValues("user")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.httpThrottled.WithLabelValues("ip")))
+}
+
+func TestRecordShed(t *testing.T) {
+	m := New()
+	m.RecordQueueDepth(3)
+	m.RecordShed("global", "queue_full")
+	assert.Equal(t, 3.0, testutil.ToFloat64(m.httpQueued))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.httpShed.WithLabelValues("global", "queue_full")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.httpShed.WithLabelValues("user", "queue_full")))
+}
+
+func TestRecordPanic(t *testing.T) {
+	m := New()
+	m.RecordPanic("/api/v1/user")
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.httpPanics.WithLabelValues("/api/v1/user")))
+	assert.Equal(t, UnmatchedRoute, RouteFromContext(context.Background()))
+}
+
+func TestRecordReviewCacheLookup(t *testing.T) {
+	m := New()
+	m.RecordReviewCacheLookup("access", true)
+	m.RecordReviewCacheLookup("access", false)
+	m.RecordReviewCacheLookup("access", true)
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.kubernetesReviewCache.WithLabelValues("access", "hit")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.kubernetesReviewCache.WithLabelValues("access", "miss")))
+}
+
+func TestRecordIdentityLookup(t *testing.T) {
+	m := New()
+	m.RecordIdentityLookup("user", "miss")
+	m.RecordIdentityLookup("user", "shared")
+	m.RecordIdentityLookup("user", "shared")
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.kubernetesIdentityLookups.WithLabelValues("user", "shared")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.kubernetesIdentityLookups.WithLabelValues("user", "miss")))
+}
+
+func TestRecordKubernetesRateLimiter(t *testing.T) {
+	m := New()
+	m.RecordKubernetesRateLimiter()
+	u, _ := url.Parse("https://api.example.com/api/v1/namespaces/ns/pods?limit=10")
+	clientmetrics.RateLimiterLatency.Observe(context.Background(), "GET", *u, 200*time.Millisecond)
+	assert.Equal(t, 1, testutil.CollectAndCount(m.kubernetesRateLimiter))
+
+	var sample dto.Metric
+	require.NoError(t, m.kubernetesRateLimiter.WithLabelValues("list", "pods").(prometheus.Histogram).Write(&sample))
+	assert.Equal(t, uint64(1), sample.GetHistogram().GetSampleCount())
+	assert.InDelta(t, 0.2, sample.GetHistogram().GetSampleSum(), 0.001)
+}
+
+func TestRecordUpstreamResilience(t *testing.T) {
+	m := New()
+	policy := resilience.New(resilience.Config{FailureThreshold: 1})
+	policy.Observe(m.RecordCircuitBreakerState, m.RecordUpstreamRetry)
+
+	breaker := policy.Breaker("kubernetes")
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.upstreamCircuitBreaker.WithLabelValues("kubernetes")))
+	require.NoError(t, breaker.Allow())
+	breaker.Record(resilience.Failed)
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.upstreamCircuitBreaker.WithLabelValues("kubernetes")))
+
+	policy.RecordRetry("kubernetes")
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.upstreamRetries.WithLabelValues("kubernetes")))
+
+	m.RecordWebhookDelivery("ci", "delivered")
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.webhookDeliveries.WithLabelValues("ci", "delivered")))
+}
+
+func TestRecordJobRun(t *testing.T) {
+	m := New()
+	m.RecordJobRun("review-cache-prune", jobs.ResultSuccess, 250*time.Millisecond)
+	m.RecordJobRun("review-cache-prune", jobs.ResultSkipped, 0)
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.jobRuns.WithLabelValues("review-cache-prune", jobs.ResultSuccess)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.jobRuns.WithLabelValues("review-cache-prune", jobs.ResultSkipped)))
+
+	var sample dto.Metric
+	require.NoError(t, m.jobRunDuration.WithLabelValues("review-cache-prune", jobs.ResultSuccess).(prometheus.Histogram).Write(&sample))
+	assert.InDelta(t, 0.25, sample.GetHistogram().GetSampleSum(), 0.001)
+
+	m.RecordHealthCheck("kubernetes", false)
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.healthCheckUp.WithLabelValues("kubernetes")))
+	m.RecordHealthCheck("kubernetes", true)
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.healthCheckUp.WithLabelValues("kubernetes")))
+
+	m.RecordLeader(true)
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.leaderLeading))
+	m.RecordLeader(false)
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.leaderLeading))
+}
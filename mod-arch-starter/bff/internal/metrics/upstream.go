@@ -0,0 +1,20 @@
+package metrics
+
+import "github.com/opendatahub-io/mod-arch-library/bff/internal/resilience"
+
+// RecordCircuitBreakerState records the state of the circuit breaker of upstream. Its
+// signature matches the state hook of resilience.Policy.Observe.
+func (m *Metrics) RecordCircuitBreakerState(upstream string, state resilience.State) {
+	m.upstreamCircuitBreaker.WithLabelValues(upstream).Set(float64(state))
+}
+
+// RecordUpstreamRetry counts a retry of a call to upstream.
+func (m *Metrics) RecordUpstreamRetry(upstream string) {
+	m.upstreamRetries.WithLabelValues(upstream).Inc()
+}
+
+// RecordWebhookDelivery counts an event of a webhook endpoint by outcome. Its signature
+// matches webhooks.Options.OnDelivery.
+func (m *Metrics) RecordWebhookDelivery(endpoint, outcome string) {
+	m.webhookDeliveries.WithLabelValues(endpoint, outcome).Inc()
+}
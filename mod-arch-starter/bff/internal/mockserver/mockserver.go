@@ -0,0 +1,310 @@
+// Package mockserver serves canned API responses, read from fixture files, in place of the
+// handlers of the BFF routes, so contract and end-to-end suites (e.g. Cypress) run without a
+// cluster or upstream services. A fixture answers a route pattern, optionally only for some
+// path or query parameters, and can delay its response or fail a fraction of the requests:
+//
+//	# fixtures/secrets.yaml
+//	- method: GET
+//	  route: /api/v1/secrets/:name
+//	  params: {name: db}
+//	  latency: 300ms
+//	  body: {data: {name: db, data: {user: "••••••"}}}
+//	- method: POST
+//	  route: /api/v1/secrets
+//	  status: 201
+//	  errorRate: 0.1
+//	  errorStatus: 503
+//	  body: {data: {name: db}}
+//
+// Requests can also inject latency or an error themselves with the LatencyHeader and
+// StatusHeader headers.
+package mockserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"sigs.k8s.io/yaml"
+)
+
+// Headers of the requests controlling their response, e.g. from a Cypress test.
+const (
+	// LatencyHeader delays the response by a Go duration, e.g. "2s", instead of the latency of
+	// the fixture.
+	LatencyHeader = "X-Mock-Latency"
+	// StatusHeader answers with an error of this status instead of the fixture.
+	StatusHeader = "X-Mock-Status"
+)
+
+// Duration is a time.Duration written as a Go duration string in fixtures, e.g. "250ms".
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("durations are strings like \"250ms\": %w", err)
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Fixture is the canned response of a route.
+type Fixture struct {
+	Method string `json:"method"`
+	// Route is the pattern of the route as registered, e.g. "/api/v1/secrets/:name".
+	Route string `json:"route"`
+	// Params and Query restrict the fixture to the requests with these path and query
+	// parameters. The first matching fixture of a route answers.
+	Params map[string]string `json:"params,omitempty"`
+	Query  map[string]string `json:"query,omitempty"`
+	// Status defaults to 200.
+	Status  int               `json:"status,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	// Body is written as JSON, or as is when it is a string and Headers set a Content-Type
+	// other than JSON.
+	Body    json.RawMessage `json:"body,omitempty"`
+	Latency Duration        `json:"latency,omitempty"`
+	// ErrorRate is the fraction of the requests answered with an error of ErrorStatus (default
+	// 500) instead, overriding Options.ErrorRate.
+	ErrorRate   float64 `json:"errorRate,omitempty"`
+	ErrorStatus int     `json:"errorStatus,omitempty"`
+
+	// file is the fixture file, for the logs.
+	file string
+}
+
+func (f Fixture) matches(r *http.Request, params httprouter.Params) bool {
+	for name, value := range f.Params {
+		if params.ByName(name) != value {
+			return false
+		}
+	}
+	query := r.URL.Query()
+	for name, value := range f.Query {
+		if query.Get(name) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// ErrorFunc writes an error response, in the error envelope of the API.
+type ErrorFunc func(w http.ResponseWriter, r *http.Request, status int, message string)
+
+// Options configures Load.
+type Options struct {
+	// Latency is added to every response.
+	Latency time.Duration
+	// ErrorRate is the fraction of the requests answered with a 500, from 0 to 1.
+	ErrorRate float64
+	// Error writes the injected errors and the 501 of the routes without fixtures; a plain
+	// text response by default.
+	Error  ErrorFunc
+	Logger *slog.Logger
+}
+
+// Server serves the fixtures of the routes.
+type Server struct {
+	opts Options
+	// fixtures are the fixtures by "METHOD route", in the order of their files.
+	fixtures map[string][]Fixture
+}
+
+// Load reads the fixtures of dir: the *.json, *.yaml and *.yml files, each holding a fixture
+// or a list of them, read in the order of their names.
+func Load(dir string, opts Options) (*Server, error) {
+	if opts.Error == nil {
+		opts.Error = func(w http.ResponseWriter, _ *http.Request, status int, message string) {
+			http.Error(w, message, status)
+		}
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the mock fixtures: %w", err)
+	}
+	s := &Server{opts: opts, fixtures: map[string][]Fixture{}}
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || ext != ".json" && ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		fixtures, err := readFixtures(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("mock fixtures %s: %w", entry.Name(), err)
+		}
+		for i, fixture := range fixtures {
+			if err := fixture.validate(); err != nil {
+				return nil, fmt.Errorf("mock fixtures %s, fixture %d: %w", entry.Name(), i, err)
+			}
+			fixture.Method = strings.ToUpper(fixture.Method)
+			fixture.file = entry.Name()
+			key := RouteKey(fixture.Method, fixture.Route)
+			s.fixtures[key] = append(s.fixtures[key], fixture)
+		}
+	}
+	if len(s.fixtures) == 0 {
+		return nil, fmt.Errorf("no mock fixtures in %s", dir)
+	}
+	return s, nil
+}
+
+func readFixtures(path string) ([]Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = yaml.YAMLToJSON(data); err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] != '[' {
+		data = append(append([]byte{'['}, data...), ']')
+	}
+	var fixtures []Fixture
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&fixtures); err != nil {
+		return nil, err
+	}
+	return fixtures, nil
+}
+
+func (f Fixture) validate() error {
+	switch {
+	case f.Method == "":
+		return errors.New("method is required")
+	case !strings.HasPrefix(f.Route, "/"):
+		return fmt.Errorf("route %q must be a route pattern, like /api/v1/namespaces", f.Route)
+	case f.Status != 0 && (f.Status < 100 || f.Status > 599):
+		return fmt.Errorf("status %d is not an HTTP status", f.Status)
+	case f.ErrorStatus != 0 && (f.ErrorStatus < 400 || f.ErrorStatus > 599):
+		return fmt.Errorf("errorStatus %d is not an HTTP error status", f.ErrorStatus)
+	case f.ErrorRate < 0 || f.ErrorRate > 1:
+		return fmt.Errorf("errorRate must be between 0 and 1, got %v", f.ErrorRate)
+	case f.Latency < 0:
+		return errors.New("latency must not be negative")
+	}
+	return nil
+}
+
+// Handle returns the handler serving the fixtures of the route; requests matching none of
+// them are answered with a 501.
+func (s *Server) Handle(method, route string) httprouter.Handle {
+	fixtures := s.fixtures[RouteKey(method, route)]
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		for _, fixture := range fixtures {
+			if fixture.matches(r, params) {
+				s.serve(w, r, fixture)
+				return
+			}
+		}
+		s.opts.Error(w, r, http.StatusNotImplemented, fmt.Sprintf("no mock fixture matches %s %s", method, r.URL.RequestURI()))
+	}
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request, fixture Fixture) {
+	latency := s.opts.Latency + time.Duration(fixture.Latency)
+	if value := r.Header.Get(LatencyHeader); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 {
+			s.opts.Error(w, r, http.StatusBadRequest, fmt.Sprintf("%s must be a duration, like 2s", LatencyHeader))
+			return
+		}
+		latency = parsed
+	}
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	errorRate, errorStatus := s.opts.ErrorRate, http.StatusInternalServerError
+	if fixture.ErrorRate > 0 {
+		errorRate = fixture.ErrorRate
+	}
+	if fixture.ErrorStatus != 0 {
+		errorStatus = fixture.ErrorStatus
+	}
+	if value := r.Header.Get(StatusHeader); value != "" {
+		status, err := strconv.Atoi(value)
+		if err != nil || status < 400 || status > 599 {
+			s.opts.Error(w, r, http.StatusBadRequest, fmt.Sprintf("%s must be an HTTP error status, like 503", StatusHeader))
+			return
+		}
+		errorRate, errorStatus = 1, status
+	}
+	if errorRate > 0 && rand.Float64() < errorRate {
+		s.opts.Error(w, r, errorStatus, fmt.Sprintf("mock error injected for %s %s", fixture.Method, fixture.Route))
+		return
+	}
+
+	for name, value := range fixture.Headers {
+		w.Header().Set(name, value)
+	}
+	body := []byte(fixture.Body)
+	switch contentType := w.Header().Get("Content-Type"); {
+	case contentType == "" && len(body) > 0:
+		w.Header().Set("Content-Type", "application/json")
+	case contentType != "" && !strings.Contains(contentType, "json"):
+		var text string
+		if json.Unmarshal(body, &text) == nil {
+			body = []byte(text)
+		}
+	}
+	status := fixture.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	if _, err := w.Write(body); err != nil {
+		s.opts.Logger.Debug("failed to write a mock response", "route", fixture.Route, "file", fixture.file, "error", err)
+	}
+}
+
+// RouteKey is the "METHOD route" of a route, as returned by Unmatched.
+func RouteKey(method, route string) string {
+	return method + " " + route
+}
+
+// Unmatched returns, sorted, the "METHOD route" of routes without fixtures and those of the
+// fixtures of unknown routes, e.g. of a renamed route.
+func (s *Server) Unmatched(routes []string) (missing, unknown []string) {
+	registered := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		registered[route] = true
+		if _, ok := s.fixtures[route]; !ok {
+			missing = append(missing, route)
+		}
+	}
+	for route := range s.fixtures {
+		if !registered[route] {
+			unknown = append(unknown, route)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(unknown)
+	return missing, unknown
+}
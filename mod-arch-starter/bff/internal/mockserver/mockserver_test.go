@@ -0,0 +1,126 @@
+package mockserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFixtures(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+}
+
+func TestServer(t *testing.T) {
+	dir := t.TempDir()
+	writeFixtures(t, dir, "secrets.yaml", `
+- method: GET
+  route: /api/v1/secrets/:name
+  params: {name: db}
+  query: {namespace: dora-namespace}
+  body: {data: {name: db}}
+- method: get
+  route: /api/v1/secrets/:name
+  status: 404
+  body: {error: {code: "404", message: not found}}
+- method: POST
+  route: /api/v1/secrets
+  status: 201
+  errorRate: 1
+  errorStatus: 503
+`)
+	writeFixtures(t, dir, "logs.json", `{"method": "GET", "route": "/api/v1/pods/:pod/logs", "headers": {"Content-Type": "text/plain"}, "body": "line 1\n", "latency": "20ms"}`)
+	writeFixtures(t, dir, "README.md", "not fixtures")
+	server, err := Load(dir, Options{})
+	require.NoError(t, err)
+
+	router := httprouter.New()
+	for _, route := range [][2]string{
+		{http.MethodGet, "/api/v1/secrets/:name"},
+		{http.MethodPost, "/api/v1/secrets"},
+		{http.MethodGet, "/api/v1/pods/:pod/logs"},
+		{http.MethodGet, "/api/v1/namespaces"},
+	} {
+		router.Handle(route[0], route[1], server.Handle(route[0], route[1]))
+	}
+	serve := func(method, target string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve(http.MethodGet, "/api/v1/secrets/db?namespace=dora-namespace", nil)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"data": {"name": "db"}}`, rr.Body.String())
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/api/v1/secrets/db?namespace=bella-namespace", nil).Code, "first matching fixture")
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/api/v1/secrets/other", nil).Code)
+	assert.Equal(t, http.StatusServiceUnavailable, serve(http.MethodPost, "/api/v1/secrets", nil).Code, "injected error")
+	assert.Equal(t, http.StatusNotImplemented, serve(http.MethodGet, "/api/v1/namespaces", nil).Code, "no fixture")
+
+	start := time.Now()
+	rr = serve(http.MethodGet, "/api/v1/pods/p/logs", nil)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	assert.Equal(t, "line 1\n", rr.Body.String(), "text body")
+
+	start = time.Now()
+	rr = serve(http.MethodGet, "/api/v1/secrets/db", map[string]string{LatencyHeader: "0s", StatusHeader: "502"})
+	assert.Less(t, time.Since(start), 20*time.Millisecond)
+	assert.Equal(t, http.StatusBadGateway, rr.Code)
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodGet, "/api/v1/secrets/db", map[string]string{StatusHeader: "200"}).Code)
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodGet, "/api/v1/secrets/db", map[string]string{LatencyHeader: "soon"}).Code)
+
+	missing, unknown := server.Unmatched([]string{
+		RouteKey(http.MethodGet, "/api/v1/secrets/:name"),
+		RouteKey(http.MethodGet, "/api/v1/pods/:pod/logs"),
+		RouteKey(http.MethodGet, "/api/v1/namespaces"),
+	})
+	assert.Equal(t, []string{"GET /api/v1/namespaces"}, missing)
+	assert.Equal(t, []string{"POST /api/v1/secrets"}, unknown)
+}
+
+func TestServer_ErrorRate(t *testing.T) {
+	dir := t.TempDir()
+	writeFixtures(t, dir, "user.json", `{"method": "GET", "route": "/api/v1/user", "body": {}}`)
+	var status int
+	server, err := Load(dir, Options{ErrorRate: 1, Error: func(w http.ResponseWriter, _ *http.Request, code int, _ string) {
+		status = code
+		w.WriteHeader(code)
+	}})
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	server.Handle(http.MethodGet, "/api/v1/user")(rr, httptest.NewRequest(http.MethodGet, "/api/v1/user", nil), nil)
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.Equal(t, http.StatusInternalServerError, status, "written by Options.Error")
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"no method":   `{"route": "/api/v1/user"}`,
+		"bad route":   `{"method": "GET", "route": "api/v1/user"}`,
+		"bad status":  `{"method": "GET", "route": "/api/v1/user", "status": 1000}`,
+		"bad rate":    `{"method": "GET", "route": "/api/v1/user", "errorRate": 2}`,
+		"bad latency": `{"method": "GET", "route": "/api/v1/user", "latency": 300}`,
+		"unknown key": `{"method": "GET", "route": "/api/v1/user", "stauts": 200}`,
+	} {
+		dir := t.TempDir()
+		writeFixtures(t, dir, "fixture.json", content)
+		_, err := Load(dir, Options{})
+		assert.ErrorContains(t, err, "fixture", name)
+	}
+
+	_, err := Load(t.TempDir(), Options{})
+	assert.ErrorContains(t, err, "no mock fixtures")
+}
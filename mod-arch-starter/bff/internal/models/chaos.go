@@ -0,0 +1,14 @@
+package models
+
+// ChaosRule is a fault injected into the requests of a route, or the calls to an upstream.
+type ChaosRule struct {
+	// Route is a route pattern, a prefix of patterns ending with "*", or "*" for every route.
+	Route string `json:"route,omitempty"`
+	// Upstream is "kubernetes", "kubernetes/<cluster>", the host of an upstream service or "*".
+	Upstream string `json:"upstream,omitempty"`
+	// Latency is a Go duration, e.g. "1.5s".
+	Latency     string  `json:"latency,omitempty"`
+	ErrorRate   float64 `json:"errorRate,omitempty" validate:"min=0,max=1"`
+	ErrorStatus int     `json:"errorStatus,omitempty" validate:"min=400,max=599"`
+	DropRate    float64 `json:"dropRate,omitempty" validate:"min=0,max=1"`
+}
@@ -0,0 +1,10 @@
+package models
+
+// ClusterModel is a cluster the API requests can target with the cluster query parameter.
+type ClusterModel struct {
+	Name string `json:"name"`
+	// Local is the cluster of requests without a cluster parameter.
+	Local bool `json:"local"`
+	// Reachable is false when the API server of the cluster didn't answer.
+	Reachable bool `json:"reachable"`
+}
@@ -0,0 +1,67 @@
+package models
+
+// Cluster health statuses, of the whole document and of its components and operators.
+const (
+	// HealthStatusHealthy means everything checked is ready.
+	HealthStatusHealthy = "healthy"
+	// HealthStatusDegraded means something checked is not ready or could not be checked.
+	HealthStatusDegraded = "degraded"
+	// HealthStatusUnavailable means nothing of a component is ready, an operator reports it is
+	// not ready, or, for the whole document, that the API server is unreachable.
+	HealthStatusUnavailable = "unavailable"
+	// HealthStatusUnknown means a component or operator could not be read (e.g. not allowed).
+	HealthStatusUnknown = "unknown"
+)
+
+// ClusterHealthModel summarizes the health of the cluster for the system status banner of the UI.
+type ClusterHealthModel struct {
+	Status     string            `json:"status"`
+	APIServer  APIServerHealth   `json:"apiServer"`
+	Components []ComponentHealth `json:"components"`
+	Operators  []OperatorHealth  `json:"operators"`
+}
+
+// APIServerHealth reports whether the API server answered. The document holds no timings, so
+// polling it with If-None-Match is answered 304 while nothing changes.
+type APIServerHealth struct {
+	Reachable bool `json:"reachable"`
+}
+
+// ComponentHealth summarizes the Deployments and Pods of a component; only cluster admins see
+// the Deployments.
+type ComponentHealth struct {
+	Name        string             `json:"name"`
+	Namespace   string             `json:"namespace"`
+	Status      string             `json:"status"`
+	Message     string             `json:"message,omitempty"`
+	Deployments []DeploymentHealth `json:"deployments,omitempty" access:"cluster-admin"`
+	ReadyPods   int                `json:"readyPods"`
+	TotalPods   int                `json:"totalPods"`
+}
+
+// DeploymentHealth is the readiness of a Deployment of a component.
+type DeploymentHealth struct {
+	Name          string `json:"name"`
+	Replicas      int32  `json:"replicas"`
+	ReadyReplicas int32  `json:"readyReplicas"`
+	// Available mirrors the Available condition of the Deployment.
+	Available bool `json:"available"`
+}
+
+// OperatorHealth reports the status conditions of an operator resource, e.g. a
+// DataScienceCluster; only cluster admins see the Conditions.
+type OperatorHealth struct {
+	Name       string            `json:"name"`
+	Kind       string            `json:"kind,omitempty"`
+	Status     string            `json:"status"`
+	Message    string            `json:"message,omitempty"`
+	Conditions []HealthCondition `json:"conditions,omitempty" access:"cluster-admin"`
+}
+
+// HealthCondition is a status condition of an operator resource.
+type HealthCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// ConfigMapModel is a ConfigMap of a namespace. Redacted values are empty and their keys are
+// listed in RedactedKeys; an update keeps the current value of the keys still listed there.
+type ConfigMapModel struct {
+	Name         string            `json:"name" validate:"dnssubdomain"`
+	Namespace    string            `json:"namespace,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Data         map[string]string `json:"data"`
+	RedactedKeys []string          `json:"redactedKeys,omitempty"`
+	// ManagedBy is the module that created the ConfigMap through the BFF, if any. Read-only.
+	ManagedBy string `json:"managedBy,omitempty"`
+	// ResourceVersion makes an update fail with a conflict when the ConfigMap changed since it was read.
+	ResourceVersion   string     `json:"resourceVersion,omitempty"`
+	CreationTimestamp *time.Time `json:"creationTimestamp,omitempty"`
+}
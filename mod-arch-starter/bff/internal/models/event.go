@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Event types, as set by Kubernetes.
+const (
+	EventTypeNormal  = "Normal"
+	EventTypeWarning = "Warning"
+)
+
+// EventModel is a Kubernetes Event about a resource, e.g. to show why a pod is not starting.
+type EventModel struct {
+	Name string `json:"name"`
+	// Type is EventTypeNormal or EventTypeWarning.
+	Type    string `json:"type"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+	// Count is how many times the event occurred between FirstTimestamp and LastTimestamp.
+	Count          int32      `json:"count"`
+	FirstTimestamp *time.Time `json:"firstTimestamp,omitempty"`
+	LastTimestamp  *time.Time `json:"lastTimestamp,omitempty"`
+	// Source is the component that reported the event, e.g. kubelet.
+	Source         string           `json:"source,omitempty"`
+	InvolvedObject EventObjectModel `json:"involvedObject"`
+}
+
+// EventObjectModel is the object an event is about.
+type EventObjectModel struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// FieldPath is the part of the object, e.g. spec.containers{server}.
+	FieldPath string `json:"fieldPath,omitempty"`
+}
@@ -0,0 +1,32 @@
+package models
+
+// FrontendConfig is the runtime configuration of the frontend, published by the BFF so it
+// isn't baked into the frontend build. Every value is public.
+type FrontendConfig struct {
+	DeploymentMode string `json:"deploymentMode"`
+	AuthMethod     string `json:"authMethod"`
+	// AuthTokenHeader is the header the frontend sends the token in (user_token auth method).
+	AuthTokenHeader string `json:"authTokenHeader,omitempty"`
+	DevMode         bool   `json:"devMode"`
+	// KubeflowMode tells the frontend it is embedded in the Kubeflow Central Dashboard, whose
+	// namespace selector it follows.
+	KubeflowMode bool `json:"kubeflowMode,omitempty"`
+	// GuestMode tells the frontend the requests without credentials are served as a read-only
+	// guest, so it can offer to sign in instead of requiring it.
+	GuestMode bool `json:"guestMode,omitempty"`
+	// CSRF names the cookie and header of the CSRF token, when the protection is enabled.
+	CSRF *CSRFConfig `json:"csrf,omitempty"`
+	// Features are the feature flags by name, with their value for anonymous callers: targeted
+	// values are served by /api/v1/features.
+	Features map[string]bool `json:"features"`
+	// Services are the URLs of the upstream services the frontend calls directly, by name.
+	Services map[string]string `json:"services"`
+	// Extensions holds the values registered by the module, by name.
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// CSRFConfig names the CSRF token cookie and the header it is echoed in.
+type CSRFConfig struct {
+	CookieName string `json:"cookieName"`
+	HeaderName string `json:"headerName"`
+}
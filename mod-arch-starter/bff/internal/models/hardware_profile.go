@@ -0,0 +1,64 @@
+package models
+
+import corev1 "k8s.io/api/core/v1"
+
+// Sources of the hardware profiles.
+const (
+	HardwareProfileSourceHardwareProfile    = "HardwareProfile"
+	HardwareProfileSourceAcceleratorProfile = "AcceleratorProfile"
+)
+
+// Types of the resources of a hardware profile, as in the HardwareProfile CRD.
+const (
+	HardwareResourceCPU         = "CPU"
+	HardwareResourceMemory      = "Memory"
+	HardwareResourceAccelerator = "Accelerator"
+)
+
+// HardwareProfilesModel is what the resource pickers of the UI offer: the hardware profiles of
+// the platform, and the accelerators of the nodes.
+type HardwareProfilesModel struct {
+	Profiles []HardwareProfile `json:"profiles"`
+	// Accelerators is null when the user may not list nodes.
+	Accelerators []AcceleratorCapacity `json:"accelerators"`
+}
+
+// HardwareProfile is a HardwareProfile or an AcceleratorProfile, normalized: an
+// AcceleratorProfile is a profile of one Accelerator resource.
+type HardwareProfile struct {
+	Name        string             `json:"name"`
+	DisplayName string             `json:"displayName"`
+	Description string             `json:"description,omitempty"`
+	Source      string             `json:"source"`
+	Enabled     bool               `json:"enabled"`
+	Resources   []HardwareResource `json:"resources"`
+	// NodeSelector, Tolerations and LocalQueueName are set on the workloads using the profile.
+	NodeSelector   map[string]string   `json:"nodeSelector,omitempty"`
+	Tolerations    []corev1.Toleration `json:"tolerations,omitempty"`
+	LocalQueueName string              `json:"localQueueName,omitempty"`
+}
+
+// HardwareResource is a resource of a hardware profile with the amounts a workload can
+// request, as quantities (e.g. "2" or "8Gi"). MaxCount is empty when unbounded.
+type HardwareResource struct {
+	Identifier   string `json:"identifier"`
+	DisplayName  string `json:"displayName"`
+	ResourceType string `json:"resourceType"`
+	MinCount     string `json:"minCount"`
+	DefaultCount string `json:"defaultCount"`
+	MaxCount     string `json:"maxCount,omitempty"`
+}
+
+// AcceleratorCapacity is the capacity of the schedulable nodes with accelerators of a
+// resource and product.
+type AcceleratorCapacity struct {
+	// Identifier is the extended resource of the accelerators, e.g. nvidia.com/gpu.
+	Identifier string `json:"identifier"`
+	// Product is the model of the accelerators, from the node labels of their operator, when known.
+	Product string `json:"product,omitempty"`
+	Nodes   int    `json:"nodes"`
+	// Allocatable is the count allocatable on all the nodes; MaxPerNode the most a workload can
+	// request on one node.
+	Allocatable int64 `json:"allocatable"`
+	MaxPerNode  int64 `json:"maxPerNode"`
+}
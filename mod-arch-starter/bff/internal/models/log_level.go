@@ -0,0 +1,14 @@
+package models
+
+// LogLevels is the log level of the BFF and its per-package overrides, e.g. "proxy": "DEBUG".
+type LogLevels struct {
+	Level    string            `json:"level"`
+	Packages map[string]string `json:"packages"`
+}
+
+// LogLevelsUpdate changes the log levels. An empty level keeps the current one; a package
+// mapped to an empty level loses its override and uses the BFF level again.
+type LogLevelsUpdate struct {
+	Level    string            `json:"level,omitempty"`
+	Packages map[string]string `json:"packages,omitempty"`
+}
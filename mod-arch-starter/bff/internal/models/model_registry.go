@@ -0,0 +1,95 @@
+package models
+
+// Types of the model registry REST API (api/model_registry/v1alpha3), as exchanged by
+// modelregistry.Client. Timestamps are milliseconds since epoch encoded as strings, like the
+// upstream API. On updates, empty fields are left unchanged.
+
+// Model registry entity states.
+const (
+	ModelRegistryStateLive     = "LIVE"
+	ModelRegistryStateArchived = "ARCHIVED"
+)
+
+// Model registry metadata value types.
+const (
+	MetadataTypeString = "MetadataStringValue"
+	MetadataTypeInt    = "MetadataIntValue"
+	MetadataTypeDouble = "MetadataDoubleValue"
+	MetadataTypeBool   = "MetadataBoolValue"
+	MetadataTypeStruct = "MetadataStructValue"
+)
+
+// ModelArtifactType is the artifactType of model artifacts.
+const ModelArtifactType = "model-artifact"
+
+// MetadataValue is a custom property value. MetadataType tells which of the values is set.
+type MetadataValue struct {
+	MetadataType string  `json:"metadataType"`
+	StringValue  *string `json:"string_value,omitempty"`
+	// IntValue is an int64 encoded as a string.
+	IntValue    *string  `json:"int_value,omitempty"`
+	DoubleValue *float64 `json:"double_value,omitempty"`
+	BoolValue   *bool    `json:"bool_value,omitempty"`
+	// StructValue is base64-encoded JSON.
+	StructValue *string `json:"struct_value,omitempty"`
+}
+
+// NewStringMetadataValue returns a string custom property, the type the UI mostly deals with.
+func NewStringMetadataValue(value string) MetadataValue {
+	return MetadataValue{MetadataType: MetadataTypeString, StringValue: &value}
+}
+
+type RegisteredModel struct {
+	ID                       string                   `json:"id,omitempty"`
+	Name                     string                   `json:"name,omitempty"`
+	Description              string                   `json:"description,omitempty"`
+	ExternalID               string                   `json:"externalId,omitempty"`
+	Owner                    string                   `json:"owner,omitempty"`
+	State                    string                   `json:"state,omitempty"`
+	CustomProperties         map[string]MetadataValue `json:"customProperties,omitempty"`
+	CreateTimeSinceEpoch     string                   `json:"createTimeSinceEpoch,omitempty"`
+	LastUpdateTimeSinceEpoch string                   `json:"lastUpdateTimeSinceEpoch,omitempty"`
+}
+
+type ModelVersion struct {
+	ID                       string                   `json:"id,omitempty"`
+	Name                     string                   `json:"name,omitempty"`
+	Description              string                   `json:"description,omitempty"`
+	ExternalID               string                   `json:"externalId,omitempty"`
+	RegisteredModelID        string                   `json:"registeredModelId,omitempty"`
+	Author                   string                   `json:"author,omitempty"`
+	State                    string                   `json:"state,omitempty"`
+	CustomProperties         map[string]MetadataValue `json:"customProperties,omitempty"`
+	CreateTimeSinceEpoch     string                   `json:"createTimeSinceEpoch,omitempty"`
+	LastUpdateTimeSinceEpoch string                   `json:"lastUpdateTimeSinceEpoch,omitempty"`
+}
+
+type ModelArtifact struct {
+	ID                       string                   `json:"id,omitempty"`
+	Name                     string                   `json:"name,omitempty"`
+	Description              string                   `json:"description,omitempty"`
+	ExternalID               string                   `json:"externalId,omitempty"`
+	ArtifactType             string                   `json:"artifactType,omitempty"`
+	URI                      string                   `json:"uri,omitempty"`
+	State                    string                   `json:"state,omitempty"`
+	ModelFormatName          string                   `json:"modelFormatName,omitempty"`
+	ModelFormatVersion       string                   `json:"modelFormatVersion,omitempty"`
+	StorageKey               string                   `json:"storageKey,omitempty"`
+	StoragePath              string                   `json:"storagePath,omitempty"`
+	ServiceAccountName       string                   `json:"serviceAccountName,omitempty"`
+	CustomProperties         map[string]MetadataValue `json:"customProperties,omitempty"`
+	CreateTimeSinceEpoch     string                   `json:"createTimeSinceEpoch,omitempty"`
+	LastUpdateTimeSinceEpoch string                   `json:"lastUpdateTimeSinceEpoch,omitempty"`
+}
+
+// ModelRegistryList is a page of a model registry listing. NextPageToken is empty on the last page.
+type ModelRegistryList[T any] struct {
+	Items         []T    `json:"items"`
+	NextPageToken string `json:"nextPageToken"`
+	PageSize      int32  `json:"pageSize"`
+	Size          int32  `json:"size"`
+}
+
+type RegisteredModelList = ModelRegistryList[RegisteredModel]
+type ModelVersionList = ModelRegistryList[ModelVersion]
+type ModelArtifactList = ModelRegistryList[ModelArtifact]
@@ -3,6 +3,8 @@ package models
 type NamespaceModel struct {
 	Name        string  `json:"name"`
 	DisplayName *string `json:"displayName,omitempty"`
+	// Owner is the owner of the Kubeflow Profile of the namespace, in Kubeflow mode.
+	Owner string `json:"owner,omitempty"`
 }
 
 func NewNamespaceModelFromNamespace(name string) NamespaceModel {
@@ -12,3 +14,31 @@ func NewNamespaceModelFromNamespace(name string) NamespaceModel {
 		DisplayName: &displayName,
 	}
 }
+
+// NamespaceProvisioningRequest asks for a namespace created from a template of the
+// namespace provisioning workflow.
+type NamespaceProvisioningRequest struct {
+	Name        string `json:"name" validate:"required,dnslabel"`
+	DisplayName string `json:"displayName,omitempty" validate:"max=256"`
+	Description string `json:"description,omitempty" validate:"max=1024"`
+	// Template names the template of the namespace and its companion resources (default
+	// "default").
+	Template string `json:"template,omitempty"`
+	// Parameters are free-form values of the template, e.g. its quota.
+	Parameters map[string]string `json:"parameters,omitempty" validate:"max=32"`
+}
+
+// NamespaceProvisioningModel is the result of a namespace provisioning operation.
+type NamespaceProvisioningModel struct {
+	Namespace string `json:"namespace"`
+	Template  string `json:"template"`
+	// Objects are the objects created, the Namespace first.
+	Objects []ProvisionedObjectModel `json:"objects"`
+}
+
+// ProvisionedObjectModel is an object created for a provisioned namespace.
+type ProvisionedObjectModel struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+}
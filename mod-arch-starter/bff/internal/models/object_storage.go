@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// PresignRequest asks for a URL letting the browser download (GET) or upload (PUT) an object
+// directly, without credentials.
+type PresignRequest struct {
+	// Bucket defaults to the bucket of the Secret.
+	Bucket string `json:"bucket,omitempty"`
+	Key    string `json:"key" validate:"required,max=1024"`
+	Method string `json:"method" validate:"required,oneof=GET PUT"`
+	// ExpiresInSeconds is the validity of the URL, by default and at most the configured one.
+	ExpiresInSeconds int `json:"expiresInSeconds,omitempty" validate:"min=1"`
+}
+
+// PresignedURL is a URL to GET or PUT an object until ExpiresAt.
+type PresignedURL struct {
+	URL       string    `json:"url"`
+	Method    string    `json:"method"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
@@ -0,0 +1,21 @@
+package models
+
+// PermissionCheck describes a single RBAC check and its result.
+type PermissionCheck struct {
+	Verb      string `json:"verb"`
+	Group     string `json:"group"`
+	Resource  string `json:"resource"`
+	Namespace string `json:"namespace,omitempty"`
+	Allowed   bool   `json:"allowed"`
+	// Error is set, and Allowed false, when the check of a batch could not be performed.
+	Error string `json:"error,omitempty"`
+}
+
+// PermissionRequest is one check of a batch: may the user perform Verb on Resource (in Group
+// and Namespace)?
+type PermissionRequest struct {
+	Verb      string `json:"verb" validate:"required"`
+	Group     string `json:"group,omitempty"`
+	Resource  string `json:"resource" validate:"required"`
+	Namespace string `json:"namespace,omitempty"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "encoding/json"
+
+// UserPreferences are the UI preferences of a user, stored by the BFF.
+type UserPreferences struct {
+	// Theme is the UI theme, e.g. "light", "dark" or "system".
+	Theme string `json:"theme,omitempty"`
+	// PinnedNamespaces are listed first by namespace selectors.
+	PinnedNamespaces []string `json:"pinnedNamespaces,omitempty"`
+	// Tables are the settings of the tables of the UI, by table ID.
+	Tables map[string]TablePreferences `json:"tables,omitempty"`
+	// Modules are the preferences of downstream modules, by module name, stored as sent.
+	Modules map[string]json.RawMessage `json:"modules,omitempty"`
+	// ResourceVersion makes an update fail with a conflict when the preferences changed since
+	// they were read. Empty when no preferences are stored yet.
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+// TablePreferences are the settings of a table of the UI.
+type TablePreferences struct {
+	// Columns are the visible columns, in order.
+	Columns       []string `json:"columns,omitempty"`
+	SortBy        string   `json:"sortBy,omitempty"`
+	SortDirection string   `json:"sortDirection,omitempty"`
+	PageSize      int      `json:"pageSize,omitempty"`
+}
@@ -0,0 +1,51 @@
+package models
+
+// QuotaModel is the quota of a namespace: the ResourceQuotas with their usage and the
+// LimitRanges, for UIs warning that a quota is about to be reached.
+type QuotaModel struct {
+	Namespace string `json:"namespace"`
+	// Resources is the usage of every resource with a quota, against the quota it uses the most.
+	Resources   []ResourceUsageModel `json:"resources"`
+	Quotas      []ResourceQuotaModel `json:"quotas"`
+	LimitRanges []LimitRangeModel    `json:"limitRanges"`
+}
+
+// ResourceUsageModel is the usage of a resource against its hard limit, as quantities (e.g.
+// "3" of "4", or "6Gi" of "8Gi"). The cpu, memory and ephemeral-storage resources are named
+// by their requests.* equivalents.
+type ResourceUsageModel struct {
+	// Resource is the quota resource, e.g. requests.nvidia.com/gpu or count/pods.
+	Resource string `json:"resource"`
+	Used     string `json:"used"`
+	Hard     string `json:"hard"`
+	// Percent is Used in percent of Hard, rounded to a tenth; 100 when Hard is 0.
+	Percent float64 `json:"percent"`
+	// Quota names the ResourceQuota of the usage in QuotaModel.Resources.
+	Quota string `json:"quota,omitempty"`
+}
+
+// ResourceQuotaModel is a ResourceQuota of the namespace and its usage.
+type ResourceQuotaModel struct {
+	Name string `json:"name"`
+	// Scopes limit the quota to some workloads, e.g. BestEffort or Terminating.
+	Scopes    []string             `json:"scopes,omitempty"`
+	Resources []ResourceUsageModel `json:"resources"`
+}
+
+// LimitRangeModel is a LimitRange of the namespace.
+type LimitRangeModel struct {
+	Name   string       `json:"name"`
+	Limits []LimitModel `json:"limits"`
+}
+
+// LimitModel is the range of a resource of the objects of a type (Container, Pod or
+// PersistentVolumeClaim), and the defaults of the containers, as quantities.
+type LimitModel struct {
+	Type                 string `json:"type"`
+	Resource             string `json:"resource"`
+	Min                  string `json:"min,omitempty"`
+	Max                  string `json:"max,omitempty"`
+	Default              string `json:"default,omitempty"`
+	DefaultRequest       string `json:"defaultRequest,omitempty"`
+	MaxLimitRequestRatio string `json:"maxLimitRequestRatio,omitempty"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// The roles of the namespace access listed and granted through the role-bindings endpoints,
+// bound as the ClusterRoles of the same name.
+const (
+	RoleAdmin = "admin"
+	RoleEdit  = "edit"
+	RoleView  = "view"
+)
+
+// RoleBindingModel is a RoleBinding granting the users and groups of Subjects a role in a
+// namespace, as shown by a "share this project" UI.
+type RoleBindingModel struct {
+	Name string `json:"name"`
+	// Role is admin, edit or view.
+	Role     string                    `json:"role"`
+	Subjects []RoleBindingSubjectModel `json:"subjects"`
+	// ManagedBy is the module that granted the access through the BFF, if any. Read-only.
+	ManagedBy string `json:"managedBy,omitempty"`
+	// CreatedBy is the user who granted the access through the BFF. Read-only.
+	CreatedBy         string     `json:"createdBy,omitempty"`
+	CreationTimestamp *time.Time `json:"creationTimestamp,omitempty"`
+}
+
+// RoleBindingSubjectModel is a user or group bound to a role.
+type RoleBindingSubjectModel struct {
+	// Kind is User or Group.
+	Kind string `json:"kind" validate:"required,oneof=User Group"`
+	Name string `json:"name" validate:"required,max=253"`
+}
+
+// RoleBindingRequest grants a user or group access to a namespace.
+type RoleBindingRequest struct {
+	Role    string                  `json:"role" validate:"required,oneof=edit view"`
+	Subject RoleBindingSubjectModel `json:"subject"`
+}
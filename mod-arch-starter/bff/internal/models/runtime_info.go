@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// RuntimeInfo is a snapshot of the Go runtime of the BFF, served to cluster admins for debugging.
+type RuntimeInfo struct {
+	GoVersion  string        `json:"goVersion"`
+	Goroutines int           `json:"goroutines"`
+	NumCPU     int           `json:"numCPU"`
+	GOMAXPROCS int           `json:"gomaxprocs"`
+	Memory     RuntimeMemory `json:"memory"`
+	GC         RuntimeGC     `json:"gc"`
+	Build      RuntimeBuild  `json:"build"`
+}
+
+// RuntimeMemory is the memory use of the BFF, in bytes.
+type RuntimeMemory struct {
+	HeapAlloc   uint64 `json:"heapAlloc"`
+	HeapInuse   uint64 `json:"heapInuse"`
+	HeapObjects uint64 `json:"heapObjects"`
+	// Sys is the memory obtained from the operating system.
+	Sys uint64 `json:"sys"`
+}
+
+// RuntimeGC is the garbage collector activity since the BFF started; LastRun is omitted
+// before the first collection.
+type RuntimeGC struct {
+	Runs       uint32        `json:"runs"`
+	LastRun    *time.Time    `json:"lastRun,omitempty"`
+	PauseTotal time.Duration `json:"pauseTotalNs"`
+	LastPause  time.Duration `json:"lastPauseNs"`
+}
+
+// RuntimeBuild is the build information embedded in the binary, e.g. the "vcs.revision" setting.
+type RuntimeBuild struct {
+	Path     string            `json:"path,omitempty"`
+	Version  string            `json:"version,omitempty"`
+	Settings map[string]string `json:"settings"`
+}
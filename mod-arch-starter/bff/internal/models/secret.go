@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// SecretModel is a Secret of a namespace. Values are base64-encoded, as in the Kubernetes API.
+// Redacted values are null and their keys are listed in RedactedKeys; an update keeps the
+// current value of the keys still listed there.
+type SecretModel struct {
+	Name         string            `json:"name" validate:"dnssubdomain"`
+	Namespace    string            `json:"namespace,omitempty"`
+	Type         string            `json:"type,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Data         map[string][]byte `json:"data"`
+	RedactedKeys []string          `json:"redactedKeys,omitempty"`
+	// ManagedBy is the module that created the Secret through the BFF, if any. Read-only.
+	ManagedBy string `json:"managedBy,omitempty"`
+	// ResourceVersion makes an update fail with a conflict when the Secret changed since it was read.
+	ResourceVersion   string     `json:"resourceVersion,omitempty"`
+	CreationTimestamp *time.Time `json:"creationTimestamp,omitempty"`
+}
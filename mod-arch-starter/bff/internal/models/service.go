@@ -0,0 +1,53 @@
+package models
+
+// Service health statuses.
+const (
+	// ServiceStatusAvailable means at least one endpoint of the Service is ready.
+	ServiceStatusAvailable = "available"
+	// ServiceStatusUnavailable means the Service has no ready endpoint.
+	ServiceStatusUnavailable = "unavailable"
+	// ServiceStatusUnknown means the endpoints could not be read (e.g. not allowed, ExternalName Services).
+	ServiceStatusUnknown = "unknown"
+)
+
+// ServiceModel is a backend instance discovered from a Kubernetes Service.
+type ServiceModel struct {
+	Name        string `json:"name"`
+	Namespace   string `json:"namespace"`
+	DisplayName string `json:"displayName"`
+	Description string `json:"description,omitempty"`
+	// ExternalAddress is the external route of the Service, when it is exposed outside the cluster.
+	ExternalAddress string `json:"externalAddress,omitempty"`
+	// ExternalURLs are the URLs of the Routes, Ingresses and HTTPRoutes exposing the Service,
+	// most preferred first.
+	ExternalURLs []ServiceURL      `json:"externalURLs,omitempty"`
+	Ports        []ServicePort     `json:"ports"`
+	Health       ServiceHealth     `json:"health"`
+	Labels       map[string]string `json:"labels,omitempty"`
+}
+
+// ServicePort is a port of a discovered Service with its in-cluster URL.
+type ServicePort struct {
+	Name     string `json:"name,omitempty"`
+	Port     int32  `json:"port"`
+	Protocol string `json:"protocol"`
+	URL      string `json:"url"`
+}
+
+// ServiceURL is an external URL of a discovered Service, from the Route, Ingress or HTTPRoute
+// named Name (Source is its kind). Port is the port of the Service it targets, by name or
+// number, when the object sets one.
+type ServiceURL struct {
+	URL    string `json:"url"`
+	Source string `json:"source"`
+	Name   string `json:"name"`
+	TLS    bool   `json:"tls"`
+	Port   string `json:"port,omitempty"`
+}
+
+// ServiceHealth summarizes the EndpointSlices of a Service.
+type ServiceHealth struct {
+	Status         string `json:"status"`
+	ReadyEndpoints int    `json:"readyEndpoints"`
+	TotalEndpoints int    `json:"totalEndpoints"`
+}
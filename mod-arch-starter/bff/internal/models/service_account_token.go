@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// ServiceAccountTokenRequest asks for a token of a ServiceAccount.
+type ServiceAccountTokenRequest struct {
+	// Audiences scope the token, e.g. to a model registry. At least one is required.
+	Audiences []string `json:"audiences,omitempty" validate:"min=1,max=10"`
+	// ExpirationSeconds is the validity of the token, at least 10 minutes; 0 means the
+	// longest allowed.
+	ExpirationSeconds int64 `json:"expirationSeconds,omitempty" validate:"min=600"`
+}
+
+// ServiceAccountTokenModel is a token minted for a ServiceAccount. The token is only
+// returned once, and never stored or logged by the BFF.
+type ServiceAccountTokenModel struct {
+	ServiceAccount      string    `json:"serviceAccount"`
+	Namespace           string    `json:"namespace"`
+	Token               string    `json:"token"`
+	Audiences           []string  `json:"audiences,omitempty"`
+	ExpirationTimestamp time.Time `json:"expirationTimestamp"`
+}
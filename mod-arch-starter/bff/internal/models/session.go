@@ -0,0 +1,8 @@
+package models
+
+// Logout is the result of /api/v1/logout.
+type Logout struct {
+	// EndSessionURL ends the session at the OIDC provider too, when it supports RP-initiated
+	// logout: the frontend navigates to it.
+	EndSessionURL string `json:"endSessionUrl,omitempty"`
+}
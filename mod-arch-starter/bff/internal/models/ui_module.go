@@ -0,0 +1,25 @@
+package models
+
+// UIModule is the manifest of a federated frontend module, for a host shell to discover and
+// load it.
+type UIModule struct {
+	Name string `json:"name" validate:"required,dnslabel"`
+	// RemoteEntry is the URL of the module federation remote entry, e.g.
+	// "/notebooks/remoteEntry.js" or an absolute URL on another origin.
+	RemoteEntry string `json:"remoteEntry" validate:"required"`
+	// Routes are the routes the module adds to the shell.
+	Routes []UIModuleRoute `json:"routes"`
+	// Permissions are required to use the module; the shell checks them for the user with
+	// POST /api/v1/permissions/batch.
+	Permissions []PermissionRequest `json:"permissions"`
+}
+
+// UIModuleRoute is a route of the shell rendered by a module.
+type UIModuleRoute struct {
+	// Path is the route in the shell, e.g. "/notebooks/*".
+	Path string `json:"path" validate:"required"`
+	// Exposed is the federated module rendering it, e.g. "./NotebooksPage".
+	Exposed string `json:"exposed" validate:"required"`
+	// Title labels the route in the navigation; empty to leave it out.
+	Title string `json:"title,omitempty"`
+}
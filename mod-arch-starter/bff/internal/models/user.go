@@ -1,6 +1,32 @@
 package models
 
 type User struct {
-	UserID       string `json:"userId"`
-	ClusterAdmin bool   `json:"clusterAdmin"`
+	UserID       string   `json:"userId"`
+	Groups       []string `json:"groups"`
+	ClusterAdmin bool     `json:"clusterAdmin"`
+	// Guest is set for the synthetic identity of guest mode.
+	Guest bool `json:"guest,omitempty"`
+	// NamespaceRoles is only listed on request (namespaceRoles=true).
+	NamespaceRoles []NamespaceRoles `json:"namespaceRoles,omitempty"`
+}
+
+// NamespaceRoles are the effective permissions of the user in a namespace they can access,
+// from a SelfSubjectRulesReview.
+type NamespaceRoles struct {
+	Namespace string         `json:"namespace"`
+	Rules     []ResourceRule `json:"rules"`
+	// Incomplete is set when the API server could not list every rule, e.g. with webhook
+	// authorizers: a missing rule doesn't mean a denied request.
+	Incomplete bool `json:"incomplete,omitempty"`
+	// Error is set, and Rules empty, when the rules of the namespace could not be reviewed.
+	Error string `json:"error,omitempty"`
+}
+
+// ResourceRule allows Verbs on Resources (in APIGroups, and limited to ResourceNames when
+// set). "*" matches any verb, group or resource.
+type ResourceRule struct {
+	Verbs         []string `json:"verbs"`
+	APIGroups     []string `json:"apiGroups,omitempty"`
+	Resources     []string `json:"resources,omitempty"`
+	ResourceNames []string `json:"resourceNames,omitempty"`
 }
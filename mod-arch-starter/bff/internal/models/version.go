@@ -0,0 +1,16 @@
+package models
+
+// VersionInfo describes the build of the running BFF, e.g. for the about dialog of the frontend.
+type VersionInfo struct {
+	Version string `json:"version"`
+	// GitCommit is the commit the BFF was built from, and GitTreeState "dirty" when it had
+	// uncommitted changes; both are empty when unknown.
+	GitCommit    string `json:"gitCommit,omitempty"`
+	GitTreeState string `json:"gitTreeState,omitempty"`
+	// BuildDate is RFC 3339, e.g. "2025-06-01T12:00:00Z"; empty when unknown.
+	BuildDate string `json:"buildDate,omitempty"`
+	GoVersion string `json:"goVersion"`
+	Compiler  string `json:"compiler"`
+	// Platform is the operating system and architecture, e.g. "linux/amd64".
+	Platform string `json:"platform"`
+}
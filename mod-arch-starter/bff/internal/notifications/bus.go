@@ -0,0 +1,226 @@
+// Package notifications is the bus of the user-facing notifications of the BFF, like a resource
+// created or an operation failed: repositories, watchers and jobs publish them, and the UI
+// receives those meant for its user as Server-Sent Events. The bus keeps the recent
+// notifications, so a reconnecting client replays the ones it missed.
+//
+// The bus lives in the memory of its replica: a notification reaches the clients connected to
+// the replica that published it.
+package notifications
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultHistory is the number of recent notifications kept for replay.
+const DefaultHistory = 100
+
+// subscriptionBuffer is the number of notifications a subscriber may lag behind before the bus
+// drops it; the client then reconnects and replays them from the history.
+const subscriptionBuffer = 64
+
+// Severity tells the UI how to show a notification.
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeveritySuccess Severity = "success"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// Notification is a message for the users of the UI.
+type Notification struct {
+	// ID is set by Publish; clients send it back in Last-Event-ID when reconnecting.
+	ID string `json:"id"`
+	// Type classifies the notification for the UI, e.g. "resource.created" or "operation.failed".
+	Type     string   `json:"type"`
+	Severity Severity `json:"severity"`
+	Title    string   `json:"title"`
+	Message  string   `json:"message,omitempty"`
+	// Resource is the object the notification is about, if any.
+	Resource *Resource `json:"resource,omitempty"`
+	// Data is the machine-readable detail of the notification for the UI, e.g. the progress of
+	// an upload.
+	Data any `json:"data,omitempty"`
+	// Time is set by Publish when zero.
+	Time time.Time `json:"time"`
+
+	// Users and Groups are the recipients: a user receives the notification when named in
+	// Users or member of one of Groups. Without either, every user receives it.
+	Users  []string `json:"-"`
+	Groups []string `json:"-"`
+
+	// Transient notifications, like progress updates superseded by the next one, are not kept
+	// for replay, and subscribers lagging behind miss them instead of being dropped.
+	Transient bool `json:"-"`
+}
+
+// Resource identifies the Kubernetes object of a notification.
+type Resource struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// For reports whether the user with groups receives n.
+func (n Notification) For(user string, groups []string) bool {
+	if len(n.Users) == 0 && len(n.Groups) == 0 {
+		return true
+	}
+	if user != "" && slices.Contains(n.Users, user) {
+		return true
+	}
+	for _, group := range groups {
+		if slices.Contains(n.Groups, group) {
+			return true
+		}
+	}
+	return false
+}
+
+type entry struct {
+	seq          uint64
+	notification Notification
+}
+
+// Bus fans the published notifications out to the subscribers. A nil Bus publishes nothing.
+type Bus struct {
+	// epoch tells apart the IDs of this process from those of other replicas and restarts.
+	epoch string
+	size  int
+
+	mu      sync.Mutex
+	seq     uint64
+	history []entry
+	// lost is the sequence number of the last notification that aged out of the history, or
+	// was not kept without history.
+	lost        uint64
+	subscribers map[*Subscription]struct{}
+}
+
+// New creates a bus keeping the last history notifications for replay (none when zero).
+func New(history int) *Bus {
+	epoch := make([]byte, 4)
+	_, _ = rand.Read(epoch)
+	return &Bus{
+		epoch:       hex.EncodeToString(epoch),
+		size:        max(history, 0),
+		subscribers: map[*Subscription]struct{}{},
+	}
+}
+
+// Publish sends n to the subscribers it is meant for and keeps it for replay. It returns n with
+// its ID and time set.
+func (b *Bus) Publish(n Notification) Notification {
+	if b == nil {
+		return n
+	}
+	if n.Time.IsZero() {
+		n.Time = time.Now()
+	}
+	if n.Severity == "" {
+		n.Severity = SeverityInfo
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.seq++
+	n.ID = b.epoch + "-" + strconv.FormatUint(b.seq, 10)
+	switch {
+	case n.Transient:
+	case b.size == 0:
+		b.lost = b.seq
+	default:
+		if len(b.history) == b.size {
+			b.lost = b.history[0].seq
+			b.history = slices.Delete(b.history, 0, 1)
+		}
+		b.history = append(b.history, entry{seq: b.seq, notification: n})
+	}
+
+	for sub := range b.subscribers {
+		if !sub.filter(n) {
+			continue
+		}
+		select {
+		case sub.ch <- n:
+		default:
+			if n.Transient {
+				continue
+			}
+			// Dropping the subscriber closes its stream: the client reconnects with the ID of
+			// the last notification it got, and replays the rest.
+			b.unsubscribe(sub)
+		}
+	}
+	return n
+}
+
+// Subscription receives the notifications passing its filter on C, until Close. C is closed
+// when the subscriber fell too far behind.
+type Subscription struct {
+	C <-chan Notification
+
+	ch     chan Notification
+	filter func(Notification) bool
+	bus    *Bus
+}
+
+// Subscribe subscribes to the notifications passing filter. It also returns those of the
+// history to replay: the ones after lastID, the ID of the last notification a reconnecting
+// client got, or all of them. resumed is false when lastID is set but unknown, because it comes
+// from another replica or aged out of the history: the client then missed notifications, and
+// gets the whole history instead.
+func (b *Bus) Subscribe(lastID string, filter func(Notification) bool) (replay []Notification, resumed bool, sub *Subscription) {
+	ch := make(chan Notification, subscriptionBuffer)
+	sub = &Subscription{C: ch, ch: ch, filter: filter, bus: b}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers[sub] = struct{}{}
+
+	after, resumed := uint64(0), lastID == ""
+	if seq, ok := b.parseID(lastID); ok && seq >= b.lost {
+		after, resumed = seq, true
+	}
+	for _, e := range b.history {
+		if e.seq > after && filter(e.notification) {
+			replay = append(replay, e.notification)
+		}
+	}
+	return replay, resumed, sub
+}
+
+// Close ends the subscription.
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.unsubscribe(s)
+}
+
+// unsubscribe is called with b.mu held.
+func (b *Bus) unsubscribe(sub *Subscription) {
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.ch)
+	}
+}
+
+// parseID returns the sequence number of an ID of this bus.
+func (b *Bus) parseID(id string) (uint64, bool) {
+	epoch, seq, found := strings.Cut(id, "-")
+	if !found || epoch != b.epoch {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil || n > b.seq {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,104 @@
+package notifications
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func all(Notification) bool { return true }
+
+func titles(notifications []Notification) []string {
+	var titles []string
+	for _, n := range notifications {
+		titles = append(titles, n.Title)
+	}
+	return titles
+}
+
+func TestNotificationFor(t *testing.T) {
+	assert.True(t, Notification{}.For("dora", nil), "without recipients, everyone")
+	n := Notification{Users: []string{"dora"}, Groups: []string{"admins"}}
+	assert.True(t, n.For("dora", nil))
+	assert.True(t, n.For("bella", []string{"dev", "admins"}))
+	assert.False(t, n.For("bella", []string{"dev"}))
+	assert.False(t, n.For("", nil))
+}
+
+func TestBus(t *testing.T) {
+	bus := New(3)
+	first := bus.Publish(Notification{Title: "one"})
+	assert.NotEmpty(t, first.ID)
+	assert.False(t, first.Time.IsZero())
+	assert.Equal(t, SeverityInfo, first.Severity)
+	bus.Publish(Notification{Title: "for bella", Users: []string{"bella"}})
+
+	dora := func(n Notification) bool { return n.For("dora", nil) }
+	replay, resumed, sub := bus.Subscribe("", dora)
+	defer sub.Close()
+	assert.True(t, resumed)
+	assert.Equal(t, []string{"one"}, titles(replay), "a new client gets the history meant for it")
+
+	third := bus.Publish(Notification{Title: "three"})
+	bus.Publish(Notification{Title: "for bella again", Users: []string{"bella"}})
+	assert.Equal(t, "three", (<-sub.C).Title)
+	assert.Empty(t, sub.C, "filtered by recipient")
+
+	replay, resumed, other := bus.Subscribe(first.ID, all)
+	other.Close()
+	assert.True(t, resumed)
+	assert.Equal(t, []string{"for bella", "three", "for bella again"}, titles(replay), "newer than the last ID")
+
+	replay, resumed, other = bus.Subscribe(third.ID, dora)
+	other.Close()
+	assert.True(t, resumed)
+	assert.Empty(t, replay)
+
+	bus.Publish(Notification{Title: "five"})
+	_, resumed, other = bus.Subscribe(first.ID, all)
+	other.Close()
+	assert.False(t, resumed, "notifications after the last ID aged out")
+
+	replay, resumed, other = bus.Subscribe("0badc0de-2", all)
+	other.Close()
+	assert.False(t, resumed, "an ID of another replica")
+	assert.Equal(t, []string{"three", "for bella again", "five"}, titles(replay))
+
+	var nilBus *Bus
+	assert.NotPanics(t, func() { nilBus.Publish(Notification{Title: "dropped"}) })
+}
+
+func TestBus_DropsLaggingSubscribers(t *testing.T) {
+	bus := New(0)
+	_, _, sub := bus.Subscribe("", all)
+	for range subscriptionBuffer + 1 {
+		bus.Publish(Notification{Title: "spam"})
+	}
+	received := 0
+	for range sub.C {
+		received++
+	}
+	assert.Equal(t, subscriptionBuffer, received, "C is closed once the subscriber falls behind")
+	require.NotPanics(t, sub.Close)
+}
+
+func TestBus_Transient(t *testing.T) {
+	bus := New(10)
+	_, _, sub := bus.Subscribe("", all)
+	defer sub.Close()
+	for range subscriptionBuffer + 1 {
+		bus.Publish(Notification{Title: "progress", Transient: true})
+	}
+	last := bus.Publish(Notification{Title: "done"})
+	assert.Len(t, sub.C, subscriptionBuffer, "lagging subscribers miss transient notifications but stay subscribed")
+
+	replay, resumed, other := bus.Subscribe("", all)
+	other.Close()
+	assert.True(t, resumed)
+	assert.Equal(t, []string{"done"}, titles(replay), "transient notifications are not replayed")
+	replay, resumed, other = bus.Subscribe(bus.epoch+"-3", all)
+	other.Close()
+	assert.True(t, resumed, "resumed from a transient notification")
+	assert.Equal(t, []Notification{last}, replay)
+}
@@ -0,0 +1,235 @@
+// Package objectdiff compares two versions of a Kubernetes object, typically the stored
+// object and the result of a server-side dry run, so UIs can preview what a write would
+// change:
+//
+//	[
+//	  {"op": "replace", "path": "/spec/image", "oldValue": "jupyter", "value": "codeserver", "managers": ["kubectl-edit"]},
+//	  {"op": "add", "path": "/metadata/labels/team", "value": "a"}
+//	]
+//
+// Changes are listed like the operations of a JSON patch, by RFC 6901 JSON pointer, one per
+// changed scalar or list item. The status and the metadata set by the API server
+// (resourceVersion, managedFields, ...) are left out, and each change names the field
+// managers of the current object owning the field, read from its managedFields, so a write
+// overriding the fields another controller or user applied can be flagged.
+package objectdiff
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// The operations of a Change, those of JSON patch.
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+)
+
+// Change is a field whose value differs between two versions of an object.
+type Change struct {
+	Op string `json:"op"`
+	// Path is the JSON pointer of the field, e.g. /data/app.properties.
+	Path string `json:"path"`
+	// OldValue is the current value, unset for an add.
+	OldValue any `json:"oldValue,omitempty"`
+	// Value is the new value, unset for a remove.
+	Value any `json:"value,omitempty"`
+	// Redacted is set when the values are left out (see Redact).
+	Redacted bool `json:"redacted,omitempty"`
+	// Managers are the field managers owning the field in the current object.
+	Managers []string `json:"managers,omitempty"`
+}
+
+// serverFields are the metadata fields set by the API server, never by a write.
+var serverFields = []string{"managedFields", "resourceVersion", "generation", "uid", "creationTimestamp", "selfLink"}
+
+// Compare returns the changes from current to proposed, sorted by path. current is nil for
+// an object being created.
+func Compare(current, proposed *unstructured.Unstructured) []Change {
+	var before, after map[string]any
+	var owners []fieldOwner
+	if current != nil {
+		before = withoutServerFields(current.Object)
+		owners = managedFields(current)
+	}
+	if proposed != nil {
+		after = withoutServerFields(proposed.Object)
+	}
+
+	changes := []Change{}
+	compare(&changes, nil, before, after, true, true)
+	for i := range changes {
+		changes[i].Managers = owningManagers(owners, Segments(changes[i].Path))
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// Values returns the changes from before to after, two values decoded from JSON, sorted by
+// path. Unlike Compare, no field is left out.
+func Values(before, after any) []Change {
+	changes := []Change{}
+	compare(&changes, nil, before, after, true, true)
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// Redact clears the values of the changes whose path redact matches, marking them Redacted.
+func Redact(changes []Change, redact func(path string) bool) {
+	for i := range changes {
+		if redact(changes[i].Path) {
+			changes[i].OldValue, changes[i].Value, changes[i].Redacted = nil, nil, true
+		}
+	}
+}
+
+// Pointer returns the JSON pointer of the path segments.
+func Pointer(segments ...string) string {
+	var b strings.Builder
+	for _, segment := range segments {
+		b.WriteByte('/')
+		b.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(segment))
+	}
+	return b.String()
+}
+
+// Segments returns the path segments of a JSON pointer, the reverse of Pointer.
+func Segments(pointer string) []string {
+	if pointer == "" {
+		return nil
+	}
+	segments := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(segment)
+	}
+	return segments
+}
+
+// compare appends the changes from before to after at path. A value is missing on a side
+// whose has flag is false; maps are compared key by key, and lists item by item when both
+// sides hold one.
+func compare(changes *[]Change, path []string, before, after any, hasBefore, hasAfter bool) {
+	beforeMap, beforeIsMap := before.(map[string]any)
+	afterMap, afterIsMap := after.(map[string]any)
+	if (beforeIsMap || !hasBefore) && (afterIsMap || !hasAfter) {
+		keys := map[string]bool{}
+		for key := range beforeMap {
+			keys[key] = true
+		}
+		for key := range afterMap {
+			keys[key] = true
+		}
+		for key := range keys {
+			b, inBefore := beforeMap[key]
+			a, inAfter := afterMap[key]
+			compare(changes, append(slices.Clip(path), key), b, a, inBefore, inAfter)
+		}
+		return
+	}
+
+	beforeList, beforeIsList := before.([]any)
+	afterList, afterIsList := after.([]any)
+	if beforeIsList && afterIsList {
+		for i := range max(len(beforeList), len(afterList)) {
+			var b, a any
+			if i < len(beforeList) {
+				b = beforeList[i]
+			}
+			if i < len(afterList) {
+				a = afterList[i]
+			}
+			compare(changes, append(slices.Clip(path), strconv.Itoa(i)), b, a, i < len(beforeList), i < len(afterList))
+		}
+		return
+	}
+
+	switch {
+	case !hasBefore:
+		*changes = append(*changes, Change{Op: OpAdd, Path: Pointer(path...), Value: after})
+	case !hasAfter:
+		*changes = append(*changes, Change{Op: OpRemove, Path: Pointer(path...), OldValue: before})
+	case !reflect.DeepEqual(before, after):
+		*changes = append(*changes, Change{Op: OpReplace, Path: Pointer(path...), OldValue: before, Value: after})
+	}
+}
+
+func withoutServerFields(object map[string]any) map[string]any {
+	copied := make(map[string]any, len(object))
+	for key, value := range object {
+		copied[key] = value
+	}
+	if metadata, ok := object["metadata"].(map[string]any); ok {
+		trimmed := make(map[string]any, len(metadata))
+		for key, value := range metadata {
+			if !slices.Contains(serverFields, key) {
+				trimmed[key] = value
+			}
+		}
+		copied["metadata"] = trimmed
+	}
+	delete(copied, "status")
+	return copied
+}
+
+// fieldOwner is the field set of a managedFields entry, in the FieldsV1 format:
+// {"f:spec": {"f:image": {}}, "f:metadata": {"f:labels": {"f:team": {}}}}.
+type fieldOwner struct {
+	manager string
+	fields  map[string]any
+}
+
+func managedFields(obj *unstructured.Unstructured) []fieldOwner {
+	var owners []fieldOwner
+	for _, entry := range obj.GetManagedFields() {
+		if entry.FieldsV1 == nil || entry.Manager == "" {
+			continue
+		}
+		var fields map[string]any
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			continue
+		}
+		owners = append(owners, fieldOwner{manager: entry.Manager, fields: fields})
+	}
+	return owners
+}
+
+// owningManagers returns the sorted managers owning the field at path, or a field below it.
+// The items of a list are named by key or value in FieldsV1, not by index: a manager owning
+// items of a list owns every change within it.
+func owningManagers(owners []fieldOwner, path []string) []string {
+	var managers []string
+	for _, owner := range owners {
+		if owns(owner.fields, path) && !slices.Contains(managers, owner.manager) {
+			managers = append(managers, owner.manager)
+		}
+	}
+	sort.Strings(managers)
+	return managers
+}
+
+func owns(fields map[string]any, path []string) bool {
+	for _, segment := range path {
+		if next, ok := fields["f:"+segment].(map[string]any); ok {
+			fields = next
+			continue
+		}
+		if next, ok := fields["i:"+segment].(map[string]any); ok {
+			fields = next
+			continue
+		}
+		for key := range fields {
+			if strings.HasPrefix(key, "k:") || strings.HasPrefix(key, "v:") {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+package objectdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func configMap(data map[string]any, labels map[string]any) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]any{"name": "settings", "namespace": "dora-namespace", "labels": labels},
+		"data":       data,
+	}}
+}
+
+func TestCompare(t *testing.T) {
+	current := configMap(map[string]any{"app.properties": "a=1", "old": "x"}, map[string]any{"team": "a"})
+	current.SetResourceVersion("1")
+	current.SetManagedFields([]metav1.ManagedFieldsEntry{
+		{Manager: "kubectl", Operation: metav1.ManagedFieldsOperationApply, FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:data":{"f:app.properties":{}}}`)}},
+		{Manager: "dashboard", Operation: metav1.ManagedFieldsOperationUpdate, FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:data":{".":{},"f:old":{}},"f:metadata":{"f:labels":{}}}`)}},
+	})
+	proposed := configMap(map[string]any{"app.properties": "a=2", "new/key": "y"}, map[string]any{"team": "a", "tier": "web"})
+	proposed.SetResourceVersion("2")
+
+	assert.Equal(t, []Change{
+		{Op: OpReplace, Path: "/data/app.properties", OldValue: "a=1", Value: "a=2", Managers: []string{"kubectl"}},
+		{Op: OpAdd, Path: "/data/new~1key", Value: "y"},
+		{Op: OpRemove, Path: "/data/old", OldValue: "x", Managers: []string{"dashboard"}},
+		{Op: OpAdd, Path: "/metadata/labels/tier", Value: "web"},
+	}, Compare(current, proposed), "server fields are left out")
+
+	assert.Empty(t, Compare(current, current))
+}
+
+func TestCompare_Create(t *testing.T) {
+	changes := Compare(nil, configMap(map[string]any{"key": "value"}, nil))
+	assert.Equal(t, []Change{
+		{Op: OpAdd, Path: "/apiVersion", Value: "v1"},
+		{Op: OpAdd, Path: "/data/key", Value: "value"},
+		{Op: OpAdd, Path: "/kind", Value: "ConfigMap"},
+		{Op: OpAdd, Path: "/metadata/name", Value: "settings"},
+		{Op: OpAdd, Path: "/metadata/namespace", Value: "dora-namespace"},
+	}, changes)
+}
+
+func TestCompare_Lists(t *testing.T) {
+	pod := func(image string, args ...any) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{"spec": map[string]any{
+			"containers": []any{map[string]any{"name": "main", "image": image, "args": args}},
+		}}}
+	}
+	current := pod("jupyter", "a", "b")
+	current.SetManagedFields([]metav1.ManagedFieldsEntry{{
+		Manager:  "notebook-controller",
+		FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:containers":{"k:{\"name\":\"main\"}":{"f:image":{}}}}}`)},
+	}})
+
+	assert.Equal(t, []Change{
+		{Op: OpRemove, Path: "/spec/containers/0/args/1", OldValue: "b", Managers: []string{"notebook-controller"}},
+		{Op: OpReplace, Path: "/spec/containers/0/image", OldValue: "jupyter", Value: "codeserver", Managers: []string{"notebook-controller"}},
+	}, Compare(current, pod("codeserver", "a")), "keyed list items are owned as a whole")
+}
+
+func TestValues(t *testing.T) {
+	before := map[string]any{"data": []any{map[string]any{"name": "a", "status": "ok"}}, "metadata": map[string]any{"creationTimestamp": "x"}}
+	after := map[string]any{"data": []any{map[string]any{"name": "a", "status": 1.0}, "b"}}
+	assert.Equal(t, []Change{
+		{Op: OpReplace, Path: "/data/0/status", OldValue: "ok", Value: 1.0},
+		{Op: OpAdd, Path: "/data/1", Value: "b"},
+		{Op: OpRemove, Path: "/metadata/creationTimestamp", OldValue: "x"},
+	}, Values(before, after), "no field is left out")
+	assert.Empty(t, Values(before, before))
+}
+
+func TestRedact(t *testing.T) {
+	changes := []Change{
+		{Op: OpReplace, Path: "/data/password", OldValue: "a", Value: "b"},
+		{Op: OpAdd, Path: "/metadata/labels/team", Value: "a"},
+	}
+	Redact(changes, func(path string) bool { return path == Pointer("data", "password") })
+	assert.Equal(t, []Change{
+		{Op: OpReplace, Path: "/data/password", Redacted: true},
+		{Op: OpAdd, Path: "/metadata/labels/team", Value: "a"},
+	}, changes)
+}
@@ -0,0 +1,236 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const contentTypeJSON = "application/json"
+
+// Operation describes a route for the document.
+type Operation struct {
+	// Method is the HTTP method, e.g. http.MethodGet.
+	Method string
+	// Path uses the httprouter syntax; ":name" and "*name" segments become path parameters.
+	Path string
+
+	ID          string
+	Summary     string
+	Description string
+	Tags        []string
+
+	// Parameters lists the query and header parameters. Path parameters are derived from Path;
+	// list one with In "path" to describe it.
+	Parameters []Parameter
+
+	// Request is a value of the request body type, e.g. RegisteredModelEnvelope{}; nil for none.
+	Request any
+	// RequestContentType of the request (default application/json), e.g. multipart/form-data.
+	RequestContentType string
+	// Response is a value of the response body type; nil for none. A *Schema is used as is.
+	Response any
+	// Status is the success status code (default 200).
+	Status int
+	// ContentType of the response (default application/json).
+	ContentType string
+
+	// Public operations don't require the document's security schemes.
+	Public bool
+	// Deprecated operations are kept for existing clients only.
+	Deprecated bool
+}
+
+// Parameter is a query, header or path parameter of an Operation.
+type Parameter struct {
+	Name string
+	// In is "query" (default), "header" or "path".
+	In          string
+	Description string
+	Required    bool
+	// Schema defaults to a string.
+	Schema *Schema
+}
+
+// Query returns a string query parameter.
+func Query(name, description string, required bool) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Required: required}
+}
+
+// Builder collects operations and builds their Document.
+type Builder struct {
+	info     Info
+	servers  []Server
+	schemes  map[string]SecurityScheme
+	security []SecurityRequirement
+	errorRes any
+	ops      []Operation
+}
+
+func NewBuilder(info Info) *Builder {
+	return &Builder{info: info, schemes: map[string]SecurityScheme{}}
+}
+
+// AddServer adds a server URL, e.g. the path prefix the API is served under.
+func (b *Builder) AddServer(server Server) *Builder {
+	b.servers = append(b.servers, server)
+	return b
+}
+
+// AddSecurityScheme adds a security scheme that every non-public operation accepts; with
+// several schemes, any one of them is enough.
+func (b *Builder) AddSecurityScheme(name string, scheme SecurityScheme) *Builder {
+	b.schemes[name] = scheme
+	b.security = append(b.security, SecurityRequirement{name: {}})
+	return b
+}
+
+// ErrorResponse sets the body type of the default (error) response of every operation.
+func (b *Builder) ErrorResponse(value any) *Builder {
+	b.errorRes = value
+	return b
+}
+
+func (b *Builder) Add(ops ...Operation) *Builder {
+	b.ops = append(b.ops, ops...)
+	return b
+}
+
+// Build returns the document of the added operations. It fails when two operations share a
+// method and path.
+func (b *Builder) Build() (*Document, error) {
+	gen := newSchemaGenerator()
+	doc := &Document{
+		OpenAPI:  Version,
+		Info:     b.info,
+		Servers:  b.servers,
+		Paths:    map[string]PathItem{},
+		Security: b.security,
+	}
+
+	var errorResponse *Response
+	if b.errorRes != nil {
+		errorResponse = &Response{
+			Description: "Error",
+			Content:     map[string]MediaType{contentTypeJSON: {Schema: gen.schemaOf(b.errorRes)}},
+		}
+	}
+
+	ops := append([]Operation(nil), b.ops...)
+	sort.SliceStable(ops, func(i, j int) bool { return ops[i].Path < ops[j].Path })
+	for _, op := range ops {
+		path, pathParams := convertPath(op.Path)
+		method := strings.ToLower(op.Method)
+		if method == "" {
+			return nil, fmt.Errorf("operation %s: method is required", op.Path)
+		}
+		item := doc.Paths[path]
+		if item == nil {
+			item = PathItem{}
+			doc.Paths[path] = item
+		}
+		if _, exists := item[method]; exists {
+			return nil, fmt.Errorf("duplicate operation %s %s", op.Method, op.Path)
+		}
+		item[method] = b.operation(gen, op, pathParams, errorResponse)
+	}
+
+	doc.Components.Schemas = gen.schemas
+	if len(b.schemes) > 0 {
+		doc.Components.SecuritySchemes = b.schemes
+	}
+	return doc, nil
+}
+
+func (b *Builder) operation(gen *schemaGenerator, op Operation, pathParams []string, errorResponse *Response) *OperationObject {
+	obj := &OperationObject{
+		OperationID: op.ID,
+		Summary:     op.Summary,
+		Description: op.Description,
+		Tags:        op.Tags,
+		Deprecated:  op.Deprecated,
+		Responses:   map[string]Response{},
+	}
+	if op.Public && len(b.security) > 0 {
+		// An empty requirement opts out of the document's security
+		obj.Security = []SecurityRequirement{{}}
+	}
+
+	described := map[string]Parameter{}
+	for _, p := range op.Parameters {
+		if p.In == "path" {
+			described[p.Name] = p
+		}
+	}
+	for _, name := range pathParams {
+		p, ok := described[name]
+		if !ok {
+			p = Parameter{Name: name}
+		}
+		p.In, p.Required = "path", true
+		obj.Parameters = append(obj.Parameters, parameterObject(p))
+	}
+	for _, p := range op.Parameters {
+		if p.In != "path" {
+			obj.Parameters = append(obj.Parameters, parameterObject(p))
+		}
+	}
+
+	if op.Request != nil {
+		contentType := op.RequestContentType
+		if contentType == "" {
+			contentType = contentTypeJSON
+		}
+		obj.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{contentType: {Schema: gen.schemaOf(op.Request)}},
+		}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	response := Response{Description: http.StatusText(status)}
+	if op.Response != nil {
+		contentType := op.ContentType
+		if contentType == "" {
+			contentType = contentTypeJSON
+		}
+		response.Content = map[string]MediaType{contentType: {Schema: gen.schemaOf(op.Response)}}
+	}
+	obj.Responses[strconv.Itoa(status)] = response
+	if errorResponse != nil {
+		obj.Responses["default"] = *errorResponse
+	}
+	return obj
+}
+
+func parameterObject(p Parameter) ParameterObject {
+	in := p.In
+	if in == "" {
+		in = "query"
+	}
+	schema := p.Schema
+	if schema == nil {
+		schema = &Schema{Type: "string"}
+	}
+	return ParameterObject{Name: p.Name, In: in, Description: p.Description, Required: p.Required, Schema: schema}
+}
+
+// convertPath turns an httprouter path into an OpenAPI path template and returns its
+// parameter names, e.g. /watch/:resource becomes /watch/{resource}.
+func convertPath(routerPath string) (string, []string) {
+	segments := strings.Split(routerPath, "/")
+	var params []string
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			name := segment[1:]
+			params = append(params, name)
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
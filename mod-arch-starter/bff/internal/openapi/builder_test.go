@@ -0,0 +1,153 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type page[T any] struct {
+	Items []T    `json:"items"`
+	Next  string `json:"next,omitempty"`
+}
+
+type base struct {
+	ID string `json:"id"`
+}
+
+type item struct {
+	base
+	Name    string            `json:"name" description:"Display name"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Created time.Time         `json:"created"`
+	Count   int64             `json:"count,string"`
+	Parent  *item             `json:"parent,omitempty"`
+	Raw     json.RawMessage   `json:"raw,omitempty"`
+	Skipped string            `json:"-"`
+}
+
+type envelope struct {
+	Data page[item] `json:"data"`
+}
+
+func TestBuilder_Schemas(t *testing.T) {
+	doc, err := NewBuilder(Info{Title: "test", Version: "1"}).
+		Add(Operation{Method: http.MethodGet, Path: "/items", Response: envelope{}}).
+		Build()
+	require.NoError(t, err)
+
+	response := doc.Paths["/items"]["get"].Responses["200"]
+	assert.Equal(t, "#/components/schemas/envelope", response.Content["application/json"].Schema.Ref)
+
+	pageSchema := doc.Components.Schemas["pageitem"]
+	require.NotNil(t, pageSchema, "generic type names drop the package path: %v", keys(doc.Components.Schemas))
+	assert.Equal(t, []string{"items"}, pageSchema.Required)
+	assert.Equal(t, "#/components/schemas/item", pageSchema.Properties["items"].Items.Ref)
+
+	itemSchema := doc.Components.Schemas["item"]
+	require.NotNil(t, itemSchema)
+	assert.ElementsMatch(t, []string{"id", "name", "labels", "created", "count", "parent", "raw"}, keys(itemSchema.Properties))
+	assert.Equal(t, []string{"id", "name", "created", "count"}, itemSchema.Required)
+	assert.Equal(t, "Display name", itemSchema.Properties["name"].Description)
+	assert.Equal(t, &Schema{Type: "object", AdditionalProperties: &Schema{Type: "string"}}, itemSchema.Properties["labels"])
+	assert.Equal(t, "date-time", itemSchema.Properties["created"].Format)
+	assert.Equal(t, "string", itemSchema.Properties["count"].Type)
+	assert.Equal(t, "#/components/schemas/item", itemSchema.Properties["parent"].Ref, "recursive types are referenced")
+}
+
+type constrained struct {
+	Name     string   `json:"name,omitempty" validate:"required,dnslabel"`
+	Replicas int      `json:"replicas,omitempty" validate:"min=1,max=10"`
+	Mode     string   `json:"mode,omitempty" validate:"oneof=fast safe"`
+	Tags     []string `json:"tags,omitempty" validate:"max=5"`
+}
+
+func TestBuilder_ValidateTags(t *testing.T) {
+	doc, err := NewBuilder(Info{Title: "test", Version: "1"}).
+		Add(Operation{Method: http.MethodPost, Path: "/items", Request: constrained{}}).
+		Build()
+	require.NoError(t, err)
+
+	schema := doc.Components.Schemas["constrained"]
+	require.NotNil(t, schema)
+	assert.Equal(t, []string{"name"}, schema.Required, "required despite omitempty")
+	one, ten, five, maxLabel := 1.0, 10.0, 5, 63
+	assert.Equal(t, &Schema{Type: "string", Pattern: validation.DNSLabelPattern, MaxLength: &maxLabel}, schema.Properties["name"])
+	assert.Equal(t, &Schema{Type: "integer", Format: "int64", Minimum: &one, Maximum: &ten}, schema.Properties["replicas"])
+	assert.Equal(t, []any{"fast", "safe"}, schema.Properties["mode"].Enum)
+	assert.Equal(t, &five, schema.Properties["tags"].MaxItems)
+}
+
+func TestBuilder_Operations(t *testing.T) {
+	builder := NewBuilder(Info{Title: "test", Version: "1"}).
+		AddSecurityScheme("bearer", SecurityScheme{Type: "http", Scheme: "bearer"}).
+		ErrorResponse(struct {
+			Message string `json:"message"`
+		}{}).
+		Add(
+			Operation{Method: http.MethodPost, Path: "/ns/:namespace/items", Request: item{}, Response: item{}, Status: http.StatusCreated,
+				Parameters: []Parameter{{Name: "namespace", In: "path", Description: "Namespace"}, Query("dryRun", "", false)}},
+			Operation{Method: http.MethodGet, Path: "/files/*filepath", Public: true},
+		)
+
+	doc, err := builder.Build()
+	require.NoError(t, err)
+
+	create := doc.Paths["/ns/{namespace}/items"]["post"]
+	require.NotNil(t, create)
+	assert.Equal(t, []ParameterObject{
+		{Name: "namespace", In: "path", Description: "Namespace", Required: true, Schema: &Schema{Type: "string"}},
+		{Name: "dryRun", In: "query", Schema: &Schema{Type: "string"}},
+	}, create.Parameters)
+	assert.True(t, create.RequestBody.Required)
+	assert.Contains(t, create.Responses, "201")
+	assert.Contains(t, create.Responses, "default")
+	assert.Nil(t, create.Security, "operations use the document security")
+	assert.Equal(t, []SecurityRequirement{{"bearer": {}}}, doc.Security)
+
+	files := doc.Paths["/files/{filepath}"]["get"]
+	require.NotNil(t, files)
+	assert.Equal(t, "filepath", files.Parameters[0].Name)
+	assert.Equal(t, []SecurityRequirement{{}}, files.Security)
+	assert.Nil(t, files.Responses["200"].Content)
+
+	_, err = builder.Add(Operation{Method: http.MethodGet, Path: "/files/*filepath"}).Build()
+	assert.ErrorContains(t, err, "duplicate operation")
+}
+
+func TestSwaggerUIHandler(t *testing.T) {
+	handler := SwaggerUIHandler("Test API", "../openapi.json")
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		return rr
+	}
+
+	rr := get("/")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "<title>Test API</title>")
+	assert.NotContains(t, rr.Body.String(), "<script>", "no inline script")
+
+	rr = get("/swagger-ui-bundle.js")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotEmpty(t, rr.Body.Bytes())
+
+	rr = get("/swagger-initializer.js")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `url: "../openapi.json"`)
+}
+
+func keys[V any](m map[string]V) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	return result
+}
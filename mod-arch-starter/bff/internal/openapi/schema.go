@@ -0,0 +1,233 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/validation"
+)
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	rawMessageType    = reflect.TypeFor[json.RawMessage]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// schemaGenerator derives JSON schemas from Go types the way encoding/json marshals them.
+// Named struct types become components, referenced with $ref.
+type schemaGenerator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{schemas: map[string]*Schema{}, names: map[reflect.Type]string{}}
+}
+
+// schemaOf returns the schema of the type of value; nil for a nil value.
+func (g *schemaGenerator) schemaOf(value any) *Schema {
+	if value == nil {
+		return nil
+	}
+	if schema, ok := value.(*Schema); ok {
+		return schema
+	}
+	return g.schema(reflect.TypeOf(value))
+}
+
+func (g *schemaGenerator) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	case reflect.PointerTo(t).Implements(jsonMarshalerType):
+		// Custom JSON encoding: the shape cannot be derived
+		return &Schema{}
+	case reflect.PointerTo(t).Implements(textMarshalerType):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return g.ref(t)
+	default:
+		// Interfaces and anything else encoding/json can hold
+		return &Schema{}
+	}
+}
+
+// ref registers the component of a named struct type and returns a reference to it.
+func (g *schemaGenerator) ref(t reflect.Type) *Schema {
+	name, ok := g.names[t]
+	if !ok {
+		name = g.componentName(t)
+		g.names[t] = name
+		// Registered before the fields so recursive types terminate
+		g.schemas[name] = &Schema{}
+		*g.schemas[name] = *g.structSchema(t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+var typeArgPackage = regexp.MustCompile(`[\w./-]*\.`)
+
+// componentName names the component of t after its type name. Instantiated generic types drop
+// the package paths of their type arguments, e.g. ModelRegistryList[RegisteredModel] becomes
+// ModelRegistryListRegisteredModel; a name already taken by another type is prefixed with the
+// package name.
+func (g *schemaGenerator) componentName(t reflect.Type) string {
+	name := typeArgPackage.ReplaceAllString(t.Name(), "")
+	name = strings.NewReplacer("[", "", "]", "", ",", "", "*", "", " ", "").Replace(name)
+	if _, taken := g.schemas[name]; taken {
+		name = exportedName(path.Base(t.PkgPath())) + name
+	}
+	return name
+}
+
+func exportedName(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.addFields(schema, t)
+	if len(schema.Properties) == 0 {
+		schema.Properties = nil
+	}
+	return schema
+}
+
+// addFields adds the JSON fields of struct type t to schema, inlining embedded structs like
+// encoding/json does. Fields without omitempty, or with the required validate rule, are
+// required.
+func (g *schemaGenerator) addFields(schema *Schema, t reflect.Type) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.addFields(schema, embedded)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := g.schema(field.Type)
+		if strings.Contains(options, "string") && property.Ref == "" {
+			property = &Schema{Type: "string"}
+		}
+		if description := field.Tag.Get("description"); description != "" {
+			if property.Ref != "" {
+				// Siblings of $ref are ignored in OpenAPI 3.0
+				property = &Schema{Description: description, AllOf: []*Schema{property}}
+			} else {
+				property.Description = description
+			}
+		}
+		required := constrain(property, validation.Rules(field.Tag.Get(validation.TagName)))
+		schema.Properties[name] = property
+		if required || (!strings.Contains(options, "omitempty") && !strings.Contains(options, "omitzero")) {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// constrain adds the constraints of the validate rules of a field (see package validation) to
+// its property, and reports whether the field is required. Referenced schemas are left as is.
+func constrain(property *Schema, rules []validation.Rule) (required bool) {
+	for _, rule := range rules {
+		if rule.Name == "required" {
+			required = true
+			continue
+		}
+		if property.Ref != "" {
+			continue
+		}
+		switch rule.Name {
+		case "min", "max":
+			bound, _ := strconv.ParseFloat(rule.Param, 64)
+			switch property.Type {
+			case "string":
+				setBound(rule.Name, int(bound), &property.MinLength, &property.MaxLength)
+			case "array":
+				setBound(rule.Name, int(bound), &property.MinItems, &property.MaxItems)
+			case "integer", "number":
+				setBound(rule.Name, bound, &property.Minimum, &property.Maximum)
+			}
+		case "oneof":
+			for _, value := range strings.Fields(rule.Param) {
+				if n, err := strconv.ParseInt(value, 10, 64); err == nil && property.Type == "integer" {
+					property.Enum = append(property.Enum, n)
+				} else {
+					property.Enum = append(property.Enum, value)
+				}
+			}
+		case "dnslabel":
+			property.Pattern = validation.DNSLabelPattern
+			setBound("max", 63, &property.MinLength, &property.MaxLength)
+		case "dnssubdomain":
+			property.Pattern = validation.DNSSubdomainPattern
+			setBound("max", 253, &property.MinLength, &property.MaxLength)
+		}
+	}
+	return required
+}
+
+func setBound[T any](rule string, bound T, minimum, maximum **T) {
+	if rule == "min" {
+		*minimum = &bound
+	} else {
+		*maximum = &bound
+	}
+}
@@ -0,0 +1,65 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"strings"
+
+	swaggerFiles "github.com/swaggo/files"
+)
+
+var swaggerUIIndex = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <title>{{.}}</title>
+  <link rel="stylesheet" type="text/css" href="swagger-ui.css">
+  <link rel="icon" type="image/png" href="favicon-32x32.png" sizes="32x32">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="swagger-ui-bundle.js"></script>
+  <script src="swagger-ui-standalone-preset.js"></script>
+  <script src="swagger-initializer.js"></script>
+</body>
+</html>
+`))
+
+// swaggerUIInitializer configures the UI from a script file rather than an inline script, so
+// the page works under a Content-Security-Policy without 'unsafe-inline'.
+const swaggerUIInitializer = `window.onload = () => {
+  window.ui = SwaggerUIBundle({
+    url: %s,
+    dom_id: "#swagger-ui",
+    deepLinking: true,
+    presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+    layout: "StandaloneLayout",
+  });
+};
+`
+
+// SwaggerUIHandler serves Swagger UI, embedded in the binary, showing the document at specURL
+// (relative URLs are resolved against the UI). Mount it with the UI prefix stripped, so it
+// sees "/" for the page and "/<asset>" for its scripts and styles.
+func SwaggerUIHandler(title, specURL string) http.Handler {
+	assets := http.FileServer(swaggerFiles.HTTP)
+	quotedURL, _ := json.Marshal(specURL)
+	initializer := fmt.Sprintf(swaggerUIInitializer, quotedURL)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch strings.TrimPrefix(r.URL.Path, "/") {
+		case "", "index.html":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_ = swaggerUIIndex.Execute(w, title)
+		case "swagger-initializer.js":
+			// Replaces the bundled initializer, which loads the petstore example
+			w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+			_, _ = io.WriteString(w, initializer)
+		default:
+			assets.ServeHTTP(w, r)
+		}
+	})
+}
@@ -0,0 +1,255 @@
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// componentPrefix prefixes the references to the schemas of the components.
+const componentPrefix = "#/components/schemas/"
+
+// ResponseSchema returns the schema of the body of the response with status of the operation
+// serving method on path, a request path such as /api/v1/secrets/db; the default response is
+// used for the statuses the operation doesn't list. The schema is nil for a response without
+// JSON body.
+func (d *Document) ResponseSchema(method, path string, status int) (*Schema, error) {
+	template, ok := d.matchPath(path)
+	if !ok {
+		return nil, fmt.Errorf("no operation of the OpenAPI document serves %s", path)
+	}
+	op := d.Paths[template][strings.ToLower(method)]
+	if op == nil {
+		return nil, fmt.Errorf("no operation of the OpenAPI document serves %s %s", method, template)
+	}
+	response, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		if response, ok = op.Responses["default"]; !ok {
+			return nil, fmt.Errorf("%s %s doesn't document the status %d", method, template, status)
+		}
+	}
+	return response.Content[contentTypeJSON].Schema, nil
+}
+
+// matchPath returns the path template of the document matching path, the one with the most
+// literal segments when several do. A trailing parameter, e.g. of a catch-all route, matches the
+// rest of the path when no template matches it segment by segment.
+func (d *Document) matchPath(path string) (string, bool) {
+	segments := strings.Split(strings.TrimSuffix(path, "/"), "/")
+	best, bestLiterals, found := "", -1, false
+	var catchAll []string
+	for template := range d.Paths {
+		parts := strings.Split(strings.TrimSuffix(template, "/"), "/")
+		if len(parts) != len(segments) {
+			if last := parts[len(parts)-1]; isPathParameter(last) && len(segments) > len(parts) && segmentsMatch(parts[:len(parts)-1], segments[:len(parts)-1]) {
+				catchAll = append(catchAll, template)
+			}
+			continue
+		}
+		if !segmentsMatch(parts, segments) {
+			continue
+		}
+		literals := 0
+		for _, part := range parts {
+			if !isPathParameter(part) {
+				literals++
+			}
+		}
+		if literals > bestLiterals || (literals == bestLiterals && template < best) {
+			best, bestLiterals, found = template, literals, true
+		}
+	}
+	if !found && len(catchAll) > 0 {
+		// The longest prefix is the most specific
+		sort.Slice(catchAll, func(i, j int) bool { return len(catchAll[i]) > len(catchAll[j]) })
+		return catchAll[0], true
+	}
+	return best, found
+}
+
+func segmentsMatch(parts, segments []string) bool {
+	for i, part := range parts {
+		if !isPathParameter(part) && part != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func isPathParameter(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+// Validate checks value, decoded from JSON, against schema, resolving the references to the
+// components of d, and returns the problems prefixed with the JSON pointer of the value, e.g.
+// `/data/0: missing required property "name"`.
+//
+// The schemas are generated from Go types, whose nil slices, maps and pointers encode as null:
+// null is accepted for arrays, objects and references, and for the other types only when they
+// are nullable. The properties an object schema doesn't declare are reported, as the
+// document no longer describes the response.
+func (d *Document) Validate(schema *Schema, value any) []string {
+	var problems []string
+	d.validate(&problems, "", schema, value)
+	return problems
+}
+
+func (d *Document) validate(problems *[]string, path string, schema *Schema, value any) {
+	if schema == nil {
+		return
+	}
+	report := func(format string, args ...any) {
+		*problems = append(*problems, pointerOrRoot(path)+": "+fmt.Sprintf(format, args...))
+	}
+
+	if schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, componentPrefix)
+		component, ok := d.Components.Schemas[name]
+		if !ok {
+			report("unknown schema %s", schema.Ref)
+			return
+		}
+		if value == nil {
+			return
+		}
+		d.validate(problems, path, component, value)
+		return
+	}
+	for _, part := range schema.AllOf {
+		d.validate(problems, path, part, value)
+	}
+
+	if value == nil {
+		if !schema.Nullable && schema.Type != "" && schema.Type != "array" && schema.Type != "object" {
+			report("null, want %s", schema.Type)
+		}
+		return
+	}
+	if len(schema.Enum) > 0 && !slices.ContainsFunc(schema.Enum, func(allowed any) bool { return enumEqual(allowed, value) }) {
+		report("%v is not one of %v", value, schema.Enum)
+	}
+
+	switch schema.Type {
+	case "":
+		// Any value
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			report("%s, want string", jsonType(value))
+			return
+		}
+		if schema.MinLength != nil && len(s) < *schema.MinLength {
+			report("shorter than %d characters", *schema.MinLength)
+		}
+		if schema.MaxLength != nil && len(s) > *schema.MaxLength {
+			report("longer than %d characters", *schema.MaxLength)
+		}
+		if schema.Pattern != "" {
+			if re, err := regexp.Compile(schema.Pattern); err == nil && !re.MatchString(s) {
+				report("%q doesn't match %s", s, schema.Pattern)
+			}
+		}
+	case "integer", "number":
+		n, ok := value.(float64)
+		if !ok {
+			report("%s, want %s", jsonType(value), schema.Type)
+			return
+		}
+		if schema.Type == "integer" && n != math.Trunc(n) {
+			report("%v, want integer", n)
+		}
+		if schema.Minimum != nil && n < *schema.Minimum {
+			report("%v is less than %v", n, *schema.Minimum)
+		}
+		if schema.Maximum != nil && n > *schema.Maximum {
+			report("%v is greater than %v", n, *schema.Maximum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			report("%s, want boolean", jsonType(value))
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			report("%s, want array", jsonType(value))
+			return
+		}
+		if schema.MinItems != nil && len(items) < *schema.MinItems {
+			report("fewer than %d items", *schema.MinItems)
+		}
+		if schema.MaxItems != nil && len(items) > *schema.MaxItems {
+			report("more than %d items", *schema.MaxItems)
+		}
+		for i, item := range items {
+			d.validate(problems, path+"/"+strconv.Itoa(i), schema.Items, item)
+		}
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			report("%s, want object", jsonType(value))
+			return
+		}
+		for _, name := range schema.Required {
+			if _, ok := object[name]; !ok {
+				report("missing required property %q", name)
+			}
+		}
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			childPath := path + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+			switch property, declared := schema.Properties[key]; {
+			case declared:
+				d.validate(problems, childPath, property, object[key])
+			case schema.AdditionalProperties != nil:
+				d.validate(problems, childPath, schema.AdditionalProperties, object[key])
+			case schema.Properties != nil:
+				report("undocumented property %q", key)
+			}
+		}
+	default:
+		report("unknown schema type %q", schema.Type)
+	}
+}
+
+// enumEqual compares an enum value of a schema, e.g. an int64 of an integer enum, with a value
+// decoded from JSON.
+func enumEqual(allowed, value any) bool {
+	switch allowed := allowed.(type) {
+	case int64:
+		return value == float64(allowed)
+	case int:
+		return value == float64(allowed)
+	}
+	return allowed == value
+}
+
+func jsonType(value any) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func pointerOrRoot(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
@@ -0,0 +1,82 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type errorBody struct {
+	Error struct {
+		Code string `json:"code"`
+	} `json:"error"`
+}
+
+func TestDocument_ResponseSchema(t *testing.T) {
+	doc, err := NewBuilder(Info{Title: "test", Version: "1"}).
+		ErrorResponse(errorBody{}).
+		Add(
+			Operation{Method: http.MethodGet, Path: "/items/:id", Response: envelope{}},
+			Operation{Method: http.MethodGet, Path: "/items/latest", Response: page[item]{}},
+			Operation{Method: http.MethodGet, Path: "/files/*path", Response: item{}},
+			Operation{Method: http.MethodDelete, Path: "/items/:id", Status: http.StatusNoContent},
+		).
+		Build()
+	require.NoError(t, err)
+
+	schema, err := doc.ResponseSchema(http.MethodGet, "/items/a", http.StatusOK)
+	require.NoError(t, err)
+	assert.Equal(t, "#/components/schemas/envelope", schema.Ref)
+	schema, err = doc.ResponseSchema(http.MethodGet, "/items/latest", http.StatusOK)
+	require.NoError(t, err)
+	assert.Equal(t, "#/components/schemas/pageitem", schema.Ref, "literal segments win")
+	schema, err = doc.ResponseSchema(http.MethodGet, "/files/a/b.txt", http.StatusOK)
+	require.NoError(t, err)
+	assert.Equal(t, "#/components/schemas/item", schema.Ref, "catch-all")
+	schema, err = doc.ResponseSchema(http.MethodGet, "/items/a", http.StatusNotFound)
+	require.NoError(t, err)
+	assert.Equal(t, "#/components/schemas/errorBody", schema.Ref, "default response")
+	schema, err = doc.ResponseSchema(http.MethodDelete, "/items/a", http.StatusNoContent)
+	require.NoError(t, err)
+	assert.Nil(t, schema)
+
+	_, err = doc.ResponseSchema(http.MethodPut, "/items/a", http.StatusOK)
+	assert.ErrorContains(t, err, "PUT /items/{id}")
+	_, err = doc.ResponseSchema(http.MethodGet, "/other", http.StatusOK)
+	assert.Error(t, err)
+}
+
+func TestDocument_Validate(t *testing.T) {
+	doc, err := NewBuilder(Info{Title: "test", Version: "1"}).
+		Add(Operation{Method: http.MethodGet, Path: "/items", Response: envelope{}}).
+		Build()
+	require.NoError(t, err)
+	// Validated as served, through JSON
+	data, err := json.Marshal(doc)
+	require.NoError(t, err)
+	doc = &Document{}
+	require.NoError(t, json.Unmarshal(data, doc))
+	schema, err := doc.ResponseSchema(http.MethodGet, "/items", http.StatusOK)
+	require.NoError(t, err)
+
+	decode := func(body string) any {
+		var value any
+		require.NoError(t, json.Unmarshal([]byte(body), &value))
+		return value
+	}
+	valid := `{"data": {"items": [{"id": "a", "name": "A", "created": "2024-01-01T00:00:00Z", "count": "2", "labels": {"team": "a"}, "raw": [1]}]}}`
+	assert.Empty(t, doc.Validate(schema, decode(valid)))
+	assert.Empty(t, doc.Validate(schema, decode(`{"data": {"items": null}}`)), "nil slices encode as null")
+
+	assert.Equal(t, []string{
+		`/data/items/0: missing required property "created"`,
+		`/data/items/0: missing required property "count"`,
+		`/data/items/0: undocumented property "extra"`,
+		`/data/items/0/labels/team: number, want string`,
+		`/data/items/0/name: null, want string`,
+	}, doc.Validate(schema, decode(`{"data": {"items": [{"id": "a", "name": null, "labels": {"team": 1}, "extra": true}]}}`)))
+	assert.Equal(t, []string{"/data: array, want object"}, doc.Validate(schema, decode(`{"data": []}`)))
+}
@@ -0,0 +1,48 @@
+package operations
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps the operations in the memory of the replica, so they are lost on restart
+// and only visible from the replica that started them.
+type MemoryStore struct {
+	mu         sync.Mutex
+	operations map[string]Operation
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{operations: map[string]Operation{}}
+}
+
+func (s *MemoryStore) Save(_ context.Context, op Operation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.operations[op.ID] = op
+	return nil
+}
+
+func (s *MemoryStore) Get(_ context.Context, id string) (Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	op, ok := s.operations[id]
+	if !ok {
+		return Operation{}, ErrNotFound
+	}
+	return op, nil
+}
+
+func (s *MemoryStore) DeleteCompleted(_ context.Context, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	deleted := 0
+	for id, op := range s.operations {
+		if op.CompletedAt != nil && op.CompletedAt.Before(before) {
+			delete(s.operations, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
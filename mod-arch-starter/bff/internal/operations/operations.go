@@ -0,0 +1,282 @@
+// Package operations tracks the long-running actions of the BFF, like provisioning a namespace
+// or registering a model: the request starting one returns its ID right away, the action runs
+// in the background reporting its progress, and clients poll the operation or follow it until
+// it completes. Operations are kept in a Store, in memory unless another one is plugged in.
+package operations
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// DefaultRetention is how long completed operations are kept for their clients to see.
+const DefaultRetention = time.Hour
+
+// Status is the state of an operation.
+type Status string
+
+const (
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// ErrNotFound is returned for an unknown, or pruned, operation.
+var ErrNotFound = errors.New("operations: operation not found")
+
+// Operation is the state of a long-running action.
+type Operation struct {
+	ID string `json:"id"`
+	// Type names the action, e.g. "namespace-provisioning".
+	Type   string `json:"type"`
+	Status Status `json:"status"`
+	// Progress is the completion percentage reported by the action, 100 once it succeeded.
+	Progress int `json:"progress"`
+	// Message describes the current step, e.g. "creating the RoleBindings".
+	Message string `json:"message,omitempty"`
+	// Result is the JSON value returned by the action once it succeeded, e.g. the created object.
+	Result json.RawMessage `json:"result,omitempty"`
+	// Error is the message of the failure of the action.
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+	// Owner identifies the user who started the operation; only they can see it.
+	Owner string `json:"-"`
+}
+
+// Done reports whether the operation completed, successfully or not.
+func (o Operation) Done() bool {
+	return o.Status == StatusSucceeded || o.Status == StatusFailed
+}
+
+// Store persists the operations, e.g. in a database so they survive restarts and are visible
+// from every replica.
+type Store interface {
+	// Save creates or replaces the operation with the ID of op.
+	Save(ctx context.Context, op Operation) error
+	// Get returns the operation id, or ErrNotFound.
+	Get(ctx context.Context, id string) (Operation, error)
+	// DeleteCompleted deletes the operations completed before, and returns how many it deleted.
+	DeleteCompleted(ctx context.Context, before time.Time) (int, error)
+}
+
+// Func is a long-running action. It reports its progress on progress, and returns its result,
+// encoded as JSON in the operation, or its error.
+type Func func(ctx context.Context, progress *Progress) (result any, err error)
+
+// Progress reports the progress of a running operation.
+type Progress struct {
+	tracker *Tracker
+	id      string
+}
+
+// Report records that the operation is percent complete, at the step described by message.
+func (p *Progress) Report(ctx context.Context, percent int, message string) {
+	p.tracker.update(ctx, p.id, func(op *Operation) {
+		op.Progress = min(max(percent, 0), 99)
+		op.Message = message
+	})
+}
+
+// Tracker runs the operations and keeps their state in its Store.
+type Tracker struct {
+	store  Store
+	logger *slog.Logger
+	now    func() time.Time
+
+	// ctx is the parent of the contexts of the runs, canceled by Stop.
+	ctx     context.Context
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+
+	mu       sync.Mutex
+	watchers map[string]map[chan Operation]struct{}
+
+	onCompleted func(op Operation)
+}
+
+func NewTracker(store Store, logger *slog.Logger) *Tracker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Tracker{
+		store:    store,
+		logger:   logger,
+		now:      time.Now,
+		ctx:      ctx,
+		cancel:   cancel,
+		watchers: map[string]map[chan Operation]struct{}{},
+	}
+}
+
+// OnCompleted sets fn to be called with every operation once it completed, successfully or
+// not, e.g. to notify webhooks. Set it before starting operations.
+func (t *Tracker) OnCompleted(fn func(op Operation)) {
+	t.onCompleted = fn
+}
+
+// Start records a running operation of opType for owner, runs fn in the background and returns
+// the operation right away. The context of fn keeps the values of ctx, like the request ID, but
+// not its cancellation: it is only canceled by Stop.
+func (t *Tracker) Start(ctx context.Context, owner, opType string, fn Func) (Operation, error) {
+	if t.ctx.Err() != nil {
+		return Operation{}, errors.New("operations: the tracker is stopped")
+	}
+	now := t.now()
+	op := Operation{
+		ID:        newID(),
+		Type:      opType,
+		Status:    StatusRunning,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Owner:     owner,
+	}
+	if err := t.store.Save(ctx, op); err != nil {
+		return Operation{}, fmt.Errorf("operations: failed to save operation: %w", err)
+	}
+
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(t.ctx, cancel)
+	t.running.Add(1)
+	go func() {
+		defer t.running.Done()
+		defer cancel()
+		defer stop()
+		t.run(runCtx, op, fn)
+	}()
+	return op, nil
+}
+
+// run runs fn and records its outcome.
+func (t *Tracker) run(ctx context.Context, op Operation, fn Func) {
+	logger := t.logger.With("operation", op.ID, "type", op.Type)
+	result, err := func() (result any, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = fmt.Errorf("panic: %v", p)
+				logger.Error("operation panicked", "panic", p, "stack", string(debug.Stack()))
+			}
+		}()
+		return fn(ctx, &Progress{tracker: t, id: op.ID})
+	}()
+
+	var encoded json.RawMessage
+	if err == nil && result != nil {
+		if encoded, err = json.Marshal(result); err != nil {
+			err = fmt.Errorf("failed to encode the result: %w", err)
+		}
+	}
+	if err != nil && t.ctx.Err() != nil {
+		err = fmt.Errorf("interrupted by the shutdown of the BFF: %w", err)
+	}
+
+	// The context of the run may be canceled by now; the outcome is still recorded
+	completed, saved := t.update(context.WithoutCancel(ctx), op.ID, func(op *Operation) {
+		completed := t.now()
+		op.CompletedAt = &completed
+		if err != nil {
+			op.Status, op.Error = StatusFailed, err.Error()
+			return
+		}
+		op.Status, op.Progress, op.Message, op.Result = StatusSucceeded, 100, "", encoded
+	})
+	if err != nil {
+		logger.Warn("operation failed", "error", err)
+	} else {
+		logger.Debug("operation succeeded")
+	}
+	if saved && t.onCompleted != nil {
+		t.onCompleted(completed)
+	}
+}
+
+// update applies change to the stored operation id, sends the result to its watchers and
+// returns it, with false when it was not saved. Completed operations don't change anymore.
+func (t *Tracker) update(ctx context.Context, id string, change func(op *Operation)) (Operation, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	op, err := t.store.Get(ctx, id)
+	if err != nil {
+		t.logger.Warn("failed to update operation", "operation", id, "error", err)
+		return op, false
+	}
+	if op.Done() {
+		return op, false
+	}
+	change(&op)
+	op.UpdatedAt = t.now()
+	if err := t.store.Save(ctx, op); err != nil {
+		t.logger.Warn("failed to update operation", "operation", id, "error", err)
+		return op, false
+	}
+	for ch := range t.watchers[id] {
+		// Watchers only need the latest state: replace the one not received yet
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- op
+	}
+	return op, true
+}
+
+// Get returns the operation id.
+func (t *Tracker) Get(ctx context.Context, id string) (Operation, error) {
+	return t.store.Get(ctx, id)
+}
+
+// Watch returns a channel receiving the state of the operation id whenever it changes, replacing
+// the state not received yet, until stop is called. Get the operation after watching it, so no
+// change is missed in between.
+func (t *Tracker) Watch(id string) (changes <-chan Operation, stop func()) {
+	ch := make(chan Operation, 1)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.watchers[id] == nil {
+		t.watchers[id] = map[chan Operation]struct{}{}
+	}
+	t.watchers[id][ch] = struct{}{}
+	return ch, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		delete(t.watchers[id], ch)
+		if len(t.watchers[id]) == 0 {
+			delete(t.watchers, id)
+		}
+	}
+}
+
+// Prune deletes the operations completed more than retention ago.
+func (t *Tracker) Prune(ctx context.Context, retention time.Duration) (int, error) {
+	return t.store.DeleteCompleted(ctx, t.now().Add(-retention))
+}
+
+// Stop cancels the running operations, which fail, and waits for them to return or for ctx to
+// be done. Operations can't be started afterwards.
+func (t *Tracker) Stop(ctx context.Context) error {
+	t.cancel()
+	done := make(chan struct{})
+	go func() {
+		t.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("operations: operations still running on stop: %w", ctx.Err())
+	}
+}
+
+func newID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,132 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTracker() *Tracker {
+	return NewTracker(NewMemoryStore(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// waitDone follows the operation id until it completes.
+func waitDone(t *testing.T, tracker *Tracker, id string) Operation {
+	t.Helper()
+	changes, stop := tracker.Watch(id)
+	defer stop()
+	op, err := tracker.Get(context.Background(), id)
+	require.NoError(t, err)
+	for !op.Done() {
+		select {
+		case op = <-changes:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("operation %s did not complete", id)
+		}
+	}
+	return op
+}
+
+func TestTracker(t *testing.T) {
+	tracker := newTestTracker()
+	completions := make(chan Operation, 1)
+	tracker.OnCompleted(func(op Operation) { completions <- op })
+	type key struct{}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "request"))
+	step := make(chan struct{})
+	op, err := tracker.Start(ctx, "user:dora", "namespace-provisioning", func(ctx context.Context, progress *Progress) (any, error) {
+		assert.Equal(t, "request", ctx.Value(key{}), "keeps the values of the request")
+		progress.Report(ctx, 50, "creating the RoleBindings")
+		<-step
+		return map[string]string{"namespace": "dora"}, ctx.Err()
+	})
+	require.NoError(t, err)
+	cancel()
+	assert.NotEmpty(t, op.ID)
+	assert.Equal(t, StatusRunning, op.Status)
+	assert.Equal(t, "user:dora", op.Owner)
+
+	changes, stop := tracker.Watch(op.ID)
+	defer stop()
+	assert.Eventually(t, func() bool {
+		current, err := tracker.Get(context.Background(), op.ID)
+		return err == nil && current.Progress == 50
+	}, 5*time.Second, time.Millisecond)
+	current, _ := tracker.Get(context.Background(), op.ID)
+	assert.Equal(t, "creating the RoleBindings", current.Message)
+
+	close(step)
+	done := waitDone(t, tracker, op.ID)
+	assert.Equal(t, StatusSucceeded, done.Status, "canceling the request doesn't cancel the operation")
+	assert.Equal(t, 100, done.Progress)
+	assert.JSONEq(t, `{"namespace":"dora"}`, string(done.Result))
+	require.NotNil(t, done.CompletedAt)
+	assert.Eventually(t, func() bool {
+		select {
+		case last := <-changes:
+			return last.Done()
+		default:
+			return false
+		}
+	}, 5*time.Second, time.Millisecond, "watchers get the completion")
+	completed := <-completions
+	assert.Equal(t, StatusSucceeded, completed.Status)
+	assert.Equal(t, "user:dora", completed.Owner)
+
+	_, err = tracker.Get(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestTracker_Failures(t *testing.T) {
+	tracker := newTestTracker()
+	failed, err := tracker.Start(context.Background(), "", "import", func(context.Context, *Progress) (any, error) {
+		return nil, errors.New("quota exceeded")
+	})
+	require.NoError(t, err)
+	panicked, err := tracker.Start(context.Background(), "", "import", func(context.Context, *Progress) (any, error) {
+		panic("boom")
+	})
+	require.NoError(t, err)
+
+	op := waitDone(t, tracker, failed.ID)
+	assert.Equal(t, StatusFailed, op.Status)
+	assert.Equal(t, "quota exceeded", op.Error)
+	op = waitDone(t, tracker, panicked.ID)
+	assert.Equal(t, StatusFailed, op.Status)
+	assert.Contains(t, op.Error, "panic: boom")
+}
+
+func TestTracker_PruneAndStop(t *testing.T) {
+	tracker := newTestTracker()
+	quick, err := tracker.Start(context.Background(), "", "quick", func(context.Context, *Progress) (any, error) { return nil, nil })
+	require.NoError(t, err)
+	waitDone(t, tracker, quick.ID)
+	slow, err := tracker.Start(context.Background(), "", "slow", func(ctx context.Context, _ *Progress) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	require.NoError(t, err)
+
+	pruned, err := tracker.Prune(context.Background(), time.Hour)
+	require.NoError(t, err)
+	assert.Zero(t, pruned, "within the retention")
+	pruned, err = tracker.Prune(context.Background(), -time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, 1, pruned, "only the completed operations")
+	_, err = tracker.Get(context.Background(), quick.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, tracker.Stop(context.Background()))
+	op, err := tracker.Get(context.Background(), slow.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, op.Status)
+	assert.Contains(t, op.Error, "shutdown")
+	_, err = tracker.Start(context.Background(), "", "late", func(context.Context, *Progress) (any, error) { return nil, nil })
+	assert.Error(t, err, "stopped")
+}
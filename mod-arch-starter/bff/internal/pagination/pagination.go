@@ -0,0 +1,229 @@
+// Package pagination implements the list query parameters shared by the BFF listings: page,
+// pageSize, orderBy, filter and nextPageToken. Listings held in memory are sorted, filtered
+// and paged with Apply; Kubernetes listings map the options to the server-side limit and
+// continue token with KubernetesListOptions.
+package pagination
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MaxPageSize bounds ListOptions.PageSize.
+const MaxPageSize = 1000
+
+// ListOptions selects a page of a listing. Zero values return every item in their natural order.
+type ListOptions struct {
+	// Page is the 1-based page number, or 0 to start from NextPageToken or the first page.
+	Page int
+	// PageSize is the number of items per page, or 0 for a single page of every item.
+	PageSize int
+	// OrderBy is the field to sort by, in reverse order when Descending ("-name").
+	OrderBy    string
+	Descending bool
+	// Filter holds the comma-separated terms of the filter parameter: "field:value" matches
+	// items whose field contains value, a bare "value" items with any field containing it.
+	Filter []FilterTerm
+	// NextPageToken is the token of the page to return, from the previous page.
+	NextPageToken string
+}
+
+// FilterTerm is a term of the filter parameter; Field is empty for a bare value.
+type FilterTerm struct {
+	Field string
+	Value string
+}
+
+// Metadata describes the page of a listing, returned in the metadata of PagedResponse.
+type Metadata struct {
+	// TotalCount is the number of items of the listing across pages, when it is known.
+	TotalCount *int `json:"totalCount,omitempty"`
+	// Page is the number of the page, for listings paged by number.
+	Page     int `json:"page,omitempty"`
+	PageSize int `json:"pageSize,omitempty"`
+	// NextPageToken returns the next page when passed as nextPageToken; it is empty on the last page.
+	NextPageToken string `json:"nextPageToken,omitempty"`
+}
+
+// ParseListOptions reads the page, pageSize, orderBy, filter and nextPageToken query parameters
+// of a BFF request. Field names are checked later, against the fields of the listing.
+func ParseListOptions(query url.Values) (ListOptions, error) {
+	opts := ListOptions{NextPageToken: query.Get("nextPageToken")}
+
+	if value := query.Get("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil || page < 1 {
+			return ListOptions{}, fmt.Errorf("invalid page %q: must be a positive integer", value)
+		}
+		opts.Page = page
+	}
+	if value := query.Get("pageSize"); value != "" {
+		pageSize, err := strconv.Atoi(value)
+		if err != nil || pageSize < 1 || pageSize > MaxPageSize {
+			return ListOptions{}, fmt.Errorf("invalid pageSize %q: must be between 1 and %d", value, MaxPageSize)
+		}
+		opts.PageSize = pageSize
+	}
+	if opts.Page > 0 && opts.PageSize == 0 {
+		return ListOptions{}, fmt.Errorf("page requires pageSize")
+	}
+	if opts.Page > 0 && opts.NextPageToken != "" {
+		return ListOptions{}, fmt.Errorf("page and nextPageToken are mutually exclusive")
+	}
+
+	if value := query.Get("orderBy"); value != "" {
+		opts.OrderBy, opts.Descending = strings.CutPrefix(value, "-")
+		if opts.OrderBy == "" {
+			return ListOptions{}, fmt.Errorf("invalid orderBy %q: must be a field name, prefixed with - for descending order", value)
+		}
+	}
+
+	if value := query.Get("filter"); value != "" {
+		for _, term := range strings.Split(value, ",") {
+			term = strings.TrimSpace(term)
+			if term == "" {
+				continue
+			}
+			field, match, ok := strings.Cut(term, ":")
+			if !ok {
+				field, match = "", term
+			}
+			if ok && (field == "" || match == "") {
+				return ListOptions{}, fmt.Errorf("invalid filter term %q: must be field:value or value", term)
+			}
+			opts.Filter = append(opts.Filter, FilterTerm{Field: field, Value: match})
+		}
+	}
+
+	return opts, nil
+}
+
+// Fields names the fields of T that a listing can be sorted and filtered by. Values compare as
+// strings, case-insensitively for filters.
+type Fields[T any] map[string]func(T) string
+
+// Apply filters, sorts and pages items, which hold the whole listing. Its page tokens are page
+// numbers, so clients can either follow NextPageToken or ask for a page. Errors are caused by
+// the options, e.g. an unknown field, and are meant for a 400 response.
+func Apply[T any](items []T, opts ListOptions, fields Fields[T]) ([]T, Metadata, error) {
+	for _, term := range opts.Filter {
+		if _, ok := fields[term.Field]; term.Field != "" && !ok {
+			return nil, Metadata{}, fmt.Errorf("invalid filter field %q: must be one of %s", term.Field, fields.names())
+		}
+	}
+	var orderBy func(T) string
+	if opts.OrderBy != "" {
+		var ok bool
+		if orderBy, ok = fields[opts.OrderBy]; !ok {
+			return nil, Metadata{}, fmt.Errorf("invalid orderBy field %q: must be one of %s", opts.OrderBy, fields.names())
+		}
+	}
+	page := opts.Page
+	if opts.NextPageToken != "" {
+		var err error
+		if page, err = strconv.Atoi(opts.NextPageToken); err != nil || page < 1 {
+			return nil, Metadata{}, fmt.Errorf("invalid nextPageToken %q", opts.NextPageToken)
+		}
+	}
+
+	result := make([]T, 0, len(items))
+	for _, item := range items {
+		if fields.match(item, opts.Filter) {
+			result = append(result, item)
+		}
+	}
+	if orderBy != nil {
+		slices.SortStableFunc(result, func(a, b T) int {
+			if opts.Descending {
+				return cmp.Compare(orderBy(b), orderBy(a))
+			}
+			return cmp.Compare(orderBy(a), orderBy(b))
+		})
+	}
+
+	total := len(result)
+	metadata := Metadata{TotalCount: &total}
+	if opts.PageSize == 0 {
+		return result, metadata, nil
+	}
+
+	page = max(page, 1)
+	metadata.Page, metadata.PageSize = page, opts.PageSize
+	start := min((page-1)*opts.PageSize, total)
+	end := min(start+opts.PageSize, total)
+	if end < total {
+		metadata.NextPageToken = strconv.Itoa(page + 1)
+	}
+	return result[start:end], metadata, nil
+}
+
+func (f Fields[T]) match(item T, terms []FilterTerm) bool {
+	for _, term := range terms {
+		value := strings.ToLower(term.Value)
+		if term.Field != "" {
+			if !strings.Contains(strings.ToLower(f[term.Field](item)), value) {
+				return false
+			}
+			continue
+		}
+		found := false
+		for _, field := range f {
+			if strings.Contains(strings.ToLower(field(item)), value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (f Fields[T]) names() string {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return strings.Join(names, ", ")
+}
+
+// KubernetesListOptions maps the options to the server-side pagination of a Kubernetes list:
+// PageSize is the limit and NextPageToken the continue token. Kubernetes neither sorts, pages
+// by number nor filters by these terms, so Page, OrderBy and Filter are rejected; use label
+// and field selectors instead.
+func (o ListOptions) KubernetesListOptions() (metav1.ListOptions, error) {
+	switch {
+	case o.Page > 0:
+		return metav1.ListOptions{}, fmt.Errorf("page is not supported by this listing, use nextPageToken")
+	case o.OrderBy != "":
+		return metav1.ListOptions{}, fmt.Errorf("orderBy is not supported by this listing")
+	case len(o.Filter) > 0:
+		return metav1.ListOptions{}, fmt.Errorf("filter is not supported by this listing")
+	}
+	return metav1.ListOptions{Limit: int64(o.PageSize), Continue: o.NextPageToken}, nil
+}
+
+// KubernetesMetadata returns the metadata of a page of count items of the Kubernetes list
+// returned for opts. The total count is only known on the first page, from the remaining item
+// count the API server estimates.
+func KubernetesMetadata(list metav1.ListInterface, count int, opts ListOptions) Metadata {
+	metadata := Metadata{PageSize: opts.PageSize, NextPageToken: list.GetContinue()}
+	if opts.NextPageToken == "" {
+		switch remaining := list.GetRemainingItemCount(); {
+		case metadata.NextPageToken == "":
+			metadata.TotalCount = &count
+		case remaining != nil:
+			total := count + int(*remaining)
+			metadata.TotalCount = &total
+		}
+	}
+	return metadata
+}
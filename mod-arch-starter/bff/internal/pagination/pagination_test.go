@@ -0,0 +1,129 @@
+package pagination
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type item struct {
+	Name  string
+	Owner string
+}
+
+var fields = Fields[item]{
+	"name":  func(i item) string { return i.Name },
+	"owner": func(i item) string { return i.Owner },
+}
+
+var items = []item{{"beta", "alice"}, {"alpha", "bob"}, {"delta", "alice"}, {"gamma", "Bob"}, {"epsilon", "carol"}}
+
+func parse(t *testing.T, query string) ListOptions {
+	t.Helper()
+	values, err := url.ParseQuery(query)
+	require.NoError(t, err)
+	opts, err := ParseListOptions(values)
+	require.NoError(t, err)
+	return opts
+}
+
+func names(items []item) []string {
+	result := []string{}
+	for _, i := range items {
+		result = append(result, i.Name)
+	}
+	return result
+}
+
+func TestParseListOptions(t *testing.T) {
+	assert.Equal(t, ListOptions{
+		Page:       2,
+		PageSize:   10,
+		OrderBy:    "name",
+		Descending: true,
+		Filter:     []FilterTerm{{Field: "owner", Value: "bob"}, {Value: "a"}},
+	}, parse(t, "page=2&pageSize=10&orderBy=-name&filter=owner:bob,+a,"))
+	assert.Equal(t, ListOptions{NextPageToken: "abc"}, parse(t, "nextPageToken=abc"))
+
+	for _, query := range []string{
+		"page=0&pageSize=1", "page=x&pageSize=1", "pageSize=0", "pageSize=1001", "page=2",
+		"page=2&pageSize=1&nextPageToken=abc", "orderBy=-", "filter=owner:", "filter=:bob",
+	} {
+		values, _ := url.ParseQuery(query)
+		_, err := ParseListOptions(values)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestApply(t *testing.T) {
+	page, metadata, err := Apply(items, ListOptions{}, fields)
+	require.NoError(t, err)
+	assert.Equal(t, names(items), names(page), "no options keep the order")
+	assert.Equal(t, 5, *metadata.TotalCount)
+	assert.Zero(t, metadata.PageSize)
+
+	page, metadata, err = Apply(items, parse(t, "orderBy=name&pageSize=2"), fields)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alpha", "beta"}, names(page))
+	assert.Equal(t, Metadata{TotalCount: metadata.TotalCount, Page: 1, PageSize: 2, NextPageToken: "2"}, metadata)
+
+	page, metadata, err = Apply(items, parse(t, "orderBy=name&pageSize=2&nextPageToken="+metadata.NextPageToken), fields)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"delta", "epsilon"}, names(page))
+	assert.Equal(t, 2, metadata.Page)
+
+	page, metadata, err = Apply(items, parse(t, "orderBy=name&pageSize=2&page=3"), fields)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gamma"}, names(page))
+	assert.Empty(t, metadata.NextPageToken, "last page")
+
+	page, _, err = Apply(items, parse(t, "orderBy=name&pageSize=2&page=9"), fields)
+	require.NoError(t, err)
+	assert.NotNil(t, page)
+	assert.Empty(t, page, "past the last page")
+
+	page, metadata, err = Apply(items, parse(t, "orderBy=-name&filter=owner:BOB"), fields)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gamma", "alpha"}, names(page), "filters ignore case")
+	assert.Equal(t, 2, *metadata.TotalCount, "the total counts filtered items")
+
+	page, _, err = Apply(items, parse(t, "filter=ta,owner:alice"), fields)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"beta", "delta"}, names(page), "bare values match any field, terms all match")
+
+	for _, query := range []string{"orderBy=size", "filter=size:1", "nextPageToken=abc"} {
+		_, _, err = Apply(items, parse(t, query), fields)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestKubernetesListOptions(t *testing.T) {
+	opts, err := parse(t, "pageSize=50&nextPageToken=token").KubernetesListOptions()
+	require.NoError(t, err)
+	assert.Equal(t, metav1.ListOptions{Limit: 50, Continue: "token"}, opts)
+
+	for _, query := range []string{"page=1&pageSize=1", "orderBy=name", "filter=a"} {
+		_, err := parse(t, query).KubernetesListOptions()
+		assert.Error(t, err, query)
+	}
+}
+
+func TestKubernetesMetadata(t *testing.T) {
+	remaining := int64(8)
+	first := &metav1.List{ListMeta: metav1.ListMeta{Continue: "next", RemainingItemCount: &remaining}}
+	assert.Equal(t, Metadata{TotalCount: ptr(10), PageSize: 2, NextPageToken: "next"},
+		KubernetesMetadata(first, 2, ListOptions{PageSize: 2}))
+
+	assert.Equal(t, Metadata{PageSize: 2, NextPageToken: "next"},
+		KubernetesMetadata(first, 2, ListOptions{PageSize: 2, NextPageToken: "previous"}),
+		"the total is unknown after the first page")
+
+	assert.Equal(t, Metadata{TotalCount: ptr(3)}, KubernetesMetadata(&metav1.List{}, 3, ListOptions{}))
+}
+
+func ptr(i int) *int {
+	return &i
+}
@@ -0,0 +1,178 @@
+// Package panicreport captures the panics recovered by the BFF and forwards them to an error
+// tracker speaking the Sentry envelope protocol (Sentry, GlitchTip, ...), in the background
+// so that a slow or unreachable tracker never delays the 500 answered to the client.
+package panicreport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultQueueSize bounds the reports waiting to be sent.
+const DefaultQueueSize = 100
+
+// maxFrames bounds the stack frames captured by a report.
+const maxFrames = 64
+
+// Report is a recovered panic and the request it interrupted.
+type Report struct {
+	Time time.Time
+	// Message is the panic value, formatted with %v.
+	Message string
+	// Stack is the goroutine stack trace, as logged.
+	Stack string
+	// Frames are the stack frames of the panic, innermost first.
+	Frames []Frame
+
+	RequestID string
+	Method    string
+	// Path is the request path, without the query which may carry credentials.
+	Path string
+	// Route is the route pattern of the request, when known.
+	Route string
+}
+
+// Frame is a stack frame of a Report.
+type Frame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+// Capture returns the report of the panic value. Call it from the deferred function that
+// recovered the panic, so the stack is still the one of the panic.
+func Capture(value any, r *http.Request) Report {
+	report := Report{
+		Time:    time.Now(),
+		Message: fmt.Sprintf("%v", value),
+		Stack:   string(debug.Stack()),
+		Frames:  callers(),
+	}
+	if r != nil {
+		report.Method = r.Method
+		report.Path = r.URL.Path
+	}
+	return report
+}
+
+// callers returns the frames of the panicking goroutine from the panic site (the frames
+// below runtime.gopanic), without the runtime ones.
+func callers() []Frame {
+	pcs := make([]uintptr, maxFrames)
+	n := runtime.Callers(1, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var result []Frame
+	for {
+		frame, more := frames.Next()
+		switch {
+		case frame.Function == "runtime.gopanic":
+			// Drop the recovering frames
+			result = result[:0]
+		case !strings.HasPrefix(frame.Function, "runtime."):
+			result = append(result, Frame{Function: frame.Function, File: frame.File, Line: frame.Line})
+		}
+		if !more {
+			return result
+		}
+	}
+}
+
+// Sender delivers a report, e.g. a SentrySender.
+type Sender interface {
+	Send(ctx context.Context, report Report) error
+}
+
+// Options configures New. Zero values use the defaults.
+type Options struct {
+	Sender Sender
+	// QueueSize defaults to DefaultQueueSize. Reports are dropped, and logged, when it is full.
+	QueueSize int
+	Logger    *slog.Logger
+}
+
+// Reporter sends reports with its Sender in the background. A nil *Reporter is valid and
+// reports nothing.
+type Reporter struct {
+	sender Sender
+	logger *slog.Logger
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan Report
+	done   chan struct{}
+}
+
+// New starts a Reporter; Close stops it.
+func New(opts Options) *Reporter {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultQueueSize
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	r := &Reporter{
+		sender: opts.Sender,
+		logger: opts.Logger,
+		queue:  make(chan Report, opts.QueueSize),
+		done:   make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+// Report queues report for the Sender without blocking.
+func (r *Reporter) Report(report Report) {
+	if r == nil {
+		return
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return
+	}
+	select {
+	case r.queue <- report:
+	default:
+		r.logger.Error("panic report queue full, dropping report", "request_id", report.RequestID)
+	}
+}
+
+// Close stops accepting reports and waits until the queued ones are sent, or ctx is done.
+func (r *Reporter) Close(ctx context.Context) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.queue)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return errors.Join(errors.New("panic reports left unsent"), ctx.Err())
+	}
+}
+
+func (r *Reporter) run() {
+	defer close(r.done)
+	for report := range r.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if err := r.sender.Send(ctx, report); err != nil {
+			r.logger.Error("failed to send panic report", "request_id", report.RequestID, "error", err)
+		}
+		cancel()
+	}
+}
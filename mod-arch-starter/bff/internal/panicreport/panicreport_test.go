@@ -0,0 +1,140 @@
+package panicreport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func capturePanic(r *http.Request) (report Report) {
+	defer func() {
+		report = Capture(recover(), r)
+	}()
+	panicSite()
+	return
+}
+
+func panicSite() {
+	panic(errors.New("boom"))
+}
+
+func TestCapture(t *testing.T) {
+	report := capturePanic(httptest.NewRequest(http.MethodGet, "/api/v1/user?token=s3cr3t", nil))
+
+	assert.Equal(t, "boom", report.Message)
+	assert.Equal(t, http.MethodGet, report.Method)
+	assert.Equal(t, "/api/v1/user", report.Path)
+	assert.Contains(t, report.Stack, "panicSite")
+	require.NotEmpty(t, report.Frames)
+	assert.True(t, strings.HasSuffix(report.Frames[0].Function, ".panicSite"), "innermost first, from the panic site: %v", report.Frames[0])
+	for _, frame := range report.Frames {
+		assert.False(t, strings.HasPrefix(frame.Function, "runtime."), frame.Function)
+	}
+}
+
+func TestParseDSN(t *testing.T) {
+	endpoint, key, err := ParseDSN("https://public@sentry.example.com/42")
+	require.NoError(t, err)
+	assert.Equal(t, "https://sentry.example.com/api/42/envelope/", endpoint)
+	assert.Equal(t, "public", key)
+
+	endpoint, _, err = ParseDSN("http://public@glitchtip.example.com:8000/tracker/7/")
+	require.NoError(t, err)
+	assert.Equal(t, "http://glitchtip.example.com:8000/tracker/api/7/envelope/", endpoint)
+
+	for _, dsn := range []string{"", "sentry.example.com/42", "ftp://public@sentry.example.com/42", "https://sentry.example.com/42", "https://public@sentry.example.com", "https://public@sentry.example.com/"} {
+		_, _, err := ParseDSN(dsn)
+		assert.Error(t, err, dsn)
+	}
+}
+
+func TestSentrySender(t *testing.T) {
+	var auth string
+	var lines []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/42/envelope/", r.URL.Path)
+		assert.Equal(t, "application/x-sentry-envelope", r.Header.Get("Content-Type"))
+		auth = r.Header.Get("X-Sentry-Auth")
+		body, _ := io.ReadAll(r.Body)
+		for _, line := range bytes.Split(bytes.TrimSpace(body), []byte("\n")) {
+			var doc map[string]any
+			require.NoError(t, json.Unmarshal(line, &doc))
+			lines = append(lines, doc)
+		}
+	}))
+	defer server.Close()
+
+	sender, err := NewSentrySender(strings.Replace(server.URL, "://", "://public@", 1) + "/42")
+	require.NoError(t, err)
+	sender.Release = "1.0.0"
+	report := Report{
+		Time:      time.Now(),
+		Message:   "boom",
+		Frames:    []Frame{{Function: "pkg.inner", File: "inner.go", Line: 1}, {Function: "pkg.outer", File: "outer.go", Line: 2}},
+		RequestID: "req-123",
+		Method:    http.MethodGet,
+		Path:      "/api/v1/namespaces/team-a",
+		Route:     "/api/v1/namespaces/:namespace",
+	}
+	require.NoError(t, sender.Send(context.Background(), report))
+
+	assert.Equal(t, "Sentry sentry_version=7, sentry_client=mod-arch-bff, sentry_key=public", auth)
+	require.Len(t, lines, 3)
+	assert.Equal(t, lines[0]["event_id"], lines[2]["event_id"])
+	assert.Equal(t, "event", lines[1]["type"])
+
+	event := lines[2]
+	assert.Equal(t, "fatal", event["level"])
+	assert.Equal(t, "1.0.0", event["release"])
+	assert.Equal(t, "GET /api/v1/namespaces/:namespace", event["transaction"])
+	assert.Equal(t, map[string]any{"route": report.Route, "request_id": "req-123"}, event["tags"])
+	exception := event["exception"].(map[string]any)["values"].([]any)[0].(map[string]any)
+	assert.Equal(t, "boom", exception["value"])
+	frames := exception["stacktrace"].(map[string]any)["frames"].([]any)
+	assert.Equal(t, "pkg.outer", frames[0].(map[string]any)["function"], "outermost first")
+}
+
+func TestSentrySender_RejectedReport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	sender, err := NewSentrySender(strings.Replace(server.URL, "://", "://public@", 1) + "/42")
+	require.NoError(t, err)
+	assert.ErrorContains(t, sender.Send(context.Background(), Report{Message: "boom"}), "429")
+}
+
+type recordingSender struct{ reports chan Report }
+
+func (s *recordingSender) Send(_ context.Context, report Report) error {
+	s.reports <- report
+	return nil
+}
+
+func TestReporter(t *testing.T) {
+	sender := &recordingSender{reports: make(chan Report, 2)}
+	reporter := New(Options{Sender: sender, Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	reporter.Report(Report{RequestID: "req-1"})
+	require.NoError(t, reporter.Close(context.Background()))
+	assert.Equal(t, "req-1", (<-sender.reports).RequestID)
+
+	// Reports after Close are dropped
+	reporter.Report(Report{RequestID: "req-2"})
+	assert.Empty(t, sender.reports)
+
+	var none *Reporter
+	none.Report(Report{})
+	assert.NoError(t, none.Close(context.Background()))
+}
@@ -0,0 +1,171 @@
+package panicreport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// sentryClient names the BFF in the X-Sentry-Auth header.
+const sentryClient = "mod-arch-bff"
+
+// SentrySender sends reports as events of the Sentry envelope protocol, to the project of a DSN
+// ("https://<public key>@<host>[/<path>]/<project id>"). The events carry the request method,
+// path, ID and route, never its headers, query or body.
+type SentrySender struct {
+	// Release and Environment are attached to every event, when set.
+	Release     string
+	Environment string
+	// Client defaults to an http.Client with a 10s timeout.
+	Client *http.Client
+
+	dsn      string
+	endpoint string
+	key      string
+}
+
+// NewSentrySender returns the sender of dsn; it fails on an invalid DSN.
+func NewSentrySender(dsn string) (*SentrySender, error) {
+	endpoint, key, err := ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &SentrySender{dsn: dsn, endpoint: endpoint, key: key}, nil
+}
+
+// ParseDSN returns the envelope endpoint and the public key of a Sentry DSN.
+func ParseDSN(dsn string) (endpoint, key string, err error) {
+	u, err := url.Parse(dsn)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", "", fmt.Errorf("invalid DSN: must be an http(s) URL")
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return "", "", fmt.Errorf("invalid DSN: missing the public key")
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	i := strings.LastIndex(path, "/")
+	prefix, project := path[:max(i, 0)], path[i+1:]
+	if i < 0 || project == "" {
+		return "", "", fmt.Errorf("invalid DSN: missing the project ID")
+	}
+	return fmt.Sprintf("%s://%s%s/api/%s/envelope/", u.Scheme, u.Host, prefix, project), u.User.Username(), nil
+}
+
+func (s *SentrySender) Send(ctx context.Context, report Report) error {
+	body, err := s.envelope(report)
+	if err != nil {
+		return fmt.Errorf("error encoding the panic report: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating the panic report request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", fmt.Sprintf("Sentry sentry_version=7, sentry_client=%s, sentry_key=%s", sentryClient, s.key))
+
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending the panic report: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("error tracker responded %s", resp.Status)
+	}
+	return nil
+}
+
+type sentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Platform    string            `json:"platform"`
+	Level       string            `json:"level"`
+	Logger      string            `json:"logger"`
+	Release     string            `json:"release,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	Transaction string            `json:"transaction,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Request     *sentryRequest    `json:"request,omitempty"`
+	Exception   struct {
+		Values []sentryException `json:"values"`
+	} `json:"exception"`
+}
+
+type sentryRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+}
+
+type sentryException struct {
+	Type       string `json:"type"`
+	Value      string `json:"value"`
+	Mechanism  any    `json:"mechanism"`
+	Stacktrace struct {
+		Frames []sentryFrame `json:"frames"`
+	} `json:"stacktrace"`
+}
+
+type sentryFrame struct {
+	Function string `json:"function"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+}
+
+// envelope encodes report as an envelope of a single event: the envelope header, the item
+// header and the event, one JSON document per line.
+func (s *SentrySender) envelope(report Report) ([]byte, error) {
+	event := sentryEvent{
+		EventID:     strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Timestamp:   report.Time.UTC().Format(time.RFC3339Nano),
+		Platform:    "go",
+		Level:       "fatal",
+		Logger:      sentryClient,
+		Release:     s.Release,
+		Environment: s.Environment,
+		Tags:        map[string]string{},
+	}
+	if report.Method != "" {
+		event.Request = &sentryRequest{Method: report.Method, URL: report.Path}
+		event.Transaction = report.Method + " " + report.Path
+	}
+	if report.Route != "" {
+		event.Tags["route"] = report.Route
+		event.Transaction = report.Method + " " + report.Route
+	}
+	if report.RequestID != "" {
+		event.Tags["request_id"] = report.RequestID
+	}
+
+	exception := sentryException{Type: "panic", Value: report.Message, Mechanism: map[string]any{"type": "recover", "handled": false}}
+	// Sentry lists frames outermost first
+	exception.Stacktrace.Frames = make([]sentryFrame, len(report.Frames))
+	for i, frame := range report.Frames {
+		exception.Stacktrace.Frames[len(report.Frames)-1-i] = sentryFrame{Function: frame.Function, AbsPath: frame.File, Lineno: frame.Line}
+	}
+	event.Exception.Values = []sentryException{exception}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, line := range []any{
+		map[string]string{"event_id": event.EventID, "dsn": s.dsn, "sent_at": time.Now().UTC().Format(time.RFC3339Nano)},
+		map[string]string{"type": "event"},
+		event,
+	} {
+		if err := encoder.Encode(line); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,111 @@
+// Package parallel runs independent calls, e.g. to the Kubernetes API, concurrently: with a
+// bound on the calls running at once, an optional timeout per call, and the cancellation of
+// the remaining calls when one fails or the request context is done.
+package parallel
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultLimit bounds the calls running at once when Options.Limit is not set.
+const DefaultLimit = 10
+
+// Options configures a Group. Zero values use the defaults.
+type Options struct {
+	// Limit bounds the calls running at once (DefaultLimit); negative means unbounded.
+	Limit int
+	// Timeout bounds each call; zero means only the parent context applies.
+	Timeout time.Duration
+}
+
+// Group runs calls in parallel, like an errgroup.Group: the first error cancels the context
+// of the other calls and is returned by Wait. Calls started once the context is done are
+// skipped.
+type Group struct {
+	group   *errgroup.Group
+	ctx     context.Context
+	timeout time.Duration
+}
+
+// NewGroup returns a Group whose calls get a context derived from ctx.
+func NewGroup(ctx context.Context, opts Options) *Group {
+	group, ctx := errgroup.WithContext(ctx)
+	switch {
+	case opts.Limit == 0:
+		group.SetLimit(DefaultLimit)
+	case opts.Limit > 0:
+		group.SetLimit(opts.Limit)
+	}
+	return &Group{group: group, ctx: ctx, timeout: opts.Timeout}
+}
+
+// Go runs fn in a new goroutine, waiting first for a slot when the limit is reached.
+func (g *Group) Go(fn func(ctx context.Context) error) {
+	g.group.Go(func() error {
+		if err := g.ctx.Err(); err != nil {
+			return err
+		}
+		ctx := g.ctx
+		if g.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, g.timeout)
+			defer cancel()
+		}
+		return fn(ctx)
+	})
+}
+
+// Wait waits for every call and returns the first error.
+func (g *Group) Wait() error {
+	return g.group.Wait()
+}
+
+// Do runs fns in parallel and returns the first error.
+func Do(ctx context.Context, opts Options, fns ...func(ctx context.Context) error) error {
+	group := NewGroup(ctx, opts)
+	for _, fn := range fns {
+		group.Go(fn)
+	}
+	return group.Wait()
+}
+
+// Map calls fn for every item in parallel and returns the results in the order of items, or
+// the first error.
+func Map[T, R any](ctx context.Context, opts Options, items []T, fn func(ctx context.Context, item T) (R, error)) ([]R, error) {
+	results := make([]R, len(items))
+	group := NewGroup(ctx, opts)
+	for i, item := range items {
+		group.Go(func(ctx context.Context) error {
+			result, err := fn(ctx, item)
+			if err != nil {
+				return err
+			}
+			results[i] = result
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// ForEach calls fn for every index below n in parallel, for calls that handle their own
+// errors, e.g. by recording them in their result: one call never cancels the others. It
+// returns ctx.Err() when ctx is done before every call ran.
+func ForEach(ctx context.Context, opts Options, n int, fn func(ctx context.Context, i int)) error {
+	group := NewGroup(ctx, opts)
+	for i := 0; i < n; i++ {
+		group.Go(func(ctx context.Context) error {
+			fn(ctx, i)
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
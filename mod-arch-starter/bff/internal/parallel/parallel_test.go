@@ -0,0 +1,75 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMap(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	results, err := Map(context.Background(), Options{Limit: 3}, []int{1, 2, 3, 4, 5, 6, 7, 8}, func(_ context.Context, item int) (int, error) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := maxInFlight.Load()
+			if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return item * item, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 4, 9, 16, 25, 36, 49, 64}, results, "in the order of the items")
+	assert.Equal(t, int32(3), maxInFlight.Load())
+}
+
+func TestMap_Error(t *testing.T) {
+	boom := errors.New("boom")
+	var cancelled atomic.Bool
+	// Item 1 fails once item 2 runs, as the calls not started yet are skipped
+	started := make(chan struct{})
+	_, err := Map(context.Background(), Options{Limit: -1}, []int{1, 2}, func(ctx context.Context, item int) (int, error) {
+		if item == 1 {
+			<-started
+			return 0, boom
+		}
+		close(started)
+		<-ctx.Done()
+		cancelled.Store(true)
+		return 0, ctx.Err()
+	})
+	assert.ErrorIs(t, err, boom)
+	assert.True(t, cancelled.Load(), "the first error cancels the other calls")
+}
+
+func TestDo_Timeout(t *testing.T) {
+	start := time.Now()
+	err := Do(context.Background(), Options{Timeout: 20 * time.Millisecond},
+		func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() },
+		func(ctx context.Context) error { return nil },
+	)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestForEach(t *testing.T) {
+	seen := make([]bool, 20)
+	require.NoError(t, ForEach(context.Background(), Options{}, len(seen), func(_ context.Context, i int) {
+		seen[i] = true
+	}))
+	assert.NotContains(t, seen, false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var calls atomic.Int32
+	err := ForEach(ctx, Options{}, 5, func(context.Context, int) { calls.Add(1) })
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, calls.Load(), "calls are skipped once the context is done")
+}
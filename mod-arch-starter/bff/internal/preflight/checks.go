@@ -0,0 +1,119 @@
+package preflight
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// ExpiryWarning is how long before it expires a certificate is reported as a warning.
+const ExpiryWarning = 14 * 24 * time.Hour
+
+// Certificate checks the certificate and key pair of certFile and keyFile: they must load and
+// match, and the certificate must be valid at now.
+func Certificate(name, certFile, keyFile string, now time.Time) Result {
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return Fail(name, err.Error(), fmt.Sprintf("check that %s and %s are a matching PEM certificate and key", certFile, keyFile))
+	}
+	leaf := pair.Leaf
+	if leaf == nil {
+		if leaf, err = x509.ParseCertificate(pair.Certificate[0]); err != nil {
+			return Fail(name, err.Error(), fmt.Sprintf("check that %s is a PEM certificate", certFile))
+		}
+	}
+	if result, ok := validity(name, certFile, leaf, now); !ok {
+		return result
+	}
+	return Pass(name, fmt.Sprintf("%s is valid until %s", certFile, leaf.NotAfter.UTC().Format(time.RFC3339)))
+}
+
+// CABundle checks the PEM bundle of CA certificates at path: it must hold certificates, and
+// they must be valid at now. Expired certificates next to valid ones are a warning.
+func CABundle(name, path string, now time.Time) Result {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Fail(name, err.Error(), fmt.Sprintf("mount the CA bundle at %s, or remove it from the configuration", path))
+	}
+	var certs []*x509.Certificate
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return Fail(name, fmt.Sprintf("%s: %v", path, err), fmt.Sprintf("check the certificates of %s", path))
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return Fail(name, fmt.Sprintf("no certificates found in %s", path), fmt.Sprintf("check that %s is a PEM bundle of CA certificates", path))
+	}
+
+	var problems []Result
+	for _, cert := range certs {
+		if result, ok := validity(name, path, cert, now); !ok {
+			problems = append(problems, result)
+		}
+	}
+	switch {
+	case len(problems) == len(certs):
+		return problems[0]
+	case len(problems) > 0:
+		return Warn(name, problems[0].Message, fmt.Sprintf("remove the expired or not yet valid certificates from %s", path))
+	}
+	return Pass(name, fmt.Sprintf("%s holds %d valid certificates", path, len(certs)))
+}
+
+// validity checks that cert, read from path, is valid at now and doesn't expire within
+// ExpiryWarning.
+func validity(name, path string, cert *x509.Certificate, now time.Time) (Result, bool) {
+	subject := cert.Subject.String()
+	switch {
+	case now.Before(cert.NotBefore):
+		return Fail(name, fmt.Sprintf("%s (%s) is not valid before %s", path, subject, cert.NotBefore.UTC().Format(time.RFC3339)),
+			"check the clock of the node, or issue a certificate valid now"), false
+	case now.After(cert.NotAfter):
+		return Fail(name, fmt.Sprintf("%s (%s) expired on %s", path, subject, cert.NotAfter.UTC().Format(time.RFC3339)),
+			fmt.Sprintf("renew the certificate of %s", path)), false
+	case cert.NotAfter.Sub(now) < ExpiryWarning:
+		return Warn(name, fmt.Sprintf("%s (%s) expires on %s", path, subject, cert.NotAfter.UTC().Format(time.RFC3339)),
+			fmt.Sprintf("renew the certificate of %s; it is reloaded without a restart", path)), false
+	}
+	return Result{}, true
+}
+
+// Reachable GETs url with client and passes on any response below 500: it checks that the
+// upstream is reachable and trusted, not that the request is authorized. upstream names the
+// upstream in the -upstream-tls hint of certificate errors.
+func Reachable(ctx context.Context, client *http.Client, name, url, upstream string) Result {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Fail(name, err.Error(), "check the URL in the configuration")
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		var unknownAuthority x509.UnknownAuthorityError
+		var verification *tls.CertificateVerificationError
+		if errors.As(err, &unknownAuthority) || errors.As(err, &verification) {
+			return Fail(name, err.Error(), fmt.Sprintf("trust the CA of the upstream with -upstream-tls %s=ca=<path> or -bundle-paths", upstream))
+		}
+		return Fail(name, err.Error(), "check the URL, the DNS resolution and the network policies between the BFF and the upstream")
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	message := fmt.Sprintf("GET %s answered %d in %s", url, resp.StatusCode, time.Since(start).Round(time.Millisecond))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return Warn(name, message, "check the logs of the upstream")
+	}
+	return Pass(name, message)
+}
@@ -0,0 +1,122 @@
+// Package preflight checks the environment of the BFF before it serves: the permissions of its
+// service account, the reachability of its upstreams and the validity of its TLS material. Each
+// problem is reported with a hint telling how to fix it, so a misconfigured deployment fails
+// fast with an actionable report instead of with the first requests of the users.
+package preflight
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds each check.
+const DefaultTimeout = 10 * time.Second
+
+// Status is the outcome of a check, and of a Report as the worst of its results.
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	// StatusWarn is a problem that only disables a feature, or will fail soon, e.g. a
+	// certificate about to expire.
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// severity orders the statuses, for the status of a Report.
+func (s Status) severity() int {
+	switch s {
+	case StatusWarn:
+		return 1
+	case StatusFail:
+		return 2
+	}
+	return 0
+}
+
+// Result is the outcome of checking one item, e.g. a permission or a certificate.
+type Result struct {
+	// Name names the item, prefixed with its kind, e.g. "rbac/list namespaces".
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+	// Hint tells how to fix a warning or failure.
+	Hint string `json:"hint,omitempty"`
+}
+
+// Pass returns a passed result.
+func Pass(name, message string) Result {
+	return Result{Name: name, Status: StatusPass, Message: message}
+}
+
+// Warn returns a warning with the hint fixing it.
+func Warn(name, message, hint string) Result {
+	return Result{Name: name, Status: StatusWarn, Message: message, Hint: hint}
+}
+
+// Fail returns a failure with the hint fixing it.
+func Fail(name, message, hint string) Result {
+	return Result{Name: name, Status: StatusFail, Message: message, Hint: hint}
+}
+
+// Check checks one aspect of the environment, with a result per item checked.
+type Check func(ctx context.Context) []Result
+
+// Report is the outcome of Run.
+type Report struct {
+	// Status is the worst status of the results.
+	Status    Status        `json:"status"`
+	Results   []Result      `json:"results"`
+	CheckedAt time.Time     `json:"checkedAt"`
+	Duration  time.Duration `json:"duration"`
+}
+
+// Run runs the checks in parallel, each bounded by timeout (DefaultTimeout when zero), and
+// reports their results in the order of checks.
+func Run(ctx context.Context, timeout time.Duration, checks []Check) Report {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	start := time.Now()
+	results := make([][]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			results[i] = check(ctx)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusPass, Results: []Result{}, CheckedAt: start.UTC(), Duration: time.Since(start)}
+	for _, checked := range results {
+		for _, result := range checked {
+			if result.Status.severity() > report.Status.severity() {
+				report.Status = result.Status
+			}
+			report.Results = append(report.Results, result)
+		}
+	}
+	return report
+}
+
+// Log logs the warnings and failures of the report with their hints, then a summary.
+func (r Report) Log(logger *slog.Logger) {
+	counts := map[Status]int{}
+	for _, result := range r.Results {
+		counts[result.Status]++
+		switch result.Status {
+		case StatusWarn:
+			logger.Warn("preflight check warning", "check", result.Name, "message", result.Message, "hint", result.Hint)
+		case StatusFail:
+			logger.Error("preflight check failed", "check", result.Name, "message", result.Message, "hint", result.Hint)
+		}
+	}
+	logger.Info("preflight checks completed", "status", r.Status, "passed", counts[StatusPass],
+		"warnings", counts[StatusWarn], "failed", counts[StatusFail], "duration", r.Duration)
+}
@@ -0,0 +1,134 @@
+package preflight
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCertificate writes a self-signed certificate valid from notBefore to notAfter and its
+// key to dir, and returns their paths.
+func writeCertificate(t *testing.T, dir, name string, notBefore, notAfter time.Time) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile, keyFile = filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func TestCertificate(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	valid, validKey := writeCertificate(t, dir, "valid", now.Add(-time.Hour), now.Add(90*24*time.Hour))
+	expiring, expiringKey := writeCertificate(t, dir, "expiring", now.Add(-time.Hour), now.Add(24*time.Hour))
+	expired, expiredKey := writeCertificate(t, dir, "expired", now.Add(-2*time.Hour), now.Add(-time.Hour))
+
+	assert.Equal(t, StatusPass, Certificate("tls/server", valid, validKey, now).Status)
+	result := Certificate("tls/server", expiring, expiringKey, now)
+	assert.Equal(t, StatusWarn, result.Status)
+	assert.Contains(t, result.Hint, "renew")
+	result = Certificate("tls/server", expired, expiredKey, now)
+	assert.Equal(t, StatusFail, result.Status)
+	assert.Contains(t, result.Message, "expired on")
+	assert.Equal(t, StatusFail, Certificate("tls/server", valid, valid, now).Status, "no key")
+	assert.Equal(t, StatusFail, Certificate("tls/server", valid, expiredKey, now).Status, "another key")
+	assert.Equal(t, StatusFail, Certificate("tls/server", valid, validKey, now.Add(-2*time.Hour)).Status, "not valid yet")
+}
+
+func TestCABundle(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	valid, _ := writeCertificate(t, dir, "valid", now.Add(-time.Hour), now.Add(90*24*time.Hour))
+	expired, _ := writeCertificate(t, dir, "expired", now.Add(-2*time.Hour), now.Add(-time.Hour))
+	validPEM, err := os.ReadFile(valid)
+	require.NoError(t, err)
+	expiredPEM, err := os.ReadFile(expired)
+	require.NoError(t, err)
+	mixed := filepath.Join(dir, "mixed.pem")
+	require.NoError(t, os.WriteFile(mixed, append(validPEM, expiredPEM...), 0o600))
+	empty := filepath.Join(dir, "empty.pem")
+	require.NoError(t, os.WriteFile(empty, []byte("not a certificate"), 0o600))
+
+	assert.Equal(t, StatusPass, CABundle("tls/client-ca", valid, now).Status)
+	assert.Equal(t, StatusWarn, CABundle("tls/client-ca", mixed, now).Status)
+	assert.Equal(t, StatusFail, CABundle("tls/client-ca", expired, now).Status)
+	assert.Equal(t, StatusFail, CABundle("tls/client-ca", empty, now).Status)
+	result := CABundle("tls/client-ca", filepath.Join(dir, "missing.pem"), now)
+	assert.Equal(t, StatusFail, result.Status)
+	assert.Contains(t, result.Hint, "mount the CA bundle")
+}
+
+func TestReachable(t *testing.T) {
+	ctx := context.Background()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	result := Reachable(ctx, srv.Client(), "upstream/model-registry", srv.URL, "model-registry")
+	assert.Equal(t, StatusPass, result.Status, "any answer proves the upstream reachable: %s", result.Message)
+	assert.Equal(t, StatusWarn, Reachable(ctx, srv.Client(), "upstream/model-registry", srv.URL+"/down", "model-registry").Status)
+
+	result = Reachable(ctx, http.DefaultClient, "upstream/model-registry", srv.URL, "model-registry")
+	assert.Equal(t, StatusFail, result.Status, "not trusted")
+	assert.Contains(t, result.Hint, "-upstream-tls model-registry=ca=")
+
+	srv.Close()
+	result = Reachable(ctx, srv.Client(), "upstream/model-registry", srv.URL, "model-registry")
+	assert.Equal(t, StatusFail, result.Status)
+	assert.Contains(t, result.Hint, "network policies")
+}
+
+func TestRun(t *testing.T) {
+	slow := func(ctx context.Context) []Result {
+		<-ctx.Done()
+		return []Result{Fail("slow", ctx.Err().Error(), "")}
+	}
+	warn := func(context.Context) []Result {
+		return []Result{Pass("a", ""), Warn("b", "", "")}
+	}
+
+	report := Run(context.Background(), 10*time.Millisecond, []Check{warn, slow})
+	assert.Equal(t, StatusFail, report.Status)
+	require.Len(t, report.Results, 3)
+	assert.Equal(t, []string{"a", "b", "slow"}, []string{report.Results[0].Name, report.Results[1].Name, report.Results[2].Name})
+
+	assert.Equal(t, StatusWarn, Run(context.Background(), 0, []Check{warn}).Status)
+	report = Run(context.Background(), 0, nil)
+	assert.Equal(t, StatusPass, report.Status)
+	assert.NotNil(t, report.Results)
+}
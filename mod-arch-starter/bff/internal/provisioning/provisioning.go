@@ -0,0 +1,210 @@
+// Package provisioning renders the templates of the namespaces provisioned through the BFF: a
+// namespace and its companion resources (RoleBindings of the requester, ResourceQuotas,
+// NetworkPolicies, ...) as YAML manifests, executed as Go text/templates with the name of the
+// namespace, the requester and the parameters of the request.
+//
+// A template set is a directory of <name>.yaml files, one template each, or the built-in set
+// whose "default" template creates an OpenDataHub data science project:
+//
+//	apiVersion: v1
+//	kind: Namespace
+//	metadata:
+//	  name: {{ .Name }}
+//	  labels:
+//	    opendatahub.io/dashboard: "true"
+//	---
+//	apiVersion: v1
+//	kind: ResourceQuota
+//	metadata:
+//	  name: compute
+//	spec:
+//	  hard:
+//	    requests.cpu: {{ quote (.Param "cpu" "8") }}
+package provisioning
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultTemplate is the template used when a request names none.
+const DefaultTemplate = "default"
+
+// ErrUnknownTemplate is returned by Render for a template missing from the set.
+var ErrUnknownTemplate = errors.New("provisioning: unknown namespace template")
+
+//go:embed templates/*.yaml
+var builtin embed.FS
+
+var namespacesGVR = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+// Data is what the templates are executed with.
+type Data struct {
+	// Name is the name of the namespace.
+	Name        string
+	DisplayName string
+	Description string
+	// Requester is the user provisioning the namespace.
+	Requester string
+	// Parameters are the free-form values of the request, read with Param.
+	Parameters map[string]string
+}
+
+// Param is the parameter name of the request, or fallback when it is not set.
+func (d Data) Param(name, fallback string) string {
+	if value, ok := d.Parameters[name]; ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+// funcs are the template functions besides the text/template builtins. quote renders a string
+// as a double-quoted YAML scalar, so values of the request can't inject YAML.
+var funcs = template.FuncMap{
+	"quote": strconv.Quote,
+}
+
+// Manifest is an object rendered by a template and the resource it is created as.
+type Manifest struct {
+	Resource schema.GroupVersionResource
+	Object   *unstructured.Unstructured
+}
+
+// Set is a set of namespace templates by name.
+type Set struct {
+	templates map[string]*template.Template
+}
+
+// Load parses the *.yaml (or *.yml) templates of dir, or of the built-in set when dir is empty.
+func Load(dir string) (*Set, error) {
+	var files fs.FS = os.DirFS(dir)
+	if dir == "" {
+		files, _ = fs.Sub(builtin, "templates")
+	}
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the namespace templates: %w", err)
+	}
+	set := &Set{templates: map[string]*template.Template{}}
+	for _, entry := range entries {
+		ext := path.Ext(entry.Name())
+		if entry.IsDir() || ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		data, err := fs.ReadFile(files, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the namespace template %s: %w", entry.Name(), err)
+		}
+		name := strings.TrimSuffix(entry.Name(), ext)
+		tmpl, err := template.New(name).Option("missingkey=error").Funcs(funcs).Parse(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the namespace template %s: %w", entry.Name(), err)
+		}
+		set.templates[name] = tmpl
+	}
+	if len(set.templates) == 0 {
+		return nil, fmt.Errorf("no namespace templates in %s", dir)
+	}
+	return set, nil
+}
+
+// Names returns the names of the templates, sorted.
+func (s *Set) Names() []string {
+	names := make([]string, 0, len(s.templates))
+	for name := range s.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render executes the template name with data and returns its objects, the Namespace first.
+// A template without a Namespace object gets a bare one. The other objects are created in the
+// namespace: their metadata.namespace is set, and must not name another namespace.
+func (s *Set) Render(name string, data Data) ([]Manifest, error) {
+	tmpl, ok := s.templates[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTemplate, name)
+	}
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return nil, fmt.Errorf("failed to render the namespace template %s: %w", name, err)
+	}
+
+	var namespace *unstructured.Unstructured
+	var manifests []Manifest
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(&rendered))
+	for i := 0; ; i++ {
+		document, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("namespace template %s: %w", name, err)
+		}
+		obj, err := decode(document)
+		if err != nil {
+			return nil, fmt.Errorf("namespace template %s, object %d: %w", name, i, err)
+		}
+		if obj == nil {
+			continue
+		}
+		gvk := obj.GroupVersionKind()
+		if obj.GetName() == "" {
+			return nil, fmt.Errorf("namespace template %s: %s without a name", name, gvk.Kind)
+		}
+		if gvk.Group == "" && gvk.Kind == "Namespace" {
+			if namespace != nil || obj.GetName() != data.Name {
+				return nil, fmt.Errorf("namespace template %s: the only Namespace must be named {{ .Name }}", name)
+			}
+			namespace = obj
+			continue
+		}
+		if ns := obj.GetNamespace(); ns != "" && ns != data.Name {
+			return nil, fmt.Errorf("namespace template %s: %s %s is in another namespace", name, gvk.Kind, obj.GetName())
+		}
+		obj.SetNamespace(data.Name)
+		resource, _ := meta.UnsafeGuessKindToResource(gvk)
+		manifests = append(manifests, Manifest{Resource: resource, Object: obj})
+	}
+	if namespace == nil {
+		namespace = &unstructured.Unstructured{}
+		namespace.SetAPIVersion("v1")
+		namespace.SetKind("Namespace")
+		namespace.SetName(data.Name)
+	}
+	return append([]Manifest{{Resource: namespacesGVR, Object: namespace}}, manifests...), nil
+}
+
+// decode decodes a YAML document into an object, nil for an empty document.
+func decode(document []byte) (*unstructured.Unstructured, error) {
+	data, err := yaml.YAMLToJSON(document)
+	if err != nil {
+		return nil, err
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || string(trimmed) == "null" {
+		return nil, nil
+	}
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
@@ -0,0 +1,107 @@
+package provisioning
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestBuiltinTemplates(t *testing.T) {
+	set, err := Load("")
+	require.NoError(t, err)
+	assert.Equal(t, []string{DefaultTemplate}, set.Names())
+
+	manifests, err := set.Render(DefaultTemplate, Data{
+		Name:        "fraud-detection",
+		DisplayName: `Fraud "detection"`,
+		Requester:   "dora@example.com",
+		Parameters:  map[string]string{"gpu": "2"},
+	})
+	require.NoError(t, err)
+	require.Len(t, manifests, 4)
+
+	namespace := manifests[0]
+	assert.Equal(t, schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}, namespace.Resource)
+	assert.Equal(t, "fraud-detection", namespace.Object.GetName())
+	assert.Equal(t, "true", namespace.Object.GetLabels()["opendatahub.io/dashboard"])
+	assert.Equal(t, `Fraud "detection"`, namespace.Object.GetAnnotations()["openshift.io/display-name"])
+	assert.Equal(t, "dora@example.com", namespace.Object.GetAnnotations()["openshift.io/requester"])
+
+	resources := []schema.GroupVersionResource{}
+	for _, manifest := range manifests[1:] {
+		assert.Equal(t, "fraud-detection", manifest.Object.GetNamespace())
+		resources = append(resources, manifest.Resource)
+	}
+	assert.Equal(t, []schema.GroupVersionResource{
+		{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "rolebindings"},
+		{Version: "v1", Resource: "resourcequotas"},
+		{Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies"},
+	}, resources)
+
+	subjects, _, _ := unstructured.NestedSlice(manifests[1].Object.Object, "subjects")
+	assert.Equal(t, "dora@example.com", subjects[0].(map[string]any)["name"])
+	hard, _, _ := unstructured.NestedStringMap(manifests[2].Object.Object, "spec", "hard")
+	assert.Equal(t, "2", hard["requests.nvidia.com/gpu"], "parameter")
+	assert.Equal(t, "8", hard["requests.cpu"], "default of an unset parameter")
+
+	_, err = set.Render("gpu", Data{Name: "fraud-detection"})
+	assert.ErrorIs(t, err, ErrUnknownTemplate)
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	write("bare.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  owner: {{ quote .Requester }}
+---
+`)
+	write("other-namespace.yml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: kube-system
+`)
+	write("renamed.yaml", `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: {{ .Name }}-suffix
+`)
+	write("README.md", "not a template")
+
+	set, err := Load(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bare", "other-namespace", "renamed"}, set.Names())
+
+	manifests, err := set.Render("bare", Data{Name: "team-a", Requester: "bella@example.com"})
+	require.NoError(t, err)
+	require.Len(t, manifests, 2)
+	assert.Equal(t, "Namespace", manifests[0].Object.GetKind(), "a bare namespace is added")
+	assert.Equal(t, "team-a", manifests[0].Object.GetName())
+	assert.Equal(t, "team-a", manifests[1].Object.GetNamespace())
+
+	_, err = set.Render("other-namespace", Data{Name: "team-a"})
+	assert.ErrorContains(t, err, "in another namespace")
+	_, err = set.Render("renamed", Data{Name: "team-a"})
+	assert.ErrorContains(t, err, "must be named")
+
+	write("broken.yaml", "{{ .Name")
+	_, err = Load(dir)
+	assert.ErrorContains(t, err, "broken.yaml")
+
+	_, err = Load(t.TempDir())
+	assert.ErrorContains(t, err, "no namespace templates")
+}
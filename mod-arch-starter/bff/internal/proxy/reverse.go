@@ -0,0 +1,289 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+)
+
+// ─── REVERSE PROXY ───────────────────────────────────────────────────────────────
+//
+// ReverseProxy forwards every request under a route's path prefix to an upstream,
+// so module APIs can be fronted by the BFF without writing a handler per endpoint.
+// Responses are streamed (flushed as they arrive) and WebSocket upgrades are passed through,
+// with their subprotocols, so notebook kernels, terminals, log streams and exec sessions work
+// through the BFF.
+
+// Resolver returns the upstream base URL for a request, e.g. from service discovery.
+type Resolver func(r *http.Request) (*url.URL, error)
+
+// StaticTarget always proxies to target.
+func StaticTarget(target *url.URL) Resolver {
+	return func(*http.Request) (*url.URL, error) { return target, nil }
+}
+
+// ErrOriginNotAllowed is reported to the ErrorHandler, with a 403, for WebSocket upgrades from
+// an origin rejected by ReverseProxyOptions.CheckOrigin.
+var ErrOriginNotAllowed = errors.New("origin not allowed")
+
+// ErrNoUpstream is returned by resolvers that find no upstream for the request.
+// The proxy answers it with 503 instead of 502.
+var ErrNoUpstream = errors.New("no upstream available")
+
+// Route maps a path prefix to an upstream.
+type Route struct {
+	// Name identifies the route in logs.
+	Name string
+
+	// PathPrefix is the request path prefix served by the route, e.g. "/api/v1/proxy/model-registry/".
+	PathPrefix string
+
+	// RewritePrefix replaces PathPrefix before the rest of the path is appended to the
+	// upstream URL path ("" forwards only the rest), e.g. "/api/model_registry/v1alpha3/".
+	RewritePrefix string
+
+	// Target is a static upstream; Resolve takes precedence when set.
+	Target  *url.URL
+	Resolve Resolver
+
+	// Headers are set on every proxied request.
+	Headers http.Header
+
+	// Streaming clears the server write timeout for long-lived responses (SSE, watches, downloads).
+	// WebSocket upgrades always clear it.
+	Streaming bool
+
+	// Transport, when set, replaces ReverseProxyOptions.Transport for the route, e.g. with the
+	// TLS settings of its upstream. It must be an *http.Transport (or wrap one) to support upgrades.
+	Transport http.RoundTripper
+}
+
+// ReverseProxyOptions configures how requests are forwarded.
+type ReverseProxyOptions struct {
+	// Transport sends proxied requests; it must be an *http.Transport (or wrap one) to support upgrades.
+	Transport http.RoundTripper
+
+	// AuthTokenHeader and AuthTokenPrefix control how the caller's bearer token is forwarded
+	// (default "Authorization" and "Bearer ").
+	AuthTokenHeader string
+	AuthTokenPrefix string
+
+	// ErrorHandler writes the response when the upstream can't be resolved or reached.
+	// statusCode is 502, or 503 for ErrNoUpstream; handlers may prefer the status carried by
+	// err (resolvers return apierrors and Kubernetes errors). The default writes a plain statusCode.
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, statusCode int, err error)
+
+	// CheckOrigin, when set, rejects WebSocket upgrades whose Origin header it returns false for:
+	// unlike other requests, browsers let any page open a WebSocket with the user's cookies.
+	// Upgrades without an Origin don't come from a browser and are not checked.
+	CheckOrigin func(r *http.Request) bool
+
+	// DrainContext, when set, ends streaming and upgraded requests once it is done, so they
+	// don't hold up a graceful shutdown. Regular requests are left to complete.
+	DrainContext context.Context
+
+	Logger *slog.Logger
+}
+
+type compiledRoute struct {
+	Route
+	proxy *httputil.ReverseProxy
+}
+
+type ReverseProxy struct {
+	routes []*compiledRoute
+	opts   ReverseProxyOptions
+}
+
+// NewReverseProxy validates the routes and returns a handler serving all of them.
+func NewReverseProxy(routes []Route, opts ReverseProxyOptions) (*ReverseProxy, error) {
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	if opts.Transport == nil {
+		opts.Transport = http.DefaultTransport
+	}
+	if opts.AuthTokenHeader == "" {
+		opts.AuthTokenHeader = "Authorization"
+		if opts.AuthTokenPrefix == "" {
+			opts.AuthTokenPrefix = "Bearer "
+		}
+	}
+	if opts.ErrorHandler == nil {
+		opts.ErrorHandler = func(w http.ResponseWriter, _ *http.Request, statusCode int, _ error) {
+			http.Error(w, http.StatusText(statusCode), statusCode)
+		}
+	}
+
+	p := &ReverseProxy{opts: opts}
+	seen := map[string]bool{}
+	for _, route := range routes {
+		if !strings.HasPrefix(route.PathPrefix, "/") {
+			return nil, fmt.Errorf("proxy route %q: path prefix %q must start with /", route.Name, route.PathPrefix)
+		}
+		if !strings.HasSuffix(route.PathPrefix, "/") {
+			route.PathPrefix += "/"
+		}
+		if seen[route.PathPrefix] {
+			return nil, fmt.Errorf("proxy route %q: duplicate path prefix %q", route.Name, route.PathPrefix)
+		}
+		seen[route.PathPrefix] = true
+
+		if route.Resolve == nil {
+			if route.Target == nil {
+				return nil, fmt.Errorf("proxy route %q: a Target or Resolve function is required", route.Name)
+			}
+			route.Resolve = StaticTarget(route.Target)
+		}
+
+		p.routes = append(p.routes, p.compile(route))
+	}
+
+	// Longest prefix first, so nested routes win over their parents
+	sort.Slice(p.routes, func(i, j int) bool { return len(p.routes[i].PathPrefix) > len(p.routes[j].PathPrefix) })
+	return p, nil
+}
+
+// PathPrefixes returns the path prefixes served by the proxy, for mounting it on a mux.
+func (p *ReverseProxy) PathPrefixes() []string {
+	prefixes := make([]string, 0, len(p.routes))
+	for _, route := range p.routes {
+		prefixes = append(prefixes, route.PathPrefix)
+	}
+	return prefixes
+}
+
+func (p *ReverseProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	route := p.match(r.URL.Path)
+	if route == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if isUpgrade(r) && p.opts.CheckOrigin != nil && r.Header.Get("Origin") != "" && !p.opts.CheckOrigin(r) {
+		p.opts.Logger.Warn("rejected cross-origin WebSocket upgrade", "route", route.Name, "origin", r.Header.Get("Origin"))
+		p.opts.ErrorHandler(w, r, http.StatusForbidden, ErrOriginNotAllowed)
+		return
+	}
+
+	target, err := route.Resolve(r)
+	if err != nil {
+		statusCode := http.StatusBadGateway
+		if errors.Is(err, ErrNoUpstream) {
+			statusCode = http.StatusServiceUnavailable
+		}
+		p.opts.Logger.Warn("failed to resolve proxy upstream", "route", route.Name, "error", err)
+		p.opts.ErrorHandler(w, r, statusCode, err)
+		return
+	}
+
+	ctx := r.Context()
+	if route.Streaming || isUpgrade(r) {
+		// Best effort: not every ResponseWriter supports deadlines
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+		if p.opts.DrainContext != nil {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithCancel(ctx)
+			defer cancel()
+			stop := context.AfterFunc(p.opts.DrainContext, cancel)
+			defer stop()
+		}
+	}
+
+	route.proxy.ServeHTTP(w, r.WithContext(withTarget(ctx, target)))
+}
+
+func (p *ReverseProxy) match(path string) *compiledRoute {
+	for _, route := range p.routes {
+		if strings.HasPrefix(path, route.PathPrefix) || path+"/" == route.PathPrefix {
+			return route
+		}
+	}
+	return nil
+}
+
+func (p *ReverseProxy) compile(route Route) *compiledRoute {
+	cr := &compiledRoute{Route: route}
+	transport := p.opts.Transport
+	if route.Transport != nil {
+		transport = route.Transport
+	}
+	cr.proxy = &httputil.ReverseProxy{
+		Transport: transport,
+		// Flush immediately so event streams and chunked downloads are not buffered
+		FlushInterval: -1,
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			target := targetFrom(pr.In.Context())
+			rest := strings.TrimPrefix(pr.In.URL.Path, strings.TrimSuffix(route.PathPrefix, "/"))
+			rest = strings.TrimPrefix(rest, "/")
+
+			pr.SetURL(target)
+			pr.Out.URL.Path = joinPath(target.Path, route.RewritePrefix, rest)
+			pr.Out.URL.RawPath = ""
+			pr.SetXForwarded()
+
+			// The caller authenticated with the BFF; the upstream gets the verified identity instead
+			pr.Out.Header.Del("Cookie")
+			identity, _ := pr.In.Context().Value(constants.RequestIdentityKey).(*kubernetes.RequestIdentity)
+			identity.SetForwardedHeaders(pr.Out.Header, p.opts.AuthTokenHeader, p.opts.AuthTokenPrefix)
+			if requestID, ok := pr.In.Context().Value(constants.RequestIdKey).(string); ok && requestID != "" {
+				pr.Out.Header.Set(constants.RequestIDHeader, requestID)
+			}
+
+			for key, values := range route.Headers {
+				pr.Out.Header[key] = values
+			}
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			p.opts.Logger.Warn("proxy request failed", "route", route.Name, "path", r.URL.Path, "error", err)
+			p.opts.ErrorHandler(w, r, http.StatusBadGateway, err)
+		},
+	}
+	return cr
+}
+
+// joinPath joins URL path segments with single slashes, keeping the trailing slash of the
+// last non-empty one.
+func joinPath(parts ...string) string {
+	var b strings.Builder
+	trailingSlash := false
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		trailingSlash = strings.HasSuffix(part, "/")
+		if part = strings.Trim(part, "/"); part != "" {
+			b.WriteString("/")
+			b.WriteString(part)
+		}
+	}
+	if b.Len() == 0 || trailingSlash {
+		b.WriteString("/")
+	}
+	return b.String()
+}
+
+func isUpgrade(r *http.Request) bool {
+	return r.Header.Get("Upgrade") != "" && strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade")
+}
+
+type targetContextKey struct{}
+
+func withTarget(ctx context.Context, target *url.URL) context.Context {
+	return context.WithValue(ctx, targetContextKey{}, target)
+}
+
+func targetFrom(ctx context.Context) *url.URL {
+	target, _ := ctx.Value(targetContextKey{}).(*url.URL)
+	return target
+}
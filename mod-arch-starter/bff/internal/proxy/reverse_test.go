@@ -0,0 +1,528 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func mustParseURL(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("url.Parse(%q): %v", raw, err)
+	}
+	return u
+}
+
+func newTestReverseProxy(t *testing.T, routes ...Route) *ReverseProxy {
+	t.Helper()
+	p, err := NewReverseProxy(routes, ReverseProxyOptions{Logger: testLogger()})
+	if err != nil {
+		t.Fatalf("NewReverseProxy() error: %v", err)
+	}
+	return p
+}
+
+func withIdentity(r *http.Request, identity *kubernetes.RequestIdentity) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), constants.RequestIdentityKey, identity))
+}
+
+func TestNewReverseProxy_ValidatesRoutes(t *testing.T) {
+	target := &url.URL{Scheme: "http", Host: "upstream"}
+	tests := []struct {
+		name   string
+		routes []Route
+	}{
+		{"relative prefix", []Route{{Name: "a", PathPrefix: "api/v1/a/", Target: target}}},
+		{"duplicate prefix", []Route{{Name: "a", PathPrefix: "/api/v1/a", Target: target}, {Name: "b", PathPrefix: "/api/v1/a/", Target: target}}},
+		{"no target", []Route{{Name: "a", PathPrefix: "/api/v1/a/"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewReverseProxy(tt.routes, ReverseProxyOptions{}); err == nil {
+				t.Error("NewReverseProxy() expected an error")
+			}
+		})
+	}
+}
+
+func TestReverseProxy_RewritesPathAndForwardsIdentity(t *testing.T) {
+	var got *http.Request
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Clone(context.Background())
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer upstream.Close()
+
+	p := newTestReverseProxy(t,
+		Route{
+			Name:          "registry",
+			PathPrefix:    "/api/v1/proxy/registry",
+			RewritePrefix: "/api/model_registry/v1alpha3/",
+			Target:        mustParseURL(t, upstream.URL+"/base"),
+			Headers:       http.Header{"X-Module": []string{"registry"}},
+		},
+		Route{Name: "nested", PathPrefix: "/api/v1/proxy/registry/nested/", Target: mustParseURL(t, upstream.URL+"/nested")},
+	)
+
+	tests := []struct {
+		name     string
+		path     string
+		identity *kubernetes.RequestIdentity
+		wantPath string
+		check    func(t *testing.T, h http.Header)
+	}{
+		{
+			name:     "token identity",
+			path:     "/api/v1/proxy/registry/models/1?pageSize=10",
+			identity: &kubernetes.RequestIdentity{Token: "user-token"},
+			wantPath: "/base/api/model_registry/v1alpha3/models/1",
+			check: func(t *testing.T, h http.Header) {
+				if v := h.Get("Authorization"); v != "Bearer user-token" {
+					t.Errorf("Authorization = %q, want the caller token", v)
+				}
+				if v := h.Get(constants.KubeflowUserIDHeader); v != "" {
+					t.Errorf("spoofed %s header forwarded: %q", constants.KubeflowUserIDHeader, v)
+				}
+				if v := h.Get("X-Module"); v != "registry" {
+					t.Errorf("X-Module = %q, want route header", v)
+				}
+			},
+		},
+		{
+			name:     "user id identity",
+			path:     "/api/v1/proxy/registry/",
+			identity: &kubernetes.RequestIdentity{UserID: "bella@example.com", Groups: []string{"a", "b"}},
+			wantPath: "/base/api/model_registry/v1alpha3/",
+			check: func(t *testing.T, h http.Header) {
+				if v := h.Get(constants.KubeflowUserIDHeader); v != "bella@example.com" {
+					t.Errorf("%s = %q, want verified user", constants.KubeflowUserIDHeader, v)
+				}
+				if v := h.Get(constants.KubeflowUserGroupsIdHeader); v != "a,b" {
+					t.Errorf("%s = %q, want joined groups", constants.KubeflowUserGroupsIdHeader, v)
+				}
+				if v := h.Get("Authorization"); v != "" {
+					t.Errorf("Authorization = %q, want none", v)
+				}
+			},
+		},
+		{
+			name:     "longest prefix wins",
+			path:     "/api/v1/proxy/registry/nested/x",
+			wantPath: "/nested/x",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set(constants.KubeflowUserIDHeader, "spoofed@example.com")
+			req.Header.Set("Authorization", "Bearer spoofed")
+			req.Header.Set("Cookie", "session=secret")
+			if tt.identity != nil {
+				req = withIdentity(req, tt.identity)
+			}
+			req = req.WithContext(context.WithValue(req.Context(), constants.RequestIdKey, "req-1"))
+
+			rr := httptest.NewRecorder()
+			p.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusTeapot || got == nil {
+				t.Fatalf("status = %d, want the upstream response", rr.Code)
+			}
+			if got.URL.Path != tt.wantPath {
+				t.Errorf("upstream path = %q, want %q", got.URL.Path, tt.wantPath)
+			}
+			if q := req.URL.RawQuery; got.URL.RawQuery != q {
+				t.Errorf("upstream query = %q, want %q", got.URL.RawQuery, q)
+			}
+			if v := got.Header.Get("Cookie"); v != "" {
+				t.Errorf("Cookie forwarded: %q", v)
+			}
+			if tt.identity == nil && got.Header.Get("Authorization") != "" {
+				t.Error("spoofed Authorization forwarded without identity")
+			}
+			if v := got.Header.Get(constants.RequestIDHeader); v != "req-1" {
+				t.Errorf("%s = %q, want the request ID", constants.RequestIDHeader, v)
+			}
+			if tt.check != nil {
+				tt.check(t, got.Header)
+			}
+		})
+	}
+}
+
+func TestReverseProxy_ResolveErrors(t *testing.T) {
+	var gotStatus int
+	p, err := NewReverseProxy([]Route{
+		{Name: "none", PathPrefix: "/api/v1/none/", Resolve: func(*http.Request) (*url.URL, error) {
+			return nil, fmt.Errorf("%w: nothing deployed", ErrNoUpstream)
+		}},
+		{Name: "broken", PathPrefix: "/api/v1/broken/", Resolve: func(*http.Request) (*url.URL, error) {
+			return nil, errors.New("boom")
+		}},
+	}, ReverseProxyOptions{
+		Logger: testLogger(),
+		ErrorHandler: func(w http.ResponseWriter, _ *http.Request, statusCode int, _ error) {
+			gotStatus = statusCode
+			w.WriteHeader(statusCode)
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewReverseProxy() error: %v", err)
+	}
+
+	for path, want := range map[string]int{
+		"/api/v1/none/x":   http.StatusServiceUnavailable,
+		"/api/v1/broken/x": http.StatusBadGateway,
+	} {
+		gotStatus = 0
+		p.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		if gotStatus != want {
+			t.Errorf("%s: status = %d, want %d", path, gotStatus, want)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	p.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/other", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("unmatched path: status = %d, want 404", rr.Code)
+	}
+}
+
+func TestReverseProxy_StreamsResponses(t *testing.T) {
+	release := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: first\n\n")
+		w.(http.Flusher).Flush()
+		<-release
+		fmt.Fprint(w, "data: second\n\n")
+	}))
+	defer upstream.Close()
+	defer close(release)
+
+	p := newTestReverseProxy(t, Route{Name: "events", PathPrefix: "/api/v1/events/", Target: mustParseURL(t, upstream.URL), Streaming: true})
+	front := httptest.NewServer(p)
+	defer front.Close()
+
+	resp, err := http.Get(front.URL + "/api/v1/events/stream")
+	if err != nil {
+		t.Fatalf("GET error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// The first event must arrive while the upstream is still holding the response open
+	lineCh := make(chan string, 1)
+	go func() {
+		line, _ := bufio.NewReader(resp.Body).ReadString('\n')
+		lineCh <- line
+	}()
+	select {
+	case line := <-lineCh:
+		if line != "data: first\n" {
+			t.Errorf("first line = %q", line)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("first event was buffered by the proxy")
+	}
+}
+
+func TestReverseProxy_DrainContextEndsStreams(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: first\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer upstream.Close()
+
+	drainCtx, drain := context.WithCancel(context.Background())
+	defer drain()
+	p, err := NewReverseProxy([]Route{
+		{Name: "events", PathPrefix: "/api/v1/events/", Target: mustParseURL(t, upstream.URL), Streaming: true},
+	}, ReverseProxyOptions{Logger: testLogger(), DrainContext: drainCtx})
+	if err != nil {
+		t.Fatalf("NewReverseProxy() error: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/events/stream", nil))
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("stream ended before draining")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	drain()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream was not ended by the drain context")
+	}
+}
+
+func TestReverseProxy_WebSocketUpgrade(t *testing.T) {
+	var gotUser string
+	upgrader := websocket.Upgrader{}
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser = r.Header.Get(constants.KubeflowUserIDHeader)
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			mt, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := conn.WriteMessage(mt, append([]byte("echo: "), msg...)); err != nil {
+				return
+			}
+		}
+	}))
+	defer upstream.Close()
+
+	p := newTestReverseProxy(t, Route{Name: "ws", PathPrefix: "/api/v1/ws/", Target: mustParseURL(t, upstream.URL)})
+	front := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.ServeHTTP(w, withIdentity(r, &kubernetes.RequestIdentity{UserID: "bella@example.com"}))
+	}))
+	defer front.Close()
+
+	conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(front.URL, "http")+"/api/v1/ws/socket", nil)
+	if err != nil {
+		t.Fatalf("Dial error: %v", err)
+	}
+	defer conn.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("status = %d, want 101", resp.StatusCode)
+	}
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("hello")); err != nil {
+		t.Fatalf("WriteMessage error: %v", err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, msg, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage error: %v", err)
+	}
+	if string(msg) != "echo: hello" {
+		t.Errorf("message = %q, want echo", msg)
+	}
+	if gotUser != "bella@example.com" {
+		t.Errorf("upstream user = %q, want forwarded identity", gotUser)
+	}
+}
+
+func TestReverseProxy_WebSocketSubprotocolAndOrigin(t *testing.T) {
+	upgrader := websocket.Upgrader{
+		Subprotocols: []string{"v4.channel.k8s.io"},
+		CheckOrigin:  func(*http.Request) bool { return true },
+	}
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		conn.Close()
+	}))
+	defer upstream.Close()
+
+	p, err := NewReverseProxy([]Route{{Name: "terminal", PathPrefix: "/api/v1/terminal/", Target: mustParseURL(t, upstream.URL)}},
+		ReverseProxyOptions{Logger: testLogger(), CheckOrigin: OriginChecker([]string{"https://*.example.com"})})
+	if err != nil {
+		t.Fatalf("NewReverseProxy() error: %v", err)
+	}
+	front := httptest.NewServer(p)
+	defer front.Close()
+
+	dialer := websocket.Dialer{Subprotocols: []string{"v5.channel.k8s.io", "v4.channel.k8s.io"}}
+	wsURL := "ws" + strings.TrimPrefix(front.URL, "http") + "/api/v1/terminal/websocket"
+
+	conn, _, err := dialer.Dial(wsURL, http.Header{"Origin": {"https://ui.example.com"}})
+	if err != nil {
+		t.Fatalf("Dial error: %v", err)
+	}
+	conn.Close()
+	if conn.Subprotocol() != "v4.channel.k8s.io" {
+		t.Errorf("subprotocol = %q, want the one negotiated with the upstream", conn.Subprotocol())
+	}
+
+	_, resp, err := dialer.Dial(wsURL, http.Header{"Origin": {"https://evil.com"}})
+	if err == nil || resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("cross-origin upgrade: err = %v, want a 403", err)
+	}
+
+	// Non-browser clients send no Origin
+	conn, _, err = dialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Dial without Origin error: %v", err)
+	}
+	conn.Close()
+}
+
+func TestJoinPath(t *testing.T) {
+	tests := []struct {
+		parts []string
+		want  string
+	}{
+		{[]string{"", "", ""}, "/"},
+		{[]string{"/base", "", "models"}, "/base/models"},
+		{[]string{"", "/api/v1/", ""}, "/api/v1/"},
+		{[]string{"/", "", ""}, "/"},
+		{[]string{"/base/", "/api/", "models/"}, "/base/api/models/"},
+	}
+	for _, tt := range tests {
+		if got := joinPath(tt.parts...); got != tt.want {
+			t.Errorf("joinPath(%q) = %q, want %q", tt.parts, got, tt.want)
+		}
+	}
+}
+
+// ─── SERVICE RESOLVER ───────────────────────────────────────────────────────────
+
+type stubClient struct {
+	kubernetes.KubernetesClientInterface
+	reader  kubernetes.ResourceReader
+	allowed bool
+}
+
+func (c *stubClient) CanAccess(context.Context, *kubernetes.RequestIdentity, string, string, string, string) (bool, error) {
+	return c.allowed, nil
+}
+
+func (c *stubClient) Reader() kubernetes.ResourceReader {
+	return c.reader
+}
+
+type stubFactory struct {
+	kubernetes.KubernetesClientFactory
+	client *stubClient
+}
+
+func (f *stubFactory) GetClient(context.Context) (kubernetes.KubernetesClientInterface, error) {
+	return f.client, nil
+}
+
+func newStubFactory(t *testing.T, allowed bool, services ...*corev1.Service) *stubFactory {
+	t.Helper()
+	clientset := fake.NewClientset()
+	for _, svc := range services {
+		if _, err := clientset.CoreV1().Services(svc.Namespace).Create(context.Background(), svc, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("create service: %v", err)
+		}
+	}
+	reader, err := kubernetes.NewResourceCache(clientset, kubernetes.CacheConfig{}, testLogger())
+	if err != nil {
+		t.Fatalf("NewResourceCache() error: %v", err)
+	}
+	return &stubFactory{client: &stubClient{reader: reader, allowed: allowed}}
+}
+
+func TestServiceResolver(t *testing.T) {
+	https := "https"
+	registry := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: "kubeflow", Labels: map[string]string{"app.kubernetes.io/component": "model-registry"}},
+		Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{
+			{Name: "grpc-api", Port: 9090},
+			{Name: "rest-api", Port: 8443, AppProtocol: &https},
+		}},
+	}
+	other := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "kubeflow", Labels: map[string]string{"app": "other"}},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 80}}},
+	}
+
+	identity := &kubernetes.RequestIdentity{UserID: "bella@example.com"}
+	request := func(query string) *http.Request {
+		return withIdentity(httptest.NewRequest(http.MethodGet, "/api/v1/proxy/registry/"+query, nil), identity)
+	}
+
+	t.Run("resolves matching service port", func(t *testing.T) {
+		resolve, err := ServiceResolver(newStubFactory(t, true, registry, other), ServiceTarget{Selector: "app.kubernetes.io/component=model-registry", PortName: "rest-api"})
+		if err != nil {
+			t.Fatalf("ServiceResolver() error: %v", err)
+		}
+		target, err := resolve(request("?namespace=kubeflow"))
+		if err != nil {
+			t.Fatalf("resolve error: %v", err)
+		}
+		if want := "https://registry.kubeflow.svc.cluster.local:8443"; target.String() != want {
+			t.Errorf("target = %q, want %q", target, want)
+		}
+	})
+
+	t.Run("fixed namespace and first port", func(t *testing.T) {
+		resolve, err := ServiceResolver(newStubFactory(t, true, other), ServiceTarget{Namespace: "kubeflow", Selector: "app=other"})
+		if err != nil {
+			t.Fatalf("ServiceResolver() error: %v", err)
+		}
+		target, err := resolve(request(""))
+		if err != nil {
+			t.Fatalf("resolve error: %v", err)
+		}
+		if want := "http://other.kubeflow.svc.cluster.local:80"; target.String() != want {
+			t.Errorf("target = %q, want %q", target, want)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		target := ServiceTarget{Selector: "app.kubernetes.io/component=model-registry"}
+		allowed, err := ServiceResolver(newStubFactory(t, true, registry), target)
+		if err != nil {
+			t.Fatalf("ServiceResolver() error: %v", err)
+		}
+		denied, err := ServiceResolver(newStubFactory(t, false, registry), target)
+		if err != nil {
+			t.Fatalf("ServiceResolver() error: %v", err)
+		}
+		missingPort, err := ServiceResolver(newStubFactory(t, true, registry), ServiceTarget{Selector: target.Selector, PortName: "metrics"})
+		if err != nil {
+			t.Fatalf("ServiceResolver() error: %v", err)
+		}
+
+		if _, err := allowed(request("")); err == nil || errors.Is(err, ErrNoUpstream) {
+			t.Errorf("missing namespace: err = %v, want bad request", err)
+		}
+		if _, err := denied(request("?namespace=kubeflow")); !k8serrors.IsForbidden(err) {
+			t.Errorf("denied: err = %v, want forbidden", err)
+		}
+		if _, err := allowed(request("?namespace=empty")); !errors.Is(err, ErrNoUpstream) {
+			t.Errorf("no service: err = %v, want ErrNoUpstream", err)
+		}
+		if _, err := missingPort(request("?namespace=kubeflow")); !errors.Is(err, ErrNoUpstream) {
+			t.Errorf("missing port: err = %v, want ErrNoUpstream", err)
+		}
+	})
+
+	t.Run("requires a selector", func(t *testing.T) {
+		if _, err := ServiceResolver(newStubFactory(t, true), ServiceTarget{}); err == nil {
+			t.Error("ServiceResolver() expected an error for an empty selector")
+		}
+		if _, err := ServiceResolver(newStubFactory(t, true), ServiceTarget{Selector: "a in ("}); err == nil {
+			t.Error("ServiceResolver() expected an error for an invalid selector")
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/apierrors"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ServiceTarget selects the Kubernetes Service a route proxies to.
+type ServiceTarget struct {
+	// Namespace of the Service. Empty takes it from the "namespace" query parameter of the request.
+	Namespace string
+
+	// Selector is a label selector matching the Service, e.g. "app.kubernetes.io/part-of=model-registry".
+	// When several Services match, the first by name is used.
+	Selector string
+
+	// PortName selects the Service port; empty uses the first port.
+	PortName string
+
+	// Scheme is "http" or "https". Empty uses https when the port is named "https" or its
+	// appProtocol is https, and http otherwise.
+	Scheme string
+}
+
+// ServiceResolver resolves the upstream of each request to the in-cluster address of the
+// Service matching target. The lookup runs with the caller's Kubernetes client and requires
+// the caller to be allowed to get services in the namespace, so users can only reach
+// Services they could see themselves.
+func ServiceResolver(factory kubernetes.KubernetesClientFactory, target ServiceTarget) (Resolver, error) {
+	selector, err := labels.Parse(target.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid service selector %q: %w", target.Selector, err)
+	}
+	if selector.Empty() {
+		return nil, fmt.Errorf("service selector is required")
+	}
+
+	return func(r *http.Request) (*url.URL, error) {
+		ctx := r.Context()
+		namespace := target.Namespace
+		if namespace == "" {
+			namespace = r.URL.Query().Get(string(constants.NamespaceHeaderParameterKey))
+			if namespace == "" {
+				return nil, apierrors.BadRequest(fmt.Sprintf("missing required query parameter: %s", constants.NamespaceHeaderParameterKey))
+			}
+		}
+
+		identity, ok := ctx.Value(constants.RequestIdentityKey).(*kubernetes.RequestIdentity)
+		if !ok || identity == nil {
+			return nil, fmt.Errorf("missing RequestIdentity in context")
+		}
+		client, err := factory.GetClient(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Kubernetes client: %w", err)
+		}
+
+		allowed, err := client.CanAccess(ctx, identity, "get", "", "services", namespace)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, k8serrors.NewForbidden(schema.GroupResource{Resource: "services"}, "", fmt.Errorf("user cannot get services in namespace %s", namespace))
+		}
+
+		services, err := client.Reader().ListServices(ctx, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list services: %w", err)
+		}
+		for i := range services {
+			if selector.Matches(labels.Set(services[i].Labels)) {
+				return serviceURL(&services[i], target)
+			}
+		}
+		return nil, fmt.Errorf("%w: no service matching %q in namespace %s", ErrNoUpstream, target.Selector, namespace)
+	}, nil
+}
+
+func serviceURL(svc *corev1.Service, target ServiceTarget) (*url.URL, error) {
+	var port *corev1.ServicePort
+	for i := range svc.Spec.Ports {
+		if target.PortName == "" || svc.Spec.Ports[i].Name == target.PortName {
+			port = &svc.Spec.Ports[i]
+			break
+		}
+	}
+	if port == nil {
+		return nil, fmt.Errorf("%w: service %s/%s has no port %q", ErrNoUpstream, svc.Namespace, svc.Name, target.PortName)
+	}
+
+	return kubernetes.ServicePortURL(svc, port, target.Scheme), nil
+}
@@ -1,5 +1,6 @@
 // Package proxy provides WebSocket toolkit utilities for building BFF endpoints
-// that communicate with Kubernetes or other WebSocket backends.
+// that communicate with Kubernetes or other WebSocket backends, and a reverse proxy
+// that fronts module APIs served by in-cluster Services.
 package proxy
 
 import (
@@ -24,6 +25,10 @@ const (
 	HeartbeatInterval   = 15 * time.Second
 	WriteMessageTimeout = 30 * time.Second
 	MaxConnections      = 1000
+
+	// BearerSubprotocolPrefix starts the subprotocol carrying a bearer token, the way browsers,
+	// which can't set headers on WebSockets, authenticate to the Kubernetes API.
+	BearerSubprotocolPrefix = "base64url.bearer.authorization.k8s.io."
 )
 
 func NewUpgrader(allowedOrigins []string) websocket.Upgrader {
@@ -40,18 +45,31 @@ func OriginChecker(allowedOrigins []string) func(*http.Request) bool {
 		return func(r *http.Request) bool { return true }
 	}
 	allowed := make(map[string]bool, len(allowedOrigins))
+	var patterns []string
 	for _, o := range allowedOrigins {
-		allowed[strings.TrimRight(o, "/")] = true
+		o = strings.TrimRight(o, "/")
+		if strings.Contains(o, "*") {
+			patterns = append(patterns, o)
+			continue
+		}
+		allowed[o] = true
 	}
 	return func(r *http.Request) bool {
-		origin := r.Header.Get("Origin")
+		origin := strings.TrimRight(r.Header.Get("Origin"), "/")
 		if origin == "" {
 			return false
 		}
-		return allowed[strings.TrimRight(origin, "/")]
+		return allowed[origin] || slices.ContainsFunc(patterns, func(pattern string) bool { return matchOrigin(pattern, origin) })
 	}
 }
 
+// matchOrigin matches origin against a pattern with one "*", as in ALLOWED_ORIGINS
+// ("https://*.apps.example.com"). The wildcard matches at least one character.
+func matchOrigin(pattern, origin string) bool {
+	prefix, suffix, _ := strings.Cut(pattern, "*")
+	return len(origin) > len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix)
+}
+
 func SameOriginCheck(r *http.Request) bool {
 	origin := r.Header.Get("Origin")
 	if origin == "" {
@@ -149,7 +167,38 @@ func DialK8sWebSocket(targetWSURL string, tlsConfig *tls.Config, token string, t
 }
 
 func BearerSubprotocol(token string) string {
-	return "base64url.bearer.authorization.k8s.io." + base64.RawURLEncoding.EncodeToString([]byte(token))
+	return BearerSubprotocolPrefix + base64.RawURLEncoding.EncodeToString([]byte(token))
+}
+
+// TakeBearerSubprotocol removes the BearerSubprotocol offered in the Sec-WebSocket-Protocol
+// headers of h, so it isn't forwarded, and returns its token. ok is false when h offers none
+// or its token isn't valid base64url.
+func TakeBearerSubprotocol(h http.Header) (token string, ok bool) {
+	var kept []string
+	for _, value := range h.Values("Sec-WebSocket-Protocol") {
+		for _, protocol := range strings.Split(value, ",") {
+			protocol = strings.TrimSpace(protocol)
+			encoded, found := strings.CutPrefix(protocol, BearerSubprotocolPrefix)
+			if !found {
+				if protocol != "" {
+					kept = append(kept, protocol)
+				}
+				continue
+			}
+			if decoded, err := base64.RawURLEncoding.DecodeString(encoded); err == nil && !ok {
+				token, ok = string(decoded), true
+			}
+		}
+	}
+	if !ok {
+		return "", false
+	}
+	if len(kept) == 0 {
+		h.Del("Sec-WebSocket-Protocol")
+	} else {
+		h.Set("Sec-WebSocket-Protocol", strings.Join(kept, ", "))
+	}
+	return token, true
 }
 
 func NegotiatedSubprotocolHeader(targetConn *websocket.Conn, clientSubprotocols []string) http.Header {
@@ -108,6 +108,9 @@ func TestOriginChecker(t *testing.T) {
 		{"non-matching origin blocked", []string{"https://dashboard.example.com"}, "dashboard.example.com", "https://evil.com", false},
 		{"empty origin header blocked", []string{"https://dashboard.example.com"}, "dashboard.example.com", "", false},
 		{"trailing slash normalized", []string{"https://dashboard.example.com/"}, "dashboard.example.com", "https://dashboard.example.com", true},
+		{"pattern matches subdomain", []string{"https://*.apps.example.com"}, "bff.example.com", "https://ui.apps.example.com", true},
+		{"pattern needs a subdomain", []string{"https://*.apps.example.com"}, "bff.example.com", "https://.apps.example.com", false},
+		{"pattern blocks other domains", []string{"https://*.apps.example.com"}, "bff.example.com", "https://ui.apps.example.com.evil.com", false},
 	}
 
 	for _, tt := range tests {
@@ -522,3 +525,30 @@ func TestBridgeConnections_Tracking(t *testing.T) {
 		t.Errorf("after close, active count = %d, want 0", count)
 	}
 }
+
+func TestTakeBearerSubprotocol(t *testing.T) {
+	h := http.Header{}
+	h.Add("Sec-WebSocket-Protocol", BearerSubprotocol("secret-token")+", v4.channel.k8s.io")
+	h.Add("Sec-WebSocket-Protocol", "v5.channel.k8s.io")
+
+	token, ok := TakeBearerSubprotocol(h)
+	if !ok || token != "secret-token" {
+		t.Fatalf("TakeBearerSubprotocol() = %q, %v, want the token", token, ok)
+	}
+	if got := h.Get("Sec-WebSocket-Protocol"); got != "v4.channel.k8s.io, v5.channel.k8s.io" {
+		t.Errorf("Sec-WebSocket-Protocol = %q, want the other subprotocols", got)
+	}
+
+	h = http.Header{"Sec-Websocket-Protocol": {BearerSubprotocol("secret-token")}}
+	if _, ok := TakeBearerSubprotocol(h); !ok || len(h.Values("Sec-WebSocket-Protocol")) != 0 {
+		t.Errorf("Sec-WebSocket-Protocol = %q, want it removed", h.Values("Sec-WebSocket-Protocol"))
+	}
+
+	h = http.Header{"Sec-Websocket-Protocol": {"v4.channel.k8s.io", BearerSubprotocolPrefix + "not base64!"}}
+	if _, ok := TakeBearerSubprotocol(h); ok {
+		t.Error("TakeBearerSubprotocol() found a token in an invalid subprotocol")
+	}
+	if got := h.Values("Sec-WebSocket-Protocol"); len(got) != 2 {
+		t.Errorf("Sec-WebSocket-Protocol = %q, want it unchanged", got)
+	}
+}
@@ -174,9 +174,30 @@ func (ct *ConnectionTracker) cleanupStale() {
 
 	ct.mu.Unlock()
 
-	closeMsg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "stale connection")
+	closeGoingAway(stale, "stale connection")
+}
+
+// CloseAll sends a "going away" close frame to every tracked connection and closes it, e.g.
+// when the server starts draining. Clients are expected to reconnect to another replica.
+func (ct *ConnectionTracker) CloseAll(reason string) {
+	ct.mu.Lock()
+	conns := make([]*trackedConnection, 0, len(ct.connections))
+	for id, tc := range ct.connections {
+		conns = append(conns, tc)
+		delete(ct.connections, id)
+	}
+	ct.mu.Unlock()
+
+	if len(conns) > 0 {
+		ct.logger.Info("Closing WebSocket connections", slog.Int("count", len(conns)), slog.String("reason", reason))
+	}
+	closeGoingAway(conns, reason)
+}
+
+func closeGoingAway(conns []*trackedConnection, reason string) {
+	closeMsg := websocket.FormatCloseMessage(websocket.CloseGoingAway, reason)
 	deadline := time.Now().Add(5 * time.Second)
-	for _, tc := range stale {
+	for _, tc := range conns {
 		if tc.client != nil {
 			_ = tc.client.WriteControl(websocket.CloseMessage, closeMsg, deadline)
 			tc.client.Close()
@@ -207,3 +207,49 @@ func TestConnectionTracker_StaleCleanupWithConnections(t *testing.T) {
 		t.Errorf("after stale cleanup with connections, count = %d, want 0", tracker.ActiveCount())
 	}
 }
+
+func TestConnectionTracker_CloseAll(t *testing.T) {
+	closeCode := make(chan int, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		up := websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
+		conn, err := up.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetCloseHandler(func(code int, text string) error {
+			closeCode <- code
+			return nil
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http")
+	clientConn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial client error: %v", err)
+	}
+
+	tracker := NewConnectionTracker(testLogger())
+	defer tracker.Stop()
+	tracker.Track(clientConn, nil)
+
+	tracker.CloseAll("server shutting down")
+
+	if tracker.ActiveCount() != 0 {
+		t.Errorf("after CloseAll, count = %d, want 0", tracker.ActiveCount())
+	}
+	select {
+	case code := <-closeCode:
+		if code != websocket.CloseGoingAway {
+			t.Errorf("close code = %d, want %d", code, websocket.CloseGoingAway)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("peer did not receive a close frame")
+	}
+}
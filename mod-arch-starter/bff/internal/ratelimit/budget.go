@@ -0,0 +1,129 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Usage is the state of the budget of a key after a Spend.
+type Usage struct {
+	// Limit is the cost a key may spend within any window.
+	Limit int
+	// Remaining is the cost the key may still spend now.
+	Remaining int
+	// Reset is how long until the oldest cost spent in the window is refunded, 0 when the
+	// whole budget is available.
+	Reset time.Duration
+	// RetryAfter is, for a rejected cost, how long until enough is refunded to spend it.
+	RetryAfter time.Duration
+}
+
+// Budget lets each key (a user ID) spend up to a cost within any sliding window: the costs
+// spent are refunded once they are older than the window, so a user can't spend twice the
+// limit across the boundary of fixed windows. Keys idle for longer than the window are
+// dropped. It is safe for concurrent use.
+type Budget struct {
+	limit  int
+	window time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	keys      map[string]*spending
+	lastSweep time.Time
+}
+
+// spending holds the costs spent by a key within the window, oldest first.
+type spending struct {
+	costs []spent
+	total int
+}
+
+type spent struct {
+	at   time.Time
+	cost int
+}
+
+// NewBudget returns a Budget allowing limit per key within any window.
+func NewBudget(limit int, window time.Duration) *Budget {
+	return &Budget{
+		limit:  limit,
+		window: window,
+		now:    time.Now,
+		keys:   map[string]*spending{},
+	}
+}
+
+// Spend spends cost from the budget of key, and reports whether it could. A rejected cost is
+// not spent, and Usage.RetryAfter tells when it can be; a cost over the limit never can.
+func (b *Budget) Spend(key string, cost int) (bool, Usage) {
+	now := b.now()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sweep(now)
+	s, ok := b.keys[key]
+	if !ok {
+		s = &spending{}
+		b.keys[key] = s
+	}
+	s.refund(now.Add(-b.window))
+
+	allowed := s.total+cost <= b.limit
+	if allowed && cost > 0 {
+		s.costs = append(s.costs, spent{at: now, cost: cost})
+		s.total += cost
+	}
+	usage := Usage{Limit: b.limit, Remaining: max(0, b.limit-s.total)}
+	if len(s.costs) > 0 {
+		usage.Reset = s.costs[0].at.Add(b.window).Sub(now)
+	}
+	if !allowed {
+		usage.RetryAfter = b.window
+		if cost <= b.limit {
+			usage.RetryAfter = s.retryAfter(s.total+cost-b.limit, now, b.window)
+		}
+	}
+	return allowed, usage
+}
+
+// Len returns the number of tracked keys.
+func (b *Budget) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.keys)
+}
+
+// refund drops the costs spent before since.
+func (s *spending) refund(since time.Time) {
+	n := 0
+	for n < len(s.costs) && !s.costs[n].at.After(since) {
+		s.total -= s.costs[n].cost
+		n++
+	}
+	s.costs = s.costs[n:]
+}
+
+// retryAfter returns how long until the costs refunded add up to needed.
+func (s *spending) retryAfter(needed int, now time.Time, window time.Duration) time.Duration {
+	for _, c := range s.costs {
+		needed -= c.cost
+		if needed <= 0 {
+			return c.at.Add(window).Sub(now)
+		}
+	}
+	return window
+}
+
+// sweep drops the keys that spent nothing within the window, at most once per window. b.mu
+// must be held.
+func (b *Budget) sweep(now time.Time) {
+	if now.Sub(b.lastSweep) < b.window {
+		return
+	}
+	b.lastSweep = now
+	for key, s := range b.keys {
+		if len(s.costs) == 0 || !s.costs[len(s.costs)-1].at.After(now.Add(-b.window)) {
+			delete(b.keys, key)
+		}
+	}
+}
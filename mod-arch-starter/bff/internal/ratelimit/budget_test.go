@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBudget(limit int, window time.Duration) (*Budget, *time.Time) {
+	now := time.Unix(1_700_000_000, 0)
+	b := NewBudget(limit, window)
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func TestBudget_Spend(t *testing.T) {
+	b, now := newTestBudget(10, time.Minute)
+
+	allowed, usage := b.Spend("alice", 6)
+	assert.True(t, allowed)
+	assert.Equal(t, Usage{Limit: 10, Remaining: 4, Reset: time.Minute}, usage)
+
+	*now = now.Add(20 * time.Second)
+	allowed, usage = b.Spend("alice", 3)
+	assert.True(t, allowed)
+	assert.Equal(t, 1, usage.Remaining)
+	assert.Equal(t, 40*time.Second, usage.Reset, "until the first cost is refunded")
+
+	allowed, usage = b.Spend("alice", 5)
+	assert.False(t, allowed)
+	assert.Equal(t, 1, usage.Remaining, "a rejected cost is not spent")
+	assert.Equal(t, 40*time.Second, usage.RetryAfter, "the first cost refunds enough")
+	allowed, usage = b.Spend("alice", 10)
+	assert.False(t, allowed)
+	assert.Equal(t, time.Minute, usage.RetryAfter, "both costs must be refunded")
+
+	// Keys have their own budgets
+	allowed, _ = b.Spend("bob", 10)
+	assert.True(t, allowed)
+
+	// The window slides
+	*now = now.Add(40 * time.Second)
+	allowed, usage = b.Spend("alice", 5)
+	assert.True(t, allowed)
+	assert.Equal(t, 2, usage.Remaining)
+
+	allowed, usage = b.Spend("alice", 11)
+	assert.False(t, allowed, "over the limit")
+	assert.Equal(t, time.Minute, usage.RetryAfter)
+}
+
+func TestBudget_DropsIdleKeys(t *testing.T) {
+	b, now := newTestBudget(10, time.Minute)
+	b.Spend("alice", 1)
+	b.Spend("bob", 1)
+	assert.Equal(t, 2, b.Len())
+
+	*now = now.Add(time.Minute + time.Second)
+	b.Spend("bob", 1)
+	assert.Equal(t, 1, b.Len(), "alice spent nothing within the window")
+}
@@ -0,0 +1,93 @@
+// Package ratelimit implements per-key token-bucket rate limiting, used by the BFF to throttle
+// requests per user and per client IP so a runaway frontend cannot flood the Kubernetes API.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// DefaultIdleTimeout is how long the bucket of an inactive key is kept.
+const DefaultIdleTimeout = 10 * time.Minute
+
+// Limiter keeps one token bucket per key (a user ID, an IP address). Buckets of keys that
+// have been idle for longer than the idle timeout are dropped, so memory stays bounded by
+// the number of active clients. It is safe for concurrent use.
+type Limiter struct {
+	limit       rate.Limit
+	burst       int
+	idleTimeout time.Duration
+	now         func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// New returns a Limiter allowing requestsPerSecond per key on average, with bursts of up to
+// burst requests. A burst below 1 defaults to the rate rounded up.
+func New(requestsPerSecond float64, burst int) *Limiter {
+	if burst < 1 {
+		burst = max(1, int(requestsPerSecond+0.999))
+	}
+	return &Limiter{
+		limit:       rate.Limit(requestsPerSecond),
+		burst:       burst,
+		idleTimeout: DefaultIdleTimeout,
+		now:         time.Now,
+		buckets:     map[string]*bucket{},
+	}
+}
+
+// Allow takes a token from the bucket of key. When the bucket is empty it returns false and
+// how long the caller should wait before retrying; no token is consumed in that case.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	now := l.now()
+
+	l.mu.Lock()
+	l.sweep(now)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+	l.mu.Unlock()
+
+	reservation := b.limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		return false, time.Second
+	}
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// Len returns the number of tracked keys.
+func (l *Limiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
+
+// sweep drops idle buckets, at most once per idle timeout. l.mu must be held.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.idleTimeout {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.lastSeen) > l.idleTimeout {
+			delete(l.buckets, key)
+		}
+	}
+}
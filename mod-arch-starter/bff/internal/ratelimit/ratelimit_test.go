@@ -0,0 +1,58 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLimiter(requestsPerSecond float64, burst int) (*Limiter, *time.Time) {
+	now := time.Unix(1_700_000_000, 0)
+	l := New(requestsPerSecond, burst)
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	l, now := newTestLimiter(2, 3)
+
+	for i := 0; i < 3; i++ {
+		allowed, _ := l.Allow("alice")
+		assert.True(t, allowed, "request %d is within the burst", i)
+	}
+	allowed, retryAfter := l.Allow("alice")
+	assert.False(t, allowed)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	// Keys have their own buckets
+	allowed, _ = l.Allow("bob")
+	assert.True(t, allowed)
+
+	// A rejected request does not consume a token
+	*now = now.Add(500 * time.Millisecond)
+	allowed, _ = l.Allow("alice")
+	assert.True(t, allowed)
+	allowed, _ = l.Allow("alice")
+	assert.False(t, allowed)
+}
+
+func TestLimiter_DefaultBurst(t *testing.T) {
+	l, _ := newTestLimiter(0.5, 0)
+	allowed, _ := l.Allow("alice")
+	assert.True(t, allowed)
+	allowed, retryAfter := l.Allow("alice")
+	assert.False(t, allowed)
+	assert.Equal(t, 2*time.Second, retryAfter)
+}
+
+func TestLimiter_DropsIdleKeys(t *testing.T) {
+	l, now := newTestLimiter(1, 1)
+	l.Allow("alice")
+	l.Allow("bob")
+	assert.Equal(t, 2, l.Len())
+
+	*now = now.Add(DefaultIdleTimeout + time.Second)
+	l.Allow("bob")
+	assert.Equal(t, 1, l.Len(), "alice was idle for longer than the idle timeout")
+}
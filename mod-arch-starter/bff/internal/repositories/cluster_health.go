@@ -0,0 +1,298 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/parallel"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ClusterHealthWorkers bounds the components and operators checked in parallel.
+const ClusterHealthWorkers = 5
+
+var (
+	deploymentsGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	podsGVR        = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+)
+
+// ClusterComponent is a component reported by ClusterHealthRepository: the Deployments and
+// Pods matching Selector in Namespace, e.g. the model registry operator.
+type ClusterComponent struct {
+	Name      string
+	Namespace string
+	Selector  labels.Selector
+}
+
+// OperatorResource is an operator resource reported by ClusterHealthRepository, by the
+// conditions of its status, e.g. the DataScienceCluster. Namespace is empty for cluster-scoped
+// resources.
+type OperatorResource struct {
+	Name       string
+	Resource   schema.GroupVersionResource
+	Namespace  string
+	ObjectName string
+}
+
+// ClusterHealthRepository summarizes the health of the cluster in one document for the system
+// status banner of the UI: whether the API server is reachable, the readiness of the
+// configured components and the conditions of the configured operator resources.
+//
+// Workloads and operator resources are read with the client's credentials, without an access
+// review: the document only holds readiness counts and conditions. Parts the client may not
+// read are reported unknown.
+type ClusterHealthRepository struct {
+	components []ClusterComponent
+	operators  []OperatorResource
+}
+
+func NewClusterHealthRepository() *ClusterHealthRepository {
+	return &ClusterHealthRepository{}
+}
+
+// UseComponents sets the components and operator resources reported, none by default.
+func (r *ClusterHealthRepository) UseComponents(components []ClusterComponent, operators []OperatorResource) {
+	r.components = components
+	r.operators = operators
+}
+
+// GetClusterHealth checks the API server with checker, then, when it is reachable, the
+// components and operators in parallel. checker is typically the client factory, which checks
+// the API server without a request identity; a nil checker reports it reachable.
+func (r *ClusterHealthRepository) GetClusterHealth(client k8s.KubernetesClientInterface, ctx context.Context, checker k8s.APIServerChecker) (models.ClusterHealthModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "ClusterHealthRepository.GetClusterHealth",
+		attribute.Int("bff.cluster_health.components", len(r.components)),
+		attribute.Int("bff.cluster_health.operators", len(r.operators)),
+	)
+	defer span.End()
+
+	health := models.ClusterHealthModel{
+		Status:     models.HealthStatusHealthy,
+		Components: make([]models.ComponentHealth, len(r.components)),
+		Operators:  make([]models.OperatorHealth, len(r.operators)),
+	}
+
+	health.APIServer.Reachable = true
+	if checker != nil {
+		if err := checker.CheckAPIServer(ctx); err != nil {
+			// Logged rather than returned, as it may describe the network of the cluster
+			logger.FromContext(ctx).Warn("API server is unreachable", "error", err)
+			health.APIServer.Reachable = false
+		}
+	}
+
+	if !health.APIServer.Reachable {
+		health.Status = models.HealthStatusUnavailable
+		for i, component := range r.components {
+			health.Components[i] = models.ComponentHealth{Name: component.Name, Namespace: component.Namespace, Status: models.HealthStatusUnknown,
+				Message: "the API server is unreachable", Deployments: []models.DeploymentHealth{}}
+		}
+		for i, operator := range r.operators {
+			health.Operators[i] = models.OperatorHealth{Name: operator.Name, Status: models.HealthStatusUnknown,
+				Message: "the API server is unreachable", Conditions: []models.HealthCondition{}}
+		}
+		span.SetAttributes(attribute.String("bff.cluster_health.status", health.Status))
+		return health, nil
+	}
+
+	n := len(r.components)
+	err := parallel.ForEach(ctx, parallel.Options{Limit: ClusterHealthWorkers}, n+len(r.operators), func(ctx context.Context, i int) {
+		if i < n {
+			health.Components[i] = componentHealth(client, ctx, r.components[i])
+		} else {
+			health.Operators[i-n] = operatorHealth(client, ctx, r.operators[i-n])
+		}
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return models.ClusterHealthModel{}, err
+	}
+
+	for _, component := range health.Components {
+		if component.Status != models.HealthStatusHealthy {
+			health.Status = models.HealthStatusDegraded
+		}
+	}
+	for _, operator := range health.Operators {
+		if operator.Status != models.HealthStatusHealthy {
+			health.Status = models.HealthStatusDegraded
+		}
+	}
+	span.SetAttributes(attribute.String("bff.cluster_health.status", health.Status))
+	return health, nil
+}
+
+// componentHealth is healthy when every Deployment of the component is available with all its
+// replicas ready and every running Pod is ready, unavailable when nothing is ready.
+func componentHealth(client k8s.KubernetesClientInterface, ctx context.Context, component ClusterComponent) models.ComponentHealth {
+	health := models.ComponentHealth{Name: component.Name, Namespace: component.Namespace, Deployments: []models.DeploymentHealth{}}
+
+	var deployments []appsv1.Deployment
+	var pods []corev1.Pod
+	err := listTyped(client, ctx, deploymentsGVR, component, &deployments)
+	if err == nil {
+		err = listTyped(client, ctx, podsGVR, component, &pods)
+	}
+	if err != nil {
+		health.Status = models.HealthStatusUnknown
+		health.Message = readFailureMessage(err, "workloads")
+		logger.FromContext(ctx).Debug("failed to read component workloads, component health unknown",
+			"component", component.Name, "namespace", component.Namespace, "error", err)
+		return health
+	}
+
+	healthy, anyReady := true, false
+	for _, deployment := range deployments {
+		replicas := int32(1)
+		if deployment.Spec.Replicas != nil {
+			replicas = *deployment.Spec.Replicas
+		}
+		available := deploymentAvailable(&deployment)
+		health.Deployments = append(health.Deployments, models.DeploymentHealth{
+			Name: deployment.Name, Replicas: replicas, ReadyReplicas: deployment.Status.ReadyReplicas, Available: available,
+		})
+		healthy = healthy && available && deployment.Status.ReadyReplicas >= replicas
+		anyReady = anyReady || deployment.Status.ReadyReplicas > 0
+	}
+	sort.Slice(health.Deployments, func(i, j int) bool { return health.Deployments[i].Name < health.Deployments[j].Name })
+
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			// Completed Jobs don't serve anything
+			continue
+		}
+		health.TotalPods++
+		if podReady(&pod) {
+			health.ReadyPods++
+		}
+	}
+	healthy = healthy && health.ReadyPods == health.TotalPods
+	anyReady = anyReady || health.ReadyPods > 0
+
+	switch {
+	case len(health.Deployments) == 0 && health.TotalPods == 0:
+		health.Status = models.HealthStatusUnavailable
+		health.Message = "no Deployments or Pods match the component selector"
+	case healthy:
+		health.Status = models.HealthStatusHealthy
+	case anyReady:
+		health.Status = models.HealthStatusDegraded
+		health.Message = fmt.Sprintf("%d of %d Pods are ready", health.ReadyPods, health.TotalPods)
+	default:
+		health.Status = models.HealthStatusUnavailable
+		health.Message = "no Pods are ready"
+	}
+	return health
+}
+
+// listTyped lists the objects of gvr matching the selector of component, converted into list.
+func listTyped[T any](client k8s.KubernetesClientInterface, ctx context.Context, gvr schema.GroupVersionResource, component ClusterComponent, list *[]T) error {
+	resource, err := client.DynamicResource(gvr)
+	if err != nil {
+		return err
+	}
+	objects, err := resource.Namespace(component.Namespace).List(ctx, metav1.ListOptions{LabelSelector: component.Selector.String()})
+	if err != nil {
+		return err
+	}
+	*list = make([]T, len(objects.Items))
+	for i := range objects.Items {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(objects.Items[i].Object, &(*list)[i]); err != nil {
+			return fmt.Errorf("failed to convert %s %s: %w", gvr.Resource, objects.Items[i].GetName(), err)
+		}
+	}
+	return nil
+}
+
+func deploymentAvailable(deployment *appsv1.Deployment) bool {
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentAvailable {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func podReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// operatorHealth is unavailable when the Ready or Available condition of the resource is
+// False, degraded when its Degraded condition is True, and healthy otherwise.
+func operatorHealth(client k8s.KubernetesClientInterface, ctx context.Context, operator OperatorResource) models.OperatorHealth {
+	health := models.OperatorHealth{Name: operator.Name, Conditions: []models.HealthCondition{}}
+
+	resource, err := client.DynamicResource(operator.Resource)
+	var obj *unstructured.Unstructured
+	if err == nil {
+		obj, err = resource.Namespace(operator.Namespace).Get(ctx, operator.ObjectName, metav1.GetOptions{})
+	}
+	switch {
+	case k8serrors.IsNotFound(err):
+		health.Status = models.HealthStatusUnavailable
+		health.Message = fmt.Sprintf("%s %s not found", operator.Resource.Resource, operator.ObjectName)
+		return health
+	case err != nil:
+		health.Status = models.HealthStatusUnknown
+		health.Message = readFailureMessage(err, operator.Resource.Resource)
+		logger.FromContext(ctx).Debug("failed to read operator resource, operator health unknown",
+			"operator", operator.Name, "resource", operator.Resource.String(), "error", err)
+		return health
+	}
+	health.Kind = obj.GetKind()
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		str := func(key string) string { s, _ := condition[key].(string); return s }
+		health.Conditions = append(health.Conditions, models.HealthCondition{
+			Type: str("type"), Status: str("status"), Reason: str("reason"), Message: str("message"),
+		})
+	}
+
+	health.Status = models.HealthStatusHealthy
+	if len(health.Conditions) == 0 {
+		health.Status = models.HealthStatusUnknown
+		health.Message = "no status conditions reported yet"
+	}
+	for _, condition := range health.Conditions {
+		switch {
+		case (condition.Type == "Ready" || condition.Type == "Available") && condition.Status == string(metav1.ConditionFalse):
+			health.Status = models.HealthStatusUnavailable
+			health.Message = condition.Message
+		case condition.Type == "Degraded" && condition.Status == string(metav1.ConditionTrue) && health.Status != models.HealthStatusUnavailable:
+			health.Status = models.HealthStatusDegraded
+			health.Message = condition.Message
+		}
+	}
+	return health
+}
+
+// readFailureMessage describes an error reading what, without echoing it to the client.
+func readFailureMessage(err error, what string) string {
+	if k8serrors.IsForbidden(err) {
+		return "not allowed to read the " + what
+	}
+	return "failed to read the " + what
+}
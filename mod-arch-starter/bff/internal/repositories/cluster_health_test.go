@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes/k8mocks"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var dataScienceClustersGVR = schema.GroupVersionResource{Group: "datasciencecluster.opendatahub.io", Version: "v1", Resource: "datascienceclusters"}
+
+type apiServerCheckerFunc func(ctx context.Context) error
+
+func (f apiServerCheckerFunc) CheckAPIServer(ctx context.Context) error { return f(ctx) }
+
+func seed(t *testing.T, client k8s.KubernetesClientInterface, gvr schema.GroupVersionResource, namespace string, obj map[string]any) {
+	t.Helper()
+	resource, err := client.DynamicResource(gvr)
+	require.NoError(t, err)
+	_, err = resource.Namespace(namespace).Create(context.Background(), &unstructured.Unstructured{Object: obj}, metav1.CreateOptions{})
+	require.NoError(t, err)
+}
+
+func deploymentObject(name, app string, replicas, ready int64, available string) map[string]any {
+	return map[string]any{
+		"apiVersion": "apps/v1", "kind": "Deployment",
+		"metadata": map[string]any{"name": name, "namespace": "odh", "labels": map[string]any{"app": app}},
+		"spec":     map[string]any{"replicas": replicas},
+		"status": map[string]any{
+			"readyReplicas": ready,
+			"conditions":    []any{map[string]any{"type": "Available", "status": available}},
+		},
+	}
+}
+
+func podObject(name, app, phase, ready string) map[string]any {
+	return map[string]any{
+		"apiVersion": "v1", "kind": "Pod",
+		"metadata": map[string]any{"name": name, "namespace": "odh", "labels": map[string]any{"app": app}},
+		"status": map[string]any{
+			"phase":      phase,
+			"conditions": []any{map[string]any{"type": "Ready", "status": ready}},
+		},
+	}
+}
+
+func TestClusterHealthRepository(t *testing.T) {
+	client := k8mocks.NewMockKubernetesClient(k8mocks.DefaultMockFixtures(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	seed(t, client, deploymentsGVR, "odh", deploymentObject("model-registry-operator", "model-registry", 1, 1, "True"))
+	seed(t, client, podsGVR, "odh", podObject("model-registry-operator-x2k4p", "model-registry", "Running", "True"))
+	seed(t, client, podsGVR, "odh", podObject("model-registry-migrate-8hq2w", "model-registry", "Succeeded", "False"))
+	seed(t, client, deploymentsGVR, "odh", deploymentObject("ds-pipelines", "pipelines", 2, 1, "True"))
+	seed(t, client, podsGVR, "odh", podObject("ds-pipelines-a", "pipelines", "Running", "True"))
+	seed(t, client, podsGVR, "odh", podObject("ds-pipelines-b", "pipelines", "Running", "False"))
+	seed(t, client, dataScienceClustersGVR, "", map[string]any{
+		"apiVersion": "datasciencecluster.opendatahub.io/v1", "kind": "DataScienceCluster",
+		"metadata": map[string]any{"name": "default-dsc"},
+		"status": map[string]any{"conditions": []any{
+			map[string]any{"type": "Ready", "status": "True", "reason": "Ready"},
+			map[string]any{"type": "Degraded", "status": "False"},
+		}},
+	})
+
+	repo := NewClusterHealthRepository()
+	repo.UseComponents([]ClusterComponent{
+		{Name: "model-registry", Namespace: "odh", Selector: labels.SelectorFromSet(labels.Set{"app": "model-registry"})},
+		{Name: "pipelines", Namespace: "odh", Selector: labels.SelectorFromSet(labels.Set{"app": "pipelines"})},
+		{Name: "kserve", Namespace: "odh", Selector: labels.SelectorFromSet(labels.Set{"app": "kserve"})},
+	}, []OperatorResource{
+		{Name: "odh", Resource: dataScienceClustersGVR, ObjectName: "default-dsc"},
+		{Name: "missing", Resource: dataScienceClustersGVR, ObjectName: "other-dsc"},
+	})
+
+	health, err := repo.GetClusterHealth(client, context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, models.HealthStatusDegraded, health.Status)
+	assert.True(t, health.APIServer.Reachable)
+
+	require.Len(t, health.Components, 3)
+	assert.Equal(t, models.ComponentHealth{
+		Name: "model-registry", Namespace: "odh", Status: models.HealthStatusHealthy,
+		Deployments: []models.DeploymentHealth{{Name: "model-registry-operator", Replicas: 1, ReadyReplicas: 1, Available: true}},
+		ReadyPods:   1, TotalPods: 1,
+	}, health.Components[0])
+	assert.Equal(t, models.HealthStatusDegraded, health.Components[1].Status)
+	assert.Equal(t, "1 of 2 Pods are ready", health.Components[1].Message)
+	assert.Equal(t, models.HealthStatusUnavailable, health.Components[2].Status)
+
+	require.Len(t, health.Operators, 2)
+	assert.Equal(t, models.HealthStatusHealthy, health.Operators[0].Status)
+	assert.Equal(t, "DataScienceCluster", health.Operators[0].Kind)
+	assert.Len(t, health.Operators[0].Conditions, 2)
+	assert.Equal(t, models.HealthStatusUnavailable, health.Operators[1].Status)
+}
+
+func TestClusterHealthRepository_OperatorConditions(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []any
+		want       string
+	}{
+		{"ready", []any{map[string]any{"type": "Available", "status": "True"}}, models.HealthStatusHealthy},
+		{"not ready", []any{map[string]any{"type": "Ready", "status": "False", "message": "reconciling"}}, models.HealthStatusUnavailable},
+		{"degraded", []any{map[string]any{"type": "Ready", "status": "True"}, map[string]any{"type": "Degraded", "status": "True"}}, models.HealthStatusDegraded},
+		{"no conditions", nil, models.HealthStatusUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := k8mocks.NewMockKubernetesClient(k8mocks.DefaultMockFixtures(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+			seed(t, client, dataScienceClustersGVR, "", map[string]any{
+				"apiVersion": "datasciencecluster.opendatahub.io/v1", "kind": "DataScienceCluster",
+				"metadata": map[string]any{"name": "default-dsc"},
+				"status":   map[string]any{"conditions": tt.conditions},
+			})
+			got := operatorHealth(client, context.Background(), OperatorResource{Name: "odh", Resource: dataScienceClustersGVR, ObjectName: "default-dsc"})
+			assert.Equal(t, tt.want, got.Status)
+		})
+	}
+}
+
+func TestClusterHealthRepository_APIServerUnreachable(t *testing.T) {
+	client := k8mocks.NewMockKubernetesClient(k8mocks.DefaultMockFixtures(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	repo := NewClusterHealthRepository()
+	repo.UseComponents([]ClusterComponent{{Name: "pipelines", Namespace: "odh", Selector: labels.SelectorFromSet(labels.Set{"app": "pipelines"})}}, nil)
+
+	health, err := repo.GetClusterHealth(client, context.Background(), apiServerCheckerFunc(func(context.Context) error {
+		return errors.New("dial tcp 10.0.0.1:6443: connect: connection refused")
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, models.HealthStatusUnavailable, health.Status)
+	assert.False(t, health.APIServer.Reachable)
+	require.Len(t, health.Components, 1)
+	assert.Equal(t, models.HealthStatusUnknown, health.Components[0].Status)
+	assert.NotContains(t, health.Components[0].Message, "10.0.0.1")
+	assert.Empty(t, health.Operators)
+}
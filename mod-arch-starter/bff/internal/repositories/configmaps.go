@@ -0,0 +1,201 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/objectdiff"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var configMapsGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+// ConfigMapRepository lists, reads, creates and updates the ConfigMaps of a namespace, like
+// SecretRepository. Only the keys matching RedactionPolicy.ConfigMapKeys are redacted, and
+// binary data is kept on updates but not returned.
+type ConfigMapRepository struct {
+	objects *DynamicResourceRepository
+	manager string
+	policy  RedactionPolicy
+}
+
+func NewConfigMapRepository() *ConfigMapRepository {
+	r := &ConfigMapRepository{objects: NewDynamicResourceRepository(), manager: DefaultManager}
+	r.objects.RegisterRedactor(configMapsGVR, r.redactsField)
+	return r
+}
+
+// UseManager sets the module name recorded in ManagedByAnnotation, and of the field managers
+// of the writes.
+func (r *ConfigMapRepository) UseManager(name string) {
+	r.manager = name
+	r.objects.UseFieldManager(name)
+}
+
+// UseRedactionPolicy sets the redacted keys, and the reveal check of Get.
+func (r *ConfigMapRepository) UseRedactionPolicy(policy RedactionPolicy) {
+	r.policy = policy
+}
+
+// List returns the ConfigMaps of namespace, redacted and sorted by name. managedOnly keeps the
+// ConfigMaps created by the module.
+func (r *ConfigMapRepository) List(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, namespace string, managedOnly bool) ([]models.ConfigMapModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "ConfigMapRepository.List", attribute.String("k8s.namespace.name", namespace))
+	defer span.End()
+
+	list, err := r.objects.List(client, ctx, identity, configMapsGVR, namespace, metav1.ListOptions{})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	configMaps := []models.ConfigMapModel{}
+	for i := range list.Items {
+		configMap, err := fromUnstructured[corev1.ConfigMap](&list.Items[i])
+		if err != nil {
+			tracing.RecordError(span, err)
+			return nil, err
+		}
+		if managedOnly && configMap.Annotations[ManagedByAnnotation] != r.manager {
+			continue
+		}
+		configMaps = append(configMaps, r.newConfigMapModel(configMap, true))
+	}
+	sort.Slice(configMaps, func(i, j int) bool { return configMaps[i].Name < configMaps[j].Name })
+	return configMaps, nil
+}
+
+// Get returns the named ConfigMap, with its redacted values when reveal is set and the
+// identity may reveal them.
+func (r *ConfigMapRepository) Get(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, namespace, name string, reveal bool) (models.ConfigMapModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "ConfigMapRepository.Get",
+		attribute.String("k8s.namespace.name", namespace),
+		attribute.Bool("bff.reveal", reveal),
+	)
+	defer span.End()
+
+	if reveal {
+		if err := r.policy.checkReveal(client, ctx, identity, configMapsGVR.Resource, namespace, name); err != nil {
+			tracing.RecordError(span, err)
+			return models.ConfigMapModel{}, err
+		}
+	}
+	configMap, err := r.get(client, ctx, identity, namespace, name)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return models.ConfigMapModel{}, err
+	}
+	return r.newConfigMapModel(configMap, !reveal), nil
+}
+
+// Create creates the ConfigMap in namespace, tagged as managed by the module and created by
+// the identity, and returns it redacted.
+func (r *ConfigMapRepository) Create(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, namespace string, model models.ConfigMapModel) (models.ConfigMapModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "ConfigMapRepository.Create", attribute.String("k8s.namespace.name", namespace))
+	defer span.End()
+
+	configMap := &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Name: model.Name, Namespace: namespace, Labels: model.Labels},
+		Data:       model.Data,
+	}
+	tagManaged(&configMap.ObjectMeta, r.manager, identity)
+
+	created, err := r.apply(client, ctx, identity, namespace, configMap, ApplyOptions{CreateOnly: true})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return models.ConfigMapModel{}, err
+	}
+	return r.newConfigMapModel(created, true), nil
+}
+
+// Update applies the data, and the labels when set, of a ConfigMap managed by the module and
+// returns it redacted, a server-side apply like SecretRepository.Update. The keys listed in
+// RedactedKeys keep their current value. When ResourceVersion is set the update fails with a
+// conflict if the ConfigMap changed since.
+func (r *ConfigMapRepository) Update(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, namespace string, model models.ConfigMapModel, force bool) (models.ConfigMapModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "ConfigMapRepository.Update", attribute.String("k8s.namespace.name", namespace))
+	defer span.End()
+
+	configMap, err := r.get(client, ctx, identity, namespace, model.Name)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return models.ConfigMapModel{}, err
+	}
+	if err := checkManaged(&configMap.ObjectMeta, r.manager, configMapsGVR.GroupResource()); err != nil {
+		tracing.RecordError(span, err)
+		return models.ConfigMapModel{}, err
+	}
+
+	applied := &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: appliedMeta(&configMap.ObjectMeta, model.Labels, model.ResourceVersion),
+		Data:       keepRedacted(model.Data, model.RedactedKeys, configMap.Data),
+	}
+
+	updated, err := r.apply(client, ctx, identity, namespace, applied, ApplyOptions{Force: force})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return models.ConfigMapModel{}, err
+	}
+	return r.newConfigMapModel(updated, true), nil
+}
+
+func (r *ConfigMapRepository) get(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, namespace, name string) (*corev1.ConfigMap, error) {
+	obj, err := r.objects.Get(client, ctx, identity, configMapsGVR, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return fromUnstructured[corev1.ConfigMap](obj)
+}
+
+func (r *ConfigMapRepository) apply(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, namespace string, configMap *corev1.ConfigMap, opts ApplyOptions) (*corev1.ConfigMap, error) {
+	obj, err := toUnstructured(configMap)
+	if err != nil {
+		return nil, err
+	}
+	written, err := r.objects.Apply(client, ctx, identity, configMapsGVR, namespace, obj, opts)
+	if err != nil {
+		return nil, err
+	}
+	return fromUnstructured[corev1.ConfigMap](written)
+}
+
+// redactsField reports whether the values of the field at a JSON pointer are redacted: the
+// binary data, and the redacted keys of the data.
+func (r *ConfigMapRepository) redactsField(path string) bool {
+	segments := objectdiff.Segments(path)
+	switch {
+	case len(segments) > 0 && segments[0] == "binaryData":
+		return true
+	case len(segments) > 1 && segments[0] == "data":
+		return r.policy.redactsConfigMapKey(segments[1])
+	}
+	return false
+}
+
+func (r *ConfigMapRepository) newConfigMapModel(configMap *corev1.ConfigMap, redact bool) models.ConfigMapModel {
+	model := models.ConfigMapModel{
+		Name:              configMap.Name,
+		Namespace:         configMap.Namespace,
+		Labels:            configMap.Labels,
+		Data:              make(map[string]string, len(configMap.Data)),
+		ManagedBy:         configMap.Annotations[ManagedByAnnotation],
+		ResourceVersion:   configMap.ResourceVersion,
+		CreationTimestamp: creationTime(&configMap.ObjectMeta),
+	}
+	for _, key := range sortedKeys(configMap.Data) {
+		value := configMap.Data[key]
+		if redact && r.policy.redactsConfigMapKey(key) {
+			value = ""
+			model.RedactedKeys = append(model.RedactedKeys, key)
+		}
+		model.Data[key] = value
+	}
+	return model
+}
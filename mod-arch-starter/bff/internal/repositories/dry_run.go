@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/constants"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/objectdiff"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DryRun collects the changes the writes made with a WithDryRun context would have made.
+// It is safe for concurrent use.
+type DryRun struct {
+	mu      sync.Mutex
+	changes []objectdiff.Change
+}
+
+// WithDryRun returns a context whose creates, updates and patches through the repositories
+// are server-side dry runs: the API server admits and validates them, defaults, mutating
+// webhooks included, but stores nothing. The returned DryRun collects what would change.
+func WithDryRun(ctx context.Context) (context.Context, *DryRun) {
+	dryRun := &DryRun{changes: []objectdiff.Change{}}
+	return context.WithValue(ctx, constants.DryRunKey, dryRun), dryRun
+}
+
+// IsDryRun reports whether ctx was returned by WithDryRun.
+func IsDryRun(ctx context.Context) bool {
+	return dryRunFrom(ctx) != nil
+}
+
+// Changes returns the changes of the writes, by object in the order of the writes.
+func (d *DryRun) Changes() []objectdiff.Change {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]objectdiff.Change{}, d.changes...)
+}
+
+func (d *DryRun) record(changes []objectdiff.Change) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.changes = append(d.changes, changes...)
+}
+
+func dryRunFrom(ctx context.Context) *DryRun {
+	dryRun, _ := ctx.Value(constants.DryRunKey).(*DryRun)
+	return dryRun
+}
+
+// dryRunOption returns the DryRun option of the write requests made with ctx.
+func dryRunOption(ctx context.Context) []string {
+	if !IsDryRun(ctx) {
+		return nil
+	}
+	return []string{metav1.DryRunAll}
+}
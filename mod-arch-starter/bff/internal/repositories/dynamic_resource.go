@@ -0,0 +1,406 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/objectdiff"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/dynamic"
+)
+
+// ResourceValidator checks an object before DynamicResourceRepository creates, updates or
+// patches it. Return a k8serrors.NewInvalid error so the client gets a 422 with the causes.
+type ResourceValidator func(obj *unstructured.Unstructured) error
+
+// FieldRedactor reports whether the values of the field at path, a JSON pointer such as
+// /data/password, are left out of the changes of dry runs (see WithDryRun).
+type FieldRedactor func(path string) bool
+
+// DynamicResourceRepository reads and writes arbitrary resources, typically custom resources
+// such as InferenceServices or Notebooks, by GroupVersionResource through the dynamic client,
+// so modules don't need a typed client or scheme for them.
+//
+// Every call first checks the identity may perform the verb with a SubjectAccessReview, like
+// WatchRepository: the internal client uses the backend credentials, so without the check
+// any user could act with them. Token and impersonation clients are authorized by the API
+// server anyway; UseAccessReview(false) saves the review for them.
+//
+// Writes made with a WithDryRun context are server-side dry runs, and record the changes from
+// the stored object to the dry-run result, reading it with a get access review. Writes are
+// made as the field manager of the module for the identity (see kubernetes.FieldManager).
+type DynamicResourceRepository struct {
+	skipAccessReview bool
+	fieldManager     string
+
+	mu         sync.RWMutex
+	validators map[schema.GroupVersionResource][]ResourceValidator
+	redactors  map[schema.GroupVersionResource][]FieldRedactor
+}
+
+func NewDynamicResourceRepository() *DynamicResourceRepository {
+	return &DynamicResourceRepository{
+		fieldManager: DefaultManager,
+		validators:   map[schema.GroupVersionResource][]ResourceValidator{},
+		redactors:    map[schema.GroupVersionResource][]FieldRedactor{},
+	}
+}
+
+// UseAccessReview enables (the default) or disables the access review run before each call.
+// Only disable it when every client acts as the user, i.e. not with the internal auth method.
+func (r *DynamicResourceRepository) UseAccessReview(enabled bool) {
+	r.skipAccessReview = !enabled
+}
+
+// UseFieldManager sets the module of the field managers of the writes (default
+// DefaultManager).
+func (r *DynamicResourceRepository) UseFieldManager(module string) {
+	r.fieldManager = module
+}
+
+// RegisterValidator adds a validator run on the objects of gvr before they are written.
+func (r *DynamicResourceRepository) RegisterValidator(gvr schema.GroupVersionResource, validator ResourceValidator) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.validators[gvr] = append(r.validators[gvr], validator)
+}
+
+// RegisterRedactor adds a redactor of the dry-run changes of the objects of gvr.
+func (r *DynamicResourceRepository) RegisterRedactor(gvr schema.GroupVersionResource, redactor FieldRedactor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.redactors[gvr] = append(r.redactors[gvr], redactor)
+}
+
+// SchemaValidator returns a ResourceValidator checking objects against the OpenAPI v3 schema
+// of a CustomResourceDefinition version, e.g. crd.Spec.Versions[0].Schema.OpenAPIV3Schema.
+// The API server validates too; this reports problems before anything is sent, with the
+// same messages.
+func SchemaValidator(schema *apiextensionsv1.JSONSchemaProps) (ResourceValidator, error) {
+	var internal apiextensions.JSONSchemaProps
+	if err := apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(schema, &internal, nil); err != nil {
+		return nil, fmt.Errorf("failed to convert schema: %w", err)
+	}
+	validator, _, err := validation.NewSchemaValidator(&internal)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+
+	return func(obj *unstructured.Unstructured) error {
+		errs := validation.ValidateCustomResource(field.NewPath(""), obj.UnstructuredContent(), validator)
+		if len(errs) == 0 {
+			return nil
+		}
+		return k8serrors.NewInvalid(obj.GroupVersionKind().GroupKind(), obj.GetName(), errs)
+	}, nil
+}
+
+func (r *DynamicResourceRepository) List(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	ctx, span := startDynamicSpan(ctx, "DynamicResourceRepository.List", gvr, namespace)
+	defer span.End()
+
+	resource, err := r.resource(client, ctx, identity, "list", gvr, namespace, "")
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	list, err := resource.List(ctx, opts)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, fmt.Errorf("error listing %s: %w", gvr.Resource, err)
+	}
+	return list, nil
+}
+
+func (r *DynamicResourceRepository) Get(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error) {
+	ctx, span := startDynamicSpan(ctx, "DynamicResourceRepository.Get", gvr, namespace)
+	defer span.End()
+
+	resource, err := r.resource(client, ctx, identity, "get", gvr, namespace, name)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	obj, err := resource.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, fmt.Errorf("error getting %s %s: %w", gvr.Resource, name, err)
+	}
+	return obj, nil
+}
+
+// Create creates obj in namespace after running the validators of gvr.
+func (r *DynamicResourceRepository) Create(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, gvr schema.GroupVersionResource, namespace string, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	ctx, span := startDynamicSpan(ctx, "DynamicResourceRepository.Create", gvr, namespace)
+	defer span.End()
+
+	resource, err := r.resource(client, ctx, identity, "create", gvr, namespace, "")
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	if err := r.validate(gvr, obj); err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	created, err := resource.Create(ctx, obj, metav1.CreateOptions{DryRun: dryRunOption(ctx), FieldManager: k8s.FieldManager(r.fieldManager, identity)})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, fmt.Errorf("error creating %s: %w", gvr.Resource, err)
+	}
+	r.recordDryRun(ctx, gvr, nil, created)
+	return created, nil
+}
+
+// Update replaces obj after running the validators of gvr.
+func (r *DynamicResourceRepository) Update(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, gvr schema.GroupVersionResource, namespace string, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	ctx, span := startDynamicSpan(ctx, "DynamicResourceRepository.Update", gvr, namespace)
+	defer span.End()
+
+	resource, err := r.resource(client, ctx, identity, "update", gvr, namespace, obj.GetName())
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	if err := r.validate(gvr, obj); err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	current, err := r.dryRunCurrent(client, ctx, identity, gvr, namespace, obj.GetName())
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	updated, err := resource.Update(ctx, obj, metav1.UpdateOptions{DryRun: dryRunOption(ctx), FieldManager: k8s.FieldManager(r.fieldManager, identity)})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, fmt.Errorf("error updating %s %s: %w", gvr.Resource, obj.GetName(), err)
+	}
+	r.recordDryRun(ctx, gvr, current, updated)
+	return updated, nil
+}
+
+// Patch applies a patch of patchType to the named object. When gvr has validators the patch
+// is first applied as a server-side dry run, and the validators check the patched object.
+func (r *DynamicResourceRepository) Patch(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, gvr schema.GroupVersionResource, namespace, name string, patchType types.PatchType, data []byte) (*unstructured.Unstructured, error) {
+	ctx, span := startDynamicSpan(ctx, "DynamicResourceRepository.Patch", gvr, namespace)
+	defer span.End()
+
+	resource, err := r.resource(client, ctx, identity, "patch", gvr, namespace, name)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	current, err := r.dryRunCurrent(client, ctx, identity, gvr, namespace, name)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	if r.hasValidators(gvr) {
+		patched, err := resource.Patch(ctx, name, patchType, data, metav1.PatchOptions{DryRun: []string{metav1.DryRunAll}, FieldManager: k8s.FieldManager(r.fieldManager, identity)})
+		if err != nil {
+			tracing.RecordError(span, err)
+			return nil, fmt.Errorf("error patching %s %s: %w", gvr.Resource, name, err)
+		}
+		if err := r.validate(gvr, patched); err != nil {
+			tracing.RecordError(span, err)
+			return nil, err
+		}
+		if IsDryRun(ctx) {
+			r.recordDryRun(ctx, gvr, current, patched)
+			return patched, nil
+		}
+	}
+	patched, err := resource.Patch(ctx, name, patchType, data, metav1.PatchOptions{DryRun: dryRunOption(ctx), FieldManager: k8s.FieldManager(r.fieldManager, identity)})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, fmt.Errorf("error patching %s %s: %w", gvr.Resource, name, err)
+	}
+	r.recordDryRun(ctx, gvr, current, patched)
+	return patched, nil
+}
+
+// ApplyOptions are the options of DynamicResourceRepository.Apply.
+type ApplyOptions struct {
+	// Force takes over the fields other field managers own instead of failing with a conflict.
+	Force bool
+	// CreateOnly fails with a 409 when the object exists, like Create.
+	CreateOnly bool
+}
+
+// Apply server-side applies obj to namespace (see kubernetes.Apply), creating it when missing,
+// after running the validators of gvr on the result of a dry run. Prefer it to Update to
+// write the fields the module manages: the fields set by other controllers are kept, and a
+// field another manager set to another value is a conflict unless opts.Force takes it over.
+// The access review checks patch, and create when the object doesn't exist.
+func (r *DynamicResourceRepository) Apply(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, gvr schema.GroupVersionResource, namespace string, obj *unstructured.Unstructured, opts ApplyOptions) (*unstructured.Unstructured, error) {
+	ctx, span := startDynamicSpan(ctx, "DynamicResourceRepository.Apply", gvr, namespace)
+	defer span.End()
+
+	resource, err := r.resource(client, ctx, identity, "patch", gvr, namespace, obj.GetName())
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	_, err = resource.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	exists := err == nil
+	if err != nil && !k8serrors.IsNotFound(err) {
+		tracing.RecordError(span, err)
+		return nil, fmt.Errorf("error getting %s %s: %w", gvr.Resource, obj.GetName(), err)
+	}
+	if exists && opts.CreateOnly {
+		err := k8serrors.NewAlreadyExists(gvr.GroupResource(), obj.GetName())
+		tracing.RecordError(span, err)
+		return nil, fmt.Errorf("error creating %s: %w", gvr.Resource, err)
+	}
+	if !exists {
+		if err := r.review(client, ctx, identity, "create", gvr, namespace, obj.GetName()); err != nil {
+			tracing.RecordError(span, err)
+			return nil, err
+		}
+	}
+	var current *unstructured.Unstructured
+	if exists {
+		if current, err = r.dryRunCurrent(client, ctx, identity, gvr, namespace, obj.GetName()); err != nil {
+			tracing.RecordError(span, err)
+			return nil, err
+		}
+	}
+
+	fieldManager := k8s.FieldManager(r.fieldManager, identity)
+	if r.hasValidators(gvr) {
+		applied, err := k8s.Apply(ctx, resource, obj, fieldManager, k8s.ApplyOptions{Force: opts.Force, DryRun: true})
+		if err != nil {
+			tracing.RecordError(span, err)
+			return nil, fmt.Errorf("error applying %s %s: %w", gvr.Resource, obj.GetName(), err)
+		}
+		if err := r.validate(gvr, applied); err != nil {
+			tracing.RecordError(span, err)
+			return nil, err
+		}
+		if IsDryRun(ctx) {
+			r.recordDryRun(ctx, gvr, current, applied)
+			return applied, nil
+		}
+	}
+	applied, err := k8s.Apply(ctx, resource, obj, fieldManager, k8s.ApplyOptions{Force: opts.Force, DryRun: IsDryRun(ctx)})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, fmt.Errorf("error applying %s %s: %w", gvr.Resource, obj.GetName(), err)
+	}
+	r.recordDryRun(ctx, gvr, current, applied)
+	return applied, nil
+}
+
+func (r *DynamicResourceRepository) Delete(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, gvr schema.GroupVersionResource, namespace, name string) error {
+	ctx, span := startDynamicSpan(ctx, "DynamicResourceRepository.Delete", gvr, namespace)
+	defer span.End()
+
+	resource, err := r.resource(client, ctx, identity, "delete", gvr, namespace, name)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+	if err := resource.Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
+		tracing.RecordError(span, err)
+		return fmt.Errorf("error deleting %s %s: %w", gvr.Resource, name, err)
+	}
+	return nil
+}
+
+// resource runs the access review for verb and returns the dynamic client of gvr in namespace
+// ("" for cluster-scoped resources).
+func (r *DynamicResourceRepository) resource(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, verb string, gvr schema.GroupVersionResource, namespace, name string) (dynamic.ResourceInterface, error) {
+	if err := r.review(client, ctx, identity, verb, gvr, namespace, name); err != nil {
+		return nil, err
+	}
+
+	resource, err := client.DynamicResource(gvr)
+	if err != nil {
+		return nil, err
+	}
+	if namespace == "" {
+		return resource, nil
+	}
+	return resource.Namespace(namespace), nil
+}
+
+// dryRunCurrent returns the stored object a dry run of ctx compares its result with, or nil
+// when ctx is not a dry run.
+func (r *DynamicResourceRepository) dryRunCurrent(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error) {
+	if !IsDryRun(ctx) {
+		return nil, nil
+	}
+	return r.Get(client, ctx, identity, gvr, namespace, name)
+}
+
+// recordDryRun records the redacted changes from current to written in the DryRun of ctx.
+func (r *DynamicResourceRepository) recordDryRun(ctx context.Context, gvr schema.GroupVersionResource, current, written *unstructured.Unstructured) {
+	dryRun := dryRunFrom(ctx)
+	if dryRun == nil {
+		return
+	}
+	r.mu.RLock()
+	redactors := r.redactors[gvr]
+	r.mu.RUnlock()
+
+	changes := objectdiff.Compare(current, written)
+	for _, redact := range redactors {
+		objectdiff.Redact(changes, redact)
+	}
+	dryRun.record(changes)
+}
+
+// review fails with a 403 unless the identity may perform verb on gvr in namespace.
+func (r *DynamicResourceRepository) review(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, verb string, gvr schema.GroupVersionResource, namespace, name string) error {
+	if r.skipAccessReview {
+		return nil
+	}
+	allowed, err := client.CanAccess(ctx, identity, verb, gvr.Group, gvr.Resource, namespace)
+	if err != nil {
+		return fmt.Errorf("error checking permission: %w", err)
+	}
+	if !allowed {
+		return k8serrors.NewForbidden(gvr.GroupResource(), name, fmt.Errorf("user cannot %s %s in namespace %q", verb, gvr.Resource, namespace))
+	}
+	return nil
+}
+
+func (r *DynamicResourceRepository) hasValidators(gvr schema.GroupVersionResource) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.validators[gvr]) > 0
+}
+
+func (r *DynamicResourceRepository) validate(gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+	r.mu.RLock()
+	validators := r.validators[gvr]
+	r.mu.RUnlock()
+
+	for _, validate := range validators {
+		if err := validate(obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func startDynamicSpan(ctx context.Context, name string, gvr schema.GroupVersionResource, namespace string) (context.Context, trace.Span) {
+	return tracing.StartSpan(ctx, name,
+		attribute.String("k8s.resource", gvr.String()),
+		attribute.String("k8s.namespace.name", namespace),
+	)
+}
@@ -0,0 +1,148 @@
+package repositories
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes/k8mocks"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/objectdiff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var notebooksGVR = schema.GroupVersionResource{Group: "kubeflow.org", Version: "v1", Resource: "notebooks"}
+
+func notebook(name, namespace string, spec map[string]any) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "kubeflow.org/v1",
+		"kind":       "Notebook",
+		"metadata":   map[string]any{"name": name, "namespace": namespace},
+		"spec":       spec,
+	}}
+}
+
+func TestDynamicResourceRepository(t *testing.T) {
+	client := k8mocks.NewMockKubernetesClient(k8mocks.DefaultMockFixtures(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	dora := &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com"}
+	ctx := context.Background()
+	repo := NewDynamicResourceRepository()
+
+	created, err := repo.Create(client, ctx, dora, notebooksGVR, "dora-namespace", notebook("workbench", "dora-namespace", map[string]any{"image": "jupyter"}))
+	require.NoError(t, err)
+	assert.Equal(t, "workbench", created.GetName())
+
+	list, err := repo.List(client, ctx, dora, notebooksGVR, "dora-namespace", metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+
+	patched, err := repo.Patch(client, ctx, dora, notebooksGVR, "dora-namespace", "workbench", types.MergePatchType, []byte(`{"spec":{"image":"codeserver"}}`))
+	require.NoError(t, err)
+	image, _, _ := unstructured.NestedString(patched.Object, "spec", "image")
+	assert.Equal(t, "codeserver", image)
+
+	got, err := repo.Get(client, ctx, dora, notebooksGVR, "dora-namespace", "workbench")
+	require.NoError(t, err)
+	assert.Equal(t, patched.Object["spec"], got.Object["spec"])
+
+	require.NoError(t, repo.Delete(client, ctx, dora, notebooksGVR, "dora-namespace", "workbench"))
+	_, err = repo.Get(client, ctx, dora, notebooksGVR, "dora-namespace", "workbench")
+	assert.True(t, k8serrors.IsNotFound(err), "got %v", err)
+}
+
+func TestDynamicResourceRepository_AccessReview(t *testing.T) {
+	client := k8mocks.NewMockKubernetesClient(k8mocks.DefaultMockFixtures(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	dora := &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com"}
+	repo := NewDynamicResourceRepository()
+
+	_, err := repo.List(client, context.Background(), dora, notebooksGVR, "bella-namespace", metav1.ListOptions{})
+	assert.True(t, k8serrors.IsForbidden(err), "got %v", err)
+
+	repo.UseAccessReview(false)
+	_, err = repo.List(client, context.Background(), dora, notebooksGVR, "bella-namespace", metav1.ListOptions{})
+	assert.NoError(t, err)
+}
+
+func TestDynamicResourceRepository_SchemaValidation(t *testing.T) {
+	client := k8mocks.NewMockKubernetesClient(k8mocks.DefaultMockFixtures(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	admin := &k8s.RequestIdentity{UserID: "user@example.com"}
+	repo := NewDynamicResourceRepository()
+
+	validator, err := SchemaValidator(&apiextensionsv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextensionsv1.JSONSchemaProps{
+			"spec": {
+				Type:     "object",
+				Required: []string{"image"},
+				Properties: map[string]apiextensionsv1.JSONSchemaProps{
+					"image": {Type: "string"},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	repo.RegisterValidator(notebooksGVR, validator)
+
+	_, err = repo.Create(client, context.Background(), admin, notebooksGVR, "kubeflow", notebook("broken", "kubeflow", map[string]any{"image": 42}))
+	require.Error(t, err)
+	assert.True(t, k8serrors.IsInvalid(err), "got %v", err)
+
+	_, err = repo.Create(client, context.Background(), admin, notebooksGVR, "kubeflow", notebook("valid", "kubeflow", map[string]any{"image": "jupyter"}))
+	require.NoError(t, err)
+
+	_, err = repo.Patch(client, context.Background(), admin, notebooksGVR, "kubeflow", "valid", types.MergePatchType, []byte(`{"spec":{"image":null}}`))
+	assert.True(t, k8serrors.IsInvalid(err), "got %v", err)
+}
+
+func TestDynamicResourceRepository_DryRun(t *testing.T) {
+	client := k8mocks.NewMockKubernetesClient(k8mocks.DefaultMockFixtures(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	dora := &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com"}
+	repo := NewDynamicResourceRepository()
+	repo.RegisterRedactor(notebooksGVR, func(path string) bool { return path == "/spec/token" })
+
+	ctx, dryRun := WithDryRun(context.Background())
+	created, err := repo.Create(client, ctx, dora, notebooksGVR, "dora-namespace", notebook("workbench", "dora-namespace", map[string]any{"image": "jupyter", "token": "t0"}))
+	require.NoError(t, err)
+	assert.Equal(t, "workbench", created.GetName())
+	_, err = repo.Get(client, context.Background(), dora, notebooksGVR, "dora-namespace", "workbench")
+	assert.True(t, k8serrors.IsNotFound(err), "nothing is created, got %v", err)
+	assert.Contains(t, dryRun.Changes(), objectdiff.Change{Op: objectdiff.OpAdd, Path: "/spec/image", Value: "jupyter"})
+	assert.Contains(t, dryRun.Changes(), objectdiff.Change{Op: objectdiff.OpAdd, Path: "/spec/token", Redacted: true})
+
+	_, err = repo.Create(client, context.Background(), dora, notebooksGVR, "dora-namespace", notebook("workbench", "dora-namespace", map[string]any{"image": "jupyter"}))
+	require.NoError(t, err)
+
+	ctx, dryRun = WithDryRun(context.Background())
+	patched, err := repo.Patch(client, ctx, dora, notebooksGVR, "dora-namespace", "workbench", types.MergePatchType, []byte(`{"spec":{"image":"codeserver"}}`))
+	require.NoError(t, err)
+	image, _, _ := unstructured.NestedString(patched.Object, "spec", "image")
+	assert.Equal(t, "codeserver", image)
+	assert.Equal(t, []objectdiff.Change{{Op: objectdiff.OpReplace, Path: "/spec/image", OldValue: "jupyter", Value: "codeserver"}}, dryRun.Changes())
+
+	stored, err := repo.Get(client, context.Background(), dora, notebooksGVR, "dora-namespace", "workbench")
+	require.NoError(t, err)
+	image, _, _ = unstructured.NestedString(stored.Object, "spec", "image")
+	assert.Equal(t, "jupyter", image, "the patch is not stored")
+
+	ctx, dryRun = WithDryRun(context.Background())
+	update := stored.DeepCopy()
+	_ = unstructured.SetNestedField(update.Object, "a", "metadata", "labels", "team")
+	_, err = repo.Update(client, ctx, dora, notebooksGVR, "dora-namespace", update)
+	require.NoError(t, err)
+	assert.Equal(t, []objectdiff.Change{{Op: objectdiff.OpAdd, Path: "/metadata/labels/team", Value: "a"}}, dryRun.Changes())
+	stored, err = repo.Get(client, context.Background(), dora, notebooksGVR, "dora-namespace", "workbench")
+	require.NoError(t, err)
+	assert.Empty(t, stored.GetLabels(), "the update is not stored")
+
+	ctx, _ = WithDryRun(context.Background())
+	_, err = repo.Create(client, ctx, dora, notebooksGVR, "dora-namespace", notebook("workbench", "dora-namespace", nil))
+	assert.True(t, k8serrors.IsAlreadyExists(err), "dry runs are checked, got %v", err)
+}
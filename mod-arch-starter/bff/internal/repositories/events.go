@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// EventObject is the namespaced resource whose events are listed. UID is optional and limits
+// the events to one incarnation of the resource, e.g. not those of a deleted pod of the same name.
+type EventObject struct {
+	Kind string
+	Name string
+	UID  string
+}
+
+// EventsRepository lists the Kubernetes Events about a resource, for troubleshooting in the UI.
+type EventsRepository struct{}
+
+func NewEventsRepository() *EventsRepository {
+	return &EventsRepository{}
+}
+
+// GetEvents lists the events about object in namespace, most recent first. The identity must
+// be allowed to list events in the namespace.
+func (r *EventsRepository) GetEvents(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, namespace string, object EventObject) ([]models.EventModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "EventsRepository.GetEvents",
+		attribute.String("k8s.namespace.name", namespace),
+		attribute.String("k8s.object.kind", object.Kind),
+		attribute.String("k8s.object.name", object.Name),
+	)
+	defer span.End()
+
+	allowed, err := client.CanAccess(ctx, identity, "list", "", "events", namespace)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, fmt.Errorf("error checking access to events: %w", err)
+	}
+	if !allowed {
+		err := k8serrors.NewForbidden(schema.GroupResource{Resource: "events"}, "", fmt.Errorf("user cannot list events in namespace %s", namespace))
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	selector := fields.Set{
+		"involvedObject.kind": object.Kind,
+		"involvedObject.name": object.Name,
+	}
+	if object.UID != "" {
+		selector["involvedObject.uid"] = object.UID
+	}
+	events, err := client.Reader().ListEvents(ctx, namespace, fields.SelectorFromSet(selector))
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, fmt.Errorf("error fetching events: %w", err)
+	}
+
+	eventModels := make([]models.EventModel, 0, len(events))
+	for i := range events {
+		eventModels = append(eventModels, newEventModel(&events[i]))
+	}
+	sort.SliceStable(eventModels, func(i, j int) bool {
+		return timeOf(eventModels[i].LastTimestamp).After(timeOf(eventModels[j].LastTimestamp))
+	})
+	span.SetAttributes(attribute.Int("bff.events.count", len(eventModels)))
+	return eventModels, nil
+}
+
+// newEventModel normalizes the fields of core/v1 events, which differ whether they were
+// recorded with the legacy API (count, firstTimestamp, lastTimestamp) or with events.k8s.io/v1
+// (eventTime and series).
+func newEventModel(event *corev1.Event) models.EventModel {
+	model := models.EventModel{
+		Name:    event.Name,
+		Type:    event.Type,
+		Reason:  event.Reason,
+		Message: event.Message,
+		Count:   max(event.Count, 1),
+		Source:  event.Source.Component,
+		InvolvedObject: models.EventObjectModel{
+			Kind:      event.InvolvedObject.Kind,
+			Name:      event.InvolvedObject.Name,
+			FieldPath: event.InvolvedObject.FieldPath,
+		},
+	}
+	if model.Source == "" {
+		model.Source = event.ReportingController
+	}
+
+	first := firstTime(event.FirstTimestamp.Time, event.EventTime.Time, event.CreationTimestamp.Time)
+	last := firstTime(event.LastTimestamp.Time, first)
+	if event.Series != nil {
+		model.Count = max(model.Count, event.Series.Count)
+		last = firstTime(event.Series.LastObservedTime.Time, last)
+	}
+	if !first.IsZero() {
+		model.FirstTimestamp = &first
+	}
+	if !last.IsZero() {
+		model.LastTimestamp = &last
+	}
+	return model
+}
+
+// firstTime returns the first non-zero time, or the zero time.
+func firstTime(times ...time.Time) time.Time {
+	for _, t := range times {
+		if !t.IsZero() {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func timeOf(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes/k8mocks"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEventsRepository_GetEvents(t *testing.T) {
+	ctx := context.Background()
+	client := k8mocks.NewMockKubernetesClient(k8mocks.DefaultMockFixtures(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	repo := NewEventsRepository()
+	dora := &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com"}
+	pod := EventObject{Kind: "Pod", Name: "mod-arch-dora-7c9d8-x2k4p"}
+
+	events, err := repo.GetEvents(client, ctx, dora, "dora-namespace", pod)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "BackOff", events[0].Reason, "most recent first")
+	assert.Equal(t, models.EventTypeWarning, events[0].Type)
+	assert.Equal(t, int32(5), events[0].Count)
+	assert.Equal(t, 4*time.Minute, events[0].LastTimestamp.Sub(*events[0].FirstTimestamp))
+	assert.Equal(t, models.EventObjectModel{Kind: "Pod", Name: "mod-arch-dora-7c9d8-x2k4p"}, events[0].InvolvedObject)
+	assert.Equal(t, "Scheduled", events[1].Reason)
+
+	events, err = repo.GetEvents(client, ctx, dora, "dora-namespace", EventObject{Kind: "Deployment", Name: "mod-arch-dora-7c9d8-x2k4p"})
+	require.NoError(t, err)
+	assert.Empty(t, events, "the kind must match too")
+
+	_, err = repo.GetEvents(client, ctx, dora, "bella-namespace", EventObject{Kind: "Deployment", Name: "mod-arch-bella"})
+	assert.True(t, k8serrors.IsForbidden(err))
+}
+
+func TestNewEventModel(t *testing.T) {
+	created := time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)
+	lastObserved := created.Add(10 * time.Minute)
+
+	// Recorded with events.k8s.io/v1: no count or timestamps, but an event time and a series
+	model := newEventModel(&corev1.Event{
+		ObjectMeta:          metav1.ObjectMeta{Name: "e", CreationTimestamp: metav1.NewTime(created)},
+		Type:                corev1.EventTypeWarning,
+		Reason:              "Unhealthy",
+		EventTime:           metav1.NewMicroTime(created),
+		Series:              &corev1.EventSeries{Count: 3, LastObservedTime: metav1.NewMicroTime(lastObserved)},
+		ReportingController: "kubelet",
+	})
+	assert.Equal(t, int32(3), model.Count)
+	assert.Equal(t, created, model.FirstTimestamp.UTC())
+	assert.Equal(t, lastObserved, model.LastTimestamp.UTC())
+	assert.Equal(t, "kubelet", model.Source)
+
+	model = newEventModel(&corev1.Event{ObjectMeta: metav1.ObjectMeta{Name: "e", CreationTimestamp: metav1.NewTime(created)}})
+	assert.Equal(t, int32(1), model.Count)
+	assert.Equal(t, created, model.FirstTimestamp.UTC(), "falls back to the creation time")
+	assert.Equal(t, created, model.LastTimestamp.UTC())
+}
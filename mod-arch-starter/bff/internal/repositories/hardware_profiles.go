@@ -0,0 +1,203 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/odhdashboard"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/parallel"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var nodesGVR = schema.GroupVersionResource{Version: "v1", Resource: "nodes"}
+
+// acceleratorResources are the extended resources counted as accelerators on the nodes, besides
+// the Accelerator resources of the profiles.
+var acceleratorResources = []string{"nvidia.com/gpu", "amd.com/gpu", "habana.ai/gaudi", "intel.com/gpu"}
+
+// acceleratorProductLabels are the node labels naming the model of the accelerators of an
+// extended resource, set by the feature discovery of their operator.
+var acceleratorProductLabels = map[string]string{
+	"nvidia.com/gpu": "nvidia.com/gpu.product",
+	"amd.com/gpu":    "amd.com/gpu.product-name",
+}
+
+// HardwareProfileRepository lists what the resource pickers of the UI offer: the
+// HardwareProfiles and AcceleratorProfiles of the platform, normalized into one model, and the
+// accelerators of the schedulable nodes with their allocatable counts.
+//
+// The profiles are read through the odhdashboard client and missing on clusters without the
+// dashboard. Nodes are read with the client's credentials once an access review allows the
+// identity to list them; otherwise the accelerators are not reported.
+type HardwareProfileRepository struct{}
+
+func NewHardwareProfileRepository() *HardwareProfileRepository {
+	return &HardwareProfileRepository{}
+}
+
+// GetHardwareProfiles returns the profiles, hardware profiles first, and the accelerator
+// capacity of the nodes, read in parallel.
+func (r *HardwareProfileRepository) GetHardwareProfiles(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, dashboard *odhdashboard.Client) (models.HardwareProfilesModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "HardwareProfileRepository.GetHardwareProfiles")
+	defer span.End()
+
+	var hardwareProfiles []odhdashboard.HardwareProfile
+	var acceleratorProfiles []odhdashboard.AcceleratorProfile
+	var nodes []corev1.Node
+	var nodesVisible bool
+	err := parallel.Do(ctx, parallel.Options{},
+		func(ctx context.Context) error {
+			var err error
+			if hardwareProfiles, err = dashboard.HardwareProfiles(ctx); err != nil && !errors.Is(err, odhdashboard.ErrNotInstalled) {
+				return err
+			}
+			return nil
+		},
+		func(ctx context.Context) error {
+			var err error
+			if acceleratorProfiles, err = dashboard.AcceleratorProfiles(ctx); err != nil && !errors.Is(err, odhdashboard.ErrNotInstalled) {
+				return err
+			}
+			return nil
+		},
+		func(ctx context.Context) error {
+			var err error
+			if nodesVisible, err = client.CanAccess(ctx, identity, "list", "", "nodes", ""); err != nil {
+				return fmt.Errorf("error checking access to nodes: %w", err)
+			}
+			if !nodesVisible {
+				return nil
+			}
+			if nodes, err = listObjects[corev1.Node](client, ctx, nodesGVR, ""); err != nil {
+				return fmt.Errorf("failed to list nodes: %w", err)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return models.HardwareProfilesModel{}, err
+	}
+
+	result := models.HardwareProfilesModel{Profiles: []models.HardwareProfile{}}
+	accelerators := map[string]bool{}
+	for _, resource := range acceleratorResources {
+		accelerators[resource] = true
+	}
+	sort.Slice(hardwareProfiles, func(i, j int) bool { return hardwareProfiles[i].Name < hardwareProfiles[j].Name })
+	for _, profile := range hardwareProfiles {
+		normalized := models.HardwareProfile{
+			Name:           profile.Name,
+			DisplayName:    profile.DisplayName,
+			Description:    profile.Description,
+			Source:         models.HardwareProfileSourceHardwareProfile,
+			Enabled:        profile.Enabled,
+			Resources:      make([]models.HardwareResource, 0, len(profile.Identifiers)),
+			NodeSelector:   profile.NodeSelector,
+			Tolerations:    profile.Tolerations,
+			LocalQueueName: profile.LocalQueueName,
+		}
+		for _, identifier := range profile.Identifiers {
+			resource := models.HardwareResource{
+				Identifier:   identifier.Identifier,
+				DisplayName:  identifier.DisplayName,
+				ResourceType: hardwareResourceType(identifier),
+				MinCount:     identifier.MinCount.String(),
+				DefaultCount: identifier.DefaultCount.String(),
+			}
+			if identifier.MaxCount != nil {
+				resource.MaxCount = identifier.MaxCount.String()
+			}
+			if resource.ResourceType == models.HardwareResourceAccelerator {
+				accelerators[resource.Identifier] = true
+			}
+			normalized.Resources = append(normalized.Resources, resource)
+		}
+		result.Profiles = append(result.Profiles, normalized)
+	}
+	sort.Slice(acceleratorProfiles, func(i, j int) bool { return acceleratorProfiles[i].Name < acceleratorProfiles[j].Name })
+	for _, profile := range acceleratorProfiles {
+		accelerators[profile.Identifier] = true
+		result.Profiles = append(result.Profiles, models.HardwareProfile{
+			Name:        profile.Name,
+			DisplayName: profile.DisplayName,
+			Description: profile.Description,
+			Source:      models.HardwareProfileSourceAcceleratorProfile,
+			Enabled:     profile.Enabled,
+			Resources: []models.HardwareResource{{
+				Identifier:   profile.Identifier,
+				DisplayName:  profile.DisplayName,
+				ResourceType: models.HardwareResourceAccelerator,
+				MinCount:     "1",
+				DefaultCount: "1",
+			}},
+			Tolerations: profile.Tolerations,
+		})
+	}
+
+	if nodesVisible {
+		result.Accelerators = acceleratorCapacity(nodes, accelerators)
+	}
+	span.SetAttributes(
+		attribute.Int("bff.hardware_profiles.count", len(result.Profiles)),
+		attribute.Int("bff.hardware_profiles.accelerators", len(result.Accelerators)),
+	)
+	return result, nil
+}
+
+// hardwareResourceType is the resource type of identifier, guessed from the resource when the
+// profile doesn't set it.
+func hardwareResourceType(identifier odhdashboard.HardwareIdentifier) string {
+	switch {
+	case identifier.ResourceType != "":
+		return identifier.ResourceType
+	case identifier.Identifier == string(corev1.ResourceCPU):
+		return models.HardwareResourceCPU
+	case identifier.Identifier == string(corev1.ResourceMemory):
+		return models.HardwareResourceMemory
+	default:
+		return models.HardwareResourceAccelerator
+	}
+}
+
+// acceleratorCapacity sums the allocatable accelerators of the schedulable nodes per resource
+// and product, sorted by resource then product.
+func acceleratorCapacity(nodes []corev1.Node, accelerators map[string]bool) []models.AcceleratorCapacity {
+	capacity := []models.AcceleratorCapacity{}
+	index := map[[2]string]int{}
+	for _, node := range nodes {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		for name, quantity := range node.Status.Allocatable {
+			count := quantity.Value()
+			if !accelerators[string(name)] || count <= 0 {
+				continue
+			}
+			key := [2]string{string(name), node.Labels[acceleratorProductLabels[string(name)]]}
+			i, ok := index[key]
+			if !ok {
+				i = len(capacity)
+				index[key] = i
+				capacity = append(capacity, models.AcceleratorCapacity{Identifier: key[0], Product: key[1]})
+			}
+			capacity[i].Nodes++
+			capacity[i].Allocatable += count
+			capacity[i].MaxPerNode = max(capacity[i].MaxPerNode, count)
+		}
+	}
+	sort.Slice(capacity, func(i, j int) bool {
+		if capacity[i].Identifier != capacity[j].Identifier {
+			return capacity[i].Identifier < capacity[j].Identifier
+		}
+		return capacity[i].Product < capacity[j].Product
+	})
+	return capacity
+}
@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes/k8mocks"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/odhdashboard"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func nodeObject(name string, unschedulable bool, labels map[string]any, allocatable map[string]any) map[string]any {
+	return map[string]any{
+		"apiVersion": "v1", "kind": "Node",
+		"metadata": map[string]any{"name": name, "labels": labels},
+		"spec":     map[string]any{"unschedulable": unschedulable},
+		"status":   map[string]any{"allocatable": allocatable},
+	}
+}
+
+func TestHardwareProfileRepository(t *testing.T) {
+	client := k8mocks.NewMockKubernetesClient(k8mocks.DefaultMockFixtures(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	seed(t, client, odhdashboard.HardwareProfilesGVR, "opendatahub", map[string]any{
+		"apiVersion": "infrastructure.opendatahub.io/v1", "kind": "HardwareProfile",
+		"metadata": map[string]any{"name": "gpu-large", "namespace": "opendatahub", "annotations": map[string]any{
+			odhdashboard.DisplayNameAnnotation: "Large GPU",
+		}},
+		"spec": map[string]any{
+			"identifiers": []any{
+				map[string]any{"identifier": "cpu", "displayName": "CPU", "minCount": int64(2), "defaultCount": int64(4), "maxCount": int64(8)},
+				map[string]any{"identifier": "memory", "displayName": "Memory", "minCount": "8Gi", "defaultCount": "16Gi"},
+				map[string]any{"identifier": "nvidia.com/gpu", "displayName": "GPU", "minCount": int64(1), "defaultCount": int64(1), "resourceType": "Accelerator"},
+			},
+			"scheduling": map[string]any{"type": "Node", "node": map[string]any{"nodeSelector": map[string]any{"gpu": "a100"}}},
+		},
+	})
+	seed(t, client, odhdashboard.AcceleratorProfilesGVR, "opendatahub", map[string]any{
+		"apiVersion": "dashboard.opendatahub.io/v1", "kind": "AcceleratorProfile",
+		"metadata": map[string]any{"name": "gaudi", "namespace": "opendatahub"},
+		"spec":     map[string]any{"displayName": "Intel Gaudi", "identifier": "habana.ai/gaudi", "enabled": false},
+	})
+	seed(t, client, nodesGVR, "", nodeObject("gpu-1", false, map[string]any{"nvidia.com/gpu.product": "NVIDIA-A100"}, map[string]any{"cpu": "32", "nvidia.com/gpu": "4"}))
+	seed(t, client, nodesGVR, "", nodeObject("gpu-2", false, map[string]any{"nvidia.com/gpu.product": "NVIDIA-A100"}, map[string]any{"nvidia.com/gpu": "2"}))
+	seed(t, client, nodesGVR, "", nodeObject("gpu-3", false, map[string]any{"nvidia.com/gpu.product": "Tesla-T4"}, map[string]any{"nvidia.com/gpu": "1"}))
+	seed(t, client, nodesGVR, "", nodeObject("gpu-cordoned", true, map[string]any{"nvidia.com/gpu.product": "Tesla-T4"}, map[string]any{"nvidia.com/gpu": "8"}))
+	seed(t, client, nodesGVR, "", nodeObject("gaudi-1", false, nil, map[string]any{"habana.ai/gaudi": "8"}))
+	seed(t, client, nodesGVR, "", nodeObject("cpu-1", false, nil, map[string]any{"cpu": "16", "nvidia.com/gpu": "0"}))
+
+	repo := NewHardwareProfileRepository()
+	dashboard := odhdashboard.NewClient(client, odhdashboard.Options{Namespace: "opendatahub"})
+	admin := &k8s.RequestIdentity{UserID: "user@example.com"}
+
+	result, err := repo.GetHardwareProfiles(client, context.Background(), admin, dashboard)
+	require.NoError(t, err)
+	assert.Equal(t, []models.HardwareProfile{
+		{
+			Name: "gpu-large", DisplayName: "Large GPU", Source: models.HardwareProfileSourceHardwareProfile, Enabled: true,
+			Resources: []models.HardwareResource{
+				{Identifier: "cpu", DisplayName: "CPU", ResourceType: models.HardwareResourceCPU, MinCount: "2", DefaultCount: "4", MaxCount: "8"},
+				{Identifier: "memory", DisplayName: "Memory", ResourceType: models.HardwareResourceMemory, MinCount: "8Gi", DefaultCount: "16Gi"},
+				{Identifier: "nvidia.com/gpu", DisplayName: "GPU", ResourceType: models.HardwareResourceAccelerator, MinCount: "1", DefaultCount: "1"},
+			},
+			NodeSelector: map[string]string{"gpu": "a100"},
+		},
+		{
+			Name: "gaudi", DisplayName: "Intel Gaudi", Source: models.HardwareProfileSourceAcceleratorProfile,
+			Resources: []models.HardwareResource{
+				{Identifier: "habana.ai/gaudi", DisplayName: "Intel Gaudi", ResourceType: models.HardwareResourceAccelerator, MinCount: "1", DefaultCount: "1"},
+			},
+		},
+	}, result.Profiles)
+	assert.Equal(t, []models.AcceleratorCapacity{
+		{Identifier: "habana.ai/gaudi", Nodes: 1, Allocatable: 8, MaxPerNode: 8},
+		{Identifier: "nvidia.com/gpu", Product: "NVIDIA-A100", Nodes: 2, Allocatable: 6, MaxPerNode: 4},
+		{Identifier: "nvidia.com/gpu", Product: "Tesla-T4", Nodes: 1, Allocatable: 1, MaxPerNode: 1},
+	}, result.Accelerators)
+
+	// Non-admins get the profiles, not the nodes
+	result, err = repo.GetHardwareProfiles(client, context.Background(), &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com"}, dashboard)
+	require.NoError(t, err)
+	assert.Len(t, result.Profiles, 2)
+	assert.Nil(t, result.Accelerators)
+}
+
+func TestHardwareProfileRepository_NoProfiles(t *testing.T) {
+	client := k8mocks.NewMockKubernetesClient(k8mocks.DefaultMockFixtures(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	dashboard := odhdashboard.NewClient(client, odhdashboard.Options{Namespace: "opendatahub"})
+
+	result, err := NewHardwareProfileRepository().GetHardwareProfiles(client, context.Background(), &k8s.RequestIdentity{UserID: "user@example.com"}, dashboard)
+	require.NoError(t, err)
+	assert.Equal(t, models.HardwareProfilesModel{Profiles: []models.HardwareProfile{}, Accelerators: []models.AcceleratorCapacity{}}, result)
+}
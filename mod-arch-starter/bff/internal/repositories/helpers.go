@@ -1,8 +1,14 @@
 package repositories
 
 import (
+	"context"
 	"fmt"
 	"net/url"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func FilterPageValues(values url.Values) url.Values {
@@ -36,3 +42,23 @@ func UrlWithPageParams(url string, values url.Values) string {
 	pageValues := FilterPageValues(values)
 	return UrlWithParams(url, pageValues)
 }
+
+// listObjects lists the objects of gvr in namespace (cluster-wide when empty) with the
+// client's credentials, converted into T.
+func listObjects[T any](client k8s.KubernetesClientInterface, ctx context.Context, gvr schema.GroupVersionResource, namespace string) ([]T, error) {
+	resource, err := client.DynamicResource(gvr)
+	if err != nil {
+		return nil, err
+	}
+	list, err := resource.Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	objects := make([]T, len(list.Items))
+	for i := range list.Items {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(list.Items[i].Object, &objects[i]); err != nil {
+			return nil, fmt.Errorf("failed to convert %s %s: %w", gvr.Resource, list.Items[i].GetName(), err)
+		}
+	}
+	return objects, nil
+}
@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"golang.org/x/sync/singleflight"
+	utilcache "k8s.io/apimachinery/pkg/util/cache"
+)
+
+const (
+	// LookupUser names the GetUser lookups of the UserRepository.
+	LookupUser = "user"
+)
+
+const (
+	// IdentityLookupHit is a lookup answered from the cache.
+	IdentityLookupHit = "hit"
+	// IdentityLookupMiss is a lookup that called the API server.
+	IdentityLookupMiss = "miss"
+	// IdentityLookupShared is a lookup that waited for the result of a concurrent miss.
+	IdentityLookupShared = "shared"
+)
+
+// IdentityCache memoizes the lookups derived from the identity of a request, such as GetUser,
+// which nearly every page load makes. Concurrent lookups of one identity share a single round
+// trip to the API server, and their results are kept for TTL in a bounded LRU; a zero TTL only
+// shares the concurrent lookups. Errors are never cached. A nil *IdentityCache looks up every
+// time.
+type IdentityCache struct {
+	ttl     time.Duration
+	entries *utilcache.LRUExpireCache
+	flights singleflight.Group
+	observe func(lookup, result string)
+}
+
+// NewIdentityCache returns a cache keeping up to maxEntries results for ttl.
+func NewIdentityCache(ttl time.Duration, maxEntries int) *IdentityCache {
+	if maxEntries <= 0 {
+		maxEntries = config.DefaultIdentityCacheMaxEntries
+	}
+	return &IdentityCache{ttl: ttl, entries: utilcache.NewLRUExpireCache(maxEntries)}
+}
+
+// Observe calls fn on every lookup with its result (IdentityLookupHit, IdentityLookupMiss or
+// IdentityLookupShared), e.g. to count the dedupe rate. Call it before the cache is used.
+func (c *IdentityCache) Observe(fn func(lookup, result string)) {
+	if c != nil {
+		c.observe = fn
+	}
+}
+
+func (c *IdentityCache) record(lookup, result string) {
+	if c.observe != nil {
+		c.observe(lookup, result)
+	}
+}
+
+// lookupIdentity returns the cached result of lookup for identity in the cluster of ctx, or
+// loads it once for the concurrent callers. The shared load isn't canceled with the request
+// that started it.
+func lookupIdentity[T any](ctx context.Context, c *IdentityCache, lookup string, identity *k8s.RequestIdentity, load func(ctx context.Context) (T, error)) (T, error) {
+	if c == nil || identity == nil {
+		return load(ctx)
+	}
+	key := lookup + "\x00" + k8s.ClusterFromContext(ctx) + "\x00" + identityCacheKey(identity)
+	if value, ok := c.entries.Get(key); ok {
+		c.record(lookup, IdentityLookupHit)
+		return value.(T), nil
+	}
+
+	loaded := false
+	value, err, _ := c.flights.Do(key, func() (any, error) {
+		loaded = true
+		value, err := load(context.WithoutCancel(ctx))
+		if err == nil && c.ttl > 0 {
+			c.entries.Add(key, value, c.ttl)
+		}
+		return value, err
+	})
+	if loaded {
+		c.record(lookup, IdentityLookupMiss)
+	} else {
+		c.record(lookup, IdentityLookupShared)
+	}
+	return value.(T), err
+}
+
+// identityCacheKey identifies the user, groups and token of identity without keeping the
+// token in memory, whatever the order of the groups.
+func identityCacheKey(identity *k8s.RequestIdentity) string {
+	groups := slices.Clone(identity.Groups)
+	slices.Sort(groups)
+	sum := sha256.Sum256([]byte(identity.UserID + "\x00" + strings.Join(groups, "\x00") + "\x00" + identity.Token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// LogsRepository streams pod logs on behalf of the requesting identity. Like watches,
+// authorization is enforced by the client before the stream opens: a "get" SubjectAccessReview
+// on pods/log for the internal auth method, the API server itself for token and impersonation
+// clients.
+type LogsRepository struct{}
+
+func NewLogsRepository() *LogsRepository {
+	return &LogsRepository{}
+}
+
+func (r *LogsRepository) StreamPodLogs(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, namespace, pod string, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+	ctx, span := tracing.StartSpan(ctx, "LogsRepository.StreamPodLogs",
+		attribute.String("k8s.namespace.name", namespace),
+		attribute.String("k8s.pod.name", pod),
+		attribute.String("k8s.container.name", opts.Container),
+	)
+	defer span.End()
+
+	logs, err := client.StreamPodLogs(ctx, identity, namespace, pod, opts)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, fmt.Errorf("error streaming logs of pod %s: %w", pod, err)
+	}
+	return logs, nil
+}
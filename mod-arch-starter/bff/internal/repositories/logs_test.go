@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func TestLogsRepository_StreamPodLogs(t *testing.T) {
+	testutil.RequireEnv(t, testEnv)
+	ctx := context.Background()
+	repo := NewLogsRepository()
+
+	// Access to pods/log is reviewed before the stream opens: bella may only get services and
+	// namespaces in her namespace
+	bella := &k8s.RequestIdentity{UserID: "bellaNonAdmin@example.com"}
+	_, err := repo.StreamPodLogs(testEnv.InternalClient(), ctx, bella, "bella-namespace", "web", &corev1.PodLogOptions{})
+	require.Error(t, err)
+	assert.True(t, k8serrors.IsForbidden(err), err)
+
+	// envtest runs no pods, so allowed requests get as far as the missing pod
+	admin := &k8s.RequestIdentity{UserID: "user@example.com"}
+	_, err = repo.StreamPodLogs(testEnv.InternalClient(), ctx, admin, "bella-namespace", "web", &corev1.PodLogOptions{})
+	require.Error(t, err)
+	assert.True(t, k8serrors.IsNotFound(err), err)
+}
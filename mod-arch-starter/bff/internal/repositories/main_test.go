@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"os"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/testutil"
+)
+
+// testEnv is shared by the repository tests; nil when the envtest binaries are not installed.
+var testEnv *testutil.Env
+
+func TestMain(m *testing.M) {
+	os.Exit(testutil.RunWithEnv(m, func(env *testutil.Env) { testEnv = env }))
+}
@@ -0,0 +1,158 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"time"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Annotations set on the Secrets and ConfigMaps created through SecretRepository and
+// ConfigMapRepository.
+const (
+	// ManagedByAnnotation names the module that created the object. Only objects managed by
+	// the module can be updated through the BFF.
+	ManagedByAnnotation = "modarch.opendatahub.io/managed-by"
+	// CreatedByAnnotation is the user who created the object.
+	CreatedByAnnotation = "modarch.opendatahub.io/created-by"
+)
+
+// DefaultManager is the ManagedByAnnotation value until UseManager sets the module name.
+const DefaultManager = "mod-arch"
+
+// DefaultRevealVerb is the verb checked before redacted values are revealed.
+const DefaultRevealVerb = "get"
+
+// RedactionPolicy selects the values redacted on reads and who may reveal them. Secret values
+// are always redacted unless revealed.
+type RedactionPolicy struct {
+	// ConfigMapKeys are path.Match patterns of the ConfigMap keys redacted like Secret values,
+	// e.g. "*password*".
+	ConfigMapKeys []string
+	// RevealVerb is the verb a SubjectAccessReview checks on secrets (or configmaps) before
+	// values are revealed (default "get"). A custom verb such as "reveal" requires an explicit
+	// RBAC grant for it.
+	RevealVerb string
+}
+
+func (p RedactionPolicy) redactsConfigMapKey(key string) bool {
+	return slices.ContainsFunc(p.ConfigMapKeys, func(pattern string) bool {
+		matched, _ := path.Match(pattern, key)
+		return matched
+	})
+}
+
+// checkReveal fails with a 403 unless the identity may reveal the values of resource in namespace.
+func (p RedactionPolicy) checkReveal(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, resource, namespace, name string) error {
+	verb := p.RevealVerb
+	if verb == "" {
+		verb = DefaultRevealVerb
+	}
+	allowed, err := client.CanAccess(ctx, identity, verb, "", resource, namespace)
+	if err != nil {
+		return fmt.Errorf("error checking permission: %w", err)
+	}
+	if !allowed {
+		return k8serrors.NewForbidden(schema.GroupResource{Resource: resource}, name, fmt.Errorf("user cannot reveal the values of %s in namespace %q", resource, namespace))
+	}
+	return nil
+}
+
+// tagManaged sets the ownership annotations of an object created by manager for identity.
+func tagManaged(meta *metav1.ObjectMeta, manager string, identity *k8s.RequestIdentity) {
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+	meta.Annotations[ManagedByAnnotation] = manager
+	if identity != nil && identity.UserID != "" {
+		meta.Annotations[CreatedByAnnotation] = identity.UserID
+	}
+}
+
+// checkManaged fails with a 403 unless the object was created by manager.
+func checkManaged(meta *metav1.ObjectMeta, manager string, resource schema.GroupResource) error {
+	if meta.Annotations[ManagedByAnnotation] != manager {
+		return k8serrors.NewForbidden(resource, meta.Name, fmt.Errorf("%s %q is not managed by %s", resource.Resource, meta.Name, manager))
+	}
+	return nil
+}
+
+// appliedMeta returns the metadata an update applies to the managed object of current: its
+// name, namespace and ownership annotations, which the field manager keeps owning, labels, the
+// current labels when nil, and resourceVersion when set, to only apply to that version.
+func appliedMeta(current *metav1.ObjectMeta, labels map[string]string, resourceVersion string) metav1.ObjectMeta {
+	meta := metav1.ObjectMeta{
+		Name:            current.Name,
+		Namespace:       current.Namespace,
+		Labels:          labels,
+		Annotations:     map[string]string{},
+		ResourceVersion: resourceVersion,
+	}
+	if labels == nil {
+		meta.Labels = current.Labels
+	}
+	for _, key := range []string{ManagedByAnnotation, CreatedByAnnotation} {
+		if value, ok := current.Annotations[key]; ok {
+			meta.Annotations[key] = value
+		}
+	}
+	return meta
+}
+
+// keepRedacted returns data with the current values of the redacted keys, so objects read
+// redacted can be written back.
+func keepRedacted[V any](data map[string]V, redacted []string, current map[string]V) map[string]V {
+	merged := make(map[string]V, len(data))
+	for key, value := range data {
+		merged[key] = value
+	}
+	for _, key := range redacted {
+		if value, ok := current[key]; ok {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+func sortedKeys[V any](data map[string]V) []string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func creationTime(meta *metav1.ObjectMeta) *time.Time {
+	if meta.CreationTimestamp.IsZero() {
+		return nil
+	}
+	return &meta.CreationTimestamp.Time
+}
+
+// fromUnstructured converts a dynamic client object to its typed form.
+func fromUnstructured[T any](obj *unstructured.Unstructured) (*T, error) {
+	typed := new(T)
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), typed); err != nil {
+		return nil, fmt.Errorf("error converting %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+	return typed, nil
+}
+
+// toUnstructured converts a typed object, whose TypeMeta must be set, for the dynamic client.
+func toUnstructured(obj runtime.Object) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: content}, nil
+}
@@ -0,0 +1,236 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/modelregistry"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// modelRegistryPortNames are the REST ports of a model registry Service, most preferred first:
+// the kube-rbac-proxy port of secured registries, then the plain REST port.
+var modelRegistryPortNames = []string{"https-api", "http-api"}
+
+// ModelRegistryRepository resolves model registry Services and reads and writes registered
+// models, model versions and model artifacts through a modelregistry client created for the
+// caller, which the registry authorizes.
+type ModelRegistryRepository struct{}
+
+func NewModelRegistryRepository() *ModelRegistryRepository {
+	return &ModelRegistryRepository{}
+}
+
+// GetModelRegistryURL returns the REST URL of the model registry Service name in namespace.
+// The identity must be allowed to get services in the namespace.
+func (r *ModelRegistryRepository) GetModelRegistryURL(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, namespace, name string) (string, error) {
+	ctx, span := tracing.StartSpan(ctx, "ModelRegistryRepository.GetModelRegistryURL",
+		attribute.String("k8s.namespace.name", namespace),
+		attribute.String("k8s.service.name", name),
+	)
+	defer span.End()
+
+	allowed, err := client.CanAccess(ctx, identity, "get", "", "services", namespace)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return "", fmt.Errorf("error checking access to services: %w", err)
+	}
+	if !allowed {
+		err := k8serrors.NewForbidden(schema.GroupResource{Resource: "services"}, name, fmt.Errorf("user cannot get services in namespace %s", namespace))
+		tracing.RecordError(span, err)
+		return "", err
+	}
+
+	svc, err := client.Reader().GetService(ctx, namespace, name)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return "", fmt.Errorf("error getting model registry %s: %w", name, err)
+	}
+	selector, _ := labels.Parse(modelregistry.ServiceSelector)
+	if !selector.Matches(labels.Set(svc.Labels)) {
+		err := k8serrors.NewNotFound(schema.GroupResource{Resource: "modelregistries"}, name)
+		tracing.RecordError(span, err)
+		return "", err
+	}
+
+	port := modelRegistryPort(svc)
+	if port == nil {
+		err := fmt.Errorf("model registry %s/%s has no REST port", namespace, name)
+		tracing.RecordError(span, err)
+		return "", err
+	}
+	return k8s.ServicePortURL(svc, port, "").String(), nil
+}
+
+func modelRegistryPort(svc *corev1.Service) *corev1.ServicePort {
+	for _, name := range modelRegistryPortNames {
+		if i := slices.IndexFunc(svc.Spec.Ports, func(p corev1.ServicePort) bool { return p.Name == name }); i >= 0 {
+			return &svc.Spec.Ports[i]
+		}
+	}
+	if len(svc.Spec.Ports) == 1 {
+		return &svc.Spec.Ports[0]
+	}
+	return nil
+}
+
+// ─── REGISTERED MODELS ──────────────────────────────────────
+
+func (r *ModelRegistryRepository) ListRegisteredModels(client modelregistry.ClientInterface, ctx context.Context, opts modelregistry.ListOptions) (*models.RegisteredModelList, error) {
+	ctx, span := tracing.StartSpan(ctx, "ModelRegistryRepository.ListRegisteredModels")
+	defer span.End()
+
+	list, err := client.ListRegisteredModels(ctx, opts)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("bff.model_registry.count", len(list.Items)))
+	return list, nil
+}
+
+func (r *ModelRegistryRepository) GetRegisteredModel(client modelregistry.ClientInterface, ctx context.Context, id string) (*models.RegisteredModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "ModelRegistryRepository.GetRegisteredModel", attribute.String("bff.model_registry.id", id))
+	defer span.End()
+
+	model, err := client.GetRegisteredModel(ctx, id)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	return model, nil
+}
+
+func (r *ModelRegistryRepository) CreateRegisteredModel(client modelregistry.ClientInterface, ctx context.Context, model models.RegisteredModel) (*models.RegisteredModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "ModelRegistryRepository.CreateRegisteredModel")
+	defer span.End()
+
+	created, err := client.CreateRegisteredModel(ctx, model)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	return created, nil
+}
+
+func (r *ModelRegistryRepository) UpdateRegisteredModel(client modelregistry.ClientInterface, ctx context.Context, id string, model models.RegisteredModel) (*models.RegisteredModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "ModelRegistryRepository.UpdateRegisteredModel", attribute.String("bff.model_registry.id", id))
+	defer span.End()
+
+	updated, err := client.UpdateRegisteredModel(ctx, id, model)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	return updated, nil
+}
+
+// ─── MODEL VERSIONS ─────────────────────────────────────────
+
+func (r *ModelRegistryRepository) ListModelVersions(client modelregistry.ClientInterface, ctx context.Context, registeredModelID string, opts modelregistry.ListOptions) (*models.ModelVersionList, error) {
+	ctx, span := tracing.StartSpan(ctx, "ModelRegistryRepository.ListModelVersions", attribute.String("bff.model_registry.id", registeredModelID))
+	defer span.End()
+
+	list, err := client.ListModelVersions(ctx, registeredModelID, opts)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("bff.model_registry.count", len(list.Items)))
+	return list, nil
+}
+
+func (r *ModelRegistryRepository) GetModelVersion(client modelregistry.ClientInterface, ctx context.Context, id string) (*models.ModelVersion, error) {
+	ctx, span := tracing.StartSpan(ctx, "ModelRegistryRepository.GetModelVersion", attribute.String("bff.model_registry.id", id))
+	defer span.End()
+
+	version, err := client.GetModelVersion(ctx, id)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	return version, nil
+}
+
+func (r *ModelRegistryRepository) CreateModelVersion(client modelregistry.ClientInterface, ctx context.Context, registeredModelID string, version models.ModelVersion) (*models.ModelVersion, error) {
+	ctx, span := tracing.StartSpan(ctx, "ModelRegistryRepository.CreateModelVersion", attribute.String("bff.model_registry.id", registeredModelID))
+	defer span.End()
+
+	created, err := client.CreateModelVersion(ctx, registeredModelID, version)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	return created, nil
+}
+
+func (r *ModelRegistryRepository) UpdateModelVersion(client modelregistry.ClientInterface, ctx context.Context, id string, version models.ModelVersion) (*models.ModelVersion, error) {
+	ctx, span := tracing.StartSpan(ctx, "ModelRegistryRepository.UpdateModelVersion", attribute.String("bff.model_registry.id", id))
+	defer span.End()
+
+	updated, err := client.UpdateModelVersion(ctx, id, version)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	return updated, nil
+}
+
+// ─── MODEL ARTIFACTS ────────────────────────────────────────
+
+func (r *ModelRegistryRepository) ListModelArtifacts(client modelregistry.ClientInterface, ctx context.Context, modelVersionID string, opts modelregistry.ListOptions) (*models.ModelArtifactList, error) {
+	ctx, span := tracing.StartSpan(ctx, "ModelRegistryRepository.ListModelArtifacts", attribute.String("bff.model_registry.id", modelVersionID))
+	defer span.End()
+
+	list, err := client.ListModelArtifacts(ctx, modelVersionID, opts)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("bff.model_registry.count", len(list.Items)))
+	return list, nil
+}
+
+func (r *ModelRegistryRepository) GetModelArtifact(client modelregistry.ClientInterface, ctx context.Context, id string) (*models.ModelArtifact, error) {
+	ctx, span := tracing.StartSpan(ctx, "ModelRegistryRepository.GetModelArtifact", attribute.String("bff.model_registry.id", id))
+	defer span.End()
+
+	artifact, err := client.GetModelArtifact(ctx, id)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	return artifact, nil
+}
+
+func (r *ModelRegistryRepository) CreateModelArtifact(client modelregistry.ClientInterface, ctx context.Context, modelVersionID string, artifact models.ModelArtifact) (*models.ModelArtifact, error) {
+	ctx, span := tracing.StartSpan(ctx, "ModelRegistryRepository.CreateModelArtifact", attribute.String("bff.model_registry.id", modelVersionID))
+	defer span.End()
+
+	created, err := client.CreateModelArtifact(ctx, modelVersionID, artifact)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	return created, nil
+}
+
+func (r *ModelRegistryRepository) UpdateModelArtifact(client modelregistry.ClientInterface, ctx context.Context, id string, artifact models.ModelArtifact) (*models.ModelArtifact, error) {
+	ctx, span := tracing.StartSpan(ctx, "ModelRegistryRepository.UpdateModelArtifact", attribute.String("bff.model_registry.id", id))
+	defer span.End()
+
+	updated, err := client.UpdateModelArtifact(ctx, id, artifact)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	return updated, nil
+}
@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes/k8mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func TestModelRegistryRepository_GetModelRegistryURL(t *testing.T) {
+	ctx := context.Background()
+	fixtures := k8mocks.DefaultMockFixtures()
+	fixtures.Services = append(fixtures.Services,
+		k8mocks.MockService{Name: "registry", Namespace: "dora-namespace", Labels: map[string]string{"component": "model-registry"}},
+	)
+	client := k8mocks.NewMockKubernetesClient(fixtures, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	repo := NewModelRegistryRepository()
+	dora := &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com"}
+
+	serverURL, err := repo.GetModelRegistryURL(client, ctx, dora, "dora-namespace", "registry")
+	require.NoError(t, err)
+	assert.Equal(t, "http://registry.dora-namespace.svc.cluster.local:8080", serverURL)
+
+	_, err = repo.GetModelRegistryURL(client, ctx, dora, "dora-namespace", "mod-arch-dora")
+	assert.True(t, k8serrors.IsNotFound(err), "services without the model registry label are not registries: %v", err)
+
+	_, err = repo.GetModelRegistryURL(client, ctx, &k8s.RequestIdentity{UserID: "bellaNonAdmin@example.com"}, "dora-namespace", "registry")
+	assert.True(t, k8serrors.IsForbidden(err), "got %v", err)
+}
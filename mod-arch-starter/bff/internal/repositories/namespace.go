@@ -2,29 +2,178 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"time"
 
 	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
 	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/provisioning"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
-type NamespaceRepository struct{}
+var namespacesGVR = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+// rollbackTimeout bounds the deletion of the objects of a failed provisioning, which also
+// runs once the provisioning is cancelled.
+const rollbackTimeout = 30 * time.Second
+
+// NamespaceRepository lists the namespaces the requesting identity can access.
+// RBAC filtering is delegated to the Kubernetes client, which runs a (Self)SubjectAccessReview
+// per namespace unless the identity is a cluster admin.
+//
+// It also provisions namespaces from the templates of package provisioning.
+type NamespaceRepository struct {
+	manager string
+	// profiles lists the namespaces of the Kubeflow Profiles instead
+	profiles bool
+}
 
 func NewNamespaceRepository() *NamespaceRepository {
-	return &NamespaceRepository{}
+	return &NamespaceRepository{manager: DefaultManager}
+}
+
+// UseManager sets the module name recorded in ManagedByAnnotation on provisioned objects.
+func (r *NamespaceRepository) UseManager(name string) {
+	r.manager = name
+}
+
+// UseProfiles lists the namespaces of the Kubeflow Profiles, for Kubeflow mode.
+func (r *NamespaceRepository) UseProfiles() {
+	r.profiles = true
 }
 
 func (r *NamespaceRepository) GetNamespaces(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity) ([]models.NamespaceModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "NamespaceRepository.GetNamespaces", attribute.Bool("bff.namespaces.profiles", r.profiles))
+	defer span.End()
+
+	var namespaceModels = []models.NamespaceModel{}
+	if r.profiles {
+		var err error
+		if namespaceModels, err = getProfileNamespaces(client, ctx, identity); err != nil {
+			tracing.RecordError(span, err)
+			return nil, err
+		}
+	} else {
+		namespaces, err := client.GetNamespaces(ctx, identity)
+		if err != nil {
+			tracing.RecordError(span, err)
+			return nil, fmt.Errorf("error fetching namespaces: %w", err)
+		}
+		for _, ns := range namespaces {
+			namespaceModels = append(namespaceModels, models.NewNamespaceModelFromNamespace(ns.Name))
+		}
+	}
+
+	sort.Slice(namespaceModels, func(i, j int) bool {
+		return namespaceModels[i].Name < namespaceModels[j].Name
+	})
+	span.SetAttributes(attribute.Int("bff.namespaces.count", len(namespaceModels)))
+	logger.FromContext(ctx).Debug("listed namespaces", "count", len(namespaceModels))
+
+	return namespaceModels, nil
+}
 
-	namespaces, err := client.GetNamespaces(ctx, identity)
+// CheckProvision fails with a 403 unless the identity may create namespaces, and with a 409
+// when the namespace exists. Handlers call it before starting the provisioning, so those
+// errors are answered right away.
+func (r *NamespaceRepository) CheckProvision(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, name string) error {
+	ctx, span := tracing.StartSpan(ctx, "NamespaceRepository.CheckProvision", attribute.String("k8s.namespace.name", name))
+	defer span.End()
+
+	allowed, err := client.CanAccess(ctx, identity, "create", "", "namespaces", "")
 	if err != nil {
-		return nil, fmt.Errorf("error fetching namespaces: %w", err)
+		tracing.RecordError(span, err)
+		return fmt.Errorf("error checking access to namespaces: %w", err)
+	}
+	if !allowed {
+		return k8serrors.NewForbidden(namespacesGVR.GroupResource(), name, fmt.Errorf("user cannot create namespaces"))
 	}
+	namespaces, err := client.DynamicResource(namespacesGVR)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+	_, err = namespaces.Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		return k8serrors.NewAlreadyExists(namespacesGVR.GroupResource(), name)
+	case k8serrors.IsNotFound(err):
+		return nil
+	default:
+		tracing.RecordError(span, err)
+		return fmt.Errorf("error fetching namespace %s: %w", name, err)
+	}
+}
 
-	var namespaceModels = []models.NamespaceModel{}
-	for _, ns := range namespaces {
-		namespaceModels = append(namespaceModels, models.NewNamespaceModelFromNamespace(ns.Name))
+// Provision creates the objects rendered from a namespace template, the Namespace first,
+// tagged as managed by the module and created by the identity, and reports each step to
+// report. The objects are created with the client's credentials once CheckProvision passed:
+// the templates are the operator's, and the requester has no access to the namespace yet.
+//
+// When an object can't be created, those already created are deleted in the reverse order
+// and the error says whether the rollback completed.
+func (r *NamespaceRepository) Provision(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, manifests []provisioning.Manifest, report func(percent int, message string)) ([]models.ProvisionedObjectModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "NamespaceRepository.Provision", attribute.Int("bff.provisioning.objects", len(manifests)))
+	defer span.End()
+
+	var created []provisioning.Manifest
+	objects := make([]models.ProvisionedObjectModel, 0, len(manifests))
+	for i, manifest := range manifests {
+		obj := manifest.Object
+		report(i*100/len(manifests), fmt.Sprintf("creating %s %s", obj.GetKind(), obj.GetName()))
+		meta := metav1.ObjectMeta{Annotations: obj.GetAnnotations()}
+		tagManaged(&meta, r.manager, identity)
+		obj.SetAnnotations(meta.Annotations)
+
+		err := r.create(client, ctx, manifest)
+		if err != nil {
+			err = fmt.Errorf("failed to create %s %s: %w", obj.GetKind(), obj.GetName(), err)
+			if rollbackErr := r.rollback(client, ctx, created); rollbackErr != nil {
+				err = errors.Join(err, fmt.Errorf("rollback incomplete: %w", rollbackErr))
+			} else {
+				err = fmt.Errorf("%w (rolled back)", err)
+			}
+			tracing.RecordError(span, err)
+			return nil, err
+		}
+		created = append(created, manifest)
+		objects = append(objects, models.ProvisionedObjectModel{APIVersion: obj.GetAPIVersion(), Kind: obj.GetKind(), Name: obj.GetName()})
 	}
+	logger.FromContext(ctx).Info("provisioned namespace", "namespace", manifests[0].Object.GetName(), "objects", len(objects))
+	return objects, nil
+}
 
-	return namespaceModels, nil
+func (r *NamespaceRepository) create(client k8s.KubernetesClientInterface, ctx context.Context, manifest provisioning.Manifest) error {
+	resource, err := client.DynamicResource(manifest.Resource)
+	if err != nil {
+		return err
+	}
+	_, err = resource.Namespace(manifest.Object.GetNamespace()).Create(ctx, manifest.Object, metav1.CreateOptions{})
+	return err
+}
+
+// rollback deletes the created objects, the last first, even once ctx is cancelled.
+func (r *NamespaceRepository) rollback(client k8s.KubernetesClientInterface, ctx context.Context, created []provisioning.Manifest) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollbackTimeout)
+	defer cancel()
+
+	var errs []error
+	for i := len(created) - 1; i >= 0; i-- {
+		obj := created[i].Object
+		resource, err := client.DynamicResource(created[i].Resource)
+		if err == nil {
+			err = resource.Namespace(obj.GetNamespace()).Delete(ctx, obj.GetName(), metav1.DeleteOptions{})
+		}
+		if err != nil && !k8serrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete %s %s: %w", obj.GetKind(), obj.GetName(), err))
+		}
+	}
+	return errors.Join(errs...)
 }
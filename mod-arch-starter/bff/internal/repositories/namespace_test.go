@@ -0,0 +1,171 @@
+package repositories
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes/k8mocks"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/provisioning"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func namespaceNames(namespaces []models.NamespaceModel) []string {
+	names := make([]string, 0, len(namespaces))
+	for _, ns := range namespaces {
+		names = append(names, ns.Name)
+	}
+	return names
+}
+
+func profileObject(name, ownerKind, owner string) map[string]any {
+	return map[string]any{
+		"apiVersion": "kubeflow.org/v1", "kind": "Profile",
+		"metadata": map[string]any{"name": name},
+		"spec":     map[string]any{"owner": map[string]any{"kind": ownerKind, "name": owner}},
+	}
+}
+
+func TestNamespaceRepository_GetNamespacesProfiles(t *testing.T) {
+	client := k8mocks.NewMockKubernetesClient(k8mocks.DefaultMockFixtures(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	seed(t, client, profilesGVR, "", profileObject("dora-namespace", "User", "doraNonAdmin@example.com"))
+	seed(t, client, profilesGVR, "", profileObject("bella-namespace", "User", "bellaNonAdmin@example.com"))
+	seed(t, client, profilesGVR, "", profileObject("team-namespace", "Group", "team"))
+	repo := NewNamespaceRepository()
+	repo.UseProfiles()
+	ctx := context.Background()
+
+	namespaces, err := repo.GetNamespaces(client, ctx, &k8s.RequestIdentity{UserID: "user@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bella-namespace", "dora-namespace", "team-namespace"}, namespaceNames(namespaces), "cluster admins see every profile, and only the profiles")
+	assert.Equal(t, "bellaNonAdmin@example.com", namespaces[0].Owner)
+	assert.Empty(t, namespaces[2].Owner, "owned by a group")
+
+	namespaces, err = repo.GetNamespaces(client, ctx, &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dora-namespace"}, namespaceNames(namespaces))
+	assert.Equal(t, "doraNonAdmin@example.com", namespaces[0].Owner)
+
+	namespaces, err = repo.GetNamespaces(client, ctx, &k8s.RequestIdentity{UserID: "nobody@example.com"})
+	require.NoError(t, err)
+	assert.Empty(t, namespaces, "a failed review hides the namespace")
+}
+
+func TestNamespaceRepository_GetNamespaces(t *testing.T) {
+	testutil.RequireEnv(t, testEnv)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		identity *k8s.RequestIdentity
+		want     []string
+		wantAll  bool
+	}{
+		{
+			name:     "cluster admin sees every namespace",
+			identity: &k8s.RequestIdentity{UserID: "user@example.com"},
+			wantAll:  true,
+		},
+		{
+			name:     "user bound to a single namespace",
+			identity: &k8s.RequestIdentity{UserID: "bellaNonAdmin@example.com"},
+			want:     []string{"bella-namespace"},
+		},
+		{
+			name:     "access granted through a group",
+			identity: &k8s.RequestIdentity{UserID: "someone@example.com", Groups: []string{"dora-namespace-group"}},
+			want:     []string{"dora-namespace"},
+		},
+		{
+			name:     "user without bindings",
+			identity: &k8s.RequestIdentity{UserID: "nobody@example.com"},
+			want:     []string{},
+		},
+	}
+
+	repo := NewNamespaceRepository()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clients := map[string]k8s.KubernetesClientInterface{"internal": testEnv.InternalClient()}
+			if tt.wantAll {
+				// Token-based clients list namespaces as the user, which only cluster admins may do.
+				impersonated, err := testEnv.ClientFor(tt.identity)
+				require.NoError(t, err)
+				clients["impersonated"] = impersonated
+			}
+
+			for clientName, client := range clients {
+				namespaces, err := repo.GetNamespaces(client, ctx, tt.identity)
+				require.NoError(t, err, clientName)
+				names := namespaceNames(namespaces)
+				if tt.wantAll {
+					assert.Subset(t, names, []string{"bella-namespace", "bento-namespace", "dora-namespace", "kubeflow"}, clientName)
+					assert.IsIncreasing(t, names, clientName)
+					continue
+				}
+				assert.Equal(t, tt.want, names, clientName)
+			}
+		})
+	}
+}
+
+func TestNamespaceRepository_Provision(t *testing.T) {
+	client := k8mocks.NewMockKubernetesClient(k8mocks.DefaultMockFixtures(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	templates, err := provisioning.Load("")
+	require.NoError(t, err)
+	repo := NewNamespaceRepository()
+	ctx := context.Background()
+	admin := &k8s.RequestIdentity{UserID: "user@example.com"}
+	render := func(name string) []provisioning.Manifest {
+		manifests, err := templates.Render(provisioning.DefaultTemplate, provisioning.Data{Name: name, Requester: admin.UserID})
+		require.NoError(t, err)
+		return manifests
+	}
+
+	err = repo.CheckProvision(client, ctx, &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com"}, "team-a")
+	assert.True(t, k8serrors.IsForbidden(err), err)
+	require.NoError(t, repo.CheckProvision(client, ctx, admin, "team-a"))
+
+	var reports []int
+	objects, err := repo.Provision(client, ctx, admin, render("team-a"), func(percent int, _ string) { reports = append(reports, percent) })
+	require.NoError(t, err)
+	assert.Equal(t, []models.ProvisionedObjectModel{
+		{APIVersion: "v1", Kind: "Namespace", Name: "team-a"},
+		{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "RoleBinding", Name: "admin"},
+		{APIVersion: "v1", Kind: "ResourceQuota", Name: "compute"},
+		{APIVersion: "networking.k8s.io/v1", Kind: "NetworkPolicy", Name: "allow-same-namespace-and-ingress"},
+	}, objects)
+	assert.Equal(t, []int{0, 25, 50, 75}, reports)
+	namespaces, err := client.DynamicResource(namespacesGVR)
+	require.NoError(t, err)
+	namespace, err := namespaces.Get(ctx, "team-a", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultManager, namespace.GetAnnotations()[ManagedByAnnotation])
+	assert.Equal(t, admin.UserID, namespace.GetAnnotations()[CreatedByAnnotation])
+
+	assert.True(t, k8serrors.IsAlreadyExists(repo.CheckProvision(client, ctx, admin, "team-a")))
+
+	// The NetworkPolicy exists, so the objects created before it are deleted again
+	networkPolicies := schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies"}
+	seed(t, client, networkPolicies, "team-b", map[string]any{
+		"apiVersion": "networking.k8s.io/v1", "kind": "NetworkPolicy",
+		"metadata": map[string]any{"name": "allow-same-namespace-and-ingress", "namespace": "team-b"},
+	})
+	_, err = repo.Provision(client, ctx, admin, render("team-b"), func(int, string) {})
+	require.ErrorContains(t, err, "failed to create NetworkPolicy allow-same-namespace-and-ingress")
+	assert.ErrorContains(t, err, "(rolled back)")
+	_, err = namespaces.Get(ctx, "team-b", metav1.GetOptions{})
+	assert.True(t, k8serrors.IsNotFound(err), "namespace rolled back")
+	roleBindings, err := client.DynamicResource(schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "rolebindings"})
+	require.NoError(t, err)
+	_, err = roleBindings.Namespace("team-b").Get(ctx, "admin", metav1.GetOptions{})
+	assert.True(t, k8serrors.IsNotFound(err), "RoleBinding rolled back")
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/apierrors"
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/objectstore"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ObjectStorageRepository connects to the S3-compatible storage of the credentials in a Secret
+// of the namespace, like a data connection of the ODH dashboard. The Secret is read on behalf
+// of the identity, so only the users who can get it browse the storage through the BFF; its
+// values are never returned.
+type ObjectStorageRepository struct {
+	secrets *SecretRepository
+	config  objectstore.Config
+}
+
+func NewObjectStorageRepository() *ObjectStorageRepository {
+	return &ObjectStorageRepository{secrets: NewSecretRepository()}
+}
+
+// UseClientConfig sets the TLS configuration, allowed hosts, circuit breakers and transport of
+// the clients; the credentials of cfg are ignored. The transport is shared by every client, so
+// connections to the same storage are reused.
+func (r *ObjectStorageRepository) UseClientConfig(cfg objectstore.Config) {
+	if cfg.Transport == nil {
+		cfg.Transport = objectstore.NewTransport(cfg)
+	}
+	r.config = cfg
+}
+
+// Client returns a client of the storage of the credentials in the Secret secretName of
+// namespace. It fails with a 400 when the Secret holds no S3 credentials.
+func (r *ObjectStorageRepository) Client(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, namespace, secretName string) (*objectstore.Client, error) {
+	ctx, span := tracing.StartSpan(ctx, "ObjectStorageRepository.Client", attribute.String("k8s.namespace.name", namespace))
+	defer span.End()
+
+	secret, err := r.secrets.get(client, ctx, identity, namespace, secretName)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	creds, err := objectstore.CredentialsFromSecret(secret.Data)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, apierrors.Wrap(http.StatusBadRequest, fmt.Sprintf("the Secret %s has no object storage credentials", secretName), err)
+	}
+	cfg := r.config
+	cfg.Credentials = creds
+	storage, err := objectstore.New(cfg)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, apierrors.Wrap(http.StatusBadRequest, fmt.Sprintf("the Secret %s has an invalid object storage endpoint", secretName), err)
+	}
+	return storage, nil
+}
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/parallel"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// PermissionCheckWorkers bounds the access reviews of a batch run in parallel.
+const PermissionCheckWorkers = 10
+
+type PermissionRepository struct{}
+
+func NewPermissionRepository() *PermissionRepository {
+	return &PermissionRepository{}
+}
+
+func (r *PermissionRepository) CheckPermission(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, verb, group, resource, namespace string) (models.PermissionCheck, error) {
+	ctx, span := tracing.StartSpan(ctx, "PermissionRepository.CheckPermission",
+		attribute.String("k8s.verb", verb),
+		attribute.String("k8s.resource", resource),
+		attribute.String("k8s.namespace.name", namespace),
+	)
+	defer span.End()
+
+	allowed, err := client.CanAccess(ctx, identity, verb, group, resource, namespace)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return models.PermissionCheck{}, fmt.Errorf("error checking permission: %w", err)
+	}
+
+	return models.PermissionCheck{
+		Verb:      verb,
+		Group:     group,
+		Resource:  resource,
+		Namespace: namespace,
+		Allowed:   allowed,
+	}, nil
+}
+
+// CheckPermissions runs the checks of a batch on a pool of PermissionCheckWorkers and returns
+// their results in the same order. Identical checks are reviewed once. A check that fails is
+// reported as not allowed with its error, so one failure doesn't fail the batch; the batch
+// fails only when ctx is done.
+func (r *PermissionRepository) CheckPermissions(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, checks []models.PermissionRequest) ([]models.PermissionCheck, error) {
+	ctx, span := tracing.StartSpan(ctx, "PermissionRepository.CheckPermissions",
+		attribute.Int("bff.permission.checks", len(checks)),
+	)
+	defer span.End()
+
+	unique := make(map[models.PermissionRequest][]int, len(checks))
+	var order []models.PermissionRequest
+	for i, check := range checks {
+		if _, seen := unique[check]; !seen {
+			order = append(order, check)
+		}
+		unique[check] = append(unique[check], i)
+	}
+
+	results := make([]models.PermissionCheck, len(checks))
+	err := parallel.ForEach(ctx, parallel.Options{Limit: PermissionCheckWorkers}, len(order), func(ctx context.Context, i int) {
+		check := order[i]
+		result := models.PermissionCheck{Verb: check.Verb, Group: check.Group, Resource: check.Resource, Namespace: check.Namespace}
+		allowed, err := client.CanAccess(ctx, identity, check.Verb, check.Group, check.Resource, check.Namespace)
+		if err != nil {
+			result.Error = err.Error()
+		}
+		result.Allowed = allowed && err == nil
+		for _, j := range unique[check] {
+			results[j] = result
+		}
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, fmt.Errorf("error checking permissions: %w", err)
+	}
+	return results, nil
+}
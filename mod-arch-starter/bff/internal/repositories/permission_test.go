@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes/k8mocks"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPermissions(t *testing.T) {
+	client := &concurrentClient{MockKubernetesClient: k8mocks.NewMockKubernetesClient(k8mocks.DefaultMockFixtures(), slog.New(slog.NewTextHandler(io.Discard, nil)))}
+	dora := &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com"}
+
+	checks := []models.PermissionRequest{
+		{Verb: "create", Resource: "secrets", Namespace: "dora-namespace"},
+		{Verb: "create", Resource: "secrets", Namespace: "bella-namespace"},
+		{Verb: "get", Resource: "pods", Namespace: "broken"},
+		{Verb: "create", Resource: "secrets", Namespace: "dora-namespace"},
+	}
+	for i := 0; i < 30; i++ {
+		checks = append(checks, models.PermissionRequest{Verb: "list", Group: "apps", Resource: "deployments", Namespace: fmt.Sprintf("ns-%d", i)})
+	}
+
+	results, err := NewPermissionRepository().CheckPermissions(client, context.Background(), dora, checks)
+	require.NoError(t, err)
+	require.Len(t, results, len(checks))
+	assert.Equal(t, models.PermissionCheck{Verb: "create", Resource: "secrets", Namespace: "dora-namespace", Allowed: true}, results[0])
+	assert.False(t, results[1].Allowed)
+	assert.Equal(t, models.PermissionCheck{Verb: "get", Resource: "pods", Namespace: "broken", Error: "unavailable"}, results[2], "failed checks don't fail the batch")
+	assert.Equal(t, results[0], results[3])
+	assert.Equal(t, "ns-29", results[33].Namespace, "results are in the order of the checks")
+
+	assert.Equal(t, len(checks)-1, client.calls, "identical checks are reviewed once")
+	assert.LessOrEqual(t, client.maxInFlight, PermissionCheckWorkers)
+	assert.Greater(t, client.maxInFlight, 1, "reviews run in parallel")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = NewPermissionRepository().CheckPermissions(client, ctx, dora, checks)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// concurrentClient records how many access reviews of a MockKubernetesClient run at once, and
+// fails those of the "broken" namespace.
+type concurrentClient struct {
+	*k8mocks.MockKubernetesClient
+	mu                           sync.Mutex
+	calls, inFlight, maxInFlight int
+}
+
+func (c *concurrentClient) CanAccess(ctx context.Context, identity *k8s.RequestIdentity, verb, group, resource, namespace string) (bool, error) {
+	c.mu.Lock()
+	c.calls++
+	c.inFlight++
+	c.maxInFlight = max(c.maxInFlight, c.inFlight)
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.inFlight--
+		c.mu.Unlock()
+	}()
+
+	time.Sleep(5 * time.Millisecond)
+	if namespace == "broken" {
+		return false, errors.New("unavailable")
+	}
+	return c.MockKubernetesClient.CanAccess(ctx, identity, verb, group, resource, namespace)
+}
@@ -0,0 +1,240 @@
+package repositories
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/apierrors"
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// PreferencesKey is the ConfigMap key holding the preferences, as JSON.
+	PreferencesKey = "preferences.json"
+	// PreferencesUserAnnotation is the user ID of a preferences ConfigMap.
+	PreferencesUserAnnotation = "modarch.opendatahub.io/user"
+	// PreferencesSelector selects the preferences ConfigMaps, e.g. to clean them up.
+	PreferencesSelector = "app.kubernetes.io/component=user-preferences"
+	// MaxPreferencesSize bounds the stored preferences of a user, in bytes.
+	MaxPreferencesSize = 256 * 1024
+)
+
+var preferencesResource = schema.GroupResource{Resource: "preferences"}
+
+// PreferencesRepository stores the UI preferences of each user in a ConfigMap of its own, in the
+// namespace set by UseNamespace, named after a hash of the user ID. The resourceVersion of the
+// ConfigMap is the version of the preferences, for optimistic concurrency.
+//
+// The ConfigMaps are read and written with the client of the request, without an access review:
+// a user only ever reaches their own. With internal auth those are the BFF credentials; with
+// token auth the users need get, create, update and delete on configmaps in the namespace.
+type PreferencesRepository struct {
+	namespace string
+}
+
+func NewPreferencesRepository() *PreferencesRepository {
+	return &PreferencesRepository{}
+}
+
+// UseNamespace sets the namespace of the preferences ConfigMaps; without one every call fails
+// with a 503.
+func (r *PreferencesRepository) UseNamespace(namespace string) {
+	r.namespace = namespace
+}
+
+// Get returns the preferences of the identity, empty when none are stored.
+func (r *PreferencesRepository) Get(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity) (models.UserPreferences, error) {
+	ctx, span := tracing.StartSpan(ctx, "PreferencesRepository.Get", attribute.String("k8s.namespace.name", r.namespace))
+	defer span.End()
+
+	_, prefs, err := r.get(client, ctx, identity)
+	if k8serrors.IsNotFound(err) {
+		return models.UserPreferences{}, nil
+	}
+	if err != nil {
+		tracing.RecordError(span, err)
+		return models.UserPreferences{}, err
+	}
+	return prefs, nil
+}
+
+// Put replaces the preferences of the identity. Without a ResourceVersion the preferences are
+// created, and fail with a conflict when some are already stored; with one they are updated,
+// and fail with a conflict when they changed since (or were deleted).
+func (r *PreferencesRepository) Put(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, prefs models.UserPreferences) (models.UserPreferences, error) {
+	ctx, span := tracing.StartSpan(ctx, "PreferencesRepository.Put",
+		attribute.String("k8s.namespace.name", r.namespace),
+		attribute.Bool("bff.create", prefs.ResourceVersion == ""),
+	)
+	defer span.End()
+
+	updated, err := r.put(client, ctx, identity, prefs)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return models.UserPreferences{}, err
+	}
+	return updated, nil
+}
+
+// Delete removes the preferences of the identity, provided they are still at resourceVersion
+// when it is set. Deleting preferences that are not stored is not an error.
+func (r *PreferencesRepository) Delete(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, resourceVersion string) error {
+	ctx, span := tracing.StartSpan(ctx, "PreferencesRepository.Delete", attribute.String("k8s.namespace.name", r.namespace))
+	defer span.End()
+
+	err := r.delete(client, ctx, identity, resourceVersion)
+	if k8serrors.IsNotFound(err) && resourceVersion == "" {
+		return nil
+	}
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+	return nil
+}
+
+func (r *PreferencesRepository) delete(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, resourceVersion string) error {
+	configMap, _, err := r.get(client, ctx, identity)
+	if err != nil {
+		return err
+	}
+	if err := checkResourceVersion(configMap, resourceVersion); err != nil {
+		return err
+	}
+	configMaps, err := r.configMaps(client)
+	if err != nil {
+		return err
+	}
+	// The precondition covers changes between the get and the delete
+	opts := metav1.DeleteOptions{Preconditions: &metav1.Preconditions{ResourceVersion: &configMap.ResourceVersion}}
+	return configMaps.Delete(ctx, configMap.Name, opts)
+}
+
+func (r *PreferencesRepository) put(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, prefs models.UserPreferences) (models.UserPreferences, error) {
+	resourceVersion := prefs.ResourceVersion
+	prefs.ResourceVersion = ""
+	data, err := json.Marshal(prefs)
+	if err != nil {
+		return models.UserPreferences{}, fmt.Errorf("error encoding preferences: %w", err)
+	}
+	if len(data) > MaxPreferencesSize {
+		return models.UserPreferences{}, apierrors.New(http.StatusRequestEntityTooLarge, fmt.Sprintf("preferences must not be larger than %d bytes", MaxPreferencesSize))
+	}
+
+	var configMap *corev1.ConfigMap
+	if resourceVersion == "" {
+		userID, err := client.GetUser(ctx, identity)
+		if err != nil {
+			return models.UserPreferences{}, fmt.Errorf("failed to get user identity: %w", err)
+		}
+		configMap = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name:        preferencesName(userID),
+			Namespace:   r.namespace,
+			Labels:      map[string]string{"app.kubernetes.io/component": "user-preferences"},
+			Annotations: map[string]string{PreferencesUserAnnotation: userID},
+		}}
+	} else {
+		configMap, _, err = r.get(client, ctx, identity)
+		if k8serrors.IsNotFound(err) {
+			err = k8serrors.NewConflict(preferencesResource, "", fmt.Errorf("the preferences were deleted since they were read"))
+		}
+		if err == nil {
+			err = checkResourceVersion(configMap, resourceVersion)
+		}
+		if err != nil {
+			return models.UserPreferences{}, err
+		}
+	}
+	configMap.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"}
+	configMap.Data = map[string]string{PreferencesKey: string(data)}
+	obj, err := toUnstructured(configMap)
+	if err != nil {
+		return models.UserPreferences{}, err
+	}
+
+	configMaps, err := r.configMaps(client)
+	if err != nil {
+		return models.UserPreferences{}, err
+	}
+	if resourceVersion == "" {
+		obj, err = configMaps.Create(ctx, obj, metav1.CreateOptions{})
+	} else {
+		obj, err = configMaps.Update(ctx, obj, metav1.UpdateOptions{})
+	}
+	if k8serrors.IsAlreadyExists(err) {
+		err = k8serrors.NewConflict(preferencesResource, "", fmt.Errorf("preferences are already stored, update them with their resourceVersion"))
+	}
+	if err != nil {
+		return models.UserPreferences{}, err
+	}
+	prefs.ResourceVersion = obj.GetResourceVersion()
+	return prefs, nil
+}
+
+// get returns the preferences ConfigMap of the identity and its preferences.
+func (r *PreferencesRepository) get(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity) (*corev1.ConfigMap, models.UserPreferences, error) {
+	userID, err := client.GetUser(ctx, identity)
+	if err != nil {
+		return nil, models.UserPreferences{}, fmt.Errorf("failed to get user identity: %w", err)
+	}
+
+	configMaps, err := r.configMaps(client)
+	if err != nil {
+		return nil, models.UserPreferences{}, err
+	}
+	obj, err := configMaps.Get(ctx, preferencesName(userID), metav1.GetOptions{})
+	if err != nil {
+		return nil, models.UserPreferences{}, err
+	}
+	configMap, err := fromUnstructured[corev1.ConfigMap](obj)
+	if err != nil {
+		return nil, models.UserPreferences{}, err
+	}
+
+	var prefs models.UserPreferences
+	if data := configMap.Data[PreferencesKey]; data != "" {
+		if err := json.Unmarshal([]byte(data), &prefs); err != nil {
+			return nil, models.UserPreferences{}, fmt.Errorf("error decoding the preferences of %s: %w", configMap.Name, err)
+		}
+	}
+	prefs.ResourceVersion = configMap.ResourceVersion
+	return configMap, prefs, nil
+}
+
+// configMaps returns the ConfigMaps of the preferences namespace.
+func (r *PreferencesRepository) configMaps(client k8s.KubernetesClientInterface) (dynamic.ResourceInterface, error) {
+	if r.namespace == "" {
+		return nil, apierrors.New(http.StatusServiceUnavailable, "user preferences are not configured")
+	}
+	resource, err := client.DynamicResource(configMapsGVR)
+	if err != nil {
+		return nil, fmt.Errorf("error getting dynamic client for configmaps: %w", err)
+	}
+	return resource.Namespace(r.namespace), nil
+}
+
+func checkResourceVersion(configMap *corev1.ConfigMap, resourceVersion string) error {
+	if resourceVersion != "" && configMap.ResourceVersion != resourceVersion {
+		return k8serrors.NewConflict(preferencesResource, "", fmt.Errorf("the preferences changed since they were read, at resourceVersion %s", resourceVersion))
+	}
+	return nil
+}
+
+// preferencesName is the ConfigMap name of userID. User IDs are hashed as they are not valid
+// object names, e.g. e-mail addresses.
+func preferencesName(userID string) string {
+	sum := sha256.Sum256([]byte(userID))
+	return "mod-arch-preferences-" + hex.EncodeToString(sum[:16])
+}
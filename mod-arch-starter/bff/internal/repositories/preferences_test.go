@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/apierrors"
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes/k8mocks"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPreferencesRepository(t *testing.T) {
+	client := k8mocks.NewMockKubernetesClient(k8mocks.DefaultMockFixtures(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	dora := &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com"}
+	bella := &k8s.RequestIdentity{UserID: "bellaNonAdmin@example.com"}
+	ctx := context.Background()
+	repo := NewPreferencesRepository()
+	repo.UseNamespace("kubeflow")
+
+	prefs, err := repo.Get(client, ctx, dora)
+	require.NoError(t, err)
+	assert.Equal(t, models.UserPreferences{}, prefs, "nothing stored yet")
+
+	created, err := repo.Put(client, ctx, dora, models.UserPreferences{
+		Theme:            "dark",
+		PinnedNamespaces: []string{"dora-namespace"},
+		Tables:           map[string]models.TablePreferences{"services": {Columns: []string{"name", "health"}, PageSize: 50}},
+		Modules:          map[string]json.RawMessage{"notebooks": json.RawMessage(`{"view":"grid"}`)},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, created.ResourceVersion)
+
+	_, err = repo.Put(client, ctx, dora, models.UserPreferences{Theme: "light"})
+	assert.True(t, k8serrors.IsConflict(err), "created twice: %v", err)
+
+	got, err := repo.Get(client, ctx, dora)
+	require.NoError(t, err)
+	assert.Equal(t, created, got)
+	other, err := repo.Get(client, ctx, bella)
+	require.NoError(t, err)
+	assert.Empty(t, other.Theme, "preferences are per user")
+
+	got.Theme = "light"
+	updated, err := repo.Put(client, ctx, dora, got)
+	require.NoError(t, err)
+	assert.NotEqual(t, created.ResourceVersion, updated.ResourceVersion)
+
+	// Writes of stale preferences conflict
+	_, err = repo.Put(client, ctx, dora, created)
+	assert.True(t, k8serrors.IsConflict(err), "got %v", err)
+	err = repo.Delete(client, ctx, dora, created.ResourceVersion)
+	assert.True(t, k8serrors.IsConflict(err), "got %v", err)
+
+	require.NoError(t, repo.Delete(client, ctx, dora, updated.ResourceVersion))
+	require.NoError(t, repo.Delete(client, ctx, dora, ""), "already deleted")
+	_, err = repo.Put(client, ctx, dora, updated)
+	assert.True(t, k8serrors.IsConflict(err), "updated after a delete: %v", err)
+
+	// The ConfigMaps are labelled and annotated with the user
+	configMaps, err := client.DynamicResource(configMapsGVR)
+	require.NoError(t, err)
+	_, err = repo.Put(client, ctx, bella, models.UserPreferences{Theme: "dark"})
+	require.NoError(t, err)
+	list, err := configMaps.Namespace("kubeflow").List(ctx, metav1.ListOptions{LabelSelector: PreferencesSelector})
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+	assert.Equal(t, "bellaNonAdmin@example.com", list.Items[0].GetAnnotations()[PreferencesUserAnnotation])
+}
+
+func TestPreferencesRepository_Limits(t *testing.T) {
+	client := k8mocks.NewMockKubernetesClient(k8mocks.DefaultMockFixtures(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	dora := &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com"}
+	ctx := context.Background()
+	repo := NewPreferencesRepository()
+
+	_, err := repo.Get(client, ctx, dora)
+	assert.Equal(t, http.StatusServiceUnavailable, apierrors.FromError(err).StatusCode, "no namespace: %v", err)
+
+	repo.UseNamespace("kubeflow")
+	large := make([]string, MaxPreferencesSize/8)
+	for i := range large {
+		large[i] = "namespace"
+	}
+	_, err = repo.Put(client, ctx, dora, models.UserPreferences{PinnedNamespaces: large})
+	assert.Equal(t, http.StatusRequestEntityTooLarge, apierrors.FromError(err).StatusCode, "got %v", err)
+}
@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/parallel"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// profilesGVR is the resource of the Kubeflow Profiles, cluster-scoped and named after their
+// namespace.
+var profilesGVR = schema.GroupVersionResource{Group: "kubeflow.org", Version: "v1", Resource: "profiles"}
+
+// ProfileAccessWorkers bounds the access reviews of the Profile namespaces run in parallel.
+const ProfileAccessWorkers = 10
+
+// getProfileNamespaces returns the namespaces of the Kubeflow Profiles identity can get, with
+// the owner of each, like the namespace selector of the Kubeflow Central Dashboard. The
+// Profiles are listed with the credentials of client; the access to each namespace is reviewed
+// unless identity is a cluster admin, so contributors see the namespaces shared with them.
+func getProfileNamespaces(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity) ([]models.NamespaceModel, error) {
+	resource, err := client.DynamicResource(profilesGVR)
+	if err != nil {
+		return nil, err
+	}
+	list, err := resource.List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing Kubeflow profiles: %w", err)
+	}
+
+	allowed := make([]bool, len(list.Items))
+	isAdmin, err := client.IsClusterAdmin(ctx, identity)
+	if err != nil {
+		// Reviewed namespace by namespace instead
+		logger.FromContext(ctx).Warn("failed to check cluster admin status", "user", identity.UserID, "error", err)
+	}
+	err = parallel.ForEach(ctx, parallel.Options{Limit: ProfileAccessWorkers}, len(list.Items), func(ctx context.Context, i int) {
+		if isAdmin {
+			allowed[i] = true
+			return
+		}
+		ok, err := client.CanAccess(ctx, identity, "get", "", "namespaces", list.Items[i].GetName())
+		if err != nil {
+			logger.FromContext(ctx).Warn("failed to review the access to a profile namespace", "namespace", list.Items[i].GetName(), "error", err)
+			return
+		}
+		allowed[i] = ok
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := []models.NamespaceModel{}
+	for i := range list.Items {
+		if !allowed[i] {
+			continue
+		}
+		namespace := models.NewNamespaceModelFromNamespace(list.Items[i].GetName())
+		namespace.Owner = profileOwner(&list.Items[i])
+		namespaces = append(namespaces, namespace)
+	}
+	return namespaces, nil
+}
+
+// profileOwner returns the user owning profile, or "" for a group or missing owner.
+func profileOwner(profile *unstructured.Unstructured) string {
+	kind, _, _ := unstructured.NestedString(profile.Object, "spec", "owner", "kind")
+	name, _, _ := unstructured.NestedString(profile.Object, "spec", "owner", "name")
+	if kind != "User" {
+		return ""
+	}
+	return name
+}
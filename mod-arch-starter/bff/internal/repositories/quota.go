@@ -0,0 +1,180 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/parallel"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	resourceQuotasGVR = schema.GroupVersionResource{Version: "v1", Resource: "resourcequotas"}
+	limitRangesGVR    = schema.GroupVersionResource{Version: "v1", Resource: "limitranges"}
+)
+
+// QuotaRepository reads the ResourceQuotas and LimitRanges of a namespace, with the usage the
+// quota controller records in the status of the quotas.
+type QuotaRepository struct{}
+
+func NewQuotaRepository() *QuotaRepository {
+	return &QuotaRepository{}
+}
+
+// GetQuota returns the quotas and limit ranges of namespace, sorted by name, and the usage of
+// every resource against the quota it uses the most. The identity must be allowed to list
+// resourcequotas and limitranges in the namespace.
+func (r *QuotaRepository) GetQuota(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, namespace string) (models.QuotaModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "QuotaRepository.GetQuota", attribute.String("k8s.namespace.name", namespace))
+	defer span.End()
+
+	var quotas []corev1.ResourceQuota
+	var limitRanges []corev1.LimitRange
+	err := parallel.Do(ctx, parallel.Options{},
+		func(ctx context.Context) error {
+			var err error
+			quotas, err = listAllowed[corev1.ResourceQuota](client, ctx, identity, resourceQuotasGVR, namespace)
+			return err
+		},
+		func(ctx context.Context) error {
+			var err error
+			limitRanges, err = listAllowed[corev1.LimitRange](client, ctx, identity, limitRangesGVR, namespace)
+			return err
+		},
+	)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return models.QuotaModel{}, err
+	}
+
+	quota := models.QuotaModel{
+		Namespace:   namespace,
+		Resources:   []models.ResourceUsageModel{},
+		Quotas:      make([]models.ResourceQuotaModel, 0, len(quotas)),
+		LimitRanges: make([]models.LimitRangeModel, 0, len(limitRanges)),
+	}
+	sort.Slice(quotas, func(i, j int) bool { return quotas[i].Name < quotas[j].Name })
+	most := map[string]int{}
+	for _, resourceQuota := range quotas {
+		model := newResourceQuotaModel(&resourceQuota)
+		for _, usage := range model.Resources {
+			usage.Quota = model.Name
+			if i, ok := most[usage.Resource]; !ok {
+				most[usage.Resource] = len(quota.Resources)
+				quota.Resources = append(quota.Resources, usage)
+			} else if usage.Percent > quota.Resources[i].Percent {
+				quota.Resources[i] = usage
+			}
+		}
+		quota.Quotas = append(quota.Quotas, model)
+	}
+	sort.Slice(quota.Resources, func(i, j int) bool { return quota.Resources[i].Resource < quota.Resources[j].Resource })
+
+	sort.Slice(limitRanges, func(i, j int) bool { return limitRanges[i].Name < limitRanges[j].Name })
+	for _, limitRange := range limitRanges {
+		quota.LimitRanges = append(quota.LimitRanges, newLimitRangeModel(&limitRange))
+	}
+	span.SetAttributes(attribute.Int("bff.quota.count", len(quota.Quotas)))
+	return quota, nil
+}
+
+// listAllowed lists the objects of gvr in namespace once the identity is allowed to.
+func listAllowed[T any](client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, gvr schema.GroupVersionResource, namespace string) ([]T, error) {
+	allowed, err := client.CanAccess(ctx, identity, "list", gvr.Group, gvr.Resource, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("error checking access to %s: %w", gvr.Resource, err)
+	}
+	if !allowed {
+		return nil, k8serrors.NewForbidden(gvr.GroupResource(), "", fmt.Errorf("user cannot list %s in namespace %s", gvr.Resource, namespace))
+	}
+	objects, err := listObjects[T](client, ctx, gvr, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching %s: %w", gvr.Resource, err)
+	}
+	return objects, nil
+}
+
+// newResourceQuotaModel reports the usage of the hard limits of the status of quota, or of its
+// spec until the quota controller has seen it.
+func newResourceQuotaModel(quota *corev1.ResourceQuota) models.ResourceQuotaModel {
+	hard := quota.Status.Hard
+	if len(hard) == 0 {
+		hard = quota.Spec.Hard
+	}
+	model := models.ResourceQuotaModel{Name: quota.Name, Resources: make([]models.ResourceUsageModel, 0, len(hard))}
+	for _, scope := range quota.Spec.Scopes {
+		model.Scopes = append(model.Scopes, string(scope))
+	}
+	for name, limit := range hard {
+		used := quota.Status.Used[name]
+		model.Resources = append(model.Resources, models.ResourceUsageModel{
+			Resource: quotaResourceName(name),
+			Used:     used.String(),
+			Hard:     limit.String(),
+			Percent:  usagePercent(used, limit),
+		})
+	}
+	sort.Slice(model.Resources, func(i, j int) bool { return model.Resources[i].Resource < model.Resources[j].Resource })
+	return model
+}
+
+// quotaResourceName names the standard resources by their requests.* equivalent in quotas,
+// so "cpu" and "requests.cpu" are the same resource.
+func quotaResourceName(name corev1.ResourceName) string {
+	switch name {
+	case corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage:
+		return corev1.DefaultResourceRequestsPrefix + string(name)
+	}
+	return string(name)
+}
+
+func usagePercent(used, hard resource.Quantity) float64 {
+	if hard.IsZero() {
+		return 100
+	}
+	return math.Round(used.AsApproximateFloat64()/hard.AsApproximateFloat64()*1000) / 10
+}
+
+func newLimitRangeModel(limitRange *corev1.LimitRange) models.LimitRangeModel {
+	model := models.LimitRangeModel{Name: limitRange.Name, Limits: []models.LimitModel{}}
+	for _, item := range limitRange.Spec.Limits {
+		names := map[corev1.ResourceName]bool{}
+		for _, list := range []corev1.ResourceList{item.Min, item.Max, item.Default, item.DefaultRequest, item.MaxLimitRequestRatio} {
+			for name := range list {
+				names[name] = true
+			}
+		}
+		start := len(model.Limits)
+		for name := range names {
+			model.Limits = append(model.Limits, models.LimitModel{
+				Type:                 string(item.Type),
+				Resource:             string(name),
+				Min:                  quantityString(item.Min, name),
+				Max:                  quantityString(item.Max, name),
+				Default:              quantityString(item.Default, name),
+				DefaultRequest:       quantityString(item.DefaultRequest, name),
+				MaxLimitRequestRatio: quantityString(item.MaxLimitRequestRatio, name),
+			})
+		}
+		limits := model.Limits[start:]
+		sort.Slice(limits, func(i, j int) bool { return limits[i].Resource < limits[j].Resource })
+	}
+	return model
+}
+
+// quantityString is the quantity of name in list, empty when not set.
+func quantityString(list corev1.ResourceList, name corev1.ResourceName) string {
+	if quantity, ok := list[name]; ok {
+		return quantity.String()
+	}
+	return ""
+}
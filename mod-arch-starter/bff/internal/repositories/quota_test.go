@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes/k8mocks"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func TestQuotaRepository(t *testing.T) {
+	client := k8mocks.NewMockKubernetesClient(k8mocks.DefaultMockFixtures(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	seed(t, client, resourceQuotasGVR, "dora-namespace", map[string]any{
+		"apiVersion": "v1", "kind": "ResourceQuota",
+		"metadata": map[string]any{"name": "compute", "namespace": "dora-namespace"},
+		"spec":     map[string]any{"hard": map[string]any{"cpu": "4", "requests.nvidia.com/gpu": "5"}},
+		"status": map[string]any{
+			"hard": map[string]any{"cpu": "4", "requests.nvidia.com/gpu": "5"},
+			"used": map[string]any{"cpu": "1500m", "requests.nvidia.com/gpu": "4"},
+		},
+	})
+	seed(t, client, resourceQuotasGVR, "dora-namespace", map[string]any{
+		"apiVersion": "v1", "kind": "ResourceQuota",
+		"metadata": map[string]any{"name": "best-effort", "namespace": "dora-namespace"},
+		"spec":     map[string]any{"hard": map[string]any{"requests.cpu": "2", "count/pods": "0"}, "scopes": []any{"BestEffort"}},
+		"status": map[string]any{
+			"hard": map[string]any{"requests.cpu": "2", "count/pods": "0"},
+			"used": map[string]any{"requests.cpu": "1"},
+		},
+	})
+	// Not seen by the quota controller yet
+	seed(t, client, resourceQuotasGVR, "dora-namespace", map[string]any{
+		"apiVersion": "v1", "kind": "ResourceQuota",
+		"metadata": map[string]any{"name": "storage", "namespace": "dora-namespace"},
+		"spec":     map[string]any{"hard": map[string]any{"requests.storage": "100Gi"}},
+	})
+	seed(t, client, limitRangesGVR, "dora-namespace", map[string]any{
+		"apiVersion": "v1", "kind": "LimitRange",
+		"metadata": map[string]any{"name": "defaults", "namespace": "dora-namespace"},
+		"spec": map[string]any{"limits": []any{map[string]any{
+			"type":           "Container",
+			"max":            map[string]any{"cpu": "8", "memory": "32Gi"},
+			"default":        map[string]any{"cpu": "1"},
+			"defaultRequest": map[string]any{"cpu": "500m"},
+		}}},
+	})
+
+	repo := NewQuotaRepository()
+	dora := &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com"}
+	quota, err := repo.GetQuota(client, context.Background(), dora, "dora-namespace")
+	require.NoError(t, err)
+
+	assert.Equal(t, "dora-namespace", quota.Namespace)
+	assert.Equal(t, []models.ResourceUsageModel{
+		{Resource: "count/pods", Used: "0", Hard: "0", Percent: 100, Quota: "best-effort"},
+		{Resource: "requests.cpu", Used: "1", Hard: "2", Percent: 50, Quota: "best-effort"},
+		{Resource: "requests.nvidia.com/gpu", Used: "4", Hard: "5", Percent: 80, Quota: "compute"},
+		{Resource: "requests.storage", Used: "0", Hard: "100Gi", Percent: 0, Quota: "storage"},
+	}, quota.Resources)
+	require.Len(t, quota.Quotas, 3)
+	assert.Equal(t, models.ResourceQuotaModel{
+		Name: "compute",
+		Resources: []models.ResourceUsageModel{
+			{Resource: "requests.cpu", Used: "1500m", Hard: "4", Percent: 37.5},
+			{Resource: "requests.nvidia.com/gpu", Used: "4", Hard: "5", Percent: 80},
+		},
+	}, quota.Quotas[1])
+	assert.Equal(t, []string{"BestEffort"}, quota.Quotas[0].Scopes)
+	assert.Equal(t, []models.LimitRangeModel{{Name: "defaults", Limits: []models.LimitModel{
+		{Type: "Container", Resource: "cpu", Max: "8", Default: "1", DefaultRequest: "500m"},
+		{Type: "Container", Resource: "memory", Max: "32Gi"},
+	}}}, quota.LimitRanges)
+
+	// Empty namespace
+	quota, err = repo.GetQuota(client, context.Background(), &k8s.RequestIdentity{UserID: "bellaNonAdmin@example.com"}, "bella-namespace")
+	require.NoError(t, err)
+	assert.Empty(t, quota.Resources)
+	assert.Empty(t, quota.Quotas)
+	assert.Empty(t, quota.LimitRanges)
+
+	_, err = repo.GetQuota(client, context.Background(), dora, "bella-namespace")
+	assert.True(t, k8serrors.IsForbidden(err), err)
+}
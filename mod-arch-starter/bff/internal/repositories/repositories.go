@@ -2,15 +2,47 @@ package repositories
 
 // Repositories struct is a single convenient container to hold and represent all our repositories.
 type Repositories struct {
-	HealthCheck *HealthCheckRepository
-	User        *UserRepository
-	Namespace   *NamespaceRepository
+	HealthCheck     *HealthCheckRepository
+	ClusterHealth   *ClusterHealthRepository
+	User            *UserRepository
+	Namespace       *NamespaceRepository
+	Permission      *PermissionRepository
+	Watch           *WatchRepository
+	Logs            *LogsRepository
+	Events          *EventsRepository
+	Service         *ServiceRepository
+	DynamicResource *DynamicResourceRepository
+	Secret          *SecretRepository
+	ConfigMap       *ConfigMapRepository
+	Preferences     *PreferencesRepository
+	ModelRegistry   *ModelRegistryRepository
+	ObjectStorage   *ObjectStorageRepository
+	Hardware        *HardwareProfileRepository
+	Quota           *QuotaRepository
+	RoleBinding     *RoleBindingRepository
+	ServiceAccount  *ServiceAccountTokenRepository
 }
 
 func NewRepositories() *Repositories {
 	return &Repositories{
-		HealthCheck: NewHealthCheckRepository(),
-		User:        NewUserRepository(),
-		Namespace:   NewNamespaceRepository(),
+		HealthCheck:     NewHealthCheckRepository(),
+		ClusterHealth:   NewClusterHealthRepository(),
+		User:            NewUserRepository(),
+		Namespace:       NewNamespaceRepository(),
+		Permission:      NewPermissionRepository(),
+		Watch:           NewWatchRepository(),
+		Logs:            NewLogsRepository(),
+		Events:          NewEventsRepository(),
+		Service:         NewServiceRepository(),
+		DynamicResource: NewDynamicResourceRepository(),
+		Secret:          NewSecretRepository(),
+		ConfigMap:       NewConfigMapRepository(),
+		Preferences:     NewPreferencesRepository(),
+		ModelRegistry:   NewModelRegistryRepository(),
+		ObjectStorage:   NewObjectStorageRepository(),
+		Hardware:        NewHardwareProfileRepository(),
+		Quota:           NewQuotaRepository(),
+		RoleBinding:     NewRoleBindingRepository(),
+		ServiceAccount:  NewServiceAccountTokenRepository(),
 	}
 }
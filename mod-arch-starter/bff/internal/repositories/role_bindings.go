@@ -0,0 +1,184 @@
+package repositories
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var roleBindingsGVR = schema.GroupVersionResource{Group: rbacv1.GroupName, Version: "v1", Resource: "rolebindings"}
+
+// dashboardLabel marks the RoleBindings shown by the OpenDataHub dashboard, which lists the
+// access granted through either UI.
+const dashboardLabel = "opendatahub.io/dashboard"
+
+// sharingRoles are the ClusterRoles of the bindings listed by RoleBindingRepository.
+var sharingRoles = []string{models.RoleAdmin, models.RoleEdit, models.RoleView}
+
+// RoleBindingRepository lists, grants and revokes the access of users and groups to a
+// namespace, for the "share this project" UIs: RoleBindings of the admin, edit and view
+// ClusterRoles. Other bindings, and service account subjects, are left out.
+//
+// Access is reviewed for every call, like DynamicResourceRepository, so only the identities
+// allowed to manage the RoleBindings of the namespace, i.e. its admins, list and change them.
+type RoleBindingRepository struct {
+	objects *DynamicResourceRepository
+	manager string
+}
+
+func NewRoleBindingRepository() *RoleBindingRepository {
+	return &RoleBindingRepository{objects: NewDynamicResourceRepository(), manager: DefaultManager}
+}
+
+// UseManager sets the module name recorded in ManagedByAnnotation, and of the field managers
+// of the writes.
+func (r *RoleBindingRepository) UseManager(name string) {
+	r.manager = name
+	r.objects.UseFieldManager(name)
+}
+
+// List returns the bindings of the sharing roles in namespace, sorted by name.
+func (r *RoleBindingRepository) List(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, namespace string) ([]models.RoleBindingModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "RoleBindingRepository.List", attribute.String("k8s.namespace.name", namespace))
+	defer span.End()
+
+	list, err := r.objects.List(client, ctx, identity, roleBindingsGVR, namespace, metav1.ListOptions{})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	bindings := []models.RoleBindingModel{}
+	for i := range list.Items {
+		binding, err := fromUnstructured[rbacv1.RoleBinding](&list.Items[i])
+		if err != nil {
+			tracing.RecordError(span, err)
+			return nil, err
+		}
+		if model, ok := newRoleBindingModel(binding); ok {
+			bindings = append(bindings, model)
+		}
+	}
+	sort.Slice(bindings, func(i, j int) bool { return bindings[i].Name < bindings[j].Name })
+	span.SetAttributes(attribute.Int("bff.rolebindings.count", len(bindings)))
+	return bindings, nil
+}
+
+// Grant binds the role to the subject in namespace, tagged as managed by the module and
+// created by the identity. The binding is created as the identity, so the API server checks
+// that it holds the permissions of the role or may bind it. The binding is named after the role and subject, so granting the
+// same access twice fails with a conflict.
+func (r *RoleBindingRepository) Grant(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, namespace string, request models.RoleBindingRequest) (models.RoleBindingModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "RoleBindingRepository.Grant",
+		attribute.String("k8s.namespace.name", namespace),
+		attribute.String("bff.rolebinding.role", request.Role),
+	)
+	defer span.End()
+
+	binding := &rbacv1.RoleBinding{
+		TypeMeta: metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "RoleBinding"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      roleBindingName(request),
+			Namespace: namespace,
+			Labels:    map[string]string{dashboardLabel: "true"},
+		},
+		RoleRef:  rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: request.Role},
+		Subjects: []rbacv1.Subject{{APIGroup: rbacv1.GroupName, Kind: request.Subject.Kind, Name: request.Subject.Name}},
+	}
+	tagManaged(&binding.ObjectMeta, r.manager, identity)
+
+	obj, err := toUnstructured(binding)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return models.RoleBindingModel{}, err
+	}
+	// The API server refuses the bindings granting more than their creator may do, unless it
+	// may bind the role: create it as the identity, not with the backend credentials, which
+	// may bind the sharing roles.
+	if impersonator, ok := client.(k8s.Impersonator); ok {
+		if client, err = impersonator.Impersonate(identity); err != nil {
+			tracing.RecordError(span, err)
+			return models.RoleBindingModel{}, err
+		}
+	}
+	created, err := r.objects.Create(client, ctx, identity, roleBindingsGVR, namespace, obj)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return models.RoleBindingModel{}, err
+	}
+	if binding, err = fromUnstructured[rbacv1.RoleBinding](created); err != nil {
+		tracing.RecordError(span, err)
+		return models.RoleBindingModel{}, err
+	}
+	model, _ := newRoleBindingModel(binding)
+	return model, nil
+}
+
+// Revoke deletes the named binding of a sharing role, revoking the access of all its
+// subjects. Other RoleBindings can't be deleted through it.
+func (r *RoleBindingRepository) Revoke(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, namespace, name string) error {
+	ctx, span := tracing.StartSpan(ctx, "RoleBindingRepository.Revoke", attribute.String("k8s.namespace.name", namespace))
+	defer span.End()
+
+	obj, err := r.objects.Get(client, ctx, identity, roleBindingsGVR, namespace, name)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+	binding, err := fromUnstructured[rbacv1.RoleBinding](obj)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+	if _, ok := newRoleBindingModel(binding); !ok {
+		err := k8serrors.NewForbidden(roleBindingsGVR.GroupResource(), name, fmt.Errorf("rolebinding %q does not share the namespace", name))
+		tracing.RecordError(span, err)
+		return err
+	}
+	if err := r.objects.Delete(client, ctx, identity, roleBindingsGVR, namespace, name); err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+	return nil
+}
+
+// newRoleBindingModel returns the model of a binding of a sharing role to users or groups,
+// and false for the other bindings.
+func newRoleBindingModel(binding *rbacv1.RoleBinding) (models.RoleBindingModel, bool) {
+	if binding.RoleRef.Kind != "ClusterRole" || !slices.Contains(sharingRoles, binding.RoleRef.Name) {
+		return models.RoleBindingModel{}, false
+	}
+	model := models.RoleBindingModel{
+		Name:              binding.Name,
+		Role:              binding.RoleRef.Name,
+		Subjects:          []models.RoleBindingSubjectModel{},
+		ManagedBy:         binding.Annotations[ManagedByAnnotation],
+		CreatedBy:         binding.Annotations[CreatedByAnnotation],
+		CreationTimestamp: creationTime(&binding.ObjectMeta),
+	}
+	for _, subject := range binding.Subjects {
+		if subject.Kind == rbacv1.UserKind || subject.Kind == rbacv1.GroupKind {
+			model.Subjects = append(model.Subjects, models.RoleBindingSubjectModel{Kind: subject.Kind, Name: subject.Name})
+		}
+	}
+	return model, len(model.Subjects) > 0
+}
+
+// roleBindingName names the binding of a grant, e.g. "edit-user-3f9a1c2b": user and group
+// names may not be valid object names.
+func roleBindingName(request models.RoleBindingRequest) string {
+	sum := sha256.Sum256([]byte(request.Subject.Kind + "/" + request.Subject.Name))
+	return fmt.Sprintf("%s-%s-%s", request.Role, strings.ToLower(request.Subject.Kind), hex.EncodeToString(sum[:4]))
+}
@@ -0,0 +1,153 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes/k8mocks"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+func roleBindingObject(name, roleKind, role string, subjects ...map[string]any) map[string]any {
+	items := make([]any, len(subjects))
+	for i, subject := range subjects {
+		items[i] = subject
+	}
+	return map[string]any{
+		"apiVersion": "rbac.authorization.k8s.io/v1", "kind": "RoleBinding",
+		"metadata": map[string]any{"name": name, "namespace": "dora-namespace"},
+		"roleRef":  map[string]any{"apiGroup": "rbac.authorization.k8s.io", "kind": roleKind, "name": role},
+		"subjects": items,
+	}
+}
+
+func TestRoleBindingRepository(t *testing.T) {
+	client := k8mocks.NewMockKubernetesClient(k8mocks.DefaultMockFixtures(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	user := func(name string) map[string]any { return map[string]any{"kind": "User", "name": name} }
+	seed(t, client, roleBindingsGVR, "dora-namespace", roleBindingObject("admin", "ClusterRole", "admin", user("dora@example.com")))
+	seed(t, client, roleBindingsGVR, "dora-namespace", roleBindingObject("team-view", "ClusterRole", "view",
+		map[string]any{"kind": "Group", "name": "data-scientists"},
+		map[string]any{"kind": "ServiceAccount", "name": "pipeline-runner", "namespace": "dora-namespace"}))
+	seed(t, client, roleBindingsGVR, "dora-namespace", roleBindingObject("pipeline-runner", "ClusterRole", "edit",
+		map[string]any{"kind": "ServiceAccount", "name": "pipeline-runner", "namespace": "dora-namespace"}))
+	seed(t, client, roleBindingsGVR, "dora-namespace", roleBindingObject("operator", "Role", "operator", user("bella@example.com")))
+
+	repo := NewRoleBindingRepository()
+	ctx := context.Background()
+	dora := &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com"}
+
+	bindings, err := repo.List(client, ctx, dora, "dora-namespace")
+	require.NoError(t, err)
+	assert.Equal(t, []models.RoleBindingModel{
+		{Name: "admin", Role: models.RoleAdmin, Subjects: []models.RoleBindingSubjectModel{{Kind: "User", Name: "dora@example.com"}}},
+		{Name: "team-view", Role: models.RoleView, Subjects: []models.RoleBindingSubjectModel{{Kind: "Group", Name: "data-scientists"}}},
+	}, bindings, "bindings of other roles and to service accounts only are left out")
+
+	request := models.RoleBindingRequest{Role: models.RoleEdit, Subject: models.RoleBindingSubjectModel{Kind: "User", Name: "bella@example.com"}}
+	granted, err := repo.Grant(client, ctx, dora, "dora-namespace", request)
+	require.NoError(t, err)
+	assert.Equal(t, roleBindingName(request), granted.Name)
+	assert.Regexp(t, `^edit-user-[0-9a-f]{8}$`, granted.Name)
+	assert.Equal(t, models.RoleEdit, granted.Role)
+	assert.Equal(t, []models.RoleBindingSubjectModel{request.Subject}, granted.Subjects)
+	assert.Equal(t, DefaultManager, granted.ManagedBy)
+	assert.Equal(t, dora.UserID, granted.CreatedBy)
+
+	_, err = repo.Grant(client, ctx, dora, "dora-namespace", request)
+	assert.True(t, k8serrors.IsAlreadyExists(err), err)
+	_, err = repo.Grant(client, ctx, &k8s.RequestIdentity{UserID: "bellaNonAdmin@example.com"}, "dora-namespace", request)
+	assert.True(t, k8serrors.IsForbidden(err), err)
+
+	require.NoError(t, repo.Revoke(client, ctx, dora, "dora-namespace", granted.Name))
+	bindings, err = repo.List(client, ctx, dora, "dora-namespace")
+	require.NoError(t, err)
+	assert.Len(t, bindings, 2)
+	err = repo.Revoke(client, ctx, dora, "dora-namespace", "operator")
+	assert.True(t, k8serrors.IsForbidden(err), "only bindings of the sharing roles are revoked")
+	err = repo.Revoke(client, ctx, dora, "dora-namespace", granted.Name)
+	assert.True(t, k8serrors.IsNotFound(err), err)
+}
+
+// impersonatingClient is a client with the backend credentials, which may bind every role,
+// whose impersonated clients may only bind the roles of binders, like the API server checking
+// the RoleBindings for privilege escalation.
+type impersonatingClient struct {
+	*k8mocks.MockKubernetesClient
+	binders      []string
+	impersonated []string
+}
+
+func (c *impersonatingClient) Impersonate(identity *k8s.RequestIdentity) (k8s.KubernetesClientInterface, error) {
+	c.impersonated = append(c.impersonated, identity.UserID)
+	return &impersonatedClient{MockKubernetesClient: c.MockKubernetesClient, identity: identity, bind: slices.Contains(c.binders, identity.UserID)}, nil
+}
+
+type impersonatedClient struct {
+	*k8mocks.MockKubernetesClient
+	identity *k8s.RequestIdentity
+	bind     bool
+}
+
+func (c *impersonatedClient) DynamicResource(gvr schema.GroupVersionResource) (dynamic.NamespaceableResourceInterface, error) {
+	resource, err := c.MockKubernetesClient.DynamicResource(gvr)
+	if err != nil || c.bind {
+		return resource, err
+	}
+	return &escalationRefusingResource{NamespaceableResourceInterface: resource, identity: c.identity}, nil
+}
+
+type escalationRefusingResource struct {
+	dynamic.NamespaceableResourceInterface
+	identity *k8s.RequestIdentity
+}
+
+func (r *escalationRefusingResource) Namespace(namespace string) dynamic.ResourceInterface {
+	return &escalationRefusingNamespace{ResourceInterface: r.NamespaceableResourceInterface.Namespace(namespace), identity: r.identity}
+}
+
+type escalationRefusingNamespace struct {
+	dynamic.ResourceInterface
+	identity *k8s.RequestIdentity
+}
+
+func (r *escalationRefusingNamespace) Create(_ context.Context, obj *unstructured.Unstructured, _ metav1.CreateOptions, _ ...string) (*unstructured.Unstructured, error) {
+	return nil, k8serrors.NewForbidden(roleBindingsGVR.GroupResource(), obj.GetName(),
+		fmt.Errorf("user %q (groups=%q) is attempting to grant RBAC permissions not currently held", r.identity.UserID, r.identity.Groups))
+}
+
+func TestRoleBindingRepository_GrantAsIdentity(t *testing.T) {
+	mock := k8mocks.NewMockKubernetesClient(k8mocks.DefaultMockFixtures(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	repo := NewRoleBindingRepository()
+	ctx := context.Background()
+	dora := &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com"}
+	request := models.RoleBindingRequest{Role: models.RoleEdit, Subject: models.RoleBindingSubjectModel{Kind: "User", Name: "bella@example.com"}}
+
+	allowed, err := mock.CanAccess(ctx, dora, "create", "rbac.authorization.k8s.io", "rolebindings", "dora-namespace")
+	require.NoError(t, err)
+	require.True(t, allowed, "dora may create the rolebindings of her namespace")
+
+	client := &impersonatingClient{MockKubernetesClient: mock}
+	_, err = repo.Grant(client, ctx, dora, "dora-namespace", request)
+	assert.True(t, k8serrors.IsForbidden(err), "the binding is created as dora, who may not bind the role: %v", err)
+	assert.Equal(t, []string{dora.UserID}, client.impersonated)
+	bindings, err := repo.List(client, ctx, dora, "dora-namespace")
+	require.NoError(t, err)
+	assert.Empty(t, bindings)
+
+	client.binders = []string{dora.UserID}
+	granted, err := repo.Grant(client, ctx, dora, "dora-namespace", request)
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleEdit, granted.Role)
+}
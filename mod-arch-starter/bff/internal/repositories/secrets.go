@@ -0,0 +1,205 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/objectdiff"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var secretsGVR = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+
+// SecretRepository lists, reads, creates and updates the Secrets of a namespace. Values are
+// redacted unless a read asks to reveal them and the identity passes the reveal check of the
+// RedactionPolicy. Secrets created through it are tagged with ManagedByAnnotation, and only
+// those can be updated, so modules can't overwrite Secrets they don't own.
+//
+// Access is reviewed for every call, like DynamicResourceRepository.
+type SecretRepository struct {
+	objects *DynamicResourceRepository
+	manager string
+	policy  RedactionPolicy
+}
+
+func NewSecretRepository() *SecretRepository {
+	objects := NewDynamicResourceRepository()
+	objects.RegisterRedactor(secretsGVR, func(path string) bool {
+		segments := objectdiff.Segments(path)
+		return len(segments) > 0 && (segments[0] == "data" || segments[0] == "stringData")
+	})
+	return &SecretRepository{objects: objects, manager: DefaultManager}
+}
+
+// UseManager sets the module name recorded in ManagedByAnnotation, and of the field managers
+// of the writes.
+func (r *SecretRepository) UseManager(name string) {
+	r.manager = name
+	r.objects.UseFieldManager(name)
+}
+
+// UseRedactionPolicy sets the reveal check of Get.
+func (r *SecretRepository) UseRedactionPolicy(policy RedactionPolicy) {
+	r.policy = policy
+}
+
+// List returns the Secrets of namespace, redacted and sorted by name. managedOnly keeps the
+// Secrets created by the module.
+func (r *SecretRepository) List(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, namespace string, managedOnly bool) ([]models.SecretModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "SecretRepository.List", attribute.String("k8s.namespace.name", namespace))
+	defer span.End()
+
+	list, err := r.objects.List(client, ctx, identity, secretsGVR, namespace, metav1.ListOptions{})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	secrets := []models.SecretModel{}
+	for i := range list.Items {
+		secret, err := fromUnstructured[corev1.Secret](&list.Items[i])
+		if err != nil {
+			tracing.RecordError(span, err)
+			return nil, err
+		}
+		if managedOnly && secret.Annotations[ManagedByAnnotation] != r.manager {
+			continue
+		}
+		secrets = append(secrets, newSecretModel(secret, true))
+	}
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].Name < secrets[j].Name })
+	return secrets, nil
+}
+
+// Get returns the named Secret, with its values when reveal is set and the identity may
+// reveal them.
+func (r *SecretRepository) Get(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, namespace, name string, reveal bool) (models.SecretModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "SecretRepository.Get",
+		attribute.String("k8s.namespace.name", namespace),
+		attribute.Bool("bff.reveal", reveal),
+	)
+	defer span.End()
+
+	if reveal {
+		if err := r.policy.checkReveal(client, ctx, identity, secretsGVR.Resource, namespace, name); err != nil {
+			tracing.RecordError(span, err)
+			return models.SecretModel{}, err
+		}
+	}
+	secret, err := r.get(client, ctx, identity, namespace, name)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return models.SecretModel{}, err
+	}
+	return newSecretModel(secret, !reveal), nil
+}
+
+// Create creates the Secret in namespace, tagged as managed by the module and created by the
+// identity, and returns it redacted.
+func (r *SecretRepository) Create(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, namespace string, model models.SecretModel) (models.SecretModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "SecretRepository.Create", attribute.String("k8s.namespace.name", namespace))
+	defer span.End()
+
+	secret := &corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{Name: model.Name, Namespace: namespace, Labels: model.Labels},
+		Type:       corev1.SecretType(model.Type),
+		Data:       model.Data,
+	}
+	tagManaged(&secret.ObjectMeta, r.manager, identity)
+
+	created, err := r.apply(client, ctx, identity, namespace, secret, ApplyOptions{CreateOnly: true})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return models.SecretModel{}, err
+	}
+	return newSecretModel(created, true), nil
+}
+
+// Update applies the data, and the labels when set, of a Secret managed by the module and
+// returns it redacted. The keys listed in RedactedKeys keep their current value. When
+// ResourceVersion is set the update fails with a conflict if the Secret changed since.
+//
+// The update is a server-side apply as the field manager of the module for the identity:
+// the keys and labels it applied before and left out are removed, those set by other
+// controllers are kept, and setting a key another manager owns to another value fails with a
+// conflict (see kubernetes.ApplyConflicts) unless force takes it over.
+func (r *SecretRepository) Update(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, namespace string, model models.SecretModel, force bool) (models.SecretModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "SecretRepository.Update", attribute.String("k8s.namespace.name", namespace))
+	defer span.End()
+
+	secret, err := r.get(client, ctx, identity, namespace, model.Name)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return models.SecretModel{}, err
+	}
+	if err := checkManaged(&secret.ObjectMeta, r.manager, secretsGVR.GroupResource()); err != nil {
+		tracing.RecordError(span, err)
+		return models.SecretModel{}, err
+	}
+
+	applied := &corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: appliedMeta(&secret.ObjectMeta, model.Labels, model.ResourceVersion),
+		Type:       secret.Type,
+		Data:       keepRedacted(model.Data, model.RedactedKeys, secret.Data),
+	}
+	if model.Type != "" {
+		applied.Type = corev1.SecretType(model.Type)
+	}
+
+	updated, err := r.apply(client, ctx, identity, namespace, applied, ApplyOptions{Force: force})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return models.SecretModel{}, err
+	}
+	return newSecretModel(updated, true), nil
+}
+
+func (r *SecretRepository) get(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, namespace, name string) (*corev1.Secret, error) {
+	obj, err := r.objects.Get(client, ctx, identity, secretsGVR, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return fromUnstructured[corev1.Secret](obj)
+}
+
+func (r *SecretRepository) apply(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, namespace string, secret *corev1.Secret, opts ApplyOptions) (*corev1.Secret, error) {
+	obj, err := toUnstructured(secret)
+	if err != nil {
+		return nil, err
+	}
+	written, err := r.objects.Apply(client, ctx, identity, secretsGVR, namespace, obj, opts)
+	if err != nil {
+		return nil, err
+	}
+	return fromUnstructured[corev1.Secret](written)
+}
+
+func newSecretModel(secret *corev1.Secret, redact bool) models.SecretModel {
+	model := models.SecretModel{
+		Name:              secret.Name,
+		Namespace:         secret.Namespace,
+		Type:              string(secret.Type),
+		Labels:            secret.Labels,
+		Data:              make(map[string][]byte, len(secret.Data)),
+		ManagedBy:         secret.Annotations[ManagedByAnnotation],
+		ResourceVersion:   secret.ResourceVersion,
+		CreationTimestamp: creationTime(&secret.ObjectMeta),
+	}
+	for key, value := range secret.Data {
+		if redact {
+			value = nil
+		}
+		model.Data[key] = value
+	}
+	if redact {
+		model.RedactedKeys = sortedKeys(secret.Data)
+	}
+	return model
+}
@@ -0,0 +1,190 @@
+package repositories
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes/k8mocks"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSecretRepository(t *testing.T) {
+	client := k8mocks.NewMockKubernetesClient(k8mocks.DefaultMockFixtures(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	dora := &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com"}
+	ctx := context.Background()
+	repo := NewSecretRepository()
+	repo.UseManager("notebooks")
+
+	created, err := repo.Create(client, ctx, dora, "dora-namespace", models.SecretModel{
+		Name: "connection",
+		Type: string(corev1.SecretTypeOpaque),
+		Data: map[string][]byte{"user": []byte("dora"), "password": []byte("s3cret")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "notebooks", created.ManagedBy)
+	assert.Equal(t, []string{"password", "user"}, created.RedactedKeys)
+	assert.Nil(t, created.Data["password"], "values are redacted")
+
+	// Secrets not created through the repository are listed, but can't be updated
+	unmanaged, err := toUnstructured(&corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{Name: "aaa-token", Namespace: "dora-namespace"},
+		Data:       map[string][]byte{"token": []byte("t")},
+	})
+	require.NoError(t, err)
+	_, err = repo.objects.Create(client, ctx, dora, secretsGVR, "dora-namespace", unmanaged)
+	require.NoError(t, err)
+
+	secrets, err := repo.List(client, ctx, dora, "dora-namespace", false)
+	require.NoError(t, err)
+	require.Len(t, secrets, 2)
+	assert.Equal(t, "aaa-token", secrets[0].Name)
+	assert.Nil(t, secrets[0].Data["token"])
+	managed, err := repo.List(client, ctx, dora, "dora-namespace", true)
+	require.NoError(t, err)
+	require.Len(t, managed, 1)
+	assert.Equal(t, "connection", managed[0].Name)
+
+	_, err = repo.Update(client, ctx, dora, "dora-namespace", models.SecretModel{Name: "aaa-token", Data: map[string][]byte{"token": []byte("u")}}, false)
+	assert.True(t, k8serrors.IsForbidden(err), "got %v", err)
+
+	// Redacted keys keep their value, the others are replaced
+	created.Data = map[string][]byte{"user": []byte("dora2"), "password": nil}
+	created.RedactedKeys = []string{"password"}
+	_, err = repo.Update(client, ctx, dora, "dora-namespace", created, false)
+	require.NoError(t, err)
+
+	revealed, err := repo.Get(client, ctx, dora, "dora-namespace", "connection", true)
+	require.NoError(t, err)
+	assert.Empty(t, revealed.RedactedKeys)
+	assert.Equal(t, map[string][]byte{"user": []byte("dora2"), "password": []byte("s3cret")}, revealed.Data)
+
+	_, err = repo.Get(client, ctx, &k8s.RequestIdentity{UserID: "bellaNonAdmin@example.com"}, "dora-namespace", "connection", true)
+	assert.True(t, k8serrors.IsForbidden(err), "got %v", err)
+}
+
+func TestSecretRepository_RevealVerb(t *testing.T) {
+	client := k8mocks.NewMockKubernetesClient(k8mocks.DefaultMockFixtures(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	dora := &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com"}
+	ctx := context.Background()
+	repo := NewSecretRepository()
+	_, err := repo.Create(client, ctx, dora, "dora-namespace", models.SecretModel{Name: "connection", Data: map[string][]byte{"password": []byte("s3cret")}})
+	require.NoError(t, err)
+
+	var checked []string
+	repo.UseRedactionPolicy(RedactionPolicy{RevealVerb: "reveal"})
+	recording := &recordingClient{MockKubernetesClient: client, checked: &checked}
+	_, err = repo.Get(recording, ctx, dora, "dora-namespace", "connection", true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"reveal secrets", "get secrets"}, checked)
+
+	// Redacted reads only need get
+	checked = nil
+	_, err = repo.Get(recording, ctx, dora, "dora-namespace", "connection", false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"get secrets"}, checked)
+}
+
+// recordingClient records the access reviews of a MockKubernetesClient.
+type recordingClient struct {
+	*k8mocks.MockKubernetesClient
+	checked *[]string
+}
+
+func (c *recordingClient) CanAccess(ctx context.Context, identity *k8s.RequestIdentity, verb, group, resource, namespace string) (bool, error) {
+	*c.checked = append(*c.checked, verb+" "+resource)
+	return c.MockKubernetesClient.CanAccess(ctx, identity, verb, group, resource, namespace)
+}
+
+func TestConfigMapRepository(t *testing.T) {
+	client := k8mocks.NewMockKubernetesClient(k8mocks.DefaultMockFixtures(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	dora := &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com"}
+	ctx := context.Background()
+	repo := NewConfigMapRepository()
+	repo.UseRedactionPolicy(RedactionPolicy{ConfigMapKeys: []string{"*password*"}})
+
+	created, err := repo.Create(client, ctx, dora, "dora-namespace", models.ConfigMapModel{
+		Name:   "settings",
+		Labels: map[string]string{"app": "notebooks"},
+		Data:   map[string]string{"url": "https://db", "db_password": "s3cret"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultManager, created.ManagedBy)
+	assert.Equal(t, []string{"db_password"}, created.RedactedKeys)
+	assert.Equal(t, map[string]string{"url": "https://db", "db_password": ""}, created.Data)
+
+	created.Data["url"] = "https://db2"
+	created.Labels = nil
+	updated, err := repo.Update(client, ctx, dora, "dora-namespace", created, false)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "notebooks"}, updated.Labels, "labels are kept when not set")
+
+	revealed, err := repo.Get(client, ctx, dora, "dora-namespace", "settings", true)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"url": "https://db2", "db_password": "s3cret"}, revealed.Data)
+
+	_, err = repo.List(client, ctx, &k8s.RequestIdentity{UserID: "bellaNonAdmin@example.com"}, "dora-namespace", false)
+	assert.True(t, k8serrors.IsForbidden(err), "got %v", err)
+}
+
+func TestSecretRepository_Apply(t *testing.T) {
+	client := k8mocks.NewMockKubernetesClient(k8mocks.DefaultMockFixtures(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	dora := &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com"}
+	admin := &k8s.RequestIdentity{UserID: "user@example.com"}
+	ctx := context.Background()
+	repo := NewSecretRepository()
+	repo.UseManager("notebooks")
+
+	created, err := repo.Create(client, ctx, dora, "dora-namespace", models.SecretModel{
+		Name: "connection",
+		Data: map[string][]byte{"user": []byte("dora"), "password": []byte("s3cret"), "old": []byte("x")},
+	})
+	require.NoError(t, err)
+	_, err = repo.Create(client, ctx, dora, "dora-namespace", models.SecretModel{Name: "connection"})
+	assert.True(t, k8serrors.IsAlreadyExists(err), "got %v", err)
+
+	// A controller applies its own key, which updates keep
+	controller := NewDynamicResourceRepository()
+	controller.UseFieldManager("rotator")
+	obj, err := toUnstructured(&corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{Name: "connection", Namespace: "dora-namespace"},
+		Data:       map[string][]byte{"rotated": []byte("yes")},
+	})
+	require.NoError(t, err)
+	_, err = controller.Apply(client, ctx, admin, secretsGVR, "dora-namespace", obj, ApplyOptions{})
+	require.NoError(t, err)
+
+	created.Data = map[string][]byte{"user": []byte("dora2"), "password": nil}
+	created.RedactedKeys = []string{"password"}
+	_, err = repo.Update(client, ctx, dora, "dora-namespace", created, false)
+	assert.True(t, k8serrors.IsConflict(err), "the controller changed the version read")
+	created.ResourceVersion = ""
+	_, err = repo.Update(client, ctx, dora, "dora-namespace", created, false)
+	require.NoError(t, err)
+	secret, err := repo.get(client, ctx, dora, "dora-namespace", "connection")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"user": []byte("dora2"), "password": []byte("s3cret"), "rotated": []byte("yes")}, secret.Data,
+		"the keys the user applied and left out are removed, the others kept")
+	assert.Equal(t, "doraNonAdmin@example.com", secret.Annotations[CreatedByAnnotation])
+
+	// Setting a key another user applied is a conflict, unless forced
+	update := models.SecretModel{Name: "connection", Data: map[string][]byte{"user": []byte("admin")}}
+	_, err = repo.Update(client, ctx, admin, "dora-namespace", update, false)
+	require.True(t, k8serrors.IsConflict(err), "got %v", err)
+	assert.Equal(t, []k8s.ApplyConflict{{Manager: "notebooks/doraNonAdmin@example.com", Field: ".data.user"}}, k8s.ApplyConflicts(err))
+
+	_, err = repo.Update(client, ctx, admin, "dora-namespace", update, true)
+	require.NoError(t, err)
+	secret, err = repo.get(client, ctx, dora, "dora-namespace", "connection")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("admin"), secret.Data["user"])
+}
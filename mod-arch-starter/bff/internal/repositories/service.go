@@ -0,0 +1,225 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/cache"
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/parallel"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Annotations read from discovered Services.
+const (
+	ServiceDisplayNameAnnotation     = "displayName"
+	ServiceDescriptionAnnotation     = "description"
+	ServiceExternalAddressAnnotation = "routing.opendatahub.io/external-address-rest"
+)
+
+// ServiceSelector selects Services by labels and, optionally, by annotations.
+type ServiceSelector struct {
+	Labels      labels.Selector
+	Annotations labels.Selector
+}
+
+// ParseServiceSelector parses a label selector and an annotation selector, both in label
+// selector syntax. The label selector is required so discovery never lists every Service.
+func ParseServiceSelector(labelSelector, annotationSelector string) (ServiceSelector, error) {
+	labelSel, err := labels.Parse(labelSelector)
+	if err != nil {
+		return ServiceSelector{}, fmt.Errorf("invalid service label selector %q: %w", labelSelector, err)
+	}
+	if labelSel.Empty() {
+		return ServiceSelector{}, fmt.Errorf("service label selector is required")
+	}
+	annotationSel, err := labels.Parse(annotationSelector)
+	if err != nil {
+		return ServiceSelector{}, fmt.Errorf("invalid service annotation selector %q: %w", annotationSelector, err)
+	}
+	return ServiceSelector{Labels: labelSel, Annotations: annotationSel}, nil
+}
+
+// String returns the selectors in label selector syntax, e.g. for cache keys.
+func (s ServiceSelector) String() string {
+	annotations := ""
+	if s.Annotations != nil {
+		annotations = s.Annotations.String()
+	}
+	return s.Labels.String() + ";" + annotations
+}
+
+func (s ServiceSelector) matches(svc *corev1.Service) bool {
+	return s.Labels.Matches(labels.Set(svc.Labels)) &&
+		(s.Annotations == nil || s.Annotations.Matches(labels.Set(svc.Annotations)))
+}
+
+// ServiceRepository discovers the backend Services of a namespace and reports their health
+// from their EndpointSlices.
+type ServiceRepository struct {
+	cache        *cache.Cache
+	urlResolvers []ServiceURLResolver
+}
+
+func NewServiceRepository() *ServiceRepository {
+	return &ServiceRepository{}
+}
+
+// UseCache serves GetServices from c, per identity, namespace and selector. A nil c disables
+// caching.
+func (r *ServiceRepository) UseCache(c *cache.Cache) {
+	r.cache = c
+}
+
+// GetServices lists the Services in namespace matching selector. The identity must be allowed
+// to list services in the namespace; health is "unknown" unless it may also list endpointslices.
+func (r *ServiceRepository) GetServices(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, namespace string, selector ServiceSelector) ([]models.ServiceModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "ServiceRepository.GetServices")
+	defer span.End()
+	span.SetAttributes(attribute.String("k8s.namespace.name", namespace))
+
+	key := cache.Key{
+		Resource:  "services",
+		Namespace: namespace,
+		Identity:  identity,
+		Params:    []string{selector.String()},
+	}
+	serviceModels, err := cache.Get(ctx, r.cache, key, func(ctx context.Context) ([]models.ServiceModel, error) {
+		return r.listServices(client, ctx, identity, namespace, selector)
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("bff.services.count", len(serviceModels)))
+	return serviceModels, nil
+}
+
+func (r *ServiceRepository) listServices(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, namespace string, selector ServiceSelector) ([]models.ServiceModel, error) {
+	allowed, err := client.CanAccess(ctx, identity, "list", "", "services", namespace)
+	if err != nil {
+		return nil, fmt.Errorf("error checking access to services: %w", err)
+	}
+	if !allowed {
+		return nil, k8serrors.NewForbidden(schema.GroupResource{Resource: "services"}, "", fmt.Errorf("user cannot list services in namespace %s", namespace))
+	}
+
+	var services []corev1.Service
+	var canReadEndpoints bool
+	var urls map[string][]models.ServiceURL
+	err = parallel.Do(ctx, parallel.Options{},
+		func(ctx context.Context) error {
+			var err error
+			if services, err = client.Reader().ListServices(ctx, namespace); err != nil {
+				return fmt.Errorf("error fetching services: %w", err)
+			}
+			return nil
+		},
+		func(ctx context.Context) error {
+			var err error
+			if canReadEndpoints, err = client.CanAccess(ctx, identity, "list", "discovery.k8s.io", "endpointslices", namespace); err != nil {
+				return fmt.Errorf("error checking access to endpointslices: %w", err)
+			}
+			return nil
+		},
+		func(ctx context.Context) error {
+			urls = r.resolveURLs(client, ctx, identity, namespace)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []*corev1.Service
+	for i := range services {
+		if selector.matches(&services[i]) {
+			matched = append(matched, &services[i])
+		}
+	}
+	var serviceModels = make([]models.ServiceModel, len(matched))
+	// The health of each Service is an EndpointSlice listing of its own
+	_ = parallel.ForEach(ctx, parallel.Options{}, len(matched), func(ctx context.Context, i int) {
+		serviceModels[i] = newServiceModel(matched[i])
+		serviceModels[i].ExternalURLs = urls[matched[i].Name]
+		if canReadEndpoints {
+			serviceModels[i].Health = serviceHealth(ctx, client.Reader(), matched[i])
+		}
+	})
+
+	sort.Slice(serviceModels, func(i, j int) bool {
+		return serviceModels[i].Name < serviceModels[j].Name
+	})
+	return serviceModels, nil
+}
+
+func newServiceModel(svc *corev1.Service) models.ServiceModel {
+	model := models.ServiceModel{
+		Name:            svc.Name,
+		Namespace:       svc.Namespace,
+		DisplayName:     svc.Annotations[ServiceDisplayNameAnnotation],
+		Description:     svc.Annotations[ServiceDescriptionAnnotation],
+		ExternalAddress: svc.Annotations[ServiceExternalAddressAnnotation],
+		Ports:           []models.ServicePort{},
+		Health:          models.ServiceHealth{Status: models.ServiceStatusUnknown},
+		Labels:          svc.Labels,
+	}
+	if model.DisplayName == "" {
+		model.DisplayName = svc.Name
+	}
+
+	for i := range svc.Spec.Ports {
+		port := &svc.Spec.Ports[i]
+		protocol := strings.ToLower(string(port.Protocol))
+		if port.AppProtocol != nil && *port.AppProtocol != "" {
+			protocol = *port.AppProtocol
+		}
+		model.Ports = append(model.Ports, models.ServicePort{
+			Name:     port.Name,
+			Port:     port.Port,
+			Protocol: protocol,
+			URL:      k8s.ServicePortURL(svc, port, "").String(),
+		})
+	}
+	return model
+}
+
+// serviceHealth counts the ready endpoints of a Service. ExternalName Services have no
+// endpoints and errors leave the status unknown rather than failing the whole listing.
+func serviceHealth(ctx context.Context, reader k8s.ResourceReader, svc *corev1.Service) models.ServiceHealth {
+	health := models.ServiceHealth{Status: models.ServiceStatusUnknown}
+	if svc.Spec.Type == corev1.ServiceTypeExternalName {
+		return health
+	}
+
+	endpointSlices, err := reader.ListEndpointSlices(ctx, svc.Namespace, svc.Name)
+	if err != nil {
+		logger.FromContext(ctx).Debug("failed to list endpointslices, service health unknown",
+			"namespace", svc.Namespace, "service", svc.Name, "error", err)
+		return health
+	}
+	for _, slice := range endpointSlices {
+		for _, endpoint := range slice.Endpoints {
+			health.TotalEndpoints++
+			// A nil Ready condition means ready (see the EndpointConditions API docs)
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				health.ReadyEndpoints++
+			}
+		}
+	}
+
+	health.Status = models.ServiceStatusUnavailable
+	if health.ReadyEndpoints > 0 {
+		health.Status = models.ServiceStatusAvailable
+	}
+	return health
+}
@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var serviceAccountsGVR = schema.GroupVersionResource{Version: "v1", Resource: "serviceaccounts"}
+
+// ServiceAccountTokenRepository mints short-lived ServiceAccount tokens through the
+// TokenRequest API, on behalf of the users allowed to create serviceaccounts/token in the
+// namespace, e.g. for external tools connecting to a model registry. The tokens are bound to
+// no object, so they stay valid until they expire or the ServiceAccount is deleted.
+type ServiceAccountTokenRepository struct{}
+
+func NewServiceAccountTokenRepository() *ServiceAccountTokenRepository {
+	return &ServiceAccountTokenRepository{}
+}
+
+// Mint requests a token of the ServiceAccount name in namespace, scoped to audiences (the
+// audience of the API server when empty) and valid for ttl. The identity must be allowed to
+// create serviceaccounts/token in the namespace. The issuance is logged, the token is not.
+func (r *ServiceAccountTokenRepository) Mint(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, namespace, name string, audiences []string, ttl time.Duration) (models.ServiceAccountTokenModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "ServiceAccountTokenRepository.Mint",
+		attribute.String("k8s.namespace.name", namespace),
+		attribute.String("k8s.serviceaccount.name", name),
+	)
+	defer span.End()
+
+	allowed, err := client.CanAccess(ctx, identity, "create", "", "serviceaccounts/token", namespace)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return models.ServiceAccountTokenModel{}, err
+	}
+	if !allowed {
+		return models.ServiceAccountTokenModel{}, k8serrors.NewForbidden(serviceAccountsGVR.GroupResource(), name, fmt.Errorf("user cannot create tokens of service accounts in namespace %s", namespace))
+	}
+
+	expirationSeconds := int64(ttl / time.Second)
+	request, err := toUnstructured(&authenticationv1.TokenRequest{
+		TypeMeta: metav1.TypeMeta{APIVersion: authenticationv1.SchemeGroupVersion.String(), Kind: "TokenRequest"},
+		// The dynamic client posts to the subresource of the object named here
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: authenticationv1.TokenRequestSpec{
+			Audiences:         audiences,
+			ExpirationSeconds: &expirationSeconds,
+		},
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return models.ServiceAccountTokenModel{}, err
+	}
+	serviceAccounts, err := client.DynamicResource(serviceAccountsGVR)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return models.ServiceAccountTokenModel{}, err
+	}
+	created, err := serviceAccounts.Namespace(namespace).Create(ctx, request, metav1.CreateOptions{}, "token")
+	if err != nil {
+		tracing.RecordError(span, err)
+		return models.ServiceAccountTokenModel{}, err
+	}
+	response, err := fromUnstructured[authenticationv1.TokenRequest](created)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return models.ServiceAccountTokenModel{}, err
+	}
+	if response.Status.Token == "" {
+		err := fmt.Errorf("no token issued for service account %s/%s", namespace, name)
+		tracing.RecordError(span, err)
+		return models.ServiceAccountTokenModel{}, err
+	}
+
+	// The API server may shorten the validity, or fill in the audiences it defaults to
+	if len(response.Spec.Audiences) > 0 {
+		audiences = response.Spec.Audiences
+	}
+	logger.FromContext(ctx).Info("minted service account token",
+		"user", identity.UserID, "namespace", namespace, "serviceAccount", name,
+		"audiences", strings.Join(audiences, ","), "expiration", response.Status.ExpirationTimestamp.Time)
+	return models.ServiceAccountTokenModel{
+		ServiceAccount:      name,
+		Namespace:           namespace,
+		Token:               response.Status.Token,
+		Audiences:           audiences,
+		ExpirationTimestamp: response.Status.ExpirationTimestamp.UTC(),
+	}, nil
+}
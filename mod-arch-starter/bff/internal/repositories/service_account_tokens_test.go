@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes/k8mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func TestServiceAccountTokenRepository_Mint(t *testing.T) {
+	client := k8mocks.NewMockKubernetesClient(k8mocks.DefaultMockFixtures(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	seed(t, client, serviceAccountsGVR, "dora-namespace", map[string]any{
+		"apiVersion": "v1", "kind": "ServiceAccount",
+		"metadata": map[string]any{"name": "registry-client", "namespace": "dora-namespace"},
+	})
+	repo := NewServiceAccountTokenRepository()
+	dora := &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com"}
+	ctx := context.Background()
+
+	before := time.Now()
+	token, err := repo.Mint(client, ctx, dora, "dora-namespace", "registry-client", []string{"model-registry"}, 20*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "registry-client", token.ServiceAccount)
+	assert.Equal(t, "dora-namespace", token.Namespace)
+	assert.NotEmpty(t, token.Token)
+	assert.Equal(t, []string{"model-registry"}, token.Audiences)
+	assert.WithinDuration(t, before.Add(20*time.Minute), token.ExpirationTimestamp, 5*time.Second)
+
+	_, err = repo.Mint(client, ctx, dora, "dora-namespace", "missing", nil, 20*time.Minute)
+	assert.True(t, k8serrors.IsNotFound(err), err)
+	_, err = repo.Mint(client, ctx, dora, "bella-namespace", "default", nil, 20*time.Minute)
+	assert.True(t, k8serrors.IsForbidden(err), err)
+}
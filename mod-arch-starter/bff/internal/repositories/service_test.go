@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes/k8mocks"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func serviceNames(services []models.ServiceModel) []string {
+	names := make([]string, 0, len(services))
+	for _, svc := range services {
+		names = append(names, svc.Name)
+	}
+	return names
+}
+
+func newServiceTestClient() k8s.KubernetesClientInterface {
+	fixtures := k8mocks.DefaultMockFixtures()
+	fixtures.Services = append(fixtures.Services,
+		k8mocks.MockService{Name: "mod-arch-down", Namespace: "kubeflow", Labels: map[string]string{"component": "mod-arch"}, Unavailable: true},
+	)
+	return k8mocks.NewMockKubernetesClient(fixtures, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestServiceRepository_GetServices(t *testing.T) {
+	ctx := context.Background()
+	client := newServiceTestClient()
+	repo := NewServiceRepository()
+	admin := &k8s.RequestIdentity{UserID: "user@example.com"}
+
+	t.Run("filters by label selector", func(t *testing.T) {
+		selector, err := ParseServiceSelector("component=mod-arch", "")
+		require.NoError(t, err)
+
+		services, err := repo.GetServices(client, ctx, admin, "kubeflow", selector)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"mod-arch", "mod-arch-down", "mod-arch-one"}, serviceNames(services))
+
+		svc := services[0]
+		assert.Equal(t, "Mod Arch", svc.DisplayName)
+		assert.Equal(t, "Mod Arch Description", svc.Description)
+		assert.Equal(t, []models.ServicePort{
+			{Name: "http-api", Port: 8080, Protocol: "http", URL: "http://mod-arch.kubeflow.svc.cluster.local:8080"},
+			{Name: "grpc-api", Port: 9090, Protocol: "grpc", URL: "http://mod-arch.kubeflow.svc.cluster.local:9090"},
+		}, svc.Ports)
+		assert.Equal(t, models.ServiceHealth{Status: models.ServiceStatusAvailable, ReadyEndpoints: 1, TotalEndpoints: 1}, svc.Health)
+
+		down := services[1]
+		assert.Equal(t, "mod-arch-down", down.DisplayName, "display name falls back to the name")
+		assert.Equal(t, models.ServiceHealth{Status: models.ServiceStatusUnavailable, TotalEndpoints: 1}, down.Health)
+	})
+
+	t.Run("filters by annotation selector", func(t *testing.T) {
+		selector, err := ParseServiceSelector("component=mod-arch", "displayName")
+		require.NoError(t, err)
+
+		services, err := repo.GetServices(client, ctx, admin, "kubeflow", selector)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"mod-arch", "mod-arch-one"}, serviceNames(services))
+	})
+
+	t.Run("namespace restricted user", func(t *testing.T) {
+		selector, err := ParseServiceSelector("component=mod-arch", "")
+		require.NoError(t, err)
+		dora := &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com"}
+
+		services, err := repo.GetServices(client, ctx, dora, "dora-namespace", selector)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"mod-arch-dora"}, serviceNames(services))
+
+		_, err = repo.GetServices(client, ctx, dora, "kubeflow", selector)
+		assert.True(t, k8serrors.IsForbidden(err), "got %v", err)
+	})
+}
+
+func TestParseServiceSelector(t *testing.T) {
+	_, err := ParseServiceSelector("", "")
+	assert.Error(t, err, "empty label selector")
+
+	_, err = ParseServiceSelector("component in (", "")
+	assert.Error(t, err, "invalid label selector")
+
+	_, err = ParseServiceSelector("component=mod-arch", "a b")
+	assert.Error(t, err, "invalid annotation selector")
+
+	selector, err := ParseServiceSelector("component=mod-arch", "")
+	require.NoError(t, err)
+	assert.True(t, selector.Annotations.Empty())
+}
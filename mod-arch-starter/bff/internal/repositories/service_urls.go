@@ -0,0 +1,478 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/logger"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Kinds of the objects external Service URLs are resolved from, the Source of a models.ServiceURL.
+const (
+	ServiceURLSourceRoute     = "Route"
+	ServiceURLSourceIngress   = "Ingress"
+	ServiceURLSourceHTTPRoute = "HTTPRoute"
+)
+
+var (
+	routesGVR     = schema.GroupVersionResource{Group: "route.openshift.io", Version: "v1", Resource: "routes"}
+	ingressesGVR  = schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}
+	httpRoutesGVR = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes"}
+	gatewaysGVR   = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "gateways"}
+)
+
+// ServiceURLResolver resolves the external URLs of the Services of namespace from one kind of
+// routing object, by Service name. Resolvers skip, rather than fail on, routing APIs the cluster
+// doesn't serve or the identity may not list, so discovery works on any cluster.
+type ServiceURLResolver func(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, namespace string) (map[string][]models.ServiceURL, error)
+
+// UseURLResolvers sets the resolvers of the external URLs of the listed Services, most
+// preferred first, none by default. The URLs of each Service are ordered by resolver, then
+// with TLS first, then by object name.
+func (r *ServiceRepository) UseURLResolvers(resolvers ...ServiceURLResolver) {
+	r.urlResolvers = resolvers
+}
+
+// resolveURLs runs the URL resolvers of r in order and merges their URLs, by Service name.
+// Errors leave out the URLs of a resolver rather than failing the whole listing.
+func (r *ServiceRepository) resolveURLs(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, namespace string) map[string][]models.ServiceURL {
+	urls := map[string][]models.ServiceURL{}
+	for _, resolve := range r.urlResolvers {
+		resolved, err := resolve(client, ctx, identity, namespace)
+		if err != nil {
+			logger.FromContext(ctx).Debug("failed to resolve service urls", "namespace", namespace, "error", err)
+			continue
+		}
+		for name, serviceURLs := range resolved {
+			sortServiceURLs(serviceURLs)
+			for _, serviceURL := range serviceURLs {
+				if !containsURL(urls[name], serviceURL.URL) {
+					urls[name] = append(urls[name], serviceURL)
+				}
+			}
+		}
+	}
+	return urls
+}
+
+func sortServiceURLs(urls []models.ServiceURL) {
+	sort.SliceStable(urls, func(i, j int) bool {
+		if urls[i].TLS != urls[j].TLS {
+			return urls[i].TLS
+		}
+		if urls[i].Name != urls[j].Name {
+			return urls[i].Name < urls[j].Name
+		}
+		return urls[i].URL < urls[j].URL
+	})
+}
+
+func containsURL(urls []models.ServiceURL, u string) bool {
+	for _, serviceURL := range urls {
+		if serviceURL.URL == u {
+			return true
+		}
+	}
+	return false
+}
+
+// listRouting lists the objects of gvr in namespace, converted into list, or returns false when
+// the identity may not list them or the cluster doesn't serve gvr.
+func listRouting[T any](client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, gvr schema.GroupVersionResource, namespace string, list *[]T) (bool, error) {
+	allowed, err := client.CanAccess(ctx, identity, "list", gvr.Group, gvr.Resource, namespace)
+	if err != nil {
+		return false, fmt.Errorf("error checking access to %s: %w", gvr.Resource, err)
+	}
+	if !allowed {
+		return false, nil
+	}
+	resource, err := client.DynamicResource(gvr)
+	if err != nil {
+		return false, err
+	}
+	objects, err := resource.Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if k8serrors.IsNotFound(err) || isNoMatch(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error listing %s: %w", gvr.Resource, err)
+	}
+	*list = make([]T, 0, len(objects.Items))
+	for i := range objects.Items {
+		var obj T
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(objects.Items[i].Object, &obj); err != nil {
+			// One malformed object doesn't hide the URLs of the others
+			logger.FromContext(ctx).Debug("skipping unreadable routing object", "resource", gvr.Resource,
+				"namespace", namespace, "name", objects.Items[i].GetName(), "error", err)
+			continue
+		}
+		*list = append(*list, obj)
+	}
+	return true, nil
+}
+
+// isNoMatch reports whether err means the API group of a resource is not served, as returned
+// by clients with a REST mapper.
+func isNoMatch(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "no matches for kind")
+}
+
+// serviceURL builds the URL of host and path. Ports are left out when they are the default
+// port of the scheme.
+func serviceURL(tls bool, host string, port int32, path string) string {
+	u := url.URL{Scheme: "http", Host: host, Path: path}
+	if tls {
+		u.Scheme = "https"
+	}
+	if port != 0 && !(tls && port == 443) && !(!tls && port == 80) {
+		u.Host = net.JoinHostPort(host, strconv.Itoa(int(port)))
+	}
+	if u.Path == "/" {
+		u.Path = ""
+	}
+	return u.String()
+}
+
+// usableHost reports whether host can be linked to: set and not a wildcard.
+func usableHost(host string) bool {
+	return host != "" && !strings.HasPrefix(host, "*")
+}
+
+func portString(port intstr.IntOrString) string {
+	if port.Type == intstr.String {
+		return port.StrVal
+	}
+	if port.IntVal == 0 {
+		return ""
+	}
+	return strconv.Itoa(int(port.IntVal))
+}
+
+// route is the part of an OpenShift Route the URLs are resolved from.
+type route struct {
+	Metadata metav1.ObjectMeta `json:"metadata"`
+	Spec     struct {
+		Host              string        `json:"host"`
+		Path              string        `json:"path"`
+		To                routeTarget   `json:"to"`
+		AlternateBackends []routeTarget `json:"alternateBackends"`
+		Port              *struct {
+			TargetPort intstr.IntOrString `json:"targetPort"`
+		} `json:"port"`
+		TLS *struct{} `json:"tls"`
+	} `json:"spec"`
+	Status struct {
+		Ingress []struct {
+			Host       string `json:"host"`
+			Conditions []struct {
+				Type   string `json:"type"`
+				Status string `json:"status"`
+			} `json:"conditions"`
+		} `json:"ingress"`
+	} `json:"status"`
+}
+
+type routeTarget struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// admittedHost returns the host a router admitted the Route with, spec.host otherwise: the
+// host is generated by the router when spec.host is empty.
+func (rt *route) admittedHost() string {
+	for _, ingress := range rt.Status.Ingress {
+		for _, condition := range ingress.Conditions {
+			if condition.Type == "Admitted" && condition.Status == string(metav1.ConditionTrue) && ingress.Host != "" {
+				return ingress.Host
+			}
+		}
+	}
+	return rt.Spec.Host
+}
+
+// RouteURLs resolves Service URLs from the OpenShift Routes of the namespace, on their admitted
+// host; Routes with TLS are https.
+func RouteURLs(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, namespace string) (map[string][]models.ServiceURL, error) {
+	var routes []route
+	if ok, err := listRouting(client, ctx, identity, routesGVR, namespace, &routes); !ok {
+		return nil, err
+	}
+
+	urls := map[string][]models.ServiceURL{}
+	for i := range routes {
+		rt := &routes[i]
+		host := rt.admittedHost()
+		if !usableHost(host) {
+			continue
+		}
+		serviceURL := models.ServiceURL{
+			URL:    serviceURL(rt.Spec.TLS != nil, host, 0, rt.Spec.Path),
+			Source: ServiceURLSourceRoute,
+			Name:   rt.Metadata.Name,
+			TLS:    rt.Spec.TLS != nil,
+		}
+		if rt.Spec.Port != nil {
+			serviceURL.Port = portString(rt.Spec.Port.TargetPort)
+		}
+		for _, target := range append([]routeTarget{rt.Spec.To}, rt.Spec.AlternateBackends...) {
+			if (target.Kind == "" || target.Kind == "Service") && target.Name != "" && !containsURL(urls[target.Name], serviceURL.URL) {
+				urls[target.Name] = append(urls[target.Name], serviceURL)
+			}
+		}
+	}
+	return urls, nil
+}
+
+// IngressURLs resolves Service URLs from the Ingresses of the namespace. Hosts listed in the
+// TLS section of an Ingress are https; rules without a host use the load balancer address.
+func IngressURLs(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, namespace string) (map[string][]models.ServiceURL, error) {
+	var ingresses []networkingv1.Ingress
+	if ok, err := listRouting(client, ctx, identity, ingressesGVR, namespace, &ingresses); !ok {
+		return nil, err
+	}
+
+	urls := map[string][]models.ServiceURL{}
+	for i := range ingresses {
+		ingress := &ingresses[i]
+		tls := func(host string) bool {
+			for _, entry := range ingress.Spec.TLS {
+				// A TLS entry without hosts covers the default host of the load balancer
+				if len(entry.Hosts) == 0 || containsString(entry.Hosts, host) {
+					return true
+				}
+			}
+			return false
+		}
+		add := func(host, path string, backend *networkingv1.IngressServiceBackend) {
+			if backend == nil || backend.Name == "" {
+				return
+			}
+			if host == "" {
+				host = loadBalancerHost(ingress)
+			}
+			if !usableHost(host) {
+				return
+			}
+			serviceURL := models.ServiceURL{
+				URL: serviceURL(tls(host), host, 0, path), Source: ServiceURLSourceIngress, Name: ingress.Name, TLS: tls(host),
+				Port: backend.Port.Name,
+			}
+			if serviceURL.Port == "" && backend.Port.Number != 0 {
+				serviceURL.Port = strconv.Itoa(int(backend.Port.Number))
+			}
+			if !containsURL(urls[backend.Name], serviceURL.URL) {
+				urls[backend.Name] = append(urls[backend.Name], serviceURL)
+			}
+		}
+
+		for _, rule := range ingress.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				add(rule.Host, staticPath(path.Path), path.Backend.Service)
+			}
+		}
+		if ingress.Spec.DefaultBackend != nil {
+			add("", "", ingress.Spec.DefaultBackend.Service)
+		}
+	}
+	return urls, nil
+}
+
+func loadBalancerHost(ingress *networkingv1.Ingress) string {
+	for _, lb := range ingress.Status.LoadBalancer.Ingress {
+		if lb.Hostname != "" {
+			return lb.Hostname
+		}
+		if lb.IP != "" {
+			return lb.IP
+		}
+	}
+	return ""
+}
+
+// staticPath returns path when it can be linked to, "" for paths with regular expressions
+// (used by some ingress controllers with ImplementationSpecific paths).
+func staticPath(path string) string {
+	if strings.ContainsAny(path, "()[]*+?^$|\\") {
+		return ""
+	}
+	return path
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// httpRoute is the part of a Gateway API HTTPRoute the URLs are resolved from.
+type httpRoute struct {
+	Metadata metav1.ObjectMeta `json:"metadata"`
+	Spec     struct {
+		Hostnames  []string `json:"hostnames"`
+		ParentRefs []struct {
+			Group       string `json:"group"`
+			Kind        string `json:"kind"`
+			Namespace   string `json:"namespace"`
+			Name        string `json:"name"`
+			SectionName string `json:"sectionName"`
+		} `json:"parentRefs"`
+		Rules []struct {
+			Matches []struct {
+				Path *struct {
+					Type  string `json:"type"`
+					Value string `json:"value"`
+				} `json:"path"`
+			} `json:"matches"`
+			BackendRefs []struct {
+				Group     string `json:"group"`
+				Kind      string `json:"kind"`
+				Namespace string `json:"namespace"`
+				Name      string `json:"name"`
+				Port      int32  `json:"port"`
+			} `json:"backendRefs"`
+		} `json:"rules"`
+	} `json:"spec"`
+}
+
+// gateway is the part of a Gateway API Gateway the URLs are resolved from.
+type gateway struct {
+	Spec struct {
+		Listeners []gatewayListener `json:"listeners"`
+	} `json:"spec"`
+}
+
+type gatewayListener struct {
+	Name     string `json:"name"`
+	Hostname string `json:"hostname"`
+	Port     int32  `json:"port"`
+	Protocol string `json:"protocol"`
+}
+
+// HTTPRouteURLs resolves Service URLs from the Gateway API HTTPRoutes of the namespace. The
+// scheme and port come from the listeners of the parent Gateways (HTTPS listeners are TLS);
+// HTTPRoutes without hostnames use the hostnames of those listeners.
+func HTTPRouteURLs(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, namespace string) (map[string][]models.ServiceURL, error) {
+	var routes []httpRoute
+	if ok, err := listRouting(client, ctx, identity, httpRoutesGVR, namespace, &routes); !ok {
+		return nil, err
+	}
+
+	gateways := map[string]*gateway{}
+	getGateway := func(namespace, name string) *gateway {
+		key := namespace + "/" + name
+		if gw, ok := gateways[key]; ok {
+			return gw
+		}
+		gw := readGateway(client, ctx, identity, namespace, name)
+		gateways[key] = gw
+		return gw
+	}
+
+	urls := map[string][]models.ServiceURL{}
+	for i := range routes {
+		rt := &routes[i]
+
+		// The listeners the route is attached to
+		var listeners []gatewayListener
+		for _, parent := range rt.Spec.ParentRefs {
+			if (parent.Group != "" && parent.Group != gatewaysGVR.Group) || (parent.Kind != "" && parent.Kind != "Gateway") {
+				continue
+			}
+			parentNamespace := parent.Namespace
+			if parentNamespace == "" {
+				parentNamespace = rt.Metadata.Namespace
+			}
+			gw := getGateway(parentNamespace, parent.Name)
+			if gw == nil {
+				// Unknown listeners: assume a plain HTTP listener on the default port
+				listeners = append(listeners, gatewayListener{Protocol: "HTTP"})
+				continue
+			}
+			for _, listener := range gw.Spec.Listeners {
+				if (parent.SectionName == "" || parent.SectionName == listener.Name) && (listener.Protocol == "HTTP" || listener.Protocol == "HTTPS") {
+					listeners = append(listeners, listener)
+				}
+			}
+		}
+
+		for _, rule := range rt.Spec.Rules {
+			path := ""
+			for _, match := range rule.Matches {
+				if match.Path != nil && (match.Path.Type == "" || match.Path.Type == "PathPrefix" || match.Path.Type == "Exact") {
+					path = match.Path.Value
+					break
+				}
+			}
+			for _, backend := range rule.BackendRefs {
+				if (backend.Group != "" || (backend.Kind != "" && backend.Kind != "Service")) ||
+					(backend.Namespace != "" && backend.Namespace != rt.Metadata.Namespace) || backend.Name == "" {
+					continue
+				}
+				for _, listener := range listeners {
+					hosts := rt.Spec.Hostnames
+					if len(hosts) == 0 {
+						hosts = []string{listener.Hostname}
+					}
+					for _, host := range hosts {
+						if !usableHost(host) {
+							continue
+						}
+						tls := listener.Protocol == "HTTPS"
+						serviceURL := models.ServiceURL{
+							URL: serviceURL(tls, host, listener.Port, path), Source: ServiceURLSourceHTTPRoute, Name: rt.Metadata.Name, TLS: tls,
+						}
+						if backend.Port != 0 {
+							serviceURL.Port = strconv.Itoa(int(backend.Port))
+						}
+						if !containsURL(urls[backend.Name], serviceURL.URL) {
+							urls[backend.Name] = append(urls[backend.Name], serviceURL)
+						}
+					}
+				}
+			}
+		}
+	}
+	return urls, nil
+}
+
+// readGateway returns the Gateway namespace/name, or nil when the identity may not get it or
+// it can't be read.
+func readGateway(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, namespace, name string) *gateway {
+	allowed, err := client.CanAccess(ctx, identity, "get", gatewaysGVR.Group, gatewaysGVR.Resource, namespace)
+	if err != nil || !allowed {
+		return nil
+	}
+	resource, err := client.DynamicResource(gatewaysGVR)
+	if err != nil {
+		return nil
+	}
+	obj, err := resource.Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		logger.FromContext(ctx).Debug("failed to get gateway, assuming an HTTP listener", "namespace", namespace, "gateway", name, "error", err)
+		return nil
+	}
+	var gw gateway
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &gw); err != nil {
+		return nil
+	}
+	return &gw
+}
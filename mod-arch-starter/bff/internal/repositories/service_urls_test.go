@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seedRoutingObjects(t *testing.T, client k8s.KubernetesClientInterface) {
+	seed(t, client, routesGVR, "kubeflow", map[string]any{
+		"apiVersion": "route.openshift.io/v1", "kind": "Route",
+		"metadata": map[string]any{"name": "mod-arch", "namespace": "kubeflow"},
+		"spec": map[string]any{
+			"to":   map[string]any{"kind": "Service", "name": "mod-arch"},
+			"port": map[string]any{"targetPort": "http-api"},
+			"tls":  map[string]any{"termination": "edge"},
+		},
+		"status": map[string]any{"ingress": []any{map[string]any{
+			"host":       "mod-arch-kubeflow.apps.example.com",
+			"conditions": []any{map[string]any{"type": "Admitted", "status": "True"}},
+		}}},
+	})
+	seed(t, client, ingressesGVR, "kubeflow", map[string]any{
+		"apiVersion": "networking.k8s.io/v1", "kind": "Ingress",
+		"metadata": map[string]any{"name": "mod-arch", "namespace": "kubeflow"},
+		"spec": map[string]any{
+			"tls": []any{map[string]any{"hosts": []any{"secure.example.com"}}},
+			"rules": []any{
+				map[string]any{"host": "plain.example.com", "http": map[string]any{"paths": []any{
+					map[string]any{"path": "/api", "pathType": "Prefix", "backend": map[string]any{"service": map[string]any{"name": "mod-arch", "port": map[string]any{"number": int64(8080)}}}},
+				}}},
+				map[string]any{"host": "secure.example.com", "http": map[string]any{"paths": []any{
+					map[string]any{"path": "/", "pathType": "Prefix", "backend": map[string]any{"service": map[string]any{"name": "mod-arch", "port": map[string]any{"name": "http-api"}}}},
+				}}},
+				map[string]any{"host": "*.example.com", "http": map[string]any{"paths": []any{
+					map[string]any{"path": "/", "pathType": "Prefix", "backend": map[string]any{"service": map[string]any{"name": "mod-arch-one", "port": map[string]any{"number": int64(8080)}}}},
+				}}},
+			},
+		},
+	})
+	seed(t, client, gatewaysGVR, "gateways", map[string]any{
+		"apiVersion": "gateway.networking.k8s.io/v1", "kind": "Gateway",
+		"metadata": map[string]any{"name": "public", "namespace": "gateways"},
+		"spec": map[string]any{"listeners": []any{
+			map[string]any{"name": "http", "port": int64(80), "protocol": "HTTP", "hostname": "gw.example.com"},
+			map[string]any{"name": "https", "port": int64(8443), "protocol": "HTTPS", "hostname": "gw.example.com"},
+		}},
+	})
+	seed(t, client, httpRoutesGVR, "kubeflow", map[string]any{
+		"apiVersion": "gateway.networking.k8s.io/v1", "kind": "HTTPRoute",
+		"metadata": map[string]any{"name": "mod-arch-one", "namespace": "kubeflow"},
+		"spec": map[string]any{
+			"parentRefs": []any{map[string]any{"name": "public", "namespace": "gateways", "sectionName": "https"}},
+			"rules": []any{map[string]any{
+				"matches":     []any{map[string]any{"path": map[string]any{"type": "PathPrefix", "value": "/one"}}},
+				"backendRefs": []any{map[string]any{"name": "mod-arch-one", "port": int64(8080)}},
+			}},
+		},
+	})
+}
+
+func TestServiceRepository_ExternalURLs(t *testing.T) {
+	ctx := context.Background()
+	client := newServiceTestClient()
+	seedRoutingObjects(t, client)
+	admin := &k8s.RequestIdentity{UserID: "user@example.com"}
+	selector, err := ParseServiceSelector("component=mod-arch", "")
+	require.NoError(t, err)
+
+	repo := NewServiceRepository()
+	repo.UseURLResolvers(RouteURLs, IngressURLs, HTTPRouteURLs)
+	services, err := repo.GetServices(client, ctx, admin, "kubeflow", selector)
+	require.NoError(t, err)
+	require.Equal(t, []string{"mod-arch", "mod-arch-down", "mod-arch-one"}, serviceNames(services))
+
+	assert.Equal(t, []models.ServiceURL{
+		{URL: "https://mod-arch-kubeflow.apps.example.com", Source: ServiceURLSourceRoute, Name: "mod-arch", TLS: true, Port: "http-api"},
+		{URL: "https://secure.example.com", Source: ServiceURLSourceIngress, Name: "mod-arch", TLS: true, Port: "http-api"},
+		{URL: "http://plain.example.com/api", Source: ServiceURLSourceIngress, Name: "mod-arch", Port: "8080"},
+	}, services[0].ExternalURLs)
+	assert.Empty(t, services[1].ExternalURLs)
+	assert.Equal(t, []models.ServiceURL{
+		{URL: "https://gw.example.com:8443/one", Source: ServiceURLSourceHTTPRoute, Name: "mod-arch-one", TLS: true, Port: "8080"},
+	}, services[2].ExternalURLs, "wildcard hosts are left out")
+
+	t.Run("precedence follows the resolvers", func(t *testing.T) {
+		repo := NewServiceRepository()
+		repo.UseURLResolvers(IngressURLs, RouteURLs)
+		services, err := repo.GetServices(client, ctx, admin, "kubeflow", selector)
+		require.NoError(t, err)
+		require.Len(t, services[0].ExternalURLs, 3)
+		assert.Equal(t, ServiceURLSourceIngress, services[0].ExternalURLs[0].Source)
+		assert.Equal(t, ServiceURLSourceRoute, services[0].ExternalURLs[2].Source)
+		assert.Empty(t, services[2].ExternalURLs)
+	})
+
+	t.Run("routing objects the user may not list are skipped", func(t *testing.T) {
+		dora := &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com"}
+		urls, err := RouteURLs(client, ctx, dora, "kubeflow")
+		require.NoError(t, err)
+		assert.Empty(t, urls)
+	})
+}
+
+func TestRouteURLs_Host(t *testing.T) {
+	client := newServiceTestClient()
+	seed(t, client, routesGVR, "kubeflow", map[string]any{
+		"apiVersion": "route.openshift.io/v1", "kind": "Route",
+		"metadata": map[string]any{"name": "pending", "namespace": "kubeflow"},
+		"spec": map[string]any{
+			"host":              "mod-arch.example.com",
+			"path":              "/ui",
+			"to":                map[string]any{"kind": "Service", "name": "mod-arch"},
+			"alternateBackends": []any{map[string]any{"kind": "Service", "name": "mod-arch-one"}},
+		},
+	})
+
+	urls, err := RouteURLs(client, context.Background(), &k8s.RequestIdentity{UserID: "user@example.com"}, "kubeflow")
+	require.NoError(t, err)
+	want := []models.ServiceURL{{URL: "http://mod-arch.example.com/ui", Source: ServiceURLSourceRoute, Name: "pending"}}
+	assert.Equal(t, map[string][]models.ServiceURL{"mod-arch": want, "mod-arch-one": want}, urls)
+}
+
+func TestServiceURL(t *testing.T) {
+	assert.Equal(t, "https://example.com", serviceURL(true, "example.com", 443, "/"))
+	assert.Equal(t, "http://example.com:8080/api", serviceURL(false, "example.com", 8080, "/api"))
+	assert.Equal(t, "http://[fd00::1]:8080", serviceURL(false, "fd00::1", 8080, ""))
+	assert.Equal(t, "", staticPath("/api/(.*)"))
+}
@@ -1,31 +1,144 @@
 package repositories
 
 import (
+	"context"
 	"fmt"
+	"slices"
 
 	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
 	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/parallel"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-type UserRepository struct{}
+// NamespaceRolesWorkers bounds the rules reviews of GetNamespaceRoles run in parallel.
+const NamespaceRolesWorkers = 10
+
+type UserRepository struct {
+	adminGroups []string
+	identities  *IdentityCache
+}
 
 func NewUserRepository() *UserRepository {
 	return &UserRepository{}
 }
 
-func (r *UserRepository) GetUser(client k8s.KubernetesClientInterface, identity *k8s.RequestIdentity) (*models.User, error) {
-	isAdmin, err := client.IsClusterAdmin(identity)
+// UseAdminGroups makes members of any of groups cluster admins, without the cluster-admin
+// access review.
+func (r *UserRepository) UseAdminGroups(groups []string) {
+	r.adminGroups = slices.Clone(groups)
+}
+
+// UseIdentityCache memoizes GetUser in cache.
+func (r *UserRepository) UseIdentityCache(cache *IdentityCache) {
+	r.identities = cache
+}
+
+// GetUser resolves the user ID, groups and cluster-admin status of identity with parallel
+// calls, shared with the concurrent calls for the same identity and cached by the identity
+// cache. A failed cluster-admin review is ignored for members of an admin group. The guest
+// identity is returned as is, without calls.
+func (r *UserRepository) GetUser(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity) (*models.User, error) {
+	ctx, span := tracing.StartSpan(ctx, "UserRepository.GetUser")
+	defer span.End()
+
+	if identity != nil && identity.Guest {
+		return &models.User{UserID: identity.UserID, Groups: append([]string{}, identity.Groups...), Guest: true}, nil
+	}
+	user, err := lookupIdentity(ctx, r.identities, LookupUser, identity, func(ctx context.Context) (models.User, error) {
+		return r.lookupUser(client, ctx, identity)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to check admin status: %w", err)
+		tracing.RecordError(span, err)
+		return nil, err
 	}
+	// Callers get their own copy of the cached user
+	user.Groups = slices.Clone(user.Groups)
+	return &user, nil
+}
+
+func (r *UserRepository) lookupUser(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity) (models.User, error) {
 
-	userID, err := client.GetUser(identity)
+	var userID string
+	var groups []string
+	var clusterAdmin bool
+	var clusterAdminErr error
+	err := parallel.Do(ctx, parallel.Options{},
+		func(ctx context.Context) error {
+			var err error
+			if userID, err = client.GetUser(ctx, identity); err != nil {
+				return fmt.Errorf("failed to get user identity: %w", err)
+			}
+			return nil
+		},
+		func(ctx context.Context) error {
+			var err error
+			if groups, err = client.GetGroups(ctx, identity); err != nil {
+				return fmt.Errorf("failed to get user groups: %w", err)
+			}
+			return nil
+		},
+		func(ctx context.Context) error {
+			clusterAdmin, clusterAdminErr = client.IsClusterAdmin(ctx, identity)
+			return nil
+		},
+	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user identity: %w", err)
+		return models.User{}, err
 	}
 
-	return &models.User{
+	isAdmin := slices.ContainsFunc(groups, func(group string) bool {
+		return slices.Contains(r.adminGroups, group)
+	})
+	if !isAdmin {
+		if clusterAdminErr != nil {
+			return models.User{}, fmt.Errorf("failed to check admin status: %w", clusterAdminErr)
+		}
+		isAdmin = clusterAdmin
+	}
+
+	return models.User{
 		UserID:       userID,
+		Groups:       groups,
 		ClusterAdmin: isAdmin,
 	}, nil
 }
+
+// GetNamespaceRoles reviews the rules of identity in every namespace it can access, on a
+// bounded worker pool. The namespaces whose review fails are returned with their error.
+func (r *UserRepository) GetNamespaceRoles(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity) ([]models.NamespaceRoles, error) {
+	ctx, span := tracing.StartSpan(ctx, "UserRepository.GetNamespaceRoles")
+	defer span.End()
+
+	namespaces, err := client.GetNamespaces(ctx, identity)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, fmt.Errorf("error fetching namespaces: %w", err)
+	}
+	span.SetAttributes(attribute.Int("bff.namespaces.count", len(namespaces)))
+
+	roles := make([]models.NamespaceRoles, len(namespaces))
+	err = parallel.ForEach(ctx, parallel.Options{Limit: NamespaceRolesWorkers}, len(namespaces), func(ctx context.Context, i int) {
+		roles[i] = models.NamespaceRoles{Namespace: namespaces[i].Name, Rules: []models.ResourceRule{}}
+		status, err := client.GetRules(ctx, identity, namespaces[i].Name)
+		if err != nil {
+			roles[i].Error = err.Error()
+			return
+		}
+		roles[i].Incomplete = status.Incomplete
+		for _, rule := range status.ResourceRules {
+			roles[i].Rules = append(roles[i].Rules, models.ResourceRule{
+				Verbs:         rule.Verbs,
+				APIGroups:     rule.APIGroups,
+				Resources:     rule.Resources,
+				ResourceNames: rule.ResourceNames,
+			})
+		}
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, fmt.Errorf("error reviewing namespace roles: %w", err)
+	}
+	return roles, nil
+}
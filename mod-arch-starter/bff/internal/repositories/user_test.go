@@ -0,0 +1,223 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes/k8mocks"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/models"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authv1 "k8s.io/api/authorization/v1"
+)
+
+func TestUserRepository_GetUser(t *testing.T) {
+	testutil.RequireEnv(t, testEnv)
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		userID    string
+		groups    []string
+		wantAdmin bool
+	}{
+		{name: "cluster admin", userID: "user@example.com", wantAdmin: true},
+		{name: "namespace restricted user", userID: "doraNonAdmin@example.com", groups: []string{"dora-namespace-group"}},
+	}
+
+	repo := NewUserRepository()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity := &k8s.RequestIdentity{UserID: tt.userID, Groups: tt.groups}
+
+			internal, err := repo.GetUser(testEnv.InternalClient(), ctx, identity)
+			require.NoError(t, err)
+			assert.Equal(t, tt.userID, internal.UserID)
+			assert.Equal(t, tt.wantAdmin, internal.ClusterAdmin)
+
+			client, err := testEnv.ClientFor(identity)
+			require.NoError(t, err)
+			impersonated, err := repo.GetUser(client, ctx, identity)
+			require.NoError(t, err)
+			assert.Equal(t, tt.userID, impersonated.UserID)
+			assert.Equal(t, tt.wantAdmin, impersonated.ClusterAdmin)
+		})
+	}
+}
+
+func TestUserRepository_GetUserAdminGroups(t *testing.T) {
+	client := k8mocks.NewMockKubernetesClient(k8mocks.DefaultMockFixtures(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	dora := &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com"}
+
+	repo := NewUserRepository()
+	user, err := repo.GetUser(client, context.Background(), dora)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dora-namespace-group", "dora-service-group"}, user.Groups)
+	assert.False(t, user.ClusterAdmin)
+
+	repo.UseAdminGroups([]string{"odh-admins", "dora-service-group"})
+	user, err = repo.GetUser(client, context.Background(), dora)
+	require.NoError(t, err)
+	assert.True(t, user.ClusterAdmin)
+}
+
+func TestUserRepository_GetUserParallel(t *testing.T) {
+	client := &slowAdminClient{MockKubernetesClient: k8mocks.NewMockKubernetesClient(k8mocks.DefaultMockFixtures(), slog.New(slog.NewTextHandler(io.Discard, nil)))}
+	dora := &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com"}
+	repo := NewUserRepository()
+
+	_, err := repo.GetUser(client, context.Background(), dora)
+	assert.ErrorContains(t, err, "failed to check admin status")
+
+	// The failed review doesn't matter for admin group members
+	repo.UseAdminGroups([]string{"dora-service-group"})
+	start := time.Now()
+	user, err := repo.GetUser(client, context.Background(), dora)
+	require.NoError(t, err)
+	assert.True(t, user.ClusterAdmin)
+	assert.Less(t, time.Since(start), 2*slowAdminDelay, "the calls run in parallel")
+}
+
+const slowAdminDelay = 100 * time.Millisecond
+
+// slowAdminClient is a MockKubernetesClient whose lookups take slowAdminDelay and whose
+// cluster-admin review fails.
+type slowAdminClient struct {
+	*k8mocks.MockKubernetesClient
+}
+
+func (c *slowAdminClient) GetUser(ctx context.Context, identity *k8s.RequestIdentity) (string, error) {
+	time.Sleep(slowAdminDelay)
+	return c.MockKubernetesClient.GetUser(ctx, identity)
+}
+
+func (c *slowAdminClient) GetGroups(ctx context.Context, identity *k8s.RequestIdentity) ([]string, error) {
+	time.Sleep(slowAdminDelay)
+	return c.MockKubernetesClient.GetGroups(ctx, identity)
+}
+
+func (c *slowAdminClient) IsClusterAdmin(context.Context, *k8s.RequestIdentity) (bool, error) {
+	time.Sleep(slowAdminDelay)
+	return false, errors.New("unavailable")
+}
+
+func TestUserRepository_GetUserIdentityCache(t *testing.T) {
+	client := &countingUserClient{MockKubernetesClient: k8mocks.NewMockKubernetesClient(k8mocks.DefaultMockFixtures(), slog.New(slog.NewTextHandler(io.Discard, nil)))}
+	dora := &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com", Groups: []string{"b", "a"}}
+	results := map[string]int{}
+	var mu sync.Mutex
+	cache := NewIdentityCache(time.Minute, 10)
+	cache.Observe(func(lookup, result string) {
+		mu.Lock()
+		defer mu.Unlock()
+		results[result]++
+	})
+	repo := NewUserRepository()
+	repo.UseIdentityCache(cache)
+
+	// Concurrent lookups share one round trip
+	client.delay = 50 * time.Millisecond
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			user, err := repo.GetUser(client, context.Background(), dora)
+			assert.NoError(t, err)
+			assert.Equal(t, "doraNonAdmin@example.com", user.UserID)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), client.calls.Load())
+	assert.Equal(t, map[string]int{IdentityLookupMiss: 1, IdentityLookupShared: 4}, results)
+
+	// Then the result is cached, whatever the order of the groups
+	client.delay = 0
+	user, err := repo.GetUser(client, context.Background(), &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com", Groups: []string{"a", "b"}})
+	require.NoError(t, err)
+	user.Groups[0] = "mutated"
+	user, err = repo.GetUser(client, context.Background(), dora)
+	require.NoError(t, err)
+	assert.NotContains(t, user.Groups, "mutated")
+	assert.Equal(t, int32(1), client.calls.Load())
+	assert.Equal(t, 2, results[IdentityLookupHit])
+
+	// Per cluster and identity
+	_, err = repo.GetUser(client, k8s.WithCluster(context.Background(), "east"), dora)
+	require.NoError(t, err)
+	_, err = repo.GetUser(client, context.Background(), &k8s.RequestIdentity{UserID: "user@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), client.calls.Load())
+
+	// Errors aren't cached
+	client.fail.Store(true)
+	_, err = repo.GetUser(client, context.Background(), &k8s.RequestIdentity{UserID: "user@example.com", Groups: []string{"odh-admins"}})
+	assert.Error(t, err)
+	client.fail.Store(false)
+	_, err = repo.GetUser(client, context.Background(), &k8s.RequestIdentity{UserID: "user@example.com", Groups: []string{"odh-admins"}})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(5), client.calls.Load())
+}
+
+// countingUserClient is a MockKubernetesClient counting its user lookups, which take delay and
+// fail while fail is set.
+type countingUserClient struct {
+	*k8mocks.MockKubernetesClient
+	delay time.Duration
+	calls atomic.Int32
+	fail  atomic.Bool
+}
+
+func (c *countingUserClient) GetUser(ctx context.Context, identity *k8s.RequestIdentity) (string, error) {
+	c.calls.Add(1)
+	time.Sleep(c.delay)
+	if c.fail.Load() {
+		return "", errors.New("unavailable")
+	}
+	return c.MockKubernetesClient.GetUser(ctx, identity)
+}
+
+func TestUserRepository_GetNamespaceRoles(t *testing.T) {
+	client := &failingRulesClient{MockKubernetesClient: k8mocks.NewMockKubernetesClient(k8mocks.DefaultMockFixtures(), slog.New(slog.NewTextHandler(io.Discard, nil))), namespace: "kubeflow"}
+	repo := NewUserRepository()
+
+	roles, err := repo.GetNamespaceRoles(client, context.Background(), &k8s.RequestIdentity{UserID: "doraNonAdmin@example.com"})
+	require.NoError(t, err)
+	require.Len(t, roles, 1)
+	assert.Equal(t, "dora-namespace", roles[0].Namespace)
+	assert.Equal(t, []models.ResourceRule{{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}}}, roles[0].Rules)
+
+	roles, err = repo.GetNamespaceRoles(client, context.Background(), &k8s.RequestIdentity{UserID: "user@example.com"})
+	require.NoError(t, err)
+	require.Greater(t, len(roles), 1)
+	for _, role := range roles {
+		if role.Namespace == client.namespace {
+			assert.Equal(t, "unavailable", role.Error)
+			assert.Empty(t, role.Rules)
+		} else {
+			assert.Empty(t, role.Error)
+			assert.Len(t, role.Rules, 1)
+		}
+	}
+}
+
+// failingRulesClient is a MockKubernetesClient whose rules review of namespace fails.
+type failingRulesClient struct {
+	*k8mocks.MockKubernetesClient
+	namespace string
+}
+
+func (c *failingRulesClient) GetRules(ctx context.Context, identity *k8s.RequestIdentity, namespace string) (*authv1.SubjectRulesReviewStatus, error) {
+	if namespace == c.namespace {
+		return nil, errors.New("unavailable")
+	}
+	return c.MockKubernetesClient.GetRules(ctx, identity, namespace)
+}
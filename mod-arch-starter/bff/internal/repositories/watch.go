@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	k8s "github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/kubernetes"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/tracing"
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/watchmux"
+	"go.opentelemetry.io/otel/attribute"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// sharedWatchAccessTTL is how long the access of a subscriber of a shared watch is trusted
+// before it is reviewed again.
+const sharedWatchAccessTTL = time.Minute
+
+// WatchRepository opens Kubernetes watches on behalf of the requesting identity.
+// Authorization is enforced by the client: a "watch" SubjectAccessReview for the internal
+// auth method, the API server itself for token and impersonation clients.
+//
+// With UseMultiplexer, the watches of the clients watching with the credentials of the BFF
+// (the internal auth method) are shared through a watchmux.Mux: one upstream watch per
+// resource and namespace, whatever the number of users. Each subscription is authorized with
+// the same review, repeated every minute so a user losing access stops receiving events. A
+// cluster-wide watch of namespaces is also open to the users who can't watch them all, and
+// filtered to the namespaces they can get, like GetNamespaces.
+type WatchRepository struct {
+	mux *watchmux.Mux
+}
+
+func NewWatchRepository() *WatchRepository {
+	return &WatchRepository{}
+}
+
+// UseMultiplexer shares the watches of the clients implementing kubernetes.SharedWatcher
+// through mux (nil disables it).
+func (r *WatchRepository) UseMultiplexer(mux *watchmux.Mux) {
+	r.mux = mux
+}
+
+func (r *WatchRepository) WatchResource(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (watch.Interface, error) {
+	ctx, span := tracing.StartSpan(ctx, "WatchRepository.WatchResource",
+		attribute.String("k8s.resource", gvr.String()),
+		attribute.String("k8s.namespace.name", namespace),
+	)
+	defer span.End()
+
+	shared, ok := client.(k8s.SharedWatcher)
+	if r.mux == nil || !ok {
+		w, err := client.WatchResource(ctx, identity, gvr, namespace, opts)
+		if err != nil {
+			tracing.RecordError(span, err)
+			return nil, fmt.Errorf("error watching %s: %w", gvr.Resource, err)
+		}
+		return w, nil
+	}
+
+	span.SetAttributes(attribute.Bool("bff.watch.shared", true))
+	filter, err := sharedWatchFilter(client, ctx, identity, gvr, namespace)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, fmt.Errorf("error watching %s: %w", gvr.Resource, err)
+	}
+	key := watchmux.Key{Cluster: k8s.ClusterFromContext(ctx), GVR: gvr, Namespace: namespace}
+	w, err := r.mux.Watch(ctx, key, func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+		return shared.SharedWatch(ctx, gvr, namespace, opts)
+	}, opts.ResourceVersion, filter)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, fmt.Errorf("error watching %s: %w", gvr.Resource, err)
+	}
+	return w, nil
+}
+
+// sharedWatchFilter authorizes identity to subscribe to a shared watch, and returns the
+// filter of its events.
+func sharedWatchFilter(client k8s.KubernetesClientInterface, ctx context.Context, identity *k8s.RequestIdentity, gvr schema.GroupVersionResource, namespace string) (watchmux.Filter, error) {
+	canWatch := func(ctx context.Context) error {
+		allowed, err := client.CanAccess(ctx, identity, "watch", gvr.Group, gvr.Resource, namespace)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return k8serrors.NewForbidden(gvr.GroupResource(), "", fmt.Errorf("user %q cannot watch %s in namespace %q", identity.UserID, gvr.Resource, namespace))
+		}
+		return nil
+	}
+
+	err := canWatch(ctx)
+	switch {
+	case err == nil:
+		reviewed := time.Now()
+		return func(ctx context.Context, _ runtime.Object) (bool, error) {
+			if time.Since(reviewed) < sharedWatchAccessTTL {
+				return true, nil
+			}
+			if err := canWatch(ctx); err != nil {
+				return false, err
+			}
+			reviewed = time.Now()
+			return true, nil
+		}, nil
+	case !k8serrors.IsForbidden(err) || gvr.Group != "" || gvr.Resource != "namespaces" || namespace != "":
+		return nil, err
+	}
+
+	type decision struct {
+		allowed  bool
+		reviewed time.Time
+	}
+	decisions := map[string]decision{}
+	return func(ctx context.Context, obj runtime.Object) (bool, error) {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return false, nil
+		}
+		name := accessor.GetName()
+		if d, ok := decisions[name]; ok && time.Since(d.reviewed) < sharedWatchAccessTTL {
+			return d.allowed, nil
+		}
+		allowed, err := client.CanAccess(ctx, identity, "get", "", "namespaces", name)
+		if err != nil {
+			return false, err
+		}
+		decisions[name] = decision{allowed: allowed, reviewed: time.Now()}
+		return allowed, nil
+	}, nil
+}
@@ -0,0 +1,150 @@
+package resilience
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// State is the state of a circuit breaker.
+type State int
+
+const (
+	// StateClosed lets every call through.
+	StateClosed State = iota
+	// StateHalfOpen lets a single probe call through after the cooldown; its outcome
+	// closes or reopens the circuit.
+	StateHalfOpen
+	// StateOpen fails every call without calling the upstream.
+	StateOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half-open"
+	case StateOpen:
+		return "open"
+	default:
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+}
+
+// Result is the outcome of a call let through by a Breaker.
+type Result int
+
+const (
+	// Succeeded is any response of a healthy upstream, including 4xx.
+	Succeeded Result = iota
+	// Failed is a transient failure: no response, a timeout or a 5xx.
+	Failed
+	// Abandoned is a call cancelled by its caller, which says nothing about the upstream.
+	Abandoned
+)
+
+// ErrCircuitOpen is matched (with errors.Is) by the errors of calls rejected by an open circuit.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// OpenError rejects a call to an upstream whose circuit is open.
+type OpenError struct {
+	Upstream string
+	// RetryAfter is the time left before a probe call is let through.
+	RetryAfter time.Duration
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("%s is unavailable: %v", e.Upstream, ErrCircuitOpen)
+}
+
+func (e *OpenError) Is(target error) bool { return target == ErrCircuitOpen }
+
+// Breaker is the circuit breaker of one upstream: after FailureThreshold consecutive failures
+// the circuit opens and calls fail fast for the cooldown, so a flapping upstream is not flooded
+// with requests that would time out anyway; then a single probe decides whether it closes
+// again. A nil *Breaker lets every call through. It is safe for concurrent use.
+type Breaker struct {
+	upstream  string
+	threshold int
+	cooldown  time.Duration
+	onChange  func(upstream string, state State)
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// Allow reports whether a call may go through, returning an *OpenError otherwise. Every
+// allowed call must be followed by Record.
+func (b *Breaker) Allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		elapsed := b.now().Sub(b.openedAt)
+		if elapsed < b.cooldown {
+			return &OpenError{Upstream: b.upstream, RetryAfter: b.cooldown - elapsed}
+		}
+		b.setState(StateHalfOpen)
+		b.probing = true
+		return nil
+	case StateHalfOpen:
+		if b.probing {
+			return &OpenError{Upstream: b.upstream}
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// Record reports the outcome of a call let through by Allow.
+func (b *Breaker) Record(result Result) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateHalfOpen {
+		b.probing = false
+	}
+	switch result {
+	case Succeeded:
+		b.failures = 0
+		if b.state != StateClosed {
+			b.setState(StateClosed)
+		}
+	case Failed:
+		b.failures++
+		if b.state == StateHalfOpen || (b.state == StateClosed && b.failures >= b.threshold) {
+			b.openedAt = b.now()
+			b.setState(StateOpen)
+		}
+	}
+}
+
+// State returns the current state of the circuit.
+func (b *Breaker) State() State {
+	if b == nil {
+		return StateClosed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+func (b *Breaker) setState(state State) {
+	b.state = state
+	if b.onChange != nil {
+		b.onChange(b.upstream, state)
+	}
+}
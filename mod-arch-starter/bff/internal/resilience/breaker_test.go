@@ -0,0 +1,62 @@
+package resilience
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreaker(t *testing.T) {
+	var states []State
+	policy := New(Config{FailureThreshold: 2, Cooldown: time.Minute})
+	policy.Observe(func(upstream string, state State) {
+		assert.Equal(t, "kubernetes", upstream)
+		states = append(states, state)
+	}, nil)
+	breaker := policy.Breaker("kubernetes")
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+
+	require.NoError(t, breaker.Allow())
+	breaker.Record(Failed)
+	require.NoError(t, breaker.Allow())
+	breaker.Record(Abandoned)
+	require.NoError(t, breaker.Allow())
+	breaker.Record(Failed)
+	assert.Equal(t, StateOpen, breaker.State(), "consecutive failures open the circuit")
+
+	err := breaker.Allow()
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	var openErr *OpenError
+	require.ErrorAs(t, err, &openErr)
+	assert.Equal(t, time.Minute, openErr.RetryAfter)
+
+	now = now.Add(time.Minute)
+	require.NoError(t, breaker.Allow(), "a probe is let through after the cooldown")
+	assert.ErrorIs(t, breaker.Allow(), ErrCircuitOpen, "only one")
+	breaker.Record(Failed)
+	assert.Equal(t, StateOpen, breaker.State(), "a failed probe reopens the circuit")
+
+	now = now.Add(time.Minute)
+	require.NoError(t, breaker.Allow())
+	breaker.Record(Succeeded)
+	assert.Equal(t, StateClosed, breaker.State())
+	assert.Equal(t, []State{StateClosed, StateOpen, StateHalfOpen, StateOpen, StateHalfOpen, StateClosed}, states)
+	assert.Same(t, breaker, policy.Breaker("kubernetes"))
+}
+
+func TestBreaker_Disabled(t *testing.T) {
+	breaker := New(Config{}).Breaker("kubernetes")
+	assert.Nil(t, breaker)
+	for range 10 {
+		require.NoError(t, breaker.Allow())
+		breaker.Record(Failed)
+	}
+	assert.Equal(t, StateClosed, breaker.State())
+
+	var policy *Policy
+	assert.Nil(t, policy.Breaker("kubernetes"))
+	policy.RecordRetry("kubernetes")
+}
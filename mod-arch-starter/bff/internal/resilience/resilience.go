@@ -0,0 +1,204 @@
+// Package resilience protects the BFF from flapping upstreams (the Kubernetes API server,
+// model registries, ...): idempotent calls are retried on transient errors with a jittered
+// exponential backoff, and a circuit breaker per upstream fails calls fast while the upstream
+// keeps failing, instead of letting every UI request wait for a timeout.
+package resilience
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/config"
+)
+
+// Config configures a Policy.
+type Config struct {
+	// MaxRetries is the number of retries of an idempotent call after a transient error;
+	// zero disables retries.
+	MaxRetries int
+	// MinBackoff and MaxBackoff bound the exponential backoff between retries (default
+	// config.DefaultUpstreamRetryMinBackoff and config.DefaultUpstreamRetryMaxBackoff).
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// FailureThreshold is the number of consecutive failures opening the circuit of an
+	// upstream; zero disables the circuit breakers.
+	FailureThreshold int
+	// Cooldown is how long an open circuit fails calls before a probe (default
+	// config.DefaultCircuitBreakerCooldown).
+	Cooldown time.Duration
+}
+
+// ConfigFromEnv returns the resilience settings from the BFF configuration.
+func ConfigFromEnv(cfg config.EnvConfig) Config {
+	return Config{
+		MaxRetries:       cfg.UpstreamMaxRetries,
+		MinBackoff:       cfg.UpstreamRetryMinBackoff,
+		MaxBackoff:       cfg.UpstreamRetryMaxBackoff,
+		FailureThreshold: cfg.CircuitBreakerFailures,
+		Cooldown:         cfg.CircuitBreakerCooldown,
+	}
+}
+
+// Policy holds the retry settings and the circuit breakers of every upstream. A nil *Policy
+// neither retries nor breaks circuits. It is safe for concurrent use.
+type Policy struct {
+	cfg Config
+
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+	onState  func(upstream string, state State)
+	onRetry  func(upstream string)
+}
+
+// New returns the Policy of cfg.
+func New(cfg Config) *Policy {
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = config.DefaultUpstreamRetryMinBackoff
+	}
+	if cfg.MaxBackoff < cfg.MinBackoff {
+		cfg.MaxBackoff = max(config.DefaultUpstreamRetryMaxBackoff, cfg.MinBackoff)
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = config.DefaultCircuitBreakerCooldown
+	}
+	return &Policy{cfg: cfg, breakers: map[string]*Breaker{}}
+}
+
+// Config returns the settings of p, with the defaults applied.
+func (p *Policy) Config() Config {
+	if p == nil {
+		return Config{}
+	}
+	return p.cfg
+}
+
+// Observe calls onState when the circuit of an upstream changes state and onRetry on every
+// retry, e.g. to record them as metrics. Either may be nil. Call it before p is used.
+func (p *Policy) Observe(onState func(upstream string, state State), onRetry func(upstream string)) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onState, p.onRetry = onState, onRetry
+}
+
+// Breaker returns the circuit breaker of upstream, creating it on first use, or nil when the
+// circuit breakers are disabled. Name upstreams by service (e.g. "kubernetes") or host, so
+// their number stays bounded.
+func (p *Policy) Breaker(upstream string) *Breaker {
+	if p == nil || p.cfg.FailureThreshold <= 0 {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	b, ok := p.breakers[upstream]
+	if !ok {
+		b = &Breaker{
+			upstream:  upstream,
+			threshold: p.cfg.FailureThreshold,
+			cooldown:  p.cfg.Cooldown,
+			onChange:  p.onState,
+			now:       time.Now,
+		}
+		p.breakers[upstream] = b
+		if p.onState != nil {
+			p.onState(upstream, StateClosed)
+		}
+	}
+	return b
+}
+
+// RecordRetry reports a retry of a call to upstream to the Observe hook.
+func (p *Policy) RecordRetry(upstream string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	onRetry := p.onRetry
+	p.mu.Unlock()
+	if onRetry != nil {
+		onRetry(upstream)
+	}
+}
+
+// Backoff returns the delay before retry attempt (0-based): exponential between minDelay and
+// maxDelay, minus up to 20% of jitter so concurrent callers don't retry in lockstep.
+func Backoff(minDelay, maxDelay time.Duration, attempt int) time.Duration {
+	delay := minDelay << min(attempt, 16)
+	if delay <= 0 || delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay - time.Duration(rand.Int64N(int64(delay)/5+1))
+}
+
+// Sleep waits for delay, returning false early when ctx is done.
+func Sleep(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// TransientStatus reports whether an upstream response status is worth retrying: 429 and the
+// 5xx of an overloaded or restarting upstream.
+func TransientStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// FailureStatus reports whether a response status counts as a failure of the upstream for
+// its circuit breaker: the transient 5xx. A 429 is the upstream protecting itself, not failing.
+func FailureStatus(code int) bool {
+	return code != http.StatusTooManyRequests && TransientStatus(code)
+}
+
+// Idempotent reports whether a request with method may be sent again.
+func Idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// TransportResult classifies the outcome of a call that got err (nil when a response with
+// status was received) and whose caller context is ctx.
+func TransportResult(ctx context.Context, status int, err error) Result {
+	switch {
+	case ctx.Err() != nil || errors.Is(err, context.Canceled):
+		return Abandoned
+	case err != nil || FailureStatus(status):
+		return Failed
+	default:
+		return Succeeded
+	}
+}
+
+// ParseRetryAfter returns the delay of a Retry-After header, in seconds or as an HTTP date.
+func ParseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
@@ -0,0 +1,39 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff(t *testing.T) {
+	for attempt, want := range map[int]time.Duration{0: 100 * time.Millisecond, 1: 200 * time.Millisecond, 3: 800 * time.Millisecond, 4: time.Second, 100: time.Second} {
+		delay := Backoff(100*time.Millisecond, time.Second, attempt)
+		assert.LessOrEqual(t, delay, want, "attempt %d", attempt)
+		assert.GreaterOrEqual(t, delay, want*4/5, "up to 20%% of jitter")
+	}
+}
+
+func TestTransportResult(t *testing.T) {
+	ctx := context.Background()
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	assert.Equal(t, Succeeded, TransportResult(ctx, http.StatusOK, nil))
+	assert.Equal(t, Succeeded, TransportResult(ctx, http.StatusNotFound, nil), "4xx come from a healthy upstream")
+	assert.Equal(t, Succeeded, TransportResult(ctx, http.StatusTooManyRequests, nil))
+	assert.Equal(t, Failed, TransportResult(ctx, http.StatusServiceUnavailable, nil))
+	assert.Equal(t, Failed, TransportResult(ctx, 0, errors.New("connection reset by peer")))
+	assert.Equal(t, Failed, TransportResult(ctx, 0, context.DeadlineExceeded), "an attempt timing out")
+	assert.Equal(t, Abandoned, TransportResult(cancelled, 0, context.Canceled))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	assert.Equal(t, 3*time.Second, ParseRetryAfter("3"))
+	assert.Zero(t, ParseRetryAfter(""))
+	assert.Zero(t, ParseRetryAfter("soon"))
+}
@@ -0,0 +1,83 @@
+package resilience
+
+import (
+	"io"
+	"net/http"
+)
+
+// WrapTransport returns a wrapper adding the retries and the circuit breaker of upstream to an
+// HTTP transport. Its result matches transport.WrapperFunc, so it can be passed to
+// rest.Config.Wrap.
+//
+// Idempotent requests whose body can be replayed are retried after a connection error or a
+// transient status. Responses with a Retry-After header are returned to the caller, which
+// knows better: client-go already honors them. Upgraded connections (exec, port-forward) are
+// never retried.
+func (p *Policy) WrapTransport(upstream string) func(http.RoundTripper) http.RoundTripper {
+	return p.WrapTransportFor(func(*http.Request) string { return upstream })
+}
+
+// WrapTransportFor is WrapTransport for a transport shared by several upstreams, e.g. the API
+// servers of several clusters: upstream names the upstream of each request.
+func (p *Policy) WrapTransportFor(upstream func(req *http.Request) string) func(http.RoundTripper) http.RoundTripper {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		if p == nil || (p.cfg.MaxRetries <= 0 && p.cfg.FailureThreshold <= 0) {
+			return rt
+		}
+		return &roundTripper{policy: p, upstream: upstream, next: rt}
+	}
+}
+
+type roundTripper struct {
+	policy   *Policy
+	upstream func(req *http.Request) string
+	next     http.RoundTripper
+}
+
+func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	cfg := rt.policy.cfg
+	retries := 0
+	if Idempotent(req.Method) && (req.Body == nil || req.GetBody != nil) && req.Header.Get("Upgrade") == "" {
+		retries = cfg.MaxRetries
+	}
+	upstream := rt.upstream(req)
+	breaker := rt.policy.Breaker(upstream)
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		if err := breaker.Allow(); err != nil {
+			return nil, err
+		}
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				breaker.Record(Abandoned)
+				return nil, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+
+		resp, err := rt.next.RoundTrip(req)
+		status := 0
+		if err == nil {
+			status = resp.StatusCode
+		}
+		breaker.Record(TransportResult(ctx, status, err))
+
+		transient := err != nil || (TransientStatus(status) && resp.Header.Get("Retry-After") == "")
+		if attempt >= retries || !transient || ctx.Err() != nil {
+			return resp, err
+		}
+		if resp != nil {
+			// Drain the body so the connection can be reused
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+
+		rt.policy.RecordRetry(upstream)
+		if !Sleep(ctx, Backoff(cfg.MinBackoff, cfg.MaxBackoff, attempt)) {
+			return nil, ctx.Err()
+		}
+	}
+}
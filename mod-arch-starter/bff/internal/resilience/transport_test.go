@@ -0,0 +1,121 @@
+package resilience
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapTransport(t *testing.T) {
+	var calls atomic.Int32
+	var status atomic.Int32
+	status.Store(http.StatusServiceUnavailable)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1)%3 != 0 {
+			if r.URL.Query().Get("retry-after") != "" {
+				w.Header().Set("Retry-After", "1")
+			}
+			w.WriteHeader(int(status.Load()))
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	var retries atomic.Int32
+	policy := New(Config{MaxRetries: 2, MinBackoff: time.Millisecond})
+	policy.Observe(nil, func(upstream string) {
+		assert.Equal(t, "kubernetes", upstream)
+		retries.Add(1)
+	})
+	client := &http.Client{Transport: policy.WrapTransport("kubernetes")(http.DefaultTransport)}
+
+	req, err := http.NewRequest(http.MethodPut, srv.URL, strings.NewReader("payload"))
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "idempotent requests are retried")
+	assert.Equal(t, "payload", string(body), "with their body")
+	assert.Equal(t, int32(2), retries.Load())
+
+	calls.Store(0)
+	resp, err = client.Post(srv.URL, "text/plain", strings.NewReader("payload"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode, "POST is not retried")
+	assert.Equal(t, int32(1), calls.Load())
+
+	calls.Store(0)
+	resp, err = client.Get(srv.URL + "?retry-after=1")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode, "Retry-After is left to the caller")
+
+	calls.Store(0)
+	status.Store(http.StatusNotFound)
+	resp, err = client.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "4xx are not retried")
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestWrapTransport_CircuitBreaker(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	policy := New(Config{FailureThreshold: 2, Cooldown: time.Minute})
+	client := &http.Client{Transport: policy.WrapTransport("kubernetes")(http.DefaultTransport)}
+
+	for range 2 {
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	_, err := client.Get(srv.URL)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, int32(2), calls.Load(), "an open circuit fails fast")
+}
+
+func TestWrapTransportFor(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	policy := New(Config{FailureThreshold: 1, Cooldown: time.Minute})
+	client := &http.Client{Transport: policy.WrapTransportFor(func(req *http.Request) string {
+		return "kubernetes/" + req.URL.Query().Get("cluster")
+	})(http.DefaultTransport)}
+
+	resp, err := client.Get(srv.URL + "?cluster=spoke")
+	require.NoError(t, err)
+	resp.Body.Close()
+	_, err = client.Get(srv.URL + "?cluster=spoke")
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+
+	resp, err = client.Get(srv.URL + "?cluster=hub")
+	require.NoError(t, err, "each upstream has its own circuit")
+	resp.Body.Close()
+	assert.Equal(t, StateOpen, policy.Breaker("kubernetes/spoke").State())
+}
+
+func TestWrapTransport_Disabled(t *testing.T) {
+	assert.Equal(t, http.DefaultTransport, New(Config{}).WrapTransport("kubernetes")(http.DefaultTransport))
+	var policy *Policy
+	assert.Equal(t, http.DefaultTransport, policy.WrapTransport("kubernetes")(http.DefaultTransport))
+}
@@ -0,0 +1,196 @@
+// Package servertls terminates TLS in the BFF, for deployments without a sidecar proxy: it
+// serves a certificate and key files reloaded when they are rotated (e.g. by cert-manager),
+// optionally verifies client certificates against a CA bundle, and defaults to modern
+// protocol versions and cipher suites.
+package servertls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Client certificate policies of Config.ClientAuth.
+const (
+	// ClientAuthNone doesn't request client certificates.
+	ClientAuthNone = "none"
+	// ClientAuthOptional verifies client certificates when clients send one.
+	ClientAuthOptional = "optional"
+	// ClientAuthRequire rejects the handshakes of clients without a valid certificate.
+	ClientAuthRequire = "require"
+)
+
+// Protocol versions of Config.MinVersion.
+const (
+	Version12 = "1.2"
+	Version13 = "1.3"
+)
+
+// CipherSuites are the TLS 1.2 cipher suites served: forward secret AEAD suites only. TLS 1.3
+// suites are not configurable and are all modern.
+var CipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+}
+
+// Config sets the files and policies of a Server.
+type Config struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile is the PEM bundle of the CAs client certificates are verified against,
+	// required unless ClientAuth is ClientAuthNone.
+	ClientCAFile string
+	// ClientAuth is ClientAuthNone (the default when empty), ClientAuthOptional or
+	// ClientAuthRequire.
+	ClientAuth string
+	// MinVersion is Version12 or Version13 (the default when empty).
+	MinVersion string
+}
+
+// ValidClientAuth reports whether policy is a supported client certificate policy.
+func ValidClientAuth(policy string) bool {
+	switch policy {
+	case "", ClientAuthNone, ClientAuthOptional, ClientAuthRequire:
+		return true
+	default:
+		return false
+	}
+}
+
+// ValidMinVersion reports whether version is a supported minimum protocol version.
+func ValidMinVersion(version string) bool {
+	switch version {
+	case "", Version12, Version13:
+		return true
+	default:
+		return false
+	}
+}
+
+// Server holds the certificate and client CAs served, reloaded from their files by Reload. It
+// is safe for concurrent use.
+type Server struct {
+	cfg    Config
+	logger *slog.Logger
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+}
+
+// New loads the files of cfg. It fails when they can't be read or parsed, so a misconfigured
+// server doesn't start.
+func New(cfg Config, logger *slog.Logger) (*Server, error) {
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, errors.New("a certificate and a key file are required")
+	}
+	if !ValidClientAuth(cfg.ClientAuth) {
+		return nil, fmt.Errorf("invalid client certificate policy %q", cfg.ClientAuth)
+	}
+	if !ValidMinVersion(cfg.MinVersion) {
+		return nil, fmt.Errorf("invalid minimum TLS version %q", cfg.MinVersion)
+	}
+	if cfg.verifiesClients() && cfg.ClientCAFile == "" {
+		return nil, errors.New("a client CA file is required to verify client certificates")
+	}
+	s := &Server{cfg: cfg, logger: logger}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (c Config) verifiesClients() bool {
+	return c.ClientAuth == ClientAuthOptional || c.ClientAuth == ClientAuthRequire
+}
+
+// Files returns the files Reload reads, to be watched for rotations.
+func (s *Server) Files() []string {
+	files := []string{s.cfg.CertFile, s.cfg.KeyFile}
+	if s.cfg.verifiesClients() {
+		files = append(files, s.cfg.ClientCAFile)
+	}
+	return files
+}
+
+// Reload reads the certificate, key and client CAs again. On error the current ones are kept
+// and served: a rotation writing the certificate before the key is retried on the next change.
+func (s *Server) Reload() error {
+	cert, err := tls.LoadX509KeyPair(s.cfg.CertFile, s.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load the TLS certificate: %w", err)
+	}
+	var clientCAs *x509.CertPool
+	if s.cfg.verifiesClients() {
+		pem, err := os.ReadFile(s.cfg.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read the client CA file: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in the client CA file %s", s.cfg.ClientCAFile)
+		}
+	}
+
+	s.mu.Lock()
+	s.cert = &cert
+	s.clientCAs = clientCAs
+	s.mu.Unlock()
+
+	if leaf := cert.Leaf; leaf != nil {
+		s.logger.Info("loaded TLS certificate", "subject", leaf.Subject.String(), "notAfter", leaf.NotAfter.Format(time.RFC3339))
+		if time.Until(leaf.NotAfter) < 0 {
+			s.logger.Warn("TLS certificate has expired", "notAfter", leaf.NotAfter.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+// TLSConfig returns the configuration of an http.Server serving the current certificate and
+// verifying client certificates against the current client CAs.
+func (s *Server) TLSConfig() *tls.Config {
+	base := &tls.Config{
+		MinVersion:       tls.VersionTLS13,
+		CipherSuites:     CipherSuites,
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256, tls.CurveP384},
+		NextProtos:       []string{"h2", "http/1.1"},
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			s.mu.RLock()
+			defer s.mu.RUnlock()
+			return s.cert, nil
+		},
+	}
+	if s.cfg.MinVersion == Version12 {
+		base.MinVersion = tls.VersionTLS12
+	}
+	switch s.cfg.ClientAuth {
+	case ClientAuthOptional:
+		base.ClientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuthRequire:
+		base.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	if !s.cfg.verifiesClients() {
+		return base
+	}
+
+	// The client CAs are part of the configuration of each handshake, so rotations apply to
+	// new connections
+	config := base.Clone()
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		handshake := base.Clone()
+		s.mu.RLock()
+		handshake.ClientCAs = s.clientCAs
+		s.mu.RUnlock()
+		return handshake, nil
+	}
+	return config
+}
@@ -0,0 +1,211 @@
+package servertls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns the PEM certificate and key of subject, for a server or a client.
+func (ca *testCA) issue(t *testing.T, serial int64, subject pkix.Name, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+// serve serves s on a local listener, answering with the common name of the client certificate.
+func serve(t *testing.T, s *Server) string {
+	t.Helper()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", s.TLSConfig())
+	require.NoError(t, err)
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.VerifiedChains) > 0 {
+			_, _ = io.WriteString(w, r.TLS.VerifiedChains[0][0].Subject.CommonName)
+		}
+	})}
+	go func() { _ = srv.Serve(ln) }()
+	t.Cleanup(func() { _ = srv.Close() })
+	return "https://" + ln.Addr().String()
+}
+
+func newClient(ca *testCA, certs ...tls.Certificate) *http.Client {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certs}}}
+}
+
+func get(client *http.Client, url string) (string, *tls.ConnectionState, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	return string(body), resp.TLS, err
+}
+
+func newTestServer(t *testing.T, cfg Config, ca *testCA) *Server {
+	t.Helper()
+	dir := t.TempDir()
+	certPEM, keyPEM := ca.issue(t, 2, pkix.Name{CommonName: "bff"}, x509.ExtKeyUsageServerAuth)
+	cfg.CertFile, cfg.KeyFile, cfg.ClientCAFile = filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+	writeFile(t, cfg.CertFile, certPEM)
+	writeFile(t, cfg.KeyFile, keyPEM)
+	writeFile(t, cfg.ClientCAFile, ca.pem)
+	s, err := New(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	return s
+}
+
+func TestServer_Reload(t *testing.T) {
+	ca := newTestCA(t)
+	s := newTestServer(t, Config{}, ca)
+	url := serve(t, s)
+	client := newClient(ca)
+
+	_, state, err := get(client, url)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), state.PeerCertificates[0].SerialNumber.Int64())
+	assert.Equal(t, uint16(tls.VersionTLS13), state.Version)
+
+	// A rotation is served to new connections; a half-written one keeps the current certificate
+	certPEM, keyPEM := ca.issue(t, 3, pkix.Name{CommonName: "bff"}, x509.ExtKeyUsageServerAuth)
+	writeFile(t, s.cfg.CertFile, certPEM)
+	assert.Error(t, s.Reload())
+	writeFile(t, s.cfg.KeyFile, keyPEM)
+	require.NoError(t, s.Reload())
+
+	client.CloseIdleConnections()
+	_, state, err = get(client, url)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), state.PeerCertificates[0].SerialNumber.Int64())
+}
+
+func TestServer_MinVersion(t *testing.T) {
+	ca := newTestCA(t)
+	client := newClient(ca)
+	client.Transport.(*http.Transport).TLSClientConfig.MaxVersion = tls.VersionTLS12
+
+	_, _, err := get(client, serve(t, newTestServer(t, Config{}, ca)))
+	assert.Error(t, err, "TLS 1.2 is refused by default")
+
+	_, state, err := get(client, serve(t, newTestServer(t, Config{MinVersion: Version12}, ca)))
+	require.NoError(t, err)
+	assert.Contains(t, CipherSuites, state.CipherSuite)
+}
+
+func TestServer_ClientAuth(t *testing.T) {
+	ca := newTestCA(t)
+	certPEM, keyPEM := ca.issue(t, 10, pkix.Name{CommonName: "alice", Organization: []string{"admins"}}, x509.ExtKeyUsageClientAuth)
+	clientCert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	otherCA := newTestCA(t)
+	otherPEM, otherKeyPEM := otherCA.issue(t, 11, pkix.Name{CommonName: "mallory"}, x509.ExtKeyUsageClientAuth)
+	otherCert, err := tls.X509KeyPair(otherPEM, otherKeyPEM)
+	require.NoError(t, err)
+
+	t.Run("require", func(t *testing.T) {
+		url := serve(t, newTestServer(t, Config{ClientAuth: ClientAuthRequire}, ca))
+
+		user, _, err := get(newClient(ca, clientCert), url)
+		require.NoError(t, err)
+		assert.Equal(t, "alice", user)
+
+		_, _, err = get(newClient(ca), url)
+		assert.Error(t, err)
+		_, _, err = get(newClient(ca, otherCert), url)
+		assert.Error(t, err)
+	})
+
+	t.Run("optional", func(t *testing.T) {
+		s := newTestServer(t, Config{ClientAuth: ClientAuthOptional}, ca)
+		url := serve(t, s)
+
+		user, _, err := get(newClient(ca), url)
+		require.NoError(t, err)
+		assert.Empty(t, user)
+		_, _, err = get(newClient(ca, otherCert), url)
+		assert.Error(t, err, "certificates that are sent are verified")
+
+		// Rotated client CAs apply to new connections
+		writeFile(t, s.cfg.ClientCAFile, append(append([]byte{}, ca.pem...), otherCA.pem...))
+		require.NoError(t, s.Reload())
+		user, _, err = get(newClient(ca, otherCert), url)
+		require.NoError(t, err)
+		assert.Equal(t, "mallory", user)
+	})
+}
+
+func TestNew_Invalid(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing")
+
+	_, err := New(Config{CertFile: missing, KeyFile: missing}, logger)
+	assert.ErrorContains(t, err, "certificate")
+	_, err = New(Config{CertFile: missing, KeyFile: missing, ClientAuth: ClientAuthRequire}, logger)
+	assert.ErrorContains(t, err, "client CA file is required")
+	_, err = New(Config{CertFile: missing, KeyFile: missing, ClientAuth: "always"}, logger)
+	assert.ErrorContains(t, err, "client certificate policy")
+}
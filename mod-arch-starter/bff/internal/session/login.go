@@ -0,0 +1,101 @@
+package session
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// loginTimeout bounds how long a sign-in may take at the provider.
+const loginTimeout = 10 * time.Minute
+
+// loginState is the state of a sign-in in progress, sealed in the login cookie between the
+// redirect to the provider and its callback.
+type loginState struct {
+	State    string    `json:"state"`
+	Verifier string    `json:"verifier"`
+	ReturnTo string    `json:"returnTo"`
+	Expires  time.Time `json:"expires"`
+}
+
+// ErrLoginFailed is returned (wrapped) by CompleteLogin when the callback doesn't complete a
+// sign-in of this browser, or the provider refused it.
+var ErrLoginFailed = errors.New("sign-in failed")
+
+// BeginLogin starts a sign-in and returns the URL of the provider to redirect the browser to.
+// Once signed in, CompleteLogin redirects it back to returnTo, a path of this origin.
+func (m *Manager) BeginLogin(w http.ResponseWriter, r *http.Request, returnTo string) (string, error) {
+	state := loginState{
+		State:    randomString(),
+		Verifier: randomString(),
+		ReturnTo: SafeReturnPath(returnTo),
+		Expires:  m.now().Add(loginTimeout),
+	}
+	authURL, err := m.cfg.Provider.AuthCodeURL(r.Context(), state.State, state.Verifier)
+	if err != nil {
+		return "", fmt.Errorf("failed to build the sign-in URL: %w", err)
+	}
+	sealed, err := m.cfg.Sealer.Seal(m.cfg.Login.Name, state)
+	if err != nil {
+		return "", err
+	}
+	m.cfg.Login.set(w, sealed)
+	return authURL, nil
+}
+
+// CompleteLogin completes the sign-in of the callback request r: it checks the state, exchanges
+// the authorization code for tokens and starts their session. It returns the path to redirect
+// the browser to.
+func (m *Manager) CompleteLogin(w http.ResponseWriter, r *http.Request) (string, error) {
+	cookie, err := r.Cookie(m.cfg.Login.Name)
+	if err != nil {
+		return "", fmt.Errorf("%w: no sign-in in progress", ErrLoginFailed)
+	}
+	// The state is used once
+	m.cfg.Login.clear(w)
+	var state loginState
+	if err := m.cfg.Sealer.Open(m.cfg.Login.Name, cookie.Value, &state); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrLoginFailed, err)
+	}
+	if m.now().After(state.Expires) {
+		return "", fmt.Errorf("%w: the sign-in timed out", ErrLoginFailed)
+	}
+
+	query := r.URL.Query()
+	if subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(state.State)) != 1 {
+		return "", fmt.Errorf("%w: state mismatch", ErrLoginFailed)
+	}
+	if code := query.Get("error"); code != "" {
+		return "", fmt.Errorf("%w: the provider answered %s %s", ErrLoginFailed, code, query.Get("error_description"))
+	}
+	code := query.Get("code")
+	if code == "" {
+		return "", fmt.Errorf("%w: missing authorization code", ErrLoginFailed)
+	}
+
+	tokens, err := m.cfg.Provider.Exchange(r.Context(), code, state.Verifier)
+	if err != nil {
+		var tokenErr *TokenError
+		if errors.As(err, &tokenErr) {
+			return "", fmt.Errorf("%w: %v", ErrLoginFailed, err)
+		}
+		return "", fmt.Errorf("failed to exchange the authorization code: %w", err)
+	}
+	if _, err := m.Start(w, r, tokens); err != nil {
+		return "", err
+	}
+	return state.ReturnTo, nil
+}
+
+// randomString returns 32 random bytes, base64url-encoded: a state or PKCE verifier
+// (RFC 7636 requires 43 to 128 characters).
+func randomString() string {
+	b := make([]byte, 32)
+	// crypto/rand.Read never fails since Go 1.24
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,270 @@
+package session
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/oidc"
+)
+
+// OAuthConfig configures an OAuthClient.
+type OAuthConfig struct {
+	// IssuerURL is the OIDC issuer whose discovered endpoints are used.
+	IssuerURL string
+	ClientID  string
+	// ClientSecret authenticates the client at the token endpoint; empty for public clients.
+	ClientSecret string
+	// RedirectURL is the callback URL of the BFF registered at the provider.
+	RedirectURL string
+	Scopes      []string
+	// PostLogoutRedirectURL is where the provider sends the browser back once its session
+	// ended, when set.
+	PostLogoutRedirectURL string
+	// HTTPClient calls the provider. Defaults to a client with a 10 second timeout.
+	HTTPClient *http.Client
+}
+
+// TokenError is an error answered by the token endpoint (RFC 6749 section 5.2), e.g.
+// invalid_grant for an expired or revoked refresh token.
+type TokenError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *TokenError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("token endpoint: %s: %s", e.Code, e.Description)
+	}
+	return "token endpoint: " + e.Code
+}
+
+// OAuthClient is the Provider of an OIDC issuer: it signs users in with the authorization code
+// flow and PKCE, refreshes their tokens and revokes them. The endpoints of the issuer are
+// discovered on first use.
+type OAuthClient struct {
+	cfg OAuthConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	metadata *oidc.ProviderMetadata
+}
+
+// NewOAuthClient returns the client of cfg.
+func NewOAuthClient(cfg OAuthConfig) *OAuthClient {
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &OAuthClient{cfg: cfg, now: time.Now}
+}
+
+// endpoints returns the metadata of the issuer, discovered again after failures.
+func (c *OAuthClient) endpoints(ctx context.Context) (*oidc.ProviderMetadata, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.metadata != nil {
+		return c.metadata, nil
+	}
+	metadata, err := oidc.Discover(ctx, c.cfg.IssuerURL, c.cfg.HTTPClient)
+	if err != nil {
+		return nil, err
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" {
+		return nil, errors.New("OIDC discovery document has no authorization or token endpoint")
+	}
+	c.metadata = metadata
+	return metadata, nil
+}
+
+func (c *OAuthClient) AuthCodeURL(ctx context.Context, state, verifier string) (string, error) {
+	metadata, err := c.endpoints(ctx)
+	if err != nil {
+		return "", err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+	return withQuery(metadata.AuthorizationEndpoint, url.Values{
+		"response_type":         {"code"},
+		"client_id":             {c.cfg.ClientID},
+		"redirect_uri":          {c.cfg.RedirectURL},
+		"scope":                 {strings.Join(c.cfg.Scopes, " ")},
+		"state":                 {state},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	})
+}
+
+func (c *OAuthClient) Exchange(ctx context.Context, code, verifier string) (Tokens, error) {
+	return c.token(ctx, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {c.cfg.RedirectURL},
+		"code_verifier": {verifier},
+	})
+}
+
+func (c *OAuthClient) Refresh(ctx context.Context, refreshToken string) (Tokens, error) {
+	return c.token(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+}
+
+// Revoke revokes the refresh token, then the access token (RFC 7009). Providers without a
+// revocation endpoint let them expire.
+func (c *OAuthClient) Revoke(ctx context.Context, tokens Tokens) error {
+	metadata, err := c.endpoints(ctx)
+	if err != nil {
+		return err
+	}
+	if metadata.RevocationEndpoint == "" {
+		return nil
+	}
+	var errs []error
+	for _, revoked := range []struct{ hint, token string }{
+		{"refresh_token", tokens.RefreshToken},
+		{"access_token", tokens.AccessToken},
+	} {
+		hint, token := revoked.hint, revoked.token
+		if token == "" {
+			continue
+		}
+		resp, err := c.post(ctx, metadata.RevocationEndpoint, url.Values{"token": {token}, "token_type_hint": {hint}})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			errs = append(errs, fmt.Errorf("revocation of the %s returned HTTP %d", hint, resp.StatusCode))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *OAuthClient) EndSessionURL(ctx context.Context, idToken string) (string, error) {
+	metadata, err := c.endpoints(ctx)
+	if err != nil {
+		return "", err
+	}
+	if metadata.EndSessionEndpoint == "" {
+		return "", nil
+	}
+	query := url.Values{"client_id": {c.cfg.ClientID}}
+	if idToken != "" {
+		query.Set("id_token_hint", idToken)
+	}
+	if c.cfg.PostLogoutRedirectURL != "" {
+		query.Set("post_logout_redirect_uri", c.cfg.PostLogoutRedirectURL)
+	}
+	return withQuery(metadata.EndSessionEndpoint, query)
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	IDToken      string `json:"id_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// token requests tokens of the grant form from the token endpoint.
+func (c *OAuthClient) token(ctx context.Context, form url.Values) (Tokens, error) {
+	metadata, err := c.endpoints(ctx)
+	if err != nil {
+		return Tokens{}, err
+	}
+	resp, err := c.post(ctx, metadata.TokenEndpoint, form)
+	if err != nil {
+		return Tokens{}, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return Tokens{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		tokenErr := &TokenError{}
+		if json.Unmarshal(body, tokenErr) == nil && tokenErr.Code != "" {
+			return Tokens{}, tokenErr
+		}
+		return Tokens{}, fmt.Errorf("token endpoint returned HTTP %d", resp.StatusCode)
+	}
+
+	var token tokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return Tokens{}, fmt.Errorf("invalid token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return Tokens{}, errors.New("token response has no access token")
+	}
+	tokens := Tokens{AccessToken: token.AccessToken, RefreshToken: token.RefreshToken, IDToken: token.IDToken}
+	if token.ExpiresIn > 0 {
+		tokens.Expiry = c.now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	if exp, ok := jwtExpiry(token.IDToken); ok && (tokens.Expiry.IsZero() || exp.Before(tokens.Expiry)) {
+		tokens.Expiry = exp
+	}
+	return tokens, nil
+}
+
+// post posts form to endpoint, authenticating the client with HTTP Basic authentication when
+// it has a secret (client_secret_basic), or by its ID otherwise.
+func (c *OAuthClient) post(ctx context.Context, endpoint string, form url.Values) (*http.Response, error) {
+	if c.cfg.ClientSecret == "" {
+		form.Set("client_id", c.cfg.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.cfg.ClientSecret != "" {
+		// The credentials are form-encoded first (RFC 6749 section 2.3.1)
+		req.SetBasicAuth(url.QueryEscape(c.cfg.ClientID), url.QueryEscape(c.cfg.ClientSecret))
+	}
+	return c.cfg.HTTPClient.Do(req)
+}
+
+// withQuery adds query to the query of endpoint.
+func withQuery(endpoint string, query url.Values) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	values := u.Query()
+	for key, v := range query {
+		values[key] = v
+	}
+	u.RawQuery = values.Encode()
+	return u.String(), nil
+}
+
+// jwtExpiry returns the exp claim of a JWT, without verifying it: the token is verified where it
+// is used, this only schedules its refresh.
+func jwtExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	var claims struct {
+		Exp float64 `json:"exp"`
+	}
+	if json.Unmarshal(payload, &claims) != nil || claims.Exp <= 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(claims.Exp), 0), true
+}
@@ -0,0 +1,153 @@
+package session
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/integrations/oidc"
+)
+
+// testIdP is an authorization server issuing the code "code" for the PKCE challenge it was
+// given, and refreshing the refresh token "refresh".
+type testIdP struct {
+	server    *httptest.Server
+	challenge string
+
+	mu      sync.Mutex
+	revoked []string
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	t.Helper()
+	idp := &testIdP{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(oidc.ProviderMetadata{
+			Issuer:                idp.server.URL,
+			AuthorizationEndpoint: idp.server.URL + "/auth?prompt=login",
+			TokenEndpoint:         idp.server.URL + "/token",
+			RevocationEndpoint:    idp.server.URL + "/revoke",
+			EndSessionEndpoint:    idp.server.URL + "/logout",
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		// The credentials are form-encoded (RFC 6749 section 2.3.1)
+		id, secret, _ := r.BasicAuth()
+		id, _ = url.QueryUnescape(id)
+		secret, _ = url.QueryUnescape(secret)
+		if id != "bff" || secret != "s3cr3t/+" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.PostFormValue("grant_type") {
+		case "authorization_code":
+			verifier := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+			if r.PostFormValue("code") != "code" || base64.RawURLEncoding.EncodeToString(verifier[:]) != idp.challenge {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"invalid_grant","error_description":"bad code"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"access_token":"access","refresh_token":"refresh","id_token":"` + testJWT(time.Now().Add(5*time.Minute)) + `","expires_in":3600}`))
+		case "refresh_token":
+			if r.PostFormValue("refresh_token") != "refresh" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"access_token":"access-2","refresh_token":"refresh-2","expires_in":3600}`))
+		}
+	})
+	mux.HandleFunc("/revoke", func(w http.ResponseWriter, r *http.Request) {
+		idp.mu.Lock()
+		idp.revoked = append(idp.revoked, r.PostFormValue("token_type_hint")+"="+r.PostFormValue("token"))
+		idp.mu.Unlock()
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func testJWT(exp time.Time) string {
+	claims, _ := json.Marshal(map[string]any{"sub": "alice", "exp": exp.Unix()})
+	return "e30." + base64.RawURLEncoding.EncodeToString(claims) + ".sig"
+}
+
+func newTestOAuthClient(idp *testIdP) *OAuthClient {
+	return NewOAuthClient(OAuthConfig{
+		IssuerURL:             idp.server.URL,
+		ClientID:              "bff",
+		ClientSecret:          "s3cr3t/+",
+		RedirectURL:           "https://bff.example.com/api/v1/login/callback",
+		Scopes:                []string{"openid", "email"},
+		PostLogoutRedirectURL: "https://bff.example.com/",
+	})
+}
+
+func TestOAuthClient_AuthorizationCode(t *testing.T) {
+	idp := newTestIdP(t)
+	client := newTestOAuthClient(idp)
+	ctx := context.Background()
+
+	authURL, err := client.AuthCodeURL(ctx, "state", "verifier-of-the-sign-in")
+	require.NoError(t, err)
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	query := u.Query()
+	assert.Equal(t, "login", query.Get("prompt"), "the query of the endpoint is kept")
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, "openid email", query.Get("scope"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	idp.challenge = query.Get("code_challenge")
+
+	_, err = client.Exchange(ctx, "code", "another-verifier")
+	var tokenErr *TokenError
+	require.ErrorAs(t, err, &tokenErr, "the PKCE verifier is checked")
+	assert.Equal(t, "invalid_grant", tokenErr.Code)
+
+	tokens, err := client.Exchange(ctx, "code", "verifier-of-the-sign-in")
+	require.NoError(t, err)
+	assert.Equal(t, "access", tokens.AccessToken)
+	assert.Equal(t, "refresh", tokens.RefreshToken)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), tokens.Expiry, 2*time.Second, "the ID token expires first")
+}
+
+func TestOAuthClient_RefreshAndRevoke(t *testing.T) {
+	idp := newTestIdP(t)
+	client := newTestOAuthClient(idp)
+	ctx := context.Background()
+
+	tokens, err := client.Refresh(ctx, "refresh")
+	require.NoError(t, err)
+	assert.Equal(t, "access-2", tokens.AccessToken)
+	assert.Equal(t, "refresh-2", tokens.RefreshToken)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), tokens.Expiry, 2*time.Second)
+
+	_, err = client.Refresh(ctx, "revoked")
+	var tokenErr *TokenError
+	require.ErrorAs(t, err, &tokenErr)
+	assert.Equal(t, "invalid_grant", tokenErr.Code)
+
+	require.NoError(t, client.Revoke(ctx, tokens))
+	assert.Equal(t, []string{"refresh_token=refresh-2", "access_token=access-2"}, idp.revoked)
+
+	endURL, err := client.EndSessionURL(ctx, "id-token")
+	require.NoError(t, err)
+	u, err := url.Parse(endURL)
+	require.NoError(t, err)
+	assert.Equal(t, "/logout", u.Path)
+	assert.Equal(t, "id-token", u.Query().Get("id_token_hint"))
+	assert.Equal(t, "https://bff.example.com/", u.Query().Get("post_logout_redirect_uri"))
+}
@@ -0,0 +1,299 @@
+// Package session keeps the OAuth tokens of signed-in browser users, for deployments where the
+// BFF authenticates users itself rather than behind an authenticating proxy. Sessions are kept
+// in an encrypted cookie or in a server-side store addressed by a cookie, are refreshed before
+// their tokens expire, and end after an idle or an absolute timeout.
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Names of the stores of the -session-store flag.
+const (
+	StoreCookie = "cookie"
+	StoreMemory = "memory"
+)
+
+const (
+	// touchInterval bounds how often the activity of a session is saved, so the cookie store
+	// doesn't rewrite the cookie on every request. The idle timeout is as precise.
+	touchInterval = time.Minute
+
+	// refreshGrace is how long the tokens of a refresh are reused for the same refresh token:
+	// requests sent with the cookie of before a refresh don't refresh again with a refresh token
+	// the provider may have rotated.
+	refreshGrace = 30 * time.Second
+)
+
+// ErrNoSession is returned when a request has no session.
+var ErrNoSession = errors.New("no session")
+
+// ErrExpired is returned when the session of a request ended: it timed out, or its tokens
+// expired and could not be refreshed.
+var ErrExpired = errors.New("session expired")
+
+// IsValidStore reports whether name is a supported session store.
+func IsValidStore(name string) bool {
+	return name == StoreCookie || name == StoreMemory
+}
+
+// Tokens are the OAuth tokens of a session.
+type Tokens struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+	IDToken      string `json:"idToken,omitempty"`
+	// Expiry is when the first of the access and ID tokens expires; zero when unknown.
+	Expiry time.Time `json:"expiry,omitzero"`
+}
+
+// Session is the state of a signed-in user.
+type Session struct {
+	// ID identifies the session in a server-side store; it is empty in cookies.
+	ID        string    `json:"-"`
+	Tokens    Tokens    `json:"tokens"`
+	CreatedAt time.Time `json:"createdAt"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+// Token returns the bearer token authenticating the user: the ID token, which the Kubernetes API
+// server OIDC authenticator expects, or the access token when the provider issued none.
+func (s *Session) Token() string {
+	if s.Tokens.IDToken != "" {
+		return s.Tokens.IDToken
+	}
+	return s.Tokens.AccessToken
+}
+
+// Timeouts end sessions.
+type Timeouts struct {
+	// Idle ends sessions without requests for this long.
+	Idle time.Duration
+	// Absolute ends sessions this long after the sign-in, refreshed or not.
+	Absolute time.Duration
+}
+
+// Expired reports whether s timed out at now.
+func (t Timeouts) Expired(s *Session, now time.Time) bool {
+	return (t.Idle > 0 && now.Sub(s.LastSeen) > t.Idle) ||
+		(t.Absolute > 0 && now.Sub(s.CreatedAt) > t.Absolute)
+}
+
+// Store keeps sessions between requests.
+type Store interface {
+	// Load returns the session of r, ErrNoSession without one.
+	Load(r *http.Request) (*Session, error)
+	// Save keeps s, setting the cookie of the session on w.
+	Save(w http.ResponseWriter, r *http.Request, s *Session) error
+	// Delete removes the session of r and clears its cookie.
+	Delete(w http.ResponseWriter, r *http.Request) error
+}
+
+// Provider is the OAuth authorization server of the sessions (see OAuthClient).
+type Provider interface {
+	// AuthCodeURL returns the URL the browser signs in at, for the authorization code flow.
+	AuthCodeURL(ctx context.Context, state, verifier string) (string, error)
+	// Exchange returns the tokens of an authorization code.
+	Exchange(ctx context.Context, code, verifier string) (Tokens, error)
+	// Refresh returns new tokens for a refresh token.
+	Refresh(ctx context.Context, refreshToken string) (Tokens, error)
+	// Revoke revokes the tokens at the provider, when it supports revocation.
+	Revoke(ctx context.Context, tokens Tokens) error
+	// EndSessionURL returns the URL ending the session of an ID token at the provider, empty
+	// when it doesn't support RP-initiated logout.
+	EndSessionURL(ctx context.Context, idToken string) (string, error)
+}
+
+// Config configures a Manager.
+type Config struct {
+	Store    Store
+	Provider Provider
+	Timeouts Timeouts
+	// RefreshBefore refreshes the tokens this long before they expire.
+	RefreshBefore time.Duration
+	// Login is the cookie of the state of sign-ins in progress, sealed by Sealer.
+	Login  CookieOptions
+	Sealer *Sealer
+}
+
+// Manager starts, loads, refreshes and ends the sessions of a Store. It is safe for concurrent
+// use.
+type Manager struct {
+	cfg    Config
+	logger *slog.Logger
+	now    func() time.Time
+
+	refreshes singleflight.Group
+	mu        sync.Mutex
+	refreshed map[string]refreshResult
+}
+
+type refreshResult struct {
+	tokens Tokens
+	at     time.Time
+}
+
+// NewManager returns the Manager of cfg.
+func NewManager(cfg Config, logger *slog.Logger) *Manager {
+	return &Manager{cfg: cfg, logger: logger, now: time.Now, refreshed: map[string]refreshResult{}}
+}
+
+// Start starts a session of tokens, replacing the current session of r, if any.
+func (m *Manager) Start(w http.ResponseWriter, r *http.Request, tokens Tokens) (*Session, error) {
+	if err := m.cfg.Store.Delete(w, r); err != nil {
+		m.logger.Debug("failed to delete the previous session", "error", err)
+	}
+	now := m.now()
+	s := &Session{Tokens: tokens, CreatedAt: now, LastSeen: now}
+	if err := m.cfg.Store.Save(w, r, s); err != nil {
+		return nil, fmt.Errorf("failed to save the session: %w", err)
+	}
+	return s, nil
+}
+
+// Load returns the session of r, refreshing its tokens when they are about to expire, and saves
+// its activity. Sessions that ended are deleted and ErrExpired returned; ErrNoSession is returned
+// without a session. When the session can't be saved, it is returned with the refreshed tokens
+// and the error is logged.
+func (m *Manager) Load(w http.ResponseWriter, r *http.Request) (*Session, error) {
+	s, err := m.cfg.Store.Load(r)
+	if err != nil {
+		return nil, err
+	}
+	now := m.now()
+	if m.cfg.Timeouts.Expired(s, now) {
+		return nil, m.expire(w, r, "timed out")
+	}
+
+	changed := false
+	if expiry := s.Tokens.Expiry; !expiry.IsZero() && !now.Before(expiry.Add(-m.cfg.RefreshBefore)) {
+		if s.Tokens.RefreshToken == "" {
+			if !now.Before(expiry) {
+				return nil, m.expire(w, r, "tokens expired")
+			}
+		} else {
+			tokens, err := m.refresh(r.Context(), s.Tokens)
+			switch {
+			case err == nil:
+				s.Tokens, changed = tokens, true
+			case now.Before(expiry):
+				// Retried by the next requests
+				m.logger.Warn("failed to refresh the session tokens", "error", err)
+			default:
+				m.logger.Info("ending a session whose tokens could not be refreshed", "error", err)
+				return nil, m.expire(w, r, "tokens expired")
+			}
+		}
+	}
+
+	if changed || now.Sub(s.LastSeen) >= touchInterval {
+		s.LastSeen = now
+		if err := m.cfg.Store.Save(w, r, s); err != nil {
+			m.logger.Error("failed to save the session", "error", err)
+		}
+	}
+	return s, nil
+}
+
+// End revokes the tokens of the session of r at the provider and deletes it. It returns the
+// session that ended, nil without one, so the provider session can be ended too (see
+// Provider.EndSessionURL).
+func (m *Manager) End(w http.ResponseWriter, r *http.Request) (*Session, error) {
+	s, err := m.cfg.Store.Load(r)
+	if errors.Is(err, ErrNoSession) {
+		// Clear leftover cookies all the same
+		return nil, m.cfg.Store.Delete(w, r)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := m.cfg.Provider.Revoke(r.Context(), s.Tokens); err != nil {
+		// The session ends all the same; the tokens expire on their own
+		m.logger.Warn("failed to revoke the session tokens", "error", err)
+	}
+	return s, m.cfg.Store.Delete(w, r)
+}
+
+// Provider returns the provider of the sessions.
+func (m *Manager) Provider() Provider {
+	return m.cfg.Provider
+}
+
+func (m *Manager) expire(w http.ResponseWriter, r *http.Request, reason string) error {
+	if err := m.cfg.Store.Delete(w, r); err != nil {
+		m.logger.Warn("failed to delete an expired session", "error", err)
+	}
+	return fmt.Errorf("%w: %s", ErrExpired, reason)
+}
+
+// refresh refreshes tokens once for concurrent requests, and reuses the result for the requests
+// that follow within refreshGrace.
+func (m *Manager) refresh(ctx context.Context, tokens Tokens) (Tokens, error) {
+	m.mu.Lock()
+	recent, ok := m.refreshed[tokens.RefreshToken]
+	m.mu.Unlock()
+	if ok && m.now().Sub(recent.at) < refreshGrace {
+		return recent.tokens, nil
+	}
+
+	v, err, _ := m.refreshes.Do(tokens.RefreshToken, func() (any, error) {
+		// Not canceled with the request that started it, as the others share its result
+		refreshed, err := m.cfg.Provider.Refresh(context.WithoutCancel(ctx), tokens.RefreshToken)
+		if err != nil {
+			return Tokens{}, err
+		}
+		if refreshed.RefreshToken == "" {
+			refreshed.RefreshToken = tokens.RefreshToken
+		}
+		now := m.now()
+		m.mu.Lock()
+		for token, result := range m.refreshed {
+			if now.Sub(result.at) >= refreshGrace {
+				delete(m.refreshed, token)
+			}
+		}
+		m.refreshed[tokens.RefreshToken] = refreshResult{tokens: refreshed, at: now}
+		m.mu.Unlock()
+		return refreshed, nil
+	})
+	return v.(Tokens), err
+}
+
+type contextKey struct{}
+
+type contextValue struct {
+	session *Session
+	err     error
+}
+
+// NewContext returns a context carrying the session of a request, or the error loading it.
+func NewContext(ctx context.Context, s *Session, err error) context.Context {
+	return context.WithValue(ctx, contextKey{}, contextValue{session: s, err: err})
+}
+
+// FromContext returns the session of the context, or the error loading it: ErrNoSession when
+// sessions were not loaded.
+func FromContext(ctx context.Context) (*Session, error) {
+	value, ok := ctx.Value(contextKey{}).(contextValue)
+	if !ok {
+		return nil, ErrNoSession
+	}
+	return value.session, value.err
+}
+
+// SafeReturnPath returns path when it is a path of this origin, "/" otherwise, so sign-ins
+// can't redirect to other sites.
+func SafeReturnPath(path string) string {
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") || strings.HasPrefix(path, "/\\") {
+		return "/"
+	}
+	return path
+}
@@ -0,0 +1,288 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProvider refreshes tokens into "refreshed-<n>" tokens valid for an hour.
+type fakeProvider struct {
+	now        func() time.Time
+	refreshes  atomic.Int32
+	refreshErr error
+	revoked    []string
+	mu         sync.Mutex
+}
+
+func (p *fakeProvider) AuthCodeURL(_ context.Context, state, _ string) (string, error) {
+	return "https://idp.example.com/auth?state=" + state, nil
+}
+
+func (p *fakeProvider) Exchange(_ context.Context, code, _ string) (Tokens, error) {
+	return Tokens{AccessToken: "access-" + code, RefreshToken: "refresh-" + code, Expiry: p.now().Add(time.Hour)}, nil
+}
+
+func (p *fakeProvider) Refresh(context.Context, string) (Tokens, error) {
+	n := p.refreshes.Add(1)
+	if p.refreshErr != nil {
+		return Tokens{}, p.refreshErr
+	}
+	// Slow enough for concurrent requests to overlap
+	time.Sleep(10 * time.Millisecond)
+	return Tokens{AccessToken: "refreshed-" + string(rune('0'+n)), Expiry: p.now().Add(time.Hour)}, nil
+}
+
+func (p *fakeProvider) Revoke(_ context.Context, tokens Tokens) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.revoked = append(p.revoked, tokens.RefreshToken)
+	return nil
+}
+
+func (p *fakeProvider) EndSessionURL(context.Context, string) (string, error) { return "", nil }
+
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+func newTestManager(t *testing.T) (*Manager, *fakeProvider, *clock) {
+	t.Helper()
+	now := &clock{now: time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)}
+	sealer, err := NewSealer("a test secret of at least 32 bytes")
+	require.NoError(t, err)
+	provider := &fakeProvider{now: now.Now}
+	m := NewManager(Config{
+		Store:         NewCookieStore(CookieOptions{Name: "session", Path: "/"}, sealer),
+		Provider:      provider,
+		Timeouts:      Timeouts{Idle: 30 * time.Minute, Absolute: 8 * time.Hour},
+		RefreshBefore: time.Minute,
+		Login:         CookieOptions{Name: "session_login", Path: "/"},
+		Sealer:        sealer,
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	m.now = now.Now
+	return m, provider, now
+}
+
+// browser keeps the cookies set by the responses it was given, like a browser would.
+type browser struct {
+	cookies map[string]*http.Cookie
+}
+
+func (b *browser) request(target string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	for _, c := range b.cookies {
+		r.AddCookie(c)
+	}
+	return r
+}
+
+func (b *browser) keep(rr *httptest.ResponseRecorder) {
+	for _, c := range rr.Result().Cookies() {
+		if c.MaxAge < 0 {
+			delete(b.cookies, c.Name)
+		} else {
+			b.cookies[c.Name] = c
+		}
+	}
+}
+
+func signIn(t *testing.T, m *Manager) *browser {
+	t.Helper()
+	b := &browser{cookies: map[string]*http.Cookie{}}
+	rr := httptest.NewRecorder()
+	_, err := m.Start(rr, b.request("/"), Tokens{AccessToken: "access", RefreshToken: "refresh", Expiry: m.now().Add(time.Hour)})
+	require.NoError(t, err)
+	b.keep(rr)
+	return b
+}
+
+func load(m *Manager, b *browser) (*Session, error) {
+	rr := httptest.NewRecorder()
+	s, err := m.Load(rr, b.request("/api/v1/user"))
+	b.keep(rr)
+	return s, err
+}
+
+func TestManager_IdleAndAbsoluteTimeouts(t *testing.T) {
+	m, _, now := newTestManager(t)
+	b := signIn(t, m)
+
+	// Activity keeps the session alive past the idle timeout
+	for range 4 {
+		now.Advance(20 * time.Minute)
+		_, err := load(m, b)
+		require.NoError(t, err)
+	}
+
+	now.Advance(31 * time.Minute)
+	_, err := load(m, b)
+	assert.ErrorIs(t, err, ErrExpired)
+	assert.Empty(t, b.cookies, "the cookie of an expired session is cleared")
+	_, err = load(m, b)
+	assert.ErrorIs(t, err, ErrNoSession)
+
+	b = signIn(t, m)
+	m.cfg.Timeouts.Idle = 0
+	now.Advance(8*time.Hour + time.Second)
+	_, err = load(m, b)
+	assert.ErrorIs(t, err, ErrExpired, "the absolute timeout ends active sessions")
+}
+
+func TestManager_Refresh(t *testing.T) {
+	m, provider, now := newTestManager(t)
+	m.cfg.Timeouts.Idle = 0
+	b := signIn(t, m)
+
+	now.Advance(58 * time.Minute)
+	s, err := load(m, b)
+	require.NoError(t, err)
+	assert.Equal(t, "access", s.Tokens.AccessToken, "not refreshed before RefreshBefore")
+
+	now.Advance(90 * time.Second)
+	s, err = load(m, b)
+	require.NoError(t, err)
+	assert.Equal(t, "refreshed-1", s.Tokens.AccessToken)
+	assert.Equal(t, "refresh", s.Tokens.RefreshToken, "the refresh token is kept when none is issued")
+
+	s, err = load(m, b)
+	require.NoError(t, err)
+	assert.Equal(t, "refreshed-1", s.Tokens.AccessToken, "the refreshed tokens are saved")
+	assert.Equal(t, int32(1), provider.refreshes.Load())
+}
+
+func TestManager_ConcurrentRefresh(t *testing.T) {
+	m, provider, now := newTestManager(t)
+	m.cfg.Timeouts.Idle = 0
+	b := signIn(t, m)
+	now.Advance(time.Hour)
+
+	// Requests sent with the same cookie refresh once
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s, err := m.Load(httptest.NewRecorder(), b.request("/api/v1/user"))
+			if assert.NoError(t, err) {
+				assert.Equal(t, "refreshed-1", s.Tokens.AccessToken)
+			}
+		}()
+	}
+	wg.Wait()
+	_, err := load(m, &browser{cookies: b.cookies})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), provider.refreshes.Load())
+}
+
+func TestManager_RefreshFailure(t *testing.T) {
+	m, provider, now := newTestManager(t)
+	m.cfg.Timeouts.Idle = 0
+	b := signIn(t, m)
+	provider.refreshErr = &TokenError{Code: "invalid_grant"}
+
+	now.Advance(59*time.Minute + 30*time.Second)
+	s, err := load(m, b)
+	require.NoError(t, err, "tokens still valid are used while the refresh fails")
+	assert.Equal(t, "access", s.Tokens.AccessToken)
+
+	now.Advance(time.Minute)
+	_, err = load(m, b)
+	assert.ErrorIs(t, err, ErrExpired)
+	assert.Empty(t, b.cookies)
+}
+
+func TestManager_End(t *testing.T) {
+	m, provider, _ := newTestManager(t)
+	b := signIn(t, m)
+
+	rr := httptest.NewRecorder()
+	s, err := m.End(rr, b.request("/api/v1/logout"))
+	require.NoError(t, err)
+	require.NotNil(t, s)
+	b.keep(rr)
+	assert.Equal(t, []string{"refresh"}, provider.revoked)
+	assert.Empty(t, b.cookies)
+
+	s, err = m.End(httptest.NewRecorder(), b.request("/api/v1/logout"))
+	assert.NoError(t, err, "ending no session succeeds")
+	assert.Nil(t, s)
+}
+
+func TestManager_Login(t *testing.T) {
+	m, _, now := newTestManager(t)
+	b := &browser{cookies: map[string]*http.Cookie{}}
+
+	rr := httptest.NewRecorder()
+	authURL, err := m.BeginLogin(rr, b.request("/api/v1/login"), "/models?page=2")
+	require.NoError(t, err)
+	b.keep(rr)
+	require.Contains(t, b.cookies, "session_login")
+	state := authURL[len("https://idp.example.com/auth?state="):]
+
+	_, err = m.CompleteLogin(httptest.NewRecorder(), b.request("/api/v1/login/callback?code=c&state=forged"))
+	assert.ErrorIs(t, err, ErrLoginFailed)
+
+	rr = httptest.NewRecorder()
+	returnTo, err := m.CompleteLogin(rr, b.request("/api/v1/login/callback?code=c&state="+state))
+	require.NoError(t, err)
+	b.keep(rr)
+	assert.Equal(t, "/models?page=2", returnTo)
+	assert.NotContains(t, b.cookies, "session_login", "the state is used once")
+	s, err := load(m, b)
+	require.NoError(t, err)
+	assert.Equal(t, "access-c", s.Tokens.AccessToken)
+
+	// Timed out sign-ins fail
+	rr = httptest.NewRecorder()
+	authURL, err = m.BeginLogin(rr, b.request("/api/v1/login"), "https://evil.example.com")
+	require.NoError(t, err)
+	b.keep(rr)
+	now.Advance(loginTimeout + time.Second)
+	_, err = m.CompleteLogin(httptest.NewRecorder(), b.request("/api/v1/login/callback?code=c&state="+authURL[len("https://idp.example.com/auth?state="):]))
+	assert.ErrorIs(t, err, ErrLoginFailed)
+}
+
+func TestSafeReturnPath(t *testing.T) {
+	for path, want := range map[string]string{
+		"/models?page=2":           "/models?page=2",
+		"":                         "/",
+		"https://evil.example.com": "/",
+		"//evil.example.com":       "/",
+		"/\\evil.example.com":      "/",
+	} {
+		assert.Equal(t, want, SafeReturnPath(path), path)
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	_, err := FromContext(context.Background())
+	assert.ErrorIs(t, err, ErrNoSession)
+
+	ctx := NewContext(context.Background(), nil, errors.Join(ErrExpired))
+	_, err = FromContext(ctx)
+	assert.ErrorIs(t, err, ErrExpired)
+}
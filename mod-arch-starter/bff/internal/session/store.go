@@ -0,0 +1,224 @@
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxCookieSize is the size of the largest cookie browsers are required to keep, name and
+// attributes included (RFC 6265).
+const maxCookieSize = 4096
+
+// CookieOptions are the attributes of a session cookie. It is always HttpOnly.
+type CookieOptions struct {
+	Name     string
+	Path     string
+	Secure   bool
+	SameSite http.SameSite
+}
+
+func (o CookieOptions) set(w http.ResponseWriter, value string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     o.Name,
+		Value:    value,
+		Path:     o.Path,
+		Secure:   o.Secure,
+		HttpOnly: true,
+		SameSite: o.SameSite,
+	})
+}
+
+func (o CookieOptions) clear(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     o.Name,
+		Path:     o.Path,
+		Secure:   o.Secure,
+		HttpOnly: true,
+		SameSite: o.SameSite,
+		MaxAge:   -1,
+	})
+}
+
+// Sealer encrypts and authenticates cookie values with AES-GCM, the key being derived from a
+// secret.
+type Sealer struct {
+	aead cipher.AEAD
+}
+
+// NewSealer returns the Sealer of secret. Any string works, but it should be long and random.
+func NewSealer(secret string) (*Sealer, error) {
+	if secret == "" {
+		return nil, errors.New("a session secret is required")
+	}
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Sealer{aead: aead}, nil
+}
+
+// Seal returns the JSON of v encrypted for the cookie name: it can't be read, changed or
+// moved to another cookie.
+func (s *Sealer) Seal(name string, v any) (string, error) {
+	plaintext, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	_, _ = rand.Read(nonce)
+	return base64.RawURLEncoding.EncodeToString(s.aead.Seal(nonce, nonce, plaintext, []byte(name))), nil
+}
+
+// Open decodes a value sealed for the cookie name into v.
+func (s *Sealer) Open(name, value string, v any) error {
+	sealed, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(sealed) < s.aead.NonceSize() {
+		return errors.New("malformed sealed value")
+	}
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	plaintext, err := s.aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return errors.New("invalid sealed value")
+	}
+	return json.Unmarshal(plaintext, v)
+}
+
+// CookieStore keeps sessions in their cookie, sealed, so any replica serves them. Tokens must
+// fit in a cookie: the sessions of providers issuing large tokens need a server-side store.
+type CookieStore struct {
+	cookie CookieOptions
+	sealer *Sealer
+}
+
+// NewCookieStore returns a store of sessions sealed in the cookie of options.
+func NewCookieStore(cookie CookieOptions, sealer *Sealer) *CookieStore {
+	return &CookieStore{cookie: cookie, sealer: sealer}
+}
+
+func (c *CookieStore) Load(r *http.Request) (*Session, error) {
+	cookie, err := r.Cookie(c.cookie.Name)
+	if err != nil {
+		return nil, ErrNoSession
+	}
+	var s Session
+	if err := c.sealer.Open(c.cookie.Name, cookie.Value, &s); err != nil {
+		// Tampered with, or sealed with a previous secret
+		return nil, fmt.Errorf("%w: %v", ErrNoSession, err)
+	}
+	return &s, nil
+}
+
+func (c *CookieStore) Save(w http.ResponseWriter, _ *http.Request, s *Session) error {
+	value, err := c.sealer.Seal(c.cookie.Name, s)
+	if err != nil {
+		return err
+	}
+	// The attributes take about 100 bytes
+	if len(c.cookie.Name)+len(value)+100 > maxCookieSize {
+		return fmt.Errorf("the session is too large for a cookie (%d bytes), use the memory session store", len(value))
+	}
+	c.cookie.set(w, value)
+	return nil
+}
+
+func (c *CookieStore) Delete(w http.ResponseWriter, r *http.Request) error {
+	if _, err := r.Cookie(c.cookie.Name); err == nil {
+		c.cookie.clear(w)
+	}
+	return nil
+}
+
+// MemoryStore keeps sessions in memory, addressed by a random ID in their cookie. Sessions are
+// lost on restart and are only known to the replica that started them: it suits single
+// replicas, or sticky sessions.
+type MemoryStore struct {
+	cookie   CookieOptions
+	timeouts Timeouts
+	now      func() time.Time
+
+	mu        sync.Mutex
+	sessions  map[string]Session
+	lastSweep time.Time
+}
+
+// NewMemoryStore returns a store of sessions in memory, evicted once they time out.
+func NewMemoryStore(cookie CookieOptions, timeouts Timeouts) *MemoryStore {
+	return &MemoryStore{cookie: cookie, timeouts: timeouts, now: time.Now, sessions: map[string]Session{}}
+}
+
+func (m *MemoryStore) Load(r *http.Request) (*Session, error) {
+	cookie, err := r.Cookie(m.cookie.Name)
+	if err != nil {
+		return nil, ErrNoSession
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[cookie.Value]
+	if !ok {
+		return nil, ErrNoSession
+	}
+	s.ID = cookie.Value
+	return &s, nil
+}
+
+func (m *MemoryStore) Save(w http.ResponseWriter, _ *http.Request, s *Session) error {
+	if s.ID == "" {
+		id := make([]byte, 32)
+		_, _ = rand.Read(id)
+		s.ID = base64.RawURLEncoding.EncodeToString(id)
+		m.cookie.set(w, s.ID)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[s.ID] = *s
+	m.sweep()
+	return nil
+}
+
+func (m *MemoryStore) Delete(w http.ResponseWriter, r *http.Request) error {
+	cookie, err := r.Cookie(m.cookie.Name)
+	if err != nil {
+		return nil
+	}
+	m.mu.Lock()
+	delete(m.sessions, cookie.Value)
+	m.mu.Unlock()
+	m.cookie.clear(w)
+	return nil
+}
+
+// Len returns the number of sessions kept.
+func (m *MemoryStore) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.sessions)
+}
+
+// sweep evicts the sessions that timed out, at most every touchInterval. It must be called with
+// m.mu held.
+func (m *MemoryStore) sweep() {
+	now := m.now()
+	if now.Sub(m.lastSweep) < touchInterval {
+		return
+	}
+	m.lastSweep = now
+	for id, s := range m.sessions {
+		if m.timeouts.Expired(&s, now) {
+			delete(m.sessions, id)
+		}
+	}
+}
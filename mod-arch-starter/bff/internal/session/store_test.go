@@ -0,0 +1,95 @@
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealer(t *testing.T) {
+	sealer, err := NewSealer("a test secret of at least 32 bytes")
+	require.NoError(t, err)
+	sealed, err := sealer.Seal("session", Tokens{AccessToken: "secret-token"})
+	require.NoError(t, err)
+	assert.NotContains(t, sealed, "secret-token")
+
+	var tokens Tokens
+	require.NoError(t, sealer.Open("session", sealed, &tokens))
+	assert.Equal(t, "secret-token", tokens.AccessToken)
+
+	assert.Error(t, sealer.Open("session_login", sealed, &tokens), "values are bound to their cookie")
+	tampered := []byte(sealed)
+	tampered[len(tampered)-1] ^= 1
+	assert.Error(t, sealer.Open("session", string(tampered), &tokens))
+	other, err := NewSealer("another secret of at least 32 bytes")
+	require.NoError(t, err)
+	assert.Error(t, other.Open("session", sealed, &tokens))
+
+	_, err = NewSealer("")
+	assert.Error(t, err)
+}
+
+func TestCookieStore(t *testing.T) {
+	sealer, err := NewSealer("a test secret of at least 32 bytes")
+	require.NoError(t, err)
+	store := NewCookieStore(CookieOptions{Name: "session", Path: "/", Secure: true, SameSite: http.SameSiteLaxMode}, sealer)
+
+	_, err = store.Load(httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.ErrorIs(t, err, ErrNoSession)
+
+	rr := httptest.NewRecorder()
+	require.NoError(t, store.Save(rr, nil, &Session{Tokens: Tokens{AccessToken: "token"}}))
+	cookie := rr.Result().Cookies()[0]
+	assert.True(t, cookie.HttpOnly)
+	assert.True(t, cookie.Secure)
+	assert.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(cookie)
+	s, err := store.Load(r)
+	require.NoError(t, err)
+	assert.Equal(t, "token", s.Tokens.AccessToken)
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: "session", Value: "forged"})
+	_, err = store.Load(r)
+	assert.ErrorIs(t, err, ErrNoSession)
+
+	err = store.Save(httptest.NewRecorder(), nil, &Session{Tokens: Tokens{AccessToken: strings.Repeat("x", maxCookieSize)}})
+	assert.ErrorContains(t, err, "memory session store")
+}
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	store := NewMemoryStore(CookieOptions{Name: "session", Path: "/"}, Timeouts{Idle: 30 * time.Minute})
+	store.now = func() time.Time { return now }
+
+	rr := httptest.NewRecorder()
+	s := &Session{Tokens: Tokens{AccessToken: "token"}, CreatedAt: now, LastSeen: now}
+	require.NoError(t, store.Save(rr, nil, s))
+	cookie := rr.Result().Cookies()[0]
+	assert.Equal(t, s.ID, cookie.Value)
+	assert.NotContains(t, cookie.Value, "token", "the cookie only holds the session ID")
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(cookie)
+	loaded, err := store.Load(r)
+	require.NoError(t, err)
+	assert.Equal(t, "token", loaded.Tokens.AccessToken)
+
+	// Sessions that timed out are evicted
+	now = now.Add(time.Hour)
+	require.NoError(t, store.Save(httptest.NewRecorder(), nil, &Session{CreatedAt: now, LastSeen: now}))
+	assert.Equal(t, 1, store.Len())
+	_, err = store.Load(r)
+	assert.ErrorIs(t, err, ErrNoSession)
+
+	rr = httptest.NewRecorder()
+	require.NoError(t, store.Delete(rr, r))
+	assert.Equal(t, -1, rr.Result().Cookies()[0].MaxAge)
+}
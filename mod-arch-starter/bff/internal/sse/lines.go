@@ -0,0 +1,83 @@
+package sse
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/apierrors"
+)
+
+// Event types sent by StreamLines.
+const (
+	// EventLog carries one line of text, without its line break.
+	EventLog = "log"
+	// EventEnd tells the client the text is complete; it should close the EventSource
+	// rather than let it reconnect.
+	EventEnd = "end"
+)
+
+// StreamLines sends every line read from r as an EventLog event, then EventEnd when r ends,
+// e.g. for pod logs. A heartbeat comment is sent every heartbeat (default 30s) while no line
+// arrives. It returns when r ends, ctx is done or the client goes away; read failures are sent
+// as EventError and returned.
+//
+// Callers must close r once StreamLines returns, which also ends the pending read.
+func StreamLines(ctx context.Context, stream *Stream, r io.Reader, heartbeat time.Duration) error {
+	if heartbeat <= 0 {
+		heartbeat = defaultHeartbeat
+	}
+
+	lines := make(chan string)
+	readErr := make(chan error, 1)
+	go func() {
+		reader := bufio.NewReader(r)
+		for {
+			line, err := reader.ReadString('\n')
+			if line != "" {
+				select {
+				case lines <- strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"):
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-ticker.C:
+			if err := stream.Comment("heartbeat"); err != nil {
+				return err
+			}
+
+		case line := <-lines:
+			if err := stream.Send(Event{Event: EventLog, Data: line}); err != nil {
+				return err
+			}
+
+		case err := <-readErr:
+			if errors.Is(err, io.EOF) {
+				return stream.Send(Event{Event: EventEnd})
+			}
+			if ctx.Err() != nil {
+				return nil
+			}
+			_ = stream.Send(Event{Event: EventError, Data: apierrors.FromError(err).Envelope("").Error})
+			return err
+		}
+	}
+}
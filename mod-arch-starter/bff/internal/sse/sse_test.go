@@ -0,0 +1,171 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+func namespace(name, resourceVersion string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, ResourceVersion: resourceVersion}}
+}
+
+func TestStreamSend(t *testing.T) {
+	rr := httptest.NewRecorder()
+	stream, err := NewStream(rr)
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(Event{ID: "1", Event: "added", Data: map[string]string{"name": "a"}}))
+	require.NoError(t, stream.Send(Event{Data: "line1\nline2", Retry: 2 * time.Second}))
+	require.NoError(t, stream.Comment("heartbeat"))
+
+	assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "id: 1\nevent: added\ndata: {\"name\":\"a\"}\n\n"+
+		"retry: 2000\ndata: line1\ndata: line2\n\n"+
+		": heartbeat\n\n", rr.Body.String())
+}
+
+// scriptedWatches returns a WatchFunc that hands out the given watches (or errors) in order
+// and records the resourceVersion each one was opened with.
+type scriptedWatches struct {
+	results  []any
+	versions []string
+}
+
+func (s *scriptedWatches) watch(_ context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	s.versions = append(s.versions, opts.ResourceVersion)
+	if len(s.results) == 0 {
+		return nil, k8serrors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, "", nil)
+	}
+	next := s.results[0]
+	s.results = s.results[1:]
+	if err, ok := next.(error); ok {
+		return nil, err
+	}
+	return next.(watch.Interface), nil
+}
+
+func closedWatch(events ...watch.Event) watch.Interface {
+	fw := watch.NewFakeWithChanSize(len(events), false)
+	for _, e := range events {
+		fw.Action(e.Type, e.Object)
+	}
+	fw.Stop()
+	return fw
+}
+
+func TestStreamWatch_ReconnectAndResync(t *testing.T) {
+	script := &scriptedWatches{results: []any{
+		closedWatch(
+			watch.Event{Type: watch.Added, Object: namespace("a", "10")},
+			watch.Event{Type: watch.Bookmark, Object: namespace("", "12")},
+		),
+		// transient failure, retried from the last bookmark
+		k8serrors.NewServiceUnavailable("apiserver restarting"),
+		closedWatch(
+			watch.Event{Type: watch.Modified, Object: namespace("a", "13")},
+			watch.Event{Type: watch.Error, Object: &k8serrors.NewResourceExpired("too old").ErrStatus},
+		),
+		closedWatch(
+			watch.Event{Type: watch.Added, Object: namespace("a", "20")},
+		),
+	}}
+
+	rr := httptest.NewRecorder()
+	stream, err := NewStream(rr)
+	require.NoError(t, err)
+
+	err = StreamWatch(context.Background(), stream, script.watch, WatchOptions{ResourceVersion: "5", MinBackoff: time.Millisecond})
+	assert.True(t, k8serrors.IsForbidden(err))
+
+	assert.Equal(t, []string{"5", "12", "12", "", "20"}, script.versions)
+
+	body := rr.Body.String()
+	events := strings.Split(strings.TrimSpace(body), "\n\n")
+	require.Len(t, events, 6)
+	assert.True(t, strings.HasPrefix(events[0], "id: 10\nevent: added\ndata: {"))
+	assert.Equal(t, "id: 12\nevent: bookmark\ndata: ", events[1])
+	assert.True(t, strings.HasPrefix(events[2], "id: 13\nevent: modified\n"))
+	assert.Equal(t, "event: resync\ndata: ", events[3])
+	assert.True(t, strings.HasPrefix(events[4], "id: 20\nevent: added\n"))
+	assert.True(t, strings.HasPrefix(events[5], "event: error\ndata: {\"code\":\"403\""))
+}
+
+func TestStreamWatch_StopsWhenContextDone(t *testing.T) {
+	fw := watch.NewFake()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	rr := httptest.NewRecorder()
+	stream, err := NewStream(rr)
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- StreamWatch(ctx, stream, func(context.Context, metav1.ListOptions) (watch.Interface, error) { return fw, nil }, WatchOptions{})
+	}()
+
+	fw.Add(namespace("a", "1"))
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("StreamWatch did not return after the context was cancelled")
+	}
+	assert.True(t, fw.IsStopped())
+}
+
+type nonFlushingWriter struct{ http.ResponseWriter }
+
+func TestNewStream_RequiresFlusher(t *testing.T) {
+	_, err := NewStream(nonFlushingWriter{httptest.NewRecorder()})
+	assert.ErrorIs(t, err, ErrStreamingUnsupported)
+}
+
+func TestStreamLines(t *testing.T) {
+	rr := httptest.NewRecorder()
+	stream, err := NewStream(rr)
+	require.NoError(t, err)
+
+	require.NoError(t, StreamLines(context.Background(), stream, strings.NewReader("first\r\nsecond\n\nlast"), 0))
+	assert.Equal(t, "event: log\ndata: first\n\nevent: log\ndata: second\n\nevent: log\ndata: \n\n"+
+		"event: log\ndata: last\n\nevent: end\ndata: \n\n", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	stream, err = NewStream(rr)
+	require.NoError(t, err)
+	err = StreamLines(context.Background(), stream, io.MultiReader(strings.NewReader("first\n"), iotest.ErrReader(errors.New("connection reset"))), 0)
+	require.Error(t, err)
+	assert.Contains(t, rr.Body.String(), "event: log\ndata: first\n\nevent: error\n")
+	assert.NotContains(t, rr.Body.String(), "event: end")
+}
+
+func TestStreamLines_HeartbeatUntilContextDone(t *testing.T) {
+	rr := httptest.NewRecorder()
+	stream, err := NewStream(rr)
+	require.NoError(t, err)
+
+	r, w := io.Pipe()
+	defer w.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	require.NoError(t, StreamLines(ctx, stream, r, 10*time.Millisecond))
+	assert.Contains(t, rr.Body.String(), ": heartbeat\n\n")
+	assert.NotContains(t, rr.Body.String(), "event:")
+}
@@ -0,0 +1,111 @@
+// Package sse implements Server-Sent Events streaming for the BFF, including a watch loop that
+// turns a Kubernetes watch into a resumable event stream for the frontend.
+package sse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrStreamingUnsupported is returned when the ResponseWriter cannot be flushed.
+var ErrStreamingUnsupported = errors.New("streaming is not supported by the response writer")
+
+// Event is a single Server-Sent Event. Data is JSON-encoded unless it is a string.
+type Event struct {
+	// ID is sent as the event id; browsers send it back in Last-Event-ID on reconnect.
+	ID string
+	// Event is the event type (e.g. "added"); empty means the default "message" type.
+	Event string
+	Data  any
+	// Retry tells the browser how long to wait before reconnecting; zero leaves the default.
+	Retry time.Duration
+}
+
+// Stream writes events to an HTTP response. It is safe for concurrent use.
+type Stream struct {
+	mu         sync.Mutex
+	w          http.ResponseWriter
+	controller *http.ResponseController
+}
+
+// NewStream sends the event-stream headers and disables the server write timeout for this
+// response, since streams outlive it by design.
+func NewStream(w http.ResponseWriter) (*Stream, error) {
+	controller := http.NewResponseController(w)
+
+	// Clearing the deadline fails on writers that don't support it (e.g. in tests); that's fine.
+	_ = controller.SetWriteDeadline(time.Time{})
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	// Disable response buffering in nginx-based proxies
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if err := controller.Flush(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrStreamingUnsupported, err)
+	}
+
+	return &Stream{w: w, controller: controller}, nil
+}
+
+// Send writes and flushes a single event.
+func (s *Stream) Send(event Event) error {
+	var data string
+	switch d := event.Data.(type) {
+	case nil:
+	case string:
+		data = d
+	default:
+		encoded, err := json.Marshal(d)
+		if err != nil {
+			return fmt.Errorf("failed to encode event data: %w", err)
+		}
+		data = string(encoded)
+	}
+
+	var b strings.Builder
+	if event.ID != "" {
+		fmt.Fprintf(&b, "id: %s\n", sanitize(event.ID))
+	}
+	if event.Event != "" {
+		fmt.Fprintf(&b, "event: %s\n", sanitize(event.Event))
+	}
+	if event.Retry > 0 {
+		fmt.Fprintf(&b, "retry: %d\n", event.Retry.Milliseconds())
+	}
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+
+	return s.write(b.String())
+}
+
+// Comment writes an SSE comment, which clients ignore. It is used as a heartbeat to keep
+// idle connections from being closed by proxies.
+func (s *Stream) Comment(text string) error {
+	return s.write(": " + sanitize(text) + "\n\n")
+}
+
+func (s *Stream) write(payload string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.w.Write([]byte(payload)); err != nil {
+		return err
+	}
+	return s.controller.Flush()
+}
+
+// sanitize strips line breaks, which would end the field early.
+func sanitize(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
@@ -0,0 +1,204 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/apierrors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// Event types sent by StreamWatch.
+const (
+	EventAdded    = "added"
+	EventModified = "modified"
+	EventDeleted  = "deleted"
+	// EventBookmark only advances the event ID (resourceVersion); it carries no object.
+	EventBookmark = "bookmark"
+	// EventResync tells the client its state is stale: it must drop what it has, and the
+	// current objects follow as "added" events.
+	EventResync = "resync"
+	// EventError carries the error envelope of a failure that ends the stream (e.g. 403).
+	EventError = "error"
+)
+
+const (
+	defaultHeartbeat  = 30 * time.Second
+	defaultMinBackoff = time.Second
+	defaultMaxBackoff = 30 * time.Second
+)
+
+// WatchFunc opens a Kubernetes watch, e.g. on behalf of the requesting identity.
+type WatchFunc func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+
+// WatchOptions tunes StreamWatch. Zero values use the defaults.
+type WatchOptions struct {
+	// ResourceVersion resumes the stream after this version, typically taken from the
+	// Last-Event-ID header of a reconnecting browser. Empty starts with the current objects.
+	ResourceVersion string
+	// Heartbeat is how often a comment is sent on idle streams (default 30s).
+	Heartbeat time.Duration
+	// MinBackoff and MaxBackoff bound the delay between reconnect attempts after a
+	// transient failure (default 1s and 30s).
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	Logger     *slog.Logger
+}
+
+// StreamWatch forwards watch events to the stream until ctx is done or the client goes away.
+//
+// Every event carries the object's resourceVersion as its ID, and bookmarks are requested so
+// the ID keeps advancing on quiet streams. When the API server ends a watch (which it does
+// routinely) StreamWatch reopens it from the last resourceVersion, backing off on transient
+// errors. If that resourceVersion has expired (410 Gone) it sends EventResync and starts over
+// from the current state. Authorization and other permanent failures are sent as EventError
+// and returned.
+func StreamWatch(ctx context.Context, stream *Stream, watchFn WatchFunc, opts WatchOptions) error {
+	if opts.Heartbeat <= 0 {
+		opts.Heartbeat = defaultHeartbeat
+	}
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = defaultMinBackoff
+	}
+	if opts.MaxBackoff < opts.MinBackoff {
+		opts.MaxBackoff = max(defaultMaxBackoff, opts.MinBackoff)
+	}
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+
+	resourceVersion := opts.ResourceVersion
+	backoff := opts.MinBackoff
+
+	for ctx.Err() == nil {
+		w, err := watchFn(ctx, metav1.ListOptions{
+			ResourceVersion:     resourceVersion,
+			AllowWatchBookmarks: true,
+		})
+		if err != nil {
+			switch {
+			case ctx.Err() != nil:
+				return nil
+			case k8serrors.IsResourceExpired(err) || k8serrors.IsGone(err):
+				resourceVersion = ""
+				if err := stream.Send(Event{Event: EventResync}); err != nil {
+					return err
+				}
+				continue
+			case isPermanent(err):
+				_ = stream.Send(Event{Event: EventError, Data: apierrors.FromError(err).Envelope("").Error})
+				return err
+			}
+
+			logger.Warn("watch failed, retrying", "error", err, "backoff", backoff)
+			if !sleep(ctx, backoff) {
+				return nil
+			}
+			backoff = min(backoff*2, opts.MaxBackoff)
+			continue
+		}
+
+		backoff = opts.MinBackoff
+		resourceVersion, err = forwardEvents(ctx, stream, w, resourceVersion, opts.Heartbeat, logger)
+		w.Stop()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// forwardEvents consumes a single watch until it ends and returns the last resourceVersion
+// seen, or "" when a resync is needed. An error means the client can no longer be written to.
+func forwardEvents(ctx context.Context, stream *Stream, w watch.Interface, resourceVersion string, heartbeat time.Duration, logger *slog.Logger) (string, error) {
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return resourceVersion, nil
+
+		case <-ticker.C:
+			if err := stream.Comment("heartbeat"); err != nil {
+				return resourceVersion, err
+			}
+
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				// The API server closed the watch; reopen from where we are.
+				return resourceVersion, nil
+			}
+
+			switch event.Type {
+			case watch.Added, watch.Modified, watch.Deleted:
+				resourceVersion = objectResourceVersion(event.Object, resourceVersion)
+				err := stream.Send(Event{
+					ID:    resourceVersion,
+					Event: strings.ToLower(string(event.Type)),
+					Data:  event.Object,
+				})
+				if err != nil {
+					return resourceVersion, err
+				}
+
+			case watch.Bookmark:
+				resourceVersion = objectResourceVersion(event.Object, resourceVersion)
+				if err := stream.Send(Event{ID: resourceVersion, Event: EventBookmark}); err != nil {
+					return resourceVersion, err
+				}
+
+			case watch.Error:
+				err := k8serrors.FromObject(event.Object)
+				if k8serrors.IsResourceExpired(err) || k8serrors.IsGone(err) {
+					return "", stream.Send(Event{Event: EventResync})
+				}
+				logger.Warn("watch returned an error event, reconnecting", "error", err)
+				return resourceVersion, nil
+			}
+		}
+	}
+}
+
+func objectResourceVersion(obj runtime.Object, fallback string) string {
+	accessor, err := meta.Accessor(obj)
+	if err != nil || accessor.GetResourceVersion() == "" {
+		return fallback
+	}
+	return accessor.GetResourceVersion()
+}
+
+// isPermanent reports errors that retrying won't fix.
+func isPermanent(err error) bool {
+	var status k8serrors.APIStatus
+	if !errors.As(err, &status) {
+		return false
+	}
+	return k8serrors.IsForbidden(err) ||
+		k8serrors.IsUnauthorized(err) ||
+		k8serrors.IsNotFound(err) ||
+		k8serrors.IsBadRequest(err) ||
+		k8serrors.IsMethodNotSupported(err) ||
+		k8serrors.IsInvalid(err)
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,32 @@
+package testutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestObjectName(t *testing.T) {
+	name := objectName("access", UserSubject("doraNonAdmin@example.com"))
+	assert.Equal(t, "access-user-doranonadmin-example.com", name)
+	assert.Empty(t, validation.IsDNS1123Subdomain(name))
+}
+
+func TestAssetsDirectoryFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("KUBEBUILDER_ASSETS", "")
+	t.Setenv("ENVTEST_ASSETS", dir)
+
+	// A directory without kube-apiserver is skipped.
+	got, _ := assetsDirectory()
+	assert.NotEqual(t, dir, got)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "kube-apiserver"), nil, 0o755))
+	got, err := assetsDirectory()
+	require.NoError(t, err)
+	assert.Equal(t, dir, got)
+}
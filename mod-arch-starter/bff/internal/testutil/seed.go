@@ -0,0 +1,99 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// UserSubject returns an RBAC subject for a user name.
+func UserSubject(name string) rbacv1.Subject {
+	return rbacv1.Subject{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: name}
+}
+
+// GroupSubject returns an RBAC subject for a group name.
+func GroupSubject(name string) rbacv1.Subject {
+	return rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: name}
+}
+
+// CreateNamespaces creates the given namespaces.
+func (e *Env) CreateNamespaces(ctx context.Context, names ...string) error {
+	for _, name := range names {
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if _, err := e.Clientset.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create namespace %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// GrantClusterAdmin binds the built-in cluster-admin ClusterRole to the subject.
+func (e *Env) GrantClusterAdmin(ctx context.Context, subject rbacv1.Subject) error {
+	binding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: objectName("cluster-admin", subject),
+		},
+		Subjects: []rbacv1.Subject{subject},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     "cluster-admin",
+		},
+	}
+
+	if _, err := e.Clientset.RbacV1().ClusterRoleBindings().Create(ctx, binding, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create cluster-admin ClusterRoleBinding for %s: %w", subject.Name, err)
+	}
+	return nil
+}
+
+// GrantNamespaceAccess creates a Role in the namespace with the given rules and binds it to the subject.
+// With no rules, the subject gets get/list on namespaces and services, which is what the
+// namespace listing and the mocked envtest users rely on.
+func (e *Env) GrantNamespaceAccess(ctx context.Context, subject rbacv1.Subject, namespace string, rules ...rbacv1.PolicyRule) error {
+	if len(rules) == 0 {
+		rules = []rbacv1.PolicyRule{{
+			APIGroups: []string{""},
+			Resources: []string{"namespaces", "services"},
+			Verbs:     []string{"get", "list"},
+		}}
+	}
+
+	name := objectName("access", subject)
+	role := &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Rules:      rules,
+	}
+	if _, err := e.Clientset.RbacV1().Roles(namespace).Create(ctx, role, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create Role %s/%s: %w", namespace, name, err)
+	}
+
+	binding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Subjects:   []rbacv1.Subject{subject},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     name,
+		},
+	}
+	if _, err := e.Clientset.RbacV1().RoleBindings(namespace).Create(ctx, binding, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create RoleBinding %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
+
+// objectName derives a valid RBAC object name from the subject (user names are often e-mails).
+func objectName(prefix string, subject rbacv1.Subject) string {
+	name := strings.ToLower(fmt.Sprintf("%s-%s-%s", prefix, subject.Kind, subject.Name))
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '.' {
+			return r
+		}
+		return '-'
+	}, name)
+}
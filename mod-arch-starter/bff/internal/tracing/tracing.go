@@ -0,0 +1,113 @@
+// Package tracing wires OpenTelemetry tracing into the BFF: an OTLP/HTTP exporter configured
+// through the standard OTEL_* environment variables, W3C trace-context propagation, and helpers
+// to start spans in repositories.
+//
+// Until Setup is called the global tracer provider is a no-op, so spans started with StartSpan
+// cost next to nothing when tracing is disabled.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// DefaultServiceName is reported when OTEL_SERVICE_NAME is not set.
+	DefaultServiceName = "mod-arch-bff"
+
+	instrumentationName = "github.com/opendatahub-io/mod-arch-library/bff"
+)
+
+// Setup installs a global tracer provider that batches spans to an OTLP/HTTP collector and
+// enables W3C trace-context and baggage propagation. The exporter honours the standard
+// environment variables (OTEL_EXPORTER_OTLP_ENDPOINT, OTEL_EXPORTER_OTLP_TRACES_ENDPOINT,
+// OTEL_EXPORTER_OTLP_HEADERS, OTEL_EXPORTER_OTLP_INSECURE, ...), as does the sampler
+// (OTEL_TRACES_SAMPLER, OTEL_TRACES_SAMPLER_ARG) and the resource (OTEL_SERVICE_NAME,
+// OTEL_RESOURCE_ATTRIBUTES).
+//
+// The returned function flushes pending spans and must be called on shutdown.
+func Setup(ctx context.Context, serviceVersion string) (func(context.Context) error, error) {
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+
+	// Attributes from the environment take precedence over the defaults.
+	res, err := resource.Merge(
+		resource.NewSchemaless(
+			semconv.ServiceName(DefaultServiceName),
+			semconv.ServiceVersion(serviceVersion),
+		),
+		resource.Environment(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
+
+// StartSpan starts a child span of the span in ctx, e.g. StartSpan(ctx, "UserRepository.GetUser").
+// The caller must end the span; use RecordError to mark it as failed.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// RecordError marks the span as failed with err. It is a no-op for a nil error.
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// TraceID returns the trace ID of the span in ctx, or "" when there is no sampled span.
+func TraceID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return ""
+	}
+	return spanContext.TraceID().String()
+}
+
+// Middleware starts a server span per request, continuing the trace propagated by the caller
+// (e.g. the frontend). spanName names the span from the request, typically "<METHOD> <route>".
+func Middleware(spanName func(r *http.Request) string, next http.Handler) http.Handler {
+	return otelhttp.NewHandler(next, "bff",
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return spanName(r)
+		}),
+	)
+}
+
+// WrapTransport instruments an outgoing HTTP transport with client spans and propagates the
+// trace context to the server. spanName names the span from the request.
+// Its result can be adapted to transport.WrapperFunc for Kubernetes clients.
+func WrapTransport(rt http.RoundTripper, spanName func(r *http.Request) string) http.RoundTripper {
+	return otelhttp.NewTransport(rt,
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return spanName(r)
+		}),
+	)
+}
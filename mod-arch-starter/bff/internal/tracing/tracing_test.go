@@ -0,0 +1,99 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func setupTestProvider(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return recorder
+}
+
+func TestEndToEndSpans(t *testing.T) {
+	recorder := setupTestProvider(t)
+
+	// Fake API server that checks the trace context is propagated
+	var apiServerTraceParent string
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiServerTraceParent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer apiServer.Close()
+
+	client := &http.Client{Transport: WrapTransport(http.DefaultTransport, func(r *http.Request) string { return "k8s list namespaces" })}
+
+	var handlerTraceID string
+	handler := Middleware(func(r *http.Request) string { return r.Method + " /api/v1/namespaces" }, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlerTraceID = TraceID(r.Context())
+
+		ctx, span := StartSpan(r.Context(), "NamespaceRepository.GetNamespaces")
+		defer span.End()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiServer.URL+"/api/v1/namespaces", nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+	}))
+
+	// Trace started by the frontend
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/namespaces", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", handlerTraceID)
+	assert.Contains(t, apiServerTraceParent, "4bf92f3577b34da6a3ce929d0e0e4736")
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	names := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range spans {
+		names[span.Name()] = span
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	}
+	require.Contains(t, names, "GET /api/v1/namespaces")
+	require.Contains(t, names, "NamespaceRepository.GetNamespaces")
+	require.Contains(t, names, "k8s list namespaces")
+	assert.Equal(t, names["NamespaceRepository.GetNamespaces"].SpanContext().SpanID(), names["k8s list namespaces"].Parent().SpanID())
+	assert.Equal(t, names["GET /api/v1/namespaces"].SpanContext().SpanID(), names["NamespaceRepository.GetNamespaces"].Parent().SpanID())
+}
+
+func TestRecordError(t *testing.T) {
+	recorder := setupTestProvider(t)
+
+	_, span := StartSpan(context.Background(), "UserRepository.GetUser")
+	RecordError(span, nil)
+	RecordError(span, errors.New("boom"))
+	span.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "boom", spans[0].Status().Description)
+}
+
+func TestTraceIDWithoutSpan(t *testing.T) {
+	assert.Empty(t, TraceID(context.Background()))
+}
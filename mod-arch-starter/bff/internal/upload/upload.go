@@ -0,0 +1,348 @@
+// Package upload receives the files of multipart/form-data requests, streaming each one to a
+// destination, like an object store or an upstream API, without buffering the whole body in
+// memory or on disk. It bounds the size of the request and of each file, checks their content
+// types, and reports the progress of the transfer:
+//
+//	result, err := upload.Receive(w, r, upload.Options{
+//		MaxSize:      1 << 30,
+//		AllowedTypes: []string{"application/zip", "text/*"},
+//	}, func(ctx context.Context, file *upload.File) error {
+//		_, err := storage.Upload(ctx, "", "uploads/"+file.Filename, file, file.ContentType)
+//		return err
+//	})
+//
+// The errors are *apierrors.Error answering the client with 400, 413 or 415, except the ones
+// of the destination, returned as is.
+package upload
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/apierrors"
+)
+
+const (
+	// DefaultMaxSize is the default limit of the size of the request body.
+	DefaultMaxSize = 100 << 20
+	// DefaultProgressInterval is the default interval between the progress reports.
+	DefaultProgressInterval = time.Second
+	// MaxFieldSize is the limit of the size of each form field other than files.
+	MaxFieldSize = 64 << 10
+	// sniffLen is the number of bytes the content type of a file is detected from.
+	sniffLen = 512
+)
+
+// errFileTooLarge is the error of reading a file beyond Options.MaxFileSize.
+var errFileTooLarge = errors.New("upload: the file is too large")
+
+// Options configure Receive.
+type Options struct {
+	// MaxSize bounds the request body, files and fields included (default DefaultMaxSize).
+	MaxSize int64
+	// MaxFileSize bounds each file (default MaxSize).
+	MaxFileSize int64
+	// MaxFiles bounds the number of files (default 1).
+	MaxFiles int
+	// AllowedTypes are the media types a file may have, e.g. "application/zip", or a type
+	// with any subtype, e.g. "image/*". Without any, every type is allowed. A file declared as
+	// application/octet-stream, or without a type, has the type of its extension, or else the
+	// one detected from its first bytes.
+	AllowedTypes []string
+	// OnProgress, when set, is called with the progress of the request every ProgressInterval
+	// (default DefaultProgressInterval), and once when it is complete. It is called by the
+	// goroutine reading the files, so it must not block.
+	OnProgress       func(Progress)
+	ProgressInterval time.Duration
+}
+
+// File is a file being received. The destination reads its content from it.
+type File struct {
+	// Field is the name of the form field of the file.
+	Field string
+	// Filename is the base name of the file sent by the client.
+	Filename string
+	// ContentType is the media type of the file, declared by the client or detected.
+	ContentType string
+	// Fields are the form fields sent before the file, e.g. the folder to store it in. They
+	// must not be modified.
+	Fields map[string]string
+	io.Reader
+}
+
+// Destination stores a file, reading it until EOF. It may return early: the rest of the file
+// is then skipped. Its error fails the request.
+type Destination func(ctx context.Context, file *File) error
+
+// ToWriter returns a Destination copying the files to w, e.g. an io.Pipe to an upstream.
+func ToWriter(w io.Writer) Destination {
+	return func(_ context.Context, file *File) error {
+		_, err := io.Copy(w, file)
+		return err
+	}
+}
+
+// FileInfo describes a received file.
+type FileInfo struct {
+	Field       string `json:"field"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+}
+
+// Result is what Receive received: the files, in order, and the other form fields.
+type Result struct {
+	Files  []FileInfo
+	Fields map[string]string
+}
+
+// Progress is the progress of an upload.
+type Progress struct {
+	// Filename is the file being received, empty between files.
+	Filename string `json:"filename,omitempty"`
+	// Received is the number of bytes of the request body received so far.
+	Received int64 `json:"received"`
+	// Total is the size of the request body, or -1 when unknown.
+	Total int64 `json:"total"`
+	// Done is set by the last report, once the request has been received.
+	Done bool `json:"done,omitempty"`
+}
+
+// Receive reads the multipart/form-data body of r and streams each file to dest, in order.
+// Form fields other than files are collected in the result; those sent before a file are
+// available to the destination in File.Fields. The read deadline of the server is lifted:
+// MaxSize bounds the transfer instead.
+func Receive(w http.ResponseWriter, r *http.Request, opts Options, dest Destination) (*Result, error) {
+	opts = opts.withDefaults()
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" || params["boundary"] == "" {
+		return nil, apierrors.New(http.StatusUnsupportedMediaType, "the request must be multipart/form-data")
+	}
+	if r.ContentLength > opts.MaxSize {
+		return nil, tooLarge("the request", opts.MaxSize)
+	}
+
+	// Large uploads outlive the server read timeout
+	_ = http.NewResponseController(w).SetReadDeadline(time.Time{})
+	progress := &progressReader{
+		r:        http.MaxBytesReader(w, r.Body, opts.MaxSize),
+		report:   opts.OnProgress,
+		interval: opts.ProgressInterval,
+		progress: Progress{Total: r.ContentLength},
+	}
+	if r.ContentLength < 0 {
+		progress.progress.Total = -1
+	}
+	reader := multipart.NewReader(progress, params["boundary"])
+
+	result := &Result{Fields: map[string]string{}}
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, readError(err, opts)
+		}
+		if part.FileName() == "" {
+			value, err := readField(part)
+			if err != nil {
+				return nil, readError(err, opts)
+			}
+			result.Fields[part.FormName()] = value
+			continue
+		}
+		if len(result.Files) == opts.MaxFiles {
+			err := apierrors.BadRequest(fmt.Sprintf("at most %d files can be uploaded at once", opts.MaxFiles))
+			err.Details = map[string]any{"maxFiles": opts.MaxFiles}
+			return nil, err
+		}
+		info, err := receiveFile(r.Context(), part, result.Fields, opts, progress, dest)
+		if err != nil {
+			return nil, err
+		}
+		result.Files = append(result.Files, info)
+	}
+	if len(result.Files) == 0 {
+		return nil, apierrors.BadRequest("the request has no file")
+	}
+	progress.done()
+	return result, nil
+}
+
+func (opts Options) withDefaults() Options {
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = DefaultMaxSize
+	}
+	if opts.MaxFileSize <= 0 || opts.MaxFileSize > opts.MaxSize {
+		opts.MaxFileSize = opts.MaxSize
+	}
+	if opts.MaxFiles <= 0 {
+		opts.MaxFiles = 1
+	}
+	if opts.ProgressInterval <= 0 {
+		opts.ProgressInterval = DefaultProgressInterval
+	}
+	return opts
+}
+
+// receiveFile checks the content type of part and streams it to dest.
+func receiveFile(ctx context.Context, part *multipart.Part, fields map[string]string, opts Options, progress *progressReader, dest Destination) (FileInfo, error) {
+	filename := path.Base(strings.ReplaceAll(part.FileName(), `\`, "/"))
+	content := &fileReader{r: part, limit: opts.MaxFileSize}
+	buffered := bufio.NewReaderSize(content, sniffLen)
+	contentType, err := detectContentType(part.Header.Get("Content-Type"), filename, buffered)
+	if err != nil {
+		return FileInfo{}, readError(err, opts)
+	}
+	if !allowed(contentType, opts.AllowedTypes) {
+		err := apierrors.New(http.StatusUnsupportedMediaType, fmt.Sprintf("the file %s has the unsupported type %s", filename, contentType))
+		err.Details = map[string]any{"contentType": contentType, "allowedTypes": opts.AllowedTypes}
+		return FileInfo{}, err
+	}
+
+	progress.setFile(filename)
+	defer progress.setFile("")
+	file := &File{Field: part.FormName(), Filename: filename, ContentType: contentType, Fields: fields, Reader: buffered}
+	if err := dest(ctx, file); err != nil {
+		// The destination fails with the error of reading the body, possibly wrapped
+		if errors.Is(err, errFileTooLarge) || errors.As(err, new(*http.MaxBytesError)) {
+			return FileInfo{}, readError(err, opts)
+		}
+		return FileInfo{}, err
+	}
+	return FileInfo{Field: part.FormName(), Filename: filename, ContentType: contentType, Size: content.n}, nil
+}
+
+// detectContentType returns the declared media type or, when it is missing or generic, the
+// one of the extension of filename or the one detected from the first bytes of r.
+func detectContentType(declared, filename string, r *bufio.Reader) (string, error) {
+	if mediaType, _, err := mime.ParseMediaType(declared); err == nil && mediaType != "application/octet-stream" {
+		return mediaType, nil
+	}
+	if mediaType, _, err := mime.ParseMediaType(mime.TypeByExtension(path.Ext(filename))); err == nil {
+		return mediaType, nil
+	}
+	head, err := r.Peek(sniffLen)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	return mediaType, nil
+}
+
+// allowed reports whether mediaType matches one of patterns, or there are none.
+func allowed(mediaType string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return true
+			}
+		} else if strings.EqualFold(mediaType, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+func readField(part *multipart.Part) (string, error) {
+	value, err := io.ReadAll(io.LimitReader(part, MaxFieldSize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(value) > MaxFieldSize {
+		err := apierrors.BadRequest(fmt.Sprintf("the field %s is larger than %d bytes", part.FormName(), MaxFieldSize))
+		err.Details = map[string]any{"field": part.FormName(), "limit": MaxFieldSize}
+		return "", err
+	}
+	return string(value), nil
+}
+
+// readError maps the error of reading the body to the response of the client.
+func readError(err error, opts Options) error {
+	var apiErr *apierrors.Error
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr
+	case errors.Is(err, errFileTooLarge):
+		return tooLarge("a file", opts.MaxFileSize)
+	case errors.As(err, new(*http.MaxBytesError)):
+		return tooLarge("the request", opts.MaxSize)
+	default:
+		return apierrors.Wrap(http.StatusBadRequest, "the multipart body is malformed", err)
+	}
+}
+
+func tooLarge(what string, limit int64) error {
+	err := apierrors.New(http.StatusRequestEntityTooLarge, fmt.Sprintf("%s is larger than the limit of %d bytes", what, limit))
+	err.Details = map[string]any{"limit": limit}
+	return err
+}
+
+// fileReader counts the bytes of a file and fails beyond limit.
+type fileReader struct {
+	r     io.Reader
+	limit int64
+	n     int64
+}
+
+func (f *fileReader) Read(p []byte) (int, error) {
+	if f.n == f.limit {
+		// Whether the file ends at the limit or goes beyond it
+		var probe [1]byte
+		n, err := f.r.Read(probe[:])
+		if n > 0 {
+			return 0, errFileTooLarge
+		}
+		return 0, err
+	}
+	if remaining := f.limit - f.n; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := f.r.Read(p)
+	f.n += int64(n)
+	return n, err
+}
+
+// progressReader counts the bytes of the request body and reports the progress at most every
+// interval. It is read by one goroutine at a time, the one of Receive or of the destination.
+type progressReader struct {
+	r        io.Reader
+	report   func(Progress)
+	interval time.Duration
+	progress Progress
+	last     time.Time
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.progress.Received += int64(n)
+	if now := time.Now(); p.report != nil && now.Sub(p.last) >= p.interval {
+		p.last = now
+		p.report(p.progress)
+	}
+	return n, err
+}
+
+func (p *progressReader) setFile(filename string) {
+	p.progress.Filename = filename
+}
+
+func (p *progressReader) done() {
+	if p.report != nil {
+		p.progress.Done = true
+		p.report(p.progress)
+	}
+}
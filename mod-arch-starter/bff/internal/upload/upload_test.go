@@ -0,0 +1,238 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/apierrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type part struct {
+	field, filename, contentType, content string
+}
+
+func multipartRequest(t *testing.T, parts ...part) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, p := range parts {
+		header := textproto.MIMEHeader{}
+		if p.filename == "" {
+			header.Set("Content-Disposition", `form-data; name="`+p.field+`"`)
+		} else {
+			header.Set("Content-Disposition", `form-data; name="`+p.field+`"; filename="`+p.filename+`"`)
+		}
+		if p.contentType != "" {
+			header.Set("Content-Type", p.contentType)
+		}
+		w, err := writer.CreatePart(header)
+		require.NoError(t, err)
+		_, err = io.WriteString(w, p.content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	req := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+// collect returns a destination keeping the files it receives.
+func collect(files map[string]string) Destination {
+	return func(_ context.Context, file *File) error {
+		content, err := io.ReadAll(file)
+		if err != nil {
+			return err
+		}
+		files[file.Filename] = string(content)
+		return nil
+	}
+}
+
+func statusOf(t *testing.T, err error) int {
+	var apiErr *apierrors.Error
+	require.True(t, errors.As(err, &apiErr), "%v", err)
+	return apiErr.StatusCode
+}
+
+func TestReceive(t *testing.T) {
+	files := map[string]string{}
+	var progress []Progress
+	var prefix string
+	req := multipartRequest(t,
+		part{field: "prefix", content: "models/"},
+		part{field: "file", filename: `C:\Users\dora\config.json`, contentType: "application/json", content: `{"layers":40}`},
+		part{field: "file", filename: "LICENSE", content: "Apache License"},
+		part{field: "file", filename: "model.json", contentType: "application/octet-stream", content: "{}"},
+	)
+	result, err := Receive(httptest.NewRecorder(), req, Options{
+		MaxFiles:     3,
+		AllowedTypes: []string{"application/json", "text/*"},
+		OnProgress:   func(p Progress) { progress = append(progress, p) },
+	}, func(ctx context.Context, file *File) error {
+		prefix = file.Fields["prefix"]
+		return collect(files)(ctx, file)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []FileInfo{
+		{Field: "file", Filename: "config.json", ContentType: "application/json", Size: 13},
+		{Field: "file", Filename: "LICENSE", ContentType: "text/plain", Size: 14},
+		{Field: "file", Filename: "model.json", ContentType: "application/json", Size: 2},
+	}, result.Files, "the type of files without one is detected")
+	assert.Equal(t, map[string]string{"prefix": "models/"}, result.Fields)
+	assert.Equal(t, "models/", prefix, "the fields sent before a file")
+	assert.Equal(t, map[string]string{"config.json": `{"layers":40}`, "LICENSE": "Apache License", "model.json": "{}"}, files)
+
+	require.NotEmpty(t, progress)
+	last := progress[len(progress)-1]
+	assert.True(t, last.Done)
+	assert.Equal(t, req.ContentLength, last.Total)
+	assert.Equal(t, req.ContentLength, last.Received)
+	for _, p := range progress[:len(progress)-1] {
+		assert.False(t, p.Done)
+	}
+}
+
+func TestReceive_Errors(t *testing.T) {
+	discard := func(_ context.Context, file *File) error {
+		_, err := io.Copy(io.Discard, file)
+		return err
+	}
+	tests := []struct {
+		name   string
+		req    func() *http.Request
+		opts   Options
+		dest   Destination
+		status int
+	}{
+		{
+			name: "not multipart",
+			req: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("{}"))
+				req.Header.Set("Content-Type", "application/json")
+				return req
+			},
+			status: http.StatusUnsupportedMediaType,
+		},
+		{
+			name: "type not allowed",
+			req: func() *http.Request {
+				return multipartRequest(t, part{field: "file", filename: "a.zip", contentType: "application/zip", content: "PK"})
+			},
+			opts:   Options{AllowedTypes: []string{"image/*"}},
+			status: http.StatusUnsupportedMediaType,
+		},
+		{
+			name: "detected type not allowed",
+			req: func() *http.Request {
+				return multipartRequest(t, part{field: "file", filename: "index", content: "<html><body>"})
+			},
+			opts:   Options{AllowedTypes: []string{"image/*"}},
+			status: http.StatusUnsupportedMediaType,
+		},
+		{
+			name: "file too large",
+			req: func() *http.Request {
+				return multipartRequest(t, part{field: "file", filename: "a.txt", content: "0123456789"})
+			},
+			opts:   Options{MaxFileSize: 9},
+			status: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "request too large",
+			req: func() *http.Request {
+				return multipartRequest(t, part{field: "file", filename: "a.txt", content: "0123456789"})
+			},
+			opts:   Options{MaxSize: 100},
+			status: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "request of unknown size too large",
+			req: func() *http.Request {
+				req := multipartRequest(t, part{field: "file", filename: "a.txt", content: strings.Repeat("0", 1000)})
+				req.ContentLength = -1
+				return req
+			},
+			opts:   Options{MaxSize: 500},
+			status: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "too many files",
+			req: func() *http.Request {
+				return multipartRequest(t, part{field: "file", filename: "a.txt", content: "a"}, part{field: "file", filename: "b.txt", content: "b"})
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "no file",
+			req:    func() *http.Request { return multipartRequest(t, part{field: "prefix", content: "models/"}) },
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "field too large",
+			req: func() *http.Request {
+				return multipartRequest(t, part{field: "prefix", content: strings.Repeat("a", MaxFieldSize+1)})
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "malformed",
+			req: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("--b\r\nContent-Disposition: form-data; name=\"file\"; filename=\"a\"\r\n\r\nabc"))
+				req.Header.Set("Content-Type", "multipart/form-data; boundary=b")
+				return req
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "destination failing",
+			req:  func() *http.Request { return multipartRequest(t, part{field: "file", filename: "a.txt", content: "a"}) },
+			dest: func(context.Context, *File) error {
+				return apierrors.New(http.StatusForbidden, "no")
+			},
+			status: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := tt.dest
+			if dest == nil {
+				dest = discard
+			}
+			_, err := Receive(httptest.NewRecorder(), tt.req(), tt.opts, dest)
+			require.Error(t, err)
+			assert.Equal(t, tt.status, statusOf(t, err))
+		})
+	}
+}
+
+func TestReceive_StreamsFiles(t *testing.T) {
+	// A body of unknown size, received as it is written
+	reader, writer := io.Pipe()
+	body := multipart.NewWriter(writer)
+	go func() {
+		file, _ := body.CreateFormFile("file", "weights.bin")
+		for range 64 {
+			_, _ = file.Write(bytes.Repeat([]byte{1}, 1<<16))
+		}
+		_ = body.Close()
+		_ = writer.Close()
+	}()
+	req := httptest.NewRequest(http.MethodPost, "/upload", reader)
+	req.Header.Set("Content-Type", body.FormDataContentType())
+
+	var received bytes.Buffer
+	result, err := Receive(httptest.NewRecorder(), req, Options{MaxSize: 8 << 20}, ToWriter(&received))
+	require.NoError(t, err)
+	assert.Equal(t, int64(4<<20), result.Files[0].Size)
+	assert.Equal(t, "application/octet-stream", result.Files[0].ContentType)
+	assert.Equal(t, 4<<20, received.Len())
+}
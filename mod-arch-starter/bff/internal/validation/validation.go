@@ -0,0 +1,305 @@
+// Package validation checks decoded request bodies against the rules of their validate struct
+// tags, and reports every broken rule with the JSON path of its field, so that handlers answer
+// invalid bodies with the same field-level details:
+//
+//	type PermissionRequest struct {
+//		Verb     string `json:"verb" validate:"required"`
+//		Resource string `json:"resource" validate:"required,max=63"`
+//	}
+//
+// The rules of a tag are comma-separated:
+//   - required: the value is not the zero value (nil, "", 0, false, empty slice or map);
+//   - min=N and max=N bound a number, the number of characters of a string, or the number of
+//     items of a slice or map;
+//   - oneof=a b c: the string or integer is one of the space-separated values;
+//   - dnslabel and dnssubdomain: the string is a Kubernetes name (an RFC 1123 label or subdomain).
+//
+// Rules other than required accept zero values, which makes fields optional unless required.
+// Structs are validated recursively, through pointers, slices and maps. A type implementing
+// Validator is checked by its Validate method as well, for rules tags cannot express.
+//
+// The OpenAPI document derives the matching JSON schema constraints from the same tags.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
+)
+
+// TagName is the struct tag holding the rules of a field.
+const TagName = "validate"
+
+// Patterns of the dnslabel and dnssubdomain rules, as used in JSON schemas.
+const (
+	DNSLabelPattern     = `^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	DNSSubdomainPattern = `^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+)
+
+// Rule is a rule of a validate tag, e.g. {Name: "max", Param: "63"}.
+type Rule struct {
+	Name  string
+	Param string
+}
+
+// Rules parses a validate tag. It panics on an unknown rule or an invalid parameter: tags are
+// fixed at compile time, so an invalid one is a programming error.
+func Rules(tag string) []Rule {
+	if tag == "" {
+		return nil
+	}
+	var rules []Rule
+	for _, part := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch name {
+		case "required", "dnslabel", "dnssubdomain":
+			if param != "" {
+				panic(fmt.Sprintf("validation: rule %q takes no parameter in %q", name, tag))
+			}
+		case "min", "max":
+			if _, err := strconv.ParseFloat(param, 64); err != nil {
+				panic(fmt.Sprintf("validation: rule %q needs a number in %q", name, tag))
+			}
+		case "oneof":
+			if strings.TrimSpace(param) == "" {
+				panic(fmt.Sprintf("validation: rule oneof needs values in %q", tag))
+			}
+		default:
+			panic(fmt.Sprintf("validation: unknown rule %q in %q", name, tag))
+		}
+		rules = append(rules, Rule{Name: name, Param: param})
+	}
+	return rules
+}
+
+// Validator is implemented by types with rules between fields or that tags cannot express.
+// An Errors returned by Validate is reported under the path of the value; any other error is
+// reported on the value itself.
+type Validator interface {
+	Validate() error
+}
+
+// FieldError is a broken rule of a field, identified by its JSON path (e.g. "data[1].verb").
+// Field is empty for the body itself.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + " " + e.Message
+}
+
+// Errors are the broken rules of a body, in field order.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Invalid returns the Errors of a single broken rule of field, for the checks made by handlers
+// (e.g. a name that must match the path).
+func Invalid(field, message string) Errors {
+	return Errors{{Field: field, Message: message}}
+}
+
+// Validate checks v, usually a pointer to a decoded body, and returns the Errors of its broken
+// rules, or nil.
+func Validate(v any) error {
+	var errs Errors
+	validateValue(&errs, "", reflect.ValueOf(v))
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func validateValue(errs *Errors, path string, v reflect.Value) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		validateStruct(errs, path, v)
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			validateValue(errs, fmt.Sprintf("%s[%d]", path, i), v.Index(i))
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int { return strings.Compare(fmt.Sprint(a), fmt.Sprint(b)) })
+		for _, key := range keys {
+			validateValue(errs, fmt.Sprintf("%s[%v]", path, key), v.MapIndex(key))
+		}
+	}
+}
+
+func validateStruct(errs *Errors, path string, v reflect.Value) {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			// Embedded structs are inlined, like encoding/json does
+			validateValue(errs, path, v.Field(i))
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+
+		value := v.Field(i)
+		if message := check(Rules(field.Tag.Get(TagName)), value); message != "" {
+			*errs = append(*errs, FieldError{Field: fieldPath, Message: message})
+			continue
+		}
+		validateValue(errs, fieldPath, value)
+	}
+
+	if v.CanAddr() {
+		v = v.Addr()
+	}
+	if !v.CanInterface() {
+		return
+	}
+	validator, ok := v.Interface().(Validator)
+	if !ok {
+		return
+	}
+	err := validator.Validate()
+	var fieldErrs Errors
+	switch {
+	case err == nil:
+	case errors.As(err, &fieldErrs):
+		for _, fieldErr := range fieldErrs {
+			if path != "" {
+				fieldErr.Field = strings.TrimSuffix(path+"."+fieldErr.Field, ".")
+			}
+			*errs = append(*errs, fieldErr)
+		}
+	default:
+		*errs = append(*errs, FieldError{Field: path, Message: err.Error()})
+	}
+}
+
+// check returns the message of the first rule broken by v, or "".
+func check(rules []Rule, v reflect.Value) string {
+	for _, rule := range rules {
+		if rule.Name == "required" {
+			if isEmpty(v) {
+				return "is required"
+			}
+			continue
+		}
+		if isEmpty(v) {
+			continue
+		}
+		for v.Kind() == reflect.Pointer {
+			v = v.Elem()
+		}
+		if message := checkRule(rule, v); message != "" {
+			return message
+		}
+	}
+	return ""
+}
+
+func checkRule(rule Rule, v reflect.Value) string {
+	switch rule.Name {
+	case "min", "max":
+		bound, _ := strconv.ParseFloat(rule.Param, 64)
+		value, unit := measure(v)
+		if bound == 1 {
+			unit = strings.TrimSuffix(unit, "s")
+		}
+		switch {
+		case rule.Name == "min" && value < bound:
+			return fmt.Sprintf("must be at least %s%s", rule.Param, unit)
+		case rule.Name == "max" && value > bound:
+			return fmt.Sprintf("must be at most %s%s", rule.Param, unit)
+		}
+	case "oneof":
+		values := strings.Fields(rule.Param)
+		if !slices.Contains(values, format(v)) {
+			return "must be one of " + strings.Join(values, ", ")
+		}
+	case "dnslabel":
+		if len(k8svalidation.IsDNS1123Label(v.String())) > 0 {
+			return "must be a lowercase RFC 1123 label (at most 63 lowercase alphanumeric characters or '-', starting and ending with an alphanumeric character)"
+		}
+	case "dnssubdomain":
+		if len(k8svalidation.IsDNS1123Subdomain(v.String())) > 0 {
+			return "must be a lowercase RFC 1123 subdomain (at most 253 lowercase alphanumeric characters, '-' or '.', starting and ending with an alphanumeric character)"
+		}
+	}
+	return ""
+}
+
+// isEmpty reports whether v is the zero value, or an empty slice or map.
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+// format returns the string or integer v as written in oneof.
+func format(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	default:
+		panic(fmt.Sprintf("validation: oneof doesn't apply to %s", v.Type()))
+	}
+}
+
+// measure returns what min and max bound in v, and its unit in messages.
+func measure(v reflect.Value) (float64, string) {
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), ""
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), ""
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), ""
+	default:
+		panic(fmt.Sprintf("validation: min and max don't apply to %s", v.Type()))
+	}
+}
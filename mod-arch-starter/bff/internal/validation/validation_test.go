@@ -0,0 +1,93 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type port struct {
+	Name     string `json:"name,omitempty" validate:"dnslabel"`
+	Number   int    `json:"number" validate:"required,min=1,max=65535"`
+	Protocol string `json:"protocol,omitempty" validate:"oneof=TCP UDP"`
+}
+
+type base struct {
+	Name string `json:"name" validate:"required,dnssubdomain"`
+}
+
+type service struct {
+	base
+	Description string            `json:"description,omitempty" validate:"max=10"`
+	Ports       []port            `json:"ports" validate:"required,max=2"`
+	Primary     *port             `json:"primary,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty" validate:"max=1"`
+	Backends    map[string]port   `json:"backends,omitempty"`
+	Ignored     string            `json:"-" validate:"required"`
+}
+
+// Validate checks a rule between fields.
+func (s *service) Validate() error {
+	if s.Primary != nil && len(s.Ports) > 0 && s.Primary.Number != s.Ports[0].Number {
+		return Invalid("primary.number", "must be the number of the first port")
+	}
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	valid := &service{
+		base:    base{Name: "web.team-a"},
+		Ports:   []port{{Name: "http", Number: 80, Protocol: "TCP"}},
+		Primary: &port{Number: 80},
+	}
+	assert.NoError(t, Validate(valid))
+
+	err := Validate(&service{
+		base:        base{Name: "Web"},
+		Description: "more than ten characters",
+		Ports:       []port{{Name: "http_1", Number: 80}, {Number: 70000, Protocol: "SCTP"}, {}},
+		Primary:     &port{Number: 443},
+		Labels:      map[string]string{"a": "1", "b": "2"},
+		Backends:    map[string]port{"b": {}, "a": {Number: 80}},
+	})
+	var errs Errors
+	require.True(t, errors.As(err, &errs))
+	assert.Equal(t, Errors{
+		{Field: "name", Message: "must be a lowercase RFC 1123 subdomain (at most 253 lowercase alphanumeric characters, '-' or '.', starting and ending with an alphanumeric character)"},
+		{Field: "description", Message: "must be at most 10 characters"},
+		{Field: "ports", Message: "must be at most 2 items"},
+		{Field: "labels", Message: "must be at most 1 item"},
+		{Field: "backends[b].number", Message: "is required"},
+		{Field: "primary.number", Message: "must be the number of the first port"},
+	}, errs)
+
+	err = Validate(&service{
+		base:  base{Name: "web"},
+		Ports: []port{{Name: "http_1", Number: 80}, {Number: 70000, Protocol: "SCTP"}},
+	})
+	assert.EqualError(t, err, "ports[0].name must be a lowercase RFC 1123 label (at most 63 lowercase alphanumeric characters or '-', starting and ending with an alphanumeric character); "+
+		"ports[1].number must be at most 65535; ports[1].protocol must be one of TCP, UDP")
+
+	assert.EqualError(t, Validate(&service{base: base{Name: "web"}}), "ports is required")
+	assert.NoError(t, Validate(nil))
+}
+
+type nested struct {
+	Data *service `json:"data"`
+}
+
+func TestValidate_NestedValidator(t *testing.T) {
+	err := Validate(&nested{Data: &service{base: base{Name: "web"}, Ports: []port{{Number: 80}}, Primary: &port{Number: 443}}})
+	assert.Equal(t, Errors{{Field: "data.primary.number", Message: "must be the number of the first port"}}, err)
+}
+
+func TestRules(t *testing.T) {
+	assert.Equal(t, []Rule{{Name: "required"}, {Name: "max", Param: "63"}, {Name: "oneof", Param: "a b"}}, Rules("required, max=63,oneof=a b"))
+	assert.Nil(t, Rules(""))
+
+	for _, tag := range []string{"unknown", "max", "min=one", "oneof=", "required=true"} {
+		assert.Panics(t, func() { Rules(tag) }, tag)
+	}
+}
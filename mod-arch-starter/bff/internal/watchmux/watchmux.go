@@ -0,0 +1,559 @@
+// Package watchmux shares one Kubernetes watch of a resource between all the clients watching
+// it, so the SSE sessions of hundreds of browsers subscribed to the namespaces, or the
+// services of a namespace, cost the API server one watch instead of one each. The first
+// subscriber of a resource opens the upstream watch, with the credentials of the BFF, and it
+// is closed a little after the last one leaves.
+//
+// Subscribers get a watch.Interface like the one of the API server: the current objects as
+// ADDED events, or the events after the resourceVersion they resume from, then the changes.
+// A resourceVersion that is unknown, because it aged out of the history or comes from
+// another replica, fails with a 410 Expired like on the API server, and the subscriber starts
+// over. Each subscriber has a Filter, the RBAC checks of its user, run on its own goroutine so
+// a slow check only delays its subscriber.
+package watchmux
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+const (
+	// DefaultHistory is the number of recent events of an upstream kept for the subscribers
+	// resuming from a resourceVersion.
+	DefaultHistory = 256
+	// DefaultLinger is how long an upstream without subscribers stays open, for the browsers
+	// reconnecting or reloading the page.
+	DefaultLinger = 30 * time.Second
+
+	// subscriptionBuffer is the number of events a subscriber may lag behind before it is
+	// dropped with a 410 Expired; it then starts over from the current objects.
+	subscriptionBuffer = 256
+
+	minBackoff = time.Second
+	maxBackoff = 30 * time.Second
+)
+
+// ErrClosed is returned by Watch once the multiplexer is closed.
+var ErrClosed = errors.New("watch multiplexer closed")
+
+// Key identifies an upstream watch.
+type Key struct {
+	// Cluster is the cluster of the watch, "" for the local cluster.
+	Cluster string
+	GVR     schema.GroupVersionResource
+	// Namespace is the namespace watched, "" for a cluster-wide watch.
+	Namespace string
+}
+
+func (k Key) String() string {
+	return fmt.Sprintf("%s/%s/%s", k.Cluster, k.GVR.String(), k.Namespace)
+}
+
+// Source opens the upstream watch of a Key, with the credentials of the BFF.
+type Source func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+
+// Filter reports whether a subscriber receives the events of obj. An error ends the
+// subscription with an error event carrying it, e.g. a 403 once the user lost access. It is
+// called from a single goroutine per subscription.
+type Filter func(ctx context.Context, obj runtime.Object) (bool, error)
+
+// Options tunes the multiplexer. Zero values use the defaults.
+type Options struct {
+	// History is the number of recent events kept per upstream (default DefaultHistory).
+	History int
+	// Linger is how long an upstream without subscribers stays open (default DefaultLinger).
+	Linger time.Duration
+	Logger *slog.Logger
+}
+
+// Mux shares upstream watches between subscribers. It is safe for concurrent use.
+type Mux struct {
+	opts   Options
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu        sync.Mutex
+	upstreams map[Key]*upstream
+}
+
+func New(opts Options) *Mux {
+	if opts.History <= 0 {
+		opts.History = DefaultHistory
+	}
+	if opts.Linger <= 0 {
+		opts.Linger = DefaultLinger
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Mux{opts: opts, ctx: ctx, cancel: cancel, upstreams: map[Key]*upstream{}}
+}
+
+// Watch subscribes to the events of key after resourceVersion, or from the current objects
+// when empty, passing filter (nil passes all). The upstream watch is opened with source when
+// key has none; its failure to open is returned. The subscription ends when ctx is done or
+// it is stopped.
+func (m *Mux) Watch(ctx context.Context, key Key, source Source, resourceVersion string, filter Filter) (watch.Interface, error) {
+	for {
+		u, err := m.upstream(key, source)
+		if err != nil {
+			return nil, err
+		}
+		select {
+		case <-u.ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if u.err != nil {
+			return nil, u.err
+		}
+		sub, err := u.subscribe(ctx, resourceVersion, filter)
+		if errors.Is(err, errUpstreamStopped) {
+			// The upstream lingered out meanwhile; open another
+			continue
+		}
+		return sub, err
+	}
+}
+
+// Upstreams returns the number of open upstream watches.
+func (m *Mux) Upstreams() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.upstreams)
+}
+
+// Close stops the upstream watches, ending their subscriptions.
+func (m *Mux) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cancel()
+	m.upstreams = map[Key]*upstream{}
+}
+
+func (m *Mux) upstream(key Key, source Source) (*upstream, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.ctx.Err() != nil {
+		return nil, ErrClosed
+	}
+	if u, ok := m.upstreams[key]; ok {
+		return u, nil
+	}
+	ctx, cancel := context.WithCancel(m.ctx)
+	u := &upstream{
+		mux:         m,
+		key:         key,
+		cancel:      cancel,
+		ready:       make(chan struct{}),
+		objects:     map[string]runtime.Object{},
+		subscribers: map[*subscription]struct{}{},
+	}
+	m.upstreams[key] = u
+	m.opts.Logger.Debug("opening shared watch", "key", key.String())
+	go u.run(ctx, source)
+	return u, nil
+}
+
+// remove forgets u, unless another upstream replaced it.
+func (m *Mux) remove(u *upstream) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.upstreams[u.key] == u {
+		delete(m.upstreams, u.key)
+	}
+}
+
+var errUpstreamStopped = errors.New("upstream stopped")
+
+type entry struct {
+	resourceVersion string
+	event           watch.Event
+}
+
+// upstream is the shared watch of a Key: the current objects, the recent events and the
+// subscribers.
+type upstream struct {
+	mux    *Mux
+	key    Key
+	cancel context.CancelFunc
+	// ready is closed once the first watch opened, or failed with err.
+	ready chan struct{}
+	err   error
+
+	mu              sync.Mutex
+	stopped         bool
+	objects         map[string]runtime.Object
+	history         []entry
+	resourceVersion string
+	subscribers     map[*subscription]struct{}
+	linger          *time.Timer
+}
+
+// run keeps the upstream watch open, reopening it from the last resourceVersion when the API
+// server ends it, until the upstream is stopped.
+func (u *upstream) run(ctx context.Context, source Source) {
+	logger := u.mux.opts.Logger.With("key", u.key.String())
+	defer u.stop(nil)
+
+	resourceVersion := ""
+	opened := false
+	backoff := minBackoff
+	for ctx.Err() == nil {
+		w, err := source(ctx, metav1.ListOptions{ResourceVersion: resourceVersion, AllowWatchBookmarks: true})
+		if err != nil {
+			switch {
+			case ctx.Err() != nil:
+				return
+			case k8serrors.IsResourceExpired(err) || k8serrors.IsGone(err):
+				resourceVersion = ""
+				u.reset()
+				continue
+			case !opened:
+				u.err = err
+				close(u.ready)
+				return
+			case isPermanent(err):
+				logger.Warn("shared watch failed", "error", err)
+				u.stop(errorEvent(err))
+				return
+			}
+			logger.Warn("shared watch failed, retrying", "error", err, "backoff", backoff)
+			if !sleep(ctx, backoff) {
+				return
+			}
+			backoff = min(backoff*2, maxBackoff)
+			continue
+		}
+		if !opened {
+			opened = true
+			close(u.ready)
+		}
+		backoff = minBackoff
+		resourceVersion = u.consume(ctx, w, resourceVersion, logger)
+		w.Stop()
+	}
+}
+
+// consume publishes the events of a single watch until it ends, and returns the last
+// resourceVersion seen, or "" when the watch must start over.
+func (u *upstream) consume(ctx context.Context, w watch.Interface, resourceVersion string, logger *slog.Logger) string {
+	for {
+		select {
+		case <-ctx.Done():
+			return resourceVersion
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return resourceVersion
+			}
+			switch event.Type {
+			case watch.Added, watch.Modified, watch.Deleted, watch.Bookmark:
+				resourceVersion = objectResourceVersion(event.Object, resourceVersion)
+				u.publish(event, resourceVersion)
+			case watch.Error:
+				err := k8serrors.FromObject(event.Object)
+				if k8serrors.IsResourceExpired(err) || k8serrors.IsGone(err) {
+					u.reset()
+					return ""
+				}
+				logger.Warn("shared watch returned an error event, reconnecting", "error", err)
+				return resourceVersion
+			}
+		}
+	}
+}
+
+// publish records event and sends it to the subscribers, dropping those lagging behind.
+func (u *upstream) publish(event watch.Event, resourceVersion string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if key, ok := objectKey(event.Object); ok {
+		switch event.Type {
+		case watch.Added, watch.Modified:
+			u.objects[key] = event.Object
+		case watch.Deleted:
+			delete(u.objects, key)
+		}
+	}
+	u.resourceVersion = resourceVersion
+	if len(u.history) == u.mux.opts.History {
+		u.history = append(u.history[:0], u.history[1:]...)
+	}
+	u.history = append(u.history, entry{resourceVersion: resourceVersion, event: event})
+
+	for sub := range u.subscribers {
+		select {
+		case sub.in <- event:
+		default:
+			u.drop(sub, expiredEvent("the subscriber fell too far behind"))
+		}
+	}
+}
+
+// reset forgets the objects and the history after the upstream watch expired: the
+// subscribers are dropped with a 410 Expired, and start over.
+func (u *upstream) reset() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.objects = map[string]runtime.Object{}
+	u.history = nil
+	u.resourceVersion = ""
+	for sub := range u.subscribers {
+		u.drop(sub, expiredEvent("the shared watch restarted"))
+	}
+}
+
+// stop ends the upstream and its subscriptions, with end when set.
+func (u *upstream) stop(end *watch.Event) {
+	u.mux.remove(u)
+	u.cancel()
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.stopped {
+		return
+	}
+	u.stopped = true
+	if u.linger != nil {
+		u.linger.Stop()
+	}
+	for sub := range u.subscribers {
+		u.drop(sub, end)
+	}
+	u.mux.opts.Logger.Debug("closed shared watch", "key", u.key.String())
+}
+
+func (u *upstream) subscribe(ctx context.Context, resourceVersion string, filter Filter) (*subscription, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.stopped {
+		return nil, errUpstreamStopped
+	}
+
+	var replay []watch.Event
+	switch {
+	case resourceVersion == "":
+		keys := make([]string, 0, len(u.objects))
+		for key := range u.objects {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			replay = append(replay, watch.Event{Type: watch.Added, Object: u.objects[key]})
+		}
+	case resourceVersion == u.resourceVersion:
+	default:
+		found := false
+		for i := len(u.history) - 1; i >= 0; i-- {
+			if u.history[i].resourceVersion == resourceVersion {
+				for _, e := range u.history[i+1:] {
+					replay = append(replay, e.event)
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, k8serrors.NewResourceExpired(fmt.Sprintf("resourceVersion %s is not in the history of the shared watch", resourceVersion))
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	sub := &subscription{
+		upstream: u,
+		in:       make(chan watch.Event, len(replay)+subscriptionBuffer),
+		result:   make(chan watch.Event),
+		cancel:   cancel,
+		filter:   filter,
+		visible:  map[string]bool{},
+	}
+	for _, event := range replay {
+		sub.in <- event
+	}
+	u.subscribers[sub] = struct{}{}
+	if u.linger != nil {
+		u.linger.Stop()
+		u.linger = nil
+	}
+	go sub.run(ctx)
+	return sub, nil
+}
+
+func (u *upstream) unsubscribe(sub *subscription) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if _, ok := u.subscribers[sub]; !ok {
+		return
+	}
+	u.drop(sub, nil)
+	if len(u.subscribers) == 0 && !u.stopped && u.linger == nil {
+		u.linger = time.AfterFunc(u.mux.opts.Linger, u.lingerOut)
+	}
+}
+
+// lingerOut stops the upstream when it still has no subscribers.
+func (u *upstream) lingerOut() {
+	u.mu.Lock()
+	idle := len(u.subscribers) == 0 && !u.stopped
+	u.mu.Unlock()
+	if idle {
+		u.stop(nil)
+	}
+}
+
+// drop ends sub, after end when set. u.mu must be held.
+func (u *upstream) drop(sub *subscription, end *watch.Event) {
+	delete(u.subscribers, sub)
+	sub.end = end
+	close(sub.in)
+}
+
+// subscription is the watch.Interface of a subscriber.
+type subscription struct {
+	upstream *upstream
+	// in is closed by the upstream when the subscription is dropped, after setting end.
+	in     chan watch.Event
+	end    *watch.Event
+	result chan watch.Event
+	cancel context.CancelFunc
+	filter Filter
+	// visible are the keys of the objects sent to the subscriber, when filtered.
+	visible map[string]bool
+}
+
+func (s *subscription) ResultChan() <-chan watch.Event {
+	return s.result
+}
+
+func (s *subscription) Stop() {
+	s.cancel()
+	s.upstream.unsubscribe(s)
+}
+
+func (s *subscription) run(ctx context.Context) {
+	defer close(s.result)
+	defer s.upstream.unsubscribe(s)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-s.in:
+			if !ok {
+				if s.end != nil {
+					s.send(ctx, *s.end)
+				}
+				return
+			}
+			event, ok, err := s.pass(ctx, event)
+			if err != nil {
+				s.send(ctx, *errorEvent(err))
+				return
+			}
+			if ok && !s.send(ctx, event) {
+				return
+			}
+		}
+	}
+}
+
+// pass runs the filter on event. An object the subscriber can no longer see is sent as
+// deleted, and one it starts seeing as added, like the objects leaving and entering the label
+// selector of a watch.
+func (s *subscription) pass(ctx context.Context, event watch.Event) (watch.Event, bool, error) {
+	key, ok := objectKey(event.Object)
+	if s.filter == nil || !ok || event.Type == watch.Bookmark {
+		return event, true, nil
+	}
+	if event.Type == watch.Deleted {
+		visible := s.visible[key]
+		delete(s.visible, key)
+		return event, visible, nil
+	}
+	allowed, err := s.filter(ctx, event.Object)
+	if err != nil {
+		return event, false, err
+	}
+	switch {
+	case allowed && !s.visible[key]:
+		s.visible[key] = true
+		event.Type = watch.Added
+	case !allowed && s.visible[key]:
+		delete(s.visible, key)
+		event.Type = watch.Deleted
+	case !allowed:
+		return event, false, nil
+	}
+	return event, true, nil
+}
+
+func (s *subscription) send(ctx context.Context, event watch.Event) bool {
+	select {
+	case s.result <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func objectKey(obj runtime.Object) (string, bool) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return "", false
+	}
+	return accessor.GetNamespace() + "/" + accessor.GetName(), true
+}
+
+func objectResourceVersion(obj runtime.Object, fallback string) string {
+	accessor, err := meta.Accessor(obj)
+	if err != nil || accessor.GetResourceVersion() == "" {
+		return fallback
+	}
+	return accessor.GetResourceVersion()
+}
+
+func expiredEvent(message string) *watch.Event {
+	return errorEvent(k8serrors.NewResourceExpired(message))
+}
+
+// errorEvent is the watch.Error event of err, carrying its status like the API server.
+func errorEvent(err error) *watch.Event {
+	var status k8serrors.APIStatus
+	if !errors.As(err, &status) {
+		status = k8serrors.NewInternalError(err)
+	}
+	s := status.Status()
+	return &watch.Event{Type: watch.Error, Object: &s}
+}
+
+// isPermanent reports errors that reopening the watch won't fix.
+func isPermanent(err error) bool {
+	return k8serrors.IsForbidden(err) ||
+		k8serrors.IsUnauthorized(err) ||
+		k8serrors.IsNotFound(err) ||
+		k8serrors.IsBadRequest(err) ||
+		k8serrors.IsMethodNotSupported(err)
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
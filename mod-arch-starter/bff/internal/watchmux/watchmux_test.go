@@ -0,0 +1,211 @@
+package watchmux
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+var namespacesKey = Key{GVR: schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}}
+
+func namespace(name, resourceVersion string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, ResourceVersion: resourceVersion}}
+}
+
+// fakeSource serves the same fake watch to every upstream it opens, counting them.
+type fakeSource struct {
+	watch *watch.FakeWatcher
+	opens atomic.Int32
+}
+
+func newFakeSource() *fakeSource {
+	return &fakeSource{watch: watch.NewFakeWithChanSize(16, false)}
+}
+
+func (s *fakeSource) open(context.Context, metav1.ListOptions) (watch.Interface, error) {
+	s.opens.Add(1)
+	return s.watch, nil
+}
+
+func next(t *testing.T, w watch.Interface) watch.Event {
+	t.Helper()
+	select {
+	case event, ok := <-w.ResultChan():
+		require.True(t, ok, "the subscription ended")
+		return event
+	case <-time.After(time.Second):
+		require.Fail(t, "no event")
+		return watch.Event{}
+	}
+}
+
+func name(event watch.Event) string {
+	return event.Object.(metav1.Object).GetName()
+}
+
+func TestMux_SharesTheUpstream(t *testing.T) {
+	mux := New(Options{})
+	defer mux.Close()
+	source := newFakeSource()
+	ctx := context.Background()
+
+	first, err := mux.Watch(ctx, namespacesKey, source.open, "", nil)
+	require.NoError(t, err)
+	defer first.Stop()
+	source.watch.Add(namespace("dora-namespace", "1"))
+	assert.Equal(t, "dora-namespace", name(next(t, first)))
+
+	// A new subscriber gets the current objects, then the changes like the first
+	second, err := mux.Watch(ctx, namespacesKey, source.open, "", nil)
+	require.NoError(t, err)
+	defer second.Stop()
+	event := next(t, second)
+	assert.Equal(t, watch.Added, event.Type)
+	assert.Equal(t, "dora-namespace", name(event))
+
+	source.watch.Modify(namespace("dora-namespace", "2"))
+	assert.Equal(t, watch.Modified, next(t, first).Type)
+	assert.Equal(t, watch.Modified, next(t, second).Type)
+	assert.Equal(t, int32(1), source.opens.Load())
+	assert.Equal(t, 1, mux.Upstreams())
+}
+
+func TestMux_Resume(t *testing.T) {
+	mux := New(Options{History: 2})
+	defer mux.Close()
+	source := newFakeSource()
+	ctx := context.Background()
+
+	w, err := mux.Watch(ctx, namespacesKey, source.open, "", nil)
+	require.NoError(t, err)
+	defer w.Stop()
+	for i, rv := range []string{"1", "2", "3"} {
+		source.watch.Add(namespace(string(rune('a'+i)), rv))
+		next(t, w)
+	}
+
+	resumed, err := mux.Watch(ctx, namespacesKey, source.open, "2", nil)
+	require.NoError(t, err)
+	defer resumed.Stop()
+	assert.Equal(t, "c", name(next(t, resumed)), "the events after the resourceVersion")
+
+	_, err = mux.Watch(ctx, namespacesKey, source.open, "1", nil)
+	assert.True(t, k8serrors.IsResourceExpired(err), "aged out of the history, got %v", err)
+}
+
+func TestMux_Filter(t *testing.T) {
+	mux := New(Options{})
+	defer mux.Close()
+	source := newFakeSource()
+	allowed := map[string]bool{"dora-namespace": true}
+	var denied atomic.Bool
+	filter := func(_ context.Context, obj runtime.Object) (bool, error) {
+		if denied.Load() {
+			return false, k8serrors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, "", errors.New("no access"))
+		}
+		return allowed[obj.(metav1.Object).GetName()], nil
+	}
+
+	w, err := mux.Watch(context.Background(), namespacesKey, source.open, "", filter)
+	require.NoError(t, err)
+	defer w.Stop()
+	source.watch.Add(namespace("bella-namespace", "1"))
+	source.watch.Add(namespace("dora-namespace", "2"))
+	assert.Equal(t, "dora-namespace", name(next(t, w)), "bella-namespace is filtered out")
+
+	// Deletions of objects the subscriber saw pass, others not
+	source.watch.Delete(namespace("bella-namespace", "3"))
+	source.watch.Delete(namespace("dora-namespace", "4"))
+	event := next(t, w)
+	assert.Equal(t, watch.Deleted, event.Type)
+	assert.Equal(t, "dora-namespace", name(event))
+
+	// An object the subscriber can no longer see is deleted, one it starts seeing is added
+	source.watch.Add(namespace("dora-namespace", "5"))
+	next(t, w)
+	delete(allowed, "dora-namespace")
+	allowed["bella-namespace"] = true
+	source.watch.Modify(namespace("dora-namespace", "6"))
+	source.watch.Modify(namespace("bella-namespace", "7"))
+	assert.Equal(t, watch.Deleted, next(t, w).Type)
+	event = next(t, w)
+	assert.Equal(t, watch.Added, event.Type)
+	assert.Equal(t, "bella-namespace", name(event))
+
+	denied.Store(true)
+	source.watch.Modify(namespace("bella-namespace", "8"))
+	event = next(t, w)
+	require.Equal(t, watch.Error, event.Type)
+	assert.True(t, k8serrors.IsForbidden(k8serrors.FromObject(event.Object)))
+	_, ok := <-w.ResultChan()
+	assert.False(t, ok, "the error ends the subscription")
+}
+
+func TestMux_DropsSlowSubscribers(t *testing.T) {
+	mux := New(Options{})
+	defer mux.Close()
+	source := &fakeSource{watch: watch.NewFakeWithChanSize(subscriptionBuffer+2, false)}
+
+	w, err := mux.Watch(context.Background(), namespacesKey, source.open, "", nil)
+	require.NoError(t, err)
+	defer w.Stop()
+	for i := 0; i < subscriptionBuffer+2; i++ {
+		source.watch.Add(namespace("ns", "1"))
+	}
+
+	// Not reading the events, the subscriber lags behind
+	require.Eventually(t, func() bool {
+		mux.mu.Lock()
+		u := mux.upstreams[namespacesKey]
+		mux.mu.Unlock()
+		u.mu.Lock()
+		defer u.mu.Unlock()
+		return len(u.subscribers) == 0
+	}, time.Second, 5*time.Millisecond)
+	var last watch.Event
+	for event := range w.ResultChan() {
+		last = event
+	}
+	assert.Equal(t, watch.Error, last.Type)
+	assert.True(t, k8serrors.IsResourceExpired(k8serrors.FromObject(last.Object)), "starting over")
+}
+
+func TestMux_Linger(t *testing.T) {
+	mux := New(Options{Linger: 20 * time.Millisecond})
+	defer mux.Close()
+	source := newFakeSource()
+
+	w, err := mux.Watch(context.Background(), namespacesKey, source.open, "", nil)
+	require.NoError(t, err)
+	w.Stop()
+	assert.Equal(t, 1, mux.Upstreams(), "kept for reconnects")
+	assert.Eventually(t, func() bool { return mux.Upstreams() == 0 }, time.Second, 5*time.Millisecond)
+	assert.True(t, source.watch.IsStopped())
+}
+
+func TestMux_OpenError(t *testing.T) {
+	mux := New(Options{})
+	defer mux.Close()
+	forbidden := k8serrors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, "", errors.New("no access"))
+
+	_, err := mux.Watch(context.Background(), namespacesKey, func(context.Context, metav1.ListOptions) (watch.Interface, error) {
+		return nil, forbidden
+	}, "", nil)
+	assert.True(t, k8serrors.IsForbidden(err), "got %v", err)
+	assert.Eventually(t, func() bool { return mux.Upstreams() == 0 }, time.Second, 5*time.Millisecond)
+
+	mux.Close()
+	_, err = mux.Watch(context.Background(), namespacesKey, newFakeSource().open, "", nil)
+	assert.ErrorIs(t, err, ErrClosed)
+}
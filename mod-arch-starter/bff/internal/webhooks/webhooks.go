@@ -0,0 +1,391 @@
+// Package webhooks delivers the events of the BFF, like a resource created through the API or
+// an operation completed, to the HTTP endpoints of the platform teams: CI systems, chat bots or
+// inventories. A Dispatcher queues the events of each endpoint and POSTs them in the background
+// as JSON, signed with the HMAC of the endpoint secret, retrying the transient failures with
+// an exponential backoff. Events whose delivery gives up are logged as dead letters, with their
+// payload, so nothing is lost silently:
+//
+//	POST /hooks/bff HTTP/1.1
+//	Content-Type: application/json
+//	X-ModArch-Event: resource.created
+//	X-ModArch-Delivery: 4f1c9a0de2b7c3a8
+//	X-ModArch-Timestamp: 1760437139
+//	X-ModArch-Signature: sha256=9c1e...
+//
+//	{"id":"4f1c9a0de2b7c3a8","type":"resource.created","time":"...","user":"dora@example.com",
+//	 "resource":{"resource":"secrets","namespace":"dora-namespace","name":"db"}}
+//
+// Events live in the memory of the replica that dispatched them: those still queued when the
+// BFF stops past its shutdown timeout are logged as dead letters too.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/opendatahub-io/mod-arch-library/bff/internal/resilience"
+)
+
+// Types of the events dispatched by the BFF.
+const (
+	EventResourceCreated    = "resource.created"
+	EventResourceUpdated    = "resource.updated"
+	EventResourceDeleted    = "resource.deleted"
+	EventOperationCompleted = "operation.completed"
+)
+
+// EventTypes lists the types of the events dispatched by the BFF.
+var EventTypes = []string{EventResourceCreated, EventResourceUpdated, EventResourceDeleted, EventOperationCompleted}
+
+// Headers of the deliveries.
+const (
+	EventHeader     = "X-ModArch-Event"
+	DeliveryHeader  = "X-ModArch-Delivery"
+	TimestampHeader = "X-ModArch-Timestamp"
+	SignatureHeader = "X-ModArch-Signature"
+)
+
+const (
+	// DefaultQueueSize bounds the events waiting for an endpoint when Options.QueueSize is not set.
+	DefaultQueueSize = 1000
+	// DefaultMaxAttempts is the number of deliveries of an event when Options.MaxAttempts is
+	// not set.
+	DefaultMaxAttempts = 5
+	// DefaultMinBackoff and DefaultMaxBackoff bound the delay between the attempts.
+	DefaultMinBackoff = time.Second
+	DefaultMaxBackoff = time.Minute
+)
+
+// Event is a notification of the activity of the BFF for the webhook endpoints.
+type Event struct {
+	// ID is set by Dispatch; endpoints deduplicate the retried deliveries with it.
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	// Time is set by Dispatch when zero.
+	Time time.Time `json:"time"`
+	// User is the user whose request caused the event, if any.
+	User     string    `json:"user,omitempty"`
+	Resource *Resource `json:"resource,omitempty"`
+	// Data is the detail of the event, e.g. the operation of operation.completed.
+	Data any `json:"data,omitempty"`
+}
+
+// Resource identifies the object of an event.
+type Resource struct {
+	// Resource is the API resource, e.g. "secrets".
+	Resource  string `json:"resource"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+}
+
+// Endpoint is a receiver of the events.
+type Endpoint struct {
+	Name string
+	URL  string
+	// Secret signs the deliveries; they are unsigned when empty.
+	Secret string
+	// Events are path.Match patterns of the event types delivered, e.g. "resource.*"; all
+	// events are delivered when empty.
+	Events []string
+}
+
+// Accepts reports whether events of eventType are delivered to the endpoint.
+func (e Endpoint) Accepts(eventType string) bool {
+	if len(e.Events) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(e.Events, func(pattern string) bool {
+		matched, _ := path.Match(pattern, eventType)
+		return matched
+	})
+}
+
+// ValidatePattern checks an event type pattern of Endpoint.Events: it must match one of
+// EventTypes.
+func ValidatePattern(pattern string) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid event pattern %q: %w", pattern, err)
+	}
+	if !(Endpoint{Events: []string{pattern}}).acceptsAny() {
+		return fmt.Errorf("event pattern %q matches none of %v", pattern, EventTypes)
+	}
+	return nil
+}
+
+func (e Endpoint) acceptsAny() bool {
+	return slices.ContainsFunc(EventTypes, e.Accepts)
+}
+
+// Sign returns the signature of a delivery: the hex HMAC-SHA256, keyed with secret, of the
+// timestamp header, a dot and the body, prefixed with "sha256=". Endpoints recompute it to
+// authenticate the deliveries, and reject old timestamps to prevent replays.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the Sign signature of a delivery, in constant time.
+func Verify(secret, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// Options configures New. Zero values use the defaults.
+type Options struct {
+	Endpoints []Endpoint
+	// Client sends the deliveries; a client with a 10s timeout by default.
+	Client *http.Client
+	// QueueSize defaults to DefaultQueueSize per endpoint. Events are dropped, and logged as
+	// dead letters, when the queue of their endpoint is full.
+	QueueSize int
+	// MaxAttempts defaults to DefaultMaxAttempts.
+	MaxAttempts int
+	// MinBackoff and MaxBackoff default to DefaultMinBackoff and DefaultMaxBackoff. A
+	// Retry-After of the endpoint is honored up to MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	Logger     *slog.Logger
+	// OnDelivery, when set, observes the outcome of each event per endpoint: "delivered",
+	// "failed" (given up after the attempts) or "dropped" (queue full or shutdown), e.g. for
+	// metrics.
+	OnDelivery func(endpoint, outcome string)
+}
+
+// Outcomes reported to Options.OnDelivery.
+const (
+	OutcomeDelivered = "delivered"
+	OutcomeFailed    = "failed"
+	OutcomeDropped   = "dropped"
+)
+
+// Dispatcher delivers the events to the endpoints accepting them, in the background: each
+// endpoint has its queue and worker, so a slow or failing endpoint delays only its own
+// events, which it receives in order. A nil *Dispatcher is valid and dispatches nothing.
+type Dispatcher struct {
+	opts    Options
+	workers []*worker
+
+	// ctx is canceled when Close gives up waiting, to abort the backoffs.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.RWMutex
+	closed  bool
+	running sync.WaitGroup
+}
+
+type worker struct {
+	endpoint Endpoint
+	queue    chan Event
+}
+
+// New starts a Dispatcher; Close stops it.
+func New(opts Options) *Dispatcher {
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultQueueSize
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = DefaultMinBackoff
+	}
+	if opts.MaxBackoff < opts.MinBackoff {
+		opts.MaxBackoff = max(DefaultMaxBackoff, opts.MinBackoff)
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	if opts.OnDelivery == nil {
+		opts.OnDelivery = func(string, string) {}
+	}
+
+	d := &Dispatcher{opts: opts}
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+	for _, endpoint := range opts.Endpoints {
+		w := &worker{endpoint: endpoint, queue: make(chan Event, opts.QueueSize)}
+		d.workers = append(d.workers, w)
+		d.running.Add(1)
+		go d.run(w)
+	}
+	return d
+}
+
+// Dispatch queues event for the endpoints accepting its type without blocking, and returns it
+// with its ID and time set.
+func (d *Dispatcher) Dispatch(event Event) Event {
+	if d == nil {
+		return event
+	}
+	if event.ID == "" {
+		event.ID = newID()
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return event
+	}
+	for _, w := range d.workers {
+		if !w.endpoint.Accepts(event.Type) {
+			continue
+		}
+		select {
+		case w.queue <- event:
+		default:
+			d.deadLetter(w.endpoint, event, 0, errors.New("queue full"))
+			d.opts.OnDelivery(w.endpoint.Name, OutcomeDropped)
+		}
+	}
+	return event
+}
+
+// Close stops accepting events and waits until the queued ones are delivered, or ctx is done:
+// the retries are then abandoned, and the undelivered events logged as dead letters.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		for _, w := range d.workers {
+			close(w.queue)
+		}
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		<-done
+		return errors.Join(errors.New("webhook events left undelivered"), ctx.Err())
+	}
+}
+
+func (d *Dispatcher) run(w *worker) {
+	defer d.running.Done()
+	for event := range w.queue {
+		if d.ctx.Err() != nil {
+			d.deadLetter(w.endpoint, event, 0, d.ctx.Err())
+			d.opts.OnDelivery(w.endpoint.Name, OutcomeDropped)
+			continue
+		}
+		d.deliver(w.endpoint, event)
+	}
+}
+
+// deliver sends event to endpoint until it is accepted with a 2xx, the endpoint rejects it
+// with another status than 408, 429 or 5xx, or the attempts are exhausted.
+func (d *Dispatcher) deliver(endpoint Endpoint, event Event) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		d.deadLetter(endpoint, event, 0, fmt.Errorf("failed to encode the event: %w", err))
+		d.opts.OnDelivery(endpoint.Name, OutcomeFailed)
+		return
+	}
+	logger := d.opts.Logger.With("endpoint", endpoint.Name, "event", event.ID, "type", event.Type)
+
+	for attempt := 1; ; attempt++ {
+		retryAfter, err := d.send(endpoint, event, body)
+		if err == nil {
+			logger.Debug("delivered webhook event", "attempt", attempt)
+			d.opts.OnDelivery(endpoint.Name, OutcomeDelivered)
+			return
+		}
+		var permanent permanentError
+		if errors.As(err, &permanent) || attempt == d.opts.MaxAttempts {
+			d.deadLetter(endpoint, event, attempt, err)
+			d.opts.OnDelivery(endpoint.Name, OutcomeFailed)
+			return
+		}
+		delay := resilience.Backoff(d.opts.MinBackoff, d.opts.MaxBackoff, attempt-1)
+		if retryAfter > 0 {
+			delay = min(retryAfter, d.opts.MaxBackoff)
+		}
+		logger.Warn("webhook delivery failed, retrying", "attempt", attempt, "retry_in", delay, "error", err)
+		if !resilience.Sleep(d.ctx, delay) {
+			d.deadLetter(endpoint, event, attempt, errors.Join(err, d.ctx.Err()))
+			d.opts.OnDelivery(endpoint.Name, OutcomeDropped)
+			return
+		}
+	}
+}
+
+// permanentError is a rejection of the endpoint not worth retrying.
+type permanentError struct{ error }
+
+// send makes one delivery attempt, returning the Retry-After of a rejected one.
+func (d *Dispatcher) send(endpoint Endpoint, event Event, body []byte) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, permanentError{err}
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event.Type)
+	req.Header.Set(DeliveryHeader, event.ID)
+	req.Header.Set(TimestampHeader, timestamp)
+	if endpoint.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(endpoint.Secret, timestamp, body))
+	}
+
+	resp, err := d.opts.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return 0, nil
+	case resilience.TransientStatus(resp.StatusCode) || resp.StatusCode == http.StatusRequestTimeout:
+		return resilience.ParseRetryAfter(resp.Header.Get("Retry-After")), fmt.Errorf("endpoint answered %s", resp.Status)
+	default:
+		return 0, permanentError{fmt.Errorf("endpoint rejected the event with %s", resp.Status)}
+	}
+}
+
+// deadLetter logs an event given up, with its payload so it can be replayed by hand.
+func (d *Dispatcher) deadLetter(endpoint Endpoint, event Event, attempts int, err error) {
+	payload, _ := json.Marshal(event)
+	d.opts.Logger.Error("webhook event dead-lettered",
+		"endpoint", endpoint.Name, "event", event.ID, "type", event.Type,
+		"attempts", attempts, "error", err, "payload", string(payload))
+}
+
+func newID() string {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,188 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndpointAccepts(t *testing.T) {
+	all := Endpoint{}
+	assert.True(t, all.Accepts(EventOperationCompleted))
+
+	resources := Endpoint{Events: []string{"resource.*"}}
+	assert.True(t, resources.Accepts(EventResourceCreated))
+	assert.True(t, resources.Accepts(EventResourceDeleted))
+	assert.False(t, resources.Accepts(EventOperationCompleted))
+
+	assert.NoError(t, ValidatePattern("resource.*"))
+	assert.NoError(t, ValidatePattern(EventOperationCompleted))
+	assert.ErrorContains(t, ValidatePattern("resource.renamed"), "matches none")
+	assert.ErrorContains(t, ValidatePattern("resource.[a"), "invalid event pattern")
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+	signature := Sign("s3cret", "1760437139", body)
+	assert.Regexp(t, "^sha256=[0-9a-f]{64}$", signature)
+	assert.True(t, Verify("s3cret", "1760437139", body, signature))
+	assert.False(t, Verify("other", "1760437139", body, signature))
+	assert.False(t, Verify("s3cret", "1760437140", body, signature), "the timestamp is signed")
+	assert.False(t, Verify("s3cret", "1760437139", []byte(`{"id":"2"}`), signature))
+}
+
+// receiver is a webhook endpoint answering with the statuses of responses in turn, then 204.
+type receiver struct {
+	mu        sync.Mutex
+	responses []int
+	received  []*http.Request
+	bodies    [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.received = append(rc.received, r)
+	rc.bodies = append(rc.bodies, body)
+	status := http.StatusNoContent
+	if len(rc.responses) > 0 {
+		status, rc.responses = rc.responses[0], rc.responses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (rc *receiver) count() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return len(rc.received)
+}
+
+func TestDispatcher(t *testing.T) {
+	flaky := &receiver{responses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}}
+	flakyServer := httptest.NewServer(flaky)
+	defer flakyServer.Close()
+	operations := &receiver{}
+	operationsServer := httptest.NewServer(operations)
+	defer operationsServer.Close()
+
+	var outcomes sync.Map
+	dispatcher := New(Options{
+		Endpoints: []Endpoint{
+			{Name: "ci", URL: flakyServer.URL, Secret: "s3cret", Events: []string{"resource.*"}},
+			{Name: "chat", URL: operationsServer.URL, Events: []string{EventOperationCompleted}},
+		},
+		MinBackoff: time.Millisecond,
+		MaxBackoff: 5 * time.Millisecond,
+		Logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+		OnDelivery: func(endpoint, outcome string) { outcomes.Store(endpoint, outcome) },
+	})
+
+	created := dispatcher.Dispatch(Event{
+		Type:     EventResourceCreated,
+		User:     "dora@example.com",
+		Resource: &Resource{Resource: "secrets", Namespace: "dora-namespace", Name: "db"},
+	})
+	assert.NotEmpty(t, created.ID)
+	assert.False(t, created.Time.IsZero())
+	dispatcher.Dispatch(Event{Type: EventOperationCompleted, Data: map[string]string{"status": "succeeded"}})
+	require.NoError(t, dispatcher.Close(context.Background()))
+
+	require.Equal(t, 3, flaky.count(), "retried twice")
+	req, body := flaky.received[2], flaky.bodies[2]
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, EventResourceCreated, req.Header.Get(EventHeader))
+	assert.Equal(t, created.ID, req.Header.Get(DeliveryHeader))
+	assert.True(t, Verify("s3cret", req.Header.Get(TimestampHeader), body, req.Header.Get(SignatureHeader)))
+	var delivered Event
+	require.NoError(t, json.Unmarshal(body, &delivered))
+	assert.Equal(t, created.ID, delivered.ID)
+	assert.Equal(t, "db", delivered.Resource.Name)
+	assert.Equal(t, flaky.bodies[0], body, "retries send the same event")
+
+	require.Equal(t, 1, operations.count(), "filtered by type")
+	assert.Empty(t, operations.received[0].Header.Get(SignatureHeader), "no secret, no signature")
+	for _, endpoint := range []string{"ci", "chat"} {
+		outcome, _ := outcomes.Load(endpoint)
+		assert.Equal(t, OutcomeDelivered, outcome, endpoint)
+	}
+
+	// Closed
+	dispatcher.Dispatch(Event{Type: EventResourceCreated})
+	assert.Equal(t, 3, flaky.count())
+}
+
+func TestDispatcher_DeadLetters(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		if r.Header.Get(EventHeader) == EventResourceDeleted {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	var mu sync.Mutex
+	var outcomes []string
+	dispatcher := New(Options{
+		Endpoints:   []Endpoint{{Name: "ci", URL: server.URL}},
+		MaxAttempts: 3,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  time.Millisecond,
+		Logger:      slog.New(slog.NewJSONHandler(&logs, nil)),
+		OnDelivery: func(_, outcome string) {
+			mu.Lock()
+			defer mu.Unlock()
+			outcomes = append(outcomes, outcome)
+		},
+	})
+	dispatcher.Dispatch(Event{Type: EventResourceCreated, Resource: &Resource{Resource: "secrets", Name: "db"}})
+	dispatcher.Dispatch(Event{Type: EventResourceDeleted})
+	require.NoError(t, dispatcher.Close(context.Background()))
+
+	assert.Equal(t, int32(4), attempts.Load(), "3 attempts, then 1 for the rejected event")
+	assert.Equal(t, []string{OutcomeFailed, OutcomeFailed}, outcomes)
+	assert.Contains(t, logs.String(), `"msg":"webhook event dead-lettered"`)
+	assert.Contains(t, logs.String(), `"attempts":3`)
+	assert.Contains(t, logs.String(), `\"name\":\"db\"`, "the payload is logged")
+	assert.Contains(t, logs.String(), "400 Bad Request")
+}
+
+func TestDispatcher_CloseTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	dispatcher := New(Options{
+		Endpoints:  []Endpoint{{Name: "ci", URL: server.URL}},
+		MinBackoff: time.Hour,
+		Logger:     slog.New(slog.NewJSONHandler(&logs, nil)),
+	})
+	dispatcher.Dispatch(Event{Type: EventResourceCreated})
+	dispatcher.Dispatch(Event{Type: EventResourceUpdated})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorContains(t, dispatcher.Close(ctx), "left undelivered")
+	assert.Equal(t, 2, bytes.Count(logs.Bytes(), []byte("webhook event dead-lettered")), "the retried and the queued events")
+
+	var nilDispatcher *Dispatcher
+	nilDispatcher.Dispatch(Event{Type: EventResourceCreated})
+	assert.NoError(t, nilDispatcher.Close(context.Background()))
+}